@@ -0,0 +1,636 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+const (
+	// JSONSchemaDraft is the JSON Schema dialect of the published annotation schemas.
+	// The schemas only use the subset shared with OpenAPI v3, so they can be embedded as OpenAPI components.
+	JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// quantityPattern matches the non-negative resource.Quantity strings.
+	quantityPattern = `^\+?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$`
+	// cpuSetPattern matches the Linux CPU list format, e.g. "0-3,8,10-11".
+	cpuSetPattern = `^([0-9]+(-[0-9]+)?)(,[0-9]+(-[0-9]+)?)*$`
+)
+
+var cpuSetRegexp = regexp.MustCompile(cpuSetPattern)
+
+// JSONSchema is the JSON Schema of an annotation payload.
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type       string                 `json:"type,omitempty"`
+	Format     string                 `json:"format,omitempty"`
+	Pattern    string                 `json:"pattern,omitempty"`
+	Enum       []string               `json:"enum,omitempty"`
+	Minimum    *int64                 `json:"minimum,omitempty"`
+	Maximum    *int64                 `json:"maximum,omitempty"`
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+	Items      *JSONSchema            `json:"items,omitempty"`
+	// PropertyNames constrains the keys of maps keyed by an enum type.
+	PropertyNames *JSONSchema `json:"propertyNames,omitempty"`
+	// AdditionalProperties is either a *JSONSchema for maps, or false for structs which reject unknown fields.
+	AdditionalProperties interface{}   `json:"additionalProperties,omitempty"`
+	AnyOf                []*JSONSchema `json:"anyOf,omitempty"`
+
+	XIntOrString bool `json:"x-kubernetes-int-or-string,omitempty"`
+}
+
+// AnnotationProtocol describes an annotation whose value is a JSON payload of a Go type.
+type AnnotationProtocol struct {
+	// Key is the annotation key.
+	Key string
+	// Description describes who sets the annotation and what it means.
+	Description string
+
+	newPayload func() interface{}
+	validate   func(payload interface{}, fldPath *field.Path) field.ErrorList
+}
+
+var annotationProtocols = []*AnnotationProtocol{
+	{
+		Key:         AnnotationResourceSpec,
+		Description: "The CPU bind and exclusive policies preferred by the pod.",
+		newPayload:  func() interface{} { return &ResourceSpec{} },
+	},
+	{
+		Key:         AnnotationResourceStatus,
+		Description: "The CPUs and the NUMA Node resources allocated to the pod by koord-scheduler.",
+		newPayload:  func() interface{} { return &ResourceStatus{} },
+		validate:    validateResourceStatus,
+	},
+	{
+		Key:         AnnotationDeviceAllocated,
+		Description: "The devices allocated to the pod by koord-scheduler, grouped by the device type.",
+		newPayload:  func() interface{} { return &DeviceAllocations{} },
+		validate:    validateDeviceAllocations,
+	},
+	{
+		Key:         AnnotationExtendedResourceSpec,
+		Description: "The extended resources of the pod containers, e.g. batch resources.",
+		newPayload:  func() interface{} { return &ExtendedResourceSpec{} },
+	},
+	{
+		Key:         AnnotationNUMAAntiAffinity,
+		Description: "The pods whose NUMA Nodes should not be shared with the pod.",
+		newPayload:  func() interface{} { return &NUMAAntiAffinity{} },
+		validate:    validateNUMAAntiAffinity,
+	},
+	{
+		Key:         AnnotationReservationAllocated,
+		Description: "The reservation allocated to the pod by koord-scheduler.",
+		newPayload:  func() interface{} { return &ReservationAllocated{} },
+	},
+	{
+		Key:         AnnotationReservationAffinity,
+		Description: "The reservations which the pod must allocate from.",
+		newPayload:  func() interface{} { return &ReservationAffinity{} },
+		validate:    validateReservationAffinity,
+	},
+	{
+		Key:         AnnotationReservationTolerations,
+		Description: "The tolerations of the pod to the taints of the capacity-only reservations.",
+		newPayload:  func() interface{} { return &[]corev1.Toleration{} },
+	},
+	{
+		Key:         AnnotationCustomUsageThresholds,
+		Description: "The node-level utilization thresholds used by the LoadAwareScheduling plugin.",
+		newPayload:  func() interface{} { return &CustomUsageThresholds{} },
+	},
+	{
+		Key:         AnnotationPreferredNodes,
+		Description: "The preferred target nodes of a pod rebalanced by koord-descheduler.",
+		newPayload:  func() interface{} { return &PreferredNodes{} },
+	},
+	{
+		Key:         AnnotationCheckpointStatus,
+		Description: "The checkpoint of the pod containers made by koordlet for the migration.",
+		newPayload:  func() interface{} { return &CheckpointStatus{} },
+	},
+	{
+		Key:         AnnotationPodCPUBurst,
+		Description: "The pod-level CPU Burst config which overrides the NodeSLO.",
+		newPayload:  func() interface{} { return &slov1alpha1.CPUBurstConfig{} },
+	},
+	{
+		Key:         AnnotationPodMemoryQoS,
+		Description: "The pod-level Memory QoS config which overrides the NodeSLO.",
+		newPayload:  func() interface{} { return &slov1alpha1.PodMemoryQOSConfig{} },
+	},
+}
+
+// annotationEnums are the allowed values of the string types used in annotation payloads.
+var annotationEnums = map[reflect.Type][]string{
+	reflect.TypeOf(CPUBindPolicy("")): {
+		string(CPUBindPolicyDefault), string(CPUBindPolicyFullPCPUs),
+		string(CPUBindPolicySpreadByPCPUs), string(CPUBindPolicyConstrainedBurst),
+	},
+	reflect.TypeOf(CPUExclusivePolicy("")): {
+		string(CPUExclusivePolicyNone), string(CPUExclusivePolicyPCPULevel), string(CPUExclusivePolicyNUMANodeLevel),
+	},
+	reflect.TypeOf(schedulingv1alpha1.DeviceType("")): {
+		string(schedulingv1alpha1.GPU), string(schedulingv1alpha1.FPGA),
+		string(schedulingv1alpha1.RDMA), string(schedulingv1alpha1.NIC),
+	},
+	reflect.TypeOf(CheckpointPhase("")): {
+		string(CheckpointSucceeded), string(CheckpointFailed), string(CheckpointUnsupported),
+	},
+	reflect.TypeOf(slov1alpha1.CPUBurstPolicy("")): {
+		string(slov1alpha1.CPUBurstNone), string(slov1alpha1.CPUBurstOnly),
+		string(slov1alpha1.CFSQuotaBurstOnly), string(slov1alpha1.CPUBurstAuto),
+	},
+	reflect.TypeOf(slov1alpha1.PodMemoryQOSPolicy("")): {
+		string(slov1alpha1.PodMemoryQOSPolicyDefault), string(slov1alpha1.PodMemoryQOSPolicyNone),
+		string(slov1alpha1.PodMemoryQOSPolicyAuto),
+	},
+}
+
+type fieldBounds struct {
+	min, max *int64
+}
+
+func bounds(min, max int64) fieldBounds {
+	return fieldBounds{min: &min, max: &max}
+}
+
+func lowerBound(min int64) fieldBounds {
+	return fieldBounds{min: &min}
+}
+
+// annotationFieldBounds are the value ranges of the integer fields, keyed by the struct type and the json name.
+// They mirror the kubebuilder validation markers of the same fields in the CRDs.
+var annotationFieldBounds = map[reflect.Type]map[string]fieldBounds{
+	reflect.TypeOf(DeviceAllocation{}): {
+		"minor": lowerBound(0),
+	},
+	reflect.TypeOf(CPUSharedPool{}): {
+		"socket": lowerBound(0),
+		"node":   lowerBound(0),
+	},
+	reflect.TypeOf(NUMANodeResource{}): {
+		"node": lowerBound(0),
+	},
+	reflect.TypeOf(slov1alpha1.CPUBurstConfig{}): {
+		"cpuBurstPercent":      bounds(0, 10000),
+		"cfsQuotaBurstPercent": lowerBound(0),
+		"startupBoostPercent":  lowerBound(0),
+		"startupBoostSeconds":  lowerBound(0),
+	},
+	reflect.TypeOf(slov1alpha1.MemoryQOS{}): {
+		"minLimitPercent":   lowerBound(0),
+		"lowLimitPercent":   lowerBound(0),
+		"throttlingPercent": lowerBound(0),
+		"wmarkRatio":        bounds(0, 100),
+		"wmarkScalePermill": bounds(1, 1000),
+		"wmarkMinAdj":       bounds(-25, 50),
+	},
+}
+
+// annotationRawMessageTypes are the Go types of the json.RawMessage fields, keyed by the struct type and the json name.
+var annotationRawMessageTypes = map[reflect.Type]map[string]reflect.Type{
+	reflect.TypeOf(DeviceAllocation{}): {
+		"extension": reflect.TypeOf(DeviceAllocationExtension{}),
+	},
+}
+
+var (
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	timeType        = reflect.TypeOf(metav1.Time{})
+	durationType    = reflect.TypeOf(metav1.Duration{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+)
+
+// GetAnnotationProtocols returns the annotation protocols sorted by the key.
+func GetAnnotationProtocols() []*AnnotationProtocol {
+	protocols := make([]*AnnotationProtocol, len(annotationProtocols))
+	copy(protocols, annotationProtocols)
+	sort.Slice(protocols, func(i, j int) bool {
+		return protocols[i].Key < protocols[j].Key
+	})
+	return protocols
+}
+
+// GetAnnotationProtocol returns the protocol of the annotation key, or nil if the annotation is not a JSON protocol.
+func GetAnnotationProtocol(key string) *AnnotationProtocol {
+	for _, p := range annotationProtocols {
+		if p.Key == key {
+			return p
+		}
+	}
+	return nil
+}
+
+// Schema generates the JSON Schema of the annotation payload from its Go type.
+func (p *AnnotationProtocol) Schema() *JSONSchema {
+	schema := schemaForType(reflect.TypeOf(p.newPayload()), map[reflect.Type]bool{})
+	schema.Schema = JSONSchemaDraft
+	schema.ID = p.Key
+	schema.Title = p.Key
+	schema.Description = p.Description
+	return schema
+}
+
+// Validate checks the annotation value against the protocol. Unknown fields, values out of
+// the enums and bounds, and negative quantities are rejected.
+func (p *AnnotationProtocol) Validate(value string, fldPath *field.Path) field.ErrorList {
+	payload := p.newPayload()
+	if err := decodeStrict([]byte(value), payload); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, err.Error())}
+	}
+	allErrs := validateValue(reflect.ValueOf(payload), fldPath)
+	if p.validate != nil {
+		allErrs = append(allErrs, p.validate(payload, fldPath)...)
+	}
+	return allErrs
+}
+
+// ValidateAnnotations validates the values of all annotation protocols present in annotations.
+// Annotations without a JSON protocol are ignored.
+func ValidateAnnotations(annotations map[string]string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, p := range GetAnnotationProtocols() {
+		value, ok := annotations[p.Key]
+		if !ok {
+			continue
+		}
+		allErrs = append(allErrs, p.Validate(value, fldPath.Key(p.Key))...)
+	}
+	return allErrs
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the top-level value")
+	}
+	return nil
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case quantityType:
+		return &JSONSchema{
+			AnyOf:        []*JSONSchema{{Type: "integer", Minimum: int64Ptr(0)}, {Type: "string", Pattern: quantityPattern}},
+			XIntOrString: true,
+		}
+	case intOrStringType:
+		return &JSONSchema{
+			AnyOf:        []*JSONSchema{{Type: "integer"}, {Type: "string"}},
+			XIntOrString: true,
+		}
+	case rawMessageType:
+		return &JSONSchema{}
+	case timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &JSONSchema{Type: "string", Format: "duration"}
+	}
+	if values, ok := annotationEnums[t]; ok {
+		return &JSONSchema{Type: "string", Enum: values}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &JSONSchema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &JSONSchema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"}
+		}
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem(), visiting)}
+	case reflect.Map:
+		schema := &JSONSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem(), visiting)}
+		if values, ok := annotationEnums[t.Key()]; ok {
+			schema.PropertyNames = &JSONSchema{Enum: values}
+		}
+		return schema
+	case reflect.Struct:
+		if visiting[t] {
+			return &JSONSchema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+		addStructProperties(schema, t, visiting)
+		return schema
+	}
+	return &JSONSchema{}
+}
+
+func addStructProperties(schema *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, inline, ok := jsonFieldName(f)
+		if !ok {
+			continue
+		}
+		if inline {
+			addStructProperties(schema, f.Type, visiting)
+			continue
+		}
+		fieldType := f.Type
+		if rawType, ok := annotationRawMessageTypes[t][name]; ok {
+			fieldType = rawType
+		}
+		property := schemaForType(fieldType, visiting)
+		if b, ok := annotationFieldBounds[t][name]; ok {
+			property.Minimum, property.Maximum = b.min, b.max
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// jsonFieldName returns the json name of the struct field, and whether the field is an inlined struct.
+func jsonFieldName(f reflect.StructField) (name string, inline bool, ok bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name = strings.Split(tag, ",")[0]
+	if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+		return "", true, true
+	}
+	if f.PkgPath != "" {
+		return "", false, false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, false, true
+}
+
+// validateValue walks the decoded payload and checks the enums, the bounds and the quantities.
+func validateValue(v reflect.Value, fldPath *field.Path) field.ErrorList {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case quantityType:
+		q := v.Interface().(resource.Quantity)
+		if q.Sign() < 0 {
+			return field.ErrorList{field.Invalid(fldPath, q.String(), "must be greater than or equal to 0")}
+		}
+		return nil
+	case rawMessageType, timeType, durationType, intOrStringType:
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	switch v.Kind() {
+	case reflect.String:
+		if values, ok := annotationEnums[v.Type()]; ok && v.String() != "" {
+			if !containsString(values, v.String()) {
+				allErrs = append(allErrs, field.NotSupported(fldPath, v.String(), values))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			allErrs = append(allErrs, validateValue(v.Index(i), fldPath.Index(i))...)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			keyPath := fldPath.Key(fmt.Sprint(key.Interface()))
+			allErrs = append(allErrs, validateValue(key, keyPath)...)
+			allErrs = append(allErrs, validateValue(v.MapIndex(key), keyPath)...)
+		}
+	case reflect.Struct:
+		allErrs = append(allErrs, validateStruct(v, fldPath)...)
+	}
+	return allErrs
+}
+
+func validateStruct(v reflect.Value, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, inline, ok := jsonFieldName(t.Field(i))
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if inline {
+			allErrs = append(allErrs, validateStruct(fv, fldPath)...)
+			continue
+		}
+		childPath := fldPath.Child(name)
+		if b, ok := annotationFieldBounds[t][name]; ok {
+			allErrs = append(allErrs, validateBounds(fv, b, childPath)...)
+		}
+		if rawType, ok := annotationRawMessageTypes[t][name]; ok {
+			allErrs = append(allErrs, validateRawMessage(fv.Bytes(), rawType, childPath)...)
+			continue
+		}
+		allErrs = append(allErrs, validateValue(fv, childPath)...)
+	}
+	return allErrs
+}
+
+func validateRawMessage(data []byte, t reflect.Type, fldPath *field.Path) field.ErrorList {
+	if len(data) == 0 {
+		return nil
+	}
+	payload := reflect.New(t)
+	if err := decodeStrict(data, payload.Interface()); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, string(data), err.Error())}
+	}
+	return validateValue(payload, fldPath)
+}
+
+func validateBounds(v reflect.Value, b fieldBounds, fldPath *field.Path) field.ErrorList {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	var value int64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = v.Int()
+	default:
+		return nil
+	}
+	if b.min != nil && value < *b.min {
+		return field.ErrorList{field.Invalid(fldPath, value, fmt.Sprintf("must be greater than or equal to %d", *b.min))}
+	}
+	if b.max != nil && value > *b.max {
+		return field.ErrorList{field.Invalid(fldPath, value, fmt.Sprintf("must be less than or equal to %d", *b.max))}
+	}
+	return nil
+}
+
+func validateResourceStatus(payload interface{}, fldPath *field.Path) field.ErrorList {
+	status := payload.(*ResourceStatus)
+	if status.CPUSet != "" && !cpuSetRegexp.MatchString(status.CPUSet) {
+		return field.ErrorList{field.Invalid(fldPath.Child("cpuset"), status.CPUSet, "must be a Linux CPU list, e.g. 0-3,8")}
+	}
+	var allErrs field.ErrorList
+	for i, pool := range status.CPUSharedPools {
+		if pool.CPUSet != "" && !cpuSetRegexp.MatchString(pool.CPUSet) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cpuSharedPools").Index(i).Child("cpuset"), pool.CPUSet, "must be a Linux CPU list, e.g. 0-3,8"))
+		}
+	}
+	return allErrs
+}
+
+func validateDeviceAllocations(payload interface{}, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allocations := *payload.(*DeviceAllocations)
+	deviceTypes := make([]string, 0, len(allocations))
+	for deviceType := range allocations {
+		deviceTypes = append(deviceTypes, string(deviceType))
+	}
+	sort.Strings(deviceTypes)
+	for _, deviceType := range deviceTypes {
+		minors := map[int32]bool{}
+		for i, allocation := range allocations[schedulingv1alpha1.DeviceType(deviceType)] {
+			allocationPath := fldPath.Key(deviceType).Index(i)
+			if allocation == nil {
+				allErrs = append(allErrs, field.Required(allocationPath, "device allocation must not be null"))
+				continue
+			}
+			if minors[allocation.Minor] {
+				allErrs = append(allErrs, field.Duplicate(allocationPath.Child("minor"), allocation.Minor))
+			}
+			minors[allocation.Minor] = true
+		}
+	}
+	return allErrs
+}
+
+func validateNUMAAntiAffinity(payload interface{}, fldPath *field.Path) field.ErrorList {
+	antiAffinity := payload.(*NUMAAntiAffinity)
+	if (antiAffinity.ServiceName == "") == (antiAffinity.LabelSelector == nil) {
+		return field.ErrorList{field.Invalid(fldPath, "", "exactly one of serviceName and labelSelector must be specified")}
+	}
+	if _, err := metav1.LabelSelectorAsSelector(antiAffinity.LabelSelector); err != nil {
+		return field.ErrorList{field.Invalid(fldPath.Child("labelSelector"), antiAffinity.LabelSelector, err.Error())}
+	}
+	return nil
+}
+
+func validateReservationAffinity(payload interface{}, fldPath *field.Path) field.ErrorList {
+	affinity := payload.(*ReservationAffinity)
+	if affinity.ReservationName == "" && affinity.LabelSelector == nil {
+		return field.ErrorList{field.Invalid(fldPath, "", "at least one of reservationName and labelSelector must be specified")}
+	}
+	if _, err := metav1.LabelSelectorAsSelector(affinity.LabelSelector); err != nil {
+		return field.ErrorList{field.Invalid(fldPath.Child("labelSelector"), affinity.LabelSelector, err.Error())}
+	}
+	return nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+// AnnotationOpenAPIFileName is the file name of the OpenAPI document bundling all annotation schemas.
+const AnnotationOpenAPIFileName = "openapi.json"
+
+// MarshalAnnotationSchemas renders the published schema files, keyed by the file name. Each annotation protocol
+// has its own JSON Schema file, and the OpenAPI document references them as components.
+func MarshalAnnotationSchemas() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	components := map[string]*JSONSchema{}
+	for _, p := range GetAnnotationProtocols() {
+		schema := p.Schema()
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[annotationSchemaFileName(p.Key)] = append(data, '\n')
+
+		component := *schema
+		component.Schema, component.ID = "", ""
+		components[p.Key] = &component
+	}
+
+	document := map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]string{
+			"title":   "Koordinator Annotation Protocols",
+			"version": "v1",
+		},
+		"paths": map[string]interface{}{},
+		"components": map[string]interface{}{
+			"schemas": components,
+		},
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[AnnotationOpenAPIFileName] = append(data, '\n')
+	return files, nil
+}
+
+func annotationSchemaFileName(key string) string {
+	return strings.Replace(key, "/", "_", -1) + ".schema.json"
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantErrs    []string
+	}{
+		{
+			name: "annotations without protocols",
+			annotations: map[string]string{
+				"foo":                  "bar",
+				AnnotationGangMinNum:   "2",
+				AnnotationEvictionCost: "100",
+			},
+		},
+		{
+			name: "valid protocols",
+			annotations: map[string]string{
+				AnnotationResourceSpec:        `{"preferredCPUBindPolicy":"FullPCPUs","preferredCPUExclusivePolicy":"PCPULevel"}`,
+				AnnotationResourceStatus:      `{"cpuset":"0-3,8","cpuSharedPools":[{"socket":0,"node":0,"cpuset":"4-7"}]}`,
+				AnnotationDeviceAllocated:     `{"gpu":[{"minor":0,"resources":{"koordinator.sh/gpu-core":100,"koordinator.sh/gpu-memory":"16Gi"},"extension":{"gpuVendor":"nvidia"}}]}`,
+				AnnotationPodCPUBurst:         `{"policy":"auto","cpuBurstPercent":1000}`,
+				AnnotationPodMemoryQoS:        `{"policy":"auto","minLimitPercent":100,"wmarkRatio":95}`,
+				AnnotationNUMAAntiAffinity:    `{"labelSelector":{"matchLabels":{"app":"producer"}}}`,
+				AnnotationReservationAffinity: `{"reservationName":"r1"}`,
+			},
+		},
+		{
+			name: "malformed json",
+			annotations: map[string]string{
+				AnnotationResourceStatus: `{"cpuset":`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[scheduling.koordinator.sh/resource-status]: Invalid value: "{\"cpuset\":": unexpected EOF`,
+			},
+		},
+		{
+			name: "unknown field",
+			annotations: map[string]string{
+				AnnotationResourceSpec: `{"preferredCPUBindPolicy":"FullPCPUs","foo":"bar"}`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[scheduling.koordinator.sh/resource-spec]: Invalid value: "{\"preferredCPUBindPolicy\":\"FullPCPUs\",\"foo\":\"bar\"}": json: unknown field "foo"`,
+			},
+		},
+		{
+			name: "trailing data",
+			annotations: map[string]string{
+				AnnotationReservationAllocated: `{"name":"r1"}{}`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[scheduling.koordinator.sh/reservation-allocated]: Invalid value: "{\"name\":\"r1\"}{}": unexpected data after the top-level value`,
+			},
+		},
+		{
+			name: "invalid device allocations",
+			annotations: map[string]string{
+				AnnotationDeviceAllocated: `{"tpu":[{"minor":0}],"gpu":[{"minor":1,"resources":{"koordinator.sh/gpu-core":"-1"}},{"minor":1,"extension":{"foo":1}},null]}`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[scheduling.koordinator.sh/device-allocated][gpu][0].resources[koordinator.sh/gpu-core]: Invalid value: "-1": must be greater than or equal to 0`,
+				`metadata.annotations[scheduling.koordinator.sh/device-allocated][gpu][1].extension: Invalid value: "{\"foo\":1}": json: unknown field "foo"`,
+				`metadata.annotations[scheduling.koordinator.sh/device-allocated][tpu]: Unsupported value: "tpu": supported values: "gpu", "fpga", "rdma", "nic"`,
+				`metadata.annotations[scheduling.koordinator.sh/device-allocated][gpu][1].minor: Duplicate value: 1`,
+				`metadata.annotations[scheduling.koordinator.sh/device-allocated][gpu][2]: Required value: device allocation must not be null`,
+			},
+		},
+		{
+			name: "invalid cpuset and bounds",
+			annotations: map[string]string{
+				AnnotationResourceStatus: `{"cpuset":"0-3,a"}`,
+				AnnotationPodCPUBurst:    `{"policy":"always","cpuBurstPercent":20000}`,
+				AnnotationPodMemoryQoS:   `{"wmarkMinAdj":-50}`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[koordinator.sh/cpuBurst].policy: Unsupported value: "always": supported values: "none", "cpuBurstOnly", "cfsQuotaBurstOnly", "auto"`,
+				`metadata.annotations[koordinator.sh/cpuBurst].cpuBurstPercent: Invalid value: 20000: must be less than or equal to 10000`,
+				`metadata.annotations[koordinator.sh/memoryQOS].wmarkMinAdj: Invalid value: -50: must be greater than or equal to -25`,
+				`metadata.annotations[scheduling.koordinator.sh/resource-status].cpuset: Invalid value: "0-3,a": must be a Linux CPU list, e.g. 0-3,8`,
+			},
+		},
+		{
+			name: "invalid numa anti-affinity",
+			annotations: map[string]string{
+				AnnotationNUMAAntiAffinity: `{}`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[scheduling.koordinator.sh/numa-anti-affinity]: Invalid value: "": exactly one of serviceName and labelSelector must be specified`,
+			},
+		},
+		{
+			name: "invalid reservation affinity",
+			annotations: map[string]string{
+				AnnotationReservationAffinity: `{}`,
+			},
+			wantErrs: []string{
+				`metadata.annotations[scheduling.koordinator.sh/reservation-affinity]: Invalid value: "": at least one of reservationName and labelSelector must be specified`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allErrs := ValidateAnnotations(tt.annotations, field.NewPath("metadata", "annotations"))
+			var gotErrs []string
+			for _, err := range allErrs {
+				gotErrs = append(gotErrs, err.Error())
+			}
+			assert.Equal(t, tt.wantErrs, gotErrs)
+		})
+	}
+}
+
+func TestAnnotationProtocolSchema(t *testing.T) {
+	schema := GetAnnotationProtocol(AnnotationPodMemoryQoS).Schema()
+	assert.Equal(t, JSONSchemaDraft, schema.Schema)
+	assert.Equal(t, AnnotationPodMemoryQoS, schema.ID)
+	assert.Equal(t, false, schema.AdditionalProperties)
+	// the inlined MemoryQOS fields are flattened
+	assert.Equal(t, []string{"default", "none", "auto"}, schema.Properties["policy"].Enum)
+	wmarkRatio := schema.Properties["wmarkRatio"]
+	assert.Equal(t, "integer", wmarkRatio.Type)
+	assert.Equal(t, int64(0), *wmarkRatio.Minimum)
+	assert.Equal(t, int64(100), *wmarkRatio.Maximum)
+
+	schema = GetAnnotationProtocol(AnnotationDeviceAllocated).Schema()
+	assert.Equal(t, []string{"gpu", "fpga", "rdma", "nic"}, schema.PropertyNames.Enum)
+	allocation := schema.AdditionalProperties.(*JSONSchema).Items
+	assert.Equal(t, "string", allocation.Properties["extension"].Properties["gpuVendor"].Type)
+	assert.True(t, allocation.Properties["resources"].AdditionalProperties.(*JSONSchema).XIntOrString)
+
+	assert.Nil(t, GetAnnotationProtocol(AnnotationGangName))
+}
+
+func TestPublishedAnnotationSchemasUpToDate(t *testing.T) {
+	files, err := MarshalAnnotationSchemas()
+	assert.NoError(t, err)
+	var document map[string]interface{}
+	assert.NoError(t, json.Unmarshal(files[AnnotationOpenAPIFileName], &document))
+	assert.Len(t, document["components"].(map[string]interface{})["schemas"], len(annotationProtocols))
+
+	published, err := filepath.Glob(filepath.Join("schemas", "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, published, len(files))
+	for name, data := range files {
+		got, err := os.ReadFile(filepath.Join("schemas", name))
+		assert.NoError(t, err)
+		assert.Equal(t, string(data), string(got), "schemas/%s is out of date, please run `make manifests`", name)
+	}
+}
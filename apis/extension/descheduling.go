@@ -17,8 +17,11 @@ limitations under the License.
 package extension
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -32,6 +35,33 @@ const (
 	// If a batch of Pods to be evicted have the same priority, they will be sorted by cost,
 	// and the Pod with the smallest cost will be evicted.
 	AnnotationEvictionCost = SchedulingDomainPrefix + "/eviction-cost"
+
+	// AnnotationEvictionInitiator is set on the Eviction objects created by koordinator components
+	// to indicate the initiator, so that the evictions can be limited by the ClusterEvictionBudget.
+	AnnotationEvictionInitiator = DomainPrefix + "eviction-initiator"
+
+	// AnnotationCheckpointEnabled opts the pod in to be checkpointed before it is evicted by a PodMigrationJob which
+	// requests the checkpoint, so that its containers can be restored from the checkpoint archives instead of being
+	// killed and recreated. It is usually set in the pod template of the workload with the value "true".
+	AnnotationCheckpointEnabled = SchedulingDomainPrefix + "/checkpoint-enabled"
+
+	// AnnotationMigrationCheckpoint is set on the PodMigrationJob with the value "true" to request checkpointing the
+	// opted-in pod before evicting it.
+	AnnotationMigrationCheckpoint = SchedulingDomainPrefix + "/migration-checkpoint"
+
+	// AnnotationCheckpointRequest is set on the pod by koord-descheduler to request koordlet to checkpoint the
+	// containers of the pod. The value identifies the request, i.e. the name of the PodMigrationJob.
+	AnnotationCheckpointRequest = SchedulingDomainPrefix + "/checkpoint-request"
+
+	// AnnotationCheckpointStatus is set on the pod by koordlet to respond to the checkpoint request.
+	// For specific value definitions, see CheckpointStatus.
+	AnnotationCheckpointStatus = SchedulingDomainPrefix + "/checkpoint-status"
+)
+
+const (
+	EvictionInitiatorKoordlet    = "koordlet"
+	EvictionInitiatorDescheduler = "koord-descheduler"
+	EvictionInitiatorScheduler   = "koord-scheduler"
 )
 
 func GetEvictionCost(annotations map[string]string) (int32, error) {
@@ -57,3 +87,75 @@ func validFirstDigit(str string) bool {
 	}
 	return str[0] == '-' || (str[0] == '0' && str == "0") || (str[0] >= '1' && str[0] <= '9')
 }
+
+// CheckpointPhase is the result of a checkpoint request.
+type CheckpointPhase string
+
+const (
+	// CheckpointSucceeded means all running containers of the pod are checkpointed.
+	CheckpointSucceeded CheckpointPhase = "Succeeded"
+	// CheckpointFailed means the checkpoint is attempted but failed.
+	CheckpointFailed CheckpointPhase = "Failed"
+	// CheckpointUnsupported means the node or the pod does not support the checkpoint, e.g. CRIU is not installed.
+	CheckpointUnsupported CheckpointPhase = "Unsupported"
+)
+
+// CheckpointStatus is the result of checkpointing the containers of a pod.
+type CheckpointStatus struct {
+	// Request is the checkpoint request which the status responds to.
+	Request string `json:"request,omitempty"`
+	// Phase is the result of the checkpoint request.
+	Phase CheckpointPhase `json:"phase,omitempty"`
+	// Message describes why the checkpoint failed or is unsupported.
+	Message string `json:"message,omitempty"`
+	// Containers are the checkpoints of the containers.
+	Containers []ContainerCheckpoint `json:"containers,omitempty"`
+	// FinishTime is the time when the checkpoint request is finished.
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
+}
+
+// ContainerCheckpoint is the checkpoint of a container.
+type ContainerCheckpoint struct {
+	// Name is the name of the container.
+	Name string `json:"name,omitempty"`
+	// Archive is the path of the checkpoint archive on the node.
+	Archive string `json:"archive,omitempty"`
+}
+
+// IsCheckpointEnabled returns whether the pod opts in to be checkpointed before the migration.
+func IsCheckpointEnabled(annotations map[string]string) bool {
+	return annotations[AnnotationCheckpointEnabled] == "true"
+}
+
+// IsMigrationCheckpointRequested returns whether the PodMigrationJob requests checkpointing the pod.
+func IsMigrationCheckpointRequested(annotations map[string]string) bool {
+	return annotations[AnnotationMigrationCheckpoint] == "true"
+}
+
+// GetCheckpointStatus parses the checkpoint status from annotations.
+func GetCheckpointStatus(annotations map[string]string) (*CheckpointStatus, error) {
+	data, ok := annotations[AnnotationCheckpointStatus]
+	if !ok {
+		return nil, nil
+	}
+	status := &CheckpointStatus{}
+	if err := json.Unmarshal([]byte(data), status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// SetCheckpointStatus sets the checkpoint status into the annotations of obj.
+func SetCheckpointStatus(obj metav1.Object, status *CheckpointStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationCheckpointStatus] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
@@ -19,6 +19,7 @@ package extension
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apiserver/pkg/quota/v1"
@@ -38,8 +39,195 @@ const (
 	AnnotationSharedWeight = QuotaKoordinatorPrefix + "/shared-weight"
 	AnnotationRuntime      = QuotaKoordinatorPrefix + "/runtime"
 	AnnotationRequest      = QuotaKoordinatorPrefix + "/request"
+
+	// AnnotationQuotaSchedules declares the time windows in which the min/max of the quota are overridden.
+	AnnotationQuotaSchedules = QuotaKoordinatorPrefix + "/schedules"
+	// AnnotationQuotaOriginalSpec records the min/max declared by the user while a schedule is active.
+	AnnotationQuotaOriginalSpec = QuotaKoordinatorPrefix + "/original-spec"
+	// AnnotationQuotaActiveSchedule records the name of the active schedule.
+	AnnotationQuotaActiveSchedule = QuotaKoordinatorPrefix + "/active-schedule"
+
+	// AnnotationQuotaBatchTier declares the min/max of the batch tier of the quota, i.e. the batch-cpu and batch-memory
+	// overcommitted by the batch pods, which are accounted independently of the guaranteed cpu and memory in the spec.
+	AnnotationQuotaBatchTier = QuotaKoordinatorPrefix + "/batch-tier"
+
+	// AnnotationQuotaSchedulingGated gates the scheduling of the pending pod until its quota has the headroom to
+	// admit it. It is set and removed by the scheduler for the batch pods.
+	AnnotationQuotaSchedulingGated = QuotaKoordinatorPrefix + "/scheduling-gated"
 )
 
+// ReasonQuotaSchedulingGated is the reason the scheduler refuses the pod gated by its quota.
+const ReasonQuotaSchedulingGated = "Scheduling gated until the quota has the headroom to admit the pod"
+
+// QuotaSchedule overrides the min/max of the quota during a daily time window.
+type QuotaSchedule struct {
+	Name string `json:"name,omitempty"`
+	// Start and End are in the format of HH:MM. The window crosses midnight if End is not after Start.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Weekdays limits the days on which the window starts, e.g. "Mon", "Sat". Empty means every day.
+	Weekdays []string `json:"weekdays,omitempty"`
+	// TimeZone is an IANA time zone name, defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Min and Max override the spec of the quota. The original value is kept if not specified.
+	Min corev1.ResourceList `json:"min,omitempty"`
+	Max corev1.ResourceList `json:"max,omitempty"`
+}
+
+// QuotaOriginalSpec is the min/max of the quota before any schedule was applied.
+type QuotaOriginalSpec struct {
+	Min corev1.ResourceList `json:"min,omitempty"`
+	Max corev1.ResourceList `json:"max,omitempty"`
+	// Applied is the min/max last applied by the schedule, the spec changed from it is changed by the user.
+	Applied *QuotaAppliedSpec `json:"applied,omitempty"`
+}
+
+// QuotaAppliedSpec is the min/max applied to the quota by the active schedule.
+type QuotaAppliedSpec struct {
+	Min corev1.ResourceList `json:"min,omitempty"`
+	Max corev1.ResourceList `json:"max,omitempty"`
+}
+
+// QuotaTier is the min/max of a resource tier of the quota.
+type QuotaTier struct {
+	Min corev1.ResourceList `json:"min,omitempty"`
+	Max corev1.ResourceList `json:"max,omitempty"`
+}
+
+// QuotaBatchTierResourceNames are the resources of the batch tier of the quota.
+var QuotaBatchTierResourceNames = []corev1.ResourceName{BatchCPU, BatchMemory}
+
+// GetQuotaBatchTier returns the min/max of the batch tier, which only keeps the batch-cpu and batch-memory.
+func GetQuotaBatchTier(quota *v1alpha1.ElasticQuota) (*QuotaTier, error) {
+	value, exist := quota.Annotations[AnnotationQuotaBatchTier]
+	if !exist || value == "" {
+		return nil, nil
+	}
+	tier := &QuotaTier{}
+	if err := json.Unmarshal([]byte(value), tier); err != nil {
+		return nil, err
+	}
+	tier.Min = v1.Mask(tier.Min, QuotaBatchTierResourceNames)
+	tier.Max = v1.Mask(tier.Max, QuotaBatchTierResourceNames)
+	return tier, nil
+}
+
+func GetQuotaSchedules(quota *v1alpha1.ElasticQuota) ([]QuotaSchedule, error) {
+	value, exist := quota.Annotations[AnnotationQuotaSchedules]
+	if !exist || value == "" {
+		return nil, nil
+	}
+	var schedules []QuotaSchedule
+	if err := json.Unmarshal([]byte(value), &schedules); err != nil {
+		return nil, err
+	}
+	for i := range schedules {
+		if _, _, err := schedules[i].window(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return schedules, nil
+}
+
+func GetQuotaOriginalSpec(quota *v1alpha1.ElasticQuota) (*QuotaOriginalSpec, error) {
+	value, exist := quota.Annotations[AnnotationQuotaOriginalSpec]
+	if !exist || value == "" {
+		return nil, nil
+	}
+	originalSpec := &QuotaOriginalSpec{}
+	if err := json.Unmarshal([]byte(value), originalSpec); err != nil {
+		return nil, err
+	}
+	return originalSpec, nil
+}
+
+// GetActiveQuotaSchedule returns the first schedule whose window contains now.
+func GetActiveQuotaSchedule(schedules []QuotaSchedule, now time.Time) *QuotaSchedule {
+	for i := range schedules {
+		if schedules[i].IsActive(now) {
+			return &schedules[i]
+		}
+	}
+	return nil
+}
+
+// IsActive checks whether now is in the window started today or yesterday.
+func (s *QuotaSchedule) IsActive(now time.Time) bool {
+	for _, day := range []time.Time{now, now.AddDate(0, 0, -1)} {
+		start, end, err := s.window(day)
+		if err != nil {
+			return false
+		}
+		if !s.startsOn(start.Weekday()) {
+			continue
+		}
+		if !now.Before(start) && now.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the window which starts on the day of t in the time zone of the schedule.
+func (s *QuotaSchedule) window(t time.Time) (time.Time, time.Time, error) {
+	location := time.UTC
+	if s.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(s.TimeZone)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid timeZone of quota schedule %q, err: %v", s.Name, err)
+		}
+	}
+	startClock, err := time.Parse("15:04", s.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start of quota schedule %q, err: %v", s.Name, err)
+	}
+	endClock, err := time.Parse("15:04", s.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end of quota schedule %q, err: %v", s.Name, err)
+	}
+	for _, weekday := range s.Weekdays {
+		if _, ok := weekdays[weekday]; !ok {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid weekday %q of quota schedule %q", weekday, s.Name)
+		}
+	}
+
+	t = t.In(location)
+	start := time.Date(t.Year(), t.Month(), t.Day(), startClock.Hour(), startClock.Minute(), 0, 0, location)
+	end := time.Date(t.Year(), t.Month(), t.Day(), endClock.Hour(), endClock.Minute(), 0, 0, location)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+func (s *QuotaSchedule) startsOn(weekday time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, v := range s.Weekdays {
+		if weekdays[v] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// IsPodQuotaSchedulingGated checks if the scheduling of the pod is gated by its quota.
+func IsPodQuotaSchedulingGated(pod *corev1.Pod) bool {
+	return pod != nil && pod.Annotations[AnnotationQuotaSchedulingGated] == "true"
+}
+
 func GetParentQuotaName(quota *v1alpha1.ElasticQuota) string {
 	parentName := quota.Labels[LabelQuotaParent]
 	if parentName == "" {
@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+)
+
+func TestGetQuotaSchedules(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		wantLen    int
+		wantErr    bool
+	}{
+		{
+			name: "no schedules",
+		},
+		{
+			name:       "valid schedules",
+			annotation: `[{"name":"night","start":"22:00","end":"06:00","timeZone":"Asia/Shanghai"},{"name":"weekend","start":"00:00","end":"00:00","weekdays":["Sat","Sun"]}]`,
+			wantLen:    2,
+		},
+		{
+			name:       "invalid start",
+			annotation: `[{"name":"night","start":"25:00","end":"06:00"}]`,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid weekday",
+			annotation: `[{"name":"night","start":"22:00","end":"06:00","weekdays":["Someday"]}]`,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid timeZone",
+			annotation: `[{"name":"night","start":"22:00","end":"06:00","timeZone":"Nowhere/City"}]`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := &v1alpha1.ElasticQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{},
+				},
+			}
+			if tt.annotation != "" {
+				quota.Annotations[AnnotationQuotaSchedules] = tt.annotation
+			}
+			got, err := GetQuotaSchedules(quota)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Len(t, got, tt.wantLen)
+		})
+	}
+}
+
+func TestQuotaScheduleIsActive(t *testing.T) {
+	// 2022-11-04 is a Friday
+	friday := func(hour, min int) time.Time {
+		return time.Date(2022, 11, 4, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		schedule QuotaSchedule
+		now      time.Time
+		want     bool
+	}{
+		{
+			name:     "in daytime window",
+			schedule: QuotaSchedule{Start: "09:00", End: "18:00"},
+			now:      friday(12, 0),
+			want:     true,
+		},
+		{
+			name:     "end of window is excluded",
+			schedule: QuotaSchedule{Start: "09:00", End: "18:00"},
+			now:      friday(18, 0),
+			want:     false,
+		},
+		{
+			name:     "window crossing midnight, before midnight",
+			schedule: QuotaSchedule{Start: "22:00", End: "06:00"},
+			now:      friday(23, 0),
+			want:     true,
+		},
+		{
+			name:     "window crossing midnight, after midnight",
+			schedule: QuotaSchedule{Start: "22:00", End: "06:00"},
+			now:      friday(5, 59),
+			want:     true,
+		},
+		{
+			name:     "window crossing midnight, outside",
+			schedule: QuotaSchedule{Start: "22:00", End: "06:00"},
+			now:      friday(12, 0),
+			want:     false,
+		},
+		{
+			name:     "window started on the allowed weekday",
+			schedule: QuotaSchedule{Start: "22:00", End: "06:00", Weekdays: []string{"Thu"}},
+			now:      friday(1, 0),
+			want:     true,
+		},
+		{
+			name:     "window started on other weekday",
+			schedule: QuotaSchedule{Start: "22:00", End: "06:00", Weekdays: []string{"Fri"}},
+			now:      friday(1, 0),
+			want:     false,
+		},
+		{
+			name:     "whole day window",
+			schedule: QuotaSchedule{Start: "00:00", End: "00:00", Weekdays: []string{"Fri"}},
+			now:      friday(23, 59),
+			want:     true,
+		},
+		{
+			name:     "window in time zone",
+			schedule: QuotaSchedule{Start: "09:00", End: "18:00", TimeZone: "Asia/Shanghai"},
+			now:      friday(2, 0),
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.schedule.IsActive(tt.now))
+		})
+	}
+}
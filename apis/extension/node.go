@@ -18,6 +18,8 @@ package extension
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -30,6 +32,13 @@ const (
 	// AnnotationNodeCPUSharedPools describes the CPU Shared Pool defined by Koordinator.
 	// The shared pool is mainly used by Koordinator LS Pods or K8s Burstable Pods.
 	AnnotationNodeCPUSharedPools = NodeDomainPrefix + "/cpu-shared-pools"
+	// AnnotationNodeGPUCoreOversellPercent amplifies the koordinator.sh/gpu-core capacity of each GPU on the node,
+	// e.g. "150" means each GPU can be allocated up to 150 gpu-core. gpu-memory is never oversold.
+	AnnotationNodeGPUCoreOversellPercent = NodeDomainPrefix + "/gpu-core-oversell-percent"
+	// AnnotationNodeSchedulingPaused pauses scheduling new pods to the node by the koordinator plugins if set to "true",
+	// e.g. during the maintenance of the colocation components. Unlike cordon, it does not affect other schedulers
+	// and the node status, and the scheduling resumes once it is removed.
+	AnnotationNodeSchedulingPaused = NodeDomainPrefix + "/scheduling-paused"
 
 	// LabelNodeCPUBindPolicy constrains how to bind CPU logical CPUs when scheduling.
 	LabelNodeCPUBindPolicy = NodeDomainPrefix + "/cpu-bind-policy"
@@ -153,3 +162,25 @@ func GetNodeCPUBindPolicy(nodeLabels map[string]string, kubeletCPUPolicy *Kubele
 	}
 	return NodeCPUBindPolicyNone
 }
+
+// GetNodeGPUCoreOversellPercent returns the gpu-core oversell percent of the node and whether it is set.
+func GetNodeGPUCoreOversellPercent(annotations map[string]string) (int64, bool, error) {
+	data, ok := annotations[AnnotationNodeGPUCoreOversellPercent]
+	if !ok {
+		return 0, false, nil
+	}
+	percent, err := strconv.ParseInt(data, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	if percent < 100 {
+		return 0, false, fmt.Errorf("gpu-core oversell percent should not be less than 100, got %v", percent)
+	}
+	return percent, true, nil
+}
+
+// IsNodeSchedulingPaused checks if the scheduling of the node is paused by the annotation.
+func IsNodeSchedulingPaused(annotations map[string]string) bool {
+	paused, err := strconv.ParseBool(annotations[AnnotationNodeSchedulingPaused])
+	return err == nil && paused
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"encoding/json"
+	"fmt"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+const (
+	// PodMetricExtensionKeyPSI is the key of the PodPSI in the extensions of the NodeMetric pod entries.
+	PodMetricExtensionKeyPSI = "psi"
+)
+
+// PodPSI is the pressure stall information of a pod averaged over the NodeMetric aggregate duration. Each value is the
+// percentage of the time in which some (or all) tasks of the pod are stalled on the resource, so it tells the pods
+// suffering contention from the pods simply busy.
+type PodPSI struct {
+	SomeCPU    float64 `json:"someCPU"`
+	SomeMemory float64 `json:"someMemory"`
+	SomeIO     float64 `json:"someIO"`
+	// FullCPU is only reported by the kernels supporting the cpu full pressure (5.13+).
+	FullCPU    float64 `json:"fullCPU,omitempty"`
+	FullMemory float64 `json:"fullMemory"`
+	FullIO     float64 `json:"fullIO"`
+}
+
+// GetPodPSI returns the PodPSI in the extensions of the pod metric, or nil if not reported.
+func GetPodPSI(podMetric *slov1alpha1.PodMetricInfo) (*PodPSI, error) {
+	if podMetric == nil || podMetric.Extensions == nil || podMetric.Extensions.Object == nil {
+		return nil, nil
+	}
+	value, ok := podMetric.Extensions.Object[PodMetricExtensionKeyPSI]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	// the value is a generic map when decoded from the api server, so convert it through json
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	psi := &PodPSI{}
+	if err := json.Unmarshal(data, psi); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pod psi, err: %w", err)
+	}
+	return psi, nil
+}
+
+// SetPodPSI sets the PodPSI into the extensions of the pod metric.
+func SetPodPSI(podMetric *slov1alpha1.PodMetricInfo, psi *PodPSI) {
+	if podMetric.Extensions == nil {
+		podMetric.Extensions = &slov1alpha1.ExtensionsMap{}
+	}
+	if podMetric.Extensions.Object == nil {
+		podMetric.Extensions.Object = map[string]interface{}{}
+	}
+	podMetric.Extensions.Object[PodMetricExtensionKeyPSI] = psi
+}
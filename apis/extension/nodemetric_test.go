@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extension
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestGetPodPSI(t *testing.T) {
+	psi := &PodPSI{
+		SomeCPU:    12.5,
+		SomeMemory: 1.5,
+		SomeIO:     0.5,
+		FullMemory: 0.25,
+	}
+
+	got, err := GetPodPSI(&slov1alpha1.PodMetricInfo{})
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	podMetric := &slov1alpha1.PodMetricInfo{Name: "test-pod", Namespace: "default"}
+	SetPodPSI(podMetric, psi)
+	got, err = GetPodPSI(podMetric)
+	assert.NoError(t, err)
+	assert.Equal(t, psi, got)
+
+	// the extensions decoded from the api server
+	data, err := json.Marshal(podMetric)
+	assert.NoError(t, err)
+	decoded := &slov1alpha1.PodMetricInfo{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	got, err = GetPodPSI(decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, psi, got)
+
+	decoded.Extensions.Object[PodMetricExtensionKeyPSI] = "invalid"
+	got, err = GetPodPSI(decoded)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
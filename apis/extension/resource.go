@@ -20,24 +20,59 @@ import (
 	"encoding/json"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	BatchCPU    corev1.ResourceName = ResourceDomainPrefix + "batch-cpu"
 	BatchMemory corev1.ResourceName = ResourceDomainPrefix + "batch-memory"
+	// BatchGPUCore and BatchGPUMemory are the idle GPU capacity reclaimed from the LS pods, which are in the same units
+	// as the koordinator.sh/gpu-core and koordinator.sh/gpu-memory.
+	BatchGPUCore   corev1.ResourceName = ResourceDomainPrefix + "batch-gpu-core"
+	BatchGPUMemory corev1.ResourceName = ResourceDomainPrefix + "batch-gpu-memory"
 
 	ResourceNvidiaGPU      corev1.ResourceName = "nvidia.com/gpu"
+	ResourceAMDGPU         corev1.ResourceName = "amd.com/gpu"
 	ResourceRDMA           corev1.ResourceName = DomainPrefix + "rdma"
+	ResourceRDMAVF         corev1.ResourceName = DomainPrefix + "rdma-vf"
 	ResourceFPGA           corev1.ResourceName = DomainPrefix + "fpga"
+	ResourceNetBandwidth   corev1.ResourceName = DomainPrefix + "net-bandwidth"
 	ResourceGPU            corev1.ResourceName = DomainPrefix + "gpu"
 	ResourceGPUCore        corev1.ResourceName = DomainPrefix + "gpu-core"
 	ResourceGPUMemory      corev1.ResourceName = DomainPrefix + "gpu-memory"
 	ResourceGPUMemoryRatio corev1.ResourceName = DomainPrefix + "gpu-memory-ratio"
+
+	// ResourceDiskIOThroughput and ResourceDiskIOPS are the disk IO usages reported in the NodeMetric, which are the
+	// total of the reads and the writes in bytes and in operations per second.
+	ResourceDiskIOThroughput corev1.ResourceName = DomainPrefix + "disk-io-throughput"
+	ResourceDiskIOPS         corev1.ResourceName = DomainPrefix + "disk-iops"
 )
 
 const (
 	LabelGPUModel         string = NodeDomainPrefix + "/gpu-model"
 	LabelGPUDriverVersion string = NodeDomainPrefix + "/gpu-driver-version"
+	// LabelGPUVendor is the vendor of the GPUs on the node, e.g. nvidia, amd. Empty means nvidia.
+	LabelGPUVendor string = NodeDomainPrefix + "/gpu-vendor"
+)
+
+const (
+	GPUVendorNVIDIA = "nvidia"
+	GPUVendorAMD    = "amd"
+)
+
+const (
+	// LabelGPUIsolationProvider specifies the provider to isolate the memory and core of the shared GPUs for the pod,
+	// e.g. MPS, cGPU. The memory and core of the shared GPUs are not isolated if it is not specified.
+	LabelGPUIsolationProvider = DomainPrefix + "gpu-isolation-provider"
+)
+
+type GPUIsolationProvider string
+
+const (
+	// GPUIsolationProviderMPS isolates the shared GPUs by the limits of NVIDIA Multi-Process Service.
+	GPUIsolationProviderMPS GPUIsolationProvider = "MPS"
+	// GPUIsolationProviderCGPU isolates the shared GPUs by cGPU.
+	GPUIsolationProviderCGPU GPUIsolationProvider = "cGPU"
 )
 
 const (
@@ -52,6 +87,10 @@ const (
 	// It annotates the requests/limits of extended resources and can be used by runtime proxy and koordlet that
 	// cannot get the original pod spec in CRI requests.
 	AnnotationExtendedResourceSpec = NodeDomainPrefix + "/extended-resource-spec"
+
+	// AnnotationNUMAAntiAffinity represents the pods which the pod should be co-located with on the same node
+	// but not share any NUMA Node with. For specific value definitions, see NUMAAntiAffinity.
+	AnnotationNUMAAntiAffinity = SchedulingDomainPrefix + "/numa-anti-affinity"
 )
 
 var (
@@ -78,6 +117,9 @@ type ResourceStatus struct {
 	CPUSet string `json:"cpuset,omitempty"`
 	// CPUSharedPools represents the desired CPU Shared Pools used by LS Pods.
 	CPUSharedPools []CPUSharedPool `json:"cpuSharedPools,omitempty"`
+	// NUMANodeResources represents the resources allocated on the NUMA Nodes, e.g. the hugepages.
+	// koordlet binds the memory of the Pod to the NUMA Nodes accordingly.
+	NUMANodeResources []NUMANodeResource `json:"numaNodeResources,omitempty"`
 }
 
 // CPUBindPolicy defines the CPU binding policy
@@ -125,6 +167,36 @@ type CPUSharedPool struct {
 	CPUSet string `json:"cpuset,omitempty"`
 }
 
+// NUMANodeResource describes the resources allocated on a NUMA Node.
+type NUMANodeResource struct {
+	Node      int32               `json:"node"`
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// NUMAAntiAffinity references the pods in the same namespace by a Service or a LabelSelector.
+// The pod must be scheduled to a node running the referenced pods, and its CPUs are allocated
+// from the NUMA Nodes not used by the referenced pods, e.g. paired producer/consumer processes
+// avoiding mutual interference. Only one of ServiceName and LabelSelector can be specified.
+type NUMAAntiAffinity struct {
+	// ServiceName references the pods selected by the Service.
+	ServiceName string `json:"serviceName,omitempty"`
+	// LabelSelector references the pods matching the selector.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// GetNUMAAntiAffinity parses NUMAAntiAffinity from annotations
+func GetNUMAAntiAffinity(annotations map[string]string) (*NUMAAntiAffinity, error) {
+	data, ok := annotations[AnnotationNUMAAntiAffinity]
+	if !ok {
+		return nil, nil
+	}
+	antiAffinity := &NUMAAntiAffinity{}
+	if err := json.Unmarshal([]byte(data), antiAffinity); err != nil {
+		return nil, err
+	}
+	return antiAffinity, nil
+}
+
 // GetResourceSpec parses ResourceSpec from annotations
 func GetResourceSpec(annotations map[string]string) (*ResourceSpec, error) {
 	resourceSpec := &ResourceSpec{
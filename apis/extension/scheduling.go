@@ -18,10 +18,14 @@ package extension
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
@@ -36,6 +40,40 @@ const (
 
 	// AnnotationReservationAllocated represents the reservation allocated by the pod.
 	AnnotationReservationAllocated = SchedulingDomainPrefix + "/reservation-allocated"
+
+	// AnnotationReservationAffinity represents the constraints of the reservations that the pod must allocate from.
+	// The pod fails to schedule instead of using the free node resources if no reservation matches.
+	// For specific value definitions, see ReservationAffinity.
+	AnnotationReservationAffinity = SchedulingDomainPrefix + "/reservation-affinity"
+
+	// AnnotationReservationTolerations represents the tolerations of the pod to the taints of the capacity-only
+	// reservations. Only the pods with the annotation can allocate the capacity-only reservations.
+	// The value is a JSON array of corev1.Toleration, e.g. [{"key": "pool", "operator": "Equal", "value": "gpu"}].
+	AnnotationReservationTolerations = SchedulingDomainPrefix + "/reservation-tolerations"
+
+	// AnnotationReservationRenewTime is the RFC3339 time when the reservation is renewed last time. The TTL of a
+	// renewed reservation counts from the renew time instead of the creation time, so the owners can keep using the
+	// reservation by refreshing the annotation periodically.
+	AnnotationReservationRenewTime = SchedulingDomainPrefix + "/reservation-renew-time"
+
+	// AnnotationProactiveReservation enables the proactive reservations of a Deployment or StatefulSet when set to
+	// "true". koord-manager keeps the Reservations sized to the surge of the next rollout for the workload.
+	AnnotationProactiveReservation = SchedulingDomainPrefix + "/proactive-reservation"
+	// LabelProactiveReservationWorkloadKind is the kind of the workload which the proactive reservation is created for.
+	LabelProactiveReservationWorkloadKind = SchedulingDomainPrefix + "/proactive-reservation-workload-kind"
+	// LabelProactiveReservationWorkloadUID is the UID of the workload which the proactive reservation is created for.
+	LabelProactiveReservationWorkloadUID = SchedulingDomainPrefix + "/proactive-reservation-workload-uid"
+	// LabelProactiveReservationTemplateHash is the hash of the workload pod template when the proactive reservation
+	// is created.
+	LabelProactiveReservationTemplateHash = SchedulingDomainPrefix + "/proactive-reservation-template-hash"
+	// AnnotationProactiveReservationWorkload is the namespaced name of the workload which the proactive reservation
+	// is created for, e.g. default/nginx.
+	AnnotationProactiveReservationWorkload = SchedulingDomainPrefix + "/proactive-reservation-workload"
+
+	// AnnotationReservedForMigration is set on the Reservation created by koord-descheduler for a PodMigrationJob. The
+	// reservation is held for the replacement of the migrating pod, so it cannot be allocated by other pods until the
+	// migrating pod is evicted. For specific value definitions, see ReservedForMigration.
+	AnnotationReservedForMigration = SchedulingDomainPrefix + "/reserved-for-migration"
 )
 
 const (
@@ -45,6 +83,42 @@ const (
 
 	// AnnotationDeviceAllocated represents the device allocated by the pod
 	AnnotationDeviceAllocated = SchedulingDomainPrefix + "/device-allocated"
+
+	// AnnotationDeviceAllocationRef represents the name of the PodDeviceAllocation in the namespace of the pod,
+	// which stores the devices allocated by the pod instead of the annotation scheduling.koordinator.sh/device-allocated.
+	AnnotationDeviceAllocationRef = SchedulingDomainPrefix + "/device-allocation-ref"
+
+	// AnnotationDRADeviceResources represents the device resources requested by a DRA ResourceClaim.
+	// It is set on the ResourceClass and can be overridden by the ResourceClaim,
+	// e.g. {"koordinator.sh/gpu-core": "50", "koordinator.sh/gpu-memory-ratio": "50"}
+	// The scheduler records the total device resources of the claims on the pod scheduled with them.
+	AnnotationDRADeviceResources = SchedulingDomainPrefix + "/dra-device-resources"
+
+	// AnnotationGPUCompaction marks the pods migrated by the descheduler to compact the fragmented GPUs,
+	// the scheduler prefers the partially allocated GPUs for them.
+	AnnotationGPUCompaction = SchedulingDomainPrefix + "/gpu-compaction"
+
+	// AnnotationGPUCompactionSource represents the GPU released by the pod migrated to compact the fragmented GPUs,
+	// the scheduler never allocates the GPU to the pod again. For specific value definitions, see GPUCompactionSource.
+	AnnotationGPUCompactionSource = SchedulingDomainPrefix + "/gpu-compaction-source"
+
+	// AnnotationPreferredNodes represents the nodes evaluated by the descheduler as the preferred targets of a
+	// rebalanced pod. The scheduler prefers these nodes for the pod and the following pods of its controller until
+	// the hint expires. For specific value definitions, see PreferredNodes.
+	AnnotationPreferredNodes = SchedulingDomainPrefix + "/preferred-nodes"
+
+	// AnnotationGPUIsolation specifies how the GPUs allocated to the pod are isolated from other pods.
+	AnnotationGPUIsolation = DomainPrefix + "gpu-isolation"
+)
+
+// GPUIsolation is the value of AnnotationGPUIsolation.
+type GPUIsolation string
+
+const (
+	// GPUIsolationExclusive means the pod is only allocated the GPUs without any allocation,
+	// and the GPUs allocated to the pod are not shared with other pods even if they are partially allocated,
+	// e.g. the latency-sensitive inference services.
+	GPUIsolationExclusive GPUIsolation = "exclusive"
 )
 
 const (
@@ -74,6 +148,11 @@ const (
 	// The annotation is added by the scheduler when the gang times out
 	AnnotationGangTimeout = AnnotationGangPrefix + "/timeout"
 
+	// AnnotationGangGPUTopologyKey specifies the node label key (e.g. topology.kubernetes.io/zone) that divides nodes
+	// into topology domains. If set, the scheduler checks whether the aggregated GPU requests of the pending gang
+	// children fit in the free GPU capacity of a single domain before placing any of them.
+	AnnotationGangGPUTopologyKey = AnnotationGangPrefix + "/gpu-topology-key"
+
 	GangModeStrict    = "Strict"
 	GangModeNonStrict = "NonStrict"
 )
@@ -162,6 +241,58 @@ func RemoveReservationAllocated(pod *corev1.Pod, r *schedulingv1alpha1.Reservati
 	return false, nil
 }
 
+// ReservationAffinity requires the pod to allocate from the reservations which match the name and the label
+// selector. The reservations should still match the pod with their owner specs.
+type ReservationAffinity struct {
+	// ReservationName is the name of the reservation to allocate from.
+	ReservationName string `json:"reservationName,omitempty"`
+	// LabelSelector selects the reservations to allocate from by their labels.
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+func GetReservationAffinity(annotations map[string]string) (*ReservationAffinity, error) {
+	data, ok := annotations[AnnotationReservationAffinity]
+	if !ok {
+		return nil, nil
+	}
+	affinity := &ReservationAffinity{}
+	if err := json.Unmarshal([]byte(data), affinity); err != nil {
+		return nil, err
+	}
+	return affinity, nil
+}
+
+// GetReservationTolerations returns the tolerations of the pod to the capacity-only reservations. It returns nil
+// if the pod has no tolerations annotation, and a non-nil empty slice if the annotation is an empty array.
+func GetReservationTolerations(annotations map[string]string) ([]corev1.Toleration, error) {
+	data, ok := annotations[AnnotationReservationTolerations]
+	if !ok {
+		return nil, nil
+	}
+	tolerations := []corev1.Toleration{}
+	if err := json.Unmarshal([]byte(data), &tolerations); err != nil {
+		return nil, err
+	}
+	if tolerations == nil {
+		tolerations = []corev1.Toleration{}
+	}
+	return tolerations, nil
+}
+
+// GetReservationRenewTime returns the time when the reservation is renewed last time. It returns nil if the
+// reservation is never renewed.
+func GetReservationRenewTime(annotations map[string]string) (*metav1.Time, error) {
+	data, ok := annotations[AnnotationReservationRenewTime]
+	if !ok {
+		return nil, nil
+	}
+	renewTime, err := time.Parse(time.RFC3339, data)
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.Time{Time: renewTime}, nil
+}
+
 // DeviceAllocations would be injected into Pod as form of annotation during Pre-bind stage.
 /*
 {
@@ -193,6 +324,26 @@ type DeviceAllocation struct {
 	Extension json.RawMessage     `json:"extension,omitempty"`
 }
 
+// DeviceAllocationExtension is the extension of DeviceAllocation,
+// e.g. the SR-IOV virtual functions bound to the pod from the allocated RDMA device.
+type DeviceAllocationExtension struct {
+	VirtualFunctions []schedulingv1alpha1.VirtualFunction `json:"vfs,omitempty"`
+	// GPUVendor is the vendor of the allocated GPU, which is used by the runtime hooks to expose the GPU
+	// to the container in the vendor-specific way, e.g. AMD_VISIBLE_DEVICES for AMD GPUs.
+	GPUVendor string `json:"gpuVendor,omitempty"`
+}
+
+func GetDeviceAllocationExtension(allocation *DeviceAllocation) (*DeviceAllocationExtension, error) {
+	if allocation == nil || len(allocation.Extension) == 0 {
+		return nil, nil
+	}
+	extension := &DeviceAllocationExtension{}
+	if err := json.Unmarshal(allocation.Extension, extension); err != nil {
+		return nil, err
+	}
+	return extension, nil
+}
+
 var GetDeviceAllocations = func(podAnnotations map[string]string) (DeviceAllocations, error) {
 	deviceAllocations := DeviceAllocations{}
 	data, ok := podAnnotations[AnnotationDeviceAllocated]
@@ -220,6 +371,261 @@ func SetDeviceAllocations(pod *corev1.Pod, allocations DeviceAllocations) error
 	return nil
 }
 
+// GetDeviceAllocationRef returns the name of the PodDeviceAllocation referenced by the pod, or empty if
+// the devices allocated by the pod are recorded in the annotations.
+func GetDeviceAllocationRef(podAnnotations map[string]string) string {
+	return podAnnotations[AnnotationDeviceAllocationRef]
+}
+
+// SetDeviceAllocationRef makes the pod reference the PodDeviceAllocation storing its devices, which replaces
+// the annotation scheduling.koordinator.sh/device-allocated.
+func SetDeviceAllocationRef(pod *corev1.Pod, name string) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	delete(pod.Annotations, AnnotationDeviceAllocated)
+	pod.Annotations[AnnotationDeviceAllocationRef] = name
+}
+
+// CountDeviceAllocations returns the number of devices in the allocations.
+func CountDeviceAllocations(allocations DeviceAllocations) int {
+	count := 0
+	for _, deviceAllocations := range allocations {
+		count += len(deviceAllocations)
+	}
+	return count
+}
+
+// NewPodDeviceAllocation returns the PodDeviceAllocation storing the devices allocated by the pod on the node.
+// It shares the name and namespace with the pod and is owned by the pod, so it is garbage collected with the pod.
+func NewPodDeviceAllocation(pod *corev1.Pod, nodeName string, allocations DeviceAllocations) *schedulingv1alpha1.PodDeviceAllocation {
+	deviceTypes := make([]string, 0, len(allocations))
+	for deviceType := range allocations {
+		deviceTypes = append(deviceTypes, string(deviceType))
+	}
+	sort.Strings(deviceTypes)
+
+	var devices []schedulingv1alpha1.PodDeviceAllocationItem
+	for _, deviceType := range deviceTypes {
+		for _, allocation := range allocations[schedulingv1alpha1.DeviceType(deviceType)] {
+			if allocation == nil {
+				continue
+			}
+			item := schedulingv1alpha1.PodDeviceAllocationItem{
+				Type:      schedulingv1alpha1.DeviceType(deviceType),
+				Minor:     allocation.Minor,
+				Resources: allocation.Resources.DeepCopy(),
+			}
+			if len(allocation.Extension) > 0 {
+				item.Extension = &runtime.RawExtension{Raw: append([]byte(nil), allocation.Extension...)}
+			}
+			devices = append(devices, item)
+		}
+	}
+
+	return &schedulingv1alpha1.PodDeviceAllocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "Pod",
+					Name:       pod.Name,
+					UID:        pod.UID,
+				},
+			},
+		},
+		Spec: schedulingv1alpha1.PodDeviceAllocationSpec{
+			PodUID:   pod.UID,
+			NodeName: nodeName,
+			Devices:  devices,
+		},
+	}
+}
+
+// GetDeviceAllocationsFromPodDeviceAllocation returns the devices stored in the PodDeviceAllocation.
+func GetDeviceAllocationsFromPodDeviceAllocation(podDeviceAllocation *schedulingv1alpha1.PodDeviceAllocation) DeviceAllocations {
+	if podDeviceAllocation == nil || len(podDeviceAllocation.Spec.Devices) == 0 {
+		return nil
+	}
+	allocations := DeviceAllocations{}
+	for i := range podDeviceAllocation.Spec.Devices {
+		item := &podDeviceAllocation.Spec.Devices[i]
+		allocation := &DeviceAllocation{
+			Minor:     item.Minor,
+			Resources: item.Resources.DeepCopy(),
+		}
+		if item.Extension != nil && len(item.Extension.Raw) > 0 {
+			allocation.Extension = append(json.RawMessage(nil), item.Extension.Raw...)
+		}
+		allocations[item.Type] = append(allocations[item.Type], allocation)
+	}
+	return allocations
+}
+
+// PodDeviceAllocationGetter gets the PodDeviceAllocation with the name in the namespace.
+type PodDeviceAllocationGetter func(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error)
+
+// ResolveDeviceAllocations returns the devices allocated by the pod. They are read from the PodDeviceAllocation
+// referenced by the pod if any, otherwise parsed from the annotation scheduling.koordinator.sh/device-allocated.
+func ResolveDeviceAllocations(namespace string, podUID types.UID, podAnnotations map[string]string, getter PodDeviceAllocationGetter) (DeviceAllocations, error) {
+	name := GetDeviceAllocationRef(podAnnotations)
+	if name == "" {
+		return GetDeviceAllocations(podAnnotations)
+	}
+	if getter == nil {
+		return nil, fmt.Errorf("failed to resolve PodDeviceAllocation %s/%s, no getter", namespace, name)
+	}
+	podDeviceAllocation, err := getter(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if podUID != "" && podDeviceAllocation.Spec.PodUID != podUID {
+		return nil, fmt.Errorf("PodDeviceAllocation %s/%s belongs to pod %s", namespace, name, podDeviceAllocation.Spec.PodUID)
+	}
+	return GetDeviceAllocationsFromPodDeviceAllocation(podDeviceAllocation), nil
+}
+
+// GetDRADeviceResources parses the device resources of a DRA ResourceClaim or ResourceClass from annotations.
+func GetDRADeviceResources(annotations map[string]string) (corev1.ResourceList, error) {
+	data, ok := annotations[AnnotationDRADeviceResources]
+	if !ok {
+		return nil, nil
+	}
+	resources := corev1.ResourceList{}
+	if err := json.Unmarshal([]byte(data), &resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// SetDRADeviceResources records the device resources translated from the DRA ResourceClaims on the pod,
+// so that they are accounted along with the requests of the pod after it is scheduled.
+func SetDRADeviceResources(pod *corev1.Pod, resources corev1.ResourceList) error {
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return err
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[AnnotationDRADeviceResources] = string(data)
+	return nil
+}
+
+// PreferredNodes is the scheduling hint of the preferred target nodes.
+type PreferredNodes struct {
+	// Nodes are the preferred nodes in the order of preference.
+	Nodes []string `json:"nodes,omitempty"`
+	// ExpireTime is the time after which the hint is ignored. Never expired if not set.
+	ExpireTime *metav1.Time `json:"expireTime,omitempty"`
+}
+
+// IsExpired returns whether the hint is expired at the given time.
+func (p *PreferredNodes) IsExpired(now time.Time) bool {
+	return p.ExpireTime != nil && !now.Before(p.ExpireTime.Time)
+}
+
+// GetPreferredNodes parses the preferred nodes hint from annotations.
+func GetPreferredNodes(annotations map[string]string) (*PreferredNodes, error) {
+	data, ok := annotations[AnnotationPreferredNodes]
+	if !ok {
+		return nil, nil
+	}
+	preferredNodes := &PreferredNodes{}
+	if err := json.Unmarshal([]byte(data), preferredNodes); err != nil {
+		return nil, err
+	}
+	return preferredNodes, nil
+}
+
+// SetPreferredNodes sets the preferred nodes hint into the annotations of obj.
+func SetPreferredNodes(obj metav1.Object, preferredNodes *PreferredNodes) error {
+	data, err := json.Marshal(preferredNodes)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationPreferredNodes] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// GPUCompactionSource is the GPU released by the pod migrated for the GPU compaction.
+type GPUCompactionSource struct {
+	NodeName string `json:"nodeName,omitempty"`
+	Minor    int32  `json:"minor"`
+}
+
+// GetGPUCompactionSource parses the GPU released by the GPU compaction from annotations.
+func GetGPUCompactionSource(annotations map[string]string) (*GPUCompactionSource, error) {
+	data, ok := annotations[AnnotationGPUCompactionSource]
+	if !ok {
+		return nil, nil
+	}
+	source := &GPUCompactionSource{}
+	if err := json.Unmarshal([]byte(data), source); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// SetGPUCompactionSource sets the GPU released by the GPU compaction into the annotations of obj.
+func SetGPUCompactionSource(obj metav1.Object, source *GPUCompactionSource) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationGPUCompactionSource] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// ReservedForMigration identifies the pod migrated into the reservation.
+type ReservedForMigration struct {
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	UID       types.UID `json:"uid,omitempty"`
+	// JobName is the name of the PodMigrationJob which creates the reservation.
+	JobName string `json:"jobName,omitempty"`
+}
+
+// GetReservedForMigration parses the migrating pod from the annotations of the reservation.
+func GetReservedForMigration(annotations map[string]string) (*ReservedForMigration, error) {
+	data, ok := annotations[AnnotationReservedForMigration]
+	if !ok {
+		return nil, nil
+	}
+	reservedForMigration := &ReservedForMigration{}
+	if err := json.Unmarshal([]byte(data), reservedForMigration); err != nil {
+		return nil, err
+	}
+	return reservedForMigration, nil
+}
+
+// SetReservedForMigration sets the migrating pod into the annotations of obj.
+func SetReservedForMigration(obj metav1.Object, reservedForMigration *ReservedForMigration) error {
+	data, err := json.Marshal(reservedForMigration)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationReservedForMigration] = string(data)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
 var GetMinNum = func(pod *corev1.Pod) (int, error) {
 	minRequiredNum, err := strconv.ParseInt(pod.Annotations[AnnotationGangMinNum], 10, 32)
 	if err != nil {
@@ -17,7 +17,9 @@ limitations under the License.
 package extension
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -138,3 +140,165 @@ func Test_SetDeviceAllocations(t *testing.T) {
 		})
 	}
 }
+
+func TestGetReservationTolerations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []corev1.Toleration
+		wantErr     bool
+	}{
+		{
+			name: "no annotation",
+			want: nil,
+		},
+		{
+			name: "empty tolerations",
+			annotations: map[string]string{
+				AnnotationReservationTolerations: `[]`,
+			},
+			want: []corev1.Toleration{},
+		},
+		{
+			name: "null tolerations",
+			annotations: map[string]string{
+				AnnotationReservationTolerations: `null`,
+			},
+			want: []corev1.Toleration{},
+		},
+		{
+			name: "parse tolerations",
+			annotations: map[string]string{
+				AnnotationReservationTolerations: `[{"key":"pool","operator":"Exists"}]`,
+			},
+			want: []corev1.Toleration{
+				{Key: "pool", Operator: corev1.TolerationOpExists},
+			},
+		},
+		{
+			name: "invalid tolerations",
+			annotations: map[string]string{
+				AnnotationReservationTolerations: `{}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetReservationTolerations(tt.annotations)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetReservationRenewTime(t *testing.T) {
+	got, err := GetReservationRenewTime(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = GetReservationRenewTime(map[string]string{
+		AnnotationReservationRenewTime: "2022-10-01T08:00:00Z",
+	})
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(&metav1.Time{Time: time.Date(2022, 10, 1, 8, 0, 0, 0, time.UTC)}))
+
+	_, err = GetReservationRenewTime(map[string]string{
+		AnnotationReservationRenewTime: "1h",
+	})
+	assert.Error(t, err)
+}
+
+func TestGPUCompactionSource(t *testing.T) {
+	got, err := GetGPUCompactionSource(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	pod := &corev1.Pod{}
+	source := &GPUCompactionSource{NodeName: "test-node", Minor: 1}
+	assert.NoError(t, SetGPUCompactionSource(pod, source))
+	got, err = GetGPUCompactionSource(pod.Annotations)
+	assert.NoError(t, err)
+	assert.Equal(t, source, got)
+
+	_, err = GetGPUCompactionSource(map[string]string{
+		AnnotationGPUCompactionSource: "1",
+	})
+	assert.Error(t, err)
+}
+
+func Test_PodDeviceAllocation(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+			Annotations: map[string]string{
+				AnnotationDeviceAllocated: `{}`,
+			},
+		},
+	}
+	allocations := DeviceAllocations{
+		schedulingv1alpha1.GPU: []*DeviceAllocation{
+			{
+				Minor: 0,
+				Resources: corev1.ResourceList{
+					ResourceGPUCore:   resource.MustParse("100"),
+					ResourceGPUMemory: resource.MustParse("80Gi"),
+				},
+			},
+			{
+				Minor: 1,
+				Resources: corev1.ResourceList{
+					ResourceGPUCore:   resource.MustParse("100"),
+					ResourceGPUMemory: resource.MustParse("80Gi"),
+				},
+			},
+		},
+		schedulingv1alpha1.RDMA: []*DeviceAllocation{
+			{
+				Minor: 0,
+				Resources: corev1.ResourceList{
+					ResourceRDMA: resource.MustParse("1"),
+				},
+				Extension: json.RawMessage(`{"vfs":[{"minor":2,"busID":"0000:1f:00.2"}]}`),
+			},
+		},
+	}
+	assert.Equal(t, 3, CountDeviceAllocations(allocations))
+
+	podDeviceAllocation := NewPodDeviceAllocation(pod, "test-node", allocations)
+	assert.Equal(t, pod.Name, podDeviceAllocation.Name)
+	assert.Equal(t, pod.Namespace, podDeviceAllocation.Namespace)
+	assert.Equal(t, pod.UID, podDeviceAllocation.Spec.PodUID)
+	assert.Equal(t, "test-node", podDeviceAllocation.Spec.NodeName)
+	assert.Len(t, podDeviceAllocation.OwnerReferences, 1)
+	assert.Equal(t, pod.UID, podDeviceAllocation.OwnerReferences[0].UID)
+	assert.Len(t, podDeviceAllocation.Spec.Devices, 3)
+	assert.Equal(t, allocations, GetDeviceAllocationsFromPodDeviceAllocation(podDeviceAllocation))
+
+	SetDeviceAllocationRef(pod, podDeviceAllocation.Name)
+	assert.Equal(t, podDeviceAllocation.Name, GetDeviceAllocationRef(pod.Annotations))
+	_, ok := pod.Annotations[AnnotationDeviceAllocated]
+	assert.False(t, ok)
+
+	getter := func(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error) {
+		assert.Equal(t, pod.Namespace, namespace)
+		assert.Equal(t, podDeviceAllocation.Name, name)
+		return podDeviceAllocation, nil
+	}
+	resolved, err := ResolveDeviceAllocations(pod.Namespace, pod.UID, pod.Annotations, getter)
+	assert.NoError(t, err)
+	assert.Equal(t, allocations, resolved)
+	_, err = ResolveDeviceAllocations(pod.Namespace, "other-uid", pod.Annotations, getter)
+	assert.Error(t, err)
+	_, err = ResolveDeviceAllocations(pod.Namespace, pod.UID, pod.Annotations, nil)
+	assert.Error(t, err)
+
+	// the pods without the reference fall back to the annotation
+	assert.NoError(t, SetDeviceAllocations(pod, allocations))
+	delete(pod.Annotations, AnnotationDeviceAllocationRef)
+	resolved, err = ResolveDeviceAllocations(pod.Namespace, pod.UID, pod.Annotations, nil)
+	assert.NoError(t, err)
+	assert.Len(t, resolved[schedulingv1alpha1.GPU], 2)
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/mohae/deepcopy"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
@@ -32,6 +33,8 @@ const (
 	ResourceQOSConfigKey       = "resource-qos-config"
 	CPUBurstConfigKey          = "cpu-burst-config"
 	SystemConfigKey            = "system-config"
+	EvictionBudgetConfigKey    = "eviction-budget-config"
+	ReservationLimitConfigKey  = "reservation-limit-config"
 )
 
 // +k8s:deepcopy-gen=true
@@ -102,6 +105,60 @@ type NodeResourceQOSStrategy struct {
 	*slov1alpha1.ResourceQOSStrategy
 }
 
+type EvictionBudgetScope string
+
+const (
+	// EvictionBudgetScopeNamespace counts the evictions of all the matched pods in a namespace together.
+	EvictionBudgetScopeNamespace EvictionBudgetScope = "Namespace"
+	// EvictionBudgetScopeWorkload counts the evictions of the matched pods by their controller,
+	// and a pod without controller is counted by itself.
+	EvictionBudgetScopeWorkload EvictionBudgetScope = "Workload"
+)
+
+// +k8s:deepcopy-gen=true
+type EvictionBudgetCfg struct {
+	Budgets []ClusterEvictionBudget `json:"budgets,omitempty"`
+}
+
+// ClusterEvictionBudget limits the evictions initiated by koordinator components in a time window,
+// so that the aggressive SLO reactions can't violate the availability of the workloads.
+// +k8s:deepcopy-gen=true
+type ClusterEvictionBudget struct {
+	Name string `json:"name,omitempty"`
+	// Namespaces of the pods limited by the budget, empty means all namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// PodSelector selects the pods limited by the budget, nil means all pods.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Initiators are the components limited by the budget, e.g. koordlet, koord-descheduler. Empty means all.
+	Initiators []string `json:"initiators,omitempty"`
+	// Scope determines how the evictions are counted, default is Workload.
+	Scope EvictionBudgetScope `json:"scope,omitempty"`
+	// MaxEvictions is the max number of evictions allowed in the Window for each counting scope.
+	MaxEvictions int32 `json:"maxEvictions"`
+	// Window is the length of the sliding time window, which should not be longer than 24h.
+	Window metav1.Duration `json:"window"`
+}
+
+// +k8s:deepcopy-gen=true
+type ReservationLimitCfg struct {
+	NamespaceLimits []ReservationNamespaceLimit `json:"namespaceLimits,omitempty"`
+}
+
+// ReservationNamespaceLimit limits the Reservations held by each namespace, so that a single team can't fence off
+// the cluster via large or long-lived Reservations. The namespace of a Reservation is the namespace of its template.
+// +k8s:deepcopy-gen=true
+type ReservationNamespaceLimit struct {
+	Name string `json:"name,omitempty"`
+	// Namespaces limited by the entry, and each of them is limited separately. Empty means the namespaces not listed
+	// by any other entries.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Max is the max total requests of the pending and active Reservations in a namespace.
+	Max corev1.ResourceList `json:"max,omitempty"`
+	// MaxTTL is the max lifetime of a Reservation in the namespace, and the Reservations never expiring are rejected.
+	// Nil means no limit.
+	MaxTTL *metav1.Duration `json:"maxTTL,omitempty"`
+}
+
 // +k8s:deepcopy-gen=true
 type ExtensionCfgMap struct {
 	Object map[string]ExtensionCfg `json:",inline"`
@@ -208,6 +265,8 @@ type ColocationStrategy struct {
 	MetricAggregatePolicy          *slov1alpha1.AggregatePolicy `json:"metricAggregatePolicy,omitempty"`
 	CPUReclaimThresholdPercent     *int64                       `json:"cpuReclaimThresholdPercent,omitempty"`
 	MemoryReclaimThresholdPercent  *int64                       `json:"memoryReclaimThresholdPercent,omitempty"`
+	GPUReclaimThresholdPercent     *int64                       `json:"gpuReclaimThresholdPercent,omitempty"`
+	GPUCoreOversellPercent         *int64                       `json:"gpuCoreOversellPercent,omitempty"` // the same as DeviceShareArgs of the scheduler
 	MemoryCalculatePolicy          *CalculatePolicy             `json:"memoryCalculatePolicy,omitempty"`
 	DegradeTimeMinutes             *int64                       `json:"degradeTimeMinutes,omitempty"`
 	UpdateTimeThresholdSeconds     *int64                       `json:"updateTimeThresholdSeconds,omitempty"`
@@ -23,6 +23,7 @@ package extension
 
 import (
 	"github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	timex "time"
 )
@@ -79,6 +80,37 @@ func (in *CPUBurstCfg) DeepCopy() *CPUBurstCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEvictionBudget) DeepCopyInto(out *ClusterEvictionBudget) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Initiators != nil {
+		in, out := &in.Initiators, &out.Initiators
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Window = in.Window
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEvictionBudget.
+func (in *ClusterEvictionBudget) DeepCopy() *ClusterEvictionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEvictionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ColocationCfg) DeepCopyInto(out *ColocationCfg) {
 	*out = *in
@@ -135,6 +167,16 @@ func (in *ColocationStrategy) DeepCopyInto(out *ColocationStrategy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.GPUReclaimThresholdPercent != nil {
+		in, out := &in.GPUReclaimThresholdPercent, &out.GPUReclaimThresholdPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.GPUCoreOversellPercent != nil {
+		in, out := &in.GPUCoreOversellPercent, &out.GPUCoreOversellPercent
+		*out = new(int64)
+		**out = **in
+	}
 	if in.MemoryCalculatePolicy != nil {
 		in, out := &in.MemoryCalculatePolicy, &out.MemoryCalculatePolicy
 		*out = new(CalculatePolicy)
@@ -184,6 +226,28 @@ func (in *ColocationStrategyExtender) DeepCopy() *ColocationStrategyExtender {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvictionBudgetCfg) DeepCopyInto(out *EvictionBudgetCfg) {
+	*out = *in
+	if in.Budgets != nil {
+		in, out := &in.Budgets, &out.Budgets
+		*out = make([]ClusterEvictionBudget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvictionBudgetCfg.
+func (in *EvictionBudgetCfg) DeepCopy() *EvictionBudgetCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(EvictionBudgetCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExtensionCfgMap) DeepCopyInto(out *ExtensionCfgMap) {
 	*out = *in
@@ -327,6 +391,60 @@ func (in *NodeSystemStrategy) DeepCopy() *NodeSystemStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationLimitCfg) DeepCopyInto(out *ReservationLimitCfg) {
+	*out = *in
+	if in.NamespaceLimits != nil {
+		in, out := &in.NamespaceLimits, &out.NamespaceLimits
+		*out = make([]ReservationNamespaceLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationLimitCfg.
+func (in *ReservationLimitCfg) DeepCopy() *ReservationLimitCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationLimitCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationNamespaceLimit) DeepCopyInto(out *ReservationNamespaceLimit) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MaxTTL != nil {
+		in, out := &in.MaxTTL, &out.MaxTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationNamespaceLimit.
+func (in *ReservationNamespaceLimit) DeepCopy() *ReservationNamespaceLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationNamespaceLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceQOSCfg) DeepCopyInto(out *ResourceQOSCfg) {
 	*out = *in
@@ -0,0 +1,773 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//
+//Copyright 2022 The Koordinator Authors.
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+// To regenerate podstats.pb.go run hack/generate-koordlet-api.sh
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1
+// 	protoc        v3.12.3
+// source: podstats.proto
+
+package v1alpha1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PodStatsFilter selects the pods to report. Empty fields match all pods.
+type PodStatsFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Namespace of the pod.
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Name of the pod.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// UID of the pod.
+	Uid string `protobuf:"bytes,3,opt,name=uid,proto3" json:"uid,omitempty"`
+}
+
+func (x *PodStatsFilter) Reset() {
+	*x = PodStatsFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodStatsFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodStatsFilter) ProtoMessage() {}
+
+func (x *PodStatsFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodStatsFilter.ProtoReflect.Descriptor instead.
+func (*PodStatsFilter) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PodStatsFilter) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *PodStatsFilter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PodStatsFilter) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+// PodUsage is the latest resource usage of a pod collected by koordlet.
+type PodUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// CPU usage in milli-cores.
+	CpuMilliCores int64 `protobuf:"varint,1,opt,name=cpu_milli_cores,json=cpuMilliCores,proto3" json:"cpu_milli_cores,omitempty"`
+	// Memory usage in bytes.
+	MemoryBytes int64 `protobuf:"varint,2,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	// Unix timestamp in seconds when the usage was collected.
+	UpdateTimestamp int64 `protobuf:"varint,3,opt,name=update_timestamp,json=updateTimestamp,proto3" json:"update_timestamp,omitempty"`
+}
+
+func (x *PodUsage) Reset() {
+	*x = PodUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodUsage) ProtoMessage() {}
+
+func (x *PodUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodUsage.ProtoReflect.Descriptor instead.
+func (*PodUsage) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PodUsage) GetCpuMilliCores() int64 {
+	if x != nil {
+		return x.CpuMilliCores
+	}
+	return 0
+}
+
+func (x *PodUsage) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *PodUsage) GetUpdateTimestamp() int64 {
+	if x != nil {
+		return x.UpdateTimestamp
+	}
+	return 0
+}
+
+// PodQoSParams is the QoS parameters currently applied on the pod-level cgroup.
+type PodQoSParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// cgroup directory of the pod, relative to the cgroup root.
+	CgroupDir string `protobuf:"bytes,1,opt,name=cgroup_dir,json=cgroupDir,proto3" json:"cgroup_dir,omitempty"`
+	// Value of cpu.shares.
+	CpuShares int64 `protobuf:"varint,2,opt,name=cpu_shares,json=cpuShares,proto3" json:"cpu_shares,omitempty"`
+	// Value of cpu.cfs_quota_us, -1 means unlimited.
+	CfsQuotaUs int64 `protobuf:"varint,3,opt,name=cfs_quota_us,json=cfsQuotaUs,proto3" json:"cfs_quota_us,omitempty"`
+	// Value of cpu.cfs_period_us.
+	CfsPeriodUs int64 `protobuf:"varint,4,opt,name=cfs_period_us,json=cfsPeriodUs,proto3" json:"cfs_period_us,omitempty"`
+	// Value of memory.limit_in_bytes.
+	MemoryLimitBytes int64 `protobuf:"varint,5,opt,name=memory_limit_bytes,json=memoryLimitBytes,proto3" json:"memory_limit_bytes,omitempty"`
+}
+
+func (x *PodQoSParams) Reset() {
+	*x = PodQoSParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodQoSParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodQoSParams) ProtoMessage() {}
+
+func (x *PodQoSParams) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodQoSParams.ProtoReflect.Descriptor instead.
+func (*PodQoSParams) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PodQoSParams) GetCgroupDir() string {
+	if x != nil {
+		return x.CgroupDir
+	}
+	return ""
+}
+
+func (x *PodQoSParams) GetCpuShares() int64 {
+	if x != nil {
+		return x.CpuShares
+	}
+	return 0
+}
+
+func (x *PodQoSParams) GetCfsQuotaUs() int64 {
+	if x != nil {
+		return x.CfsQuotaUs
+	}
+	return 0
+}
+
+func (x *PodQoSParams) GetCfsPeriodUs() int64 {
+	if x != nil {
+		return x.CfsPeriodUs
+	}
+	return 0
+}
+
+func (x *PodQoSParams) GetMemoryLimitBytes() int64 {
+	if x != nil {
+		return x.MemoryLimitBytes
+	}
+	return 0
+}
+
+// PodStats holds the resource usage and applied QoS parameters of a pod.
+type PodStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Namespace of the pod.
+	Namespace string `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Name of the pod.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// UID of the pod.
+	Uid string `protobuf:"bytes,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	// Koordinator QoS class of the pod, e.g. LSR, LS, BE.
+	QosClass string `protobuf:"bytes,4,opt,name=qos_class,json=qosClass,proto3" json:"qos_class,omitempty"`
+	// Kubernetes QoS class of the pod, e.g. Guaranteed, Burstable, BestEffort.
+	KubeQosClass string `protobuf:"bytes,5,opt,name=kube_qos_class,json=kubeQosClass,proto3" json:"kube_qos_class,omitempty"`
+	// Usage is not set if koordlet has not collected the pod yet.
+	Usage *PodUsage `protobuf:"bytes,6,opt,name=usage,proto3" json:"usage,omitempty"`
+	// QoSParams is not set if the pod-level cgroup can not be read.
+	QosParams *PodQoSParams `protobuf:"bytes,7,opt,name=qos_params,json=qosParams,proto3" json:"qos_params,omitempty"`
+}
+
+func (x *PodStats) Reset() {
+	*x = PodStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodStats) ProtoMessage() {}
+
+func (x *PodStats) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodStats.ProtoReflect.Descriptor instead.
+func (*PodStats) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PodStats) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *PodStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PodStats) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *PodStats) GetQosClass() string {
+	if x != nil {
+		return x.QosClass
+	}
+	return ""
+}
+
+func (x *PodStats) GetKubeQosClass() string {
+	if x != nil {
+		return x.KubeQosClass
+	}
+	return ""
+}
+
+func (x *PodStats) GetUsage() *PodUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *PodStats) GetQosParams() *PodQoSParams {
+	if x != nil {
+		return x.QosParams
+	}
+	return nil
+}
+
+type ListPodStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filter *PodStatsFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (x *ListPodStatsRequest) Reset() {
+	*x = ListPodStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPodStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPodStatsRequest) ProtoMessage() {}
+
+func (x *ListPodStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPodStatsRequest.ProtoReflect.Descriptor instead.
+func (*ListPodStatsRequest) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPodStatsRequest) GetFilter() *PodStatsFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type ListPodStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodStats []*PodStats `protobuf:"bytes,1,rep,name=pod_stats,json=podStats,proto3" json:"pod_stats,omitempty"`
+	// Unix timestamp in seconds when the response was generated.
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *ListPodStatsResponse) Reset() {
+	*x = ListPodStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPodStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPodStatsResponse) ProtoMessage() {}
+
+func (x *ListPodStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPodStatsResponse.ProtoReflect.Descriptor instead.
+func (*ListPodStatsResponse) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListPodStatsResponse) GetPodStats() []*PodStats {
+	if x != nil {
+		return x.PodStats
+	}
+	return nil
+}
+
+func (x *ListPodStatsResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type WatchPodStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filter *PodStatsFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Interval in seconds between two responses, the server default is used if not set.
+	IntervalSeconds int64 `protobuf:"varint,2,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+}
+
+func (x *WatchPodStatsRequest) Reset() {
+	*x = WatchPodStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_podstats_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchPodStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchPodStatsRequest) ProtoMessage() {}
+
+func (x *WatchPodStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_podstats_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchPodStatsRequest.ProtoReflect.Descriptor instead.
+func (*WatchPodStatsRequest) Descriptor() ([]byte, []int) {
+	return file_podstats_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchPodStatsRequest) GetFilter() *PodStatsFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *WatchPodStatsRequest) GetIntervalSeconds() int64 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+var File_podstats_proto protoreflect.FileDescriptor
+
+var file_podstats_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x70, 0x6f, 0x64, 0x73, 0x74, 0x61, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x11, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70,
+	0x68, 0x61, 0x31, 0x22, 0x54, 0x0a, 0x0e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x22, 0x80, 0x01, 0x0a, 0x08, 0x50, 0x6f,
+	0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x63, 0x70, 0x75, 0x5f, 0x6d, 0x69,
+	0x6c, 0x6c, 0x69, 0x5f, 0x63, 0x6f, 0x72, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0d, 0x63, 0x70, 0x75, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x43, 0x6f, 0x72, 0x65, 0x73, 0x12, 0x21,
+	0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x29, 0x0a, 0x10, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x75, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0xc0, 0x01, 0x0a,
+	0x0c, 0x50, 0x6f, 0x64, 0x51, 0x6f, 0x53, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x69, 0x72, 0x12, 0x1d, 0x0a, 0x0a,
+	0x63, 0x70, 0x75, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x63, 0x70, 0x75, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0c, 0x63,
+	0x66, 0x73, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x5f, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x63, 0x66, 0x73, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x55, 0x73, 0x12, 0x22, 0x0a,
+	0x0d, 0x63, 0x66, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x5f, 0x75, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x63, 0x66, 0x73, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x55,
+	0x73, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22,
+	0x84, 0x02, 0x0a, 0x08, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09,
+	0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64,
+	0x12, 0x1b, 0x0a, 0x09, 0x71, 0x6f, 0x73, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x71, 0x6f, 0x73, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x24, 0x0a,
+	0x0e, 0x6b, 0x75, 0x62, 0x65, 0x5f, 0x71, 0x6f, 0x73, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6b, 0x75, 0x62, 0x65, 0x51, 0x6f, 0x73, 0x43, 0x6c,
+	0x61, 0x73, 0x73, 0x12, 0x31, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52,
+	0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3e, 0x0a, 0x0a, 0x71, 0x6f, 0x73, 0x5f, 0x70, 0x61,
+	0x72, 0x61, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x6b, 0x6f, 0x6f,
+	0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50,
+	0x6f, 0x64, 0x51, 0x6f, 0x53, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x09, 0x71, 0x6f, 0x73,
+	0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x50, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f,
+	0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x39, 0x0a,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e,
+	0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61,
+	0x31, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x6e, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x38, 0x0a, 0x09, 0x70, 0x6f, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76,
+	0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x08, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x7c, 0x0a, 0x14, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x39, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c,
+	0x70, 0x68, 0x61, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x10, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x32, 0xdb, 0x01, 0x0a, 0x0f, 0x50, 0x6f, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x61, 0x0a, 0x0c, 0x4c, 0x69,
+	0x73, 0x74, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x26, 0x2e, 0x6b, 0x6f, 0x6f,
+	0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x27, 0x2e, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31,
+	0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x6f, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x65, 0x0a,
+	0x0d, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x27,
+	0x2e, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68,
+	0x61, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c,
+	0x65, 0x74, 0x2e, 0x76, 0x31, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x30, 0x01, 0x42, 0x3e, 0x5a, 0x3c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x2d, 0x73,
+	0x68, 0x2f, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x69, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x2f, 0x61, 0x70,
+	0x69, 0x73, 0x2f, 0x6b, 0x6f, 0x6f, 0x72, 0x64, 0x6c, 0x65, 0x74, 0x2f, 0x76, 0x31, 0x61, 0x6c,
+	0x70, 0x68, 0x61, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_podstats_proto_rawDescOnce sync.Once
+	file_podstats_proto_rawDescData = file_podstats_proto_rawDesc
+)
+
+func file_podstats_proto_rawDescGZIP() []byte {
+	file_podstats_proto_rawDescOnce.Do(func() {
+		file_podstats_proto_rawDescData = protoimpl.X.CompressGZIP(file_podstats_proto_rawDescData)
+	})
+	return file_podstats_proto_rawDescData
+}
+
+var file_podstats_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_podstats_proto_goTypes = []interface{}{
+	(*PodStatsFilter)(nil),       // 0: koordlet.v1alpha1.PodStatsFilter
+	(*PodUsage)(nil),             // 1: koordlet.v1alpha1.PodUsage
+	(*PodQoSParams)(nil),         // 2: koordlet.v1alpha1.PodQoSParams
+	(*PodStats)(nil),             // 3: koordlet.v1alpha1.PodStats
+	(*ListPodStatsRequest)(nil),  // 4: koordlet.v1alpha1.ListPodStatsRequest
+	(*ListPodStatsResponse)(nil), // 5: koordlet.v1alpha1.ListPodStatsResponse
+	(*WatchPodStatsRequest)(nil), // 6: koordlet.v1alpha1.WatchPodStatsRequest
+}
+var file_podstats_proto_depIdxs = []int32{
+	1, // 0: koordlet.v1alpha1.PodStats.usage:type_name -> koordlet.v1alpha1.PodUsage
+	2, // 1: koordlet.v1alpha1.PodStats.qos_params:type_name -> koordlet.v1alpha1.PodQoSParams
+	0, // 2: koordlet.v1alpha1.ListPodStatsRequest.filter:type_name -> koordlet.v1alpha1.PodStatsFilter
+	3, // 3: koordlet.v1alpha1.ListPodStatsResponse.pod_stats:type_name -> koordlet.v1alpha1.PodStats
+	0, // 4: koordlet.v1alpha1.WatchPodStatsRequest.filter:type_name -> koordlet.v1alpha1.PodStatsFilter
+	4, // 5: koordlet.v1alpha1.PodStatsService.ListPodStats:input_type -> koordlet.v1alpha1.ListPodStatsRequest
+	6, // 6: koordlet.v1alpha1.PodStatsService.WatchPodStats:input_type -> koordlet.v1alpha1.WatchPodStatsRequest
+	5, // 7: koordlet.v1alpha1.PodStatsService.ListPodStats:output_type -> koordlet.v1alpha1.ListPodStatsResponse
+	5, // 8: koordlet.v1alpha1.PodStatsService.WatchPodStats:output_type -> koordlet.v1alpha1.ListPodStatsResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_podstats_proto_init() }
+func file_podstats_proto_init() {
+	if File_podstats_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_podstats_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodStatsFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_podstats_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodUsage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_podstats_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodQoSParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_podstats_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_podstats_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPodStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_podstats_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPodStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_podstats_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchPodStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_podstats_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_podstats_proto_goTypes,
+		DependencyIndexes: file_podstats_proto_depIdxs,
+		MessageInfos:      file_podstats_proto_msgTypes,
+	}.Build()
+	File_podstats_proto = out.File
+	file_podstats_proto_rawDesc = nil
+	file_podstats_proto_goTypes = nil
+	file_podstats_proto_depIdxs = nil
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.12.3
+// source: podstats.proto
+
+package v1alpha1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PodStatsServiceClient is the client API for PodStatsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PodStatsServiceClient interface {
+	// ListPodStats returns the current statistics of the selected pods.
+	ListPodStats(ctx context.Context, in *ListPodStatsRequest, opts ...grpc.CallOption) (*ListPodStatsResponse, error)
+	// WatchPodStats streams the statistics of the selected pods periodically.
+	WatchPodStats(ctx context.Context, in *WatchPodStatsRequest, opts ...grpc.CallOption) (PodStatsService_WatchPodStatsClient, error)
+}
+
+type podStatsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPodStatsServiceClient(cc grpc.ClientConnInterface) PodStatsServiceClient {
+	return &podStatsServiceClient{cc}
+}
+
+func (c *podStatsServiceClient) ListPodStats(ctx context.Context, in *ListPodStatsRequest, opts ...grpc.CallOption) (*ListPodStatsResponse, error) {
+	out := new(ListPodStatsResponse)
+	err := c.cc.Invoke(ctx, "/koordlet.v1alpha1.PodStatsService/ListPodStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *podStatsServiceClient) WatchPodStats(ctx context.Context, in *WatchPodStatsRequest, opts ...grpc.CallOption) (PodStatsService_WatchPodStatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PodStatsService_ServiceDesc.Streams[0], "/koordlet.v1alpha1.PodStatsService/WatchPodStats", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &podStatsServiceWatchPodStatsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PodStatsService_WatchPodStatsClient interface {
+	Recv() (*ListPodStatsResponse, error)
+	grpc.ClientStream
+}
+
+type podStatsServiceWatchPodStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *podStatsServiceWatchPodStatsClient) Recv() (*ListPodStatsResponse, error) {
+	m := new(ListPodStatsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PodStatsServiceServer is the server API for PodStatsService service.
+// All implementations must embed UnimplementedPodStatsServiceServer
+// for forward compatibility
+type PodStatsServiceServer interface {
+	// ListPodStats returns the current statistics of the selected pods.
+	ListPodStats(context.Context, *ListPodStatsRequest) (*ListPodStatsResponse, error)
+	// WatchPodStats streams the statistics of the selected pods periodically.
+	WatchPodStats(*WatchPodStatsRequest, PodStatsService_WatchPodStatsServer) error
+	mustEmbedUnimplementedPodStatsServiceServer()
+}
+
+// UnimplementedPodStatsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPodStatsServiceServer struct {
+}
+
+func (UnimplementedPodStatsServiceServer) ListPodStats(context.Context, *ListPodStatsRequest) (*ListPodStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPodStats not implemented")
+}
+func (UnimplementedPodStatsServiceServer) WatchPodStats(*WatchPodStatsRequest, PodStatsService_WatchPodStatsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPodStats not implemented")
+}
+func (UnimplementedPodStatsServiceServer) mustEmbedUnimplementedPodStatsServiceServer() {}
+
+// UnsafePodStatsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PodStatsServiceServer will
+// result in compilation errors.
+type UnsafePodStatsServiceServer interface {
+	mustEmbedUnimplementedPodStatsServiceServer()
+}
+
+func RegisterPodStatsServiceServer(s grpc.ServiceRegistrar, srv PodStatsServiceServer) {
+	s.RegisterService(&PodStatsService_ServiceDesc, srv)
+}
+
+func _PodStatsService_ListPodStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPodStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PodStatsServiceServer).ListPodStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/koordlet.v1alpha1.PodStatsService/ListPodStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PodStatsServiceServer).ListPodStats(ctx, req.(*ListPodStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PodStatsService_WatchPodStats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchPodStatsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PodStatsServiceServer).WatchPodStats(m, &podStatsServiceWatchPodStatsServer{stream})
+}
+
+type PodStatsService_WatchPodStatsServer interface {
+	Send(*ListPodStatsResponse) error
+	grpc.ServerStream
+}
+
+type podStatsServiceWatchPodStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *podStatsServiceWatchPodStatsServer) Send(m *ListPodStatsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PodStatsService_ServiceDesc is the grpc.ServiceDesc for PodStatsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PodStatsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "koordlet.v1alpha1.PodStatsService",
+	HandlerType: (*PodStatsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListPodStats",
+			Handler:    _PodStatsService_ListPodStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPodStats",
+			Handler:       _PodStatsService_WatchPodStats_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "podstats.proto",
+}
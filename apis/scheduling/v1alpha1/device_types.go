@@ -27,10 +27,15 @@ const (
 	GPU  DeviceType = "gpu"
 	FPGA DeviceType = "fpga"
 	RDMA DeviceType = "rdma"
+	NIC  DeviceType = "nic"
 )
 
 type DeviceSpec struct {
 	Devices []DeviceInfo `json:"devices,omitempty"`
+	// MaxPodsPerGPU limits how many pods can share a single GPU on the node regardless of the remaining gpu-core,
+	// which overrides the limit configured in koord-scheduler. Zero means no limit.
+	// +optional
+	MaxPodsPerGPU *int32 `json:"maxPodsPerGPU,omitempty"`
 }
 
 type DeviceInfo struct {
@@ -44,10 +49,60 @@ type DeviceInfo struct {
 	Health bool `json:"health,omitempty"`
 	// Resources is a set of (resource name, quantity) pairs
 	Resources corev1.ResourceList `json:"resources,omitempty"`
+	// VFs represents the SR-IOV virtual functions of the device, only used by RDMA devices now
+	VFs []VirtualFunction `json:"vfs,omitempty"`
+}
+
+type VirtualFunction struct {
+	// Minor represents the Minor number of VirtualFunction, starting from 0
+	Minor int32 `json:"minor"`
+	// BusID represents the PCIe bus id of VirtualFunction, e.g. 0000:1f:00.2
+	BusID string `json:"busID,omitempty"`
 }
 
 type DeviceStatus struct {
 	Allocations []DeviceAllocation `json:"allocations,omitempty"`
+	// Conditions represents the errors of the devices detected by koordlet, e.g. from the kernel log.
+	// +optional
+	Conditions []DeviceCondition `json:"conditions,omitempty"`
+}
+
+type DeviceConditionType string
+
+const (
+	// DeviceConditionXidError indicates the GPU reports a critical Xid error which is not caused by the applications.
+	DeviceConditionXidError DeviceConditionType = "XidError"
+	// DeviceConditionLinkFlapping indicates the link of the NIC goes down repeatedly in a short period.
+	DeviceConditionLinkFlapping DeviceConditionType = "LinkFlapping"
+	// DeviceConditionRDMALinkError indicates the RDMA device reports link errors.
+	DeviceConditionRDMALinkError DeviceConditionType = "RDMALinkError"
+)
+
+type DeviceCondition struct {
+	// Type is the type of the condition
+	Type DeviceConditionType `json:"type"`
+	// Status is the status of the condition, True means the error is observed
+	Status corev1.ConditionStatus `json:"status"`
+	// DeviceType is the type of the device which the condition is about
+	DeviceType DeviceType `json:"deviceType,omitempty"`
+	// Minor is the minor of the device in spec.devices, which is nil if the device is not reported in spec.devices
+	// +optional
+	Minor *int32 `json:"minor,omitempty"`
+	// BusID is the PCIe bus id of the device, e.g. 0000:3b:00.0
+	// +optional
+	BusID string `json:"busID,omitempty"`
+	// Name is the name of the device in the kernel, e.g. eth0 or mlx5_0
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Reason is a brief reason for the condition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable message about the condition, e.g. the latest kernel log
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the time when the condition is last observed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 type DeviceAllocation struct {
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type PodDeviceAllocationSpec struct {
+	// PodUID is the UID of the pod which the devices are allocated to. It guards against
+	// a pod recreated with the same name reading the allocation of its predecessor.
+	PodUID types.UID `json:"podUID,omitempty"`
+	// NodeName is the node where the devices are allocated.
+	NodeName string `json:"nodeName,omitempty"`
+	// Devices are the devices allocated to the pod.
+	Devices []PodDeviceAllocationItem `json:"devices,omitempty"`
+}
+
+type PodDeviceAllocationItem struct {
+	// Type represents the type of device
+	Type DeviceType `json:"type"`
+	// Minor represents the Minor number of Device, starting from 0
+	Minor int32 `json:"minor"`
+	// Resources is a set of (resource name, quantity) pairs allocated from the device
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+	// Extension is the vendor or device specific information of the allocation,
+	// e.g. the SR-IOV virtual functions bound to the pod.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Extension *runtime.RawExtension `json:"extension,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=pda
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.nodeName"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// PodDeviceAllocation stores the devices allocated to a pod out of the pod annotations. It is used for the pods
+// allocating so many devices that the annotation scheduling.koordinator.sh/device-allocated grows too large, and is
+// referenced from the pod by the annotation scheduling.koordinator.sh/device-allocation-ref. It is owned by the pod
+// and shares the name and namespace with the pod.
+type PodDeviceAllocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PodDeviceAllocationSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+type PodDeviceAllocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PodDeviceAllocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PodDeviceAllocation{}, &PodDeviceAllocationList{})
+}
@@ -165,6 +165,7 @@ const (
 	PodMigrationJobConditionReservationPodBoundReservation PodMigrationJobConditionType = "PodBoundReservation"
 	PodMigrationJobConditionBoundPodReady                  PodMigrationJobConditionType = "BoundPodReady"
 	PodMigrationJobConditionReservationBound               PodMigrationJobConditionType = "ReservationBound"
+	PodMigrationJobConditionCheckpoint                     PodMigrationJobConditionType = "Checkpoint"
 )
 
 // These are valid reasons of PodMigrationJob.
@@ -183,6 +184,9 @@ const (
 	PodMigrationJobReasonEvictComplete             = "EvictComplete"
 	PodMigrationJobReasonWaitForPodBindReservation = "WaitForPodBindReservation"
 	PodMigrationJobReasonWaitForBoundPodReady      = "WaitForBoundPodReady"
+	PodMigrationJobReasonCheckpointing             = "Checkpointing"
+	PodMigrationJobReasonCheckpointComplete        = "CheckpointComplete"
+	PodMigrationJobReasonCheckpointFailed          = "CheckpointFailed"
 )
 
 type PodMigrationJobConditionStatus string
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -36,11 +37,22 @@ type ReservationSpec struct {
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:validation:Required
 	Template *corev1.PodTemplateSpec `json:"template"`
+	// NodeSelector restricts the nodes where the resources can be reserved, e.g. to a dedicated node pool, without
+	// touching the template. The node must match it in addition to the `template.spec.nodeSelector`.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// NodeAffinity restricts the nodes where the resources can be reserved in addition to the node affinity of the
+	// template. The node must satisfy the required terms of both, while the preferred terms are appended to the ones
+	// of the template.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +optional
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
 	// Specify the owners who can allocate the reserved resources.
 	// Multiple owner selectors and ORed.
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:MinItems=1
-	Owners []ReservationOwner `json:"owners"`
+	// It is required unless the reservation is capacity-only.
+	// +optional
+	Owners []ReservationOwner `json:"owners,omitempty"`
 	// Time-to-Live period for the reservation.
 	// `expires` and `ttl` are mutually exclusive. Defaults to 24h. Set 0 to disable expiration.
 	// +kubebuilder:default="24h"
@@ -62,8 +74,41 @@ type ReservationSpec struct {
 	// and are not allocatable to other owners anymore.
 	// +optional
 	AllocateOnce bool `json:"allocateOnce,omitempty"`
+	// AllocatePolicy represents the allocation policy of reserved resources that the reservation wants.
+	// By default, a pod can allocate the reservation as long as its requests fit within the reserved resources.
+	// When `AllocatePolicy` is `ExactMatch`, the reservation is only allocatable to the owner whose requests exactly
+	// match the reserved resources, so that the small pods cannot nibble at the reservation intended for a large pod.
+	// +kubebuilder:validation:Enum=Default;ExactMatch
+	// +optional
+	AllocatePolicy ReservationAllocatePolicy `json:"allocatePolicy,omitempty"`
+	// By default, the unallocated remainder of a reservation keeps reserved until the reservation is expired even if
+	// the owners only allocate part of it. When `Restock` is set and `AllocateOnce` is not set, the unallocated
+	// remainder of a partially allocated reservation is periodically returned to the node and recorded in
+	// `status.restocked`. The restocked resources are no longer allocatable to the owners.
+	// +optional
+	Restock bool `json:"restock,omitempty"`
+	// CapacityOnly indicates the reservation declares no owners and reserves generic capacity, e.g. a pre-warmed
+	// capacity pool. A capacity-only reservation can be allocated by any pod whose requests fit the reserved resources
+	// and which tolerates all `taints` of the reservation with the annotation
+	// `scheduling.koordinator.sh/reservation-tolerations`. Pods without the annotation never allocate it.
+	// +optional
+	CapacityOnly bool `json:"capacityOnly,omitempty"`
+	// Taints of the capacity-only reservation, which must be tolerated by the pods allocating the reservation.
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
 }
 
+type ReservationAllocatePolicy string
+
+const (
+	// ReservationAllocatePolicyDefault allows the owner to allocate the reservation if its requests fit within
+	// the reserved resources.
+	ReservationAllocatePolicyDefault ReservationAllocatePolicy = "Default"
+	// ReservationAllocatePolicyExactMatch only allows the owner to allocate the reservation if its requests are
+	// exactly equal to the reserved resources.
+	ReservationAllocatePolicyExactMatch ReservationAllocatePolicy = "ExactMatch"
+)
+
 // ReservationTemplateSpec describes the data a Reservation should have when created from a template
 type ReservationTemplateSpec struct {
 	// Standard object's metadata.
@@ -90,6 +135,9 @@ type ReservationStatus struct {
 	// Current resource owners which allocated the reservation resources.
 	// +optional
 	CurrentOwners []corev1.ObjectReference `json:"currentOwners,omitempty"`
+	// Pods currently allocated from the reservation, with the resources allocated by each of them.
+	// +optional
+	AllocatedPods []ReservationAllocatedPod `json:"allocatedPods,omitempty"`
 	// Name of node the reservation is scheduled on.
 	// +optional
 	NodeName string `json:"nodeName,omitempty"`
@@ -99,6 +147,29 @@ type ReservationStatus struct {
 	// Resource allocated by current owners.
 	// +optional
 	Allocated corev1.ResourceList `json:"allocated,omitempty"`
+	// Resource returned to the node from the unallocated remainder when the reservation is restocked.
+	// +optional
+	Restocked corev1.ResourceList `json:"restocked,omitempty"`
+	// Name of node the reservation is nominated to run on after preempting lower priority pods. It is cleared when
+	// the reservation is scheduled.
+	// +optional
+	NominatedNodeName string `json:"nominatedNodeName,omitempty"`
+	// Pods preempted by the reservation.
+	// +optional
+	PreemptedPods []corev1.ObjectReference `json:"preemptedPods,omitempty"`
+}
+
+// ReservationAllocatedPod describes a pod allocated from the reservation.
+type ReservationAllocatedPod struct {
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	UID types.UID `json:"uid,omitempty"`
+	// Resources allocated by the pod from the reservation.
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
 }
 
 // ReservationOwner indicates the owner specification which can allocate reserved resources.
@@ -111,6 +182,11 @@ type ReservationOwner struct {
 	Controller *ReservationControllerReference `json:"controller,omitempty"`
 	// +optional
 	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+	// NodeAffinity is evaluated against the reservation's node when the matched pods consume the reservation.
+	// The reservation is not consumed by the pods if its node no longer satisfies the node affinity, e.g. the node
+	// labels are changed after the reservation is scheduled.
+	// +optional
+	NodeAffinity *corev1.NodeSelector `json:"nodeAffinity,omitempty"`
 }
 
 type ReservationControllerReference struct {
@@ -142,6 +218,8 @@ type ReservationConditionType string
 const (
 	ReservationConditionScheduled ReservationConditionType = "Scheduled"
 	ReservationConditionReady     ReservationConditionType = "Ready"
+	// ReservationConditionResized indicates whether the last resize of the Available reservation succeeded.
+	ReservationConditionResized ReservationConditionType = "Resized"
 )
 
 type ConditionStatus string
@@ -159,6 +237,9 @@ const (
 	ReasonReservationAvailable = "Available"
 	ReasonReservationSucceeded = "Succeeded"
 	ReasonReservationExpired   = "Expired"
+
+	ReasonReservationResized      = "Resized"
+	ReasonReservationResizeFailed = "ResizeFailed"
 )
 
 type ReservationCondition struct {
@@ -96,6 +96,27 @@ func (in *DeviceAllocationItem) DeepCopy() *DeviceAllocationItem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceCondition) DeepCopyInto(out *DeviceCondition) {
+	*out = *in
+	if in.Minor != nil {
+		in, out := &in.Minor, &out.Minor
+		*out = new(int32)
+		**out = **in
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceCondition.
+func (in *DeviceCondition) DeepCopy() *DeviceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceInfo) DeepCopyInto(out *DeviceInfo) {
 	*out = *in
@@ -111,6 +132,11 @@ func (in *DeviceInfo) DeepCopyInto(out *DeviceInfo) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.VFs != nil {
+		in, out := &in.VFs, &out.VFs
+		*out = make([]VirtualFunction, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceInfo.
@@ -165,6 +191,11 @@ func (in *DeviceSpec) DeepCopyInto(out *DeviceSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MaxPodsPerGPU != nil {
+		in, out := &in.MaxPodsPerGPU, &out.MaxPodsPerGPU
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceSpec.
@@ -187,6 +218,13 @@ func (in *DeviceStatus) DeepCopyInto(out *DeviceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]DeviceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceStatus.
@@ -199,6 +237,113 @@ func (in *DeviceStatus) DeepCopy() *DeviceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDeviceAllocation) DeepCopyInto(out *PodDeviceAllocation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDeviceAllocation.
+func (in *PodDeviceAllocation) DeepCopy() *PodDeviceAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDeviceAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodDeviceAllocation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDeviceAllocationItem) DeepCopyInto(out *PodDeviceAllocationItem) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Extension != nil {
+		in, out := &in.Extension, &out.Extension
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDeviceAllocationItem.
+func (in *PodDeviceAllocationItem) DeepCopy() *PodDeviceAllocationItem {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDeviceAllocationItem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDeviceAllocationList) DeepCopyInto(out *PodDeviceAllocationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodDeviceAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDeviceAllocationList.
+func (in *PodDeviceAllocationList) DeepCopy() *PodDeviceAllocationList {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDeviceAllocationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodDeviceAllocationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDeviceAllocationSpec) DeepCopyInto(out *PodDeviceAllocationSpec) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]PodDeviceAllocationItem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDeviceAllocationSpec.
+func (in *PodDeviceAllocationSpec) DeepCopy() *PodDeviceAllocationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDeviceAllocationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodMigrateReservationOptions) DeepCopyInto(out *PodMigrateReservationOptions) {
 	*out = *in
@@ -446,6 +591,28 @@ func (in *Reservation) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationAllocatedPod) DeepCopyInto(out *ReservationAllocatedPod) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationAllocatedPod.
+func (in *ReservationAllocatedPod) DeepCopy() *ReservationAllocatedPod {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationAllocatedPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReservationCondition) DeepCopyInto(out *ReservationCondition) {
 	*out = *in
@@ -529,6 +696,11 @@ func (in *ReservationOwner) DeepCopyInto(out *ReservationOwner) {
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(v1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationOwner.
@@ -549,6 +721,18 @@ func (in *ReservationSpec) DeepCopyInto(out *ReservationSpec) {
 		*out = new(v1.PodTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(v1.NodeAffinity)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Owners != nil {
 		in, out := &in.Owners, &out.Owners
 		*out = make([]ReservationOwner, len(*in))
@@ -565,6 +749,13 @@ func (in *ReservationSpec) DeepCopyInto(out *ReservationSpec) {
 		in, out := &in.Expires, &out.Expires
 		*out = (*in).DeepCopy()
 	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]v1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationSpec.
@@ -592,6 +783,13 @@ func (in *ReservationStatus) DeepCopyInto(out *ReservationStatus) {
 		*out = make([]v1.ObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllocatedPods != nil {
+		in, out := &in.AllocatedPods, &out.AllocatedPods
+		*out = make([]ReservationAllocatedPod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Allocatable != nil {
 		in, out := &in.Allocatable, &out.Allocatable
 		*out = make(v1.ResourceList, len(*in))
@@ -606,6 +804,18 @@ func (in *ReservationStatus) DeepCopyInto(out *ReservationStatus) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Restocked != nil {
+		in, out := &in.Restocked, &out.Restocked
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.PreemptedPods != nil {
+		in, out := &in.PreemptedPods, &out.PreemptedPods
+		*out = make([]v1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationStatus.
@@ -634,3 +844,18 @@ func (in *ReservationTemplateSpec) DeepCopy() *ReservationTemplateSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualFunction) DeepCopyInto(out *VirtualFunction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualFunction.
+func (in *VirtualFunction) DeepCopy() *VirtualFunction {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualFunction)
+	in.DeepCopyInto(out)
+	return out
+}
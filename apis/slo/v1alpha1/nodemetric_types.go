@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -38,6 +39,19 @@ type NodeMetricInfo struct {
 	NodeUsage ResourceMap `json:"nodeUsage,omitempty"`
 	// AggregatedNodeUsages will report only if there are enough samples
 	AggregatedNodeUsages []AggregatedUsage `json:"aggregatedNodeUsages,omitempty"`
+	// SystemUsage is the resource usage of the system components, e.g. kubelet, container runtime and system daemons
+	SystemUsage ResourceMap `json:"systemUsage,omitempty"`
+	// NUMAUsages are the resource usages of the NUMA nodes, sorted by the NUMA node id
+	NUMAUsages []NUMAUsage `json:"numaUsages,omitempty"`
+}
+
+type NUMAUsage struct {
+	// NUMANodeID is the id of the NUMA node
+	NUMANodeID int32 `json:"numaNodeID"`
+	// Usage is the cpu and memory usage of the NUMA node, where the memory usage excludes the page cache
+	Usage ResourceMap `json:"usage,omitempty"`
+	// Free is the free resources of the NUMA node, e.g. the free memory
+	Free ResourceMap `json:"free,omitempty"`
 }
 
 type AggregatedUsage struct {
@@ -49,6 +63,9 @@ type PodMetricInfo struct {
 	Name      string      `json:"name,omitempty"`
 	Namespace string      `json:"namespace,omitempty"`
 	PodUsage  ResourceMap `json:"podUsage,omitempty"`
+	// ReclaimableUsage is the usage of the pod which can be reclaimed without hurting the workloads, e.g. the cold memory
+	// not accessed for a while
+	ReclaimableUsage ResourceMap `json:"reclaimableUsage,omitempty"`
 	// Third party extensions for PodMetric
 	Extensions *ExtensionsMap `json:"extensions,omitempty"`
 }
@@ -83,6 +100,42 @@ type NodeMetricStatus struct {
 
 	// PodsMetric contains the metrics for pods belong to this node.
 	PodsMetric []*PodMetricInfo `json:"podsMetric,omitempty"`
+
+	// Conditions represents the observations of the reported metrics made by koord-manager.
+	// +optional
+	Conditions []NodeMetricCondition `json:"conditions,omitempty"`
+}
+
+type NodeMetricConditionType string
+
+const (
+	// NodeMetricConditionDegraded indicates the reported metrics are anomalous, e.g. a sudden usage cliff, flatlined
+	// reports or impossible values, so the consumers like the load-aware scheduling should not trust them.
+	NodeMetricConditionDegraded NodeMetricConditionType = "Degraded"
+)
+
+// These are the reasons of the Degraded condition.
+const (
+	NodeMetricReasonUsageCliff      = "UsageCliff"
+	NodeMetricReasonFlatlined       = "Flatlined"
+	NodeMetricReasonImpossibleValue = "ImpossibleValue"
+	NodeMetricReasonNormal          = "Normal"
+)
+
+type NodeMetricCondition struct {
+	// Type is the type of the condition
+	Type NodeMetricConditionType `json:"type"`
+	// Status is the status of the condition, True means the anomaly is observed
+	Status corev1.ConditionStatus `json:"status"`
+	// Reason is a brief reason for the condition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable message about the condition
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +genclient
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -129,6 +130,7 @@ type ResourceQOS struct {
 	CPUQOS     *CPUQOSCfg     `json:"cpuQOS,omitempty"`
 	MemoryQOS  *MemoryQOSCfg  `json:"memoryQOS,omitempty"`
 	ResctrlQOS *ResctrlQOSCfg `json:"resctrlQOS,omitempty"`
+	NetworkQOS *NetworkQOSCfg `json:"networkQOS,omitempty"`
 }
 
 type ResourceQOSStrategy struct {
@@ -165,6 +167,9 @@ type ResourceThresholdStrategy struct {
 	CPUSuppressThresholdPercent *int64 `json:"cpuSuppressThresholdPercent,omitempty"`
 	// CPUSuppressPolicy
 	CPUSuppressPolicy CPUSuppressPolicy `json:"cpuSuppressPolicy,omitempty"`
+	// CPUSuppressAutoTuning adjusts the BE cfs quota step by step according to the interference of LS pods instead
+	// of the fixed CPUSuppressThresholdPercent, which only works with the cfsQuota suppress policy
+	CPUSuppressAutoTuning *CPUSuppressAutoTuningStrategy `json:"cpuSuppressAutoTuning,omitempty"`
 
 	// upper: memory evict threshold percentage (0,100), default = 70
 	// +kubebuilder:validation:Maximum=100
@@ -185,6 +190,48 @@ type ResourceThresholdStrategy struct {
 	CPUEvictTimeWindowSeconds *int64 `json:"cpuEvictTimeWindowSeconds,omitempty"`
 }
 
+// CPUSuppressAutoTuningStrategy configures a PID-like feedback controller for the BE cfs quota.
+// The error of each round is the max interference of LS pods (cpu PSI, cpu throttled ratio or run queue latency)
+// minus its target, a positive error scales down the BE quota and a negative one scales it up.
+type CPUSuppressAutoTuningStrategy struct {
+	// whether the auto tuning is enabled, default = false
+	Enable *bool `json:"enable,omitempty"`
+	// target of LS pods' cpu PSI some avg10 percentage, default = 10.
+	// The error of each target is the excess in percentage of the target, e.g. PSI 15 is an error of 50 for target 10.
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	TargetLSCPUPSIPercent *int64 `json:"targetLSCPUPSIPercent,omitempty"`
+	// target of LS pods' cpu throttled ratio percentage, default = 10
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	TargetLSCPUThrottledPercent *int64 `json:"targetLSCPUThrottledPercent,omitempty"`
+	// target of LS pods' average run queue latency in microseconds, which is collected by the SchedLatencyCollector.
+	// The latency is not used if not set.
+	// +kubebuilder:validation:Minimum=1
+	TargetLSSchedLatencyMicroseconds *int64 `json:"targetLSSchedLatencyMicroseconds,omitempty"`
+	// proportional gain in thousandths, default = 500
+	// +kubebuilder:validation:Minimum=0
+	ProportionalGainMilli *int64 `json:"proportionalGainMilli,omitempty"`
+	// integral gain in thousandths, default = 100
+	// +kubebuilder:validation:Minimum=0
+	IntegralGainMilli *int64 `json:"integralGainMilli,omitempty"`
+	// derivative gain in thousandths, default = 0
+	// +kubebuilder:validation:Minimum=0
+	DerivativeGainMilli *int64 `json:"derivativeGainMilli,omitempty"`
+	// max change of the BE quota in each round, percentage of the node cpu capacity, default = 5
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=1
+	MaxStepPercent *int64 `json:"maxStepPercent,omitempty"`
+	// lower bound of the BE quota, percentage of the node cpu capacity, default = 5
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	MinBECPUPercent *int64 `json:"minBECPUPercent,omitempty"`
+	// upper bound of the BE quota, percentage of the node cpu capacity, default = CPUSuppressThresholdPercent
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:validation:Minimum=0
+	MaxBECPUPercent *int64 `json:"maxBECPUPercent,omitempty"`
+}
+
 // ResctrlQOSCfg stores node-level config of resctrl qos
 type ResctrlQOSCfg struct {
 	// Enable indicates whether the resctrl qos is enabled.
@@ -207,6 +254,22 @@ type ResctrlQOS struct {
 	MBAPercent *int64 `json:"mbaPercent,omitempty"`
 }
 
+// NetworkQOSCfg stores node-level config of network qos
+type NetworkQOSCfg struct {
+	// Enable indicates whether the network qos is enabled.
+	Enable     *bool `json:"enable,omitempty"`
+	NetworkQOS `json:",inline"`
+}
+
+type NetworkQOS struct {
+	// DSCP (Differentiated Services Code Point) marked on the egress packets of the pods, so that the switches can
+	// prioritize the traffic by the QoS class, e.g. 46 (EF) for LSR, 34 (AF41) for LS and 8 (CS1) for BE.
+	// Close: 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=63
+	DSCP *int64 `json:"dscp,omitempty"`
+}
+
 type CPUBurstPolicy string
 
 const (
@@ -230,6 +293,13 @@ type CPUBurstConfig struct {
 	CFSQuotaBurstPercent *int64 `json:"cfsQuotaBurstPercent,omitempty"`
 	// specifies a period of time for pod can use at burst, default = -1 (unlimited)
 	CFSQuotaBurstPeriodSeconds *int64 `json:"cfsQuotaBurstPeriodSeconds,omitempty"`
+	// cfs quota scale up ceil percentage of the throttled LS containers in the startup window, which speeds up
+	// the cold start like the JIT warmup and the class loading, the startup boost is disabled if not larger than 100
+	// +kubebuilder:validation:Minimum=0
+	StartupBoostPercent *int64 `json:"startupBoostPercent,omitempty"`
+	// specifies a period of time since the container started for the startup boost, default = 0 (disabled)
+	// +kubebuilder:validation:Minimum=0
+	StartupBoostSeconds *int64 `json:"startupBoostSeconds,omitempty"`
 }
 
 type CPUBurstStrategy struct {
@@ -243,6 +313,29 @@ type SystemStrategy struct {
 	MinFreeKbytesFactor *int64 `json:"minFreeKbytesFactor,omitempty"`
 	// /proc/sys/vm/watermark_scale_factor
 	WatermarkScaleFactor *int64 `json:"watermarkScaleFactor,omitempty"`
+	// NodeTuning is the tuning profile of the node, which is usually declared for the nodes of a role
+	NodeTuning *NodeTuningProfile `json:"nodeTuning,omitempty"`
+}
+
+// NodeTuningProfile declares the kernel parameters and the interrupt layout of the node. The values replaced by
+// koordlet are rolled back when they fail the verification or are removed from the profile.
+type NodeTuningProfile struct {
+	// Name of the profile, e.g. the node role
+	Name string `json:"name,omitempty"`
+	// Sysctls are the kernel parameters by the name, e.g. net.core.somaxconn
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+	// IRQAffinity steers the interrupts of the network devices
+	IRQAffinity *IRQAffinityProfile `json:"irqAffinity,omitempty"`
+}
+
+type IRQAffinityProfile struct {
+	// Devices are the network interfaces whose IRQs are steered, e.g. eth0. For the virtual devices without IRQs
+	// (e.g. macvlan), the receive packet steering (RPS) of their queues is set instead.
+	Devices []string `json:"devices,omitempty"`
+	// CPUs is the Linux CPU list the interrupts are steered to, e.g. 0-3. All the CPUs of the node if empty.
+	CPUs string `json:"cpus,omitempty"`
+	// ExcludeExclusiveCPUs steers the interrupts away from the CPUs bound exclusively by the LSE and LSR pods
+	ExcludeExclusiveCPUs *bool `json:"excludeExclusiveCPUs,omitempty"`
 }
 
 // NodeSLOSpec defines the desired state of NodeSLO
@@ -261,8 +354,46 @@ type NodeSLOSpec struct {
 
 // NodeSLOStatus defines the observed state of NodeSLO
 type NodeSLOStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the generation of the NodeSLO spec most recently applied by koordlet.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastAppliedTime is the last time koordlet applied the NodeSLO spec.
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// Conditions represents the observations of the applied state made by koord-manager.
+	// +optional
+	Conditions []NodeSLOCondition `json:"conditions,omitempty"`
+}
+
+type NodeSLOConditionType string
+
+const (
+	// NodeSLOConditionDrifted indicates koordlet has not applied the latest NodeSLO spec for too long, e.g. the
+	// koordlet instance is stuck or keeps crashing.
+	NodeSLOConditionDrifted NodeSLOConditionType = "Drifted"
+)
+
+// These are the reasons of the Drifted condition.
+const (
+	NodeSLOReasonApplyLagging = "ApplyLagging"
+	NodeSLOReasonNotReported  = "NotReported"
+	NodeSLOReasonSynced       = "Synced"
+)
+
+type NodeSLOCondition struct {
+	// Type is the type of the condition
+	Type NodeSLOConditionType `json:"type"`
+	// Status is the status of the condition, True means the drift is observed
+	Status corev1.ConditionStatus `json:"status"`
+	// Reason is a brief reason for the condition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable message about the condition
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +genclient
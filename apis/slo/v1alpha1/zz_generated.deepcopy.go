@@ -89,6 +89,16 @@ func (in *CPUBurstConfig) DeepCopyInto(out *CPUBurstConfig) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.StartupBoostPercent != nil {
+		in, out := &in.StartupBoostPercent, &out.StartupBoostPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.StartupBoostSeconds != nil {
+		in, out := &in.StartupBoostSeconds, &out.StartupBoostSeconds
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUBurstConfig.
@@ -163,6 +173,96 @@ func (in *CPUQOSCfg) DeepCopy() *CPUQOSCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUSuppressAutoTuningStrategy) DeepCopyInto(out *CPUSuppressAutoTuningStrategy) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TargetLSCPUPSIPercent != nil {
+		in, out := &in.TargetLSCPUPSIPercent, &out.TargetLSCPUPSIPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TargetLSCPUThrottledPercent != nil {
+		in, out := &in.TargetLSCPUThrottledPercent, &out.TargetLSCPUThrottledPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TargetLSSchedLatencyMicroseconds != nil {
+		in, out := &in.TargetLSSchedLatencyMicroseconds, &out.TargetLSSchedLatencyMicroseconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ProportionalGainMilli != nil {
+		in, out := &in.ProportionalGainMilli, &out.ProportionalGainMilli
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IntegralGainMilli != nil {
+		in, out := &in.IntegralGainMilli, &out.IntegralGainMilli
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DerivativeGainMilli != nil {
+		in, out := &in.DerivativeGainMilli, &out.DerivativeGainMilli
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxStepPercent != nil {
+		in, out := &in.MaxStepPercent, &out.MaxStepPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinBECPUPercent != nil {
+		in, out := &in.MinBECPUPercent, &out.MinBECPUPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxBECPUPercent != nil {
+		in, out := &in.MaxBECPUPercent, &out.MaxBECPUPercent
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUSuppressAutoTuningStrategy.
+func (in *CPUSuppressAutoTuningStrategy) DeepCopy() *CPUSuppressAutoTuningStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUSuppressAutoTuningStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IRQAffinityProfile) DeepCopyInto(out *IRQAffinityProfile) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeExclusiveCPUs != nil {
+		in, out := &in.ExcludeExclusiveCPUs, &out.ExcludeExclusiveCPUs
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IRQAffinityProfile.
+func (in *IRQAffinityProfile) DeepCopy() *IRQAffinityProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(IRQAffinityProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemoryQOS) DeepCopyInto(out *MemoryQOS) {
 	*out = *in
@@ -244,6 +344,64 @@ func (in *MemoryQOSCfg) DeepCopy() *MemoryQOSCfg {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMAUsage) DeepCopyInto(out *NUMAUsage) {
+	*out = *in
+	in.Usage.DeepCopyInto(&out.Usage)
+	in.Free.DeepCopyInto(&out.Free)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMAUsage.
+func (in *NUMAUsage) DeepCopy() *NUMAUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMAUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQOS) DeepCopyInto(out *NetworkQOS) {
+	*out = *in
+	if in.DSCP != nil {
+		in, out := &in.DSCP, &out.DSCP
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQOS.
+func (in *NetworkQOS) DeepCopy() *NetworkQOS {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQOS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkQOSCfg) DeepCopyInto(out *NetworkQOSCfg) {
+	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
+	in.NetworkQOS.DeepCopyInto(&out.NetworkQOS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkQOSCfg.
+func (in *NetworkQOSCfg) DeepCopy() *NetworkQOSCfg {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkQOSCfg)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeMetric) DeepCopyInto(out *NodeMetric) {
 	*out = *in
@@ -301,6 +459,22 @@ func (in *NodeMetricCollectPolicy) DeepCopy() *NodeMetricCollectPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMetricCondition) DeepCopyInto(out *NodeMetricCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetricCondition.
+func (in *NodeMetricCondition) DeepCopy() *NodeMetricCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMetricCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeMetricInfo) DeepCopyInto(out *NodeMetricInfo) {
 	*out = *in
@@ -312,6 +486,14 @@ func (in *NodeMetricInfo) DeepCopyInto(out *NodeMetricInfo) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.SystemUsage.DeepCopyInto(&out.SystemUsage)
+	if in.NUMAUsages != nil {
+		in, out := &in.NUMAUsages, &out.NUMAUsages
+		*out = make([]NUMAUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetricInfo.
@@ -399,6 +581,13 @@ func (in *NodeMetricStatus) DeepCopyInto(out *NodeMetricStatus) {
 			}
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeMetricCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetricStatus.
@@ -417,7 +606,7 @@ func (in *NodeSLO) DeepCopyInto(out *NodeSLO) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSLO.
@@ -438,6 +627,22 @@ func (in *NodeSLO) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSLOCondition) DeepCopyInto(out *NodeSLOCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSLOCondition.
+func (in *NodeSLOCondition) DeepCopy() *NodeSLOCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSLOCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeSLOList) DeepCopyInto(out *NodeSLOList) {
 	*out = *in
@@ -512,6 +717,17 @@ func (in *NodeSLOSpec) DeepCopy() *NodeSLOSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeSLOStatus) DeepCopyInto(out *NodeSLOStatus) {
 	*out = *in
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeSLOCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSLOStatus.
@@ -524,6 +740,33 @@ func (in *NodeSLOStatus) DeepCopy() *NodeSLOStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeTuningProfile) DeepCopyInto(out *NodeTuningProfile) {
+	*out = *in
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IRQAffinity != nil {
+		in, out := &in.IRQAffinity, &out.IRQAffinity
+		*out = new(IRQAffinityProfile)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeTuningProfile.
+func (in *NodeTuningProfile) DeepCopy() *NodeTuningProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeTuningProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodMemoryQOSConfig) DeepCopyInto(out *PodMemoryQOSConfig) {
 	*out = *in
@@ -544,6 +787,7 @@ func (in *PodMemoryQOSConfig) DeepCopy() *PodMemoryQOSConfig {
 func (in *PodMetricInfo) DeepCopyInto(out *PodMetricInfo) {
 	*out = *in
 	in.PodUsage.DeepCopyInto(&out.PodUsage)
+	in.ReclaimableUsage.DeepCopyInto(&out.ReclaimableUsage)
 	if in.Extensions != nil {
 		in, out := &in.Extensions, &out.Extensions
 		*out = (*in).DeepCopy()
@@ -658,6 +902,11 @@ func (in *ResourceQOS) DeepCopyInto(out *ResourceQOS) {
 		*out = new(ResctrlQOSCfg)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NetworkQOS != nil {
+		in, out := &in.NetworkQOS, &out.NetworkQOS
+		*out = new(NetworkQOSCfg)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQOS.
@@ -723,6 +972,11 @@ func (in *ResourceThresholdStrategy) DeepCopyInto(out *ResourceThresholdStrategy
 		*out = new(int64)
 		**out = **in
 	}
+	if in.CPUSuppressAutoTuning != nil {
+		in, out := &in.CPUSuppressAutoTuning, &out.CPUSuppressAutoTuning
+		*out = new(CPUSuppressAutoTuningStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.MemoryEvictThresholdPercent != nil {
 		in, out := &in.MemoryEvictThresholdPercent, &out.MemoryEvictThresholdPercent
 		*out = new(int64)
@@ -778,6 +1032,11 @@ func (in *SystemStrategy) DeepCopyInto(out *SystemStrategy) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.NodeTuning != nil {
+		in, out := &in.NodeTuning, &out.NodeTuning
+		*out = new(NodeTuningProfile)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemStrategy.
@@ -43,7 +43,9 @@ import (
 	"github.com/koordinator-sh/koordinator/cmd/koord-manager/extensions"
 	extclient "github.com/koordinator-sh/koordinator/pkg/client"
 	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/reservation-controller/proactive"
 	sloconfig "github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+	"github.com/koordinator-sh/koordinator/pkg/slo-controller/federation"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/nodemetric"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/noderesource"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/nodeslo"
@@ -52,6 +54,8 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/util/fieldindex"
 	_ "github.com/koordinator-sh/koordinator/pkg/util/metrics/leadership"
 	"github.com/koordinator-sh/koordinator/pkg/webhook"
+	reservationmutating "github.com/koordinator-sh/koordinator/pkg/webhook/reservation/mutating"
+	reservationvalidating "github.com/koordinator-sh/koordinator/pkg/webhook/reservation/validating"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -79,9 +83,12 @@ func init() {
 }
 
 var controllerAddFuncs = map[string]func(manager.Manager) error{
-	"NodeMetric":   nodemetric.Add,
-	"NodeResource": noderesource.Add,
-	"NodeSLO":      nodeslo.Add,
+	"NodeMetric":           nodemetric.Add,
+	"NodeResource":         noderesource.Add,
+	"NodeSLO":              nodeslo.Add,
+	"NodeSLODrift":         nodeslo.AddDriftController,
+	"FederationExporter":   federation.Add,
+	"ProactiveReservation": proactive.Add,
 }
 
 func main() {
@@ -102,6 +109,10 @@ func main() {
 	flag.StringVar(&pprofAddr, "pprof-addr", ":8090", "The address the pprof binds to.")
 	flag.StringVar(&syncPeriodStr, "sync-period", "", "Determines the minimum frequency at which watched resources are reconciled.")
 	sloconfig.InitFlags(flag.CommandLine)
+	federation.InitFlags(flag.CommandLine)
+	nodeslo.InitFlags(flag.CommandLine)
+	reservationmutating.InitFlags(flag.CommandLine)
+	reservationvalidating.InitFlags(flag.CommandLine)
 
 	utilfeature.DefaultMutableFeatureGate.AddFlag(pflag.CommandLine)
 	klog.InitFlags(nil)
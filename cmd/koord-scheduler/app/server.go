@@ -398,10 +398,12 @@ func Setup(ctx context.Context, opts *options.Options, outOfTreeRegistryOptions
 	}
 
 	schedulerInternalHandler := &eventhandlers.SchedulerInternalHandlerImpl{
-		Scheduler: sched,
+		Scheduler:  sched,
+		NodeLister: cc.InformerFactory.Core().V1().Nodes().Lister(),
 	}
-	eventhandlers.AddScheduleEventHandler(sched, schedulerInternalHandler, frameworkExtenderFactory.KoordinatorSharedInformerFactory())
+	eventhandlers.AddScheduleEventHandler(sched, schedulerInternalHandler, frameworkExtenderFactory.KoordinatorClientSet(), frameworkExtenderFactory.KoordinatorSharedInformerFactory())
 	eventhandlers.AddReservationErrorHandler(sched, schedulerInternalHandler, frameworkExtenderFactory.KoordinatorClientSet(), frameworkExtenderFactory.KoordinatorSharedInformerFactory())
+	eventhandlers.AddQuotaSchedulingGateErrorHandler(sched)
 
 	return &cc, sched, frameworkExtenderFactory, nil
 }
@@ -32,6 +32,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/nodenumaresource"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/preferrednodes"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/reservation"
 
 	// Ensure metric package is initialized
@@ -49,6 +50,7 @@ var koordinatorPlugins = map[string]frameworkruntime.PluginFactory{
 	deviceshare.Name:                 deviceshare.New,
 	elasticquota.Name:                elasticquota.New,
 	compatibledefaultpreemption.Name: compatibledefaultpreemption.New,
+	preferrednodes.Name:              preferrednodes.New,
 }
 
 func flatten(plugins map[string]frameworkruntime.PluginFactory) []app.Option {
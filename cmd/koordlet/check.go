@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/check"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/config"
+)
+
+// runCheck runs the `koordlet check` subcommand, which exercises the enforcement paths of the koordlet on the node and
+// prints the capability report. It accepts the same flags as the koordlet, e.g. -cgroup-root-dir, and returns a
+// non-zero exit code if any check fails.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	config.NewConfiguration().InitFlags(fs)
+	_ = fs.Parse(args)
+
+	results := check.RunChecks(check.DefaultChecks())
+	if err := check.PrintReport(os.Stdout, results); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to print the report, err: %v\n", err)
+		return 1
+	}
+	if check.HasFailure(results) {
+		return 1
+	}
+	return 0
+}
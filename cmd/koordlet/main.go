@@ -34,15 +34,24 @@ import (
 	agent "github.com/koordinator-sh/koordinator/pkg/koordlet"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/config"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 )
 
 func init() {}
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(os.Args[2:]))
+	}
+
 	cfg := config.NewConfiguration()
 	cfg.InitFlags(flag.CommandLine)
 	flag.Parse()
 
+	if err := logs.Setup(cfg.LogConf); err != nil {
+		klog.Fatalf("Unable to setup logging: %v", err)
+	}
+
 	go wait.Forever(klog.Flush, 5*time.Second)
 	defer klog.Flush()
 
@@ -93,6 +102,9 @@ func main() {
 		if features.DefaultKoordletFeatureGate.Enabled(features.AuditEventsHTTPHandler) {
 			http.HandleFunc("/events", audit.HttpHandler())
 		}
+		if features.DefaultKoordletFeatureGate.Enabled(features.LogLevelHTTPHandler) {
+			http.HandleFunc("/debug/loglevel", logs.HttpHandler())
+		}
 		// http.HandleFunc("/healthz", d.HealthzHandler())
 		klog.Fatalf("Prometheus monitoring failed: %v", http.ListenAndServe(*options.ServerAddr, nil))
 	}()
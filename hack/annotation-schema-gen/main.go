@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// annotation-schema-gen writes the JSON Schema and OpenAPI files of the koordinator annotation protocols.
+func main() {
+	outputDir := flag.String("output-dir", "apis/extension/schemas", "the directory to write the schema files")
+	flag.Parse()
+
+	files, err := extension.MarshalAnnotationSchemas()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate annotation schemas, err: %v\n", err)
+		os.Exit(1)
+	}
+	if err = os.MkdirAll(*outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create output dir %s, err: %v\n", *outputDir, err)
+		os.Exit(1)
+	}
+	for name, data := range files {
+		if err = os.WriteFile(filepath.Join(*outputDir, name), data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s, err: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakePodDeviceAllocations implements PodDeviceAllocationInterface
+type FakePodDeviceAllocations struct {
+	Fake *FakeSchedulingV1alpha1
+	ns   string
+}
+
+var poddeviceallocationsResource = schema.GroupVersionResource{Group: "scheduling.koordinator.sh", Version: "v1alpha1", Resource: "poddeviceallocations"}
+
+var poddeviceallocationsKind = schema.GroupVersionKind{Group: "scheduling.koordinator.sh", Version: "v1alpha1", Kind: "PodDeviceAllocation"}
+
+// Get takes name of the podDeviceAllocation, and returns the corresponding podDeviceAllocation object, and an error if there is any.
+func (c *FakePodDeviceAllocations) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.PodDeviceAllocation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(poddeviceallocationsResource, c.ns, name), &v1alpha1.PodDeviceAllocation{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PodDeviceAllocation), err
+}
+
+// List takes label and field selectors, and returns the list of PodDeviceAllocations that match those selectors.
+func (c *FakePodDeviceAllocations) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.PodDeviceAllocationList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(poddeviceallocationsResource, poddeviceallocationsKind, c.ns, opts), &v1alpha1.PodDeviceAllocationList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.PodDeviceAllocationList{ListMeta: obj.(*v1alpha1.PodDeviceAllocationList).ListMeta}
+	for _, item := range obj.(*v1alpha1.PodDeviceAllocationList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested podDeviceAllocations.
+func (c *FakePodDeviceAllocations) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(poddeviceallocationsResource, c.ns, opts))
+}
+
+// Create takes the representation of a podDeviceAllocation and creates it.  Returns the server's representation of the podDeviceAllocation, and an error, if there is any.
+func (c *FakePodDeviceAllocations) Create(ctx context.Context, podDeviceAllocation *v1alpha1.PodDeviceAllocation, opts v1.CreateOptions) (result *v1alpha1.PodDeviceAllocation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(poddeviceallocationsResource, c.ns, podDeviceAllocation), &v1alpha1.PodDeviceAllocation{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PodDeviceAllocation), err
+}
+
+// Update takes the representation of a podDeviceAllocation and updates it. Returns the server's representation of the podDeviceAllocation, and an error, if there is any.
+func (c *FakePodDeviceAllocations) Update(ctx context.Context, podDeviceAllocation *v1alpha1.PodDeviceAllocation, opts v1.UpdateOptions) (result *v1alpha1.PodDeviceAllocation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(poddeviceallocationsResource, c.ns, podDeviceAllocation), &v1alpha1.PodDeviceAllocation{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PodDeviceAllocation), err
+}
+
+// Delete takes name of the podDeviceAllocation and deletes it. Returns an error if one occurs.
+func (c *FakePodDeviceAllocations) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(poddeviceallocationsResource, c.ns, name), &v1alpha1.PodDeviceAllocation{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakePodDeviceAllocations) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(poddeviceallocationsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.PodDeviceAllocationList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched podDeviceAllocation.
+func (c *FakePodDeviceAllocations) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PodDeviceAllocation, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(poddeviceallocationsResource, c.ns, name, pt, data, subresources...), &v1alpha1.PodDeviceAllocation{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.PodDeviceAllocation), err
+}
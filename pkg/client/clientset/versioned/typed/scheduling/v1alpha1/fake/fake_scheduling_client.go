@@ -32,6 +32,10 @@ func (c *FakeSchedulingV1alpha1) Devices() v1alpha1.DeviceInterface {
 	return &FakeDevices{c}
 }
 
+func (c *FakeSchedulingV1alpha1) PodDeviceAllocations(namespace string) v1alpha1.PodDeviceAllocationInterface {
+	return &FakePodDeviceAllocations{c, namespace}
+}
+
 func (c *FakeSchedulingV1alpha1) PodMigrationJobs() v1alpha1.PodMigrationJobInterface {
 	return &FakePodMigrationJobs{c}
 }
@@ -20,6 +20,8 @@ package v1alpha1
 
 type DeviceExpansion interface{}
 
+type PodDeviceAllocationExpansion interface{}
+
 type PodMigrationJobExpansion interface{}
 
 type ReservationExpansion interface{}
@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	scheme "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// PodDeviceAllocationsGetter has a method to return a PodDeviceAllocationInterface.
+// A group's client should implement this interface.
+type PodDeviceAllocationsGetter interface {
+	PodDeviceAllocations(namespace string) PodDeviceAllocationInterface
+}
+
+// PodDeviceAllocationInterface has methods to work with PodDeviceAllocation resources.
+type PodDeviceAllocationInterface interface {
+	Create(ctx context.Context, podDeviceAllocation *v1alpha1.PodDeviceAllocation, opts v1.CreateOptions) (*v1alpha1.PodDeviceAllocation, error)
+	Update(ctx context.Context, podDeviceAllocation *v1alpha1.PodDeviceAllocation, opts v1.UpdateOptions) (*v1alpha1.PodDeviceAllocation, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.PodDeviceAllocation, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.PodDeviceAllocationList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PodDeviceAllocation, err error)
+	PodDeviceAllocationExpansion
+}
+
+// podDeviceAllocations implements PodDeviceAllocationInterface
+type podDeviceAllocations struct {
+	client rest.Interface
+	ns     string
+}
+
+// newPodDeviceAllocations returns a PodDeviceAllocations
+func newPodDeviceAllocations(c *SchedulingV1alpha1Client, namespace string) *podDeviceAllocations {
+	return &podDeviceAllocations{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the podDeviceAllocation, and returns the corresponding podDeviceAllocation object, and an error if there is any.
+func (c *podDeviceAllocations) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.PodDeviceAllocation, err error) {
+	result = &v1alpha1.PodDeviceAllocation{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of PodDeviceAllocations that match those selectors.
+func (c *podDeviceAllocations) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.PodDeviceAllocationList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.PodDeviceAllocationList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested podDeviceAllocations.
+func (c *podDeviceAllocations) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a podDeviceAllocation and creates it.  Returns the server's representation of the podDeviceAllocation, and an error, if there is any.
+func (c *podDeviceAllocations) Create(ctx context.Context, podDeviceAllocation *v1alpha1.PodDeviceAllocation, opts v1.CreateOptions) (result *v1alpha1.PodDeviceAllocation, err error) {
+	result = &v1alpha1.PodDeviceAllocation{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(podDeviceAllocation).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a podDeviceAllocation and updates it. Returns the server's representation of the podDeviceAllocation, and an error, if there is any.
+func (c *podDeviceAllocations) Update(ctx context.Context, podDeviceAllocation *v1alpha1.PodDeviceAllocation, opts v1.UpdateOptions) (result *v1alpha1.PodDeviceAllocation, err error) {
+	result = &v1alpha1.PodDeviceAllocation{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		Name(podDeviceAllocation.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(podDeviceAllocation).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the podDeviceAllocation and deletes it. Returns an error if one occurs.
+func (c *podDeviceAllocations) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *podDeviceAllocations) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched podDeviceAllocation.
+func (c *podDeviceAllocations) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.PodDeviceAllocation, err error) {
+	result = &v1alpha1.PodDeviceAllocation{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("poddeviceallocations").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -26,6 +26,8 @@ import (
 type Interface interface {
 	// Devices returns a DeviceInformer.
 	Devices() DeviceInformer
+	// PodDeviceAllocations returns a PodDeviceAllocationInformer.
+	PodDeviceAllocations() PodDeviceAllocationInformer
 	// PodMigrationJobs returns a PodMigrationJobInformer.
 	PodMigrationJobs() PodMigrationJobInformer
 	// Reservations returns a ReservationInformer.
@@ -48,6 +50,11 @@ func (v *version) Devices() DeviceInformer {
 	return &deviceInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// PodDeviceAllocations returns a PodDeviceAllocationInformer.
+func (v *version) PodDeviceAllocations() PodDeviceAllocationInformer {
+	return &podDeviceAllocationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // PodMigrationJobs returns a PodMigrationJobInformer.
 func (v *version) PodMigrationJobs() PodMigrationJobInformer {
 	return &podMigrationJobInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -22,6 +22,14 @@ package v1alpha1
 // DeviceLister.
 type DeviceListerExpansion interface{}
 
+// PodDeviceAllocationListerExpansion allows custom methods to be added to
+// PodDeviceAllocationLister.
+type PodDeviceAllocationListerExpansion interface{}
+
+// PodDeviceAllocationNamespaceListerExpansion allows custom methods to be added to
+// PodDeviceAllocationNamespaceLister.
+type PodDeviceAllocationNamespaceListerExpansion interface{}
+
 // PodMigrationJobListerExpansion allows custom methods to be added to
 // PodMigrationJobLister.
 type PodMigrationJobListerExpansion interface{}
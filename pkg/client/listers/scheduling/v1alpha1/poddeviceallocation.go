@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodDeviceAllocationLister helps list PodDeviceAllocations.
+// All objects returned here must be treated as read-only.
+type PodDeviceAllocationLister interface {
+	// List lists all PodDeviceAllocations in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.PodDeviceAllocation, err error)
+	// PodDeviceAllocations returns an object that can list and get PodDeviceAllocations.
+	PodDeviceAllocations(namespace string) PodDeviceAllocationNamespaceLister
+	PodDeviceAllocationListerExpansion
+}
+
+// podDeviceAllocationLister implements the PodDeviceAllocationLister interface.
+type podDeviceAllocationLister struct {
+	indexer cache.Indexer
+}
+
+// NewPodDeviceAllocationLister returns a new PodDeviceAllocationLister.
+func NewPodDeviceAllocationLister(indexer cache.Indexer) PodDeviceAllocationLister {
+	return &podDeviceAllocationLister{indexer: indexer}
+}
+
+// List lists all PodDeviceAllocations in the indexer.
+func (s *podDeviceAllocationLister) List(selector labels.Selector) (ret []*v1alpha1.PodDeviceAllocation, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PodDeviceAllocation))
+	})
+	return ret, err
+}
+
+// PodDeviceAllocations returns an object that can list and get PodDeviceAllocations.
+func (s *podDeviceAllocationLister) PodDeviceAllocations(namespace string) PodDeviceAllocationNamespaceLister {
+	return podDeviceAllocationNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// PodDeviceAllocationNamespaceLister helps list and get PodDeviceAllocations.
+// All objects returned here must be treated as read-only.
+type PodDeviceAllocationNamespaceLister interface {
+	// List lists all PodDeviceAllocations in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.PodDeviceAllocation, err error)
+	// Get retrieves the PodDeviceAllocation from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.PodDeviceAllocation, error)
+	PodDeviceAllocationNamespaceListerExpansion
+}
+
+// podDeviceAllocationNamespaceLister implements the PodDeviceAllocationNamespaceLister
+// interface.
+type podDeviceAllocationNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all PodDeviceAllocations in the indexer for a given namespace.
+func (s podDeviceAllocationNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.PodDeviceAllocation, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.PodDeviceAllocation))
+	})
+	return ret, err
+}
+
+// Get retrieves the PodDeviceAllocation from the indexer for a given namespace and name.
+func (s podDeviceAllocationNamespaceLister) Get(name string) (*v1alpha1.PodDeviceAllocation, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("poddeviceallocation"), name)
+	}
+	return obj.(*v1alpha1.PodDeviceAllocation), nil
+}
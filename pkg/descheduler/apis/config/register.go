@@ -48,6 +48,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DeschedulerConfiguration{},
 		&MigrationControllerArgs{},
 		&LowNodeLoadArgs{},
+		&OrphanedReservationArgs{},
+		&GPUCompactionArgs{},
 	)
 	return nil
 }
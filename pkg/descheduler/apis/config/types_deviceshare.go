@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GPUCompactionArgs holds arguments used to configure the GPUCompaction plugin.
+type GPUCompactionArgs struct {
+	metav1.TypeMeta
+
+	// DryRun means only detect the fragmented GPUs and the pods to migrate, but don't migrate them.
+	DryRun bool
+
+	// NodeSelector selects the nodes to compact.
+	NodeSelector *metav1.LabelSelector
+
+	// EvictableNamespaces carries a list of included/excluded namespaces of the pods to migrate.
+	EvictableNamespaces *Namespaces
+
+	// MinFragmentedGPUs is the minimum number of partially allocated GPUs on a node to trigger the compaction.
+	MinFragmentedGPUs int32
+
+	// MaxMigratingPodsPerNode limits the number of pods migrated from a node in one round, zero means no limit.
+	MaxMigratingPodsPerNode int32
+}
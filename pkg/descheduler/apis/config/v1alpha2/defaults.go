@@ -41,6 +41,10 @@ const (
 	defaultMigrationJobEvictionPolicy = migrationevictor.NativeEvictorName
 	defaultMigrationEvictQPS          = 10
 	defaultMigrationEvictBurst        = 1
+
+	defaultOrphanedReservationGracePeriod = 5 * time.Minute
+
+	defaultGPUCompactionMinFragmentedGPUs = 2
 )
 
 var (
@@ -247,3 +251,26 @@ func SetDefaults_LowNodeLoadArgs(obj *LowNodeLoadArgs) {
 		obj.AnomalyCondition.ConsecutiveAbnormalities = defaultLoadAnomalyCondition.ConsecutiveAbnormalities
 	}
 }
+
+// SetDefaults_OrphanedReservationArgs sets the default parameters for OrphanedReservation plugin.
+func SetDefaults_OrphanedReservationArgs(obj *OrphanedReservationArgs) {
+	if obj.DryRun == nil {
+		obj.DryRun = pointer.Bool(false)
+	}
+	if obj.GracePeriod == nil {
+		obj.GracePeriod = &metav1.Duration{Duration: defaultOrphanedReservationGracePeriod}
+	}
+}
+
+// SetDefaults_GPUCompactionArgs sets the default parameters for GPUCompaction plugin.
+func SetDefaults_GPUCompactionArgs(obj *GPUCompactionArgs) {
+	if obj.DryRun == nil {
+		obj.DryRun = pointer.Bool(false)
+	}
+	if obj.MinFragmentedGPUs == nil {
+		obj.MinFragmentedGPUs = pointer.Int32(defaultGPUCompactionMinFragmentedGPUs)
+	}
+	if obj.MaxMigratingPodsPerNode == nil {
+		obj.MaxMigratingPodsPerNode = pointer.Int32(0)
+	}
+}
@@ -56,6 +56,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DeschedulerConfiguration{},
 		&MigrationControllerArgs{},
 		&LowNodeLoadArgs{},
+		&OrphanedReservationArgs{},
+		&GPUCompactionArgs{},
 	)
 
 	return nil
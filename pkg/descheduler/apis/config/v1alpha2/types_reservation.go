@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OrphanedReservationArgs holds arguments used to configure the OrphanedReservation plugin.
+type OrphanedReservationArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// DryRun means only detect the orphaned reservations and record events, but don't expire them.
+	// Default is false
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// GracePeriod is the minimum age of a reservation before it can be considered orphaned.
+	// It protects reservations that are created before their owner workloads.
+	// Default is 5 minutes.
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+
+	// AllowList contains the names of reservations that are never cleaned up.
+	AllowList []string `json:"allowList,omitempty"`
+
+	// AllowListSelector selects the reservations that are never cleaned up.
+	AllowListSelector *metav1.LabelSelector `json:"allowListSelector,omitempty"`
+}
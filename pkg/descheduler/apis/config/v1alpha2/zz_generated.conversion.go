@@ -49,6 +49,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*GPUCompactionArgs)(nil), (*config.GPUCompactionArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_GPUCompactionArgs_To_config_GPUCompactionArgs(a.(*GPUCompactionArgs), b.(*config.GPUCompactionArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.GPUCompactionArgs)(nil), (*GPUCompactionArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_GPUCompactionArgs_To_v1alpha2_GPUCompactionArgs(a.(*config.GPUCompactionArgs), b.(*GPUCompactionArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*LoadAnomalyCondition)(nil), (*config.LoadAnomalyCondition)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(a.(*LoadAnomalyCondition), b.(*config.LoadAnomalyCondition), scope)
 	}); err != nil {
@@ -109,6 +119,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*OrphanedReservationArgs)(nil), (*config.OrphanedReservationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha2_OrphanedReservationArgs_To_config_OrphanedReservationArgs(a.(*OrphanedReservationArgs), b.(*config.OrphanedReservationArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.OrphanedReservationArgs)(nil), (*OrphanedReservationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_OrphanedReservationArgs_To_v1alpha2_OrphanedReservationArgs(a.(*config.OrphanedReservationArgs), b.(*OrphanedReservationArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Plugin)(nil), (*config.Plugin)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha2_Plugin_To_config_Plugin(a.(*Plugin), b.(*config.Plugin), scope)
 	}); err != nil {
@@ -286,6 +306,46 @@ func Convert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in *config
 	return autoConvert_config_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(in, out, s)
 }
 
+func autoConvert_v1alpha2_GPUCompactionArgs_To_config_GPUCompactionArgs(in *GPUCompactionArgs, out *config.GPUCompactionArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_bool_To_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	out.EvictableNamespaces = (*config.Namespaces)(unsafe.Pointer(in.EvictableNamespaces))
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MinFragmentedGPUs, &out.MinFragmentedGPUs, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxMigratingPodsPerNode, &out.MaxMigratingPodsPerNode, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha2_GPUCompactionArgs_To_config_GPUCompactionArgs is an autogenerated conversion function.
+func Convert_v1alpha2_GPUCompactionArgs_To_config_GPUCompactionArgs(in *GPUCompactionArgs, out *config.GPUCompactionArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_GPUCompactionArgs_To_config_GPUCompactionArgs(in, out, s)
+}
+
+func autoConvert_config_GPUCompactionArgs_To_v1alpha2_GPUCompactionArgs(in *config.GPUCompactionArgs, out *GPUCompactionArgs, s conversion.Scope) error {
+	if err := v1.Convert_bool_To_Pointer_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	out.NodeSelector = (*v1.LabelSelector)(unsafe.Pointer(in.NodeSelector))
+	out.EvictableNamespaces = (*Namespaces)(unsafe.Pointer(in.EvictableNamespaces))
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MinFragmentedGPUs, &out.MinFragmentedGPUs, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxMigratingPodsPerNode, &out.MaxMigratingPodsPerNode, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_GPUCompactionArgs_To_v1alpha2_GPUCompactionArgs is an autogenerated conversion function.
+func Convert_config_GPUCompactionArgs_To_v1alpha2_GPUCompactionArgs(in *config.GPUCompactionArgs, out *GPUCompactionArgs, s conversion.Scope) error {
+	return autoConvert_config_GPUCompactionArgs_To_v1alpha2_GPUCompactionArgs(in, out, s)
+}
+
 func autoConvert_v1alpha2_LoadAnomalyCondition_To_config_LoadAnomalyCondition(in *LoadAnomalyCondition, out *config.LoadAnomalyCondition, s conversion.Scope) error {
 	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.Timeout, &out.Timeout, s); err != nil {
 		return err
@@ -530,6 +590,40 @@ func Convert_config_Namespaces_To_v1alpha2_Namespaces(in *config.Namespaces, out
 	return autoConvert_config_Namespaces_To_v1alpha2_Namespaces(in, out, s)
 }
 
+func autoConvert_v1alpha2_OrphanedReservationArgs_To_config_OrphanedReservationArgs(in *OrphanedReservationArgs, out *config.OrphanedReservationArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_bool_To_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_v1_Duration_To_v1_Duration(&in.GracePeriod, &out.GracePeriod, s); err != nil {
+		return err
+	}
+	out.AllowList = *(*[]string)(unsafe.Pointer(&in.AllowList))
+	out.AllowListSelector = (*v1.LabelSelector)(unsafe.Pointer(in.AllowListSelector))
+	return nil
+}
+
+// Convert_v1alpha2_OrphanedReservationArgs_To_config_OrphanedReservationArgs is an autogenerated conversion function.
+func Convert_v1alpha2_OrphanedReservationArgs_To_config_OrphanedReservationArgs(in *OrphanedReservationArgs, out *config.OrphanedReservationArgs, s conversion.Scope) error {
+	return autoConvert_v1alpha2_OrphanedReservationArgs_To_config_OrphanedReservationArgs(in, out, s)
+}
+
+func autoConvert_config_OrphanedReservationArgs_To_v1alpha2_OrphanedReservationArgs(in *config.OrphanedReservationArgs, out *OrphanedReservationArgs, s conversion.Scope) error {
+	if err := v1.Convert_bool_To_Pointer_bool(&in.DryRun, &out.DryRun, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_v1_Duration_To_Pointer_v1_Duration(&in.GracePeriod, &out.GracePeriod, s); err != nil {
+		return err
+	}
+	out.AllowList = *(*[]string)(unsafe.Pointer(&in.AllowList))
+	out.AllowListSelector = (*v1.LabelSelector)(unsafe.Pointer(in.AllowListSelector))
+	return nil
+}
+
+// Convert_config_OrphanedReservationArgs_To_v1alpha2_OrphanedReservationArgs is an autogenerated conversion function.
+func Convert_config_OrphanedReservationArgs_To_v1alpha2_OrphanedReservationArgs(in *config.OrphanedReservationArgs, out *OrphanedReservationArgs, s conversion.Scope) error {
+	return autoConvert_config_OrphanedReservationArgs_To_v1alpha2_OrphanedReservationArgs(in, out, s)
+}
+
 func autoConvert_v1alpha2_Plugin_To_config_Plugin(in *Plugin, out *config.Plugin, s conversion.Scope) error {
 	out.Name = in.Name
 	return nil
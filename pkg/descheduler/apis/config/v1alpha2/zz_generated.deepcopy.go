@@ -117,6 +117,56 @@ func (in *DeschedulerProfile) DeepCopy() *DeschedulerProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUCompactionArgs) DeepCopyInto(out *GPUCompactionArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictableNamespaces != nil {
+		in, out := &in.EvictableNamespaces, &out.EvictableNamespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinFragmentedGPUs != nil {
+		in, out := &in.MinFragmentedGPUs, &out.MinFragmentedGPUs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxMigratingPodsPerNode != nil {
+		in, out := &in.MaxMigratingPodsPerNode, &out.MaxMigratingPodsPerNode
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUCompactionArgs.
+func (in *GPUCompactionArgs) DeepCopy() *GPUCompactionArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUCompactionArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GPUCompactionArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadAnomalyCondition) DeepCopyInto(out *LoadAnomalyCondition) {
 	*out = *in
@@ -407,6 +457,51 @@ func (in ObjectLimiterMap) DeepCopy() ObjectLimiterMap {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedReservationArgs) DeepCopyInto(out *OrphanedReservationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(bool)
+		**out = **in
+	}
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AllowList != nil {
+		in, out := &in.AllowList, &out.AllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowListSelector != nil {
+		in, out := &in.AllowListSelector, &out.AllowListSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedReservationArgs.
+func (in *OrphanedReservationArgs) DeepCopy() *OrphanedReservationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedReservationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OrphanedReservationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Plugin) DeepCopyInto(out *Plugin) {
 	*out = *in
@@ -30,8 +30,10 @@ import (
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
 	scheme.AddTypeDefaultingFunc(&DeschedulerConfiguration{}, func(obj interface{}) { SetObjectDefaults_DeschedulerConfiguration(obj.(*DeschedulerConfiguration)) })
+	scheme.AddTypeDefaultingFunc(&GPUCompactionArgs{}, func(obj interface{}) { SetObjectDefaults_GPUCompactionArgs(obj.(*GPUCompactionArgs)) })
 	scheme.AddTypeDefaultingFunc(&LowNodeLoadArgs{}, func(obj interface{}) { SetObjectDefaults_LowNodeLoadArgs(obj.(*LowNodeLoadArgs)) })
 	scheme.AddTypeDefaultingFunc(&MigrationControllerArgs{}, func(obj interface{}) { SetObjectDefaults_MigrationControllerArgs(obj.(*MigrationControllerArgs)) })
+	scheme.AddTypeDefaultingFunc(&OrphanedReservationArgs{}, func(obj interface{}) { SetObjectDefaults_OrphanedReservationArgs(obj.(*OrphanedReservationArgs)) })
 	return nil
 }
 
@@ -39,6 +41,10 @@ func SetObjectDefaults_DeschedulerConfiguration(in *DeschedulerConfiguration) {
 	SetDefaults_DeschedulerConfiguration(in)
 }
 
+func SetObjectDefaults_GPUCompactionArgs(in *GPUCompactionArgs) {
+	SetDefaults_GPUCompactionArgs(in)
+}
+
 func SetObjectDefaults_LowNodeLoadArgs(in *LowNodeLoadArgs) {
 	SetDefaults_LowNodeLoadArgs(in)
 }
@@ -46,3 +52,7 @@ func SetObjectDefaults_LowNodeLoadArgs(in *LowNodeLoadArgs) {
 func SetObjectDefaults_MigrationControllerArgs(in *MigrationControllerArgs) {
 	SetDefaults_MigrationControllerArgs(in)
 }
+
+func SetObjectDefaults_OrphanedReservationArgs(in *OrphanedReservationArgs) {
+	SetDefaults_OrphanedReservationArgs(in)
+}
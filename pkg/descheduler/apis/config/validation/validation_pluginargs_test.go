@@ -104,3 +104,111 @@ func TestValidateMigrationControllerArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOrphanedReservationArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *v1alpha2.OrphanedReservationArgs
+		wantErr bool
+	}{
+		{
+			name:    "default args",
+			args:    &v1alpha2.OrphanedReservationArgs{},
+			wantErr: false,
+		},
+		{
+			name: "invalid gracePeriod",
+			args: &v1alpha2.OrphanedReservationArgs{
+				GracePeriod: &metav1.Duration{Duration: -1 * time.Minute},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty name in allowList",
+			args: &v1alpha2.OrphanedReservationArgs{
+				AllowList: []string{"test", ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid allowListSelector",
+			args: &v1alpha2.OrphanedReservationArgs{
+				AllowListSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test/a/b/c": "123",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1alpha2.SetDefaults_OrphanedReservationArgs(tt.args)
+			args := &deschedulerconfig.OrphanedReservationArgs{}
+			assert.NoError(t, v1alpha2.Convert_v1alpha2_OrphanedReservationArgs_To_config_OrphanedReservationArgs(tt.args, args, nil))
+			if err := ValidateOrphanedReservationArgs(nil, args); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOrphanedReservationArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGPUCompactionArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *v1alpha2.GPUCompactionArgs
+		wantErr bool
+	}{
+		{
+			name:    "default args",
+			args:    &v1alpha2.GPUCompactionArgs{},
+			wantErr: false,
+		},
+		{
+			name: "invalid minFragmentedGPUs",
+			args: &v1alpha2.GPUCompactionArgs{
+				MinFragmentedGPUs: pointer.Int32(1),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid maxMigratingPodsPerNode",
+			args: &v1alpha2.GPUCompactionArgs{
+				MaxMigratingPodsPerNode: pointer.Int32(-1),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid nodeSelector",
+			args: &v1alpha2.GPUCompactionArgs{
+				NodeSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test/a/b/c": "123",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "both include and exclude namespaces",
+			args: &v1alpha2.GPUCompactionArgs{
+				EvictableNamespaces: &v1alpha2.Namespaces{
+					Include: []string{"a"},
+					Exclude: []string{"b"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1alpha2.SetDefaults_GPUCompactionArgs(tt.args)
+			args := &deschedulerconfig.GPUCompactionArgs{}
+			assert.NoError(t, v1alpha2.Convert_v1alpha2_GPUCompactionArgs_To_config_GPUCompactionArgs(tt.args, args, nil))
+			if err := ValidateGPUCompactionArgs(nil, args); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateGPUCompactionArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
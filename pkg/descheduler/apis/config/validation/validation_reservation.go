@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+)
+
+func ValidateOrphanedReservationArgs(path *field.Path, args *deschedulerconfig.OrphanedReservationArgs) error {
+	var allErrs field.ErrorList
+
+	if args.GracePeriod.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("gracePeriod"), args.GracePeriod, "gracePeriod must be greater than or equal to 0"))
+	}
+
+	for i, name := range args.AllowList {
+		if name == "" {
+			allErrs = append(allErrs, field.Invalid(path.Child("allowList").Index(i), name, "reservation name must not be empty"))
+		}
+	}
+
+	if args.AllowListSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(args.AllowListSelector, path.Child("allowListSelector"))...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return allErrs.ToAggregate()
+}
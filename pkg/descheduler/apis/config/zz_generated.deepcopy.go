@@ -122,6 +122,41 @@ func (in *Float64OrString) DeepCopy() *Float64OrString {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUCompactionArgs) DeepCopyInto(out *GPUCompactionArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EvictableNamespaces != nil {
+		in, out := &in.EvictableNamespaces, &out.EvictableNamespaces
+		*out = new(Namespaces)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUCompactionArgs.
+func (in *GPUCompactionArgs) DeepCopy() *GPUCompactionArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUCompactionArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GPUCompactionArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadAnomalyCondition) DeepCopyInto(out *LoadAnomalyCondition) {
 	*out = *in
@@ -369,6 +404,42 @@ func (in ObjectLimiterMap) DeepCopy() ObjectLimiterMap {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedReservationArgs) DeepCopyInto(out *OrphanedReservationArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.GracePeriod = in.GracePeriod
+	if in.AllowList != nil {
+		in, out := &in.AllowList, &out.AllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowListSelector != nil {
+		in, out := &in.AllowListSelector, &out.AllowListSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedReservationArgs.
+func (in *OrphanedReservationArgs) DeepCopy() *OrphanedReservationArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedReservationArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OrphanedReservationArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Plugin) DeepCopyInto(out *Plugin) {
 	*out = *in
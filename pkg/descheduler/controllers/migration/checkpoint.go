@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration/util"
+)
+
+// defaultCheckpointTimeout is how long the job waits for koordlet to checkpoint the pod before
+// falling back to evict the pod directly.
+const defaultCheckpointTimeout = 5 * time.Minute
+
+// checkpointPod requests koordlet to checkpoint the containers of the pod before it is evicted if both the job
+// and the pod opt in. Any failure of the checkpoint falls back to the plain eviction, i.e. kill and recreate.
+// Nothing restores the pod from the checkpoint on the target node yet, so the checkpointed pod is never evicted,
+// which would lose the checkpointed state, and the job is aborted with the archives recorded for the restore.
+// It returns true when the pod can be evicted.
+func (r *Reconciler) checkpointPod(ctx context.Context, job *sev1alpha1.PodMigrationJob, pod *corev1.Pod) (bool, reconcile.Result, error) {
+	if !extension.IsMigrationCheckpointRequested(job.Annotations) || !extension.IsCheckpointEnabled(pod.Annotations) {
+		return true, reconcile.Result{}, nil
+	}
+
+	_, cond := util.GetCondition(&job.Status, sev1alpha1.PodMigrationJobConditionCheckpoint)
+	if cond != nil && cond.Reason != sev1alpha1.PodMigrationJobReasonCheckpointing {
+		return true, reconcile.Result{}, nil
+	}
+
+	if cond == nil {
+		klog.V(4).Infof("MigrationJob %s requests to checkpoint Pod %q", job.Name, klog.KObj(pod))
+		newPod := pod.DeepCopy()
+		if newPod.Annotations == nil {
+			newPod.Annotations = map[string]string{}
+		}
+		newPod.Annotations[extension.AnnotationCheckpointRequest] = job.Name
+		if err := r.Client.Patch(ctx, newPod, client.MergeFrom(pod)); err != nil {
+			klog.Errorf("Failed to request checkpoint of Pod %q, MigrationJob: %s, err: %v", klog.KObj(pod), job.Name, err)
+			return false, reconcile.Result{}, err
+		}
+		cond = &sev1alpha1.PodMigrationJobCondition{
+			Type:   sev1alpha1.PodMigrationJobConditionCheckpoint,
+			Status: sev1alpha1.PodMigrationJobConditionStatusFalse,
+			Reason: sev1alpha1.PodMigrationJobReasonCheckpointing,
+		}
+		err := r.updateCondition(ctx, job, cond)
+		if err == nil {
+			r.eventRecorder.Eventf(job, nil, corev1.EventTypeNormal, sev1alpha1.PodMigrationJobReasonCheckpointing, "Migrating", "Waiting for Pod %q checkpointed", klog.KObj(pod))
+		}
+		return false, reconcile.Result{RequeueAfter: defaultRequeueAfter}, err
+	}
+
+	status, err := extension.GetCheckpointStatus(pod.Annotations)
+	if err != nil {
+		klog.Warningf("Failed to parse checkpoint status of Pod %q, MigrationJob: %s, err: %v", klog.KObj(pod), job.Name, err)
+		status = nil
+	}
+	if status == nil || status.Request != job.Name {
+		if r.clock.Since(cond.LastTransitionTime.Time) < defaultCheckpointTimeout {
+			return false, reconcile.Result{RequeueAfter: defaultRequeueAfter}, nil
+		}
+		status = &extension.CheckpointStatus{
+			Phase:   extension.CheckpointFailed,
+			Message: fmt.Sprintf("timeout after %v", defaultCheckpointTimeout),
+		}
+	}
+
+	if status.Phase == extension.CheckpointSucceeded {
+		archives := make([]string, 0, len(status.Containers))
+		for _, c := range status.Containers {
+			archives = append(archives, fmt.Sprintf("%s=%s", c.Name, c.Archive))
+		}
+		cond = &sev1alpha1.PodMigrationJobCondition{
+			Type:    sev1alpha1.PodMigrationJobConditionCheckpoint,
+			Status:  sev1alpha1.PodMigrationJobConditionStatusTrue,
+			Reason:  sev1alpha1.PodMigrationJobReasonCheckpointComplete,
+			Message: fmt.Sprintf("Pod %q checkpointed on node %q, archives: %s", klog.KObj(pod), pod.Spec.NodeName, strings.Join(archives, ",")),
+		}
+		err = r.updateCondition(ctx, job, cond)
+		if err != nil {
+			return false, reconcile.Result{}, err
+		}
+		r.eventRecorder.Eventf(job, nil, corev1.EventTypeNormal, sev1alpha1.PodMigrationJobReasonCheckpointComplete, "Migrating", "%s", cond.Message)
+		return false, reconcile.Result{}, r.abortJobByCheckpointComplete(ctx, job, cond.Message)
+	}
+
+	cond = &sev1alpha1.PodMigrationJobCondition{
+		Type:    sev1alpha1.PodMigrationJobConditionCheckpoint,
+		Status:  sev1alpha1.PodMigrationJobConditionStatusFalse,
+		Reason:  sev1alpha1.PodMigrationJobReasonCheckpointFailed,
+		Message: fmt.Sprintf("Failed to checkpoint Pod %q, phase: %s, message: %s, fall back to evict it", klog.KObj(pod), status.Phase, status.Message),
+	}
+	err = r.updateCondition(ctx, job, cond)
+	if err == nil {
+		r.eventRecorder.Eventf(job, nil, corev1.EventTypeWarning, sev1alpha1.PodMigrationJobReasonCheckpointFailed, "Migrating", "%s", cond.Message)
+	}
+	return err == nil, reconcile.Result{}, err
+}
+
+func (r *Reconciler) abortJobByCheckpointComplete(ctx context.Context, job *sev1alpha1.PodMigrationJob, msg string) error {
+	if err := r.deleteReservation(ctx, job); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	job.Status.Phase = sev1alpha1.PodMigrationJobFailed
+	job.Status.Reason = sev1alpha1.PodMigrationJobReasonCheckpointComplete
+	job.Status.Message = fmt.Sprintf("Abort job without evicting the checkpointed Pod since the restore is not supported, %s", msg)
+	err := r.Client.Status().Update(ctx, job)
+	if err == nil {
+		r.eventRecorder.Eventf(job, nil, corev1.EventTypeWarning, sev1alpha1.PodMigrationJobReasonCheckpointComplete, "Migrating", job.Status.Message)
+	}
+	return err
+}
@@ -27,6 +27,7 @@ import (
 	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -61,6 +62,8 @@ import (
 const (
 	Name                = names.MigrationController
 	defaultRequeueAfter = 3 * time.Second
+	// defaultPreferredNodesTTL is how long the scheduler honors the preferred nodes hint of an evicted pod
+	defaultPreferredNodesTTL = 5 * time.Minute
 )
 
 var (
@@ -777,6 +780,10 @@ func (r *Reconciler) evictPod(ctx context.Context, job *sev1alpha1.PodMigrationJ
 	if job.Spec.DeleteOptions == nil {
 		job.Spec.DeleteOptions = r.args.DefaultDeleteOptions
 	}
+	if checkpointed, result, err := r.checkpointPod(ctx, job, pod); err != nil || !checkpointed {
+		return false, result, err
+	}
+	r.markPreferredNodes(ctx, job, pod)
 	err = r.evictorInterpreter.Evict(ctx, job, pod)
 	if err != nil {
 		r.eventRecorder.Eventf(job, nil, corev1.EventTypeWarning, sev1alpha1.PodMigrationJobReasonEvicting, "Migrating", "Failed evict Pod %q caused by %v", podNamespacedName, err)
@@ -798,6 +805,24 @@ func (r *Reconciler) evictPod(ctx context.Context, job *sev1alpha1.PodMigrationJ
 	return false, reconcile.Result{RequeueAfter: defaultRequeueAfter}, err
 }
 
+// markPreferredNodes copies the preferred nodes hint of the job to the pod before evicting it, so that the scheduler
+// prefers these nodes for the following pods of its controller for a while.
+func (r *Reconciler) markPreferredNodes(ctx context.Context, job *sev1alpha1.PodMigrationJob, pod *corev1.Pod) {
+	preferredNodes, err := extension.GetPreferredNodes(job.Annotations)
+	if err != nil || preferredNodes == nil || len(preferredNodes.Nodes) == 0 {
+		return
+	}
+	expireTime := metav1.NewTime(r.clock.Now().Add(defaultPreferredNodesTTL))
+	preferredNodes.ExpireTime = &expireTime
+	newPod := pod.DeepCopy()
+	if err := extension.SetPreferredNodes(newPod, preferredNodes); err != nil {
+		return
+	}
+	if err := r.Client.Patch(ctx, newPod, client.MergeFrom(pod)); err != nil {
+		klog.Warningf("Failed to mark the preferred nodes on Pod %q, MigrationJob: %s, err: %v", klog.KObj(pod), job.Name, err)
+	}
+}
+
 func (r *Reconciler) prepareJobWithReservationScheduleSuccess(ctx context.Context, job *sev1alpha1.PodMigrationJob, reservationObj reservation.Object) error {
 	scheduledNodeName := reservationObj.GetScheduledNodeName()
 	if scheduledNodeName == "" || job.Status.NodeName != "" {
@@ -40,6 +40,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/v1alpha2"
@@ -576,6 +577,175 @@ func TestEvictPod(t *testing.T) {
 	assert.Equal(t, expectCond, cond)
 }
 
+func TestEvictPodWithPreferredNodes(t *testing.T) {
+	reconciler := newTestReconciler()
+	fakeClock := clock.NewFakeClock(time.Now())
+	reconciler.clock = fakeClock
+	reconciler.evictorInterpreter = fakeEvictionInterpreter{}
+
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			CreationTimestamp: metav1.Time{Time: time.Now()},
+			Annotations: map[string]string{
+				extension.AnnotationPreferredNodes: `{"nodes":["node-1","node-2"]}`,
+			},
+		},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{
+				Namespace: "default",
+				Name:      "test-pod",
+			},
+		},
+	}
+	assert.Nil(t, reconciler.Create(context.TODO(), job))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+		},
+	}
+	assert.Nil(t, reconciler.Client.Create(context.TODO(), pod))
+
+	evicted, _, err := reconciler.evictPod(context.TODO(), job)
+	assert.False(t, evicted)
+	assert.Nil(t, err)
+
+	gotPod := &corev1.Pod{}
+	assert.Nil(t, reconciler.Client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "test-pod"}, gotPod))
+	preferredNodes, err := extension.GetPreferredNodes(gotPod.Annotations)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"node-1", "node-2"}, preferredNodes.Nodes)
+	assert.NotNil(t, preferredNodes.ExpireTime)
+	assert.True(t, preferredNodes.IsExpired(fakeClock.Now().Add(defaultPreferredNodesTTL)))
+	assert.False(t, preferredNodes.IsExpired(fakeClock.Now()))
+}
+
+func TestEvictPodWithCheckpoint(t *testing.T) {
+	tests := []struct {
+		name             string
+		checkpointStatus *extension.CheckpointStatus
+		timeout          bool
+		wantCondStatus   sev1alpha1.PodMigrationJobConditionStatus
+		wantCondReason   string
+		wantAborted      bool
+	}{
+		{
+			name: "checkpoint succeeded",
+			checkpointStatus: &extension.CheckpointStatus{
+				Request: "test",
+				Phase:   extension.CheckpointSucceeded,
+				Containers: []extension.ContainerCheckpoint{
+					{Name: "main", Archive: "/var/lib/kubelet/checkpoints/checkpoint-test-pod_default-main.tar"},
+				},
+			},
+			wantCondStatus: sev1alpha1.PodMigrationJobConditionStatusTrue,
+			wantCondReason: sev1alpha1.PodMigrationJobReasonCheckpointComplete,
+			wantAborted:    true,
+		},
+		{
+			name: "checkpoint unsupported",
+			checkpointStatus: &extension.CheckpointStatus{
+				Request: "test",
+				Phase:   extension.CheckpointUnsupported,
+				Message: "criu not found",
+			},
+			wantCondStatus: sev1alpha1.PodMigrationJobConditionStatusFalse,
+			wantCondReason: sev1alpha1.PodMigrationJobReasonCheckpointFailed,
+		},
+		{
+			name:           "checkpoint timeout",
+			timeout:        true,
+			wantCondStatus: sev1alpha1.PodMigrationJobConditionStatusFalse,
+			wantCondReason: sev1alpha1.PodMigrationJobReasonCheckpointFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := newTestReconciler()
+			fakeClock := clock.NewFakeClock(time.Now())
+			reconciler.clock = fakeClock
+			reconciler.evictorInterpreter = fakeEvictionInterpreter{}
+
+			job := &sev1alpha1.PodMigrationJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					CreationTimestamp: metav1.Time{Time: time.Now()},
+					Annotations: map[string]string{
+						extension.AnnotationMigrationCheckpoint: "true",
+					},
+				},
+				Spec: sev1alpha1.PodMigrationJobSpec{
+					PodRef: &corev1.ObjectReference{
+						Namespace: "default",
+						Name:      "test-pod",
+					},
+				},
+			}
+			assert.Nil(t, reconciler.Create(context.TODO(), job))
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod",
+					Annotations: map[string]string{
+						extension.AnnotationCheckpointEnabled: "true",
+					},
+				},
+			}
+			assert.Nil(t, reconciler.Client.Create(context.TODO(), pod))
+
+			evicted, result, err := reconciler.evictPod(context.TODO(), job)
+			assert.False(t, evicted)
+			assert.Equal(t, reconcile.Result{RequeueAfter: defaultRequeueAfter}, result)
+			assert.Nil(t, err)
+			_, cond := util.GetCondition(&job.Status, sev1alpha1.PodMigrationJobConditionCheckpoint)
+			assert.NotNil(t, cond)
+			assert.Equal(t, sev1alpha1.PodMigrationJobReasonCheckpointing, cond.Reason)
+			_, cond = util.GetCondition(&job.Status, sev1alpha1.PodMigrationJobConditionEviction)
+			assert.Nil(t, cond)
+
+			gotPod := &corev1.Pod{}
+			assert.Nil(t, reconciler.Client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "test-pod"}, gotPod))
+			assert.Equal(t, "test", gotPod.Annotations[extension.AnnotationCheckpointRequest])
+
+			evicted, result, err = reconciler.evictPod(context.TODO(), job)
+			assert.False(t, evicted)
+			assert.Equal(t, reconcile.Result{RequeueAfter: defaultRequeueAfter}, result)
+			assert.Nil(t, err)
+			_, cond = util.GetCondition(&job.Status, sev1alpha1.PodMigrationJobConditionEviction)
+			assert.Nil(t, cond)
+
+			if tt.checkpointStatus != nil {
+				assert.Nil(t, extension.SetCheckpointStatus(gotPod, tt.checkpointStatus))
+				assert.Nil(t, reconciler.Client.Update(context.TODO(), gotPod))
+			}
+			if tt.timeout {
+				fakeClock.Step(defaultCheckpointTimeout + time.Second)
+			}
+
+			evicted, result, err = reconciler.evictPod(context.TODO(), job)
+			assert.False(t, evicted)
+			assert.Nil(t, err)
+			_, cond = util.GetCondition(&job.Status, sev1alpha1.PodMigrationJobConditionCheckpoint)
+			assert.NotNil(t, cond)
+			assert.Equal(t, tt.wantCondStatus, cond.Status)
+			assert.Equal(t, tt.wantCondReason, cond.Reason)
+			_, cond = util.GetCondition(&job.Status, sev1alpha1.PodMigrationJobConditionEviction)
+			if tt.wantAborted {
+				// the checkpointed pod is not evicted since nothing restores it
+				assert.Equal(t, reconcile.Result{}, result)
+				assert.Nil(t, cond)
+				assert.Equal(t, sev1alpha1.PodMigrationJobFailed, job.Status.Phase)
+				assert.Equal(t, sev1alpha1.PodMigrationJobReasonCheckpointComplete, job.Status.Reason)
+				return
+			}
+			assert.Equal(t, reconcile.Result{RequeueAfter: defaultRequeueAfter}, result)
+			assert.NotNil(t, cond)
+			assert.Equal(t, sev1alpha1.PodMigrationJobReasonEvicting, cond.Reason)
+		})
+	}
+}
+
 func TestDeleteReservation(t *testing.T) {
 	reconciler := newTestReconciler()
 	assert.Nil(t, reconciler.deleteReservation(context.TODO(), &sev1alpha1.PodMigrationJob{}))
@@ -656,6 +826,56 @@ func TestCreateReservation(t *testing.T) {
 	assert.Equal(t, expectReservationRef, job.Spec.ReservationOptions.ReservationRef)
 }
 
+func TestCreateReservationReservedForMigration(t *testing.T) {
+	reconciler := newTestReconciler()
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			UID:               uuid.NewUUID(),
+			CreationTimestamp: metav1.Time{Time: time.Now()},
+		},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{
+				Namespace: "default",
+				Name:      "test-pod",
+			},
+		},
+	}
+	assert.Nil(t, reconciler.Client.Create(context.TODO(), job))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       uuid.NewUUID(),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node",
+		},
+	}
+	assert.Nil(t, reconciler.Client.Create(context.TODO(), pod))
+	reconciler.reservationInterpreter = fakeReservationInterpreter{
+		reservation: &sev1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: string(job.UID),
+				UID:  uuid.NewUUID(),
+			},
+		},
+	}
+	assert.Nil(t, reconciler.createReservation(context.TODO(), job))
+
+	// the reservation is held for the replacement of the scheduled pod
+	reservedForMigration, err := extension.GetReservedForMigration(job.Spec.ReservationOptions.Template.Annotations)
+	assert.NoError(t, err)
+	expected := &extension.ReservedForMigration{
+		Namespace: "default",
+		Name:      "test-pod",
+		UID:       pod.UID,
+		JobName:   "test",
+	}
+	assert.Equal(t, expected, reservedForMigration)
+	assert.Empty(t, job.Spec.ReservationOptions.Template.Spec.Template.Spec.NodeName)
+}
+
 func TestWaitForPendingPodScheduled(t *testing.T) {
 	reconciler := newTestReconciler()
 
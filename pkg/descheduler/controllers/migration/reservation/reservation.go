@@ -53,7 +53,7 @@ func (r *Reservation) GetReservationConditions() []sev1alpha1.ReservationConditi
 }
 
 func (r *Reservation) QueryPreemptedPodsRefs() []corev1.ObjectReference {
-	return nil
+	return r.Status.PreemptedPods
 }
 
 func (r *Reservation) GetBoundPod() *corev1.ObjectReference {
@@ -84,6 +84,42 @@ func CreateOrUpdateReservationOptions(job *sev1alpha1.PodMigrationJob, pod *core
 	reservationOptions.Template.ObjectMeta.Labels[LabelCreatedBy] = DefaultCreator
 	reservationOptions.Template.ObjectMeta.Labels[extension.LabelReservationOrder] = strconv.FormatInt(time.Now().UnixMilli(), 10)
 
+	// the reserve pod carries the GPU compaction mark, so that the scheduler prefers the partially allocated GPUs
+	// and never allocates the GPU to be released
+	if job.Annotations[extension.AnnotationGPUCompaction] == "true" {
+		template := reservationOptions.Template.Spec.Template
+		annotations := make(map[string]string, len(template.Annotations)+2)
+		for k, v := range template.Annotations {
+			annotations[k] = v
+		}
+		annotations[extension.AnnotationGPUCompaction] = "true"
+		if source, ok := job.Annotations[extension.AnnotationGPUCompactionSource]; ok {
+			annotations[extension.AnnotationGPUCompactionSource] = source
+		}
+		template.Annotations = annotations
+	}
+	// the reserve pod carries the preferred nodes hint evaluated by the descheduler
+	if preferredNodes, ok := job.Annotations[extension.AnnotationPreferredNodes]; ok {
+		template := reservationOptions.Template.Spec.Template
+		annotations := make(map[string]string, len(template.Annotations)+1)
+		for k, v := range template.Annotations {
+			annotations[k] = v
+		}
+		annotations[extension.AnnotationPreferredNodes] = preferredNodes
+		template.Annotations = annotations
+	}
+
+	// the reservation is held for the replacement of the scheduled pod until the pod is evicted, while the pending
+	// pod allocates the reservation by itself
+	if pod.Spec.NodeName != "" {
+		_ = extension.SetReservedForMigration(&reservationOptions.Template.ObjectMeta, &extension.ReservedForMigration{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+			JobName:   job.Name,
+		})
+	}
+
 	if (reservationOptions.Template.Spec.TTL == nil && reservationOptions.Template.Spec.Expires == nil) &&
 		job.Spec.TTL != nil && job.Spec.TTL.Duration > 0 {
 		reservationOptions.Template.Spec.TTL = job.Spec.TTL
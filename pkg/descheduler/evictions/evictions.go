@@ -33,6 +33,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	evictutils "github.com/koordinator-sh/koordinator/pkg/descheduler/evictions/utils"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
@@ -170,6 +171,9 @@ func EvictPod(ctx context.Context, client clientset.Interface, pod *corev1.Pod,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pod.Name,
 			Namespace: pod.Namespace,
+			Annotations: map[string]string{
+				extension.AnnotationEvictionInitiator: extension.EvictionInitiatorDescheduler,
+			},
 		},
 		DeleteOptions: deleteOptions,
 	}
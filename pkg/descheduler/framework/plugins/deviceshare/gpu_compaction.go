@@ -0,0 +1,302 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	schedulinglisters "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
+)
+
+const (
+	GPUCompactionName = "GPUCompaction"
+
+	reasonGPUCompaction = "GPUCompaction"
+)
+
+var _ framework.BalancePlugin = &GPUCompaction{}
+
+// GPUCompaction migrates the pods sharing GPUs from the partially allocated GPUs of a node,
+// so that the shared-GPU pods are consolidated onto fewer GPUs and the freed GPUs can be used as whole cards.
+// The pods are migrated in ReservationFirst mode and marked with the GPU compaction annotation,
+// so the deviceshare scheduler plugin places the reservations on the already fragmented GPUs first.
+type GPUCompaction struct {
+	handle       framework.Handle
+	podFilter    framework.FilterFunc
+	nodeSelector labels.Selector
+	deviceLister schedulinglisters.DeviceLister
+	// podDeviceAllocationLister resolves the devices of the pods referencing PodDeviceAllocations.
+	podDeviceAllocationLister schedulinglisters.PodDeviceAllocationLister
+	args                      *deschedulerconfig.GPUCompactionArgs
+}
+
+// NewGPUCompaction builds plugin from its arguments while passing a handle
+func NewGPUCompaction(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	gpuCompactionArgs, ok := args.(*deschedulerconfig.GPUCompactionArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type GPUCompactionArgs, got %T", args)
+	}
+	if err := validation.ValidateGPUCompactionArgs(nil, gpuCompactionArgs); err != nil {
+		return nil, err
+	}
+
+	nodeSelector := labels.Everything()
+	if gpuCompactionArgs.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(gpuCompactionArgs.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing node selector: %v", err)
+		}
+		nodeSelector = selector
+	}
+
+	var excludedNamespaces sets.String
+	var includedNamespaces sets.String
+	if gpuCompactionArgs.EvictableNamespaces != nil {
+		excludedNamespaces = sets.NewString(gpuCompactionArgs.EvictableNamespaces.Exclude...)
+		includedNamespaces = sets.NewString(gpuCompactionArgs.EvictableNamespaces.Include...)
+	}
+
+	podFilter, err := podutil.NewOptions().
+		WithFilter(handle.Evictor().Filter).
+		WithoutNamespaces(excludedNamespaces).
+		WithNamespaces(includedNamespaces).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	koordClientSet, ok := handle.(koordclientset.Interface)
+	if !ok {
+		kubeConfig := *handle.KubeConfig()
+		kubeConfig.ContentType = runtime.ContentTypeJSON
+		kubeConfig.AcceptContentTypes = runtime.ContentTypeJSON
+		var err error
+		koordClientSet, err = koordclientset.NewForConfig(&kubeConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(koordClientSet, 0)
+	deviceInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Devices()
+	deviceInformer.Informer()
+	podDeviceAllocationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().PodDeviceAllocations()
+	podDeviceAllocationInformer.Informer()
+	koordSharedInformerFactory.Start(context.TODO().Done())
+	koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+
+	return &GPUCompaction{
+		handle:       handle,
+		podFilter:    podFilter,
+		nodeSelector: nodeSelector,
+		deviceLister: deviceInformer.Lister(),
+		args:         gpuCompactionArgs,
+
+		podDeviceAllocationLister: podDeviceAllocationInformer.Lister(),
+	}, nil
+}
+
+// Name retrieves the plugin name
+func (pl *GPUCompaction) Name() string {
+	return GPUCompactionName
+}
+
+// Balance extension point implementation for the plugin
+func (pl *GPUCompaction) Balance(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	for _, node := range nodes {
+		if !pl.nodeSelector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		if err := pl.compactNode(ctx, node); err != nil {
+			klog.ErrorS(err, "Failed to compact GPUs", "node", node.Name)
+		}
+	}
+	return nil
+}
+
+// gpuUsage is the gpu-core usage of a GPU and the pods allocated on it.
+type gpuUsage struct {
+	minor int32
+	total int64
+	used  int64
+	pods  []*corev1.Pod
+}
+
+func (pl *GPUCompaction) compactNode(ctx context.Context, node *corev1.Node) error {
+	device, err := pl.deviceLister.Get(node.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	gpus := map[int32]*gpuUsage{}
+	for _, info := range device.Spec.Devices {
+		if info.Type != sev1alpha1.GPU || !info.Health || info.Minor == nil {
+			continue
+		}
+		total := info.Resources.Name(apiext.ResourceGPUCore, "").Value()
+		if total <= 0 {
+			continue
+		}
+		gpus[*info.Minor] = &gpuUsage{minor: *info.Minor, total: total}
+	}
+	if len(gpus) < int(pl.args.MinFragmentedGPUs) {
+		return nil
+	}
+
+	pods, err := podutil.ListPodsOnANode(node.Name, pl.handle.GetPodsAssignedToNodeFunc(), nil)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods {
+		allocations, err := pl.getDeviceAllocations(pod)
+		if err != nil {
+			klog.V(4).InfoS("Failed to get device allocations of pod", "pod", klog.KObj(pod), "err", err)
+			continue
+		}
+		for _, allocation := range allocations[sev1alpha1.GPU] {
+			gpu := gpus[allocation.Minor]
+			if gpu == nil {
+				continue
+			}
+			gpu.used += allocation.Resources.Name(apiext.ResourceGPUCore, "").Value()
+			gpu.pods = append(gpu.pods, pod)
+		}
+	}
+
+	var fragmented []*gpuUsage
+	var totalUsed int64
+	for _, gpu := range gpus {
+		if gpu.used > 0 && gpu.used < gpu.total {
+			fragmented = append(fragmented, gpu)
+			totalUsed += gpu.used
+		}
+	}
+	if len(fragmented) < int(pl.args.MinFragmentedGPUs) {
+		return nil
+	}
+	// the GPUs of a node are the same model, so the fragments can be packed into ceil(used/total) GPUs at best
+	perGPU := fragmented[0].total
+	releasable := len(fragmented) - int((totalUsed+perGPU-1)/perGPU)
+	if releasable <= 0 {
+		return nil
+	}
+	klog.V(4).InfoS("Found fragmented GPUs", "node", node.Name, "fragmented", len(fragmented), "releasable", releasable)
+
+	// the least used GPUs are the cheapest to release
+	sort.Slice(fragmented, func(i, j int) bool {
+		if fragmented[i].used != fragmented[j].used {
+			return fragmented[i].used < fragmented[j].used
+		}
+		return fragmented[i].minor < fragmented[j].minor
+	})
+
+	migrating := 0
+	for _, gpu := range fragmented {
+		if releasable == 0 {
+			break
+		}
+		if !pl.isMigratable(gpu) {
+			continue
+		}
+		if pl.args.MaxMigratingPodsPerNode > 0 && migrating+len(gpu.pods) > int(pl.args.MaxMigratingPodsPerNode) {
+			break
+		}
+		jobCtx, err := newGPUCompactionJobContext(ctx, node, gpu)
+		if err != nil {
+			return err
+		}
+		releasable--
+		migrating += len(gpu.pods)
+		for _, pod := range gpu.pods {
+			pl.migrate(jobCtx, node, gpu, pod)
+		}
+	}
+	return nil
+}
+
+// newGPUCompactionJobContext returns the context of the migration jobs releasing the GPU, which records the GPU on
+// the jobs so that the migrated pods are never allocated the GPU again.
+func newGPUCompactionJobContext(ctx context.Context, node *corev1.Node, gpu *gpuUsage) (context.Context, error) {
+	meta := &metav1.ObjectMeta{
+		Annotations: map[string]string{apiext.AnnotationGPUCompaction: "true"},
+	}
+	if err := apiext.SetGPUCompactionSource(meta, &apiext.GPUCompactionSource{NodeName: node.Name, Minor: gpu.minor}); err != nil {
+		return nil, err
+	}
+	return migration.WithContext(ctx, &migration.JobContext{
+		Annotations: meta.Annotations,
+		Mode:        sev1alpha1.PodMigrationJobModeReservationFirst,
+	}), nil
+}
+
+// isMigratable returns true if all the pods on the GPU are evictable and only share the GPU,
+// otherwise the GPU can't be released.
+func (pl *GPUCompaction) isMigratable(gpu *gpuUsage) bool {
+	for _, pod := range gpu.pods {
+		if !pl.podFilter(pod) {
+			return false
+		}
+		allocations, err := pl.getDeviceAllocations(pod)
+		if err != nil || len(allocations[sev1alpha1.GPU]) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (pl *GPUCompaction) getDeviceAllocations(pod *corev1.Pod) (apiext.DeviceAllocations, error) {
+	return apiext.ResolveDeviceAllocations(pod.Namespace, pod.UID, pod.Annotations, func(namespace, name string) (*sev1alpha1.PodDeviceAllocation, error) {
+		return pl.podDeviceAllocationLister.PodDeviceAllocations(namespace).Get(name)
+	})
+}
+
+func (pl *GPUCompaction) migrate(ctx context.Context, node *corev1.Node, gpu *gpuUsage, pod *corev1.Pod) {
+	if pl.args.DryRun {
+		klog.InfoS("Pod shares a fragmented GPU, but skip to migrate it in dry run mode", "pod", klog.KObj(pod), "node", node.Name, "minor", gpu.minor)
+		pl.handle.EventRecorder().Eventf(pod, nil, corev1.EventTypeNormal, reasonGPUCompaction, "Descheduling", "Pod shares fragmented GPU %d (dry run)", gpu.minor)
+		return
+	}
+	evictionOptions := framework.EvictOptions{
+		PluginName: GPUCompactionName,
+		Reason:     fmt.Sprintf("compact fragmented GPU %d of node %s", gpu.minor, node.Name),
+	}
+	if !pl.handle.Evictor().Evict(ctx, pod, evictionOptions) {
+		klog.InfoS("Failed to migrate pod from fragmented GPU", "pod", klog.KObj(pod), "node", node.Name, "minor", gpu.minor)
+		return
+	}
+	klog.InfoS("Migrated pod from fragmented GPU", "pod", klog.KObj(pod), "node", node.Name, "minor", gpu.minor)
+}
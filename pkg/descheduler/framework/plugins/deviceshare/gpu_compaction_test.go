@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/test"
+)
+
+type fakeFrameworkHandle struct {
+	framework.Handle
+	koordclientset.Interface
+	evictor               *fakeEvictor
+	getPodsAssignedToNode framework.GetPodsAssignedToNodeFunc
+}
+
+func (f *fakeFrameworkHandle) Evictor() framework.Evictor {
+	return f.evictor
+}
+
+func (f *fakeFrameworkHandle) GetPodsAssignedToNodeFunc() framework.GetPodsAssignedToNodeFunc {
+	return f.getPodsAssignedToNode
+}
+
+func (f *fakeFrameworkHandle) EventRecorder() events.EventRecorder {
+	return &events.FakeRecorder{}
+}
+
+type fakeEvictor struct {
+	evicted []string
+	jobCtxs map[string]*migration.JobContext
+}
+
+func (f *fakeEvictor) Filter(pod *corev1.Pod) bool {
+	return pod.Labels["evictable"] != "false"
+}
+
+func (f *fakeEvictor) PreEvictionFilter(pod *corev1.Pod) bool {
+	return true
+}
+
+func (f *fakeEvictor) Evict(ctx context.Context, pod *corev1.Pod, evictOptions framework.EvictOptions) bool {
+	f.evicted = append(f.evicted, pod.Name)
+	if f.jobCtxs == nil {
+		f.jobCtxs = map[string]*migration.JobContext{}
+	}
+	f.jobCtxs[pod.Name] = migration.FromContext(ctx)
+	return true
+}
+
+func newTestGPUDevice(nodeName string, gpus int) *sev1alpha1.Device {
+	device := &sev1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+	}
+	for i := 0; i < gpus; i++ {
+		device.Spec.Devices = append(device.Spec.Devices, sev1alpha1.DeviceInfo{
+			Minor:  pointer.Int32(int32(i)),
+			Type:   sev1alpha1.GPU,
+			Health: true,
+			Resources: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("100"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+			},
+		})
+	}
+	return device
+}
+
+func newTestGPUPod(t *testing.T, name, nodeName string, gpuCores map[int32]int64) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+	allocations := apiext.DeviceAllocations{}
+	for minor := int32(0); minor < 8; minor++ {
+		core, ok := gpuCores[minor]
+		if !ok {
+			continue
+		}
+		allocations[sev1alpha1.GPU] = append(allocations[sev1alpha1.GPU], &apiext.DeviceAllocation{
+			Minor: minor,
+			Resources: corev1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(core, resource.DecimalSI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(core, resource.DecimalSI),
+			},
+		})
+	}
+	assert.NoError(t, apiext.SetDeviceAllocations(pod, allocations))
+	return pod
+}
+
+func TestGPUCompaction(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node", Labels: map[string]string{"gpu": "true"}},
+	}
+	tests := []struct {
+		name        string
+		args        *deschedulerconfig.GPUCompactionArgs
+		pods        func(t *testing.T) []*corev1.Pod
+		refPods     sets.String
+		wantEvicted []string
+	}{
+		{
+			name: "migrate pods from the least used fragmented GPU",
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 50}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 25}),
+					newTestGPUPod(t, "pod-3", node.Name, map[int32]int64{2: 100}),
+				}
+			},
+			wantEvicted: []string{"pod-2"},
+		},
+		{
+			name: "migrate pods from multiple fragmented GPUs",
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 20}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 30}),
+					newTestGPUPod(t, "pod-3", node.Name, map[int32]int64{2: 25}),
+					newTestGPUPod(t, "pod-4", node.Name, map[int32]int64{2: 25}),
+				}
+			},
+			wantEvicted: []string{"pod-1", "pod-2"},
+		},
+		{
+			name: "fragments can't be packed into fewer GPUs",
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 60}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 70}),
+				}
+			},
+		},
+		{
+			name: "resolve the devices from PodDeviceAllocations",
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 50}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 25}),
+					newTestGPUPod(t, "pod-3", node.Name, map[int32]int64{2: 100}),
+				}
+			},
+			refPods:     sets.NewString("pod-1", "pod-2"),
+			wantEvicted: []string{"pod-2"},
+		},
+		{
+			name: "skip GPU with non-evictable pods",
+			pods: func(t *testing.T) []*corev1.Pod {
+				pod := newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 20})
+				pod.Labels = map[string]string{"evictable": "false"}
+				return []*corev1.Pod{
+					pod,
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 30}),
+					newTestGPUPod(t, "pod-3", node.Name, map[int32]int64{2: 60}),
+				}
+			},
+			wantEvicted: []string{"pod-2"},
+		},
+		{
+			name: "limit migrating pods per node",
+			args: &deschedulerconfig.GPUCompactionArgs{
+				MinFragmentedGPUs:       2,
+				MaxMigratingPodsPerNode: 1,
+			},
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 10}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{0: 10}),
+					newTestGPUPod(t, "pod-3", node.Name, map[int32]int64{1: 30}),
+				}
+			},
+		},
+		{
+			name: "node not selected",
+			args: &deschedulerconfig.GPUCompactionArgs{
+				MinFragmentedGPUs: 2,
+				NodeSelector:      &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "false"}},
+			},
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 50}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 25}),
+				}
+			},
+		},
+		{
+			name: "dry run",
+			args: &deschedulerconfig.GPUCompactionArgs{
+				DryRun:            true,
+				MinFragmentedGPUs: 2,
+			},
+			pods: func(t *testing.T) []*corev1.Pod {
+				return []*corev1.Pod{
+					newTestGPUPod(t, "pod-1", node.Name, map[int32]int64{0: 50}),
+					newTestGPUPod(t, "pod-2", node.Name, map[int32]int64{1: 25}),
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			objs := []runtime.Object{node}
+			koordObjs := []runtime.Object{newTestGPUDevice(node.Name, 4)}
+			gpuMinors := map[string]int32{}
+			for _, pod := range tt.pods(t) {
+				allocations, err := apiext.GetDeviceAllocations(pod.Annotations)
+				assert.NoError(t, err)
+				gpuMinors[pod.Name] = allocations[sev1alpha1.GPU][0].Minor
+				if tt.refPods.Has(pod.Name) {
+					podDeviceAllocation := apiext.NewPodDeviceAllocation(pod, node.Name, allocations)
+					apiext.SetDeviceAllocationRef(pod, podDeviceAllocation.Name)
+					koordObjs = append(koordObjs, podDeviceAllocation)
+				}
+				objs = append(objs, pod)
+			}
+			kubeClient := kubefake.NewSimpleClientset(objs...)
+			sharedInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+			getPodsAssignedToNode, err := test.BuildGetPodsAssignedToNodeFunc(sharedInformerFactory.Core().V1().Pods())
+			assert.NoError(t, err)
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			args := tt.args
+			if args == nil {
+				args = &deschedulerconfig.GPUCompactionArgs{MinFragmentedGPUs: 2}
+			}
+			evictor := &fakeEvictor{}
+			pl, err := NewGPUCompaction(args, &fakeFrameworkHandle{
+				Interface:             koordfake.NewSimpleClientset(koordObjs...),
+				evictor:               evictor,
+				getPodsAssignedToNode: getPodsAssignedToNode,
+			})
+			assert.NoError(t, err)
+
+			status := pl.(framework.BalancePlugin).Balance(ctx, []*corev1.Node{node})
+			assert.Nil(t, status)
+			assert.Equal(t, tt.wantEvicted, evictor.evicted)
+			for podName, jobCtx := range evictor.jobCtxs {
+				// the job records the GPU released by the pod
+				source, err := apiext.GetGPUCompactionSource(jobCtx.Annotations)
+				assert.NoError(t, err)
+				assert.Equal(t, &apiext.GPUCompactionSource{NodeName: node.Name, Minor: gpuMinors[podName]}, source)
+				assert.Equal(t, "true", jobCtx.Annotations[apiext.AnnotationGPUCompaction])
+				assert.Equal(t, sev1alpha1.PodMigrationJobModeReservationFirst, jobCtx.Mode)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package loadaware
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 
@@ -26,10 +27,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	slolisters "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
 	nodeutil "github.com/koordinator-sh/koordinator/pkg/descheduler/node"
 	podutil "github.com/koordinator-sh/koordinator/pkg/descheduler/pod"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/utils/sorter"
@@ -63,6 +67,9 @@ type evictionReasonGeneratorFn func(nodeInfo NodeInfo) string
 const (
 	MinResourcePercentage = 0
 	MaxResourcePercentage = 100
+
+	// maxPreferredNodes is the max number of the preferred target nodes recorded for an evicted pod
+	maxPreferredNodes = 3
 )
 
 func normalizePercentage(percent Percentage) Percentage {
@@ -261,6 +268,14 @@ func evictPodsFromSourceNodes(
 	}
 	klog.V(4).InfoS("Total capacity to be moved", keysAndValues...)
 
+	// the less loaded destination nodes are more preferred as the targets of the evicted pods
+	preferredNodes := make([]NodeInfo, len(destinationNodes))
+	copy(preferredNodes, destinationNodes)
+	sortNodesByUsage(preferredNodes, sorter.GenDefaultResourceToWeightMap(resourceNames), true)
+	getPreferredNodes := func(pod *corev1.Pod, podMetric *slov1alpha1.ResourceMap) []string {
+		return getPreferredTargetNodes(pod, podMetric, preferredNodes, nodeFit, nodeIndexer)
+	}
+
 	for _, srcNode := range sourceNodes {
 		nonRemovablePods, removablePods := classifyPods(
 			srcNode.allPods,
@@ -286,8 +301,56 @@ func evictPodsFromSourceNodes(
 			map[string]corev1.ResourceList{srcNode.node.Name: srcNode.node.Status.Allocatable},
 			sorter.GenDefaultResourceToWeightMap(resourceNames),
 		)
-		evictPods(ctx, dryRun, removablePods, srcNode, totalAvailableUsages, podEvictor, podFilter, continueEviction, evictionReasonGenerator)
+		evictPods(ctx, dryRun, removablePods, srcNode, totalAvailableUsages, podEvictor, podFilter, continueEviction, evictionReasonGenerator, getPreferredNodes)
+	}
+}
+
+// getPreferredTargetNodes returns the nodes which have enough headroom below the high thresholds for the usage
+// of the pod, at most maxPreferredNodes of them are returned in the order of the given nodes.
+func getPreferredTargetNodes(
+	pod *corev1.Pod,
+	podMetric *slov1alpha1.ResourceMap,
+	nodes []NodeInfo,
+	nodeFit bool,
+	nodeIndexer podutil.GetPodsAssignedToNodeFunc,
+) []string {
+	if podMetric == nil {
+		return nil
+	}
+	var preferredNodes []string
+	for _, nodeInfo := range nodes {
+		if len(preferredNodes) >= maxPreferredNodes {
+			break
+		}
+		if nodeInfo.node.Name == pod.Spec.NodeName {
+			continue
+		}
+		fits := true
+		for resourceName, threshold := range nodeInfo.thresholds.highResourceThreshold {
+			usage := nodeInfo.usage[resourceName]
+			if usage == nil {
+				continue
+			}
+			quantity := podMetric.ResourceList[resourceName]
+			if resourceName == corev1.ResourcePods {
+				quantity = *resource.NewQuantity(1, resource.DecimalSI)
+			}
+			headroom := threshold.DeepCopy()
+			headroom.Sub(*usage)
+			if headroom.Cmp(quantity) < 0 {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			continue
+		}
+		if nodeFit && len(nodeutil.NodeFit(nodeIndexer, pod, nodeInfo.node)) > 0 {
+			continue
+		}
+		preferredNodes = append(preferredNodes, nodeInfo.node.Name)
 	}
+	return preferredNodes
 }
 
 func evictPods(
@@ -300,30 +363,38 @@ func evictPods(
 	podFilter framework.FilterFunc,
 	continueEviction continueEvictionCond,
 	evictionReasonGenerator evictionReasonGeneratorFn,
+	getPreferredNodes func(pod *corev1.Pod, podMetric *slov1alpha1.ResourceMap) []string,
 ) {
 	for _, pod := range inputPods {
 		if !continueEviction(nodeInfo, totalAvailableUsages) {
 			return
 		}
 
+		metrics.PolicyCandidatesEvaluated.WithLabelValues(LowNodeLoadName).Inc()
 		if !podFilter(pod) {
+			metrics.PolicyEvictionsBlocked.WithLabelValues(LowNodeLoadName, metrics.EvictionBlockedByFilter).Inc()
 			klog.V(4).InfoS("Pod aborted eviction because it was filtered by filters", "pod", klog.KObj(pod))
 			continue
 		}
+		podMetric := nodeInfo.podMetrics[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
 		if dryRun {
 			klog.InfoS("Evict pod in dry run mode", "pod", klog.KObj(pod))
 		} else {
 			evictionOptions := framework.EvictOptions{
-				Reason: evictionReasonGenerator(nodeInfo),
+				Reason:         evictionReasonGenerator(nodeInfo),
+				DecisionValues: nodeUsageDecisionValues(nodeInfo),
+			}
+			evictCtx := ctx
+			if preferredNodes := getPreferredNodes(pod, podMetric); len(preferredNodes) > 0 {
+				evictCtx = withPreferredNodes(ctx, preferredNodes)
 			}
-			if !podEvictor.Evict(ctx, pod, evictionOptions) {
+			if !podEvictor.Evict(evictCtx, pod, evictionOptions) {
 				klog.InfoS("Failed to Evict Pod", "pod", klog.KObj(pod))
 				continue
 			}
 			klog.InfoS("Evicted Pod", "pod", klog.KObj(pod))
 		}
 
-		podMetric := nodeInfo.podMetrics[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
 		if podMetric == nil {
 			klog.V(4).InfoS("Failed to find PodMetric", "pod", klog.KObj(pod))
 			continue
@@ -355,6 +426,35 @@ func evictPods(
 	}
 }
 
+// nodeUsageDecisionValues returns the usage and the high thresholds of the source node, which are the metric values
+// triggering the eviction of its pods.
+func nodeUsageDecisionValues(nodeInfo NodeInfo) map[string]string {
+	usagePercentages := resourceUsagePercentages(nodeInfo.NodeUsage)
+	values := map[string]string{}
+	for resourceName, usage := range nodeInfo.usage {
+		if usage == nil {
+			continue
+		}
+		values[fmt.Sprintf("%s/usage", resourceName)] = usage.String()
+		values[fmt.Sprintf("%s/usagePercentage", resourceName)] = fmt.Sprintf("%.2f", usagePercentages[resourceName])
+		if threshold := nodeInfo.thresholds.highResourceThreshold[resourceName]; threshold != nil {
+			values[fmt.Sprintf("%s/highThreshold", resourceName)] = threshold.String()
+		}
+	}
+	return values
+}
+
+// withPreferredNodes records the preferred nodes on the PodMigrationJob as the scheduling hint of the evicted pod.
+func withPreferredNodes(ctx context.Context, preferredNodes []string) context.Context {
+	data, err := json.Marshal(&apiext.PreferredNodes{Nodes: preferredNodes})
+	if err != nil {
+		return ctx
+	}
+	return migration.WithContext(ctx, &migration.JobContext{
+		Annotations: map[string]string{apiext.AnnotationPreferredNodes: string(data)},
+	})
+}
+
 // sortNodesByUsage sorts nodes based on usage.
 func sortNodesByUsage(nodes []NodeInfo, resourceToWeightMap sorter.ResourceToWeightMap, ascending bool) {
 	scorer := sorter.ResourceUsageScorer(resourceToWeightMap)
@@ -164,6 +164,34 @@ func TestResourceUsagePercentages(t *testing.T) {
 	t.Logf("resourceUsagePercentage: %#v\n", resourceUsagePercentage)
 }
 
+func TestNodeUsageDecisionValues(t *testing.T) {
+	nodeInfo := NodeInfo{
+		NodeUsage: &NodeUsage{
+			node: &corev1.Node{
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("32"),
+					},
+				},
+			},
+			usage: map[corev1.ResourceName]*resource.Quantity{
+				corev1.ResourceCPU: resource.NewMilliQuantity(28800, resource.DecimalSI),
+			},
+		},
+		thresholds: NodeThresholds{
+			highResourceThreshold: map[corev1.ResourceName]*resource.Quantity{
+				corev1.ResourceCPU: resource.NewMilliQuantity(25600, resource.DecimalSI),
+			},
+		},
+	}
+	expected := map[string]string{
+		"cpu/usage":           "28800m",
+		"cpu/usagePercentage": "90.00",
+		"cpu/highThreshold":   "25600m",
+	}
+	assert.Equal(t, expected, nodeUsageDecisionValues(nodeInfo))
+}
+
 func TestSortNodesByUsageDescendingOrder(t *testing.T) {
 	nodeList := []NodeInfo{testNode1, testNode2, testNode3}
 	expectedNodeList := []NodeInfo{testNode3, testNode1, testNode2}
@@ -181,3 +209,69 @@ func TestSortNodesByUsageAscendingOrder(t *testing.T) {
 
 	assert.Equal(t, expectedNodeList, nodeList)
 }
+
+func TestGetPreferredTargetNodes(t *testing.T) {
+	withCPUThreshold := func(nodeInfo NodeInfo, threshold string) NodeInfo {
+		quantity := resource.MustParse(threshold)
+		nodeInfo.thresholds = NodeThresholds{
+			highResourceThreshold: map[corev1.ResourceName]*resource.Quantity{
+				corev1.ResourceCPU: &quantity,
+			},
+		}
+		return nodeInfo
+	}
+	// the headroom of cpu: node2 780m, node1 270m, node3 470m
+	nodes := []NodeInfo{
+		withCPUThreshold(testNode2, "2"),
+		withCPUThreshold(testNode1, "2"),
+		withCPUThreshold(testNode3, "2"),
+	}
+	podUsage := func(cpu string) *slov1alpha1.ResourceMap {
+		return &slov1alpha1.ResourceMap{
+			ResourceList: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pod       *corev1.Pod
+		podMetric *slov1alpha1.ResourceMap
+		want      []string
+	}{
+		{
+			name: "missing pod metric",
+			pod:  &corev1.Pod{},
+			want: nil,
+		},
+		{
+			name:      "all nodes have enough headroom",
+			pod:       &corev1.Pod{},
+			podMetric: podUsage("200m"),
+			want:      []string{"node2", "node1", "node3"},
+		},
+		{
+			name:      "only one node has enough headroom",
+			pod:       &corev1.Pod{},
+			podMetric: podUsage("500m"),
+			want:      []string{"node2"},
+		},
+		{
+			name:      "skip the current node of the pod",
+			pod:       &corev1.Pod{Spec: corev1.PodSpec{NodeName: "node2"}},
+			podMetric: podUsage("200m"),
+			want:      []string{"node1", "node3"},
+		},
+		{
+			name:      "no node has enough headroom",
+			pod:       &corev1.Pod{},
+			podMetric: podUsage("1"),
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPreferredTargetNodes(tt.pod, tt.podMetric, nodes, false, nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -17,14 +17,18 @@ limitations under the License.
 package plugins
 
 import (
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/deviceshare"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/kubernetes"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/loadaware"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/plugins/reservation"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework/runtime"
 )
 
 func NewInTreeRegistry() runtime.Registry {
 	registry := runtime.Registry{
-		loadaware.LowNodeLoadName: loadaware.NewLowNodeLoad,
+		deviceshare.GPUCompactionName:       deviceshare.NewGPUCompaction,
+		loadaware.LowNodeLoadName:           loadaware.NewLowNodeLoad,
+		reservation.OrphanedReservationName: reservation.NewOrphanedReservation,
 	}
 	kubernetes.SetupK8sDeschedulerPlugins(registry)
 	return registry
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	schedulinglisters "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+const (
+	OrphanedReservationName = "OrphanedReservation"
+
+	reasonOrphanedReservation = "OrphanedReservation"
+)
+
+var _ framework.DeschedulePlugin = &OrphanedReservation{}
+
+// OrphanedReservation expires the reservations whose owner workloads no longer exist,
+// so that the capacity held by reservations created by forgotten automation can be released.
+type OrphanedReservation struct {
+	handle            framework.Handle
+	koordClientSet    koordclientset.Interface
+	reservationLister schedulinglisters.ReservationLister
+	args              *deschedulerconfig.OrphanedReservationArgs
+	allowList         sets.String
+	allowListSelector labels.Selector
+
+	deploymentLister  appslisters.DeploymentLister
+	replicaSetLister  appslisters.ReplicaSetLister
+	statefulSetLister appslisters.StatefulSetLister
+	daemonSetLister   appslisters.DaemonSetLister
+	jobLister         batchlisters.JobLister
+	cronJobLister     batchlisters.CronJobLister
+	// controllersSynced checks the caches of the controllers are synced, otherwise the controllers not in the
+	// caches yet are mistaken as deleted.
+	controllersSynced []cache.InformerSynced
+}
+
+// NewOrphanedReservation builds plugin from its arguments while passing a handle
+func NewOrphanedReservation(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	orphanedReservationArgs, ok := args.(*deschedulerconfig.OrphanedReservationArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type OrphanedReservationArgs, got %T", args)
+	}
+	if err := validation.ValidateOrphanedReservationArgs(nil, orphanedReservationArgs); err != nil {
+		return nil, err
+	}
+
+	allowListSelector := labels.Nothing()
+	if orphanedReservationArgs.AllowListSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(orphanedReservationArgs.AllowListSelector)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing allow list selector: %v", err)
+		}
+		allowListSelector = selector
+	}
+
+	koordClientSet, ok := handle.(koordclientset.Interface)
+	if !ok {
+		kubeConfig := *handle.KubeConfig()
+		kubeConfig.ContentType = runtime.ContentTypeJSON
+		kubeConfig.AcceptContentTypes = runtime.ContentTypeJSON
+		var err error
+		koordClientSet, err = koordclientset.NewForConfig(&kubeConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(koordClientSet, 0)
+	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations()
+	reservationInformer.Informer()
+	koordSharedInformerFactory.Start(context.TODO().Done())
+	koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+
+	// the informers of the controllers are started along with the shared informer factory of the descheduler
+	sharedInformerFactory := handle.SharedInformerFactory()
+	deploymentInformer := sharedInformerFactory.Apps().V1().Deployments()
+	replicaSetInformer := sharedInformerFactory.Apps().V1().ReplicaSets()
+	statefulSetInformer := sharedInformerFactory.Apps().V1().StatefulSets()
+	daemonSetInformer := sharedInformerFactory.Apps().V1().DaemonSets()
+	jobInformer := sharedInformerFactory.Batch().V1().Jobs()
+	cronJobInformer := sharedInformerFactory.Batch().V1().CronJobs()
+
+	return &OrphanedReservation{
+		handle:            handle,
+		koordClientSet:    koordClientSet,
+		reservationLister: reservationInformer.Lister(),
+		args:              orphanedReservationArgs,
+		allowList:         sets.NewString(orphanedReservationArgs.AllowList...),
+		allowListSelector: allowListSelector,
+		deploymentLister:  deploymentInformer.Lister(),
+		replicaSetLister:  replicaSetInformer.Lister(),
+		statefulSetLister: statefulSetInformer.Lister(),
+		daemonSetLister:   daemonSetInformer.Lister(),
+		jobLister:         jobInformer.Lister(),
+		cronJobLister:     cronJobInformer.Lister(),
+		controllersSynced: []cache.InformerSynced{
+			deploymentInformer.Informer().HasSynced,
+			replicaSetInformer.Informer().HasSynced,
+			statefulSetInformer.Informer().HasSynced,
+			daemonSetInformer.Informer().HasSynced,
+			jobInformer.Informer().HasSynced,
+			cronJobInformer.Informer().HasSynced,
+		},
+	}, nil
+}
+
+// Name retrieves the plugin name
+func (pl *OrphanedReservation) Name() string {
+	return OrphanedReservationName
+}
+
+// Deschedule extension point implementation for the plugin.
+// Reservations are cluster-scoped objects, so the nodes are not used.
+func (pl *OrphanedReservation) Deschedule(ctx context.Context, nodes []*corev1.Node) *framework.Status {
+	for _, synced := range pl.controllersSynced {
+		if !synced() {
+			klog.V(4).Infof("Skip checking orphaned reservations since the caches of the controllers are not synced")
+			return nil
+		}
+	}
+	reservations, err := pl.reservationLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list reservations, err: %v", err)
+		return &framework.Status{Err: err}
+	}
+
+	now := time.Now()
+	for _, r := range reservations {
+		if !pl.isCandidate(r, now) {
+			continue
+		}
+		orphaned, err := pl.isOrphaned(r)
+		if err != nil {
+			klog.Errorf("Failed to check owners of reservation %s, err: %v", r.Name, err)
+			continue
+		}
+		if !orphaned {
+			continue
+		}
+
+		if pl.args.DryRun {
+			klog.Infof("Reservation %s is orphaned since all its owners are gone, but skip to expire it in dry run mode", r.Name)
+			pl.handle.EventRecorder().Eventf(r, nil, corev1.EventTypeNormal, reasonOrphanedReservation, "Descheduling", "Reservation is orphaned (dry run)")
+			continue
+		}
+		if err := pl.expireReservation(ctx, r); err != nil {
+			klog.Errorf("Failed to expire orphaned reservation %s, err: %v", r.Name, err)
+			continue
+		}
+		klog.Infof("Expired orphaned reservation %s since all its owners are gone", r.Name)
+		pl.handle.EventRecorder().Eventf(r, nil, corev1.EventTypeWarning, reasonOrphanedReservation, "Descheduling", "Reservation is expired since all its owners are gone")
+	}
+	return nil
+}
+
+func (pl *OrphanedReservation) isCandidate(r *sev1alpha1.Reservation, now time.Time) bool {
+	if r.DeletionTimestamp != nil ||
+		reservationutil.IsReservationSucceeded(r) || reservationutil.IsReservationFailed(r) {
+		return false
+	}
+	if r.CreationTimestamp.Add(pl.args.GracePeriod.Duration).After(now) {
+		return false
+	}
+	if pl.allowList.Has(r.Name) || pl.allowListSelector.Matches(labels.Set(r.Labels)) {
+		return false
+	}
+	return len(r.Spec.Owners) > 0
+}
+
+// isOrphaned returns true only if every owner of the reservation refers to a controller that no longer exists.
+// Owners described by object references or label selectors can be satisfied by pods created later,
+// so the reservation is never considered orphaned with such owners.
+func (pl *OrphanedReservation) isOrphaned(r *sev1alpha1.Reservation) (bool, error) {
+	for i := range r.Spec.Owners {
+		owner := &r.Spec.Owners[i]
+		if owner.Controller == nil || owner.Object != nil || owner.LabelSelector != nil {
+			return false, nil
+		}
+		namespace := owner.Controller.Namespace
+		if namespace == "" && r.Spec.Template != nil {
+			namespace = r.Spec.Template.Namespace
+		}
+		if namespace == "" {
+			return false, nil
+		}
+		missing, err := pl.isControllerMissing(namespace, &owner.Controller.OwnerReference)
+		if err != nil || !missing {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (pl *OrphanedReservation) isControllerMissing(namespace string, ref *metav1.OwnerReference) (bool, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return false, nil
+	}
+
+	var obj metav1.Object
+	switch gv.WithKind(ref.Kind).GroupKind() {
+	case appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind():
+		obj, err = pl.deploymentLister.Deployments(namespace).Get(ref.Name)
+	case appsv1.SchemeGroupVersion.WithKind("ReplicaSet").GroupKind():
+		obj, err = pl.replicaSetLister.ReplicaSets(namespace).Get(ref.Name)
+	case appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind():
+		obj, err = pl.statefulSetLister.StatefulSets(namespace).Get(ref.Name)
+	case appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind():
+		obj, err = pl.daemonSetLister.DaemonSets(namespace).Get(ref.Name)
+	case batchv1.SchemeGroupVersion.WithKind("Job").GroupKind():
+		obj, err = pl.jobLister.Jobs(namespace).Get(ref.Name)
+	case batchv1.SchemeGroupVersion.WithKind("CronJob").GroupKind():
+		obj, err = pl.cronJobLister.CronJobs(namespace).Get(ref.Name)
+	default:
+		// unknown workloads are never treated as missing
+		return false, nil
+	}
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// the workload was deleted and recreated with the same name
+	return ref.UID != "" && obj.GetUID() != ref.UID, nil
+}
+
+func (pl *OrphanedReservation) expireReservation(ctx context.Context, r *sev1alpha1.Reservation) error {
+	newR := r.DeepCopy()
+	reservationutil.SetReservationExpired(newR)
+	_, err := pl.koordClientSet.SchedulingV1alpha1().Reservations().UpdateStatus(ctx, newR, metav1.UpdateOptions{})
+	return err
+}
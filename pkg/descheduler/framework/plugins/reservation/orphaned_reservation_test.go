@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+type fakeFrameworkHandle struct {
+	framework.Handle
+	koordclientset.Interface
+	kubeClient            kubernetes.Interface
+	sharedInformerFactory informers.SharedInformerFactory
+	eventRecorder         events.EventRecorder
+}
+
+func (f *fakeFrameworkHandle) ClientSet() kubernetes.Interface {
+	return f.kubeClient
+}
+
+func (f *fakeFrameworkHandle) SharedInformerFactory() informers.SharedInformerFactory {
+	return f.sharedInformerFactory
+}
+
+func (f *fakeFrameworkHandle) EventRecorder() events.EventRecorder {
+	return f.eventRecorder
+}
+
+func newTestReservation(name string, age time.Duration, owners ...sev1alpha1.ReservationOwner) *sev1alpha1.Reservation {
+	return &sev1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Spec: sev1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+				},
+			},
+			Owners: owners,
+		},
+		Status: sev1alpha1.ReservationStatus{
+			Phase:    sev1alpha1.ReservationAvailable,
+			NodeName: "test-node",
+		},
+	}
+}
+
+func deploymentOwner(name string, uid types.UID) sev1alpha1.ReservationOwner {
+	return sev1alpha1.ReservationOwner{
+		Controller: &sev1alpha1.ReservationControllerReference{
+			OwnerReference: metav1.OwnerReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       name,
+				UID:        uid,
+			},
+		},
+	}
+}
+
+func TestOrphanedReservation(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "existing",
+			UID:       "existing-uid",
+		},
+	}
+	tests := []struct {
+		name         string
+		args         *deschedulerconfig.OrphanedReservationArgs
+		reservations []*sev1alpha1.Reservation
+		wantExpired  []string
+	}{
+		{
+			name: "expire reservation whose deployment is deleted",
+			reservations: []*sev1alpha1.Reservation{
+				newTestReservation("r-deleted", time.Hour, deploymentOwner("deleted", "")),
+				newTestReservation("r-existing", time.Hour, deploymentOwner("existing", "existing-uid")),
+			},
+			wantExpired: []string{"r-deleted"},
+		},
+		{
+			name: "expire reservation whose deployment is recreated",
+			reservations: []*sev1alpha1.Reservation{
+				newTestReservation("r-recreated", time.Hour, deploymentOwner("existing", "old-uid")),
+			},
+			wantExpired: []string{"r-recreated"},
+		},
+		{
+			name: "skip young reservation",
+			reservations: []*sev1alpha1.Reservation{
+				newTestReservation("r-young", time.Minute, deploymentOwner("deleted", "")),
+			},
+		},
+		{
+			name: "skip reservation with non-controller owners",
+			reservations: []*sev1alpha1.Reservation{
+				newTestReservation("r-mixed", time.Hour, deploymentOwner("deleted", ""), sev1alpha1.ReservationOwner{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				}),
+			},
+		},
+		{
+			name: "skip reservation with unknown controller kind",
+			reservations: []*sev1alpha1.Reservation{
+				newTestReservation("r-unknown", time.Hour, sev1alpha1.ReservationOwner{
+					Controller: &sev1alpha1.ReservationControllerReference{
+						OwnerReference: metav1.OwnerReference{APIVersion: "example.io/v1", Kind: "Workload", Name: "deleted"},
+					},
+				}),
+			},
+		},
+		{
+			name: "skip reservations in allow list",
+			args: &deschedulerconfig.OrphanedReservationArgs{
+				GracePeriod: metav1.Duration{Duration: 5 * time.Minute},
+				AllowList:   []string{"r-allowed"},
+				AllowListSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"keep": "true"},
+				},
+			},
+			reservations: func() []*sev1alpha1.Reservation {
+				labeled := newTestReservation("r-labeled", time.Hour, deploymentOwner("deleted", ""))
+				labeled.Labels = map[string]string{"keep": "true"}
+				return []*sev1alpha1.Reservation{
+					newTestReservation("r-allowed", time.Hour, deploymentOwner("deleted", "")),
+					labeled,
+				}
+			}(),
+		},
+		{
+			name: "dry run",
+			args: &deschedulerconfig.OrphanedReservationArgs{
+				DryRun:      true,
+				GracePeriod: metav1.Duration{Duration: 5 * time.Minute},
+			},
+			reservations: []*sev1alpha1.Reservation{
+				newTestReservation("r-deleted", time.Hour, deploymentOwner("deleted", "")),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objs []runtime.Object
+			for _, r := range tt.reservations {
+				objs = append(objs, r)
+			}
+			koordClientSet := koordfake.NewSimpleClientset(objs...)
+			args := tt.args
+			if args == nil {
+				args = &deschedulerconfig.OrphanedReservationArgs{
+					GracePeriod: metav1.Duration{Duration: 5 * time.Minute},
+				}
+			}
+			kubeClient := kubefake.NewSimpleClientset(deployment)
+			sharedInformerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+			pl, err := NewOrphanedReservation(args, &fakeFrameworkHandle{
+				Interface:             koordClientSet,
+				kubeClient:            kubeClient,
+				sharedInformerFactory: sharedInformerFactory,
+				eventRecorder:         &events.FakeRecorder{},
+			})
+			assert.NoError(t, err)
+			sharedInformerFactory.Start(nil)
+			sharedInformerFactory.WaitForCacheSync(nil)
+
+			status := pl.(framework.DeschedulePlugin).Deschedule(context.TODO(), nil)
+			assert.Nil(t, status)
+
+			var gotExpired []string
+			for _, r := range tt.reservations {
+				got, err := koordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), r.Name, metav1.GetOptions{})
+				assert.NoError(t, err)
+				if reservationutil.IsReservationExpired(got) {
+					gotExpired = append(gotExpired, got.Name)
+				}
+			}
+			assert.Equal(t, tt.wantExpired, gotExpired)
+		})
+	}
+}
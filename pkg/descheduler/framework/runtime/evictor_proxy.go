@@ -18,11 +18,13 @@ package runtime
 
 import (
 	"context"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
 )
 
 type EvictionLimiter interface {
@@ -99,7 +101,11 @@ func (e *evictorProxy) Evict(ctx context.Context, pod *corev1.Pod, opts framewor
 	if len(e.handle.evictPlugins) == 0 {
 		panic("No Evictor plugin is registered in the frameworkImpl.")
 	}
+	framework.FillEvictOptionsFromContext(ctx, &opts)
+	metrics.PolicyEvictionsRequested.WithLabelValues(opts.PluginName).Inc()
 	if !e.AllowEvict(pod) {
+		metrics.PolicyEvictionsBlocked.WithLabelValues(opts.PluginName, metrics.EvictionBlockedByLimiter).Inc()
+		logEvictionDecision(pod, opts, metrics.EvictionBlockedByLimiter)
 		return false
 	}
 	if e.dryRun {
@@ -107,9 +113,40 @@ func (e *evictorProxy) Evict(ctx context.Context, pod *corev1.Pod, opts framewor
 	} else {
 		succeeded := e.handle.evictPlugins[0].Evict(ctx, pod, opts)
 		if !succeeded {
+			metrics.PolicyEvictionsBlocked.WithLabelValues(opts.PluginName, metrics.EvictionBlockedByEvictor).Inc()
+			logEvictionDecision(pod, opts, metrics.EvictionBlockedByEvictor)
 			return false
 		}
 	}
 	e.Done(pod)
+	logEvictionDecision(pod, opts, "")
 	return true
 }
+
+// logEvictionDecision records the decision of the eviction with the metric values which triggered it, so that
+// why a pod was evicted, or why the eviction was blocked, can be audited afterwards.
+func logEvictionDecision(pod *corev1.Pod, opts framework.EvictOptions, blockedReason string) {
+	decision := "Evict"
+	if blockedReason != "" {
+		decision = "Blocked"
+	}
+	keysAndValues := []interface{}{
+		"pod", klog.KObj(pod),
+		"node", pod.Spec.NodeName,
+		"strategy", opts.PluginName,
+		"decision", decision,
+		"reason", opts.Reason,
+	}
+	if blockedReason != "" {
+		keysAndValues = append(keysAndValues, "blockedReason", blockedReason)
+	}
+	names := make([]string, 0, len(opts.DecisionValues))
+	for name := range opts.DecisionValues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		keysAndValues = append(keysAndValues, name, opts.DecisionValues[name])
+	}
+	klog.InfoS("Eviction decision", keysAndValues...)
+}
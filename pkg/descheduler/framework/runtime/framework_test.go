@@ -23,14 +23,18 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
 
 	deschedulerconfig "github.com/koordinator-sh/koordinator/pkg/descheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/descheduler/framework"
+	deschedulermetrics "github.com/koordinator-sh/koordinator/pkg/descheduler/metrics"
 )
 
 const (
@@ -551,3 +555,90 @@ func TestRunBalancePlugins(t *testing.T) {
 		})
 	}
 }
+
+type fakeEvictionLimiter struct {
+	allowed bool
+	evicted uint
+}
+
+func (l *fakeEvictionLimiter) AllowEvict(pod *corev1.Pod) bool          { return l.allowed }
+func (l *fakeEvictionLimiter) Done(pod *corev1.Pod)                     { l.evicted++ }
+func (l *fakeEvictionLimiter) Reset()                                   { l.evicted = 0 }
+func (l *fakeEvictionLimiter) NodeLimitExceeded(node *corev1.Node) bool { return false }
+func (l *fakeEvictionLimiter) TotalEvicted() uint                       { return l.evicted }
+
+func TestEvictorProxyPolicyMetrics(t *testing.T) {
+	deschedulermetrics.Register()
+
+	profile := &deschedulerconfig.DeschedulerProfile{
+		Name: testProfileName,
+		Plugins: &deschedulerconfig.Plugins{
+			Evict: deschedulerconfig.PluginSet{
+				Enabled: []deschedulerconfig.Plugin{
+					{Name: evictorPluginName},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod"},
+		Spec:       corev1.PodSpec{NodeName: "test-node"},
+	}
+	opts := framework.EvictOptions{
+		Reason:         "node is overutilized",
+		DecisionValues: map[string]string{"cpu/usagePercentage": "90.00"},
+	}
+	getCounter := func(m metrics.CounterMetric) float64 {
+		v, err := testutil.GetCounterMetricValue(m)
+		assert.NoError(t, err)
+		return v
+	}
+
+	tests := []struct {
+		name          string
+		dryRun        bool
+		allowed       bool
+		want          bool
+		blockedReason string
+	}{
+		{
+			name:          "blocked by the eviction limiter",
+			dryRun:        true,
+			allowed:       false,
+			want:          false,
+			blockedReason: deschedulermetrics.EvictionBlockedByLimiter,
+		},
+		{
+			name:          "rejected by the evictor",
+			allowed:       true,
+			want:          false,
+			blockedReason: deschedulermetrics.EvictionBlockedByEvictor,
+		},
+		{
+			name:    "evicted in dry run mode",
+			dryRun:  true,
+			allowed: true,
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := "strategy-" + tt.name
+			limiter := &fakeEvictionLimiter{allowed: tt.allowed}
+			f, err := NewFramework(registry, profile, WithDryRun(tt.dryRun), WithEvictionLimiter(limiter))
+			assert.NoError(t, err)
+
+			ctx := framework.PluginNameWithContext(context.TODO(), strategy)
+			assert.Equal(t, tt.want, f.Evictor().Evict(ctx, pod, opts))
+
+			assert.Equal(t, float64(1), getCounter(deschedulermetrics.PolicyEvictionsRequested.WithLabelValues(strategy)))
+			for _, reason := range []string{deschedulermetrics.EvictionBlockedByLimiter, deschedulermetrics.EvictionBlockedByEvictor} {
+				var want float64
+				if reason == tt.blockedReason {
+					want = 1
+				}
+				assert.Equal(t, want, getCounter(deschedulermetrics.PolicyEvictionsBlocked.WithLabelValues(strategy, reason)), reason)
+			}
+		})
+	}
+}
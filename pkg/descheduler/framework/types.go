@@ -112,6 +112,9 @@ type EvictOptions struct {
 	PluginName string
 	// Reason allows for passing details about the specific eviction for logging.
 	Reason string
+	// DecisionValues holds the metric values which triggered the eviction, e.g. the usage and the thresholds of the
+	// node, they are recorded in the decision logs for auditing why the pod was evicted.
+	DecisionValues map[string]string
 	// DeleteOptions holds the arguments used to delete
 	DeleteOptions *metav1.DeleteOptions
 }
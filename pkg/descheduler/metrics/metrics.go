@@ -38,11 +38,48 @@ var (
 			StabilityLevel: metrics.ALPHA,
 		}, []string{"result", "strategy", "namespace", "node"})
 
+	PolicyCandidatesEvaluated = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "policy_candidates_evaluated",
+			Help:           "Number of pods evaluated as the eviction candidates, by the strategy",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"strategy"})
+
+	PolicyEvictionsRequested = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "policy_evictions_requested",
+			Help:           "Number of evictions requested, by the strategy",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"strategy"})
+
+	PolicyEvictionsBlocked = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "policy_evictions_blocked",
+			Help:           "Number of evictions blocked, by the strategy, by the reason",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"strategy", "reason"})
+
 	metricsList = []metrics.Registerable{
 		PodsEvicted,
+		PolicyCandidatesEvaluated,
+		PolicyEvictionsRequested,
+		PolicyEvictionsBlocked,
 	}
 )
 
+// The reasons of the blocked evictions.
+const (
+	// EvictionBlockedByFilter means the candidate is rejected by the filters of the strategy.
+	EvictionBlockedByFilter = "Filtered"
+	// EvictionBlockedByLimiter means the eviction exceeds the limits of the evictions per node, namespace or workload.
+	EvictionBlockedByLimiter = "LimitExceeded"
+	// EvictionBlockedByEvictor means the evictor fails or refuses to evict the pod.
+	EvictionBlockedByEvictor = "EvictorRejected"
+)
+
 var registerMetrics sync.Once
 
 // Register all metrics.
@@ -36,8 +36,34 @@ const (
 	// ElasticQuotaValidatingWebhook enables validating webhook for ElasticQuotas creations or updates
 	ElasticQuotaValidatingWebhook featuregate.Feature = "ElasticValidatingWebhook"
 
+	// ReservationMutatingWebhook enables mutating webhook for Reservations creations.
+	ReservationMutatingWebhook featuregate.Feature = "ReservationMutatingWebhook"
+
+	// ReservationValidatingWebhook enables validating webhook for Reservations creations or updates.
+	ReservationValidatingWebhook featuregate.Feature = "ReservationValidatingWebhook"
+
 	// WebhookFramework enables webhook framework
 	WebhookFramework featuregate.Feature = "WebhookFramework"
+
+	// EvictionBudgetWebhook enables validating webhook for Pod evictions initiated by koordinator components,
+	// which limits the evictions according to the ClusterEvictionBudget.
+	EvictionBudgetWebhook featuregate.Feature = "EvictionBudgetWebhook"
+
+	// FederationCapacityExporter enables exporting the colocation capacity of the cluster for the multi-cluster
+	// schedulers.
+	FederationCapacityExporter featuregate.Feature = "FederationCapacityExporter"
+
+	// ProactiveReservation enables creating the Reservations for the annotated Deployments and StatefulSets ahead of
+	// their rollouts.
+	ProactiveReservation featuregate.Feature = "ProactiveReservation"
+
+	// ReservationQuotaAdmission enables the reservation validating webhook to reject the Reservations exceeding the max
+	// of their ElasticQuotas, where the pending Reservations are charged since admitted.
+	ReservationQuotaAdmission featuregate.Feature = "ReservationQuotaAdmission"
+
+	// PodAnnotationProtocolValidation enables the pod validating webhook to reject the Pods whose annotations of the
+	// koordinator protocols violate the published schemas. Only the annotations created or changed are validated.
+	PodAnnotationProtocolValidation featuregate.Feature = "PodAnnotationProtocolValidation"
 )
 
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
@@ -45,7 +71,15 @@ var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	PodValidatingWebhook:          {Default: true, PreRelease: featuregate.Beta},
 	ElasticQuotaMutatingWebhook:   {Default: true, PreRelease: featuregate.Beta},
 	ElasticQuotaValidatingWebhook: {Default: true, PreRelease: featuregate.Beta},
+	ReservationMutatingWebhook:    {Default: false, PreRelease: featuregate.Alpha},
+	ReservationValidatingWebhook:  {Default: false, PreRelease: featuregate.Alpha},
 	WebhookFramework:              {Default: true, PreRelease: featuregate.Beta},
+	EvictionBudgetWebhook:         {Default: false, PreRelease: featuregate.Alpha},
+	FederationCapacityExporter:    {Default: false, PreRelease: featuregate.Alpha},
+	ProactiveReservation:          {Default: false, PreRelease: featuregate.Alpha},
+	ReservationQuotaAdmission:     {Default: false, PreRelease: featuregate.Alpha},
+
+	PodAnnotationProtocolValidation: {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
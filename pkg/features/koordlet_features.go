@@ -108,6 +108,89 @@ const (
 	//
 	// PSICollector enables psi collector feature of koordlet.
 	PSICollector featuregate.Feature = "PSICollector"
+
+	// alpha: v1.1
+	//
+	// PodStatsServer enables the local gRPC server of koordlet which exports the per-pod resource usage
+	// and applied QoS parameters to the co-resident agents.
+	PodStatsServer featuregate.Feature = "PodStatsServer"
+
+	// alpha: v1.1
+	//
+	// CPUSetDriftRepair verifies the container cpuset cgroups against the cpuset allocated by the scheduler,
+	// and repairs the drift, e.g. the cgroups rewritten by kubelet after restart.
+	CPUSetDriftRepair featuregate.Feature = "CPUSetDriftRepair"
+
+	// alpha: v1.1
+	//
+	// NUMAStatCollector enables the collector of the memory NUMA locality of pods and the numastat of NUMA nodes.
+	NUMAStatCollector featuregate.Feature = "NUMAStatCollector"
+
+	// alpha: v1.1
+	//
+	// RDMAStatCollector enables the collector of the traffic and congestion counters of RDMA devices, which are
+	// attributed to the pods by the SR-IOV virtual functions allocated to them.
+	RDMAStatCollector featuregate.Feature = "RDMAStatCollector"
+
+	// alpha: v1.1
+	//
+	// LogLevelHTTPHandler is used to get and adjust the log verbosity of koordlet modules from koordlet port.
+	LogLevelHTTPHandler featuregate.Feature = "LogLevelHTTPHandler"
+
+	// alpha: v1.1
+	//
+	// KernelLogWatcher watches the kernel log for the GPU Xid errors, NIC link flaps and RDMA link errors, which are
+	// reported as the Device conditions and the node events. It only works with the Accelerators enabled.
+	KernelLogWatcher featuregate.Feature = "KernelLogWatcher"
+
+	// alpha: v1.1
+	//
+	// ContainerCheckpoint checkpoints the containers of the opted-in pods via the kubelet checkpoint API when
+	// requested by the PodMigrationJobs, so that the migrated pods can be restored from the checkpoints.
+	ContainerCheckpoint featuregate.Feature = "ContainerCheckpoint"
+
+	// alpha: v1.1
+	//
+	// SchedLatencyCollector enables the eBPF collector of the run queue latency of pods, i.e. the delay between the
+	// wakeup and the run of the tasks, which requires the kernel supporting the eBPF tracepoint programs.
+	SchedLatencyCollector featuregate.Feature = "SchedLatencyCollector"
+
+	// alpha: v1.1
+	//
+	// ColdMemoryCollector enables the collector of the pods' cold memory from the idle page statistics of kidled,
+	// which is reported as the reclaimable usage in NodeMetric. It requires the kernel supporting kidled.
+	ColdMemoryCollector featuregate.Feature = "ColdMemoryCollector"
+
+	// alpha: v1.1
+	//
+	// CPIInterferenceDetection flags the LS pods whose CPI rises significantly over their baseline while the BE pods
+	// are running, i.e. degraded by the noisy neighbors. It only works with the CPICollector enabled.
+	CPIInterferenceDetection featuregate.Feature = "CPIInterferenceDetection"
+
+	// alpha: v1.1
+	//
+	// ResctrlMonitor measures the LLC occupancy and memory bandwidth of the QoS classes and pods by the resctrl
+	// monitoring groups (Intel RDT CMT/MBM), which also lifts the MBA throttling when the memory bandwidth of the
+	// node is not contended. It only works with the RdtResctrl enabled.
+	ResctrlMonitor featuregate.Feature = "ResctrlMonitor"
+
+	// alpha: v1.1
+	//
+	// NodeTuning applies the sysctls and the IRQ affinity of the network devices declared by the node tuning profile
+	// in the NodeSLO, and rolls back the values failing the verification or removed from the profile.
+	NodeTuning featuregate.Feature = "NodeTuning"
+
+	// alpha: v1.1
+	//
+	// MetricsExporter exposes the node, pod and container metrics in the metric cache on the Prometheus endpoint of
+	// koordlet, so the clusters without a separate node-exporter or cadvisor pipeline can scrape them.
+	MetricsExporter featuregate.Feature = "MetricsExporter"
+
+	// alpha: v1.1
+	//
+	// AdaptiveNodeMetricReport reports the NodeMetric sooner on the significant usage changes and slower when the
+	// usages are stable, and quantizes the pod usages, to reduce the write load of apiserver in the large clusters.
+	AdaptiveNodeMetricReport featuregate.Feature = "AdaptiveNodeMetricReport"
 )
 
 func init() {
@@ -119,19 +202,33 @@ var (
 	DefaultKoordletFeatureGate        featuregate.FeatureGate        = DefaultMutableKoordletFeatureGate
 
 	defaultKoordletFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-		AuditEvents:            {Default: false, PreRelease: featuregate.Alpha},
-		AuditEventsHTTPHandler: {Default: false, PreRelease: featuregate.Alpha},
-		BECPUSuppress:          {Default: true, PreRelease: featuregate.Beta},
-		BECPUEvict:             {Default: false, PreRelease: featuregate.Alpha},
-		BEMemoryEvict:          {Default: false, PreRelease: featuregate.Alpha},
-		CPUBurst:               {Default: true, PreRelease: featuregate.Beta},
-		SystemConfig:           {Default: false, PreRelease: featuregate.Alpha},
-		RdtResctrl:             {Default: true, PreRelease: featuregate.Beta},
-		CgroupReconcile:        {Default: false, PreRelease: featuregate.Alpha},
-		NodeTopologyReport:     {Default: true, PreRelease: featuregate.Beta},
-		Accelerators:           {Default: false, PreRelease: featuregate.Alpha},
-		CPICollector:           {Default: false, PreRelease: featuregate.Alpha},
-		PSICollector:           {Default: false, PreRelease: featuregate.Alpha},
+		AuditEvents:              {Default: false, PreRelease: featuregate.Alpha},
+		AuditEventsHTTPHandler:   {Default: false, PreRelease: featuregate.Alpha},
+		BECPUSuppress:            {Default: true, PreRelease: featuregate.Beta},
+		BECPUEvict:               {Default: false, PreRelease: featuregate.Alpha},
+		BEMemoryEvict:            {Default: false, PreRelease: featuregate.Alpha},
+		CPUBurst:                 {Default: true, PreRelease: featuregate.Beta},
+		SystemConfig:             {Default: false, PreRelease: featuregate.Alpha},
+		RdtResctrl:               {Default: true, PreRelease: featuregate.Beta},
+		CgroupReconcile:          {Default: false, PreRelease: featuregate.Alpha},
+		NodeTopologyReport:       {Default: true, PreRelease: featuregate.Beta},
+		Accelerators:             {Default: false, PreRelease: featuregate.Alpha},
+		CPICollector:             {Default: false, PreRelease: featuregate.Alpha},
+		PSICollector:             {Default: false, PreRelease: featuregate.Alpha},
+		PodStatsServer:           {Default: false, PreRelease: featuregate.Alpha},
+		CPUSetDriftRepair:        {Default: false, PreRelease: featuregate.Alpha},
+		NUMAStatCollector:        {Default: false, PreRelease: featuregate.Alpha},
+		RDMAStatCollector:        {Default: false, PreRelease: featuregate.Alpha},
+		LogLevelHTTPHandler:      {Default: false, PreRelease: featuregate.Alpha},
+		KernelLogWatcher:         {Default: false, PreRelease: featuregate.Alpha},
+		ContainerCheckpoint:      {Default: false, PreRelease: featuregate.Alpha},
+		SchedLatencyCollector:    {Default: false, PreRelease: featuregate.Alpha},
+		ColdMemoryCollector:      {Default: false, PreRelease: featuregate.Alpha},
+		CPIInterferenceDetection: {Default: false, PreRelease: featuregate.Alpha},
+		ResctrlMonitor:           {Default: false, PreRelease: featuregate.Alpha},
+		NodeTuning:               {Default: false, PreRelease: featuregate.Alpha},
+		MetricsExporter:          {Default: false, PreRelease: featuregate.Alpha},
+		AdaptiveNodeMetricReport: {Default: false, PreRelease: featuregate.Alpha},
 	}
 )
 
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package koordctl
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+
+	koordinatorclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+)
+
+// clientGetter returns the client of the koordinator resources built from the kubeconfig flags.
+type clientGetter func() (koordinatorclientset.Interface, error)
+
+// NewKoordctlCommand returns the root command of kubectl-koord. The binary works as a kubectl plugin once it is
+// installed in PATH, e.g. `kubectl koord reservation list`.
+func NewKoordctlCommand(out io.Writer) *cobra.Command {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+
+	cmd := &cobra.Command{
+		Use:           "kubectl-koord",
+		Short:         "kubectl-koord inspects the koordinator resources",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&loadingRules.ExplicitPath, clientcmd.RecommendedConfigPathFlag, "", "Path to the kubeconfig file to use for CLI requests.")
+	clientcmd.BindOverrideFlags(overrides, flags, clientcmd.RecommendedConfigOverrideFlags(""))
+
+	getClient := func() (koordinatorclientset.Interface, error) {
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		return koordinatorclientset.NewForConfig(config)
+	}
+	cmd.AddCommand(newReservationCommand(getClient, out))
+	cmd.AddCommand(newSchedulerConfigCommand(out))
+	return cmd
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package koordctl
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newReservationCommand(getClient clientGetter, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "reservation",
+		Aliases: []string{"reservations"},
+		Short:   "Inspect the reservations",
+	}
+	cmd.AddCommand(newReservationListCommand(getClient, out))
+	cmd.AddCommand(newReservationDescribeCommand(getClient, out))
+	return cmd
+}
+
+func newReservationListCommand(getClient clientGetter, out io.Writer) *cobra.Command {
+	var selector, nodeName string
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the reservations with the reserved and allocated resources, the owners and the remaining TTL",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			reservationList, err := client.SchedulingV1alpha1().Reservations().List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			var infos []*reservationInfo
+			for i := range reservationList.Items {
+				r := &reservationList.Items[i]
+				if nodeName != "" && r.Status.NodeName != nodeName {
+					continue
+				}
+				infos = append(infos, newReservationInfo(r, now))
+			}
+			sort.Slice(infos, func(i, j int) bool {
+				return infos[i].name < infos[j].name
+			})
+			return printReservationList(out, infos)
+		},
+	}
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.")
+	cmd.Flags().StringVar(&nodeName, "node", "", "Only list the reservations on the node.")
+	return cmd
+}
+
+func newReservationDescribeCommand(getClient clientGetter, out io.Writer) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe NAME [NAME...]",
+		Short: "Show the details of the reservations, including the owners, the allocated pods and devices",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := getClient()
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			for i, name := range args {
+				r, err := client.SchedulingV1alpha1().Reservations().Get(context.TODO(), name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					if _, err := io.WriteString(out, "\n\n"); err != nil {
+						return err
+					}
+				}
+				info := newReservationInfo(r, now)
+				info.devices = resolveReservationDevices(r, func(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error) {
+					return client.SchedulingV1alpha1().PodDeviceAllocations(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+				})
+				if err := describeReservation(out, info); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
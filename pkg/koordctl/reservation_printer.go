@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package koordctl
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+const (
+	noneValue = "<none>"
+	// maxListedOwners is the number of the current owners shown in a row of the list.
+	maxListedOwners = 2
+)
+
+// reservationInfo is the printable summary of a reservation.
+type reservationInfo struct {
+	reservation *schedulingv1alpha1.Reservation
+	name        string
+	phase       string
+	node        string
+	reserved    corev1.ResourceList
+	allocated   corev1.ResourceList
+	owners      []string
+	devices     apiext.DeviceAllocations
+	ttl         string
+	age         string
+}
+
+func newReservationInfo(r *schedulingv1alpha1.Reservation, now time.Time) *reservationInfo {
+	info := &reservationInfo{
+		reservation: r,
+		name:        r.Name,
+		phase:       string(r.Status.Phase),
+		node:        r.Status.NodeName,
+		reserved:    r.Status.Allocatable,
+		allocated:   r.Status.Allocated,
+		ttl:         getRemainingTTL(r, now),
+		age:         translateTimestampSince(r.CreationTimestamp, now),
+	}
+	if info.phase == "" {
+		info.phase = string(schedulingv1alpha1.ReservationPending)
+	}
+	if info.node == "" {
+		info.node = noneValue
+	}
+	// the allocatable is set once the reservation is scheduled, the requests of the template are reserved before
+	if len(info.reserved) == 0 && r.Spec.Template != nil {
+		info.reserved, _ = resourceapi.PodRequestsAndLimits(reservationutil.NewReservePod(r))
+	}
+	for _, owner := range r.Status.CurrentOwners {
+		info.owners = append(info.owners, formatObjectReference(&owner))
+	}
+	return info
+}
+
+// resolveReservationDevices returns the devices held by the reservation, which are read from the PodDeviceAllocation
+// referenced by the reservation if any, the same as the pods. The devices failed to resolve are not shown.
+func resolveReservationDevices(r *schedulingv1alpha1.Reservation, getter apiext.PodDeviceAllocationGetter) apiext.DeviceAllocations {
+	reservePod := reservationutil.NewReservePod(r)
+	devices, err := apiext.ResolveDeviceAllocations(reservePod.Namespace, reservePod.UID, r.Annotations, getter)
+	if err != nil {
+		return nil
+	}
+	return devices
+}
+
+// getRemainingTTL returns the time left before the reservation expires.
+func getRemainingTTL(r *schedulingv1alpha1.Reservation, now time.Time) string {
+	if reservationutil.IsReservationFailed(r) || reservationutil.IsReservationSucceeded(r) {
+		return "-"
+	}
+	expirationTime := reservationutil.GetReservationExpirationTime(r)
+	if expirationTime == nil {
+		return noneValue
+	}
+	if !now.Before(*expirationTime) {
+		return "expired"
+	}
+	return duration.HumanDuration(expirationTime.Sub(now))
+}
+
+func translateTimestampSince(timestamp metav1.Time, now time.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(timestamp.Time))
+}
+
+func formatResourceList(resourceList corev1.ResourceList) string {
+	if len(resourceList) == 0 {
+		return noneValue
+	}
+	names := make([]string, 0, len(resourceList))
+	for name := range resourceList {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	items := make([]string, 0, len(names))
+	for _, name := range names {
+		quantity := resourceList[corev1.ResourceName(name)]
+		items = append(items, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	return strings.Join(items, ",")
+}
+
+func formatObjectReference(ref *corev1.ObjectReference) string {
+	name := ref.Name
+	if ref.Namespace != "" {
+		name = ref.Namespace + "/" + ref.Name
+	}
+	if ref.Kind != "" && ref.Kind != "Pod" {
+		name = ref.Kind + "/" + name
+	}
+	return name
+}
+
+func formatOwners(owners []string) string {
+	if len(owners) == 0 {
+		return noneValue
+	}
+	if len(owners) > maxListedOwners {
+		return fmt.Sprintf("%s +%d", strings.Join(owners[:maxListedOwners], ","), len(owners)-maxListedOwners)
+	}
+	return strings.Join(owners, ",")
+}
+
+func printReservationList(out io.Writer, infos []*reservationInfo) error {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPHASE\tNODE\tRESERVED\tALLOCATED\tOWNERS\tTTL\tAGE")
+	for _, info := range infos {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", info.name, info.phase, info.node,
+			formatResourceList(info.reserved), formatResourceList(info.allocated), formatOwners(info.owners), info.ttl, info.age)
+	}
+	return w.Flush()
+}
+
+func describeReservation(out io.Writer, info *reservationInfo) error {
+	r := info.reservation
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", info.name)
+	fmt.Fprintf(w, "Phase:\t%s\n", info.phase)
+	fmt.Fprintf(w, "Node:\t%s\n", info.node)
+	fmt.Fprintf(w, "Created:\t%s (%s ago)\n", r.CreationTimestamp.Format(time.RFC3339), info.age)
+	if expirationTime := reservationutil.GetReservationExpirationTime(r); expirationTime != nil {
+		fmt.Fprintf(w, "Expires:\t%s (%s)\n", expirationTime.Format(time.RFC3339), info.ttl)
+	} else {
+		fmt.Fprintf(w, "Expires:\t%s\n", noneValue)
+	}
+	fmt.Fprintf(w, "AllocateOnce:\t%s\n", strconv.FormatBool(r.Spec.AllocateOnce))
+	if r.Spec.AllocatePolicy != "" {
+		fmt.Fprintf(w, "AllocatePolicy:\t%s\n", r.Spec.AllocatePolicy)
+	}
+	fmt.Fprintf(w, "Reserved:\t%s\n", formatResourceList(info.reserved))
+	fmt.Fprintf(w, "Allocated:\t%s\n", formatResourceList(info.allocated))
+
+	fmt.Fprintf(w, "Owners:\n")
+	if len(r.Spec.Owners) == 0 {
+		fmt.Fprintf(w, "  %s\n", noneValue)
+	}
+	for i := range r.Spec.Owners {
+		owner := &r.Spec.Owners[i]
+		if owner.Object != nil {
+			fmt.Fprintf(w, "  Object:\t%s\n", formatObjectReference(owner.Object))
+		}
+		if owner.Controller != nil {
+			fmt.Fprintf(w, "  Controller:\t%s/%s/%s\n", owner.Controller.Kind, owner.Controller.Namespace, owner.Controller.Name)
+		}
+		if owner.LabelSelector != nil {
+			fmt.Fprintf(w, "  LabelSelector:\t%s\n", metav1.FormatLabelSelector(owner.LabelSelector))
+		}
+		if owner.NodeAffinity != nil {
+			fmt.Fprintf(w, "  NodeAffinity:\t%d term(s)\n", len(owner.NodeAffinity.NodeSelectorTerms))
+		}
+	}
+
+	fmt.Fprintf(w, "Current Owners:\n")
+	if len(info.owners) == 0 {
+		fmt.Fprintf(w, "  %s\n", noneValue)
+	}
+	for _, owner := range info.owners {
+		fmt.Fprintf(w, "  %s\n", owner)
+	}
+
+	fmt.Fprintf(w, "Allocated Pods:\n")
+	if len(r.Status.AllocatedPods) == 0 {
+		fmt.Fprintf(w, "  %s\n", noneValue)
+	}
+	for _, pod := range r.Status.AllocatedPods {
+		fmt.Fprintf(w, "  %s/%s\t%s\n", pod.Namespace, pod.Name, formatResourceList(pod.Resources))
+	}
+
+	if devices := info.devices; len(devices) > 0 {
+		fmt.Fprintf(w, "Devices:\n")
+		deviceTypes := make([]string, 0, len(devices))
+		for deviceType := range devices {
+			deviceTypes = append(deviceTypes, string(deviceType))
+		}
+		sort.Strings(deviceTypes)
+		for _, deviceType := range deviceTypes {
+			var minors []string
+			for _, allocation := range devices[schedulingv1alpha1.DeviceType(deviceType)] {
+				minors = append(minors, strconv.Itoa(int(allocation.Minor)))
+			}
+			fmt.Fprintf(w, "  %s:\tminors %s\n", deviceType, strings.Join(minors, ","))
+		}
+	}
+
+	fmt.Fprintf(w, "Conditions:\n")
+	if len(r.Status.Conditions) == 0 {
+		fmt.Fprintf(w, "  %s\n", noneValue)
+	} else {
+		fmt.Fprintf(w, "  Type\tStatus\tReason\tLastTransitionTime\tMessage\n")
+		fmt.Fprintf(w, "  ----\t------\t------\t------------------\t-------\n")
+	}
+	for _, condition := range r.Status.Conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason,
+			condition.LastTransitionTime.Format(time.RFC3339), condition.Message)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package koordctl
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordinatorclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func newTestReservation(name, nodeName string, phase schedulingv1alpha1.ReservationPhase, createTime time.Time) *schedulingv1alpha1.Reservation {
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(createTime),
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("4"),
+									corev1.ResourceMemory: resource.MustParse("8Gi"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				},
+			},
+			TTL: &metav1.Duration{Duration: time.Hour},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    phase,
+			NodeName: nodeName,
+		},
+	}
+}
+
+func Test_newReservationInfo(t *testing.T) {
+	now := time.Now()
+	pending := newTestReservation("r-pending", "", schedulingv1alpha1.ReservationPending, now.Add(-10*time.Minute))
+	info := newReservationInfo(pending, now)
+	assert.Equal(t, "Pending", info.phase)
+	assert.Equal(t, noneValue, info.node)
+	assert.Equal(t, "cpu=4,memory=8Gi", formatResourceList(info.reserved))
+	assert.Equal(t, noneValue, formatResourceList(info.allocated))
+	assert.Equal(t, "50m", info.ttl)
+	assert.Equal(t, "10m", info.age)
+
+	available := newTestReservation("r-available", "test-node", schedulingv1alpha1.ReservationAvailable, now.Add(-2*time.Hour))
+	available.Status.Allocatable = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+	available.Status.Allocated = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+	available.Status.CurrentOwners = []corev1.ObjectReference{
+		{Namespace: "default", Name: "pod-1"},
+		{Namespace: "default", Name: "pod-2"},
+		{Namespace: "default", Name: "pod-3"},
+	}
+	info = newReservationInfo(available, now)
+	assert.Equal(t, "test-node", info.node)
+	assert.Equal(t, "cpu=2", formatResourceList(info.reserved))
+	assert.Equal(t, "cpu=1", formatResourceList(info.allocated))
+	assert.Equal(t, "default/pod-1,default/pod-2 +1", formatOwners(info.owners))
+	assert.Equal(t, "expired", info.ttl)
+
+	failed := newTestReservation("r-failed", "", schedulingv1alpha1.ReservationFailed, now)
+	assert.Equal(t, "-", newReservationInfo(failed, now).ttl)
+
+	noTTL := newTestReservation("r-no-ttl", "", schedulingv1alpha1.ReservationPending, now)
+	noTTL.Spec.TTL = &metav1.Duration{}
+	assert.Equal(t, noneValue, newReservationInfo(noTTL, now).ttl)
+}
+
+func TestReservationCommand(t *testing.T) {
+	now := time.Now()
+	r1 := newTestReservation("r1", "node-1", schedulingv1alpha1.ReservationAvailable, now)
+	r1.Status.Allocatable = r1.Spec.Template.Spec.Containers[0].Resources.Requests
+	r1.Status.CurrentOwners = []corev1.ObjectReference{{Namespace: "default", Name: "pod-1"}}
+	r1.Status.AllocatedPods = []schedulingv1alpha1.ReservationAllocatedPod{
+		{
+			Namespace: "default",
+			Name:      "pod-1",
+			Resources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		},
+	}
+	r2 := newTestReservation("r2", "node-2", schedulingv1alpha1.ReservationAvailable, now)
+	// the devices of r2 are stored in the PodDeviceAllocation referenced by it
+	r2.UID = "r2-uid"
+	podDeviceAllocation := apiext.NewPodDeviceAllocation(reservationutil.NewReservePod(r2), "node-2", apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 0, Resources: corev1.ResourceList{apiext.ResourceGPUCore: resource.MustParse("100")}},
+			{Minor: 1, Resources: corev1.ResourceList{apiext.ResourceGPUCore: resource.MustParse("100")}},
+		},
+	})
+	r2.Annotations = map[string]string{apiext.AnnotationDeviceAllocationRef: podDeviceAllocation.Name}
+	client := koordfake.NewSimpleClientset(r1, r2, podDeviceAllocation)
+	getClient := func() (koordinatorclientset.Interface, error) {
+		return client, nil
+	}
+
+	runCommand := func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		cmd := newReservationCommand(getClient, out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	got, err := runCommand("list")
+	assert.NoError(t, err)
+	assert.Contains(t, got, "NAME")
+	assert.Contains(t, got, "r1")
+	assert.Contains(t, got, "r2")
+	assert.Contains(t, got, "default/pod-1")
+
+	got, err = runCommand("list", "--node", "node-2")
+	assert.NoError(t, err)
+	assert.NotContains(t, got, "r1")
+	assert.Contains(t, got, "r2")
+
+	got, err = runCommand("describe", "r1")
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Name:")
+	assert.Contains(t, got, "cpu=4,memory=8Gi")
+	assert.Contains(t, got, "app=test")
+	assert.Contains(t, got, "default/pod-1")
+	assert.Contains(t, got, "cpu=1")
+	assert.NotContains(t, got, "Devices:")
+
+	got, err = runCommand("describe", "r2")
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Devices:")
+	assert.Contains(t, got, "minors 0,1")
+
+	_, err = runCommand("describe", "not-found")
+	assert.Error(t, err)
+}
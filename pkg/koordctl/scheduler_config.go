@@ -0,0 +1,423 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package koordctl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+	schedconfigv1beta2 "k8s.io/kube-scheduler/config/v1beta2"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	schedvalidation "k8s.io/kubernetes/pkg/scheduler/apis/config/validation"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/scheme"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/v1beta2"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+)
+
+const (
+	defaultSchedulerName           = "koord-scheduler"
+	defaultLeaderElectionNamespace = "koordinator-system"
+
+	loadAwareSchedulingPlugin = "LoadAwareScheduling"
+	nodeNUMAResourcePlugin    = "NodeNUMAResource"
+	deviceSharePlugin         = "DeviceShare"
+	reservationPlugin         = "Reservation"
+	batchResourceFitPlugin    = "BatchResourceFit"
+	coschedulingPlugin        = "Coscheduling"
+	elasticQuotaPlugin        = "ElasticQuota"
+	preferredNodesPlugin      = "PreferredNodes"
+
+	nodeResourcesFitPlugin  = "NodeResourcesFit"
+	defaultPreemptionPlugin = "DefaultPreemption"
+	defaultBinderPlugin     = "DefaultBinder"
+)
+
+// schedulerConfigIntent is the high-level intent of the koord-scheduler profile, from which the complete
+// KubeSchedulerConfiguration is generated. The colocation plugins, i.e. BatchResourceFit and the batch resources
+// scored by NodeResourcesFit, are always enabled.
+type schedulerConfigIntent struct {
+	// SchedulerName is the name of the profile, defaults to koord-scheduler.
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// LeaderElection configures the leader election of the scheduler, which is enabled by default.
+	LeaderElection *leaderElectionIntent `json:"leaderElection,omitempty"`
+	// LoadAware enables the LoadAwareScheduling to filter and score the nodes by the actual usages.
+	LoadAware *loadAwareIntent `json:"loadAware,omitempty"`
+	// GPUShare enables the DeviceShare to allocate the shared GPUs and other devices.
+	GPUShare bool `json:"gpuShare,omitempty"`
+	// Gang enables the Coscheduling to schedule the gangs all or nothing.
+	Gang bool `json:"gang,omitempty"`
+	// ElasticQuota enables the ElasticQuota to schedule the pods by the quota groups.
+	ElasticQuota *elasticQuotaIntent `json:"elasticQuota,omitempty"`
+	// Reservation enables the Reservation to reserve the resources for the owner pods.
+	Reservation bool `json:"reservation,omitempty"`
+	// NUMA enables the NodeNUMAResource to bind the CPUs and allocate the NUMA resources.
+	NUMA bool `json:"numa,omitempty"`
+}
+
+type leaderElectionIntent struct {
+	LeaderElect       *bool  `json:"leaderElect,omitempty"`
+	ResourceNamespace string `json:"resourceNamespace,omitempty"`
+}
+
+type loadAwareIntent struct {
+	// UsageThresholds are the usage thresholds in percentage of the nodes to filter, e.g. cpu: 65.
+	UsageThresholds map[corev1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+	// ProdUsageThresholds are the usage thresholds in percentage of the Prod pods on the nodes to filter.
+	ProdUsageThresholds map[corev1.ResourceName]int64 `json:"prodUsageThresholds,omitempty"`
+}
+
+type elasticQuotaIntent struct {
+	QuotaGroupNamespace string `json:"quotaGroupNamespace,omitempty"`
+}
+
+func newSchedulerConfigCommand(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scheduler-config",
+		Short: "Generate and validate the koord-scheduler configuration",
+	}
+	cmd.AddCommand(newSchedulerConfigGenerateCommand(out))
+	cmd.AddCommand(newSchedulerConfigValidateCommand(out))
+	return cmd
+}
+
+func newSchedulerConfigGenerateCommand(out io.Writer) *cobra.Command {
+	var intentFile string
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate the KubeSchedulerConfiguration of koord-scheduler from an intent file",
+		Example: `  # intent.yaml
+  loadAware:
+    usageThresholds:
+      cpu: 65
+      memory: 95
+  gpuShare: true
+  gang: true
+
+  kubectl koord scheduler-config generate -f intent.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(intentFile)
+			if err != nil {
+				return err
+			}
+			intent := &schedulerConfigIntent{}
+			if err := yaml.UnmarshalStrict(data, intent); err != nil {
+				return fmt.Errorf("failed to parse the intent file %s, err: %w", intentFile, err)
+			}
+			configData, err := generateSchedulerConfig(intent)
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(configData)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&intentFile, "filename", "f", "", "Path to the intent file.")
+	_ = cmd.MarkFlagRequired("filename")
+	return cmd
+}
+
+func newSchedulerConfigValidateCommand(out io.Writer) *cobra.Command {
+	var configFile string
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the KubeSchedulerConfiguration of koord-scheduler, including the koordinator plugin args",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(configFile)
+			if err != nil {
+				return err
+			}
+			if err := validateSchedulerConfig(data); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(out, "%s is valid\n", configFile)
+			return err
+		},
+	}
+	cmd.Flags().StringVarP(&configFile, "filename", "f", "", "Path to the KubeSchedulerConfiguration file.")
+	_ = cmd.MarkFlagRequired("filename")
+	return cmd
+}
+
+// generateSchedulerConfig generates the KubeSchedulerConfiguration in YAML from the intent, which is validated
+// by decoding it in the same way as koord-scheduler.
+func generateSchedulerConfig(intent *schedulerConfigIntent) ([]byte, error) {
+	cfg, err := newSchedulerConfig(intent)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := runtime.SerializerInfoForMediaType(scheme.Codecs.SupportedMediaTypes(), runtime.ContentTypeYAML)
+	if !ok {
+		return nil, fmt.Errorf("unable to locate encoder -- %q is not a supported media type", runtime.ContentTypeYAML)
+	}
+	encoder := scheme.Codecs.EncoderForVersion(info.Serializer, schedconfigv1beta2.SchemeGroupVersion)
+	buf := &bytes.Buffer{}
+	if err := encoder.Encode(cfg, buf); err != nil {
+		return nil, err
+	}
+	if err := validateSchedulerConfig(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("generated an invalid scheduler config, err: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newSchedulerConfig(intent *schedulerConfigIntent) (*schedconfigv1beta2.KubeSchedulerConfiguration, error) {
+	schedulerName := intent.SchedulerName
+	if schedulerName == "" {
+		schedulerName = defaultSchedulerName
+	}
+	cfg := &schedconfigv1beta2.KubeSchedulerConfiguration{}
+	cfg.LeaderElection.ResourceLock = "leases"
+	cfg.LeaderElection.ResourceName = schedulerName
+	cfg.LeaderElection.ResourceNamespace = defaultLeaderElectionNamespace
+	if intent.LeaderElection != nil {
+		cfg.LeaderElection.LeaderElect = intent.LeaderElection.LeaderElect
+		if intent.LeaderElection.ResourceNamespace != "" {
+			cfg.LeaderElection.ResourceNamespace = intent.LeaderElection.ResourceNamespace
+		}
+	}
+	componentbaseconfigv1alpha1.RecommendedDefaultLeaderElectionConfiguration(&cfg.LeaderElection)
+	componentbaseconfigv1alpha1.RecommendedDefaultClientConnectionConfiguration(&cfg.ClientConnection)
+
+	enabled := sets.NewString(batchResourceFitPlugin, preferredNodesPlugin)
+	pluginConfig := []schedconfigv1beta2.PluginConfig{
+		newPluginConfig(nodeResourcesFitPlugin, &schedconfigv1beta2.NodeResourcesFitArgs{
+			ScoringStrategy: &schedconfigv1beta2.ScoringStrategy{
+				Type: schedconfigv1beta2.LeastAllocated,
+				Resources: []schedconfigv1beta2.ResourceSpec{
+					{Name: string(corev1.ResourceCPU), Weight: 1},
+					{Name: string(corev1.ResourceMemory), Weight: 1},
+					{Name: string(extension.BatchCPU), Weight: 1},
+					{Name: string(extension.BatchMemory), Weight: 1},
+				},
+			},
+		}),
+	}
+	if intent.LoadAware != nil {
+		enabled.Insert(loadAwareSchedulingPlugin)
+		pluginConfig = append(pluginConfig, newPluginConfig(loadAwareSchedulingPlugin, &v1beta2.LoadAwareSchedulingArgs{
+			UsageThresholds:     intent.LoadAware.UsageThresholds,
+			ProdUsageThresholds: intent.LoadAware.ProdUsageThresholds,
+		}))
+	}
+	if intent.NUMA {
+		enabled.Insert(nodeNUMAResourcePlugin)
+		pluginConfig = append(pluginConfig, newPluginConfig(nodeNUMAResourcePlugin, &v1beta2.NodeNUMAResourceArgs{}))
+	}
+	if intent.GPUShare {
+		enabled.Insert(deviceSharePlugin)
+		pluginConfig = append(pluginConfig, newPluginConfig(deviceSharePlugin, &v1beta2.DeviceShareArgs{}))
+	}
+	if intent.Reservation {
+		enabled.Insert(reservationPlugin)
+		pluginConfig = append(pluginConfig, newPluginConfig(reservationPlugin, &v1beta2.ReservationArgs{}))
+	}
+	if intent.Gang {
+		enabled.Insert(coschedulingPlugin)
+		pluginConfig = append(pluginConfig, newPluginConfig(coschedulingPlugin, &v1beta2.CoschedulingArgs{}))
+	}
+	if intent.ElasticQuota != nil {
+		enabled.Insert(elasticQuotaPlugin)
+		pluginConfig = append(pluginConfig, newPluginConfig(elasticQuotaPlugin, &v1beta2.ElasticQuotaArgs{
+			QuotaGroupNamespace: intent.ElasticQuota.QuotaGroupNamespace,
+		}))
+	}
+	for _, pc := range pluginConfig {
+		scheme.Scheme.Default(pc.Args.Object)
+	}
+
+	cfg.Profiles = []schedconfigv1beta2.KubeSchedulerProfile{
+		{
+			SchedulerName: pointer.String(schedulerName),
+			Plugins:       newProfilePlugins(enabled),
+			PluginConfig:  pluginConfig,
+		},
+	}
+	return cfg, nil
+}
+
+func newPluginConfig(name string, args runtime.Object) schedconfigv1beta2.PluginConfig {
+	return schedconfigv1beta2.PluginConfig{
+		Name: name,
+		Args: runtime.RawExtension{Object: args},
+	}
+}
+
+// newProfilePlugins enables the koordinator plugins at their extension points in the same order as the reference
+// profile in config/manager/scheduler-config.yaml.
+func newProfilePlugins(enabled sets.String) *schedconfigv1beta2.Plugins {
+	pluginSet := func(names ...string) schedconfigv1beta2.PluginSet {
+		ps := schedconfigv1beta2.PluginSet{}
+		for _, name := range names {
+			if enabled.Has(name) {
+				ps.Enabled = append(ps.Enabled, schedconfigv1beta2.Plugin{Name: name})
+			}
+		}
+		return ps
+	}
+	disableAll := []schedconfigv1beta2.Plugin{{Name: "*"}}
+
+	plugins := &schedconfigv1beta2.Plugins{
+		PreFilter: pluginSet(nodeNUMAResourcePlugin, deviceSharePlugin, reservationPlugin, coschedulingPlugin, elasticQuotaPlugin),
+		Filter:    pluginSet(loadAwareSchedulingPlugin, nodeNUMAResourcePlugin, deviceSharePlugin, reservationPlugin, batchResourceFitPlugin),
+		PreScore:  pluginSet(reservationPlugin, preferredNodesPlugin),
+		Score:     pluginSet(loadAwareSchedulingPlugin, nodeNUMAResourcePlugin, deviceSharePlugin, reservationPlugin, preferredNodesPlugin),
+		// Reservation must reserve before DeviceShare, so that the pod inherits the devices of the reservation
+		Reserve:  pluginSet(loadAwareSchedulingPlugin, nodeNUMAResourcePlugin, reservationPlugin, deviceSharePlugin, coschedulingPlugin, elasticQuotaPlugin),
+		Permit:   pluginSet(coschedulingPlugin),
+		PreBind:  pluginSet(nodeNUMAResourcePlugin, deviceSharePlugin, reservationPlugin),
+		PostBind: pluginSet(coschedulingPlugin),
+	}
+	for i := range plugins.Score.Enabled {
+		weight := int32(1)
+		if plugins.Score.Enabled[i].Name == reservationPlugin {
+			// the reserved nodes are preferred over any other scores
+			weight = 5000
+		}
+		plugins.Score.Enabled[i].Weight = pointer.Int32(weight)
+	}
+	if enabled.Has(coschedulingPlugin) {
+		plugins.QueueSort = schedconfigv1beta2.PluginSet{
+			Enabled:  []schedconfigv1beta2.Plugin{{Name: coschedulingPlugin}},
+			Disabled: disableAll,
+		}
+	}
+	// the koordinator plugins try to make room for the pod before the default preemption
+	if postFilter := pluginSet(reservationPlugin, coschedulingPlugin, deviceSharePlugin, elasticQuotaPlugin); len(postFilter.Enabled) > 0 {
+		postFilter.Enabled = append(postFilter.Enabled, schedconfigv1beta2.Plugin{Name: defaultPreemptionPlugin})
+		postFilter.Disabled = disableAll
+		plugins.PostFilter = postFilter
+	}
+	if enabled.Has(reservationPlugin) {
+		plugins.Bind = schedconfigv1beta2.PluginSet{
+			Enabled:  []schedconfigv1beta2.Plugin{{Name: reservationPlugin}, {Name: defaultBinderPlugin}},
+			Disabled: disableAll,
+		}
+	}
+	return plugins
+}
+
+// validateSchedulerConfig decodes the KubeSchedulerConfiguration as koord-scheduler does, and validates it with the
+// in-tree validation, the koordinator plugin args and the combinations of the koordinator plugins.
+func validateSchedulerConfig(data []byte) error {
+	obj, gvk, err := scheme.Codecs.UniversalDecoder().Decode(data, nil, nil)
+	if err != nil {
+		return err
+	}
+	cfg, ok := obj.(*schedconfig.KubeSchedulerConfiguration)
+	if !ok {
+		return fmt.Errorf("couldn't decode as KubeSchedulerConfiguration, got %s", gvk)
+	}
+	if err := schedvalidation.ValidateKubeSchedulerConfiguration(cfg); err != nil {
+		return err
+	}
+	var errs []error
+	for i := range cfg.Profiles {
+		errs = append(errs, validateSchedulerProfile(&cfg.Profiles[i])...)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func validateSchedulerProfile(profile *schedconfig.KubeSchedulerProfile) []error {
+	var errs []error
+	for _, pc := range profile.PluginConfig {
+		var err error
+		switch args := pc.Args.(type) {
+		case *config.LoadAwareSchedulingArgs:
+			err = validation.ValidateLoadAwareSchedulingArgs(args)
+		case *config.ElasticQuotaArgs:
+			err = validation.ValidateElasticQuotaArgs(args)
+		case *config.CoschedulingArgs:
+			err = validation.ValidateCoschedulingArgs(args)
+		case *config.DeviceShareArgs:
+			err = validation.ValidateDeviceShareArgs(args)
+		case *config.ReservationArgs:
+			err = validation.ValidateReservationArgs(args)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: invalid args of plugin %s, err: %w", profile.SchedulerName, pc.Name, err))
+		}
+	}
+	if profile.Plugins == nil {
+		return errs
+	}
+
+	plugins := profile.Plugins
+	enabled := sets.NewString()
+	for _, ps := range []schedconfig.PluginSet{plugins.QueueSort, plugins.PreFilter, plugins.Filter, plugins.PostFilter,
+		plugins.PreScore, plugins.Score, plugins.Reserve, plugins.Permit, plugins.PreBind, plugins.Bind, plugins.PostBind} {
+		for _, p := range ps.Enabled {
+			enabled.Insert(p.Name)
+		}
+	}
+	for _, pc := range profile.PluginConfig {
+		if pc.Args != nil && isKoordinatorPlugin(pc.Name) && !enabled.Has(pc.Name) {
+			errs = append(errs, fmt.Errorf("profile %s: plugin %s has args but is not enabled", profile.SchedulerName, pc.Name))
+		}
+	}
+	if enabled.Has(coschedulingPlugin) {
+		if len(plugins.QueueSort.Enabled) != 1 || plugins.QueueSort.Enabled[0].Name != coschedulingPlugin {
+			errs = append(errs, fmt.Errorf("profile %s: Coscheduling must be the only queueSort plugin to keep the gang members together", profile.SchedulerName))
+		}
+		if pluginIndex(plugins.Permit, coschedulingPlugin) < 0 {
+			errs = append(errs, fmt.Errorf("profile %s: Coscheduling must be enabled at permit to wait for the gang", profile.SchedulerName))
+		}
+	}
+	if reservationIndex := pluginIndex(plugins.Reserve, reservationPlugin); reservationIndex >= 0 {
+		if deviceShareIndex := pluginIndex(plugins.Reserve, deviceSharePlugin); deviceShareIndex >= 0 && deviceShareIndex < reservationIndex {
+			errs = append(errs, fmt.Errorf("profile %s: Reservation must reserve before DeviceShare, so that the pod inherits the devices of the reservation", profile.SchedulerName))
+		}
+	}
+	if enabled.Has(reservationPlugin) {
+		if len(plugins.Bind.Enabled) == 0 || plugins.Bind.Enabled[0].Name != reservationPlugin {
+			errs = append(errs, fmt.Errorf("profile %s: Reservation must be the first bind plugin to bind the reserve pods", profile.SchedulerName))
+		}
+	}
+	return errs
+}
+
+func isKoordinatorPlugin(name string) bool {
+	switch name {
+	case loadAwareSchedulingPlugin, nodeNUMAResourcePlugin, deviceSharePlugin, reservationPlugin, batchResourceFitPlugin,
+		coschedulingPlugin, elasticQuotaPlugin, preferredNodesPlugin:
+		return true
+	}
+	return false
+}
+
+func pluginIndex(ps schedconfig.PluginSet, name string) int {
+	for i, p := range ps.Enabled {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package koordctl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/scheme"
+)
+
+func enabledPluginNames(ps schedconfig.PluginSet) []string {
+	var names []string
+	for _, p := range ps.Enabled {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestGenerateSchedulerConfig(t *testing.T) {
+	intent := &schedulerConfigIntent{
+		LoadAware: &loadAwareIntent{
+			UsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 70, corev1.ResourceMemory: 90},
+		},
+		GPUShare:     true,
+		Gang:         true,
+		ElasticQuota: &elasticQuotaIntent{QuotaGroupNamespace: "quota-system"},
+		Reservation:  true,
+	}
+	data, err := generateSchedulerConfig(intent)
+	assert.NoError(t, err)
+
+	obj, _, err := scheme.Codecs.UniversalDecoder().Decode(data, nil, nil)
+	assert.NoError(t, err)
+	cfg := obj.(*schedconfig.KubeSchedulerConfiguration)
+	assert.True(t, cfg.LeaderElection.LeaderElect)
+	assert.Equal(t, "leases", cfg.LeaderElection.ResourceLock)
+	assert.Equal(t, "koord-scheduler", cfg.LeaderElection.ResourceName)
+	assert.Equal(t, "koordinator-system", cfg.LeaderElection.ResourceNamespace)
+	assert.Len(t, cfg.Profiles, 1)
+	profile := cfg.Profiles[0]
+	assert.Equal(t, "koord-scheduler", profile.SchedulerName)
+
+	plugins := profile.Plugins
+	assert.Equal(t, []string{coschedulingPlugin}, enabledPluginNames(plugins.QueueSort))
+	assert.Contains(t, enabledPluginNames(plugins.Filter), loadAwareSchedulingPlugin)
+	assert.Contains(t, enabledPluginNames(plugins.Filter), batchResourceFitPlugin)
+	assert.NotContains(t, enabledPluginNames(plugins.Filter), nodeNUMAResourcePlugin)
+	assert.Less(t, pluginIndex(plugins.Reserve, reservationPlugin), pluginIndex(plugins.Reserve, deviceSharePlugin))
+	assert.Equal(t, []string{reservationPlugin, coschedulingPlugin, deviceSharePlugin, elasticQuotaPlugin, defaultPreemptionPlugin}, enabledPluginNames(plugins.PostFilter))
+	assert.Equal(t, []string{reservationPlugin, defaultBinderPlugin}, enabledPluginNames(plugins.Bind))
+
+	var loadAwareArgs *config.LoadAwareSchedulingArgs
+	var elasticQuotaArgs *config.ElasticQuotaArgs
+	for _, pc := range profile.PluginConfig {
+		switch args := pc.Args.(type) {
+		case *config.LoadAwareSchedulingArgs:
+			loadAwareArgs = args
+		case *config.ElasticQuotaArgs:
+			elasticQuotaArgs = args
+		}
+	}
+	if assert.NotNil(t, loadAwareArgs) {
+		assert.Equal(t, map[corev1.ResourceName]int64{corev1.ResourceCPU: 70, corev1.ResourceMemory: 90}, loadAwareArgs.UsageThresholds)
+	}
+	if assert.NotNil(t, elasticQuotaArgs) {
+		assert.Equal(t, "quota-system", elasticQuotaArgs.QuotaGroupNamespace)
+	}
+
+	// the generated config is also accepted by the validate command
+	configFile := filepath.Join(t.TempDir(), "scheduler-config.yaml")
+	assert.NoError(t, os.WriteFile(configFile, data, 0644))
+	out := &bytes.Buffer{}
+	cmd := newSchedulerConfigCommand(out)
+	cmd.SetArgs([]string{"validate", "-f", configFile})
+	assert.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "is valid")
+}
+
+func TestGenerateSchedulerConfig_InvalidIntent(t *testing.T) {
+	intent := &schedulerConfigIntent{
+		LoadAware: &loadAwareIntent{
+			UsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 120},
+		},
+	}
+	_, err := generateSchedulerConfig(intent)
+	assert.Error(t, err)
+}
+
+func TestValidateSchedulerConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name: "valid profile",
+			config: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: koord-scheduler
+  plugins:
+    reserve:
+      enabled:
+      - name: Reservation
+      - name: DeviceShare
+    bind:
+      disabled:
+      - name: "*"
+      enabled:
+      - name: Reservation
+      - name: DefaultBinder
+`,
+		},
+		{
+			name: "DeviceShare reserves before Reservation",
+			config: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: koord-scheduler
+  plugins:
+    reserve:
+      enabled:
+      - name: DeviceShare
+      - name: Reservation
+    bind:
+      disabled:
+      - name: "*"
+      enabled:
+      - name: Reservation
+      - name: DefaultBinder
+`,
+			wantErr: true,
+		},
+		{
+			name: "Coscheduling without queueSort",
+			config: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: koord-scheduler
+  plugins:
+    preFilter:
+      enabled:
+      - name: Coscheduling
+    permit:
+      enabled:
+      - name: Coscheduling
+`,
+			wantErr: true,
+		},
+		{
+			name: "args of the plugin not enabled",
+			config: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: koord-scheduler
+  pluginConfig:
+  - name: LoadAwareScheduling
+    args:
+      apiVersion: kubescheduler.config.k8s.io/v1beta2
+      kind: LoadAwareSchedulingArgs
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid plugin args",
+			config: `apiVersion: kubescheduler.config.k8s.io/v1beta2
+kind: KubeSchedulerConfiguration
+leaderElection:
+  leaderElect: false
+profiles:
+- schedulerName: koord-scheduler
+  plugins:
+    filter:
+      enabled:
+      - name: LoadAwareScheduling
+  pluginConfig:
+  - name: LoadAwareScheduling
+    args:
+      apiVersion: kubescheduler.config.k8s.io/v1beta2
+      kind: LoadAwareSchedulingArgs
+      usageThresholds:
+        cpu: 120
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedulerConfig([]byte(tt.config))
+			assert.Equal(t, tt.wantErr, err != nil, "err: %v", err)
+		})
+	}
+}
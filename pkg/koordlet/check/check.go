@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+type Status string
+
+const (
+	StatusOK      Status = "OK"
+	StatusFailed  Status = "FAILED"
+	StatusSkipped Status = "SKIPPED"
+)
+
+// Check exercises one enforcement path of the koordlet on the node, e.g. writing the cgroups or creating the resctrl
+// groups, and returns whether the path works on the node with a message for the triage.
+type Check struct {
+	Name string
+	Run  func() (Status, string)
+}
+
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+}
+
+// scratchName is the name of the temporary cgroup and resctrl group created by the checks, which are removed once
+// checked.
+var scratchName = fmt.Sprintf("koordlet-check-%d", os.Getpid())
+
+// DefaultChecks returns the checks of the enforcement paths in order. The cgroup check goes first since it sets up
+// the cgroup driver of the node, which the cgroup paths depend on.
+func DefaultChecks() []Check {
+	return []Check{
+		{Name: "cgroup", Run: checkCgroup},
+		{Name: "cgroup-write", Run: checkCgroupWrite},
+		{Name: "resctrl", Run: checkResctrl},
+		{Name: "nvml", Run: checkNVML},
+		{Name: "cri", Run: checkCRI},
+	}
+}
+
+// RunChecks runs the checks in order. A panic of the check is recovered and reported as a failure, so that the rest
+// checks are still run.
+func RunChecks(checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		results = append(results, runCheck(c))
+	}
+	return results
+}
+
+func runCheck(c Check) (result Result) {
+	result.Name = c.Name
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status, result.Message = StatusFailed, fmt.Sprintf("panic: %v", r)
+		}
+	}()
+	result.Status, result.Message = c.Run()
+	return result
+}
+
+// HasFailure checks if any of the results is failed.
+func HasFailure(results []Result) bool {
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintReport prints the capability report of the results as a table.
+func PrintReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMESSAGE")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, r.Status, r.Message)
+	}
+	return tw.Flush()
+}
+
+func checkCgroup() (Status, string) {
+	version := "v1"
+	if system.GetCurrentCgroupVersion() == system.CgroupVersionV2 {
+		version = "v2"
+	}
+	driver := system.GuessCgroupDriverFromCgroupName()
+	if !driver.Validate() {
+		return StatusFailed, fmt.Sprintf("cgroup %s, cannot detect the cgroup driver from the kubepods cgroup under %s",
+			version, system.Conf.CgroupRootDir)
+	}
+	system.SetupCgroupPathFormatter(driver)
+	return StatusOK, fmt.Sprintf("cgroup %s, driver %s, anolis os %v", version, driver, system.HostSystemInfo.IsAnolisOS)
+}
+
+// scratchCgroupValues are the values written into the scratch cgroup, which are the defaults of the cgroup files.
+var scratchCgroupValues = map[system.CgroupVersion]map[system.ResourceType]string{
+	system.CgroupVersionV1: {
+		system.CPUSharesName:   "1024",
+		system.CPUCFSQuotaName: "-1",
+		system.MemoryLimitName: "-1",
+	},
+	system.CgroupVersionV2: {
+		system.CPUSharesName:   "100",
+		system.CPUCFSQuotaName: "max",
+		system.MemoryLimitName: "max",
+	},
+}
+
+func checkCgroupWrite() (Status, string) {
+	parentDir := filepath.Join(koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort), scratchName)
+	scratchDirs, written, err := writeScratchCgroup(parentDir)
+	for _, dir := range scratchDirs {
+		_ = os.RemoveAll(dir)
+	}
+	if err != nil {
+		return StatusFailed, err.Error()
+	}
+	return StatusOK, fmt.Sprintf("wrote %s in scratch cgroup %s", strings.Join(written, ","), parentDir)
+}
+
+// writeScratchCgroup creates the scratch cgroup in each subsystem and writes the cgroup files like the resource
+// executor does. It returns the created cgroup dirs to remove even if failed, and the written files.
+func writeScratchCgroup(parentDir string) ([]string, []string, error) {
+	values := scratchCgroupValues[system.GetCurrentCgroupVersion()]
+	resourceTypes := []system.ResourceType{system.CPUSharesName, system.CPUCFSQuotaName, system.MemoryLimitName}
+
+	var scratchDirs, written []string
+	for _, t := range resourceTypes {
+		r, err := system.GetCgroupResource(t)
+		if err != nil {
+			return scratchDirs, written, err
+		}
+		filePath := r.Path(parentDir)
+		dir := filepath.Dir(filePath)
+		if !containsString(scratchDirs, dir) {
+			if err = os.Mkdir(dir, 0755); err != nil {
+				return scratchDirs, written, fmt.Errorf("failed to create scratch cgroup %s, err: %v", dir, err)
+			}
+			scratchDirs = append(scratchDirs, dir)
+		}
+		if err = system.CommonFileWrite(filePath, values[t]); err != nil {
+			return scratchDirs, written, fmt.Errorf("failed to write %s, err: %v", filePath, err)
+		}
+		written = append(written, filepath.Base(filePath))
+	}
+	return scratchDirs, written, nil
+}
+
+func checkResctrl() (Status, string) {
+	if supported, err := system.IsSupportResctrl(); err != nil {
+		return StatusFailed, fmt.Sprintf("failed to check resctrl support, err: %v", err)
+	} else if !supported {
+		return StatusSkipped, "resctrl is not supported by the cpu or the kernel"
+	}
+	if err := system.CheckAndTryEnableResctrlCat(); err != nil {
+		return StatusFailed, err.Error()
+	}
+
+	groupDir := system.GetResctrlGroupRootDirPath(scratchName)
+	if err := system.InitCatGroupIfNotExist(scratchName); err != nil {
+		return StatusFailed, err.Error()
+	}
+	defer os.Remove(groupDir)
+	schemataPath := system.GetResctrlSchemataFilePath(scratchName)
+	if _, err := os.Stat(schemataPath); err != nil {
+		return StatusFailed, fmt.Sprintf("schemata of scratch group %s not found, err: %v", groupDir, err)
+	}
+	return StatusOK, fmt.Sprintf("created scratch group %s, monitor enabled %v", groupDir, system.IsResctrlMonitorEnabled())
+}
+
+func checkCRI() (Status, string) {
+	var errs []string
+	for _, runtimeType := range []string{"containerd", "docker"} {
+		if _, err := runtime.GetRuntimeHandler(runtimeType); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", runtimeType, err))
+			continue
+		}
+		return StatusOK, fmt.Sprintf("connected to %s", runtimeType)
+	}
+	return StatusFailed, strings.Join(errs, "; ")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package check
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_RunChecks(t *testing.T) {
+	checks := []Check{
+		{Name: "ok", Run: func() (Status, string) { return StatusOK, "works" }},
+		{Name: "panic", Run: func() (Status, string) { panic("test") }},
+		{Name: "skipped", Run: func() (Status, string) { return StatusSkipped, "not supported" }},
+	}
+	results := RunChecks(checks)
+	assert.Equal(t, []Result{
+		{Name: "ok", Status: StatusOK, Message: "works"},
+		{Name: "panic", Status: StatusFailed, Message: "panic: test"},
+		{Name: "skipped", Status: StatusSkipped, Message: "not supported"},
+	}, results)
+	assert.True(t, HasFailure(results))
+	assert.False(t, HasFailure(results[2:]))
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, PrintReport(buf, results))
+	assert.Equal(t, "CHECK    STATUS   MESSAGE\n"+
+		"ok       OK       works\n"+
+		"panic    FAILED   panic: test\n"+
+		"skipped  SKIPPED  not supported\n", buf.String())
+}
+
+func Test_checkCgroupWrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		useCgroupsV2  bool
+		subfs         []string
+		missingParent bool
+		wantFiles     map[string]string
+		wantStatus    Status
+	}{
+		{
+			name:  "cgroup v1",
+			subfs: []string{system.CgroupCPUDir, system.CgroupMemDir},
+			wantFiles: map[string]string{
+				filepath.Join(system.CgroupCPUDir, system.CPUSharesName):   "1024",
+				filepath.Join(system.CgroupCPUDir, system.CPUCFSQuotaName): "-1",
+				filepath.Join(system.CgroupMemDir, system.MemoryLimitName): "-1",
+			},
+			wantStatus: StatusOK,
+		},
+		{
+			name:         "cgroup v2",
+			useCgroupsV2: true,
+			subfs:        []string{system.CgroupV2Dir},
+			wantFiles: map[string]string{
+				system.CPUWeightName: "100",
+				system.CPUMaxName:    "max",
+				system.MemoryMaxName: "max",
+			},
+			wantStatus: StatusOK,
+		},
+		{
+			name:          "qos cgroup not found",
+			subfs:         []string{system.CgroupCPUDir, system.CgroupMemDir},
+			missingParent: true,
+			wantStatus:    StatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.useCgroupsV2)
+			system.SetupCgroupPathFormatter(system.Cgroupfs)
+			defer system.SetupCgroupPathFormatter(system.Systemd)
+
+			qosDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+			if !tt.missingParent {
+				for _, subfs := range tt.subfs {
+					helper.MkDirAll(filepath.Join(subfs, qosDir))
+				}
+			}
+
+			status, msg := checkCgroupWrite()
+			assert.Equal(t, tt.wantStatus, status, msg)
+			// the scratch cgroups are removed after checked
+			for _, subfs := range tt.subfs {
+				assert.NoDirExists(t, filepath.Join(helper.TempDir, subfs, qosDir, scratchName))
+			}
+			if tt.wantStatus != StatusOK {
+				return
+			}
+
+			scratchDirs, _, err := writeScratchCgroup(filepath.Join(qosDir, scratchName))
+			assert.NoError(t, err)
+			assert.Len(t, scratchDirs, len(tt.subfs))
+			for file, want := range tt.wantFiles {
+				subfs, name := filepath.Split(file)
+				assert.Equal(t, want, helper.ReadFileContents(filepath.Join(subfs, qosDir, scratchName, name)))
+			}
+		})
+	}
+}
@@ -34,11 +34,14 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	maframework "github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsexporter"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/podstats"
 	qosmanagerconfig "github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager/config"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
@@ -60,6 +63,9 @@ type Configuration struct {
 	QosManagerConf     *qosmanagerconfig.Config
 	RuntimeHookConf    *runtimehooks.Config
 	AuditConf          *audit.Config
+	PodStatsConf       *podstats.Config
+	ExporterConf       *metricsexporter.Config
+	LogConf            *logs.Config
 	FeatureGates       map[string]bool
 }
 
@@ -74,6 +80,9 @@ func NewConfiguration() *Configuration {
 		QosManagerConf:     qosmanagerconfig.NewDefaultConfig(),
 		RuntimeHookConf:    runtimehooks.NewDefaultConfig(),
 		AuditConf:          audit.NewDefaultConfig(),
+		PodStatsConf:       podstats.NewDefaultConfig(),
+		ExporterConf:       metricsexporter.NewDefaultConfig(),
+		LogConf:            logs.NewDefaultConfig(),
 	}
 }
 
@@ -87,6 +96,9 @@ func (c *Configuration) InitFlags(fs *flag.FlagSet) {
 	c.ResManagerConf.InitFlags(fs)
 	c.RuntimeHookConf.InitFlags(fs)
 	c.AuditConf.InitFlags(fs)
+	c.PodStatsConf.InitFlags(fs)
+	c.ExporterConf.InitFlags(fs)
+	c.LogConf.InitFlags(fs)
 	resourceexecutor.Conf.InitFlags(fs)
 	fs.Var(cliflag.NewMapStringBool(&c.FeatureGates), "feature-gates", "A set of key=value pairs that describe feature gates for alpha/experimental features. "+
 		"Options are:\n"+strings.Join(features.DefaultKoordletFeatureGate.KnownFeatures(), "\n"))
@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	topologyclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned"
+	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -34,10 +36,13 @@ import (
 
 	clientsetbeta1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	"github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/config"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsexporter"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/podstats"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/qosmanager"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resmanager"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks"
@@ -64,6 +69,7 @@ type daemon struct {
 	resManager     resmanager.ResManager
 	qosManager     qosmanager.QoSManager
 	runtimeHook    runtimehooks.RuntimeHook
+	podStatsServer podstats.Server
 }
 
 func NewDaemon(config *config.Configuration) (Daemon, error) {
@@ -131,6 +137,24 @@ func NewDaemon(config *config.Configuration) (Daemon, error) {
 		return nil, err
 	}
 
+	var podStatsServer podstats.Server
+	if features.DefaultKoordletFeatureGate.Enabled(features.PodStatsServer) {
+		podStatsServer, err = podstats.NewServer(config.PodStatsConf, statesInformer, metricCache)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if features.DefaultKoordletFeatureGate.Enabled(features.MetricsExporter) {
+		exporter, err := metricsexporter.NewExporter(config.ExporterConf, statesInformer, metricCache)
+		if err != nil {
+			return nil, err
+		}
+		if err = prometheus.Register(exporter); err != nil {
+			return nil, fmt.Errorf("failed to register metrics exporter, err: %w", err)
+		}
+	}
+
 	d := &daemon{
 		metricAdvisor:  collectorService,
 		statesInformer: statesInformer,
@@ -138,6 +162,7 @@ func NewDaemon(config *config.Configuration) (Daemon, error) {
 		resManager:     resManagerService,
 		qosManager:     qosManager,
 		runtimeHook:    runtimeHook,
+		podStatsServer: podStatsServer,
 	}
 
 	return d, nil
@@ -147,7 +172,11 @@ func (d *daemon) Run(stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 	klog.Infof("Starting daemon")
 
+	// writers are the GC loop and the collectors writing the metric cache
+	var writers sync.WaitGroup
+	writers.Add(1)
 	go func() {
+		defer writers.Done()
 		if err := d.metricCache.Run(stopCh); err != nil {
 			klog.Fatalf("Unable to run the metric cache: ", err)
 		}
@@ -165,7 +194,9 @@ func (d *daemon) Run(stopCh <-chan struct{}) {
 	}
 
 	// start metric advisor
+	writers.Add(1)
 	go func() {
+		defer writers.Done()
 		if err := d.metricAdvisor.Run(stopCh); err != nil {
 			klog.Fatalf("Unable to run the metric advisor: ", err)
 		}
@@ -196,7 +227,21 @@ func (d *daemon) Run(stopCh <-chan struct{}) {
 		}
 	}()
 
+	if d.podStatsServer != nil {
+		go func() {
+			if err := d.podStatsServer.Run(stopCh); err != nil {
+				klog.Fatalf("Unable to run the pod stats server: ", err)
+			}
+		}()
+	}
+
 	klog.Info("Start daemon successfully")
 	<-stopCh
 	klog.Info("Shutting down daemon")
+
+	// close the metric cache after its writers stop, so that the file-backed metrics are checkpointed before exiting
+	writers.Wait()
+	if err := d.metricCache.Close(); err != nil {
+		klog.Warningf("failed to close metric cache, error %v", err)
+	}
 }
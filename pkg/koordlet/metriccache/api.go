@@ -17,6 +17,7 @@ limitations under the License.
 package metriccache
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
@@ -32,20 +33,39 @@ type GPUMetric struct {
 	SMUtil      uint32            // current utilization rate for the device
 	MemoryUsed  resource.Quantity // used memory on the device, in bytes
 	MemoryTotal resource.Quantity // total memory on device, in bytes
+	Temperature uint32            // current core temperature of the device, in degrees C
 }
 
 type MemoryMetric struct {
 	MemoryWithoutCache resource.Quantity
 }
 
+// NetworkMetric is the network bandwidth used, which is the traffic of the physical interfaces for the node, and the
+// traffic of the interfaces in the network namespace for the pod.
+type NetworkMetric struct {
+	RxBytesPerSec resource.Quantity // received bytes per second
+	TxBytesPerSec resource.Quantity // transmitted bytes per second
+}
+
+// DiskIOMetric is the disk IO throughput and IOPS, which is the IO of the physical disks for the node, and the IO
+// issued by the cgroup for the pod and the container.
+type DiskIOMetric struct {
+	ReadBytesPerSec  resource.Quantity // read bytes per second
+	WriteBytesPerSec resource.Quantity // written bytes per second
+	ReadIOPS         resource.Quantity // read operations per second
+	WriteIOPS        resource.Quantity // write operations per second
+}
+
 type CPUThrottledMetric struct {
 	ThrottledRatio float64
 }
 
 type NodeResourceMetric struct {
-	CPUUsed    CPUMetric
-	MemoryUsed MemoryMetric
-	GPUs       []GPUMetric
+	CPUUsed     CPUMetric
+	MemoryUsed  MemoryMetric
+	GPUs        []GPUMetric
+	NetworkUsed NetworkMetric
+	DiskIOUsed  DiskIOMetric
 }
 
 type NodeResourceQueryResult struct {
@@ -54,10 +74,14 @@ type NodeResourceQueryResult struct {
 }
 
 type PodResourceMetric struct {
-	PodUID     string
-	CPUUsed    CPUMetric
-	MemoryUsed MemoryMetric
-	GPUs       []GPUMetric
+	PodUID      string
+	CPUUsed     CPUMetric
+	MemoryUsed  MemoryMetric
+	GPUs        []GPUMetric
+	NetworkUsed NetworkMetric
+	DiskIOUsed  DiskIOMetric
+	// HugePagesUsed is the hugepages charged to the pod cgroup, keyed by the hugepage resource name
+	HugePagesUsed corev1.ResourceList
 }
 
 type PodResourceQueryResult struct {
@@ -70,6 +94,7 @@ type ContainerResourceMetric struct {
 	CPUUsed     CPUMetric
 	MemoryUsed  MemoryMetric
 	GPUs        []GPUMetric
+	DiskIOUsed  DiskIOMetric
 }
 
 type ContainerResourceQueryResult struct {
@@ -90,6 +115,35 @@ type BECPUResourceQueryResult struct {
 	Metric *BECPUResourceMetric
 }
 
+// SystemResourceMetric is the resource usage of the system cgroups, e.g. kubelet, container runtime and system daemons.
+type SystemResourceMetric struct {
+	CPUUsed    CPUMetric
+	MemoryUsed MemoryMetric
+}
+
+type SystemResourceQueryResult struct {
+	QueryResult
+	Metric *SystemResourceMetric
+}
+
+// NUMAResourceMetric is the resource usage of a NUMA node.
+type NUMAResourceMetric struct {
+	NUMANodeID int32
+	CPUUsed    CPUMetric
+	MemoryUsed MemoryMetric
+	// MemoryFree is the free memory of the NUMA node, i.e. MemFree in its meminfo
+	MemoryFree resource.Quantity
+	// HugePagesTotal and HugePagesFree are the hugepage pools of the NUMA node, keyed by the hugepage resource name
+	HugePagesTotal corev1.ResourceList
+	HugePagesFree  corev1.ResourceList
+}
+
+type NUMAResourceQueryResult struct {
+	QueryResult
+	// Metrics are sorted by the NUMA node id
+	Metrics []*NUMAResourceMetric
+}
+
 type PodThrottledMetric struct {
 	PodUID             string
 	CPUThrottledMetric *CPUThrottledMetric
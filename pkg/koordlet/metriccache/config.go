@@ -21,6 +21,10 @@ import "flag"
 type Config struct {
 	MetricGCIntervalSeconds int
 	MetricExpireSeconds     int
+	// MetricDBPath is the file to persist the metrics, the metrics are kept in memory if it is empty.
+	MetricDBPath string
+	// MetricDBMaxSizeMB caps the size of the metric data, the oldest metrics are pruned when exceeded. 0 means no limit.
+	MetricDBMaxSizeMB int
 }
 
 func NewDefaultConfig() *Config {
@@ -33,4 +37,6 @@ func NewDefaultConfig() *Config {
 func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.MetricGCIntervalSeconds, "metric-gc-interval-seconds", c.MetricGCIntervalSeconds, "Collect node metrics interval by seconds")
 	fs.IntVar(&c.MetricExpireSeconds, "metric-expire-seconds", c.MetricExpireSeconds, "Collect pod metrics expire by seconds")
+	fs.StringVar(&c.MetricDBPath, "metric-db-path", c.MetricDBPath, "The file to persist the metric cache, the metrics are kept in memory if empty")
+	fs.IntVar(&c.MetricDBMaxSizeMB, "metric-db-max-size-mb", c.MetricDBMaxSizeMB, "The max size of the metric cache in MB, the oldest metrics are pruned when exceeded, 0 means no limit")
 }
@@ -37,12 +37,16 @@ func Test_InitFlags(t *testing.T) {
 		"",
 		"--metric-gc-interval-seconds=100",
 		"--metric-expire-seconds=600",
+		"--metric-db-path=/var/lib/koordlet/metrics.db",
+		"--metric-db-max-size-mb=256",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
 	type fields struct {
 		MetricGCIntervalSeconds int
 		MetricExpireSeconds     int
+		MetricDBPath            string
+		MetricDBMaxSizeMB       int
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -57,6 +61,8 @@ func Test_InitFlags(t *testing.T) {
 			fields: fields{
 				MetricGCIntervalSeconds: 100,
 				MetricExpireSeconds:     600,
+				MetricDBPath:            "/var/lib/koordlet/metrics.db",
+				MetricDBMaxSizeMB:       256,
 			},
 			args: args{fs: fs},
 		},
@@ -66,6 +72,8 @@ func Test_InitFlags(t *testing.T) {
 			raw := &Config{
 				MetricGCIntervalSeconds: tt.fields.MetricGCIntervalSeconds,
 				MetricExpireSeconds:     tt.fields.MetricExpireSeconds,
+				MetricDBPath:            tt.fields.MetricDBPath,
+				MetricDBMaxSizeMB:       tt.fields.MetricDBMaxSizeMB,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
@@ -19,9 +19,11 @@ package metriccache
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -51,6 +53,22 @@ const (
 
 	MetricNamePodCPI InterferenceMetricName = "PodCPI"
 	MetricNamePodPSI InterferenceMetricName = "PodPSI"
+	// MetricNamePodSchedLatency is the run queue latency of the pod tasks, i.e. the delay between the wakeup and
+	// the run of the tasks on the CPU, which is collected by the eBPF collector.
+	MetricNamePodSchedLatency InterferenceMetricName = "PodSchedLatency"
+	// MetricNamePodColdMemory is the cold memory of the pod, i.e. the pages not accessed for a while, which is
+	// collected from the idle page statistics of kidled.
+	MetricNamePodColdMemory InterferenceMetricName = "PodColdMemory"
+	// MetricNamePodRDMAStat is the traffic and congestion rates of the RDMA virtual functions allocated to the pod,
+	// which is collected from the port counters of the RDMA devices.
+	MetricNamePodRDMAStat InterferenceMetricName = "PodRDMAStat"
+)
+
+const (
+	// pruneDBSteps is the number of the steps to prune the metrics within the expiration when the database is oversize.
+	pruneDBSteps = 10
+	// compactDBFreeRatio is the ratio of the free pages in the database to trigger the compaction.
+	compactDBFreeRatio = 0.25
 )
 
 type QueryParam struct {
@@ -91,11 +109,14 @@ func (q *QueryParam) FillDefaultValue() {
 
 type MetricCache interface {
 	Run(stopCh <-chan struct{}) error
+	Close() error
 	GetNodeResourceMetric(param *QueryParam) NodeResourceQueryResult
 	GetPodResourceMetric(podUID *string, param *QueryParam) PodResourceQueryResult
 	GetContainerResourceMetric(containerID *string, param *QueryParam) ContainerResourceQueryResult
 	GetNodeCPUInfo(param *QueryParam) (*NodeCPUInfo, error)
 	GetBECPUResourceMetric(param *QueryParam) BECPUResourceQueryResult
+	GetSystemResourceMetric(param *QueryParam) SystemResourceQueryResult
+	GetNUMAResourceMetric(param *QueryParam) NUMAResourceQueryResult
 	GetPodThrottledMetric(podUID *string, param *QueryParam) PodThrottledQueryResult
 	GetContainerThrottledMetric(containerID *string, param *QueryParam) ContainerThrottledQueryResult
 	GetContainerInterferenceMetric(metricName InterferenceMetricName, podUID *string, containerID *string, param *QueryParam) ContainerInterferenceQueryResult
@@ -105,6 +126,8 @@ type MetricCache interface {
 	InsertContainerResourceMetric(t time.Time, containerResUsed *ContainerResourceMetric) error
 	InsertNodeCPUInfo(info *NodeCPUInfo) error
 	InsertBECPUResourceMetric(t time.Time, metric *BECPUResourceMetric) error
+	InsertSystemResourceMetric(t time.Time, metric *SystemResourceMetric) error
+	InsertNUMAResourceMetric(t time.Time, metric *NUMAResourceMetric) error
 	InsertPodThrottledMetrics(t time.Time, metric *PodThrottledMetric) error
 	InsertContainerThrottledMetrics(t time.Time, metric *ContainerThrottledMetric) error
 	InsertContainerInterferenceMetrics(t time.Time, metric *ContainerInterferenceMetric) error
@@ -117,7 +140,13 @@ type metricCache struct {
 }
 
 func NewMetricCache(cfg *Config) (MetricCache, error) {
-	database, err := NewStorage()
+	var database *storage
+	var err error
+	if cfg.MetricDBPath != "" {
+		database, err = NewFileStorage(cfg.MetricDBPath)
+	} else {
+		database, err = NewStorage()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -130,13 +159,20 @@ func NewMetricCache(cfg *Config) (MetricCache, error) {
 func (m *metricCache) Run(stopCh <-chan struct{}) error {
 	defer utilruntime.HandleCrash()
 
-	go wait.Until(func() {
+	// block until the GC loop stops, so that the caller can close the database once Run returns
+	wait.Until(func() {
 		m.recycleDB()
 	}, time.Duration(m.config.MetricGCIntervalSeconds)*time.Second, stopCh)
 
 	return nil
 }
 
+// Close closes the database so that the file-backed metrics are checkpointed. It must be called after the GC loop
+// and the writers of the metric cache stop.
+func (m *metricCache) Close() error {
+	return m.db.Close()
+}
+
 func (m *metricCache) GetNodeResourceMetric(param *QueryParam) NodeResourceQueryResult {
 	result := NodeResourceQueryResult{}
 	if param == nil || param.Start == nil || param.End == nil {
@@ -164,6 +200,21 @@ func (m *metricCache) GetNodeResourceMetric(param *QueryParam) NodeResourceQuery
 		result.Error = fmt.Errorf("get node aggregate MemoryUsedBytes failed, metrics %v, error %v", metrics, err)
 		return result
 	}
+	netRxBytes, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "NetRxBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get node aggregate NetRxBytes failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+	netTxBytes, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "NetTxBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get node aggregate NetTxBytes failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+	diskIOUsed, err := aggregateDiskIOMetric(metrics, aggregateFunc)
+	if err != nil {
+		result.Error = fmt.Errorf("get node aggregate DiskIOMetric failed, metrics %v, error %v", metrics, err)
+		return result
+	}
 
 	// gpu metrics time series.
 	// m.GPUs is a slice.
@@ -198,6 +249,11 @@ func (m *metricCache) GetNodeResourceMetric(param *QueryParam) NodeResourceQuery
 			MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
 		},
 		GPUs: aggregateGPUMetrics,
+		NetworkUsed: NetworkMetric{
+			RxBytesPerSec: *resource.NewQuantity(int64(netRxBytes), resource.DecimalSI),
+			TxBytesPerSec: *resource.NewQuantity(int64(netTxBytes), resource.DecimalSI),
+		},
+		DiskIOUsed: diskIOUsed,
 	}
 
 	return result
@@ -232,6 +288,24 @@ func (m *metricCache) GetPodResourceMetric(podUID *string, param *QueryParam) Po
 			*podUID, metrics, err)
 		return result
 	}
+	netRxBytes, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "NetRxBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get pod %v aggregate NetRxBytes failed, metrics %v, error %v",
+			*podUID, metrics, err)
+		return result
+	}
+	netTxBytes, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "NetTxBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get pod %v aggregate NetTxBytes failed, metrics %v, error %v",
+			*podUID, metrics, err)
+		return result
+	}
+	diskIOUsed, err := aggregateDiskIOMetric(metrics, aggregateFunc)
+	if err != nil {
+		result.Error = fmt.Errorf("get pod %v aggregate DiskIOMetric failed, metrics %v, error %v",
+			*podUID, metrics, err)
+		return result
+	}
 
 	// gpu metrics time series.
 	// m.GPUs is a slice.
@@ -268,6 +342,14 @@ func (m *metricCache) GetPodResourceMetric(podUID *string, param *QueryParam) Po
 			MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
 		},
 		GPUs: aggregateGPUMetrics,
+		NetworkUsed: NetworkMetric{
+			RxBytesPerSec: *resource.NewQuantity(int64(netRxBytes), resource.DecimalSI),
+			TxBytesPerSec: *resource.NewQuantity(int64(netTxBytes), resource.DecimalSI),
+		},
+		DiskIOUsed: diskIOUsed,
+		HugePagesUsed: latestHugePages(len(metrics), func(i int) (time.Time, HugePagesBytes) {
+			return metrics[i].Timestamp, metrics[i].HugePagesUsed
+		}),
 	}
 
 	return result
@@ -303,6 +385,12 @@ func (m *metricCache) GetContainerResourceMetric(containerID *string, param *Que
 			containerID, metrics, err)
 		return result
 	}
+	diskIOUsed, err := aggregateDiskIOMetric(metrics, aggregateFunc)
+	if err != nil {
+		result.Error = fmt.Errorf("get container %v aggregate DiskIOMetric failed, metrics %v, error %v",
+			containerID, metrics, err)
+		return result
+	}
 
 	count, err := count(metrics)
 	if err != nil {
@@ -338,7 +426,8 @@ func (m *metricCache) GetContainerResourceMetric(containerID *string, param *Que
 		MemoryUsed: MemoryMetric{
 			MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
 		},
-		GPUs: aggregateGPUMetrics,
+		GPUs:       aggregateGPUMetrics,
+		DiskIOUsed: diskIOUsed,
 	}
 	return result
 }
@@ -392,6 +481,117 @@ func (m *metricCache) GetBECPUResourceMetric(param *QueryParam) BECPUResourceQue
 	return result
 }
 
+func (m *metricCache) GetSystemResourceMetric(param *QueryParam) SystemResourceQueryResult {
+	result := SystemResourceQueryResult{}
+	if param == nil || param.Start == nil || param.End == nil {
+		result.Error = fmt.Errorf("SystemResourceMetric query parameters are illegal %v", param)
+		return result
+	}
+	metrics, err := m.db.GetSystemResourceMetric(param.Start, param.End)
+	if err != nil {
+		result.Error = fmt.Errorf("get SystemResourceMetric failed, query params %v, error %v", param, err)
+		return result
+	}
+	if len(metrics) == 0 {
+		result.Error = fmt.Errorf("get SystemResourceMetric not exist, query params %v", param)
+		return result
+	}
+
+	aggregateFunc := getAggregateFunc(param.Aggregate)
+	cpuUsed, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "CPUUsedCores", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get system aggregate CPUUsedCores failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+	memoryUsed, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: "MemoryUsedBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		result.Error = fmt.Errorf("get system aggregate MemoryUsedBytes failed, metrics %v, error %v", metrics, err)
+		return result
+	}
+
+	result.AggregateInfo, err = generateMetricAggregateInfo(metrics)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.Metric = &SystemResourceMetric{
+		CPUUsed: CPUMetric{
+			CPUUsed: *resource.NewMilliQuantity(int64(cpuUsed*1000), resource.DecimalSI),
+		},
+		MemoryUsed: MemoryMetric{
+			MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
+		},
+	}
+	return result
+}
+
+func (m *metricCache) GetNUMAResourceMetric(param *QueryParam) NUMAResourceQueryResult {
+	result := NUMAResourceQueryResult{}
+	if param == nil || param.Start == nil || param.End == nil {
+		result.Error = fmt.Errorf("NUMAResourceMetric query parameters are illegal %v", param)
+		return result
+	}
+	metrics, err := m.db.GetNUMAResourceMetric(param.Start, param.End)
+	if err != nil {
+		result.Error = fmt.Errorf("get NUMAResourceMetric failed, query params %v, error %v", param, err)
+		return result
+	}
+	if len(metrics) == 0 {
+		result.Error = fmt.Errorf("get NUMAResourceMetric not exist, query params %v", param)
+		return result
+	}
+
+	numaMetrics := map[int32][]numaResourceMetric{}
+	for _, metric := range metrics {
+		numaMetrics[metric.NUMANodeID] = append(numaMetrics[metric.NUMANodeID], metric)
+	}
+	aggregateFunc := getAggregateFunc(param.Aggregate)
+	for numaNodeID, nodeMetrics := range numaMetrics {
+		cpuUsed, err := aggregateFunc(nodeMetrics, AggregateParam{ValueFieldName: "CPUUsedCores", TimeFieldName: "Timestamp"})
+		if err != nil {
+			result.Error = fmt.Errorf("get NUMA %d aggregate CPUUsedCores failed, metrics %v, error %v", numaNodeID, nodeMetrics, err)
+			return result
+		}
+		memoryUsed, err := aggregateFunc(nodeMetrics, AggregateParam{ValueFieldName: "MemoryUsedBytes", TimeFieldName: "Timestamp"})
+		if err != nil {
+			result.Error = fmt.Errorf("get NUMA %d aggregate MemoryUsedBytes failed, metrics %v, error %v", numaNodeID, nodeMetrics, err)
+			return result
+		}
+		memoryFree, err := aggregateFunc(nodeMetrics, AggregateParam{ValueFieldName: "MemoryFreeBytes", TimeFieldName: "Timestamp"})
+		if err != nil {
+			result.Error = fmt.Errorf("get NUMA %d aggregate MemoryFreeBytes failed, metrics %v, error %v", numaNodeID, nodeMetrics, err)
+			return result
+		}
+		result.Metrics = append(result.Metrics, &NUMAResourceMetric{
+			NUMANodeID: numaNodeID,
+			CPUUsed: CPUMetric{
+				CPUUsed: *resource.NewMilliQuantity(int64(cpuUsed*1000), resource.DecimalSI),
+			},
+			MemoryUsed: MemoryMetric{
+				MemoryWithoutCache: *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
+			},
+			MemoryFree: *resource.NewQuantity(int64(memoryFree), resource.BinarySI),
+			HugePagesTotal: latestHugePages(len(nodeMetrics), func(i int) (time.Time, HugePagesBytes) {
+				return nodeMetrics[i].Timestamp, nodeMetrics[i].HugePagesTotal
+			}),
+			HugePagesFree: latestHugePages(len(nodeMetrics), func(i int) (time.Time, HugePagesBytes) {
+				return nodeMetrics[i].Timestamp, nodeMetrics[i].HugePagesFree
+			}),
+		})
+	}
+	sort.Slice(result.Metrics, func(i, j int) bool {
+		return result.Metrics[i].NUMANodeID < result.Metrics[j].NUMANodeID
+	})
+
+	result.AggregateInfo, err = generateMetricAggregateInfo(metrics)
+	if err != nil {
+		result.Error = err
+		result.Metrics = nil
+	}
+	return result
+}
+
 func (m *metricCache) GetNodeCPUInfo(param *QueryParam) (*NodeCPUInfo, error) {
 	// get node cpu info from the rawRecordTable
 	if param == nil {
@@ -591,6 +791,12 @@ func aggregatePodInterferenceMetricByName(metricName InterferenceMetricName, met
 		return aggregateCPI(metrics, aggregateFunc)
 	case MetricNamePodPSI:
 		return aggregatePSI(metrics, aggregateFunc)
+	case MetricNamePodSchedLatency:
+		return aggregateSchedLatency(metrics, aggregateFunc)
+	case MetricNamePodColdMemory:
+		return aggregateColdMemory(metrics, aggregateFunc)
+	case MetricNamePodRDMAStat:
+		return aggregateRDMAStat(metrics, aggregateFunc)
 	default:
 		return nil, fmt.Errorf("get unknown metric name")
 	}
@@ -662,6 +868,51 @@ func aggregatePSI(metrics interface{}, aggregateFunc AggregationFunc) (interface
 	return metricValue, nil
 }
 
+func aggregateSchedLatency(metrics interface{}, aggregateFunc AggregationFunc) (interface{}, error) {
+	avgLatency, err := aggregateFunc(metrics, AggregateParam{
+		ValueFieldName: "AvgLatencyMicroseconds", TimeFieldName: "Timestamp"})
+	if err != nil {
+		return nil, err
+	}
+	delayRatio, err := aggregateFunc(metrics, AggregateParam{
+		ValueFieldName: "DelayRatio", TimeFieldName: "Timestamp"})
+	if err != nil {
+		return nil, err
+	}
+	metricValue := &SchedLatencyMetric{
+		AvgLatencyMicroseconds: avgLatency,
+		DelayRatio:             delayRatio,
+	}
+	return metricValue, nil
+}
+
+func aggregateColdMemory(metrics interface{}, aggregateFunc AggregationFunc) (interface{}, error) {
+	coldAnonBytes, err := aggregateFunc(metrics, AggregateParam{
+		ValueFieldName: "ColdAnonBytes", TimeFieldName: "Timestamp"})
+	if err != nil {
+		return nil, err
+	}
+	return &ColdMemoryMetric{ColdAnonBytes: coldAnonBytes}, nil
+}
+
+func aggregateRDMAStat(metrics interface{}, aggregateFunc AggregationFunc) (interface{}, error) {
+	metricValue := &RDMAStatMetric{}
+	for fieldName, value := range map[string]*float64{
+		"RxBytesPerSecond":    &metricValue.RxBytesPerSecond,
+		"TxBytesPerSecond":    &metricValue.TxBytesPerSecond,
+		"CNPSentPerSecond":    &metricValue.CNPSentPerSecond,
+		"CNPHandledPerSecond": &metricValue.CNPHandledPerSecond,
+		"ECNMarkedPerSecond":  &metricValue.ECNMarkedPerSecond,
+	} {
+		v, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: fieldName, TimeFieldName: "Timestamp"})
+		if err != nil {
+			return nil, err
+		}
+		*value = v
+	}
+	return metricValue, nil
+}
+
 func (m *metricCache) InsertNodeResourceMetric(t time.Time, nodeResUsed *NodeResourceMetric) error {
 	gpuUsages := make([]gpuResourceMetric, len(nodeResUsed.GPUs))
 	for idx, usage := range nodeResUsed.GPUs {
@@ -671,6 +922,7 @@ func (m *metricCache) InsertNodeResourceMetric(t time.Time, nodeResUsed *NodeRes
 			SMUtil:      float64(usage.SMUtil),
 			MemoryUsed:  float64(usage.MemoryUsed.Value()),
 			MemoryTotal: float64(usage.MemoryTotal.Value()),
+			Temperature: float64(usage.Temperature),
 			Timestamp:   t,
 		}
 	}
@@ -679,6 +931,12 @@ func (m *metricCache) InsertNodeResourceMetric(t time.Time, nodeResUsed *NodeRes
 		CPUUsedCores:    float64(nodeResUsed.CPUUsed.CPUUsed.MilliValue()) / 1000,
 		MemoryUsedBytes: float64(nodeResUsed.MemoryUsed.MemoryWithoutCache.Value()),
 		GPUs:            gpuUsages,
+		NetRxBytes:      float64(nodeResUsed.NetworkUsed.RxBytesPerSec.Value()),
+		NetTxBytes:      float64(nodeResUsed.NetworkUsed.TxBytesPerSec.Value()),
+		DiskReadBytes:   float64(nodeResUsed.DiskIOUsed.ReadBytesPerSec.Value()),
+		DiskWriteBytes:  float64(nodeResUsed.DiskIOUsed.WriteBytesPerSec.Value()),
+		DiskReadIOPS:    float64(nodeResUsed.DiskIOUsed.ReadIOPS.Value()),
+		DiskWriteIOPS:   float64(nodeResUsed.DiskIOUsed.WriteIOPS.Value()),
 		Timestamp:       t,
 	}
 	return m.db.InsertNodeResourceMetric(dbItem)
@@ -693,6 +951,7 @@ func (m *metricCache) InsertPodResourceMetric(t time.Time, podResUsed *PodResour
 			SMUtil:      float64(usage.SMUtil),
 			MemoryUsed:  float64(usage.MemoryUsed.Value()),
 			MemoryTotal: float64(usage.MemoryTotal.Value()),
+			Temperature: float64(usage.Temperature),
 			Timestamp:   t,
 		}
 	}
@@ -702,6 +961,13 @@ func (m *metricCache) InsertPodResourceMetric(t time.Time, podResUsed *PodResour
 		CPUUsedCores:    float64(podResUsed.CPUUsed.CPUUsed.MilliValue()) / 1000,
 		MemoryUsedBytes: float64(podResUsed.MemoryUsed.MemoryWithoutCache.Value()),
 		GPUs:            gpuUsages,
+		NetRxBytes:      float64(podResUsed.NetworkUsed.RxBytesPerSec.Value()),
+		NetTxBytes:      float64(podResUsed.NetworkUsed.TxBytesPerSec.Value()),
+		DiskReadBytes:   float64(podResUsed.DiskIOUsed.ReadBytesPerSec.Value()),
+		DiskWriteBytes:  float64(podResUsed.DiskIOUsed.WriteBytesPerSec.Value()),
+		DiskReadIOPS:    float64(podResUsed.DiskIOUsed.ReadIOPS.Value()),
+		DiskWriteIOPS:   float64(podResUsed.DiskIOUsed.WriteIOPS.Value()),
+		HugePagesUsed:   toHugePagesBytes(podResUsed.HugePagesUsed),
 		Timestamp:       t,
 	}
 	return m.db.InsertPodResourceMetric(dbItem)
@@ -716,6 +982,7 @@ func (m *metricCache) InsertContainerResourceMetric(t time.Time, containerResUse
 			SMUtil:      float64(usage.SMUtil),
 			MemoryUsed:  float64(usage.MemoryUsed.Value()),
 			MemoryTotal: float64(usage.MemoryTotal.Value()),
+			Temperature: float64(usage.Temperature),
 			Timestamp:   t,
 		}
 	}
@@ -724,6 +991,10 @@ func (m *metricCache) InsertContainerResourceMetric(t time.Time, containerResUse
 		CPUUsedCores:    float64(containerResUsed.CPUUsed.CPUUsed.MilliValue()) / 1000,
 		MemoryUsedBytes: float64(containerResUsed.MemoryUsed.MemoryWithoutCache.Value()),
 		GPUs:            gpuUsages,
+		DiskReadBytes:   float64(containerResUsed.DiskIOUsed.ReadBytesPerSec.Value()),
+		DiskWriteBytes:  float64(containerResUsed.DiskIOUsed.WriteBytesPerSec.Value()),
+		DiskReadIOPS:    float64(containerResUsed.DiskIOUsed.ReadIOPS.Value()),
+		DiskWriteIOPS:   float64(containerResUsed.DiskIOUsed.WriteIOPS.Value()),
 		Timestamp:       t,
 	}
 	return m.db.InsertContainerResourceMetric(dbItem)
@@ -739,6 +1010,28 @@ func (m *metricCache) InsertBECPUResourceMetric(t time.Time, metric *BECPUResour
 	return m.db.InsertBECPUResourceMetric(dbItem)
 }
 
+func (m *metricCache) InsertSystemResourceMetric(t time.Time, metric *SystemResourceMetric) error {
+	dbItem := &systemResourceMetric{
+		CPUUsedCores:    float64(metric.CPUUsed.CPUUsed.MilliValue()) / 1000,
+		MemoryUsedBytes: float64(metric.MemoryUsed.MemoryWithoutCache.Value()),
+		Timestamp:       t,
+	}
+	return m.db.InsertSystemResourceMetric(dbItem)
+}
+
+func (m *metricCache) InsertNUMAResourceMetric(t time.Time, metric *NUMAResourceMetric) error {
+	dbItem := &numaResourceMetric{
+		NUMANodeID:      metric.NUMANodeID,
+		CPUUsedCores:    float64(metric.CPUUsed.CPUUsed.MilliValue()) / 1000,
+		MemoryUsedBytes: float64(metric.MemoryUsed.MemoryWithoutCache.Value()),
+		MemoryFreeBytes: float64(metric.MemoryFree.Value()),
+		HugePagesTotal:  toHugePagesBytes(metric.HugePagesTotal),
+		HugePagesFree:   toHugePagesBytes(metric.HugePagesFree),
+		Timestamp:       t,
+	}
+	return m.db.InsertNUMAResourceMetric(dbItem)
+}
+
 func (m *metricCache) InsertNodeCPUInfo(info *NodeCPUInfo) error {
 	infoBytes, err := json.Marshal(info)
 	if err != nil {
@@ -779,6 +1072,24 @@ func (m *metricCache) InsertPodInterferenceMetrics(t time.Time, metric *PodInter
 	return m.convertAndInsertPodInterferenceMetric(t, metric)
 }
 
+// aggregateDiskIOMetric aggregates the disk IO columns of the node, pod or container resource metrics.
+func aggregateDiskIOMetric(metrics interface{}, aggregateFunc AggregationFunc) (DiskIOMetric, error) {
+	var values [4]float64
+	for i, fieldName := range []string{"DiskReadBytes", "DiskWriteBytes", "DiskReadIOPS", "DiskWriteIOPS"} {
+		v, err := aggregateFunc(metrics, AggregateParam{ValueFieldName: fieldName, TimeFieldName: "Timestamp"})
+		if err != nil {
+			return DiskIOMetric{}, fmt.Errorf("aggregate %s failed, error %v", fieldName, err)
+		}
+		values[i] = v
+	}
+	return DiskIOMetric{
+		ReadBytesPerSec:  *resource.NewQuantity(int64(values[0]), resource.DecimalSI),
+		WriteBytesPerSec: *resource.NewQuantity(int64(values[1]), resource.DecimalSI),
+		ReadIOPS:         *resource.NewQuantity(int64(values[2]), resource.DecimalSI),
+		WriteIOPS:        *resource.NewQuantity(int64(values[3]), resource.DecimalSI),
+	}, nil
+}
+
 func (m *metricCache) aggregateGPUUsages(gpuResourceMetricsByTime [][]gpuResourceMetric, aggregateFunc AggregationFunc) ([]GPUMetric, error) {
 	if len(gpuResourceMetricsByTime) == 0 {
 		return nil, nil
@@ -810,12 +1121,18 @@ func (m *metricCache) aggregateGPUUsages(gpuResourceMetricsByTime [][]gpuResourc
 			return nil, err
 		}
 
+		temperature, err := aggregateFunc(v, AggregateParam{ValueFieldName: "Temperature", TimeFieldName: "Timestamp"})
+		if err != nil {
+			return nil, err
+		}
+
 		g := GPUMetric{
 			DeviceUUID:  v[len(v)-1].DeviceUUID,
 			Minor:       v[len(v)-1].Minor,
 			SMUtil:      uint32(smutil),
 			MemoryUsed:  *resource.NewQuantity(int64(memoryUsed), resource.BinarySI),
 			MemoryTotal: *resource.NewQuantity(int64(v[len(v)-1].MemoryTotal), resource.BinarySI),
+			Temperature: uint32(temperature),
 		}
 		metrics = append(metrics, g)
 	}
@@ -827,48 +1144,121 @@ func (m *metricCache) recycleDB() {
 	now := time.Now()
 	oldTime := time.Unix(0, 0)
 	expiredTime := now.Add(-time.Duration(m.config.MetricExpireSeconds) * time.Second)
-	if err := m.db.DeletePodResourceMetric(&oldTime, &expiredTime); err != nil {
+	m.deleteMetrics(&oldTime, &expiredTime)
+	if m.config.MetricDBMaxSizeMB > 0 {
+		m.pruneDB(now, expiredTime, int64(m.config.MetricDBMaxSizeMB)*1024*1024)
+	}
+	m.compactDB()
+
+	// raw records do not need to cleanup
+	nodeResCount, _ := m.db.CountNodeResourceMetric()
+	podResCount, _ := m.db.CountPodResourceMetric()
+	containerResCount, _ := m.db.CountContainerResourceMetric()
+	beCPUResCount, _ := m.db.CountBECPUResourceMetric()
+	systemResCount, _ := m.db.CountSystemResourceMetric()
+	numaResCount, _ := m.db.CountNUMAResourceMetric()
+	podThrottledResCount, _ := m.db.CountPodThrottledMetric()
+	containerThrottledResCount, _ := m.db.CountContainerThrottledMetric()
+	containerCPIResCount, _ := m.db.CountContainerCPIMetric()
+	containerPSIResCount, _ := m.db.CountContainerPSIMetric()
+	podPSIResCount, _ := m.db.CountPodPSIMetric()
+	podSchedLatencyResCount, _ := m.db.CountPodSchedLatencyMetric()
+	podColdMemoryResCount, _ := m.db.CountPodColdMemoryMetric()
+	podRDMAStatResCount, _ := m.db.CountPodRDMAStatMetric()
+	klog.V(4).Infof("expired metric data before %v has been recycled, remaining in db size: "+
+		"nodeResCount=%v, podResCount=%v, containerResCount=%v, beCPUResCount=%v, systemResCount=%v, numaResCount=%v, "+
+		"podThrottledResCount=%v, containerThrottledResCount=%v, containerCPIResCount=%v, containerPSIResCount=%v, "+
+		"podPSIResCount=%v, podSchedLatencyResCount=%v, podColdMemoryResCount=%v, podRDMAStatResCount=%v",
+		expiredTime, nodeResCount, podResCount, containerResCount, beCPUResCount, systemResCount, numaResCount,
+		podThrottledResCount, containerThrottledResCount, containerCPIResCount, containerPSIResCount,
+		podPSIResCount, podSchedLatencyResCount, podColdMemoryResCount, podRDMAStatResCount)
+}
+
+func (m *metricCache) deleteMetrics(start, end *time.Time) {
+	if err := m.db.DeletePodResourceMetric(start, end); err != nil {
 		klog.Warningf("DeletePodResourceMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeleteNodeResourceMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteNodeResourceMetric(start, end); err != nil {
 		klog.Warningf("DeleteNodeResourceMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeleteContainerResourceMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteContainerResourceMetric(start, end); err != nil {
 		klog.Warningf("DeleteContainerResourceMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeleteBECPUResourceMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteBECPUResourceMetric(start, end); err != nil {
 		klog.Warningf("DeleteBECPUResourceMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeletePodThrottledMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteSystemResourceMetric(start, end); err != nil {
+		klog.Warningf("DeleteSystemResourceMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeleteNUMAResourceMetric(start, end); err != nil {
+		klog.Warningf("DeleteNUMAResourceMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeletePodThrottledMetric(start, end); err != nil {
 		klog.Warningf("DeletePodThrottledMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeleteContainerThrottledMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteContainerThrottledMetric(start, end); err != nil {
 		klog.Warningf("DeleteContainerThrottledMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeleteContainerCPIMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteContainerCPIMetric(start, end); err != nil {
 		klog.Warningf("DeleteContainerCPIMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeleteContainerPSIMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeleteContainerPSIMetric(start, end); err != nil {
 		klog.Warningf("DeleteContainerPSIMetric failed during recycle, error %v", err)
 	}
-	if err := m.db.DeletePodPSIMetric(&oldTime, &expiredTime); err != nil {
+	if err := m.db.DeletePodPSIMetric(start, end); err != nil {
 		klog.Warningf("DeletePodPSIMetric failed during recycle, error %v", err)
 	}
-	// raw records do not need to cleanup
-	nodeResCount, _ := m.db.CountNodeResourceMetric()
-	podResCount, _ := m.db.CountPodResourceMetric()
-	containerResCount, _ := m.db.CountContainerResourceMetric()
-	beCPUResCount, _ := m.db.CountBECPUResourceMetric()
-	podThrottledResCount, _ := m.db.CountPodThrottledMetric()
-	containerThrottledResCount, _ := m.db.CountContainerThrottledMetric()
-	containerCPIResCount, _ := m.db.CountContainerCPIMetric()
-	containerPSIResCount, _ := m.db.CountContainerPSIMetric()
-	podPSIResCount, _ := m.db.CountPodPSIMetric()
-	klog.V(4).Infof("expired metric data before %v has been recycled, remaining in db size: "+
-		"nodeResCount=%v, podResCount=%v, containerResCount=%v, beCPUResCount=%v, podThrottledResCount=%v, "+
-		"containerThrottledResCount=%v, containerCPIResCount=%v, containerPSIResCount=%v, podPSIResCount=%v",
-		expiredTime, nodeResCount, podResCount, containerResCount, beCPUResCount, podThrottledResCount,
-		containerThrottledResCount, containerCPIResCount, containerPSIResCount, podPSIResCount)
+	if err := m.db.DeletePodSchedLatencyMetric(start, end); err != nil {
+		klog.Warningf("DeletePodSchedLatencyMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeletePodColdMemoryMetric(start, end); err != nil {
+		klog.Warningf("DeletePodColdMemoryMetric failed during recycle, error %v", err)
+	}
+	if err := m.db.DeletePodRDMAStatMetric(start, end); err != nil {
+		klog.Warningf("DeletePodRDMAStatMetric failed during recycle, error %v", err)
+	}
+}
+
+// pruneDB deletes the oldest metrics step by step until the data size is under maxSize, so that the database does not
+// grow unbounded when a node runs too many pods or containers within the expiration.
+func (m *metricCache) pruneDB(now, expiredTime time.Time, maxSize int64) {
+	step := now.Sub(expiredTime) / pruneDBSteps
+	if step <= 0 {
+		return
+	}
+	oldTime := time.Unix(0, 0)
+	pruneTime := expiredTime
+	for i := 0; i < pruneDBSteps; i++ {
+		used, _, err := m.db.Size()
+		if err != nil {
+			klog.Warningf("get database size failed during recycle, error %v", err)
+			return
+		}
+		if used <= maxSize {
+			return
+		}
+		pruneTime = pruneTime.Add(step)
+		klog.V(4).Infof("database size %v exceeds the limit %v, prune the metric data before %v", used, maxSize, pruneTime)
+		m.deleteMetrics(&oldTime, &pruneTime)
+	}
+}
+
+// compactDB releases the free pages left by the deleted metrics when they take a considerable part of the database.
+// The free pages are reused by the new metrics, so the compaction is skipped in the steady state.
+func (m *metricCache) compactDB() {
+	used, free, err := m.db.Size()
+	if err != nil {
+		klog.Warningf("get database size failed during recycle, error %v", err)
+		return
+	}
+	if free <= 0 || float64(free) < float64(used+free)*compactDBFreeRatio {
+		return
+	}
+	if err := m.db.Compact(); err != nil {
+		klog.Warningf("compact database failed during recycle, error %v", err)
+		return
+	}
+	klog.V(4).Infof("database has been compacted, released %v bytes", free)
 }
 
 func getAggregateFunc(aggregationType AggregationType) AggregationFunc {
@@ -914,6 +1304,30 @@ type PSIMetric struct {
 	CPUFullSupported bool
 }
 
+type SchedLatencyMetric struct {
+	// AvgLatencyMicroseconds is the mean latency of the tasks waiting on the run queue after the wakeup.
+	AvgLatencyMicroseconds float64
+	// DelayRatio is the total waiting time of the tasks divided by the collect interval, i.e. the average number of
+	// the tasks waiting on the run queue.
+	DelayRatio float64
+}
+
+type ColdMemoryMetric struct {
+	// ColdAnonBytes is the evictable anonymous pages idle for at least one kidled scan period, which can be swapped
+	// out without hurting the workloads.
+	ColdAnonBytes float64
+}
+
+// RDMAStatMetric is the per-second rates of the RDMA port counters. The congestion notifications (CNP) and the ECN
+// marked packets indicate the congestion of the RDMA network shared by the pods.
+type RDMAStatMetric struct {
+	RxBytesPerSecond    float64
+	TxBytesPerSecond    float64
+	CNPSentPerSecond    float64
+	CNPHandledPerSecond float64
+	ECNMarkedPerSecond  float64
+}
+
 func (m *metricCache) convertAndInsertContainerInterferenceMetric(t time.Time, metric *ContainerInterferenceMetric) error {
 	switch metric.MetricName {
 	case MetricNameContainerCPI:
@@ -959,6 +1373,33 @@ func (m *metricCache) convertAndInsertPodInterferenceMetric(t time.Time, metric
 			Timestamp:        t,
 		}
 		return m.db.InsertPodPSIMetric(dbItem)
+	case MetricNamePodSchedLatency:
+		dbItem := &podSchedLatencyMetric{
+			PodUID:                 metric.PodUID,
+			AvgLatencyMicroseconds: metric.MetricValue.(*SchedLatencyMetric).AvgLatencyMicroseconds,
+			DelayRatio:             metric.MetricValue.(*SchedLatencyMetric).DelayRatio,
+			Timestamp:              t,
+		}
+		return m.db.InsertPodSchedLatencyMetric(dbItem)
+	case MetricNamePodColdMemory:
+		dbItem := &podColdMemoryMetric{
+			PodUID:        metric.PodUID,
+			ColdAnonBytes: metric.MetricValue.(*ColdMemoryMetric).ColdAnonBytes,
+			Timestamp:     t,
+		}
+		return m.db.InsertPodColdMemoryMetric(dbItem)
+	case MetricNamePodRDMAStat:
+		rdmaStat := metric.MetricValue.(*RDMAStatMetric)
+		dbItem := &podRDMAStatMetric{
+			PodUID:              metric.PodUID,
+			RxBytesPerSecond:    rdmaStat.RxBytesPerSecond,
+			TxBytesPerSecond:    rdmaStat.TxBytesPerSecond,
+			CNPSentPerSecond:    rdmaStat.CNPSentPerSecond,
+			CNPHandledPerSecond: rdmaStat.CNPHandledPerSecond,
+			ECNMarkedPerSecond:  rdmaStat.ECNMarkedPerSecond,
+			Timestamp:           t,
+		}
+		return m.db.InsertPodRDMAStatMetric(dbItem)
 	default:
 		return fmt.Errorf("get unknown metric name")
 	}
@@ -1009,7 +1450,45 @@ func (m *metricCache) convertAndGetPodInterferenceMetric(metricName Interference
 		}, nil
 	case MetricNamePodPSI:
 		return m.db.GetPodPSIMetric(podUID, start, end)
+	case MetricNamePodSchedLatency:
+		return m.db.GetPodSchedLatencyMetric(podUID, start, end)
+	case MetricNamePodColdMemory:
+		return m.db.GetPodColdMemoryMetric(podUID, start, end)
+	case MetricNamePodRDMAStat:
+		return m.db.GetPodRDMAStatMetric(podUID, start, end)
 	default:
 		return nil, fmt.Errorf("get unknown metric name")
 	}
 }
+
+func toHugePagesBytes(hugePages corev1.ResourceList) HugePagesBytes {
+	if len(hugePages) == 0 {
+		return nil
+	}
+	m := make(HugePagesBytes, len(hugePages))
+	for resourceName, q := range hugePages {
+		m[string(resourceName)] = float64(q.Value())
+	}
+	return m
+}
+
+// latestHugePages returns the hugepages of the latest sample rather than aggregating the samples, since the hugepages
+// are preallocated and only change on the pod creation and deletion.
+func latestHugePages(n int, sample func(i int) (time.Time, HugePagesBytes)) corev1.ResourceList {
+	var latestTime time.Time
+	var latest HugePagesBytes
+	for i := 0; i < n; i++ {
+		t, m := sample(i)
+		if len(m) > 0 && !t.Before(latestTime) {
+			latestTime, latest = t, m
+		}
+	}
+	if len(latest) == 0 {
+		return nil
+	}
+	hugePages := make(corev1.ResourceList, len(latest))
+	for resourceName, bytes := range latest {
+		hugePages[corev1.ResourceName(resourceName)] = *resource.NewQuantity(int64(bytes), resource.BinarySI)
+	}
+	return hugePages
+}
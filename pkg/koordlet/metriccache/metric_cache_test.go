@@ -17,11 +17,14 @@ limitations under the License.
 package metriccache
 
 import (
+	"fmt"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
@@ -58,6 +61,16 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(30, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(15000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(60, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(30, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(600, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -82,6 +95,16 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(10, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(5000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(20, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(10, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(200, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -106,6 +129,16 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(400, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -133,6 +166,16 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+					},
+					NetworkUsed: NetworkMetric{
+						RxBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						TxBytesPerSec: *resource.NewQuantity(400, resource.DecimalSI),
+					},
 					GPUs: []GPUMetric{
 						{
 							DeviceUUID:  "1",
@@ -164,6 +207,16 @@ func Test_metricCache_NodeResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(15, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(7500, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(1500, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(30, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(15, resource.DecimalSI),
+					},
+					NetworkUsed: NetworkMetric{
+						RxBytesPerSec: *resource.NewQuantity(1500, resource.DecimalSI),
+						TxBytesPerSec: *resource.NewQuantity(300, resource.DecimalSI),
+					},
 					GPUs: []GPUMetric{
 						{
 							DeviceUUID:  "1",
@@ -265,6 +318,16 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(30, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(15000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(60, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(30, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(600, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -290,6 +353,16 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(10, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(5000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(20, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(10, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(200, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -315,6 +388,16 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(400, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -340,6 +423,16 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
+						NetworkUsed: NetworkMetric{
+							RxBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							TxBytesPerSec: *resource.NewQuantity(400, resource.DecimalSI),
+						},
 					},
 				},
 			},
@@ -352,6 +445,16 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+					},
+					NetworkUsed: NetworkMetric{
+						RxBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						TxBytesPerSec: *resource.NewQuantity(400, resource.DecimalSI),
+					},
 					GPUs: []GPUMetric{
 						{
 							DeviceUUID:  "1",
@@ -381,6 +484,16 @@ func Test_metricCache_PodResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(15, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(7500, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(1500, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(30, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(15, resource.DecimalSI),
+					},
+					NetworkUsed: NetworkMetric{
+						RxBytesPerSec: *resource.NewQuantity(1500, resource.DecimalSI),
+						TxBytesPerSec: *resource.NewQuantity(300, resource.DecimalSI),
+					},
 					GPUs: []GPUMetric{
 						{
 							DeviceUUID:  "1",
@@ -479,6 +592,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(30, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(15000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(60, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(30, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -504,6 +623,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(10, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(5000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(20, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(10, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -529,6 +654,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
 						GPUs: []GPUMetric{
 							{
 								DeviceUUID:  "1",
@@ -554,6 +685,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
 					},
 				},
 			},
@@ -566,6 +703,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+					},
 					GPUs: []GPUMetric{
 						{
 							DeviceUUID:  "1",
@@ -594,6 +737,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(15, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(7500, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(1500, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(30, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(15, resource.DecimalSI),
+					},
 					GPUs: []GPUMetric{
 						{
 							DeviceUUID:  "1",
@@ -632,6 +781,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(30, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(15000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(60, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(30, resource.DecimalSI),
+						},
 					},
 					now.Add(-time.Second * 10): {
 						ContainerID: "container-id-3",
@@ -641,6 +796,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(10, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(5000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(20, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(10, resource.DecimalSI),
+						},
 					},
 					now.Add(-time.Second * 5): {
 						ContainerID: "container-id-3",
@@ -650,6 +811,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
 					},
 					now.Add(-time.Second * 4): {
 						ContainerID: "container-id-4",
@@ -659,6 +826,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 						MemoryUsed: MemoryMetric{
 							MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 						},
+						DiskIOUsed: DiskIOMetric{
+							ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+							WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+							ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+							WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+						},
 					},
 				},
 			},
@@ -671,6 +844,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+					},
 				},
 				QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 3}},
 			},
@@ -683,6 +862,12 @@ func Test_metricCache_ContainerResourceMetric_CRUD(t *testing.T) {
 					MemoryUsed: MemoryMetric{
 						MemoryWithoutCache: *resource.NewQuantity(20, resource.BinarySI),
 					},
+					DiskIOUsed: DiskIOMetric{
+						ReadBytesPerSec:  *resource.NewQuantity(10000, resource.DecimalSI),
+						WriteBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+						ReadIOPS:         *resource.NewQuantity(40, resource.DecimalSI),
+						WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+					},
 				},
 				QueryResult: QueryResult{AggregateInfo: &AggregateInfo{MetricsCount: 2}},
 			},
@@ -832,6 +1017,176 @@ func Test_metricCache_BECPUResourceMetric_CRUD(t *testing.T) {
 	}
 }
 
+func Test_metricCache_SystemResourceMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	samples := map[time.Time]SystemResourceMetric{
+		now.Add(-time.Second * 120): {
+			CPUUsed:    CPUMetric{CPUUsed: *resource.NewQuantity(2, resource.DecimalSI)},
+			MemoryUsed: MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(3*1024*1024*1024, resource.BinarySI)},
+		},
+		now.Add(-time.Second * 10): {
+			CPUUsed:    CPUMetric{CPUUsed: *resource.NewQuantity(1, resource.DecimalSI)},
+			MemoryUsed: MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(2*1024*1024*1024, resource.BinarySI)},
+		},
+		now.Add(-time.Second * 5): {
+			CPUUsed:    CPUMetric{CPUUsed: *resource.NewMilliQuantity(500, resource.DecimalSI)},
+			MemoryUsed: MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(1024*1024*1024, resource.BinarySI)},
+		},
+	}
+	for ts, sample := range samples {
+		err := m.InsertSystemResourceMetric(ts, &sample)
+		assert.NoError(t, err)
+	}
+
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeAVG,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetSystemResourceMetric(params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(3), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, int64(1166), got.Metric.CPUUsed.CPUUsed.MilliValue())
+	assert.Equal(t, int64(2*1024*1024*1024), got.Metric.MemoryUsed.MemoryWithoutCache.Value())
+
+	// delete expire items
+	m.recycleDB()
+
+	gotAfterDel := m.GetSystemResourceMetric(params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, int64(2), gotAfterDel.AggregateInfo.MetricsCount)
+	assert.Equal(t, int64(750), gotAfterDel.Metric.CPUUsed.CPUUsed.MilliValue())
+	assert.Equal(t, int64(1536*1024*1024), gotAfterDel.Metric.MemoryUsed.MemoryWithoutCache.Value())
+
+	// no metric in the window
+	start := now.Add(time.Second)
+	end := now.Add(2 * time.Second)
+	gotEmpty := m.GetSystemResourceMetric(&QueryParam{Aggregate: AggregationTypeAVG, Start: &start, End: &end})
+	assert.Error(t, gotEmpty.Error)
+	assert.Nil(t, gotEmpty.Metric)
+
+	gotIllegal := m.GetSystemResourceMetric(nil)
+	assert.Error(t, gotIllegal.Error)
+}
+
+func Test_metricCache_PodResourceMetric_HugePages(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	podUID := "pod-uid-1"
+	assert.NoError(t, m.InsertPodResourceMetric(now.Add(-time.Second*10), &PodResourceMetric{
+		PodUID:        podUID,
+		HugePagesUsed: corev1.ResourceList{"hugepages-2Mi": resource.MustParse("4Mi")},
+	}))
+	assert.NoError(t, m.InsertPodResourceMetric(now.Add(-time.Second*5), &PodResourceMetric{
+		PodUID:        podUID,
+		HugePagesUsed: corev1.ResourceList{"hugepages-2Mi": resource.MustParse("8Mi")},
+	}))
+
+	start := now.Add(-time.Minute)
+	got := m.GetPodResourceMetric(&podUID, &QueryParam{Aggregate: AggregationTypeAVG, Start: &start, End: &now})
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(8*1024*1024), got.Metric.HugePagesUsed.Name("hugepages-2Mi", resource.BinarySI).Value())
+
+	// the pod using no hugepages has none reported
+	otherPodUID := "pod-uid-2"
+	assert.NoError(t, m.InsertPodResourceMetric(now.Add(-time.Second*5), &PodResourceMetric{PodUID: otherPodUID}))
+	got = m.GetPodResourceMetric(&otherPodUID, &QueryParam{Aggregate: AggregationTypeAVG, Start: &start, End: &now})
+	assert.NoError(t, got.Error)
+	assert.Nil(t, got.Metric.HugePagesUsed)
+}
+
+func Test_metricCache_NUMAResourceMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	newSample := func(numaNodeID int32, cpuMilli int64, memoryUsedGi, memoryFreeGi int64) *NUMAResourceMetric {
+		return &NUMAResourceMetric{
+			NUMANodeID: numaNodeID,
+			CPUUsed:    CPUMetric{CPUUsed: *resource.NewMilliQuantity(cpuMilli, resource.DecimalSI)},
+			MemoryUsed: MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(memoryUsedGi*1024*1024*1024, resource.BinarySI)},
+			MemoryFree: *resource.NewQuantity(memoryFreeGi*1024*1024*1024, resource.BinarySI),
+		}
+	}
+	assert.NoError(t, m.InsertNUMAResourceMetric(now.Add(-time.Second*120), newSample(1, 4000, 8, 8)))
+	assert.NoError(t, m.InsertNUMAResourceMetric(now.Add(-time.Second*10), newSample(1, 2000, 4, 12)))
+	hugePagesSample := newSample(0, 1000, 2, 14)
+	hugePagesSample.HugePagesTotal = corev1.ResourceList{"hugepages-2Mi": resource.MustParse("1Gi")}
+	hugePagesSample.HugePagesFree = corev1.ResourceList{"hugepages-2Mi": resource.MustParse("1Gi")}
+	assert.NoError(t, m.InsertNUMAResourceMetric(now.Add(-time.Second*10), hugePagesSample))
+	hugePagesSample = newSample(0, 3000, 4, 12)
+	hugePagesSample.HugePagesTotal = corev1.ResourceList{"hugepages-2Mi": resource.MustParse("1Gi")}
+	hugePagesSample.HugePagesFree = corev1.ResourceList{"hugepages-2Mi": resource.MustParse("256Mi")}
+	assert.NoError(t, m.InsertNUMAResourceMetric(now.Add(-time.Second*5), hugePagesSample))
+
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeAVG,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetNUMAResourceMetric(params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(4), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, 2, len(got.Metrics))
+	assert.Equal(t, int32(0), got.Metrics[0].NUMANodeID)
+	assert.Equal(t, int64(2000), got.Metrics[0].CPUUsed.CPUUsed.MilliValue())
+	assert.Equal(t, int64(3*1024*1024*1024), got.Metrics[0].MemoryUsed.MemoryWithoutCache.Value())
+	assert.Equal(t, int64(13*1024*1024*1024), got.Metrics[0].MemoryFree.Value())
+	// the hugepages are of the latest sample
+	assert.Equal(t, int64(1024*1024*1024), got.Metrics[0].HugePagesTotal.Name("hugepages-2Mi", resource.BinarySI).Value())
+	assert.Equal(t, int64(256*1024*1024), got.Metrics[0].HugePagesFree.Name("hugepages-2Mi", resource.BinarySI).Value())
+	assert.Nil(t, got.Metrics[1].HugePagesTotal)
+	assert.Equal(t, int32(1), got.Metrics[1].NUMANodeID)
+	assert.Equal(t, int64(3000), got.Metrics[1].CPUUsed.CPUUsed.MilliValue())
+	assert.Equal(t, int64(6*1024*1024*1024), got.Metrics[1].MemoryUsed.MemoryWithoutCache.Value())
+	assert.Equal(t, int64(10*1024*1024*1024), got.Metrics[1].MemoryFree.Value())
+
+	// delete expire items
+	m.recycleDB()
+
+	gotAfterDel := m.GetNUMAResourceMetric(params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, int64(3), gotAfterDel.AggregateInfo.MetricsCount)
+	assert.Equal(t, 2, len(gotAfterDel.Metrics))
+	assert.Equal(t, int64(2000), gotAfterDel.Metrics[1].CPUUsed.CPUUsed.MilliValue())
+	assert.Equal(t, int64(12*1024*1024*1024), gotAfterDel.Metrics[1].MemoryFree.Value())
+
+	// no metric in the window
+	start := now.Add(time.Second)
+	end := now.Add(2 * time.Second)
+	gotEmpty := m.GetNUMAResourceMetric(&QueryParam{Aggregate: AggregationTypeAVG, Start: &start, End: &end})
+	assert.Error(t, gotEmpty.Error)
+	assert.Nil(t, gotEmpty.Metrics)
+
+	gotIllegal := m.GetNUMAResourceMetric(nil)
+	assert.Error(t, gotIllegal.Error)
+}
+
 func Test_metricCache_NodeCPUInfo_CRUD(t *testing.T) {
 	type args struct {
 		config  *Config
@@ -1225,12 +1580,12 @@ func Test_metricCache_aggregateGPUUsages(t *testing.T) {
 				aggregateFunc: getAggregateFunc(AggregationTypeAVG),
 				gpuResourceMetrics: [][]gpuResourceMetric{
 					{
-						{DeviceUUID: "1-1", Minor: 0, SMUtil: 20, MemoryUsed: 1000, MemoryTotal: 10000},
-						{DeviceUUID: "2-1", Minor: 1, SMUtil: 40, MemoryUsed: 2000, MemoryTotal: 20000},
+						{DeviceUUID: "1-1", Minor: 0, SMUtil: 20, MemoryUsed: 1000, MemoryTotal: 10000, Temperature: 40},
+						{DeviceUUID: "2-1", Minor: 1, SMUtil: 40, MemoryUsed: 2000, MemoryTotal: 20000, Temperature: 50},
 					},
 					{
-						{DeviceUUID: "1-1", Minor: 0, SMUtil: 40, MemoryUsed: 4000, MemoryTotal: 10000},
-						{DeviceUUID: "2-1", Minor: 1, SMUtil: 30, MemoryUsed: 1000, MemoryTotal: 20000},
+						{DeviceUUID: "1-1", Minor: 0, SMUtil: 40, MemoryUsed: 4000, MemoryTotal: 10000, Temperature: 60},
+						{DeviceUUID: "2-1", Minor: 1, SMUtil: 30, MemoryUsed: 1000, MemoryTotal: 20000, Temperature: 70},
 					},
 				},
 			},
@@ -1241,6 +1596,7 @@ func Test_metricCache_aggregateGPUUsages(t *testing.T) {
 					SMUtil:      30,
 					MemoryUsed:  *resource.NewQuantity(2500, resource.BinarySI),
 					MemoryTotal: *resource.NewQuantity(10000, resource.BinarySI),
+					Temperature: 50,
 				},
 				{
 					DeviceUUID:  "2-1",
@@ -1248,6 +1604,7 @@ func Test_metricCache_aggregateGPUUsages(t *testing.T) {
 					SMUtil:      35,
 					MemoryUsed:  *resource.NewQuantity(1500, resource.BinarySI),
 					MemoryTotal: *resource.NewQuantity(20000, resource.BinarySI),
+					Temperature: 60,
 				},
 			},
 		},
@@ -2431,3 +2788,276 @@ func Test_metricCache_PodPSIMetric_CRUD(t *testing.T) {
 		})
 	}
 }
+
+func Test_metricCache_PodSchedLatencyMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	samples := map[time.Time]PodInterferenceMetric{
+		now.Add(-time.Second * 120): {
+			MetricName:  MetricNamePodSchedLatency,
+			PodUID:      "pod-uid-1",
+			MetricValue: &SchedLatencyMetric{AvgLatencyMicroseconds: 900, DelayRatio: 0.9},
+		},
+		now.Add(-time.Second * 10): {
+			MetricName:  MetricNamePodSchedLatency,
+			PodUID:      "pod-uid-1",
+			MetricValue: &SchedLatencyMetric{AvgLatencyMicroseconds: 300, DelayRatio: 0.75},
+		},
+		now.Add(-time.Second * 5): {
+			MetricName:  MetricNamePodSchedLatency,
+			PodUID:      "pod-uid-1",
+			MetricValue: &SchedLatencyMetric{AvgLatencyMicroseconds: 100, DelayRatio: 0.25},
+		},
+		now.Add(-time.Second * 4): {
+			MetricName:  MetricNamePodSchedLatency,
+			PodUID:      "pod-uid-2",
+			MetricValue: &SchedLatencyMetric{AvgLatencyMicroseconds: 50, DelayRatio: 0.05},
+		},
+	}
+	for ts, sample := range samples {
+		sample := sample
+		assert.NoError(t, m.InsertPodInterferenceMetrics(ts, &sample))
+	}
+
+	podUID := "pod-uid-1"
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeLast,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetPodInterferenceMetric(MetricNamePodSchedLatency, &podUID, params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(3), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, &SchedLatencyMetric{AvgLatencyMicroseconds: 100, DelayRatio: 0.25}, got.Metric.MetricValue)
+
+	// delete expire items
+	m.recycleDB()
+	params.Aggregate = AggregationTypeAVG
+	gotAfterDel := m.GetPodInterferenceMetric(MetricNamePodSchedLatency, &podUID, params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, int64(2), gotAfterDel.AggregateInfo.MetricsCount)
+	assert.Equal(t, &SchedLatencyMetric{AvgLatencyMicroseconds: 200, DelayRatio: 0.5}, gotAfterDel.Metric.MetricValue)
+}
+
+func Test_metricCache_PodColdMemoryMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	samples := map[time.Time]PodInterferenceMetric{
+		now.Add(-time.Second * 120): {
+			MetricName:  MetricNamePodColdMemory,
+			PodUID:      "pod-uid-1",
+			MetricValue: &ColdMemoryMetric{ColdAnonBytes: 8 << 20},
+		},
+		now.Add(-time.Second * 10): {
+			MetricName:  MetricNamePodColdMemory,
+			PodUID:      "pod-uid-1",
+			MetricValue: &ColdMemoryMetric{ColdAnonBytes: 2 << 20},
+		},
+		now.Add(-time.Second * 5): {
+			MetricName:  MetricNamePodColdMemory,
+			PodUID:      "pod-uid-1",
+			MetricValue: &ColdMemoryMetric{ColdAnonBytes: 4 << 20},
+		},
+		now.Add(-time.Second * 4): {
+			MetricName:  MetricNamePodColdMemory,
+			PodUID:      "pod-uid-2",
+			MetricValue: &ColdMemoryMetric{ColdAnonBytes: 1 << 20},
+		},
+	}
+	for ts, sample := range samples {
+		sample := sample
+		assert.NoError(t, m.InsertPodInterferenceMetrics(ts, &sample))
+	}
+
+	podUID := "pod-uid-1"
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeLast,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetPodInterferenceMetric(MetricNamePodColdMemory, &podUID, params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(3), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, &ColdMemoryMetric{ColdAnonBytes: 4 << 20}, got.Metric.MetricValue)
+
+	// delete expire items
+	m.recycleDB()
+	params.Aggregate = AggregationTypeAVG
+	gotAfterDel := m.GetPodInterferenceMetric(MetricNamePodColdMemory, &podUID, params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, int64(2), gotAfterDel.AggregateInfo.MetricsCount)
+	assert.Equal(t, &ColdMemoryMetric{ColdAnonBytes: 3 << 20}, gotAfterDel.Metric.MetricValue)
+}
+
+func Test_metricCache_PodRDMAStatMetric_CRUD(t *testing.T) {
+	now := time.Now()
+	s, _ := NewStorage()
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			MetricExpireSeconds:     60,
+		},
+		db: s,
+	}
+	samples := map[time.Time]PodInterferenceMetric{
+		now.Add(-time.Second * 120): {
+			MetricName:  MetricNamePodRDMAStat,
+			PodUID:      "pod-uid-1",
+			MetricValue: &RDMAStatMetric{RxBytesPerSecond: 1000, CNPHandledPerSecond: 10},
+		},
+		now.Add(-time.Second * 10): {
+			MetricName:  MetricNamePodRDMAStat,
+			PodUID:      "pod-uid-1",
+			MetricValue: &RDMAStatMetric{RxBytesPerSecond: 200, TxBytesPerSecond: 100, CNPHandledPerSecond: 2},
+		},
+		now.Add(-time.Second * 5): {
+			MetricName:  MetricNamePodRDMAStat,
+			PodUID:      "pod-uid-1",
+			MetricValue: &RDMAStatMetric{RxBytesPerSecond: 400, TxBytesPerSecond: 300, CNPHandledPerSecond: 4},
+		},
+		now.Add(-time.Second * 4): {
+			MetricName:  MetricNamePodRDMAStat,
+			PodUID:      "pod-uid-2",
+			MetricValue: &RDMAStatMetric{RxBytesPerSecond: 100},
+		},
+	}
+	for ts, sample := range samples {
+		sample := sample
+		assert.NoError(t, m.InsertPodInterferenceMetrics(ts, &sample))
+	}
+
+	podUID := "pod-uid-1"
+	oldStartTime := time.Unix(0, 0)
+	params := &QueryParam{
+		Aggregate: AggregationTypeLast,
+		Start:     &oldStartTime,
+		End:       &now,
+	}
+	got := m.GetPodInterferenceMetric(MetricNamePodRDMAStat, &podUID, params)
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(3), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, &RDMAStatMetric{RxBytesPerSecond: 400, TxBytesPerSecond: 300, CNPHandledPerSecond: 4}, got.Metric.MetricValue)
+
+	// delete expire items
+	m.recycleDB()
+	params.Aggregate = AggregationTypeAVG
+	gotAfterDel := m.GetPodInterferenceMetric(MetricNamePodRDMAStat, &podUID, params)
+	assert.NoError(t, gotAfterDel.Error)
+	assert.Equal(t, int64(2), gotAfterDel.AggregateInfo.MetricsCount)
+	assert.Equal(t, &RDMAStatMetric{RxBytesPerSecond: 300, TxBytesPerSecond: 200, CNPHandledPerSecond: 3}, gotAfterDel.Metric.MetricValue)
+}
+
+func Test_metricCache_recycleDB_pruneAndCompact(t *testing.T) {
+	now := time.Now()
+	s, err := newStorage(fmt.Sprintf("file:%s?loc=auto&_busy_timeout=5000", filepath.Join(t.TempDir(), "metrics.db")))
+	assert.NoError(t, err)
+	defer s.Close()
+	m := &metricCache{
+		config: &Config{
+			MetricGCIntervalSeconds: 60,
+			// the expiration is long enough that no samples are expired even on the slow disks
+			MetricExpireSeconds: 3600,
+		},
+		db: s,
+	}
+	emptyUsed, _, err := s.Size()
+	assert.NoError(t, err)
+	podUID := "test-pod-uid"
+	// one sample per second within the expiration, whose pages outweigh the empty tables
+	for i := 0; i < 2000; i++ {
+		err := m.InsertPodResourceMetric(now.Add(-time.Duration(i)*time.Second-time.Millisecond), &PodResourceMetric{
+			PodUID:     podUID,
+			CPUUsed:    CPUMetric{CPUUsed: *resource.NewQuantity(1, resource.DecimalSI)},
+			MemoryUsed: MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(1024, resource.BinarySI)},
+		})
+		assert.NoError(t, err)
+	}
+	count := func() int64 {
+		c, err := s.CountPodResourceMetric()
+		assert.NoError(t, err)
+		return c
+	}
+
+	// nothing is expired or pruned without the limit
+	m.recycleDB()
+	assert.Equal(t, int64(2000), count())
+	used, _, err := s.Size()
+	assert.NoError(t, err)
+
+	// the oldest metrics are pruned until the size is under the limit
+	maxSize := emptyUsed + (used-emptyUsed)/2
+	m.pruneDB(now, now.Add(-2000*time.Second), maxSize)
+	remaining := count()
+	assert.Less(t, remaining, int64(2000))
+	assert.Greater(t, remaining, int64(0))
+	gotUsed, gotFree, err := s.Size()
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, gotUsed, maxSize)
+	assert.Greater(t, gotFree, int64(0))
+	oldTime, newestPruned := time.Unix(0, 0), now.Add(-time.Duration(remaining)*time.Second)
+	oldest, err := s.GetPodResourceMetric(&podUID, &oldTime, &newestPruned)
+	assert.NoError(t, err)
+	assert.Len(t, oldest, 0, "the oldest metrics should be pruned")
+
+	// the free pages are released
+	m.compactDB()
+	_, gotFree, err = s.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), gotFree)
+	assert.Equal(t, remaining, count())
+}
+
+func Test_metricCache_persistAcrossRestart(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.MetricDBPath = filepath.Join(t.TempDir(), "metric-cache.db")
+	now := time.Now()
+	start, end := now.Add(-time.Hour), now.Add(time.Second)
+
+	c, err := NewMetricCache(cfg)
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		assert.NoError(t, c.Run(stopCh))
+		close(stopped)
+	}()
+	for i := 1; i <= 100; i++ {
+		assert.NoError(t, c.InsertNodeResourceMetric(now.Add(-time.Duration(i)*time.Second), &NodeResourceMetric{
+			CPUUsed:    CPUMetric{CPUUsed: *resource.NewMilliQuantity(int64(i*100), resource.DecimalSI)},
+			MemoryUsed: MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(1024, resource.BinarySI)},
+		}))
+	}
+	close(stopCh)
+	// the database is closed after the GC loop stops
+	<-stopped
+	assert.NoError(t, c.Close())
+	assert.Error(t, c.(*metricCache).db.CheckIntegrity())
+
+	// the P95 of the window before the restart is kept
+	c, err = NewMetricCache(cfg)
+	assert.NoError(t, err)
+	defer c.Close()
+	got := c.GetNodeResourceMetric(&QueryParam{Aggregate: AggregationTypeP95, Start: &start, End: &end})
+	assert.NoError(t, got.Error)
+	assert.Equal(t, int64(100), got.AggregateInfo.MetricsCount)
+	assert.Equal(t, int64(9500), got.Metric.CPUUsed.CPUUsed.MilliValue())
+}
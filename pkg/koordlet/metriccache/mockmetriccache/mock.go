@@ -51,6 +51,20 @@ func (m *MockMetricCache) EXPECT() *MockMetricCacheMockRecorder {
 	return m.recorder
 }
 
+// Close mocks base method.
+func (m *MockMetricCache) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockMetricCacheMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockMetricCache)(nil).Close))
+}
+
 // GetBECPUResourceMetric mocks base method.
 func (m *MockMetricCache) GetBECPUResourceMetric(param *metriccache.QueryParam) metriccache.BECPUResourceQueryResult {
 	m.ctrl.T.Helper()
@@ -107,6 +121,20 @@ func (mr *MockMetricCacheMockRecorder) GetContainerThrottledMetric(containerID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContainerThrottledMetric", reflect.TypeOf((*MockMetricCache)(nil).GetContainerThrottledMetric), containerID, param)
 }
 
+// GetNUMAResourceMetric mocks base method.
+func (m *MockMetricCache) GetNUMAResourceMetric(param *metriccache.QueryParam) metriccache.NUMAResourceQueryResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNUMAResourceMetric", param)
+	ret0, _ := ret[0].(metriccache.NUMAResourceQueryResult)
+	return ret0
+}
+
+// GetNUMAResourceMetric indicates an expected call of GetNUMAResourceMetric.
+func (mr *MockMetricCacheMockRecorder) GetNUMAResourceMetric(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNUMAResourceMetric", reflect.TypeOf((*MockMetricCache)(nil).GetNUMAResourceMetric), param)
+}
+
 // GetNodeCPUInfo mocks base method.
 func (m *MockMetricCache) GetNodeCPUInfo(param *metriccache.QueryParam) (*metriccache.NodeCPUInfo, error) {
 	m.ctrl.T.Helper()
@@ -178,6 +206,20 @@ func (mr *MockMetricCacheMockRecorder) GetPodThrottledMetric(podUID, param inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodThrottledMetric", reflect.TypeOf((*MockMetricCache)(nil).GetPodThrottledMetric), podUID, param)
 }
 
+// GetSystemResourceMetric mocks base method.
+func (m *MockMetricCache) GetSystemResourceMetric(param *metriccache.QueryParam) metriccache.SystemResourceQueryResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemResourceMetric", param)
+	ret0, _ := ret[0].(metriccache.SystemResourceQueryResult)
+	return ret0
+}
+
+// GetSystemResourceMetric indicates an expected call of GetSystemResourceMetric.
+func (mr *MockMetricCacheMockRecorder) GetSystemResourceMetric(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemResourceMetric", reflect.TypeOf((*MockMetricCache)(nil).GetSystemResourceMetric), param)
+}
+
 // InsertBECPUResourceMetric mocks base method.
 func (m *MockMetricCache) InsertBECPUResourceMetric(t time.Time, metric *metriccache.BECPUResourceMetric) error {
 	m.ctrl.T.Helper()
@@ -234,6 +276,20 @@ func (mr *MockMetricCacheMockRecorder) InsertContainerThrottledMetrics(t, metric
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertContainerThrottledMetrics", reflect.TypeOf((*MockMetricCache)(nil).InsertContainerThrottledMetrics), t, metric)
 }
 
+// InsertNUMAResourceMetric mocks base method.
+func (m *MockMetricCache) InsertNUMAResourceMetric(t time.Time, metric *metriccache.NUMAResourceMetric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNUMAResourceMetric", t, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertNUMAResourceMetric indicates an expected call of InsertNUMAResourceMetric.
+func (mr *MockMetricCacheMockRecorder) InsertNUMAResourceMetric(t, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNUMAResourceMetric", reflect.TypeOf((*MockMetricCache)(nil).InsertNUMAResourceMetric), t, metric)
+}
+
 // InsertNodeCPUInfo mocks base method.
 func (m *MockMetricCache) InsertNodeCPUInfo(info *metriccache.NodeCPUInfo) error {
 	m.ctrl.T.Helper()
@@ -304,6 +360,20 @@ func (mr *MockMetricCacheMockRecorder) InsertPodThrottledMetrics(t, metric inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPodThrottledMetrics", reflect.TypeOf((*MockMetricCache)(nil).InsertPodThrottledMetrics), t, metric)
 }
 
+// InsertSystemResourceMetric mocks base method.
+func (m *MockMetricCache) InsertSystemResourceMetric(t time.Time, metric *metriccache.SystemResourceMetric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSystemResourceMetric", t, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertSystemResourceMetric indicates an expected call of InsertSystemResourceMetric.
+func (mr *MockMetricCacheMockRecorder) InsertSystemResourceMetric(t, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSystemResourceMetric", reflect.TypeOf((*MockMetricCache)(nil).InsertSystemResourceMetric), t, metric)
+}
+
 // Run mocks base method.
 func (m *MockMetricCache) Run(stopCh <-chan struct{}) error {
 	m.ctrl.T.Helper()
@@ -18,11 +18,14 @@ package metriccache
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"k8s.io/klog/v2"
 )
 
 type storage struct {
@@ -32,6 +35,32 @@ type storage struct {
 func NewStorage() (*storage, error) {
 	return newStorage("file::memory:?mode=memory&cache=shared&loc=auto&_busy_timeout=5000")
 }
+
+// NewFileStorage opens the storage persisted in the file at path. Since the metrics are only a cache refilled by the
+// collectors, a corrupted file, e.g. left by a node crash in the middle of a write, is dropped and rebuilt.
+func NewFileStorage(path string) (*storage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("fail to create database dir, %v", err)
+	}
+	// the WAL journal with the NORMAL synchronous mode avoids a fsync per insert, and loses at most the latest
+	// transactions on a power failure without corrupting the database
+	dsn := fmt.Sprintf("file:%s?loc=auto&_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL", path)
+	s, err := newStorage(dsn)
+	if err == nil {
+		if err = s.CheckIntegrity(); err == nil {
+			return s, nil
+		}
+		s.Close()
+	}
+	klog.Warningf("database %s is corrupted and will be rebuilt, error %v", path, err)
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("fail to remove corrupted database, %v", err)
+		}
+	}
+	return newStorage(dsn)
+}
+
 func newStorage(dsn string) (*storage, error) {
 	db, err := gorm.Open(sqlite.Open(dsn),
 		&gorm.Config{})
@@ -39,24 +68,29 @@ func newStorage(dsn string) (*storage, error) {
 		return nil, fmt.Errorf("fail to create database, %v", err)
 	}
 
-	db.AutoMigrate(&nodeResourceMetric{}, &podResourceMetric{}, &containerResourceMetric{}, &beCPUResourceMetric{})
-	db.AutoMigrate(&rawRecord{})
-	db.AutoMigrate(&podThrottledMetric{}, &containerThrottledMetric{})
-	db.AutoMigrate(&containerCPIMetric{}, &containerPSIMetric{}, &podPSIMetric{})
-
 	database, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("fail to init database %v", err)
 	}
 	database.SetMaxOpenConns(1)
 
+	// the tables persisted by an older version are migrated on start, and the database is rebuilt by the caller
+	// if the migration fails
+	if err = db.AutoMigrate(&nodeResourceMetric{}, &podResourceMetric{}, &containerResourceMetric{}, &beCPUResourceMetric{},
+		&systemResourceMetric{}, &numaResourceMetric{}, &rawRecord{}, &podThrottledMetric{}, &containerThrottledMetric{},
+		&containerCPIMetric{}, &containerPSIMetric{}, &podPSIMetric{}, &podSchedLatencyMetric{}, &podColdMemoryMetric{},
+		&podRDMAStatMetric{}); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("fail to migrate database, %v", err)
+	}
+
 	s := &storage{
 		db: db,
 	}
 	return s, nil
 }
 
-// for ut only, Close() is not necessary for gorm
+// Close closes the database, which is necessary for the file storage to checkpoint the WAL journal on stop.
 func (s *storage) Close() error {
 	d, err := s.db.DB()
 	if err != nil {
@@ -65,6 +99,37 @@ func (s *storage) Close() error {
 	return d.Close()
 }
 
+func (s *storage) CheckIntegrity() error {
+	var results []string
+	if err := s.db.Raw("PRAGMA quick_check").Scan(&results).Error; err != nil {
+		return err
+	}
+	if len(results) != 1 || results[0] != "ok" {
+		return fmt.Errorf("integrity check failed, %v", results)
+	}
+	return nil
+}
+
+// Size returns the bytes of the pages holding data and the bytes of the free pages left by the deleted records.
+func (s *storage) Size() (used int64, free int64, err error) {
+	var pageSize, pageCount, freelistCount int64
+	if err = s.db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		return 0, 0, err
+	}
+	if err = s.db.Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		return 0, 0, err
+	}
+	if err = s.db.Raw("PRAGMA freelist_count").Scan(&freelistCount).Error; err != nil {
+		return 0, 0, err
+	}
+	return (pageCount - freelistCount) * pageSize, freelistCount * pageSize, nil
+}
+
+// Compact rebuilds the database to release the free pages.
+func (s *storage) Compact() error {
+	return s.db.Exec("VACUUM").Error
+}
+
 func (s *storage) InsertNodeResourceMetric(n *nodeResourceMetric) error {
 	return s.db.Create(n).Error
 }
@@ -81,6 +146,14 @@ func (s *storage) InsertBECPUResourceMetric(b *beCPUResourceMetric) error {
 	return s.db.Create(b).Error
 }
 
+func (s *storage) InsertSystemResourceMetric(m *systemResourceMetric) error {
+	return s.db.Create(m).Error
+}
+
+func (s *storage) InsertNUMAResourceMetric(m *numaResourceMetric) error {
+	return s.db.Create(m).Error
+}
+
 // InsertRawRecord inserts a raw record into the db
 func (s *storage) InsertRawRecord(record *rawRecord) error {
 	return s.db.Clauses(clause.OnConflict{
@@ -108,6 +181,18 @@ func (s *storage) InsertPodPSIMetric(m *podPSIMetric) error {
 	return s.db.Create(m).Error
 }
 
+func (s *storage) InsertPodSchedLatencyMetric(m *podSchedLatencyMetric) error {
+	return s.db.Create(m).Error
+}
+
+func (s *storage) InsertPodColdMemoryMetric(m *podColdMemoryMetric) error {
+	return s.db.Create(m).Error
+}
+
+func (s *storage) InsertPodRDMAStatMetric(m *podRDMAStatMetric) error {
+	return s.db.Create(m).Error
+}
+
 func (s *storage) GetNodeResourceMetric(start, end *time.Time) ([]nodeResourceMetric, error) {
 	var nodeMetrics []nodeResourceMetric
 	err := s.db.Where("timestamp BETWEEN ? AND ? order by timestamp", start, end).Find(&nodeMetrics).Error
@@ -134,6 +219,18 @@ func (s *storage) GetBECPUResourceMetric(start, end *time.Time) ([]beCPUResource
 	return metrics, err
 }
 
+func (s *storage) GetSystemResourceMetric(start, end *time.Time) ([]systemResourceMetric, error) {
+	var metrics []systemResourceMetric
+	err := s.db.Where("timestamp BETWEEN ? AND ?", start, end).Find(&metrics).Error
+	return metrics, err
+}
+
+func (s *storage) GetNUMAResourceMetric(start, end *time.Time) ([]numaResourceMetric, error) {
+	var metrics []numaResourceMetric
+	err := s.db.Where("timestamp BETWEEN ? AND ?", start, end).Find(&metrics).Error
+	return metrics, err
+}
+
 func (s *storage) GetRawRecord(recordName string) (*rawRecord, error) {
 	record := &rawRecord{}
 	err := s.db.Where("record_type = ?", recordName).First(&record).Error
@@ -170,6 +267,24 @@ func (s *storage) GetPodPSIMetric(uid *string, start, end *time.Time) ([]podPSIM
 	return metrics, err
 }
 
+func (s *storage) GetPodSchedLatencyMetric(uid *string, start, end *time.Time) ([]podSchedLatencyMetric, error) {
+	var metrics []podSchedLatencyMetric
+	err := s.db.Where("pod_uid = ? AND timestamp BETWEEN ? AND ?", uid, start, end).Find(&metrics).Error
+	return metrics, err
+}
+
+func (s *storage) GetPodColdMemoryMetric(uid *string, start, end *time.Time) ([]podColdMemoryMetric, error) {
+	var metrics []podColdMemoryMetric
+	err := s.db.Where("pod_uid = ? AND timestamp BETWEEN ? AND ?", uid, start, end).Find(&metrics).Error
+	return metrics, err
+}
+
+func (s *storage) GetPodRDMAStatMetric(uid *string, start, end *time.Time) ([]podRDMAStatMetric, error) {
+	var metrics []podRDMAStatMetric
+	err := s.db.Where("pod_uid = ? AND timestamp BETWEEN ? AND ?", uid, start, end).Find(&metrics).Error
+	return metrics, err
+}
+
 func (s *storage) GetContainerCPIMetricByPodUid(podUid *string, start, end *time.Time) ([]containerCPIMetric, error) {
 	var metrics []containerCPIMetric
 	err := s.db.Where("pod_uid = ? AND timestamp BETWEEN ? AND ?", podUid, start, end).Find(&metrics).Error
@@ -192,6 +307,14 @@ func (s *storage) DeleteBECPUResourceMetric(start, end *time.Time) error {
 	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&beCPUResourceMetric{}).Error
 }
 
+func (s *storage) DeleteSystemResourceMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&systemResourceMetric{}).Error
+}
+
+func (s *storage) DeleteNUMAResourceMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&numaResourceMetric{}).Error
+}
+
 func (s *storage) DeletePodThrottledMetric(start, end *time.Time) error {
 	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&podThrottledMetric{}).Error
 }
@@ -212,6 +335,18 @@ func (s *storage) DeletePodPSIMetric(start, end *time.Time) error {
 	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&podPSIMetric{}).Error
 }
 
+func (s *storage) DeletePodSchedLatencyMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&podSchedLatencyMetric{}).Error
+}
+
+func (s *storage) DeletePodColdMemoryMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&podColdMemoryMetric{}).Error
+}
+
+func (s *storage) DeletePodRDMAStatMetric(start, end *time.Time) error {
+	return s.db.Where("timestamp BETWEEN ? AND ?", start, end).Delete(&podRDMAStatMetric{}).Error
+}
+
 func (s *storage) CountNodeResourceMetric() (int64, error) {
 	count := int64(0)
 	err := s.db.Model(&nodeResourceMetric{}).Count(&count).Error
@@ -236,6 +371,18 @@ func (s *storage) CountBECPUResourceMetric() (int64, error) {
 	return count, err
 }
 
+func (s *storage) CountSystemResourceMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&systemResourceMetric{}).Count(&count).Error
+	return count, err
+}
+
+func (s *storage) CountNUMAResourceMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&numaResourceMetric{}).Count(&count).Error
+	return count, err
+}
+
 func (s *storage) CountPodThrottledMetric() (int64, error) {
 	count := int64(0)
 	err := s.db.Model(&podThrottledMetric{}).Count(&count).Error
@@ -265,3 +412,21 @@ func (s *storage) CountPodPSIMetric() (int64, error) {
 	err := s.db.Model(&podPSIMetric{}).Count(&count).Error
 	return count, err
 }
+
+func (s *storage) CountPodSchedLatencyMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&podSchedLatencyMetric{}).Count(&count).Error
+	return count, err
+}
+
+func (s *storage) CountPodColdMemoryMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&podColdMemoryMetric{}).Count(&count).Error
+	return count, err
+}
+
+func (s *storage) CountPodRDMAStatMetric() (int64, error) {
+	count := int64(0)
+	err := s.db.Model(&podRDMAStatMetric{}).Count(&count).Error
+	return count, err
+}
@@ -34,6 +34,7 @@ type gpuResourceMetric struct {
 	SMUtil      float64 // current utilization rate for the device
 	MemoryUsed  float64 // used memory on the device, in bytes
 	MemoryTotal float64 // total memory on the device, in bytes
+	Temperature float64 // current core temperature of the device, in degrees C
 	Timestamp   time.Time
 }
 
@@ -61,11 +62,42 @@ func (array GPUMetricsArray) Value() (driver.Value, error) {
 	return json.Marshal(array)
 }
 
+// HugePagesBytes is the hugepage bytes keyed by the hugepage resource name, e.g. hugepages-2Mi.
+type HugePagesBytes map[string]float64
+
+// Implement gorm customize data type.
+// Read data from database.
+func (m *HugePagesBytes) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New(fmt.Sprint("Failed to unmarshal JSONB value:", value))
+	}
+	return json.Unmarshal(bytes, m)
+}
+
+// Implement gorm customize data type.
+// Write data to database.
+func (m HugePagesBytes) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
 type nodeResourceMetric struct {
 	ID              uint64 `gorm:"primarykey"`
 	CPUUsedCores    float64
 	MemoryUsedBytes float64
 	GPUs            GPUMetricsArray `gorm:"type:text"`
+	NetRxBytes      float64         // received bytes per second
+	NetTxBytes      float64         // transmitted bytes per second
+	DiskReadBytes   float64         // read bytes per second
+	DiskWriteBytes  float64         // written bytes per second
+	DiskReadIOPS    float64
+	DiskWriteIOPS   float64
 	Timestamp       time.Time
 }
 
@@ -75,6 +107,13 @@ type podResourceMetric struct {
 	CPUUsedCores    float64
 	MemoryUsedBytes float64
 	GPUs            GPUMetricsArray `gorm:"type:text"`
+	NetRxBytes      float64         // received bytes per second
+	NetTxBytes      float64         // transmitted bytes per second
+	DiskReadBytes   float64         // read bytes per second
+	DiskWriteBytes  float64         // written bytes per second
+	DiskReadIOPS    float64
+	DiskWriteIOPS   float64
+	HugePagesUsed   HugePagesBytes `gorm:"type:text"`
 	Timestamp       time.Time
 }
 
@@ -84,6 +123,10 @@ type containerResourceMetric struct {
 	CPUUsedCores    float64
 	MemoryUsedBytes float64
 	GPUs            GPUMetricsArray `gorm:"type:text"`
+	DiskReadBytes   float64         // read bytes per second
+	DiskWriteBytes  float64         // written bytes per second
+	DiskReadIOPS    float64
+	DiskWriteIOPS   float64
 	Timestamp       time.Time
 }
 
@@ -109,6 +152,24 @@ type beCPUResourceMetric struct {
 	Timestamp       time.Time
 }
 
+type systemResourceMetric struct {
+	ID              uint64 `gorm:"primarykey"`
+	CPUUsedCores    float64
+	MemoryUsedBytes float64
+	Timestamp       time.Time
+}
+
+type numaResourceMetric struct {
+	ID              uint64 `gorm:"primarykey"`
+	NUMANodeID      int32
+	CPUUsedCores    float64
+	MemoryUsedBytes float64
+	MemoryFreeBytes float64
+	HugePagesTotal  HugePagesBytes `gorm:"type:text"`
+	HugePagesFree   HugePagesBytes `gorm:"type:text"`
+	Timestamp       time.Time
+}
+
 type containerCPIMetric struct {
 	ID           uint64 `gorm:"primarykey"`
 	PodUID       string `gorm:"index:idx_container_cpi_poduid"`
@@ -145,6 +206,32 @@ type podPSIMetric struct {
 	Timestamp        time.Time
 }
 
+type podSchedLatencyMetric struct {
+	ID                     uint64 `gorm:"primarykey"`
+	PodUID                 string `gorm:"index:idx_pod_sched_latency_uid"`
+	AvgLatencyMicroseconds float64
+	DelayRatio             float64
+	Timestamp              time.Time
+}
+
+type podColdMemoryMetric struct {
+	ID            uint64 `gorm:"primarykey"`
+	PodUID        string `gorm:"index:idx_pod_cold_memory_uid"`
+	ColdAnonBytes float64
+	Timestamp     time.Time
+}
+
+type podRDMAStatMetric struct {
+	ID                  uint64 `gorm:"primarykey"`
+	PodUID              string `gorm:"index:idx_pod_rdma_stat_uid"`
+	RxBytesPerSecond    float64
+	TxBytesPerSecond    float64
+	CNPSentPerSecond    float64
+	CNPHandledPerSecond float64
+	ECNMarkedPerSecond  float64
+	Timestamp           time.Time
+}
+
 type rawRecord struct {
 	RecordType string `gorm:"primarykey"`
 	RecordStr  string
@@ -17,9 +17,13 @@ limitations under the License.
 package metriccache
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func EqualPodResourceMetric(a, b *podResourceMetric) bool {
@@ -978,3 +982,37 @@ func Test_storage_PodPSIMetric_CRUD(t *testing.T) {
 		})
 	}
 }
+
+func Test_NewFileStorage(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "metriccache", "metrics.db")
+	now := time.Now()
+	start, end := now.Add(-time.Second), now.Add(time.Second)
+
+	s, err := NewFileStorage(dbPath)
+	assert.NoError(t, err)
+	assert.NoError(t, s.InsertNodeResourceMetric(&nodeResourceMetric{CPUUsedCores: 1, MemoryUsedBytes: 2, Timestamp: now}))
+	assert.NoError(t, s.Close())
+
+	// the metrics are kept after reopened
+	s, err = NewFileStorage(dbPath)
+	assert.NoError(t, err)
+	got, err := s.GetNodeResourceMetric(&start, &end)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	used, free, err := s.Size()
+	assert.NoError(t, err)
+	assert.Greater(t, used, int64(0))
+	assert.Equal(t, int64(0), free)
+	assert.NoError(t, s.Close())
+
+	// the corrupted database is rebuilt
+	assert.NoError(t, os.WriteFile(dbPath, []byte("not a sqlite database"), 0644))
+	s, err = NewFileStorage(dbPath)
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.NoError(t, s.CheckIntegrity())
+	got, err = s.GetNodeResourceMetric(&start, &end)
+	assert.NoError(t, err)
+	assert.Len(t, got, 0)
+	assert.NoError(t, s.InsertNodeResourceMetric(&nodeResourceMetric{CPUUsedCores: 1, MemoryUsedBytes: 2, Timestamp: now}))
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	ContainerCPUSetRepair = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "container_cpuset_repair",
+		Help:      "Number of container cpuset cgroups repaired by koordlet since they drift from the allocated cpuset",
+	}, []string{NodeKey, StatusKey})
+
+	ContainerCPUSetKubeletConflict = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "container_cpuset_kubelet_conflict",
+		Help:      "Number of container cpusets allocated by koordinator found overlapping with the CPUs pinned by the kubelet static CPU manager",
+	}, []string{NodeKey})
+
+	CPUSetRepairCollector = []prometheus.Collector{
+		ContainerCPUSetRepair,
+		ContainerCPUSetKubeletConflict,
+	}
+)
+
+func RecordContainerCPUSetRepair(err error) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[StatusKey] = StatusSucceed
+	if err != nil {
+		labels[StatusKey] = StatusFailed
+	}
+	ContainerCPUSetRepair.With(labels).Inc()
+}
+
+func RecordContainerCPUSetKubeletConflict() {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	ContainerCPUSetKubeletConflict.With(labels).Inc()
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	PodCPIDegradationRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_cpi_degradation_ratio",
+		Help:      "Ratio of the recent CPI of the LS pod to its baseline, which is high when the pod suffers the interference",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace})
+
+	PodCPIInterference = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_cpi_interference",
+		Help:      "Number of the LS pods found degraded by the noisy neighbors according to the CPI",
+	}, []string{NodeKey})
+
+	InterferenceCollectors = []prometheus.Collector{
+		PodCPIDegradationRatio,
+		PodCPIInterference,
+	}
+)
+
+func ResetPodCPIDegradationRatio() {
+	PodCPIDegradationRatio.Reset()
+}
+
+func RecordPodCPIDegradationRatio(pod *corev1.Pod, ratio float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[PodUID] = string(pod.UID)
+	labels[PodName] = pod.Name
+	labels[PodNamespace] = pod.Namespace
+	PodCPIDegradationRatio.With(labels).Set(ratio)
+}
+
+func RecordPodCPIInterference() {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	PodCPIInterference.With(labels).Inc()
+}
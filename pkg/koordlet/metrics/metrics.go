@@ -31,6 +31,11 @@ func init() {
 	prometheus.MustRegister(PSICollectors...)
 	prometheus.MustRegister(CPUSuppressCollector...)
 	prometheus.MustRegister(CPUBurstCollector...)
+	prometheus.MustRegister(CPUSetRepairCollector...)
+	prometheus.MustRegister(NUMAStatCollectors...)
+	prometheus.MustRegister(RDMAStatCollectors...)
+	prometheus.MustRegister(InterferenceCollectors...)
+	prometheus.MustRegister(ResctrlCollectors...)
 }
 
 const (
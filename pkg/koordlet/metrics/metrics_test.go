@@ -138,12 +138,33 @@ func TestCommonCollectors(t *testing.T) {
 		RecordContainerScaledCFSBurstUS(testingPod.Namespace, testingPod.Name, testingContainer.ContainerID, testingContainer.Name, 1000000)
 		RecordContainerScaledCFSQuotaUS(testingPod.Namespace, testingPod.Name, testingContainer.ContainerID, testingContainer.Name, 1000000)
 		RecordPodEviction("evictByCPU")
+		RecordContainerCPUSetRepair(nil)
+		RecordContainerCPUSetRepair(testingErr)
+		RecordContainerCPUSetKubeletConflict()
 		ResetContainerCPI()
 		RecordContainerCPI(testingContainer, testingPod, 1, 1)
 		ResetContainerPSI()
 		RecordContainerPSI(testingContainer, testingPod, testingPSI)
 		ResetPodPSI()
 		RecordPodPSI(testingPod, testingPSI)
+		RecordNodeNUMAStat(0, NUMAStatFieldHit, 1000)
+		RecordNodeNUMAStat(0, NUMAStatFieldMiss, 10)
+		ResetPodMemoryNUMAStat()
+		RecordPodMemoryNUMAPages(testingPod, 0, 100)
+		RecordPodMemoryNUMALocalityRatio(testingPod, 0.9)
+		ResetRDMAStat()
+		RecordNodeRDMAStat("mlx5_0", "1", RDMAStatRxBytes, 1024)
+		RecordNodeRDMAStatRate("mlx5_0", "1", RDMAStatCNPHandled, 10)
+		RecordPodRDMAStat(testingPod, "mlx5_2", "1", RDMAStatTxBytes, 2048)
+		RecordPodRDMAStatRate(testingPod, "mlx5_2", "1", RDMAStatECNMarked, 5)
+		ResetPodCPIDegradationRatio()
+		RecordPodCPIDegradationRatio(testingPod, 1.5)
+		RecordPodCPIInterference()
+		ResetResctrlMonitor()
+		RecordResctrlLLCOccupancy("BE", 1048576)
+		RecordResctrlMemoryBandwidth("BE", MemoryBandwidthTotal, 1000)
+		RecordPodResctrlLLCOccupancy(testingPod, "BE", 1048576)
+		RecordPodResctrlMemoryBandwidth(testingPod, "BE", MemoryBandwidthLocal, 500)
 	})
 }
 
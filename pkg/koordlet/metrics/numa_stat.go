@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	NUMANodeKey      = "numa_node"
+	NUMAStatFieldKey = "field"
+
+	NUMAStatFieldHit   = "numa_hit"
+	NUMAStatFieldMiss  = "numa_miss"
+	NUMAStatFieldLocal = "local_node"
+	NUMAStatFieldOther = "other_node"
+)
+
+var (
+	NodeNUMAStat = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_numa_stat",
+		Help:      "Pages allocated on the NUMA node by the numastat field, which is a counter accumulated by the kernel",
+	}, []string{NodeKey, NUMANodeKey, NUMAStatFieldKey})
+
+	PodMemoryNUMAPages = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_memory_numa_pages",
+		Help:      "Memory pages of the pod allocated on each NUMA node collected from memory.numa_stat",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace, NUMANodeKey})
+
+	PodMemoryNUMALocalityRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_memory_numa_locality_ratio",
+		Help:      "Ratio of the pod memory pages allocated on the NUMA nodes local to the pod cpuset",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace})
+
+	NUMAStatCollectors = []prometheus.Collector{
+		NodeNUMAStat,
+		PodMemoryNUMAPages,
+		PodMemoryNUMALocalityRatio,
+	}
+)
+
+func RecordNodeNUMAStat(numaNodeID int, field string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[NUMANodeKey] = strconv.Itoa(numaNodeID)
+	labels[NUMAStatFieldKey] = field
+	NodeNUMAStat.With(labels).Set(value)
+}
+
+func RecordPodMemoryNUMAPages(pod *corev1.Pod, numaNodeID int, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[PodUID] = string(pod.UID)
+	labels[PodName] = pod.Name
+	labels[PodNamespace] = pod.Namespace
+	labels[NUMANodeKey] = strconv.Itoa(numaNodeID)
+	PodMemoryNUMAPages.With(labels).Set(value)
+}
+
+func RecordPodMemoryNUMALocalityRatio(pod *corev1.Pod, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[PodUID] = string(pod.UID)
+	labels[PodName] = pod.Name
+	labels[PodNamespace] = pod.Namespace
+	PodMemoryNUMALocalityRatio.With(labels).Set(value)
+}
+
+func ResetPodMemoryNUMAStat() {
+	PodMemoryNUMAPages.Reset()
+	PodMemoryNUMALocalityRatio.Reset()
+}
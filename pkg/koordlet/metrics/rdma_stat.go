@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	RDMADeviceKey      = "rdma_device"
+	RDMAPortKey        = "port"
+	RDMAStatFieldKey   = "field"
+	RDMAStatRxBytes    = "rx_bytes"
+	RDMAStatTxBytes    = "tx_bytes"
+	RDMAStatCNPSent    = "cnp_sent"
+	RDMAStatCNPHandled = "cnp_handled"
+	RDMAStatECNMarked  = "ecn_marked_packets"
+)
+
+var (
+	NodeRDMAStat = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_rdma_stat",
+		Help:      "Traffic and congestion counters of the RDMA device port by the field, which is a counter accumulated by the device",
+	}, []string{NodeKey, RDMADeviceKey, RDMAPortKey, RDMAStatFieldKey})
+
+	NodeRDMAStatRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "node_rdma_stat_rate",
+		Help:      "Per-second rate of the RDMA device port counters by the field in the last collect interval",
+	}, []string{NodeKey, RDMADeviceKey, RDMAPortKey, RDMAStatFieldKey})
+
+	PodRDMAStat = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_rdma_stat",
+		Help:      "Traffic and congestion counters of the RDMA virtual functions allocated to the pod by the field",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace, RDMADeviceKey, RDMAPortKey, RDMAStatFieldKey})
+
+	PodRDMAStatRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_rdma_stat_rate",
+		Help:      "Per-second rate of the counters of the RDMA virtual functions allocated to the pod by the field in the last collect interval",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace, RDMADeviceKey, RDMAPortKey, RDMAStatFieldKey})
+
+	RDMAStatCollectors = []prometheus.Collector{
+		NodeRDMAStat,
+		NodeRDMAStatRate,
+		PodRDMAStat,
+		PodRDMAStatRate,
+	}
+)
+
+func RecordNodeRDMAStat(device, port, field string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[RDMADeviceKey] = device
+	labels[RDMAPortKey] = port
+	labels[RDMAStatFieldKey] = field
+	NodeRDMAStat.With(labels).Set(value)
+}
+
+func RecordNodeRDMAStatRate(device, port, field string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[RDMADeviceKey] = device
+	labels[RDMAPortKey] = port
+	labels[RDMAStatFieldKey] = field
+	NodeRDMAStatRate.With(labels).Set(value)
+}
+
+func RecordPodRDMAStat(pod *corev1.Pod, device, port, field string, value float64) {
+	labels := genPodRDMALabels(pod, device, port, field)
+	if labels == nil {
+		return
+	}
+	PodRDMAStat.With(labels).Set(value)
+}
+
+func RecordPodRDMAStatRate(pod *corev1.Pod, device, port, field string, value float64) {
+	labels := genPodRDMALabels(pod, device, port, field)
+	if labels == nil {
+		return
+	}
+	PodRDMAStatRate.With(labels).Set(value)
+}
+
+func ResetRDMAStat() {
+	NodeRDMAStat.Reset()
+	NodeRDMAStatRate.Reset()
+	PodRDMAStat.Reset()
+	PodRDMAStatRate.Reset()
+}
+
+func genPodRDMALabels(pod *corev1.Pod, device, port, field string) prometheus.Labels {
+	labels := genNodeLabels()
+	if labels == nil {
+		return nil
+	}
+	labels[PodUID] = string(pod.UID)
+	labels[PodName] = pod.Name
+	labels[PodNamespace] = pod.Namespace
+	labels[RDMADeviceKey] = device
+	labels[RDMAPortKey] = port
+	labels[RDMAStatFieldKey] = field
+	return labels
+}
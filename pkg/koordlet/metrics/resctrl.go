@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	ResctrlGroupKey = "resctrl_group"
+	// MemoryBandwidthTypeKey is the type of the memory bandwidth, which is total or local.
+	MemoryBandwidthTypeKey = "type"
+
+	MemoryBandwidthTotal = "total"
+	MemoryBandwidthLocal = "local"
+)
+
+var (
+	ResctrlLLCOccupancy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "resctrl_llc_occupancy_bytes",
+		Help:      "Bytes of the last level cache occupied by the tasks of the resctrl group, which is a QoS class or the root",
+	}, []string{NodeKey, ResctrlGroupKey})
+
+	ResctrlMemoryBandwidth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "resctrl_memory_bandwidth_bytes_per_second",
+		Help:      "Memory bandwidth of the tasks of the resctrl group in the last reconcile interval by the type",
+	}, []string{NodeKey, ResctrlGroupKey, MemoryBandwidthTypeKey})
+
+	PodResctrlLLCOccupancy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_resctrl_llc_occupancy_bytes",
+		Help:      "Bytes of the last level cache occupied by the pod measured by its resctrl monitoring group",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace, ResctrlGroupKey})
+
+	PodResctrlMemoryBandwidth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: KoordletSubsystem,
+		Name:      "pod_resctrl_memory_bandwidth_bytes_per_second",
+		Help:      "Memory bandwidth of the pod measured by its resctrl monitoring group in the last reconcile interval by the type",
+	}, []string{NodeKey, PodUID, PodName, PodNamespace, ResctrlGroupKey, MemoryBandwidthTypeKey})
+
+	ResctrlCollectors = []prometheus.Collector{
+		ResctrlLLCOccupancy,
+		ResctrlMemoryBandwidth,
+		PodResctrlLLCOccupancy,
+		PodResctrlMemoryBandwidth,
+	}
+)
+
+func RecordResctrlLLCOccupancy(group string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[ResctrlGroupKey] = group
+	ResctrlLLCOccupancy.With(labels).Set(value)
+}
+
+func RecordResctrlMemoryBandwidth(group, bandwidthType string, value float64) {
+	labels := genNodeLabels()
+	if labels == nil {
+		return
+	}
+	labels[ResctrlGroupKey] = group
+	labels[MemoryBandwidthTypeKey] = bandwidthType
+	ResctrlMemoryBandwidth.With(labels).Set(value)
+}
+
+func RecordPodResctrlLLCOccupancy(pod *corev1.Pod, group string, value float64) {
+	labels := genPodResctrlLabels(pod, group)
+	if labels == nil {
+		return
+	}
+	PodResctrlLLCOccupancy.With(labels).Set(value)
+}
+
+func RecordPodResctrlMemoryBandwidth(pod *corev1.Pod, group, bandwidthType string, value float64) {
+	labels := genPodResctrlLabels(pod, group)
+	if labels == nil {
+		return
+	}
+	labels[MemoryBandwidthTypeKey] = bandwidthType
+	PodResctrlMemoryBandwidth.With(labels).Set(value)
+}
+
+func ResetResctrlMonitor() {
+	ResctrlLLCOccupancy.Reset()
+	ResctrlMemoryBandwidth.Reset()
+	PodResctrlLLCOccupancy.Reset()
+	PodResctrlMemoryBandwidth.Reset()
+}
+
+func genPodResctrlLabels(pod *corev1.Pod, group string) prometheus.Labels {
+	labels := genNodeLabels()
+	if labels == nil {
+		return nil
+	}
+	labels[PodUID] = string(pod.UID)
+	labels[PodName] = pod.Name
+	labels[PodNamespace] = pod.Namespace
+	labels[ResctrlGroupKey] = group
+	return labels
+}
@@ -24,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
@@ -32,6 +31,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -39,6 +39,8 @@ const (
 	CollectorName = "BEResourceCollector"
 )
 
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
 type beResourceCollector struct {
 	collectInterval time.Duration
 	started         *atomic.Bool
@@ -70,9 +72,9 @@ func (b *beResourceCollector) Setup(s *framework.Context) {
 func (b *beResourceCollector) Run(stopCh <-chan struct{}) {
 	if !cache.WaitForCacheSync(stopCh, b.statesInformer.HasSynced) {
 		// Koordlet exit because of statesInformer sync failed.
-		klog.Fatalf("timed out waiting for states informer caches to sync")
+		logger.Fatalf("timed out waiting for states informer caches to sync")
 	}
-	go wait.Until(b.collectBECPUResourceMetric, b.collectInterval, stopCh)
+	wait.Until(b.collectBECPUResourceMetric, b.collectInterval, stopCh)
 }
 
 func (b *beResourceCollector) Started() bool {
@@ -80,11 +82,11 @@ func (b *beResourceCollector) Started() bool {
 }
 
 func (b *beResourceCollector) collectBECPUResourceMetric() {
-	klog.V(6).Info("collectBECPUResourceMetric start")
+	logger.V(6).Info("collectBECPUResourceMetric start")
 
 	realMilliLimit, err := b.getBECPURealMilliLimit()
 	if err != nil {
-		klog.Errorf("getBECPURealMilliLimit failed, error: %v", err)
+		logger.Errorf("getBECPURealMilliLimit failed, error: %v", err)
 		return
 	}
 
@@ -92,12 +94,12 @@ func (b *beResourceCollector) collectBECPUResourceMetric() {
 
 	beCPUUsageCores, err := b.getBECPUUsageCores()
 	if err != nil {
-		klog.Errorf("getBECPUUsageCores failed, error: %v", err)
+		logger.Errorf("getBECPUUsageCores failed, error: %v", err)
 		return
 	}
 
 	if beCPUUsageCores == nil {
-		klog.Info("beCPUUsageCores is nil")
+		logger.Info("beCPUUsageCores is nil")
 		return
 	}
 
@@ -110,11 +112,11 @@ func (b *beResourceCollector) collectBECPUResourceMetric() {
 	collectTime := time.Now()
 	err = b.metricDB.InsertBECPUResourceMetric(collectTime, &beCPUMetric)
 	if err != nil {
-		klog.Errorf("InsertBECPUResourceMetric failed, error: %v", err)
+		logger.Errorf("InsertBECPUResourceMetric failed, error: %v", err)
 		return
 	}
 	b.started.Store(true)
-	klog.V(6).Info("collectBECPUResourceMetric finished")
+	logger.V(6).Info("collectBECPUResourceMetric finished")
 }
 
 func (b *beResourceCollector) getBECPURealMilliLimit() (int, error) {
@@ -166,13 +168,13 @@ func (b *beResourceCollector) getBECPURequestSum() resource.Quantity {
 }
 
 func (b *beResourceCollector) getBECPUUsageCores() (*resource.Quantity, error) {
-	klog.V(6).Info("getBECPUUsageCores start")
+	logger.V(6).Info("getBECPUUsageCores start")
 
 	collectTime := time.Now()
 	BECgroupParentDir := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
 	currentCPUUsage, err := b.cgroupReader.ReadCPUAcctUsage(BECgroupParentDir)
 	if err != nil {
-		klog.Warningf("failed to collect be cgroup usage, error: %v", err)
+		logger.Warningf("failed to collect be cgroup usage, error: %v", err)
 		return nil, err
 	}
 
@@ -183,7 +185,7 @@ func (b *beResourceCollector) getBECPUUsageCores() (*resource.Quantity, error) {
 	}
 
 	if lastCPUStat == nil {
-		klog.V(6).Infof("ignore the first cpu stat collection")
+		logger.V(6).Infof("ignore the first cpu stat collection")
 		return nil, nil
 	}
 
@@ -191,6 +193,6 @@ func (b *beResourceCollector) getBECPUUsageCores() (*resource.Quantity, error) {
 	cpuUsageValue := float64(currentCPUUsage-lastCPUStat.CPUUsage) / float64(collectTime.Sub(lastCPUStat.Timestamp))
 	// 1.0 CPU = 1000 Milli-CPU
 	cpuUsageCores := resource.NewMilliQuantity(int64(cpuUsageValue*1000), resource.DecimalSI)
-	klog.V(6).Infof("collectBECPUUsageCores finished %.2f", cpuUsageValue)
+	logger.V(6).Infof("collectBECPUUsageCores finished %.2f", cpuUsageValue)
 	return cpuUsageCores, nil
 }
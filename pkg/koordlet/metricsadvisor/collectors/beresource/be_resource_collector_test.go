@@ -320,8 +320,8 @@ func Test_beResourceColelctor_Run(t *testing.T) {
 	assert.True(t, collector.Enabled())
 	assert.True(t, collector.Started())
 	assert.NotPanics(t, func() {
-		stopCh := make(chan struct{}, 1)
+		stopCh := make(chan struct{})
+		close(stopCh)
 		collector.Run(stopCh)
-		stopCh <- struct{}{}
 	})
 }
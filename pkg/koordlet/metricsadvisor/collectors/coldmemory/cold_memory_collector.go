@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coldmemory
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	CollectorName = "ColdMemoryCollector"
+)
+
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
+// coldMemoryCollector collects the cold memory of the pods from the idle page statistics scanned by kidled. Only the
+// cold anonymous pages are collected, since the memory usage reported excludes the page cache, and they can only be
+// reclaimed when the swap is enabled on the node.
+type coldMemoryCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	statesInformer  statesinformer.StatesInformer
+	metricCache     metriccache.MetricCache
+}
+
+func New(opt *framework.Options) framework.Collector {
+	return &coldMemoryCollector{
+		collectInterval: time.Duration(opt.Config.ColdMemoryCollectorIntervalSeconds) * time.Second,
+		started:         atomic.NewBool(false),
+		statesInformer:  opt.StatesInformer,
+		metricCache:     opt.MetricCache,
+	}
+}
+
+func (c *coldMemoryCollector) Enabled() bool {
+	if !features.DefaultKoordletFeatureGate.Enabled(features.ColdMemoryCollector) {
+		return false
+	}
+	if !system.IsKidledEnabled() {
+		logger.Warningf("kidled is not enabled by the kernel, skip collecting the cold memory")
+		return false
+	}
+	return true
+}
+
+func (c *coldMemoryCollector) Setup(ctx *framework.Context) {}
+
+func (c *coldMemoryCollector) Run(stopCh <-chan struct{}) {
+	if !cache.WaitForCacheSync(stopCh, c.statesInformer.HasSynced) {
+		// Koordlet exit because of statesInformer sync failed.
+		logger.Fatalf("timed out waiting for states informer caches to sync")
+	}
+	wait.Until(c.collectPodColdMemory, c.collectInterval, stopCh)
+}
+
+func (c *coldMemoryCollector) Started() bool {
+	return c.started.Load()
+}
+
+func (c *coldMemoryCollector) collectPodColdMemory() {
+	logger.V(6).Info("start collectPodColdMemory")
+	swapTotalKB, err := koordletutil.GetMemInfoSwapTotalKB()
+	if err != nil {
+		logger.Warningf("failed to get the swap of the node, err: %v", err)
+		return
+	}
+	// the cold anonymous pages cannot be reclaimed without the swap
+	if swapTotalKB <= 0 {
+		logger.V(5).Info("swap is disabled on the node, skip collecting the cold memory")
+		c.started.Store(true)
+		return
+	}
+
+	collectTime := time.Now()
+	podMetas := c.statesInformer.GetAllPods()
+	for _, meta := range podMetas {
+		pod := meta.Pod
+		podCgroupDir := koordletutil.GetPodCgroupDirWithKube(meta.CgroupDir)
+		stats, err := system.GetMemoryIdlePageStats(podCgroupDir)
+		if err != nil {
+			logger.V(4).Infof("failed to get idle page stats of pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		podMetric := &metriccache.PodInterferenceMetric{
+			MetricName:  metriccache.MetricNamePodColdMemory,
+			PodUID:      string(pod.UID),
+			MetricValue: &metriccache.ColdMemoryMetric{ColdAnonBytes: float64(stats.IdleAnonBytes())},
+		}
+		if err := c.metricCache.InsertPodInterferenceMetrics(collectTime, podMetric); err != nil {
+			logger.Errorf("insert pod %s/%s cold memory metrics failed, err %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	c.started.Store(true)
+	logger.V(5).Infof("collectPodColdMemory finished at %s, pod num %d", collectTime, len(podMetas))
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coldmemory
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const testIdlePageStats = `# version: 1.0
+# scan_period_in_seconds: 120
+# buckets: 1,2,5
+#   [1,2)  [2,5)  [5,+inf)
+  csei      4096      0     8192
+  dsei         0   4096        0
+  cfei    102400      0        0
+  csui     65536      0        0
+  csea         0      0     4096
+  slab         0      0        0
+`
+
+func Test_coldMemoryCollector_collectPodColdMemory(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldProcRootDir := system.Conf.ProcRootDir
+	system.Conf.ProcRootDir = filepath.Join(helper.TempDir, "proc")
+	defer func() {
+		system.Conf.ProcRootDir = oldProcRootDir
+	}()
+	helper.SetCgroupsV2(false)
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			UID:       "xxxxxxxx",
+		},
+	}
+	testPodWithoutStats := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod-1",
+			Namespace: "default",
+			UID:       "yyyyyyyy",
+		},
+	}
+	podCgroupDir := koordletutil.GetPodCgroupDirWithKube("/kubepods-podxxxxxxxx.slice")
+	helper.WriteFileContents(system.GetMemoryIdlePageStatsPath(podCgroupDir), testIdlePageStats)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStatesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+	mockMetricCache := mockmetriccache.NewMockMetricCache(ctrl)
+	mockStatesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{
+		{CgroupDir: "/kubepods-podxxxxxxxx.slice", Pod: testPod},
+		{CgroupDir: "/kubepods-podyyyyyyyy.slice", Pod: testPodWithoutStats},
+	}).AnyTimes()
+	var got []*metriccache.ColdMemoryMetric
+	mockMetricCache.EXPECT().InsertPodInterferenceMetrics(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ time.Time, metric *metriccache.PodInterferenceMetric) error {
+			assert.Equal(t, metriccache.MetricNamePodColdMemory, metric.MetricName)
+			assert.Equal(t, "xxxxxxxx", metric.PodUID)
+			got = append(got, metric.MetricValue.(*metriccache.ColdMemoryMetric))
+			return nil
+		}).Times(1)
+
+	collector := New(&framework.Options{
+		Config:         framework.NewDefaultConfig(),
+		StatesInformer: mockStatesInformer,
+		MetricCache:    mockMetricCache,
+	})
+	c := collector.(*coldMemoryCollector)
+	assert.False(t, c.Enabled())
+
+	// the cold memory is not collected without the swap
+	helper.WriteFileContents(system.GetProcFilePath(system.ProcMemInfoName), "MemTotal:       263432804 kB\n"+
+		"SwapTotal:             0 kB\n")
+	c.collectPodColdMemory()
+	assert.True(t, c.Started())
+	assert.Len(t, got, 0)
+
+	helper.WriteFileContents(system.GetProcFilePath(system.ProcMemInfoName), "MemTotal:       263432804 kB\n"+
+		"SwapTotal:       8388604 kB\n")
+	c.collectPodColdMemory()
+	assert.Equal(t, []*metriccache.ColdMemoryMetric{{ColdAnonBytes: 4096 + 8192 + 4096 + 4096}}, got)
+}
@@ -21,18 +21,20 @@ import (
 
 	"go.uber.org/atomic"
 	"k8s.io/apimachinery/pkg/util/wait"
-	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 )
 
 const (
 	CollectorName = "NodeInfoCollector"
 )
 
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
 // TODO more ut is needed for this plugin
 type nodeInfoCollector struct {
 	collectInterval time.Duration
@@ -55,7 +57,7 @@ func (n *nodeInfoCollector) Enabled() bool {
 func (n *nodeInfoCollector) Setup(s *framework.Context) {}
 
 func (n *nodeInfoCollector) Run(stopCh <-chan struct{}) {
-	go wait.Until(n.collectNodeCPUInfo, n.collectInterval, stopCh)
+	wait.Until(n.collectNodeCPUInfo, n.collectInterval, stopCh)
 }
 
 func (n *nodeInfoCollector) Started() bool {
@@ -63,11 +65,11 @@ func (n *nodeInfoCollector) Started() bool {
 }
 
 func (n *nodeInfoCollector) collectNodeCPUInfo() {
-	klog.V(6).Info("start nodeInfoCollector")
+	logger.V(6).Info("start nodeInfoCollector")
 
 	localCPUInfo, err := koordletutil.GetLocalCPUInfo()
 	if err != nil {
-		klog.Warningf("failed to collect node cpu info, err: %s", err)
+		logger.Warningf("failed to collect node cpu info, err: %s", err)
 		metrics.RecordCollectNodeCPUInfoStatus(err)
 		return
 	}
@@ -77,12 +79,12 @@ func (n *nodeInfoCollector) collectNodeCPUInfo() {
 		ProcessorInfos: localCPUInfo.ProcessorInfos,
 		TotalInfo:      localCPUInfo.TotalInfo,
 	}
-	klog.V(6).Infof("collect cpu info finished, nodeCPUInfo %v", nodeCPUInfo)
+	logger.V(6).Infof("collect cpu info finished, nodeCPUInfo %v", nodeCPUInfo)
 	if err = n.metricDB.InsertNodeCPUInfo(nodeCPUInfo); err != nil {
-		klog.Errorf("insert node cpu info error: %v", err)
+		logger.Errorf("insert node cpu info error: %v", err)
 	}
 
 	n.started.Store(true)
-	klog.Infof("collectNodeCPUInfo finished, cpu info: processors %v", len(nodeCPUInfo.ProcessorInfos))
+	logger.Infof("collectNodeCPUInfo finished, cpu info: processors %v", len(nodeCPUInfo.ProcessorInfos))
 	metrics.RecordCollectNodeCPUInfoStatus(nil)
 }
@@ -23,12 +23,12 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
@@ -36,6 +36,8 @@ const (
 	CollectorName = "NodeResourceCollector"
 )
 
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
 // TODO more ut is needed for this plugin
 type nodeResourceCollector struct {
 	collectInterval time.Duration
@@ -43,6 +45,10 @@ type nodeResourceCollector struct {
 	metricDB        metriccache.MetricCache
 
 	lastNodeCPUStat *framework.CPUStat
+	lastNodeNetStat *framework.NetStat
+	lastNodeIOStat  *framework.DiskIOStat
+	// lastNUMACPUStats are the cpu stats of the NUMA nodes, indexed by the NUMA node id
+	lastNUMACPUStats map[int]*framework.CPUStat
 
 	deviceCollectors map[string]framework.DeviceCollector
 }
@@ -69,9 +75,9 @@ func (n *nodeResourceCollector) Run(stopCh <-chan struct{}) {
 	}
 	if !cache.WaitForCacheSync(stopCh, devicesSynced) {
 		// Koordlet exit because of statesInformer sync failed.
-		klog.Fatalf("timed out waiting for devices to sync")
+		logger.Fatalf("timed out waiting for devices to sync")
 	}
-	go wait.Until(n.collectNodeResUsed, n.collectInterval, stopCh)
+	wait.Until(n.collectNodeResUsed, n.collectInterval, stopCh)
 }
 
 func (n *nodeResourceCollector) Started() bool {
@@ -79,21 +85,24 @@ func (n *nodeResourceCollector) Started() bool {
 }
 
 func (n *nodeResourceCollector) collectNodeResUsed() {
-	klog.V(6).Info("collectNodeResUsed start")
+	logger.V(6).Info("collectNodeResUsed start")
 	collectTime := time.Now()
 	currentCPUTick, err0 := koordletutil.GetCPUStatUsageTicks()
 	memUsageValue, err1 := koordletutil.GetMemInfoUsageKB()
 	if err0 != nil || err1 != nil {
-		klog.Warningf("failed to collect node usage, CPU err: %s, Memory err: %s", err0, err1)
+		logger.Warningf("failed to collect node usage, CPU err: %s, Memory err: %s", err0, err1)
 		return
 	}
+	networkUsed := n.collectNodeNetworkUsed(collectTime)
+	diskIOUsed := n.collectNodeDiskIOUsed(collectTime)
+	n.collectNUMAResUsed(collectTime)
 	lastCPUStat := n.lastNodeCPUStat
 	n.lastNodeCPUStat = &framework.CPUStat{
 		CPUTick:   currentCPUTick,
 		Timestamp: collectTime,
 	}
 	if lastCPUStat == nil {
-		klog.V(6).Infof("ignore the first cpu stat collection")
+		logger.V(6).Infof("ignore the first cpu stat collection")
 		return
 	}
 	// 1 jiffies could be 10ms
@@ -109,21 +118,109 @@ func (n *nodeResourceCollector) collectNodeResUsed() {
 			// 1.0 kB Memory = 1024 B
 			MemoryWithoutCache: *resource.NewQuantity(memUsageValue*1024, resource.BinarySI),
 		},
+		NetworkUsed: networkUsed,
+		DiskIOUsed:  diskIOUsed,
 	}
 
 	for deviceName, deviceCollector := range n.deviceCollectors {
 		if err := deviceCollector.FillNodeMetric(&nodeMetric); err != nil {
-			klog.Warningf("fill node device usage failed for %v, error: %v", deviceName, err)
+			logger.Warningf("fill node device usage failed for %v, error: %v", deviceName, err)
 		}
 	}
 
 	if err := n.metricDB.InsertNodeResourceMetric(collectTime, &nodeMetric); err != nil {
-		klog.Errorf("insert node resource metric error: %v", err)
+		logger.Errorf("insert node resource metric error: %v", err)
 	}
 
 	// update collect time
 	n.started.Store(true)
 	metrics.RecordNodeUsedCPU(cpuUsageValue) // in cpu cores
 
-	klog.Infof("collectNodeResUsed finished %+v", nodeMetric)
+	logger.Infof("collectNodeResUsed finished %+v", nodeMetric)
+}
+
+// collectNodeNetworkUsed returns the bandwidth used by the physical interfaces of the node since the last collection.
+func (n *nodeResourceCollector) collectNodeNetworkUsed(collectTime time.Time) metriccache.NetworkMetric {
+	netDevStat, err := system.GetHostNetDevStat()
+	if err != nil {
+		logger.Warningf("failed to collect node network usage, err: %s", err)
+		n.lastNodeNetStat = nil
+		return metriccache.NetworkMetric{}
+	}
+	lastNetStat := n.lastNodeNetStat
+	n.lastNodeNetStat = &framework.NetStat{
+		RxBytes:   netDevStat.RxBytes,
+		TxBytes:   netDevStat.TxBytes,
+		Timestamp: collectTime,
+	}
+	if lastNetStat == nil {
+		return metriccache.NetworkMetric{}
+	}
+	return framework.CalculateNetworkMetric(lastNetStat, n.lastNodeNetStat)
+}
+
+// collectNodeDiskIOUsed returns the IO of the physical disks of the node since the last collection.
+func (n *nodeResourceCollector) collectNodeDiskIOUsed(collectTime time.Time) metriccache.DiskIOMetric {
+	ioStat, err := system.GetHostDiskIOStat()
+	if err != nil {
+		logger.Warningf("failed to collect node disk io usage, err: %s", err)
+		n.lastNodeIOStat = nil
+		return metriccache.DiskIOMetric{}
+	}
+	lastIOStat := n.lastNodeIOStat
+	n.lastNodeIOStat = &framework.DiskIOStat{
+		DiskIOStat: *ioStat,
+		Timestamp:  collectTime,
+	}
+	if lastIOStat == nil {
+		return metriccache.DiskIOMetric{}
+	}
+	return framework.CalculateDiskIOMetric(lastIOStat, n.lastNodeIOStat)
+}
+
+// collectNUMAResUsed collects the cpu and memory usage of each NUMA node since the last collection, so that the
+// NUMA-aware scheduling can tell how loaded each NUMA node is rather than the whole node.
+func (n *nodeResourceCollector) collectNUMAResUsed(collectTime time.Time) {
+	numaCPUTicks, err0 := koordletutil.GetNUMANodeCPUStatUsageTicks()
+	numaMemInfos, err1 := koordletutil.GetNUMANodeMemInfos()
+	if err0 != nil || err1 != nil {
+		logger.Warningf("failed to collect NUMA usage, CPU err: %s, Memory err: %s", err0, err1)
+		n.lastNUMACPUStats = nil
+		return
+	}
+	// the hugepages are optional, which are not configured on most hosts
+	hugePagesTotal, hugePagesFree, err := koordletutil.GetNUMANodeHugePages()
+	if err != nil {
+		logger.V(4).Infof("failed to collect NUMA hugepages, err: %s", err)
+	}
+
+	lastCPUStats := n.lastNUMACPUStats
+	n.lastNUMACPUStats = make(map[int]*framework.CPUStat, len(numaCPUTicks))
+	for numaNodeID, cpuTick := range numaCPUTicks {
+		n.lastNUMACPUStats[numaNodeID] = &framework.CPUStat{
+			CPUTick:   cpuTick,
+			Timestamp: collectTime,
+		}
+		lastCPUStat := lastCPUStats[numaNodeID]
+		memInfo := numaMemInfos[numaNodeID]
+		if lastCPUStat == nil || memInfo == nil || cpuTick < lastCPUStat.CPUTick {
+			continue
+		}
+		cpuUsageValue := float64(cpuTick-lastCPUStat.CPUTick) / system.GetPeriodTicks(lastCPUStat.Timestamp, collectTime)
+		numaMetric := &metriccache.NUMAResourceMetric{
+			NUMANodeID: int32(numaNodeID),
+			CPUUsed: metriccache.CPUMetric{
+				CPUUsed: *resource.NewMilliQuantity(int64(cpuUsageValue*1000), resource.DecimalSI),
+			},
+			MemoryUsed: metriccache.MemoryMetric{
+				MemoryWithoutCache: *resource.NewQuantity(koordletutil.GetNUMAMemUsageKB(memInfo)*1024, resource.BinarySI),
+			},
+			MemoryFree:     *resource.NewQuantity(int64(memInfo.MemFree)*1024, resource.BinarySI),
+			HugePagesTotal: hugePagesTotal[numaNodeID],
+			HugePagesFree:  hugePagesFree[numaNodeID],
+		}
+		if err := n.metricDB.InsertNUMAResourceMetric(collectTime, numaMetric); err != nil {
+			logger.Errorf("insert NUMA %d resource metric error: %v", numaNodeID, err)
+		}
+	}
 }
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numastat
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	CollectorName = "NUMAStatCollector"
+)
+
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
+// numaStatCollector collects the memory NUMA locality of pods from memory.numa_stat, and the numa_hit/numa_miss
+// of NUMA nodes from numastat, since the kernel does not provide numa_hit/numa_miss per cgroup.
+// The memory pages of a pod allocated on the NUMA nodes of its cpuset are regarded as local.
+type numaStatCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	metricCache     metriccache.MetricCache
+	statesInformer  statesinformer.StatesInformer
+	cgroupReader    resourceexecutor.CgroupReader
+}
+
+func New(opt *framework.Options) framework.Collector {
+	return &numaStatCollector{
+		collectInterval: time.Duration(opt.Config.NUMAStatCollectorIntervalSeconds) * time.Second,
+		started:         atomic.NewBool(false),
+		metricCache:     opt.MetricCache,
+		statesInformer:  opt.StatesInformer,
+		cgroupReader:    opt.CgroupReader,
+	}
+}
+
+func (n *numaStatCollector) Enabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.NUMAStatCollector)
+}
+
+func (n *numaStatCollector) Setup(c *framework.Context) {}
+
+func (n *numaStatCollector) Run(stopCh <-chan struct{}) {
+	if !cache.WaitForCacheSync(stopCh, n.statesInformer.HasSynced) {
+		// Koordlet exit because of statesInformer sync failed.
+		logger.Fatalf("timed out waiting for states informer caches to sync")
+	}
+	wait.Until(n.collectNUMAStat, n.collectInterval, stopCh)
+}
+
+func (n *numaStatCollector) Started() bool {
+	return n.started.Load()
+}
+
+func (n *numaStatCollector) collectNUMAStat() {
+	logger.V(6).Info("start collectNUMAStat")
+	n.collectNodeNUMAStat()
+
+	cpuToNUMANode, err := n.getCPUToNUMANode()
+	if err != nil {
+		logger.Warningf("failed to get node cpu info for numa stat, err: %v", err)
+	}
+
+	podMetas := n.statesInformer.GetAllPods()
+	metrics.ResetPodMemoryNUMAStat()
+	for _, meta := range podMetas {
+		n.collectPodNUMAStat(meta, cpuToNUMANode)
+	}
+	n.started.Store(true)
+	logger.V(5).Infof("collectNUMAStat finished, pod num %d", len(podMetas))
+}
+
+func (n *numaStatCollector) collectNodeNUMAStat() {
+	stats, err := system.GetNUMANodeStats()
+	if err != nil {
+		logger.Warningf("failed to collect node numastat, err: %v", err)
+		return
+	}
+	for numaNodeID, stat := range stats {
+		metrics.RecordNodeNUMAStat(numaNodeID, metrics.NUMAStatFieldHit, float64(stat.NumaHit))
+		metrics.RecordNodeNUMAStat(numaNodeID, metrics.NUMAStatFieldMiss, float64(stat.NumaMiss))
+		metrics.RecordNodeNUMAStat(numaNodeID, metrics.NUMAStatFieldLocal, float64(stat.LocalNode))
+		metrics.RecordNodeNUMAStat(numaNodeID, metrics.NUMAStatFieldOther, float64(stat.OtherNode))
+	}
+}
+
+func (n *numaStatCollector) collectPodNUMAStat(meta *statesinformer.PodMeta, cpuToNUMANode map[int32]int32) {
+	pod := meta.Pod
+	podCgroupDir := koordletutil.GetPodCgroupDirWithKube(meta.CgroupDir)
+	numaPages, err := n.cgroupReader.ReadMemoryNumaStat(podCgroupDir)
+	if err != nil {
+		logger.V(4).Infof("failed to collect pod %s/%s memory numa stat, err: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	for _, pages := range numaPages {
+		metrics.RecordPodMemoryNUMAPages(pod, pages.NumaId, float64(pages.PagesNum))
+	}
+
+	if len(cpuToNUMANode) == 0 {
+		return
+	}
+	cpus, err := n.cgroupReader.ReadCPUSet(podCgroupDir)
+	if err != nil {
+		logger.V(4).Infof("failed to collect pod %s/%s cpuset for numa locality, err: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	localNUMANodes := sets.NewInt()
+	for _, cpu := range cpus.ToSliceNoSort() {
+		if numaNodeID, ok := cpuToNUMANode[int32(cpu)]; ok {
+			localNUMANodes.Insert(int(numaNodeID))
+		}
+	}
+	ratio, ok := calcNUMALocalityRatio(numaPages, localNUMANodes)
+	if !ok {
+		return
+	}
+	logger.V(6).Infof("collect pod %s/%s memory numa locality ratio %v, local numa nodes %v",
+		pod.Namespace, pod.Name, ratio, localNUMANodes.List())
+	metrics.RecordPodMemoryNUMALocalityRatio(pod, ratio)
+}
+
+func (n *numaStatCollector) getCPUToNUMANode() (map[int32]int32, error) {
+	nodeCPUInfo, err := n.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
+	if err != nil {
+		return nil, err
+	}
+	if nodeCPUInfo == nil {
+		return nil, nil
+	}
+	cpuToNUMANode := make(map[int32]int32, len(nodeCPUInfo.ProcessorInfos))
+	for _, processor := range nodeCPUInfo.ProcessorInfos {
+		cpuToNUMANode[processor.CPUID] = processor.NodeID
+	}
+	return cpuToNUMANode, nil
+}
+
+// calcNUMALocalityRatio returns the ratio of the memory pages allocated on the local NUMA nodes.
+func calcNUMALocalityRatio(numaPages []system.NumaMemoryPages, localNUMANodes sets.Int) (float64, bool) {
+	if localNUMANodes.Len() == 0 {
+		return 0, false
+	}
+	var total, local uint64
+	for _, pages := range numaPages {
+		total += pages.PagesNum
+		if localNUMANodes.Has(pages.NumaId) {
+			local += pages.PagesNum
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return float64(local) / float64(total), true
+}
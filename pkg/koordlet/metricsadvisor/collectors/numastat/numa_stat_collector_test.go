@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package numastat
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_calcNUMALocalityRatio(t *testing.T) {
+	numaPages := []system.NumaMemoryPages{
+		{NumaId: 0, PagesNum: 300},
+		{NumaId: 1, PagesNum: 100},
+	}
+	tests := []struct {
+		name           string
+		numaPages      []system.NumaMemoryPages
+		localNUMANodes sets.Int
+		want           float64
+		wantOK         bool
+	}{
+		{
+			name:           "mostly local",
+			numaPages:      numaPages,
+			localNUMANodes: sets.NewInt(0),
+			want:           0.75,
+			wantOK:         true,
+		},
+		{
+			name:           "all numa nodes are local",
+			numaPages:      numaPages,
+			localNUMANodes: sets.NewInt(0, 1),
+			want:           1,
+			wantOK:         true,
+		},
+		{
+			name:           "no local numa node",
+			numaPages:      numaPages,
+			localNUMANodes: sets.NewInt(),
+			wantOK:         false,
+		},
+		{
+			name:           "no memory pages",
+			numaPages:      []system.NumaMemoryPages{{NumaId: 0}},
+			localNUMANodes: sets.NewInt(0),
+			wantOK:         false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := calcNUMALocalityRatio(tt.numaPages, tt.localNUMANodes)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_numaStatCollector_collectNUMAStat(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+	metrics.Register(testNode)
+	defer metrics.Register(nil)
+
+	testPodMetaDir := "/kubepods-podxxxxxxxx.slice"
+	testPodParentDir := koordletutil.GetPodCgroupDirWithKube(testPodMetaDir)
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "test",
+			UID:       "xxxxxxxx",
+		},
+	}
+	helper.WriteCgroupFileContents(testPodParentDir, system.MemoryNumaStat, "total=400 N0=300 N1=100\nfile=200 N0=150 N1=50\nanon=200 N0=150 N1=50\n")
+	helper.WriteCgroupFileContents(testPodParentDir, system.CPUSet, "0-1")
+	helper.WriteFileContents(system.GetNUMANodeStatPath(0), "numa_hit 1000\nnuma_miss 20\nnuma_foreign 30\ninterleave_hit 0\nlocal_node 990\nother_node 10\n")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{
+		CgroupDir: testPodMetaDir,
+		Pod:       testPod,
+	}}).Times(1)
+	metricCache.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(&metriccache.NodeCPUInfo{
+		ProcessorInfos: []koordletutil.ProcessorInfo{
+			{CPUID: 0, NodeID: 0},
+			{CPUID: 1, NodeID: 0},
+			{CPUID: 2, NodeID: 1},
+			{CPUID: 3, NodeID: 1},
+		},
+	}, nil).Times(1)
+
+	c := New(&framework.Options{
+		Config:         &framework.Config{NUMAStatCollectorIntervalSeconds: 30},
+		StatesInformer: statesInformer,
+		MetricCache:    metricCache,
+		CgroupReader:   resourceexecutor.NewCgroupReader(),
+	}).(*numaStatCollector)
+	assert.False(t, c.Enabled())
+	c.collectNUMAStat()
+	assert.True(t, c.Started())
+
+	podLabels := []string{testNode.Name, string(testPod.UID), testPod.Name, testPod.Namespace}
+	assert.Equal(t, float64(300), testutil.ToFloat64(metrics.PodMemoryNUMAPages.WithLabelValues(append(podLabels, "0")...)))
+	assert.Equal(t, float64(100), testutil.ToFloat64(metrics.PodMemoryNUMAPages.WithLabelValues(append(podLabels, "1")...)))
+	assert.Equal(t, 0.75, testutil.ToFloat64(metrics.PodMemoryNUMALocalityRatio.WithLabelValues(podLabels...)))
+	assert.Equal(t, float64(1000), testutil.ToFloat64(metrics.NodeNUMAStat.WithLabelValues(testNode.Name, "0", metrics.NUMAStatFieldHit)))
+	assert.Equal(t, float64(20), testutil.ToFloat64(metrics.NodeNUMAStat.WithLabelValues(testNode.Name, "0", metrics.NUMAStatFieldMiss)))
+}
@@ -27,7 +27,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
@@ -36,10 +35,13 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/perf"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
 type performanceCollector struct {
 	cpiEnbaled                bool
 	psiEnabled                bool
@@ -77,7 +79,7 @@ func (p *performanceCollector) Setup(s *framework.Context) {}
 func (p *performanceCollector) Run(stopCh <-chan struct{}) {
 	if !cache.WaitForCacheSync(stopCh, p.statesInformer.HasSynced) {
 		// Koordlet exit because of statesInformer sync failed.
-		klog.Fatalf("timed out waiting for states informer caches to sync")
+		logger.Fatalf("timed out waiting for states informer caches to sync")
 	}
 	if p.psiEnabled {
 		// CgroupV1 psi collector support only on anolis os currently
@@ -86,18 +88,30 @@ func (p *performanceCollector) Run(stopCh <-chan struct{}) {
 			memPressureCheck, _ := system.CPUAcctMemoryPressure.IsSupported("")
 			ioPressureCheck, _ := system.CPUAcctIOPressure.IsSupported("")
 			if !(cpuPressureCheck && memPressureCheck && ioPressureCheck) {
-				klog.V(5).Infof("system now not support psi feature in CgroupV1, please check pressure file exist and readable in cpuacct directory.")
+				logger.V(5).Infof("system now not support psi feature in CgroupV1, please check pressure file exist and readable in cpuacct directory.")
 				return
 			}
 		}
-		go wait.Until(func() {
-			p.collectContainerPSI()
-			p.collectPodPSI()
-		}, p.psiCollectInterval, stopCh)
+	}
+	var wg sync.WaitGroup
+	if p.psiEnabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(func() {
+				p.collectContainerPSI()
+				p.collectPodPSI()
+			}, p.psiCollectInterval, stopCh)
+		}()
 	}
 	if p.cpiEnbaled {
-		go wait.Until(p.collectContainerCPI, p.cpiCollectInterval, stopCh)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(p.collectContainerCPI, p.cpiCollectInterval, stopCh)
+		}()
 	}
+	wg.Wait()
 }
 
 func (p *performanceCollector) Started() bool {
@@ -105,7 +119,7 @@ func (p *performanceCollector) Started() bool {
 }
 
 func (p *performanceCollector) collectContainerCPI() {
-	klog.V(6).Infof("start collectContainerCPI")
+	logger.V(6).Infof("start collectContainerCPI")
 	timeWindow := time.Now()
 	containerStatusesMap := map[*corev1.ContainerStatus]*statesinformer.PodMeta{}
 	podMetas := p.statesInformer.GetAllPods()
@@ -122,7 +136,7 @@ func (p *performanceCollector) collectContainerCPI() {
 	wg.Add(len(containerStatusesMap))
 	nodeCpuInfo, err := p.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
 	if err != nil {
-		klog.Errorf("failed to get node cpu info : %v", err)
+		logger.Errorf("failed to get node cpu info : %v", err)
 		return
 	}
 	cpuNumber := nodeCpuInfo.TotalInfo.NumberCPUs
@@ -155,26 +169,26 @@ func (p *performanceCollector) collectContainerCPI() {
 			}
 			perfCollector, ok := oneCollector.(*perf.PerfCollector)
 			if !ok {
-				klog.Errorf("PerfCollector type convert failed")
+				logger.Errorf("PerfCollector type convert failed")
 				return
 			}
 			p.profilePerfOnSingleContainer(status, perfCollector, pod)
 			err1 := perfCollector.CleanUp()
 			if err1 != nil {
-				klog.Errorf("PerfCollector cleanup err : %v", err1)
+				logger.Errorf("PerfCollector cleanup err : %v", err1)
 			}
 		}(containerStatus, pod)
 	}
 	wg1.Wait()
 	p.started.Store(true)
-	klog.V(5).Infof("collectContainerCPI for time window %s finished at %s, container num %d",
+	logger.V(5).Infof("collectContainerCPI for time window %s finished at %s, container num %d",
 		timeWindow, time.Now(), len(containerStatusesMap))
 }
 
 func (p *performanceCollector) getAndStartCollectorOnSingleContainer(podParentCgroupDir string, containerStatus *corev1.ContainerStatus, number int32) (*perf.PerfCollector, error) {
 	perfCollector, err := util.GetContainerPerfCollector(podParentCgroupDir, containerStatus, number)
 	if err != nil {
-		klog.Errorf("get and start container %s collector err: %v", containerStatus.Name, err)
+		logger.Errorf("get and start container %s collector err: %v", containerStatus.Name, err)
 		return nil, err
 	}
 	return perfCollector, nil
@@ -184,7 +198,7 @@ func (p *performanceCollector) profilePerfOnSingleContainer(containerStatus *cor
 	collectTime := time.Now()
 	cycles, instructions, err := util.GetContainerCyclesAndInstructions(collector)
 	if err != nil {
-		klog.Errorf("collect container %s cpi err: %v", containerStatus.Name, err)
+		logger.Errorf("collect container %s cpi err: %v", containerStatus.Name, err)
 		return
 	}
 	containerCpiMetric := &metriccache.ContainerInterferenceMetric{
@@ -198,13 +212,13 @@ func (p *performanceCollector) profilePerfOnSingleContainer(containerStatus *cor
 	}
 	err = p.metricCache.InsertContainerInterferenceMetrics(collectTime, containerCpiMetric)
 	if err != nil {
-		klog.Errorf("insert container cpi metrics failed, err %v", err)
+		logger.Errorf("insert container cpi metrics failed, err %v", err)
 	}
 	metrics.RecordContainerCPI(containerStatus, pod, float64(cycles), float64(instructions))
 }
 
 func (p *performanceCollector) collectContainerPSI() {
-	klog.V(6).Infof("start collectContainerPSI")
+	logger.V(6).Infof("start collectContainerPSI")
 	timeWindow := time.Now()
 	containerStatusesMap := map[*corev1.ContainerStatus]*statesinformer.PodMeta{}
 	podMetas := p.statesInformer.GetAllPods()
@@ -228,7 +242,7 @@ func (p *performanceCollector) collectContainerPSI() {
 	}
 	wg.Wait()
 	p.started.Store(true)
-	klog.V(5).Infof("collectContainerPSI for time window %s finished at %s, container num %d",
+	logger.V(5).Infof("collectContainerPSI for time window %s finished at %s, container num %d",
 		timeWindow, time.Now(), len(containerStatusesMap))
 }
 
@@ -236,12 +250,12 @@ func (p *performanceCollector) collectSingleContainerPSI(podParentCgroupDir stri
 	collectTime := time.Now()
 	containerPath, err := util.GetContainerCgroupPathWithKube(podParentCgroupDir, containerStatus)
 	if err != nil {
-		klog.Errorf("failed to get container path for container %v/%v/%v cgroup path failed, error: %v", pod.Namespace, pod.Name, containerStatus.Name, err)
+		logger.Errorf("failed to get container path for container %v/%v/%v cgroup path failed, error: %v", pod.Namespace, pod.Name, containerStatus.Name, err)
 		return
 	}
 	containerPSI, err := p.cgroupReader.ReadPSI(containerPath)
 	if err != nil {
-		klog.Errorf("collect container %s psi err: %v", containerStatus.Name, err)
+		logger.Errorf("collect container %s psi err: %v", containerStatus.Name, err)
 		return
 	}
 	containerPsiMetric := &metriccache.ContainerInterferenceMetric{
@@ -260,13 +274,13 @@ func (p *performanceCollector) collectSingleContainerPSI(podParentCgroupDir stri
 	}
 	err = p.metricCache.InsertContainerInterferenceMetrics(collectTime, containerPsiMetric)
 	if err != nil {
-		klog.Errorf("insert container psi metrics failed, err %v", err)
+		logger.Errorf("insert container psi metrics failed, err %v", err)
 	}
 	metrics.RecordContainerPSI(containerStatus, pod, containerPSI)
 }
 
 func (p *performanceCollector) collectPodPSI() {
-	klog.V(6).Infof("start collectPodPSI")
+	logger.V(6).Infof("start collectPodPSI")
 	timeWindow := time.Now()
 	podMetas := p.statesInformer.GetAllPods()
 	var wg sync.WaitGroup
@@ -282,7 +296,7 @@ func (p *performanceCollector) collectPodPSI() {
 	}
 	wg.Wait()
 	p.started.Store(true)
-	klog.V(5).Infof("collectPodPSI for time window %s finished at %s, pod num %d",
+	logger.V(5).Infof("collectPodPSI for time window %s finished at %s, pod num %d",
 		timeWindow, time.Now(), len(podMetas))
 }
 
@@ -291,7 +305,7 @@ func (p *performanceCollector) collectSinglePodPSI(pod *corev1.Pod, podCgroupDir
 	paths := util.GetPodCgroupDirWithKube(podCgroupDir)
 	podPSI, err := p.cgroupReader.ReadPSI(paths)
 	if err != nil {
-		klog.Errorf("collect pod %v/%v psi err: %v", pod.Namespace, pod.Name, err)
+		logger.Errorf("collect pod %v/%v psi err: %v", pod.Namespace, pod.Name, err)
 		return
 	}
 	podPsiMetric := &metriccache.PodInterferenceMetric{
@@ -309,7 +323,7 @@ func (p *performanceCollector) collectSinglePodPSI(pod *corev1.Pod, podCgroupDir
 	}
 	err = p.metricCache.InsertPodInterferenceMetrics(collectTime, podPsiMetric)
 	if err != nil {
-		klog.Errorf("insert pod psi metrics failed, err %v", err)
+		logger.Errorf("insert pod psi metrics failed, err %v", err)
 	}
 	metrics.RecordPodPSI(pod, podPSI)
 }
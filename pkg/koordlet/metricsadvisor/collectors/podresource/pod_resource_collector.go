@@ -17,27 +17,33 @@ limitations under the License.
 package podresource
 
 import (
+	"context"
 	"time"
 
 	gocache "github.com/patrickmn/go-cache"
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
 const (
 	CollectorName = "PodResourceCollector"
 )
 
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
 type podResourceCollector struct {
 	collectInterval      time.Duration
 	started              *atomic.Bool
@@ -46,12 +52,30 @@ type podResourceCollector struct {
 	cgroupReader         resourceexecutor.CgroupReader
 	lastPodCPUStat       *gocache.Cache
 	lastContainerCPUStat *gocache.Cache
+	lastPodNetStat       *gocache.Cache
+	lastContainerIOStat  *gocache.Cache
+
+	// shards is the number of shards the pods are split into, one shard is collected in each tick
+	shards    int
+	nextShard int
+	limiter   *rate.Limiter
+	skipper   *framework.CgroupSkipper
+	// statsSource is where the CPU and memory usages come from, the cgroups or the CRI stats of the runtime
+	statsSource string
 
 	deviceCollectors map[string]framework.DeviceCollector
 }
 
 func New(opt *framework.Options) framework.Collector {
 	collectInterval := time.Duration(opt.Config.CollectResUsedIntervalSeconds) * time.Second
+	shards := opt.Config.CollectPodShards
+	if shards < 1 {
+		shards = 1
+	}
+	var limiter *rate.Limiter
+	if qps := opt.Config.CollectPodCgroupReadQPS; qps > 0 {
+		limiter = rate.NewLimiter(rate.Limit(qps), qps)
+	}
 	return &podResourceCollector{
 		collectInterval:      collectInterval,
 		started:              atomic.NewBool(false),
@@ -60,6 +84,12 @@ func New(opt *framework.Options) framework.Collector {
 		cgroupReader:         opt.CgroupReader,
 		lastPodCPUStat:       gocache.New(collectInterval*framework.ContextExpiredRatio, framework.CleanupInterval),
 		lastContainerCPUStat: gocache.New(collectInterval*framework.ContextExpiredRatio, framework.CleanupInterval),
+		lastPodNetStat:       gocache.New(collectInterval*framework.ContextExpiredRatio, framework.CleanupInterval),
+		lastContainerIOStat:  gocache.New(collectInterval*framework.ContextExpiredRatio, framework.CleanupInterval),
+		shards:               shards,
+		limiter:              limiter,
+		skipper:              framework.NewCgroupSkipper(opt.Config.CollectIdlePodMaxSkipRounds),
+		statsSource:          opt.Config.ContainerStatsSource,
 	}
 }
 
@@ -77,9 +107,10 @@ func (p *podResourceCollector) Run(stopCh <-chan struct{}) {
 	}
 	if !cache.WaitForCacheSync(stopCh, p.statesInformer.HasSynced, devicesSynced) {
 		// Koordlet exit because of statesInformer sync failed.
-		klog.Fatalf("timed out waiting for states informer caches to sync")
+		logger.Fatalf("timed out waiting for states informer caches to sync")
 	}
-	go wait.Until(p.collectPodResUsed, p.collectInterval, stopCh)
+	// spread the shards evenly within the collect interval
+	wait.Until(p.collectPodResUsed, p.collectInterval/time.Duration(p.shards), stopCh)
 }
 
 func (p *podResourceCollector) Started() bool {
@@ -87,43 +118,80 @@ func (p *podResourceCollector) Started() bool {
 }
 
 func (p *podResourceCollector) collectPodResUsed() {
-	klog.V(6).Info("start collectPodResUsed")
-	podMetas := p.statesInformer.GetAllPods()
+	logger.V(6).Info("start collectPodResUsed")
+	allPodMetas := p.statesInformer.GetAllPods()
+	shard := p.nextShard % p.shards
+	p.nextShard = (shard + 1) % p.shards
+	podMetas := framework.ShardPods(allPodMetas, p.shards)[shard]
+	if p.statsSource == framework.ContainerStatsSourceCRI {
+		p.collectPodsResUsedByCRI(podMetas)
+	} else {
+		p.collectPodsResUsedByCgroup(podMetas)
+	}
+
+	// all shards have been collected
+	if p.nextShard == 0 {
+		podUIDs := sets.NewString()
+		for _, meta := range allPodMetas {
+			podUIDs.Insert(string(meta.Pod.UID))
+		}
+		p.skipper.Prune(podUIDs)
+		// update collect time
+		p.started.Store(true)
+	}
+	logger.Infof("collectPodResUsed finished, shard %d/%d, pod num %d", shard+1, p.shards, len(podMetas))
+}
+
+func (p *podResourceCollector) collectPodsResUsedByCgroup(podMetas []*statesinformer.PodMeta) {
 	for _, meta := range podMetas {
 		pod := meta.Pod
 		uid := string(pod.UID) // types.UID
-		collectTime := time.Now()
 		podCgroupDir := koordletutil.GetPodCgroupDirWithKube(meta.CgroupDir)
+		if p.skipper.ShouldSkip(uid, podCgroupDir) {
+			logger.V(6).Infof("skip collecting idle pod %s/%s since its cgroup is not modified", pod.Namespace, pod.Name)
+			continue
+		}
 
+		p.waitCgroupRead()
+		collectTime := time.Now()
 		currentCPUUsage, err0 := p.cgroupReader.ReadCPUAcctUsage(podCgroupDir)
 		memStat, err1 := p.cgroupReader.ReadMemoryStat(podCgroupDir)
 		if err0 != nil || err1 != nil {
 			// higher verbosity for probably non-running pods
 			if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
-				klog.V(6).Infof("failed to collect non-running pod usage for %s/%s, CPU err: %s, Memory "+
+				logger.V(6).Infof("failed to collect non-running pod usage for %s/%s, CPU err: %s, Memory "+
 					"err: %s", pod.Namespace, pod.Name, err0, err1)
 			} else {
-				klog.Warningf("failed to collect pod usage for %s/%s, CPU err: %s, Memory err: %s",
+				logger.Warningf("failed to collect pod usage for %s/%s, CPU err: %s, Memory err: %s",
 					pod.Namespace, pod.Name, err0, err1)
 			}
 			continue
 		}
 
+		networkUsed := p.collectPodNetworkUsed(meta, collectTime)
+		// the blkio counters of cgroups-v1 are not hierarchical, so the disk IO of the pod is summed by its containers
+		diskIOUsed := p.collectContainerResUsed(meta)
 		lastCPUStatValue, ok := p.lastPodCPUStat.Get(uid)
 		p.lastPodCPUStat.Set(uid, framework.CPUStat{
 			CPUUsage:  currentCPUUsage,
 			Timestamp: collectTime,
 		}, gocache.DefaultExpiration)
-		klog.V(6).Infof("last pod cpu stat size in pod resource collector cache %v", p.lastPodCPUStat.ItemCount())
+		logger.V(6).Infof("last pod cpu stat size in pod resource collector cache %v", p.lastPodCPUStat.ItemCount())
 		if !ok {
-			klog.Infof("ignore the first cpu stat collection for pod %s/%s", pod.Namespace, pod.Name)
+			logger.Infof("ignore the first cpu stat collection for pod %s/%s", pod.Namespace, pod.Name)
 			continue
 		}
 		lastCPUStat := lastCPUStatValue.(framework.CPUStat)
 		// do subtraction and division first to avoid overflow
 		cpuUsageValue := float64(currentCPUUsage-lastCPUStat.CPUUsage) / float64(collectTime.Sub(lastCPUStat.Timestamp))
+		// the pod using less than 1 milli-CPU is regarded as idle
+		p.skipper.Record(uid, podCgroupDir, cpuUsageValue*1000 < 1)
 
 		memUsageValue := memStat.Usage()
+		hugePagesUsed, err := koordletutil.GetPodHugePagesUsed(pod, podCgroupDir)
+		if err != nil {
+			logger.V(4).Infof("failed to collect pod hugepages usage for %s/%s, err: %s", pod.Namespace, pod.Name, err)
+		}
 
 		podMetric := metriccache.PodResourceMetric{
 			PodUID: uid,
@@ -135,44 +203,88 @@ func (p *podResourceCollector) collectPodResUsed() {
 				// 1.0 kB Memory = 1024 B
 				MemoryWithoutCache: *resource.NewQuantity(memUsageValue, resource.BinarySI),
 			},
+			NetworkUsed:   networkUsed,
+			DiskIOUsed:    diskIOUsed,
+			HugePagesUsed: hugePagesUsed,
 		}
 		for deviceName, deviceCollector := range p.deviceCollectors {
 			if err := deviceCollector.FillPodMetric(&podMetric, meta.CgroupDir, meta.Pod.Status.ContainerStatuses); err != nil {
-				klog.Warningf("fill pod %s/%s/%s device usage failed for %v, error: %v",
+				logger.Warningf("fill pod %s/%s device usage failed for %v, error: %v",
 					pod.Namespace, pod.Name, deviceName, err)
 			}
 		}
 
-		klog.V(6).Infof("collect pod %s/%s, uid %s finished, metric %+v",
+		logger.V(6).Infof("collect pod %s/%s, uid %s finished, metric %+v",
 			meta.Pod.Namespace, meta.Pod.Name, meta.Pod.UID, podMetric)
 
 		if err := p.metricDB.InsertPodResourceMetric(collectTime, &podMetric); err != nil {
-			klog.Errorf("insert pod %s/%s, uid %s resource metric failed, metric %v, err %v",
+			logger.Errorf("insert pod %s/%s, uid %s resource metric failed, metric %v, err %v",
 				pod.Namespace, pod.Name, uid, podMetric, err)
 		}
-		p.collectContainerResUsed(meta)
 	}
+}
 
-	// update collect time
-	p.started.Store(true)
-	klog.Infof("collectPodResUsed finished, pod num %d", len(podMetas))
+func (p *podResourceCollector) waitCgroupRead() {
+	if p.limiter == nil {
+		return
+	}
+	if err := p.limiter.Wait(context.TODO()); err != nil {
+		logger.V(5).Infof("failed to wait for the rate limiter of cgroup read, err: %v", err)
+	}
+}
+
+// collectPodNetworkUsed returns the bandwidth used by the pod since the last collection, which is read from the network
+// namespace of a process in the pod. The pods in the host network are not accounted.
+func (p *podResourceCollector) collectPodNetworkUsed(meta *statesinformer.PodMeta, collectTime time.Time) metriccache.NetworkMetric {
+	pod := meta.Pod
+	uid := string(pod.UID)
+	if pod.Spec.HostNetwork {
+		return metriccache.NetworkMetric{}
+	}
+	pids, err := koordletutil.GetPIDsInPod(meta.CgroupDir, pod.Status.ContainerStatuses)
+	if err != nil || len(pids) == 0 {
+		logger.V(6).Infof("failed to get pid of pod %s/%s for network usage, err: %v", pod.Namespace, pod.Name, err)
+		p.lastPodNetStat.Delete(uid)
+		return metriccache.NetworkMetric{}
+	}
+	netDevStat, err := system.GetProcessNetDevStat(pids[0])
+	if err != nil {
+		logger.V(6).Infof("failed to collect network usage of pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
+		p.lastPodNetStat.Delete(uid)
+		return metriccache.NetworkMetric{}
+	}
+	curNetStat := &framework.NetStat{
+		RxBytes:   netDevStat.RxBytes,
+		TxBytes:   netDevStat.TxBytes,
+		Timestamp: collectTime,
+	}
+	lastNetStatValue, ok := p.lastPodNetStat.Get(uid)
+	p.lastPodNetStat.Set(uid, curNetStat, gocache.DefaultExpiration)
+	if !ok {
+		return metriccache.NetworkMetric{}
+	}
+	return framework.CalculateNetworkMetric(lastNetStatValue.(*framework.NetStat), curNetStat)
 }
 
-func (p *podResourceCollector) collectContainerResUsed(meta *statesinformer.PodMeta) {
-	klog.V(6).Infof("start collectContainerResUsed")
+// collectContainerResUsed collects the resource usages of the containers in the pod, and returns the total disk IO of
+// the containers.
+func (p *podResourceCollector) collectContainerResUsed(meta *statesinformer.PodMeta) metriccache.DiskIOMetric {
+	logger.V(6).Infof("start collectContainerResUsed")
 	pod := meta.Pod
+	podDiskIOUsed := metriccache.DiskIOMetric{}
 	for i := range pod.Status.ContainerStatuses {
 		containerStat := &pod.Status.ContainerStatuses[i]
-		collectTime := time.Now()
 		if len(containerStat.ContainerID) == 0 {
-			klog.V(5).Infof("container %s/%s/%s id is empty, maybe not ready, skip this round",
+			logger.V(5).Infof("container %s/%s/%s id is empty, maybe not ready, skip this round",
 				pod.Namespace, pod.Name, containerStat.Name)
 			continue
 		}
+		p.waitCgroupRead()
+		collectTime := time.Now()
 
 		containerCgroupDir, err := koordletutil.GetContainerCgroupPathWithKube(meta.CgroupDir, containerStat)
 		if err != nil {
-			klog.V(4).Infof("failed to collect container usage for %s/%s/%s, cannot get container cgroup, err: %s",
+			logger.V(4).Infof("failed to collect container usage for %s/%s/%s, cannot get container cgroup, err: %s",
 				pod.Namespace, pod.Name, containerStat.Name, err)
 			continue
 		}
@@ -183,23 +295,29 @@ func (p *podResourceCollector) collectContainerResUsed(meta *statesinformer.PodM
 		if err0 != nil || err1 != nil {
 			// higher verbosity for probably non-running pods
 			if containerStat.State.Running == nil {
-				klog.V(6).Infof("failed to collect non-running container usage for %s/%s/%s, CPU err: %s, Memory err: %s",
+				logger.V(6).Infof("failed to collect non-running container usage for %s/%s/%s, CPU err: %s, Memory err: %s",
 					pod.Namespace, pod.Name, containerStat.Name, err0, err1)
 			} else {
-				klog.V(4).Infof("failed to collect container usage for %s/%s/%s, CPU err: %s, Memory err: %s",
+				logger.V(4).Infof("failed to collect container usage for %s/%s/%s, CPU err: %s, Memory err: %s",
 					pod.Namespace, pod.Name, containerStat.Name, err0, err1)
 			}
 			continue
 		}
 
+		diskIOUsed := p.collectContainerDiskIOUsed(containerCgroupDir, containerStat.ContainerID, collectTime)
+		podDiskIOUsed.ReadBytesPerSec.Add(diskIOUsed.ReadBytesPerSec)
+		podDiskIOUsed.WriteBytesPerSec.Add(diskIOUsed.WriteBytesPerSec)
+		podDiskIOUsed.ReadIOPS.Add(diskIOUsed.ReadIOPS)
+		podDiskIOUsed.WriteIOPS.Add(diskIOUsed.WriteIOPS)
+
 		lastCPUStatValue, ok := p.lastContainerCPUStat.Get(containerStat.ContainerID)
 		p.lastContainerCPUStat.Set(containerStat.ContainerID, framework.CPUStat{
 			CPUUsage:  currentCPUUsage,
 			Timestamp: collectTime,
 		}, gocache.DefaultExpiration)
-		klog.V(6).Infof("last container cpu stat size in pod resource collector cache %v", p.lastPodCPUStat.ItemCount())
+		logger.V(6).Infof("last container cpu stat size in pod resource collector cache %v", p.lastPodCPUStat.ItemCount())
 		if !ok {
-			klog.V(5).Infof("ignore the first cpu stat collection for container %s/%s/%s",
+			logger.V(5).Infof("ignore the first cpu stat collection for container %s/%s/%s",
 				pod.Namespace, pod.Name, containerStat.Name)
 			continue
 		}
@@ -219,21 +337,43 @@ func (p *podResourceCollector) collectContainerResUsed(meta *statesinformer.PodM
 				// 1.0 kB Memory = 1024 B
 				MemoryWithoutCache: *resource.NewQuantity(memUsageValue, resource.BinarySI),
 			},
+			DiskIOUsed: diskIOUsed,
 		}
 
 		for deviceName, deviceCollector := range p.deviceCollectors {
 			if err := deviceCollector.FillContainerMetric(&containerMetric, meta.CgroupDir, containerStat); err != nil {
-				klog.Warningf("fill container %s/%s/%s device usage failed for %v, error: %v",
+				logger.Warningf("fill container %s/%s/%s device usage failed for %v, error: %v",
 					pod.Namespace, pod.Name, containerStat.Name, deviceName, err)
 			}
 		}
 
-		klog.V(6).Infof("collect container %s/%s/%s, id %s finished, metric %+v",
+		logger.V(6).Infof("collect container %s/%s/%s, id %s finished, metric %+v",
 			meta.Pod.Namespace, meta.Pod.Name, containerStat.Name, meta.Pod.UID, containerMetric)
 		if err := p.metricDB.InsertContainerResourceMetric(collectTime, &containerMetric); err != nil {
-			klog.Errorf("insert container resource metric error: %v", err)
+			logger.Errorf("insert container resource metric error: %v", err)
 		}
 	}
-	klog.V(5).Infof("collectContainerResUsed for pod %s/%s finished, container num %d",
+	logger.V(5).Infof("collectContainerResUsed for pod %s/%s finished, container num %d",
 		pod.Namespace, pod.Name, len(pod.Status.ContainerStatuses))
+	return podDiskIOUsed
+}
+
+// collectContainerDiskIOUsed returns the disk IO issued by the container since the last collection.
+func (p *podResourceCollector) collectContainerDiskIOUsed(containerCgroupDir, containerID string, collectTime time.Time) metriccache.DiskIOMetric {
+	ioStat, err := p.cgroupReader.ReadDiskIOStat(containerCgroupDir)
+	if err != nil {
+		logger.V(6).Infof("failed to collect disk io usage of container %s, err: %v", containerID, err)
+		p.lastContainerIOStat.Delete(containerID)
+		return metriccache.DiskIOMetric{}
+	}
+	curIOStat := &framework.DiskIOStat{
+		DiskIOStat: *ioStat,
+		Timestamp:  collectTime,
+	}
+	lastIOStatValue, ok := p.lastContainerIOStat.Get(containerID)
+	p.lastContainerIOStat.Set(containerID, curIOStat, gocache.DefaultExpiration)
+	if !ok {
+		return metriccache.DiskIOMetric{}
+	}
+	return framework.CalculateDiskIOMetric(lastIOStatValue.(*framework.DiskIOStat), curIOStat)
 }
@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podresource
+
+import (
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+	"k8s.io/apimachinery/pkg/api/resource"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	koordletruntime "github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+var getContainerStatsHandler = koordletruntime.GetContainerStatsHandler // for test
+
+// collectPodsResUsedByCRI collects the CPU and memory usages of the pods and containers from the stats listed by the
+// container runtime, which works for the sandboxed runtimes (e.g. Kata, gVisor) whose cgroup paths differ from runc.
+// The usage of a pod is the sum of its containers. The disk IO and the device usages are still collected from the
+// cgroups as far as they are available.
+func (p *podResourceCollector) collectPodsResUsedByCRI(podMetas []*statesinformer.PodMeta) {
+	statsHandler, err := getContainerStatsHandler()
+	if err != nil {
+		logger.Warningf("failed to get container stats handler, err: %v", err)
+		return
+	}
+	containerStats, err := statsHandler.ListContainerStats()
+	if err != nil {
+		logger.Warningf("failed to list container stats, err: %v", err)
+		return
+	}
+	collectTime := time.Now()
+	statsByID := make(map[string]*runtimeapi.ContainerStats, len(containerStats))
+	for _, stats := range containerStats {
+		if id := stats.GetAttributes().GetId(); len(id) > 0 {
+			statsByID[id] = stats
+		}
+	}
+
+	for _, meta := range podMetas {
+		p.collectPodResUsedByCRI(meta, statsByID, collectTime)
+	}
+}
+
+func (p *podResourceCollector) collectPodResUsedByCRI(meta *statesinformer.PodMeta,
+	statsByID map[string]*runtimeapi.ContainerStats, collectTime time.Time) {
+	pod := meta.Pod
+	uid := string(pod.UID)
+	var podCPUUsed float64
+	var podMemUsage uint64
+	podDiskIOUsed := metriccache.DiskIOMetric{}
+	hasStats, hasCPUUsed := false, false
+	for i := range pod.Status.ContainerStatuses {
+		containerStat := &pod.Status.ContainerStatuses[i]
+		_, containerID, err := util.ParseContainerId(containerStat.ContainerID)
+		if err != nil {
+			logger.V(5).Infof("container %s/%s/%s id is invalid, maybe not ready, skip this round, err: %v",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		stats, ok := statsByID[containerID]
+		if !ok {
+			logger.V(6).Infof("container %s/%s/%s has no stats from the runtime", pod.Namespace, pod.Name, containerStat.Name)
+			continue
+		}
+		hasStats = true
+		cpuUsage := stats.GetCpu().GetUsageCoreNanoSeconds().GetValue()
+		// the working set excludes the inactive file pages, which is in line with the usage read from the cgroup
+		memUsage := stats.GetMemory().GetWorkingSetBytes().GetValue()
+		podMemUsage += memUsage
+
+		var diskIOUsed metriccache.DiskIOMetric
+		if containerCgroupDir, err := koordletutil.GetContainerCgroupPathWithKube(meta.CgroupDir, containerStat); err == nil {
+			diskIOUsed = p.collectContainerDiskIOUsed(containerCgroupDir, containerStat.ContainerID, collectTime)
+			podDiskIOUsed.ReadBytesPerSec.Add(diskIOUsed.ReadBytesPerSec)
+			podDiskIOUsed.WriteBytesPerSec.Add(diskIOUsed.WriteBytesPerSec)
+			podDiskIOUsed.ReadIOPS.Add(diskIOUsed.ReadIOPS)
+			podDiskIOUsed.WriteIOPS.Add(diskIOUsed.WriteIOPS)
+		}
+
+		// the cumulative usages of the containers restarted separately cannot be summed, so the usage of the pod is
+		// summed by the usages of the containers since the last collection
+		cpuUsageValue, ok := calculateCPUUsage(p.lastContainerCPUStat, containerStat.ContainerID, cpuUsage, collectTime)
+		if !ok {
+			logger.V(5).Infof("ignore the first cpu stat collection for container %s/%s/%s",
+				pod.Namespace, pod.Name, containerStat.Name)
+			continue
+		}
+		hasCPUUsed = true
+		podCPUUsed += cpuUsageValue
+		containerMetric := metriccache.ContainerResourceMetric{
+			ContainerID: containerStat.ContainerID,
+			CPUUsed: metriccache.CPUMetric{
+				CPUUsed: *resource.NewMilliQuantity(int64(cpuUsageValue*1000), resource.DecimalSI),
+			},
+			MemoryUsed: metriccache.MemoryMetric{
+				MemoryWithoutCache: *resource.NewQuantity(int64(memUsage), resource.BinarySI),
+			},
+			DiskIOUsed: diskIOUsed,
+		}
+		for deviceName, deviceCollector := range p.deviceCollectors {
+			if err := deviceCollector.FillContainerMetric(&containerMetric, meta.CgroupDir, containerStat); err != nil {
+				logger.Warningf("fill container %s/%s/%s device usage failed for %v, error: %v",
+					pod.Namespace, pod.Name, containerStat.Name, deviceName, err)
+			}
+		}
+		if err := p.metricDB.InsertContainerResourceMetric(collectTime, &containerMetric); err != nil {
+			logger.Errorf("insert container resource metric error: %v", err)
+		}
+	}
+	if !hasStats {
+		logger.V(6).Infof("pod %s/%s has no container stats from the runtime, skip this round", pod.Namespace, pod.Name)
+		return
+	}
+	if !hasCPUUsed {
+		logger.Infof("ignore the first cpu stat collection for pod %s/%s", pod.Namespace, pod.Name)
+		return
+	}
+
+	podMetric := metriccache.PodResourceMetric{
+		PodUID: uid,
+		CPUUsed: metriccache.CPUMetric{
+			CPUUsed: *resource.NewMilliQuantity(int64(podCPUUsed*1000), resource.DecimalSI),
+		},
+		MemoryUsed: metriccache.MemoryMetric{
+			MemoryWithoutCache: *resource.NewQuantity(int64(podMemUsage), resource.BinarySI),
+		},
+		DiskIOUsed: podDiskIOUsed,
+	}
+	for deviceName, deviceCollector := range p.deviceCollectors {
+		if err := deviceCollector.FillPodMetric(&podMetric, meta.CgroupDir, pod.Status.ContainerStatuses); err != nil {
+			logger.Warningf("fill pod %s/%s device usage failed for %v, error: %v",
+				pod.Namespace, pod.Name, deviceName, err)
+		}
+	}
+	logger.V(6).Infof("collect pod %s/%s, uid %s by cri finished, metric %+v", pod.Namespace, pod.Name, uid, podMetric)
+	if err := p.metricDB.InsertPodResourceMetric(collectTime, &podMetric); err != nil {
+		logger.Errorf("insert pod %s/%s, uid %s resource metric failed, metric %v, err %v",
+			pod.Namespace, pod.Name, uid, podMetric, err)
+	}
+}
+
+// calculateCPUUsage records the cumulative cpu usage of the key and returns the cores used since the last record. It
+// returns false when there is no last record or the counter goes backwards, e.g. a container of the pod restarts.
+func calculateCPUUsage(lastStats *gocache.Cache, key string, cpuUsage uint64, collectTime time.Time) (float64, bool) {
+	lastStatValue, ok := lastStats.Get(key)
+	lastStats.Set(key, framework.CPUStat{
+		CPUUsage:  cpuUsage,
+		Timestamp: collectTime,
+	}, gocache.DefaultExpiration)
+	if !ok {
+		return 0, false
+	}
+	lastStat := lastStatValue.(framework.CPUStat)
+	if cpuUsage < lastStat.CPUUsage || !collectTime.After(lastStat.Timestamp) {
+		return 0, false
+	}
+	// do subtraction and division first to avoid overflow
+	return float64(cpuUsage-lastStat.CPUUsage) / float64(collectTime.Sub(lastStat.Timestamp)), true
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podresource
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+	critesting "k8s.io/cri-api/pkg/apis/testing"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/runtime/handler"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_podResourceCollector_collectPodResUsedByCRI(t *testing.T) {
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "test",
+			UID:       "xxxxxxxx",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "test-container-1",
+					ContainerID: "containerd://123abc",
+				},
+				{
+					Name:        "test-container-2",
+					ContainerID: "containerd://456def",
+				},
+				{
+					Name: "test-container-not-ready",
+				},
+			},
+		},
+	}
+	newContainerStats := func(id string, cpuUsage, memUsage uint64) *runtimeapi.ContainerStats {
+		return &runtimeapi.ContainerStats{
+			Attributes: &runtimeapi.ContainerAttributes{Id: id},
+			Cpu:        &runtimeapi.CpuUsage{UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: cpuUsage}},
+			Memory:     &runtimeapi.MemoryUsage{WorkingSetBytes: &runtimeapi.UInt64Value{Value: memUsage}},
+		}
+	}
+	tests := []struct {
+		name          string
+		handlerErr    error
+		lastStatTime  time.Duration
+		restarted     bool
+		wantPodMetric bool
+		wantMemory    int64
+		wantCPU       int64
+		wantContainer int
+	}{
+		{
+			name:          "collect pod and containers from cri stats",
+			lastStatTime:  -time.Second,
+			wantPodMetric: true,
+			wantMemory:    300 << 20,
+			wantCPU:       1000,
+			wantContainer: 2,
+		},
+		{
+			name:          "sum the cpu usages of the containers not restarted",
+			lastStatTime:  -time.Second,
+			restarted:     true,
+			wantPodMetric: true,
+			wantMemory:    300 << 20,
+			wantCPU:       500,
+			wantContainer: 1,
+		},
+		{
+			name:          "ignore the first collection",
+			wantPodMetric: false,
+		},
+		{
+			name:          "failed to get the stats handler",
+			handlerErr:    fmt.Errorf("expected error"),
+			lastStatTime:  -time.Second,
+			wantPodMetric: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+
+			fakeHandler := handler.NewFakeRuntimeHandler().(*handler.FakeRuntimeHandler)
+			fakeHandler.SetFakeContainers([]*critesting.FakeContainer{
+				{ContainerStatus: runtimeapi.ContainerStatus{Id: "123abc"}},
+				{ContainerStatus: runtimeapi.ContainerStatus{Id: "456def"}},
+			})
+			fakeHandler.SetFakeContainerStats([]*runtimeapi.ContainerStats{
+				newContainerStats("123abc", 2000000000, 100<<20),
+				newContainerStats("456def", 3000000000, 200<<20),
+			})
+			oldGetHandler := getContainerStatsHandler
+			getContainerStatsHandler = func() (handler.ContainerStatsHandler, error) {
+				return fakeHandler, tt.handlerErr
+			}
+			defer func() {
+				getContainerStatsHandler = oldGetHandler
+			}()
+
+			statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+			statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{Pod: testPod}}).Times(1)
+			metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+			var podMetric *metriccache.PodResourceMetric
+			var containerMetrics []*metriccache.ContainerResourceMetric
+			metricCache.EXPECT().InsertPodResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ time.Time, m *metriccache.PodResourceMetric) error {
+					podMetric = m
+					return nil
+				}).AnyTimes()
+			metricCache.EXPECT().InsertContainerResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ time.Time, m *metriccache.ContainerResourceMetric) error {
+					containerMetrics = append(containerMetrics, m)
+					return nil
+				}).AnyTimes()
+
+			collector := New(&framework.Options{
+				Config: &framework.Config{
+					CollectResUsedIntervalSeconds: 1,
+					ContainerStatsSource:          framework.ContainerStatsSourceCRI,
+				},
+				StatesInformer: statesInformer,
+				MetricCache:    metricCache,
+				CgroupReader:   resourceexecutor.NewCgroupReader(),
+			})
+			c := collector.(*podResourceCollector)
+			if tt.lastStatTime != 0 {
+				lastTime := time.Now().Add(tt.lastStatTime)
+				c.lastContainerCPUStat.Set("containerd://123abc", framework.CPUStat{CPUUsage: 1500000000, Timestamp: lastTime}, gocache.DefaultExpiration)
+				lastCPUUsage := uint64(2500000000)
+				if tt.restarted {
+					lastCPUUsage = 4000000000
+				}
+				c.lastContainerCPUStat.Set("containerd://456def", framework.CPUStat{CPUUsage: lastCPUUsage, Timestamp: lastTime}, gocache.DefaultExpiration)
+			}
+			deviceCollector := &fakeDeviceCollector{}
+			c.deviceCollectors = map[string]framework.DeviceCollector{"fake": deviceCollector}
+
+			c.collectPodResUsed()
+			assert.True(t, c.Started())
+			if !tt.wantPodMetric {
+				assert.Nil(t, podMetric)
+				return
+			}
+			assert.NotNil(t, podMetric)
+			assert.Equal(t, string(testPod.UID), podMetric.PodUID)
+			assert.Equal(t, tt.wantMemory, podMetric.MemoryUsed.MemoryWithoutCache.Value())
+			// allowing for the elapsed time of the test
+			assert.InDelta(t, tt.wantCPU, podMetric.CPUUsed.CPUUsed.MilliValue(), 50)
+			assert.Equal(t, tt.wantContainer, len(containerMetrics))
+			assert.Equal(t, 1, deviceCollector.podFilled)
+			assert.Equal(t, tt.wantContainer, deviceCollector.containerFilled)
+		})
+	}
+}
+
+type fakeDeviceCollector struct {
+	framework.DeviceCollector
+	podFilled       int
+	containerFilled int
+}
+
+func (f *fakeDeviceCollector) FillPodMetric(podMetric *metriccache.PodResourceMetric, podParentDir string, cs []corev1.ContainerStatus) error {
+	f.podFilled++
+	return nil
+}
+
+func (f *fakeDeviceCollector) FillContainerMetric(containerMetric *metriccache.ContainerResourceMetric, podParentDir string, c *corev1.ContainerStatus) error {
+	f.containerFilled++
+	return nil
+}
+
+func Test_calculateCPUUsage(t *testing.T) {
+	now := time.Now()
+	lastStats := gocache.New(time.Minute, time.Minute)
+
+	_, ok := calculateCPUUsage(lastStats, "test", 1000000000, now)
+	assert.False(t, ok, "the first record")
+
+	got, ok := calculateCPUUsage(lastStats, "test", 3000000000, now.Add(time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, got)
+
+	_, ok = calculateCPUUsage(lastStats, "test", 1000000000, now.Add(2*time.Second))
+	assert.False(t, ok, "the counter goes backwards")
+
+	got, ok = calculateCPUUsage(lastStats, "test", 1500000000, now.Add(3*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, got)
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package podresource
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -26,6 +29,7 @@ import (
 	"go.uber.org/atomic"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
@@ -33,6 +37,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
@@ -245,8 +250,150 @@ func Test_podResourceCollector_Run(t *testing.T) {
 	assert.True(t, collector.Enabled())
 	assert.True(t, collector.Started())
 	assert.NotPanics(t, func() {
-		stopCh := make(chan struct{}, 1)
+		stopCh := make(chan struct{})
+		close(stopCh)
 		collector.Run(stopCh)
-		stopCh <- struct{}{}
 	})
 }
+
+func Test_podResourceCollector_collectPodResUsedInShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var podMetas []*statesinformer.PodMeta
+	for i := 0; i < 10; i++ {
+		podMetas = append(podMetas, &statesinformer.PodMeta{
+			CgroupDir: fmt.Sprintf("/kubepods-pod%d.slice", i),
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("test-pod-%d", i),
+					Namespace: "test",
+					UID:       types.UID(fmt.Sprintf("%d", i)),
+				},
+			},
+		})
+	}
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return(podMetas).Times(2)
+	metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+
+	collector := New(&framework.Options{
+		Config: &framework.Config{
+			CollectResUsedIntervalSeconds: 1,
+			CollectPodShards:              2,
+			CollectPodCgroupReadQPS:       1000,
+		},
+		StatesInformer: statesInformer,
+		MetricCache:    metricCache,
+		CgroupReader:   resourceexecutor.NewCgroupReader(),
+	})
+	c := collector.(*podResourceCollector)
+	c.collectPodResUsed()
+	assert.False(t, c.Started())
+	assert.Equal(t, 1, c.nextShard)
+	c.collectPodResUsed()
+	assert.True(t, c.Started())
+	assert.Equal(t, 0, c.nextShard)
+}
+
+// BenchmarkPodResourceCollector_collectPodResUsed measures a round of the pod resource collection on a node with
+// high pod density.
+func BenchmarkPodResourceCollector_collectPodResUsed(b *testing.B) {
+	const podNum = 500
+	oldCgroupRootDir := system.Conf.CgroupRootDir
+	system.Conf.CgroupRootDir = b.TempDir()
+	defer func() {
+		system.Conf.CgroupRootDir = oldCgroupRootDir
+	}()
+
+	writeFile := func(r system.Resource, dir string, content string) {
+		filePath := system.GetCgroupFilePath(dir, r)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	writeCgroup := func(dir string) {
+		writeFile(system.CPUAcctUsage, dir, "1000000000\n")
+		writeFile(system.CPUAcctUsageV2, dir, "usage_usec 1000000\nuser_usec 900000\nsystem_usec 100000\n")
+		writeFile(system.MemoryStat, dir, "total_cache 104857600\ntotal_rss 104857600\ntotal_inactive_anon 104857600\ntotal_active_anon 0\n"+
+			"total_inactive_file 104857600\ntotal_active_file 0\ntotal_unevictable 0\n")
+		writeFile(system.MemoryStatV2, dir, "file 104857600\nanon 104857600\ninactive_anon 104857600\nactive_anon 0\n"+
+			"inactive_file 104857600\nactive_file 0\nunevictable 0\n")
+	}
+	var podMetas []*statesinformer.PodMeta
+	for i := 0; i < podNum; i++ {
+		meta := &statesinformer.PodMeta{
+			CgroupDir: fmt.Sprintf("/kubepods-pod%d.slice", i),
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("test-pod-%d", i),
+					Namespace: "test",
+					UID:       types.UID(fmt.Sprintf("%d", i)),
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{
+							Name:        "test-container",
+							ContainerID: fmt.Sprintf("containerd://%d", i),
+							State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+						},
+					},
+				},
+			},
+		}
+		writeCgroup(koordletutil.GetPodCgroupDirWithKube(meta.CgroupDir))
+		containerDir, err := koordletutil.GetContainerCgroupPathWithKube(meta.CgroupDir, &meta.Pod.Status.ContainerStatuses[0])
+		if err != nil {
+			b.Fatal(err)
+		}
+		writeCgroup(containerDir)
+		podMetas = append(podMetas, meta)
+	}
+
+	tests := []struct {
+		name   string
+		config *framework.Config
+	}{
+		{
+			name: "collect all pods",
+			config: &framework.Config{
+				CollectResUsedIntervalSeconds: 1,
+			},
+		},
+		{
+			name: "skip idle pods",
+			config: &framework.Config{
+				CollectResUsedIntervalSeconds: 1,
+				CollectIdlePodMaxSkipRounds:   5,
+			},
+		},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			ctrl := gomock.NewController(b)
+			defer ctrl.Finish()
+			statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+			statesInformer.EXPECT().GetAllPods().Return(podMetas).AnyTimes()
+			metricCache := mock_metriccache.NewMockMetricCache(ctrl)
+			metricCache.EXPECT().InsertPodResourceMetric(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			metricCache.EXPECT().InsertContainerResourceMetric(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			c := New(&framework.Options{
+				Config:         tt.config,
+				StatesInformer: statesInformer,
+				MetricCache:    metricCache,
+				CgroupReader:   resourceexecutor.NewCgroupReader(),
+			}).(*podResourceCollector)
+			// the first round only records the cpu stats
+			c.collectPodResUsed()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.collectPodResUsed()
+			}
+		})
+	}
+}
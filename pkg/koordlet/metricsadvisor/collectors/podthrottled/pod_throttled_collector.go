@@ -24,13 +24,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
@@ -38,6 +38,8 @@ const (
 	CollectorName = "PodThrottledCollector"
 )
 
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
 // TODO more ut is needed for this plugin
 type podThrottledCollector struct {
 	collectInterval time.Duration
@@ -72,9 +74,9 @@ func (p *podThrottledCollector) Setup(c *framework.Context) {}
 func (p *podThrottledCollector) Run(stopCh <-chan struct{}) {
 	if !cache.WaitForCacheSync(stopCh, p.statesInformer.HasSynced) {
 		// Koordlet exit because of statesInformer sync failed.
-		klog.Fatalf("timed out waiting for states informer caches to sync")
+		logger.Fatalf("timed out waiting for states informer caches to sync")
 	}
-	go wait.Until(p.collectPodThrottledInfo, p.collectInterval, stopCh)
+	wait.Until(p.collectPodThrottledInfo, p.collectInterval, stopCh)
 }
 
 func (p *podThrottledCollector) Started() bool {
@@ -82,7 +84,7 @@ func (p *podThrottledCollector) Started() bool {
 }
 
 func (c *podThrottledCollector) collectPodThrottledInfo() {
-	klog.V(6).Info("start collectPodThrottledInfo")
+	logger.V(6).Info("start collectPodThrottledInfo")
 	podMetas := c.statesInformer.GetAllPods()
 	for _, meta := range podMetas {
 		pod := meta.Pod
@@ -93,23 +95,23 @@ func (c *podThrottledCollector) collectPodThrottledInfo() {
 		if err != nil || currentCPUStat == nil {
 			if pod.Status.Phase == corev1.PodRunning {
 				// print running pod collection error
-				klog.V(4).Infof("collect pod %s/%s, uid %v cpu throttled failed, err %v, metric %v",
+				logger.V(4).Infof("collect pod %s/%s, uid %v cpu throttled failed, err %v, metric %v",
 					pod.Namespace, pod.Name, uid, err, currentCPUStat)
 			}
 			continue
 		}
 		lastCPUThrottledValue, ok := c.lastPodCPUThrottled.Get(uid)
 		c.lastPodCPUThrottled.Set(uid, currentCPUStat, gocache.DefaultExpiration)
-		klog.V(6).Infof("last pod cpu stat size in pod throttled collector cache %v", c.lastPodCPUThrottled.ItemCount())
+		logger.V(6).Infof("last pod cpu stat size in pod throttled collector cache %v", c.lastPodCPUThrottled.ItemCount())
 		if !ok {
-			klog.V(6).Infof("collect pod %s/%s, uid %s cpu throttled first point",
+			logger.V(6).Infof("collect pod %s/%s, uid %s cpu throttled first point",
 				meta.Pod.Namespace, meta.Pod.Name, meta.Pod.UID)
 			continue
 		}
 		lastCPUThrottled := lastCPUThrottledValue.(*system.CPUStatRaw)
 		cpuThrottledRatio := system.CalcCPUThrottledRatio(currentCPUStat, lastCPUThrottled)
 
-		klog.V(6).Infof("collect pod %s/%s, uid %s throttled finished, metric %v",
+		logger.V(6).Infof("collect pod %s/%s, uid %s throttled finished, metric %v",
 			meta.Pod.Namespace, meta.Pod.Name, meta.Pod.UID, cpuThrottledRatio)
 		podMetric := &metriccache.PodThrottledMetric{
 			PodUID: uid,
@@ -119,13 +121,13 @@ func (c *podThrottledCollector) collectPodThrottledInfo() {
 		}
 		err = c.metricDB.InsertPodThrottledMetrics(collectTime, podMetric)
 		if err != nil {
-			klog.Infof("insert pod %s/%s, uid %s cpu throttled metric failed, metric %v, err %v",
+			logger.Infof("insert pod %s/%s, uid %s cpu throttled metric failed, metric %v, err %v",
 				pod.Namespace, pod.Name, uid, podMetric, err)
 		}
 		c.collectContainerThrottledInfo(meta)
 	} // end for podMeta
 	c.started.Store(true)
-	klog.Infof("collectPodThrottledInfo finished, pod num %d", len(podMetas))
+	logger.Infof("collectPodThrottledInfo finished, pod num %d", len(podMetas))
 }
 
 func (c *podThrottledCollector) collectContainerThrottledInfo(podMeta *statesinformer.PodMeta) {
@@ -134,14 +136,14 @@ func (c *podThrottledCollector) collectContainerThrottledInfo(podMeta *statesinf
 		collectTime := time.Now()
 		containerStat := &pod.Status.ContainerStatuses[i]
 		if len(containerStat.ContainerID) == 0 {
-			klog.V(5).Infof("container %s/%s/%s id is empty, maybe not ready, skip this round",
+			logger.V(5).Infof("container %s/%s/%s id is empty, maybe not ready, skip this round",
 				pod.Namespace, pod.Name, containerStat.Name)
 			continue
 		}
 
 		containerCgroupDir, err := koordletutil.GetContainerCgroupPathWithKube(podMeta.CgroupDir, containerStat)
 		if err != nil {
-			klog.V(4).Infof("collect container %s/%s/%s cpu throttled failed, cannot get container cgroup, err: %s",
+			logger.V(4).Infof("collect container %s/%s/%s cpu throttled failed, cannot get container cgroup, err: %s",
 				pod.Namespace, pod.Name, containerStat.Name, err)
 			continue
 		}
@@ -150,19 +152,19 @@ func (c *podThrottledCollector) collectContainerThrottledInfo(podMeta *statesinf
 		if err != nil {
 			// higher verbosity for probably non-running pods
 			if containerStat.State.Running == nil {
-				klog.V(6).Infof("collect non-running container %s/%s/%s cpu throttled failed, err: %s",
+				logger.V(6).Infof("collect non-running container %s/%s/%s cpu throttled failed, err: %s",
 					pod.Namespace, pod.Name, containerStat.Name, err)
 			} else {
-				klog.V(4).Infof("collect container %s/%s/%s cpu throttled failed, err: %s",
+				logger.V(4).Infof("collect container %s/%s/%s cpu throttled failed, err: %s",
 					pod.Namespace, pod.Name, containerStat.Name, err)
 			}
 			continue
 		}
 		lastCPUThrottledValue, ok := c.lastContainerCPUThrottled.Get(containerStat.ContainerID)
 		c.lastContainerCPUThrottled.Set(containerStat.ContainerID, currentCPUStat, gocache.DefaultExpiration)
-		klog.V(6).Infof("last container cpu stat size in pod throttled collector cache %v", c.lastContainerCPUThrottled.ItemCount())
+		logger.V(6).Infof("last container cpu stat size in pod throttled collector cache %v", c.lastContainerCPUThrottled.ItemCount())
 		if !ok {
-			klog.V(6).Infof("collect container %s/%s/%s cpu throttled first point",
+			logger.V(6).Infof("collect container %s/%s/%s cpu throttled first point",
 				pod.Namespace, pod.Name, containerStat.Name)
 			continue
 		}
@@ -177,9 +179,9 @@ func (c *podThrottledCollector) collectContainerThrottledInfo(podMeta *statesinf
 		}
 		err = c.metricDB.InsertContainerThrottledMetrics(collectTime, containerMetric)
 		if err != nil {
-			klog.Warningf("insert container throttled metrics failed, err %v", err)
+			logger.Warningf("insert container throttled metrics failed, err %v", err)
 		}
 	} // end for container status
-	klog.V(5).Infof("collectContainerThrottledInfo for pod %s/%s finished, container num %d",
+	logger.V(5).Infof("collectContainerThrottledInfo for pod %s/%s finished, container num %d",
 		pod.Namespace, pod.Name, len(pod.Status.ContainerStatuses))
 }
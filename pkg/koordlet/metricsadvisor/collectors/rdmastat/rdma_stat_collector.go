@@ -0,0 +1,227 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdmastat
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/atomic"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const (
+	CollectorName = "RDMAStatCollector"
+)
+
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
+// rdmaStatCollector collects the traffic and congestion counters of the RDMA device ports, and attributes the
+// counters of the SR-IOV virtual functions to the pods they are allocated to according to the device allocation
+// annotation. The physical functions are shared by the pods, so their counters are only reported for the node.
+// The rates of the pods are also stored in the metric cache for the interference detection.
+type rdmaStatCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	statesInformer  statesinformer.StatesInformer
+	metricCache     metriccache.MetricCache
+	// lastSamples are the counters collected in the last round by the device and port, used to calculate the rates.
+	lastSamples    map[string]*system.RDMAPortCounters
+	lastSampleTime time.Time
+}
+
+func New(opt *framework.Options) framework.Collector {
+	return &rdmaStatCollector{
+		collectInterval: time.Duration(opt.Config.RDMAStatCollectorIntervalSeconds) * time.Second,
+		started:         atomic.NewBool(false),
+		statesInformer:  opt.StatesInformer,
+		metricCache:     opt.MetricCache,
+		lastSamples:     map[string]*system.RDMAPortCounters{},
+	}
+}
+
+func (r *rdmaStatCollector) Enabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.RDMAStatCollector)
+}
+
+func (r *rdmaStatCollector) Setup(c *framework.Context) {}
+
+func (r *rdmaStatCollector) Run(stopCh <-chan struct{}) {
+	if !cache.WaitForCacheSync(stopCh, r.statesInformer.HasSynced) {
+		// Koordlet exit because of statesInformer sync failed.
+		logger.Fatalf("timed out waiting for states informer caches to sync")
+	}
+	wait.Until(r.collectRDMAStat, r.collectInterval, stopCh)
+}
+
+func (r *rdmaStatCollector) Started() bool {
+	return r.started.Load()
+}
+
+func (r *rdmaStatCollector) collectRDMAStat() {
+	logger.V(6).Info("start collectRDMAStat")
+	counters, err := system.GetRDMAPortCounters()
+	if err != nil {
+		logger.Warningf("failed to collect rdma port counters, err: %v", err)
+		return
+	}
+	now := time.Now()
+	rates := r.calcRates(counters, now)
+
+	metrics.ResetRDMAStat()
+	countersByBusID := map[string][]*system.RDMAPortCounters{}
+	for _, c := range counters {
+		for field, v := range getCounterFields(c) {
+			metrics.RecordNodeRDMAStat(c.Device, c.Port, field, v)
+		}
+		for field, v := range rates[getSampleKey(c)] {
+			metrics.RecordNodeRDMAStatRate(c.Device, c.Port, field, v)
+		}
+		if c.BusID != "" {
+			countersByBusID[c.BusID] = append(countersByBusID[c.BusID], c)
+		}
+	}
+
+	podMetas := r.statesInformer.GetAllPods()
+	for _, meta := range podMetas {
+		pod := meta.Pod
+		var podRates map[string]float64
+		for _, busID := range getPodRDMAVFBusIDs(pod, r.statesInformer.GetPodDeviceAllocation) {
+			for _, c := range countersByBusID[busID] {
+				for field, v := range getCounterFields(c) {
+					metrics.RecordPodRDMAStat(pod, c.Device, c.Port, field, v)
+				}
+				portRates, ok := rates[getSampleKey(c)]
+				if !ok {
+					continue
+				}
+				if podRates == nil {
+					podRates = map[string]float64{}
+				}
+				for field, v := range portRates {
+					metrics.RecordPodRDMAStatRate(pod, c.Device, c.Port, field, v)
+					podRates[field] += v
+				}
+			}
+		}
+		if podRates == nil {
+			continue
+		}
+		podMetric := &metriccache.PodInterferenceMetric{
+			MetricName: metriccache.MetricNamePodRDMAStat,
+			PodUID:     string(pod.UID),
+			MetricValue: &metriccache.RDMAStatMetric{
+				RxBytesPerSecond:    podRates[metrics.RDMAStatRxBytes],
+				TxBytesPerSecond:    podRates[metrics.RDMAStatTxBytes],
+				CNPSentPerSecond:    podRates[metrics.RDMAStatCNPSent],
+				CNPHandledPerSecond: podRates[metrics.RDMAStatCNPHandled],
+				ECNMarkedPerSecond:  podRates[metrics.RDMAStatECNMarked],
+			},
+		}
+		if err := r.metricCache.InsertPodInterferenceMetrics(now, podMetric); err != nil {
+			logger.Errorf("insert pod %s/%s rdma stat metrics failed, err %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	r.started.Store(true)
+	logger.V(5).Infof("collectRDMAStat finished, rdma port num %d, pod num %d", len(counters), len(podMetas))
+}
+
+// calcRates returns the per-second rates of the counters since the last round by the device and port, and replaces
+// the last samples with the given counters. The counters reset or firstly seen have no rates.
+func (r *rdmaStatCollector) calcRates(counters []*system.RDMAPortCounters, now time.Time) map[string]map[string]float64 {
+	rates := map[string]map[string]float64{}
+	seconds := now.Sub(r.lastSampleTime).Seconds()
+	samples := make(map[string]*system.RDMAPortCounters, len(counters))
+	for _, c := range counters {
+		key := getSampleKey(c)
+		samples[key] = c
+		last, ok := r.lastSamples[key]
+		if !ok || seconds <= 0 {
+			continue
+		}
+		lastFields := getCounterFields(last)
+		fieldRates := map[string]float64{}
+		for field, v := range getCounterFields(c) {
+			if v < lastFields[field] {
+				fieldRates = nil
+				break
+			}
+			fieldRates[field] = (v - lastFields[field]) / seconds
+		}
+		if fieldRates != nil {
+			rates[key] = fieldRates
+		}
+	}
+	r.lastSamples = samples
+	r.lastSampleTime = now
+	return rates
+}
+
+func getSampleKey(c *system.RDMAPortCounters) string {
+	return c.Device + "/" + c.Port
+}
+
+func getCounterFields(c *system.RDMAPortCounters) map[string]float64 {
+	return map[string]float64{
+		metrics.RDMAStatRxBytes:    float64(c.RxBytes),
+		metrics.RDMAStatTxBytes:    float64(c.TxBytes),
+		metrics.RDMAStatCNPSent:    float64(c.CNPSent),
+		metrics.RDMAStatCNPHandled: float64(c.CNPHandled),
+		metrics.RDMAStatECNMarked:  float64(c.ECNMarkedPackets),
+	}
+}
+
+// getPodRDMAVFBusIDs returns the PCIe bus ids of the RDMA virtual functions allocated to the pod.
+func getPodRDMAVFBusIDs(pod *corev1.Pod, getter apiext.PodDeviceAllocationGetter) []string {
+	if pod == nil {
+		return nil
+	}
+	allocations, err := apiext.ResolveDeviceAllocations(pod.Namespace, pod.UID, pod.Annotations, getter)
+	if err != nil {
+		logger.V(4).Infof("failed to get device allocations of pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
+		return nil
+	}
+	var busIDs []string
+	for _, allocation := range allocations[schedulingv1alpha1.RDMA] {
+		extension, err := apiext.GetDeviceAllocationExtension(allocation)
+		if err != nil {
+			logger.V(4).Infof("failed to get rdma allocation extension of pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+		if extension == nil {
+			continue
+		}
+		for _, vf := range extension.VirtualFunctions {
+			if vf.BusID != "" {
+				busIDs = append(busIDs, strings.ToLower(vf.BusID))
+			}
+		}
+	}
+	return busIDs
+}
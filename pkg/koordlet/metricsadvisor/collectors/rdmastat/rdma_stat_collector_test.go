@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdmastat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_getPodRDMAVFBusIDs(t *testing.T) {
+	getter := func(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error) {
+		return &schedulingv1alpha1.PodDeviceAllocation{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: schedulingv1alpha1.PodDeviceAllocationSpec{
+				Devices: []schedulingv1alpha1.PodDeviceAllocationItem{
+					{
+						Type:      schedulingv1alpha1.RDMA,
+						Minor:     0,
+						Extension: &runtime.RawExtension{Raw: []byte(`{"vfs":[{"minor":1,"busID":"0000:AF:00.2"}]}`)},
+					},
+				},
+			},
+		}, nil
+	}
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{
+			name: "no device allocated",
+		},
+		{
+			name: "rdma vfs in PodDeviceAllocation",
+			annotations: map[string]string{
+				apiext.AnnotationDeviceAllocationRef: "test",
+			},
+			want: []string{"0000:af:00.2"},
+		},
+		{
+			name: "invalid device allocations",
+			annotations: map[string]string{
+				apiext.AnnotationDeviceAllocated: "invalid",
+			},
+		},
+		{
+			name: "rdma devices without vfs",
+			annotations: map[string]string{
+				apiext.AnnotationDeviceAllocated: `{"rdma":[{"minor":0,"resources":{"koordinator.sh/rdma":100}}]}`,
+			},
+		},
+		{
+			name: "rdma vfs",
+			annotations: map[string]string{
+				apiext.AnnotationDeviceAllocated: `{"gpu":[{"minor":0,"resources":{"koordinator.sh/gpu-core":100}}],` +
+					`"rdma":[{"minor":0,"resources":{"koordinator.sh/rdma":50},"extension":{"vfs":[{"minor":1,"busID":"0000:3B:00.2"}]}},` +
+					`{"minor":1,"resources":{"koordinator.sh/rdma":50},"extension":{"vfs":[{"minor":1,"busID":"0000:5e:00.2"}]}}]}`,
+			},
+			want: []string{"0000:3b:00.2", "0000:5e:00.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", Annotations: tt.annotations}}
+			assert.Equal(t, tt.want, getPodRDMAVFBusIDs(pod, getter))
+		})
+	}
+}
+
+func Test_rdmaStatCollector_collectRDMAStat(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+	}
+	metrics.Register(testNode)
+	defer metrics.Register(nil)
+
+	writePortCounters := func(device string, rxData, txData, cnpHandled string) {
+		portDir := filepath.Join(system.GetRDMADeviceDir(device), "ports", "1")
+		helper.WriteFileContents(filepath.Join(portDir, "counters", system.RDMAPortRcvDataName), rxData)
+		helper.WriteFileContents(filepath.Join(portDir, "counters", system.RDMAPortXmitDataName), txData)
+		helper.WriteFileContents(filepath.Join(portDir, "hw_counters", system.RDMARPCNPHandledName), cnpHandled)
+	}
+	writePortCounters("mlx5_0", "1000", "2000", "10")
+	writePortCounters("mlx5_2", "100", "200", "1")
+	assert.NoError(t, os.Symlink("../../../0000:3b:00.0", filepath.Join(system.GetRDMADeviceDir("mlx5_0"), "device")))
+	assert.NoError(t, os.Symlink("../../../0000:3b:00.2", filepath.Join(system.GetRDMADeviceDir("mlx5_2"), "device")))
+
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "test",
+			UID:       "xxxxxxxx",
+			Annotations: map[string]string{
+				apiext.AnnotationDeviceAllocated: `{"rdma":[{"minor":0,"resources":{"koordinator.sh/rdma":50},"extension":{"vfs":[{"minor":1,"busID":"0000:3b:00.2"}]}}]}`,
+			},
+		},
+	}
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{Pod: testPod}}).AnyTimes()
+	mockMetricCache := mockmetriccache.NewMockMetricCache(ctrl)
+	var got []*metriccache.RDMAStatMetric
+	mockMetricCache.EXPECT().InsertPodInterferenceMetrics(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ time.Time, metric *metriccache.PodInterferenceMetric) error {
+			assert.Equal(t, metriccache.MetricNamePodRDMAStat, metric.MetricName)
+			assert.Equal(t, string(testPod.UID), metric.PodUID)
+			got = append(got, metric.MetricValue.(*metriccache.RDMAStatMetric))
+			return nil
+		}).AnyTimes()
+
+	c := New(&framework.Options{
+		Config:         &framework.Config{RDMAStatCollectorIntervalSeconds: 10},
+		StatesInformer: statesInformer,
+		MetricCache:    mockMetricCache,
+	}).(*rdmaStatCollector)
+	assert.False(t, c.Enabled())
+	c.collectRDMAStat()
+	assert.True(t, c.Started())
+
+	podLabels := []string{testNode.Name, string(testPod.UID), testPod.Name, testPod.Namespace, "mlx5_2", "1"}
+	assert.Equal(t, float64(4000), testutil.ToFloat64(metrics.NodeRDMAStat.WithLabelValues(testNode.Name, "mlx5_0", "1", metrics.RDMAStatRxBytes)))
+	assert.Equal(t, float64(10), testutil.ToFloat64(metrics.NodeRDMAStat.WithLabelValues(testNode.Name, "mlx5_0", "1", metrics.RDMAStatCNPHandled)))
+	assert.Equal(t, float64(800), testutil.ToFloat64(metrics.PodRDMAStat.WithLabelValues(append(podLabels, metrics.RDMAStatTxBytes)...)))
+	// the physical function is not attributed to the pod
+	assert.Equal(t, 5, testutil.CollectAndCount(metrics.PodRDMAStat))
+	// no rate in the first round
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.PodRDMAStatRate))
+	assert.Empty(t, got)
+
+	// the rates are calculated with the last samples
+	c.lastSampleTime = c.lastSampleTime.Add(-10 * time.Second)
+	writePortCounters("mlx5_2", "350", "200", "21")
+	c.collectRDMAStat()
+	assert.InDelta(t, float64(100), testutil.ToFloat64(metrics.PodRDMAStatRate.WithLabelValues(append(podLabels, metrics.RDMAStatRxBytes)...)), 1)
+	assert.InDelta(t, float64(2), testutil.ToFloat64(metrics.PodRDMAStatRate.WithLabelValues(append(podLabels, metrics.RDMAStatCNPHandled)...)), 0.1)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.NodeRDMAStatRate.WithLabelValues(testNode.Name, "mlx5_0", "1", metrics.RDMAStatRxBytes)))
+	assert.Len(t, got, 1)
+	assert.InDelta(t, float64(100), got[0].RxBytesPerSecond, 1)
+	assert.InDelta(t, float64(2), got[0].CNPHandledPerSecond, 0.1)
+
+	// the counters reset have no rates
+	c.lastSampleTime = c.lastSampleTime.Add(-10 * time.Second)
+	writePortCounters("mlx5_2", "0", "0", "0")
+	c.collectRDMAStat()
+	assert.Equal(t, 5, testutil.CollectAndCount(metrics.PodRDMAStat))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.PodRDMAStatRate))
+	assert.Len(t, got, 1)
+}
@@ -0,0 +1,169 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedlatency
+
+import (
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+)
+
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
+// schedLatencyCollector collects the run queue latency of the pods with the eBPF tracer, which is the real
+// scheduling delay of the pod tasks rather than the cpu utilization, so that the interference of the LS pods can be
+// detected before they are throttled or starved.
+type schedLatencyCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	statesInformer  statesinformer.StatesInformer
+	metricCache     metriccache.MetricCache
+	cgroupReader    resourceexecutor.CgroupReader
+
+	newTracer    func() (schedLatencyTracer, error)
+	tracer       schedLatencyTracer
+	lastReadTime time.Time
+}
+
+func New(opt *framework.Options) framework.Collector {
+	return &schedLatencyCollector{
+		collectInterval: time.Duration(opt.Config.SchedLatencyCollectorIntervalSeconds) * time.Second,
+		started:         atomic.NewBool(false),
+		statesInformer:  opt.StatesInformer,
+		metricCache:     opt.MetricCache,
+		cgroupReader:    opt.CgroupReader,
+		newTracer:       newBPFSchedLatencyTracer,
+	}
+}
+
+func (s *schedLatencyCollector) Enabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.SchedLatencyCollector)
+}
+
+func (s *schedLatencyCollector) Setup(c *framework.Context) {}
+
+func (s *schedLatencyCollector) Run(stopCh <-chan struct{}) {
+	if !cache.WaitForCacheSync(stopCh, s.statesInformer.HasSynced) {
+		// Koordlet exit because of statesInformer sync failed.
+		logger.Fatalf("timed out waiting for states informer caches to sync")
+	}
+	tracer, err := s.newTracer()
+	if err != nil {
+		logger.Errorf("failed to start the sched latency tracer, the collector is disabled, err: %v", err)
+		return
+	}
+	s.tracer = tracer
+	s.lastReadTime = time.Now()
+	wait.Until(s.collectPodSchedLatency, s.collectInterval, stopCh)
+	if err := s.tracer.close(); err != nil {
+		logger.Errorf("failed to stop the sched latency tracer, err: %v", err)
+	}
+}
+
+func (s *schedLatencyCollector) Started() bool {
+	return s.started.Load()
+}
+
+func (s *schedLatencyCollector) collectPodSchedLatency() {
+	logger.V(6).Infof("start collectPodSchedLatency")
+	delays, err := s.tracer.readAndReset()
+	if err != nil {
+		logger.Errorf("failed to read the sched latency of tasks, err: %v", err)
+		return
+	}
+	collectTime := time.Now()
+	interval := collectTime.Sub(s.lastReadTime)
+	s.lastReadTime = collectTime
+	if interval <= 0 {
+		return
+	}
+
+	podMetas := s.statesInformer.GetAllPods()
+	for _, meta := range podMetas {
+		pod := meta.Pod
+		tasks := s.getPodTasks(meta)
+		if tasks == nil {
+			continue
+		}
+		var totalNs, count uint64
+		for _, task := range tasks {
+			if delay, ok := delays[uint32(task)]; ok {
+				totalNs += delay.TotalNs
+				count += delay.Count
+			}
+		}
+		latency := &metriccache.SchedLatencyMetric{
+			DelayRatio: float64(totalNs) / float64(interval.Nanoseconds()),
+		}
+		if count > 0 {
+			latency.AvgLatencyMicroseconds = float64(totalNs) / float64(count) / float64(time.Microsecond)
+		}
+		podMetric := &metriccache.PodInterferenceMetric{
+			MetricName:  metriccache.MetricNamePodSchedLatency,
+			PodUID:      string(pod.UID),
+			MetricValue: latency,
+		}
+		if err := s.metricCache.InsertPodInterferenceMetrics(collectTime, podMetric); err != nil {
+			logger.Errorf("insert pod %s/%s sched latency metrics failed, err %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	s.started.Store(true)
+	logger.V(5).Infof("collectPodSchedLatency finished at %s, pod num %d, task num %d",
+		collectTime, len(podMetas), len(delays))
+}
+
+// getPodTasks returns the tasks of the pod containers, or nil if none of the containers can be read.
+func (s *schedLatencyCollector) getPodTasks(meta *statesinformer.PodMeta) []int32 {
+	pod := meta.Pod
+	var tasks []int32
+	for i := range pod.Status.ContainerStatuses {
+		containerStat := &pod.Status.ContainerStatuses[i]
+		if containerStat.ContainerID == "" || containerStat.State.Running == nil {
+			continue
+		}
+		containerDir, err := koordletutil.GetContainerCgroupPathWithKube(meta.CgroupDir, containerStat)
+		if err != nil {
+			logger.V(4).Infof("failed to get container %s/%s/%s cgroup path, err: %v",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		containerTasks, err := s.cgroupReader.ReadCPUTasks(containerDir)
+		if err != nil {
+			logger.V(4).Infof("failed to read container %s/%s/%s tasks, err: %v",
+				pod.Namespace, pod.Name, containerStat.Name, err)
+			continue
+		}
+		if tasks == nil {
+			tasks = []int32{}
+		}
+		tasks = append(tasks, containerTasks...)
+	}
+	return tasks
+}
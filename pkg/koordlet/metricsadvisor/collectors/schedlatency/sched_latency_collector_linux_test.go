@@ -0,0 +1,200 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedlatency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+type fakeSchedLatencyTracer struct {
+	delays map[uint32]taskDelay
+	closed bool
+}
+
+func (f *fakeSchedLatencyTracer) readAndReset() (map[uint32]taskDelay, error) {
+	delays := f.delays
+	f.delays = map[uint32]taskDelay{}
+	return delays, nil
+}
+
+func (f *fakeSchedLatencyTracer) close() error {
+	f.closed = true
+	return nil
+}
+
+func Test_schedLatencyCollector_collectPodSchedLatency(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	testContainerParentDir := "/kubepods.slice/kubepods-podxxxxxxxx.slice/cri-containerd-123abc.scope"
+	helper.WriteCgroupFileContents(testContainerParentDir, system.CPUTasks, "101\n102\n")
+	testPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			UID:       "xxxxxxxx",
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "test-container",
+					ContainerID: "containerd://123abc",
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				},
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStatesInformer := mockstatesinformer.NewMockStatesInformer(ctrl)
+	mockMetricCache := mockmetriccache.NewMockMetricCache(ctrl)
+	mockStatesInformer.EXPECT().HasSynced().Return(true).AnyTimes()
+	mockStatesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{
+		CgroupDir: "/kubepods-podxxxxxxxx.slice",
+		Pod:       testPod,
+	}}).AnyTimes()
+	var got []*metriccache.SchedLatencyMetric
+	mockMetricCache.EXPECT().InsertPodInterferenceMetrics(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ time.Time, metric *metriccache.PodInterferenceMetric) error {
+			assert.Equal(t, metriccache.MetricNamePodSchedLatency, metric.MetricName)
+			assert.Equal(t, "xxxxxxxx", metric.PodUID)
+			got = append(got, metric.MetricValue.(*metriccache.SchedLatencyMetric))
+			return nil
+		}).Times(2)
+
+	tracer := &fakeSchedLatencyTracer{
+		delays: map[uint32]taskDelay{
+			101: {TotalNs: 2000000, Count: 2},
+			102: {TotalNs: 1000000, Count: 2},
+			// the task not belonging to any pod
+			999: {TotalNs: 9000000, Count: 1},
+		},
+	}
+	collector := New(&framework.Options{
+		Config:         framework.NewDefaultConfig(),
+		StatesInformer: mockStatesInformer,
+		MetricCache:    mockMetricCache,
+		CgroupReader:   resourceexecutor.NewCgroupReader(),
+	})
+	c := collector.(*schedLatencyCollector)
+	c.newTracer = func() (schedLatencyTracer, error) {
+		return tracer, nil
+	}
+	assert.False(t, c.Enabled())
+	c.tracer = tracer
+	c.lastReadTime = time.Now().Add(-time.Second)
+
+	c.collectPodSchedLatency()
+	assert.True(t, c.Started())
+	assert.Len(t, got, 1)
+	assert.Equal(t, float64(750), got[0].AvgLatencyMicroseconds)
+	assert.InDelta(t, 0.003, got[0].DelayRatio, 0.001)
+
+	// the pod without the tasks scheduled in the interval has no latency
+	c.collectPodSchedLatency()
+	assert.Len(t, got, 2)
+	assert.Equal(t, &metriccache.SchedLatencyMetric{}, got[1])
+
+	// the tracer is closed once Run returns
+	stopCh := make(chan struct{})
+	close(stopCh)
+	c.Run(stopCh)
+	assert.True(t, tracer.closed)
+}
+
+func Test_parseTracepointFieldOffset(t *testing.T) {
+	format := `name: sched_switch
+ID: 372
+format:
+	field:unsigned short common_type;	offset:0;	size:2;	signed:0;
+	field:int common_pid;	offset:4;	size:4;	signed:1;
+
+	field:char prev_comm[16];	offset:8;	size:16;	signed:0;
+	field:pid_t prev_pid;	offset:24;	size:4;	signed:1;
+	field:long prev_state;	offset:32;	size:8;	signed:1;
+	field:char next_comm[16];	offset:40;	size:16;	signed:0;
+	field:pid_t next_pid;	offset:56;	size:4;	signed:1;
+	field:int next_prio;	offset:60;	size:4;	signed:1;
+`
+	got, err := parseTracepointFieldOffset(format, "next_pid")
+	assert.NoError(t, err)
+	assert.Equal(t, int16(56), got)
+	got, err = parseTracepointFieldOffset(format, "prev_pid")
+	assert.NoError(t, err)
+	assert.Equal(t, int16(24), got)
+	_, err = parseTracepointFieldOffset(format, "pid")
+	assert.Error(t, err)
+}
+
+func Test_schedLatencyInstructions(t *testing.T) {
+	// the programs are verified by the kernel only if the eBPF maps are allowed to create
+	wakeupMap, err := ebpf.NewMap(&ebpf.MapSpec{Type: ebpf.LRUHash, KeySize: 4, ValueSize: 8, MaxEntries: 16})
+	if err != nil {
+		t.Skipf("eBPF is not supported, err: %v", err)
+	}
+	defer wakeupMap.Close()
+	delayMap, err := ebpf.NewMap(&ebpf.MapSpec{Type: ebpf.LRUHash, KeySize: 4, ValueSize: 16, MaxEntries: 16})
+	assert.NoError(t, err)
+	defer delayMap.Close()
+
+	for name, instructions := range map[string]asm.Instructions{
+		"wakeup": newWakeupInstructions(wakeupMap.FD(), 24),
+		"switch": newSwitchInstructions(wakeupMap.FD(), delayMap.FD(), 56, 24, 32),
+	} {
+		prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+			Type:         ebpf.TracePoint,
+			Instructions: instructions,
+			License:      "GPL",
+		})
+		assert.NoError(t, err, name)
+		if prog != nil {
+			prog.Close()
+		}
+	}
+
+	// the delays are reset after read
+	tracer := &bpfSchedLatencyTracer{wakeupMap: wakeupMap, delayMap: delayMap}
+	assert.NoError(t, delayMap.Put(uint32(101), taskDelay{TotalNs: 1000, Count: 1}))
+	got, err := tracer.readAndReset()
+	assert.NoError(t, err)
+	assert.Equal(t, map[uint32]taskDelay{101: {TotalNs: 1000, Count: 1}}, got)
+	got, err = tracer.readAndReset()
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
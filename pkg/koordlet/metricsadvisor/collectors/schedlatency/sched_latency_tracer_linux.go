@@ -0,0 +1,335 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedlatency
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/link"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// maxTrackedTasks is the max number of the tasks tracked by the eBPF maps, the least recently used ones are
+	// evicted when the maps are full.
+	maxTrackedTasks = 65536
+	// taskReportStateMask masks the task states reported by prev_state of sched_switch. The task switched out with
+	// none of them, i.e. TASK_RUNNING with or without the preemption flag above the mask, is still runnable.
+	taskReportStateMask = 0xff
+)
+
+var (
+	// tracefsDirs are the candidates of the tracefs mount, where the debugfs one is used by the link package.
+	tracefsDirs = []string{"/sys/kernel/debug/tracing", "/sys/kernel/tracing"}
+
+	tracepointFieldRegexp = regexp.MustCompile(`field:[^;]*\s(\w+);\s*offset:(\d+);`)
+)
+
+// taskDelay is the value of the delay map, which must be in the same layout of the one updated by the eBPF program.
+type taskDelay struct {
+	// TotalNs is the total latency of the task waiting on the run queue in nanoseconds.
+	TotalNs uint64
+	// Count is the number of the task switched in after the wakeup or the preemption.
+	Count uint64
+}
+
+// schedLatencyTracer measures the run queue latency of the tasks, i.e. the delay between the wakeup or the preemption
+// and the run.
+type schedLatencyTracer interface {
+	// readAndReset returns the latency of the tasks since the last read, which is keyed by the task id.
+	readAndReset() (map[uint32]taskDelay, error)
+	close() error
+}
+
+// bpfSchedLatencyTracer records the wakeup time of a task on the sched_wakeup and sched_wakeup_new tracepoints,
+// or the time of a runnable task switched out on the sched_switch tracepoint, and accumulates the delay into
+// the delay map when the task is switched in on the sched_switch tracepoint.
+type bpfSchedLatencyTracer struct {
+	wakeupMap *ebpf.Map
+	delayMap  *ebpf.Map
+	programs  []*ebpf.Program
+	links     []link.Link
+}
+
+func newBPFSchedLatencyTracer() (schedLatencyTracer, error) {
+	// the memory of the eBPF objects is charged to the memlock rlimit before kernel 5.11, the creation of the maps
+	// fails later if it is not allowed to remove the limit on the old kernels
+	if err := unix.Setrlimit(unix.RLIMIT_MEMLOCK, &unix.Rlimit{Cur: unix.RLIM_INFINITY, Max: unix.RLIM_INFINITY}); err != nil {
+		logger.V(4).Infof("failed to remove the memlock rlimit, err: %v", err)
+	}
+	tracefsDir, err := getTracefsDir()
+	if err != nil {
+		return nil, err
+	}
+	wakeupPidOffset, err := readTracepointFieldOffset(tracefsDir, "sched_wakeup", "pid")
+	if err != nil {
+		return nil, err
+	}
+	wakeupNewPidOffset, err := readTracepointFieldOffset(tracefsDir, "sched_wakeup_new", "pid")
+	if err != nil {
+		return nil, err
+	}
+	switchPidOffset, err := readTracepointFieldOffset(tracefsDir, "sched_switch", "next_pid")
+	if err != nil {
+		return nil, err
+	}
+	switchPrevPidOffset, err := readTracepointFieldOffset(tracefsDir, "sched_switch", "prev_pid")
+	if err != nil {
+		return nil, err
+	}
+	switchPrevStateOffset, err := readTracepointFieldOffset(tracefsDir, "sched_switch", "prev_state")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &bpfSchedLatencyTracer{}
+	t.wakeupMap, err = ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "koord_wakeup",
+		Type:       ebpf.LRUHash,
+		KeySize:    4,
+		ValueSize:  8,
+		MaxEntries: maxTrackedTasks,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the wakeup map, err: %v", err)
+	}
+	t.delayMap, err = ebpf.NewMap(&ebpf.MapSpec{
+		Name:       "koord_delay",
+		Type:       ebpf.LRUHash,
+		KeySize:    4,
+		ValueSize:  16,
+		MaxEntries: maxTrackedTasks,
+	})
+	if err != nil {
+		t.close()
+		return nil, fmt.Errorf("failed to create the delay map, err: %v", err)
+	}
+
+	attaches := []struct {
+		name         string
+		instructions asm.Instructions
+	}{
+		{name: "sched_wakeup", instructions: newWakeupInstructions(t.wakeupMap.FD(), wakeupPidOffset)},
+		{name: "sched_wakeup_new", instructions: newWakeupInstructions(t.wakeupMap.FD(), wakeupNewPidOffset)},
+		{name: "sched_switch", instructions: newSwitchInstructions(t.wakeupMap.FD(), t.delayMap.FD(), switchPidOffset, switchPrevPidOffset, switchPrevStateOffset)},
+	}
+	for _, a := range attaches {
+		prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+			Type:         ebpf.TracePoint,
+			Instructions: a.instructions,
+			License:      "GPL",
+		})
+		if err != nil {
+			t.close()
+			return nil, fmt.Errorf("failed to load the program of %s, err: %v", a.name, err)
+		}
+		t.programs = append(t.programs, prog)
+		l, err := link.Tracepoint("sched", a.name, prog)
+		if err != nil {
+			t.close()
+			return nil, fmt.Errorf("failed to attach the program to %s, err: %v", a.name, err)
+		}
+		t.links = append(t.links, l)
+	}
+	return t, nil
+}
+
+// newWakeupInstructions returns the program which records the wakeup time of the task:
+//
+//	wakeup[ctx->pid] = ktime_get_ns()
+func newWakeupInstructions(wakeupMapFD int, pidOffset int16) asm.Instructions {
+	return asm.Instructions{
+		asm.LoadMem(asm.R2, asm.R1, pidOffset, asm.Word),
+		asm.StoreMem(asm.RFP, -4, asm.R2, asm.Word),
+		asm.FnKtimeGetNs.Call(),
+		asm.StoreMem(asm.RFP, -16, asm.R0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, wakeupMapFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -16),
+		asm.Mov.Imm(asm.R4, 0), // BPF_ANY
+		asm.FnMapUpdateElem.Call(),
+		asm.Mov.Imm(asm.R0, 0),
+		asm.Return(),
+	}
+}
+
+// newSwitchInstructions returns the program which records the time of the preempted task switched out,
+// and accumulates the latency of the task switched in:
+//
+//	if ctx->prev_state & taskReportStateMask == 0 && ctx->prev_pid != 0 {
+//		wakeup[ctx->prev_pid] = ktime_get_ns()
+//	}
+//	ts = wakeup[ctx->next_pid]
+//	if ts != nil {
+//		delete(wakeup, ctx->next_pid)
+//		delay[ctx->next_pid].TotalNs += ktime_get_ns() - ts
+//		delay[ctx->next_pid].Count += 1
+//	}
+//
+// Only the low word of prev_state is loaded, which holds the reported states on the little-endian machines.
+func newSwitchInstructions(wakeupMapFD, delayMapFD int, pidOffset, prevPidOffset, prevStateOffset int16) asm.Instructions {
+	return asm.Instructions{
+		asm.Mov.Reg(asm.R9, asm.R1),
+		// the preempted task is still runnable, and waits on the run queue until it is switched in again
+		asm.LoadMem(asm.R2, asm.R9, prevStateOffset, asm.Word),
+		asm.And.Imm(asm.R2, taskReportStateMask),
+		asm.JNE.Imm(asm.R2, 0, "next"),
+		asm.LoadMem(asm.R2, asm.R9, prevPidOffset, asm.Word),
+		asm.JEq.Imm(asm.R2, 0, "next"),
+		asm.StoreMem(asm.RFP, -28, asm.R2, asm.Word),
+		asm.FnKtimeGetNs.Call(),
+		asm.StoreMem(asm.RFP, -40, asm.R0, asm.DWord),
+		asm.LoadMapPtr(asm.R1, wakeupMapFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -28),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -40),
+		asm.Mov.Imm(asm.R4, 0), // BPF_ANY
+		asm.FnMapUpdateElem.Call(),
+		asm.LoadMem(asm.R2, asm.R9, pidOffset, asm.Word).Sym("next"),
+		// the idle task is never woken up
+		asm.JEq.Imm(asm.R2, 0, "exit"),
+		asm.StoreMem(asm.RFP, -4, asm.R2, asm.Word),
+		asm.LoadMapPtr(asm.R1, wakeupMapFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "exit"),
+		asm.LoadMem(asm.R6, asm.R0, 0, asm.DWord),
+		asm.FnKtimeGetNs.Call(),
+		asm.Sub.Reg(asm.R0, asm.R6),
+		asm.Mov.Reg(asm.R7, asm.R0),
+		asm.LoadMapPtr(asm.R1, wakeupMapFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.FnMapDeleteElem.Call(),
+		asm.LoadMapPtr(asm.R1, delayMapFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.FnMapLookupElem.Call(),
+		asm.JEq.Imm(asm.R0, 0, "init"),
+		asm.StoreXAdd(asm.R0, asm.R7, asm.DWord),
+		asm.Mov.Imm(asm.R1, 1),
+		asm.Add.Imm(asm.R0, 8),
+		asm.StoreXAdd(asm.R0, asm.R1, asm.DWord),
+		asm.Ja.Label("exit"),
+		asm.StoreMem(asm.RFP, -24, asm.R7, asm.DWord).Sym("init"),
+		asm.StoreImm(asm.RFP, -16, 1, asm.DWord),
+		asm.LoadMapPtr(asm.R1, delayMapFD),
+		asm.Mov.Reg(asm.R2, asm.RFP),
+		asm.Add.Imm(asm.R2, -4),
+		asm.Mov.Reg(asm.R3, asm.RFP),
+		asm.Add.Imm(asm.R3, -24),
+		asm.Mov.Imm(asm.R4, 1), // BPF_NOEXIST
+		asm.FnMapUpdateElem.Call(),
+		asm.Mov.Imm(asm.R0, 0).Sym("exit"),
+		asm.Return(),
+	}
+}
+
+// readAndReset iterates the delay map and deletes the entries read, so that each read returns the latency since
+// the last one. The latency accumulated between the lookup and the deletion of an entry is dropped.
+func (t *bpfSchedLatencyTracer) readAndReset() (map[uint32]taskDelay, error) {
+	delays := map[uint32]taskDelay{}
+	var key uint32
+	var value taskDelay
+	iter := t.delayMap.Iterate()
+	for iter.Next(&key, &value) {
+		delays[key] = value
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate the delay map, err: %v", err)
+	}
+	for pid := range delays {
+		if err := t.delayMap.Delete(pid); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return nil, fmt.Errorf("failed to delete the task %d from the delay map, err: %v", pid, err)
+		}
+	}
+	return delays, nil
+}
+
+func (t *bpfSchedLatencyTracer) close() error {
+	var errs []error
+	for _, l := range t.links {
+		if err := l.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, prog := range t.programs {
+		if err := prog.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, m := range []*ebpf.Map{t.wakeupMap, t.delayMap} {
+		if m == nil {
+			continue
+		}
+		if err := m.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to close the sched latency tracer, errs: %v", errs)
+	}
+	return nil
+}
+
+func getTracefsDir() (string, error) {
+	for _, dir := range tracefsDirs {
+		if _, err := os.Stat(filepath.Join(dir, "events")); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("tracefs is not mounted in any of %v", tracefsDirs)
+}
+
+func readTracepointFieldOffset(tracefsDir, event, field string) (int16, error) {
+	content, err := os.ReadFile(filepath.Join(tracefsDir, "events", "sched", event, "format"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the format of tracepoint %s, err: %v", event, err)
+	}
+	return parseTracepointFieldOffset(string(content), field)
+}
+
+// parseTracepointFieldOffset returns the offset of the field in the tracepoint format, e.g.
+//
+//	field:pid_t pid;	offset:24;	size:4;	signed:1;
+func parseTracepointFieldOffset(format, field string) (int16, error) {
+	for _, match := range tracepointFieldRegexp.FindAllStringSubmatch(format, -1) {
+		if match[1] != field {
+			continue
+		}
+		offset, err := strconv.ParseInt(match[2], 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse the offset of field %s, err: %v", field, err)
+		}
+		return int16(offset), nil
+	}
+	return 0, fmt.Errorf("field %s not found in the tracepoint format", field)
+}
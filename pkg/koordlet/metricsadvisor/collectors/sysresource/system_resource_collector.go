@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysresource
+
+import (
+	"strings"
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+)
+
+const (
+	CollectorName = "SystemResourceCollector"
+)
+
+var logger = logs.NewLogger(logs.ModuleMetricsAdvisor)
+
+// systemResourceCollector collects the resource usage of the system cgroups (e.g. kubelet, container runtime and
+// system daemons), so that the system overhead can be reported separately from the pods' usage.
+type systemResourceCollector struct {
+	collectInterval time.Duration
+	started         *atomic.Bool
+	metricDB        metriccache.MetricCache
+	cgroupReader    resourceexecutor.CgroupReader
+	cgroupDirs      []string
+
+	lastSystemCPUStat *framework.CPUStat
+}
+
+func New(opt *framework.Options) framework.Collector {
+	return &systemResourceCollector{
+		collectInterval: time.Duration(opt.Config.CollectResUsedIntervalSeconds) * time.Second,
+		started:         atomic.NewBool(false),
+		metricDB:        opt.MetricCache,
+		cgroupReader:    opt.CgroupReader,
+		cgroupDirs:      parseCgroupDirs(opt.Config.SystemCgroupDirs),
+	}
+}
+
+func (s *systemResourceCollector) Enabled() bool {
+	return len(s.cgroupDirs) > 0
+}
+
+func (s *systemResourceCollector) Setup(c *framework.Context) {
+	return
+}
+
+func (s *systemResourceCollector) Run(stopCh <-chan struct{}) {
+	wait.Until(s.collectSystemResourceMetric, s.collectInterval, stopCh)
+}
+
+func (s *systemResourceCollector) Started() bool {
+	return s.started.Load()
+}
+
+func (s *systemResourceCollector) collectSystemResourceMetric() {
+	logger.V(6).Info("collectSystemResourceMetric start")
+
+	cpuUsageCores, err := s.getSystemCPUUsageCores()
+	if err != nil {
+		logger.Errorf("getSystemCPUUsageCores failed, error: %v", err)
+		return
+	}
+	if cpuUsageCores == nil {
+		logger.V(6).Info("systemCPUUsageCores is nil")
+		return
+	}
+
+	memoryUsed, err := s.getSystemMemoryUsage()
+	if err != nil {
+		logger.Errorf("getSystemMemoryUsage failed, error: %v", err)
+		return
+	}
+
+	systemMetric := metriccache.SystemResourceMetric{
+		CPUUsed: metriccache.CPUMetric{
+			CPUUsed: *cpuUsageCores,
+		},
+		MemoryUsed: metriccache.MemoryMetric{
+			MemoryWithoutCache: *memoryUsed,
+		},
+	}
+
+	collectTime := time.Now()
+	err = s.metricDB.InsertSystemResourceMetric(collectTime, &systemMetric)
+	if err != nil {
+		logger.Errorf("InsertSystemResourceMetric failed, error: %v", err)
+		return
+	}
+	s.started.Store(true)
+	logger.V(6).Info("collectSystemResourceMetric finished")
+}
+
+func (s *systemResourceCollector) getSystemCPUUsageCores() (*resource.Quantity, error) {
+	collectTime := time.Now()
+	currentCPUUsage := uint64(0)
+	for _, dir := range s.cgroupDirs {
+		usage, err := s.cgroupReader.ReadCPUAcctUsage(dir)
+		if err != nil {
+			return nil, err
+		}
+		currentCPUUsage += usage
+	}
+
+	lastCPUStat := s.lastSystemCPUStat
+	s.lastSystemCPUStat = &framework.CPUStat{
+		CPUUsage:  currentCPUUsage,
+		Timestamp: collectTime,
+	}
+
+	if lastCPUStat == nil {
+		logger.V(6).Infof("ignore the first cpu stat collection")
+		return nil, nil
+	}
+	// the cgroups may be recreated, e.g. the system slice is reloaded
+	if currentCPUUsage < lastCPUStat.CPUUsage {
+		logger.V(6).Infof("ignore the cpu stat collection since the usage decreased")
+		return nil, nil
+	}
+
+	// NOTICE: do subtraction and division first to avoid overflow
+	cpuUsageValue := float64(currentCPUUsage-lastCPUStat.CPUUsage) / float64(collectTime.Sub(lastCPUStat.Timestamp))
+	// 1.0 CPU = 1000 Milli-CPU
+	return resource.NewMilliQuantity(int64(cpuUsageValue*1000), resource.DecimalSI), nil
+}
+
+func (s *systemResourceCollector) getSystemMemoryUsage() (*resource.Quantity, error) {
+	memoryUsage := int64(0)
+	for _, dir := range s.cgroupDirs {
+		memStat, err := s.cgroupReader.ReadMemoryStat(dir)
+		if err != nil {
+			return nil, err
+		}
+		memoryUsage += memStat.Usage()
+	}
+	return resource.NewQuantity(memoryUsage, resource.BinarySI), nil
+}
+
+func parseCgroupDirs(dirs string) []string {
+	var result []string
+	for _, dir := range strings.Split(dirs, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
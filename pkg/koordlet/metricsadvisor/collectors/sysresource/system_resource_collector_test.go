@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysresource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+const testMemoryStat = `
+total_cache 104857600
+total_rss 104857600
+total_inactive_anon 104857600
+total_active_anon 0
+total_inactive_file 104857600
+total_active_file 0
+total_unevictable 0
+`
+
+func Test_collectSystemResourceMetric(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.SetCgroupsV2(false)
+	helper.WriteCgroupFileContents("system.slice/", system.CPUAcctUsage, "12002000000000")
+	helper.WriteCgroupFileContents("system.slice/", system.MemoryStat, testMemoryStat)
+	helper.WriteCgroupFileContents("kubelet.slice/", system.CPUAcctUsage, "1000000000")
+	helper.WriteCgroupFileContents("kubelet.slice/", system.MemoryStat, testMemoryStat)
+
+	metricCache, err := metriccache.NewMetricCache(metriccache.NewDefaultConfig())
+	assert.NoError(t, err)
+	collector := &systemResourceCollector{
+		started:      atomic.NewBool(false),
+		metricDB:     metricCache,
+		cgroupReader: resourceexecutor.NewCgroupReader(),
+		cgroupDirs:   []string{"system.slice/", "kubelet.slice/"},
+		lastSystemCPUStat: &framework.CPUStat{
+			CPUUsage:  12000000000000,
+			Timestamp: time.Now().Add(-1 * time.Second),
+		},
+	}
+
+	collector.collectSystemResourceMetric()
+	assert.True(t, collector.Started())
+
+	oldStartTime := time.Unix(0, 0)
+	now := time.Now()
+	got := metricCache.GetSystemResourceMetric(&metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeLast,
+		Start:     &oldStartTime,
+		End:       &now,
+	})
+	assert.NoError(t, got.Error)
+	// (2s + 1s) cpu time in about 1s
+	assert.Equal(t, int64(3), got.Metric.CPUUsed.CPUUsed.Value())
+	assert.Equal(t, int64(2*104857600), got.Metric.MemoryUsed.MemoryWithoutCache.Value())
+}
+
+func Test_getSystemCPUUsageCores(t *testing.T) {
+	tests := []struct {
+		name          string
+		cpuacctUsage  string
+		lastCPUStat   *framework.CPUStat
+		wantNil       bool
+		wantCores     int64
+		wantLastUsage uint64
+		wantErr       bool
+	}{
+		{
+			name:          "ignore the first collection",
+			cpuacctUsage:  "12000000000000\n",
+			wantNil:       true,
+			wantLastUsage: 12000000000000,
+		},
+		{
+			name:          "calculate usage",
+			cpuacctUsage:  "12004000000000\n",
+			lastCPUStat:   &framework.CPUStat{CPUUsage: 12000000000000},
+			wantCores:     4,
+			wantLastUsage: 12004000000000,
+		},
+		{
+			name:          "ignore decreased usage",
+			cpuacctUsage:  "1000\n",
+			lastCPUStat:   &framework.CPUStat{CPUUsage: 12000000000000},
+			wantNil:       true,
+			wantLastUsage: 1000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(false)
+			helper.WriteCgroupFileContents("system.slice/", system.CPUAcctUsage, tt.cpuacctUsage)
+
+			collector := &systemResourceCollector{
+				started:      atomic.NewBool(false),
+				cgroupReader: resourceexecutor.NewCgroupReader(),
+				cgroupDirs:   []string{"system.slice/"},
+			}
+			if tt.lastCPUStat != nil {
+				collector.lastSystemCPUStat = tt.lastCPUStat
+				collector.lastSystemCPUStat.Timestamp = time.Now().Add(-1 * time.Second)
+			}
+			got, err := collector.getSystemCPUUsageCores()
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantNil, got == nil)
+			if !tt.wantNil {
+				assert.Equal(t, tt.wantCores, got.Value())
+			}
+			assert.Equal(t, tt.wantLastUsage, collector.lastSystemCPUStat.CPUUsage)
+		})
+	}
+}
+
+func Test_parseCgroupDirs(t *testing.T) {
+	assert.Nil(t, parseCgroupDirs(""))
+	assert.Equal(t, []string{"system.slice/", "kubelet/"}, parseCgroupDirs(" system.slice/, ,kubelet/"))
+}
+
+func Test_systemResourceCollector_Run(t *testing.T) {
+	metricCache, _ := metriccache.NewMetricCache(metriccache.NewDefaultConfig())
+	c := New(&framework.Options{
+		Config:       framework.NewDefaultConfig(),
+		MetricCache:  metricCache,
+		CgroupReader: resourceexecutor.NewCgroupReader(),
+	})
+	collector := c.(*systemResourceCollector)
+	collector.started = atomic.NewBool(true)
+	collector.Setup(&framework.Context{})
+	assert.True(t, collector.Enabled())
+	assert.True(t, collector.Started())
+	assert.NotPanics(t, func() {
+		stopCh := make(chan struct{})
+		close(stopCh)
+		collector.Run(stopCh)
+	})
+}
@@ -60,7 +60,7 @@ func (g *gpuCollector) Setup(fra *framework.Context) {
 }
 
 func (g *gpuCollector) Run(stopCh <-chan struct{}) {
-	go wait.Until(g.gpuDeviceManager.collectGPUUsage, g.collectInterval, stopCh)
+	wait.Until(g.gpuDeviceManager.collectGPUUsage, g.collectInterval, stopCh)
 }
 
 func (g *gpuCollector) Started() bool {
@@ -19,7 +19,6 @@ package gpu
 import (
 	"errors"
 	"fmt"
-	"sort"
 	"sync"
 	"time"
 
@@ -40,6 +39,7 @@ type gpuDeviceManager struct {
 	devices          []*device
 	collectTime      time.Time
 	start            *atomic.Bool
+	devicesMetrics   []*rawDeviceMetric
 	processesMetrics map[uint32][]*rawGPUMetric
 }
 
@@ -48,6 +48,12 @@ type rawGPUMetric struct {
 	MemoryUsed uint64
 }
 
+// rawDeviceMetric is the status of the whole device, which also counts the processes running out of the pods.
+type rawDeviceMetric struct {
+	SMUtil      uint32 // utilization rate of the device during the last sample period
+	Temperature uint32 // current core temperature of the device, in degrees C
+}
+
 type device struct {
 	Minor       int32 // index starting from 0
 	DeviceUUID  string
@@ -154,10 +160,22 @@ func (g *gpuDeviceManager) getNodeGPUUsage() []metriccache.GPUMetric {
 			MemoryUsed:  *resource.NewQuantity(int64(tmp[i].MemoryUsed), resource.BinarySI),
 			MemoryTotal: *resource.NewQuantity(int64(g.devices[i].MemoryTotal), resource.BinarySI),
 		}
+		// prefer the device utilization, since the processes out of the pods are invisible in the process metrics
+		if deviceMetric := g.getDeviceMetric(i); deviceMetric != nil {
+			rtn[i].SMUtil = deviceMetric.SMUtil
+			rtn[i].Temperature = deviceMetric.Temperature
+		}
 	}
 	return rtn
 }
 
+func (g *gpuDeviceManager) getDeviceMetric(idx int) *rawDeviceMetric {
+	if idx >= len(g.devicesMetrics) {
+		return nil
+	}
+	return g.devicesMetrics[idx]
+}
+
 func (g *gpuDeviceManager) getTotalGPUUsageOfPIDs(pids []uint32) []metriccache.GPUMetric {
 	g.RLock()
 	defer g.RUnlock()
@@ -182,13 +200,17 @@ func (g *gpuDeviceManager) getTotalGPUUsageOfPIDs(pids []uint32) []metriccache.G
 	rtn := make([]metriccache.GPUMetric, 0)
 	for i := 0; i < g.deviceCount; i++ {
 		if value, ok := tmp[i]; ok {
-			rtn = append(rtn, metriccache.GPUMetric{
+			metric := metriccache.GPUMetric{
 				DeviceUUID:  g.devices[i].DeviceUUID,
 				Minor:       g.devices[i].Minor,
 				SMUtil:      value.SMUtil,
 				MemoryUsed:  *resource.NewQuantity(int64(value.MemoryUsed), resource.BinarySI),
 				MemoryTotal: *resource.NewQuantity(int64(g.devices[i].MemoryTotal), resource.BinarySI),
-			})
+			}
+			if deviceMetric := g.getDeviceMetric(i); deviceMetric != nil {
+				metric.Temperature = deviceMetric.Temperature
+			}
+			rtn = append(rtn, metric)
 		}
 	}
 	return rtn
@@ -226,47 +248,66 @@ func (g *gpuDeviceManager) getContainerGPUUsage(podParentDir string, c *corev1.C
 }
 
 func (g *gpuDeviceManager) collectGPUUsage() {
+	devicesMetrics := make([]*rawDeviceMetric, len(g.devices))
 	processesGPUUsages := make(map[uint32][]*rawGPUMetric)
 	for deviceIndex, gpuDevice := range g.devices {
+		devicesMetrics[deviceIndex] = collectDeviceMetric(deviceIndex, gpuDevice.Device)
+
 		processesInfos, ret := gpuDevice.Device.GetComputeRunningProcesses()
 		if ret != nvml.SUCCESS {
 			klog.Warningf("Unable to get process info for device at index %d: %v", deviceIndex, nvml.ErrorString(ret))
 			continue
 		}
 		processUtilizations, ret := gpuDevice.Device.GetProcessUtilization(1024)
-		if ret != nvml.SUCCESS {
+		if ret != nvml.SUCCESS && ret != nvml.ERROR_NOT_FOUND {
 			klog.Warningf("Unable to get process utilization for device at index %d: %v", deviceIndex, nvml.ErrorString(ret))
 			continue
 		}
-
-		// Sort by pid.
-		sort.Slice(processesInfos, func(i, j int) bool {
-			return processesInfos[i].Pid < processesInfos[j].Pid
-		})
-		sort.Slice(processUtilizations, func(i, j int) bool {
-			return processUtilizations[i].Pid < processUtilizations[j].Pid
-		})
+		// the processes without a utilization sample during the sample period, e.g. the idle ones, are not returned,
+		// so the utilizations are matched by the pid rather than the position.
+		processSMUtils := make(map[uint32]uint32, len(processUtilizations))
+		for _, utilization := range processUtilizations {
+			processSMUtils[utilization.Pid] = utilization.SmUtil
+		}
 
 		klog.V(3).Infof("Found %d processes on device %d\n", len(processesInfos), deviceIndex)
-		for idx, info := range processesInfos {
+		for _, info := range processesInfos {
 			if _, ok := processesGPUUsages[info.Pid]; !ok {
 				// pid not exist.
 				// init processes gpu metric array.
 				processesGPUUsages[info.Pid] = make([]*rawGPUMetric, g.deviceCount)
 			}
 			processesGPUUsages[info.Pid][deviceIndex] = &rawGPUMetric{
-				SMUtil:     processUtilizations[idx].SmUtil,
+				SMUtil:     processSMUtils[info.Pid],
 				MemoryUsed: info.UsedGpuMemory,
 			}
 		}
 	}
 	g.Lock()
+	g.devicesMetrics = devicesMetrics
 	g.processesMetrics = processesGPUUsages
 	g.collectTime = time.Now()
 	g.start.Store(true)
 	g.Unlock()
 }
 
+func collectDeviceMetric(deviceIndex int, gpuDevice nvml.Device) *rawDeviceMetric {
+	utilization, ret := gpuDevice.GetUtilizationRates()
+	if ret != nvml.SUCCESS {
+		klog.Warningf("Unable to get utilization for device at index %d: %v", deviceIndex, nvml.ErrorString(ret))
+		return nil
+	}
+	temperature, ret := gpuDevice.GetTemperature(nvml.TEMPERATURE_GPU)
+	if ret != nvml.SUCCESS {
+		klog.Warningf("Unable to get temperature for device at index %d: %v", deviceIndex, nvml.ErrorString(ret))
+		return nil
+	}
+	return &rawDeviceMetric{
+		SMUtil:      utilization.Gpu,
+		Temperature: temperature,
+	}
+}
+
 func (g *gpuDeviceManager) started() bool {
 	return g.start.Load()
 }
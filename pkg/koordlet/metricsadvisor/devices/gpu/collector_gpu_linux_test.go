@@ -35,6 +35,7 @@ func Test_gpuUsageDetailRecord_GetNodeGPUUsage(t *testing.T) {
 	type fields struct {
 		deviceCount      int
 		devices          []*device
+		devicesMetrics   []*rawDeviceMetric
 		processesMetrics map[uint32][]*rawGPUMetric
 	}
 	tests := []struct {
@@ -123,12 +124,47 @@ func Test_gpuUsageDetailRecord_GetNodeGPUUsage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "device metrics",
+			fields: fields{
+				deviceCount: 2,
+				devices: []*device{
+					{Minor: 0, DeviceUUID: "test-device1", MemoryTotal: 8000},
+					{Minor: 1, DeviceUUID: "test-device2", MemoryTotal: 9000},
+				},
+				devicesMetrics: []*rawDeviceMetric{
+					{SMUtil: 95, Temperature: 65},
+					nil,
+				},
+				processesMetrics: map[uint32][]*rawGPUMetric{
+					122: {{SMUtil: 70, MemoryUsed: 1500}, {SMUtil: 30, MemoryUsed: 1000}},
+				},
+			},
+			want: []metriccache.GPUMetric{
+				{
+					DeviceUUID:  "test-device1",
+					Minor:       0,
+					SMUtil:      95,
+					MemoryUsed:  *resource.NewQuantity(1500, resource.BinarySI),
+					MemoryTotal: *resource.NewQuantity(8000, resource.BinarySI),
+					Temperature: 65,
+				},
+				{
+					DeviceUUID:  "test-device2",
+					Minor:       1,
+					SMUtil:      30,
+					MemoryUsed:  *resource.NewQuantity(1000, resource.BinarySI),
+					MemoryTotal: *resource.NewQuantity(9000, resource.BinarySI),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &gpuDeviceManager{
 				deviceCount:      tt.fields.deviceCount,
 				devices:          tt.fields.devices,
+				devicesMetrics:   tt.fields.devicesMetrics,
 				processesMetrics: tt.fields.processesMetrics,
 			}
 			if got := g.getNodeGPUUsage(); !reflect.DeepEqual(got, tt.want) {
@@ -142,6 +178,7 @@ func Test_gpuUsageDetailRecord_GetPIDsTotalGPUUsage(t *testing.T) {
 	type fields struct {
 		deviceCount      int
 		devices          []*device
+		devicesMetrics   []*rawDeviceMetric
 		processesMetrics map[uint32][]*rawGPUMetric
 	}
 	type args struct {
@@ -270,12 +307,44 @@ func Test_gpuUsageDetailRecord_GetPIDsTotalGPUUsage(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "device temperature",
+			args: args{
+				pids: []uint32{122},
+			},
+			fields: fields{
+				deviceCount: 2,
+				devices: []*device{
+					{Minor: 0, DeviceUUID: "test-device1", MemoryTotal: 14000},
+					{Minor: 1, DeviceUUID: "test-device2", MemoryTotal: 24000},
+				},
+				devicesMetrics: []*rawDeviceMetric{
+					{SMUtil: 95, Temperature: 65},
+					{SMUtil: 50, Temperature: 55},
+				},
+				processesMetrics: map[uint32][]*rawGPUMetric{
+					122: {{SMUtil: 70, MemoryUsed: 1500}, nil},
+					222: {{SMUtil: 25, MemoryUsed: 1000}, {SMUtil: 50, MemoryUsed: 1000}},
+				},
+			},
+			want: []metriccache.GPUMetric{
+				{
+					DeviceUUID:  "test-device1",
+					Minor:       0,
+					SMUtil:      70,
+					MemoryUsed:  *resource.NewQuantity(1500, resource.BinarySI),
+					MemoryTotal: *resource.NewQuantity(14000, resource.BinarySI),
+					Temperature: 65,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &gpuDeviceManager{
 				deviceCount:      tt.fields.deviceCount,
 				devices:          tt.fields.devices,
+				devicesMetrics:   tt.fields.devicesMetrics,
 				processesMetrics: tt.fields.processesMetrics,
 			}
 			if got := g.getTotalGPUUsageOfPIDs(tt.args.pids); !reflect.DeepEqual(got, tt.want) {
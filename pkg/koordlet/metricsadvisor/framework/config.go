@@ -19,6 +19,8 @@ package framework
 import (
 	"flag"
 	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
 )
 
 const (
@@ -26,21 +28,59 @@ const (
 	ContextExpiredRatio = 20
 )
 
+const (
+	// ContainerStatsSourceCgroup reads the container usages from the cgroups of the pods and containers.
+	ContainerStatsSourceCgroup = "cgroup"
+	// ContainerStatsSourceCRI lists the container usages from the CRI stats API of the container runtime.
+	ContainerStatsSourceCRI = "cri"
+)
+
 type Config struct {
-	CollectResUsedIntervalSeconds     int
-	CollectNodeCPUInfoIntervalSeconds int
-	CPICollectorIntervalSeconds       int
-	PSICollectorIntervalSeconds       int
-	CPICollectorTimeWindowSeconds     int
+	CollectResUsedIntervalSeconds        int
+	CollectNodeCPUInfoIntervalSeconds    int
+	CPICollectorIntervalSeconds          int
+	PSICollectorIntervalSeconds          int
+	CPICollectorTimeWindowSeconds        int
+	NUMAStatCollectorIntervalSeconds     int
+	RDMAStatCollectorIntervalSeconds     int
+	SchedLatencyCollectorIntervalSeconds int
+	ColdMemoryCollectorIntervalSeconds   int
+	// SystemCgroupDirs are the relative cgroup dirs of the system components (e.g. kubelet, container runtime and
+	// system daemons), separated by commas.
+	SystemCgroupDirs string
+	// CollectPodShards is the number of shards the pods are split into in the resource usage collection. The shards
+	// are collected at even offsets within the interval to avoid CPU spikes on the nodes with high pod densities.
+	CollectPodShards int
+	// CollectPodCgroupReadQPS limits the rate of the pod cgroups read in the resource usage collection. Zero means
+	// no limit.
+	CollectPodCgroupReadQPS int
+	// CollectIdlePodMaxSkipRounds is the max rounds in a row the resource usage collection of an idle pod can be
+	// skipped when its cgroup is not modified. Zero means never skip.
+	CollectIdlePodMaxSkipRounds int
+	// CollectorGates enables or disables the collectors by name, e.g. RDMAStatCollector=false. The collectors not
+	// listed are enabled.
+	CollectorGates map[string]bool
+	// ContainerStatsSource is where the CPU and memory usages of the pods and containers come from, "cgroup" or
+	// "cri". The CRI stats fit the nodes running sandboxed runtimes (e.g. Kata, gVisor) whose cgroup paths differ.
+	ContainerStatsSource string
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		CollectResUsedIntervalSeconds:     1,
-		CollectNodeCPUInfoIntervalSeconds: 60,
-		CPICollectorIntervalSeconds:       60,
-		PSICollectorIntervalSeconds:       10,
-		CPICollectorTimeWindowSeconds:     10,
+		CollectResUsedIntervalSeconds:        1,
+		CollectNodeCPUInfoIntervalSeconds:    60,
+		CPICollectorIntervalSeconds:          60,
+		PSICollectorIntervalSeconds:          10,
+		CPICollectorTimeWindowSeconds:        10,
+		NUMAStatCollectorIntervalSeconds:     30,
+		RDMAStatCollectorIntervalSeconds:     10,
+		SchedLatencyCollectorIntervalSeconds: 10,
+		ColdMemoryCollectorIntervalSeconds:   60,
+		SystemCgroupDirs:                     "system.slice/",
+		CollectPodShards:                     1,
+		CollectPodCgroupReadQPS:              0,
+		CollectIdlePodMaxSkipRounds:          0,
+		ContainerStatsSource:                 ContainerStatsSourceCgroup,
 	}
 }
 
@@ -51,4 +91,14 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.CPICollectorIntervalSeconds, "cpi-collector-interval-seconds", c.CPICollectorIntervalSeconds, "Collect cpi interval by seconds")
 	fs.IntVar(&c.PSICollectorIntervalSeconds, "psi-collector-interval-seconds", c.PSICollectorIntervalSeconds, "Collect psi interval by seconds")
 	fs.IntVar(&c.CPICollectorTimeWindowSeconds, "collect-cpi-timewindow-seconds", c.CPICollectorTimeWindowSeconds, "Collect cpi time window by seconds")
+	fs.IntVar(&c.NUMAStatCollectorIntervalSeconds, "numa-stat-collector-interval-seconds", c.NUMAStatCollectorIntervalSeconds, "Collect memory numa stat interval by seconds")
+	fs.IntVar(&c.RDMAStatCollectorIntervalSeconds, "rdma-stat-collector-interval-seconds", c.RDMAStatCollectorIntervalSeconds, "Collect rdma traffic counters interval by seconds")
+	fs.IntVar(&c.SchedLatencyCollectorIntervalSeconds, "sched-latency-collector-interval-seconds", c.SchedLatencyCollectorIntervalSeconds, "Collect pod run queue latency interval by seconds")
+	fs.IntVar(&c.ColdMemoryCollectorIntervalSeconds, "cold-memory-collector-interval-seconds", c.ColdMemoryCollectorIntervalSeconds, "Collect pod cold memory from the kidled idle page stats interval by seconds")
+	fs.StringVar(&c.SystemCgroupDirs, "system-cgroup-dirs", c.SystemCgroupDirs, "Relative cgroup dirs of the system components (e.g. kubelet, container runtime) separated by commas, whose usage is reported as the system overhead")
+	fs.IntVar(&c.CollectPodShards, "collect-pod-shards", c.CollectPodShards, "Number of shards the pods are split into and collected at even offsets within the resource usage collect interval")
+	fs.IntVar(&c.CollectPodCgroupReadQPS, "collect-pod-cgroup-read-qps", c.CollectPodCgroupReadQPS, "Max rate of reading pod cgroups in the resource usage collection, 0 means no limit")
+	fs.IntVar(&c.CollectIdlePodMaxSkipRounds, "collect-idle-pod-max-skip-rounds", c.CollectIdlePodMaxSkipRounds, "Max rounds in a row the resource usage collection of an idle pod with unmodified cgroup can be skipped, 0 means never skip")
+	fs.Var(cliflag.NewMapStringBool(&c.CollectorGates), "collector-gates", "A set of name=bool pairs that enable or disable the metric collectors, e.g. RDMAStatCollector=false. The collectors not listed are enabled")
+	fs.StringVar(&c.ContainerStatsSource, "container-stats-source", c.ContainerStatsSource, "Source of the pod and container CPU/memory usages, \"cgroup\" or \"cri\". Use \"cri\" on the nodes running sandboxed runtimes (e.g. Kata, gVisor) whose cgroup paths differ")
 }
@@ -25,11 +25,18 @@ import (
 
 func Test_NewDefaultConfig(t *testing.T) {
 	expectConfig := &Config{
-		CollectResUsedIntervalSeconds:     1,
-		CollectNodeCPUInfoIntervalSeconds: 60,
-		CPICollectorIntervalSeconds:       60,
-		PSICollectorIntervalSeconds:       10,
-		CPICollectorTimeWindowSeconds:     10,
+		CollectResUsedIntervalSeconds:        1,
+		CollectNodeCPUInfoIntervalSeconds:    60,
+		CPICollectorIntervalSeconds:          60,
+		PSICollectorIntervalSeconds:          10,
+		CPICollectorTimeWindowSeconds:        10,
+		NUMAStatCollectorIntervalSeconds:     30,
+		RDMAStatCollectorIntervalSeconds:     10,
+		SchedLatencyCollectorIntervalSeconds: 10,
+		ColdMemoryCollectorIntervalSeconds:   60,
+		SystemCgroupDirs:                     "system.slice/",
+		CollectPodShards:                     1,
+		ContainerStatsSource:                 ContainerStatsSourceCgroup,
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -43,15 +50,35 @@ func Test_InitFlags(t *testing.T) {
 		"--cpi-collector-interval-seconds=90",
 		"--psi-collector-interval-seconds=5",
 		"--collect-cpi-timewindow-seconds=15",
+		"--numa-stat-collector-interval-seconds=60",
+		"--rdma-stat-collector-interval-seconds=20",
+		"--sched-latency-collector-interval-seconds=5",
+		"--cold-memory-collector-interval-seconds=120",
+		"--system-cgroup-dirs=system.slice/,kubepods.slice/kubelet/",
+		"--collect-pod-shards=4",
+		"--collect-pod-cgroup-read-qps=200",
+		"--collect-idle-pod-max-skip-rounds=5",
+		"--collector-gates=RDMAStatCollector=false",
+		"--container-stats-source=cri",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
 	type fields struct {
-		CollectResUsedIntervalSeconds     int
-		CollectNodeCPUInfoIntervalSeconds int
-		CPICollectorIntervalSeconds       int
-		PSICollectorIntervalSeconds       int
-		CPICollectorTimeWindowSeconds     int
+		CollectResUsedIntervalSeconds        int
+		CollectNodeCPUInfoIntervalSeconds    int
+		CPICollectorIntervalSeconds          int
+		PSICollectorIntervalSeconds          int
+		CPICollectorTimeWindowSeconds        int
+		NUMAStatCollectorIntervalSeconds     int
+		RDMAStatCollectorIntervalSeconds     int
+		SchedLatencyCollectorIntervalSeconds int
+		ColdMemoryCollectorIntervalSeconds   int
+		SystemCgroupDirs                     string
+		CollectPodShards                     int
+		CollectPodCgroupReadQPS              int
+		CollectIdlePodMaxSkipRounds          int
+		CollectorGates                       map[string]bool
+		ContainerStatsSource                 string
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -64,11 +91,21 @@ func Test_InitFlags(t *testing.T) {
 		{
 			name: "not default",
 			fields: fields{
-				CollectResUsedIntervalSeconds:     3,
-				CollectNodeCPUInfoIntervalSeconds: 90,
-				CPICollectorIntervalSeconds:       90,
-				PSICollectorIntervalSeconds:       5,
-				CPICollectorTimeWindowSeconds:     15,
+				CollectResUsedIntervalSeconds:        3,
+				CollectNodeCPUInfoIntervalSeconds:    90,
+				CPICollectorIntervalSeconds:          90,
+				PSICollectorIntervalSeconds:          5,
+				CPICollectorTimeWindowSeconds:        15,
+				NUMAStatCollectorIntervalSeconds:     60,
+				RDMAStatCollectorIntervalSeconds:     20,
+				SchedLatencyCollectorIntervalSeconds: 5,
+				ColdMemoryCollectorIntervalSeconds:   120,
+				SystemCgroupDirs:                     "system.slice/,kubepods.slice/kubelet/",
+				CollectPodShards:                     4,
+				CollectPodCgroupReadQPS:              200,
+				CollectIdlePodMaxSkipRounds:          5,
+				CollectorGates:                       map[string]bool{"RDMAStatCollector": false},
+				ContainerStatsSource:                 ContainerStatsSourceCRI,
 			},
 			args: args{fs: fs},
 		},
@@ -76,11 +113,21 @@ func Test_InitFlags(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			raw := &Config{
-				CollectResUsedIntervalSeconds:     tt.fields.CollectResUsedIntervalSeconds,
-				CollectNodeCPUInfoIntervalSeconds: tt.fields.CollectNodeCPUInfoIntervalSeconds,
-				CPICollectorIntervalSeconds:       tt.fields.CPICollectorIntervalSeconds,
-				PSICollectorIntervalSeconds:       tt.fields.PSICollectorIntervalSeconds,
-				CPICollectorTimeWindowSeconds:     tt.fields.CPICollectorTimeWindowSeconds,
+				CollectResUsedIntervalSeconds:        tt.fields.CollectResUsedIntervalSeconds,
+				CollectNodeCPUInfoIntervalSeconds:    tt.fields.CollectNodeCPUInfoIntervalSeconds,
+				CPICollectorIntervalSeconds:          tt.fields.CPICollectorIntervalSeconds,
+				PSICollectorIntervalSeconds:          tt.fields.PSICollectorIntervalSeconds,
+				CPICollectorTimeWindowSeconds:        tt.fields.CPICollectorTimeWindowSeconds,
+				NUMAStatCollectorIntervalSeconds:     tt.fields.NUMAStatCollectorIntervalSeconds,
+				RDMAStatCollectorIntervalSeconds:     tt.fields.RDMAStatCollectorIntervalSeconds,
+				SchedLatencyCollectorIntervalSeconds: tt.fields.SchedLatencyCollectorIntervalSeconds,
+				ColdMemoryCollectorIntervalSeconds:   tt.fields.ColdMemoryCollectorIntervalSeconds,
+				SystemCgroupDirs:                     tt.fields.SystemCgroupDirs,
+				CollectPodShards:                     tt.fields.CollectPodShards,
+				CollectPodCgroupReadQPS:              tt.fields.CollectPodCgroupReadQPS,
+				CollectIdlePodMaxSkipRounds:          tt.fields.CollectIdlePodMaxSkipRounds,
+				CollectorGates:                       tt.fields.CollectorGates,
+				ContainerStatsSource:                 tt.fields.ContainerStatsSource,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
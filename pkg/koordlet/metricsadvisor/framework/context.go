@@ -19,7 +19,11 @@ package framework
 import (
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
 type Context struct {
@@ -53,3 +57,56 @@ type CPUStat struct {
 	CPUUsage  uint64
 	Timestamp time.Time
 }
+
+type NetStat struct {
+	RxBytes   uint64
+	TxBytes   uint64
+	Timestamp time.Time
+}
+
+// CalculateNetworkMetric returns the network bandwidth used between the last and the current stats. The bandwidth
+// is regarded as zero if the counters decrease, e.g. the interfaces are recreated.
+func CalculateNetworkMetric(last, cur *NetStat) metriccache.NetworkMetric {
+	seconds := cur.Timestamp.Sub(last.Timestamp).Seconds()
+	if seconds <= 0 || cur.RxBytes < last.RxBytes || cur.TxBytes < last.TxBytes {
+		return metriccache.NetworkMetric{
+			RxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+			TxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+		}
+	}
+	// do subtraction and division first to avoid overflow
+	return metriccache.NetworkMetric{
+		RxBytesPerSec: *resource.NewQuantity(int64(float64(cur.RxBytes-last.RxBytes)/seconds), resource.DecimalSI),
+		TxBytesPerSec: *resource.NewQuantity(int64(float64(cur.TxBytes-last.TxBytes)/seconds), resource.DecimalSI),
+	}
+}
+
+type DiskIOStat struct {
+	system.DiskIOStat
+	Timestamp time.Time
+}
+
+// CalculateDiskIOMetric returns the disk IO throughput and IOPS between the last and the current stats. The IO is
+// regarded as zero if the counters decrease, e.g. the disks are replugged or the cgroup is recreated.
+func CalculateDiskIOMetric(last, cur *DiskIOStat) metriccache.DiskIOMetric {
+	seconds := cur.Timestamp.Sub(last.Timestamp).Seconds()
+	if seconds <= 0 || cur.ReadBytes < last.ReadBytes || cur.WriteBytes < last.WriteBytes ||
+		cur.ReadIOs < last.ReadIOs || cur.WriteIOs < last.WriteIOs {
+		return metriccache.DiskIOMetric{
+			ReadBytesPerSec:  *resource.NewQuantity(0, resource.DecimalSI),
+			WriteBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+			ReadIOPS:         *resource.NewQuantity(0, resource.DecimalSI),
+			WriteIOPS:        *resource.NewQuantity(0, resource.DecimalSI),
+		}
+	}
+	rate := func(last, cur uint64) resource.Quantity {
+		// do subtraction and division first to avoid overflow
+		return *resource.NewQuantity(int64(float64(cur-last)/seconds), resource.DecimalSI)
+	}
+	return metriccache.DiskIOMetric{
+		ReadBytesPerSec:  rate(last.ReadBytes, cur.ReadBytes),
+		WriteBytesPerSec: rate(last.WriteBytes, cur.WriteBytes),
+		ReadIOPS:         rate(last.ReadIOs, cur.ReadIOs),
+		WriteIOPS:        rate(last.WriteIOs, cur.WriteIOs),
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_CalculateNetworkMetric(t *testing.T) {
+	now := time.Now()
+	zero := metriccache.NetworkMetric{
+		RxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+		TxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+	}
+	tests := []struct {
+		name string
+		last *NetStat
+		cur  *NetStat
+		want metriccache.NetworkMetric
+	}{
+		{
+			name: "calculate the rates",
+			last: &NetStat{RxBytes: 1000, TxBytes: 2000, Timestamp: now.Add(-10 * time.Second)},
+			cur:  &NetStat{RxBytes: 11000, TxBytes: 7000, Timestamp: now},
+			want: metriccache.NetworkMetric{
+				RxBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+				TxBytesPerSec: *resource.NewQuantity(500, resource.DecimalSI),
+			},
+		},
+		{
+			name: "counters reset",
+			last: &NetStat{RxBytes: 11000, TxBytes: 7000, Timestamp: now.Add(-10 * time.Second)},
+			cur:  &NetStat{RxBytes: 1000, TxBytes: 8000, Timestamp: now},
+			want: zero,
+		},
+		{
+			name: "no time elapsed",
+			last: &NetStat{RxBytes: 1000, TxBytes: 2000, Timestamp: now},
+			cur:  &NetStat{RxBytes: 11000, TxBytes: 7000, Timestamp: now},
+			want: zero,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CalculateNetworkMetric(tt.last, tt.cur))
+		})
+	}
+}
+
+func Test_CalculateDiskIOMetric(t *testing.T) {
+	now := time.Now()
+	newDiskIOStat := func(readBytes, writeBytes, readIOs, writeIOs uint64, timestamp time.Time) *DiskIOStat {
+		return &DiskIOStat{
+			DiskIOStat: system.DiskIOStat{
+				ReadBytes:  readBytes,
+				WriteBytes: writeBytes,
+				ReadIOs:    readIOs,
+				WriteIOs:   writeIOs,
+			},
+			Timestamp: timestamp,
+		}
+	}
+	zero := metriccache.DiskIOMetric{
+		ReadBytesPerSec:  *resource.NewQuantity(0, resource.DecimalSI),
+		WriteBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+		ReadIOPS:         *resource.NewQuantity(0, resource.DecimalSI),
+		WriteIOPS:        *resource.NewQuantity(0, resource.DecimalSI),
+	}
+	tests := []struct {
+		name string
+		last *DiskIOStat
+		cur  *DiskIOStat
+		want metriccache.DiskIOMetric
+	}{
+		{
+			name: "calculate the rates",
+			last: newDiskIOStat(4096, 8192, 10, 20, now.Add(-10*time.Second)),
+			cur:  newDiskIOStat(45056, 110592, 110, 520, now),
+			want: metriccache.DiskIOMetric{
+				ReadBytesPerSec:  *resource.NewQuantity(4096, resource.DecimalSI),
+				WriteBytesPerSec: *resource.NewQuantity(10240, resource.DecimalSI),
+				ReadIOPS:         *resource.NewQuantity(10, resource.DecimalSI),
+				WriteIOPS:        *resource.NewQuantity(50, resource.DecimalSI),
+			},
+		},
+		{
+			name: "counters reset",
+			last: newDiskIOStat(45056, 110592, 110, 520, now.Add(-10*time.Second)),
+			cur:  newDiskIOStat(4096, 8192, 10, 20, now),
+			want: zero,
+		},
+		{
+			name: "no time elapsed",
+			last: newDiskIOStat(4096, 8192, 10, 20, now),
+			cur:  newDiskIOStat(45056, 110592, 110, 520, now),
+			want: zero,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CalculateDiskIOMetric(tt.last, tt.cur))
+		})
+	}
+}
@@ -28,6 +28,7 @@ type DeviceFactory = func(opt *Options) DeviceCollector
 type Collector interface {
 	Enabled() bool
 	Setup(s *Context)
+	// Run collects the metrics until stopCh is closed, and returns after its collecting loops stop.
 	Run(stopCh <-chan struct{})
 	Started() bool
 }
@@ -35,6 +36,7 @@ type Collector interface {
 type DeviceCollector interface {
 	Enabled() bool
 	Setup(s *Context)
+	// Run collects the metrics until stopCh is closed, and returns after its collecting loops stop.
 	Run(stopCh <-chan struct{})
 	Shutdown()
 	Started() bool
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+// ShardPods splits the pods into the given number of shards by the hash of the pod UID, so that a pod is always
+// collected in the same shard across the rounds.
+func ShardPods(podMetas []*statesinformer.PodMeta, shards int) [][]*statesinformer.PodMeta {
+	if shards <= 1 {
+		return [][]*statesinformer.PodMeta{podMetas}
+	}
+	result := make([][]*statesinformer.PodMeta, shards)
+	for _, meta := range podMetas {
+		if meta == nil || meta.Pod == nil {
+			continue
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(meta.Pod.UID))
+		i := int(h.Sum32() % uint32(shards))
+		result[i] = append(result[i], meta)
+	}
+	return result
+}
+
+type cgroupRecord struct {
+	modTime time.Time
+	idle    bool
+	skipped int
+}
+
+// CgroupSkipper decides whether the collection of a cgroup can be skipped in a round with the mtime heuristics.
+// A cgroup is skipped when it was idle in the last collection and its directory has not been modified since, i.e.
+// no child cgroup is created or removed. A cgroup is skipped for at most maxSkipRounds rounds in a row so the
+// metrics of an idle cgroup are still refreshed.
+type CgroupSkipper struct {
+	maxSkipRounds int
+	lock          sync.Mutex
+	records       map[string]*cgroupRecord
+}
+
+func NewCgroupSkipper(maxSkipRounds int) *CgroupSkipper {
+	// the last cpu stat of a cgroup expires after ContextExpiredRatio rounds
+	if maxSkipRounds >= ContextExpiredRatio {
+		maxSkipRounds = ContextExpiredRatio - 1
+	}
+	return &CgroupSkipper{
+		maxSkipRounds: maxSkipRounds,
+		records:       map[string]*cgroupRecord{},
+	}
+}
+
+// ShouldSkip returns whether the collection of the cgroup identified by key can be skipped in this round.
+func (s *CgroupSkipper) ShouldSkip(key string, cgroupDir string) bool {
+	if s == nil || s.maxSkipRounds <= 0 {
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	record, ok := s.records[key]
+	if !ok || !record.idle || record.skipped >= s.maxSkipRounds {
+		return false
+	}
+	modTime, err := getCgroupModTime(cgroupDir)
+	if err != nil || !modTime.Equal(record.modTime) {
+		return false
+	}
+	record.skipped++
+	return true
+}
+
+// Record records the result of the collection of the cgroup identified by key.
+func (s *CgroupSkipper) Record(key string, cgroupDir string, idle bool) {
+	if s == nil || s.maxSkipRounds <= 0 {
+		return
+	}
+	modTime, err := getCgroupModTime(cgroupDir)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil {
+		delete(s.records, key)
+		return
+	}
+	s.records[key] = &cgroupRecord{modTime: modTime, idle: idle}
+}
+
+// Prune removes the records of the cgroups whose keys are not in the given set.
+func (s *CgroupSkipper) Prune(keys sets.String) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key := range s.records {
+		if !keys.Has(key) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func getCgroupModTime(cgroupDir string) (time.Time, error) {
+	r, err := system.GetCgroupResource(system.CPUAcctUsageName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(filepath.Dir(system.GetCgroupFilePath(cgroupDir, r)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
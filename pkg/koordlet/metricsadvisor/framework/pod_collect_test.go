@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_ShardPods(t *testing.T) {
+	var podMetas []*statesinformer.PodMeta
+	for i := 0; i < 100; i++ {
+		podMetas = append(podMetas, &statesinformer.PodMeta{
+			Pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(fmt.Sprintf("pod-%d", i))}},
+		})
+	}
+
+	shards := ShardPods(podMetas, 1)
+	assert.Equal(t, [][]*statesinformer.PodMeta{podMetas}, shards)
+
+	shards = ShardPods(podMetas, 4)
+	assert.Len(t, shards, 4)
+	uids := sets.NewString()
+	for _, shard := range shards {
+		assert.NotEmpty(t, shard)
+		for _, meta := range shard {
+			uids.Insert(string(meta.Pod.UID))
+		}
+	}
+	assert.Equal(t, len(podMetas), uids.Len())
+	// a pod stays in the same shard
+	assert.Equal(t, shards, ShardPods(podMetas, 4))
+}
+
+func Test_CgroupSkipper(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	cgroupDir := "kubepods.slice/kubepods-pod123.slice"
+	helper.WriteCgroupFileContents(cgroupDir, system.CPUAcctUsage, "0")
+
+	skipper := NewCgroupSkipper(0)
+	skipper.Record("123", cgroupDir, true)
+	assert.False(t, skipper.ShouldSkip("123", cgroupDir))
+
+	skipper = NewCgroupSkipper(2)
+	assert.False(t, skipper.ShouldSkip("123", cgroupDir))
+	skipper.Record("123", cgroupDir, false)
+	assert.False(t, skipper.ShouldSkip("123", cgroupDir))
+	// an idle cgroup is skipped for at most maxSkipRounds rounds
+	skipper.Record("123", cgroupDir, true)
+	assert.True(t, skipper.ShouldSkip("123", cgroupDir))
+	assert.True(t, skipper.ShouldSkip("123", cgroupDir))
+	assert.False(t, skipper.ShouldSkip("123", cgroupDir))
+
+	// the cgroup dir is modified
+	skipper.Record("123", cgroupDir, true)
+	r, err := system.GetCgroupResource(system.CPUAcctUsageName)
+	assert.NoError(t, err)
+	modTime := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(filepath.Dir(system.GetCgroupFilePath(cgroupDir, r)), modTime, modTime))
+	assert.False(t, skipper.ShouldSkip("123", cgroupDir))
+
+	skipper.Prune(sets.NewString("456"))
+	assert.Empty(t, skipper.records)
+
+	assert.Equal(t, ContextExpiredRatio-1, NewCgroupSkipper(100).maxSkipRounds)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CollectorPlugin is the registration of a collector in the metric advisor.
+type CollectorPlugin struct {
+	Factory CollectorFactory
+	// Interval returns the collection interval of the collector from the config. The collector is not run if the
+	// interval is not positive. Nil means the collector manages the intervals by itself.
+	Interval func(cfg *Config) time.Duration
+	// Dependencies are the names of the collectors which the collector depends on, e.g. it reads their metrics from
+	// the metric cache. The collector is run after its dependencies have started, and is not run without them.
+	Dependencies []string
+}
+
+// CollectorRegistry is a collection of the collector plugins by name.
+type CollectorRegistry map[string]CollectorPlugin
+
+// Register adds a new collector to the registry. It returns an error if the name is already registered.
+func (r CollectorRegistry) Register(name string, plugin CollectorPlugin) error {
+	if _, ok := r[name]; ok {
+		return fmt.Errorf("a collector named %v already exists", name)
+	}
+	if plugin.Factory == nil {
+		return fmt.Errorf("collector %v has no factory", name)
+	}
+	r[name] = plugin
+	return nil
+}
+
+// Merge merges the collectors of the provided registry into the current one. It returns an error if any name
+// already exists in the current registry.
+func (r CollectorRegistry) Merge(in CollectorRegistry) error {
+	for name, plugin := range in {
+		if err := r.Register(name, plugin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SortedNames returns the names of the collectors where each collector follows its dependencies, and the collectors
+// are sorted by name otherwise. The collectors with unknown or circular dependencies are not returned but reported
+// in the invalid map with the reasons.
+func (r CollectorRegistry) SortedNames() ([]string, map[string]error) {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	states := map[string]int{}
+	invalid := map[string]error{}
+	sorted := make([]string, 0, len(r))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch states[name] {
+		case visiting:
+			return fmt.Errorf("circular dependency on collector %v", name)
+		case visited:
+			return invalid[name]
+		}
+		states[name] = visiting
+		defer func() { states[name] = visited }()
+		for _, dep := range r[name].Dependencies {
+			if _, ok := r[dep]; !ok {
+				invalid[name] = fmt.Errorf("depends on unknown collector %v", dep)
+				return invalid[name]
+			}
+			if err := visit(dep); err != nil {
+				invalid[name] = fmt.Errorf("depends on invalid collector %v, %v", dep, err)
+				return invalid[name]
+			}
+		}
+		sorted = append(sorted, name)
+		return nil
+	}
+	for _, name := range names {
+		_ = visit(name)
+	}
+	return sorted, invalid
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorRegistry(t *testing.T) {
+	factory := func(opt *Options) Collector { return nil }
+	r := CollectorRegistry{}
+	assert.NoError(t, r.Register("a", CollectorPlugin{Factory: factory}))
+	assert.Error(t, r.Register("a", CollectorPlugin{Factory: factory}))
+	assert.Error(t, r.Register("b", CollectorPlugin{}))
+
+	assert.NoError(t, r.Merge(CollectorRegistry{
+		"b": {Factory: factory, Dependencies: []string{"c"}},
+		"c": {Factory: factory, Dependencies: []string{"a"}},
+		"d": {Factory: factory, Dependencies: []string{"unknown"}},
+		"e": {Factory: factory, Dependencies: []string{"f"}},
+		"f": {Factory: factory, Dependencies: []string{"e"}},
+		"g": {Factory: factory, Dependencies: []string{"d"}},
+	}))
+	assert.Error(t, r.Merge(CollectorRegistry{"a": {Factory: factory}}))
+
+	sorted, invalid := r.SortedNames()
+	assert.Equal(t, []string{"a", "c", "b"}, sorted)
+	assert.Len(t, invalid, 4)
+	for _, name := range []string{"d", "e", "f", "g"} {
+		assert.Error(t, invalid[name], name)
+	}
+}
@@ -17,16 +17,26 @@ limitations under the License.
 package metricsadvisor
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/beresource"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/coldmemory"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/nodeinfo"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/noderesource"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/numastat"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/performance"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/podresource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/podthrottled"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/rdmastat"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/schedlatency"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/sysresource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/devices/gpu"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
@@ -43,19 +53,46 @@ var (
 		gpu.DeviceCollectorName: gpu.New,
 	}
 
-	collectorPlugins = map[string]framework.CollectorFactory{
-		noderesource.CollectorName: noderesource.New,
-		beresource.CollectorName:   beresource.New,
-		nodeinfo.CollectorName:     nodeinfo.New,
-		podresource.CollectorName:  podresource.New,
-		podthrottled.CollectorName: podthrottled.New,
-		performance.CollectorName:  performance.New,
+	collectorPlugins = framework.CollectorRegistry{
+		noderesource.CollectorName: {Factory: noderesource.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.CollectResUsedIntervalSeconds })},
+		beresource.CollectorName:   {Factory: beresource.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.CollectResUsedIntervalSeconds })},
+		nodeinfo.CollectorName:     {Factory: nodeinfo.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.CollectNodeCPUInfoIntervalSeconds })},
+		podresource.CollectorName:  {Factory: podresource.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.CollectResUsedIntervalSeconds })},
+		podthrottled.CollectorName: {Factory: podthrottled.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.CollectResUsedIntervalSeconds })},
+		// the performance collector collects the CPI and the PSI in their own intervals
+		performance.CollectorName:  {Factory: performance.New},
+		sysresource.CollectorName:  {Factory: sysresource.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.CollectResUsedIntervalSeconds })},
+		numastat.CollectorName:     {Factory: numastat.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.NUMAStatCollectorIntervalSeconds })},
+		rdmastat.CollectorName:     {Factory: rdmastat.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.RDMAStatCollectorIntervalSeconds })},
+		schedlatency.CollectorName: {Factory: schedlatency.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.SchedLatencyCollectorIntervalSeconds })},
+		coldmemory.CollectorName:   {Factory: coldmemory.New, Interval: intervalSeconds(func(cfg *framework.Config) int { return cfg.ColdMemoryCollectorIntervalSeconds })},
 	}
+
+	// outOfTreeCollectorPlugins are the collectors registered by RegisterCollector.
+	outOfTreeCollectorPlugins = framework.CollectorRegistry{}
 )
 
+// RegisterCollector registers an out-of-tree collector, e.g. of the vendor devices, which is usually called in the
+// init of the collector package compiled into the koordlet. It must be called before NewMetricAdvisor.
+func RegisterCollector(name string, plugin framework.CollectorPlugin) error {
+	if _, ok := collectorPlugins[name]; ok {
+		return fmt.Errorf("a collector named %v already exists", name)
+	}
+	return outOfTreeCollectorPlugins.Register(name, plugin)
+}
+
+func intervalSeconds(seconds func(cfg *framework.Config) int) func(cfg *framework.Config) time.Duration {
+	return func(cfg *framework.Config) time.Duration {
+		return time.Duration(seconds(cfg)) * time.Second
+	}
+}
+
 type metricAdvisor struct {
 	options *framework.Options
 	context *framework.Context
+	// collectorOrder is the names of the collectors, where each collector follows its dependencies.
+	collectorOrder []string
+	dependencies   map[string][]string
 }
 
 func NewMetricAdvisor(cfg *framework.Config, statesInformer statesinformer.StatesInformer, metricCache metriccache.MetricCache) MetricAdvisor {
@@ -67,22 +104,63 @@ func NewMetricAdvisor(cfg *framework.Config, statesInformer statesinformer.State
 	}
 	ctx := &framework.Context{
 		DeviceCollectors: make(map[string]framework.DeviceCollector, len(devicePlugins)),
-		Collectors:       make(map[string]framework.Collector, len(collectorPlugins)),
+		Collectors:       make(map[string]framework.Collector, len(collectorPlugins)+len(outOfTreeCollectorPlugins)),
 	}
 	for name, device := range devicePlugins {
 		ctx.DeviceCollectors[name] = device(opt)
 	}
-	for name, collector := range collectorPlugins {
-		ctx.Collectors[name] = collector(opt)
-	}
 
 	c := &metricAdvisor{
-		options: opt,
-		context: ctx,
+		options:      opt,
+		context:      ctx,
+		dependencies: map[string][]string{},
 	}
+	registry := framework.CollectorRegistry{}
+	_ = registry.Merge(collectorPlugins)
+	_ = registry.Merge(outOfTreeCollectorPlugins)
+	c.setupCollectors(registry)
 	return c
 }
 
+// setupCollectors creates the collectors of the registry in the order of the dependencies. A collector is skipped if
+// it is disabled by the collector gates, its interval is not positive, or any of its dependencies is skipped or not
+// enabled.
+func (m *metricAdvisor) setupCollectors(registry framework.CollectorRegistry) {
+	sortedNames, invalid := registry.SortedNames()
+	for name, err := range invalid {
+		klog.Errorf("collector %v is invalid and skipped, err: %v", name, err)
+	}
+	for _, name := range sortedNames {
+		plugin := registry[name]
+		if enabled, ok := m.options.Config.CollectorGates[name]; ok && !enabled {
+			klog.V(4).Infof("collector %v is disabled by the collector gates", name)
+			continue
+		}
+		if plugin.Interval != nil {
+			if interval := plugin.Interval(m.options.Config); interval <= 0 {
+				klog.V(4).Infof("collector %v is skipped for the interval %v", name, interval)
+				continue
+			}
+		}
+		if dep, ok := m.missingDependency(plugin.Dependencies); ok {
+			klog.Warningf("collector %v is skipped since its dependency %v is not enabled", name, dep)
+			continue
+		}
+		m.context.Collectors[name] = plugin.Factory(m.options)
+		m.collectorOrder = append(m.collectorOrder, name)
+		m.dependencies[name] = plugin.Dependencies
+	}
+}
+
+func (m *metricAdvisor) missingDependency(dependencies []string) (string, bool) {
+	for _, dep := range dependencies {
+		if collector, ok := m.context.Collectors[dep]; !ok || !collector.Enabled() {
+			return dep, true
+		}
+	}
+	return "", false
+}
+
 func (m *metricAdvisor) HasSynced() bool {
 	return framework.CollectorsHasStarted(m.context.Collectors)
 }
@@ -101,24 +179,42 @@ func (m *metricAdvisor) Run(stopCh <-chan struct{}) error {
 	defer klog.Info("shutting down metric advisor")
 	klog.Info("Starting collector for NodeMetric")
 
+	// the collectors are joined before returning, so that none of them writes the metric cache after it is closed
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	runCollector := func(run func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run()
+		}()
+	}
+
 	for name, dc := range m.context.DeviceCollectors {
 		klog.V(4).Infof("ready to start device collector %v", name)
 		if !dc.Enabled() {
 			klog.V(4).Infof("device collector %v is not enabled, skip running", name)
 			continue
 		}
-		go dc.Run(stopCh)
+		dc := dc
+		runCollector(func() { dc.Run(stopCh) })
 		klog.V(4).Infof("device collector %v start", name)
 	}
 
-	for name, collector := range m.context.Collectors {
+	for _, name := range m.collectorOrder {
+		collector := m.context.Collectors[name]
 		klog.V(4).Infof("ready to start collector %v", name)
 		if !collector.Enabled() {
 			klog.V(4).Infof("collector %v is not enabled, skip running", name)
 			continue
 		}
-		go collector.Run(stopCh)
-		klog.V(4).Infof("collector %v start", name)
+		name, collector := name, collector
+		if len(m.dependencies[name]) == 0 {
+			runCollector(func() { collector.Run(stopCh) })
+			klog.V(4).Infof("collector %v start", name)
+			continue
+		}
+		runCollector(func() { m.runAfterDependencies(name, collector, stopCh) })
 	}
 
 	klog.Info("Starting successfully")
@@ -126,6 +222,22 @@ func (m *metricAdvisor) Run(stopCh <-chan struct{}) error {
 	return nil
 }
 
+// runAfterDependencies runs the collector once its dependencies have started.
+func (m *metricAdvisor) runAfterDependencies(name string, collector framework.Collector, stopCh <-chan struct{}) {
+	dependencies := make(map[string]framework.Collector, len(m.dependencies[name]))
+	for _, dep := range m.dependencies[name] {
+		dependencies[dep] = m.context.Collectors[dep]
+	}
+	if !cache.WaitForCacheSync(stopCh, func() bool {
+		return framework.CollectorsHasStarted(dependencies)
+	}) {
+		klog.Warningf("collector %v is not started since its dependencies %v have not started", name, m.dependencies[name])
+		return
+	}
+	klog.V(4).Infof("collector %v start", name)
+	collector.Run(stopCh)
+}
+
 func (m *metricAdvisor) setup() {
 	for _, device := range m.context.DeviceCollectors {
 		device.Setup(m.context)
@@ -17,7 +17,9 @@ limitations under the License.
 package metricsadvisor
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -26,6 +28,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/collectors/noderesource"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metricsadvisor/framework"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
@@ -237,3 +240,105 @@ unevictable 0
 		})
 	}
 }
+
+type fakeCollector struct {
+	name    string
+	enabled bool
+	lock    sync.Mutex
+	started bool
+	stopped bool
+	runLog  *[]string
+	logLock *sync.Mutex
+}
+
+func (f *fakeCollector) Enabled() bool { return f.enabled }
+
+func (f *fakeCollector) Setup(s *framework.Context) {}
+
+func (f *fakeCollector) Run(stopCh <-chan struct{}) {
+	f.logLock.Lock()
+	*f.runLog = append(*f.runLog, f.name)
+	f.logLock.Unlock()
+	f.lock.Lock()
+	f.started = true
+	f.lock.Unlock()
+	<-stopCh
+	f.lock.Lock()
+	f.stopped = true
+	f.lock.Unlock()
+}
+
+func (f *fakeCollector) Started() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.started
+}
+
+func Test_metricAdvisor_setupCollectors(t *testing.T) {
+	var runLog []string
+	logLock := &sync.Mutex{}
+	newFactory := func(name string, enabled bool) framework.CollectorFactory {
+		return func(opt *framework.Options) framework.Collector {
+			return &fakeCollector{name: name, enabled: enabled, runLog: &runLog, logLock: logLock}
+		}
+	}
+	interval := func(d time.Duration) func(cfg *framework.Config) time.Duration {
+		return func(cfg *framework.Config) time.Duration { return d }
+	}
+	registry := framework.CollectorRegistry{
+		"base":          {Factory: newFactory("base", true), Interval: interval(time.Second)},
+		"derived":       {Factory: newFactory("derived", true), Interval: interval(time.Second), Dependencies: []string{"base"}},
+		"gated":         {Factory: newFactory("gated", true)},
+		"zeroInterval":  {Factory: newFactory("zeroInterval", true), Interval: interval(0)},
+		"disabled":      {Factory: newFactory("disabled", false)},
+		"onDisabled":    {Factory: newFactory("onDisabled", true), Dependencies: []string{"disabled"}},
+		"onGated":       {Factory: newFactory("onGated", true), Dependencies: []string{"gated"}},
+		"unknownDepend": {Factory: newFactory("unknownDepend", true), Dependencies: []string{"unknown"}},
+	}
+	m := &metricAdvisor{
+		options: &framework.Options{
+			Config: &framework.Config{
+				CollectResUsedIntervalSeconds: 1,
+				CollectorGates:                map[string]bool{"gated": false, "base": true},
+			},
+		},
+		context: &framework.Context{
+			DeviceCollectors: map[string]framework.DeviceCollector{},
+			Collectors:       map[string]framework.Collector{},
+		},
+		dependencies: map[string][]string{},
+	}
+	m.setupCollectors(registry)
+	assert.Equal(t, []string{"base", "derived", "disabled"}, m.collectorOrder)
+	assert.False(t, m.HasSynced())
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, m.Run(stopCh))
+	}()
+	assert.Eventually(t, m.HasSynced, 5*time.Second, 10*time.Millisecond)
+	logLock.Lock()
+	// the derived collector is run after the base collector has started
+	assert.Equal(t, []string{"base", "derived"}, runLog)
+	logLock.Unlock()
+
+	// the collectors are stopped before Run returns
+	close(stopCh)
+	<-done
+	for _, name := range []string{"base", "derived"} {
+		c := m.context.Collectors[name].(*fakeCollector)
+		c.lock.Lock()
+		assert.True(t, c.stopped, name)
+		c.lock.Unlock()
+	}
+}
+
+func TestRegisterCollector(t *testing.T) {
+	defer func() { outOfTreeCollectorPlugins = framework.CollectorRegistry{} }()
+	factory := func(opt *framework.Options) framework.Collector { return nil }
+	assert.Error(t, RegisterCollector(noderesource.CollectorName, framework.CollectorPlugin{Factory: factory}))
+	assert.NoError(t, RegisterCollector("VendorCollector", framework.CollectorPlugin{Factory: factory}))
+	assert.Error(t, RegisterCollector("VendorCollector", framework.CollectorPlugin{Factory: factory}))
+}
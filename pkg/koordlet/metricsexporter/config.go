@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsexporter
+
+import (
+	"flag"
+
+	cliflag "k8s.io/component-base/cli/flag"
+)
+
+type Config struct {
+	// MetricsExporterPodLabels are the keys of the pod labels attached to the pod and container metrics, which are
+	// exported as the labels prefixed with "label_", e.g. the pod label "app" is exported as "label_app".
+	MetricsExporterPodLabels []string
+	// MetricsExporterStaticLabels are the constant labels attached to all the exported metrics, e.g. the cluster name.
+	MetricsExporterStaticLabels map[string]string
+	// MetricsExporterQueryWindowSeconds is the time window to look up the latest metrics in the metric cache.
+	MetricsExporterQueryWindowSeconds int64
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		MetricsExporterPodLabels:          []string{},
+		MetricsExporterStaticLabels:       map[string]string{},
+		MetricsExporterQueryWindowSeconds: 120,
+	}
+}
+
+func (c *Config) InitFlags(fs *flag.FlagSet) {
+	fs.Var(cliflag.NewStringSlice(&c.MetricsExporterPodLabels), "metrics-exporter-pod-labels", "keys of the pod labels attached to the exported pod and container metrics")
+	fs.Var(cliflag.NewMapStringString(&c.MetricsExporterStaticLabels), "metrics-exporter-static-labels", "constant labels attached to all the exported metrics, e.g. cluster=prod")
+	fs.Int64Var(&c.MetricsExporterQueryWindowSeconds, "metrics-exporter-query-window-seconds", c.MetricsExporterQueryWindowSeconds, "time window in seconds to look up the latest metrics for the exporter")
+}
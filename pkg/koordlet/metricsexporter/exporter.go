@@ -0,0 +1,255 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsexporter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+const (
+	GPUMinor = "minor"
+	GPUUUID  = "uuid"
+
+	podLabelPrefix = "label_"
+)
+
+var (
+	labelNameRegexp        = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	invalidLabelCharRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// Exporter is a prometheus collector exposing the latest node, pod and container metrics in the metric cache, which
+// are the same data used by koordinator itself, e.g. the usage reported in the NodeMetric.
+type Exporter struct {
+	config         *Config
+	statesInformer statesinformer.StatesInformer
+	metricCache    metriccache.MetricCache
+
+	// podLabelNames are the exported label names of the pod labels in the same order as the config.
+	podLabelNames []string
+
+	nodeCPUUsage          *prometheus.Desc
+	nodeMemoryUsage       *prometheus.Desc
+	nodeGPUCoreUsage      *prometheus.Desc
+	nodeGPUMemoryUsage    *prometheus.Desc
+	nodeGPUMemoryTotal    *prometheus.Desc
+	podCPUUsage           *prometheus.Desc
+	podMemoryUsage        *prometheus.Desc
+	podGPUCoreUsage       *prometheus.Desc
+	podGPUMemoryUsage     *prometheus.Desc
+	podPSI                *prometheus.Desc
+	containerCPUUsage     *prometheus.Desc
+	containerMemoryUsage  *prometheus.Desc
+	containerGPUCoreUsage *prometheus.Desc
+}
+
+func NewExporter(cfg *Config, statesInformer statesinformer.StatesInformer, metricCache metriccache.MetricCache) (*Exporter, error) {
+	if cfg.MetricsExporterQueryWindowSeconds <= 0 {
+		return nil, fmt.Errorf("invalid metrics exporter query window %v", cfg.MetricsExporterQueryWindowSeconds)
+	}
+	constLabels := prometheus.Labels{}
+	for name, value := range cfg.MetricsExporterStaticLabels {
+		if !labelNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid metrics exporter static label name %q", name)
+		}
+		constLabels[name] = value
+	}
+
+	nodeLabels := []string{metrics.NodeKey}
+	gpuLabels := []string{GPUMinor, GPUUUID}
+	reserved := map[string]struct{}{}
+	for _, name := range []string{metrics.NodeKey, metrics.PodUID, metrics.PodName, metrics.PodNamespace,
+		metrics.ContainerID, metrics.ContainerName, metrics.PSIResourceType, metrics.PSIDegree, GPUMinor, GPUUUID} {
+		reserved[name] = struct{}{}
+	}
+	for name := range constLabels {
+		if _, ok := reserved[name]; ok {
+			return nil, fmt.Errorf("metrics exporter static label %q conflicts with the metric labels", name)
+		}
+		reserved[name] = struct{}{}
+	}
+	podLabelNames := make([]string, 0, len(cfg.MetricsExporterPodLabels))
+	for _, key := range cfg.MetricsExporterPodLabels {
+		name := podLabelPrefix + invalidLabelCharRegexp.ReplaceAllString(key, "_")
+		if _, ok := reserved[name]; ok {
+			return nil, fmt.Errorf("metrics exporter pod label %q conflicts with the other labels as %q", key, name)
+		}
+		reserved[name] = struct{}{}
+		podLabelNames = append(podLabelNames, name)
+	}
+	podLabels := append([]string{metrics.NodeKey, metrics.PodUID, metrics.PodName, metrics.PodNamespace}, podLabelNames...)
+	containerLabels := append(append([]string{}, podLabels...), metrics.ContainerID, metrics.ContainerName)
+
+	newDesc := func(name, help string, labels ...[]string) *prometheus.Desc {
+		var variableLabels []string
+		for _, l := range labels {
+			variableLabels = append(variableLabels, l...)
+		}
+		return prometheus.NewDesc(prometheus.BuildFQName("", metrics.KoordletSubsystem, name), help, variableLabels, constLabels)
+	}
+	return &Exporter{
+		config:                cfg,
+		statesInformer:        statesInformer,
+		metricCache:           metricCache,
+		podLabelNames:         podLabelNames,
+		nodeCPUUsage:          newDesc("node_cpu_usage_cores", "the cpu usage of the node in cores", nodeLabels),
+		nodeMemoryUsage:       newDesc("node_memory_usage_bytes", "the memory usage of the node without the page cache", nodeLabels),
+		nodeGPUCoreUsage:      newDesc("node_gpu_core_usage_percent", "the utilization of the gpu devices on the node", nodeLabels, gpuLabels),
+		nodeGPUMemoryUsage:    newDesc("node_gpu_memory_usage_bytes", "the used memory of the gpu devices on the node", nodeLabels, gpuLabels),
+		nodeGPUMemoryTotal:    newDesc("node_gpu_memory_total_bytes", "the total memory of the gpu devices on the node", nodeLabels, gpuLabels),
+		podCPUUsage:           newDesc("pod_cpu_usage_cores", "the cpu usage of the pod in cores", podLabels),
+		podMemoryUsage:        newDesc("pod_memory_usage_bytes", "the memory usage of the pod without the page cache", podLabels),
+		podGPUCoreUsage:       newDesc("pod_gpu_core_usage_percent", "the utilization of the gpu devices by the pod", podLabels, gpuLabels),
+		podGPUMemoryUsage:     newDesc("pod_gpu_memory_usage_bytes", "the used memory of the gpu devices by the pod", podLabels, gpuLabels),
+		podPSI:                newDesc("pod_psi_avg10", "the 10-second average pressure stall of the pod in percent", podLabels, []string{metrics.PSIResourceType, metrics.PSIDegree}),
+		containerCPUUsage:     newDesc("container_cpu_usage_cores", "the cpu usage of the container in cores", containerLabels),
+		containerMemoryUsage:  newDesc("container_memory_usage_bytes", "the memory usage of the container without the page cache", containerLabels),
+		containerGPUCoreUsage: newDesc("container_gpu_core_usage_percent", "the utilization of the gpu devices by the container", containerLabels, gpuLabels),
+	}, nil
+}
+
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{e.nodeCPUUsage, e.nodeMemoryUsage, e.nodeGPUCoreUsage, e.nodeGPUMemoryUsage,
+		e.nodeGPUMemoryTotal, e.podCPUUsage, e.podMemoryUsage, e.podGPUCoreUsage, e.podGPUMemoryUsage, e.podPSI,
+		e.containerCPUUsage, e.containerMemoryUsage, e.containerGPUCoreUsage} {
+		ch <- desc
+	}
+}
+
+// Collect exports the last metrics within the query window, and the metrics of the pods and containers not collected
+// during the window are omitted.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	node := e.statesInformer.GetNode()
+	if node == nil {
+		klog.V(5).Infof("node is nil, skip exporting metrics")
+		return
+	}
+	queryParam := e.generateQueryParam()
+	e.collectNode(ch, node.Name, queryParam)
+	for _, podMeta := range e.statesInformer.GetAllPods() {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		e.collectPod(ch, node.Name, podMeta.Pod, queryParam)
+	}
+}
+
+func (e *Exporter) collectNode(ch chan<- prometheus.Metric, nodeName string, queryParam *metriccache.QueryParam) {
+	queryResult := e.metricCache.GetNodeResourceMetric(queryParam)
+	if queryResult.Error != nil || queryResult.Metric == nil {
+		klog.V(5).Infof("get node resource metric failed, error %v", queryResult.Error)
+		return
+	}
+	metric := queryResult.Metric
+	e.send(ch, e.nodeCPUUsage, float64(metric.CPUUsed.CPUUsed.MilliValue())/1000, nodeName)
+	e.send(ch, e.nodeMemoryUsage, float64(metric.MemoryUsed.MemoryWithoutCache.Value()), nodeName)
+	for _, gpu := range metric.GPUs {
+		minor := strconv.Itoa(int(gpu.Minor))
+		e.send(ch, e.nodeGPUCoreUsage, float64(gpu.SMUtil), nodeName, minor, gpu.DeviceUUID)
+		e.send(ch, e.nodeGPUMemoryUsage, float64(gpu.MemoryUsed.Value()), nodeName, minor, gpu.DeviceUUID)
+		e.send(ch, e.nodeGPUMemoryTotal, float64(gpu.MemoryTotal.Value()), nodeName, minor, gpu.DeviceUUID)
+	}
+}
+
+func (e *Exporter) collectPod(ch chan<- prometheus.Metric, nodeName string, pod *corev1.Pod, queryParam *metriccache.QueryParam) {
+	podUID := string(pod.UID)
+	podLabels := []string{nodeName, podUID, pod.Name, pod.Namespace}
+	for _, key := range e.config.MetricsExporterPodLabels {
+		podLabels = append(podLabels, pod.Labels[key])
+	}
+	withLabels := func(labels ...string) []string {
+		return append(append([]string{}, podLabels...), labels...)
+	}
+
+	queryResult := e.metricCache.GetPodResourceMetric(&podUID, queryParam)
+	if queryResult.Error == nil && queryResult.Metric != nil {
+		metric := queryResult.Metric
+		e.send(ch, e.podCPUUsage, float64(metric.CPUUsed.CPUUsed.MilliValue())/1000, podLabels...)
+		e.send(ch, e.podMemoryUsage, float64(metric.MemoryUsed.MemoryWithoutCache.Value()), podLabels...)
+		for _, gpu := range metric.GPUs {
+			minor := strconv.Itoa(int(gpu.Minor))
+			e.send(ch, e.podGPUCoreUsage, float64(gpu.SMUtil), withLabels(minor, gpu.DeviceUUID)...)
+			e.send(ch, e.podGPUMemoryUsage, float64(gpu.MemoryUsed.Value()), withLabels(minor, gpu.DeviceUUID)...)
+		}
+	} else {
+		klog.V(5).Infof("get pod %v resource metric failed, error %v", podUID, queryResult.Error)
+	}
+
+	psiResult := e.metricCache.GetPodInterferenceMetric(metriccache.MetricNamePodPSI, &podUID, queryParam)
+	if psiResult.Error == nil && psiResult.Metric != nil {
+		if psi, ok := psiResult.Metric.MetricValue.(*metriccache.PSIMetric); ok {
+			e.send(ch, e.podPSI, psi.SomeCPUAvg10, withLabels(metrics.ResourceTypeCPU, metrics.DegreeSome)...)
+			e.send(ch, e.podPSI, psi.SomeMemAvg10, withLabels(metrics.ResourceTypeMem, metrics.DegreeSome)...)
+			e.send(ch, e.podPSI, psi.SomeIOAvg10, withLabels(metrics.ResourceTypeIO, metrics.DegreeSome)...)
+			if psi.CPUFullSupported {
+				e.send(ch, e.podPSI, psi.FullCPUAvg10, withLabels(metrics.ResourceTypeCPU, metrics.DegreeFull)...)
+			}
+			e.send(ch, e.podPSI, psi.FullMemAvg10, withLabels(metrics.ResourceTypeMem, metrics.DegreeFull)...)
+			e.send(ch, e.podPSI, psi.FullIOAvg10, withLabels(metrics.ResourceTypeIO, metrics.DegreeFull)...)
+		}
+	}
+
+	for i := range pod.Status.ContainerStatuses {
+		status := &pod.Status.ContainerStatuses[i]
+		if status.ContainerID == "" {
+			continue
+		}
+		containerResult := e.metricCache.GetContainerResourceMetric(&status.ContainerID, queryParam)
+		if containerResult.Error != nil || containerResult.Metric == nil {
+			klog.V(5).Infof("get container %v resource metric failed, error %v", status.ContainerID, containerResult.Error)
+			continue
+		}
+		metric := containerResult.Metric
+		containerLabels := withLabels(status.ContainerID, status.Name)
+		e.send(ch, e.containerCPUUsage, float64(metric.CPUUsed.CPUUsed.MilliValue())/1000, containerLabels...)
+		e.send(ch, e.containerMemoryUsage, float64(metric.MemoryUsed.MemoryWithoutCache.Value()), containerLabels...)
+		for _, gpu := range metric.GPUs {
+			e.send(ch, e.containerGPUCoreUsage, float64(gpu.SMUtil),
+				append(append([]string{}, containerLabels...), strconv.Itoa(int(gpu.Minor)), gpu.DeviceUUID)...)
+		}
+	}
+}
+
+func (e *Exporter) send(ch chan<- prometheus.Metric, desc *prometheus.Desc, value float64, labelValues ...string) {
+	metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, value, labelValues...)
+	if err != nil {
+		klog.V(4).Infof("failed to export metric %v, err: %v", desc, err)
+		return
+	}
+	ch <- metric
+}
+
+func (e *Exporter) generateQueryParam() *metriccache.QueryParam {
+	end := time.Now()
+	start := end.Add(-time.Duration(e.config.MetricsExporterQueryWindowSeconds) * time.Second)
+	return &metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeLast,
+		Start:     &start,
+		End:       &end,
+	}
+}
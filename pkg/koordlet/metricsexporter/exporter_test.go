@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsexporter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  func(cfg *Config)
+		wantErr bool
+	}{
+		{
+			name:   "default config",
+			config: func(cfg *Config) {},
+		},
+		{
+			name: "valid labels",
+			config: func(cfg *Config) {
+				cfg.MetricsExporterPodLabels = []string{"app", "koordinator.sh/qosClass"}
+				cfg.MetricsExporterStaticLabels = map[string]string{"cluster": "test"}
+			},
+		},
+		{
+			name: "invalid query window",
+			config: func(cfg *Config) {
+				cfg.MetricsExporterQueryWindowSeconds = 0
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid static label name",
+			config: func(cfg *Config) {
+				cfg.MetricsExporterStaticLabels = map[string]string{"cluster-name": "test"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "static label conflicts with metric labels",
+			config: func(cfg *Config) {
+				cfg.MetricsExporterStaticLabels = map[string]string{"node": "test"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "pod labels conflict after sanitized",
+			config: func(cfg *Config) {
+				cfg.MetricsExporterPodLabels = []string{"app.name", "app/name"}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultConfig()
+			tt.config(cfg)
+			_, err := NewExporter(cfg, nil, nil)
+			assert.Equal(t, tt.wantErr, err != nil, fmt.Sprintf("got err %v", err))
+		})
+	}
+}
+
+func TestExporter_Collect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "uid-1",
+			Labels:    map[string]string{"app": "test"},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "main", ContainerID: "containerd://c1"},
+				{Name: "sidecar", ContainerID: "containerd://c2"},
+				{Name: "pending"},
+			},
+		},
+	}
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetNode().Return(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}).AnyTimes()
+	si.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{Pod: pod}, {}}).AnyTimes()
+	mc := mockmetriccache.NewMockMetricCache(ctrl)
+	mc.EXPECT().GetNodeResourceMetric(gomock.Any()).Return(metriccache.NodeResourceQueryResult{
+		Metric: &metriccache.NodeResourceMetric{
+			CPUUsed:    metriccache.CPUMetric{CPUUsed: resource.MustParse("2500m")},
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("4Gi")},
+			GPUs: []metriccache.GPUMetric{
+				{
+					Minor:       0,
+					DeviceUUID:  "gpu-0",
+					SMUtil:      50,
+					MemoryUsed:  resource.MustParse("1Gi"),
+					MemoryTotal: resource.MustParse("8Gi"),
+				},
+			},
+		},
+	}).AnyTimes()
+	mc.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{
+		Metric: &metriccache.PodResourceMetric{
+			PodUID:     "uid-1",
+			CPUUsed:    metriccache.CPUMetric{CPUUsed: resource.MustParse("1500m")},
+			MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("1Gi")},
+		},
+	}).AnyTimes()
+	mc.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodPSI, gomock.Any(), gomock.Any()).Return(metriccache.PodInterferenceQueryResult{
+		Metric: &metriccache.PodInterferenceMetric{
+			MetricName: metriccache.MetricNamePodPSI,
+			PodUID:     "uid-1",
+			MetricValue: &metriccache.PSIMetric{
+				SomeCPUAvg10: 1.5,
+				SomeMemAvg10: 0.5,
+				FullMemAvg10: 0.2,
+			},
+		},
+	}).AnyTimes()
+	mc.EXPECT().GetContainerResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(func(containerID *string, _ *metriccache.QueryParam) metriccache.ContainerResourceQueryResult {
+		if *containerID != "containerd://c1" {
+			return metriccache.ContainerResourceQueryResult{QueryResult: metriccache.QueryResult{Error: fmt.Errorf("not found")}}
+		}
+		return metriccache.ContainerResourceQueryResult{
+			Metric: &metriccache.ContainerResourceMetric{
+				ContainerID: *containerID,
+				CPUUsed:     metriccache.CPUMetric{CPUUsed: resource.MustParse("1")},
+				MemoryUsed:  metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("512Mi")},
+			},
+		}
+	}).AnyTimes()
+
+	cfg := NewDefaultConfig()
+	cfg.MetricsExporterPodLabels = []string{"app", "not-exist"}
+	cfg.MetricsExporterStaticLabels = map[string]string{"cluster": "test"}
+	e, err := NewExporter(cfg, si, mc)
+	assert.NoError(t, err)
+
+	expected := `
+# HELP koordlet_node_cpu_usage_cores the cpu usage of the node in cores
+# TYPE koordlet_node_cpu_usage_cores gauge
+koordlet_node_cpu_usage_cores{cluster="test",node="test-node"} 2.5
+# HELP koordlet_node_gpu_memory_total_bytes the total memory of the gpu devices on the node
+# TYPE koordlet_node_gpu_memory_total_bytes gauge
+koordlet_node_gpu_memory_total_bytes{cluster="test",minor="0",node="test-node",uuid="gpu-0"} 8.589934592e+09
+# HELP koordlet_pod_memory_usage_bytes the memory usage of the pod without the page cache
+# TYPE koordlet_pod_memory_usage_bytes gauge
+koordlet_pod_memory_usage_bytes{cluster="test",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1"} 1.073741824e+09
+# HELP koordlet_pod_psi_avg10 the 10-second average pressure stall of the pod in percent
+# TYPE koordlet_pod_psi_avg10 gauge
+koordlet_pod_psi_avg10{cluster="test",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1",psi_degree="full",psi_resource_type="io"} 0
+koordlet_pod_psi_avg10{cluster="test",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1",psi_degree="full",psi_resource_type="mem"} 0.2
+koordlet_pod_psi_avg10{cluster="test",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1",psi_degree="some",psi_resource_type="cpu"} 1.5
+koordlet_pod_psi_avg10{cluster="test",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1",psi_degree="some",psi_resource_type="io"} 0
+koordlet_pod_psi_avg10{cluster="test",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1",psi_degree="some",psi_resource_type="mem"} 0.5
+# HELP koordlet_container_cpu_usage_cores the cpu usage of the container in cores
+# TYPE koordlet_container_cpu_usage_cores gauge
+koordlet_container_cpu_usage_cores{cluster="test",container_id="containerd://c1",container_name="main",label_app="test",label_not_exist="",node="test-node",pod_name="test-pod",pod_namespace="default",pod_uid="uid-1"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(e, strings.NewReader(expected),
+		"koordlet_node_cpu_usage_cores", "koordlet_node_gpu_memory_total_bytes", "koordlet_pod_memory_usage_bytes",
+		"koordlet_pod_psi_avg10", "koordlet_container_cpu_usage_cores"))
+	// 5 node metrics, 2 pod usages, 5 pod psi and 2 container usages
+	assert.Equal(t, 14, testutil.CollectAndCount(e))
+}
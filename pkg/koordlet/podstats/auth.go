@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podstats
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// peerCredentials is a server-side TransportCredentials which records the uid of the peer process
+// connected through the unix socket. It does not provide any transport security.
+type peerCredentials struct{}
+
+var _ credentials.TransportCredentials = peerCredentials{}
+
+// peerAuthInfo is the AuthInfo of the connections accepted with peerCredentials.
+type peerAuthInfo struct {
+	credentials.CommonAuthInfo
+	UID uint32
+}
+
+func (peerAuthInfo) AuthType() string {
+	return "peercred"
+}
+
+func (peerCredentials) ClientHandshake(_ context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, peerAuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}, nil
+}
+
+func (peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	uid, err := getPeerUID(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, peerAuthInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}, UID: uid}, nil
+}
+
+func (peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// authorizer checks whether the peer process is allowed to access the server.
+type authorizer struct {
+	allowedUIDs map[uint32]struct{}
+}
+
+func (a *authorizer) authorize(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return status.Error(codes.Unauthenticated, "missing peer credentials")
+	}
+	info, ok := p.AuthInfo.(peerAuthInfo)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "unknown peer credentials")
+	}
+	if _, ok := a.allowedUIDs[info.UID]; !ok {
+		return status.Errorf(codes.PermissionDenied, "uid %d is not allowed to access pod stats", info.UID)
+	}
+	return nil
+}
+
+func (a *authorizer) unaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authorizer) streamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podstats
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	cliflag "k8s.io/component-base/cli/flag"
+)
+
+type Config struct {
+	// PodStatsServerAddr is the path of the unix socket the server listens on.
+	PodStatsServerAddr string
+	// PodStatsServerAllowedUIDs are the uids of the local processes allowed to access the server
+	// besides root.
+	PodStatsServerAllowedUIDs []string
+	// PodStatsServerWatchIntervalSeconds is the default interval of WatchPodStats.
+	PodStatsServerWatchIntervalSeconds int64
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		PodStatsServerAddr:                 "/host-var-run-koordlet/koordlet-podstats.sock",
+		PodStatsServerAllowedUIDs:          []string{},
+		PodStatsServerWatchIntervalSeconds: 10,
+	}
+}
+
+func (c *Config) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.PodStatsServerAddr, "pod-stats-server-addr", c.PodStatsServerAddr, "unix socket path of the rpc server for pod stats")
+	fs.Var(cliflag.NewStringSlice(&c.PodStatsServerAllowedUIDs), "pod-stats-server-allowed-uids", "uids of the local processes allowed to access the pod stats server besides root")
+	fs.Int64Var(&c.PodStatsServerWatchIntervalSeconds, "pod-stats-server-watch-interval-seconds", c.PodStatsServerWatchIntervalSeconds, "default interval in seconds for watching the pod stats")
+}
+
+func (c *Config) parseAllowedUIDs() (map[uint32]struct{}, error) {
+	uids := map[uint32]struct{}{
+		0: {},
+	}
+	for _, s := range c.PodStatsServerAllowedUIDs {
+		uid, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod stats server allowed uid %q, err: %w", s, err)
+		}
+		uids[uint32(uid)] = struct{}{}
+	}
+	return uids, nil
+}
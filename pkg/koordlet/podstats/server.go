@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podstats
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	podstatsapi "github.com/koordinator-sh/koordinator/apis/koordlet/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+)
+
+const (
+	// podUsageQueryWindow is the time window to look up the latest usage of pods in the metric cache.
+	podUsageQueryWindow = 2 * time.Minute
+	// minWatchInterval limits the frequency of WatchPodStats.
+	minWatchInterval = time.Second
+)
+
+// Server exports the per-pod statistics of koordlet to the co-resident agents through a unix socket,
+// so that they do not need to read the cgroups by themselves.
+type Server interface {
+	Run(stopCh <-chan struct{}) error
+}
+
+type server struct {
+	config         *Config
+	statesInformer statesinformer.StatesInformer
+	metricCache    metriccache.MetricCache
+	authorizer     *authorizer
+	listener       net.Listener
+	server         *grpc.Server
+	podstatsapi.UnimplementedPodStatsServiceServer
+}
+
+func NewServer(cfg *Config, statesInformer statesinformer.StatesInformer, metricCache metriccache.MetricCache) (Server, error) {
+	allowedUIDs, err := cfg.parseAllowedUIDs()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PodStatsServerWatchIntervalSeconds <= 0 {
+		return nil, fmt.Errorf("invalid pod stats server watch interval %v", cfg.PodStatsServerWatchIntervalSeconds)
+	}
+	return &server{
+		config:         cfg,
+		statesInformer: statesInformer,
+		metricCache:    metricCache,
+		authorizer:     &authorizer{allowedUIDs: allowedUIDs},
+	}, nil
+}
+
+func (s *server) Run(stopCh <-chan struct{}) error {
+	if err := s.setup(); err != nil {
+		return err
+	}
+	klog.Infof("starting pod stats server on %s", s.config.PodStatsServerAddr)
+	go func() {
+		if err := s.server.Serve(s.listener); err != nil {
+			klog.Errorf("pod stats server stopped serving, err: %v", err)
+		}
+	}()
+	<-stopCh
+	klog.Infof("stopping pod stats server")
+	s.server.Stop()
+	return nil
+}
+
+func (s *server) setup() error {
+	if err := syscall.Unlink(s.config.PodStatsServerAddr); err != nil && !os.IsNotExist(err) {
+		klog.Infof("unlink error %v", err)
+	}
+	l, err := net.Listen("unix", s.config.PodStatsServerAddr)
+	if err != nil {
+		return fmt.Errorf("failed to create pod stats server, error: %w", err)
+	}
+	// the peer uid is checked on each call, and the socket file is restricted to root unless other uids are allowed
+	mode := os.FileMode(0600)
+	if len(s.authorizer.allowedUIDs) > 1 {
+		mode = 0666
+	}
+	if err = os.Chmod(s.config.PodStatsServerAddr, mode); err != nil {
+		l.Close()
+		return fmt.Errorf("failed to set mode of pod stats server socket, error: %w", err)
+	}
+	s.listener = l
+	s.server = grpc.NewServer(
+		grpc.Creds(peerCredentials{}),
+		grpc.UnaryInterceptor(s.authorizer.unaryInterceptor),
+		grpc.StreamInterceptor(s.authorizer.streamInterceptor),
+	)
+	podstatsapi.RegisterPodStatsServiceServer(s.server, s)
+	return nil
+}
+
+func (s *server) ListPodStats(ctx context.Context, req *podstatsapi.ListPodStatsRequest) (*podstatsapi.ListPodStatsResponse, error) {
+	return s.collectPodStats(req.GetFilter()), nil
+}
+
+func (s *server) WatchPodStats(req *podstatsapi.WatchPodStatsRequest, stream podstatsapi.PodStatsService_WatchPodStatsServer) error {
+	interval := time.Duration(s.config.PodStatsServerWatchIntervalSeconds) * time.Second
+	if req.GetIntervalSeconds() > 0 {
+		interval = time.Duration(req.GetIntervalSeconds()) * time.Second
+	}
+	if interval < minWatchInterval {
+		interval = minWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := stream.Send(s.collectPodStats(req.GetFilter())); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *server) collectPodStats(filter *podstatsapi.PodStatsFilter) *podstatsapi.ListPodStatsResponse {
+	now := time.Now()
+	resp := &podstatsapi.ListPodStatsResponse{
+		Timestamp: now.Unix(),
+	}
+	for _, podMeta := range s.statesInformer.GetAllPods() {
+		if podMeta == nil || podMeta.Pod == nil || !matchFilter(podMeta, filter) {
+			continue
+		}
+		pod := podMeta.Pod
+		resp.PodStats = append(resp.PodStats, &podstatsapi.PodStats{
+			Namespace:    pod.Namespace,
+			Name:         pod.Name,
+			Uid:          string(pod.UID),
+			QosClass:     string(extension.GetPodQoSClass(pod)),
+			KubeQosClass: string(pod.Status.QOSClass),
+			Usage:        s.getPodUsage(string(pod.UID), now),
+			QosParams:    getPodQoSParams(podMeta.CgroupDir),
+		})
+	}
+	return resp
+}
+
+func (s *server) getPodUsage(podUID string, now time.Time) *podstatsapi.PodUsage {
+	start := now.Add(-podUsageQueryWindow)
+	queryParam := &metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeLast,
+		Start:     &start,
+		End:       &now,
+	}
+	queryResult := s.metricCache.GetPodResourceMetric(&podUID, queryParam)
+	if queryResult.Error != nil {
+		klog.V(5).Infof("get pod %v resource metric failed, error %v", podUID, queryResult.Error)
+		return nil
+	}
+	if queryResult.Metric == nil {
+		return nil
+	}
+	usage := &podstatsapi.PodUsage{
+		CpuMilliCores: queryResult.Metric.CPUUsed.CPUUsed.MilliValue(),
+		MemoryBytes:   queryResult.Metric.MemoryUsed.MemoryWithoutCache.Value(),
+	}
+	if queryResult.AggregateInfo != nil && queryResult.AggregateInfo.MetricEnd != nil {
+		usage.UpdateTimestamp = queryResult.AggregateInfo.MetricEnd.Unix()
+	}
+	return usage
+}
+
+func getPodQoSParams(podCgroupDir string) *podstatsapi.PodQoSParams {
+	if podCgroupDir == "" {
+		return nil
+	}
+	params := &podstatsapi.PodQoSParams{
+		CgroupDir: koordletutil.GetPodCgroupDirWithKube(podCgroupDir),
+	}
+	var err error
+	if params.CpuShares, err = koordletutil.GetPodCurCPUShare(podCgroupDir); err != nil {
+		klog.V(5).Infof("get cpu shares of pod cgroup %s failed, err: %v", podCgroupDir, err)
+		return nil
+	}
+	if params.CfsQuotaUs, err = koordletutil.GetPodCurCFSQuota(podCgroupDir); err != nil {
+		klog.V(5).Infof("get cfs quota of pod cgroup %s failed, err: %v", podCgroupDir, err)
+		return nil
+	}
+	if params.CfsPeriodUs, err = koordletutil.GetPodCurCFSPeriod(podCgroupDir); err != nil {
+		klog.V(5).Infof("get cfs period of pod cgroup %s failed, err: %v", podCgroupDir, err)
+		return nil
+	}
+	if params.MemoryLimitBytes, err = koordletutil.GetPodCurMemLimitBytes(podCgroupDir); err != nil {
+		klog.V(5).Infof("get memory limit of pod cgroup %s failed, err: %v", podCgroupDir, err)
+		return nil
+	}
+	return params
+}
+
+func matchFilter(podMeta *statesinformer.PodMeta, filter *podstatsapi.PodStatsFilter) bool {
+	if filter == nil {
+		return true
+	}
+	pod := podMeta.Pod
+	if filter.Namespace != "" && filter.Namespace != pod.Namespace {
+		return false
+	}
+	if filter.Name != "" && filter.Name != pod.Name {
+		return false
+	}
+	if filter.Uid != "" && filter.Uid != string(pod.UID) {
+		return false
+	}
+	return true
+}
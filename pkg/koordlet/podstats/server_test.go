@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podstats
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	podstatsapi "github.com/koordinator-sh/koordinator/apis/koordlet/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mockstatesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func newTestPodMetas() []*statesinformer.PodMeta {
+	return []*statesinformer.PodMeta{
+		{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-ls-pod",
+					UID:       "uid-ls",
+					Labels: map[string]string{
+						extension.LabelPodQoS: string(extension.QoSLS),
+					},
+				},
+				Status: corev1.PodStatus{
+					QOSClass: corev1.PodQOSBurstable,
+				},
+			},
+			CgroupDir: "kubepods-burstable.slice/kubepods-burstable-poduid_ls.slice",
+		},
+		{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "kube-system",
+					Name:      "test-be-pod",
+					UID:       "uid-be",
+					Labels: map[string]string{
+						extension.LabelPodQoS: string(extension.QoSBE),
+					},
+				},
+				Status: corev1.PodStatus{
+					QOSClass: corev1.PodQOSBestEffort,
+				},
+			},
+			CgroupDir: "kubepods-besteffort.slice/kubepods-besteffort-poduid_be.slice",
+		},
+	}
+}
+
+func newTestServer(t *testing.T, ctrl *gomock.Controller, cfg *Config) *server {
+	podMetas := newTestPodMetas()
+	si := mockstatesinformer.NewMockStatesInformer(ctrl)
+	si.EXPECT().GetAllPods().Return(podMetas).AnyTimes()
+	mc := mockmetriccache.NewMockMetricCache(ctrl)
+	mc.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).DoAndReturn(func(podUID *string, _ *metriccache.QueryParam) metriccache.PodResourceQueryResult {
+		if *podUID != "uid-ls" {
+			return metriccache.PodResourceQueryResult{}
+		}
+		return metriccache.PodResourceQueryResult{
+			Metric: &metriccache.PodResourceMetric{
+				PodUID:     "uid-ls",
+				CPUUsed:    metriccache.CPUMetric{CPUUsed: resource.MustParse("1500m")},
+				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: resource.MustParse("1Gi")},
+			},
+		}
+	}).AnyTimes()
+	s, err := NewServer(cfg, si, mc)
+	assert.NoError(t, err)
+	return s.(*server)
+}
+
+func Test_server_ListPodStats(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	lsCgroupDir := koordletutil.GetPodCgroupDirWithKube(newTestPodMetas()[0].CgroupDir)
+	helper.WriteCgroupFileContents(lsCgroupDir, system.CPUShares, "2048")
+	helper.WriteCgroupFileContents(lsCgroupDir, system.CPUCFSQuota, "200000")
+	helper.WriteCgroupFileContents(lsCgroupDir, system.CPUCFSPeriod, "100000")
+	helper.WriteCgroupFileContents(lsCgroupDir, system.MemoryLimit, "4294967296")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	s := newTestServer(t, ctrl, NewDefaultConfig())
+
+	tests := []struct {
+		name   string
+		filter *podstatsapi.PodStatsFilter
+		want   []*podstatsapi.PodStats
+	}{
+		{
+			name:   "filter ls pod by namespace",
+			filter: &podstatsapi.PodStatsFilter{Namespace: "default"},
+			want: []*podstatsapi.PodStats{
+				{
+					Namespace:    "default",
+					Name:         "test-ls-pod",
+					Uid:          "uid-ls",
+					QosClass:     string(extension.QoSLS),
+					KubeQosClass: string(corev1.PodQOSBurstable),
+					Usage: &podstatsapi.PodUsage{
+						CpuMilliCores: 1500,
+						MemoryBytes:   1 << 30,
+					},
+					QosParams: &podstatsapi.PodQoSParams{
+						CgroupDir:        lsCgroupDir,
+						CpuShares:        2048,
+						CfsQuotaUs:       200000,
+						CfsPeriodUs:      100000,
+						MemoryLimitBytes: 4294967296,
+					},
+				},
+			},
+		},
+		{
+			name:   "be pod without usage and cgroup",
+			filter: &podstatsapi.PodStatsFilter{Uid: "uid-be"},
+			want: []*podstatsapi.PodStats{
+				{
+					Namespace:    "kube-system",
+					Name:         "test-be-pod",
+					Uid:          "uid-be",
+					QosClass:     string(extension.QoSBE),
+					KubeQosClass: string(corev1.PodQOSBestEffort),
+				},
+			},
+		},
+		{
+			name:   "no pod matched",
+			filter: &podstatsapi.PodStatsFilter{Namespace: "default", Name: "test-be-pod"},
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.ListPodStats(context.TODO(), &podstatsapi.ListPodStatsRequest{Filter: tt.filter})
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.want), len(got.PodStats))
+			for i := range tt.want {
+				assert.Equal(t, tt.want[i].String(), got.PodStats[i].String())
+			}
+		})
+	}
+
+	got, err := s.ListPodStats(context.TODO(), &podstatsapi.ListPodStatsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(got.PodStats))
+}
+
+func Test_authorizer_authorize(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.PodStatsServerAllowedUIDs = []string{"1000"}
+	allowedUIDs, err := cfg.parseAllowedUIDs()
+	assert.NoError(t, err)
+	a := &authorizer{allowedUIDs: allowedUIDs}
+
+	newPeerContext := func(authInfo peerAuthInfo) context.Context {
+		return peer.NewContext(context.TODO(), &peer.Peer{Addr: &net.UnixAddr{}, AuthInfo: authInfo})
+	}
+	assert.NoError(t, a.authorize(newPeerContext(peerAuthInfo{UID: 0})))
+	assert.NoError(t, a.authorize(newPeerContext(peerAuthInfo{UID: 1000})))
+	assert.Equal(t, codes.PermissionDenied, status.Code(a.authorize(newPeerContext(peerAuthInfo{UID: 1001}))))
+	assert.Equal(t, codes.Unauthenticated, status.Code(a.authorize(context.TODO())))
+
+	cfg.PodStatsServerAllowedUIDs = []string{"nobody"}
+	_, err = cfg.parseAllowedUIDs()
+	assert.Error(t, err)
+}
+
+func Test_server_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cfg := NewDefaultConfig()
+	cfg.PodStatsServerAddr = filepath.Join(t.TempDir(), "podstats.sock")
+	s := newTestServer(t, ctrl, cfg)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go func() {
+		assert.NoError(t, s.Run(stopCh))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "unix://"+cfg.PodStatsServerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := podstatsapi.NewPodStatsServiceClient(conn)
+
+	// only root is allowed by default
+	var resp *podstatsapi.ListPodStatsResponse
+	assert.Eventually(t, func() bool {
+		resp, err = client.ListPodStats(ctx, &podstatsapi.ListPodStatsRequest{})
+		return status.Code(err) != codes.Unavailable
+	}, 5*time.Second, 100*time.Millisecond)
+	if os.Geteuid() != 0 {
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		return
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(resp.PodStats))
+
+	stream, err := client.WatchPodStats(ctx, &podstatsapi.WatchPodStatsRequest{
+		Filter:          &podstatsapi.PodStatsFilter{Uid: "uid-ls"},
+		IntervalSeconds: 1,
+	})
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		got, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(got.PodStats))
+		assert.Equal(t, "test-ls-pod", got.PodStats[0].Name)
+	}
+}
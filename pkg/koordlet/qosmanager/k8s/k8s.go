@@ -29,6 +29,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/common/reason"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
@@ -97,6 +98,9 @@ func (r *k8sClient) evictPod(evictPod *corev1.Pod, node *corev1.Node, reasonMsg
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      evictPod.Name,
 			Namespace: evictPod.Namespace,
+			Annotations: map[string]string{
+				apiext.AnnotationEvictionInitiator: apiext.EvictionInitiatorKoordlet,
+			},
 		},
 	}
 
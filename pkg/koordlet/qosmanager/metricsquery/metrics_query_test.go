@@ -107,6 +107,16 @@ func Test_collectNodeMetricsAvg(t *testing.T) {
 			expectNodeMetric: &metriccache.NodeResourceMetric{
 				CPUUsed:    metriccache.CPUMetric{CPUUsed: *resource.NewMilliQuantity(15000, resource.DecimalSI)},
 				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(65000000000, resource.BinarySI)},
+				NetworkUsed: metriccache.NetworkMetric{
+					RxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+					TxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+				},
+				DiskIOUsed: metriccache.DiskIOMetric{
+					ReadBytesPerSec:  *resource.NewQuantity(0, resource.DecimalSI),
+					WriteBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+					ReadIOPS:         *resource.NewQuantity(0, resource.DecimalSI),
+					WriteIOPS:        *resource.NewQuantity(0, resource.DecimalSI),
+				},
 			},
 		},
 	}
@@ -180,11 +190,31 @@ func Test_collectNodeAndPodMetricLast(t *testing.T) {
 			expectNodeMetric: &metriccache.NodeResourceMetric{
 				CPUUsed:    metriccache.CPUMetric{CPUUsed: *resource.NewMilliQuantity(16000, resource.DecimalSI)},
 				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(70000000000, resource.BinarySI)},
+				NetworkUsed: metriccache.NetworkMetric{
+					RxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+					TxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+				},
+				DiskIOUsed: metriccache.DiskIOMetric{
+					ReadBytesPerSec:  *resource.NewQuantity(0, resource.DecimalSI),
+					WriteBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+					ReadIOPS:         *resource.NewQuantity(0, resource.DecimalSI),
+					WriteIOPS:        *resource.NewQuantity(0, resource.DecimalSI),
+				},
 			},
 			expectPodMetric: &metriccache.PodResourceMetric{
 				PodUID:     "test_pod",
 				CPUUsed:    metriccache.CPUMetric{CPUUsed: *resource.NewMilliQuantity(16000, resource.DecimalSI)},
 				MemoryUsed: metriccache.MemoryMetric{MemoryWithoutCache: *resource.NewQuantity(70000000000, resource.BinarySI)},
+				NetworkUsed: metriccache.NetworkMetric{
+					RxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+					TxBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+				},
+				DiskIOUsed: metriccache.DiskIOMetric{
+					ReadBytesPerSec:  *resource.NewQuantity(0, resource.DecimalSI),
+					WriteBytesPerSec: *resource.NewQuantity(0, resource.DecimalSI),
+					ReadIOPS:         *resource.NewQuantity(0, resource.DecimalSI),
+					WriteIOPS:        *resource.NewQuantity(0, resource.DecimalSI),
+				},
 			},
 		},
 	}
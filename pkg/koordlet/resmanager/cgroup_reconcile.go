@@ -38,6 +38,9 @@ import (
 type CgroupResourcesReconcile struct {
 	resmanager *resmanager
 	executor   resourceexecutor.ResourceUpdateExecutor
+	// useMemorySoftLimit indicates the memory.low is not supported on the cgroups-v1 node, then the
+	// memory.soft_limit_in_bytes is set instead to make the kernel reclaim the memory of the BE pods first.
+	useMemorySoftLimit bool
 }
 
 // cgroupResourceSummary summarizes values of cgroup resources to update; nil value means not to update
@@ -46,6 +49,7 @@ type cgroupResourceSummary struct {
 	memoryMin              *int64
 	memoryLow              *int64
 	memoryHigh             *int64
+	memorySoftLimit        *int64
 	memoryWmarkRatio       *int64
 	memoryWmarkScaleFactor *int64
 	memoryWmarkMinAdj      *int64
@@ -63,8 +67,9 @@ type cgroupResourceUpdaterMeta struct {
 func NewCgroupResourcesReconcile(resmanager *resmanager) *CgroupResourcesReconcile {
 	e := resourceexecutor.NewResourceUpdateExecutor()
 	return &CgroupResourcesReconcile{
-		resmanager: resmanager,
-		executor:   e,
+		resmanager:         resmanager,
+		executor:           e,
+		useMemorySoftLimit: !isMemoryLowSupported(),
 	}
 }
 
@@ -180,6 +185,8 @@ func (m *CgroupResourcesReconcile) calculateQoSResources(summary *cgroupResource
 		summary.memoryPriority = qosCfg.MemoryQOS.Priority
 		summary.memoryOomKillGroup = qosCfg.MemoryQOS.OomKillGroup
 	}
+	// NOTE: the qos-level soft limits are kept unlimited on the memory.low fallback, since the kernel picks the cgroup
+	// exceeding its soft limit the most and reclaims its children alike, which would spare the BE pods
 
 	return makeCgroupResources(qosDir, summary)
 }
@@ -243,7 +250,7 @@ func (m *CgroupResourcesReconcile) calculatePodResources(pod *corev1.Pod, parent
 			summary.memoryMin = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQOS.MinLimitPercent) / 100)
 		}
 		if podCfg.MemoryQOS.LowLimitPercent != nil {
-			summary.memoryLow = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQOS.LowLimitPercent) / 100)
+			summary.memoryLow = pointer.Int64Ptr(calculateMemoryLow(pod, memRequest, *podCfg.MemoryQOS.LowLimitPercent))
 		}
 		// values improved: memory.low is no less than memory.min
 		if summary.memoryMin != nil && summary.memoryLow != nil && *summary.memoryLow > 0 &&
@@ -252,6 +259,7 @@ func (m *CgroupResourcesReconcile) calculatePodResources(pod *corev1.Pod, parent
 			klog.V(5).Infof("correct calculated memory.low for pod since it is lower than memory.min, "+
 				"pod %s, current value %v", util.GetPodKey(pod), summary.memoryLow)
 		}
+		m.fallbackToMemorySoftLimit(summary, apiext.GetPodQoSClass(pod) == apiext.QoSBE)
 	}
 
 	return makeCgroupResources(parentDir, summary)
@@ -294,7 +302,7 @@ func (m *CgroupResourcesReconcile) calculateContainerResources(container *corev1
 			summary.memoryMin = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQOS.MinLimitPercent) / 100)
 		}
 		if podCfg.MemoryQOS.LowLimitPercent != nil {
-			summary.memoryLow = pointer.Int64Ptr(memRequest * (*podCfg.MemoryQOS.LowLimitPercent) / 100)
+			summary.memoryLow = pointer.Int64Ptr(calculateMemoryLow(pod, memRequest, *podCfg.MemoryQOS.LowLimitPercent))
 		}
 		// memory.high: if container's memory throttling factor is set as zero, disable memory.high by set to maximal;
 		// else if factor is set while container's limit not set, set memory.high with node memory allocatable
@@ -322,6 +330,7 @@ func (m *CgroupResourcesReconcile) calculateContainerResources(container *corev1
 			klog.V(5).Infof("correct calculated memory.high for container since it is lower than memory.min,"+
 				" pod %s, container %s, current value %v", util.GetPodKey(pod), container.Name, *summary.memoryHigh)
 		}
+		m.fallbackToMemorySoftLimit(summary, apiext.GetPodQoSClass(pod) == apiext.QoSBE)
 	}
 
 	return makeCgroupResources(parentDir, summary)
@@ -418,10 +427,52 @@ func updateCgroupSummaryForQoS(summary *cgroupResourceSummary, pod *corev1.Pod,
 		if summary.memoryLow == nil {
 			summary.memoryLow = pointer.Int64Ptr(0)
 		}
-		*summary.memoryLow += memRequest * (*podCfg.MemoryQOS.LowLimitPercent) / 100
+		*summary.memoryLow += calculateMemoryLow(pod, memRequest, *podCfg.MemoryQOS.LowLimitPercent)
 	}
 }
 
+// calculateMemoryLow calculates the memory.low proportional to the memory request for the non-BE pods. The BE pods
+// are never protected, so that the kernel reclaim lands on the BE pods first under memory pressure.
+func calculateMemoryLow(pod *corev1.Pod, memRequest int64, lowLimitPercent int64) int64 {
+	if apiext.GetPodQoSClass(pod) == apiext.QoSBE || memRequest <= 0 {
+		return 0
+	}
+	// assert no overflow for request < 1PiB
+	return memRequest * lowLimitPercent / 100
+}
+
+// fallbackToMemorySoftLimit sets the memory.soft_limit_in_bytes with the calculated memory.low when the memory.low
+// is unsupported on cgroups-v1. The soft limit works the other way around: the cgroups exceeding their soft limits
+// are reclaimed first under global memory pressure. So the BE cgroups get zero soft limits to be reclaimed first,
+// the protected ones get the memory.low, and the unprotected non-BE ones are reset to unlimited.
+func (m *CgroupResourcesReconcile) fallbackToMemorySoftLimit(summary *cgroupResourceSummary, isBE bool) {
+	if !m.useMemorySoftLimit || summary.memoryLow == nil {
+		return
+	}
+	if isBE {
+		summary.memorySoftLimit = pointer.Int64Ptr(0)
+	} else if *summary.memoryLow > 0 {
+		summary.memorySoftLimit = pointer.Int64Ptr(*summary.memoryLow)
+	} else {
+		summary.memorySoftLimit = pointer.Int64Ptr(math.MaxInt64) // writing MaxInt64 is equal to unlimited
+	}
+	// memory.low is not writable
+	summary.memoryLow = nil
+}
+
+// isMemoryLowSupported checks if the memory.low is available, which is always true on cgroups-v2 and requires the
+// kernel support (e.g. anolis os) on cgroups-v1.
+func isMemoryLowSupported() bool {
+	if system.UseCgroupsV2 {
+		return true
+	}
+	supported, msg := system.SupportedIfFileExistsInKubepods(system.MemoryLowName, system.CgroupMemDir)
+	if !supported {
+		klog.V(4).Infof("memory.low is unsupported, fallback to memory.soft_limit_in_bytes, msg: %s", msg)
+	}
+	return supported
+}
+
 // completeCgroupSummaryForQoS completes qos cgroup summary considering Guaranteed qos is higher than the others
 func completeCgroupSummaryForQoS(qosSummary map[corev1.PodQOSClass]*cgroupResourceSummary) {
 	// memory qos
@@ -473,6 +524,10 @@ func makeCgroupResources(parentDir string, summary *cgroupResourceSummary) []res
 			value:        summary.memoryHigh,
 			isMergeable:  true,
 		},
+		{
+			resourceType: system.MemorySoftLimitName,
+			value:        summary.memorySoftLimit,
+		},
 		{
 			resourceType: system.MemoryWmarkRatioName,
 			value:        summary.memoryWmarkRatio,
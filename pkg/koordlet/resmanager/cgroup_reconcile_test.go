@@ -1003,6 +1003,83 @@ func TestCgroupResourcesReconcile_getMergedPodResourceQoS(t *testing.T) {
 	}
 }
 
+func TestCgroupResourcesReconcile_calculatePodResourcesWithMemoryLow(t *testing.T) {
+	podCfg := func(lowLimitPercent int64) *slov1alpha1.ResourceQOS {
+		return &slov1alpha1.ResourceQOS{
+			MemoryQOS: &slov1alpha1.MemoryQOSCfg{
+				MemoryQOS: slov1alpha1.MemoryQOS{
+					LowLimitPercent: pointer.Int64Ptr(lowLimitPercent),
+				},
+			},
+		}
+	}
+	podLS := createPod(corev1.PodQOSBurstable, apiext.QoSLS).Pod
+	podBE := createPod(corev1.PodQOSBestEffort, apiext.QoSBE).Pod
+	tests := []struct {
+		name               string
+		useMemorySoftLimit bool
+		pod                *corev1.Pod
+		podCfg             *slov1alpha1.ResourceQOS
+		want               []resourceexecutor.ResourceUpdater
+	}{
+		{
+			name:   "memory.low proportional to the request for LS pod",
+			pod:    podLS,
+			podCfg: podCfg(80),
+			want: []resourceexecutor.ResourceUpdater{
+				createCgroupResourceUpdater(t, system.MemoryLowName, "pod0", strconv.FormatInt(testingPodMemRequestLimitBytes*80/100, 10), true),
+			},
+		},
+		{
+			name:   "memory.low is zero for BE pod",
+			pod:    podBE,
+			podCfg: podCfg(80),
+			want: []resourceexecutor.ResourceUpdater{
+				createCgroupResourceUpdater(t, system.MemoryLowName, "pod0", "0", true),
+			},
+		},
+		{
+			name:               "fallback to soft limit for LS pod",
+			useMemorySoftLimit: true,
+			pod:                podLS,
+			podCfg:             podCfg(80),
+			want: []resourceexecutor.ResourceUpdater{
+				createCgroupResourceUpdater(t, system.MemorySoftLimitName, "pod0", strconv.FormatInt(testingPodMemRequestLimitBytes*80/100, 10), false),
+			},
+		},
+		{
+			name:               "fallback to soft limit for BE pod",
+			useMemorySoftLimit: true,
+			pod:                podBE,
+			podCfg:             podCfg(80),
+			want: []resourceexecutor.ResourceUpdater{
+				createCgroupResourceUpdater(t, system.MemorySoftLimitName, "pod0", "0", false),
+			},
+		},
+		{
+			name:               "fallback to unlimited soft limit for unprotected LS pod",
+			useMemorySoftLimit: true,
+			pod:                podLS,
+			podCfg:             podCfg(0),
+			want: []resourceexecutor.ResourceUpdater{
+				createCgroupResourceUpdater(t, system.MemorySoftLimitName, "pod0", strconv.FormatInt(math.MaxInt64, 10), false),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			helper.SetCgroupsV2(false)
+			defer helper.Cleanup()
+
+			m := newTestCgroupResourcesReconcile(nil)
+			m.useMemorySoftLimit = tt.useMemorySoftLimit
+			got := m.calculatePodResources(tt.pod, "pod0", tt.podCfg)
+			assertCgroupResourceEqual(t, tt.want, got)
+		})
+	}
+}
+
 func Test_makeCgroupResources(t *testing.T) {
 	type fields struct {
 		notAnolisOS bool
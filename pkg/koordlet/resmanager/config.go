@@ -23,24 +23,33 @@ import (
 )
 
 type Config struct {
-	ReconcileIntervalSeconds   int
-	CPUSuppressIntervalSeconds int
-	CPUEvictIntervalSeconds    int
-	MemoryEvictIntervalSeconds int
-	MemoryEvictCoolTimeSeconds int
-	CPUEvictCoolTimeSeconds    int
-	QOSExtensionCfg            *plugins.QOSExtensionConfig
+	ReconcileIntervalSeconds          int
+	CPUSuppressIntervalSeconds        int
+	CPUEvictIntervalSeconds           int
+	MemoryEvictIntervalSeconds        int
+	MemoryEvictCoolTimeSeconds        int
+	CPUEvictCoolTimeSeconds           int
+	InterferenceDetectIntervalSeconds int
+	// MemoryBandwidthContentionThresholdMBps is the memory bandwidth of the node in MB/s measured by the resctrl
+	// monitoring, below which the MBA throttling of the resctrl groups is lifted. 0 means always throttling.
+	MemoryBandwidthContentionThresholdMBps int
+	// NodeTuningOriginsPath is the file to persist the original values of the files tuned by the node tuning, so that
+	// they can be rolled back after koordlet restarts.
+	NodeTuningOriginsPath string
+	QOSExtensionCfg       *plugins.QOSExtensionConfig
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		ReconcileIntervalSeconds:   1,
-		CPUSuppressIntervalSeconds: 1,
-		CPUEvictIntervalSeconds:    1,
-		MemoryEvictIntervalSeconds: 1,
-		MemoryEvictCoolTimeSeconds: 4,
-		CPUEvictCoolTimeSeconds:    20,
-		QOSExtensionCfg:            &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{}},
+		ReconcileIntervalSeconds:          1,
+		CPUSuppressIntervalSeconds:        1,
+		CPUEvictIntervalSeconds:           1,
+		MemoryEvictIntervalSeconds:        1,
+		MemoryEvictCoolTimeSeconds:        4,
+		CPUEvictCoolTimeSeconds:           20,
+		InterferenceDetectIntervalSeconds: 60,
+		NodeTuningOriginsPath:             "/var/lib/koordlet/node_tuning_origins.json",
+		QOSExtensionCfg:                   &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{}},
 	}
 }
 
@@ -51,5 +60,8 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.IntVar(&c.MemoryEvictIntervalSeconds, "memory-evict-interval-seconds", c.MemoryEvictIntervalSeconds, "evict be pod(memory) interval by seconds")
 	fs.IntVar(&c.MemoryEvictCoolTimeSeconds, "memory-evict-cool-time-seconds", c.MemoryEvictCoolTimeSeconds, "cooling time: memory next evict time should after lastEvictTime + MemoryEvictCoolTimeSeconds")
 	fs.IntVar(&c.CPUEvictCoolTimeSeconds, "cpu-evict-cool-time-seconds", c.CPUEvictCoolTimeSeconds, "cooltime: CPU next evict time should after lastEvictTime + CPUEvictCoolTimeSeconds")
+	fs.IntVar(&c.InterferenceDetectIntervalSeconds, "interference-detect-interval-seconds", c.InterferenceDetectIntervalSeconds, "detect the cpi interference of ls pods interval by seconds")
+	fs.IntVar(&c.MemoryBandwidthContentionThresholdMBps, "memory-bandwidth-contention-threshold-mbps", c.MemoryBandwidthContentionThresholdMBps, "memory bandwidth of the node in MB/s measured by resctrl monitoring, below which the mba throttling is lifted, 0 means always throttling")
+	fs.StringVar(&c.NodeTuningOriginsPath, "node-tuning-origins-path", c.NodeTuningOriginsPath, "the file to persist the original values of the files tuned by the node tuning")
 	c.QOSExtensionCfg.InitFlags(fs)
 }
@@ -27,13 +27,15 @@ import (
 
 func Test_NewDefaultConfig(t *testing.T) {
 	expectConfig := &Config{
-		ReconcileIntervalSeconds:   1,
-		CPUSuppressIntervalSeconds: 1,
-		CPUEvictIntervalSeconds:    1,
-		MemoryEvictIntervalSeconds: 1,
-		MemoryEvictCoolTimeSeconds: 4,
-		CPUEvictCoolTimeSeconds:    20,
-		QOSExtensionCfg:            &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{}},
+		ReconcileIntervalSeconds:          1,
+		CPUSuppressIntervalSeconds:        1,
+		CPUEvictIntervalSeconds:           1,
+		MemoryEvictIntervalSeconds:        1,
+		MemoryEvictCoolTimeSeconds:        4,
+		CPUEvictCoolTimeSeconds:           20,
+		InterferenceDetectIntervalSeconds: 60,
+		NodeTuningOriginsPath:             "/var/lib/koordlet/node_tuning_origins.json",
+		QOSExtensionCfg:                   &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{}},
 	}
 	defaultConfig := NewDefaultConfig()
 	assert.Equal(t, expectConfig, defaultConfig)
@@ -48,18 +50,24 @@ func Test_InitFlags(t *testing.T) {
 		"--memory-evict-interval-seconds=2",
 		"--memory-evict-cool-time-seconds=8",
 		"--cpu-evict-cool-time-seconds=40",
+		"--interference-detect-interval-seconds=30",
+		"--memory-bandwidth-contention-threshold-mbps=10000",
+		"--node-tuning-origins-path=/tmp/node_tuning_origins.json",
 		"--qos-extension-plugins=test-plugin=true",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
 	type fields struct {
-		ReconcileIntervalSeconds   int
-		CPUSuppressIntervalSeconds int
-		CPUEvictIntervalSeconds    int
-		MemoryEvictIntervalSeconds int
-		MemoryEvictCoolTimeSeconds int
-		CPUEvictCoolTimeSeconds    int
-		QOSExtensionCfg            *plugins.QOSExtensionConfig
+		ReconcileIntervalSeconds               int
+		CPUSuppressIntervalSeconds             int
+		CPUEvictIntervalSeconds                int
+		MemoryEvictIntervalSeconds             int
+		MemoryEvictCoolTimeSeconds             int
+		CPUEvictCoolTimeSeconds                int
+		InterferenceDetectIntervalSeconds      int
+		MemoryBandwidthContentionThresholdMBps int
+		NodeTuningOriginsPath                  string
+		QOSExtensionCfg                        *plugins.QOSExtensionConfig
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -72,13 +80,16 @@ func Test_InitFlags(t *testing.T) {
 		{
 			name: "not default",
 			fields: fields{
-				ReconcileIntervalSeconds:   2,
-				CPUSuppressIntervalSeconds: 2,
-				CPUEvictIntervalSeconds:    2,
-				MemoryEvictIntervalSeconds: 2,
-				MemoryEvictCoolTimeSeconds: 8,
-				CPUEvictCoolTimeSeconds:    40,
-				QOSExtensionCfg:            &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{"test-plugin": true}},
+				ReconcileIntervalSeconds:               2,
+				CPUSuppressIntervalSeconds:             2,
+				CPUEvictIntervalSeconds:                2,
+				MemoryEvictIntervalSeconds:             2,
+				MemoryEvictCoolTimeSeconds:             8,
+				CPUEvictCoolTimeSeconds:                40,
+				InterferenceDetectIntervalSeconds:      30,
+				MemoryBandwidthContentionThresholdMBps: 10000,
+				NodeTuningOriginsPath:                  "/tmp/node_tuning_origins.json",
+				QOSExtensionCfg:                        &plugins.QOSExtensionConfig{FeatureGates: map[string]bool{"test-plugin": true}},
 			},
 			args: args{fs: fs},
 		},
@@ -86,13 +97,16 @@ func Test_InitFlags(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			raw := &Config{
-				ReconcileIntervalSeconds:   tt.fields.ReconcileIntervalSeconds,
-				CPUSuppressIntervalSeconds: tt.fields.CPUSuppressIntervalSeconds,
-				CPUEvictIntervalSeconds:    tt.fields.CPUEvictIntervalSeconds,
-				MemoryEvictIntervalSeconds: tt.fields.MemoryEvictIntervalSeconds,
-				MemoryEvictCoolTimeSeconds: tt.fields.MemoryEvictCoolTimeSeconds,
-				CPUEvictCoolTimeSeconds:    tt.fields.CPUEvictCoolTimeSeconds,
-				QOSExtensionCfg:            tt.fields.QOSExtensionCfg,
+				ReconcileIntervalSeconds:               tt.fields.ReconcileIntervalSeconds,
+				CPUSuppressIntervalSeconds:             tt.fields.CPUSuppressIntervalSeconds,
+				CPUEvictIntervalSeconds:                tt.fields.CPUEvictIntervalSeconds,
+				MemoryEvictIntervalSeconds:             tt.fields.MemoryEvictIntervalSeconds,
+				MemoryEvictCoolTimeSeconds:             tt.fields.MemoryEvictCoolTimeSeconds,
+				CPUEvictCoolTimeSeconds:                tt.fields.CPUEvictCoolTimeSeconds,
+				InterferenceDetectIntervalSeconds:      tt.fields.InterferenceDetectIntervalSeconds,
+				MemoryBandwidthContentionThresholdMBps: tt.fields.MemoryBandwidthContentionThresholdMBps,
+				NodeTuningOriginsPath:                  tt.fields.NodeTuningOriginsPath,
+				QOSExtensionCfg:                        tt.fields.QOSExtensionCfg,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
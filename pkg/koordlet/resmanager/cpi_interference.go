@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	// cpiRecentWindow is the window of the recent CPI compared with the baseline.
+	cpiRecentWindow = 5 * time.Minute
+	// cpiBaselineWindow is the window right before the recent one, whose CPI is regarded as the baseline of the pod.
+	// It should be kept within the expiration of the metrics in the metric cache.
+	cpiBaselineWindow = 20 * time.Minute
+	// cpiDegradationRatio is the ratio of the recent CPI to the baseline regarded as degraded.
+	cpiDegradationRatio = 1.5
+
+	cpiInterferenceReason = "CPIInterference"
+)
+
+// CPIInterferenceDetector compares the recent CPI of the LS pods with their own baselines. A pod is flagged degraded
+// by the noisy neighbors when its CPI rises significantly while the BE pods are running, since the contention of the
+// shared resources like the LLC and the memory bandwidth stalls the instructions and increases the CPI.
+type CPIInterferenceDetector struct {
+	resmanager *resmanager
+	// degradedPods are the pods flagged in the last round, so only the newly degraded pods are reported.
+	degradedPods map[types.UID]bool
+}
+
+func NewCPIInterferenceDetector(r *resmanager) *CPIInterferenceDetector {
+	return &CPIInterferenceDetector{
+		resmanager:   r,
+		degradedPods: map[types.UID]bool{},
+	}
+}
+
+func (d *CPIInterferenceDetector) detect() {
+	podMetas := d.resmanager.statesInformer.GetAllPods()
+	hasBEPods := false
+	for _, podMeta := range podMetas {
+		if podMeta != nil && podMeta.Pod != nil && podMeta.Pod.Status.Phase == corev1.PodRunning &&
+			apiext.GetPodQoSClass(podMeta.Pod) == apiext.QoSBE {
+			hasBEPods = true
+			break
+		}
+	}
+
+	now := time.Now()
+	degradedPods := map[types.UID]bool{}
+	metrics.ResetPodCPIDegradationRatio()
+	for _, podMeta := range podMetas {
+		if podMeta == nil || podMeta.Pod == nil || !isCPIInterferenceDetectedPod(podMeta.Pod) {
+			continue
+		}
+		pod := podMeta.Pod
+		ratio, ok := d.getPodCPIDegradationRatio(pod, now)
+		if !ok {
+			continue
+		}
+		metrics.RecordPodCPIDegradationRatio(pod, ratio)
+		if !hasBEPods || ratio < cpiDegradationRatio {
+			continue
+		}
+		degradedPods[pod.UID] = true
+		if d.degradedPods[pod.UID] {
+			continue
+		}
+		klog.V(4).Infof("pod %v is degraded by the noisy neighbors, cpi ratio to the baseline %.2f", util.GetPodKey(pod), ratio)
+		d.resmanager.eventRecorder.Eventf(pod, corev1.EventTypeWarning, cpiInterferenceReason,
+			"the CPI of the pod rises to %.2f times of the baseline while the BE pods are running", ratio)
+		_ = audit.V(1).Pod(pod.Namespace, pod.Name).Reason(cpiInterferenceReason).
+			Message("cpi ratio to the baseline %.2f", ratio).Do()
+		metrics.RecordPodCPIInterference()
+	}
+	d.degradedPods = degradedPods
+}
+
+// getPodCPIDegradationRatio returns the ratio of the recent CPI of the pod to its baseline. It returns false if the
+// CPI of either window is not collected.
+func (d *CPIInterferenceDetector) getPodCPIDegradationRatio(pod *corev1.Pod, now time.Time) (float64, bool) {
+	recentStart := now.Add(-cpiRecentWindow)
+	recentCPI, ok := d.getPodCPI(pod, recentStart, now)
+	if !ok {
+		return 0, false
+	}
+	baselineCPI, ok := d.getPodCPI(pod, recentStart.Add(-cpiBaselineWindow), recentStart)
+	if !ok {
+		return 0, false
+	}
+	return recentCPI / baselineCPI, true
+}
+
+// getPodCPI returns the CPI of the pod in the window, which is the cycles over the instructions of all its containers.
+func (d *CPIInterferenceDetector) getPodCPI(pod *corev1.Pod, start, end time.Time) (float64, bool) {
+	podUID := string(pod.UID)
+	queryParam := &metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeAVG,
+		Start:     &start,
+		End:       &end,
+	}
+	var cycles, instructions float64
+	for i := range pod.Status.ContainerStatuses {
+		containerID := pod.Status.ContainerStatuses[i].ContainerID
+		if containerID == "" {
+			continue
+		}
+		result := d.resmanager.metricCache.GetContainerInterferenceMetric(metriccache.MetricNameContainerCPI, &podUID, &containerID, queryParam)
+		if result.Error != nil || result.Metric == nil {
+			klog.V(6).Infof("failed to get cpi of container %v/%v, err: %v", util.GetPodKey(pod), containerID, result.Error)
+			continue
+		}
+		cpi, ok := result.Metric.MetricValue.(*metriccache.CPIMetric)
+		if !ok || cpi == nil {
+			continue
+		}
+		cycles += float64(cpi.Cycles)
+		instructions += float64(cpi.Instructions)
+	}
+	if cycles <= 0 || instructions <= 0 {
+		return 0, false
+	}
+	return cycles / instructions, true
+}
+
+// isCPIInterferenceDetectedPod checks if the pod is a running latency-sensitive pod.
+func isCPIInterferenceDetectedPod(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	switch apiext.GetPodQoSClass(pod) {
+	case apiext.QoSLSE, apiext.QoSLSR, apiext.QoSLS:
+		return true
+	}
+	return false
+}
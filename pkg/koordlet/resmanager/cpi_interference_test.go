@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+)
+
+func TestCPIInterferenceDetector_detect(t *testing.T) {
+	newPodMeta := func(name string, qos apiext.QoSClass) *statesinformer.PodMeta {
+		return &statesinformer.PodMeta{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					UID:       types.UID(name),
+					Labels:    map[string]string{apiext.LabelPodQoS: string(qos)},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "main", ContainerID: "containerd://" + name},
+					},
+				},
+			},
+		}
+	}
+	insertCPI := func(t *testing.T, metricCache metriccache.MetricCache, podMeta *statesinformer.PodMeta, ts time.Time, cycles, instructions uint64) {
+		err := metricCache.InsertContainerInterferenceMetrics(ts, &metriccache.ContainerInterferenceMetric{
+			MetricName:  metriccache.MetricNameContainerCPI,
+			PodUID:      string(podMeta.Pod.UID),
+			ContainerID: podMeta.Pod.Status.ContainerStatuses[0].ContainerID,
+			MetricValue: &metriccache.CPIMetric{Cycles: cycles, Instructions: instructions},
+		})
+		assert.NoError(t, err)
+	}
+	tests := []struct {
+		name         string
+		withBEPod    bool
+		recentCycles uint64
+		wantDegraded bool
+	}{
+		{
+			name:         "cpi rises with be pods running",
+			withBEPod:    true,
+			recentCycles: 2000,
+			wantDegraded: true,
+		},
+		{
+			name:         "cpi rises without be pods",
+			recentCycles: 2000,
+		},
+		{
+			name:         "cpi keeps stable",
+			withBEPod:    true,
+			recentCycles: 1100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricCache, err := metriccache.NewCacheNotShareMetricCache(&metriccache.Config{MetricGCIntervalSeconds: 60, MetricExpireSeconds: 3600})
+			assert.NoError(t, err)
+			lsPodMeta := newPodMeta("ls-pod", apiext.QoSLS)
+			podMetas := []*statesinformer.PodMeta{lsPodMeta}
+			if tt.withBEPod {
+				podMetas = append(podMetas, newPodMeta("be-pod", apiext.QoSBE))
+			}
+			now := time.Now()
+			insertCPI(t, metricCache, lsPodMeta, now.Add(-10*time.Minute), 1000, 1000)
+			insertCPI(t, metricCache, lsPodMeta, now.Add(-8*time.Minute), 1000, 1000)
+			insertCPI(t, metricCache, lsPodMeta, now.Add(-time.Minute), tt.recentCycles, 1000)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+			mockStatesInformer.EXPECT().GetAllPods().Return(podMetas).AnyTimes()
+			fakeRecorder := &FakeRecorder{}
+			d := NewCPIInterferenceDetector(&resmanager{
+				statesInformer: mockStatesInformer,
+				metricCache:    metricCache,
+				eventRecorder:  fakeRecorder,
+			})
+
+			d.detect()
+			assert.Equal(t, tt.wantDegraded, d.degradedPods[lsPodMeta.Pod.UID])
+			if tt.wantDegraded {
+				assert.Equal(t, cpiInterferenceReason, fakeRecorder.eventReason)
+			} else {
+				assert.Empty(t, fakeRecorder.eventReason)
+			}
+
+			// the degraded pod is reported only once
+			fakeRecorder.eventReason = ""
+			d.detect()
+			assert.Equal(t, tt.wantDegraded, d.degradedPods[lsPodMeta.Pod.UID])
+			assert.Empty(t, fakeRecorder.eventReason)
+		})
+	}
+}
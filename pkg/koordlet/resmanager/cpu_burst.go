@@ -57,6 +57,8 @@ const (
 	cfsScaleDown
 	cfsRemain
 	cfsReset
+	// cfsStartupBoost scales up cfs quota to the startup boost ceil at once
+	cfsStartupBoost
 )
 
 func (o cfsOperation) String() string {
@@ -69,6 +71,8 @@ func (o cfsOperation) String() string {
 		return "cfsRemain"
 	case cfsReset:
 		return "cfsReset"
+	case cfsStartupBoost:
+		return "cfsStartupBoost"
 	default:
 		return fmt.Sprintf("unrecognized(%d)", o)
 	}
@@ -301,6 +305,7 @@ func (b *CPUBurst) getNodeStateForBurst(sharePoolThresholdPercent int64,
 func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podMeta *statesinformer.PodMeta,
 	nodeState nodeStateForBurst) {
 	pod := podMeta.Pod
+	now := time.Now()
 	containerMap := make(map[string]*corev1.Container)
 	for i := range pod.Spec.Containers {
 		container := &pod.Spec.Containers[i]
@@ -340,7 +345,15 @@ func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podM
 			containerCeilCFS = int64(float64(containerBaseCFS) * float64(*burstCfg.CFSQuotaBurstPercent) / 100)
 		}
 
-		originOperation := b.genOperationByContainer(burstCfg, pod, container, containerStat)
+		var originOperation cfsOperation
+		if isContainerInStartupBoost(burstCfg, pod, containerStat, now) {
+			// the ceil is raised within the startup window and falls back after it, which reverts the boost
+			startupBoostCeilCFS := int64(float64(containerBaseCFS) * float64(*burstCfg.StartupBoostPercent) / 100)
+			containerCeilCFS = util.MaxInt64(containerCeilCFS, startupBoostCeilCFS)
+			originOperation = b.genStartupBoostOperation(pod, containerStat)
+		} else {
+			originOperation = b.genOperationByContainer(burstCfg, pod, container, containerStat)
+		}
 		klog.V(6).Infof("cfs burst operation for container %v/%v/%v is %v",
 			pod.Namespace, pod.Name, containerStat.Name, originOperation)
 
@@ -360,6 +373,8 @@ func (b *CPUBurst) applyCFSQuotaBurst(burstCfg *slov1alpha1.CPUBurstConfig, podM
 			containerTargetCFS = int64(float64(containerCurCFS) * cfsDecreaseStep)
 		} else if finalOperation == cfsReset {
 			containerTargetCFS = containerBaseCFS
+		} else if finalOperation == cfsStartupBoost {
+			containerTargetCFS = containerCeilCFS
 		}
 		containerTargetCFS = util.MaxInt64(containerBaseCFS, util.MinInt64(containerTargetCFS, containerCeilCFS))
 
@@ -448,6 +463,27 @@ func (b *CPUBurst) genOperationByContainer(burstCfg *slov1alpha1.CPUBurstConfig,
 	return cfsRemain
 }
 
+// genStartupBoostOperation boosts the throttled container in the startup window without consuming the limiter tokens,
+// since the cold start is a one-off burst rather than the continuous overuse the limiter guards against.
+func (b *CPUBurst) genStartupBoostOperation(pod *corev1.Pod, containerStat *corev1.ContainerStatus) cfsOperation {
+	containerThrottled := b.resmanager.collectContainerThrottledMetricLast(&containerStat.ContainerID)
+	if containerThrottled.Error != nil {
+		klog.V(4).Infof("failed to get container %s/%s/%s throttled metric for startup boost, skip this round, reason %v",
+			pod.Namespace, pod.Name, containerStat.Name, containerThrottled.Error)
+		return cfsRemain
+	}
+	if containerThrottled.Metric == nil || containerThrottled.AggregateInfo == nil ||
+		containerThrottled.Metric.CPUThrottledMetric == nil {
+		klog.V(4).Infof("container %s/%s/%s throttled metric is nil for startup boost, skip this round, detail %v",
+			pod.Namespace, pod.Name, containerStat.Name, containerThrottled)
+		return cfsRemain
+	}
+	if containerThrottled.Metric.CPUThrottledMetric.ThrottledRatio > 0 {
+		return cfsStartupBoost
+	}
+	return cfsRemain
+}
+
 func (b *CPUBurst) applyContainerCFSQuota(podMeta *statesinformer.PodMeta, containerStat *corev1.ContainerStatus,
 	curContaienrCFS, deltaContainerCFS int64) error {
 	podDir := koordletutil.GetPodCgroupDirWithKube(podMeta.CgroupDir)
@@ -633,6 +669,22 @@ func genPodBurstConfig(pod *corev1.Pod, nodeCfg *slov1alpha1.CPUBurstConfig) *sl
 	return out
 }
 
+// isContainerInStartupBoost checks if the LS container started within the startup boost window.
+func isContainerInStartupBoost(burstCfg *slov1alpha1.CPUBurstConfig, pod *corev1.Pod,
+	containerStat *corev1.ContainerStatus, now time.Time) bool {
+	if burstCfg.StartupBoostPercent == nil || *burstCfg.StartupBoostPercent <= 100 ||
+		burstCfg.StartupBoostSeconds == nil || *burstCfg.StartupBoostSeconds <= 0 {
+		return false
+	}
+	if apiext.GetPodQoSClass(pod) != apiext.QoSLS {
+		return false
+	}
+	if containerStat.State.Running == nil || containerStat.State.Running.StartedAt.IsZero() {
+		return false
+	}
+	return now.Sub(containerStat.State.Running.StartedAt.Time) < time.Duration(*burstCfg.StartupBoostSeconds)*time.Second
+}
+
 func cpuBurstEnabled(burstPolicy slov1alpha1.CPUBurstPolicy) bool {
 	return burstPolicy == slov1alpha1.CPUBurstAuto || burstPolicy == slov1alpha1.CPUBurstOnly
 }
@@ -643,9 +695,10 @@ func cfsQuotaBurstEnabled(burstPolicy slov1alpha1.CPUBurstPolicy) bool {
 
 func changeOperationByNode(nodeState nodeStateForBurst, originOperation cfsOperation) (bool, cfsOperation) {
 	changedOperation := originOperation
-	if nodeState == nodeBurstOverload && (originOperation == cfsScaleUp || originOperation == cfsRemain) {
+	isScaleUp := originOperation == cfsScaleUp || originOperation == cfsStartupBoost
+	if nodeState == nodeBurstOverload && (isScaleUp || originOperation == cfsRemain) {
 		changedOperation = cfsScaleDown
-	} else if (nodeState == nodeBurstCooling || nodeState == nodeBurstUnknown) && originOperation == cfsScaleUp {
+	} else if (nodeState == nodeBurstCooling || nodeState == nodeBurstUnknown) && isScaleUp {
 		changedOperation = cfsRemain
 	}
 	return changedOperation != originOperation, changedOperation
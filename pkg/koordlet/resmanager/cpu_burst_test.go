@@ -764,6 +764,8 @@ func TestCPUBurst_applyCFSQuotaBurst(t *testing.T) {
 		containerCurCFSQuota map[string]int64
 		containerMetric      map[string]metriccache.ContainerResourceQueryResult
 		containerThrottled   map[string]metriccache.ContainerThrottledQueryResult
+		podQoS               apiext.QoSClass
+		containerStartedAgo  map[string]time.Duration
 	}
 	type args struct {
 		burstCfg  slov1alpha1.CPUBurstConfig
@@ -1211,6 +1213,270 @@ func TestCPUBurst_applyCFSQuotaBurst(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "startup-boost-for-throttled-ls-container-on-idle-state",
+			fields: fields{
+				podName: testPodName1,
+				containerRes: map[string]corev1.ResourceRequirements{
+					testContainerName1: {
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+						},
+					},
+				},
+				podCurCFSQuota: 2 * system.CFSBasePeriodValue,
+				containerCurCFSQuota: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					testContainerName1: *genTestContainerResourceQueryResult(testContainerID1, 1500, 1000),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					testContainerName1: *genTestContainerThrottledQueryResult(testContainerID1, 0.5),
+				},
+				podQoS: apiext.QoSLS,
+				containerStartedAgo: map[string]time.Duration{
+					testContainerName1: 10 * time.Second,
+				},
+			},
+			args: args{
+				burstCfg: slov1alpha1.CPUBurstConfig{
+					Policy:              slov1alpha1.CPUBurstOnly,
+					StartupBoostPercent: pointer.Int64Ptr(300),
+					StartupBoostSeconds: pointer.Int64Ptr(60),
+				},
+				nodeState: nodeBurstIdle,
+			},
+			want: want{
+				podCFSQuotaVal: 2 * 3 * system.CFSBasePeriodValue,
+				containerCFSQuotaVal: map[string]int64{
+					testContainerName1: 2 * 3 * system.CFSBasePeriodValue,
+				},
+			},
+		},
+		{
+			name: "startup-remain-for-unthrottled-ls-container-on-idle-state",
+			fields: fields{
+				podName: testPodName1,
+				containerRes: map[string]corev1.ResourceRequirements{
+					testContainerName1: {
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+						},
+					},
+				},
+				podCurCFSQuota: 2 * system.CFSBasePeriodValue,
+				containerCurCFSQuota: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					testContainerName1: *genTestContainerResourceQueryResult(testContainerID1, 1500, 1000),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					testContainerName1: *genTestContainerThrottledQueryResult(testContainerID1, 0),
+				},
+				podQoS: apiext.QoSLS,
+				containerStartedAgo: map[string]time.Duration{
+					testContainerName1: 10 * time.Second,
+				},
+			},
+			args: args{
+				burstCfg: slov1alpha1.CPUBurstConfig{
+					Policy:              slov1alpha1.CPUBurstOnly,
+					StartupBoostPercent: pointer.Int64Ptr(300),
+					StartupBoostSeconds: pointer.Int64Ptr(60),
+				},
+				nodeState: nodeBurstIdle,
+			},
+			want: want{
+				podCFSQuotaVal: 2 * system.CFSBasePeriodValue,
+				containerCFSQuotaVal: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+			},
+		},
+		{
+			name: "startup-remain-for-throttled-ls-container-on-cooling-state",
+			fields: fields{
+				podName: testPodName1,
+				containerRes: map[string]corev1.ResourceRequirements{
+					testContainerName1: {
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+						},
+					},
+				},
+				podCurCFSQuota: 2 * system.CFSBasePeriodValue,
+				containerCurCFSQuota: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					testContainerName1: *genTestContainerResourceQueryResult(testContainerID1, 1500, 1000),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					testContainerName1: *genTestContainerThrottledQueryResult(testContainerID1, 0.5),
+				},
+				podQoS: apiext.QoSLS,
+				containerStartedAgo: map[string]time.Duration{
+					testContainerName1: 10 * time.Second,
+				},
+			},
+			args: args{
+				burstCfg: slov1alpha1.CPUBurstConfig{
+					Policy:              slov1alpha1.CPUBurstOnly,
+					StartupBoostPercent: pointer.Int64Ptr(300),
+					StartupBoostSeconds: pointer.Int64Ptr(60),
+				},
+				nodeState: nodeBurstCooling,
+			},
+			want: want{
+				podCFSQuotaVal: 2 * system.CFSBasePeriodValue,
+				containerCFSQuotaVal: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+			},
+		},
+		{
+			name: "startup-scale-down-for-boosted-ls-container-on-overload-state",
+			fields: fields{
+				podName: testPodName1,
+				containerRes: map[string]corev1.ResourceRequirements{
+					testContainerName1: {
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+						},
+					},
+				},
+				podCurCFSQuota: 2 * 3 * system.CFSBasePeriodValue,
+				containerCurCFSQuota: map[string]int64{
+					testContainerName1: 2 * 3 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					testContainerName1: *genTestContainerResourceQueryResult(testContainerID1, 1500, 1000),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					testContainerName1: *genTestContainerThrottledQueryResult(testContainerID1, 0.5),
+				},
+				podQoS: apiext.QoSLS,
+				containerStartedAgo: map[string]time.Duration{
+					testContainerName1: 10 * time.Second,
+				},
+			},
+			args: args{
+				burstCfg: slov1alpha1.CPUBurstConfig{
+					Policy:              slov1alpha1.CPUBurstOnly,
+					StartupBoostPercent: pointer.Int64Ptr(300),
+					StartupBoostSeconds: pointer.Int64Ptr(60),
+				},
+				nodeState: nodeBurstOverload,
+			},
+			want: want{
+				podCFSQuotaVal: int64(2 * 3 * cfsDecreaseStep * float64(system.CFSBasePeriodValue)),
+				containerCFSQuotaVal: map[string]int64{
+					testContainerName1: int64(2 * 3 * cfsDecreaseStep * float64(system.CFSBasePeriodValue)),
+				},
+			},
+		},
+		{
+			name: "startup-boost-reverted-after-window",
+			fields: fields{
+				podName: testPodName1,
+				containerRes: map[string]corev1.ResourceRequirements{
+					testContainerName1: {
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+						},
+					},
+				},
+				podCurCFSQuota: 2 * 3 * system.CFSBasePeriodValue,
+				containerCurCFSQuota: map[string]int64{
+					testContainerName1: 2 * 3 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					testContainerName1: *genTestContainerResourceQueryResult(testContainerID1, 1500, 1000),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					testContainerName1: *genTestContainerThrottledQueryResult(testContainerID1, 0.5),
+				},
+				podQoS: apiext.QoSLS,
+				containerStartedAgo: map[string]time.Duration{
+					testContainerName1: 2 * time.Minute,
+				},
+			},
+			args: args{
+				burstCfg: slov1alpha1.CPUBurstConfig{
+					Policy:              slov1alpha1.CPUBurstOnly,
+					StartupBoostPercent: pointer.Int64Ptr(300),
+					StartupBoostSeconds: pointer.Int64Ptr(60),
+				},
+				nodeState: nodeBurstIdle,
+			},
+			want: want{
+				podCFSQuotaVal: 2 * system.CFSBasePeriodValue,
+				containerCFSQuotaVal: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+			},
+		},
+		{
+			name: "startup-boost-skipped-for-be-container",
+			fields: fields{
+				podName: testPodName1,
+				containerRes: map[string]corev1.ResourceRequirements{
+					testContainerName1: {
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(2000, resource.DecimalSI),
+						},
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+						},
+					},
+				},
+				podCurCFSQuota: 2 * system.CFSBasePeriodValue,
+				containerCurCFSQuota: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+				containerMetric: map[string]metriccache.ContainerResourceQueryResult{
+					testContainerName1: *genTestContainerResourceQueryResult(testContainerID1, 1500, 1000),
+				},
+				containerThrottled: map[string]metriccache.ContainerThrottledQueryResult{
+					testContainerName1: *genTestContainerThrottledQueryResult(testContainerID1, 0.5),
+				},
+				podQoS: apiext.QoSBE,
+				containerStartedAgo: map[string]time.Duration{
+					testContainerName1: 10 * time.Second,
+				},
+			},
+			args: args{
+				burstCfg: slov1alpha1.CPUBurstConfig{
+					Policy:              slov1alpha1.CPUBurstOnly,
+					StartupBoostPercent: pointer.Int64Ptr(300),
+					StartupBoostSeconds: pointer.Int64Ptr(60),
+				},
+				nodeState: nodeBurstIdle,
+			},
+			want: want{
+				podCFSQuotaVal: 2 * system.CFSBasePeriodValue,
+				containerCFSQuotaVal: map[string]int64{
+					testContainerName1: 2 * system.CFSBasePeriodValue,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1220,6 +1486,15 @@ func TestCPUBurst_applyCFSQuotaBurst(t *testing.T) {
 			defer func() { stop <- struct{}{} }()
 
 			podMeta := createPodMetaByResource(tt.fields.podName, tt.fields.containerRes)
+			if tt.fields.podQoS != "" {
+				podMeta.Pod.Labels = map[string]string{apiext.LabelPodQoS: string(tt.fields.podQoS)}
+			}
+			for i := range podMeta.Pod.Status.ContainerStatuses {
+				containerStat := &podMeta.Pod.Status.ContainerStatuses[i]
+				if ago, ok := tt.fields.containerStartedAgo[containerStat.Name]; ok {
+					containerStat.State.Running = &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now().Add(-ago))}
+				}
+			}
 
 			fakeRecorder := &FakeRecorder{}
 			client := clientsetfake.NewSimpleClientset()
@@ -36,6 +36,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kubelet"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
@@ -62,6 +63,7 @@ type CPUSuppress struct {
 	executor               resourceexecutor.ResourceUpdateExecutor
 	cgroupReader           resourceexecutor.CgroupReader
 	suppressPolicyStatuses map[string]suppressPolicyStatus
+	feedback               cpuSuppressFeedback
 }
 
 func NewCPUSuppress(r *resmanager) *CPUSuppress {
@@ -279,7 +281,12 @@ func (r *CPUSuppress) suppressBECPU() {
 		return
 	}
 	if nodeSLO.Spec.ResourceUsedThresholdWithBE.CPUSuppressPolicy == slov1alpha1.CPUCfsQuotaPolicy {
-		r.adjustByCfsQuota(suppressCPUQuantity, node)
+		if isCPUSuppressAutoTuningEnabled(nodeSLO.Spec.ResourceUsedThresholdWithBE) {
+			r.adjustByCfsQuotaFeedback(nodeSLO.Spec.ResourceUsedThresholdWithBE, node, podMetas)
+		} else {
+			r.feedback.reset()
+			r.adjustByCfsQuota(suppressCPUQuantity, node)
+		}
 		r.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)] = policyUsing
 		r.recoverCPUSetIfNeed(koordletutil.ContainerCgroupPathRelativeDepth)
 	} else {
@@ -318,6 +325,16 @@ func (r *CPUSuppress) adjustByCPUSet(cpusetQuantity *resource.Quantity, nodeCPUI
 			cpuIdToPool[int32(cpuID)] = apiext.GetPodQoSClass(podMeta.Pod)
 		}
 	}
+	// the CPUs pinned by the kubelet static policy are exclusive to their pods like the LSE ones
+	if nodeTopo := r.resmanager.statesInformer.GetNodeTopo(); nodeTopo != nil {
+		podCPUAllocs, err := apiext.GetPodCPUAllocs(nodeTopo.Annotations)
+		if err != nil {
+			klog.Warningf("failed to get pod cpu allocs of node topology, err: %v", err)
+		}
+		for _, cpuID := range kubelet.GetKubeletPinnedCPUs(podCPUAllocs, "").ToSliceNoSort() {
+			cpuIdToPool[int32(cpuID)] = apiext.QoSLSE
+		}
+	}
 	var lsrCpus []koordletutil.ProcessorInfo
 	var lsCpus []koordletutil.ProcessorInfo
 	// FIXME: be pods might be starved since lse pods can run out of all cpus
@@ -435,6 +452,11 @@ func (r *CPUSuppress) adjustByCfsQuota(cpuQuantity *resource.Quantity, node *cor
 		newBeQuota = currentBeQuota + int64(beMaxIncreaseCPUQuota)
 	}
 
+	r.writeBECfsQuota(beCgroupPath, newBeQuota)
+}
+
+// writeBECfsQuota writes the cfs quota of the best-effort cgroup
+func (r *CPUSuppress) writeBECfsQuota(beCgroupPath string, newBeQuota int64) {
 	eventHelper := audit.V(3).Node().Reason(resourceexecutor.AdjustBEByNodeCPUUsage).Message("update BE group to cfs_quota: %v", newBeQuota)
 	updater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.CPUCFSQuotaName, beCgroupPath, strconv.FormatInt(newBeQuota, 10), eventHelper)
 	if err != nil {
@@ -452,6 +474,7 @@ func (r *CPUSuppress) adjustByCfsQuota(cpuQuantity *resource.Quantity, node *cor
 }
 
 func (r *CPUSuppress) recoverCFSQuotaIfNeed() {
+	r.feedback.reset()
 	cfsQuotaPolicyStatus, exist := r.suppressPolicyStatuses[string(slov1alpha1.CPUCfsQuotaPolicy)]
 	if exist && cfsQuotaPolicyStatus == policyRecovered {
 		return
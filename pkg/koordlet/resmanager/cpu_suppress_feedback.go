@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	defaultTargetLSCPUPSIPercent       int64 = 10
+	defaultTargetLSCPUThrottledPercent int64 = 10
+	defaultProportionalGainMilli       int64 = 500
+	defaultIntegralGainMilli           int64 = 100
+	defaultDerivativeGainMilli         int64 = 0
+	defaultMaxStepPercent              int64 = 5
+	defaultMinBECPUPercent             int64 = 5
+
+	// the accumulated error is bounded to avoid the integral windup when the quota stays at the bounds
+	feedbackIntegralLimit = 100.0
+)
+
+// cpuSuppressFeedback keeps the state of the PID-like feedback controller for the BE cfs quota.
+type cpuSuppressFeedback struct {
+	initialized bool
+	integral    float64
+	lastError   float64
+}
+
+func (f *cpuSuppressFeedback) reset() {
+	*f = cpuSuppressFeedback{}
+}
+
+// cpuSuppressFeedbackParams is the CPUSuppressAutoTuningStrategy with the defaults filled.
+type cpuSuppressFeedbackParams struct {
+	targetPSIPercent       float64
+	targetThrottledPercent float64
+	// targetSchedLatency is the target of the run queue latency in microseconds, zero means not used.
+	targetSchedLatency     float64
+	kp, ki, kd             float64
+	maxStepPercent         float64
+	minPercent, maxPercent float64
+}
+
+func isCPUSuppressAutoTuningEnabled(strategy *slov1alpha1.ResourceThresholdStrategy) bool {
+	return strategy != nil && strategy.CPUSuppressAutoTuning != nil &&
+		strategy.CPUSuppressAutoTuning.Enable != nil && *strategy.CPUSuppressAutoTuning.Enable
+}
+
+func getCPUSuppressFeedbackParams(strategy *slov1alpha1.ResourceThresholdStrategy) *cpuSuppressFeedbackParams {
+	autoTuning := strategy.CPUSuppressAutoTuning
+	getOrDefault := func(v *int64, d int64) float64 {
+		if v == nil {
+			return float64(d)
+		}
+		return float64(*v)
+	}
+	maxPercentDefault := int64(100)
+	if strategy.CPUSuppressThresholdPercent != nil {
+		maxPercentDefault = *strategy.CPUSuppressThresholdPercent
+	}
+	params := &cpuSuppressFeedbackParams{
+		targetPSIPercent:       getOrDefault(autoTuning.TargetLSCPUPSIPercent, defaultTargetLSCPUPSIPercent),
+		targetThrottledPercent: getOrDefault(autoTuning.TargetLSCPUThrottledPercent, defaultTargetLSCPUThrottledPercent),
+		targetSchedLatency:     getOrDefault(autoTuning.TargetLSSchedLatencyMicroseconds, 0),
+		kp:                     getOrDefault(autoTuning.ProportionalGainMilli, defaultProportionalGainMilli) / 1000,
+		ki:                     getOrDefault(autoTuning.IntegralGainMilli, defaultIntegralGainMilli) / 1000,
+		kd:                     getOrDefault(autoTuning.DerivativeGainMilli, defaultDerivativeGainMilli) / 1000,
+		maxStepPercent:         getOrDefault(autoTuning.MaxStepPercent, defaultMaxStepPercent),
+		minPercent:             getOrDefault(autoTuning.MinBECPUPercent, defaultMinBECPUPercent),
+		maxPercent:             getOrDefault(autoTuning.MaxBECPUPercent, maxPercentDefault),
+	}
+	if params.maxPercent < params.minPercent {
+		params.maxPercent = params.minPercent
+	}
+	return params
+}
+
+// next calculates the BE quota percentage of the next round with the error of this round.
+// A positive error means the LS pods suffer more interference than the target, so the quota should be scaled down.
+func (f *cpuSuppressFeedback) next(params *cpuSuppressFeedbackParams, currentPercent, err float64) float64 {
+	if !f.initialized {
+		f.lastError = err
+		f.initialized = true
+	}
+	f.integral = math.Max(math.Min(f.integral+err, feedbackIntegralLimit), -feedbackIntegralLimit)
+	output := params.kp*err + params.ki*f.integral + params.kd*(err-f.lastError)
+	f.lastError = err
+
+	step := math.Max(math.Min(-output, params.maxStepPercent), -params.maxStepPercent)
+	return math.Max(math.Min(currentPercent+step, params.maxPercent), params.minPercent)
+}
+
+// getInterferenceError returns the excess of the value in percentage of the target, so that the errors of the
+// metrics in different units are in the same scale. A zero target is taken as one unit of the metric.
+func getInterferenceError(value, target float64) float64 {
+	return (value - target) / math.Max(target, 1) * 100
+}
+
+// getLSInterferenceError returns the max error of the LS pods' cpu PSI, cpu throttled ratio and run queue latency
+// against the targets, in percentage of the targets. It returns false if there are LS pods but none of them has
+// the interference metrics.
+func (r *CPUSuppress) getLSInterferenceError(params *cpuSuppressFeedbackParams, podMetas []*statesinformer.PodMeta) (float64, bool) {
+	queryParam := generateQueryParamsLast(r.resmanager.collectResUsedIntervalSeconds * 2)
+	lsPods, collected := 0, 0
+	// no interference at all
+	maxErr := -100.0
+	for _, podMeta := range podMetas {
+		pod := podMeta.Pod
+		if apiext.GetPodQoSClass(pod) == apiext.QoSBE || util.GetKubeQosClass(pod) == corev1.PodQOSBestEffort {
+			continue
+		}
+		lsPods++
+		podUID := string(pod.UID)
+		psiResult := r.resmanager.metricCache.GetPodInterferenceMetric(metriccache.MetricNamePodPSI, &podUID, queryParam)
+		if psiResult.Error == nil && psiResult.Metric != nil {
+			if psi, ok := psiResult.Metric.MetricValue.(*metriccache.PSIMetric); ok {
+				collected++
+				maxErr = math.Max(maxErr, getInterferenceError(psi.SomeCPUAvg10, params.targetPSIPercent))
+			}
+		}
+		throttledResult := r.resmanager.metricCache.GetPodThrottledMetric(&podUID, queryParam)
+		if throttledResult.Error == nil && throttledResult.Metric != nil && throttledResult.Metric.CPUThrottledMetric != nil {
+			collected++
+			throttledPercent := throttledResult.Metric.CPUThrottledMetric.ThrottledRatio * 100
+			maxErr = math.Max(maxErr, getInterferenceError(throttledPercent, params.targetThrottledPercent))
+		}
+		if params.targetSchedLatency <= 0 {
+			continue
+		}
+		latencyResult := r.resmanager.metricCache.GetPodInterferenceMetric(metriccache.MetricNamePodSchedLatency, &podUID, queryParam)
+		if latencyResult.Error == nil && latencyResult.Metric != nil {
+			if latency, ok := latencyResult.Metric.MetricValue.(*metriccache.SchedLatencyMetric); ok {
+				collected++
+				maxErr = math.Max(maxErr, getInterferenceError(latency.AvgLatencyMicroseconds, params.targetSchedLatency))
+			}
+		}
+	}
+	if lsPods > 0 && collected <= 0 {
+		return 0, false
+	}
+	return maxErr, true
+}
+
+// adjustByCfsQuotaFeedback adjusts the BE cfs quota in small steps according to the interference of LS pods
+func (r *CPUSuppress) adjustByCfsQuotaFeedback(strategy *slov1alpha1.ResourceThresholdStrategy, node *corev1.Node,
+	podMetas []*statesinformer.PodMeta) {
+	params := getCPUSuppressFeedbackParams(strategy)
+	lsErr, ok := r.getLSInterferenceError(params, podMetas)
+	if !ok {
+		klog.V(4).Infof("suppressBECPU: skip auto tuning since no interference metric of LS pods is collected")
+		return
+	}
+
+	beCgroupPath := koordletutil.GetPodQoSRelativePath(corev1.PodQOSBestEffort)
+	currentBeQuota, err := r.cgroupReader.ReadCPUQuota(beCgroupPath)
+	if err != nil {
+		klog.Warningf("suppressBECPU fail:get currentBeQuota fail,error: %v", err)
+		return
+	}
+	nodeQuota := float64(node.Status.Capacity.Cpu().MilliValue()) * float64(cfsPeriod) / 1000
+	if nodeQuota <= 0 {
+		klog.Warningf("suppressBECPU: skip auto tuning since node cpu capacity is invalid")
+		return
+	}
+	currentPercent := params.maxPercent
+	if currentBeQuota > 0 {
+		currentPercent = float64(currentBeQuota) * 100 / nodeQuota
+	}
+
+	nextPercent := r.feedback.next(params, currentPercent, lsErr)
+	newBeQuota := int64(math.Max(nextPercent*nodeQuota/100, float64(beMinQuota)))
+	klog.V(4).Infof("suppressBECPU: auto tuning LS interference error %.2f, be quota percent %.2f -> %.2f",
+		lsErr, currentPercent, nextPercent)
+	if newBeQuota == currentBeQuota {
+		return
+	}
+	r.writeBECfsQuota(beCgroupPath, newBeQuota)
+}
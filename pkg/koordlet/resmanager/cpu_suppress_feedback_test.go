@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+func Test_getCPUSuppressFeedbackParams(t *testing.T) {
+	strategy := &slov1alpha1.ResourceThresholdStrategy{
+		CPUSuppressThresholdPercent: pointer.Int64(65),
+		CPUSuppressAutoTuning: &slov1alpha1.CPUSuppressAutoTuningStrategy{
+			Enable:              pointer.Bool(true),
+			DerivativeGainMilli: pointer.Int64(200),
+		},
+	}
+	assert.True(t, isCPUSuppressAutoTuningEnabled(strategy))
+	expected := &cpuSuppressFeedbackParams{
+		targetPSIPercent:       10,
+		targetThrottledPercent: 10,
+		kp:                     0.5,
+		ki:                     0.1,
+		kd:                     0.2,
+		maxStepPercent:         5,
+		minPercent:             5,
+		maxPercent:             65,
+	}
+	assert.Equal(t, expected, getCPUSuppressFeedbackParams(strategy))
+
+	strategy.CPUSuppressAutoTuning.Enable = pointer.Bool(false)
+	assert.False(t, isCPUSuppressAutoTuningEnabled(strategy))
+	assert.False(t, isCPUSuppressAutoTuningEnabled(&slov1alpha1.ResourceThresholdStrategy{}))
+}
+
+func Test_cpuSuppressFeedback_next(t *testing.T) {
+	params := &cpuSuppressFeedbackParams{
+		kp:             0.5,
+		ki:             0.1,
+		maxStepPercent: 5,
+		minPercent:     10,
+		maxPercent:     60,
+	}
+	f := &cpuSuppressFeedback{}
+
+	// interference above the target scales down the quota
+	got := f.next(params, 40, 4)
+	assert.InDelta(t, 37.6, got, 0.001)
+	// the step is bounded
+	got = f.next(params, got, 100)
+	assert.InDelta(t, 32.6, got, 0.001)
+	// the quota is bounded
+	assert.Equal(t, float64(10), f.next(params, 12, 100))
+
+	// interference below the target scales up the quota slowly with the accumulated error
+	f.reset()
+	got = f.next(params, 40, -2)
+	assert.InDelta(t, 41.2, got, 0.001)
+	got = f.next(params, got, -2)
+	assert.InDelta(t, 42.6, got, 0.001)
+	assert.Equal(t, float64(60), f.next(params, 59, -20))
+}
+
+func Test_cpuSuppress_getLSInterferenceError(t *testing.T) {
+	lsPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "ls-pod",
+		UID:    types.UID("ls-pod"),
+		Labels: map[string]string{apiext.LabelPodQoS: string(apiext.QoSLS)},
+	}, Status: corev1.PodStatus{QOSClass: corev1.PodQOSBurstable}}
+	bePod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "be-pod",
+		UID:    types.UID("be-pod"),
+		Labels: map[string]string{apiext.LabelPodQoS: string(apiext.QoSBE)},
+	}, Status: corev1.PodStatus{QOSClass: corev1.PodQOSBestEffort}}
+	tests := []struct {
+		name               string
+		podMetas           []*statesinformer.PodMeta
+		psi                *metriccache.PSIMetric
+		throttled          *metriccache.PodThrottledMetric
+		schedLatency       *metriccache.SchedLatencyMetric
+		targetSchedLatency float64
+		wantErr            float64
+		wantCollect        bool
+	}{
+		{
+			name:        "no LS pod",
+			podMetas:    []*statesinformer.PodMeta{{Pod: bePod}},
+			wantErr:     -100,
+			wantCollect: true,
+		},
+		{
+			name:        "LS pod without metrics",
+			podMetas:    []*statesinformer.PodMeta{{Pod: lsPod}, {Pod: bePod}},
+			wantCollect: false,
+		},
+		{
+			name:     "PSI exceeds the target",
+			podMetas: []*statesinformer.PodMeta{{Pod: lsPod}, {Pod: bePod}},
+			psi:      &metriccache.PSIMetric{SomeCPUAvg10: 18},
+			throttled: &metriccache.PodThrottledMetric{
+				PodUID:             "ls-pod",
+				CPUThrottledMetric: &metriccache.CPUThrottledMetric{ThrottledRatio: 0.06},
+			},
+			wantErr:     80,
+			wantCollect: true,
+		},
+		{
+			name:     "throttled ratio exceeds the target",
+			podMetas: []*statesinformer.PodMeta{{Pod: lsPod}},
+			psi:      &metriccache.PSIMetric{SomeCPUAvg10: 2},
+			throttled: &metriccache.PodThrottledMetric{
+				PodUID:             "ls-pod",
+				CPUThrottledMetric: &metriccache.CPUThrottledMetric{ThrottledRatio: 0.2},
+			},
+			wantErr:     300,
+			wantCollect: true,
+		},
+		{
+			name:     "sched latency exceeds the target",
+			podMetas: []*statesinformer.PodMeta{{Pod: lsPod}},
+			psi:      &metriccache.PSIMetric{SomeCPUAvg10: 2},
+			throttled: &metriccache.PodThrottledMetric{
+				PodUID:             "ls-pod",
+				CPUThrottledMetric: &metriccache.CPUThrottledMetric{ThrottledRatio: 0.02},
+			},
+			schedLatency:       &metriccache.SchedLatencyMetric{AvgLatencyMicroseconds: 300},
+			targetSchedLatency: 200,
+			wantErr:            50,
+			wantCollect:        true,
+		},
+		{
+			name:               "only sched latency is collected",
+			podMetas:           []*statesinformer.PodMeta{{Pod: lsPod}},
+			schedLatency:       &metriccache.SchedLatencyMetric{AvgLatencyMicroseconds: 100},
+			targetSchedLatency: 200,
+			wantErr:            -50,
+			wantCollect:        true,
+		},
+		{
+			name:         "sched latency is not used without the target",
+			podMetas:     []*statesinformer.PodMeta{{Pod: lsPod}},
+			psi:          &metriccache.PSIMetric{SomeCPUAvg10: 2},
+			schedLatency: &metriccache.SchedLatencyMetric{AvgLatencyMicroseconds: 1000},
+			wantErr:      -80,
+			wantCollect:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockMetricCache := mockmetriccache.NewMockMetricCache(ctl)
+			psiResult := metriccache.PodInterferenceQueryResult{}
+			if tt.psi != nil {
+				psiResult.Metric = &metriccache.PodInterferenceMetric{
+					MetricName:  metriccache.MetricNamePodPSI,
+					PodUID:      "ls-pod",
+					MetricValue: tt.psi,
+				}
+			}
+			mockMetricCache.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodPSI, gomock.Any(), gomock.Any()).
+				Return(psiResult).AnyTimes()
+			mockMetricCache.EXPECT().GetPodThrottledMetric(gomock.Any(), gomock.Any()).
+				Return(metriccache.PodThrottledQueryResult{Metric: tt.throttled}).AnyTimes()
+			latencyResult := metriccache.PodInterferenceQueryResult{}
+			if tt.schedLatency != nil {
+				latencyResult.Metric = &metriccache.PodInterferenceMetric{
+					MetricName:  metriccache.MetricNamePodSchedLatency,
+					PodUID:      "ls-pod",
+					MetricValue: tt.schedLatency,
+				}
+			}
+			mockMetricCache.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodSchedLatency, gomock.Any(), gomock.Any()).
+				Return(latencyResult).AnyTimes()
+			r := &resmanager{
+				metricCache:                   mockMetricCache,
+				collectResUsedIntervalSeconds: 1,
+			}
+			cpuSuppress := newTestCPUSuppress(r)
+			params := &cpuSuppressFeedbackParams{
+				targetPSIPercent:       10,
+				targetThrottledPercent: 5,
+				targetSchedLatency:     tt.targetSchedLatency,
+			}
+			gotErr, gotCollect := cpuSuppress.getLSInterferenceError(params, tt.podMetas)
+			assert.Equal(t, tt.wantCollect, gotCollect)
+			assert.InDelta(t, tt.wantErr, gotErr, 0.001)
+		})
+	}
+}
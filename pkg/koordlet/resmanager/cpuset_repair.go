@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kubelet"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+// CPUSetRepair verifies the cpuset cgroups of the containers against the cpuset allocated by the scheduler in the
+// pod annotation, and repairs the drifted ones. The cpuset can drift after being applied by the runtime hooks,
+// e.g. the kubelet rewrites the cgroups of the containers after restart. The CPUs pinned by the kubelet static
+// policy for other pods are excluded from the allocated cpuset as the runtime hooks do.
+type CPUSetRepair struct {
+	resmanager   *resmanager
+	executor     resourceexecutor.ResourceUpdateExecutor
+	cgroupReader resourceexecutor.CgroupReader
+}
+
+func NewCPUSetRepair(r *resmanager) *CPUSetRepair {
+	return &CPUSetRepair{
+		resmanager:   r,
+		executor:     resourceexecutor.NewResourceUpdateExecutor(),
+		cgroupReader: r.cgroupReader,
+	}
+}
+
+func (c *CPUSetRepair) repair() {
+	var kubeletPodAllocs apiext.PodCPUAllocs
+	if nodeTopo := c.resmanager.statesInformer.GetNodeTopo(); nodeTopo != nil {
+		podCPUAllocs, err := apiext.GetPodCPUAllocs(nodeTopo.Annotations)
+		if err != nil {
+			klog.V(4).Infof("failed to get pod cpu allocs of node topology, err: %v", err)
+		}
+		kubeletPodAllocs = podCPUAllocs
+	}
+	podsMeta := c.resmanager.statesInformer.GetAllPods()
+	for _, podMeta := range podsMeta {
+		if podMeta == nil || podMeta.Pod == nil || podMeta.Pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		cpusetVal, err := util.GetCPUSetFromPod(podMeta.Pod.Annotations)
+		if err != nil {
+			klog.V(4).Infof("failed to get allocated cpuset of pod %v, err: %v", util.GetPodKey(podMeta.Pod), err)
+			continue
+		}
+		if cpusetVal == "" {
+			continue
+		}
+		allocated, err := cpuset.Parse(cpusetVal)
+		if err != nil {
+			klog.V(4).Infof("failed to parse allocated cpuset %v of pod %v, err: %v", cpusetVal, util.GetPodKey(podMeta.Pod), err)
+			continue
+		}
+		allocated, _ = kubelet.ExcludeKubeletPinnedCPUs(allocated, kubeletPodAllocs, podMeta.Pod.UID)
+
+		for i := range podMeta.Pod.Status.ContainerStatuses {
+			containerStat := &podMeta.Pod.Status.ContainerStatuses[i]
+			if containerStat.ContainerID == "" || containerStat.State.Running == nil {
+				continue
+			}
+			repaired, err := c.repairContainer(podMeta, containerStat, allocated)
+			if err != nil {
+				klog.Warningf("failed to repair cpuset of container %v/%v, err: %v",
+					util.GetPodKey(podMeta.Pod), containerStat.Name, err)
+			}
+			if repaired {
+				metrics.RecordContainerCPUSetRepair(err)
+			}
+		}
+	}
+}
+
+// repairContainer returns true if the cpuset of the container drifts and a repair is attempted.
+func (c *CPUSetRepair) repairContainer(podMeta *statesinformer.PodMeta, containerStat *corev1.ContainerStatus,
+	allocated cpuset.CPUSet) (bool, error) {
+	containerDir, err := koordletutil.GetContainerCgroupPathWithKube(podMeta.CgroupDir, containerStat)
+	if err != nil {
+		return false, fmt.Errorf("get container cgroup path failed, err: %v", err)
+	}
+	current, err := c.cgroupReader.ReadCPUSet(containerDir)
+	if err != nil {
+		return false, fmt.Errorf("read container cpuset failed, err: %v", err)
+	}
+	if current.Equals(allocated) {
+		return false, nil
+	}
+
+	klog.V(4).Infof("cpuset of container %v/%v drifts from %v to %v, try to repair",
+		util.GetPodKey(podMeta.Pod), containerStat.Name, allocated.String(), current.String())
+	eventHelper := audit.V(3).Container(containerStat.Name).Reason("CPUSetRepair").
+		Message("repair container cpuset from %v to %v", current.String(), allocated.String())
+	updater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(system.CPUSetCPUSName, containerDir, allocated.String(), eventHelper)
+	if err != nil {
+		return true, fmt.Errorf("get cpuset updater failed, err: %v", err)
+	}
+	// the drifted value may be the same as the cached one, so the update is not cacheable
+	if _, err = c.executor.Update(false, updater); err != nil {
+		return true, fmt.Errorf("update container cpuset failed, err: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	topov1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func TestCPUSetRepair_repair(t *testing.T) {
+	newPodMeta := func(name, cpusetVal string) *statesinformer.PodMeta {
+		podMeta := createPodMetaByResource(name, map[string]corev1.ResourceRequirements{
+			"main": {},
+		})
+		podMeta.Pod.Status.Phase = corev1.PodRunning
+		podMeta.Pod.Status.ContainerStatuses[0].State.Running = &corev1.ContainerStateRunning{}
+		if cpusetVal != "" {
+			podMeta.Pod.Annotations = map[string]string{
+				apiext.AnnotationResourceStatus: `{"cpuset": "` + cpusetVal + `"}`,
+			}
+		}
+		return podMeta
+	}
+	tests := []struct {
+		name          string
+		podMeta       *statesinformer.PodMeta
+		kubeletCPUSet string
+		currentCPUSet string
+		wantCPUSet    string
+	}{
+		{
+			name:          "repair drifted cpuset",
+			podMeta:       newPodMeta("test-pod-1", "2-3"),
+			currentCPUSet: "0-7",
+			wantCPUSet:    "2-3",
+		},
+		{
+			name:          "keep cpuset matching the allocation",
+			podMeta:       newPodMeta("test-pod-2", "2-3"),
+			currentCPUSet: "2,3",
+			wantCPUSet:    "2,3",
+		},
+		{
+			name:          "ignore pod without cpuset allocated",
+			podMeta:       newPodMeta("test-pod-3", ""),
+			currentCPUSet: "0-7",
+			wantCPUSet:    "0-7",
+		},
+		{
+			name:          "exclude the CPUs pinned by kubelet for other pods",
+			podMeta:       newPodMeta("test-pod-4", "2-3"),
+			kubeletCPUSet: "3-4",
+			currentCPUSet: "2-3",
+			wantCPUSet:    "2",
+		},
+		{
+			name:          "keep the allocated cpuset if all pinned by kubelet",
+			podMeta:       newPodMeta("test-pod-5", "2-3"),
+			kubeletCPUSet: "0-7",
+			currentCPUSet: "0-7",
+			wantCPUSet:    "2-3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			containerDir, err := util.GetContainerCgroupPathWithKube(tt.podMeta.CgroupDir, &tt.podMeta.Pod.Status.ContainerStatuses[0])
+			assert.NoError(t, err)
+			helper.WriteCgroupFileContents(containerDir, system.CPUSet, tt.currentCPUSet)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockStatesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+			mockStatesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{tt.podMeta}).AnyTimes()
+			nodeTopo := &topov1alpha1.NodeResourceTopology{}
+			if tt.kubeletCPUSet != "" {
+				nodeTopo.ObjectMeta = metav1.ObjectMeta{
+					Annotations: map[string]string{
+						apiext.AnnotationNodeCPUAllocs: `[{"uid": "kubelet-pod", "cpuset": "` + tt.kubeletCPUSet + `", "managedByKubelet": true}]`,
+					},
+				}
+			}
+			mockStatesInformer.EXPECT().GetNodeTopo().Return(nodeTopo).AnyTimes()
+
+			c := NewCPUSetRepair(&resmanager{
+				statesInformer: mockStatesInformer,
+				cgroupReader:   resourceexecutor.NewCgroupReader(),
+			})
+			c.repair()
+			assert.Equal(t, tt.wantCPUSet, helper.ReadCgroupFileContents(containerDir, system.CPUSet))
+		})
+	}
+}
@@ -0,0 +1,372 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+const (
+	nodeTuningFailedReason     = "NodeTuningFailed"
+	nodeTuningRolledBackReason = "NodeTuningRolledBack"
+)
+
+// tuningItem is a file to tune and its desired value.
+type tuningItem struct {
+	value string
+	// isApplied checks if the current content of the file is the desired value, since the kernel can format the
+	// written value differently, e.g. the cpu list and the cpu mask.
+	isApplied func(current string) bool
+}
+
+// NodeTuning applies the tuning profile of the node declared in the NodeSLO, i.e. the sysctls and the IRQ affinity
+// of the network devices, which complements the cpuset isolation of the LS pods. Every value written is read back
+// for verification, and the original value is restored if the verification fails, or the value is removed from
+// the profile. The original values are persisted in a file, so that the values tuned before koordlet restarts can
+// still be rolled back.
+type NodeTuning struct {
+	resmanager *resmanager
+	// originsPath is the file to persist the origins, the origins are kept in memory only if it is empty.
+	originsPath string
+	// origins are the contents of the tuned files before the first write by the file.
+	origins map[string]string
+	// originsChanged indicates the origins are changed since the last persistence.
+	originsChanged bool
+	// failures are the values failed to apply by the file, which are not retried until the desired value changes.
+	failures map[string]string
+}
+
+func NewNodeTuning(r *resmanager) *NodeTuning {
+	n := &NodeTuning{
+		resmanager: r,
+		origins:    map[string]string{},
+		failures:   map[string]string{},
+	}
+	if r.config != nil {
+		n.originsPath = r.config.NodeTuningOriginsPath
+	}
+	n.loadOrigins()
+	return n
+}
+
+func (n *NodeTuning) reconcile() {
+	nodeSLO := n.resmanager.getNodeSLOCopy()
+	if nodeSLO == nil {
+		klog.V(5).Infof("nodeSLO is nil, skip reconcile node tuning")
+		return
+	}
+	var profile *slov1alpha1.NodeTuningProfile
+	if nodeSLO.Spec.SystemStrategy != nil {
+		profile = nodeSLO.Spec.SystemStrategy.NodeTuning
+	}
+
+	items := map[string]*tuningItem{}
+	if profile != nil {
+		n.calculateSysctlItems(profile.Sysctls, items)
+		n.calculateIRQAffinityItems(profile.IRQAffinity, items)
+	}
+	n.apply(items)
+	n.rollbackRemoved(items)
+	n.saveOrigins()
+	klog.V(5).Infof("finish to reconcile node tuning, profile %s, tuned files %d", getNodeTuningProfileName(profile), len(n.origins))
+}
+
+func (n *NodeTuning) calculateSysctlItems(sysctls map[string]string, items map[string]*tuningItem) {
+	for name, value := range sysctls {
+		path, err := sysutil.GetSysctlFilePath(name)
+		if err != nil {
+			klog.Warningf("skip the invalid sysctl of node tuning, err: %v", err)
+			continue
+		}
+		desired := strings.Join(strings.Fields(value), " ")
+		items[path] = &tuningItem{
+			value: desired,
+			isApplied: func(current string) bool {
+				// the kernel separates the values of a parameter by tabs, e.g. net.ipv4.tcp_rmem
+				return strings.Join(strings.Fields(current), " ") == desired
+			},
+		}
+	}
+}
+
+func (n *NodeTuning) calculateIRQAffinityItems(profile *slov1alpha1.IRQAffinityProfile, items map[string]*tuningItem) {
+	if profile == nil || len(profile.Devices) <= 0 {
+		return
+	}
+	cpus, err := n.getIRQAffinityCPUs(profile)
+	if err != nil {
+		klog.Warningf("skip the irq affinity of node tuning, err: %v", err)
+		return
+	}
+	if cpus.IsEmpty() {
+		klog.Warningf("skip the irq affinity of node tuning, no cpu left for the interrupts")
+		return
+	}
+
+	isCPUsApplied := func(parse func(string) (cpuset.CPUSet, error)) func(string) bool {
+		return func(current string) bool {
+			currentCPUs, err := parse(current)
+			return err == nil && currentCPUs.Equals(cpus)
+		}
+	}
+	parseCPUMask := func(mask string) (cpuset.CPUSet, error) {
+		ids, err := sysutil.ParseCPUMask(mask)
+		if err != nil {
+			return cpuset.CPUSet{}, err
+		}
+		return cpuset.NewCPUSet(ids...), nil
+	}
+	for _, device := range profile.Devices {
+		irqs, err := sysutil.GetNetDeviceIRQs(device)
+		if err != nil {
+			klog.V(4).Infof("failed to get the irqs of device %s for node tuning, err: %v", device, err)
+			continue
+		}
+		for _, irq := range irqs {
+			items[sysutil.GetIRQAffinityListFilePath(irq)] = &tuningItem{
+				value:     cpus.String(),
+				isApplied: isCPUsApplied(cpuset.Parse),
+			}
+		}
+		if len(irqs) > 0 {
+			continue
+		}
+		// the virtual devices like macvlan have no irq, so the received packets are steered by the RPS instead
+		rpsPaths, err := sysutil.GetNetDeviceRPSCPUsFilePaths(device)
+		if err != nil {
+			klog.V(4).Infof("failed to get the rps files of device %s for node tuning, err: %v", device, err)
+			continue
+		}
+		for _, path := range rpsPaths {
+			items[path] = &tuningItem{
+				value:     sysutil.FormatCPUMask(cpus.ToSlice()),
+				isApplied: isCPUsApplied(parseCPUMask),
+			}
+		}
+	}
+}
+
+// getIRQAffinityCPUs returns the cpus the interrupts are steered to, which are the declared cpus or all the cpus of
+// the node, excluding the ones bound by the LSE and LSR pods if required.
+func (n *NodeTuning) getIRQAffinityCPUs(profile *slov1alpha1.IRQAffinityProfile) (cpuset.CPUSet, error) {
+	var cpus cpuset.CPUSet
+	if profile.CPUs != "" {
+		parsed, err := cpuset.Parse(profile.CPUs)
+		if err != nil {
+			return cpuset.CPUSet{}, err
+		}
+		cpus = parsed
+	} else {
+		nodeCPUInfo, err := n.resmanager.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
+		if err != nil {
+			return cpuset.CPUSet{}, err
+		}
+		builder := cpuset.NewCPUSetBuilder()
+		for _, p := range nodeCPUInfo.ProcessorInfos {
+			builder.Add(int(p.CPUID))
+		}
+		cpus = builder.Result()
+	}
+	if profile.ExcludeExclusiveCPUs == nil || !*profile.ExcludeExclusiveCPUs {
+		return cpus, nil
+	}
+
+	exclusiveCPUs := cpuset.NewCPUSetBuilder()
+	for _, podMeta := range n.resmanager.statesInformer.GetAllPods() {
+		qosClass := apiext.GetPodQoSClass(podMeta.Pod)
+		if qosClass != apiext.QoSLSE && qosClass != apiext.QoSLSR {
+			continue
+		}
+		alloc, err := apiext.GetResourceStatus(podMeta.Pod.Annotations)
+		if err != nil || alloc.CPUSet == "" {
+			continue
+		}
+		set, err := cpuset.Parse(alloc.CPUSet)
+		if err != nil {
+			klog.V(4).Infof("failed to parse cpuset info of pod %s, err: %v", util.GetPodKey(podMeta.Pod), err)
+			continue
+		}
+		exclusiveCPUs.Add(set.ToSliceNoSort()...)
+	}
+	return cpus.Difference(exclusiveCPUs.Result()), nil
+}
+
+func (n *NodeTuning) apply(items map[string]*tuningItem) {
+	for _, path := range getSortedTuningPaths(items) {
+		item := items[path]
+		if failedValue, ok := n.failures[path]; ok && failedValue == item.value {
+			continue
+		}
+		delete(n.failures, path)
+
+		current, err := sysutil.CommonFileRead(path)
+		if err != nil {
+			klog.V(4).Infof("failed to read %s for node tuning, err: %v", path, err)
+			continue
+		}
+		if item.isApplied(current) {
+			continue
+		}
+		if _, ok := n.origins[path]; !ok {
+			n.origins[path] = current
+			n.originsChanged = true
+		}
+
+		err = sysutil.CommonFileWrite(path, item.value)
+		if err == nil {
+			current, err = sysutil.CommonFileRead(path)
+		}
+		if err == nil && item.isApplied(current) {
+			_ = audit.V(3).Node().Reason("nodeTuning reconcile").Message("update %s to %s", path, item.value).Do()
+			continue
+		}
+		// the kernel can reject or adjust the value, e.g. a managed irq or a value out of the range
+		klog.Warningf("failed to verify node tuning of %s, desired %s, current %s, err: %v", path, item.value,
+			strings.TrimSpace(current), err)
+		n.failures[path] = item.value
+		n.recordEvent(corev1.EventTypeWarning, nodeTuningFailedReason, "failed to tune %s to %s, rolled back", path, item.value)
+		n.rollback(path)
+	}
+}
+
+// rollbackRemoved restores the files no longer in the profile.
+func (n *NodeTuning) rollbackRemoved(items map[string]*tuningItem) {
+	for path := range n.failures {
+		if _, ok := items[path]; !ok {
+			delete(n.failures, path)
+		}
+	}
+	var removed []string
+	for path := range n.origins {
+		if _, ok := items[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+	for _, path := range removed {
+		n.rollback(path)
+	}
+	if len(removed) > 0 {
+		n.recordEvent(corev1.EventTypeNormal, nodeTuningRolledBackReason, "rolled back %d files removed from the node tuning profile",
+			len(removed))
+	}
+}
+
+func (n *NodeTuning) rollback(path string) {
+	origin, ok := n.origins[path]
+	if !ok {
+		return
+	}
+	if err := sysutil.CommonFileWrite(path, strings.TrimSpace(origin)); err != nil {
+		// the file can be gone with the device or the irq, which needs no rollback
+		klog.Warningf("failed to roll back node tuning of %s to %s, err: %v", path, strings.TrimSpace(origin), err)
+	} else {
+		_ = audit.V(3).Node().Reason("nodeTuning rollback").Message("roll back %s to %s", path, strings.TrimSpace(origin)).Do()
+	}
+	delete(n.origins, path)
+	n.originsChanged = true
+}
+
+// loadOrigins restores the origins persisted before koordlet restarts.
+func (n *NodeTuning) loadOrigins() {
+	if n.originsPath == "" {
+		return
+	}
+	data, err := os.ReadFile(n.originsPath)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		klog.Warningf("failed to read the node tuning origins %s, err: %v", n.originsPath, err)
+		return
+	}
+	origins := map[string]string{}
+	if err = json.Unmarshal(data, &origins); err != nil {
+		klog.Warningf("failed to parse the node tuning origins %s, err: %v", n.originsPath, err)
+		return
+	}
+	n.origins = origins
+	klog.V(4).Infof("restored the node tuning origins of %d files from %s", len(origins), n.originsPath)
+}
+
+// saveOrigins persists the origins if changed. The file is replaced by renaming so that a partial write never
+// corrupts the persisted origins.
+func (n *NodeTuning) saveOrigins() {
+	if n.originsPath == "" || !n.originsChanged {
+		return
+	}
+	data, err := json.Marshal(n.origins)
+	if err != nil {
+		klog.Warningf("failed to marshal the node tuning origins, err: %v", err)
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(n.originsPath), 0755); err != nil {
+		klog.Warningf("failed to create the dir of the node tuning origins %s, err: %v", n.originsPath, err)
+		return
+	}
+	tmpPath := n.originsPath + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0644); err == nil {
+		err = os.Rename(tmpPath, n.originsPath)
+	}
+	if err != nil {
+		klog.Warningf("failed to persist the node tuning origins %s, err: %v", n.originsPath, err)
+		return
+	}
+	n.originsChanged = false
+}
+
+func (n *NodeTuning) recordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if n.resmanager.eventRecorder == nil {
+		return
+	}
+	node := n.resmanager.statesInformer.GetNode()
+	if node == nil {
+		return
+	}
+	n.resmanager.eventRecorder.Eventf(node, eventType, reason, messageFmt, args...)
+}
+
+func getSortedTuningPaths(items map[string]*tuningItem) []string {
+	paths := make([]string, 0, len(items))
+	for path := range items {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func getNodeTuningProfileName(profile *slov1alpha1.NodeTuningProfile) string {
+	if profile == nil {
+		return "<none>"
+	}
+	return profile.Name
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func TestNodeTuning_reconcile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+
+	netDir := filepath.Join(system.Conf.SysRootDir, system.SysNetSubDir)
+	helper.WriteFileContents(filepath.Join(netDir, "eth0", "device", "msi_irqs", "98"), "msix")
+	helper.WriteProcSubFileContents("irq/98/smp_affinity_list", "0-7\n")
+	helper.WriteFileContents(filepath.Join(netDir, "macvlan0", "queues", "rx-0", system.RPSCPUsFileName), "00000000\n")
+	helper.WriteProcSubFileContents("sys/net/core/somaxconn", "128\n")
+	helper.WriteProcSubFileContents("sys/net/ipv4/tcp_rmem", "4096\t87380\t6291456\n")
+	// the kernel does not keep the value written, which fails the verification
+	assert.NoError(t, os.MkdirAll(system.GetProcFilePath("sys/kernel"), 0755))
+	assert.NoError(t, os.Symlink(os.DevNull, system.GetProcFilePath("sys/kernel/unverifiable")))
+
+	lsrPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "lsr-pod",
+			Labels:      map[string]string{apiext.LabelPodQoS: string(apiext.QoSLSR)},
+			Annotations: map[string]string{apiext.AnnotationResourceStatus: `{"cpuset": "2-3"}`},
+		},
+	}
+	nodeSLO := &slov1alpha1.NodeSLO{
+		Spec: slov1alpha1.NodeSLOSpec{
+			SystemStrategy: &slov1alpha1.SystemStrategy{
+				NodeTuning: &slov1alpha1.NodeTuningProfile{
+					Name: "ls-role",
+					Sysctls: map[string]string{
+						"net.core.somaxconn":     "4096",
+						"net.ipv4.tcp_rmem":      "4096 87380 6291456",
+						"kernel.unverifiable":    "1",
+						"../../etc/invalid-name": "1",
+					},
+					IRQAffinity: &slov1alpha1.IRQAffinityProfile{
+						Devices:              []string{"eth0", "macvlan0", "not-exist"},
+						CPUs:                 "0-7",
+						ExcludeExclusiveCPUs: pointer.Bool(true),
+					},
+				},
+			},
+		},
+	}
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetNodeSLO().DoAndReturn(func() *slov1alpha1.NodeSLO { return nodeSLO }).AnyTimes()
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{{Pod: lsrPod}}).AnyTimes()
+	statesInformer.EXPECT().GetNode().Return(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}).AnyTimes()
+	recorder := &FakeRecorder{}
+	cfg := &Config{NodeTuningOriginsPath: filepath.Join(helper.TempDir, "koordlet", "node_tuning_origins.json")}
+	n := NewNodeTuning(&resmanager{config: cfg, statesInformer: statesInformer, eventRecorder: recorder})
+
+	assertContent := func(path, want string) {
+		got, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, want, string(got), path)
+	}
+	somaxconnPath := system.GetProcFilePath("sys/net/core/somaxconn")
+	irqPath := system.GetIRQAffinityListFilePath(98)
+	rpsPath := filepath.Join(netDir, "macvlan0", "queues", "rx-0", system.RPSCPUsFileName)
+
+	n.reconcile()
+	assertContent(somaxconnPath, "4096")
+	assertContent(irqPath, "0-1,4-7")
+	assertContent(rpsPath, "f3")
+	// the value formatted by the kernel is regarded as applied
+	assertContent(system.GetProcFilePath("sys/net/ipv4/tcp_rmem"), "4096\t87380\t6291456\n")
+	assert.Equal(t, nodeTuningFailedReason, recorder.eventReason)
+	assert.Equal(t, map[string]string{system.GetProcFilePath("sys/kernel/unverifiable"): "1"}, n.failures)
+	assert.Equal(t, map[string]string{
+		somaxconnPath: "128",
+		irqPath:       "0-7",
+		rpsPath:       "00000000",
+	}, n.origins)
+
+	// the values are kept and the failed one is not retried
+	recorder.eventReason = ""
+	n.reconcile()
+	assertContent(somaxconnPath, "4096")
+	assert.Equal(t, "", recorder.eventReason)
+
+	// the origins are restored after koordlet restarts
+	n = NewNodeTuning(&resmanager{config: cfg, statesInformer: statesInformer, eventRecorder: recorder})
+	assert.Equal(t, map[string]string{
+		somaxconnPath: "128",
+		irqPath:       "0-7",
+		rpsPath:       "00000000",
+	}, n.origins)
+
+	// the values removed from the profile are rolled back
+	nodeSLO = nodeSLO.DeepCopy()
+	nodeSLO.Spec.SystemStrategy.NodeTuning.Sysctls = map[string]string{"net.core.somaxconn": "4096"}
+	nodeSLO.Spec.SystemStrategy.NodeTuning.IRQAffinity.Devices = []string{"macvlan0"}
+	n.reconcile()
+	assertContent(somaxconnPath, "4096")
+	assertContent(irqPath, "0-7")
+	assertContent(rpsPath, "f3")
+	assert.Equal(t, nodeTuningRolledBackReason, recorder.eventReason)
+	assert.Empty(t, n.failures)
+
+	nodeSLO = nodeSLO.DeepCopy()
+	nodeSLO.Spec.SystemStrategy.NodeTuning = nil
+	n.reconcile()
+	assertContent(somaxconnPath, "128")
+	assertContent(rpsPath, "00000000")
+	assert.Empty(t, n.origins)
+	assertContent(cfg.NodeTuningOriginsPath, "{}")
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	// RootResctrlGroup is the resctrl root group holding the tasks not assigned to any QoS class, e.g. system daemons.
+	RootResctrlGroup = "Root"
+
+	// memoryBandwidthUncontendedRatio is the ratio of the contention threshold below which the node memory bandwidth
+	// must drop to lift the throttling, so that the MBA schemata does not flap around the threshold.
+	memoryBandwidthUncontendedRatio = 0.8
+)
+
+// resctrlMonitor measures the LLC occupancy (CMT) and the memory bandwidth (MBM) of the resctrl groups of the QoS
+// classes, and of the monitoring groups of the pods created under them. The bandwidth is calculated from the
+// accumulated traffic bytes between two reconciliations.
+type resctrlMonitor struct {
+	lastSamples map[string]*resctrlMonSample
+	// nodeBandwidth is the total memory bandwidth of the node in bytes per second measured in the last round,
+	// or negative if unknown.
+	nodeBandwidth float64
+	// contended is whether the node memory bandwidth is contended, where the groups are throttled by the MBA.
+	contended bool
+}
+
+type resctrlMonSample struct {
+	data *system.ResctrlMonData
+	time time.Time
+}
+
+func newResctrlMonitor() *resctrlMonitor {
+	return &resctrlMonitor{
+		lastSamples:   map[string]*resctrlMonSample{},
+		nodeBandwidth: -1,
+		contended:     true,
+	}
+}
+
+func isResctrlMonitorEnabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.ResctrlMonitor) && system.IsResctrlMonitorEnabled()
+}
+
+// getPodResctrlMonGroup returns the path of the monitoring group of the pod under the resctrl group of its QoS class.
+func getPodResctrlMonGroup(group string, pod *corev1.Pod) string {
+	return system.GetResctrlMonGroupPath(group, string(pod.UID))
+}
+
+// initPodResctrlMonGroup creates the monitoring group of the pod if not exist. It can fail when the RMIDs of the
+// hardware are exhausted, then the tasks of the pod are only assigned to the resctrl group of the QoS class.
+func initPodResctrlMonGroup(group string, pod *corev1.Pod) (string, bool) {
+	monGroup := getPodResctrlMonGroup(group, pod)
+	if err := initCatGroupIfNotExist(monGroup); err != nil {
+		klog.V(4).Infof("failed to init resctrl monitoring group for pod %s, err: %v", util.GetPodKey(pod), err)
+		return "", false
+	}
+	return monGroup, true
+}
+
+// cleanupResctrlMonGroups removes the monitoring groups of the pods no longer in the resctrl groups to release
+// their RMIDs.
+func cleanupResctrlMonGroups(activeMonGroups map[string]sets.String) {
+	for _, group := range resctrlGroupList {
+		monGroups, err := system.ListResctrlMonGroups(group)
+		if err != nil {
+			klog.V(5).Infof("failed to list resctrl monitoring groups of group %s, err: %v", group, err)
+			continue
+		}
+		for _, monGroup := range monGroups {
+			if activeMonGroups[group].Has(monGroup) {
+				continue
+			}
+			path := system.GetResctrlGroupRootDirPath(system.GetResctrlMonGroupPath(group, monGroup))
+			if err := os.Remove(path); err != nil {
+				klog.V(4).Infof("failed to remove resctrl monitoring group %s, err: %v", path, err)
+				continue
+			}
+			klog.V(5).Infof("resctrl monitoring group %s removed", path)
+		}
+	}
+}
+
+// collect measures the resctrl groups and the monitoring groups of the pods, and records them as the metrics.
+func (m *resctrlMonitor) collect(podsMeta []*statesinformer.PodMeta, now time.Time) {
+	metrics.ResetResctrlMonitor()
+	samples := map[string]*resctrlMonSample{}
+
+	nodeBandwidth := float64(0)
+	nodeBandwidthKnown := true
+	for _, group := range append([]string{RootResctrlGroup}, resctrlGroupList...) {
+		groupPath := group
+		if group == RootResctrlGroup {
+			groupPath = ""
+		}
+		data, total, local, ok := m.measure(groupPath, now, samples)
+		if data == nil {
+			nodeBandwidthKnown = false
+			continue
+		}
+		metrics.RecordResctrlLLCOccupancy(group, float64(data.LLCOccupancy))
+		if !ok {
+			nodeBandwidthKnown = false
+			continue
+		}
+		metrics.RecordResctrlMemoryBandwidth(group, metrics.MemoryBandwidthTotal, total)
+		metrics.RecordResctrlMemoryBandwidth(group, metrics.MemoryBandwidthLocal, local)
+		nodeBandwidth += total
+	}
+	m.nodeBandwidth = -1
+	if nodeBandwidthKnown {
+		m.nodeBandwidth = nodeBandwidth
+	}
+
+	for _, podMeta := range podsMeta {
+		pod := podMeta.Pod
+		group := getPodResctrlGroup(pod)
+		if group == UnknownResctrlGroup {
+			continue
+		}
+		monGroup := getPodResctrlMonGroup(group, pod)
+		if _, err := os.Stat(system.GetResctrlGroupRootDirPath(monGroup)); err != nil {
+			continue
+		}
+		data, total, local, ok := m.measure(monGroup, now, samples)
+		if data == nil {
+			continue
+		}
+		metrics.RecordPodResctrlLLCOccupancy(pod, group, float64(data.LLCOccupancy))
+		if ok {
+			metrics.RecordPodResctrlMemoryBandwidth(pod, group, metrics.MemoryBandwidthTotal, total)
+			metrics.RecordPodResctrlMemoryBandwidth(pod, group, metrics.MemoryBandwidthLocal, local)
+		}
+	}
+	m.lastSamples = samples
+}
+
+// measure reads the monitoring data of the group, and returns the total and local memory bandwidth since the last
+// sample. The bandwidth is unknown for the first sample or the counters reset (e.g. the group is recreated).
+func (m *resctrlMonitor) measure(groupPath string, now time.Time, samples map[string]*resctrlMonSample) (*system.ResctrlMonData, float64, float64, bool) {
+	data, err := system.ReadResctrlMonData(groupPath)
+	if err != nil {
+		klog.V(5).Infof("failed to read resctrl monitoring data of group %s, err: %v", groupPath, err)
+		return nil, 0, 0, false
+	}
+	samples[groupPath] = &resctrlMonSample{data: data, time: now}
+	last, ok := m.lastSamples[groupPath]
+	if !ok {
+		return data, 0, 0, false
+	}
+	seconds := now.Sub(last.time).Seconds()
+	if seconds <= 0 || data.MBMTotalBytes < last.data.MBMTotalBytes || data.MBMLocalBytes < last.data.MBMLocalBytes {
+		return data, 0, 0, false
+	}
+	total := float64(data.MBMTotalBytes-last.data.MBMTotalBytes) / seconds
+	local := float64(data.MBMLocalBytes-last.data.MBMLocalBytes) / seconds
+	return data, total, local, true
+}
+
+// isMemoryBandwidthContended returns whether the memory bandwidth of the node is contended, where the groups should
+// be throttled by the MBA. It is always contended when the threshold is not set or the bandwidth is unknown.
+func (m *resctrlMonitor) isMemoryBandwidthContended(thresholdMBps int) bool {
+	if m == nil || thresholdMBps <= 0 || m.nodeBandwidth < 0 {
+		return true
+	}
+	threshold := float64(thresholdMBps) * 1000 * 1000
+	if m.contended && m.nodeBandwidth < threshold*memoryBandwidthUncontendedRatio {
+		klog.V(4).Infof("node memory bandwidth %.0f B/s drops below the contention threshold %v MB/s, lift the mba throttling",
+			m.nodeBandwidth, thresholdMBps)
+		m.contended = false
+	} else if !m.contended && m.nodeBandwidth >= threshold {
+		klog.V(4).Infof("node memory bandwidth %.0f B/s exceeds the contention threshold %v MB/s, apply the mba throttling",
+			m.nodeBandwidth, thresholdMBps)
+		m.contended = true
+	}
+	return m.contended
+}
+
+// collectResctrlMonitor collects the resctrl monitoring data if enabled, otherwise the measured bandwidth is dropped.
+func (r *ResctrlReconcile) collectResctrlMonitor() {
+	if r.monitor == nil {
+		return
+	}
+	if !isResctrlMonitorEnabled() {
+		r.monitor.nodeBandwidth = -1
+		return
+	}
+	r.monitor.collect(r.resManager.statesInformer.GetAllPods(), time.Now())
+}
+
+func (r *ResctrlReconcile) isMemoryBandwidthContended() bool {
+	if r.resManager == nil || r.resManager.config == nil {
+		return true
+	}
+	return r.monitor.isMemoryBandwidthContended(r.resManager.config.MemoryBandwidthContentionThresholdMBps)
+}
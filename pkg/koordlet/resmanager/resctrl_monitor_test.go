@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resmanager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	mock_statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer/mockstatesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func testingPrepareResctrlMonData(t *testing.T, groupPath string, llcOccupancy, mbmTotalBytes, mbmLocalBytes uint64) {
+	monDataDir := filepath.Join(system.GetResctrlGroupRootDirPath(groupPath), system.ResctrlMonDataDir, "mon_L3_00")
+	assert.NoError(t, os.MkdirAll(monDataDir, 0700))
+	for name, v := range map[string]uint64{
+		system.ResctrlLLCOccupancyName:  llcOccupancy,
+		system.ResctrlMBMTotalBytesName: mbmTotalBytes,
+		system.ResctrlMBMLocalBytesName: mbmLocalBytes,
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(monDataDir, name), []byte(fmt.Sprintf("%d\n", v)), 0666))
+	}
+}
+
+// testingRecordingExecutor records the paths updated by the executor in order.
+type testingRecordingExecutor struct {
+	resourceexecutor.ResourceUpdateExecutor
+	paths []string
+}
+
+func (e *testingRecordingExecutor) Update(cacheable bool, updater resourceexecutor.ResourceUpdater) (bool, error) {
+	e.paths = append(e.paths, updater.Path())
+	return e.ResourceUpdateExecutor.Update(cacheable, updater)
+}
+
+func testingEnableResctrlMonitor(t *testing.T) func() {
+	assert.NoError(t, os.MkdirAll(filepath.Join(system.GetResctrlSubsystemDirPath(), system.RdtInfoDir, system.L3MonDir), 0700))
+	assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(map[string]bool{string(features.ResctrlMonitor): true}))
+	return func() {
+		assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(map[string]bool{string(features.ResctrlMonitor): false}))
+	}
+}
+
+func Test_resctrlMonitor_collect(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	system.Conf.SysFSRootDir = filepath.Join(helper.TempDir, "resctrlMonitor")
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pod0",
+			UID:    "p0",
+			Labels: map[string]string{extension.LabelPodQoS: string(extension.QoSBE)},
+		},
+	}
+	podsMeta := []*statesinformer.PodMeta{{Pod: pod}}
+	monGroup := getPodResctrlMonGroup(BEResctrlGroup, pod)
+
+	m := newResctrlMonitor()
+	now := time.Now()
+	testingPrepareResctrlMonData(t, "", 1000, 1000, 1000)
+	for _, group := range resctrlGroupList {
+		testingPrepareResctrlMonData(t, group, 1000, 1000, 1000)
+	}
+	testingPrepareResctrlMonData(t, monGroup, 1000, 1000, 1000)
+	m.collect(podsMeta, now)
+	// the bandwidth is unknown for the first samples
+	assert.Equal(t, float64(-1), m.nodeBandwidth)
+	assert.True(t, m.isMemoryBandwidthContended(10))
+
+	testingPrepareResctrlMonData(t, "", 1000, 1000+1000*1000, 1000)
+	for _, group := range resctrlGroupList {
+		testingPrepareResctrlMonData(t, group, 1000, 1000+2000*1000, 1000+1000*1000)
+	}
+	testingPrepareResctrlMonData(t, monGroup, 2000, 1000+2000*1000, 1000+1000*1000)
+	m.collect(podsMeta, now.Add(time.Second))
+	assert.Equal(t, float64(7000*1000), m.nodeBandwidth)
+	assert.Len(t, m.lastSamples, 5)
+
+	// the throttling is lifted only when the bandwidth drops below the threshold enough
+	assert.True(t, m.isMemoryBandwidthContended(0))
+	assert.True(t, m.isMemoryBandwidthContended(8))
+	assert.False(t, m.isMemoryBandwidthContended(10))
+	assert.False(t, m.isMemoryBandwidthContended(8))
+	assert.True(t, m.isMemoryBandwidthContended(7))
+
+	// the counters reset, e.g. the group is recreated
+	testingPrepareResctrlMonData(t, BEResctrlGroup, 1000, 1000, 1000)
+	m.collect(podsMeta, now.Add(2*time.Second))
+	assert.Equal(t, float64(-1), m.nodeBandwidth)
+}
+
+func TestResctrlReconcile_calculateAndApplyCatMbPolicyForUncontendedNode(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	system.Conf.SysFSRootDir = filepath.Join(helper.TempDir, "uncontendedMbPolicy")
+	testingPrepareResctrlL3CatGroups(t, "7ff", "L3:0=7ff;1=7ff\nMB:0=100;1=100")
+
+	config := NewDefaultConfig()
+	config.MemoryBandwidthContentionThresholdMBps = 10
+	r := newTestResctrlReconcile(&resmanager{config: config})
+	r.monitor = newResctrlMonitor()
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, r.RunInit(stop))
+
+	resourceQoS := &slov1alpha1.ResourceQOS{
+		ResctrlQOS: &slov1alpha1.ResctrlQOSCfg{
+			ResctrlQOS: slov1alpha1.ResctrlQOS{MBAPercent: pointer.Int64(50)},
+		},
+	}
+	schemataPath := system.GetResctrlSchemataFilePath(BEResctrlGroup)
+
+	// the bandwidth is unknown
+	assert.NoError(t, r.calculateAndApplyCatMbPolicyForGroup(BEResctrlGroup, 2, resourceQoS))
+	out, err := os.ReadFile(schemataPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "MB:0=50;1=50;\n", string(out))
+
+	// the bandwidth is not contended
+	r.monitor.nodeBandwidth = 1000 * 1000
+	assert.NoError(t, r.calculateAndApplyCatMbPolicyForGroup(BEResctrlGroup, 2, resourceQoS))
+	out, err = os.ReadFile(schemataPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "MB:0=100;1=100;\n", string(out))
+}
+
+func TestResctrlReconcile_reconcileResctrlMonGroups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	system.Conf.SysFSRootDir = filepath.Join(helper.TempDir, "reconcileResctrlMonGroups")
+	testingPrepareResctrlL3CatGroups(t, "", "")
+	defer testingEnableResctrlMonitor(t)()
+
+	testingPodMeta := &statesinformer.PodMeta{
+		Pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "pod0",
+				UID:    "p0",
+				Labels: map[string]string{extension.LabelPodQoS: string(extension.QoSBE)},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "container0"}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "container0", ContainerID: "containerd://c0"},
+				},
+			},
+		},
+		CgroupDir: "p0",
+	}
+	testingPrepareContainerCgroupCPUTasks(t, helper, "kubepods.slice/p0/cri-containerd-c0.scope", "122450\n122454")
+	statesInformer := mock_statesinformer.NewMockStatesInformer(ctrl)
+	statesInformer.EXPECT().GetAllPods().Return([]*statesinformer.PodMeta{testingPodMeta}).AnyTimes()
+
+	// the monitoring group of the pod is prepared since the fake resctrl does not create the files
+	monGroup := getPodResctrlMonGroup(BEResctrlGroup, testingPodMeta.Pod)
+	monGroupDir := system.GetResctrlGroupRootDirPath(monGroup)
+	assert.NoError(t, os.MkdirAll(monGroupDir, 0700))
+	assert.NoError(t, os.WriteFile(system.GetResctrlTasksFilePath(monGroup), []byte{}, 0666))
+	staleMonGroupDir := system.GetResctrlGroupRootDirPath(system.GetResctrlMonGroupPath(BEResctrlGroup, "stale"))
+	assert.NoError(t, os.MkdirAll(staleMonGroupDir, 0700))
+
+	r := newTestResctrlReconcile(&resmanager{statesInformer: statesInformer})
+	r.monitor = newResctrlMonitor()
+	executor := &testingRecordingExecutor{ResourceUpdateExecutor: r.executor}
+	r.executor = executor
+	stop := make(chan struct{})
+	defer close(stop)
+	assert.NoError(t, r.RunInit(stop))
+
+	qosStrategy := util.DefaultResourceQOSStrategy()
+	qosStrategy.BEClass.ResctrlQOS.Enable = pointer.Bool(true)
+	r.reconcileResctrlGroups(qosStrategy)
+
+	// the tasks join the QoS class group before its monitoring group
+	assert.Equal(t, []string{
+		system.GetResctrlTasksFilePath(BEResctrlGroup),
+		system.GetResctrlTasksFilePath(monGroup),
+	}, executor.paths)
+	out, err := os.ReadFile(system.GetResctrlTasksFilePath(monGroup))
+	assert.NoError(t, err)
+	assert.Equal(t, "122450122454", string(out))
+	out, err = os.ReadFile(system.GetResctrlTasksFilePath(BEResctrlGroup))
+	assert.NoError(t, err)
+	assert.Equal(t, "122450122454", string(out))
+	_, err = os.Stat(staleMonGroupDir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(monGroupDir)
+	assert.NoError(t, err)
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -54,6 +55,7 @@ type ResctrlReconcile struct {
 	resManager   *resmanager
 	executor     resourceexecutor.ResourceUpdateExecutor
 	cgroupReader resourceexecutor.CgroupReader
+	monitor      *resctrlMonitor
 }
 
 func NewResctrlReconcile(resManager *resmanager) *ResctrlReconcile {
@@ -62,6 +64,7 @@ func NewResctrlReconcile(resManager *resmanager) *ResctrlReconcile {
 		resManager:   resManager,
 		executor:     e,
 		cgroupReader: resManager.cgroupReader,
+		monitor:      newResctrlMonitor(),
 	}
 }
 
@@ -241,6 +244,10 @@ func (r *ResctrlReconcile) calculateAndApplyCatMbPolicyForGroup(group string, l3
 	if memBwPercent == "" {
 		return nil
 	}
+	// the group can use the full bandwidth when the measured memory bandwidth of the node is not contended
+	if !r.isMemoryBandwidthContended() {
+		memBwPercent = "100"
+	}
 	// calculate updating resource
 	resource := resourceexecutor.NewResctrlMbSchemataResource(group, memBwPercent, l3Num)
 
@@ -346,6 +353,13 @@ func (r *ResctrlReconcile) reconcileResctrlGroups(qosStrategy *slov1alpha1.Resou
 		}
 	}
 
+	// with the resctrl monitoring, the tasks of each pod are also assigned to its monitoring group under the group of
+	// its QoS class. a task must join the CTRL_MON group before its MON group, so the QoS class groups are written
+	// before the monitoring groups
+	monitorEnabled := r.monitor != nil && isResctrlMonitorEnabled()
+	activeMonGroups := map[string]sets.String{}
+	taskGroups := append([]string{}, resctrlGroupList...)
+
 	taskIds := map[string][]int32{}
 	podsMeta := r.resManager.statesInformer.GetAllPods()
 	for _, podMeta := range podsMeta {
@@ -363,23 +377,43 @@ func (r *ResctrlReconcile) reconcileResctrlGroups(qosStrategy *slov1alpha1.Resou
 		}
 
 		// TODO https://github.com/koordinator-sh/koordinator/pull/94#discussion_r858779795
-		if group := getPodResctrlGroup(pod); group != UnknownResctrlGroup {
-			ids := r.getPodCgroupNewTaskIds(podMeta, curTaskMaps[group])
-			taskIds[group] = append(taskIds[group], ids...)
+		group := getPodResctrlGroup(pod)
+		if group == UnknownResctrlGroup {
+			continue
+		}
+		if monitorEnabled {
+			if monGroup, ok := initPodResctrlMonGroup(group, pod); ok {
+				if activeMonGroups[group] == nil {
+					activeMonGroups[group] = sets.NewString()
+				}
+				activeMonGroups[group].Insert(string(pod.UID))
+				monTasksMap, err := system.ReadResctrlTasksMap(monGroup)
+				if err != nil {
+					klog.V(4).Infof("failed to read tasks for resctrl monitoring group %s, err: %s", monGroup, err)
+				}
+				taskGroups = append(taskGroups, monGroup)
+				taskIds[monGroup] = append(taskIds[monGroup], r.getPodCgroupNewTaskIds(podMeta, monTasksMap)...)
+			}
 		}
+		ids := r.getPodCgroupNewTaskIds(podMeta, curTaskMaps[group])
+		taskIds[group] = append(taskIds[group], ids...)
 	}
 
 	// write Cat L3 tasks for each resctrl group
-	for _, group := range resctrlGroupList {
+	for _, group := range taskGroups {
 		err = r.calculateAndApplyCatL3GroupTasks(group, taskIds[group])
 		if err != nil {
 			klog.Warningf("failed to apply l3 cat tasks for group %s, err %s", group, err)
 		}
 	}
+
+	if monitorEnabled {
+		cleanupResctrlMonGroups(activeMonGroups)
+	}
 }
 
 func (r *ResctrlReconcile) reconcile() {
-	// Step 0. create and init them if resctrl groups do not exist
+	// Step 0. create and init them if resctrl groups do not exist, and collect the resctrl monitoring data
 	// Step 1. reconcile rdt policies against `schemata` file
 	// Step 2. reconcile resctrl groups against `tasks` file
 
@@ -408,6 +442,7 @@ func (r *ResctrlReconcile) reconcile() {
 		klog.Warningf("ResctrlReconcile failed, cannot initialize cat resctrl group, err: %s", err)
 		return
 	}
+	r.collectResctrlMonitor()
 	r.reconcileCatResctrlPolicy(nodeSLO.Spec.ResourceQOSStrategy)
 	r.reconcileResctrlGroups(nodeSLO.Spec.ResourceQOSStrategy)
 }
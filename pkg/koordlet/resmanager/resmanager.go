@@ -33,6 +33,7 @@ import (
 	"k8s.io/component-base/featuregate"
 	"k8s.io/klog/v2"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	"github.com/koordinator-sh/koordinator/pkg/features"
@@ -142,12 +143,21 @@ func (r *resmanager) Run(stopCh <-chan struct{}) error {
 	util.RunFeatureWithInit(func() error { return systemConfigReconcile.RunInit(stopCh) }, systemConfigReconcile.reconcile,
 		[]featuregate.Feature{features.SystemConfig}, r.config.ReconcileIntervalSeconds, stopCh)
 
+	nodeTuning := NewNodeTuning(r)
+	util.RunFeature(nodeTuning.reconcile, []featuregate.Feature{features.NodeTuning}, r.config.ReconcileIntervalSeconds, stopCh)
+
+	cpusetRepair := NewCPUSetRepair(r)
+	util.RunFeature(cpusetRepair.repair, []featuregate.Feature{features.CPUSetDriftRepair}, r.config.ReconcileIntervalSeconds, stopCh)
+
 	cpuEvictor := NewCPUEvictor(r)
 	util.RunFeature(cpuEvictor.cpuEvict, []featuregate.Feature{features.BECPUEvict}, r.config.CPUEvictIntervalSeconds, stopCh)
 
 	memoryEvictor := NewMemoryEvictor(r)
 	util.RunFeature(memoryEvictor.memoryEvict, []featuregate.Feature{features.BEMemoryEvict}, r.config.MemoryEvictIntervalSeconds, stopCh)
 
+	cpiInterferenceDetector := NewCPIInterferenceDetector(r)
+	util.RunFeature(cpiInterferenceDetector.detect, []featuregate.Feature{features.CPIInterferenceDetection}, r.config.InterferenceDetectIntervalSeconds, stopCh)
+
 	rdtResCtrl := NewResctrlReconcile(r)
 	util.RunFeatureWithInit(func() error { return rdtResCtrl.RunInit(stopCh) }, rdtResCtrl.reconcile,
 		[]featuregate.Feature{features.RdtResctrl}, r.config.ReconcileIntervalSeconds, stopCh)
@@ -187,6 +197,9 @@ func (r *resmanager) evictPod(evictPod *corev1.Pod, node *corev1.Node, reason st
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      evictPod.Name,
 			Namespace: evictPod.Namespace,
+			Annotations: map[string]string{
+				apiext.AnnotationEvictionInitiator: apiext.EvictionInitiatorKoordlet,
+			},
 		},
 	}
 
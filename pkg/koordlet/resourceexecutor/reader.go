@@ -38,6 +38,7 @@ type CgroupReader interface {
 	ReadMemoryNumaStat(parentDir string) ([]sysutil.NumaMemoryPages, error)
 	ReadCPUTasks(parentDir string) ([]int32, error)
 	ReadPSI(parentDir string) (*PSIByResource, error)
+	ReadDiskIOStat(parentDir string) (*sysutil.DiskIOStat, error)
 }
 
 var _ CgroupReader = &CgroupV1Reader{}
@@ -273,6 +274,31 @@ func (r *CgroupV2Reader) ReadCPUSet(parentDir string) (*cpuset.CPUSet, error) {
 	return &v, nil
 }
 
+func (r *CgroupV1Reader) ReadDiskIOStat(parentDir string) (*sysutil.DiskIOStat, error) {
+	serviceBytesResource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.BlkioIOServiceBytesName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	servicedResource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV1, sysutil.BlkioIOServicedName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	serviceBytes, err := cgroupFileRead(parentDir, serviceBytesResource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cgroup file, err: %v", err)
+	}
+	serviced, err := cgroupFileRead(parentDir, servicedResource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cgroup file, err: %v", err)
+	}
+	// content: `8:0 Read 4096\n8:0 Write 8192\n8:0 Sync 8192\n8:0 Async 4096\n8:0 Total 12288\nTotal 12288`
+	v, err := sysutil.ParseBlkioIOService(serviceBytes, serviced)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup value, err: %v", err)
+	}
+	return v, nil
+}
+
 func (r *CgroupV2Reader) ReadCPUAcctUsage(parentDir string) (uint64, error) {
 	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.CPUAcctUsageName)
 	if !ok {
@@ -385,6 +411,23 @@ func (r *CgroupV2Reader) ReadPSI(parentDir string) (*PSIByResource, error) {
 	return psi, nil
 }
 
+func (r *CgroupV2Reader) ReadDiskIOStat(parentDir string) (*sysutil.DiskIOStat, error) {
+	resource, ok := sysutil.DefaultRegistry.Get(sysutil.CgroupVersionV2, sysutil.BlkioIOServiceBytesName)
+	if !ok {
+		return nil, ErrResourceNotRegistered
+	}
+	s, err := cgroupFileRead(parentDir, resource)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cgroup file, err: %v", err)
+	}
+	// content: `8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n...`
+	v, err := sysutil.ParseIOStatV2(s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse cgroup value %s, err: %v", s, err)
+	}
+	return v, nil
+}
+
 func NewCgroupReader() CgroupReader {
 	if sysutil.GetCurrentCgroupVersion() == sysutil.CgroupVersionV2 {
 		return &CgroupV2Reader{}
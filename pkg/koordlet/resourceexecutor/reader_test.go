@@ -1341,3 +1341,99 @@ func TestCgroupReader_ReadPSI(t *testing.T) {
 		})
 	}
 }
+
+func TestCgroupReader_ReadDiskIOStat(t *testing.T) {
+	type fields struct {
+		UseCgroupsV2      bool
+		ServiceBytesValue string
+		ServicedValue     string
+		IOStatV2Value     string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *sysutil.DiskIOStat
+		wantErr bool
+	}{
+		{
+			name:    "v1 path not exist",
+			fields:  fields{},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse v1 value successfully",
+			fields: fields{
+				ServiceBytesValue: "8:0 Read 4096\n8:0 Write 8192\n8:0 Total 12288\nTotal 12288\n",
+				ServicedValue:     "8:0 Read 1\n8:0 Write 2\n8:0 Total 3\nTotal 3\n",
+			},
+			want: &sysutil.DiskIOStat{
+				ReadBytes:  4096,
+				WriteBytes: 8192,
+				ReadIOs:    1,
+				WriteIOs:   2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "v1 serviced not exist",
+			fields: fields{
+				ServiceBytesValue: "8:0 Read 4096\n8:0 Write 8192\n8:0 Total 12288\nTotal 12288\n",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "v2 path not exist",
+			fields: fields{
+				UseCgroupsV2: true,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "parse v2 value successfully",
+			fields: fields{
+				UseCgroupsV2:  true,
+				IOStatV2Value: "8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n",
+			},
+			want: &sysutil.DiskIOStat{
+				ReadBytes:  4096,
+				WriteBytes: 8192,
+				ReadIOs:    1,
+				WriteIOs:   2,
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse v2 value failed",
+			fields: fields{
+				UseCgroupsV2:  true,
+				IOStatV2Value: "8:0 rbytes=abc\n",
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.fields.UseCgroupsV2)
+			parentDir := "/kubepods.slice"
+			if tt.fields.ServiceBytesValue != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.BlkioIOServiceBytes, tt.fields.ServiceBytesValue)
+			}
+			if tt.fields.ServicedValue != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.BlkioIOServiced, tt.fields.ServicedValue)
+			}
+			if tt.fields.IOStatV2Value != "" {
+				helper.WriteCgroupFileContents(parentDir, sysutil.BlkioIOServiceBytesV2, tt.fields.IOStatV2Value)
+			}
+
+			got, gotErr := NewCgroupReader().ReadDiskIOStat(parentDir)
+			assert.Equal(t, tt.wantErr, gotErr != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
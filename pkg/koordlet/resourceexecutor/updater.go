@@ -38,7 +38,6 @@ func init() {
 	// common
 	DefaultCgroupUpdaterFactory.Register(NewUnlimitedCgroupUpdater,
 		sysutil.CPUCFSQuotaName,
-		sysutil.CPUCFSPeriodName,
 		sysutil.MemoryLimitName,
 	)
 	DefaultCgroupUpdaterFactory.Register(NewCommonCgroupUpdater,
@@ -49,16 +48,20 @@ func init() {
 		sysutil.MemoryWmarkRatioName,
 		sysutil.MemoryWmarkScaleFactorName,
 		sysutil.MemoryWmarkMinAdjName,
+		sysutil.MemorySoftLimitName,
 		sysutil.MemoryPriorityName,
 		sysutil.MemoryUsePriorityOomName,
 		sysutil.MemoryOomGroupName,
+	)
+	// special cases
+	DefaultCgroupUpdaterFactory.Register(NewCPUSharesCgroupUpdater, sysutil.CPUSharesName)
+	DefaultCgroupUpdaterFactory.Register(NewCPUCFSPeriodCgroupUpdater, sysutil.CPUCFSPeriodName)
+	DefaultCgroupUpdaterFactory.Register(NewBlkioThrottleCgroupUpdater,
 		sysutil.BlkioTRIopsName,
 		sysutil.BlkioTRBpsName,
 		sysutil.BlkioTWIopsName,
 		sysutil.BlkioTWBpsName,
 	)
-	// special cases
-	DefaultCgroupUpdaterFactory.Register(NewCPUSharesCgroupUpdater, sysutil.CPUSharesName)
 	DefaultCgroupUpdaterFactory.Register(NewMergeableCgroupUpdaterIfValueLarger,
 		sysutil.MemoryMinName,
 		sysutil.MemoryLowName,
@@ -269,6 +272,14 @@ func NewCPUSharesCgroupUpdater(resourceType sysutil.ResourceType, parentDir stri
 	return NewCgroupUpdater(resourceType, parentDir, value, CgroupUpdateCPUSharesFunc, e)
 }
 
+func NewCPUCFSPeriodCgroupUpdater(resourceType sysutil.ResourceType, parentDir string, value string, e *audit.EventHelper) (ResourceUpdater, error) {
+	return NewCgroupUpdater(resourceType, parentDir, value, CgroupUpdateCPUCFSPeriodFunc, e)
+}
+
+func NewBlkioThrottleCgroupUpdater(resourceType sysutil.ResourceType, parentDir string, value string, e *audit.EventHelper) (ResourceUpdater, error) {
+	return NewCgroupUpdater(resourceType, parentDir, value, CgroupUpdateBlkioThrottleFunc, e)
+}
+
 func NewMergeableCgroupUpdaterWithCondition(resourceType sysutil.ResourceType, parentDir string, value string, mergeCondition MergeConditionFunc, e *audit.EventHelper) (ResourceUpdater, error) {
 	r, err := sysutil.GetCgroupResource(resourceType)
 	if err != nil {
@@ -374,6 +385,36 @@ func CgroupUpdateCPUSharesFunc(resource ResourceUpdater) error {
 	return cgroupWriteIfDifferentWithLog(c)
 }
 
+func CgroupUpdateCPUCFSPeriodFunc(resource ResourceUpdater) error {
+	c := resource.(*CgroupResourceUpdater)
+	// `cpu.max` (v2) holds both the cfs quota and the cfs period, so the current quota should be kept
+	if sysutil.GetCurrentCgroupVersion() == sysutil.CgroupVersionV2 {
+		cpuMax, err := cgroupFileRead(c.parentDir, c.file)
+		if err != nil {
+			return err
+		}
+		v, err := sysutil.ConvertCPUCFSPeriodToCPUMax(cpuMax, c.value)
+		if err != nil {
+			return err
+		}
+		c.value = v
+	}
+	return cgroupWriteIfDifferentWithLog(c)
+}
+
+func CgroupUpdateBlkioThrottleFunc(resource ResourceUpdater) error {
+	c := resource.(*CgroupResourceUpdater)
+	// convert values in `blkio.throttle.*` (v1) into values in `io.max` (v2)
+	if sysutil.GetCurrentCgroupVersion() == sysutil.CgroupVersionV2 {
+		v, err := sysutil.ConvertBlkioThrottleToIOMax(c.ResourceType(), c.value)
+		if err != nil {
+			return err
+		}
+		c.value = v
+	}
+	return cgroupWriteIfDifferentWithLog(c)
+}
+
 type MergeConditionFunc func(oldValue, newValue string) (mergedValue string, needMerge bool, err error)
 
 func MergeFuncUpdateCgroup(resource ResourceUpdater, mergeCondition MergeConditionFunc) (ResourceUpdater, error) {
@@ -179,6 +179,157 @@ func TestCgroupResourceUpdater_Update(t *testing.T) {
 	}
 }
 
+func TestCgroupUpdaterFactory_CgroupsV2(t *testing.T) {
+	type fields struct {
+		UseCgroupsV2 bool
+		initialValue string
+	}
+	type args struct {
+		resourceType sysutil.ResourceType
+		parentDir    string
+		value        string
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		args     args
+		wantFile sysutil.Resource
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "update cfs period on cgroups-v1",
+			fields: fields{
+				initialValue: "100000",
+			},
+			args: args{
+				resourceType: sysutil.CPUCFSPeriodName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "50000",
+			},
+			wantFile: sysutil.CPUCFSPeriod,
+			want:     "50000",
+		},
+		{
+			name: "update cfs period and keep the quota in cpu.max",
+			fields: fields{
+				UseCgroupsV2: true,
+				initialValue: "200000 100000",
+			},
+			args: args{
+				resourceType: sysutil.CPUCFSPeriodName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "50000",
+			},
+			wantFile: sysutil.CPUCFSPeriodV2,
+			want:     "200000 50000",
+		},
+		{
+			name: "update cfs period failed since cpu.max is invalid",
+			fields: fields{
+				UseCgroupsV2: true,
+				initialValue: "200000",
+			},
+			args: args{
+				resourceType: sysutil.CPUCFSPeriodName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "50000",
+			},
+			wantFile: sysutil.CPUCFSPeriodV2,
+			wantErr:  true,
+		},
+		{
+			name: "update cfs burst in cpu.max.burst",
+			fields: fields{
+				UseCgroupsV2: true,
+				initialValue: "0",
+			},
+			args: args{
+				resourceType: sysutil.CPUBurstName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "100000",
+			},
+			wantFile: sysutil.CPUBurstV2,
+			want:     "100000",
+		},
+		{
+			name: "update blkio throttle on cgroups-v1",
+			fields: fields{
+				initialValue: "",
+			},
+			args: args{
+				resourceType: sysutil.BlkioTRBpsName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "253:0 1048576",
+			},
+			wantFile: sysutil.BlkioReadBps,
+			want:     "253:0 1048576",
+		},
+		{
+			name: "update blkio throttle in io.max",
+			fields: fields{
+				UseCgroupsV2: true,
+				initialValue: "",
+			},
+			args: args{
+				resourceType: sysutil.BlkioTWIopsName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "253:0 2000",
+			},
+			wantFile: sysutil.BlkioWriteIopsV2,
+			want:     "253:0 wiops=2000",
+		},
+		{
+			name: "remove blkio throttle in io.max",
+			fields: fields{
+				UseCgroupsV2: true,
+				initialValue: "",
+			},
+			args: args{
+				resourceType: sysutil.BlkioTRBpsName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "253:0 0",
+			},
+			wantFile: sysutil.BlkioReadBpsV2,
+			want:     "253:0 rbps=max",
+		},
+		{
+			name: "update blkio throttle failed since value is invalid",
+			fields: fields{
+				UseCgroupsV2: true,
+				initialValue: "",
+			},
+			args: args{
+				resourceType: sysutil.BlkioTRBpsName,
+				parentDir:    "/kubepods.slice/kubepods.slice-podxxx",
+				value:        "1048576",
+			},
+			wantFile: sysutil.BlkioReadBpsV2,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := sysutil.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			helper.SetCgroupsV2(tt.fields.UseCgroupsV2)
+
+			u, gotErr := DefaultCgroupUpdaterFactory.New(tt.args.resourceType, tt.args.parentDir, tt.args.value, nil)
+			assert.NoError(t, gotErr)
+			c, ok := u.(*CgroupResourceUpdater)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantFile.Path(tt.args.parentDir), c.Path())
+			helper.WriteCgroupFileContents(tt.args.parentDir, c.file, tt.fields.initialValue)
+
+			gotErr = u.update()
+			assert.Equal(t, tt.wantErr, gotErr != nil, gotErr)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, helper.ReadCgroupFileContents(c.parentDir, c.file))
+			}
+		})
+	}
+}
+
 func TestDefaultResourceUpdater_Update(t *testing.T) {
 	type fields struct {
 		initialValue string
@@ -28,6 +28,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/cpuset"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/gpu"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/groupidentity"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks/networkqos"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
@@ -58,6 +59,11 @@ const (
 	//
 	// BatchResource set request and limits of cpu and memory on cgroup file.
 	BatchResource featuregate.Feature = "BatchResource"
+
+	// alpha: v1.1
+	//
+	// NetworkQOS marks the dscp of pod egress traffic according to QoS, so that the switches can prioritize LS traffic.
+	NetworkQOS featuregate.Feature = "NetworkQOS"
 )
 
 var (
@@ -66,6 +72,7 @@ var (
 		CPUSetAllocator: {Default: true, PreRelease: featuregate.Beta},
 		GPUEnvInject:    {Default: false, PreRelease: featuregate.Alpha},
 		BatchResource:   {Default: true, PreRelease: featuregate.Beta},
+		NetworkQOS:      {Default: false, PreRelease: featuregate.Alpha},
 	}
 
 	runtimeHookPlugins = map[featuregate.Feature]HookPlugin{
@@ -73,6 +80,7 @@ var (
 		CPUSetAllocator: cpuset.Object(),
 		GPUEnvInject:    gpu.Object(),
 		BatchResource:   batchresource.Object(),
+		NetworkQOS:      networkqos.Object(),
 	}
 )
 
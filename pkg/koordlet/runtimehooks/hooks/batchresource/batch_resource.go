@@ -21,7 +21,6 @@ import (
 	"sync"
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -31,11 +30,14 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/reconciler"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 const (
 	name        = "BatchResource"
 	description = "set fundamental cgroups value for batch pod"
@@ -50,7 +52,7 @@ type plugin struct {
 var podQOSConditions = []string{string(apiext.QoSBE), string(apiext.QoSLS), string(apiext.QoSNone)}
 
 func (p *plugin) Register(op hooks.Options) {
-	klog.V(5).Infof("register hook %v", name)
+	logger.V(5).Infof("register hook %v", name)
 	rule.Register(name, description,
 		rule.WithParseFunc(statesinformer.RegisterTypeNodeSLOSpec, p.parseRule),
 		rule.WithUpdateCallback(p.ruleUpdateCb))
@@ -89,19 +91,19 @@ func (p *plugin) SetPodResources(proto protocol.HooksProtocol) error {
 
 	err := p.SetPodCPUShares(proto)
 	if err != nil {
-		klog.V(5).Infof("failed to set pod cpu shares in plugin %s, pod %s/%s, err: %v",
+		logger.V(5).Infof("failed to set pod cpu shares in plugin %s, pod %s/%s, err: %v",
 			name, podCtx.Request.PodMeta.Namespace, podCtx.Request.PodMeta.Name, err)
 	}
 
 	err1 := p.SetPodCFSQuota(proto)
 	if err1 != nil {
-		klog.V(5).Infof("failed to set pod cfs quota in plugin %s, pod %s/%s, err: %v",
+		logger.V(5).Infof("failed to set pod cfs quota in plugin %s, pod %s/%s, err: %v",
 			name, podCtx.Request.PodMeta.Namespace, podCtx.Request.PodMeta.Name, err1)
 	}
 
 	err2 := p.SetPodMemoryLimit(proto)
 	if err2 != nil {
-		klog.V(5).Infof("failed to set pod memory limit in plugin %s, pod %s/%s, err: %v",
+		logger.V(5).Infof("failed to set pod memory limit in plugin %s, pod %s/%s, err: %v",
 			name, podCtx.Request.PodMeta.Namespace, podCtx.Request.PodMeta.Name, err2)
 	}
 
@@ -164,7 +166,7 @@ func (p *plugin) SetPodCFSQuota(proto protocol.HooksProtocol) error {
 	// if cfs quota is disabled, set as -1
 	if !p.getRule().getEnableCFSQuota() {
 		podCtx.Response.Resources.CFSQuota = pointer.Int64Ptr(-1)
-		klog.V(5).Infof("try to unset pod-level cfs quota since it is disabled in rule of plugin %v", name)
+		logger.V(5).Infof("try to unset pod-level cfs quota since it is disabled in rule of plugin %v", name)
 		return nil
 	}
 
@@ -236,21 +238,21 @@ func (p *plugin) SetContainerResources(proto protocol.HooksProtocol) error {
 
 	err := p.SetContainerCPUShares(proto)
 	if err != nil {
-		klog.V(5).Infof("failed to set container cpu shares in plugin %s, container %s/%s/%s, err: %v",
+		logger.V(5).Infof("failed to set container cpu shares in plugin %s, container %s/%s/%s, err: %v",
 			name, containerCtx.Request.PodMeta.Namespace, containerCtx.Request.PodMeta.Name,
 			containerCtx.Request.ContainerMeta.Name, err)
 	}
 
 	err1 := p.SetContainerCFSQuota(proto)
 	if err1 != nil {
-		klog.V(5).Infof("failed to set container cfs quota in plugin %s, container %s/%s/%s, err: %v",
+		logger.V(5).Infof("failed to set container cfs quota in plugin %s, container %s/%s/%s, err: %v",
 			name, containerCtx.Request.PodMeta.Namespace, containerCtx.Request.PodMeta.Name,
 			containerCtx.Request.ContainerMeta.Name, err)
 	}
 
 	err2 := p.SetContainerMemoryLimit(proto)
 	if err2 != nil {
-		klog.V(5).Infof("failed to set container memory limit in plugin %s, container %s/%s/%s, err: %v",
+		logger.V(5).Infof("failed to set container memory limit in plugin %s, container %s/%s/%s, err: %v",
 			name, containerCtx.Request.PodMeta.Namespace, containerCtx.Request.PodMeta.Name,
 			containerCtx.Request.ContainerMeta.Name, err)
 	}
@@ -309,7 +311,7 @@ func (p *plugin) SetContainerCFSQuota(proto protocol.HooksProtocol) error {
 	// if cfs quota is disabled, set as -1
 	if !p.getRule().getEnableCFSQuota() {
 		containerCtx.Response.Resources.CFSQuota = pointer.Int64Ptr(-1)
-		klog.V(5).Infof("try to unset container-level cfs quota since it is disabled in rule of plugin %v", name)
+		logger.V(5).Infof("try to unset container-level cfs quota since it is disabled in rule of plugin %v", name)
 		return nil
 	}
 
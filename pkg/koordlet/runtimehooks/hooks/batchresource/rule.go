@@ -19,8 +19,6 @@ package batchresource
 import (
 	"reflect"
 
-	"k8s.io/klog/v2"
-
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
@@ -55,14 +53,14 @@ func (p *plugin) parseRule(mergedNodeSLOIf interface{}) (bool, error) {
 	}
 
 	updated := p.updateRule(rule)
-	klog.V(4).Infof("runtime hook plugin %s update rule %v, new rule %v", name, updated, rule)
+	logger.V(4).Infof("runtime hook plugin %s update rule %v, new rule %v", name, updated, rule)
 	return updated, nil
 }
 
 func (p *plugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 	r := p.getRule()
 	if r == nil {
-		klog.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		logger.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
 		return nil
 	}
 	for _, podMeta := range pods {
@@ -74,7 +72,7 @@ func (p *plugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 		podCtx := &protocol.PodContext{}
 		podCtx.FromReconciler(podMeta)
 		if err := p.SetPodCFSQuota(podCtx); err != nil { // only need to change cfs quota
-			klog.V(4).Infof("failed to set pod cfs quota during callback %v, err: %v", name, err)
+			logger.V(4).Infof("failed to set pod cfs quota during callback %v, err: %v", name, err)
 			continue
 		}
 		podCtx.ReconcilerDone(p.executor)
@@ -83,7 +81,7 @@ func (p *plugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 			containerCtx := &protocol.ContainerContext{}
 			containerCtx.FromReconciler(podMeta, containerStat.Name)
 			if err := p.SetContainerCFSQuota(containerCtx); err != nil {
-				klog.V(4).Infof("failed to set container cfs quota during callback %v, container %v, err: %v",
+				logger.V(4).Infof("failed to set container cfs quota during callback %v, container %v, err: %v",
 					name, containerStat.Name, err)
 				continue
 			}
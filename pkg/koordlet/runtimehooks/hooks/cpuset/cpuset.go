@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"sync"
 
-	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -30,11 +29,14 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/reconciler"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 const (
 	name        = "CPUSetAllocator"
 	description = "set cpuset value by pod allocation"
@@ -49,7 +51,7 @@ type cpusetPlugin struct {
 var podQOSConditions = []string{string(apiext.QoSLSE), string(apiext.QoSLSR)}
 
 func (p *cpusetPlugin) Register(op hooks.Options) {
-	klog.V(5).Infof("register hook %v", name)
+	logger.V(5).Infof("register hook %v", name)
 	hooks.Register(rmconfig.PreCreateContainer, name, description, p.SetContainerCPUSetAndUnsetCFS)
 	hooks.Register(rmconfig.PreUpdateContainerResources, name, description, p.SetContainerCPUSetAndUnsetCFS)
 	hooks.Register(rmconfig.PreRunPodSandbox, name, "unset pod cpu quota if needed", UnsetPodCPUQuota)
@@ -96,6 +98,9 @@ func (p *cpusetPlugin) SetContainerCPUSet(proto protocol.HooksProtocol) error {
 	if cpusetVal, err := util.GetCPUSetFromPod(containerReq.PodAnnotations); err != nil {
 		return err
 	} else if cpusetVal != "" {
+		if r := p.getRule(); r != nil {
+			cpusetVal = r.excludeKubeletPinnedCPUs(&containerReq, cpusetVal)
+		}
 		containerCtx.Response.Resources.CPUSet = pointer.StringPtr(cpusetVal)
 		return nil
 	}
@@ -103,7 +108,7 @@ func (p *cpusetPlugin) SetContainerCPUSet(proto protocol.HooksProtocol) error {
 	// use cpushare pool for pod
 	r := p.getRule()
 	if r == nil {
-		klog.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		logger.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
 		return nil
 	}
 	cpusetValue, err := r.getContainerCPUSet(&containerReq)
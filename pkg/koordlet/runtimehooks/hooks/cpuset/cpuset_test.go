@@ -178,6 +178,30 @@ func Test_cpusetPlugin_SetContainerCPUSet(t *testing.T) {
 			wantErr:    false,
 			wantCPUSet: pointer.StringPtr("2-4"),
 		},
+		{
+			name: "set cpu by pod allocated excluding the cpus pinned by kubelet",
+			fields: fields{
+				rule: &cpusetRule{
+					kubeletPodAllocs: ext.PodCPUAllocs{
+						{UID: "kubelet-pod", CPUSet: "4-5", ManagedByKubelet: true},
+						{UID: "test-pod", CPUSet: "3", ManagedByKubelet: true},
+					},
+				},
+			},
+			args: args{
+				podAlloc: &ext.ResourceStatus{
+					CPUSet: "2-4",
+				},
+				proto: &protocol.ContainerContext{
+					Request: protocol.ContainerRequest{
+						PodMeta:      protocol.PodMeta{UID: "test-pod"},
+						CgroupParent: "kubepods/test-pod/test-container/",
+					},
+				},
+			},
+			wantErr:    false,
+			wantCPUSet: pointer.StringPtr("2-3"),
+		},
 		{
 			name: "set cpu by pod allocated share pool with nil rule",
 			fields: fields{
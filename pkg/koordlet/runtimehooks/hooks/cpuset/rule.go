@@ -23,18 +23,45 @@ import (
 
 	topov1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/klog/v2"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 
 	ext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kubelet"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
 
 type cpusetRule struct {
 	kubeletPolicy ext.KubeletCPUManagerPolicy
 	sharePools    []ext.CPUSharedPool
+	// kubeletPodAllocs are the CPUs pinned by the kubelet static CPU manager for the pods not managed by koordinator
+	kubeletPodAllocs ext.PodCPUAllocs
+}
+
+// excludeKubeletPinnedCPUs keeps the cpuset allocated by koord-scheduler off the CPUs pinned by kubelet for other
+// pods, which kubelet never moves. The conflicts are recorded in the metric.
+func (r *cpusetRule) excludeKubeletPinnedCPUs(containerReq *protocol.ContainerRequest, cpusetVal string) string {
+	if len(r.kubeletPodAllocs) == 0 {
+		return cpusetVal
+	}
+	allocated, err := cpuset.Parse(cpusetVal)
+	if err != nil {
+		logger.V(4).Infof("failed to parse allocated cpuset %v of pod %v/%v, err: %v",
+			cpusetVal, containerReq.PodMeta.Namespace, containerReq.PodMeta.Name, err)
+		return cpusetVal
+	}
+	cpus, conflicted := kubelet.ExcludeKubeletPinnedCPUs(allocated, r.kubeletPodAllocs, types.UID(containerReq.PodMeta.UID))
+	if !conflicted {
+		return cpusetVal
+	}
+	metrics.RecordContainerCPUSetKubeletConflict()
+	logger.Warningf("allocated cpuset %v of container %v/%v/%v overlaps with the CPUs pinned by kubelet, use %v instead",
+		cpusetVal, containerReq.PodMeta.Namespace, containerReq.PodMeta.Name, containerReq.ContainerMeta.Name, cpus.String())
+	return cpus.String()
 }
 
 func (r *cpusetRule) getContainerCPUSet(containerReq *protocol.ContainerRequest) (*string, error) {
@@ -107,9 +134,20 @@ func (p *cpusetPlugin) parseRule(nodeTopoIf interface{}) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	podCPUAllocs, err := ext.GetPodCPUAllocs(nodeTopo.Annotations)
+	if err != nil {
+		return false, err
+	}
+	var kubeletPodAllocs ext.PodCPUAllocs
+	for _, alloc := range podCPUAllocs {
+		if alloc.ManagedByKubelet {
+			kubeletPodAllocs = append(kubeletPodAllocs, alloc)
+		}
+	}
 	newRule := &cpusetRule{
-		kubeletPolicy: *cpuManagerPolicy,
-		sharePools:    cpuSharePools,
+		kubeletPolicy:    *cpuManagerPolicy,
+		sharePools:       cpuSharePools,
+		kubeletPodAllocs: kubeletPodAllocs,
 	}
 	updated := p.updateRule(newRule)
 	return updated, nil
@@ -121,7 +159,7 @@ func (p *cpusetPlugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 			containerCtx := &protocol.ContainerContext{}
 			containerCtx.FromReconciler(podMeta, containerStat.Name)
 			if err := p.SetContainerCPUSet(containerCtx); err != nil {
-				klog.Infof("parse cpuset from pod annotation failed during callback, error: %v", err)
+				logger.Infof("parse cpuset from pod annotation failed during callback, error: %v", err)
 				continue
 			}
 			containerCtx.ReconcilerDone(p.executor)
@@ -329,9 +329,10 @@ func Test_cpusetPlugin_parseRule(t *testing.T) {
 		rule *cpusetRule
 	}
 	type args struct {
-		nodeTopo   *topov1alpha1.NodeResourceTopology
-		cpuPolicy  *ext.KubeletCPUManagerPolicy
-		sharePools []ext.CPUSharedPool
+		nodeTopo     *topov1alpha1.NodeResourceTopology
+		cpuPolicy    *ext.KubeletCPUManagerPolicy
+		sharePools   []ext.CPUSharedPool
+		podCPUAllocs ext.PodCPUAllocs
 	}
 	tests := []struct {
 		name        string
@@ -487,6 +488,36 @@ func Test_cpusetPlugin_parseRule(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "update rule with the cpus pinned by kubelet",
+			fields: fields{
+				rule: nil,
+			},
+			args: args{
+				nodeTopo: &topov1alpha1.NodeResourceTopology{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node",
+					},
+				},
+				cpuPolicy: &ext.KubeletCPUManagerPolicy{
+					Policy: ext.KubeletCPUManagerPolicyStatic,
+				},
+				podCPUAllocs: ext.PodCPUAllocs{
+					{UID: "kubelet-pod", CPUSet: "2-3", ManagedByKubelet: true},
+					{UID: "other-pod", CPUSet: "4-5"},
+				},
+			},
+			wantUpdated: true,
+			wantRule: &cpusetRule{
+				kubeletPolicy: ext.KubeletCPUManagerPolicy{
+					Policy: ext.KubeletCPUManagerPolicyStatic,
+				},
+				kubeletPodAllocs: ext.PodCPUAllocs{
+					{UID: "kubelet-pod", CPUSet: "2-3", ManagedByKubelet: true},
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -504,6 +535,9 @@ func Test_cpusetPlugin_parseRule(t *testing.T) {
 				sharePoolJson := util.DumpJSON(tt.args.sharePools)
 				tt.args.nodeTopo.Annotations[ext.AnnotationNodeCPUSharedPools] = sharePoolJson
 			}
+			if len(tt.args.podCPUAllocs) != 0 {
+				tt.args.nodeTopo.Annotations[ext.AnnotationNodeCPUAllocs] = util.DumpJSON(tt.args.podCPUAllocs)
+			}
 			got, err := p.parseRule(tt.args.nodeTopo)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("parseRule() error = %v, wantErr %v", err, tt.wantErr)
@@ -18,24 +18,62 @@ package gpu
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
-	"k8s.io/klog/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
 
 	ext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
-const GpuAllocEnv = "NVIDIA_VISIBLE_DEVICES"
+const (
+	GpuAllocEnv    = "NVIDIA_VISIBLE_DEVICES"
+	AMDGpuAllocEnv = "AMD_VISIBLE_DEVICES"
 
-type gpuPlugin struct{}
+	// MPSActiveThreadPercentageEnv limits the portion of SMs available to the MPS clients in the container.
+	MPSActiveThreadPercentageEnv = "CUDA_MPS_ACTIVE_THREAD_PERCENTAGE"
+	// MPSPinnedDeviceMemLimitEnv limits the device memory of each visible GPU for the MPS clients in the container,
+	// e.g. "0=4096M,1=4096M", which is indexed by the order of the visible GPUs.
+	MPSPinnedDeviceMemLimitEnv = "CUDA_MPS_PINNED_DEVICE_MEM_LIMIT"
+	// CGPUMemContainerEnv is the GPU memory in GiB of each GPU available to the container isolated by cGPU.
+	CGPUMemContainerEnv = "ALIYUN_COM_GPU_MEM_CONTAINER"
+	// CGPUMemDevEnv is the total GPU memory in GiB of each GPU isolated by cGPU.
+	CGPUMemDevEnv = "ALIYUN_COM_GPU_MEM_DEV"
+
+	// NvidiaGPUDeviceMajor is the major number of the NVIDIA GPU device files, e.g. /dev/nvidia0.
+	NvidiaGPUDeviceMajor = 195
+	// NvidiaModesetDeviceMinor is the minor number of /dev/nvidia-modeset.
+	NvidiaModesetDeviceMinor = 254
+	// NvidiaCtlDeviceMinor is the minor number of /dev/nvidiactl, which is required by all the GPU processes.
+	NvidiaCtlDeviceMinor = 255
+)
+
+var logger = logs.NewLogger(logs.ModuleDeviceShare)
+
+type gpuPlugin struct {
+	executor resourceexecutor.ResourceUpdateExecutor
+	// podDeviceAllocationGetter resolves the devices of the pods referencing PodDeviceAllocations.
+	podDeviceAllocationGetter ext.PodDeviceAllocationGetter
+}
 
 func (p *gpuPlugin) Register(op hooks.Options) {
-	klog.V(5).Infof("register hook %v", "gpu env inject")
-	hooks.Register(rmconfig.PreCreateContainer, "gpu env inject", "inject NVIDIA_VISIBLE_DEVICES env into container", p.InjectContainerGPUEnv)
+	logger.V(5).Infof("register hook %v", "gpu env inject")
+	hooks.Register(rmconfig.PreCreateContainer, "gpu env inject", "inject NVIDIA_VISIBLE_DEVICES or AMD_VISIBLE_DEVICES env into container", p.InjectContainerGPUEnv)
+	hooks.Register(rmconfig.PostStartContainer, "gpu device cgroup", "allow the allocated GPU devices in the container devices cgroup", p.SetContainerGPUDevices)
+	p.executor = op.Executor
+	if op.StatesInformer != nil {
+		p.podDeviceAllocationGetter = op.StatesInformer.GetPodDeviceAllocation
+	}
 }
 
 var singleton *gpuPlugin
@@ -53,22 +91,172 @@ func (p *gpuPlugin) InjectContainerGPUEnv(proto protocol.HooksProtocol) error {
 		return fmt.Errorf("container protocol is nil for plugin gpu")
 	}
 	containerReq := containerCtx.Request
-	alloc, err := ext.GetDeviceAllocations(containerReq.PodAnnotations)
+	alloc, err := p.getDeviceAllocations(&containerReq)
 	if err != nil {
 		return err
 	}
 	devices, ok := alloc[schedulingv1alpha1.GPU]
 	if !ok || len(devices) == 0 {
-		klog.V(5).Infof("no gpu alloc info in pod anno, %s", containerReq.PodMeta.Name)
+		logger.V(5).Infof("no gpu alloc info in pod anno, %s", containerReq.PodMeta.Name)
 		return nil
 	}
 	gpuIDs := []string{}
 	for _, d := range devices {
 		gpuIDs = append(gpuIDs, fmt.Sprintf("%d", d.Minor))
 	}
+	extension, err := ext.GetDeviceAllocationExtension(devices[0])
+	if err != nil {
+		return err
+	}
+	allocEnv := GpuAllocEnv
+	if extension != nil && extension.GPUVendor == ext.GPUVendorAMD {
+		// the AMD container runtime exposes the GPUs by the env, which is consumed by ROCm
+		allocEnv = AMDGpuAllocEnv
+	}
 	if containerCtx.Response.AddContainerEnvs == nil {
 		containerCtx.Response.AddContainerEnvs = make(map[string]string)
 	}
-	containerCtx.Response.AddContainerEnvs[GpuAllocEnv] = strings.Join(gpuIDs, ",")
+	containerCtx.Response.AddContainerEnvs[allocEnv] = strings.Join(gpuIDs, ",")
+	if allocEnv == GpuAllocEnv {
+		injectGPUIsolationEnvs(containerCtx, devices)
+	}
+	return nil
+}
+
+// getDeviceAllocations returns the devices allocated by the pod of the container, which are resolved from the
+// PodDeviceAllocation if the pod references one.
+func (p *gpuPlugin) getDeviceAllocations(req *protocol.ContainerRequest) (ext.DeviceAllocations, error) {
+	return ext.ResolveDeviceAllocations(req.PodMeta.Namespace, types.UID(req.PodMeta.UID), req.PodAnnotations, p.podDeviceAllocationGetter)
+}
+
+// injectGPUIsolationEnvs injects the envs to limit the memory and core of the shared GPUs according to the
+// isolation provider specified by the pod.
+func injectGPUIsolationEnvs(containerCtx *protocol.ContainerContext, devices []*ext.DeviceAllocation) {
+	provider := ext.GPUIsolationProvider(containerCtx.Request.PodLabels[ext.LabelGPUIsolationProvider])
+	if provider == "" || !isSharedGPU(devices) {
+		return
+	}
+	envs := containerCtx.Response.AddContainerEnvs
+	switch provider {
+	case ext.GPUIsolationProviderMPS:
+		var coreLimit int64 = 100
+		memLimits := make([]string, 0, len(devices))
+		for i, d := range devices {
+			if core, ok := getGPUResource(d, ext.ResourceGPUCore, ext.BatchGPUCore); ok && core.Value() < coreLimit {
+				coreLimit = core.Value()
+			}
+			if memory, ok := getGPUResource(d, ext.ResourceGPUMemory, ext.BatchGPUMemory); ok {
+				memLimits = append(memLimits, fmt.Sprintf("%d=%dM", i, memory.Value()/(1024*1024)))
+			}
+		}
+		envs[MPSActiveThreadPercentageEnv] = strconv.FormatInt(coreLimit, 10)
+		if len(memLimits) > 0 {
+			envs[MPSPinnedDeviceMemLimitEnv] = strings.Join(memLimits, ",")
+		}
+	case ext.GPUIsolationProviderCGPU:
+		memory, ok := getGPUResource(devices[0], ext.ResourceGPUMemory, ext.BatchGPUMemory)
+		if !ok {
+			return
+		}
+		memoryGiB := memory.Value() / (1024 * 1024 * 1024)
+		if memoryGiB < 1 {
+			memoryGiB = 1
+		}
+		envs[CGPUMemContainerEnv] = strconv.FormatInt(memoryGiB, 10)
+		if ratio, ok := devices[0].Resources[ext.ResourceGPUMemoryRatio]; ok && ratio.Value() > 0 {
+			envs[CGPUMemDevEnv] = strconv.FormatInt(memory.Value()*100/ratio.Value()/(1024*1024*1024), 10)
+		}
+	default:
+		logger.V(4).Infof("unknown gpu isolation provider %s of pod %s/%s", provider,
+			containerCtx.Request.PodMeta.Namespace, containerCtx.Request.PodMeta.Name)
+	}
+}
+
+// getGPUResource returns the allocated GPU resource, or the batch one if the GPU is allocated from the batch GPU.
+func getGPUResource(d *ext.DeviceAllocation, resourceName, batchResourceName corev1.ResourceName) (resource.Quantity, bool) {
+	if q, ok := d.Resources[resourceName]; ok {
+		return q, true
+	}
+	q, ok := d.Resources[batchResourceName]
+	return q, ok
+}
+
+// isSharedGPU checks if any allocated GPU is shared with other pods, i.e. less than the whole GPU is allocated.
+// The batch GPU is always shared since it is overcommitted on the GPUs of the LS pods.
+func isSharedGPU(devices []*ext.DeviceAllocation) bool {
+	for _, d := range devices {
+		if _, ok := d.Resources[ext.BatchGPUCore]; ok {
+			return true
+		}
+		for _, resourceName := range []corev1.ResourceName{ext.ResourceGPUCore, ext.ResourceGPUMemoryRatio} {
+			if q, ok := d.Resources[resourceName]; ok && q.Value() < 100 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetContainerGPUDevices denies the NVIDIA GPU devices except the allocated ones in the devices cgroup of the container,
+// so that the container can only access the allocated GPUs when the container runtime is not aware of the GPUs.
+// It is only supported on cgroups v1.
+func (p *gpuPlugin) SetContainerGPUDevices(proto protocol.HooksProtocol) error {
+	containerCtx := proto.(*protocol.ContainerContext)
+	if containerCtx == nil {
+		return fmt.Errorf("container protocol is nil for plugin gpu")
+	}
+	if p.executor == nil || containerCtx.Request.CgroupParent == "" {
+		return nil
+	}
+	alloc, err := p.getDeviceAllocations(&containerCtx.Request)
+	if err != nil {
+		return err
+	}
+	devices, ok := alloc[schedulingv1alpha1.GPU]
+	if !ok || len(devices) == 0 {
+		return nil
+	}
+	extension, err := ext.GetDeviceAllocationExtension(devices[0])
+	if err != nil {
+		return err
+	}
+	if extension != nil && extension.GPUVendor != "" && extension.GPUVendor != ext.GPUVendorNVIDIA {
+		return nil
+	}
+	if sysutil.GetCurrentCgroupVersion() != sysutil.CgroupVersionV1 {
+		logger.V(5).Infof("skip setting gpu devices cgroup for container %s/%s/%s since cgroups v1 is not used",
+			containerCtx.Request.PodMeta.Namespace, containerCtx.Request.PodMeta.Name, containerCtx.Request.ContainerMeta.Name)
+		return nil
+	}
+
+	// deny all the NVIDIA GPUs first, then allow the allocated ones and the control devices shared by the GPUs
+	if err := p.updateDevicesCgroup(containerCtx, sysutil.DevicesDenyName, fmt.Sprintf("c %d:* rwm", NvidiaGPUDeviceMajor)); err != nil {
+		return err
+	}
+	rules := make([]string, 0, len(devices)+2)
+	for _, d := range devices {
+		rules = append(rules, fmt.Sprintf("c %d:%d rwm", NvidiaGPUDeviceMajor, d.Minor))
+	}
+	rules = append(rules, fmt.Sprintf("c %d:%d rwm", NvidiaGPUDeviceMajor, NvidiaModesetDeviceMinor),
+		fmt.Sprintf("c %d:%d rwm", NvidiaGPUDeviceMajor, NvidiaCtlDeviceMinor))
+	for _, rule := range rules {
+		if err := p.updateDevicesCgroup(containerCtx, sysutil.DevicesAllowName, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gpuPlugin) updateDevicesCgroup(containerCtx *protocol.ContainerContext, file sysutil.ResourceType, rule string) error {
+	eventHelper := audit.V(3).Container(containerCtx.Request.ContainerMeta.ID).Reason("runtime-hooks").Message(
+		"set container gpu device %v %v", file, rule)
+	updater, err := resourceexecutor.NewCommonCgroupUpdater(file, containerCtx.Request.CgroupParent, rule, eventHelper)
+	if err != nil {
+		return err
+	}
+	if _, err = p.executor.Update(false, updater); err != nil {
+		return fmt.Errorf("failed to write %s %s for container %s/%s/%s, err: %w", file, rule,
+			containerCtx.Request.PodMeta.Namespace, containerCtx.Request.PodMeta.Name, containerCtx.Request.ContainerMeta.Name, err)
+	}
 	return nil
 }
@@ -17,12 +17,17 @@ limitations under the License.
 package gpu
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
 func Test_InjectContainerGPUEnv(t *testing.T) {
@@ -77,3 +82,169 @@ func Test_InjectContainerGPUEnv(t *testing.T) {
 		}
 	}
 }
+
+func Test_InjectContainerGPUEnv_AMD(t *testing.T) {
+	containerCtx := &protocol.ContainerContext{
+		Request: protocol.ContainerRequest{
+			PodAnnotations: map[string]string{
+				ext.AnnotationDeviceAllocated: "{\"gpu\": [{\"minor\": 0, \"extension\": {\"gpuVendor\": \"amd\"}},{\"minor\": 1, \"extension\": {\"gpuVendor\": \"amd\"}}]}",
+			},
+		},
+	}
+	plugin := gpuPlugin{}
+	assert.NoError(t, plugin.InjectContainerGPUEnv(containerCtx))
+	assert.Equal(t, map[string]string{AMDGpuAllocEnv: "0,1"}, containerCtx.Response.AddContainerEnvs)
+}
+
+func Test_InjectContainerGPUEnv_PodDeviceAllocation(t *testing.T) {
+	containerCtx := &protocol.ContainerContext{
+		Request: protocol.ContainerRequest{
+			PodMeta: protocol.PodMeta{Namespace: "default", Name: "test", UID: "uid-1"},
+			PodAnnotations: map[string]string{
+				ext.AnnotationDeviceAllocationRef: "test",
+			},
+		},
+	}
+	plugin := gpuPlugin{
+		podDeviceAllocationGetter: func(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error) {
+			return &schedulingv1alpha1.PodDeviceAllocation{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+				Spec: schedulingv1alpha1.PodDeviceAllocationSpec{
+					PodUID: "uid-1",
+					Devices: []schedulingv1alpha1.PodDeviceAllocationItem{
+						{Type: schedulingv1alpha1.GPU, Minor: 2},
+						{Type: schedulingv1alpha1.GPU, Minor: 3},
+					},
+				},
+			}, nil
+		},
+	}
+	assert.NoError(t, plugin.InjectContainerGPUEnv(containerCtx))
+	assert.Equal(t, map[string]string{GpuAllocEnv: "2,3"}, containerCtx.Response.AddContainerEnvs)
+}
+
+func Test_InjectContainerGPUEnv_Isolation(t *testing.T) {
+	sharedGPUs := `{"gpu": [{"minor": 0, "resources": {"koordinator.sh/gpu-core": "50", "koordinator.sh/gpu-memory": "8Gi", "koordinator.sh/gpu-memory-ratio": "50"}}]}`
+	tests := []struct {
+		name         string
+		podLabels    map[string]string
+		allocated    string
+		expectedEnvs map[string]string
+	}{
+		{
+			name:      "shared gpu without isolation provider",
+			allocated: sharedGPUs,
+			expectedEnvs: map[string]string{
+				GpuAllocEnv: "0",
+			},
+		},
+		{
+			name:      "shared gpu isolated by MPS",
+			podLabels: map[string]string{ext.LabelGPUIsolationProvider: string(ext.GPUIsolationProviderMPS)},
+			allocated: sharedGPUs,
+			expectedEnvs: map[string]string{
+				GpuAllocEnv:                  "0",
+				MPSActiveThreadPercentageEnv: "50",
+				MPSPinnedDeviceMemLimitEnv:   "0=8192M",
+			},
+		},
+		{
+			name:      "shared gpu isolated by cGPU",
+			podLabels: map[string]string{ext.LabelGPUIsolationProvider: string(ext.GPUIsolationProviderCGPU)},
+			allocated: sharedGPUs,
+			expectedEnvs: map[string]string{
+				GpuAllocEnv:         "0",
+				CGPUMemContainerEnv: "8",
+				CGPUMemDevEnv:       "16",
+			},
+		},
+		{
+			name:      "batch gpu isolated by MPS",
+			podLabels: map[string]string{ext.LabelGPUIsolationProvider: string(ext.GPUIsolationProviderMPS)},
+			allocated: `{"gpu": [{"minor": 0, "resources": {"koordinator.sh/batch-gpu-core": "100", "koordinator.sh/batch-gpu-memory": "4Gi"}}]}`,
+			expectedEnvs: map[string]string{
+				GpuAllocEnv:                  "0",
+				MPSActiveThreadPercentageEnv: "100",
+				MPSPinnedDeviceMemLimitEnv:   "0=4096M",
+			},
+		},
+		{
+			name:      "whole gpus are not isolated",
+			podLabels: map[string]string{ext.LabelGPUIsolationProvider: string(ext.GPUIsolationProviderMPS)},
+			allocated: `{"gpu": [{"minor": 0, "resources": {"koordinator.sh/gpu-core": "100", "koordinator.sh/gpu-memory": "16Gi", "koordinator.sh/gpu-memory-ratio": "100"}},{"minor": 1, "resources": {"koordinator.sh/gpu-core": "100", "koordinator.sh/gpu-memory": "16Gi", "koordinator.sh/gpu-memory-ratio": "100"}}]}`,
+			expectedEnvs: map[string]string{
+				GpuAllocEnv: "0,1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerCtx := &protocol.ContainerContext{
+				Request: protocol.ContainerRequest{
+					PodLabels: tt.podLabels,
+					PodAnnotations: map[string]string{
+						ext.AnnotationDeviceAllocated: tt.allocated,
+					},
+				},
+			}
+			plugin := gpuPlugin{}
+			assert.NoError(t, plugin.InjectContainerGPUEnv(containerCtx))
+			assert.Equal(t, tt.expectedEnvs, containerCtx.Response.AddContainerEnvs)
+		})
+	}
+}
+
+// testingRecordingExecutor records the cgroup files and values updated by the executor in order.
+type testingRecordingExecutor struct {
+	resourceexecutor.ResourceUpdateExecutor
+	updates []string
+}
+
+func (e *testingRecordingExecutor) Update(cacheable bool, updater resourceexecutor.ResourceUpdater) (bool, error) {
+	e.updates = append(e.updates, filepath.Base(updater.Path())+" "+updater.Value())
+	return e.ResourceUpdateExecutor.Update(cacheable, updater)
+}
+
+func Test_SetContainerGPUDevices(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	helper.SetCgroupsV2(false)
+
+	containerParentDir := "kubepods.slice/kubepods-podxxx.slice/cri-containerd-123abc.scope"
+	helper.WriteCgroupFileContents(containerParentDir, system.DevicesAllow, "")
+	helper.WriteCgroupFileContents(containerParentDir, system.DevicesDeny, "")
+
+	executor := &testingRecordingExecutor{ResourceUpdateExecutor: resourceexecutor.NewResourceUpdateExecutor()}
+	plugin := gpuPlugin{executor: executor}
+	containerCtx := &protocol.ContainerContext{
+		Request: protocol.ContainerRequest{
+			CgroupParent: containerParentDir,
+			PodAnnotations: map[string]string{
+				ext.AnnotationDeviceAllocated: "{\"gpu\": [{\"minor\": 1}, {\"minor\": 3}]}",
+			},
+		},
+	}
+	assert.NoError(t, plugin.SetContainerGPUDevices(containerCtx))
+	// all the GPUs are denied before the allocated ones and the control devices are allowed
+	assert.Equal(t, []string{
+		"devices.deny c 195:* rwm",
+		"devices.allow c 195:1 rwm",
+		"devices.allow c 195:3 rwm",
+		"devices.allow c 195:254 rwm",
+		"devices.allow c 195:255 rwm",
+	}, executor.updates)
+	assert.Equal(t, "c 195:* rwm", helper.ReadCgroupFileContents(containerParentDir, system.DevicesDeny))
+	assert.Equal(t, "c 195:255 rwm", helper.ReadCgroupFileContents(containerParentDir, system.DevicesAllow))
+
+	executor.updates = nil
+	amdContainerCtx := &protocol.ContainerContext{
+		Request: protocol.ContainerRequest{
+			CgroupParent: containerParentDir,
+			PodAnnotations: map[string]string{
+				ext.AnnotationDeviceAllocated: "{\"gpu\": [{\"minor\": 0, \"extension\": {\"gpuVendor\": \"amd\"}}]}",
+			},
+		},
+	}
+	assert.NoError(t, plugin.SetContainerGPUDevices(amdContainerCtx))
+	assert.Empty(t, executor.updates)
+}
@@ -21,7 +21,6 @@ import (
 	"sync"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
@@ -30,10 +29,13 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 const (
 	name        = "GroupIdentity"
 	description = "set bvt value by priority and qos class"
@@ -49,7 +51,7 @@ type bvtPlugin struct {
 }
 
 func (b *bvtPlugin) Register(op hooks.Options) {
-	klog.V(5).Infof("register hook %v", name)
+	logger.V(5).Infof("register hook %v", name)
 	hooks.Register(rmconfig.PreRunPodSandbox, name, description, b.SetPodBvtValue)
 	rule.Register(name, description,
 		rule.WithParseFunc(statesinformer.RegisterTypeNodeSLOSpec, b.parseRule),
@@ -71,7 +73,7 @@ func (b *bvtPlugin) SystemSupported() bool {
 		}
 		bvtConfigPath := sysutil.GetProcSysFilePath(sysutil.KernelSchedGroupIdentityEnable)
 		b.sysSupported = pointer.BoolPtr(isBVTSupported || sysutil.FileExists(bvtConfigPath))
-		klog.Infof("update system supported info to %v for plugin %v, supported msg %s",
+		logger.Infof("update system supported info to %v for plugin %v, supported msg %s",
 			*b.sysSupported, name, msg)
 	}
 	return *b.sysSupported
@@ -102,7 +104,7 @@ func (b *bvtPlugin) initialize() (bool, error) {
 
 	// if cpu qos is enabled/disabled in rule, check if we need to change the sysctl config for bvt (group identity)
 	if b.kernelEnabled != nil && *b.kernelEnabled == enable {
-		klog.V(6).Infof("skip initialize bvt to %v, hook plugin rule not change", enable)
+		logger.V(6).Infof("skip initialize bvt to %v, hook plugin rule not change", enable)
 		return enable, nil
 	}
 
@@ -112,7 +114,7 @@ func (b *bvtPlugin) initialize() (bool, error) {
 		return false, fmt.Errorf("cannot enable kernel sysctl for bvt, err: %v", err)
 	}
 	b.kernelEnabled = pointer.BoolPtr(enable)
-	klog.V(4).Infof("hook plugin %s is successfully initialized to %v", name, enable)
+	logger.V(4).Infof("hook plugin %s is successfully initialized to %v", name, enable)
 	return enable, nil
 }
 
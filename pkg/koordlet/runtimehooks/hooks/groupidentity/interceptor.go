@@ -17,7 +17,6 @@ limitations under the License.
 package groupidentity
 
 import (
-	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
 	ext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -27,22 +26,22 @@ import (
 
 func (b *bvtPlugin) SetPodBvtValue(p protocol.HooksProtocol) error {
 	if !b.SystemSupported() {
-		klog.V(5).Infof("plugin %s is not supported by system", name)
+		logger.V(5).Infof("plugin %s is not supported by system", name)
 		return nil
 	}
 	r := b.getRule()
 	if r == nil {
-		klog.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		logger.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
 		return nil
 	}
 	enable, err := b.initialize()
 	if err != nil {
-		klog.V(4).Infof("failed to initialize plugin %s, err: %s", name, err)
+		logger.V(4).Infof("failed to initialize plugin %s, err: %s", name, err)
 		return nil
 	}
 	// skip if the feature is disabled by the kernel config
 	if !enable && b.hasKernelEnable() {
-		klog.V(5).Infof("skip for pod since hook plugin %s has been disabled by kernel config", name)
+		logger.V(5).Infof("skip for pod since hook plugin %s has been disabled by kernel config", name)
 		return nil
 	}
 	podCtx := p.(*protocol.PodContext)
@@ -56,22 +55,22 @@ func (b *bvtPlugin) SetPodBvtValue(p protocol.HooksProtocol) error {
 
 func (b *bvtPlugin) SetKubeQOSBvtValue(p protocol.HooksProtocol) error {
 	if !b.SystemSupported() {
-		klog.V(5).Infof("plugin %s is not supported by system", name)
+		logger.V(5).Infof("plugin %s is not supported by system", name)
 		return nil
 	}
 	r := b.getRule()
 	if r == nil {
-		klog.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		logger.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
 		return nil
 	}
 	enable, err := b.initialize()
 	if err != nil {
-		klog.V(4).Infof("failed to initialize plugin %s, err: %s", name, err)
+		logger.V(4).Infof("failed to initialize plugin %s, err: %s", name, err)
 		return nil
 	}
 	// skip if the feature is disabled by the kernel config
 	if !enable && b.hasKernelEnable() {
-		klog.V(5).Infof("skip for qos since hook plugin %s has been disabled by kernel config", name)
+		logger.V(5).Infof("skip for qos since hook plugin %s has been disabled by kernel config", name)
 		return nil
 	}
 	kubeQOSCtx := p.(*protocol.KubeQOSContext)
@@ -21,7 +21,6 @@ import (
 	"strconv"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/klog/v2"
 
 	ext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
@@ -115,18 +114,18 @@ func (b *bvtPlugin) parseRule(mergedNodeSLOIf interface{}) (bool, error) {
 	}
 
 	updated := b.updateRule(newRule)
-	klog.Infof("runtime hook plugin %s update rule %v, new rule %v", name, updated, newRule)
+	logger.Infof("runtime hook plugin %s update rule %v, new rule %v", name, updated, newRule)
 	return updated, nil
 }
 
 func (b *bvtPlugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 	if !b.SystemSupported() {
-		klog.V(5).Infof("plugin %s is not supported by system", name)
+		logger.V(5).Infof("plugin %s is not supported by system", name)
 		return nil
 	}
 	r := b.getRule()
 	if r == nil {
-		klog.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		logger.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
 		return nil
 	}
 	for _, kubeQOS := range []corev1.PodQOSClass{
@@ -136,10 +135,10 @@ func (b *bvtPlugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 		e := audit.V(3).Group(string(kubeQOS)).Reason(name).Message("set bvt to %v", bvtValue)
 		bvtUpdater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(sysutil.CPUBVTWarpNsName, kubeQOSCgroupPath, strconv.FormatInt(bvtValue, 10), e)
 		if err != nil {
-			klog.Infof("bvtupdater create failed, dir %v, error %v", kubeQOSCgroupPath, err)
+			logger.Infof("bvtupdater create failed, dir %v, error %v", kubeQOSCgroupPath, err)
 		}
 		if _, err := b.executor.Update(true, bvtUpdater); err != nil {
-			klog.Infof("update kube qos %v cpu bvt failed, dir %v, error %v", kubeQOS, kubeQOSCgroupPath, err)
+			logger.Infof("update kube qos %v cpu bvt failed, dir %v, error %v", kubeQOS, kubeQOSCgroupPath, err)
 		}
 	}
 	for _, podMeta := range pods {
@@ -150,10 +149,10 @@ func (b *bvtPlugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
 		e := audit.V(3).Pod(podMeta.Pod.Namespace, podMeta.Pod.Name).Reason(name).Message("set bvt to %v", podBvt)
 		bvtUpdater, err := resourceexecutor.DefaultCgroupUpdaterFactory.New(sysutil.CPUBVTWarpNsName, podCgroupPath, strconv.FormatInt(podBvt, 10), e)
 		if err != nil {
-			klog.Infof("bvtupdater create failed, dir %v, error %v", podCgroupPath, err)
+			logger.Infof("bvtupdater create failed, dir %v, error %v", podCgroupPath, err)
 		}
 		if _, err := b.executor.Update(true, bvtUpdater); err != nil {
-			klog.Infof("update pod %s cpu bvt failed, dir %v, error %v",
+			logger.Infof("update pod %s cpu bvt failed, dir %v, error %v",
 				util.GetPodKey(podMeta.Pod), podCgroupPath, err)
 		}
 	}
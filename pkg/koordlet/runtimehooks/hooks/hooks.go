@@ -19,13 +19,15 @@ package hooks
 import (
 	"fmt"
 
-	"k8s.io/klog/v2"
-
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	rmconfig "github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 type Hook struct {
 	name        string
 	stage       rmconfig.RuntimeHookType
@@ -34,7 +36,8 @@ type Hook struct {
 }
 
 type Options struct {
-	Executor resourceexecutor.ResourceUpdateExecutor
+	Executor       resourceexecutor.ResourceUpdateExecutor
+	StatesInformer statesinformer.StatesInformer
 }
 
 type HookFn func(protocol.HooksProtocol) error
@@ -44,10 +47,10 @@ var globalStageHooks map[rmconfig.RuntimeHookType][]*Hook
 func Register(stage rmconfig.RuntimeHookType, name, description string, hookFn HookFn) *Hook {
 	h, err := generateNewHook(stage, name)
 	if err != nil {
-		klog.Fatalf("hook %s register failed, reason: %v", name, err)
+		logger.Fatalf("hook %s register failed, reason: %v", name, err)
 		return h
 	}
-	klog.V(1).Infof("hook %s is registered", name)
+	logger.V(1).Infof("hook %s is registered", name)
 	h.description = description
 	h.fn = hookFn
 	return h
@@ -79,11 +82,11 @@ func getHooksByStage(stage rmconfig.RuntimeHookType) []*Hook {
 
 func RunHooks(failPolicy rmconfig.FailurePolicyType, stage rmconfig.RuntimeHookType, protocol protocol.HooksProtocol) error {
 	hooks := getHooksByStage(stage)
-	klog.V(5).Infof("start run %v hooks at %s", len(hooks), stage)
+	logger.V(5).Infof("start run %v hooks at %s", len(hooks), stage)
 	for _, hook := range hooks {
-		klog.V(5).Infof("call hook %v", hook.name)
+		logger.V(5).Infof("call hook %v", hook.name)
 		if err := hook.fn(protocol); err != nil {
-			klog.Errorf("failed to run hook %s in stage %s, reason: %v", hook.name, stage, err)
+			logger.Errorf("failed to run hook %s in stage %s, reason: %v", hook.name, stage, err)
 			if failPolicy == rmconfig.PolicyFail {
 				return err
 			}
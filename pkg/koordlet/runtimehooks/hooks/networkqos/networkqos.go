@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkqos
+
+import (
+	"sync"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
+const (
+	name        = "NetworkQOS"
+	description = "mark dscp of pod egress traffic by qos class"
+
+	podsRuleName        = name + "Pods"
+	podsRuleDescription = "refresh the pod addresses marked with dscp"
+)
+
+// networkQOSPlugin marks the DSCP of the egress packets of pods with nftables, so that the switches can prioritize
+// the traffic of LS pods over BE pods. The packets are matched by the pod addresses rather than the cgroups, since
+// the traffic of the pods in their own network namespaces is forwarded by the host.
+type networkQOSPlugin struct {
+	rule         *networkQOSRule
+	ruleRWMutex  sync.RWMutex
+	sysSupported *bool
+
+	rulesetMutex   sync.Mutex
+	appliedRuleset *string // the last ruleset applied successfully
+}
+
+func (n *networkQOSPlugin) Register(op hooks.Options) {
+	logger.V(5).Infof("register hook %v", name)
+	rule.Register(name, description,
+		rule.WithParseFunc(statesinformer.RegisterTypeNodeSLOSpec, n.parseRule),
+		rule.WithUpdateCallback(n.ruleUpdateCb),
+		rule.WithSystemSupported(n.SystemSupported))
+	// pod addresses are allocated after the sandbox is created, so the ruleset is refreshed on pod updates instead
+	// of the runtime hook stages
+	rule.Register(podsRuleName, podsRuleDescription,
+		rule.WithParseFunc(statesinformer.RegisterTypeAllPods, n.parsePods),
+		rule.WithUpdateCallback(n.ruleUpdateCb),
+		rule.WithSystemSupported(n.SystemSupported))
+}
+
+func (n *networkQOSPlugin) SystemSupported() bool {
+	if n.sysSupported == nil {
+		_, _, err := sysutil.ExecCmdOnHost([]string{nftCommand, "--version"})
+		n.sysSupported = pointer.BoolPtr(err == nil)
+		logger.Infof("update system supported info to %v for plugin %v, err: %v", *n.sysSupported, name, err)
+	}
+	return *n.sysSupported
+}
+
+// applyRuleset replaces the nftables table of the plugin with the ruleset atomically.
+// It skips if the ruleset is not changed since the last successful apply.
+func (n *networkQOSPlugin) applyRuleset(ruleset string) error {
+	n.rulesetMutex.Lock()
+	defer n.rulesetMutex.Unlock()
+	if n.appliedRuleset != nil && *n.appliedRuleset == ruleset {
+		logger.V(6).Infof("skip applying nftables ruleset for plugin %s, ruleset not change", name)
+		return nil
+	}
+	if _, _, err := sysutil.ExecCmdOnHost([]string{nftCommand, ruleset}); err != nil {
+		return err
+	}
+	n.appliedRuleset = pointer.String(ruleset)
+	_ = audit.V(3).Node().Reason(name).Message("apply nftables ruleset %s", ruleset).Do()
+	logger.V(4).Infof("hook plugin %s applied nftables ruleset %s", name, ruleset)
+	return nil
+}
+
+var singleton *networkQOSPlugin
+
+func Object() *networkQOSPlugin {
+	if singleton == nil {
+		singleton = &networkQOSPlugin{rule: &networkQOSRule{}}
+	}
+	return singleton
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkqos
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	ext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	nftCommand = "nft"
+	nftTable   = "inet koordinator_netqos"
+	nftChain   = "postrouting"
+	// nftChainPriority is the priority of mangle, which marks the packets before the other postrouting rules
+	nftChainPriority = -150
+)
+
+var markedQOSClasses = []ext.QoSClass{ext.QoSLSR, ext.QoSLS, ext.QoSBE}
+
+type ipFamily struct {
+	name     string // the name used by the nftables set
+	addrType string
+	protocol string
+}
+
+var (
+	ipv4Family = ipFamily{name: "ipv4", addrType: "ipv4_addr", protocol: "ip"}
+	ipv6Family = ipFamily{name: "ipv6", addrType: "ipv6_addr", protocol: "ip6"}
+)
+
+// generateRuleset generates the nftables commands which recreate the table of the plugin in one transaction. For each
+// enabled qos class, the addresses of the pods are kept in a set and the egress packets from the set are marked with
+// the dscp of the class, e.g.
+//
+//	add set inet koordinator_netqos ls_ipv4 { type ipv4_addr; }
+//	add element inet koordinator_netqos ls_ipv4 { 10.0.0.2, 10.0.0.3 }
+//	add rule inet koordinator_netqos postrouting ip saddr @ls_ipv4 ip dscp set 34
+//
+// If the rule is disabled, the table is just deleted to clean up the marking.
+func generateRuleset(r *networkQOSRule, pods []*statesinformer.PodMeta) string {
+	// adding the table before deleting makes the deletion succeed whether the table exists or not
+	cmds := []string{
+		fmt.Sprintf("add table %s", nftTable),
+		fmt.Sprintf("delete table %s", nftTable),
+	}
+	if !r.getEnable() {
+		return strings.Join(cmds, "; ")
+	}
+	cmds = append(cmds,
+		fmt.Sprintf("add table %s", nftTable),
+		fmt.Sprintf("add chain %s %s { type filter hook postrouting priority %d; }", nftTable, nftChain, nftChainPriority))
+
+	addrs := getPodAddresses(pods)
+	for _, qos := range markedQOSClasses {
+		dscp, exist := r.getPodDSCP(qos)
+		if !exist {
+			continue
+		}
+		for _, family := range []ipFamily{ipv4Family, ipv6Family} {
+			setName := fmt.Sprintf("%s_%s", strings.ToLower(string(qos)), family.name)
+			cmds = append(cmds, fmt.Sprintf("add set %s %s { type %s; }", nftTable, setName, family.addrType))
+			if setAddrs := addrs[qos][family]; len(setAddrs) > 0 {
+				cmds = append(cmds, fmt.Sprintf("add element %s %s { %s }", nftTable, setName, strings.Join(setAddrs, ", ")))
+			}
+			cmds = append(cmds, fmt.Sprintf("add rule %s %s %s saddr @%s %s dscp set %d",
+				nftTable, nftChain, family.protocol, setName, family.protocol, dscp))
+		}
+	}
+	return strings.Join(cmds, "; ")
+}
+
+// getPodAddresses returns the sorted addresses of the running pods by the network qos class and the ip family.
+// The pods in the host network are skipped since they share the addresses of the node.
+func getPodAddresses(pods []*statesinformer.PodMeta) map[ext.QoSClass]map[ipFamily][]string {
+	addrs := map[ext.QoSClass]map[ipFamily][]string{}
+	for _, podMeta := range pods {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		pod := podMeta.Pod
+		if pod.Spec.HostNetwork || util.IsPodTerminated(pod) {
+			continue
+		}
+		qos := getPodNetworkQOSClass(pod)
+		if qos == ext.QoSNone {
+			continue
+		}
+		podIPs := sets.NewString(pod.Status.PodIP)
+		for _, podIP := range pod.Status.PodIPs {
+			podIPs.Insert(podIP.IP)
+		}
+		for _, podIP := range podIPs.UnsortedList() {
+			ip := net.ParseIP(podIP)
+			if ip == nil {
+				continue
+			}
+			family := ipv6Family
+			if ip.To4() != nil {
+				family = ipv4Family
+			}
+			if addrs[qos] == nil {
+				addrs[qos] = map[ipFamily][]string{}
+			}
+			addrs[qos][family] = append(addrs[qos][family], ip.String())
+		}
+	}
+	for _, qosAddrs := range addrs {
+		for _, familyAddrs := range qosAddrs {
+			sort.Strings(familyAddrs)
+		}
+	}
+	return addrs
+}
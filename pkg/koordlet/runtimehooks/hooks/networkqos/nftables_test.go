@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkqos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+)
+
+func Test_generateRuleset(t *testing.T) {
+	newPodMeta := func(name string, qos ext.QoSClass, hostNetwork bool, podIPs ...string) *statesinformer.PodMeta {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{ext.LabelPodQoS: string(qos)},
+			},
+			Spec: corev1.PodSpec{
+				HostNetwork: hostNetwork,
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+			},
+		}
+		if len(podIPs) > 0 {
+			pod.Status.PodIP = podIPs[0]
+		}
+		for _, podIP := range podIPs {
+			pod.Status.PodIPs = append(pod.Status.PodIPs, corev1.PodIP{IP: podIP})
+		}
+		return &statesinformer.PodMeta{Pod: pod}
+	}
+	pods := []*statesinformer.PodMeta{
+		newPodMeta("ls-1", ext.QoSLS, false, "10.0.0.3"),
+		newPodMeta("ls-2", ext.QoSLS, false, "10.0.0.2", "fd00::2"),
+		newPodMeta("be-1", ext.QoSBE, false, "10.0.0.4"),
+		newPodMeta("be-host", ext.QoSBE, true, "192.168.0.1"),
+		newPodMeta("lsr-pending", ext.QoSLSR, false),
+	}
+	tests := []struct {
+		name string
+		rule *networkQOSRule
+		want string
+	}{
+		{
+			name: "delete the table if disabled",
+			rule: &networkQOSRule{},
+			want: "add table inet koordinator_netqos; delete table inet koordinator_netqos",
+		},
+		{
+			name: "mark the enabled classes",
+			rule: &networkQOSRule{
+				enable: true,
+				dscp: map[ext.QoSClass]int64{
+					ext.QoSLSR: 46,
+					ext.QoSLS:  34,
+				},
+			},
+			want: "add table inet koordinator_netqos; delete table inet koordinator_netqos; " +
+				"add table inet koordinator_netqos; " +
+				"add chain inet koordinator_netqos postrouting { type filter hook postrouting priority -150; }; " +
+				"add set inet koordinator_netqos lsr_ipv4 { type ipv4_addr; }; " +
+				"add rule inet koordinator_netqos postrouting ip saddr @lsr_ipv4 ip dscp set 46; " +
+				"add set inet koordinator_netqos lsr_ipv6 { type ipv6_addr; }; " +
+				"add rule inet koordinator_netqos postrouting ip6 saddr @lsr_ipv6 ip6 dscp set 46; " +
+				"add set inet koordinator_netqos ls_ipv4 { type ipv4_addr; }; " +
+				"add element inet koordinator_netqos ls_ipv4 { 10.0.0.2, 10.0.0.3 }; " +
+				"add rule inet koordinator_netqos postrouting ip saddr @ls_ipv4 ip dscp set 34; " +
+				"add set inet koordinator_netqos ls_ipv6 { type ipv6_addr; }; " +
+				"add element inet koordinator_netqos ls_ipv6 { fd00::2 }; " +
+				"add rule inet koordinator_netqos postrouting ip6 saddr @ls_ipv6 ip6 dscp set 34",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, generateRuleset(tt.rule, pods))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkqos
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+
+	ext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+type networkQOSRule struct {
+	enable bool
+	// dscp is the dscp value of each qos class whose network qos is enabled
+	dscp map[ext.QoSClass]int64
+}
+
+func (r *networkQOSRule) getEnable() bool {
+	if r == nil {
+		return false
+	}
+	return r.enable
+}
+
+func (r *networkQOSRule) getPodDSCP(podQOS ext.QoSClass) (int64, bool) {
+	val, exist := r.dscp[podQOS]
+	return val, exist
+}
+
+func (n *networkQOSPlugin) parseRule(mergedNodeSLOIf interface{}) (bool, error) {
+	mergedNodeSLO := mergedNodeSLOIf.(*slov1alpha1.NodeSLOSpec)
+
+	newRule := &networkQOSRule{
+		dscp: map[ext.QoSClass]int64{},
+	}
+	if mergedNodeSLO.ResourceQOSStrategy != nil {
+		for qos, resourceQOS := range map[ext.QoSClass]*slov1alpha1.ResourceQOS{
+			ext.QoSLSR: mergedNodeSLO.ResourceQOSStrategy.LSRClass,
+			ext.QoSLS:  mergedNodeSLO.ResourceQOSStrategy.LSClass,
+			ext.QoSBE:  mergedNodeSLO.ResourceQOSStrategy.BEClass,
+		} {
+			if resourceQOS == nil || resourceQOS.NetworkQOS == nil || resourceQOS.NetworkQOS.Enable == nil ||
+				!*resourceQOS.NetworkQOS.Enable || resourceQOS.NetworkQOS.DSCP == nil {
+				continue
+			}
+			newRule.dscp[qos] = *resourceQOS.NetworkQOS.DSCP
+		}
+	}
+	newRule.enable = len(newRule.dscp) > 0
+
+	updated := n.updateRule(newRule)
+	logger.Infof("runtime hook plugin %s update rule %v, new rule %v", name, updated, newRule)
+	return updated, nil
+}
+
+// parsePods always returns true since the addresses of the pods may change on any pod update, while the ruleset is
+// applied only if changed.
+func (n *networkQOSPlugin) parsePods(interface{}) (bool, error) {
+	return true, nil
+}
+
+func (n *networkQOSPlugin) ruleUpdateCb(pods []*statesinformer.PodMeta) error {
+	if !n.SystemSupported() {
+		logger.V(5).Infof("plugin %s is not supported by system", name)
+		return nil
+	}
+	r := n.getRule()
+	if r == nil {
+		logger.V(5).Infof("hook plugin rule is nil, nothing to do for plugin %v", name)
+		return nil
+	}
+	// the table is still generated when disabled, so that the marking applied before is cleaned up
+	ruleset := generateRuleset(r, pods)
+	if err := n.applyRuleset(ruleset); err != nil {
+		logger.Warningf("failed to apply nftables ruleset for plugin %s, err: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// getPodNetworkQOSClass returns the qos class whose network qos config is applied to the pod.
+// LSE pods share the config of LSR, and the pods without koordinator qos are treated as BE if they are BestEffort in
+// kubernetes qos, otherwise LS. The system pods are not marked.
+func getPodNetworkQOSClass(pod *corev1.Pod) ext.QoSClass {
+	switch podQOS := ext.GetPodQoSClass(pod); podQOS {
+	case ext.QoSLSE, ext.QoSLSR:
+		return ext.QoSLSR
+	case ext.QoSLS, ext.QoSBE:
+		return podQOS
+	case ext.QoSNone:
+		if util.GetKubeQosClass(pod) == corev1.PodQOSBestEffort {
+			return ext.QoSBE
+		}
+		return ext.QoSLS
+	default:
+		return ext.QoSNone
+	}
+}
+
+func (n *networkQOSPlugin) getRule() *networkQOSRule {
+	n.ruleRWMutex.RLock()
+	defer n.ruleRWMutex.RUnlock()
+	if n.rule == nil {
+		return nil
+	}
+	rule := *n.rule
+	return &rule
+}
+
+func (n *networkQOSPlugin) updateRule(newRule *networkQOSRule) bool {
+	n.ruleRWMutex.Lock()
+	defer n.ruleRWMutex.Unlock()
+	if !reflect.DeepEqual(newRule, n.rule) {
+		n.rule = newRule
+		return true
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkqos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	ext "github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	sysutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func Test_networkQOSPlugin_parseRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          *networkQOSRule
+		mergedNodeSLO *slov1alpha1.NodeSLOSpec
+		want          bool
+		wantRule      *networkQOSRule
+	}{
+		{
+			name: "parse enabled classes",
+			rule: &networkQOSRule{},
+			mergedNodeSLO: &slov1alpha1.NodeSLOSpec{
+				ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+					LSRClass: &slov1alpha1.ResourceQOS{
+						NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+							Enable:     pointer.Bool(true),
+							NetworkQOS: slov1alpha1.NetworkQOS{DSCP: pointer.Int64(46)},
+						},
+					},
+					LSClass: &slov1alpha1.ResourceQOS{
+						NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+							Enable:     pointer.Bool(true),
+							NetworkQOS: slov1alpha1.NetworkQOS{DSCP: pointer.Int64(34)},
+						},
+					},
+					BEClass: &slov1alpha1.ResourceQOS{
+						NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+							Enable:     pointer.Bool(false),
+							NetworkQOS: slov1alpha1.NetworkQOS{DSCP: pointer.Int64(0)},
+						},
+					},
+				},
+			},
+			want: true,
+			wantRule: &networkQOSRule{
+				enable: true,
+				dscp: map[ext.QoSClass]int64{
+					ext.QoSLSR: 46,
+					ext.QoSLS:  34,
+				},
+			},
+		},
+		{
+			name: "parse all disabled",
+			rule: &networkQOSRule{},
+			mergedNodeSLO: &slov1alpha1.NodeSLOSpec{
+				ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+					BEClass: &slov1alpha1.ResourceQOS{
+						NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+							Enable:     pointer.Bool(false),
+							NetworkQOS: slov1alpha1.NetworkQOS{DSCP: pointer.Int64(8)},
+						},
+					},
+				},
+			},
+			want: true,
+			wantRule: &networkQOSRule{
+				enable: false,
+				dscp:   map[ext.QoSClass]int64{},
+			},
+		},
+		{
+			name: "rule not changed",
+			rule: &networkQOSRule{
+				enable: true,
+				dscp:   map[ext.QoSClass]int64{ext.QoSBE: 8},
+			},
+			mergedNodeSLO: &slov1alpha1.NodeSLOSpec{
+				ResourceQOSStrategy: &slov1alpha1.ResourceQOSStrategy{
+					BEClass: &slov1alpha1.ResourceQOS{
+						NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+							Enable:     pointer.Bool(true),
+							NetworkQOS: slov1alpha1.NetworkQOS{DSCP: pointer.Int64(8)},
+						},
+					},
+				},
+			},
+			want: false,
+			wantRule: &networkQOSRule{
+				enable: true,
+				dscp:   map[ext.QoSClass]int64{ext.QoSBE: 8},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &networkQOSPlugin{rule: tt.rule}
+			got, err := n.parseRule(tt.mergedNodeSLO)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantRule, n.getRule())
+		})
+	}
+}
+
+func Test_getPodNetworkQOSClass(t *testing.T) {
+	newPod := func(qos ext.QoSClass, requests corev1.ResourceList) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: requests}},
+				},
+			},
+		}
+		if qos != ext.QoSNone {
+			pod.Labels[ext.LabelPodQoS] = string(qos)
+		}
+		return pod
+	}
+	requests := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+	assert.Equal(t, ext.QoSLSR, getPodNetworkQOSClass(newPod(ext.QoSLSE, requests)))
+	assert.Equal(t, ext.QoSLSR, getPodNetworkQOSClass(newPod(ext.QoSLSR, requests)))
+	assert.Equal(t, ext.QoSLS, getPodNetworkQOSClass(newPod(ext.QoSLS, requests)))
+	assert.Equal(t, ext.QoSBE, getPodNetworkQOSClass(newPod(ext.QoSBE, nil)))
+	assert.Equal(t, ext.QoSLS, getPodNetworkQOSClass(newPod(ext.QoSNone, requests)))
+	assert.Equal(t, ext.QoSBE, getPodNetworkQOSClass(newPod(ext.QoSNone, nil)))
+	assert.Equal(t, ext.QoSNone, getPodNetworkQOSClass(newPod(ext.QoSSystem, requests)))
+}
+
+func Test_networkQOSPlugin_ruleUpdateCb(t *testing.T) {
+	var executed []string
+	oldExecCmdOnHost := sysutil.ExecCmdOnHost
+	sysutil.ExecCmdOnHost = func(cmds []string) ([]byte, int, error) {
+		executed = append(executed, cmds[len(cmds)-1])
+		return nil, 0, nil
+	}
+	defer func() {
+		sysutil.ExecCmdOnHost = oldExecCmdOnHost
+	}()
+
+	n := &networkQOSPlugin{
+		rule: &networkQOSRule{
+			enable: true,
+			dscp:   map[ext.QoSClass]int64{ext.QoSLS: 34},
+		},
+		sysSupported: pointer.Bool(true),
+	}
+	pods := []*statesinformer.PodMeta{
+		{
+			Pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test-ls-pod",
+					Labels: map[string]string{ext.LabelPodQoS: string(ext.QoSLS)},
+				},
+				Status: corev1.PodStatus{
+					Phase: corev1.PodRunning,
+					PodIP: "10.0.0.2",
+				},
+			},
+		},
+	}
+	assert.NoError(t, n.ruleUpdateCb(pods))
+	assert.Equal(t, []string{generateRuleset(n.getRule(), pods)}, executed)
+
+	// the unchanged ruleset is not applied again
+	assert.NoError(t, n.ruleUpdateCb(pods))
+	assert.Equal(t, 1, len(executed))
+
+	// the marking is cleaned up when disabled
+	n.updateRule(&networkQOSRule{dscp: map[ext.QoSClass]int64{}})
+	assert.NoError(t, n.ruleUpdateCb(pods))
+	assert.Equal(t, 2, len(executed))
+	assert.Equal(t, "add table inet koordinator_netqos; delete table inet koordinator_netqos", executed[1])
+}
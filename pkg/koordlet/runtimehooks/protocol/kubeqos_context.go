@@ -18,7 +18,6 @@ package protocol
 
 import (
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/audit"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
@@ -66,10 +65,10 @@ func (p *KubeQOSContext) injectForExt() {
 		eventHelper := audit.V(3).Group(string(p.Request.KubeQOSClass)).Reason("runtime-hooks").Message(
 			"set kubeqos bvt to %v", *p.Response.Resources.CPUBvt)
 		if err := injectCPUBvt(p.Request.CgroupParent, *p.Response.Resources.CPUBvt, eventHelper, p.executor); err != nil {
-			klog.Infof("set kubeqos %v bvt %v on cgroup parent %v failed, error %v", p.Request.KubeQOSClass,
+			logger.Infof("set kubeqos %v bvt %v on cgroup parent %v failed, error %v", p.Request.KubeQOSClass,
 				*p.Response.Resources.CPUBvt, p.Request.CgroupParent, err)
 		} else {
-			klog.V(5).Infof("set kubeqos %v bvt %v on cgroup parent %v", p.Request.KubeQOSClass,
+			logger.V(5).Infof("set kubeqos %v bvt %v on cgroup parent %v", p.Request.KubeQOSClass,
 				*p.Response.Resources.CPUBvt, p.Request.CgroupParent)
 		}
 	}
@@ -18,7 +18,6 @@ package protocol
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	runtimeapi "github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
@@ -63,7 +62,7 @@ func (p *PodRequest) FromProxy(req *runtimeapi.PodSandboxHookRequest) {
 	// retrieve ExtendedResources from pod annotations
 	spec, err := apiext.GetExtendedResourceSpec(req.GetAnnotations())
 	if err != nil {
-		klog.V(4).Infof("failed to get ExtendedResourceSpec from proxy via annotation, pod %s/%s, err: %s",
+		logger.V(4).Infof("failed to get ExtendedResourceSpec from proxy via annotation, pod %s/%s, err: %s",
 			p.PodMeta.Namespace, p.PodMeta.Name, err)
 	}
 	if spec != nil && spec.Containers != nil {
@@ -79,7 +78,7 @@ func (p *PodRequest) FromReconciler(podMeta *statesinformer.PodMeta) {
 	// retrieve ExtendedResources from pod spec and pod annotations (prefer pod spec)
 	specFromAnnotations, err := apiext.GetExtendedResourceSpec(podMeta.Pod.Annotations)
 	if err != nil {
-		klog.V(4).Infof("failed to get ExtendedResourceSpec from reconciler via annotation, pod %s/%s, err: %s",
+		logger.V(4).Infof("failed to get ExtendedResourceSpec from reconciler via annotation, pod %s/%s, err: %s",
 			p.PodMeta.Namespace, p.PodMeta.Name, err)
 	}
 	specFromPod := util.GetPodExtendedResources(podMeta.Pod)
@@ -152,10 +151,10 @@ func (p *PodContext) injectForExt() {
 		eventHelper := audit.V(3).Pod(p.Request.PodMeta.Namespace, p.Request.PodMeta.Name).Reason("runtime-hooks").Message(
 			"set pod bvt to %v", *p.Response.Resources.CPUBvt)
 		if err := injectCPUBvt(p.Request.CgroupParent, *p.Response.Resources.CPUBvt, eventHelper, p.executor); err != nil {
-			klog.Infof("set pod %v/%v bvt %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
+			logger.Infof("set pod %v/%v bvt %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
 				p.Request.PodMeta.Name, *p.Response.Resources.CPUBvt, p.Request.CgroupParent, err)
 		} else {
-			klog.V(5).Infof("set pod %v/%v bvt %v on cgroup parent %v", p.Request.PodMeta.Namespace,
+			logger.V(5).Infof("set pod %v/%v bvt %v on cgroup parent %v", p.Request.PodMeta.Namespace,
 				p.Request.PodMeta.Name, *p.Response.Resources.CPUBvt, p.Request.CgroupParent)
 		}
 	}
@@ -165,10 +164,10 @@ func (p *PodContext) injectForExt() {
 		eventHelper := audit.V(3).Pod(p.Request.PodMeta.Namespace, p.Request.PodMeta.Name).Reason("runtime-hooks").Message(
 			"set pod cpu shares to %v", *p.Response.Resources.CPUShares)
 		if err := injectCPUShares(p.Request.CgroupParent, *p.Response.Resources.CPUShares, eventHelper, p.executor); err != nil {
-			klog.Infof("set pod %v/%v cpu shares %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
+			logger.Infof("set pod %v/%v cpu shares %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
 				p.Request.PodMeta.Name, *p.Response.Resources.CPUShares, p.Request.CgroupParent, err)
 		} else {
-			klog.V(5).Infof("set pod %v/%v cpu shares %v on cgroup parent %v",
+			logger.V(5).Infof("set pod %v/%v cpu shares %v on cgroup parent %v",
 				p.Request.PodMeta.Namespace, p.Request.PodMeta.Name, *p.Response.Resources.CPUShares, p.Request.CgroupParent)
 		}
 	}
@@ -176,10 +175,10 @@ func (p *PodContext) injectForExt() {
 		eventHelper := audit.V(3).Pod(p.Request.PodMeta.Namespace, p.Request.PodMeta.Name).Reason("runtime-hooks").Message(
 			"set pod cfs quota to %v", *p.Response.Resources.CFSQuota)
 		if err := injectCPUQuota(p.Request.CgroupParent, *p.Response.Resources.CFSQuota, eventHelper, p.executor); err != nil {
-			klog.Infof("set pod %v/%v cfs quota %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
+			logger.Infof("set pod %v/%v cfs quota %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
 				p.Request.PodMeta.Name, *p.Response.Resources.CFSQuota, p.Request.CgroupParent, err)
 		} else {
-			klog.V(5).Infof("set pod %v/%v cfs quota %v on cgroup parent %v",
+			logger.V(5).Infof("set pod %v/%v cfs quota %v on cgroup parent %v",
 				p.Request.PodMeta.Namespace, p.Request.PodMeta.Name, *p.Response.Resources.CFSQuota, p.Request.CgroupParent)
 		}
 	}
@@ -187,10 +186,10 @@ func (p *PodContext) injectForExt() {
 		eventHelper := audit.V(3).Pod(p.Request.PodMeta.Namespace, p.Request.PodMeta.Name).Reason("runtime-hooks").Message(
 			"set pod memory limit to %v", *p.Response.Resources.MemoryLimit)
 		if err := injectMemoryLimit(p.Request.CgroupParent, *p.Response.Resources.MemoryLimit, eventHelper, p.executor); err != nil {
-			klog.Infof("set pod %v/%v memory limit %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
+			logger.Infof("set pod %v/%v memory limit %v on cgroup parent %v failed, error %v", p.Request.PodMeta.Namespace,
 				p.Request.PodMeta.Name, *p.Response.Resources.MemoryLimit, p.Request.CgroupParent, err)
 		} else {
-			klog.V(5).Infof("set pod %v/%v memory limit %v on cgroup parent %v",
+			logger.V(5).Infof("set pod %v/%v memory limit %v on cgroup parent %v",
 				p.Request.PodMeta.Namespace, p.Request.PodMeta.Name, *p.Response.Resources.MemoryLimit, p.Request.CgroupParent)
 		}
 	}
@@ -25,15 +25,17 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
-	"k8s.io/klog/v2"
 
 	runtimeapi "github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 type Options struct {
 	Network       string
 	Address       string
@@ -69,7 +71,7 @@ func (s *server) Setup() error {
 }
 
 func (s *server) Start() error {
-	klog.Infof("starting runtime hook server on %s", s.options.Address)
+	logger.Infof("starting runtime hook server on %s", s.options.Address)
 	go func() {
 		s.server.Serve(s.listener)
 	}()
@@ -77,7 +79,7 @@ func (s *server) Start() error {
 }
 
 func (s *server) Stop() {
-	klog.Infof("stopping runtime hook server")
+	logger.Infof("stopping runtime hook server")
 	s.server.Stop()
 }
 
@@ -100,7 +102,7 @@ func (s *server) createRPCServer() error {
 	}
 	if s.options.Network == "unix" {
 		if err := syscall.Unlink(s.options.Address); err != nil {
-			klog.Infof("unlink error %v", err)
+			logger.Infof("unlink error %v", err)
 		}
 	}
 	l, err := net.Listen(s.options.Network, s.options.Address)
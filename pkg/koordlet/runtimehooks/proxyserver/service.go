@@ -19,8 +19,6 @@ package proxyserver
 import (
 	"context"
 
-	"k8s.io/klog/v2"
-
 	runtimeapi "github.com/koordinator-sh/koordinator/apis/runtime/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
@@ -29,7 +27,7 @@ import (
 
 func (s *server) PreRunPodSandboxHook(ctx context.Context,
 	req *runtimeapi.PodSandboxHookRequest) (*runtimeapi.PodSandboxHookResponse, error) {
-	klog.V(5).Infof("receive PreRunPodSandboxHook request %v", req.String())
+	logger.V(5).Infof("receive PreRunPodSandboxHook request %v", req.String())
 	resp := &runtimeapi.PodSandboxHookResponse{
 		Labels:       req.GetLabels(),
 		Annotations:  req.GetAnnotations(),
@@ -40,13 +38,13 @@ func (s *server) PreRunPodSandboxHook(ctx context.Context,
 	podCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PreRunPodSandbox, podCtx)
 	podCtx.ProxyDone(resp, s.options.Executor)
-	klog.V(5).Infof("send PreRunPodSandboxHook for pod %v response %v", req.PodMeta.String(), resp.String())
+	logger.V(5).Infof("send PreRunPodSandboxHook for pod %v response %v", req.PodMeta.String(), resp.String())
 	return resp, err
 }
 
 func (s *server) PostStopPodSandboxHook(ctx context.Context,
 	req *runtimeapi.PodSandboxHookRequest) (*runtimeapi.PodSandboxHookResponse, error) {
-	klog.V(5).Infof("receive PostStopPodSandboxHook request %v", req.String())
+	logger.V(5).Infof("receive PostStopPodSandboxHook request %v", req.String())
 	resp := &runtimeapi.PodSandboxHookResponse{
 		Labels:       req.GetLabels(),
 		Annotations:  req.GetAnnotations(),
@@ -57,13 +55,13 @@ func (s *server) PostStopPodSandboxHook(ctx context.Context,
 	podCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PostStopPodSandbox, podCtx)
 	podCtx.ProxyDone(resp, s.options.Executor)
-	klog.V(5).Infof("send PostStopPodSandboxHook for pod %v response %v", req.PodMeta.String(), resp.String())
+	logger.V(5).Infof("send PostStopPodSandboxHook for pod %v response %v", req.PodMeta.String(), resp.String())
 	return resp, err
 }
 
 func (s *server) PreCreateContainerHook(ctx context.Context,
 	req *runtimeapi.ContainerResourceHookRequest) (*runtimeapi.ContainerResourceHookResponse, error) {
-	klog.V(5).Infof("receive PreCreateContainerHook request %v", req.String())
+	logger.V(5).Infof("receive PreCreateContainerHook request %v", req.String())
 	resp := &runtimeapi.ContainerResourceHookResponse{
 		ContainerAnnotations: req.GetContainerAnnotations(),
 		ContainerResources:   req.GetContainerResources(),
@@ -74,14 +72,14 @@ func (s *server) PreCreateContainerHook(ctx context.Context,
 	containerCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PreCreateContainer, containerCtx)
 	containerCtx.ProxyDone(resp)
-	klog.V(5).Infof("send PreCreateContainerHook response for pod %v container %v response %v",
+	logger.V(5).Infof("send PreCreateContainerHook response for pod %v container %v response %v",
 		req.PodMeta.String(), req.ContainerMeta.String(), resp.String())
 	return resp, err
 }
 
 func (s *server) PreStartContainerHook(ctx context.Context,
 	req *runtimeapi.ContainerResourceHookRequest) (*runtimeapi.ContainerResourceHookResponse, error) {
-	klog.V(5).Infof("receive PreStartContainerHook request %v", req.String())
+	logger.V(5).Infof("receive PreStartContainerHook request %v", req.String())
 	resp := &runtimeapi.ContainerResourceHookResponse{
 		ContainerAnnotations: req.GetContainerAnnotations(),
 		ContainerResources:   req.GetContainerResources(),
@@ -92,14 +90,14 @@ func (s *server) PreStartContainerHook(ctx context.Context,
 	containerCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PreStartContainer, containerCtx)
 	containerCtx.ProxyDone(resp)
-	klog.V(5).Infof("send PreStartContainerHook for pod %v container %v response %v",
+	logger.V(5).Infof("send PreStartContainerHook for pod %v container %v response %v",
 		req.PodMeta.String(), req.ContainerMeta.String(), resp.String())
 	return resp, err
 }
 
 func (s *server) PostStartContainerHook(ctx context.Context,
 	req *runtimeapi.ContainerResourceHookRequest) (*runtimeapi.ContainerResourceHookResponse, error) {
-	klog.V(5).Infof("receive PostStartContainerHook request %v", req.String())
+	logger.V(5).Infof("receive PostStartContainerHook request %v", req.String())
 	resp := &runtimeapi.ContainerResourceHookResponse{
 		ContainerAnnotations: req.GetContainerAnnotations(),
 		ContainerResources:   req.GetContainerResources(),
@@ -110,14 +108,14 @@ func (s *server) PostStartContainerHook(ctx context.Context,
 	containerCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PostStartContainer, containerCtx)
 	containerCtx.ProxyDone(resp)
-	klog.V(5).Infof("send PostStartContainerHook for pod %v container %v response %v",
+	logger.V(5).Infof("send PostStartContainerHook for pod %v container %v response %v",
 		req.PodMeta.String(), req.ContainerMeta.String(), resp.String())
 	return resp, err
 }
 
 func (s *server) PostStopContainerHook(ctx context.Context,
 	req *runtimeapi.ContainerResourceHookRequest) (*runtimeapi.ContainerResourceHookResponse, error) {
-	klog.V(5).Infof("receive PostStopContainerHook request %v", req.String())
+	logger.V(5).Infof("receive PostStopContainerHook request %v", req.String())
 	resp := &runtimeapi.ContainerResourceHookResponse{
 		ContainerAnnotations: req.GetContainerAnnotations(),
 		ContainerResources:   req.GetContainerResources(),
@@ -128,14 +126,14 @@ func (s *server) PostStopContainerHook(ctx context.Context,
 	containerCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PostStopContainer, containerCtx)
 	containerCtx.ProxyDone(resp)
-	klog.V(5).Infof("send PostStopContainerHook for pod %v container %v response %v",
+	logger.V(5).Infof("send PostStopContainerHook for pod %v container %v response %v",
 		req.PodMeta.String(), req.ContainerMeta.String(), resp.String())
 	return resp, err
 }
 
 func (s *server) PreUpdateContainerResourcesHook(ctx context.Context,
 	req *runtimeapi.ContainerResourceHookRequest) (*runtimeapi.ContainerResourceHookResponse, error) {
-	klog.V(5).Infof("receive PreUpdateContainerResourcesHook request %v", req.String())
+	logger.V(5).Infof("receive PreUpdateContainerResourcesHook request %v", req.String())
 	resp := &runtimeapi.ContainerResourceHookResponse{
 		ContainerAnnotations: req.GetContainerAnnotations(),
 		ContainerResources:   req.GetContainerResources(),
@@ -146,7 +144,7 @@ func (s *server) PreUpdateContainerResourcesHook(ctx context.Context,
 	containerCtx.FromProxy(req)
 	err := hooks.RunHooks(s.options.PluginFailurePolicy, rmconfig.PreUpdateContainerResources, containerCtx)
 	containerCtx.ProxyDone(resp)
-	klog.V(5).Infof("send PreUpdateContainerResourcesHook for pod %v container %v response %v",
+	logger.V(5).Infof("send PreUpdateContainerResourcesHook for pod %v container %v response %v",
 		req.PodMeta.String(), req.ContainerMeta.String(), resp.String())
 	return resp, err
 }
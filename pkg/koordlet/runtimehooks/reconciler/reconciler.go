@@ -21,16 +21,18 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/protocol"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 const (
 	kubeQOSReconcileSeconds = 10
 )
@@ -151,19 +153,19 @@ func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource,
 
 		// if reconciler exist
 		if r.filter.Name() != filter.Name() {
-			klog.Fatalf("%v of level %v is already registered with filter %v by %v, cannot change to %v by %v",
+			logger.Fatalf("%v of level %v is already registered with filter %v by %v, cannot change to %v by %v",
 				cgroupFile.ResourceType(), level, r.filter.Name(), r.description, filter.Name(), description)
 		}
 
 		for _, condition := range conditions {
 			if _, ok := r.fn[condition]; ok {
-				klog.Fatalf("%v of level %v is already registered with condition %v by %v, cannot change by %v",
-					cgroupFile.ResourceType, level, condition, r.description, description)
+				logger.Fatalf("%v of level %v is already registered with condition %v by %v, cannot change by %v",
+					cgroupFile.ResourceType(), level, condition, r.description, description)
 			}
 
 			r.fn[condition] = fn
 		}
-		klog.V(1).Infof("register reconcile function %v finished, info: level=%v, resourceType=%v, add conditions=%v",
+		logger.V(1).Infof("register reconcile function %v finished, info: level=%v, resourceType=%v, add conditions=%v",
 			description, level, cgroupFile.ResourceType(), conditions)
 		return
 	}
@@ -195,9 +197,9 @@ func RegisterCgroupReconciler(level ReconcilerLevel, cgroupFile system.Resource,
 		}
 		globalCgroupReconcilers.containerLevel[string(r.cgroupFile.ResourceType())] = r
 	default:
-		klog.Fatalf("cgroup level %v is not supported", level)
+		logger.Fatalf("cgroup level %v is not supported", level)
 	}
-	klog.V(1).Infof("register reconcile function %v finished, info: level=%v, resourceType=%v, filter=%v, conditions=%v",
+	logger.V(1).Infof("register reconcile function %v finished, info: level=%v, resourceType=%v, filter=%v, conditions=%v",
 		description, level, cgroupFile.ResourceType(), filter.Name(), conditions)
 }
 
@@ -231,18 +233,19 @@ type reconciler struct {
 func (c *reconciler) Run(stopCh <-chan struct{}) error {
 	go c.reconcilePodCgroup(stopCh)
 	go c.reconcileKubeQOSCgroup(stopCh)
-	klog.V(1).Infof("start runtime hook reconciler successfully")
+	logger.V(1).Infof("start runtime hook reconciler successfully")
 	return nil
 }
 
 func (c *reconciler) podRefreshCallback(t statesinformer.RegisterType, o interface{},
-	podsMeta []*statesinformer.PodMeta) {
+	podsMeta []*statesinformer.PodMeta) error {
 	c.podsMutex.Lock()
 	defer c.podsMutex.Unlock()
 	c.podsMeta = podsMeta
 	if len(c.podUpdated) == 0 {
 		c.podUpdated <- struct{}{}
 	}
+	return nil
 }
 
 func (c *reconciler) getPodsMeta() []*statesinformer.PodMeta {
@@ -264,7 +267,7 @@ func (c *reconciler) reconcileKubeQOSCgroup(stopCh <-chan struct{}) {
 			doKubeQOSCgroup(c.executor)
 			timer.Reset(duration)
 		case <-stopCh:
-			klog.V(1).Infof("stop reconcile kube qos cgroup")
+			logger.V(1).Infof("stop reconcile kube qos cgroup")
 		}
 	}
 }
@@ -276,15 +279,15 @@ func doKubeQOSCgroup(e resourceexecutor.ResourceUpdateExecutor) {
 			kubeQOSCtx := protocol.HooksProtocolBuilder.KubeQOS(kubeQOS)
 			reconcileFn, ok := r.fn[NoneFilterCondition]
 			if !ok { // all kube qos reconcilers should register in this condition
-				klog.Warningf("calling reconcile function %v failed, error condition %s not registered",
+				logger.Warningf("calling reconcile function %v failed, error condition %s not registered",
 					r.description, NoneFilterCondition)
 				continue
 			}
 			if err := reconcileFn(kubeQOSCtx); err != nil {
-				klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
+				logger.Warningf("calling reconcile function %v failed, error %v", r.description, err)
 			} else {
 				kubeQOSCtx.ReconcilerDone(e)
-				klog.V(5).Infof("calling reconcile function %v for kube qos %v finish",
+				logger.V(5).Infof("calling reconcile function %v for kube qos %v finish",
 					r.description, kubeQOS)
 			}
 		}
@@ -302,17 +305,17 @@ func (c *reconciler) reconcilePodCgroup(stopCh <-chan struct{}) {
 				for _, r := range globalCgroupReconcilers.podLevel {
 					reconcileFn, ok := r.fn[r.filter.Filter(podMeta)]
 					if !ok {
-						klog.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
+						logger.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
 							r.description, r.filter.Filter(podMeta))
 						continue
 					}
 
 					podCtx := protocol.HooksProtocolBuilder.Pod(podMeta)
 					if err := reconcileFn(podCtx); err != nil {
-						klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
+						logger.Warningf("calling reconcile function %v failed, error %v", r.description, err)
 					} else {
 						podCtx.ReconcilerDone(c.executor)
-						klog.V(5).Infof("calling reconcile function %v for pod %v finished",
+						logger.V(5).Infof("calling reconcile function %v for pod %v finished",
 							r.description, util.GetPodKey(podMeta.Pod))
 					}
 				}
@@ -320,24 +323,24 @@ func (c *reconciler) reconcilePodCgroup(stopCh <-chan struct{}) {
 					for _, r := range globalCgroupReconcilers.containerLevel {
 						reconcileFn, ok := r.fn[r.filter.Filter(podMeta)]
 						if !ok {
-							klog.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
+							logger.V(5).Infof("calling reconcile function %v aborted, condition %s not registered",
 								r.description, r.filter.Filter(podMeta))
 							continue
 						}
 
 						containerCtx := protocol.HooksProtocolBuilder.Container(podMeta, containerStat.Name)
 						if err := reconcileFn(containerCtx); err != nil {
-							klog.Warningf("calling reconcile function %v failed, error %v", r.description, err)
+							logger.Warningf("calling reconcile function %v failed, error %v", r.description, err)
 						} else {
 							containerCtx.ReconcilerDone(c.executor)
-							klog.V(5).Infof("calling reconcile function %v for container %v/%v finish",
+							logger.V(5).Infof("calling reconcile function %v for container %v/%v finish",
 								r.description, util.GetPodKey(podMeta.Pod), containerStat.Name)
 						}
 					}
 				}
 			}
 		case <-stopCh:
-			klog.V(1).Infof("stop reconcile pod cgroup")
+			logger.V(1).Infof("stop reconcile pod cgroup")
 			return
 		}
 	}
@@ -17,16 +17,20 @@ limitations under the License.
 package rule
 
 import (
+	"fmt"
 	"reflect"
 	"runtime"
 	"sync"
 
-	"k8s.io/klog/v2"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 type Rule struct {
 	name            string
 	description     string
@@ -46,14 +50,14 @@ var globalRWMutex sync.RWMutex
 func Register(name, description string, injectOpts ...InjectOption) *Rule {
 	r, exist := find(name)
 	if exist {
-		klog.Fatalf("rule %s is conflict since name is already registered")
+		logger.Fatalf("rule %s is conflict since name is already registered", name)
 		return r
 	}
 	r.description = description
 	for _, opt := range injectOpts {
 		opt.Apply(r)
 	}
-	klog.V(5).Infof("new rule %v has registered", name)
+	logger.V(5).Infof("new rule %v has registered", name)
 	return r
 }
 
@@ -61,7 +65,7 @@ func (r *Rule) runUpdateCallbacks(pods []*statesinformer.PodMeta) {
 	for _, callbackFn := range r.callbacks {
 		if err := callbackFn(pods); err != nil {
 			cbName := runtime.FuncForPC(reflect.ValueOf(callbackFn).Pointer()).Name()
-			klog.Warningf("executing %s callback function %s failed, error %v", r.name, cbName)
+			logger.Warningf("executing %s callback function %s failed, error %v", r.name, cbName, err)
 		}
 	}
 }
@@ -77,15 +81,18 @@ func find(name string) (*Rule, bool) {
 	return newRule, false
 }
 
-func UpdateRules(ruleType statesinformer.RegisterType, ruleObj interface{}, podsMeta []*statesinformer.PodMeta) {
-	klog.V(3).Infof("applying %v rules with new %v, detail: %v",
+// UpdateRules parses the rules from the updated object and returns the errors of the rules failed to parse, which
+// means the object is not applied. The failures of updating the pods are left to the reconciler.
+func UpdateRules(ruleType statesinformer.RegisterType, ruleObj interface{}, podsMeta []*statesinformer.PodMeta) error {
+	logger.V(3).Infof("applying %v rules with new %v, detail: %v",
 		len(globalHookRules), ruleType.String(), util.DumpJSON(ruleObj))
+	var errs []error
 	for _, r := range globalHookRules {
 		if ruleType != r.parseRuleType {
 			continue
 		}
 		if !r.systemSupported {
-			klog.V(4).Infof("system unsupported for rule %s, do nothing during UpdateRules", r.name)
+			logger.V(4).Infof("system unsupported for rule %s, do nothing during UpdateRules", r.name)
 			continue
 		}
 		if r.parseRuleFn == nil {
@@ -93,14 +100,16 @@ func UpdateRules(ruleType statesinformer.RegisterType, ruleObj interface{}, pods
 		}
 		updated, err := r.parseRuleFn(ruleObj)
 		if err != nil {
-			klog.Warningf("parse rule %s from nodeSLO failed, error: %v", r.name, err)
+			logger.Warningf("parse rule %s from nodeSLO failed, error: %v", r.name, err)
+			errs = append(errs, fmt.Errorf("parse rule %s failed, err: %w", r.name, err))
 			continue
 		}
 		if updated {
-			klog.V(3).Infof("rule %s is updated, run update callback for all %v pods", r.name, len(podsMeta))
+			logger.V(3).Infof("rule %s is updated, run update callback for all %v pods", r.name, len(podsMeta))
 			r.runUpdateCallbacks(podsMeta)
 		}
 	}
+	return utilerrors.NewAggregate(errs)
 }
 
 func init() {
@@ -17,8 +17,6 @@ limitations under the License.
 package runtimehooks
 
 import (
-	"k8s.io/klog/v2"
-
 	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/resourceexecutor"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/hooks"
@@ -26,9 +24,12 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/reconciler"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/runtimehooks/rule"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/logs"
 	"github.com/koordinator-sh/koordinator/pkg/runtimeproxy/config"
 )
 
+var logger = logs.NewLogger(logs.ModuleRuntimeHooks)
+
 type HookPlugin interface {
 	Register(op hooks.Options)
 }
@@ -45,7 +46,7 @@ type runtimeHook struct {
 }
 
 func (r *runtimeHook) Run(stopCh <-chan struct{}) error {
-	klog.V(5).Infof("runtime hook server start running")
+	logger.V(5).Infof("runtime hook server start running")
 	go r.executor.Run(stopCh)
 	if err := r.server.Start(); err != nil {
 		return err
@@ -56,9 +57,9 @@ func (r *runtimeHook) Run(stopCh <-chan struct{}) error {
 	if err := r.server.Register(); err != nil {
 		return err
 	}
-	klog.V(5).Infof("runtime hook server has started")
+	logger.V(5).Infof("runtime hook server has started")
 	<-stopCh
-	klog.Infof("runtime hook is stopped")
+	logger.Infof("runtime hook is stopped")
 	return nil
 }
 
@@ -89,7 +90,8 @@ func NewRuntimeHook(si statesinformer.StatesInformer, cfg *Config) (RuntimeHook,
 	}
 
 	newPluginOptions := hooks.Options{
-		Executor: e,
+		Executor:       e,
+		StatesInformer: si,
 	}
 
 	if err != nil {
@@ -108,21 +110,24 @@ func NewRuntimeHook(si statesinformer.StatesInformer, cfg *Config) (RuntimeHook,
 	si.RegisterCallbacks(statesinformer.RegisterTypeNodeTopology, "runtime-hooks-rule-node-topo",
 		"Update hooks rule if NodeTopology infor update",
 		rule.UpdateRules)
+	si.RegisterCallbacks(statesinformer.RegisterTypeAllPods, "runtime-hooks-rule-all-pods",
+		"Update hooks rule if pods update",
+		rule.UpdateRules)
 	if err := s.Setup(); err != nil {
-		klog.Fatal("failed to setup runtime hook server, error %v", err)
+		logger.Fatalf("failed to setup runtime hook server, error %v", err)
 		return nil, err
 	}
 	return r, nil
 }
 
 func registerPlugins(op hooks.Options) {
-	klog.V(5).Infof("start register plugins for runtime hook")
+	logger.V(5).Infof("start register plugins for runtime hook")
 	for hookFeature, hookPlugin := range runtimeHookPlugins {
 		enabled := features.DefaultKoordletFeatureGate.Enabled(hookFeature)
 		if enabled {
 			hookPlugin.Register(op)
 		}
-		klog.Infof("runtime hook plugin %s enable %v", hookFeature, enabled)
+		logger.Infof("runtime hook plugin %s enable %v", hookFeature, enabled)
 	}
 }
 
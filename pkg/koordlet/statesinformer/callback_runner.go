@@ -17,6 +17,9 @@ limitations under the License.
 package statesinformer
 
 import (
+	"fmt"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
 )
 
@@ -49,11 +52,17 @@ type updateCallback struct {
 }
 
 type UpdateCbCtx struct{}
-type UpdateCbFn func(t RegisterType, obj interface{}, pods []*PodMeta)
+
+// UpdateCbFn is the callback of the updated object, which returns an error if it fails to apply the object.
+type UpdateCbFn func(t RegisterType, obj interface{}, pods []*PodMeta) error
+
+// AppliedFn is called with the object once all the callbacks of the type have applied it successfully.
+type AppliedFn func(t RegisterType, obj interface{})
 
 type callbackRunner struct {
 	callbackChans        map[RegisterType]chan UpdateCbCtx
 	stateUpdateCallbacks map[RegisterType][]updateCallback
+	appliedFns           map[RegisterType]AppliedFn
 	statesInformer       StatesInformer
 }
 
@@ -69,6 +78,7 @@ func NewCallbackRunner() *callbackRunner {
 		RegisterTypeAllPods:      {},
 		RegisterTypeNodeTopology: {},
 	}
+	c.appliedFns = map[RegisterType]AppliedFn{}
 	return c
 }
 
@@ -95,6 +105,14 @@ func (s *callbackRunner) RegisterCallbacks(rType RegisterType, name, description
 	klog.V(1).Infof("states informer callback %s has registered for type %v", name, rType.String())
 }
 
+// SetAppliedFn sets the function called once all the callbacks of the type succeed.
+func (s *callbackRunner) SetAppliedFn(rType RegisterType, fn AppliedFn) {
+	if s.appliedFns == nil {
+		s.appliedFns = map[RegisterType]AppliedFn{}
+	}
+	s.appliedFns[rType] = fn
+}
+
 func (s *callbackRunner) SendCallback(objType RegisterType) {
 	if _, exist := s.callbackChans[objType]; exist {
 		select {
@@ -108,17 +126,20 @@ func (s *callbackRunner) SendCallback(objType RegisterType) {
 	}
 }
 
-func (s *callbackRunner) runCallbacks(objType RegisterType, obj interface{}) {
+func (s *callbackRunner) runCallbacks(objType RegisterType, obj interface{}) error {
 	callbacks, exist := s.stateUpdateCallbacks[objType]
 	if !exist {
-		klog.Errorf("states informer callbacks type %v not exist", objType.String())
-		return
+		return fmt.Errorf("states informer callbacks type %v not exist", objType.String())
 	}
 	pods := s.statesInformer.GetAllPods()
+	var errs []error
 	for _, c := range callbacks {
 		klog.V(5).Infof("start running callback function %v for type %v", c.name, objType.String())
-		c.fn(objType, obj, pods)
+		if err := c.fn(objType, obj, pods); err != nil {
+			errs = append(errs, fmt.Errorf("callback %s failed, err: %w", c.name, err))
+		}
 	}
+	return utilerrors.NewAggregate(errs)
 }
 
 func (s *callbackRunner) Start(stopCh <-chan struct{}) {
@@ -131,8 +152,10 @@ func (s *callbackRunner) Start(stopCh <-chan struct{}) {
 					cbObj := s.getObjByType(cbType, cbCtx)
 					if cbObj == nil {
 						klog.Warningf("callback runner with type %v is not exist")
-					} else {
-						s.runCallbacks(cbType, cbObj)
+					} else if err := s.runCallbacks(cbType, cbObj); err != nil {
+						klog.Warningf("failed to run callbacks for type %v, err: %v", cbType.String(), err)
+					} else if appliedFn := s.appliedFns[cbType]; appliedFn != nil {
+						appliedFn(cbType, cbObj)
 					}
 				case <-stopCh:
 					klog.Infof("callback runner %v loop is exited", cbType.String())
@@ -64,8 +64,9 @@ func TestRegisterCallbacksAndRun(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			testVar := pointer.BoolPtr(false)
-			callbackFn := func(t RegisterType, obj interface{}, pods []*PodMeta) {
+			callbackFn := func(t RegisterType, obj interface{}, pods []*PodMeta) error {
 				*testVar = true
+				return nil
 			}
 			si := &callbackRunner{
 				stateUpdateCallbacks: map[RegisterType][]updateCallback{
@@ -88,7 +89,8 @@ func TestRegisterCallbacksAndRun(t *testing.T) {
 			}
 			si.RegisterCallbacks(tt.args.objType, tt.args.name, tt.args.description, callbackFn)
 			si.getObjByType(tt.args.objType, UpdateCbCtx{})
-			si.runCallbacks(tt.args.objType, &slov1alpha1.NodeSLO{})
+			err := si.runCallbacks(tt.args.objType, &slov1alpha1.NodeSLO{})
+			assert.NoError(t, err)
 			assert.Equal(t, *testVar, true)
 		})
 	}
@@ -123,9 +125,10 @@ func Test_statesInformer_startCallbackRunners(t *testing.T) {
 				nodeSLO:     nodeSLO,
 				name:        "get value from node slo label",
 				description: "get value from node slo label",
-				fn: func(t RegisterType, obj interface{}, pods []*PodMeta) {
+				fn: func(t RegisterType, obj interface{}, pods []*PodMeta) error {
 					output <- nodeSLO.Labels["test-label-key"]
 					stopCh <- struct{}{}
+					return nil
 				},
 			},
 			wantOutput: "test-label-val1",
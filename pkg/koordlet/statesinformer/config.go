@@ -33,18 +33,27 @@ type Config struct {
 	DisableQueryKubeletConfig   bool
 	EnableNodeMetricReport      bool
 	MetricReportInterval        time.Duration // Deprecated
+	// NodeMetricReportMaxInterval is the max interval of the adaptive node metric report, within which the report is
+	// skipped unless the usages change significantly. It should be less than the NodeMetric expiration of the
+	// consumers, e.g. the load-aware scheduling.
+	NodeMetricReportMaxInterval time.Duration
+	// NodeMetricReportChangeThreshold is the ratio of the node usage change to the last report, above which the
+	// adaptive node metric report is made sooner.
+	NodeMetricReportChangeThreshold float64
 }
 
 func NewDefaultConfig() *Config {
 	return &Config{
-		KubeletPreferredAddressType: string(corev1.NodeInternalIP),
-		KubeletSyncInterval:         10 * time.Second,
-		KubeletSyncTimeout:          3 * time.Second,
-		InsecureKubeletTLS:          false,
-		KubeletReadOnlyPort:         10255,
-		NodeTopologySyncInterval:    3 * time.Second,
-		DisableQueryKubeletConfig:   false,
-		EnableNodeMetricReport:      true,
+		KubeletPreferredAddressType:     string(corev1.NodeInternalIP),
+		KubeletSyncInterval:             10 * time.Second,
+		KubeletSyncTimeout:              3 * time.Second,
+		InsecureKubeletTLS:              false,
+		KubeletReadOnlyPort:             10255,
+		NodeTopologySyncInterval:        3 * time.Second,
+		DisableQueryKubeletConfig:       false,
+		EnableNodeMetricReport:          true,
+		NodeMetricReportMaxInterval:     150 * time.Second,
+		NodeMetricReportChangeThreshold: 0.1,
 	}
 }
 
@@ -58,4 +67,6 @@ func (c *Config) InitFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.DisableQueryKubeletConfig, "disable-query-kubelet-config", c.DisableQueryKubeletConfig, "Disables querying the kubelet configuration from kubelet. Flag must be set to true if kubelet-insecure-tls=true is configured")
 	fs.DurationVar(&c.MetricReportInterval, "report-interval", c.MetricReportInterval, "Deprecated since v1.1, use ColocationStrategy.MetricReportIntervalSeconds in config map of slo-controller")
 	fs.BoolVar(&c.EnableNodeMetricReport, "enable-node-metric-report", c.EnableNodeMetricReport, "Enable status update of node metric crd.")
+	fs.DurationVar(&c.NodeMetricReportMaxInterval, "node-metric-report-max-interval", c.NodeMetricReportMaxInterval, "The max interval of the adaptive node metric report when the usages are stable, which should be less than the NodeMetric expiration of the scheduler. Only works with the AdaptiveNodeMetricReport feature gate.")
+	fs.Float64Var(&c.NodeMetricReportChangeThreshold, "node-metric-report-change-threshold", c.NodeMetricReportChangeThreshold, "The ratio of the node usage change to the last report, above which the adaptive node metric report is made sooner. Only works with the AdaptiveNodeMetricReport feature gate.")
 }
@@ -33,15 +33,17 @@ func TestNewDefaultConfig(t *testing.T) {
 		{
 			name: "config",
 			want: &Config{
-				KubeletPreferredAddressType: string(corev1.NodeInternalIP),
-				KubeletSyncInterval:         10 * time.Second,
-				KubeletSyncTimeout:          3 * time.Second,
-				InsecureKubeletTLS:          false,
-				KubeletReadOnlyPort:         10255,
-				NodeTopologySyncInterval:    3 * time.Second,
-				DisableQueryKubeletConfig:   false,
-				EnableNodeMetricReport:      true,
-				MetricReportInterval:        0,
+				KubeletPreferredAddressType:     string(corev1.NodeInternalIP),
+				KubeletSyncInterval:             10 * time.Second,
+				KubeletSyncTimeout:              3 * time.Second,
+				InsecureKubeletTLS:              false,
+				KubeletReadOnlyPort:             10255,
+				NodeTopologySyncInterval:        3 * time.Second,
+				DisableQueryKubeletConfig:       false,
+				EnableNodeMetricReport:          true,
+				MetricReportInterval:            0,
+				NodeMetricReportMaxInterval:     150 * time.Second,
+				NodeMetricReportChangeThreshold: 0.1,
 			},
 		},
 	}
@@ -64,18 +66,22 @@ func TestConfig_InitFlags(t *testing.T) {
 		"--node-topology-sync-interval=10s",
 		"--disable-query-kubelet-config=true",
 		"--enable-node-metric-report=false",
+		"--node-metric-report-max-interval=120s",
+		"--node-metric-report-change-threshold=0.2",
 	}
 	fs := flag.NewFlagSet(cmdArgs[0], flag.ExitOnError)
 
 	type fields struct {
-		KubeletPreferredAddressType string
-		KubeletSyncInterval         time.Duration
-		KubeletSyncTimeout          time.Duration
-		InsecureKubeletTLS          bool
-		KubeletReadOnlyPort         uint
-		NodeTopologySyncInterval    time.Duration
-		DisableQueryKubeletConfig   bool
-		EnableNodeMetricReport      bool
+		KubeletPreferredAddressType     string
+		KubeletSyncInterval             time.Duration
+		KubeletSyncTimeout              time.Duration
+		InsecureKubeletTLS              bool
+		KubeletReadOnlyPort             uint
+		NodeTopologySyncInterval        time.Duration
+		DisableQueryKubeletConfig       bool
+		EnableNodeMetricReport          bool
+		NodeMetricReportMaxInterval     time.Duration
+		NodeMetricReportChangeThreshold float64
 	}
 	type args struct {
 		fs *flag.FlagSet
@@ -88,14 +94,16 @@ func TestConfig_InitFlags(t *testing.T) {
 		{
 			name: "not default",
 			fields: fields{
-				KubeletPreferredAddressType: "Hostname",
-				KubeletSyncInterval:         30 * time.Second,
-				KubeletSyncTimeout:          10 * time.Second,
-				InsecureKubeletTLS:          true,
-				KubeletReadOnlyPort:         10258,
-				NodeTopologySyncInterval:    10 * time.Second,
-				DisableQueryKubeletConfig:   true,
-				EnableNodeMetricReport:      false,
+				KubeletPreferredAddressType:     "Hostname",
+				KubeletSyncInterval:             30 * time.Second,
+				KubeletSyncTimeout:              10 * time.Second,
+				InsecureKubeletTLS:              true,
+				KubeletReadOnlyPort:             10258,
+				NodeTopologySyncInterval:        10 * time.Second,
+				DisableQueryKubeletConfig:       true,
+				EnableNodeMetricReport:          false,
+				NodeMetricReportMaxInterval:     120 * time.Second,
+				NodeMetricReportChangeThreshold: 0.2,
 			},
 			args: args{fs: fs},
 		},
@@ -103,14 +111,16 @@ func TestConfig_InitFlags(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			raw := &Config{
-				KubeletPreferredAddressType: tt.fields.KubeletPreferredAddressType,
-				KubeletSyncInterval:         tt.fields.KubeletSyncInterval,
-				KubeletSyncTimeout:          tt.fields.KubeletSyncTimeout,
-				InsecureKubeletTLS:          tt.fields.InsecureKubeletTLS,
-				KubeletReadOnlyPort:         tt.fields.KubeletReadOnlyPort,
-				NodeTopologySyncInterval:    tt.fields.NodeTopologySyncInterval,
-				DisableQueryKubeletConfig:   tt.fields.DisableQueryKubeletConfig,
-				EnableNodeMetricReport:      tt.fields.EnableNodeMetricReport,
+				KubeletPreferredAddressType:     tt.fields.KubeletPreferredAddressType,
+				KubeletSyncInterval:             tt.fields.KubeletSyncInterval,
+				KubeletSyncTimeout:              tt.fields.KubeletSyncTimeout,
+				InsecureKubeletTLS:              tt.fields.InsecureKubeletTLS,
+				KubeletReadOnlyPort:             tt.fields.KubeletReadOnlyPort,
+				NodeTopologySyncInterval:        tt.fields.NodeTopologySyncInterval,
+				DisableQueryKubeletConfig:       tt.fields.DisableQueryKubeletConfig,
+				EnableNodeMetricReport:          tt.fields.EnableNodeMetricReport,
+				NodeMetricReportMaxInterval:     tt.fields.NodeMetricReportMaxInterval,
+				NodeMetricReportChangeThreshold: tt.fields.NodeMetricReportChangeThreshold,
 			}
 			c := NewDefaultConfig()
 			c.InitFlags(tt.args.fs)
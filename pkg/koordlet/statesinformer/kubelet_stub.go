@@ -17,12 +17,15 @@ limitations under the License.
 package statesinformer
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strconv"
 	"time"
 
@@ -37,6 +40,9 @@ import (
 type KubeletStub interface {
 	GetAllPods() (corev1.PodList, error)
 	GetKubeletConfiguration() (*kubeletconfiginternal.KubeletConfiguration, error)
+	// CheckpointContainer checkpoints the container via the kubelet checkpoint API and returns the archives.
+	// The checkpoint may take minutes, so it is bounded by the ctx instead of the timeout of the stub.
+	CheckpointContainer(ctx context.Context, namespace, podName, containerName string) ([]string, error)
 }
 
 type kubeletStub struct {
@@ -139,3 +145,47 @@ func (k *kubeletStub) GetKubeletConfiguration() (*kubeletconfiginternal.KubeletC
 	}
 	return kubeletConfiguration, nil
 }
+
+// ErrCheckpointUnsupported means the kubelet or the container runtime does not support the checkpoint,
+// e.g. the kubelet feature gate ContainerCheckpoint is disabled.
+var ErrCheckpointUnsupported = errors.New("checkpoint unsupported")
+
+type checkpointResponse struct {
+	Items []string `json:"items"`
+}
+
+func (k *kubeletStub) CheckpointContainer(ctx context.Context, namespace, podName, containerName string) ([]string, error) {
+	checkpointURL := url.URL{
+		Scheme: k.scheme,
+		Host:   net.JoinHostPort(k.addr, strconv.Itoa(k.port)),
+		Path:   path.Join("/checkpoint", namespace, podName, containerName),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, checkpointURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := *k.httpClient
+	client.Timeout = 0
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	body, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: request %s failed, code %d", ErrCheckpointUnsupported, checkpointURL.String(), rsp.StatusCode)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request %s failed, code %d, body %s", checkpointURL.String(), rsp.StatusCode, string(body))
+	}
+
+	var checkpoint checkpointResponse
+	if err = json.Unmarshal(body, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint response: %v", err)
+	}
+	return checkpoint.Items, nil
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package statesinformer
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net"
@@ -241,3 +242,44 @@ func TestNewKubeletStub(t *testing.T) {
 		})
 	}
 }
+
+func Test_kubeletStub_CheckpointContainer(t *testing.T) {
+	token = "token"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/checkpoint/default/test-pod/main", func(w http.ResponseWriter, r *http.Request) {
+		if !validateAuth(r) || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"items":["/var/lib/kubelet/checkpoints/checkpoint-test-pod_default-main.tar"]}`))
+	})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	address, portStr, err := parseHostAndPort(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, _ := strconv.Atoi(portStr)
+	cfg := &rest.Config{
+		Host:        net.JoinHostPort(address, portStr),
+		BearerToken: token,
+		TLSClientConfig: rest.TLSClientConfig{
+			Insecure: true,
+		},
+	}
+
+	client, err := NewKubeletStub(address, port, "https", 10*time.Second, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archives, err := client.CheckpointContainer(context.TODO(), "default", "test-pod", "main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/var/lib/kubelet/checkpoints/checkpoint-test-pod_default-main.tar"}, archives)
+
+	_, err = client.CheckpointContainer(context.TODO(), "default", "test-pod", "sidecar")
+	assert.ErrorIs(t, err, ErrCheckpointUnsupported)
+}
@@ -25,7 +25,8 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	v1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
-	v1alpha10 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	v1alpha10 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	v1alpha11 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	statesinformer "github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
 	v1 "k8s.io/api/core/v1"
 )
@@ -82,10 +83,10 @@ func (mr *MockStatesInformerMockRecorder) GetNode() *gomock.Call {
 }
 
 // GetNodeSLO mocks base method.
-func (m *MockStatesInformer) GetNodeSLO() *v1alpha10.NodeSLO {
+func (m *MockStatesInformer) GetNodeSLO() *v1alpha11.NodeSLO {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetNodeSLO")
-	ret0, _ := ret[0].(*v1alpha10.NodeSLO)
+	ret0, _ := ret[0].(*v1alpha11.NodeSLO)
 	return ret0
 }
 
@@ -109,6 +110,21 @@ func (mr *MockStatesInformerMockRecorder) GetNodeTopo() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNodeTopo", reflect.TypeOf((*MockStatesInformer)(nil).GetNodeTopo))
 }
 
+// GetPodDeviceAllocation mocks base method.
+func (m *MockStatesInformer) GetPodDeviceAllocation(namespace, name string) (*v1alpha10.PodDeviceAllocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPodDeviceAllocation", namespace, name)
+	ret0, _ := ret[0].(*v1alpha10.PodDeviceAllocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPodDeviceAllocation indicates an expected call of GetPodDeviceAllocation.
+func (mr *MockStatesInformerMockRecorder) GetPodDeviceAllocation(namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodDeviceAllocation", reflect.TypeOf((*MockStatesInformer)(nil).GetPodDeviceAllocation), namespace, name)
+}
+
 // HasSynced mocks base method.
 func (m *MockStatesInformer) HasSynced() bool {
 	m.ctrl.T.Helper()
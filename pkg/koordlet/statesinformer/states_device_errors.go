@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kmsg"
+)
+
+const (
+	// linkFlapThreshold is the number of the link downs in linkFlapWindow regarded as flapping.
+	linkFlapThreshold = 3
+	linkFlapWindow    = 10 * time.Minute
+	// deviceConditionExpiration is the period after which the condition is removed if the error does not recur.
+	// The Xid errors never expire since the GPUs can not recover without a reset.
+	deviceConditionExpiration = time.Hour
+)
+
+type gpuIdentity struct {
+	minor int32
+	uuid  string
+}
+
+type deviceConditionKey struct {
+	conditionType schedulingv1alpha1.DeviceConditionType
+	busID         string
+	name          string
+}
+
+// deviceErrorTracker records the device errors detected from the kernel log as the Device conditions.
+type deviceErrorTracker struct {
+	lock sync.Mutex
+	// gpus maps the normalized PCIe bus id to the GPU.
+	gpus       map[string]gpuIdentity
+	conditions map[deviceConditionKey]*schedulingv1alpha1.DeviceCondition
+	lastSeen   map[deviceConditionKey]time.Time
+	// linkDowns records the times of the recent link downs of each NIC.
+	linkDowns map[string][]time.Time
+	now       func() time.Time
+}
+
+func newDeviceErrorTracker() *deviceErrorTracker {
+	return &deviceErrorTracker{
+		gpus:       map[string]gpuIdentity{},
+		conditions: map[deviceConditionKey]*schedulingv1alpha1.DeviceCondition{},
+		lastSeen:   map[deviceConditionKey]time.Time{},
+		linkDowns:  map[string][]time.Time{},
+		now:        time.Now,
+	}
+}
+
+func (t *deviceErrorTracker) setGPUs(gpus map[string]gpuIdentity) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.gpus = gpus
+}
+
+// record updates the conditions with the device error. It returns the condition of the error, nil if the error does
+// not make the device unhealthy, and whether the condition is newly observed.
+func (t *deviceErrorTracker) record(deviceErr *kmsg.DeviceError) (*schedulingv1alpha1.DeviceCondition, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	condition := &schedulingv1alpha1.DeviceCondition{
+		Status:  corev1.ConditionTrue,
+		BusID:   deviceErr.BusID,
+		Name:    deviceErr.Name,
+		Message: deviceErr.Message,
+	}
+	switch deviceErr.Type {
+	case kmsg.GPUXidError:
+		if !kmsg.IsCriticalXid(deviceErr.Xid) {
+			return nil, false
+		}
+		condition.Type = schedulingv1alpha1.DeviceConditionXidError
+		condition.DeviceType = schedulingv1alpha1.GPU
+		condition.Reason = fmt.Sprintf("Xid%d", deviceErr.Xid)
+		if gpu, ok := t.gpus[deviceErr.BusID]; ok {
+			minor := gpu.minor
+			condition.Minor = &minor
+		}
+	case kmsg.NICLinkDown:
+		linkDowns := append(t.linkDowns[deviceErr.BusID], now)
+		for len(linkDowns) > 0 && now.Sub(linkDowns[0]) > linkFlapWindow {
+			linkDowns = linkDowns[1:]
+		}
+		t.linkDowns[deviceErr.BusID] = linkDowns
+		if len(linkDowns) < linkFlapThreshold {
+			return nil, false
+		}
+		condition.Type = schedulingv1alpha1.DeviceConditionLinkFlapping
+		condition.DeviceType = schedulingv1alpha1.NIC
+		condition.Reason = fmt.Sprintf("LinkDown%dTimesIn%v", len(linkDowns), linkFlapWindow)
+	case kmsg.RDMALinkError:
+		condition.Type = schedulingv1alpha1.DeviceConditionRDMALinkError
+		condition.DeviceType = schedulingv1alpha1.RDMA
+		condition.Reason = string(kmsg.RDMALinkError)
+	default:
+		return nil, false
+	}
+
+	key := deviceConditionKey{conditionType: condition.Type, busID: condition.BusID, name: condition.Name}
+	t.lastSeen[key] = now
+	if old, ok := t.conditions[key]; ok {
+		old.Reason = condition.Reason
+		old.Message = condition.Message
+		return old.DeepCopy(), false
+	}
+	// the time is persisted in seconds
+	condition.LastTransitionTime = metav1.NewTime(now.Truncate(time.Second))
+	t.conditions[key] = condition
+	return condition.DeepCopy(), true
+}
+
+// restore recovers the conditions reported in the Device before the restart. The restored conditions are regarded
+// as just seen, so the recoverable errors are kept for another expiration period.
+func (t *deviceErrorTracker) restore(conditions []schedulingv1alpha1.DeviceCondition) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	for i := range conditions {
+		condition := &conditions[i]
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		key := deviceConditionKey{conditionType: condition.Type, busID: condition.BusID, name: condition.Name}
+		if _, ok := t.conditions[key]; ok {
+			continue
+		}
+		t.conditions[key] = condition.DeepCopy()
+		t.lastSeen[key] = now
+	}
+}
+
+// getConditions returns the conditions of the errors not expired, sorted by the transition time.
+func (t *deviceErrorTracker) getConditions() []schedulingv1alpha1.DeviceCondition {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	var conditions []schedulingv1alpha1.DeviceCondition
+	for key, condition := range t.conditions {
+		if key.conditionType != schedulingv1alpha1.DeviceConditionXidError && now.Sub(t.lastSeen[key]) > deviceConditionExpiration {
+			delete(t.conditions, key)
+			delete(t.lastSeen, key)
+			continue
+		}
+		conditions = append(conditions, *condition.DeepCopy())
+	}
+	sort.Slice(conditions, func(i, j int) bool {
+		if !conditions[i].LastTransitionTime.Equal(&conditions[j].LastTransitionTime) {
+			return conditions[i].LastTransitionTime.Before(&conditions[j].LastTransitionTime)
+		}
+		if conditions[i].Type != conditions[j].Type {
+			return conditions[i].Type < conditions[j].Type
+		}
+		return getDeviceConditionDevice(&conditions[i]) < getDeviceConditionDevice(&conditions[j])
+	})
+	return conditions
+}
+
+// handleDeviceError records the device error detected from the kernel log, marks the GPU unhealthy for the
+// critical Xid errors and emits an event on the node for the newly observed condition.
+func (s *statesInformer) handleDeviceError(deviceErr *kmsg.DeviceError, recorder record.EventRecorder) {
+	condition, isNew := s.deviceErrors.record(deviceErr)
+	if condition == nil {
+		return
+	}
+	if condition.Type == schedulingv1alpha1.DeviceConditionXidError {
+		s.deviceErrors.lock.Lock()
+		gpu, ok := s.deviceErrors.gpus[condition.BusID]
+		s.deviceErrors.lock.Unlock()
+		if ok {
+			s.gpuMutex.Lock()
+			s.unhealthyGPU[gpu.uuid] = struct{}{}
+			s.gpuMutex.Unlock()
+		}
+	}
+	if !isNew {
+		return
+	}
+	klog.Warningf("device error detected from kernel log, condition %s, reason %s, device %s, message: %s",
+		condition.Type, condition.Reason, getDeviceConditionDevice(condition), condition.Message)
+	if recorder == nil {
+		return
+	}
+	if node := s.GetNode(); node != nil {
+		recorder.Eventf(node, corev1.EventTypeWarning, string(condition.Type), "%s %s: %s",
+			condition.DeviceType, getDeviceConditionDevice(condition), condition.Message)
+	}
+}
+
+// restoreDeviceErrors rebuilds the device errors from the conditions of the Device, since the kernel log before the
+// restart is not replayed. The GPUs with the Xid errors are marked unhealthy again.
+func (s *statesInformer) restoreDeviceErrors() {
+	device, err := s.deviceClient.Get(context.TODO(), s.option.NodeName, metav1.GetOptions{ResourceVersion: "0"})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("failed to get device %s to restore the device errors, err: %v", s.option.NodeName, err)
+		}
+		return
+	}
+	s.deviceErrors.restore(device.Status.Conditions)
+
+	s.deviceErrors.lock.Lock()
+	defer s.deviceErrors.lock.Unlock()
+	for key := range s.deviceErrors.conditions {
+		if key.conditionType != schedulingv1alpha1.DeviceConditionXidError {
+			continue
+		}
+		if gpu, ok := s.deviceErrors.gpus[key.busID]; ok {
+			s.gpuMutex.Lock()
+			s.unhealthyGPU[gpu.uuid] = struct{}{}
+			s.gpuMutex.Unlock()
+		}
+	}
+	klog.V(4).Infof("restored %d device conditions from device %s", len(s.deviceErrors.conditions), s.option.NodeName)
+}
+
+func getDeviceConditionDevice(condition *schedulingv1alpha1.DeviceCondition) string {
+	if len(condition.Name) > 0 && len(condition.BusID) > 0 {
+		return fmt.Sprintf("%s(%s)", condition.Name, condition.BusID)
+	}
+	return condition.Name + condition.BusID
+}
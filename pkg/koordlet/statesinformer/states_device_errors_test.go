@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	schedulingfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kmsg"
+)
+
+func Test_deviceErrorTracker(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newDeviceErrorTracker()
+	tracker.now = func() time.Time { return now }
+	tracker.setGPUs(map[string]gpuIdentity{"0000:3b:00": {minor: 2, uuid: "GPU-2"}})
+
+	// the Xid caused by the applications is ignored
+	condition, isNew := tracker.record(kmsg.ParseDeviceError("NVRM: Xid (PCI:0000:3b:00): 13, Graphics SM Warp Exception"))
+	assert.Nil(t, condition)
+	assert.False(t, isNew)
+
+	condition, isNew = tracker.record(kmsg.ParseDeviceError("NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, GPU has fallen off the bus."))
+	assert.True(t, isNew)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionXidError, condition.Type)
+	assert.Equal(t, schedulingv1alpha1.GPU, condition.DeviceType)
+	assert.Equal(t, pointer.Int32(2), condition.Minor)
+	assert.Equal(t, "Xid79", condition.Reason)
+
+	// the link flaps after 3 link downs in the window
+	linkDown := kmsg.ParseDeviceError("mlx5_core 0000:5e:00.0 eth0: Link down")
+	for i := 0; i < linkFlapThreshold-1; i++ {
+		condition, _ = tracker.record(linkDown)
+		assert.Nil(t, condition)
+		now = now.Add(linkFlapWindow/2 + time.Second)
+	}
+	// the first link down is out of the window
+	condition, _ = tracker.record(linkDown)
+	assert.Nil(t, condition)
+	condition, isNew = tracker.record(linkDown)
+	assert.True(t, isNew)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionLinkFlapping, condition.Type)
+	assert.Equal(t, schedulingv1alpha1.NIC, condition.DeviceType)
+	assert.Equal(t, "eth0", condition.Name)
+	assert.Equal(t, "0000:5e:00.0", condition.BusID)
+	condition, isNew = tracker.record(linkDown)
+	assert.NotNil(t, condition)
+	assert.False(t, isNew)
+
+	condition, isNew = tracker.record(kmsg.ParseDeviceError("infiniband mlx5_0: ib_query_port failed (-5)"))
+	assert.True(t, isNew)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionRDMALinkError, condition.Type)
+	assert.Equal(t, "mlx5_0", condition.Name)
+
+	conditions := tracker.getConditions()
+	assert.Len(t, conditions, 3)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionXidError, conditions[0].Type)
+
+	// the conditions expire except the Xid errors
+	now = now.Add(deviceConditionExpiration + time.Second)
+	conditions = tracker.getConditions()
+	assert.Len(t, conditions, 1)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionXidError, conditions[0].Type)
+}
+
+func Test_handleDeviceError(t *testing.T) {
+	s := &statesInformer{
+		unhealthyGPU: map[string]struct{}{},
+		deviceErrors: newDeviceErrorTracker(),
+	}
+	s.deviceErrors.setGPUs(map[string]gpuIdentity{"0000:3b:00": {minor: 2, uuid: "GPU-2"}})
+	s.handleDeviceError(kmsg.ParseDeviceError("NVRM: Xid (PCI:0000:3b:00): 43, pid=1234, Ch 00000010"), nil)
+	assert.Empty(t, s.unhealthyGPU)
+	s.handleDeviceError(kmsg.ParseDeviceError("NVRM: Xid (PCI:0000:3b:00): 48, pid=1234, DBE (double bit error)"), nil)
+	assert.Equal(t, map[string]struct{}{"GPU-2": {}}, s.unhealthyGPU)
+}
+
+func Test_restoreDeviceErrors(t *testing.T) {
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: schedulingv1alpha1.DeviceStatus{
+			Conditions: []schedulingv1alpha1.DeviceCondition{
+				{
+					Type:       schedulingv1alpha1.DeviceConditionXidError,
+					Status:     corev1.ConditionTrue,
+					DeviceType: schedulingv1alpha1.GPU,
+					Minor:      pointer.Int32(2),
+					BusID:      "0000:3b:00",
+					Reason:     "Xid79",
+				},
+				{
+					Type:       schedulingv1alpha1.DeviceConditionRDMALinkError,
+					Status:     corev1.ConditionTrue,
+					DeviceType: schedulingv1alpha1.RDMA,
+					Name:       "mlx5_0",
+				},
+			},
+		},
+	}
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &statesInformer{
+		option:       &pluginOption{NodeName: "test-node"},
+		deviceClient: schedulingfake.NewSimpleClientset(device).SchedulingV1alpha1().Devices(),
+		unhealthyGPU: map[string]struct{}{},
+		deviceErrors: newDeviceErrorTracker(),
+	}
+	s.deviceErrors.now = func() time.Time { return now }
+	s.deviceErrors.setGPUs(map[string]gpuIdentity{"0000:3b:00": {minor: 2, uuid: "GPU-2"}})
+	s.restoreDeviceErrors()
+	assert.Equal(t, map[string]struct{}{"GPU-2": {}}, s.unhealthyGPU)
+	assert.Len(t, s.deviceErrors.getConditions(), 2)
+
+	// the restored Xid error is not reported as new
+	_, isNew := s.deviceErrors.record(kmsg.ParseDeviceError("NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, GPU has fallen off the bus."))
+	assert.False(t, isNew)
+
+	// the restored recoverable errors expire if not recurring
+	now = now.Add(deviceConditionExpiration + time.Second)
+	conditions := s.deviceErrors.getConditions()
+	assert.Len(t, conditions, 1)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionXidError, conditions[0].Type)
+}
@@ -29,11 +29,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kmsg"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -50,14 +53,17 @@ func generateQueryParam() *metriccache.QueryParam {
 func (s *statesInformer) reportDevice() {
 	node := s.GetNode()
 	gpuDevices := s.buildGPUDevice()
-	if len(gpuDevices) == 0 {
+	conditions := s.deviceErrors.getConditions()
+	if len(gpuDevices) == 0 && len(conditions) == 0 {
 		return
 	}
 
-	gpuModel, gpuDriverVer := s.getGPUDriverAndModelFunc()
-
 	device := s.buildBasicDevice(node)
-	s.fillGPUDevice(device, gpuDevices, gpuModel, gpuDriverVer)
+	if len(gpuDevices) > 0 {
+		gpuModel, gpuDriverVer := s.getGPUDriverAndModelFunc()
+		s.fillGPUDevice(device, gpuDevices, gpuModel, gpuDriverVer)
+	}
+	device.Status.Conditions = conditions
 
 	err := s.updateDevice(device)
 	if err == nil {
@@ -105,6 +111,10 @@ func (s *statesInformer) fillGPUDevice(device *schedulingv1alpha1.Device,
 	if device.Labels == nil {
 		device.Labels = make(map[string]string)
 	}
+	if len(gpuDevices) > 0 {
+		// the GPUs are collected by NVML
+		device.Labels[extension.LabelGPUVendor] = extension.GPUVendorNVIDIA
+	}
 	if gpuModel != "" {
 		device.Labels[extension.LabelGPUModel] = gpuModel
 	}
@@ -132,9 +142,13 @@ func (s *statesInformer) updateDevice(deviceNew *schedulingv1alpha1.Device) erro
 			return err
 		}
 		sorter(deviceOld.Spec.Devices)
+		// maxPodsPerGPU is managed by the cluster administrator rather than koordlet
+		deviceNew.Spec.MaxPodsPerGPU = deviceOld.Spec.MaxPodsPerGPU
+		deviceNew.Status.Allocations = deviceOld.Status.Allocations
 
 		if apiequality.Semantic.DeepEqual(deviceNew.Spec.Devices, deviceOld.Spec.Devices) &&
-			apiequality.Semantic.DeepEqual(deviceNew.Labels, deviceOld.Labels) {
+			apiequality.Semantic.DeepEqual(deviceNew.Labels, deviceOld.Labels) &&
+			apiequality.Semantic.DeepEqual(deviceNew.Status.Conditions, deviceOld.Status.Conditions) {
 			klog.V(4).Infof("Device %s has not changed and does not need to be updated", deviceNew.Name)
 			return nil
 		}
@@ -351,3 +365,74 @@ func checkHealth(stopCh <-chan struct{}, devs []string, xids chan<- string) {
 		}
 	}
 }
+
+// initDeviceErrors prepares the GPUs for the kernel log watcher and restores the device errors detected before
+// the restart. It must be called before reporting the Device, otherwise the restored conditions get overwritten.
+func (s *statesInformer) initDeviceErrors(gpuAvailable bool) {
+	if gpuAvailable {
+		s.deviceErrors.setGPUs(getGPUBusIDs())
+	}
+	s.restoreDeviceErrors()
+}
+
+// watchKernelLog detects the device errors from the kernel log until stopCh is closed.
+func (s *statesInformer) watchKernelLog(stopCh <-chan struct{}) {
+	records, err := kmsg.NewWatcher(kmsg.KmsgPath).Watch(stopCh)
+	if err != nil {
+		klog.Errorf("failed to watch kernel log %s, err: %v", kmsg.KmsgPath, err)
+		return
+	}
+
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&clientcorev1.EventSinkImpl{Interface: s.option.KubeClient.CoreV1().Events("")})
+	defer eventBroadcaster.Shutdown()
+	recorder := eventBroadcaster.NewRecorder(scheme, corev1.EventSource{Component: "koordlet-Device", Host: s.option.NodeName})
+
+	klog.Info("start to watch kernel log for device errors")
+	for r := range records {
+		if deviceErr := kmsg.ParseDeviceError(r.Message); deviceErr != nil {
+			s.handleDeviceError(deviceErr, recorder)
+		}
+	}
+}
+
+// getGPUBusIDs returns the GPUs indexed by the normalized PCIe bus id.
+func getGPUBusIDs() map[string]gpuIdentity {
+	gpus := map[string]gpuIdentity{}
+	count, ret := nvml.DeviceGetCount()
+	if ret != nvml.SUCCESS {
+		klog.Errorf("unable to get device count: %v", nvml.ErrorString(ret))
+		return gpus
+	}
+	for deviceIndex := 0; deviceIndex < count; deviceIndex++ {
+		gpuDevice, ret := nvml.DeviceGetHandleByIndex(deviceIndex)
+		if ret != nvml.SUCCESS {
+			klog.Errorf("unable to get device at index %d: %v", deviceIndex, nvml.ErrorString(ret))
+			continue
+		}
+		pciInfo, ret := gpuDevice.GetPciInfo()
+		if ret != nvml.SUCCESS {
+			klog.Errorf("failed to get pci info of device at index %d, err: %v", deviceIndex, nvml.ErrorString(ret))
+			continue
+		}
+		minor, ret := gpuDevice.GetMinorNumber()
+		if ret != nvml.SUCCESS {
+			klog.Errorf("failed to get minor of device at index %d, err: %v", deviceIndex, nvml.ErrorString(ret))
+			continue
+		}
+		uuid, ret := gpuDevice.GetUUID()
+		if ret != nvml.SUCCESS {
+			klog.Errorf("failed to get uuid of device at index %d, err: %v", deviceIndex, nvml.ErrorString(ret))
+			continue
+		}
+		busID := make([]byte, 0, len(pciInfo.BusId))
+		for _, c := range pciInfo.BusId {
+			if c == 0 {
+				break
+			}
+			busID = append(busID, byte(c))
+		}
+		gpus[kmsg.NormalizeGPUBusID(string(busID))] = gpuIdentity{minor: int32(minor), uuid: uuid}
+	}
+	return gpus
+}
@@ -32,6 +32,7 @@ import (
 	schedulingfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/kmsg"
 )
 
 func Test_reportGPUDevice(t *testing.T) {
@@ -77,6 +78,7 @@ func Test_reportGPUDevice(t *testing.T) {
 		getGPUDriverAndModelFunc: func() (string, string) {
 			return "A100", "470"
 		},
+		deviceErrors: newDeviceErrorTracker(),
 	}
 	r.reportDevice()
 	expectedDevices := []schedulingv1alpha1.DeviceInfo{
@@ -133,4 +135,17 @@ func Test_reportGPUDevice(t *testing.T) {
 	assert.Equal(t, device.Spec.Devices, expectedDevices)
 	assert.Equal(t, device.Labels[extension.LabelGPUModel], "A100")
 	assert.Equal(t, device.Labels[extension.LabelGPUDriverVersion], "470")
+	assert.Empty(t, device.Status.Conditions)
+
+	// report the device errors detected from the kernel log
+	r.deviceErrors.setGPUs(map[string]gpuIdentity{"0000:3b:00": {minor: 1, uuid: "2"}})
+	r.unhealthyGPU = map[string]struct{}{}
+	r.handleDeviceError(kmsg.ParseDeviceError("NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, GPU has fallen off the bus."), nil)
+	r.reportDevice()
+	device, err = fakeClient.Get(context.TODO(), "test", metav1.GetOptions{})
+	assert.Equal(t, nil, err)
+	assert.False(t, device.Spec.Devices[1].Health)
+	assert.Len(t, device.Status.Conditions, 1)
+	assert.Equal(t, schedulingv1alpha1.DeviceConditionXidError, device.Status.Conditions[0].Type)
+	assert.Equal(t, pointer.Int32(1), device.Status.Conditions[0].Minor)
 }
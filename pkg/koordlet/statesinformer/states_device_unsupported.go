@@ -31,6 +31,14 @@ func (s *statesInformer) gpuHealCheck(stopCh <-chan struct{}) {
 	return
 }
 
+func (s *statesInformer) initDeviceErrors(gpuAvailable bool) {
+	return
+}
+
+func (s *statesInformer) watchKernelLog(stopCh <-chan struct{}) {
+	return
+}
+
 func (s *statesInformer) getGPUDriverAndModel() (string, string) {
 	return "", ""
 }
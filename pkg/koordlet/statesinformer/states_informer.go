@@ -31,6 +31,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	schedv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/scheduling/v1alpha1"
@@ -54,6 +55,9 @@ type StatesInformer interface {
 
 	GetNodeTopo() *topov1alpha1.NodeResourceTopology
 
+	// GetPodDeviceAllocation returns the PodDeviceAllocation storing the devices allocated by a pod on the node.
+	GetPodDeviceAllocation(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error)
+
 	RegisterCallbacks(objType RegisterType, name, description string, callbackFn UpdateCbFn)
 }
 
@@ -82,6 +86,9 @@ type statesInformer struct {
 	deviceClient schedv1alpha1.DeviceInterface
 	unhealthyGPU map[string]struct{}
 	gpuMutex     sync.RWMutex
+	deviceErrors *deviceErrorTracker
+
+	podDeviceAllocations *podDeviceAllocationCache
 
 	option  *pluginOption
 	states  *pluginState
@@ -115,6 +122,9 @@ func NewStatesInformer(config *Config, kubeClient clientset.Interface, crdClient
 		metricsCache: metricsCache,
 		deviceClient: schedulingClient.Devices(),
 		unhealthyGPU: make(map[string]struct{}),
+		deviceErrors: newDeviceErrorTracker(),
+
+		podDeviceAllocations: newPodDeviceAllocationCache(),
 
 		option:  opt,
 		states:  stat,
@@ -152,13 +162,23 @@ func (s *statesInformer) Run(stopCh <-chan struct{}) error {
 	}
 
 	if features.DefaultKoordletFeatureGate.Enabled(features.Accelerators) {
-		go wait.Until(s.reportDevice, s.config.NodeTopologySyncInterval, stopCh)
 		// check is nvml is available
-		if s.initGPU() {
+		gpuAvailable := s.initGPU()
+		if features.DefaultKoordletFeatureGate.Enabled(features.KernelLogWatcher) {
+			// the device errors are restored before the Device is reported
+			s.initDeviceErrors(gpuAvailable)
+			go s.watchKernelLog(stopCh)
+		}
+		go wait.Until(s.reportDevice, s.config.NodeTopologySyncInterval, stopCh)
+		if gpuAvailable {
 			go s.gpuHealCheck(stopCh)
 		}
 	}
 
+	if features.DefaultKoordletFeatureGate.Enabled(features.ContainerCheckpoint) {
+		go s.runPodCheckpointer(stopCh)
+	}
+
 	klog.Infof("start states informer successfully")
 	s.started.Store(true)
 	<-stopCh
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
 	"sync"
 	"time"
@@ -45,6 +46,7 @@ import (
 	clientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
 	clientsetv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1"
 	listerv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
@@ -84,6 +86,7 @@ var (
 
 type nodeMetricInformer struct {
 	reportEnabled      bool
+	config             *Config
 	nodeName           string
 	nodeMetricInformer cache.SharedIndexInformer
 	nodeMetricLister   listerv1alpha1.NodeMetricLister
@@ -95,6 +98,9 @@ type nodeMetricInformer struct {
 
 	rwMutex    sync.RWMutex
 	nodeMetric *slov1alpha1.NodeMetric
+
+	// lastReportedStatus is the status of the last successful report, which is only accessed by the sync worker.
+	lastReportedStatus *slov1alpha1.NodeMetricStatus
 }
 
 func NewNodeMetricInformer() *nodeMetricInformer {
@@ -115,6 +121,7 @@ func (r *nodeMetricInformer) HasSynced() bool {
 
 func (r *nodeMetricInformer) Setup(ctx *pluginOption, state *pluginState) {
 	r.reportEnabled = ctx.config.EnableNodeMetricReport
+	r.config = ctx.config
 	r.nodeName = ctx.NodeName
 	r.nodeMetricInformer = newNodeMetricInformer(ctx.KoordClient, ctx.NodeName)
 	r.nodeMetricLister = listerv1alpha1.NewNodeMetricLister(r.nodeMetricInformer.GetIndexer())
@@ -184,14 +191,14 @@ func (r *nodeMetricInformer) Start(stopCh <-chan struct{}) {
 }
 
 func (r *nodeMetricInformer) syncNodeMetricWorker(stopCh <-chan struct{}) {
-	reportInterval := r.getNodeMetricReportInterval()
+	checkInterval := r.getNodeMetricCheckInterval()
 	for {
 		select {
 		case <-stopCh:
 			return
-		case <-time.After(reportInterval):
+		case <-time.After(checkInterval):
 			r.sync()
-			reportInterval = r.getNodeMetricReportInterval()
+			checkInterval = r.getNodeMetricCheckInterval()
 		}
 	}
 }
@@ -242,6 +249,13 @@ func (r *nodeMetricInformer) sync() {
 		NodeMetric: nodeMetricInfo,
 		PodsMetric: podMetricInfo,
 	}
+	if r.isAdaptiveReportEnabled() {
+		quantizePodsMetric(newStatus.PodsMetric)
+		if !r.needReport(newStatus) {
+			klog.V(5).Infof("node metric has not changed significantly since %v, skip this round", r.lastReportedStatus.UpdateTime)
+			return
+		}
+	}
 	retErr := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		nodeMetric, err := r.nodeMetricLister.Get(r.nodeName)
 		if errors.IsNotFound(err) {
@@ -258,6 +272,7 @@ func (r *nodeMetricInformer) sync() {
 	if retErr != nil {
 		klog.Warningf("update node metric status failed, status %v, err %v", util.DumpJSON(newStatus), retErr)
 	} else {
+		r.lastReportedStatus = newStatus
 		klog.V(4).Infof("update node metric status success, detail: %v", util.DumpJSON(newStatus))
 	}
 }
@@ -319,6 +334,8 @@ func (r *nodeMetricInformer) collectMetric() (*slov1alpha1.NodeMetricInfo, []*sl
 	nodeMetricInfo := &slov1alpha1.NodeMetricInfo{
 		NodeUsage:            r.queryNodeMetric(startTime, endTime, metriccache.AggregationTypeAVG, false),
 		AggregatedNodeUsages: r.collectNodeAggregateMetric(endTime, spec.CollectPolicy.NodeAggregatePolicy),
+		SystemUsage:          r.querySystemMetric(startTime, endTime, metriccache.AggregationTypeAVG),
+		NUMAUsages:           r.queryNUMAMetric(startTime, endTime, metriccache.AggregationTypeAVG),
 	}
 
 	podsMeta := r.podsInformer.GetAllPods()
@@ -365,6 +382,66 @@ func (r *nodeMetricInformer) queryNodeMetric(start time.Time, end time.Time, agg
 	return convertNodeMetricToResourceMap(queryResult.Metric)
 }
 
+func (r *nodeMetricInformer) querySystemMetric(start time.Time, end time.Time, aggregateType metriccache.AggregationType) slov1alpha1.ResourceMap {
+	queryParam := &metriccache.QueryParam{
+		Aggregate: aggregateType,
+		Start:     &start,
+		End:       &end,
+	}
+	queryResult := r.metricCache.GetSystemResourceMetric(queryParam)
+	if queryResult.Error != nil {
+		klog.V(5).Infof("get system resource metric failed, error %v", queryResult.Error)
+		return slov1alpha1.ResourceMap{}
+	}
+	if queryResult.Metric == nil {
+		klog.V(5).Infof("system metric not exist")
+		return slov1alpha1.ResourceMap{}
+	}
+	return slov1alpha1.ResourceMap{
+		ResourceList: corev1.ResourceList{
+			corev1.ResourceCPU:    queryResult.Metric.CPUUsed.CPUUsed,
+			corev1.ResourceMemory: queryResult.Metric.MemoryUsed.MemoryWithoutCache,
+		},
+	}
+}
+
+func (r *nodeMetricInformer) queryNUMAMetric(start time.Time, end time.Time, aggregateType metriccache.AggregationType) []slov1alpha1.NUMAUsage {
+	queryParam := &metriccache.QueryParam{
+		Aggregate: aggregateType,
+		Start:     &start,
+		End:       &end,
+	}
+	queryResult := r.metricCache.GetNUMAResourceMetric(queryParam)
+	if queryResult.Error != nil {
+		klog.V(5).Infof("get NUMA resource metric failed, error %v", queryResult.Error)
+		return nil
+	}
+	numaUsages := make([]slov1alpha1.NUMAUsage, 0, len(queryResult.Metrics))
+	for _, metric := range queryResult.Metrics {
+		usage := corev1.ResourceList{
+			corev1.ResourceCPU:    metric.CPUUsed.CPUUsed,
+			corev1.ResourceMemory: metric.MemoryUsed.MemoryWithoutCache,
+		}
+		free := corev1.ResourceList{
+			corev1.ResourceMemory: metric.MemoryFree,
+		}
+		// the used hugepages are the preallocated pages not free, no matter whether they are charged to any pod
+		for resourceName, total := range metric.HugePagesTotal {
+			hugePagesFree := metric.HugePagesFree[resourceName]
+			hugePagesUsed := total.DeepCopy()
+			hugePagesUsed.Sub(hugePagesFree)
+			usage[resourceName] = hugePagesUsed
+			free[resourceName] = hugePagesFree.DeepCopy()
+		}
+		numaUsages = append(numaUsages, slov1alpha1.NUMAUsage{
+			NUMANodeID: metric.NUMANodeID,
+			Usage:      slov1alpha1.ResourceMap{ResourceList: usage},
+			Free:       slov1alpha1.ResourceMap{ResourceList: free},
+		})
+	}
+	return numaUsages
+}
+
 func metricsInColdStart(queryStart, queryEnd time.Time, queryResult *metriccache.QueryResult) bool {
 	if queryResult == nil || queryResult.AggregateInfo == nil {
 		return true
@@ -409,10 +486,69 @@ func (r *nodeMetricInformer) collectPodMetric(podMeta *PodMeta, queryParam *metr
 		klog.Warningf("pod %v metric not exist", podUID)
 		return nil
 	}
-	return &slov1alpha1.PodMetricInfo{
-		Namespace: podMeta.Pod.Namespace,
-		Name:      podMeta.Pod.Name,
-		PodUsage:  *convertPodMetricToResourceMap(queryResult.Metric),
+	podMetric := &slov1alpha1.PodMetricInfo{
+		Namespace:        podMeta.Pod.Namespace,
+		Name:             podMeta.Pod.Name,
+		PodUsage:         *convertPodMetricToResourceMap(queryResult.Metric),
+		ReclaimableUsage: r.queryPodReclaimableUsage(podUID, queryParam),
+	}
+	if psi := r.queryPodPSI(podUID, queryParam); psi != nil {
+		apiext.SetPodPSI(podMetric, psi)
+	}
+	return podMetric
+}
+
+// queryPodPSI returns the pressure of the pod aggregated from the pod PSI collected by the PSICollector.
+func (r *nodeMetricInformer) queryPodPSI(podUID string, queryParam *metriccache.QueryParam) *apiext.PodPSI {
+	if !features.DefaultKoordletFeatureGate.Enabled(features.PSICollector) {
+		return nil
+	}
+	queryResult := r.metricCache.GetPodInterferenceMetric(metriccache.MetricNamePodPSI, &podUID, queryParam)
+	if queryResult.Error != nil || queryResult.Metric == nil {
+		klog.V(5).Infof("get pod %v psi metric failed, error %v", podUID, queryResult.Error)
+		return nil
+	}
+	psi, ok := queryResult.Metric.MetricValue.(*metriccache.PSIMetric)
+	if !ok {
+		return nil
+	}
+	podPSI := &apiext.PodPSI{
+		SomeCPU:    roundPercentage(psi.SomeCPUAvg10),
+		SomeMemory: roundPercentage(psi.SomeMemAvg10),
+		SomeIO:     roundPercentage(psi.SomeIOAvg10),
+		FullMemory: roundPercentage(psi.FullMemAvg10),
+		FullIO:     roundPercentage(psi.FullIOAvg10),
+	}
+	if psi.CPUFullSupported {
+		podPSI.FullCPU = roundPercentage(psi.FullCPUAvg10)
+	}
+	return podPSI
+}
+
+// roundPercentage keeps two decimals of the percentage, which is the precision of the kernel PSI.
+func roundPercentage(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// queryPodReclaimableUsage returns the usage of the pod which can be reclaimed, i.e. the cold memory collected by the
+// ColdMemoryCollector.
+func (r *nodeMetricInformer) queryPodReclaimableUsage(podUID string, queryParam *metriccache.QueryParam) slov1alpha1.ResourceMap {
+	if !features.DefaultKoordletFeatureGate.Enabled(features.ColdMemoryCollector) {
+		return slov1alpha1.ResourceMap{}
+	}
+	queryResult := r.metricCache.GetPodInterferenceMetric(metriccache.MetricNamePodColdMemory, &podUID, queryParam)
+	if queryResult.Error != nil || queryResult.Metric == nil {
+		klog.V(5).Infof("get pod %v cold memory metric failed, error %v", podUID, queryResult.Error)
+		return slov1alpha1.ResourceMap{}
+	}
+	coldMemory, ok := queryResult.Metric.MetricValue.(*metriccache.ColdMemoryMetric)
+	if !ok {
+		return slov1alpha1.ResourceMap{}
+	}
+	return slov1alpha1.ResourceMap{
+		ResourceList: corev1.ResourceList{
+			corev1.ResourceMemory: *resource.NewQuantity(int64(coldMemory.ColdAnonBytes), resource.BinarySI),
+		},
 	}
 }
 
@@ -443,6 +579,8 @@ func (su *statusUpdater) updateStatus(nodeMetric *slov1alpha1.NodeMetric, newSta
 
 	newNodeMetric := nodeMetric.DeepCopy()
 	newNodeMetric.Status = *newStatus
+	// the conditions are maintained by koord-manager
+	newNodeMetric.Status.Conditions = nodeMetric.Status.Conditions
 
 	_, err := su.nodeMetricClient.UpdateStatus(context.TODO(), newNodeMetric, metav1.UpdateOptions{})
 	su.previousTimestamp = time.Now()
@@ -453,10 +591,12 @@ func convertNodeMetricToResourceMap(nodeMetric *metriccache.NodeResourceMetric)
 	var deviceInfos []schedulingv1alpha1.DeviceInfo
 	if len(nodeMetric.GPUs) > 0 {
 		for _, gpu := range nodeMetric.GPUs {
+			// copy the minor since the loop variable is reused
+			minor := gpu.Minor
 			memoryRatioRaw := 100 * float64(gpu.MemoryUsed.Value()) / float64(gpu.MemoryTotal.Value())
 			gpuInfo := schedulingv1alpha1.DeviceInfo{
 				UUID:  gpu.DeviceUUID,
-				Minor: &gpu.Minor,
+				Minor: &minor,
 				Type:  schedulingv1alpha1.GPU,
 				// TODO: how to check the health status of GPU
 				Resources: map[corev1.ResourceName]resource.Quantity{
@@ -468,12 +608,17 @@ func convertNodeMetricToResourceMap(nodeMetric *metriccache.NodeResourceMetric)
 			deviceInfos = append(deviceInfos, gpuInfo)
 		}
 	}
+	resourceList := corev1.ResourceList{
+		corev1.ResourceCPU:    nodeMetric.CPUUsed.CPUUsed,
+		corev1.ResourceMemory: nodeMetric.MemoryUsed.MemoryWithoutCache,
+	}
+	if bandwidth := getNetBandwidthUsed(&nodeMetric.NetworkUsed); !bandwidth.IsZero() {
+		resourceList[apiext.ResourceNetBandwidth] = bandwidth
+	}
+	fillDiskIOUsed(resourceList, &nodeMetric.DiskIOUsed)
 	return slov1alpha1.ResourceMap{
-		ResourceList: corev1.ResourceList{
-			corev1.ResourceCPU:    nodeMetric.CPUUsed.CPUUsed,
-			corev1.ResourceMemory: nodeMetric.MemoryUsed.MemoryWithoutCache,
-		},
-		Devices: deviceInfos,
+		ResourceList: resourceList,
+		Devices:      deviceInfos,
 	}
 }
 
@@ -481,10 +626,12 @@ func convertPodMetricToResourceMap(podMetric *metriccache.PodResourceMetric) *sl
 	var deviceInfos []schedulingv1alpha1.DeviceInfo
 	if len(podMetric.GPUs) > 0 {
 		for _, gpu := range podMetric.GPUs {
+			// copy the minor since the loop variable is reused
+			minor := gpu.Minor
 			memoryRatioRaw := 100 * float64(gpu.MemoryUsed.Value()) / float64(gpu.MemoryTotal.Value())
 			gpuInfo := schedulingv1alpha1.DeviceInfo{
 				UUID:  gpu.DeviceUUID,
-				Minor: &gpu.Minor,
+				Minor: &minor,
 				Type:  schedulingv1alpha1.GPU,
 				// TODO: how to check the health status of GPU
 				Resources: map[corev1.ResourceName]resource.Quantity{
@@ -496,11 +643,42 @@ func convertPodMetricToResourceMap(podMetric *metriccache.PodResourceMetric) *sl
 			deviceInfos = append(deviceInfos, gpuInfo)
 		}
 	}
+	resourceList := corev1.ResourceList{
+		corev1.ResourceCPU:    podMetric.CPUUsed.CPUUsed,
+		corev1.ResourceMemory: podMetric.MemoryUsed.MemoryWithoutCache,
+	}
+	if bandwidth := getNetBandwidthUsed(&podMetric.NetworkUsed); !bandwidth.IsZero() {
+		resourceList[apiext.ResourceNetBandwidth] = bandwidth
+	}
+	fillDiskIOUsed(resourceList, &podMetric.DiskIOUsed)
+	for resourceName, q := range podMetric.HugePagesUsed {
+		resourceList[resourceName] = q.DeepCopy()
+	}
 	return &slov1alpha1.ResourceMap{
-		ResourceList: corev1.ResourceList{
-			corev1.ResourceCPU:    podMetric.CPUUsed.CPUUsed,
-			corev1.ResourceMemory: podMetric.MemoryUsed.MemoryWithoutCache,
-		},
-		Devices: deviceInfos,
+		ResourceList: resourceList,
+		Devices:      deviceInfos,
+	}
+}
+
+// getNetBandwidthUsed returns the used network bandwidth in bits per second, which is the greater one of the received
+// and the transmitted traffic, since the NICs are full-duplex and the net-bandwidth resource counts a single direction.
+func getNetBandwidthUsed(networkMetric *metriccache.NetworkMetric) resource.Quantity {
+	bytesPerSec := networkMetric.RxBytesPerSec.Value()
+	if txBytesPerSec := networkMetric.TxBytesPerSec.Value(); txBytesPerSec > bytesPerSec {
+		bytesPerSec = txBytesPerSec
+	}
+	return *resource.NewQuantity(bytesPerSec*8, resource.DecimalSI)
+}
+
+// fillDiskIOUsed adds the disk IO throughput in bytes per second and the IOPS into the resource list if any disk IO
+// is collected. Unlike the NICs, the reads and the writes share the disk, so they are summed up.
+func fillDiskIOUsed(resourceList corev1.ResourceList, diskIOMetric *metriccache.DiskIOMetric) {
+	throughput := diskIOMetric.ReadBytesPerSec.Value() + diskIOMetric.WriteBytesPerSec.Value()
+	if throughput > 0 {
+		resourceList[apiext.ResourceDiskIOThroughput] = *resource.NewQuantity(throughput, resource.DecimalSI)
+	}
+	iops := diskIOMetric.ReadIOPS.Value() + diskIOMetric.WriteIOPS.Value()
+	if iops > 0 {
+		resourceList[apiext.ResourceDiskIOPS] = *resource.NewQuantity(iops, resource.DecimalSI)
 	}
 }
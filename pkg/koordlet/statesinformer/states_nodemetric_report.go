@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+var (
+	// the node usage changes below the floors are never significant, which avoids the frequent reports of the idle
+	// nodes whose usages jitter at a low level
+	minSignificantCPUChange    = resource.NewMilliQuantity(100, resource.DecimalSI)
+	minSignificantMemoryChange = resource.NewQuantity(100*1024*1024, resource.BinarySI)
+
+	// the pod usages are quantized to the steps in the adaptive report, e.g. 1234567890 bytes is reported as 1177Mi,
+	// which shortens the status and filters out the noises
+	podCPUUsageStepMilli = int64(10)
+	podMemoryUsageStep   = int64(1024 * 1024)
+)
+
+func (r *nodeMetricInformer) isAdaptiveReportEnabled() bool {
+	return features.DefaultKoordletFeatureGate.Enabled(features.AdaptiveNodeMetricReport) &&
+		r.config != nil && r.config.NodeMetricReportMaxInterval > 0
+}
+
+// getNodeMetricCheckInterval returns the interval to check if the node metric should be reported. In the adaptive
+// report, it is half of the report interval so that the significant changes are reported sooner, while the other
+// reports are still at least the report interval apart.
+func (r *nodeMetricInformer) getNodeMetricCheckInterval() time.Duration {
+	reportInterval := r.getNodeMetricReportInterval()
+	if !r.isAdaptiveReportEnabled() {
+		return reportInterval
+	}
+	checkInterval := reportInterval / 2
+	if minInterval := time.Duration(minReportIntervalSeconds) * time.Second; checkInterval < minInterval {
+		checkInterval = minInterval
+	}
+	return checkInterval
+}
+
+// needReport checks if the new status should be reported in the adaptive report. The significant changes of the node
+// usages are reported at once, the pods added or removed are reported no sooner than the report interval since the
+// last report, and the others are not reported until the max interval.
+func (r *nodeMetricInformer) needReport(newStatus *slov1alpha1.NodeMetricStatus) bool {
+	lastStatus := r.lastReportedStatus
+	if lastStatus == nil || lastStatus.UpdateTime == nil || newStatus.UpdateTime == nil {
+		return true
+	}
+	elapsed := newStatus.UpdateTime.Sub(lastStatus.UpdateTime.Time)
+	if elapsed >= r.config.NodeMetricReportMaxInterval {
+		return true
+	}
+	if isNodeUsageChanged(lastStatus, newStatus, r.config.NodeMetricReportChangeThreshold) {
+		return true
+	}
+	return elapsed >= r.getNodeMetricReportInterval() && isPodsMetricChanged(lastStatus, newStatus)
+}
+
+// isNodeUsageChanged checks if the node cpu or memory usage changes over the threshold ratio.
+func isNodeUsageChanged(oldStatus, newStatus *slov1alpha1.NodeMetricStatus, threshold float64) bool {
+	if (oldStatus.NodeMetric == nil) != (newStatus.NodeMetric == nil) {
+		return true
+	}
+	if newStatus.NodeMetric == nil {
+		return false
+	}
+	oldUsage, newUsage := oldStatus.NodeMetric.NodeUsage.ResourceList, newStatus.NodeMetric.NodeUsage.ResourceList
+	return isQuantityChanged(oldUsage.Cpu(), newUsage.Cpu(), minSignificantCPUChange, threshold) ||
+		isQuantityChanged(oldUsage.Memory(), newUsage.Memory(), minSignificantMemoryChange, threshold)
+}
+
+// isPodsMetricChanged checks if any pod is added or removed.
+func isPodsMetricChanged(oldStatus, newStatus *slov1alpha1.NodeMetricStatus) bool {
+	if len(oldStatus.PodsMetric) != len(newStatus.PodsMetric) {
+		return true
+	}
+	oldPods := make(map[string]struct{}, len(oldStatus.PodsMetric))
+	for _, podMetric := range oldStatus.PodsMetric {
+		oldPods[podMetric.Namespace+"/"+podMetric.Name] = struct{}{}
+	}
+	for _, podMetric := range newStatus.PodsMetric {
+		if _, ok := oldPods[podMetric.Namespace+"/"+podMetric.Name]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isQuantityChanged(oldValue, newValue, minChange *resource.Quantity, threshold float64) bool {
+	diff := newValue.DeepCopy()
+	diff.Sub(*oldValue)
+	if diff.Sign() < 0 {
+		diff.Neg()
+	}
+	if diff.Cmp(*minChange) < 0 {
+		return false
+	}
+	return float64(diff.MilliValue()) > float64(oldValue.MilliValue())*threshold
+}
+
+// quantizePodsMetric rounds the cpu and memory usages of the pods to the steps.
+func quantizePodsMetric(podsMetric []*slov1alpha1.PodMetricInfo) {
+	for _, podMetric := range podsMetric {
+		usage := podMetric.PodUsage.ResourceList
+		if q, ok := usage[corev1.ResourceCPU]; ok {
+			usage[corev1.ResourceCPU] = *resource.NewMilliQuantity(roundToStep(q.MilliValue(), podCPUUsageStepMilli), resource.DecimalSI)
+		}
+		if q, ok := usage[corev1.ResourceMemory]; ok {
+			usage[corev1.ResourceMemory] = *resource.NewQuantity(roundToStep(q.Value(), podMemoryUsageStep), resource.BinarySI)
+		}
+	}
+}
+
+func roundToStep(value, step int64) int64 {
+	return (value + step/2) / step * step
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+)
+
+func Test_nodeMetricInformer_adaptiveReport(t *testing.T) {
+	now := time.Now()
+	newStatus := func(seconds int, cpu, memory string, pods ...string) *slov1alpha1.NodeMetricStatus {
+		status := &slov1alpha1.NodeMetricStatus{
+			UpdateTime: &metav1.Time{Time: now.Add(time.Duration(seconds) * time.Second)},
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			},
+		}
+		for _, pod := range pods {
+			status.PodsMetric = append(status.PodsMetric, &slov1alpha1.PodMetricInfo{Namespace: "default", Name: pod})
+		}
+		return status
+	}
+
+	r := &nodeMetricInformer{
+		config: NewDefaultConfig(),
+		nodeMetric: &slov1alpha1.NodeMetric{
+			Spec: slov1alpha1.NodeMetricSpec{
+				CollectPolicy: &slov1alpha1.NodeMetricCollectPolicy{ReportIntervalSeconds: pointer.Int64(60)},
+			},
+		},
+	}
+	assert.False(t, r.isAdaptiveReportEnabled())
+	assert.Equal(t, 60*time.Second, r.getNodeMetricCheckInterval())
+
+	assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(map[string]bool{string(features.AdaptiveNodeMetricReport): true}))
+	defer func() {
+		assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(map[string]bool{string(features.AdaptiveNodeMetricReport): false}))
+	}()
+	assert.True(t, r.isAdaptiveReportEnabled())
+	assert.Equal(t, 30*time.Second, r.getNodeMetricCheckInterval())
+
+	tests := []struct {
+		name   string
+		last   *slov1alpha1.NodeMetricStatus
+		status *slov1alpha1.NodeMetricStatus
+		want   bool
+	}{
+		{
+			name:   "never reported",
+			status: newStatus(0, "4", "8Gi", "a"),
+			want:   true,
+		},
+		{
+			name:   "stable usages",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(30, "4200m", "8500Mi", "a"),
+			want:   false,
+		},
+		{
+			name:   "stable usages over the max interval",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(150, "4", "8Gi", "a"),
+			want:   true,
+		},
+		{
+			name:   "significant cpu change",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(30, "5", "8Gi", "a"),
+			want:   true,
+		},
+		{
+			name:   "significant memory change",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(30, "4", "6Gi", "a"),
+			want:   true,
+		},
+		{
+			name:   "small change of the idle node",
+			last:   newStatus(0, "100m", "100Mi", "a"),
+			status: newStatus(30, "180m", "180Mi", "a"),
+			want:   false,
+		},
+		{
+			name:   "pod replaced within the report interval",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(30, "4", "8Gi", "b"),
+			want:   false,
+		},
+		{
+			name:   "pod added within the report interval",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(30, "4", "8Gi", "a", "b"),
+			want:   false,
+		},
+		{
+			name:   "pod added over the report interval",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(60, "4", "8Gi", "a", "b"),
+			want:   true,
+		},
+		{
+			name:   "stable usages and pods over the report interval",
+			last:   newStatus(0, "4", "8Gi", "a"),
+			status: newStatus(90, "4", "8Gi", "a"),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.lastReportedStatus = tt.last
+			assert.Equal(t, tt.want, r.needReport(tt.status))
+		})
+	}
+}
+
+func Test_quantizePodsMetric(t *testing.T) {
+	podsMetric := []*slov1alpha1.PodMetricInfo{
+		{
+			Name: "test-pod",
+			PodUsage: slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU:    *resource.NewMilliQuantity(1234, resource.DecimalSI),
+					corev1.ResourceMemory: *resource.NewQuantity(1234567890, resource.BinarySI),
+				},
+			},
+		},
+		{
+			Name: "idle-pod",
+			PodUsage: slov1alpha1.ResourceMap{
+				ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU: *resource.NewMilliQuantity(4, resource.DecimalSI),
+				},
+			},
+		},
+	}
+	quantizePodsMetric(podsMetric)
+	cpu, memory := podsMetric[0].PodUsage.ResourceList[corev1.ResourceCPU], podsMetric[0].PodUsage.ResourceList[corev1.ResourceMemory]
+	assert.Equal(t, "1230m", cpu.String())
+	assert.Equal(t, "1177Mi", memory.String())
+	idleCPU := podsMetric[1].PodUsage.ResourceList[corev1.ResourceCPU]
+	assert.True(t, idleCPU.IsZero())
+	_, hasMemory := podsMetric[1].PodUsage.ResourceList[corev1.ResourceMemory]
+	assert.False(t, hasMemory)
+}
@@ -18,6 +18,7 @@ package statesinformer
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -34,11 +35,13 @@ import (
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/utils/pointer"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	fakekoordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
 	clientsetv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1"
 	fakeclientslov1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1/fake"
 	listerv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mockmetriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
 )
@@ -167,6 +170,8 @@ func Test_reporter_sync_with_single_node_metric(t *testing.T) {
 		fields           fields
 		wantNilStatus    bool
 		wantNodeResource slov1alpha1.ResourceMap
+		wantSystemUsage  slov1alpha1.ResourceMap
+		wantNUMAUsages   []slov1alpha1.NUMAUsage
 		wantPodsMetric   []*slov1alpha1.PodMetricInfo
 		wantErr          bool
 	}{
@@ -236,6 +241,46 @@ func Test_reporter_sync_with_single_node_metric(t *testing.T) {
 							},
 						},
 					}).AnyTimes()
+					c.EXPECT().GetSystemResourceMetric(gomock.Any()).Return(metriccache.SystemResourceQueryResult{
+						Metric: &metriccache.SystemResourceMetric{
+							CPUUsed: metriccache.CPUMetric{
+								CPUUsed: resource.MustParse("2"),
+							},
+							MemoryUsed: metriccache.MemoryMetric{
+								MemoryWithoutCache: resource.MustParse("2Gi"),
+							},
+						},
+					}).Times(1)
+					c.EXPECT().GetNUMAResourceMetric(gomock.Any()).Return(metriccache.NUMAResourceQueryResult{
+						Metrics: []*metriccache.NUMAResourceMetric{
+							{
+								NUMANodeID: 0,
+								CPUUsed: metriccache.CPUMetric{
+									CPUUsed: resource.MustParse("10"),
+								},
+								MemoryUsed: metriccache.MemoryMetric{
+									MemoryWithoutCache: resource.MustParse("20Gi"),
+								},
+								MemoryFree: resource.MustParse("12Gi"),
+								HugePagesTotal: v1.ResourceList{
+									"hugepages-2Mi": resource.MustParse("1Gi"),
+								},
+								HugePagesFree: v1.ResourceList{
+									"hugepages-2Mi": resource.MustParse("256Mi"),
+								},
+							},
+							{
+								NUMANodeID: 1,
+								CPUUsed: metriccache.CPUMetric{
+									CPUUsed: resource.MustParse("10"),
+								},
+								MemoryUsed: metriccache.MemoryMetric{
+									MemoryWithoutCache: resource.MustParse("28Gi"),
+								},
+								MemoryFree: resource.MustParse("4Gi"),
+							},
+						},
+					}).Times(1)
 					c.EXPECT().GetPodResourceMetric(gomock.Any(), gomock.Any()).Return(metriccache.PodResourceQueryResult{
 						Metric: &metriccache.PodResourceMetric{
 							PodUID: "test-pod",
@@ -320,6 +365,44 @@ func Test_reporter_sync_with_single_node_metric(t *testing.T) {
 					},
 				},
 			}),
+			wantSystemUsage: slov1alpha1.ResourceMap{
+				ResourceList: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("2"),
+					v1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+			},
+			wantNUMAUsages: []slov1alpha1.NUMAUsage{
+				{
+					NUMANodeID: 0,
+					Usage: slov1alpha1.ResourceMap{
+						ResourceList: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("10"),
+							v1.ResourceMemory: resource.MustParse("20Gi"),
+							"hugepages-2Mi":   resource.MustParse("768Mi"),
+						},
+					},
+					Free: slov1alpha1.ResourceMap{
+						ResourceList: v1.ResourceList{
+							v1.ResourceMemory: resource.MustParse("12Gi"),
+							"hugepages-2Mi":   resource.MustParse("256Mi"),
+						},
+					},
+				},
+				{
+					NUMANodeID: 1,
+					Usage: slov1alpha1.ResourceMap{
+						ResourceList: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("10"),
+							v1.ResourceMemory: resource.MustParse("28Gi"),
+						},
+					},
+					Free: slov1alpha1.ResourceMap{
+						ResourceList: v1.ResourceList{
+							v1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
 			wantPodsMetric: []*slov1alpha1.PodMetricInfo{
 				{
 					Name:      "test-pod",
@@ -375,6 +458,12 @@ func Test_reporter_sync_with_single_node_metric(t *testing.T) {
 							},
 						},
 					}).AnyTimes()
+					c.EXPECT().GetSystemResourceMetric(gomock.Any()).Return(metriccache.SystemResourceQueryResult{
+						QueryResult: metriccache.QueryResult{Error: fmt.Errorf("system metric not exist")},
+					}).AnyTimes()
+					c.EXPECT().GetNUMAResourceMetric(gomock.Any()).Return(metriccache.NUMAResourceQueryResult{
+						QueryResult: metriccache.QueryResult{Error: fmt.Errorf("NUMA metric not exist")},
+					}).AnyTimes()
 					return c
 				},
 				podsInformer: NewPodsInformer(),
@@ -426,6 +515,8 @@ func Test_reporter_sync_with_single_node_metric(t *testing.T) {
 					assert.Nil(t, nodeMetric.Status.PodsMetric)
 				} else {
 					assert.Equal(t, tt.wantNodeResource, nodeMetric.Status.NodeMetric.NodeUsage)
+					assert.Equal(t, tt.wantSystemUsage, nodeMetric.Status.NodeMetric.SystemUsage)
+					assert.Equal(t, tt.wantNUMAUsages, nodeMetric.Status.NodeMetric.NUMAUsages)
 					assert.Equal(t, tt.wantPodsMetric, nodeMetric.Status.PodsMetric)
 				}
 			}
@@ -821,3 +912,243 @@ func Test_metricsInColdStart(t *testing.T) {
 		})
 	}
 }
+
+func Test_convertNodeMetricToResourceMap_GPUs(t *testing.T) {
+	nodeMetric := &metriccache.NodeResourceMetric{
+		GPUs: []metriccache.GPUMetric{
+			{
+				Minor:       0,
+				DeviceUUID:  "gpu-0",
+				SMUtil:      40,
+				MemoryUsed:  resource.MustParse("4Gi"),
+				MemoryTotal: resource.MustParse("16Gi"),
+			},
+			{
+				Minor:       1,
+				DeviceUUID:  "gpu-1",
+				SMUtil:      80,
+				MemoryUsed:  resource.MustParse("8Gi"),
+				MemoryTotal: resource.MustParse("16Gi"),
+			},
+		},
+	}
+	got := convertNodeMetricToResourceMap(nodeMetric)
+	assert.Len(t, got.Devices, 2)
+	for i, device := range got.Devices {
+		assert.Equal(t, int32(i), *device.Minor)
+	}
+	gpuMemoryRatio := got.Devices[1].Resources[apiext.ResourceGPUMemoryRatio]
+	assert.Equal(t, int64(50), gpuMemoryRatio.Value())
+}
+
+func Test_convertMetricToResourceMap_NetBandwidth(t *testing.T) {
+	nodeMetric := &metriccache.NodeResourceMetric{
+		NetworkUsed: metriccache.NetworkMetric{
+			RxBytesPerSec: *resource.NewQuantity(1000, resource.DecimalSI),
+			TxBytesPerSec: *resource.NewQuantity(3000, resource.DecimalSI),
+		},
+	}
+	got := convertNodeMetricToResourceMap(nodeMetric)
+	assert.Equal(t, int64(24000), got.ResourceList.Name(apiext.ResourceNetBandwidth, resource.DecimalSI).Value())
+
+	podMetric := &metriccache.PodResourceMetric{
+		NetworkUsed: metriccache.NetworkMetric{
+			RxBytesPerSec: *resource.NewQuantity(2000, resource.DecimalSI),
+			TxBytesPerSec: *resource.NewQuantity(500, resource.DecimalSI),
+		},
+	}
+	gotPod := convertPodMetricToResourceMap(podMetric)
+	assert.Equal(t, int64(16000), gotPod.ResourceList.Name(apiext.ResourceNetBandwidth, resource.DecimalSI).Value())
+
+	// the bandwidth is not reported if no network usage is collected
+	got = convertNodeMetricToResourceMap(&metriccache.NodeResourceMetric{})
+	_, ok := got.ResourceList[apiext.ResourceNetBandwidth]
+	assert.False(t, ok)
+}
+
+func Test_convertMetricToResourceMap_DiskIO(t *testing.T) {
+	nodeMetric := &metriccache.NodeResourceMetric{
+		DiskIOUsed: metriccache.DiskIOMetric{
+			ReadBytesPerSec:  *resource.NewQuantity(4096, resource.DecimalSI),
+			WriteBytesPerSec: *resource.NewQuantity(8192, resource.DecimalSI),
+			ReadIOPS:         *resource.NewQuantity(10, resource.DecimalSI),
+			WriteIOPS:        *resource.NewQuantity(20, resource.DecimalSI),
+		},
+	}
+	got := convertNodeMetricToResourceMap(nodeMetric)
+	assert.Equal(t, int64(12288), got.ResourceList.Name(apiext.ResourceDiskIOThroughput, resource.DecimalSI).Value())
+	assert.Equal(t, int64(30), got.ResourceList.Name(apiext.ResourceDiskIOPS, resource.DecimalSI).Value())
+
+	podMetric := &metriccache.PodResourceMetric{
+		DiskIOUsed: metriccache.DiskIOMetric{
+			WriteBytesPerSec: *resource.NewQuantity(1024, resource.DecimalSI),
+			WriteIOPS:        *resource.NewQuantity(1, resource.DecimalSI),
+		},
+	}
+	gotPod := convertPodMetricToResourceMap(podMetric)
+	assert.Equal(t, int64(1024), gotPod.ResourceList.Name(apiext.ResourceDiskIOThroughput, resource.DecimalSI).Value())
+	assert.Equal(t, int64(1), gotPod.ResourceList.Name(apiext.ResourceDiskIOPS, resource.DecimalSI).Value())
+
+	// the disk io is not reported if no disk io is collected
+	got = convertNodeMetricToResourceMap(&metriccache.NodeResourceMetric{})
+	_, ok := got.ResourceList[apiext.ResourceDiskIOThroughput]
+	assert.False(t, ok)
+	_, ok = got.ResourceList[apiext.ResourceDiskIOPS]
+	assert.False(t, ok)
+}
+
+func Test_convertPodMetricToResourceMap_HugePages(t *testing.T) {
+	podMetric := &metriccache.PodResourceMetric{
+		HugePagesUsed: v1.ResourceList{
+			"hugepages-2Mi": resource.MustParse("8Mi"),
+		},
+	}
+	got := convertPodMetricToResourceMap(podMetric)
+	assert.Equal(t, resource.MustParse("8Mi"), got.ResourceList["hugepages-2Mi"])
+
+	// the hugepages are not reported if the pod uses none
+	got = convertPodMetricToResourceMap(&metriccache.PodResourceMetric{})
+	_, ok := got.ResourceList["hugepages-2Mi"]
+	assert.False(t, ok)
+}
+
+func Test_nodeMetricInformer_collectPodMetric_ReclaimableUsage(t *testing.T) {
+	enabled := features.DefaultKoordletFeatureGate.Enabled(features.ColdMemoryCollector)
+	testFeatureGates := map[string]bool{string(features.ColdMemoryCollector): true}
+	assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(testFeatureGates))
+	defer func() {
+		testFeatureGates[string(features.ColdMemoryCollector)] = enabled
+		assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(testFeatureGates))
+	}()
+
+	end := time.Now()
+	start := end.Add(-defaultAggregateDurationSeconds * time.Second)
+	queryParam := &metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeAVG,
+		Start:     &start,
+		End:       &end,
+	}
+	podMeta := &PodMeta{
+		Pod: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+				UID:       "test-pod-uid",
+			},
+		},
+	}
+	podUID := string(podMeta.Pod.UID)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	c := mockmetriccache.NewMockMetricCache(ctrl)
+	c.EXPECT().GetPodResourceMetric(&podUID, queryParam).Return(metriccache.PodResourceQueryResult{
+		Metric: &metriccache.PodResourceMetric{
+			PodUID: podUID,
+			MemoryUsed: metriccache.MemoryMetric{
+				MemoryWithoutCache: resource.MustParse("1Gi"),
+			},
+		},
+	}).Times(2)
+	c.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodColdMemory, &podUID, queryParam).Return(
+		metriccache.PodInterferenceQueryResult{
+			Metric: &metriccache.PodInterferenceMetric{
+				MetricName:  metriccache.MetricNamePodColdMemory,
+				PodUID:      podUID,
+				MetricValue: &metriccache.ColdMemoryMetric{ColdAnonBytes: 256 * 1024 * 1024},
+			},
+		})
+	c.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodColdMemory, &podUID, queryParam).Return(
+		metriccache.PodInterferenceQueryResult{
+			QueryResult: metriccache.QueryResult{Error: fmt.Errorf("no cold memory metric")},
+		})
+	r := &nodeMetricInformer{metricCache: c}
+
+	got := r.collectPodMetric(podMeta, queryParam)
+	assert.Equal(t, resource.MustParse("1Gi"), got.PodUsage.ResourceList[v1.ResourceMemory])
+	assert.Equal(t, slov1alpha1.ResourceMap{
+		ResourceList: v1.ResourceList{
+			v1.ResourceMemory: *resource.NewQuantity(256*1024*1024, resource.BinarySI),
+		},
+	}, got.ReclaimableUsage)
+
+	// the pod without the cold memory collected has no reclaimable usage
+	got = r.collectPodMetric(podMeta, queryParam)
+	assert.Equal(t, slov1alpha1.ResourceMap{}, got.ReclaimableUsage)
+}
+
+func Test_nodeMetricInformer_collectPodMetric_PSI(t *testing.T) {
+	enabled := features.DefaultKoordletFeatureGate.Enabled(features.PSICollector)
+	testFeatureGates := map[string]bool{string(features.PSICollector): true}
+	assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(testFeatureGates))
+	defer func() {
+		testFeatureGates[string(features.PSICollector)] = enabled
+		assert.NoError(t, features.DefaultMutableKoordletFeatureGate.SetFromMap(testFeatureGates))
+	}()
+
+	end := time.Now()
+	start := end.Add(-defaultAggregateDurationSeconds * time.Second)
+	queryParam := &metriccache.QueryParam{
+		Aggregate: metriccache.AggregationTypeAVG,
+		Start:     &start,
+		End:       &end,
+	}
+	podMeta := &PodMeta{
+		Pod: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-pod",
+				Namespace: "default",
+				UID:       "test-pod-uid",
+			},
+		},
+	}
+	podUID := string(podMeta.Pod.UID)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	c := mockmetriccache.NewMockMetricCache(ctrl)
+	c.EXPECT().GetPodResourceMetric(&podUID, queryParam).Return(metriccache.PodResourceQueryResult{
+		Metric: &metriccache.PodResourceMetric{
+			PodUID: podUID,
+			CPUUsed: metriccache.CPUMetric{
+				CPUUsed: resource.MustParse("2"),
+			},
+		},
+	}).Times(2)
+	c.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodPSI, &podUID, queryParam).Return(
+		metriccache.PodInterferenceQueryResult{
+			Metric: &metriccache.PodInterferenceMetric{
+				MetricName: metriccache.MetricNamePodPSI,
+				PodUID:     podUID,
+				MetricValue: &metriccache.PSIMetric{
+					SomeCPUAvg10: 23.456,
+					SomeMemAvg10: 1.5,
+					SomeIOAvg10:  0.333,
+					FullCPUAvg10: 10,
+					FullMemAvg10: 0.5,
+					FullIOAvg10:  0.1,
+				},
+			},
+		})
+	c.EXPECT().GetPodInterferenceMetric(metriccache.MetricNamePodPSI, &podUID, queryParam).Return(
+		metriccache.PodInterferenceQueryResult{
+			QueryResult: metriccache.QueryResult{Error: fmt.Errorf("no psi metric")},
+		})
+	r := &nodeMetricInformer{metricCache: c}
+
+	got := r.collectPodMetric(podMeta, queryParam)
+	psi, err := apiext.GetPodPSI(got)
+	assert.NoError(t, err)
+	// the cpu full pressure is dropped since the kernel does not support it
+	assert.Equal(t, &apiext.PodPSI{
+		SomeCPU:    23.46,
+		SomeMemory: 1.5,
+		SomeIO:     0.33,
+		FullMemory: 0.5,
+		FullIO:     0.1,
+	}, psi)
+
+	// the pod without the psi collected has no psi extension
+	got = r.collectPodMetric(podMeta, queryParam)
+	assert.Nil(t, got.Extensions)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"reflect"
 	"sort"
@@ -32,6 +33,7 @@ import (
 	topologylister "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/listers/topology/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,7 +42,6 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager"
-	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/features"
@@ -53,6 +54,16 @@ import (
 
 const (
 	nodeTopoInformerName pluginName = "nodeTopoInformer"
+
+	// numaNodeZoneType is the type of the zones of the NUMA nodes, which are named as node-<id>.
+	numaNodeZoneType = "Node"
+	// placeholderZoneName and placeholderZoneType are the zone created with the topology since the zones are required.
+	placeholderZoneName = "fake-name"
+	placeholderZoneType = "fake-type"
+
+	// zoneAvailableChangeRatio is the ratio of the capacity that the available resources of a zone must change by to
+	// sync the topology, so the fluctuating free memory does not update the topology at every sync.
+	zoneAvailableChangeRatio = 0.05
 )
 
 type nodeTopoInformer struct {
@@ -277,11 +288,7 @@ func (s *nodeTopoInformer) calcNodeTopo() (map[string]string, error) {
 }
 
 func (s *nodeTopoInformer) calGuaranteedCpu(usedCPUs map[int32]*extension.CPUInfo, stateJSON string) ([]extension.PodCPUAlloc, error) {
-	if stateJSON == "" {
-		return nil, fmt.Errorf("empty state file")
-	}
-	checkpoint := &state.CPUManagerCheckpoint{}
-	err := json.Unmarshal([]byte(stateJSON), checkpoint)
+	podCPUs, err := kubelet.ParseCPUManagerCheckpoint([]byte(stateJSON))
 	if err != nil {
 		return nil, err
 	}
@@ -305,31 +312,19 @@ func (s *nodeTopoInformer) calGuaranteedCpu(usedCPUs map[int32]*extension.CPUInf
 	}
 
 	var podAllocs []extension.PodCPUAlloc
-	for podUID := range checkpoint.Entries {
-		if _, ok := managedPods[types.UID(podUID)]; ok {
-			continue
-		}
-		cpuSet := cpuset.NewCPUSet()
-		for container, cpuString := range checkpoint.Entries[podUID] {
-			if containerCPUSet, err := cpuset.Parse(cpuString); err != nil {
-				klog.Errorf("could not parse cpuset %q for container %q in pod %q: %v", cpuString, container, podUID, err)
-				continue
-			} else if containerCPUSet.Size() > 0 {
-				cpuSet = cpuSet.Union(containerCPUSet)
-			}
-		}
-		if cpuSet.IsEmpty() {
+	for podUID, cpuSet := range podCPUs {
+		if _, ok := managedPods[podUID]; ok {
 			continue
 		}
 
 		// TODO: It is possible that the data in the checkpoint file is invalid
 		//  and should be checked with the data in the cgroup to determine whether it is consistent
 		podCPUAlloc := extension.PodCPUAlloc{
-			UID:              types.UID(podUID),
+			UID:              podUID,
 			CPUSet:           cpuSet.String(),
 			ManagedByKubelet: true,
 		}
-		podMeta := pods[types.UID(podUID)]
+		podMeta := pods[podUID]
 		if podMeta != nil {
 			podCPUAlloc.Namespace = podMeta.Pod.Namespace
 			podCPUAlloc.Name = podMeta.Pod.Name
@@ -358,6 +353,10 @@ func (s *nodeTopoInformer) reportNodeTopology() {
 		klog.Errorf("failed to calculate node topology, err: %v", err)
 		return
 	}
+	numaNodeZones, err := s.calcNUMANodeZones()
+	if err != nil {
+		klog.V(4).Infof("failed to calculate the zones of NUMA nodes, err: %v", err)
+	}
 
 	node := s.nodeInformer.GetNode()
 	err = util.RetryOnConflictOrTooManyRequests(func() error {
@@ -379,6 +378,9 @@ func (s *nodeTopoInformer) reportNodeTopology() {
 		for k, v := range nodeTopoAnnotations {
 			nodeResourceTopology.Annotations[k] = v
 		}
+		if len(numaNodeZones) > 0 {
+			nodeResourceTopology.Zones = mergeNUMANodeZones(nodeResourceTopology.Zones, numaNodeZones)
+		}
 
 		if isSyncNeeded(s.nodeTopology, nodeResourceTopology, node.Name) {
 			// do UPDATE
@@ -403,7 +405,7 @@ func isSyncNeeded(oldNRT, newNRT *v1alpha1.NodeResourceTopology, nodename string
 	if oldNRT == nil || oldNRT.Annotations == nil || newNRT.Annotations == nil {
 		return true
 	}
-	if isEqualTopo(oldNRT.Annotations, newNRT.Annotations) {
+	if isEqualTopo(oldNRT.Annotations, newNRT.Annotations) && isEqualZones(oldNRT.Zones, newNRT.Zones) {
 		// do nothing
 		klog.V(4).Infof("all good, no need to report nodetopo  %s", nodename)
 		return false
@@ -446,6 +448,131 @@ func isEqualTopo(OldTopo map[string]string, NewTopo map[string]string) bool {
 	return true
 }
 
+// isEqualZones returns whether the new zones are the same as the old ones, ignoring the small changes of the available
+// resources.
+func isEqualZones(oldZones, newZones v1alpha1.ZoneList) bool {
+	if len(oldZones) != len(newZones) {
+		return false
+	}
+	for i := range oldZones {
+		oldZone, newZone := &oldZones[i], &newZones[i]
+		if oldZone.Name != newZone.Name || oldZone.Type != newZone.Type || len(oldZone.Resources) != len(newZone.Resources) {
+			return false
+		}
+		for j := range oldZone.Resources {
+			oldInfo, newInfo := &oldZone.Resources[j], &newZone.Resources[j]
+			if oldInfo.Name != newInfo.Name || !oldInfo.Capacity.Equal(newInfo.Capacity) ||
+				!oldInfo.Allocatable.Equal(newInfo.Allocatable) {
+				return false
+			}
+			availableChange := math.Abs(newInfo.Available.AsApproximateFloat64() - oldInfo.Available.AsApproximateFloat64())
+			if availableChange > newInfo.Capacity.AsApproximateFloat64()*zoneAvailableChangeRatio {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// calcNUMANodeZones returns the zones of the NUMA nodes with the cpu, memory and hugepages resources, where the
+// available memory and hugepages are the free ones of the NUMA node.
+func (s *nodeTopoInformer) calcNUMANodeZones() (v1alpha1.ZoneList, error) {
+	nodeCPUInfo, err := s.metricCache.GetNodeCPUInfo(&metriccache.QueryParam{})
+	if err != nil {
+		return nil, err
+	}
+	memInfos, err := koordletutil.GetNUMANodeMemInfos()
+	if err != nil {
+		return nil, err
+	}
+	hugePagesTotal, hugePagesFree, err := koordletutil.GetNUMANodeHugePages()
+	if err != nil {
+		return nil, err
+	}
+
+	numaNodeCPUs := map[int32]int64{}
+	for _, cpu := range nodeCPUInfo.ProcessorInfos {
+		numaNodeCPUs[cpu.NodeID]++
+	}
+	numaNodeIDs := make([]int, 0, len(memInfos))
+	for numaNodeID := range memInfos {
+		numaNodeIDs = append(numaNodeIDs, numaNodeID)
+	}
+	sort.Ints(numaNodeIDs)
+
+	zones := make(v1alpha1.ZoneList, 0, len(numaNodeIDs))
+	for _, numaNodeID := range numaNodeIDs {
+		memInfo := memInfos[numaNodeID]
+		cpus := *resource.NewQuantity(numaNodeCPUs[int32(numaNodeID)], resource.DecimalSI)
+		memoryTotal := *resource.NewQuantity(int64(memInfo.MemTotal)*1024, resource.BinarySI)
+		memoryFree := *resource.NewQuantity(int64(memInfo.MemFree)*1024, resource.BinarySI)
+		resources := v1alpha1.ResourceInfoList{
+			{Name: string(corev1.ResourceCPU), Capacity: cpus, Allocatable: cpus, Available: cpus},
+			{Name: string(corev1.ResourceMemory), Capacity: memoryTotal, Allocatable: memoryTotal, Available: memoryFree},
+		}
+		// sort the hugepages by name to keep the zones comparable between syncs
+		hugePagesNames := make([]string, 0, len(hugePagesTotal[numaNodeID]))
+		for resourceName := range hugePagesTotal[numaNodeID] {
+			hugePagesNames = append(hugePagesNames, string(resourceName))
+		}
+		sort.Strings(hugePagesNames)
+		for _, name := range hugePagesNames {
+			total := hugePagesTotal[numaNodeID][corev1.ResourceName(name)]
+			resources = append(resources, v1alpha1.ResourceInfo{
+				Name:        name,
+				Capacity:    total,
+				Allocatable: total,
+				Available:   hugePagesFree[numaNodeID][corev1.ResourceName(name)],
+			})
+		}
+		zones = append(zones, v1alpha1.Zone{
+			Name:      fmt.Sprintf("node-%d", numaNodeID),
+			Type:      numaNodeZoneType,
+			Resources: resources,
+		})
+	}
+	return zones, nil
+}
+
+// mergeNUMANodeZones replaces the zones of the NUMA nodes and the placeholder zone with the calculated ones, while the
+// other resources in the zones of the NUMA nodes are kept, e.g. the devices reported by others.
+func mergeNUMANodeZones(oldZones, numaNodeZones v1alpha1.ZoneList) v1alpha1.ZoneList {
+	oldNUMANodeZones := map[string]*v1alpha1.Zone{}
+	var zones v1alpha1.ZoneList
+	for i := range oldZones {
+		zone := &oldZones[i]
+		if zone.Type == numaNodeZoneType {
+			oldNUMANodeZones[zone.Name] = zone
+			continue
+		}
+		if zone.Name == placeholderZoneName && zone.Type == placeholderZoneType {
+			continue
+		}
+		zones = append(zones, *zone.DeepCopy())
+	}
+	for i := range numaNodeZones {
+		zone := numaNodeZones[i].DeepCopy()
+		if oldZone := oldNUMANodeZones[zone.Name]; oldZone != nil {
+			for _, info := range oldZone.Resources {
+				if !hasZoneResource(zone.Resources, info.Name) {
+					zone.Resources = append(zone.Resources, *info.DeepCopy())
+				}
+			}
+		}
+		zones = append(zones, *zone)
+	}
+	return zones
+}
+
+func hasZoneResource(resources v1alpha1.ResourceInfoList, name string) bool {
+	for _, info := range resources {
+		if info.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *nodeTopoInformer) calCPUSharePools(sharedPoolCPUs map[int32]*extension.CPUInfo) []extension.CPUSharedPool {
 	podMetas := s.podsInformer.GetAllPods()
 	for _, podMeta := range podMetas {
@@ -553,6 +680,6 @@ func newNodeTopo(node *corev1.Node) *v1alpha1.NodeResourceTopology {
 		},
 		// fields are required
 		TopologyPolicies: []string{string(v1alpha1.None)},
-		Zones:            v1alpha1.ZoneList{v1alpha1.Zone{Name: "fake-name", Type: "fake-type"}},
+		Zones:            v1alpha1.ZoneList{v1alpha1.Zone{Name: placeholderZoneName, Type: placeholderZoneType}},
 	}
 }
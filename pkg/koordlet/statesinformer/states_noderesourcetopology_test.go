@@ -19,6 +19,7 @@ package statesinformer
 import (
 	"context"
 	"encoding/json"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -27,7 +28,9 @@ import (
 	topologylister "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/listers/topology/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -41,6 +44,7 @@ import (
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache"
 	mock_metriccache "github.com/koordinator-sh/koordinator/pkg/koordlet/metriccache/mockmetriccache"
 	koordletutil "github.com/koordinator-sh/koordinator/pkg/koordlet/util"
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
 var _ topologylister.NodeResourceTopologyLister = &fakeNodeResourceTopologyLister{}
@@ -351,6 +355,39 @@ func Test_reportNodeTopology(t *testing.T) {
 	}
 	mockMetricCache.EXPECT().GetNodeCPUInfo(gomock.Any()).Return(&mockNodeCPUInfo, nil).AnyTimes()
 
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+	helper.WriteFileContents(system.GetNUMANodeMemInfoPath(0), "Node 0 MemTotal:       16777216 kB\nNode 0 MemFree:         8388608 kB\n")
+	helper.WriteFileContents(system.GetNUMANodeMemInfoPath(1), "Node 1 MemTotal:       16777216 kB\nNode 1 MemFree:         4194304 kB\n")
+	hugePagesDir := filepath.Join(system.GetNUMANodeHugePagesDir(0), "hugepages-2048kB")
+	helper.WriteFileContents(filepath.Join(hugePagesDir, system.HugePagesNrName), "512\n")
+	helper.WriteFileContents(filepath.Join(hugePagesDir, system.HugePagesFreeName), "128\n")
+	newZone := func(name string, cpu, memoryTotal, memoryFree string) topologyv1alpha1.Zone {
+		return topologyv1alpha1.Zone{
+			Name: name,
+			Type: numaNodeZoneType,
+			Resources: topologyv1alpha1.ResourceInfoList{
+				{Name: "cpu", Capacity: resource.MustParse(cpu), Allocatable: resource.MustParse(cpu), Available: resource.MustParse(cpu)},
+				{Name: "memory", Capacity: resource.MustParse(memoryTotal), Allocatable: resource.MustParse(memoryTotal), Available: resource.MustParse(memoryFree)},
+			},
+		}
+	}
+	expectedZones := topologyv1alpha1.ZoneList{
+		newZone("node-0", "4", "16Gi", "8Gi"),
+		newZone("node-1", "4", "16Gi", "4Gi"),
+	}
+	expectedZones[0].Resources = append(expectedZones[0].Resources, topologyv1alpha1.ResourceInfo{
+		Name:        "hugepages-2Mi",
+		Capacity:    resource.MustParse("1Gi"),
+		Allocatable: resource.MustParse("1Gi"),
+		Available:   resource.MustParse("256Mi"),
+	})
+
 	expectedCPUSharedPool := `[{"socket":0,"node":0,"cpuset":"0-2"},{"socket":1,"node":1,"cpuset":"6-7"}]`
 	expectedCPUTopology := `{"detail":[{"id":0,"core":0,"socket":0,"node":0},{"id":1,"core":0,"socket":0,"node":0},{"id":2,"core":1,"socket":0,"node":0},{"id":3,"core":1,"socket":0,"node":0},{"id":4,"core":2,"socket":1,"node":1},{"id":5,"core":2,"socket":1,"node":1},{"id":6,"core":3,"socket":1,"node":1},{"id":7,"core":3,"socket":1,"node":1}]}`
 
@@ -479,10 +516,67 @@ func Test_reportNodeTopology(t *testing.T) {
 			assert.Equal(t, tt.expectedKubeletCPUManagerPolicy, kubeletCPUManagerPolicy)
 			assert.Equal(t, tt.expectedCPUSharedPool, topology.Annotations[extension.AnnotationNodeCPUSharedPools])
 			assert.Equal(t, tt.expectedCPUTopology, topology.Annotations[extension.AnnotationNodeCPUTopology])
+			assert.True(t, apiequality.Semantic.DeepEqual(expectedZones, topology.Zones), "expected zones %v, got %v", expectedZones, topology.Zones)
 		})
 	}
 }
 
+func Test_mergeNUMANodeZones(t *testing.T) {
+	hugePages := topologyv1alpha1.ResourceInfo{
+		Name:        "hugepages-2Mi",
+		Capacity:    resource.MustParse("1Gi"),
+		Allocatable: resource.MustParse("1Gi"),
+		Available:   resource.MustParse("1Gi"),
+	}
+	oldZones := topologyv1alpha1.ZoneList{
+		{Name: placeholderZoneName, Type: placeholderZoneType},
+		{Name: "socket-0", Type: "Socket"},
+		{Name: "node-0", Type: numaNodeZoneType, Resources: topologyv1alpha1.ResourceInfoList{
+			{Name: "memory", Capacity: resource.MustParse("8Gi"), Allocatable: resource.MustParse("8Gi"), Available: resource.MustParse("8Gi")},
+			hugePages,
+		}},
+		{Name: "node-2", Type: numaNodeZoneType},
+	}
+	memory := topologyv1alpha1.ResourceInfo{
+		Name:        "memory",
+		Capacity:    resource.MustParse("16Gi"),
+		Allocatable: resource.MustParse("16Gi"),
+		Available:   resource.MustParse("4Gi"),
+	}
+	numaNodeZones := topologyv1alpha1.ZoneList{
+		{Name: "node-0", Type: numaNodeZoneType, Resources: topologyv1alpha1.ResourceInfoList{memory}},
+		{Name: "node-1", Type: numaNodeZoneType, Resources: topologyv1alpha1.ResourceInfoList{memory}},
+	}
+	expected := topologyv1alpha1.ZoneList{
+		{Name: "socket-0", Type: "Socket"},
+		{Name: "node-0", Type: numaNodeZoneType, Resources: topologyv1alpha1.ResourceInfoList{memory, hugePages}},
+		{Name: "node-1", Type: numaNodeZoneType, Resources: topologyv1alpha1.ResourceInfoList{memory}},
+	}
+	assert.Equal(t, expected, mergeNUMANodeZones(oldZones, numaNodeZones))
+}
+
+func Test_isEqualZones(t *testing.T) {
+	newZones := func(memoryAvailable string) topologyv1alpha1.ZoneList {
+		return topologyv1alpha1.ZoneList{
+			{Name: "node-0", Type: numaNodeZoneType, Resources: topologyv1alpha1.ResourceInfoList{
+				{Name: "memory", Capacity: resource.MustParse("16Gi"), Allocatable: resource.MustParse("16Gi"), Available: resource.MustParse(memoryAvailable)},
+			}},
+		}
+	}
+	assert.True(t, isEqualZones(nil, nil))
+	assert.True(t, isEqualZones(newZones("8Gi"), newZones("8Gi")))
+	// the available memory changes less than 5% of the capacity
+	assert.True(t, isEqualZones(newZones("8Gi"), newZones("8500Mi")))
+	assert.False(t, isEqualZones(newZones("8Gi"), newZones("9Gi")))
+	assert.False(t, isEqualZones(newZones("8Gi"), nil))
+	changedCapacity := newZones("8Gi")
+	changedCapacity[0].Resources[0].Capacity = resource.MustParse("32Gi")
+	assert.False(t, isEqualZones(newZones("8Gi"), changedCapacity))
+	renamed := newZones("8Gi")
+	renamed[0].Name = "node-1"
+	assert.False(t, isEqualZones(newZones("8Gi"), renamed))
+}
+
 func Test_isEqualTopo(t *testing.T) {
 	type args struct {
 		oldtopo map[string]string
@@ -31,6 +31,7 @@ import (
 
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	clientsetv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -42,6 +43,11 @@ type nodeSLOInformer struct {
 	nodeSLOInformer cache.SharedIndexInformer
 	nodeSLORWMutex  sync.RWMutex
 	nodeSLO         *slov1alpha1.NodeSLO
+	// specGeneration is the generation of the NodeSLO whose spec is merged into the nodeSLO.
+	specGeneration int64
+	// appliedGeneration is the generation of the NodeSLO spec applied by all the callbacks successfully.
+	appliedGeneration int64
+	nodeSLOClient     clientsetv1alpha1.NodeSLOInterface
 
 	callbackRunner *callbackRunner
 }
@@ -58,6 +64,7 @@ func (s *nodeSLOInformer) GetNodeSLO() *slov1alpha1.NodeSLO {
 
 func (s *nodeSLOInformer) Setup(ctx *pluginOption, state *pluginState) {
 	s.nodeSLOInformer = newNodeSLOInformer(ctx.KoordClient, ctx.NodeName)
+	s.nodeSLOClient = ctx.KoordClient.SloV1alpha1().NodeSLOs()
 	s.nodeSLOInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			nodeSLO, ok := obj.(*slov1alpha1.NodeSLO)
@@ -77,6 +84,8 @@ func (s *nodeSLOInformer) Setup(ctx *pluginOption, state *pluginState) {
 			}
 			if reflect.DeepEqual(oldNodeSLO.Spec, newNodeSLO.Spec) {
 				klog.V(5).Infof("find NodeSLO spec %s has not changed", newNodeSLO.Name)
+				// retry if the applied generation failed to report last time
+				s.reportAppliedStatus(newNodeSLO)
 				return
 			}
 			klog.Infof("update NodeSLO spec %v", util.DumpJSON(newNodeSLO.Spec))
@@ -84,6 +93,7 @@ func (s *nodeSLOInformer) Setup(ctx *pluginOption, state *pluginState) {
 		},
 	})
 	s.callbackRunner = state.callbackRunner
+	s.callbackRunner.SetAppliedFn(RegisterTypeNodeSLOSpec, s.onNodeSLOSpecApplied)
 }
 
 func (s *nodeSLOInformer) Start(stopCh <-chan struct{}) {
@@ -106,6 +116,58 @@ func (s *nodeSLOInformer) updateNodeSLOSpec(nodeSLO *slov1alpha1.NodeSLO) {
 	s.callbackRunner.SendCallback(RegisterTypeNodeSLOSpec)
 }
 
+// onNodeSLOSpecApplied records the generation of the spec applied by the callbacks, and reports it if the spec is
+// still the latest one, or else the newer spec is being applied.
+func (s *nodeSLOInformer) onNodeSLOSpecApplied(_ RegisterType, obj interface{}) {
+	spec, ok := obj.(*slov1alpha1.NodeSLOSpec)
+	if !ok {
+		return
+	}
+	s.nodeSLORWMutex.Lock()
+	if s.nodeSLO == nil || !reflect.DeepEqual(&s.nodeSLO.Spec, spec) {
+		s.nodeSLORWMutex.Unlock()
+		return
+	}
+	s.appliedGeneration = s.specGeneration
+	name := s.nodeSLO.Name
+	s.nodeSLORWMutex.Unlock()
+
+	if s.nodeSLOInformer == nil {
+		return
+	}
+	latest, exists, err := s.nodeSLOInformer.GetStore().GetByKey(name)
+	if err != nil || !exists {
+		klog.V(4).Infof("failed to get NodeSLO %s to report the applied status, exists %v, err: %v", name, exists, err)
+		return
+	}
+	if nodeSLO, ok := latest.(*slov1alpha1.NodeSLO); ok {
+		s.reportAppliedStatus(nodeSLO)
+	}
+}
+
+// reportAppliedStatus reports the generation of the NodeSLO spec applied by koordlet, so that koord-manager can find
+// the nodes lagging behind the desired spec. The generation is reported only after all the callbacks have applied
+// it successfully.
+func (s *nodeSLOInformer) reportAppliedStatus(nodeSLO *slov1alpha1.NodeSLO) {
+	s.nodeSLORWMutex.RLock()
+	appliedGeneration := s.appliedGeneration
+	s.nodeSLORWMutex.RUnlock()
+	if s.nodeSLOClient == nil || nodeSLO.Generation != appliedGeneration ||
+		nodeSLO.Status.ObservedGeneration == nodeSLO.Generation {
+		return
+	}
+	newNodeSLO := nodeSLO.DeepCopy()
+	now := metav1.Now()
+	newNodeSLO.Status.ObservedGeneration = nodeSLO.Generation
+	newNodeSLO.Status.LastAppliedTime = &now
+	if _, err := s.nodeSLOClient.UpdateStatus(context.TODO(), newNodeSLO, metav1.UpdateOptions{}); err != nil {
+		klog.Warningf("failed to report the applied status of NodeSLO %s, generation %d, err: %v",
+			nodeSLO.Name, nodeSLO.Generation, err)
+		return
+	}
+	klog.V(4).Infof("report the applied status of NodeSLO %s, generation %d", nodeSLO.Name, nodeSLO.Generation)
+}
+
 func (s *nodeSLOInformer) setNodeSLOSpec(nodeSLO *slov1alpha1.NodeSLO) {
 	s.nodeSLORWMutex.Lock()
 	defer s.nodeSLORWMutex.Unlock()
@@ -117,6 +179,7 @@ func (s *nodeSLOInformer) setNodeSLOSpec(nodeSLO *slov1alpha1.NodeSLO) {
 	} else {
 		s.nodeSLO.Spec = nodeSLO.Spec
 	}
+	s.specGeneration = nodeSLO.Generation
 
 	// merge nodeSLO spec with the default config
 	s.mergeNodeSLOSpec(nodeSLO)
@@ -265,6 +328,7 @@ func mergeNoneResourceQOSIfDisabled(resourceQOS *slov1alpha1.ResourceQOSStrategy
 	mergeNoneCPUQOSIfDisabled(resourceQOS)
 	mergeNoneResctrlQOSIfDisabled(resourceQOS)
 	mergeNoneMemoryQOSIfDisabled(resourceQOS)
+	mergeNoneNetworkQOSIfDisabled(resourceQOS)
 	klog.V(5).Infof("get merged node ResourceQOS %v", util.DumpJSON(resourceQOS))
 }
 
@@ -301,6 +365,22 @@ func mergeNoneMemoryQOSIfDisabled(resourceQOS *slov1alpha1.ResourceQOSStrategy)
 	}
 }
 
+// mergeNoneNetworkQOSIfDisabled completes node's network qos config according to Enable options in NetworkQOS
+func mergeNoneNetworkQOSIfDisabled(resourceQOS *slov1alpha1.ResourceQOSStrategy) {
+	if resourceQOS.LSRClass != nil && resourceQOS.LSRClass.NetworkQOS != nil &&
+		resourceQOS.LSRClass.NetworkQOS.Enable != nil && !(*resourceQOS.LSRClass.NetworkQOS.Enable) {
+		resourceQOS.LSRClass.NetworkQOS.NetworkQOS = *util.NoneNetworkQOS()
+	}
+	if resourceQOS.LSClass != nil && resourceQOS.LSClass.NetworkQOS != nil &&
+		resourceQOS.LSClass.NetworkQOS.Enable != nil && !(*resourceQOS.LSClass.NetworkQOS.Enable) {
+		resourceQOS.LSClass.NetworkQOS.NetworkQOS = *util.NoneNetworkQOS()
+	}
+	if resourceQOS.BEClass != nil && resourceQOS.BEClass.NetworkQOS != nil &&
+		resourceQOS.BEClass.NetworkQOS.Enable != nil && !(*resourceQOS.BEClass.NetworkQOS.Enable) {
+		resourceQOS.BEClass.NetworkQOS.NetworkQOS = *util.NoneNetworkQOS()
+	}
+}
+
 func mergeNoneCPUQOSIfDisabled(resourceQOS *slov1alpha1.ResourceQOSStrategy) {
 	// if CPUQOS.Enabled=false, merge with NoneCPUQOS
 	if resourceQOS.LSRClass != nil && resourceQOS.LSRClass.CPUQOS != nil &&
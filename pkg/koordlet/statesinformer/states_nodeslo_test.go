@@ -17,13 +17,17 @@ limitations under the License.
 package statesinformer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 )
 
@@ -48,6 +52,9 @@ func Test_mergeNodeSLOSpec(t *testing.T) {
 						CATRangeEndPercent: pointer.Int64Ptr(50),
 					},
 				},
+				NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+					Enable: pointer.BoolPtr(true),
+				},
 			},
 		},
 		CPUBurstStrategy: &slov1alpha1.CPUBurstStrategy{
@@ -226,13 +233,16 @@ func Test_updateNodeSLOSpec(t *testing.T) {
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSRClass.CPUQOS.CPUQOS = *util.NoneCPUQOS()
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSRClass.MemoryQOS.MemoryQOS = *util.NoneMemoryQOS()
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSRClass.ResctrlQOS.ResctrlQOS = *util.NoneResctrlQOS()
+	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSRClass.NetworkQOS.NetworkQOS = *util.NoneNetworkQOS()
 
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSClass.CPUQOS.CPUQOS = *util.NoneCPUQOS()
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSClass.MemoryQOS.MemoryQOS = *util.NoneMemoryQOS()
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSClass.ResctrlQOS.ResctrlQOS = *util.NoneResctrlQOS()
+	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.LSClass.NetworkQOS.NetworkQOS = *util.NoneNetworkQOS()
 
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.BEClass.CPUQOS.CPUQOS = *util.NoneCPUQOS()
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.BEClass.MemoryQOS.MemoryQOS = *util.NoneMemoryQOS()
+	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.BEClass.NetworkQOS.NetworkQOS = *util.NoneNetworkQOS()
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.BEClass.ResctrlQOS.Enable = pointer.BoolPtr(true)
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.BEClass.ResctrlQOS.CATRangeStartPercent = pointer.Int64Ptr(0)
 	testingUpdatedNodeSLO.Spec.ResourceQOSStrategy.BEClass.ResctrlQOS.CATRangeEndPercent = pointer.Int64Ptr(20)
@@ -253,6 +263,59 @@ func Test_updateNodeSLOSpec(t *testing.T) {
 	assert.Equal(t, testingUpdatedNodeSLO, r.nodeSLO)
 }
 
+func Test_reportAppliedStatus(t *testing.T) {
+	nodeSLO := &slov1alpha1.NodeSLO{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-node",
+			Generation: 2,
+		},
+		Status: slov1alpha1.NodeSLOStatus{
+			ObservedGeneration: 1,
+			Conditions: []slov1alpha1.NodeSLOCondition{
+				{
+					Type:   slov1alpha1.NodeSLOConditionDrifted,
+					Status: corev1.ConditionTrue,
+					Reason: slov1alpha1.NodeSLOReasonApplyLagging,
+				},
+			},
+		},
+	}
+	client := koordfake.NewSimpleClientset(nodeSLO)
+	r := nodeSLOInformer{
+		nodeSLOInformer: newNodeSLOInformer(client, nodeSLO.Name),
+		nodeSLOClient:   client.SloV1alpha1().NodeSLOs(),
+		callbackRunner:  NewCallbackRunner(),
+	}
+	assert.NoError(t, r.nodeSLOInformer.GetStore().Add(nodeSLO))
+
+	// the generation is not reported until the callbacks apply the spec
+	r.updateNodeSLOSpec(nodeSLO)
+	got, err := client.SloV1alpha1().NodeSLOs().Get(context.TODO(), nodeSLO.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.Status.ObservedGeneration)
+
+	// the callbacks applied a stale spec
+	r.onNodeSLOSpecApplied(RegisterTypeNodeSLOSpec, &slov1alpha1.NodeSLOSpec{})
+	got, err = client.SloV1alpha1().NodeSLOs().Get(context.TODO(), nodeSLO.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), got.Status.ObservedGeneration)
+
+	r.onNodeSLOSpecApplied(RegisterTypeNodeSLOSpec, r.GetNodeSLO().Spec.DeepCopy())
+	got, err = client.SloV1alpha1().NodeSLOs().Get(context.TODO(), nodeSLO.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), got.Status.ObservedGeneration)
+	assert.NotNil(t, got.Status.LastAppliedTime)
+	// the conditions are maintained by koord-manager
+	assert.Equal(t, nodeSLO.Status.Conditions, got.Status.Conditions)
+
+	// skip reporting the applied generation again
+	lastAppliedTime := got.Status.LastAppliedTime
+	r.reportAppliedStatus(got)
+	got, err = client.SloV1alpha1().NodeSLOs().Get(context.TODO(), nodeSLO.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, lastAppliedTime, got.Status.LastAppliedTime)
+}
+
 func Test_mergeSLOSpecResourceUsedThresholdWithBE(t *testing.T) {
 	testingDefaultSpec := util.DefaultResourceThresholdStrategy()
 	testingNewSpec := &slov1alpha1.ResourceThresholdStrategy{
@@ -0,0 +1,233 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+const (
+	podCheckpointSyncInterval = 5 * time.Second
+	// podCheckpointTimeout bounds a checkpoint request, which is shorter than the time koord-descheduler waits
+	// for the response before it falls back to evict the pod.
+	podCheckpointTimeout = 4 * time.Minute
+)
+
+// criuPaths are the well-known paths of the CRIU binary which the container runtimes depend on to checkpoint.
+var criuPaths = []string{"/usr/sbin/criu", "/usr/local/sbin/criu", "/usr/bin/criu", "/usr/local/bin/criu"}
+
+// podCheckpointer responds to the checkpoint requests of the pods made by koord-descheduler before migrating them.
+// The containers are checkpointed via the kubelet checkpoint API, which relies on the container runtime and CRIU,
+// and the results are reported in the pod annotation.
+type podCheckpointer struct {
+	kubeClient clientset.Interface
+	kubelet    KubeletStub
+	// criuAvailable detects whether the node is capable to checkpoint.
+	criuAvailable func() bool
+	// lock protects handled and inflight, which are updated by the asynchronous checkpoints.
+	lock sync.Mutex
+	// handled records the last handled request of each pod, since the pods from the kubelet may be out of date.
+	handled map[types.UID]string
+	// inflight records the requests being checkpointed of each pod.
+	inflight map[types.UID]string
+	wg       sync.WaitGroup
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+func newPodCheckpointer(kubeClient clientset.Interface, kubelet KubeletStub) *podCheckpointer {
+	return &podCheckpointer{
+		kubeClient:    kubeClient,
+		kubelet:       kubelet,
+		criuAvailable: isCRIUAvailable,
+		handled:       map[types.UID]string{},
+		inflight:      map[types.UID]string{},
+		timeout:       podCheckpointTimeout,
+		now:           time.Now,
+	}
+}
+
+func (s *statesInformer) runPodCheckpointer(stopCh <-chan struct{}) {
+	stub, err := newKubeletStubFromConfig(s.GetNode(), s.config)
+	if err != nil {
+		klog.Errorf("failed to create kubelet stub for pod checkpointer, err: %v", err)
+		return
+	}
+	checkpointer := newPodCheckpointer(s.option.KubeClient, stub)
+	klog.Info("start pod checkpointer")
+	wait.Until(func() {
+		checkpointer.sync(s.GetAllPods())
+	}, podCheckpointSyncInterval, stopCh)
+}
+
+// sync starts to checkpoint the pods with new requests asynchronously, so that a slow checkpoint never blocks
+// the others.
+func (c *podCheckpointer) sync(pods []*PodMeta) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	requested := make(map[types.UID]struct{}, len(c.handled))
+	for _, podMeta := range pods {
+		if podMeta == nil || podMeta.Pod == nil {
+			continue
+		}
+		pod := podMeta.Pod
+		request := pod.Annotations[apiext.AnnotationCheckpointRequest]
+		if request == "" {
+			continue
+		}
+		requested[pod.UID] = struct{}{}
+		if c.handled[pod.UID] == request || c.inflight[pod.UID] == request {
+			continue
+		}
+		if status, err := apiext.GetCheckpointStatus(pod.Annotations); err == nil && status != nil && status.Request == request {
+			c.handled[pod.UID] = request
+			continue
+		}
+		if _, ok := c.inflight[pod.UID]; ok {
+			// wait for the previous request to finish since the containers cannot be checkpointed concurrently
+			continue
+		}
+
+		c.inflight[pod.UID] = request
+		c.wg.Add(1)
+		go c.handleRequest(pod.DeepCopy(), request)
+	}
+	for uid := range c.handled {
+		if _, ok := requested[uid]; !ok {
+			delete(c.handled, uid)
+		}
+	}
+}
+
+func (c *podCheckpointer) handleRequest(pod *corev1.Pod, request string) {
+	defer c.wg.Done()
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	status := c.checkpointPod(ctx, pod)
+	status.Request = request
+	finishTime := metav1.NewTime(c.now())
+	status.FinishTime = &finishTime
+	err := c.patchCheckpointStatus(pod, status)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.inflight, pod.UID)
+	if err != nil {
+		klog.Errorf("failed to update checkpoint status of pod %s, err: %v", klog.KObj(pod), err)
+		return
+	}
+	c.handled[pod.UID] = request
+	klog.V(4).Infof("pod %s checkpoint request %s finished, phase %s, message %q",
+		klog.KObj(pod), request, status.Phase, status.Message)
+}
+
+func (c *podCheckpointer) checkpointPod(ctx context.Context, pod *corev1.Pod) *apiext.CheckpointStatus {
+	if reason := c.checkpointUnsupportedReason(pod); reason != "" {
+		return &apiext.CheckpointStatus{Phase: apiext.CheckpointUnsupported, Message: reason}
+	}
+
+	status := &apiext.CheckpointStatus{Phase: apiext.CheckpointSucceeded}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Running == nil {
+			continue
+		}
+		archives, err := c.kubelet.CheckpointContainer(ctx, pod.Namespace, pod.Name, containerStatus.Name)
+		if err != nil {
+			phase := apiext.CheckpointFailed
+			if errors.Is(err, ErrCheckpointUnsupported) {
+				phase = apiext.CheckpointUnsupported
+			}
+			return &apiext.CheckpointStatus{
+				Phase:   phase,
+				Message: fmt.Sprintf("failed to checkpoint container %s, err: %v", containerStatus.Name, err),
+			}
+		}
+		for _, archive := range archives {
+			status.Containers = append(status.Containers, apiext.ContainerCheckpoint{
+				Name:    containerStatus.Name,
+				Archive: archive,
+			})
+		}
+	}
+	if len(status.Containers) == 0 {
+		return &apiext.CheckpointStatus{Phase: apiext.CheckpointFailed, Message: "no running container checkpointed"}
+	}
+	return status
+}
+
+// checkpointUnsupportedReason returns why the pod cannot be checkpointed on the node, or empty if it can.
+func (c *podCheckpointer) checkpointUnsupportedReason(pod *corev1.Pod) string {
+	if !apiext.IsCheckpointEnabled(pod.Annotations) {
+		return "pod does not enable checkpoint"
+	}
+	if _, ok := pod.Annotations[apiext.AnnotationDeviceAllocated]; ok || apiext.GetDeviceAllocationRef(pod.Annotations) != "" {
+		return "pod with devices allocated is not supported"
+	}
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if strings.HasPrefix(containerStatus.ContainerID, "docker://") {
+			return "container runtime docker is not supported"
+		}
+	}
+	if !c.criuAvailable() {
+		return "criu is not installed on node"
+	}
+	return ""
+}
+
+func (c *podCheckpointer) patchCheckpointStatus(pod *corev1.Pod, status *apiext.CheckpointStatus) error {
+	newPod := &corev1.Pod{}
+	if err := apiext.SetCheckpointStatus(newPod, status); err != nil {
+		return err
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": newPod.Annotations,
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = c.kubeClient.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, data, metav1.PatchOptions{})
+	return err
+}
+
+func isCRIUAvailable() bool {
+	for _, p := range criuPaths {
+		if info, err := os.Stat(p); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
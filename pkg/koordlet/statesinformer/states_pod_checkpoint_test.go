@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func Test_podCheckpointer_sync(t *testing.T) {
+	newTestPod := func(name string, annotations map[string]string, containerID string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        name,
+				UID:         types.UID(name),
+				Annotations: annotations,
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:        "main",
+						ContainerID: containerID,
+						State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					},
+					{
+						Name:  "init-done",
+						State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name          string
+		pod           *corev1.Pod
+		criuAvailable bool
+		checkpoints   map[string][]string
+		wantStatus    *apiext.CheckpointStatus
+	}{
+		{
+			name:          "no checkpoint request",
+			pod:           newTestPod("test-pod", map[string]string{apiext.AnnotationCheckpointEnabled: "true"}, "containerd://abc"),
+			criuAvailable: true,
+		},
+		{
+			name: "checkpoint succeeded",
+			pod: newTestPod("test-pod", map[string]string{
+				apiext.AnnotationCheckpointEnabled: "true",
+				apiext.AnnotationCheckpointRequest: "test-job",
+			}, "containerd://abc"),
+			criuAvailable: true,
+			checkpoints:   map[string][]string{"main": {"/var/lib/kubelet/checkpoints/checkpoint-test-pod_default-main.tar"}},
+			wantStatus: &apiext.CheckpointStatus{
+				Request: "test-job",
+				Phase:   apiext.CheckpointSucceeded,
+				Containers: []apiext.ContainerCheckpoint{
+					{Name: "main", Archive: "/var/lib/kubelet/checkpoints/checkpoint-test-pod_default-main.tar"},
+				},
+			},
+		},
+		{
+			name: "pod not opt in",
+			pod: newTestPod("test-pod", map[string]string{
+				apiext.AnnotationCheckpointRequest: "test-job",
+			}, "containerd://abc"),
+			criuAvailable: true,
+			wantStatus: &apiext.CheckpointStatus{
+				Request: "test-job",
+				Phase:   apiext.CheckpointUnsupported,
+				Message: "pod does not enable checkpoint",
+			},
+		},
+		{
+			name: "criu not installed",
+			pod: newTestPod("test-pod", map[string]string{
+				apiext.AnnotationCheckpointEnabled: "true",
+				apiext.AnnotationCheckpointRequest: "test-job",
+			}, "containerd://abc"),
+			wantStatus: &apiext.CheckpointStatus{
+				Request: "test-job",
+				Phase:   apiext.CheckpointUnsupported,
+				Message: "criu is not installed on node",
+			},
+		},
+		{
+			name: "docker not supported",
+			pod: newTestPod("test-pod", map[string]string{
+				apiext.AnnotationCheckpointEnabled: "true",
+				apiext.AnnotationCheckpointRequest: "test-job",
+			}, "docker://abc"),
+			criuAvailable: true,
+			wantStatus: &apiext.CheckpointStatus{
+				Request: "test-job",
+				Phase:   apiext.CheckpointUnsupported,
+				Message: "container runtime docker is not supported",
+			},
+		},
+		{
+			name: "kubelet not supported",
+			pod: newTestPod("test-pod", map[string]string{
+				apiext.AnnotationCheckpointEnabled: "true",
+				apiext.AnnotationCheckpointRequest: "test-job",
+			}, "containerd://abc"),
+			criuAvailable: true,
+			wantStatus: &apiext.CheckpointStatus{
+				Request: "test-job",
+				Phase:   apiext.CheckpointUnsupported,
+				Message: "failed to checkpoint container main, err: checkpoint unsupported",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			kubeClient := fakeclientset.NewSimpleClientset(tt.pod)
+			c := newPodCheckpointer(kubeClient, &testKubeletStub{checkpoints: tt.checkpoints})
+			c.criuAvailable = func() bool { return tt.criuAvailable }
+			c.now = func() time.Time { return now }
+
+			c.sync([]*PodMeta{{Pod: tt.pod}})
+			c.wg.Wait()
+
+			gotPod, err := kubeClient.CoreV1().Pods(tt.pod.Namespace).Get(context.TODO(), tt.pod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			gotAnnotation := gotPod.Annotations[apiext.AnnotationCheckpointStatus]
+			gotStatus, err := apiext.GetCheckpointStatus(gotPod.Annotations)
+			assert.NoError(t, err)
+			if tt.wantStatus != nil {
+				finishTime := metav1.NewTime(now)
+				tt.wantStatus.FinishTime = &finishTime
+				assert.Equal(t, tt.wantStatus.Request, c.handled[tt.pod.UID])
+			}
+			if gotStatus != nil && gotStatus.FinishTime != nil {
+				assert.True(t, gotStatus.FinishTime.Equal(tt.wantStatus.FinishTime))
+				gotStatus.FinishTime = tt.wantStatus.FinishTime
+			}
+			assert.Equal(t, tt.wantStatus, gotStatus)
+
+			// the handled request is not checkpointed again
+			c.kubelet = &testErrorKubeletStub{}
+			c.sync([]*PodMeta{{Pod: tt.pod}})
+			c.wg.Wait()
+			gotPod, err = kubeClient.CoreV1().Pods(tt.pod.Namespace).Get(context.TODO(), tt.pod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, gotAnnotation, gotPod.Annotations[apiext.AnnotationCheckpointStatus])
+		})
+	}
+}
+
+type blockingKubeletStub struct {
+	testKubeletStub
+}
+
+func (b *blockingKubeletStub) CheckpointContainer(ctx context.Context, namespace, podName, containerName string) ([]string, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func Test_podCheckpointer_syncTimeout(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod",
+			Annotations: map[string]string{
+				apiext.AnnotationCheckpointEnabled: "true",
+				apiext.AnnotationCheckpointRequest: "test-job",
+			},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:        "main",
+					ContainerID: "containerd://abc",
+					State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				},
+			},
+		},
+	}
+	kubeClient := fakeclientset.NewSimpleClientset(pod)
+	c := newPodCheckpointer(kubeClient, &blockingKubeletStub{})
+	c.criuAvailable = func() bool { return true }
+	c.timeout = 100 * time.Millisecond
+
+	// sync returns without waiting for the checkpoint, and the inflight request is not started again
+	c.sync([]*PodMeta{{Pod: pod}})
+	c.lock.Lock()
+	assert.Equal(t, "test-job", c.inflight[pod.UID])
+	c.lock.Unlock()
+	c.sync([]*PodMeta{{Pod: pod}})
+	c.wg.Wait()
+
+	assert.Empty(t, c.inflight)
+	assert.Equal(t, "test-job", c.handled[pod.UID])
+	gotPod, err := kubeClient.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	gotStatus, err := apiext.GetCheckpointStatus(gotPod.Annotations)
+	assert.NoError(t, err)
+	assert.Equal(t, apiext.CheckpointFailed, gotStatus.Phase)
+	assert.Contains(t, gotStatus.Message, context.DeadlineExceeded.Error())
+}
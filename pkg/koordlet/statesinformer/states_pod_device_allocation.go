@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// podDeviceAllocationCache caches the PodDeviceAllocations referenced by the pods on the node. The devices of a pod
+// are not changed after it is bound, so a PodDeviceAllocation is fetched once and kept until its pod is gone.
+type podDeviceAllocationCache struct {
+	lock  sync.Mutex
+	items map[string]*schedulingv1alpha1.PodDeviceAllocation
+}
+
+func newPodDeviceAllocationCache() *podDeviceAllocationCache {
+	return &podDeviceAllocationCache{
+		items: map[string]*schedulingv1alpha1.PodDeviceAllocation{},
+	}
+}
+
+func (s *statesInformer) GetPodDeviceAllocation(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error) {
+	key := namespace + "/" + name
+	activePods := s.getActivePodUIDs()
+
+	c := s.podDeviceAllocations
+	c.lock.Lock()
+	cached, ok := c.items[key]
+	c.lock.Unlock()
+	// the cached one may be left by a deleted pod with the same name
+	if ok && activePods.Has(string(cached.Spec.PodUID)) {
+		return cached, nil
+	}
+
+	if s.option == nil || s.option.KoordClient == nil {
+		return nil, fmt.Errorf("failed to get PodDeviceAllocation %s, no client", key)
+	}
+	podDeviceAllocation, err := s.option.KoordClient.SchedulingV1alpha1().PodDeviceAllocations(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, item := range c.items {
+		if !activePods.Has(string(item.Spec.PodUID)) {
+			delete(c.items, k)
+		}
+	}
+	c.items[key] = podDeviceAllocation
+	return podDeviceAllocation, nil
+}
+
+func (s *statesInformer) getActivePodUIDs() sets.String {
+	podUIDs := sets.NewString()
+	if _, ok := s.states.informerPlugins[podsInformerName]; !ok {
+		return podUIDs
+	}
+	for _, podMeta := range s.GetAllPods() {
+		if podMeta != nil && podMeta.Pod != nil {
+			podUIDs.Insert(string(podMeta.Pod.UID))
+		}
+	}
+	return podUIDs
+}
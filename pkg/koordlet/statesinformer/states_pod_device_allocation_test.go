@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesinformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	fakekoordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+)
+
+func TestGetPodDeviceAllocation(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "uid-1"},
+	}
+	podDeviceAllocation := &schedulingv1alpha1.PodDeviceAllocation{
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		Spec:       schedulingv1alpha1.PodDeviceAllocationSpec{PodUID: pod.UID},
+	}
+	koordClient := fakekoordclientset.NewSimpleClientset(podDeviceAllocation)
+	pods := &podsInformer{
+		podMap: map[string]*PodMeta{string(pod.UID): {Pod: pod}},
+	}
+	s := &statesInformer{
+		podDeviceAllocations: newPodDeviceAllocationCache(),
+		option:               &pluginOption{KoordClient: koordClient},
+		states: &pluginState{
+			informerPlugins: map[pluginName]informerPlugin{podsInformerName: pods},
+		},
+	}
+
+	got, err := s.GetPodDeviceAllocation(pod.Namespace, pod.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, pod.UID, got.Spec.PodUID)
+
+	// the PodDeviceAllocation of an active pod is served from the cache
+	err = koordClient.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	got, err = s.GetPodDeviceAllocation(pod.Namespace, pod.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, pod.UID, got.Spec.PodUID)
+
+	// the pod is recreated with the same name, so the cached one is stale
+	recreated := pod.DeepCopy()
+	recreated.UID = "uid-2"
+	pods.podMap = map[string]*PodMeta{string(recreated.UID): {Pod: recreated}}
+	_, err = s.GetPodDeviceAllocation(pod.Namespace, pod.Name)
+	assert.Error(t, err)
+	recreatedAllocation := podDeviceAllocation.DeepCopy()
+	recreatedAllocation.Spec.PodUID = recreated.UID
+	_, err = koordClient.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace).Create(context.TODO(), recreatedAllocation, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	got, err = s.GetPodDeviceAllocation(pod.Namespace, pod.Name)
+	assert.NoError(t, err)
+	assert.Equal(t, recreated.UID, got.Spec.PodUID)
+	assert.Len(t, s.podDeviceAllocations.items, 1)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package statesinformer
 
 import (
+	"context"
 	"errors"
 	"net"
 	"os"
@@ -95,8 +96,9 @@ func Test_genPodCgroupParentDirWithCgroupfsDriver(t *testing.T) {
 }
 
 type testKubeletStub struct {
-	pods   corev1.PodList
-	config *kubeletconfiginternal.KubeletConfiguration
+	pods        corev1.PodList
+	config      *kubeletconfiginternal.KubeletConfiguration
+	checkpoints map[string][]string
 }
 
 func (t *testKubeletStub) GetAllPods() (corev1.PodList, error) {
@@ -107,6 +109,14 @@ func (t *testKubeletStub) GetKubeletConfiguration() (*kubeletconfiginternal.Kube
 	return t.config, nil
 }
 
+func (t *testKubeletStub) CheckpointContainer(ctx context.Context, namespace, podName, containerName string) ([]string, error) {
+	items, ok := t.checkpoints[containerName]
+	if !ok {
+		return nil, ErrCheckpointUnsupported
+	}
+	return items, nil
+}
+
 type testErrorKubeletStub struct {
 }
 
@@ -118,6 +128,10 @@ func (t *testErrorKubeletStub) GetKubeletConfiguration() (*kubeletconfiginternal
 	return nil, errors.New("test error")
 }
 
+func (t *testErrorKubeletStub) CheckpointContainer(ctx context.Context, namespace, podName, containerName string) ([]string, error) {
+	return nil, errors.New("test error")
+}
+
 func Test_statesInformer_syncPods(t *testing.T) {
 	stopCh := make(chan struct{}, 1)
 	defer close(stopCh)
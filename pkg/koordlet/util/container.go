@@ -147,51 +147,35 @@ func GetContainerCurTasksPath(podParentDir string, c *corev1.ContainerStatus) (s
 }
 
 func GetContainerCurCPUShare(podParentDir string, c *corev1.ContainerStatus) (int64, error) {
-	cgroupPath, err := GetContainerCgroupCPUSharePath(podParentDir, c)
-	if err != nil {
-		return 0, err
-	}
-	rawContent, err := os.ReadFile(cgroupPath)
+	containerPath, err := GetContainerCgroupPathWithKube(podParentDir, c)
 	if err != nil {
 		return 0, err
 	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadCPUShares(containerPath)
 }
 
 func GetContainerCurCFSPeriod(podParentDir string, c *corev1.ContainerStatus) (int64, error) {
-	cgroupPath, err := GetContainerCgroupCFSPeriodPath(podParentDir, c)
-	if err != nil {
-		return 0, err
-	}
-	rawContent, err := os.ReadFile(cgroupPath)
+	containerPath, err := GetContainerCgroupPathWithKube(podParentDir, c)
 	if err != nil {
 		return 0, err
 	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadCPUPeriod(containerPath)
 }
 
 func GetContainerCurCFSQuota(podParentDir string, c *corev1.ContainerStatus) (int64, error) {
-	cgroupPath, err := GetContainerCgroupCFSQuotaPath(podParentDir, c)
-	if err != nil {
-		return 0, err
-	}
-	rawContent, err := os.ReadFile(cgroupPath)
+	containerPath, err := GetContainerCgroupPathWithKube(podParentDir, c)
 	if err != nil {
 		return 0, err
 	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadCPUQuota(containerPath)
 }
 
 func GetContainerCurMemLimitBytes(podParentDir string, c *corev1.ContainerStatus) (int64, error) {
-	cgroupPath, err := GetContainerCgroupMemLimitPath(podParentDir, c)
-	if err != nil {
-		return 0, err
-	}
-	rawContent, err := os.ReadFile(cgroupPath)
+	containerPath, err := GetContainerCgroupPathWithKube(podParentDir, c)
 	if err != nil {
 		return 0, err
 	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadMemoryLimit(containerPath)
 }
 
 func GetContainerBaseCFSQuota(container *corev1.Container) int64 {
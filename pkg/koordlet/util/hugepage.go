@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+// GetHugePagesResourceName returns the hugepage resource name of the page size, e.g. hugepages-2Mi for 2048 KB.
+func GetHugePagesResourceName(pageSizeKB uint64) corev1.ResourceName {
+	return v1helper.HugePageResourceName(*resource.NewQuantity(int64(pageSizeKB)*1024, resource.BinarySI))
+}
+
+// GetNUMANodeHugePages returns the total and free hugepages of all NUMA nodes, indexed by the NUMA node id.
+// The NUMA nodes without any hugepage pool are omitted.
+func GetNUMANodeHugePages() (map[int]corev1.ResourceList, map[int]corev1.ResourceList, error) {
+	infos, err := system.GetNUMANodeHugePagesInfos()
+	if err != nil {
+		return nil, nil, err
+	}
+	total := map[int]corev1.ResourceList{}
+	free := map[int]corev1.ResourceList{}
+	for numaNodeID, nodeInfos := range infos {
+		if len(nodeInfos) == 0 {
+			continue
+		}
+		total[numaNodeID] = corev1.ResourceList{}
+		free[numaNodeID] = corev1.ResourceList{}
+		for _, info := range nodeInfos {
+			resourceName := GetHugePagesResourceName(info.PageSizeKB)
+			total[numaNodeID][resourceName] = *resource.NewQuantity(int64(info.TotalBytes()), resource.BinarySI)
+			free[numaNodeID][resourceName] = *resource.NewQuantity(int64(info.FreeBytes()), resource.BinarySI)
+		}
+	}
+	return total, free, nil
+}
+
+// GetPodHugePagesUsed returns the hugepages charged to the pod cgroup, which only reads the hugetlb usages of the
+// page sizes the pod requests, since the pod cannot use the hugepages without requesting them.
+func GetPodHugePagesUsed(pod *corev1.Pod, podCgroupDir string) (corev1.ResourceList, error) {
+	var used corev1.ResourceList
+	for _, resourceName := range getPodHugePagesResourceNames(pod) {
+		pageSize, err := v1helper.HugePageSizeFromResourceName(resourceName)
+		if err != nil {
+			return nil, err
+		}
+		usedBytes, err := system.GetHugetlbUsageBytes(podCgroupDir, uint64(pageSize.Value()/1024))
+		if err != nil {
+			return nil, fmt.Errorf("read %s usage failed, err: %v", resourceName, err)
+		}
+		if used == nil {
+			used = corev1.ResourceList{}
+		}
+		used[resourceName] = *resource.NewQuantity(int64(usedBytes), resource.BinarySI)
+	}
+	return used, nil
+}
+
+func getPodHugePagesResourceNames(pod *corev1.Pod) []corev1.ResourceName {
+	var resourceNames []corev1.ResourceName
+	visited := map[corev1.ResourceName]bool{}
+	addResourceNames := func(resources corev1.ResourceList) {
+		for resourceName := range resources {
+			if v1helper.IsHugePageResourceName(resourceName) && !visited[resourceName] {
+				visited[resourceName] = true
+				resourceNames = append(resourceNames, resourceName)
+			}
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		addResourceNames(pod.Spec.InitContainers[i].Resources.Limits)
+	}
+	for i := range pod.Spec.Containers {
+		addResourceNames(pod.Spec.Containers[i].Resources.Limits)
+	}
+	return resourceNames
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+)
+
+func TestGetHugePagesResourceName(t *testing.T) {
+	assert.Equal(t, corev1.ResourceName("hugepages-2Mi"), GetHugePagesResourceName(2048))
+	assert.Equal(t, corev1.ResourceName("hugepages-1Gi"), GetHugePagesResourceName(1048576))
+}
+
+func TestGetNUMANodeHugePages(t *testing.T) {
+	oldProcRootDir := system.Conf.ProcRootDir
+	helper := system.NewFileTestUtil(t)
+	defer func() {
+		helper.Cleanup()
+		system.Conf.ProcRootDir = oldProcRootDir
+	}()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+
+	hugePagesDir := filepath.Join(system.GetNUMANodeHugePagesDir(0), "hugepages-2048kB")
+	helper.WriteFileContents(filepath.Join(hugePagesDir, system.HugePagesNrName), "512\n")
+	helper.WriteFileContents(filepath.Join(hugePagesDir, system.HugePagesFreeName), "256\n")
+	helper.WriteFileContents(system.GetNUMANodeMemInfoPath(1), "")
+
+	total, free, err := GetNUMANodeHugePages()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]corev1.ResourceList{
+		0: {"hugepages-2Mi": *resource.NewQuantity(1024*1024*1024, resource.BinarySI)},
+	}, total)
+	assert.Equal(t, map[int]corev1.ResourceList{
+		0: {"hugepages-2Mi": *resource.NewQuantity(512*1024*1024, resource.BinarySI)},
+	}, free)
+}
+
+func TestGetPodHugePagesUsed(t *testing.T) {
+	oldProcRootDir := system.Conf.ProcRootDir
+	helper := system.NewFileTestUtil(t)
+	defer func() {
+		helper.Cleanup()
+		system.Conf.ProcRootDir = oldProcRootDir
+	}()
+	helper.SetCgroupsV2(false)
+
+	podCgroupDir := "kubepods.slice/kubepods-pod1.slice"
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+							"hugepages-2Mi":       resource.MustParse("8Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// no hugepages requested
+	got, err := GetPodHugePagesUsed(&corev1.Pod{}, podCgroupDir)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	// the hugetlb usage is missing
+	_, err = GetPodHugePagesUsed(pod, podCgroupDir)
+	assert.Error(t, err)
+
+	helper.WriteFileContents(filepath.Join(system.CgroupHugetlbDir, podCgroupDir, "hugetlb.2MB.usage_in_bytes"), "4194304\n")
+	got, err = GetPodHugePagesUsed(pod, podCgroupDir)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ResourceList{
+		"hugepages-2Mi": *resource.NewQuantity(4*1024*1024, resource.BinarySI),
+	}, got)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kmsg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type DeviceErrorType string
+
+const (
+	// GPUXidError is the Xid error reported by the NVIDIA driver.
+	GPUXidError DeviceErrorType = "GPUXidError"
+	// NICLinkDown is reported when the link of the NIC goes down.
+	NICLinkDown DeviceErrorType = "NICLinkDown"
+	// NICLinkUp is reported when the link of the NIC comes up, which is not an error but used to detect the flaps.
+	NICLinkUp DeviceErrorType = "NICLinkUp"
+	// RDMALinkError is reported when the RDMA device reports the errors of its ports or links.
+	RDMALinkError DeviceErrorType = "RDMALinkError"
+)
+
+// DeviceError is a device error detected from the kernel log.
+type DeviceError struct {
+	Type DeviceErrorType
+	// BusID is the PCIe bus id of the device, which is "domain:bus:device" for the GPUs since the NVIDIA driver
+	// omits the function, and "domain:bus:device.function" for the others.
+	BusID string
+	// Name is the name of the device in the kernel, e.g. eth0 or mlx5_0.
+	Name string
+	// Xid is the Xid of GPUXidError.
+	Xid int
+	// Message is the kernel log reporting the error.
+	Message string
+}
+
+var (
+	// NVRM: Xid (PCI:0000:3b:00): 79, pid=1234, GPU has fallen off the bus.
+	gpuXidRegexp = regexp.MustCompile(`NVRM: Xid \(PCI:([0-9a-fA-F]+:[0-9a-fA-F]+:[0-9a-fA-F]+)\): (\d+)`)
+	// mlx5_core 0000:5e:00.0 eth0: Link down
+	// ixgbe 0000:01:00.0 eth0: NIC Link is Down
+	nicLinkRegexp = regexp.MustCompile(`^\S+ ([0-9a-fA-F]+:[0-9a-fA-F]+:[0-9a-fA-F]+\.[0-9a-fA-F]) (\S+): (?:NIC )?[Ll]ink (?:is )?([Dd]own|[Uu]p)`)
+	// infiniband mlx5_0: ib_query_port failed (-5)
+	// mlx5_core 0000:5e:00.0: mlx5_health_try_recover:303:(pid 1234): health recovery flow aborted, PCI reads still not working
+	rdmaErrorRegexp = regexp.MustCompile(`^(?:infiniband (\S+)|(?:mlx\d+_core|irdma|bnxt_re) ([0-9a-fA-F]+:[0-9a-fA-F]+:[0-9a-fA-F]+\.[0-9a-fA-F])):.*(?:[Ee]rror|[Ff]ail|[Ff]atal|[Ll]ink (?:is )?[Dd]own|[Hh]ealth)`)
+)
+
+// applicationXids are the Xids caused by the applications, which do not indicate the GPU is unhealthy.
+// See http://docs.nvidia.com/deploy/xid-errors/index.html#topic_4
+var applicationXids = map[int]bool{13: true, 31: true, 43: true, 45: true, 68: true}
+
+// IsCriticalXid checks if the Xid indicates the GPU is unhealthy.
+func IsCriticalXid(xid int) bool {
+	return !applicationXids[xid]
+}
+
+// ParseDeviceError parses the device error in the kernel log message, nil if the message reports no device error.
+func ParseDeviceError(message string) *DeviceError {
+	if m := gpuXidRegexp.FindStringSubmatch(message); m != nil {
+		xid, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil
+		}
+		return &DeviceError{Type: GPUXidError, BusID: NormalizeGPUBusID(m[1]), Xid: xid, Message: message}
+	}
+	if m := nicLinkRegexp.FindStringSubmatch(message); m != nil {
+		errorType := NICLinkUp
+		if strings.EqualFold(m[3], "down") {
+			errorType = NICLinkDown
+		}
+		return &DeviceError{Type: errorType, BusID: strings.ToLower(m[1]), Name: m[2], Message: message}
+	}
+	if m := rdmaErrorRegexp.FindStringSubmatch(message); m != nil {
+		return &DeviceError{Type: RDMALinkError, Name: m[1], BusID: strings.ToLower(m[2]), Message: message}
+	}
+	return nil
+}
+
+// NormalizeGPUBusID converts the PCIe bus id of the GPU to the format of the NVIDIA driver log, e.g. both
+// 00000000:3B:00.0 reported by NVML and 0000:3b:00 in the kernel log are converted to 0000:3b:00.
+func NormalizeGPUBusID(busID string) string {
+	busID = strings.ToLower(busID)
+	if i := strings.LastIndex(busID, "."); i >= 0 {
+		busID = busID[:i]
+	}
+	parts := strings.Split(busID, ":")
+	if len(parts) == 3 && len(parts[0]) > 4 {
+		parts[0] = parts[0][len(parts[0])-4:]
+	}
+	return strings.Join(parts, ":")
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kmsg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDeviceError(t *testing.T) {
+	tests := []struct {
+		message string
+		want    *DeviceError
+	}{
+		{
+			message: "NVRM: Xid (PCI:0000:3B:00): 79, pid=1234, GPU has fallen off the bus.",
+			want:    &DeviceError{Type: GPUXidError, BusID: "0000:3b:00", Xid: 79},
+		},
+		{
+			message: "mlx5_core 0000:5e:00.0 eth0: Link down",
+			want:    &DeviceError{Type: NICLinkDown, BusID: "0000:5e:00.0", Name: "eth0"},
+		},
+		{
+			message: "ixgbe 0000:01:00.1 enp1s0f1: NIC Link is Up 10 Gbps, Flow Control: RX/TX",
+			want:    &DeviceError{Type: NICLinkUp, BusID: "0000:01:00.1", Name: "enp1s0f1"},
+		},
+		{
+			message: "infiniband mlx5_0: ib_query_port failed (-5)",
+			want:    &DeviceError{Type: RDMALinkError, Name: "mlx5_0"},
+		},
+		{
+			message: "mlx5_core 0000:5E:00.0: mlx5_health_try_recover:303:(pid 1234): health recovery flow aborted",
+			want:    &DeviceError{Type: RDMALinkError, BusID: "0000:5e:00.0"},
+		},
+		{
+			message: "infiniband mlx5_0: mlx5_ib_add: mlx5_ib device registered",
+		},
+		{
+			message: "NET: Registered protocol family 10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got := ParseDeviceError(tt.message)
+			if tt.want != nil {
+				tt.want.Message = tt.message
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizeGPUBusID(t *testing.T) {
+	assert.Equal(t, "0000:3b:00", NormalizeGPUBusID("00000000:3B:00.0"))
+	assert.Equal(t, "0000:3b:00", NormalizeGPUBusID("0000:3b:00"))
+}
+
+func TestIsCriticalXid(t *testing.T) {
+	assert.False(t, IsCriticalXid(13))
+	assert.True(t, IsCriticalXid(79))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kmsg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// KmsgPath is the path of the kernel log device.
+var KmsgPath = "/dev/kmsg"
+
+// Record is a record of the kernel log read from /dev/kmsg.
+type Record struct {
+	// Priority is the syslog priority of the record, the lower 3 bits are the log level.
+	Priority int
+	// Sequence is the 64-bit record sequence number.
+	Sequence uint64
+	// Timestamp is the time since boot when the record is logged.
+	Timestamp time.Duration
+	// Message is the text of the record without the continuation lines.
+	Message string
+}
+
+// ParseRecord parses a record of /dev/kmsg, which is formatted as "priority,sequence,timestamp,flags;message".
+// See https://www.kernel.org/doc/Documentation/ABI/testing/dev-kmsg for details.
+func ParseRecord(line string) (*Record, error) {
+	// the continuation lines starting with a space are the key/value dictionary
+	line = strings.SplitN(line, "\n", 2)[0]
+	parts := strings.SplitN(line, ";", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid kmsg record %q", line)
+	}
+	fields := strings.Split(parts[0], ",")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid kmsg record prefix %q", parts[0])
+	}
+	priority, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid kmsg priority %q, err: %w", fields[0], err)
+	}
+	sequence, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kmsg sequence %q, err: %w", fields[1], err)
+	}
+	timestamp, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kmsg timestamp %q, err: %w", fields[2], err)
+	}
+	return &Record{
+		Priority:  priority,
+		Sequence:  sequence,
+		Timestamp: time.Duration(timestamp) * time.Microsecond,
+		Message:   parts[1],
+	}, nil
+}
+
+// Watcher reads the records appended to the kernel log.
+type Watcher struct {
+	path string
+}
+
+func NewWatcher(path string) *Watcher {
+	return &Watcher{path: path}
+}
+
+// Watch sends the records logged after the watch starts to the returned channel until stopCh is closed or the
+// kernel log fails to read. The channel is closed when the watch stops.
+func (w *Watcher) Watch(stopCh <-chan struct{}) (<-chan *Record, error) {
+	f, err := os.Open(w.path)
+	if err != nil {
+		return nil, err
+	}
+	// skip the records logged before
+	if _, err = f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return nil, err
+	}
+	go func() {
+		<-stopCh
+		f.Close()
+	}()
+
+	records := make(chan *Record, 100)
+	go func() {
+		defer close(records)
+		readRecords(f, records, stopCh)
+	}()
+	return records, nil
+}
+
+func readRecords(r io.Reader, records chan<- *Record, stopCh <-chan struct{}) {
+	// each read of /dev/kmsg returns exactly one record
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if errors.Is(err, syscall.EPIPE) {
+			// the records are overwritten before being read, continue from the next record
+			klog.V(4).Infof("kmsg records are overwritten before being read")
+			continue
+		}
+		if err != nil {
+			select {
+			case <-stopCh:
+			default:
+				if err != io.EOF {
+					klog.Errorf("failed to read kmsg, err: %v", err)
+				}
+			}
+			return
+		}
+		// the continuation lines of the previous record
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+		record, err := ParseRecord(strings.TrimSuffix(line, "\n"))
+		if err != nil {
+			klog.V(5).Infof("skip kmsg record, err: %v", err)
+			continue
+		}
+		select {
+		case records <- record:
+		case <-stopCh:
+			return
+		}
+	}
+}
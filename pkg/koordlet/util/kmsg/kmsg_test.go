@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kmsg
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecord(t *testing.T) {
+	record, err := ParseRecord("6,339,5140900,-;NET: Registered protocol family 10")
+	assert.NoError(t, err)
+	assert.Equal(t, &Record{
+		Priority:  6,
+		Sequence:  339,
+		Timestamp: 5140900 * time.Microsecond,
+		Message:   "NET: Registered protocol family 10",
+	}, record)
+
+	record, err = ParseRecord("3,1234,9876,c;mlx5_core 0000:5e:00.0 eth0: Link down;extra\n SUBSYSTEM=net")
+	assert.NoError(t, err)
+	assert.Equal(t, "mlx5_core 0000:5e:00.0 eth0: Link down;extra", record.Message)
+
+	for _, line := range []string{"", "no separator", "6,339;message", "x,339,5140900,-;message", "6,x,5140900,-;message", "6,339,x,-;message"} {
+		_, err = ParseRecord(line)
+		assert.Error(t, err, line)
+	}
+}
+
+func Test_readRecords(t *testing.T) {
+	input := "6,1,100,-;first\n SUBSYSTEM=pci\ninvalid\n4,2,200,-;second\n"
+	records := make(chan *Record, 10)
+	readRecords(strings.NewReader(input), records, make(chan struct{}))
+	close(records)
+	var messages []string
+	for r := range records {
+		messages = append(messages, r.Message)
+	}
+	assert.Equal(t, []string{"first", "second"}, messages)
+}
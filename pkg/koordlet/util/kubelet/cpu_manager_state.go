@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubelet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpumanager/state"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+// ParseCPUManagerCheckpoint parses the cpu_manager_state checkpoint of kubelet, and returns the CPUs pinned by the
+// static policy for each pod, which is the union of the CPUs assigned to its containers.
+func ParseCPUManagerCheckpoint(data []byte) (map[types.UID]cpuset.CPUSet, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty state file")
+	}
+	checkpoint := &state.CPUManagerCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+	podCPUs := map[types.UID]cpuset.CPUSet{}
+	for podUID, entries := range checkpoint.Entries {
+		cpuSet := cpuset.NewCPUSet()
+		for container, cpuString := range entries {
+			containerCPUSet, err := cpuset.Parse(cpuString)
+			if err != nil {
+				klog.Errorf("could not parse cpuset %q for container %q in pod %q: %v", cpuString, container, podUID, err)
+				continue
+			}
+			cpuSet = cpuSet.Union(containerCPUSet)
+		}
+		if !cpuSet.IsEmpty() {
+			podCPUs[types.UID(podUID)] = cpuSet
+		}
+	}
+	return podCPUs, nil
+}
+
+// GetKubeletPinnedCPUs returns the CPUs pinned by kubelet in the reported pod CPU allocations, except the ones of the
+// specified pod.
+func GetKubeletPinnedCPUs(podAllocs extension.PodCPUAllocs, excludedPodUID types.UID) cpuset.CPUSet {
+	builder := cpuset.NewCPUSetBuilder()
+	for _, alloc := range podAllocs {
+		if !alloc.ManagedByKubelet || alloc.UID == excludedPodUID {
+			continue
+		}
+		cpus, err := cpuset.Parse(alloc.CPUSet)
+		if err != nil {
+			klog.V(4).Infof("failed to parse cpuset %q pinned by kubelet for pod %s, err: %v", alloc.CPUSet, alloc.UID, err)
+			continue
+		}
+		builder.Add(cpus.ToSliceNoSort()...)
+	}
+	return builder.Result()
+}
+
+// ExcludeKubeletPinnedCPUs removes the CPUs pinned by kubelet for other pods from the cpuset allocated to the pod,
+// since kubelet never moves the pinned CPUs and the two managers would keep overwriting each other. It returns true
+// if the allocated cpuset conflicts with the pinned CPUs. The allocated cpuset is kept if all of it is pinned, so that
+// the pod is not left without any CPUs.
+func ExcludeKubeletPinnedCPUs(allocated cpuset.CPUSet, podAllocs extension.PodCPUAllocs, podUID types.UID) (cpuset.CPUSet, bool) {
+	pinned := GetKubeletPinnedCPUs(podAllocs, podUID)
+	if allocated.Intersection(pinned).IsEmpty() {
+		return allocated, false
+	}
+	remaining := allocated.Difference(pinned)
+	if remaining.IsEmpty() {
+		return allocated, true
+	}
+	return remaining, true
+}
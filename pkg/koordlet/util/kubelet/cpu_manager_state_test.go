@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubelet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+func TestParseCPUManagerCheckpoint(t *testing.T) {
+	_, err := ParseCPUManagerCheckpoint(nil)
+	assert.Error(t, err)
+	_, err = ParseCPUManagerCheckpoint([]byte("bad-format"))
+	assert.Error(t, err)
+
+	podCPUs, err := ParseCPUManagerCheckpoint([]byte(`{
+		"policyName": "static",
+		"defaultCPUSet": "0,5-7",
+		"entries": {
+			"pod-1": {"main": "1-2", "sidecar": "3"},
+			"pod-2": {"main": "4", "bad": "x"},
+			"pod-3": {"main": ""}
+		},
+		"checksum": 1
+	}`))
+	assert.NoError(t, err)
+	expected := map[types.UID]cpuset.CPUSet{
+		"pod-1": cpuset.MustParse("1-3"),
+		"pod-2": cpuset.MustParse("4"),
+	}
+	assert.Equal(t, len(expected), len(podCPUs))
+	for podUID, cpus := range expected {
+		assert.True(t, cpus.Equals(podCPUs[podUID]), "pod %s, expected %v, got %v", podUID, cpus, podCPUs[podUID])
+	}
+}
+
+func TestExcludeKubeletPinnedCPUs(t *testing.T) {
+	podAllocs := extension.PodCPUAllocs{
+		{UID: "kubelet-pod-1", CPUSet: "2-3", ManagedByKubelet: true},
+		{UID: "kubelet-pod-2", CPUSet: "6", ManagedByKubelet: true},
+		{UID: "koord-pod", CPUSet: "4-5"},
+	}
+	assert.Equal(t, "2-3,6", GetKubeletPinnedCPUs(podAllocs, "").String())
+
+	tests := []struct {
+		name           string
+		allocated      string
+		podUID         types.UID
+		want           string
+		wantConflicted bool
+	}{
+		{
+			name:      "no conflicts",
+			allocated: "0-1,4-5",
+			podUID:    "test-pod",
+			want:      "0-1,4-5",
+		},
+		{
+			name:           "exclude the pinned cpus",
+			allocated:      "0-3",
+			podUID:         "test-pod",
+			want:           "0-1",
+			wantConflicted: true,
+		},
+		{
+			name:      "the cpus pinned for the pod itself",
+			allocated: "2-3",
+			podUID:    "kubelet-pod-1",
+			want:      "2-3",
+		},
+		{
+			name:           "keep the allocated cpus if all pinned",
+			allocated:      "6",
+			podUID:         "test-pod",
+			want:           "6",
+			wantConflicted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicted := ExcludeKubeletPinnedCPUs(cpuset.MustParse(tt.allocated), podAllocs, tt.podUID)
+			assert.Equal(t, tt.want, got.String())
+			assert.Equal(t, tt.wantConflicted, conflicted)
+		})
+	}
+}
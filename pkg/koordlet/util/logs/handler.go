@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+)
+
+// LevelsResponse is the response of the log level handler.
+type LevelsResponse struct {
+	Verbosity    klog.Level            `json:"verbosity"`
+	ModuleLevels map[string]klog.Level `json:"moduleLevels,omitempty"`
+}
+
+// HttpHandler serves the log verbosity of the koordlet.
+//   - GET returns the global verbosity and the module overrides.
+//   - PUT/POST with query "level" sets the verbosity of the module given by query "module", or the global
+//     verbosity if no module specified.
+//   - DELETE with query "module" removes the override of the module.
+func HttpHandler() func(http.ResponseWriter, *http.Request) {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		module := r.URL.Query().Get("module")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level, err := parseLevel(r.URL.Query().Get("level"))
+			if err != nil {
+				http.Error(rw, fmt.Sprintf("invalid level, err: %v", err), http.StatusBadRequest)
+				return
+			}
+			if module == "" {
+				if err = SetGlobalLevel(level); err != nil {
+					http.Error(rw, fmt.Sprintf("failed to set verbosity, err: %v", err), http.StatusInternalServerError)
+					return
+				}
+			} else {
+				SetModuleLevel(module, level)
+			}
+			klog.Infof("log verbosity updated, client=%v module=%q level=%v", r.RemoteAddr, module, level)
+		case http.MethodDelete:
+			if module == "" {
+				http.Error(rw, "module is required", http.StatusBadRequest)
+				return
+			}
+			ResetModuleLevel(module)
+			klog.Infof("log verbosity of module %q reset, client=%v", module, r.RemoteAddr)
+		default:
+			http.Error(rw, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(&LevelsResponse{
+			Verbosity:    GetGlobalLevel(),
+			ModuleLevels: GetModuleLevels(),
+		}); err != nil {
+			klog.Errorf("failed to write log levels response, err: %v", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog/v2"
+)
+
+func TestHttpHandler(t *testing.T) {
+	oldLevel := GetGlobalLevel()
+	defer func() {
+		assert.NoError(t, SetGlobalLevel(oldLevel))
+		ResetModuleLevel(ModuleRuntimeHooks)
+	}()
+	assert.NoError(t, SetGlobalLevel(2))
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		wantCode int
+		want     *LevelsResponse
+	}{
+		{
+			name:     "get levels",
+			method:   http.MethodGet,
+			url:      "/debug/loglevel",
+			wantCode: http.StatusOK,
+			want:     &LevelsResponse{Verbosity: 2},
+		},
+		{
+			name:     "set module level",
+			method:   http.MethodPut,
+			url:      "/debug/loglevel?module=runtimehooks&level=5",
+			wantCode: http.StatusOK,
+			want:     &LevelsResponse{Verbosity: 2, ModuleLevels: map[string]klog.Level{ModuleRuntimeHooks: 5}},
+		},
+		{
+			name:     "set global level",
+			method:   http.MethodPost,
+			url:      "/debug/loglevel?level=3",
+			wantCode: http.StatusOK,
+			want:     &LevelsResponse{Verbosity: 3, ModuleLevels: map[string]klog.Level{ModuleRuntimeHooks: 5}},
+		},
+		{
+			name:     "invalid level",
+			method:   http.MethodPut,
+			url:      "/debug/loglevel?module=runtimehooks&level=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "reset module without name",
+			method:   http.MethodDelete,
+			url:      "/debug/loglevel",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "reset module level",
+			method:   http.MethodDelete,
+			url:      "/debug/loglevel?module=runtimehooks",
+			wantCode: http.StatusOK,
+			want:     &LevelsResponse{Verbosity: 3},
+		},
+		{
+			name:     "method not allowed",
+			method:   http.MethodPatch,
+			url:      "/debug/loglevel",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	handler := HttpHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			handler(rw, httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, tt.wantCode, rw.Code)
+			if tt.want != nil {
+				got := &LevelsResponse{}
+				assert.NoError(t, json.Unmarshal(rw.Body.Bytes(), got))
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+	cliflag "k8s.io/component-base/cli/flag"
+	logsjson "k8s.io/component-base/logs/json"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// FormatText is the default klog text format.
+	FormatText = "text"
+	// FormatJSON emits each log entry as a JSON object.
+	FormatJSON = "json"
+
+	// moduleKey is the key of the module name attached to each entry of a module logger.
+	moduleKey = "module"
+)
+
+// Module names of the koordlet whose verbosity can be adjusted independently.
+const (
+	ModuleMetricsAdvisor = "metricsadvisor"
+	ModuleRuntimeHooks   = "runtimehooks"
+	ModuleDeviceShare    = "deviceshare"
+)
+
+type Config struct {
+	Format       string
+	ModuleLevels map[string]string
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Format:       FormatText,
+		ModuleLevels: map[string]string{},
+	}
+}
+
+func (c *Config) InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Format, "logging-format", c.Format, "Sets the log format. Permitted formats: \"text\", \"json\".")
+	fs.Var(cliflag.NewMapStringString(&c.ModuleLevels), "log-module-levels", "A set of module=level pairs that overrides the log verbosity of the koordlet modules, "+
+		"e.g. \"runtimehooks=5,metricsadvisor=1\". Modules without a level follow the global verbosity -v.")
+}
+
+// Setup applies the logging format and the initial module levels.
+func Setup(c *Config) error {
+	switch c.Format {
+	case "", FormatText:
+	case FormatJSON:
+		klog.SetLogger(logsjson.NewJSONLogger(zapcore.Lock(os.Stderr)))
+	default:
+		return fmt.Errorf("unsupported logging format %q", c.Format)
+	}
+	for module, levelStr := range c.ModuleLevels {
+		level, err := parseLevel(levelStr)
+		if err != nil {
+			return fmt.Errorf("invalid level of log module %s, err: %v", module, err)
+		}
+		SetModuleLevel(module, level)
+	}
+	return nil
+}
+
+var (
+	lock         sync.RWMutex
+	moduleLevels = map[string]klog.Level{}
+
+	// klogFlags is a private flag set bound to the klog global settings, so the global verbosity can be
+	// read and adjusted at runtime.
+	klogFlags = func() *flag.FlagSet {
+		fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+		klog.InitFlags(fs)
+		return fs
+	}()
+)
+
+// SetModuleLevel overrides the verbosity of the module.
+func SetModuleLevel(module string, level klog.Level) {
+	lock.Lock()
+	defer lock.Unlock()
+	moduleLevels[module] = level
+}
+
+// ResetModuleLevel removes the verbosity override of the module, so it follows the global verbosity again.
+func ResetModuleLevel(module string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(moduleLevels, module)
+}
+
+// GetModuleLevels returns a copy of the module verbosity overrides.
+func GetModuleLevels() map[string]klog.Level {
+	lock.RLock()
+	defer lock.RUnlock()
+	levels := make(map[string]klog.Level, len(moduleLevels))
+	for module, level := range moduleLevels {
+		levels[module] = level
+	}
+	return levels
+}
+
+func getModuleLevel(module string) (klog.Level, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	level, ok := moduleLevels[module]
+	return level, ok
+}
+
+// GetGlobalLevel returns the global klog verbosity.
+func GetGlobalLevel() klog.Level {
+	level, _ := parseLevel(klogFlags.Lookup("v").Value.String())
+	return level
+}
+
+// SetGlobalLevel sets the global klog verbosity.
+func SetGlobalLevel(level klog.Level) error {
+	return klogFlags.Set("v", strconv.Itoa(int(level)))
+}
+
+func parseLevel(s string) (klog.Level, error) {
+	level, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if level < 0 {
+		return 0, fmt.Errorf("level %d is negative", level)
+	}
+	return klog.Level(level), nil
+}
+
+// Logger writes structured logs tagged with the module name. The verbosity of a module can be overridden at
+// runtime, otherwise it follows the global klog verbosity.
+type Logger struct {
+	module string
+}
+
+func NewLogger(module string) Logger {
+	return Logger{module: module}
+}
+
+// V reports whether verbosity at the call site is at least the requested level.
+func (l Logger) V(level klog.Level) Verbose {
+	if moduleLevel, ok := getModuleLevel(l.module); ok {
+		return Verbose{module: l.module, enabled: level <= moduleLevel}
+	}
+	return Verbose{module: l.module, enabled: klog.V(level).Enabled()}
+}
+
+func (l Logger) Info(args ...interface{}) {
+	klog.InfoSDepth(1, fmt.Sprint(args...), moduleKey, l.module)
+}
+
+func (l Logger) Infof(format string, args ...interface{}) {
+	klog.InfoSDepth(1, fmt.Sprintf(format, args...), moduleKey, l.module)
+}
+
+func (l Logger) InfoS(msg string, keysAndValues ...interface{}) {
+	klog.InfoSDepth(1, msg, append([]interface{}{moduleKey, l.module}, keysAndValues...)...)
+}
+
+// Warning and Warningf append the module to the message since klog has no structured warning.
+func (l Logger) Warning(args ...interface{}) {
+	klog.WarningDepth(1, fmt.Sprint(args...), " ", moduleKey, "=", l.module)
+}
+
+func (l Logger) Warningf(format string, args ...interface{}) {
+	klog.WarningDepth(1, fmt.Sprintf(format, args...), " ", moduleKey, "=", l.module)
+}
+
+func (l Logger) Error(args ...interface{}) {
+	klog.ErrorSDepth(1, nil, fmt.Sprint(args...), moduleKey, l.module)
+}
+
+func (l Logger) Errorf(format string, args ...interface{}) {
+	klog.ErrorSDepth(1, nil, fmt.Sprintf(format, args...), moduleKey, l.module)
+}
+
+func (l Logger) ErrorS(err error, msg string, keysAndValues ...interface{}) {
+	klog.ErrorSDepth(1, err, msg, append([]interface{}{moduleKey, l.module}, keysAndValues...)...)
+}
+
+// Fatal and Fatalf append the module to the message like Warning, then exit the program.
+func (l Logger) Fatal(args ...interface{}) {
+	klog.FatalDepth(1, fmt.Sprint(args...), " ", moduleKey, "=", l.module)
+}
+
+func (l Logger) Fatalf(format string, args ...interface{}) {
+	klog.FatalDepth(1, fmt.Sprintf(format, args...), " ", moduleKey, "=", l.module)
+}
+
+// Verbose is a boolean type that implements Info, Infof and InfoS like klog.Verbose.
+type Verbose struct {
+	module  string
+	enabled bool
+}
+
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+func (v Verbose) Info(args ...interface{}) {
+	if v.enabled {
+		klog.InfoSDepth(1, fmt.Sprint(args...), moduleKey, v.module)
+	}
+}
+
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v.enabled {
+		klog.InfoSDepth(1, fmt.Sprintf(format, args...), moduleKey, v.module)
+	}
+}
+
+func (v Verbose) InfoS(msg string, keysAndValues ...interface{}) {
+	if v.enabled {
+		klog.InfoSDepth(1, msg, append([]interface{}{moduleKey, v.module}, keysAndValues...)...)
+	}
+}
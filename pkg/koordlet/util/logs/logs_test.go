@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog/v2"
+)
+
+func TestConfigInitFlags(t *testing.T) {
+	cfg := NewDefaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.InitFlags(fs)
+	err := fs.Parse([]string{"--logging-format=json", "--log-module-levels=runtimehooks=5, metricsadvisor=1"})
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, cfg.Format)
+	assert.Equal(t, map[string]string{ModuleRuntimeHooks: "5", ModuleMetricsAdvisor: "1"}, cfg.ModuleLevels)
+}
+
+func TestSetup(t *testing.T) {
+	defer ResetModuleLevel(ModuleRuntimeHooks)
+	assert.Error(t, Setup(&Config{Format: "xml"}))
+	assert.Error(t, Setup(&Config{Format: FormatText, ModuleLevels: map[string]string{ModuleRuntimeHooks: "-1"}}))
+	assert.NoError(t, Setup(&Config{Format: FormatText, ModuleLevels: map[string]string{ModuleRuntimeHooks: "4"}}))
+	assert.Equal(t, map[string]klog.Level{ModuleRuntimeHooks: 4}, GetModuleLevels())
+}
+
+func TestLoggerV(t *testing.T) {
+	oldLevel := GetGlobalLevel()
+	defer func() {
+		assert.NoError(t, SetGlobalLevel(oldLevel))
+		ResetModuleLevel(ModuleDeviceShare)
+	}()
+	assert.NoError(t, SetGlobalLevel(2))
+	assert.Equal(t, klog.Level(2), GetGlobalLevel())
+
+	logger := NewLogger(ModuleDeviceShare)
+	assert.True(t, logger.V(2).Enabled())
+	assert.False(t, logger.V(5).Enabled())
+
+	SetModuleLevel(ModuleDeviceShare, 5)
+	assert.True(t, logger.V(5).Enabled())
+	assert.False(t, logger.V(6).Enabled())
+	assert.False(t, NewLogger(ModuleMetricsAdvisor).V(5).Enabled(), "other modules follow the global level")
+
+	SetModuleLevel(ModuleDeviceShare, 0)
+	assert.False(t, logger.V(2).Enabled(), "module level can be lower than the global level")
+
+	ResetModuleLevel(ModuleDeviceShare)
+	assert.True(t, logger.V(2).Enabled())
+}
+
+func TestLoggerOutput(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	assert.NoError(t, fs.Set("logtostderr", "false"))
+	assert.NoError(t, fs.Set("skip_headers", "true"))
+	defer func() {
+		assert.NoError(t, fs.Set("logtostderr", "true"))
+		assert.NoError(t, fs.Set("skip_headers", "false"))
+		klog.SetOutput(nil)
+		ResetModuleLevel(ModuleDeviceShare)
+	}()
+	buf := &bytes.Buffer{}
+	klog.SetOutput(buf)
+
+	SetModuleLevel(ModuleDeviceShare, 4)
+	logger := NewLogger(ModuleDeviceShare)
+	logger.V(4).Infof("allocate gpu %d", 1)
+	logger.V(5).Infof("too verbose")
+	logger.ErrorS(nil, "failed to inject env", "container", "c1")
+	klog.Flush()
+
+	out := buf.String()
+	assert.True(t, strings.Contains(out, `"allocate gpu 1" module="deviceshare"`), out)
+	assert.False(t, strings.Contains(out, "too verbose"), out)
+	assert.True(t, strings.Contains(out, `"failed to inject env" module="deviceshare" container="c1"`), out)
+}
@@ -72,6 +72,8 @@ type MemInfo struct {
 	DirectMap1G       uint64 `json:"direct_map_1G"`
 }
 
+// readMemInfo parses the meminfo of the node, or the meminfo of a NUMA node whose lines are prefixed with the
+// NUMA node id, e.g. `Node 0 MemTotal:       32859616 kB`.
 func readMemInfo(path string) (*MemInfo, error) {
 	data, err := os.ReadFile(path)
 
@@ -91,9 +93,13 @@ func readMemInfo(path string) (*MemInfo, error) {
 		if len(fields) < 2 {
 			continue
 		}
+		keyFields := strings.Fields(fields[0])
 		valFields := strings.Fields(fields[1])
+		if len(keyFields) == 0 || len(valFields) == 0 {
+			continue
+		}
 		val, _ := strconv.ParseUint(valFields[0], 10, 64)
-		statMap[fields[0]] = val
+		statMap[keyFields[len(keyFields)-1]] = val
 	}
 
 	elem := reflect.ValueOf(&info).Elem()
@@ -124,3 +130,40 @@ func GetMemInfoUsageKB() (int64, error) {
 	usage := int64(memInfo.MemTotal - memInfo.MemAvailable)
 	return usage, nil
 }
+
+// GetMemInfoSwapTotalKB returns the node's swap space quantity (kB)
+func GetMemInfoSwapTotalKB() (uint64, error) {
+	meminfoPath := system.GetProcFilePath(system.ProcMemInfoName)
+	memInfo, err := readMemInfo(meminfoPath)
+	if err != nil {
+		return 0, err
+	}
+	return memInfo.SwapTotal, nil
+}
+
+// GetNUMANodeMemInfos returns the meminfo of all NUMA nodes on the host, indexed by the NUMA node id.
+func GetNUMANodeMemInfos() (map[int]*MemInfo, error) {
+	numaNodeIDs, err := system.GetNUMANodeIDs()
+	if err != nil {
+		return nil, err
+	}
+	memInfos := make(map[int]*MemInfo, len(numaNodeIDs))
+	for _, numaNodeID := range numaNodeIDs {
+		memInfo, err := readMemInfo(system.GetNUMANodeMemInfoPath(numaNodeID))
+		if err != nil {
+			return nil, err
+		}
+		memInfos[numaNodeID] = memInfo
+	}
+	return memInfos, nil
+}
+
+// GetNUMAMemUsageKB returns the memory usage quantity (kB) of the NUMA node without the page cache and the reclaimable
+// slab. The meminfo of NUMA nodes has no MemAvailable, so they are considered available like the kernel estimates.
+func GetNUMAMemUsageKB(memInfo *MemInfo) int64 {
+	available := memInfo.MemFree + memInfo.ActiveFile + memInfo.InactiveFile + memInfo.SReclaimable
+	if available >= memInfo.MemTotal {
+		return 0
+	}
+	return int64(memInfo.MemTotal - available)
+}
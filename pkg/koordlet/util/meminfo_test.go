@@ -23,6 +23,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
 
 func Test_readMemInfo(t *testing.T) {
@@ -172,3 +174,47 @@ func Test_GetMemInfoUsageKB(t *testing.T) {
 	}
 	t.Log("meminfo: ", memInfoUsage)
 }
+
+func Test_GetMemInfoSwapTotalKB(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldProcRootDir := system.Conf.ProcRootDir
+	system.Conf.ProcRootDir = filepath.Join(helper.TempDir, "proc")
+	defer func() {
+		system.Conf.ProcRootDir = oldProcRootDir
+	}()
+
+	helper.WriteFileContents(system.GetProcFilePath(system.ProcMemInfoName), "MemTotal:       263432804 kB\n"+
+		"MemFree:        254391744 kB\nSwapTotal:       8388604 kB\nSwapFree:        8388604 kB\n")
+	got, err := GetMemInfoSwapTotalKB()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8388604), got)
+}
+
+func Test_GetNUMANodeMemInfos(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+
+	helper.WriteFileContents(system.GetNUMANodeMemInfoPath(0), "Node 0 MemTotal:       32859616 kB\n"+
+		"Node 0 MemFree:        20000000 kB\nNode 0 MemUsed:        12859616 kB\nNode 0 Active(file):    1000000 kB\n"+
+		"Node 0 Inactive(file):  2000000 kB\nNode 0 SReclaimable:    500000 kB\nNode 0 HugePages_Total:     0\n")
+	helper.WriteFileContents(system.GetNUMANodeMemInfoPath(1), "Node 1 MemTotal:       33000000 kB\n"+
+		"Node 1 MemFree:        33000000 kB\nNode 1 MemUsed:               0 kB\n")
+	got, err := GetNUMANodeMemInfos()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]*MemInfo{
+		0: {MemTotal: 32859616, MemFree: 20000000, ActiveFile: 1000000, InactiveFile: 2000000, SReclaimable: 500000},
+		1: {MemTotal: 33000000, MemFree: 33000000},
+	}, got)
+	assert.Equal(t, int64(9359616), GetNUMAMemUsageKB(got[0]))
+	assert.Equal(t, int64(0), GetNUMAMemUsageKB(got[1]))
+
+	helper.WriteFileContents(system.GetNUMANodeStatPath(2), "numa_hit 0\n")
+	_, err = GetNUMANodeMemInfos()
+	assert.Error(t, err)
+}
@@ -17,9 +17,7 @@ limitations under the License.
 package util
 
 import (
-	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -118,39 +116,19 @@ func GetKubeQoSByCgroupParent(cgroupDir string) corev1.PodQOSClass {
 }
 
 func GetPodCurCPUShare(podParentDir string) (int64, error) {
-	cgroupPath := GetPodCgroupCPUSharePath(podParentDir)
-	rawContent, err := os.ReadFile(cgroupPath)
-	if err != nil {
-		return 0, err
-	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadCPUShares(GetPodCgroupDirWithKube(podParentDir))
 }
 
 func GetPodCurCFSPeriod(podParentDir string) (int64, error) {
-	cgroupPath := GetPodCgroupCFSPeriodPath(podParentDir)
-	rawContent, err := os.ReadFile(cgroupPath)
-	if err != nil {
-		return 0, err
-	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadCPUPeriod(GetPodCgroupDirWithKube(podParentDir))
 }
 
 func GetPodCurCFSQuota(podParentDir string) (int64, error) {
-	cgroupPath := GetPodCgroupCFSQuotaPath(podParentDir)
-	rawContent, err := os.ReadFile(cgroupPath)
-	if err != nil {
-		return 0, err
-	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadCPUQuota(GetPodCgroupDirWithKube(podParentDir))
 }
 
 func GetPodCurMemLimitBytes(podParentDir string) (int64, error) {
-	cgroupPath := GetPodCgroupMemLimitPath(podParentDir)
-	rawContent, err := os.ReadFile(cgroupPath)
-	if err != nil {
-		return 0, err
-	}
-	return strconv.ParseInt(strings.TrimSpace(string(rawContent)), 10, 64)
+	return resourceexecutor.NewCgroupReader().ReadMemoryLimit(GetPodCgroupDirWithKube(podParentDir))
 }
 
 // @return like kubepods.slice/kubepods-burstable.slice/
@@ -297,3 +297,68 @@ func Test_GetKubeQoSByCgroupParent(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetPodCurCgroupValues(t *testing.T) {
+	system.SetupCgroupPathFormatter(system.Systemd)
+	podParentDir := "kubepods-burstable.slice/kubepods-poduid1.slice"
+	podDir := GetPodCgroupDirWithKube(podParentDir)
+	tests := []struct {
+		name          string
+		useCgroupsV2  bool
+		files         map[system.Resource]string
+		wantCPUShares int64
+		wantCFSQuota  int64
+		wantCFSPeriod int64
+		wantMemLimit  int64
+	}{
+		{
+			name: "read on cgroups-v1",
+			files: map[system.Resource]string{
+				system.CPUShares:    "1024",
+				system.CPUCFSQuota:  "200000",
+				system.CPUCFSPeriod: "100000",
+				system.MemoryLimit:  "1073741824",
+			},
+			wantCPUShares: 1024,
+			wantCFSQuota:  200000,
+			wantCFSPeriod: 100000,
+			wantMemLimit:  1073741824,
+		},
+		{
+			name:         "read on cgroups-v2",
+			useCgroupsV2: true,
+			files: map[system.Resource]string{
+				system.CPUSharesV2:   "100",
+				system.CPUCFSQuotaV2: "max 100000",
+				system.MemoryLimitV2: "max",
+			},
+			wantCPUShares: 1024,
+			wantCFSQuota:  -1,
+			wantCFSPeriod: 100000,
+			wantMemLimit:  -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := system.NewFileTestUtil(t)
+			defer helper.Cleanup()
+			for r, v := range tt.files {
+				helper.WriteCgroupFileContents(podDir, r, v)
+			}
+			helper.SetCgroupsV2(tt.useCgroupsV2)
+
+			got, err := GetPodCurCPUShare(podParentDir)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCPUShares, got)
+			got, err = GetPodCurCFSQuota(podParentDir)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCFSQuota, got)
+			got, err = GetPodCurCFSPeriod(podParentDir)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCFSPeriod, got)
+			got, err = GetPodCurMemLimitBytes(podParentDir)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMemLimit, got)
+		})
+	}
+}
@@ -101,6 +101,16 @@ func (c *ContainerdRuntimeHandler) UpdateContainerResources(containerID string,
 	return err
 }
 
+func (c *ContainerdRuntimeHandler) ListContainerStats() ([]*runtimeapi.ContainerStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	resp, err := c.runtimeServiceClient.ListContainerStats(ctx, &runtimeapi.ListContainerStatsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetStats(), nil
+}
+
 func getRuntimeClient(endpoint string) (runtimeapi.RuntimeServiceClient, error) {
 	conn, err := getClientConnection(endpoint)
 	if err != nil {
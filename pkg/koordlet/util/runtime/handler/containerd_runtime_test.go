@@ -28,6 +28,7 @@ import (
 	"github.com/prashantv/gostub"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
 )
@@ -120,3 +121,49 @@ func Test_Containerd_UpdateContainerResources(t *testing.T) {
 		})
 	}
 }
+
+func Test_Containerd_ListContainerStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     *runtimeapi.ListContainerStatsResponse
+		runtimeError error
+		want         []*runtimeapi.ContainerStats
+		expectError  bool
+	}{
+		{
+			name: "test_ListContainerStats_success",
+			response: &runtimeapi.ListContainerStatsResponse{
+				Stats: []*runtimeapi.ContainerStats{
+					{
+						Attributes: &runtimeapi.ContainerAttributes{Id: "test_container_id"},
+						Cpu:        &runtimeapi.CpuUsage{UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: 1000}},
+					},
+				},
+			},
+			want: []*runtimeapi.ContainerStats{
+				{
+					Attributes: &runtimeapi.ContainerAttributes{Id: "test_container_id"},
+					Cpu:        &runtimeapi.CpuUsage{UsageCoreNanoSeconds: &runtimeapi.UInt64Value{Value: 1000}},
+				},
+			},
+		},
+		{
+			name:         "test_ListContainerStats_fail",
+			runtimeError: fmt.Errorf("ListContainerStats error"),
+			expectError:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockRuntimeClient := mock_client2.NewMockRuntimeServiceClient(ctl)
+			mockRuntimeClient.EXPECT().ListContainerStats(gomock.Any(), gomock.Any()).Return(tt.response, tt.runtimeError)
+
+			runtimeHandler := ContainerdRuntimeHandler{runtimeServiceClient: mockRuntimeClient, timeout: 1, endpoint: ContainerdEndpoint1}
+			got, gotErr := runtimeHandler.ListContainerStats()
+			assert.Equal(t, tt.expectError, gotErr != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
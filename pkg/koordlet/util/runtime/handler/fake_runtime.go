@@ -17,6 +17,7 @@ limitations under the License.
 package handler
 
 import (
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	"k8s.io/cri-api/pkg/apis/testing"
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/statesinformer"
@@ -42,3 +43,11 @@ func (f *FakeRuntimeHandler) SetFakeContainers(containers []*testing.FakeContain
 func (f *FakeRuntimeHandler) UpdateContainerResources(containerID string, opts UpdateOptions) error {
 	return nil
 }
+
+func (f *FakeRuntimeHandler) SetFakeContainerStats(containerStats []*runtimeapi.ContainerStats) {
+	f.fakeRuntimeService.SetFakeContainerStats(containerStats)
+}
+
+func (f *FakeRuntimeHandler) ListContainerStats() ([]*runtimeapi.ContainerStats, error) {
+	return f.fakeRuntimeService.ListContainerStats(nil)
+}
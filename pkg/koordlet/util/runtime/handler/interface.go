@@ -16,7 +16,11 @@ limitations under the License.
 
 package handler
 
-import "time"
+import (
+	"time"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
 
 const (
 	// unixProtocol is the network protocol of unix socket.
@@ -29,6 +33,11 @@ type ContainerRuntimeHandler interface {
 	UpdateContainerResources(containerID string, opts UpdateOptions) error
 }
 
+// ContainerStatsHandler lists the resource usages of all containers through the CRI stats API.
+type ContainerStatsHandler interface {
+	ListContainerStats() ([]*runtimeapi.ContainerStats, error)
+}
+
 type UpdateOptions struct {
 	// CPU CFS (Completely Fair Scheduler) period. Default: 0 (not specified).
 	CPUPeriod int64
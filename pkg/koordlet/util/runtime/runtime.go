@@ -48,6 +48,20 @@ func GetRuntimeHandler(runtimeType string) (handler.ContainerRuntimeHandler, err
 	}
 }
 
+// GetContainerStatsHandler returns the handler listing the container stats through the CRI, which is only supported
+// by the containerd runtime.
+func GetContainerStatsHandler() (handler.ContainerStatsHandler, error) {
+	h, err := GetRuntimeHandler("containerd")
+	if err != nil {
+		return nil, err
+	}
+	statsHandler, ok := h.(handler.ContainerStatsHandler)
+	if !ok {
+		return nil, fmt.Errorf("runtime handler %T does not support container stats", h)
+	}
+	return statsHandler, nil
+}
+
 func getDockerHandler() (handler.ContainerRuntimeHandler, error) {
 	if DockerHandler != nil {
 		return DockerHandler, nil
@@ -27,6 +27,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/perf"
 	"github.com/koordinator-sh/koordinator/pkg/koordlet/util/system"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
 
 var (
@@ -67,6 +68,70 @@ func GetCPUStatUsageTicks() (uint64, error) {
 	return readTotalCPUStat(statPath)
 }
 
+// readPerCPUStatUsageTicks returns the usage ticks of each cpu in the stat, indexed by the cpu id.
+func readPerCPUStatUsageTicks(statPath string) (map[int]uint64, error) {
+	rawStats, err := os.ReadFile(statPath)
+	if err != nil {
+		return nil, err
+	}
+	cpuTicks := map[int]uint64{}
+	for _, stat := range strings.Split(string(rawStats), "\n") {
+		fieldStat := strings.Fields(stat)
+		if len(fieldStat) == 0 || fieldStat[0] == "cpu" || !strings.HasPrefix(fieldStat[0], "cpu") {
+			continue
+		}
+		cpuID, err := strconv.Atoi(strings.TrimPrefix(fieldStat[0], "cpu"))
+		if err != nil {
+			continue
+		}
+		if len(fieldStat) <= 7 {
+			return nil, fmt.Errorf("%s is illegally formatted", statPath)
+		}
+		var total uint64 = 0
+		// format: cpuN $user $nice $system $idle $iowait $irq $softirq
+		for _, i := range []int{1, 2, 3, 6, 7} {
+			v, err := strconv.ParseUint(fieldStat[i], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse cpu stat %s, err: %s", stat, err)
+			}
+			total += v
+		}
+		cpuTicks[cpuID] = total
+	}
+	return cpuTicks, nil
+}
+
+// GetNUMANodeCPUStatUsageTicks returns the CPU usage ticks of each NUMA node, which are the sum of the ticks of the
+// cpus in the cpulist of the NUMA node.
+func GetNUMANodeCPUStatUsageTicks() (map[int]uint64, error) {
+	cpuTicks, err := readPerCPUStatUsageTicks(system.GetProcFilePath(system.ProcStatName))
+	if err != nil {
+		return nil, err
+	}
+	numaNodeIDs, err := system.GetNUMANodeIDs()
+	if err != nil {
+		return nil, err
+	}
+	numaTicks := make(map[int]uint64, len(numaNodeIDs))
+	for _, numaNodeID := range numaNodeIDs {
+		cpuListPath := system.GetNUMANodeCPUListPath(numaNodeID)
+		cpuList, err := os.ReadFile(cpuListPath)
+		if err != nil {
+			return nil, err
+		}
+		cpus, err := cpuset.Parse(strings.TrimSpace(string(cpuList)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s, err: %v", cpuListPath, err)
+		}
+		var total uint64 = 0
+		for _, cpuID := range cpus.ToSliceNoSort() {
+			total += cpuTicks[cpuID]
+		}
+		numaTicks[numaNodeID] = total
+	}
+	return numaTicks, nil
+}
+
 func readCPUAcctStatUsageTicks(statPath string) (uint64, error) {
 	// format: user $user\nnice $nice\nsystem $system\nidle $idle\niowait $iowait\nirq $irq\nsoftirq $softirq
 	rawStats, err := os.ReadFile(statPath)
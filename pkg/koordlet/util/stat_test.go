@@ -120,3 +120,33 @@ func Test_GetContainerPerfCollector(t *testing.T) {
 	_, err := GetContainerPerfCollector(tempDir, wrongContainerStatus, 1)
 	assert.NotNil(t, err)
 }
+
+func Test_GetNUMANodeCPUStatUsageTicks(t *testing.T) {
+	helper := system.NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := system.Conf.SysRootDir
+	system.Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		system.Conf.SysRootDir = oldSysRootDir
+	}()
+
+	helper.WriteProcSubFileContents(system.ProcStatName, "cpu  514003 37519 593580 1706155242 5134 45033 38832 0 0 0\n"+
+		"cpu0 9755 845 15540 26635869 3021 2312 9724 0 0 0\n"+
+		"cpu1 10075 664 10790 26653871 214 973 1163 0 0 0\n"+
+		"cpu2 100 0 100 26653871 214 0 0 0 0 0\n"+
+		"intr 574218032 193 0 0 0 4209 0 0 225 131056 131080 130910 130673 130935 130681 130682 130949 131048\n"+
+		"ctxt 701110258\n")
+	helper.WriteFileContents(system.GetNUMANodeCPUListPath(0), "0-1\n")
+	helper.WriteFileContents(system.GetNUMANodeCPUListPath(1), "2\n")
+	got, err := GetNUMANodeCPUStatUsageTicks()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]uint64{0: 61841, 1: 200}, got)
+
+	helper.WriteFileContents(system.GetNUMANodeCPUListPath(1), "invalid\n")
+	_, err = GetNUMANodeCPUStatUsageTicks()
+	assert.Error(t, err)
+
+	helper.WriteProcSubFileContents(system.ProcStatName, "cpu0 9755 845 15540\n")
+	_, err = GetNUMANodeCPUStatUsageTicks()
+	assert.Error(t, err)
+}
@@ -307,3 +307,48 @@ func ConvertCPUSharesToWeight(s string) (int64, error) {
 	}
 	return w, nil
 }
+
+// ConvertCPUCFSPeriodToCPUMax generates the content of `cpu.max` (cgroups-v2) with the given value of
+// `cpu.cfs_period_us` (cgroups-v1), which keeps the quota field of the current content.
+func ConvertCPUCFSPeriodToCPUMax(cpuMax string, period string) (string, error) {
+	// content: "max 100000", "100000 100000"; the first field indicates cfs quota
+	ss := strings.Fields(cpuMax)
+	if len(ss) != 2 {
+		return "", fmt.Errorf("parse cpu.max failed, raw content: %s, err: invalid pattern", cpuMax)
+	}
+	if _, err := strconv.ParseInt(period, 10, 64); err != nil {
+		return "", fmt.Errorf("invalid cfs period value %s, err: %v", period, err)
+	}
+	return ss[0] + " " + period, nil
+}
+
+var blkioThrottleIOMaxKeys = map[ResourceType]string{
+	BlkioTRIopsName: "riops",
+	BlkioTRBpsName:  "rbps",
+	BlkioTWIopsName: "wiops",
+	BlkioTWBpsName:  "wbps",
+}
+
+// ConvertBlkioThrottleToIOMax converts the value of a blkio throttle (cgroups-v1) into the value of `io.max`
+// (cgroups-v2). e.g. "253:0 1048576" of `blkio.throttle.read_bps_device` is converted into "253:0 rbps=1048576".
+func ConvertBlkioThrottleToIOMax(resourceType ResourceType, value string) (string, error) {
+	key, ok := blkioThrottleIOMaxKeys[resourceType]
+	if !ok {
+		return "", fmt.Errorf("resource type %s is not a blkio throttle", resourceType)
+	}
+	// content: "$MAJ:$MIN $VALUE"
+	ss := strings.Fields(value)
+	if len(ss) != 2 || !strings.Contains(ss[0], ":") {
+		return "", fmt.Errorf("invalid blkio throttle value %s, err: invalid pattern", value)
+	}
+	v, err := strconv.ParseUint(ss[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid blkio throttle value %s, err: %v", value, err)
+	}
+	// "0" removes the limit on cgroups-v1, while "max" is required on cgroups-v2
+	limit := CgroupMaxSymbolStr
+	if v > 0 {
+		limit = ss[1]
+	}
+	return fmt.Sprintf("%s %s=%s", ss[0], key, limit), nil
+}
@@ -111,6 +111,7 @@ const ( // subsystems
 	CgroupCPUAcctDir string = "cpuacct/"
 	CgroupMemDir     string = "memory/"
 	CgroupBlkioDir   string = "blkio/"
+	CgroupDevicesDir string = "devices/"
 
 	CgroupV2Dir = ""
 )
@@ -132,6 +133,7 @@ const (
 	CPUProcsName     = "cgroup.procs"
 	CPUThreadsName   = "cgroup.threads"
 	CPUMaxName       = "cpu.max"
+	CPUMaxBurstName  = "cpu.max.burst"
 	CPUWeightName    = "cpu.weight"
 
 	CPUSetCPUSName          = "cpuset.cpus"
@@ -150,9 +152,10 @@ const (
 	MemoryWmarkRatioName       = "memory.wmark_ratio"
 	MemoryWmarkScaleFactorName = "memory.wmark_scale_factor"
 	MemoryWmarkMinAdjName      = "memory.wmark_min_adj"
-	MemoryMinName              = "memory.min"  // anolis os or cgroups-v2
-	MemoryLowName              = "memory.low"  // anolis os or cgroups-v2
-	MemoryHighName             = "memory.high" // anolis os or cgroups-v2
+	MemoryMinName              = "memory.min"                 // anolis os or cgroups-v2
+	MemoryLowName              = "memory.low"                 // anolis os or cgroups-v2
+	MemoryHighName             = "memory.high"                // anolis os or cgroups-v2
+	MemorySoftLimitName        = "memory.soft_limit_in_bytes" // cgroups-v1
 	MemoryMaxName              = "memory.max"
 	MemoryCurrentName          = "memory.current"
 	MemoryPriorityName         = "memory.priority"
@@ -163,6 +166,14 @@ const (
 	BlkioTRBpsName  = "blkio.throttle.read_bps_device"
 	BlkioTWIopsName = "blkio.throttle.write_iops_device"
 	BlkioTWBpsName  = "blkio.throttle.write_bps_device"
+	IOMaxName       = "io.max"
+
+	BlkioIOServiceBytesName = "blkio.throttle.io_service_bytes"
+	BlkioIOServicedName     = "blkio.throttle.io_serviced"
+	IOStatName              = "io.stat"
+
+	DevicesAllowName = "devices.allow"
+	DevicesDenyName  = "devices.deny"
 )
 
 var (
@@ -213,6 +224,7 @@ var (
 	MemoryMin              = DefaultFactory.New(MemoryMinName, CgroupMemDir).WithValidator(NaturalInt64Validator).WithSupported(SupportedIfFileExistsInKubepods(MemoryMinName, CgroupMemDir))
 	MemoryLow              = DefaultFactory.New(MemoryLowName, CgroupMemDir).WithValidator(NaturalInt64Validator).WithSupported(SupportedIfFileExistsInKubepods(MemoryLowName, CgroupMemDir))
 	MemoryHigh             = DefaultFactory.New(MemoryHighName, CgroupMemDir).WithValidator(NaturalInt64Validator).WithSupported(SupportedIfFileExistsInKubepods(MemoryHighName, CgroupMemDir))
+	MemorySoftLimit        = DefaultFactory.New(MemorySoftLimitName, CgroupMemDir).WithValidator(NaturalInt64Validator)
 	MemoryPriority         = DefaultFactory.New(MemoryPriorityName, CgroupMemDir).WithValidator(MemoryPriorityValidator).WithSupported(SupportedIfFileExistsInKubepods(MemoryPriorityName, CgroupMemDir))
 	MemoryUsePriorityOom   = DefaultFactory.New(MemoryUsePriorityOomName, CgroupMemDir).WithValidator(MemoryUsePriorityOomValidator).WithSupported(SupportedIfFileExistsInKubepods(MemoryUsePriorityOomName, CgroupMemDir))
 	MemoryOomGroup         = DefaultFactory.New(MemoryOomGroupName, CgroupMemDir).WithValidator(MemoryOomGroupValidator).WithSupported(SupportedIfFileExistsInKubepods(MemoryOomGroupName, CgroupMemDir))
@@ -222,6 +234,12 @@ var (
 	BlkioWriteIops = DefaultFactory.New(BlkioTWIopsName, CgroupBlkioDir)
 	BlkioWriteBps  = DefaultFactory.New(BlkioTWBpsName, CgroupBlkioDir)
 
+	BlkioIOServiceBytes = DefaultFactory.New(BlkioIOServiceBytesName, CgroupBlkioDir)
+	BlkioIOServiced     = DefaultFactory.New(BlkioIOServicedName, CgroupBlkioDir)
+
+	DevicesAllow = DefaultFactory.New(DevicesAllowName, CgroupDevicesDir)
+	DevicesDeny  = DefaultFactory.New(DevicesDenyName, CgroupDevicesDir)
+
 	knownCgroupResources = []Resource{
 		CPUStat,
 		CPUShares,
@@ -246,6 +264,7 @@ var (
 		MemoryMin,
 		MemoryLow,
 		MemoryHigh,
+		MemorySoftLimit,
 		MemoryPriority,
 		MemoryUsePriorityOom,
 		MemoryOomGroup,
@@ -253,11 +272,16 @@ var (
 		BlkioReadBps,
 		BlkioWriteIops,
 		BlkioWriteBps,
+		BlkioIOServiceBytes,
+		BlkioIOServiced,
+		DevicesAllow,
+		DevicesDeny,
 	}
 
 	CPUCFSQuotaV2  = DefaultFactory.NewV2(CPUCFSQuotaName, CPUMaxName)
 	CPUCFSPeriodV2 = DefaultFactory.NewV2(CPUCFSPeriodName, CPUMaxName)
 	CPUSharesV2    = DefaultFactory.NewV2(CPUSharesName, CPUWeightName).WithValidator(CPUWeightValidator)
+	CPUBurstV2     = DefaultFactory.NewV2(CPUBurstName, CPUMaxBurstName).WithValidator(CPUBurstValidator).WithCheckSupported(SupportedIfFileExists)
 	CPUStatV2      = DefaultFactory.NewV2(CPUStatName, CPUStatName)
 	CPUAcctStatV2  = DefaultFactory.NewV2(CPUAcctStatName, CPUStatName)
 	CPUAcctUsageV2 = DefaultFactory.NewV2(CPUAcctUsageName, CPUStatName)
@@ -284,10 +308,21 @@ var (
 	MemoryUsePriorityOomV2   = DefaultFactory.NewV2(MemoryUsePriorityOomName, MemoryUsePriorityOomName).WithValidator(MemoryUsePriorityOomValidator).WithCheckSupported(SupportedIfFileExists)
 	MemoryOomGroupV2         = DefaultFactory.NewV2(MemoryOomGroupName, MemoryOomGroupName).WithValidator(MemoryOomGroupValidator).WithCheckSupported(SupportedIfFileExists)
 
+	// the blkio throttles of cgroups-v1 are all set in `io.max` on cgroups-v2
+	BlkioReadIopsV2  = DefaultFactory.NewV2(BlkioTRIopsName, IOMaxName)
+	BlkioReadBpsV2   = DefaultFactory.NewV2(BlkioTRBpsName, IOMaxName)
+	BlkioWriteIopsV2 = DefaultFactory.NewV2(BlkioTWIopsName, IOMaxName)
+	BlkioWriteBpsV2  = DefaultFactory.NewV2(BlkioTWBpsName, IOMaxName)
+
+	// the blkio counters of cgroups-v1 are all in `io.stat` on cgroups-v2
+	BlkioIOServiceBytesV2 = DefaultFactory.NewV2(BlkioIOServiceBytesName, IOStatName)
+	BlkioIOServicedV2     = DefaultFactory.NewV2(BlkioIOServicedName, IOStatName)
+
 	knownCgroupV2Resources = []Resource{
 		CPUCFSQuotaV2,
 		CPUCFSPeriodV2,
 		CPUSharesV2,
+		CPUBurstV2,
 		CPUStatV2,
 		CPUAcctStatV2,
 		CPUAcctUsageV2,
@@ -311,6 +346,12 @@ var (
 		MemoryPriorityV2,
 		MemoryUsePriorityOomV2,
 		MemoryOomGroupV2,
+		BlkioReadIopsV2,
+		BlkioReadBpsV2,
+		BlkioWriteIopsV2,
+		BlkioWriteBpsV2,
+		BlkioIOServiceBytesV2,
+		BlkioIOServicedV2,
 	}
 )
 
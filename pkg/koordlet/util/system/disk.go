@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProcDiskStatsName = "diskstats"
+	SysBlockSubDir    = "block"
+
+	// DiskSectorSize is the size of the sectors counted in `/proc/diskstats`, which is always 512 bytes regardless of
+	// the physical sector size of the disk.
+	DiskSectorSize = 512
+)
+
+// DiskIOStat is the IO counters of the disks, or of the IO issued by a cgroup.
+type DiskIOStat struct {
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadIOs    uint64
+	WriteIOs   uint64
+}
+
+func (s *DiskIOStat) add(o *DiskIOStat) {
+	s.ReadBytes += o.ReadBytes
+	s.WriteBytes += o.WriteBytes
+	s.ReadIOs += o.ReadIOs
+	s.WriteIOs += o.WriteIOs
+}
+
+// GetHostDiskIOStat returns the total IO counters of the physical disks of the host. A disk is physical if it is backed
+// by a device in `/sys/block/<disk>/device`, so the partitions and the virtual block devices stacked on the disks,
+// e.g. the device mappers, are not counted twice.
+func GetHostDiskIOStat() (*DiskIOStat, error) {
+	content, err := os.ReadFile(GetProcFilePath(ProcDiskStatsName))
+	if err != nil {
+		return nil, err
+	}
+	total := &DiskIOStat{}
+	// e.g. `   8       0 sda 1234 10 56780 300 4321 20 98760 500 0 800 800 0 0 0 0`
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 14 {
+			return nil, fmt.Errorf("invalid diskstats line %q", line)
+		}
+		if _, err := os.Stat(filepath.Join(Conf.SysRootDir, SysBlockSubDir, fields[2], "device")); err != nil {
+			continue
+		}
+		// reads completed, sectors read, writes completed, sectors written
+		var values [4]uint64
+		for i, fieldIdx := range []int{3, 5, 7, 9} {
+			values[i], err = strconv.ParseUint(fields[fieldIdx], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse diskstats line %q failed, err: %v", line, err)
+			}
+		}
+		total.add(&DiskIOStat{
+			ReadIOs:    values[0],
+			ReadBytes:  values[1] * DiskSectorSize,
+			WriteIOs:   values[2],
+			WriteBytes: values[3] * DiskSectorSize,
+		})
+	}
+	return total, nil
+}
+
+// ParseBlkioIOService parses the IO counters from `blkio.throttle.io_service_bytes` and `blkio.throttle.io_serviced`
+// of cgroups-v1, which are summed over the devices, e.g.
+// 8:0 Read 4096
+// 8:0 Write 8192
+// 8:0 Sync 8192
+// 8:0 Async 4096
+// 8:0 Total 12288
+// Total 12288
+func ParseBlkioIOService(serviceBytesContent, servicedContent string) (*DiskIOStat, error) {
+	readBytes, writeBytes, err := parseBlkioIOServiceFile(serviceBytesContent)
+	if err != nil {
+		return nil, err
+	}
+	readIOs, writeIOs, err := parseBlkioIOServiceFile(servicedContent)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskIOStat{
+		ReadBytes:  readBytes,
+		WriteBytes: writeBytes,
+		ReadIOs:    readIOs,
+		WriteIOs:   writeIOs,
+	}, nil
+}
+
+func parseBlkioIOServiceFile(content string) (read uint64, write uint64, err error) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || (fields[1] != "Read" && fields[1] != "Write") {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse blkio line %q failed, err: %v", line, err)
+		}
+		if fields[1] == "Read" {
+			read += v
+		} else {
+			write += v
+		}
+	}
+	return read, write, nil
+}
+
+// ParseIOStatV2 parses the IO counters from `io.stat` of cgroups-v2, which are summed over the devices, e.g.
+// 8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0
+func ParseIOStatV2(content string) (*DiskIOStat, error) {
+	stat := &DiskIOStat{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid io.stat line %q", line)
+			}
+			var counter *uint64
+			switch kv[0] {
+			case "rbytes":
+				counter = &stat.ReadBytes
+			case "wbytes":
+				counter = &stat.WriteBytes
+			case "rios":
+				counter = &stat.ReadIOs
+			case "wios":
+				counter = &stat.WriteIOs
+			default:
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse io.stat line %q failed, err: %v", line, err)
+			}
+			*counter += v
+		}
+	}
+	return stat, nil
+}
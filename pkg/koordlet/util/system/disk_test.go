@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHostDiskIOStat(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	helper.WriteProcSubFileContents(ProcDiskStatsName, `   7       0 loop0 100 0 200 10 0 0 0 0 0 10 10 0 0 0 0
+   8       0 sda 1000 10 20000 300 2000 20 40000 500 0 800 800 0 0 0 0
+   8       1 sda1 900 10 18000 300 1900 20 38000 500 0 800 800 0 0 0 0
+ 259       0 nvme0n1 500 0 10000 100 100 0 2000 50 0 100 150
+ 253       0 dm-0 900 0 18000 300 1900 0 38000 500 0 800 800 0 0 0 0
+`)
+	// only sda and nvme0n1 are physical
+	helper.WriteFileContents(filepath.Join(Conf.SysRootDir, SysBlockSubDir, "sda", "device", "model"), "test")
+	helper.WriteFileContents(filepath.Join(Conf.SysRootDir, SysBlockSubDir, "nvme0n1", "device", "model"), "test")
+	helper.WriteFileContents(filepath.Join(Conf.SysRootDir, SysBlockSubDir, "dm-0", "size"), "1024")
+
+	got, err := GetHostDiskIOStat()
+	assert.NoError(t, err)
+	assert.Equal(t, &DiskIOStat{
+		ReadBytes:  30000 * DiskSectorSize,
+		WriteBytes: 42000 * DiskSectorSize,
+		ReadIOs:    1500,
+		WriteIOs:   2100,
+	}, got)
+
+	helper.WriteProcSubFileContents(ProcDiskStatsName, "   8       0 sda 1000 10\n")
+	got, err = GetHostDiskIOStat()
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestParseBlkioIOService(t *testing.T) {
+	serviceBytes := `8:0 Read 4096
+8:0 Write 8192
+8:0 Sync 8192
+8:0 Async 4096
+8:0 Discard 0
+8:0 Total 12288
+8:16 Read 1024
+8:16 Write 0
+8:16 Total 1024
+Total 13312
+`
+	serviced := `8:0 Read 1
+8:0 Write 2
+8:0 Total 3
+8:16 Read 1
+8:16 Write 0
+8:16 Total 1
+Total 4
+`
+	got, err := ParseBlkioIOService(serviceBytes, serviced)
+	assert.NoError(t, err)
+	assert.Equal(t, &DiskIOStat{ReadBytes: 5120, WriteBytes: 8192, ReadIOs: 2, WriteIOs: 2}, got)
+
+	// no io has been issued
+	got, err = ParseBlkioIOService("Total 0\n", "Total 0\n")
+	assert.NoError(t, err)
+	assert.Equal(t, &DiskIOStat{}, got)
+
+	got, err = ParseBlkioIOService("8:0 Read abc\n", serviced)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
+func TestParseIOStatV2(t *testing.T) {
+	got, err := ParseIOStatV2(`8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0
+8:16 rbytes=1024 wbytes=0 rios=1 wios=0 dbytes=0 dios=0
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, &DiskIOStat{ReadBytes: 5120, WriteBytes: 8192, ReadIOs: 2, WriteIOs: 2}, got)
+
+	got, err = ParseIOStatV2("")
+	assert.NoError(t, err)
+	assert.Equal(t, &DiskIOStat{}, got)
+
+	got, err = ParseIOStatV2("8:0 rbytes\n")
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	got, err = ParseIOStatV2("8:0 rbytes=-1\n")
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
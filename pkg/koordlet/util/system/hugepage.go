@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	NUMANodeHugePagesDirName = "hugepages"
+	HugePagesNrName          = "nr_hugepages"
+	HugePagesFreeName        = "free_hugepages"
+
+	CgroupHugetlbDir string = "hugetlb/"
+
+	hugePagesDirPrefix = "hugepages-"
+	hugePagesDirSuffix = "kB"
+)
+
+// HugePagesInfo is the hugepage pool of a page size, e.g. `/sys/devices/system/node/node0/hugepages/hugepages-2048kB`.
+type HugePagesInfo struct {
+	PageSizeKB uint64
+	NumPages   uint64
+	FreePages  uint64
+}
+
+func (h *HugePagesInfo) TotalBytes() uint64 {
+	return h.NumPages * h.PageSizeKB * 1024
+}
+
+func (h *HugePagesInfo) FreeBytes() uint64 {
+	return h.FreePages * h.PageSizeKB * 1024
+}
+
+func GetNUMANodeHugePagesDir(numaNodeID int) string {
+	return filepath.Join(getNUMANodeDir(numaNodeID), NUMANodeHugePagesDirName)
+}
+
+// GetNUMANodeHugePagesInfos returns the hugepage pools of all NUMA nodes on the host, indexed by the NUMA node id.
+// The pools of each NUMA node are sorted by the page size.
+func GetNUMANodeHugePagesInfos() (map[int][]*HugePagesInfo, error) {
+	numaNodeIDs, err := GetNUMANodeIDs()
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[int][]*HugePagesInfo, len(numaNodeIDs))
+	for _, numaNodeID := range numaNodeIDs {
+		nodeInfos, err := readHugePagesInfos(GetNUMANodeHugePagesDir(numaNodeID))
+		if err != nil {
+			return nil, fmt.Errorf("read hugepages of NUMA node %d failed, err: %v", numaNodeID, err)
+		}
+		infos[numaNodeID] = nodeInfos
+	}
+	return infos, nil
+}
+
+// readHugePagesInfos reads the hugepage pools under the dir, which has no pool if the dir does not exist.
+func readHugePagesInfos(dir string) ([]*HugePagesInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var infos []*HugePagesInfo
+	for _, entry := range entries {
+		pageSizeKB, err := ParseHugePagesDirName(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		info := &HugePagesInfo{PageSizeKB: pageSizeKB}
+		if info.NumPages, err = readUint64File(filepath.Join(dir, entry.Name(), HugePagesNrName)); err != nil {
+			return nil, err
+		}
+		if info.FreePages, err = readUint64File(filepath.Join(dir, entry.Name(), HugePagesFreeName)); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].PageSizeKB < infos[j].PageSizeKB
+	})
+	return infos, nil
+}
+
+// ParseHugePagesDirName parses the page size in KB from the hugepages dir name, e.g. `hugepages-2048kB`.
+func ParseHugePagesDirName(name string) (uint64, error) {
+	if !strings.HasPrefix(name, hugePagesDirPrefix) || !strings.HasSuffix(name, hugePagesDirSuffix) {
+		return 0, fmt.Errorf("invalid hugepages dir name %s", name)
+	}
+	sizeStr := strings.TrimSuffix(strings.TrimPrefix(name, hugePagesDirPrefix), hugePagesDirSuffix)
+	pageSizeKB, err := strconv.ParseUint(sizeStr, 10, 64)
+	if err != nil || pageSizeKB == 0 {
+		return 0, fmt.Errorf("invalid hugepages dir name %s", name)
+	}
+	return pageSizeKB, nil
+}
+
+// GetHugetlbUsagePath returns the hugetlb usage file of the cgroup for the page size, which is named with the page
+// size in the largest unit, e.g. `hugetlb.2MB.usage_in_bytes` on cgroups-v1 and `hugetlb.1GB.current` on cgroups-v2.
+func GetHugetlbUsagePath(cgroupDir string, pageSizeKB uint64) string {
+	pageSize := formatHugetlbPageSize(pageSizeKB)
+	if GetCurrentCgroupVersion() == CgroupVersionV2 {
+		return filepath.Join(Conf.CgroupRootDir, CgroupV2Dir, cgroupDir, fmt.Sprintf("hugetlb.%s.current", pageSize))
+	}
+	return filepath.Join(Conf.CgroupRootDir, CgroupHugetlbDir, cgroupDir, fmt.Sprintf("hugetlb.%s.usage_in_bytes", pageSize))
+}
+
+// GetHugetlbUsageBytes returns the hugepage bytes of the page size charged to the cgroup.
+func GetHugetlbUsageBytes(cgroupDir string, pageSizeKB uint64) (uint64, error) {
+	return readUint64File(GetHugetlbUsagePath(cgroupDir, pageSizeKB))
+}
+
+func formatHugetlbPageSize(pageSizeKB uint64) string {
+	switch {
+	case pageSizeKB%(1024*1024) == 0:
+		return fmt.Sprintf("%dGB", pageSizeKB/(1024*1024))
+	case pageSizeKB%1024 == 0:
+		return fmt.Sprintf("%dMB", pageSizeKB/1024)
+	default:
+		return fmt.Sprintf("%dKB", pageSizeKB)
+	}
+}
+
+func readUint64File(filePath string) (uint64, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNUMANodeHugePagesInfos(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	writeHugePages := func(numaNodeID int, dirName, nr, free string) {
+		dir := filepath.Join(GetNUMANodeHugePagesDir(numaNodeID), dirName)
+		helper.WriteFileContents(filepath.Join(dir, HugePagesNrName), nr)
+		helper.WriteFileContents(filepath.Join(dir, HugePagesFreeName), free)
+	}
+	writeHugePages(0, "hugepages-2048kB", "512\n", "256\n")
+	writeHugePages(0, "hugepages-1048576kB", "2\n", "2\n")
+	writeHugePages(1, "hugepages-2048kB", "0\n", "0\n")
+	helper.WriteFileContents(GetNUMANodeMemInfoPath(2), "")
+	helper.MkDirAll(filepath.Join(GetNUMANodeHugePagesDir(1), "invalid"))
+
+	got, err := GetNUMANodeHugePagesInfos()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int][]*HugePagesInfo{
+		0: {
+			{PageSizeKB: 2048, NumPages: 512, FreePages: 256},
+			{PageSizeKB: 1048576, NumPages: 2, FreePages: 2},
+		},
+		1: {
+			{PageSizeKB: 2048, NumPages: 0, FreePages: 0},
+		},
+		2: nil,
+	}, got)
+	assert.Equal(t, uint64(1024*1024*1024), got[0][0].TotalBytes())
+	assert.Equal(t, uint64(512*1024*1024), got[0][0].FreeBytes())
+
+	// the pool missing the counters is invalid
+	helper.MkDirAll(filepath.Join(GetNUMANodeHugePagesDir(1), "hugepages-1048576kB"))
+	_, err = GetNUMANodeHugePagesInfos()
+	assert.Error(t, err)
+}
+
+func TestGetHugetlbUsageBytes(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	cgroupDir := "kubepods.slice/kubepods-pod1.slice"
+	helper.SetCgroupsV2(false)
+	helper.WriteFileContents(filepath.Join(CgroupHugetlbDir, cgroupDir, "hugetlb.2MB.usage_in_bytes"), "4194304\n")
+	helper.WriteFileContents(filepath.Join(CgroupHugetlbDir, cgroupDir, "hugetlb.1GB.usage_in_bytes"), "0\n")
+	got, err := GetHugetlbUsageBytes(cgroupDir, 2048)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4194304), got)
+	got, err = GetHugetlbUsageBytes(cgroupDir, 1048576)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), got)
+	_, err = GetHugetlbUsageBytes(cgroupDir, 64)
+	assert.Error(t, err)
+
+	helper.SetCgroupsV2(true)
+	helper.WriteFileContents(filepath.Join(cgroupDir, "hugetlb.64KB.current"), "65536\n")
+	got, err = GetHugetlbUsageBytes(cgroupDir, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(65536), got)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	SysKidledSubDir         = "kernel/mm/kidled"
+	KidledScanPeriodName    = "scan_period_in_seconds"
+	MemoryIdlePageStatsName = "memory.idle_page_stats"
+
+	idlePageStatsScanPeriodKey = "scan_period_in_seconds"
+	idlePageStatsBucketsKey    = "buckets"
+	idlePageStatsSlabLabel     = "slab"
+)
+
+// IdlePageStats is the idle page statistics of a memory cgroup scanned by kidled, i.e. `memory.idle_page_stats`
+// provided by the Alibaba Cloud Linux kernel. The idle pages are counted in bytes by the page type and the idle age,
+// where the page type is labeled with the flags clean/dirty, swap/file, evict/unevict and inactive/active, e.g.
+// `csei` is the clean swap-backed evictable inactive pages.
+type IdlePageStats struct {
+	ScanPeriodSeconds uint64
+	// Buckets are the lower bounds of the idle ages in the scan periods.
+	Buckets []uint64
+	// Pages are the idle page bytes of each age bucket keyed by the page type label.
+	Pages map[string][]uint64
+}
+
+// IdleAnonBytes returns the bytes of the evictable swap-backed pages idle for at least one scan period, i.e. the
+// cold anonymous memory which can be swapped out without hurting the workloads.
+func (s *IdlePageStats) IdleAnonBytes() uint64 {
+	var total uint64
+	for label, bytes := range s.Pages {
+		if len(label) != 4 || label[1] != 's' || label[2] != 'e' {
+			continue
+		}
+		for _, b := range bytes {
+			total += b
+		}
+	}
+	return total
+}
+
+func GetKidledScanPeriodPath() string {
+	return filepath.Join(Conf.SysRootDir, SysKidledSubDir, KidledScanPeriodName)
+}
+
+// IsKidledEnabled checks if the kidled is supported by the kernel and scanning the pages.
+func IsKidledEnabled() bool {
+	scanPeriod, err := readUint64File(GetKidledScanPeriodPath())
+	return err == nil && scanPeriod > 0
+}
+
+func GetMemoryIdlePageStatsPath(cgroupDir string) string {
+	if GetCurrentCgroupVersion() == CgroupVersionV2 {
+		return filepath.Join(Conf.CgroupRootDir, CgroupV2Dir, cgroupDir, MemoryIdlePageStatsName)
+	}
+	return filepath.Join(Conf.CgroupRootDir, CgroupMemDir, cgroupDir, MemoryIdlePageStatsName)
+}
+
+// GetMemoryIdlePageStats returns the idle page statistics of the memory cgroup, which include the pages of the
+// descendant cgroups if the hierarchy is used.
+func GetMemoryIdlePageStats(cgroupDir string) (*IdlePageStats, error) {
+	content, err := os.ReadFile(GetMemoryIdlePageStatsPath(cgroupDir))
+	if err != nil {
+		return nil, err
+	}
+	return ParseIdlePageStats(string(content))
+}
+
+// ParseIdlePageStats parses the content of `memory.idle_page_stats`, e.g.
+//
+//	# version: 1.0
+//	# scan_period_in_seconds: 120
+//	# buckets: 1,2,5,15,30,60,120,240
+//	#           [1,2)  [2,5)  [5,15)  [15,30)  [30,60)  [60,120)  [120,240)  [240,+inf)
+//	  csei          0      0    4096        0        0         0          0           0
+//	  ...
+func ParseIdlePageStats(content string) (*IdlePageStats, error) {
+	stats := &IdlePageStats{Pages: map[string][]uint64{}}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			key, value, ok := parseIdlePageStatsHeader(line)
+			if !ok {
+				continue
+			}
+			switch key {
+			case idlePageStatsScanPeriodKey:
+				scanPeriod, err := strconv.ParseUint(value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid scan period %s, err: %v", value, err)
+				}
+				stats.ScanPeriodSeconds = scanPeriod
+			case idlePageStatsBucketsKey:
+				for _, s := range strings.Split(value, ",") {
+					bucket, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid buckets %s, err: %v", value, err)
+					}
+					stats.Buckets = append(stats.Buckets, bucket)
+				}
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(stats.Buckets) == 0 || len(fields) != len(stats.Buckets)+1 {
+			return nil, fmt.Errorf("invalid idle page stats line %q", line)
+		}
+		if fields[0] == idlePageStatsSlabLabel {
+			continue
+		}
+		bytes := make([]uint64, len(stats.Buckets))
+		for i, field := range fields[1:] {
+			b, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid idle page stats line %q, err: %v", line, err)
+			}
+			bytes[i] = b
+		}
+		stats.Pages[fields[0]] = bytes
+	}
+	if len(stats.Buckets) == 0 {
+		return nil, fmt.Errorf("no buckets found in idle page stats")
+	}
+	return stats, nil
+}
+
+// parseIdlePageStatsHeader parses the key-value header line, e.g. `# buckets: 1,2,5`.
+func parseIdlePageStatsHeader(line string) (string, string, bool) {
+	kv := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":", 2)
+	if len(kv) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), true
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIdlePageStats = `# version: 1.0
+# page_scans: 24
+# slab_scans: 0
+# scan_period_in_seconds: 120
+# use_hierarchy: 1
+# buckets: 1,2,5,15,30,60,120,240
+#
+#   _-----=> clean/dirty
+#  / _----=> swap/file
+# | / _---=> evict/unevict
+# || / _--=> inactive/active
+# ||| / _-=> slab
+# |||| /
+# |||||             [1,2)          [2,5)         [5,15)        [15,30)        [30,60)       [60,120)      [120,240)     [240,+inf)
+  csei                  0              0         4096              0              0              0              0              0
+  dsei               8192              0              0              0              0              0              0          16384
+  cfei             102400              0              0              0              0              0              0              0
+  dfei                  0              0              0              0              0              0              0              0
+  csui                  0              0         65536              0              0              0              0              0
+  dsui                  0              0              0              0              0              0              0              0
+  cfui                  0              0              0              0              0              0              0              0
+  dfui                  0              0              0              0              0              0              0              0
+  csea                  0           4096              0              0              0              0              0              0
+  dsea                  0              0              0              0              0              0              0              0
+  cfea                  0              0              0              0              0              0              0              0
+  dfea                  0              0              0              0              0              0              0              0
+  csua                  0              0              0              0              0              0              0              0
+  dsua                  0              0              0              0              0              0              0              0
+  cfua                  0              0              0              0              0              0              0              0
+  dfua                  0              0              0              0              0              0              0              0
+  slab                  0              0              0              0              0              0              0              0
+`
+
+func TestParseIdlePageStats(t *testing.T) {
+	stats, err := ParseIdlePageStats(testIdlePageStats)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(120), stats.ScanPeriodSeconds)
+	assert.Equal(t, []uint64{1, 2, 5, 15, 30, 60, 120, 240}, stats.Buckets)
+	assert.Len(t, stats.Pages, 16)
+	assert.Equal(t, []uint64{8192, 0, 0, 0, 0, 0, 0, 16384}, stats.Pages["dsei"])
+	// the unevictable and file pages are excluded
+	assert.Equal(t, uint64(4096+8192+16384+4096), stats.IdleAnonBytes())
+
+	_, err = ParseIdlePageStats("# scan_period_in_seconds: 120\n")
+	assert.Error(t, err)
+	_, err = ParseIdlePageStats("# buckets: 1,2\n  csei 0 0 0\n")
+	assert.Error(t, err)
+	_, err = ParseIdlePageStats("# buckets: 1,2\n  csei 0 x\n")
+	assert.Error(t, err)
+}
+
+func TestGetMemoryIdlePageStats(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	assert.False(t, IsKidledEnabled())
+	helper.WriteFileContents(GetKidledScanPeriodPath(), "0\n")
+	assert.False(t, IsKidledEnabled())
+	helper.WriteFileContents(GetKidledScanPeriodPath(), "120\n")
+	assert.True(t, IsKidledEnabled())
+
+	cgroupDir := "kubepods.slice/kubepods-pod1.slice"
+	helper.SetCgroupsV2(false)
+	_, err := GetMemoryIdlePageStats(cgroupDir)
+	assert.Error(t, err)
+	helper.WriteFileContents(filepath.Join(CgroupMemDir, cgroupDir, MemoryIdlePageStatsName), testIdlePageStats)
+	stats, err := GetMemoryIdlePageStats(cgroupDir)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(32768), stats.IdleAnonBytes())
+}
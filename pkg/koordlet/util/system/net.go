@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProcNetDevName    = "net/dev"
+	SysNetSubDir      = "class/net"
+	LoopbackNetDevice = "lo"
+)
+
+// NetDevStat is the traffic counters of the network interfaces in `/proc/net/dev`.
+type NetDevStat struct {
+	RxBytes   uint64
+	RxPackets uint64
+	TxBytes   uint64
+	TxPackets uint64
+}
+
+func (s *NetDevStat) add(o *NetDevStat) {
+	s.RxBytes += o.RxBytes
+	s.RxPackets += o.RxPackets
+	s.TxBytes += o.TxBytes
+	s.TxPackets += o.TxPackets
+}
+
+// GetHostNetDevStat returns the total counters of the physical interfaces of the host. An interface is physical if it
+// is backed by a device in `/sys/class/net/<interface>/device`, so the traffic forwarded through the virtual
+// interfaces, e.g. the veths of the pods and the bridges, is not counted twice.
+func GetHostNetDevStat() (*NetDevStat, error) {
+	stats, err := readNetDevStats(GetProcFilePath(ProcNetDevName))
+	if err != nil {
+		return nil, err
+	}
+	total := &NetDevStat{}
+	for netDevice, stat := range stats {
+		if _, err := os.Stat(filepath.Join(Conf.SysRootDir, SysNetSubDir, netDevice, "device")); err != nil {
+			continue
+		}
+		total.add(stat)
+	}
+	return total, nil
+}
+
+// GetProcessNetDevStat returns the total counters of the interfaces except the loopback in the network namespace of
+// the process, which are the traffic of the pod if the process runs in a pod without the host network.
+func GetProcessNetDevStat(pid uint32) (*NetDevStat, error) {
+	stats, err := readNetDevStats(GetProcFilePath(filepath.Join(strconv.FormatUint(uint64(pid), 10), ProcNetDevName)))
+	if err != nil {
+		return nil, err
+	}
+	total := &NetDevStat{}
+	for netDevice, stat := range stats {
+		if netDevice == LoopbackNetDevice {
+			continue
+		}
+		total.add(stat)
+	}
+	return total, nil
+}
+
+// readNetDevStats parses the counters of each interface in the net/dev file, e.g.
+// Inter-|   Receive                                                |  Transmit
+//
+//	face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+//	 eth0: 2776770   11307    0    0    0     0          0         0  1318726    8956    0    0    0     0       0          0
+func readNetDevStats(path string) (map[string]*NetDevStat, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stats := map[string]*NetDevStat{}
+	for _, line := range strings.Split(string(content), "\n") {
+		idx := strings.Index(line, ":")
+		if idx < 0 {
+			// the header lines
+			continue
+		}
+		netDevice := strings.TrimSpace(line[:idx])
+		fields := strings.Fields(line[idx+1:])
+		if len(fields) < 16 {
+			return nil, fmt.Errorf("invalid net/dev line %q", line)
+		}
+		var values [4]uint64
+		for i, fieldIdx := range []int{0, 1, 8, 9} {
+			values[i], err = strconv.ParseUint(fields[fieldIdx], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse net/dev line %q failed, err: %v", line, err)
+			}
+		}
+		stats[netDevice] = &NetDevStat{
+			RxBytes:   values[0],
+			RxPackets: values[1],
+			TxBytes:   values[2],
+			TxPackets: values[3],
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testNetDevContent = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:  100000     100    0    0    0     0          0         0   100000     100    0    0    0     0       0          0
+  eth0: 2776770   11307    0    0    0     0          0         0  1318726    8956    0    0    0     0       0          0
+  eth1:    1000      10    0    0    0     0          0         0     2000      20    0    0    0     0       0          0
+veth1234:  500000    5000    0    0    0     0          0         0   600000    6000    0    0    0     0       0          0
+`
+
+func TestGetHostNetDevStat(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	helper.WriteProcSubFileContents(ProcNetDevName, testNetDevContent)
+	// only eth0 and eth1 are physical
+	helper.WriteFileContents(filepath.Join(Conf.SysRootDir, SysNetSubDir, "eth0", "device", "vendor"), "0x15b3")
+	helper.WriteFileContents(filepath.Join(Conf.SysRootDir, SysNetSubDir, "eth1", "device", "vendor"), "0x15b3")
+	helper.WriteFileContents(filepath.Join(Conf.SysRootDir, SysNetSubDir, "veth1234", "mtu"), "1500")
+
+	got, err := GetHostNetDevStat()
+	assert.NoError(t, err)
+	assert.Equal(t, &NetDevStat{RxBytes: 2777770, RxPackets: 11317, TxBytes: 1320726, TxPackets: 8976}, got)
+}
+
+func TestGetProcessNetDevStat(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	helper.WriteProcSubFileContents(filepath.Join("1234", ProcNetDevName), testNetDevContent)
+	got, err := GetProcessNetDevStat(1234)
+	assert.NoError(t, err)
+	assert.Equal(t, &NetDevStat{RxBytes: 3277770, RxPackets: 16317, TxBytes: 1920726, TxPackets: 14976}, got)
+
+	// the process not exist
+	got, err = GetProcessNetDevStat(5678)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+
+	helper.WriteProcSubFileContents(filepath.Join("1234", ProcNetDevName), "  eth0: 1 2 3\n")
+	got, err = GetProcessNetDevStat(1234)
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
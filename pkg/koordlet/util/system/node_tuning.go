@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProcSysRelativePath = "sys"
+	ProcIRQRelativePath = "irq"
+
+	IRQAffinityListFileName = "smp_affinity_list"
+	RPSCPUsFileName         = "rps_cpus"
+
+	netDeviceMSIIRQsDir  = "device/msi_irqs"
+	netDeviceQueuesDir   = "queues"
+	netDeviceRxQueueGlob = "rx-*"
+)
+
+// GetSysctlFilePath returns the file of the kernel parameter under /proc/sys.
+// e.g. net.core.somaxconn -> /proc/sys/net/core/somaxconn
+// The name separated by slashes is taken as the relative path like the sysctl command, e.g. net/ipv4/conf/eth0.1/rp_filter.
+func GetSysctlFilePath(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	relativePath := name
+	if !strings.Contains(name, "/") {
+		relativePath = strings.ReplaceAll(name, ".", "/")
+	}
+	relativePath = strings.Trim(relativePath, "/")
+	if relativePath == "" {
+		return "", fmt.Errorf("invalid sysctl name %q", name)
+	}
+	for _, elem := range strings.Split(relativePath, "/") {
+		if elem == "" || elem == "." || elem == ".." {
+			return "", fmt.Errorf("invalid sysctl name %q", name)
+		}
+	}
+	return GetProcFilePath(filepath.Join(ProcSysRelativePath, relativePath)), nil
+}
+
+// GetNetDeviceIRQs returns the MSI IRQs of the network device in `/sys/class/net/<device>/device/msi_irqs`. It
+// returns empty for the virtual devices, e.g. macvlan.
+func GetNetDeviceIRQs(device string) ([]int, error) {
+	deviceDir := filepath.Join(Conf.SysRootDir, SysNetSubDir, device)
+	if _, err := os.Stat(deviceDir); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(deviceDir, netDeviceMSIIRQsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var irqs []int
+	for _, entry := range entries {
+		irq, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		irqs = append(irqs, irq)
+	}
+	sort.Ints(irqs)
+	return irqs, nil
+}
+
+// @return /proc/irq/<irq>/smp_affinity_list
+func GetIRQAffinityListFilePath(irq int) string {
+	return GetProcFilePath(filepath.Join(ProcIRQRelativePath, strconv.Itoa(irq), IRQAffinityListFileName))
+}
+
+// GetNetDeviceRPSCPUsFilePaths returns the rps_cpus files of the receive queues of the network device.
+// e.g. /sys/class/net/eth0/queues/rx-0/rps_cpus
+func GetNetDeviceRPSCPUsFilePaths(device string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(Conf.SysRootDir, SysNetSubDir, device, netDeviceQueuesDir,
+		netDeviceRxQueueGlob, RPSCPUsFileName))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// FormatCPUMask formats the cpus into the hex bitmask used by the kernel, which is separated by commas every 32 bits.
+// e.g. [0, 1, 2, 3] -> "f", [0, 32] -> "1,00000001"
+func FormatCPUMask(cpus []int) string {
+	var words []uint32
+	for _, cpu := range cpus {
+		if cpu < 0 {
+			continue
+		}
+		for len(words) <= cpu/32 {
+			words = append(words, 0)
+		}
+		words[cpu/32] |= 1 << uint(cpu%32)
+	}
+	if len(words) <= 0 {
+		return "0"
+	}
+	var builder strings.Builder
+	for i := len(words) - 1; i >= 0; i-- {
+		if i == len(words)-1 {
+			builder.WriteString(strconv.FormatUint(uint64(words[i]), 16))
+			continue
+		}
+		builder.WriteString(fmt.Sprintf(",%08x", words[i]))
+	}
+	return builder.String()
+}
+
+// ParseCPUMask parses the hex bitmask of the kernel into the sorted cpus.
+// e.g. "00000000,0000000f" -> [0, 1, 2, 3]
+func ParseCPUMask(mask string) ([]int, error) {
+	words := strings.Split(strings.TrimSpace(mask), ",")
+	var cpus []int
+	for i := len(words) - 1; i >= 0; i-- {
+		word, err := strconv.ParseUint(strings.TrimSpace(words[i]), 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu mask %q, err: %v", mask, err)
+		}
+		offset := (len(words) - 1 - i) * 32
+		for bit := 0; bit < 32; bit++ {
+			if word&(1<<uint(bit)) != 0 {
+				cpus = append(cpus, offset+bit)
+			}
+		}
+	}
+	return cpus, nil
+}
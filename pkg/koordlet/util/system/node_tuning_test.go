@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSysctlFilePath(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+
+	got, err := GetSysctlFilePath("net.core.somaxconn")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(Conf.ProcRootDir, "sys/net/core/somaxconn"), got)
+	got, err = GetSysctlFilePath("net/ipv4/conf/eth0.1/rp_filter")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(Conf.ProcRootDir, "sys/net/ipv4/conf/eth0.1/rp_filter"), got)
+
+	for _, name := range []string{"", "..", "net/../../etc/passwd", "net..core"} {
+		_, err = GetSysctlFilePath(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestGetNetDeviceIRQs(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	netDir := filepath.Join(Conf.SysRootDir, SysNetSubDir)
+	helper.WriteFileContents(filepath.Join(netDir, "eth0", "device", "msi_irqs", "120"), "msix")
+	helper.WriteFileContents(filepath.Join(netDir, "eth0", "device", "msi_irqs", "98"), "msix")
+	helper.WriteFileContents(filepath.Join(netDir, "eth0", "queues", "rx-0", RPSCPUsFileName), "0")
+	helper.WriteFileContents(filepath.Join(netDir, "macvlan0", "queues", "rx-0", RPSCPUsFileName), "0")
+	helper.WriteFileContents(filepath.Join(netDir, "macvlan0", "queues", "rx-1", RPSCPUsFileName), "0")
+
+	irqs, err := GetNetDeviceIRQs("eth0")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{98, 120}, irqs)
+	assert.Equal(t, filepath.Join(Conf.ProcRootDir, "irq/98/smp_affinity_list"), GetIRQAffinityListFilePath(98))
+
+	irqs, err = GetNetDeviceIRQs("macvlan0")
+	assert.NoError(t, err)
+	assert.Empty(t, irqs)
+	paths, err := GetNetDeviceRPSCPUsFilePaths("macvlan0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(netDir, "macvlan0", "queues", "rx-0", RPSCPUsFileName),
+		filepath.Join(netDir, "macvlan0", "queues", "rx-1", RPSCPUsFileName),
+	}, paths)
+
+	_, err = GetNetDeviceIRQs("eth1")
+	assert.Error(t, err)
+}
+
+func TestCPUMask(t *testing.T) {
+	tests := []struct {
+		cpus []int
+		mask string
+	}{
+		{cpus: nil, mask: "0"},
+		{cpus: []int{0, 1, 2, 3}, mask: "f"},
+		{cpus: []int{4, 5, 6, 7, 31}, mask: "800000f0"},
+		{cpus: []int{0, 32}, mask: "1,00000001"},
+		{cpus: []int{33, 64}, mask: "1,00000002,00000000"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.mask, FormatCPUMask(tt.cpus))
+		got, err := ParseCPUMask(tt.mask)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.cpus, got)
+	}
+
+	got, err := ParseCPUMask("00000000,0000000f\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, got)
+	_, err = ParseCPUMask("xyz")
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	SysNUMANodeSubDir   = "devices/system/node"
+	NUMANodeStatName    = "numastat"
+	NUMANodeMemInfoName = "meminfo"
+	NUMANodeCPUListName = "cpulist"
+)
+
+// NUMANodeStat is the memory allocation statistics of a NUMA node in `/sys/devices/system/node/nodeN/numastat`.
+// The kernel only provides the numa_hit/numa_miss counters per NUMA node rather than per cgroup.
+type NUMANodeStat struct {
+	// NumaHit is the number of pages allocated on the intended node.
+	NumaHit uint64
+	// NumaMiss is the number of pages allocated on this node despite the process preferred some different node.
+	NumaMiss uint64
+	// NumaForeign is the number of pages intended for this node but actually allocated on some different node.
+	NumaForeign uint64
+	// LocalNode is the number of pages allocated on this node while the process was running on it.
+	LocalNode uint64
+	// OtherNode is the number of pages allocated on this node while the process was running on some other node.
+	OtherNode uint64
+}
+
+func GetNUMANodeStatPath(numaNodeID int) string {
+	return filepath.Join(getNUMANodeDir(numaNodeID), NUMANodeStatName)
+}
+
+func GetNUMANodeMemInfoPath(numaNodeID int) string {
+	return filepath.Join(getNUMANodeDir(numaNodeID), NUMANodeMemInfoName)
+}
+
+func GetNUMANodeCPUListPath(numaNodeID int) string {
+	return filepath.Join(getNUMANodeDir(numaNodeID), NUMANodeCPUListName)
+}
+
+func getNUMANodeDir(numaNodeID int) string {
+	return filepath.Join(Conf.SysRootDir, SysNUMANodeSubDir, fmt.Sprintf("node%d", numaNodeID))
+}
+
+// GetNUMANodeIDs returns the ids of all NUMA nodes on the host in ascending order.
+func GetNUMANodeIDs() ([]int, error) {
+	nodeDirs, err := filepath.Glob(filepath.Join(Conf.SysRootDir, SysNUMANodeSubDir, "node[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+	numaNodeIDs := make([]int, 0, len(nodeDirs))
+	for _, nodeDir := range nodeDirs {
+		numaNodeID, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(nodeDir), "node"))
+		if err != nil {
+			continue
+		}
+		numaNodeIDs = append(numaNodeIDs, numaNodeID)
+	}
+	sort.Ints(numaNodeIDs)
+	return numaNodeIDs, nil
+}
+
+// ParseNUMANodeStat parses the content of numastat, e.g.
+// `numa_hit 42\nnuma_miss 0\nnuma_foreign 0\ninterleave_hit 1\nlocal_node 40\nother_node 2\n`
+func ParseNUMANodeStat(content string) (*NUMANodeStat, error) {
+	m := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		m[fields[0]] = fields[1]
+	}
+
+	stat := &NUMANodeStat{}
+	for _, t := range []struct {
+		key   string
+		value *uint64
+	}{
+		{key: "numa_hit", value: &stat.NumaHit},
+		{key: "numa_miss", value: &stat.NumaMiss},
+		{key: "numa_foreign", value: &stat.NumaForeign},
+		{key: "local_node", value: &stat.LocalNode},
+		{key: "other_node", value: &stat.OtherNode},
+	} {
+		valueStr, ok := m[t.key]
+		if !ok {
+			return nil, fmt.Errorf("parse numastat failed, raw content %s, err: missing field %s", content, t.key)
+		}
+		v, err := strconv.ParseUint(valueStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse numastat failed, raw content %s, field %s, err: %v", content, t.key, err)
+		}
+		*t.value = v
+	}
+	return stat, nil
+}
+
+// GetNUMANodeStats returns the numastat of all NUMA nodes on the host, indexed by the NUMA node id.
+func GetNUMANodeStats() (map[int]*NUMANodeStat, error) {
+	numaNodeIDs, err := GetNUMANodeIDs()
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[int]*NUMANodeStat, len(numaNodeIDs))
+	for _, numaNodeID := range numaNodeIDs {
+		content, err := os.ReadFile(GetNUMANodeStatPath(numaNodeID))
+		if err != nil {
+			return nil, err
+		}
+		stat, err := ParseNUMANodeStat(string(content))
+		if err != nil {
+			return nil, err
+		}
+		stats[numaNodeID] = stat
+	}
+	return stats, nil
+}
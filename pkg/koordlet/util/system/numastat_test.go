@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNUMANodeStat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *NUMANodeStat
+		wantErr bool
+	}{
+		{
+			name:    "parse numastat",
+			content: "numa_hit 1000\nnuma_miss 20\nnuma_foreign 30\ninterleave_hit 5\nlocal_node 990\nother_node 10\n",
+			want: &NUMANodeStat{
+				NumaHit:     1000,
+				NumaMiss:    20,
+				NumaForeign: 30,
+				LocalNode:   990,
+				OtherNode:   10,
+			},
+		},
+		{
+			name:    "missing field",
+			content: "numa_hit 1000\nnuma_miss 20\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			content: "numa_hit abc\nnuma_miss 20\nnuma_foreign 30\nlocal_node 990\nother_node 10\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNUMANodeStat(tt.content)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetNUMANodeStats(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	helper.WriteFileContents(GetNUMANodeStatPath(0), "numa_hit 100\nnuma_miss 1\nnuma_foreign 2\ninterleave_hit 0\nlocal_node 99\nother_node 1\n")
+	helper.WriteFileContents(GetNUMANodeStatPath(1), "numa_hit 200\nnuma_miss 2\nnuma_foreign 1\ninterleave_hit 0\nlocal_node 190\nother_node 10\n")
+	helper.MkDirAll(filepath.Join(Conf.SysRootDir, SysNUMANodeSubDir, "power"))
+
+	got, err := GetNUMANodeStats()
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]*NUMANodeStat{
+		0: {NumaHit: 100, NumaMiss: 1, NumaForeign: 2, LocalNode: 99, OtherNode: 1},
+		1: {NumaHit: 200, NumaMiss: 2, NumaForeign: 1, LocalNode: 190, OtherNode: 10},
+	}, got)
+}
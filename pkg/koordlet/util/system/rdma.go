@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	SysInfinibandSubDir = "class/infiniband"
+
+	RDMAPortCountersDirName   = "counters"
+	RDMAPortHWCountersDirName = "hw_counters"
+
+	// RDMAPortRcvDataName and RDMAPortXmitDataName count the data octets divided by 4.
+	RDMAPortRcvDataName  = "port_rcv_data"
+	RDMAPortXmitDataName = "port_xmit_data"
+	// RDMANPCNPSentName counts the CNPs sent by the notification point, i.e. the receiver of the congested traffic.
+	RDMANPCNPSentName = "np_cnp_sent"
+	// RDMARPCNPHandledName counts the CNPs handled by the reaction point, i.e. the sender throttled by the congestion.
+	RDMARPCNPHandledName = "rp_cnp_handled"
+	// RDMANPECNMarkedName counts the RoCE packets received with the ECN marks.
+	RDMANPECNMarkedName = "np_ecn_marked_roce_packets"
+)
+
+// RDMAPortCounters is the traffic and congestion counters of a port of an RDMA device in
+// `/sys/class/infiniband/<device>/ports/<port>/`. The congestion counters are the hardware counters of RoCE devices,
+// which are zero if the device does not provide them.
+type RDMAPortCounters struct {
+	// Device is the name of the RDMA device, e.g. mlx5_0.
+	Device string
+	// BusID is the PCIe bus id of the RDMA device, e.g. 0000:3b:00.2, which is empty if unknown.
+	BusID string
+	// Port is the port number of the device, starting from 1.
+	Port string
+
+	RxBytes          uint64
+	TxBytes          uint64
+	CNPSent          uint64
+	CNPHandled       uint64
+	ECNMarkedPackets uint64
+}
+
+func GetRDMADeviceDir(device string) string {
+	return filepath.Join(Conf.SysRootDir, SysInfinibandSubDir, device)
+}
+
+// GetRDMAPortCounters returns the counters of all ports of the RDMA devices on the host, sorted by the device and port.
+func GetRDMAPortCounters() ([]*RDMAPortCounters, error) {
+	deviceDirs, err := filepath.Glob(filepath.Join(Conf.SysRootDir, SysInfinibandSubDir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	var counters []*RDMAPortCounters
+	for _, deviceDir := range deviceDirs {
+		device := filepath.Base(deviceDir)
+		busID := ""
+		// the device links to the PCIe device, e.g. ../../../0000:3b:00.2
+		if pciDevice, err := os.Readlink(filepath.Join(deviceDir, "device")); err == nil {
+			busID = strings.ToLower(filepath.Base(pciDevice))
+		}
+		portDirs, err := filepath.Glob(filepath.Join(deviceDir, "ports", "*"))
+		if err != nil {
+			return nil, err
+		}
+		for _, portDir := range portDirs {
+			c, err := readRDMAPortCounters(portDir)
+			if err != nil {
+				return nil, fmt.Errorf("read counters of rdma device %s failed, err: %v", device, err)
+			}
+			c.Device = device
+			c.BusID = busID
+			c.Port = filepath.Base(portDir)
+			counters = append(counters, c)
+		}
+	}
+	sort.Slice(counters, func(i, j int) bool {
+		if counters[i].Device != counters[j].Device {
+			return counters[i].Device < counters[j].Device
+		}
+		return counters[i].Port < counters[j].Port
+	})
+	return counters, nil
+}
+
+func readRDMAPortCounters(portDir string) (*RDMAPortCounters, error) {
+	c := &RDMAPortCounters{}
+	for _, t := range []struct {
+		file       string
+		value      *uint64
+		multiplier uint64
+		optional   bool
+	}{
+		{file: filepath.Join(RDMAPortCountersDirName, RDMAPortRcvDataName), value: &c.RxBytes, multiplier: 4},
+		{file: filepath.Join(RDMAPortCountersDirName, RDMAPortXmitDataName), value: &c.TxBytes, multiplier: 4},
+		{file: filepath.Join(RDMAPortHWCountersDirName, RDMANPCNPSentName), value: &c.CNPSent, multiplier: 1, optional: true},
+		{file: filepath.Join(RDMAPortHWCountersDirName, RDMARPCNPHandledName), value: &c.CNPHandled, multiplier: 1, optional: true},
+		{file: filepath.Join(RDMAPortHWCountersDirName, RDMANPECNMarkedName), value: &c.ECNMarkedPackets, multiplier: 1, optional: true},
+	} {
+		content, err := os.ReadFile(filepath.Join(portDir, t.file))
+		if err != nil {
+			if t.optional && os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s failed, raw content %s, err: %v", t.file, content, err)
+		}
+		*t.value = v * t.multiplier
+	}
+	return c, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRDMAPortCounters(t *testing.T) {
+	helper := NewFileTestUtil(t)
+	defer helper.Cleanup()
+	oldSysRootDir := Conf.SysRootDir
+	Conf.SysRootDir = filepath.Join(helper.TempDir, "sys")
+	defer func() {
+		Conf.SysRootDir = oldSysRootDir
+	}()
+
+	writePortCounters := func(device, port string, counters map[string]string) {
+		for file, v := range counters {
+			helper.WriteFileContents(filepath.Join(GetRDMADeviceDir(device), "ports", port, file), v)
+		}
+	}
+	writePortCounters("mlx5_1", "1", map[string]string{
+		"counters/port_rcv_data":                 "100",
+		"counters/port_xmit_data":                "200",
+		"hw_counters/np_cnp_sent":                "3",
+		"hw_counters/rp_cnp_handled":             "4",
+		"hw_counters/np_ecn_marked_roce_packets": "5",
+	})
+	assert.NoError(t, os.Symlink("../../../0000:3B:00.2", filepath.Join(GetRDMADeviceDir("mlx5_1"), "device")))
+	// the infiniband device provides no hardware counters
+	writePortCounters("mlx5_0", "1", map[string]string{
+		"counters/port_rcv_data":  "10",
+		"counters/port_xmit_data": "20",
+	})
+
+	got, err := GetRDMAPortCounters()
+	assert.NoError(t, err)
+	assert.Equal(t, []*RDMAPortCounters{
+		{Device: "mlx5_0", Port: "1", RxBytes: 40, TxBytes: 80},
+		{Device: "mlx5_1", BusID: "0000:3b:00.2", Port: "1", RxBytes: 400, TxBytes: 800, CNPSent: 3, CNPHandled: 4, ECNMarkedPackets: 5},
+	}, got)
+
+	// the traffic counters are required
+	writePortCounters("mlx5_2", "1", map[string]string{
+		"counters/port_rcv_data": "10",
+	})
+	got, err = GetRDMAPortCounters()
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
@@ -38,11 +38,21 @@ const (
 	ResctrlDir string = "resctrl/"
 	RdtInfoDir string = "info"
 	L3CatDir   string = "L3"
+	L3MonDir   string = "L3_MON"
+
+	ResctrlMonDataDir   string = "mon_data"
+	ResctrlMonGroupsDir string = "mon_groups"
+	// ResctrlMonL3DirPrefix is the prefix of the monitoring data dir of each L3 cache domain, e.g. mon_L3_00
+	ResctrlMonL3DirPrefix string = "mon_L3_"
 
 	ResctrlSchemataName string = "schemata"
 	ResctrlCbmMaskName  string = "cbm_mask"
 	ResctrlTasksName    string = "tasks"
 
+	ResctrlLLCOccupancyName  string = "llc_occupancy"
+	ResctrlMBMTotalBytesName string = "mbm_total_bytes"
+	ResctrlMBMLocalBytesName string = "mbm_local_bytes"
+
 	// L3SchemataPrefix is the prefix of l3 cat schemata
 	L3SchemataPrefix = "L3"
 	// MbSchemataPrefix is the prefix of mba schemata
@@ -489,6 +499,88 @@ func InitCatGroupIfNotExist(group string) error {
 	return nil
 }
 
+// IsResctrlMonitorEnabled checks if the resctrl monitoring (e.g. CMT, MBM) is enabled, whose info dir L3_MON exists.
+func IsResctrlMonitorEnabled() bool {
+	_, err := os.Stat(filepath.Join(GetResctrlSubsystemDirPath(), RdtInfoDir, L3MonDir))
+	return err == nil
+}
+
+// @groupPath BE, @monGroup pod-uid
+// @return BE/mon_groups/pod-uid
+func GetResctrlMonGroupPath(groupPath, monGroup string) string {
+	return filepath.Join(groupPath, ResctrlMonGroupsDir, monGroup)
+}
+
+// ListResctrlMonGroups returns the names of the monitoring groups under the given resctrl group.
+func ListResctrlMonGroups(groupPath string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(GetResctrlGroupRootDirPath(groupPath), ResctrlMonGroupsDir))
+	if err != nil {
+		return nil, err
+	}
+	var monGroups []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			monGroups = append(monGroups, entry.Name())
+		}
+	}
+	return monGroups, nil
+}
+
+// ResctrlMonData is the monitoring data of a resctrl group summed over the L3 cache domains.
+type ResctrlMonData struct {
+	// LLCOccupancy is the bytes of the last level cache occupied by the tasks of the group.
+	LLCOccupancy uint64
+	// MBMTotalBytes and MBMLocalBytes are the accumulated bytes of the memory traffic of the group, where the local
+	// bytes only count the traffic to the local NUMA node.
+	MBMTotalBytes uint64
+	MBMLocalBytes uint64
+}
+
+// ReadResctrlMonData reads the monitoring data of the resctrl group or monitoring group.
+// e.g. /sys/fs/resctrl/BE/mon_data/mon_L3_00/llc_occupancy, /sys/fs/resctrl/BE/mon_data/mon_L3_01/llc_occupancy
+// A data file is skipped if the hardware does not support it, while an "Unavailable" value (e.g. the RMID is recycled)
+// fails the read, since the sum would be incorrect.
+func ReadResctrlMonData(groupPath string) (*ResctrlMonData, error) {
+	monDataDir := filepath.Join(GetResctrlGroupRootDirPath(groupPath), ResctrlMonDataDir)
+	entries, err := os.ReadDir(monDataDir)
+	if err != nil {
+		return nil, err
+	}
+	data := &ResctrlMonData{}
+	domains := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), ResctrlMonL3DirPrefix) {
+			continue
+		}
+		domains++
+		for _, f := range []struct {
+			name string
+			v    *uint64
+		}{
+			{name: ResctrlLLCOccupancyName, v: &data.LLCOccupancy},
+			{name: ResctrlMBMTotalBytesName, v: &data.MBMTotalBytes},
+			{name: ResctrlMBMLocalBytesName, v: &data.MBMLocalBytes},
+		} {
+			content, err := os.ReadFile(filepath.Join(monDataDir, entry.Name(), f.name))
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			v, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s of %s, content %s, err: %v",
+					f.name, entry.Name(), strings.TrimSpace(string(content)), err)
+			}
+			*f.v += v
+		}
+	}
+	if domains <= 0 {
+		return nil, fmt.Errorf("no l3 monitoring domain found in %s", monDataDir)
+	}
+	return data, nil
+}
+
 func CalculateCatL3MaskValue(cbm uint, startPercent, endPercent int64) (string, error) {
 	// check if the parsed cbm value is valid, eg. 0xff, 0x1, 0x7ff, ...
 	// NOTE: (Cache Bit Masks) X86 hardware requires that these masks have all the '1' bits in a contiguous block.
@@ -227,3 +227,78 @@ func Test_MountResctrlSubsystem(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func Test_ReadResctrlMonData(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		noMonDir bool
+		want     *ResctrlMonData
+		wantErr  bool
+	}{
+		{
+			name:     "mon data not exist",
+			noMonDir: true,
+			wantErr:  true,
+		},
+		{
+			name:    "no l3 domain",
+			files:   map[string]string{},
+			wantErr: true,
+		},
+		{
+			name: "sum over the l3 domains",
+			files: map[string]string{
+				"mon_L3_00/llc_occupancy":   "1048576\n",
+				"mon_L3_00/mbm_total_bytes": "2000\n",
+				"mon_L3_00/mbm_local_bytes": "1500\n",
+				"mon_L3_01/llc_occupancy":   "524288\n",
+				"mon_L3_01/mbm_total_bytes": "1000\n",
+				"mon_L3_01/mbm_local_bytes": "500\n",
+			},
+			want: &ResctrlMonData{
+				LLCOccupancy:  1572864,
+				MBMTotalBytes: 3000,
+				MBMLocalBytes: 2000,
+			},
+		},
+		{
+			name: "mbm not supported",
+			files: map[string]string{
+				"mon_L3_00/llc_occupancy": "1048576\n",
+			},
+			want: &ResctrlMonData{
+				LLCOccupancy: 1048576,
+			},
+		},
+		{
+			name: "data unavailable",
+			files: map[string]string{
+				"mon_L3_00/llc_occupancy":   "1048576\n",
+				"mon_L3_00/mbm_total_bytes": "Unavailable\n",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sysFSRootDir := t.TempDir()
+			Conf = &Config{
+				SysFSRootDir: sysFSRootDir,
+			}
+			groupPath := GetResctrlMonGroupPath("BE", "pod-uid")
+			monDataDir := filepath.Join(sysFSRootDir, ResctrlDir, groupPath, ResctrlMonDataDir)
+			if !tt.noMonDir {
+				assert.NoError(t, os.MkdirAll(monDataDir, 0700))
+			}
+			for name, content := range tt.files {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(monDataDir, name)), 0700))
+				assert.NoError(t, os.WriteFile(filepath.Join(monDataDir, name), []byte(content), 0666))
+			}
+
+			got, err := ReadResctrlMonData(groupPath)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proactive
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
+)
+
+// Reconciler keeps the proactive Reservations of the annotated workloads of a kind, so that the rollouts of the
+// workloads never fail for lack of capacity. The Reservations are sized to the surge of the next rollout and
+// replenished once the rollout completes, when the consumed and outdated ones are garbage collected as well.
+type Reconciler struct {
+	client.Client
+	kind        string
+	newObject   func() client.Object
+	newWorkload func(obj client.Object) *workload
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scheduling.koordinator.sh,resources=reservations,verbs=get;list;watch;create;update;patch;delete
+
+func Add(mgr ctrl.Manager) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ProactiveReservation) {
+		return nil
+	}
+	reconcilers := []*Reconciler{
+		{
+			Client:      mgr.GetClient(),
+			kind:        KindDeployment,
+			newObject:   func() client.Object { return &appsv1.Deployment{} },
+			newWorkload: newDeploymentWorkload,
+		},
+		{
+			Client:      mgr.GetClient(),
+			kind:        KindStatefulSet,
+			newObject:   func() client.Object { return &appsv1.StatefulSet{} },
+			newWorkload: newStatefulSetWorkload,
+		},
+	}
+	for _, r := range reconcilers {
+		if err := r.SetupWithManager(mgr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.newObject()).
+		Watches(&source.Kind{Type: &schedulingv1alpha1.Reservation{}}, handler.EnqueueRequestsFromMapFunc(r.mapReservationToWorkload)).
+		Named("proactive-reservation-" + strings.ToLower(r.kind)).
+		Complete(r)
+}
+
+// mapReservationToWorkload enqueues the workload of the proactive Reservation, since the Reservations are
+// cluster-scoped and can not be owned by the namespaced workloads.
+func (r *Reconciler) mapReservationToWorkload(obj client.Object) []reconcile.Request {
+	if obj.GetLabels()[apiext.LabelProactiveReservationWorkloadKind] != r.kind {
+		return nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(obj.GetAnnotations()[apiext.AnnotationProactiveReservationWorkload])
+	if err != nil || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	obj := r.newObject()
+	var w *workload
+	if err := r.Client.Get(ctx, req.NamespacedName, obj); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("failed to get %s %v, err: %v", r.kind, req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+	} else if obj.GetDeletionTimestamp() == nil && isProactiveReservationEnabled(obj) {
+		w = r.newWorkload(obj)
+	}
+
+	reservations, err := r.listReservations(ctx, req.NamespacedName)
+	if err != nil {
+		klog.Errorf("failed to list proactive reservations of %s %v, err: %v", r.kind, req.NamespacedName, err)
+		return ctrl.Result{}, err
+	}
+
+	// clean up all reservations if the workload is deleted or opts out
+	if w == nil {
+		return ctrl.Result{}, r.deleteReservations(ctx, reservations)
+	}
+	// the reservations are being allocated by the rollout, leave them alone until the rollout completes
+	if !w.rolloutCompleted {
+		klog.V(4).Infof("rollout of %s %v is in progress, skip syncing proactive reservations", r.kind, req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	templateHash := w.templateHash()
+	desired := sets.NewString()
+	for i := 0; i < w.surge; i++ {
+		desired.Insert(w.reservationName(templateHash, i))
+	}
+	var stale []*schedulingv1alpha1.Reservation
+	existing := sets.NewString()
+	for _, reservation := range reservations {
+		if !desired.Has(reservation.Name) || isReservationConsumed(reservation) {
+			stale = append(stale, reservation)
+		} else {
+			existing.Insert(reservation.Name)
+		}
+	}
+	if err = r.deleteReservations(ctx, stale); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the stale reservation is recreated once it is gone, which triggers another reconciliation
+	for _, reservation := range stale {
+		existing.Insert(reservation.Name)
+	}
+	for i := 0; i < w.surge; i++ {
+		reservation := w.newReservation(templateHash, i)
+		if existing.Has(reservation.Name) {
+			continue
+		}
+		if err = r.Client.Create(ctx, reservation); err != nil && !errors.IsAlreadyExists(err) {
+			klog.Errorf("failed to create proactive reservation %s for %s %v, err: %v", reservation.Name, r.kind, req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		klog.V(4).Infof("created proactive reservation %s for %s %v", reservation.Name, r.kind, req.NamespacedName)
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) listReservations(ctx context.Context, key types.NamespacedName) ([]*schedulingv1alpha1.Reservation, error) {
+	reservationList := &schedulingv1alpha1.ReservationList{}
+	if err := r.Client.List(ctx, reservationList, client.MatchingLabels{apiext.LabelProactiveReservationWorkloadKind: r.kind}); err != nil {
+		return nil, err
+	}
+	var reservations []*schedulingv1alpha1.Reservation
+	for i := range reservationList.Items {
+		reservation := &reservationList.Items[i]
+		if reservation.Annotations[apiext.AnnotationProactiveReservationWorkload] == key.String() {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+func (r *Reconciler) deleteReservations(ctx context.Context, reservations []*schedulingv1alpha1.Reservation) error {
+	for _, reservation := range reservations {
+		if reservation.DeletionTimestamp != nil {
+			continue
+		}
+		if err := r.Client.Delete(ctx, reservation); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("failed to delete proactive reservation %s, err: %v", reservation.Name, err)
+			return err
+		}
+		klog.V(4).Infof("deleted proactive reservation %s", reservation.Name)
+	}
+	return nil
+}
+
+// isReservationConsumed checks if the reservation is not allocatable anymore, e.g. allocated by the rollout or
+// expired.
+func isReservationConsumed(r *schedulingv1alpha1.Reservation) bool {
+	return r.Status.Phase == schedulingv1alpha1.ReservationSucceeded || r.Status.Phase == schedulingv1alpha1.ReservationFailed
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proactive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = schedulingv1alpha1.AddToScheme(scheme)
+	return scheme
+}
+
+func newTestDeployment(replicas int32, rolloutCompleted bool) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "nginx",
+			UID:         "123456",
+			Generation:  2,
+			Annotations: map[string]string{apiext.AnnotationProactiveReservation: "true"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "nginx"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "nginx",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("2"),
+									corev1.ResourceMemory: resource.MustParse("4Gi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           replicas,
+			UpdatedReplicas:    replicas,
+			AvailableReplicas:  replicas,
+		},
+	}
+	if !rolloutCompleted {
+		deployment.Status.ObservedGeneration = 1
+	}
+	return deployment
+}
+
+func newTestDeploymentReconciler(objs ...client.Object) *Reconciler {
+	return &Reconciler{
+		Client:      fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objs...).Build(),
+		kind:        KindDeployment,
+		newObject:   func() client.Object { return &appsv1.Deployment{} },
+		newWorkload: newDeploymentWorkload,
+	}
+}
+
+func listTestReservations(t *testing.T, c client.Client) []schedulingv1alpha1.Reservation {
+	reservationList := &schedulingv1alpha1.ReservationList{}
+	assert.NoError(t, c.List(context.TODO(), reservationList))
+	return reservationList.Items
+}
+
+func TestReconcileDeployment(t *testing.T) {
+	deployment := newTestDeployment(4, true)
+	r := newTestDeploymentReconciler(deployment)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+
+	// 25% of 4 replicas
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	reservations := listTestReservations(t, r.Client)
+	assert.Len(t, reservations, 1)
+	reservation := reservations[0]
+	templateHash := newDeploymentWorkload(deployment).templateHash()
+	assert.Equal(t, "deployment-123456-"+templateHash+"-0", reservation.Name)
+	assert.Equal(t, map[string]string{
+		apiext.LabelProactiveReservationWorkloadKind: KindDeployment,
+		apiext.LabelProactiveReservationWorkloadUID:  "123456",
+		apiext.LabelProactiveReservationTemplateHash: templateHash,
+	}, reservation.Labels)
+	assert.Equal(t, "default/nginx", reservation.Annotations[apiext.AnnotationProactiveReservationWorkload])
+	assert.Equal(t, "default", reservation.Spec.Template.Namespace)
+	assert.Equal(t, deployment.Spec.Template.Spec, reservation.Spec.Template.Spec)
+	assert.Equal(t, []schedulingv1alpha1.ReservationOwner{
+		{
+			Object:        &corev1.ObjectReference{Namespace: "default"},
+			LabelSelector: deployment.Spec.Selector,
+		},
+	}, reservation.Spec.Owners)
+	assert.True(t, reservation.Spec.AllocateOnce)
+	assert.Equal(t, &metav1.Duration{}, reservation.Spec.TTL)
+
+	// scale the surge up
+	deployment.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{MaxSurge: &intstr.IntOrString{Type: intstr.Int, IntVal: 3}}
+	assert.NoError(t, r.Client.Update(context.TODO(), deployment))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Len(t, listTestReservations(t, r.Client), 3)
+
+	// no reservation for the Recreate strategy
+	deployment.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	assert.NoError(t, r.Client.Update(context.TODO(), deployment))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, listTestReservations(t, r.Client))
+}
+
+func TestReconcileDeploymentRollout(t *testing.T) {
+	deployment := newTestDeployment(4, true)
+	r := newTestDeploymentReconciler(deployment)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	reservations := listTestReservations(t, r.Client)
+	assert.Len(t, reservations, 1)
+	oldName := reservations[0].Name
+
+	// the reservation is allocated by the rollout of the new template
+	deployment.Spec.Template.Labels["version"] = "v2"
+	deployment.Generation = 3
+	assert.NoError(t, r.Client.Update(context.TODO(), deployment))
+	reservation := &reservations[0]
+	reservation.Status.Phase = schedulingv1alpha1.ReservationSucceeded
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), reservation))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	reservations = listTestReservations(t, r.Client)
+	assert.Len(t, reservations, 1)
+	assert.Equal(t, oldName, reservations[0].Name)
+
+	// the consumed reservation is replaced by a new one for the current template after the rollout completes
+	deployment.Status.ObservedGeneration = 3
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), deployment))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	reservations = listTestReservations(t, r.Client)
+	assert.Len(t, reservations, 1)
+	assert.NotEqual(t, oldName, reservations[0].Name)
+	assert.Equal(t, "v2", reservations[0].Spec.Template.Labels["version"])
+	assert.Equal(t, schedulingv1alpha1.ReservationPhase(""), reservations[0].Status.Phase)
+}
+
+func TestReconcileDeploymentGC(t *testing.T) {
+	deployment := newTestDeployment(4, true)
+	other := newTestDeployment(4, true)
+	other.Name = "other"
+	other.UID = "654321"
+	r := newTestDeploymentReconciler(deployment, other)
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}}
+	otherReq := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "other"}}
+	for _, request := range []ctrl.Request{req, otherReq} {
+		_, err := r.Reconcile(context.TODO(), request)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, listTestReservations(t, r.Client), 2)
+
+	// opt out
+	delete(deployment.Annotations, apiext.AnnotationProactiveReservation)
+	assert.NoError(t, r.Client.Update(context.TODO(), deployment))
+	_, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	reservations := listTestReservations(t, r.Client)
+	assert.Len(t, reservations, 1)
+	assert.Equal(t, "default/other", reservations[0].Annotations[apiext.AnnotationProactiveReservationWorkload])
+
+	// the workload is deleted
+	assert.NoError(t, r.Client.Delete(context.TODO(), other))
+	_, err = r.Reconcile(context.TODO(), otherReq)
+	assert.NoError(t, err)
+	assert.Empty(t, listTestReservations(t, r.Client))
+}
+
+func TestNewStatefulSetWorkload(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32(3)},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 1,
+			Replicas:           3,
+			UpdatedReplicas:    3,
+			ReadyReplicas:      3,
+		},
+	}
+	w := newStatefulSetWorkload(statefulSet)
+	assert.Equal(t, 1, w.surge)
+	assert.True(t, w.rolloutCompleted)
+
+	statefulSet.Status.UpdatedReplicas = 2
+	statefulSet.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	w = newStatefulSetWorkload(statefulSet)
+	assert.Equal(t, 0, w.surge)
+	assert.False(t, w.rolloutCompleted)
+}
+
+func TestMapReservationToWorkload(t *testing.T) {
+	r := newTestDeploymentReconciler()
+	reservation := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-reservation",
+			Labels:      map[string]string{apiext.LabelProactiveReservationWorkloadKind: KindDeployment},
+			Annotations: map[string]string{apiext.AnnotationProactiveReservationWorkload: "default/nginx"},
+		},
+	}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nginx"}},
+	}, r.mapReservationToWorkload(reservation))
+
+	reservation.Labels[apiext.LabelProactiveReservationWorkloadKind] = KindStatefulSet
+	assert.Nil(t, r.mapReservationToWorkload(reservation))
+	assert.Nil(t, r.mapReservationToWorkload(&schedulingv1alpha1.Reservation{}))
+}
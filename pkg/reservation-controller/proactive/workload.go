@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proactive
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+)
+
+// defaultMaxSurge is the default maxSurge of the Deployment rolling update.
+var defaultMaxSurge = intstr.FromString("25%")
+
+// workload is the view of a Deployment or StatefulSet needed to reserve the resources for its next rollout.
+type workload struct {
+	kind     string
+	object   client.Object
+	template *corev1.PodTemplateSpec
+	selector *metav1.LabelSelector
+	// surge is the number of the extra pods created during the rollout.
+	surge int
+	// rolloutCompleted indicates all replicas are updated to the current template and available.
+	rolloutCompleted bool
+}
+
+func isProactiveReservationEnabled(obj client.Object) bool {
+	return obj.GetAnnotations()[apiext.AnnotationProactiveReservation] == "true"
+}
+
+func newDeploymentWorkload(obj client.Object) *workload {
+	deployment := obj.(*appsv1.Deployment)
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	surge := 0
+	if replicas > 0 && deployment.Spec.Strategy.Type != appsv1.RecreateDeploymentStrategyType {
+		maxSurge := defaultMaxSurge
+		if deployment.Spec.Strategy.RollingUpdate != nil && deployment.Spec.Strategy.RollingUpdate.MaxSurge != nil {
+			maxSurge = *deployment.Spec.Strategy.RollingUpdate.MaxSurge
+		}
+		if v, err := intstr.GetScaledValueFromIntOrPercent(&maxSurge, int(replicas), true); err == nil && v > 0 {
+			surge = v
+		}
+	}
+
+	status := deployment.Status
+	return &workload{
+		kind:     KindDeployment,
+		object:   deployment,
+		template: &deployment.Spec.Template,
+		selector: deployment.Spec.Selector,
+		surge:    surge,
+		rolloutCompleted: status.ObservedGeneration >= deployment.Generation &&
+			status.Replicas == replicas &&
+			status.UpdatedReplicas == replicas &&
+			status.AvailableReplicas == replicas,
+	}
+}
+
+// newStatefulSetWorkload returns the workload of the StatefulSet. The pods of a StatefulSet are recreated one by one
+// during the rolling update, so one pod is reserved to keep the resources released by the recreated pod.
+// The partitioned rollouts are regarded as in progress until all replicas are updated.
+func newStatefulSetWorkload(obj client.Object) *workload {
+	statefulSet := obj.(*appsv1.StatefulSet)
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+
+	surge := 0
+	if replicas > 0 && statefulSet.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType {
+		surge = 1
+	}
+
+	status := statefulSet.Status
+	return &workload{
+		kind:     KindStatefulSet,
+		object:   statefulSet,
+		template: &statefulSet.Spec.Template,
+		selector: statefulSet.Spec.Selector,
+		surge:    surge,
+		rolloutCompleted: status.ObservedGeneration >= statefulSet.Generation &&
+			status.Replicas == replicas &&
+			status.UpdatedReplicas == replicas &&
+			status.ReadyReplicas == replicas,
+	}
+}
+
+func (w *workload) templateHash() string {
+	return kubecontroller.ComputeHash(w.template, nil)
+}
+
+func (w *workload) reservationName(templateHash string, index int) string {
+	return fmt.Sprintf("%s-%s-%s-%d", strings.ToLower(w.kind), w.object.GetUID(), templateHash, index)
+}
+
+// newReservation returns the index-th Reservation reserving the resources of a pod for the next rollout.
+// The Reservation never expires and is allocated once by the pods matching the workload selector.
+func (w *workload) newReservation(templateHash string, index int) *schedulingv1alpha1.Reservation {
+	template := w.template.DeepCopy()
+	template.Namespace = w.object.GetNamespace()
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: w.reservationName(templateHash, index),
+			Labels: map[string]string{
+				apiext.LabelProactiveReservationWorkloadKind: w.kind,
+				apiext.LabelProactiveReservationWorkloadUID:  string(w.object.GetUID()),
+				apiext.LabelProactiveReservationTemplateHash: templateHash,
+			},
+			Annotations: map[string]string{
+				apiext.AnnotationProactiveReservationWorkload: client.ObjectKeyFromObject(w.object).String(),
+			},
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: template,
+			Owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					Object:        &corev1.ObjectReference{Namespace: w.object.GetNamespace()},
+					LabelSelector: w.selector.DeepCopy(),
+				},
+			},
+			TTL:          &metav1.Duration{},
+			AllocateOnce: true,
+		},
+	}
+}
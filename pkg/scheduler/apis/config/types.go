@@ -48,6 +48,13 @@ type LoadAwareSchedulingArgs struct {
 	ProdUsageThresholds map[corev1.ResourceName]int64 `json:"prodUsageThresholds,omitempty"`
 	// ScoreAccordingProdUsage controls whether to score according to the utilization of Prod Pod
 	ScoreAccordingProdUsage bool `json:"scoreAccordingProdUsage,omitempty"`
+	// FilterMigratingNodes indicates whether to filter nodes that are the source of in-flight PodMigrationJobs,
+	// so that new Pods are not placed onto nodes currently being unloaded by the descheduler.
+	FilterMigratingNodes bool `json:"filterMigratingNodes,omitempty"`
+	// ProdCPUPressureThreshold indicates the threshold of the CPU pressure (PSI some avg10 in percentage) of the Prod
+	// Pods on the node. The nodes having Prod Pods stalled on the CPU over the threshold are filtered for the Prod Pods,
+	// since the Pods there suffer contention rather than being simply busy. Not enabled by default.
+	ProdCPUPressureThreshold int64 `json:"prodCPUPressureThreshold,omitempty"`
 	// Estimator indicates the expected Estimator to use
 	Estimator string `json:"estimator,omitempty"`
 	// EstimatedScalingFactors indicates the factor when estimating resource usage.
@@ -152,8 +159,29 @@ type ReservationArgs struct {
 
 	// EnablePreemption indicates whether to enable preemption for reservations.
 	EnablePreemption *bool `json:"enablePreemption,omitempty"`
+	// ScoringStrategy indicates how to select the reservation when multiple reservations on the same node or
+	// different nodes match the pod, defaults to MostAllocated.
+	ScoringStrategy ReservationScoringStrategyType `json:"scoringStrategy,omitempty"`
+	// PriorityLabel is the label key whose integer value on the reservations is the priority used by the
+	// LabelPriority strategy. The reservation with the greater value is preferred.
+	PriorityLabel string `json:"priorityLabel,omitempty"`
+	// NodePoolLabel is the label key whose value on the nodes is the node pool, by which the capacity reserved by
+	// the active reservations is aggregated in the metrics besides by the node. No node pool is aggregated if empty.
+	NodePoolLabel string `json:"nodePoolLabel,omitempty"`
 }
 
+// ReservationScoringStrategyType is the scoring strategy of the Reservation plugin.
+type ReservationScoringStrategyType string
+
+const (
+	// ReservationMostAllocated prefers the reservation which will be the most allocated after allocating the pod.
+	ReservationMostAllocated ReservationScoringStrategyType = "MostAllocated"
+	// ReservationOldest prefers the reservation created earliest.
+	ReservationOldest ReservationScoringStrategyType = "Oldest"
+	// ReservationLabelPriority prefers the reservation with the greatest value of the PriorityLabel.
+	ReservationLabelPriority ReservationScoringStrategyType = "LabelPriority"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ElasticQuotaArgs holds arguments used to configure the ElasticQuota plugin.
@@ -180,8 +208,33 @@ type ElasticQuotaArgs struct {
 
 	// EnableCheckParentQuota check parentQuotaGroups' used and runtime Quota in PreFilter
 	EnableCheckParentQuota *bool `json:"enableCheckParentQuota,omitempty"`
+
+	// MinStarvationDuration is the duration the min of a quotaGroup is starved by the over-usage of its siblings
+	// before the over-used pods of the siblings are migrated by PodMigrationJobs, nil or zero disables it.
+	MinStarvationDuration *metav1.Duration `json:"minStarvationDuration,omitempty"`
+
+	// ReservationAccountingMode indicates which quota the reserved but unconsumed resources of the active
+	// reservations are charged to, defaults to Reservation.
+	ReservationAccountingMode ReservationAccountingMode `json:"reservationAccountingMode,omitempty"`
+
+	// SchedulingGateInterval is the interval to gate the pending batch pods which cannot be admitted by the headroom
+	// of their quotas and release them once the headroom is available, nil or zero disables it.
+	SchedulingGateInterval *metav1.Duration `json:"schedulingGateInterval,omitempty"`
 }
 
+// ReservationAccountingMode is the mode to charge the reservations to the elastic quotas.
+type ReservationAccountingMode string
+
+const (
+	// ReservationAccountingReservation charges the reservation to the quota of the reservation itself,
+	// i.e. the quota label of the reservation or its template, or the quota of the template namespace.
+	ReservationAccountingReservation ReservationAccountingMode = "Reservation"
+	// ReservationAccountingOwner charges the reservation to the quota of its owners, i.e. the quota label in the
+	// owner label selector, or the quota of the namespace of the owner object or controller. It prevents the
+	// owners from bypassing their quotas by parking reservations in the other quotas.
+	ReservationAccountingOwner ReservationAccountingMode = "Owner"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // CoschedulingArgs defines the parameters for Gang Scheduling plugin.
@@ -204,4 +257,38 @@ type DeviceShareArgs struct {
 
 	// Allocator indicates the expected allocator to use
 	Allocator string `json:"allocator,omitempty"`
+	// GPUCoreOversellPercent amplifies the gpu-core capacity of each GPU, e.g. 150 means each GPU
+	// can be allocated up to 150 gpu-core. It applies to the nodes without the node annotation
+	// node.koordinator.sh/gpu-core-oversell-percent, and zero means no oversell. gpu-memory is never oversold.
+	GPUCoreOversellPercent int64 `json:"gpuCoreOversellPercent,omitempty"`
+	// EnableDRACompatibility makes the plugin consume the DRA ResourceClaims owned by or reserved for the pod,
+	// translating them into koordinator device requests through the ResourceClass annotation
+	// scheduling.koordinator.sh/dra-device-resources.
+	EnableDRACompatibility bool `json:"enableDRACompatibility,omitempty"`
+	// ScoringStrategy indicates how to score the nodes and select the GPUs, defaults to LeastUtilized.
+	ScoringStrategy DeviceScoringStrategyType `json:"scoringStrategy,omitempty"`
+	// AllowFractionalMultiGPU allows the requests of more than one GPU which are not a multiple of 100,
+	// e.g. gpu-core 150 is split into one full GPU and half of another GPU. The full GPUs are allocated
+	// exclusively, while the partial GPU is shared with other pods and only isolated by the gpu-core and
+	// gpu-memory limits enforced on the node, the same as the requests of less than one GPU.
+	AllowFractionalMultiGPU bool `json:"allowFractionalMultiGPU,omitempty"`
+	// MaxPodsPerGPU limits how many pods can share a single GPU regardless of the remaining gpu-core, since the
+	// context-switch overhead grows with the number of tenants. It applies to the nodes whose Device does not
+	// specify spec.maxPodsPerGPU, and zero means no limit.
+	MaxPodsPerGPU int32 `json:"maxPodsPerGPU,omitempty"`
+	// PodDeviceAllocationThreshold stores the devices allocated by the pod in a PodDeviceAllocation referenced
+	// from the pod instead of the annotation scheduling.koordinator.sh/device-allocated, if the pod allocates
+	// at least this number of devices. Zero means the devices are always recorded in the annotation.
+	PodDeviceAllocationThreshold int32 `json:"podDeviceAllocationThreshold,omitempty"`
 }
+
+// DeviceScoringStrategyType is the scoring strategy of the DeviceShare plugin.
+type DeviceScoringStrategyType string
+
+const (
+	// DeviceLeastUtilized prefers the GPUs physically less loaded according to the usage reported by NodeMetric.
+	DeviceLeastUtilized DeviceScoringStrategyType = "LeastUtilized"
+	// DeviceMinFragmentation prefers the nodes and GPUs on which the placement leaves the least fragments,
+	// i.e. the free resources of partially allocated GPUs which cannot hold another request of the same shape.
+	DeviceMinFragmentation DeviceScoringStrategyType = "MinFragmentation"
+)
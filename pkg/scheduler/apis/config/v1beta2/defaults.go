@@ -77,6 +77,8 @@ var (
 
 	defaultTimeout           = 600 * time.Second
 	defaultControllerWorkers = 1
+
+	defaultGPUCoreOversellPercent int64 = 0
 )
 
 // SetDefaults_LoadAwareSchedulingArgs sets the default parameters for LoadAwareScheduling plugin.
@@ -158,3 +160,10 @@ func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
 		obj.ControllerWorkers = pointer.Int64Ptr(int64(defaultControllerWorkers))
 	}
 }
+
+// SetDefaults_DeviceShareArgs sets the default parameters for DeviceShare plugin.
+func SetDefaults_DeviceShareArgs(obj *DeviceShareArgs) {
+	if obj.GPUCoreOversellPercent == nil {
+		obj.GPUCoreOversellPercent = pointer.Int64(defaultGPUCoreOversellPercent)
+	}
+}
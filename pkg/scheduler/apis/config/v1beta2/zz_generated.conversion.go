@@ -148,6 +148,22 @@ func Convert_config_CoschedulingArgs_To_v1beta2_CoschedulingArgs(in *config.Cosc
 
 func autoConvert_v1beta2_DeviceShareArgs_To_config_DeviceShareArgs(in *DeviceShareArgs, out *config.DeviceShareArgs, s conversion.Scope) error {
 	out.Allocator = in.Allocator
+	if err := v1.Convert_Pointer_int64_To_int64(&in.GPUCoreOversellPercent, &out.GPUCoreOversellPercent, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EnableDRACompatibility, &out.EnableDRACompatibility, s); err != nil {
+		return err
+	}
+	out.ScoringStrategy = config.DeviceScoringStrategyType(in.ScoringStrategy)
+	if err := v1.Convert_Pointer_bool_To_bool(&in.AllowFractionalMultiGPU, &out.AllowFractionalMultiGPU, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxPodsPerGPU, &out.MaxPodsPerGPU, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.PodDeviceAllocationThreshold, &out.PodDeviceAllocationThreshold, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -158,6 +174,22 @@ func Convert_v1beta2_DeviceShareArgs_To_config_DeviceShareArgs(in *DeviceShareAr
 
 func autoConvert_config_DeviceShareArgs_To_v1beta2_DeviceShareArgs(in *config.DeviceShareArgs, out *DeviceShareArgs, s conversion.Scope) error {
 	out.Allocator = in.Allocator
+	if err := v1.Convert_int64_To_Pointer_int64(&in.GPUCoreOversellPercent, &out.GPUCoreOversellPercent, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EnableDRACompatibility, &out.EnableDRACompatibility, s); err != nil {
+		return err
+	}
+	out.ScoringStrategy = DeviceScoringStrategyType(in.ScoringStrategy)
+	if err := v1.Convert_bool_To_Pointer_bool(&in.AllowFractionalMultiGPU, &out.AllowFractionalMultiGPU, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxPodsPerGPU, &out.MaxPodsPerGPU, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.PodDeviceAllocationThreshold, &out.PodDeviceAllocationThreshold, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -174,6 +206,9 @@ func autoConvert_v1beta2_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *Elastic
 	out.QuotaGroupNamespace = in.QuotaGroupNamespace
 	out.MonitorAllQuotas = (*bool)(unsafe.Pointer(in.MonitorAllQuotas))
 	out.EnableCheckParentQuota = (*bool)(unsafe.Pointer(in.EnableCheckParentQuota))
+	out.MinStarvationDuration = (*v1.Duration)(unsafe.Pointer(in.MinStarvationDuration))
+	out.ReservationAccountingMode = config.ReservationAccountingMode(in.ReservationAccountingMode)
+	out.SchedulingGateInterval = (*v1.Duration)(unsafe.Pointer(in.SchedulingGateInterval))
 	return nil
 }
 
@@ -190,6 +225,9 @@ func autoConvert_config_ElasticQuotaArgs_To_v1beta2_ElasticQuotaArgs(in *config.
 	out.QuotaGroupNamespace = in.QuotaGroupNamespace
 	out.MonitorAllQuotas = (*bool)(unsafe.Pointer(in.MonitorAllQuotas))
 	out.EnableCheckParentQuota = (*bool)(unsafe.Pointer(in.EnableCheckParentQuota))
+	out.MinStarvationDuration = (*v1.Duration)(unsafe.Pointer(in.MinStarvationDuration))
+	out.ReservationAccountingMode = ReservationAccountingMode(in.ReservationAccountingMode)
+	out.SchedulingGateInterval = (*v1.Duration)(unsafe.Pointer(in.SchedulingGateInterval))
 	return nil
 }
 
@@ -243,6 +281,12 @@ func autoConvert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingAr
 	if err := v1.Convert_Pointer_bool_To_bool(&in.ScoreAccordingProdUsage, &out.ScoreAccordingProdUsage, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.FilterMigratingNodes, &out.FilterMigratingNodes, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.ProdCPUPressureThreshold, &out.ProdCPUPressureThreshold, s); err != nil {
+		return err
+	}
 	out.Estimator = in.Estimator
 	out.EstimatedScalingFactors = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.EstimatedScalingFactors))
 	if in.Aggregated != nil {
@@ -271,6 +315,12 @@ func autoConvert_config_LoadAwareSchedulingArgs_To_v1beta2_LoadAwareSchedulingAr
 	if err := v1.Convert_bool_To_Pointer_bool(&in.ScoreAccordingProdUsage, &out.ScoreAccordingProdUsage, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.FilterMigratingNodes, &out.FilterMigratingNodes, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.ProdCPUPressureThreshold, &out.ProdCPUPressureThreshold, s); err != nil {
+		return err
+	}
 	out.Estimator = in.Estimator
 	out.EstimatedScalingFactors = *(*map[corev1.ResourceName]int64)(unsafe.Pointer(&in.EstimatedScalingFactors))
 	if in.Aggregated != nil {
@@ -314,6 +364,9 @@ func Convert_config_NodeNUMAResourceArgs_To_v1beta2_NodeNUMAResourceArgs(in *con
 
 func autoConvert_v1beta2_ReservationArgs_To_config_ReservationArgs(in *ReservationArgs, out *config.ReservationArgs, s conversion.Scope) error {
 	out.EnablePreemption = (*bool)(unsafe.Pointer(in.EnablePreemption))
+	out.ScoringStrategy = config.ReservationScoringStrategyType(in.ScoringStrategy)
+	out.PriorityLabel = in.PriorityLabel
+	out.NodePoolLabel = in.NodePoolLabel
 	return nil
 }
 
@@ -324,6 +377,9 @@ func Convert_v1beta2_ReservationArgs_To_config_ReservationArgs(in *ReservationAr
 
 func autoConvert_config_ReservationArgs_To_v1beta2_ReservationArgs(in *config.ReservationArgs, out *ReservationArgs, s conversion.Scope) error {
 	out.EnablePreemption = (*bool)(unsafe.Pointer(in.EnablePreemption))
+	out.ScoringStrategy = ReservationScoringStrategyType(in.ScoringStrategy)
+	out.PriorityLabel = in.PriorityLabel
+	out.NodePoolLabel = in.NodePoolLabel
 	return nil
 }
 
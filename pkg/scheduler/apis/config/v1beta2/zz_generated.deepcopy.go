@@ -67,6 +67,31 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 func (in *DeviceShareArgs) DeepCopyInto(out *DeviceShareArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.GPUCoreOversellPercent != nil {
+		in, out := &in.GPUCoreOversellPercent, &out.GPUCoreOversellPercent
+		*out = new(int64)
+		**out = **in
+	}
+	if in.EnableDRACompatibility != nil {
+		in, out := &in.EnableDRACompatibility, &out.EnableDRACompatibility
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowFractionalMultiGPU != nil {
+		in, out := &in.AllowFractionalMultiGPU, &out.AllowFractionalMultiGPU
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxPodsPerGPU != nil {
+		in, out := &in.MaxPodsPerGPU, &out.MaxPodsPerGPU
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodDeviceAllocationThreshold != nil {
+		in, out := &in.PodDeviceAllocationThreshold, &out.PodDeviceAllocationThreshold
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -126,6 +151,16 @@ func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MinStarvationDuration != nil {
+		in, out := &in.MinStarvationDuration, &out.MinStarvationDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SchedulingGateInterval != nil {
+		in, out := &in.SchedulingGateInterval, &out.SchedulingGateInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -220,6 +255,16 @@ func (in *LoadAwareSchedulingArgs) DeepCopyInto(out *LoadAwareSchedulingArgs) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.FilterMigratingNodes != nil {
+		in, out := &in.FilterMigratingNodes, &out.FilterMigratingNodes
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProdCPUPressureThreshold != nil {
+		in, out := &in.ProdCPUPressureThreshold, &out.ProdCPUPressureThreshold
+		*out = new(int64)
+		**out = **in
+	}
 	if in.EstimatedScalingFactors != nil {
 		in, out := &in.EstimatedScalingFactors, &out.EstimatedScalingFactors
 		*out = make(map[corev1.ResourceName]int64, len(*in))
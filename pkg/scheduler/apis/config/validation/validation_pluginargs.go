@@ -116,6 +116,20 @@ func ValidateElasticQuotaArgs(elasticArgs *config.ElasticQuotaArgs) error {
 		return fmt.Errorf("elasticQuotaArgs error, RevokePodCycle should be a positive value")
 	}
 
+	if elasticArgs.MinStarvationDuration != nil && elasticArgs.MinStarvationDuration.Duration < 0 {
+		return fmt.Errorf("elasticQuotaArgs error, MinStarvationDuration should be a positive value")
+	}
+
+	if elasticArgs.SchedulingGateInterval != nil && elasticArgs.SchedulingGateInterval.Duration < 0 {
+		return fmt.Errorf("elasticQuotaArgs error, SchedulingGateInterval should be a positive value")
+	}
+
+	switch elasticArgs.ReservationAccountingMode {
+	case "", config.ReservationAccountingReservation, config.ReservationAccountingOwner:
+	default:
+		return fmt.Errorf("elasticQuotaArgs error, unsupported ReservationAccountingMode %v", elasticArgs.ReservationAccountingMode)
+	}
+
 	return nil
 }
 
@@ -128,3 +142,36 @@ func ValidateCoschedulingArgs(coeSchedulingArgs *config.CoschedulingArgs) error
 	}
 	return nil
 }
+
+// ValidateDeviceShareArgs validates that DeviceShareArgs are correct.
+func ValidateDeviceShareArgs(args *config.DeviceShareArgs) error {
+	if args.GPUCoreOversellPercent != 0 && args.GPUCoreOversellPercent < 100 {
+		return fmt.Errorf("deviceShareArgs error, GPUCoreOversellPercent should be zero or not less than 100, got %v", args.GPUCoreOversellPercent)
+	}
+	if args.MaxPodsPerGPU < 0 {
+		return fmt.Errorf("deviceShareArgs error, MaxPodsPerGPU should not be negative, got %v", args.MaxPodsPerGPU)
+	}
+	if args.PodDeviceAllocationThreshold < 0 {
+		return fmt.Errorf("deviceShareArgs error, PodDeviceAllocationThreshold should not be negative, got %v", args.PodDeviceAllocationThreshold)
+	}
+	switch args.ScoringStrategy {
+	case "", config.DeviceLeastUtilized, config.DeviceMinFragmentation:
+	default:
+		return fmt.Errorf("deviceShareArgs error, unsupported ScoringStrategy %v", args.ScoringStrategy)
+	}
+	return nil
+}
+
+// ValidateReservationArgs validates that ReservationArgs are correct.
+func ValidateReservationArgs(args *config.ReservationArgs) error {
+	switch args.ScoringStrategy {
+	case "", config.ReservationMostAllocated, config.ReservationOldest:
+	case config.ReservationLabelPriority:
+		if args.PriorityLabel == "" {
+			return fmt.Errorf("reservationArgs error, PriorityLabel is required by the ScoringStrategy %v", args.ScoringStrategy)
+		}
+	default:
+		return fmt.Errorf("reservationArgs error, unsupported ScoringStrategy %v", args.ScoringStrategy)
+	}
+	return nil
+}
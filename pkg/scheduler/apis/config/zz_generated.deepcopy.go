@@ -126,6 +126,16 @@ func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.MinStarvationDuration != nil {
+		in, out := &in.MinStarvationDuration, &out.MinStarvationDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SchedulingGateInterval != nil {
+		in, out := &in.SchedulingGateInterval, &out.SchedulingGateInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	return
 }
 
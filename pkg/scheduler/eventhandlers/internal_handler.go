@@ -17,7 +17,11 @@ limitations under the License.
 package eventhandlers
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/kubernetes/pkg/scheduler"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -29,6 +33,7 @@ type SchedulerInternalHandler interface {
 	GetCache() SchedulerInternalCacheHandler
 	GetQueue() SchedulerInternalQueueHandler
 	MoveAllToActiveOrBackoffQueue(event framework.ClusterEvent)
+	GetNodeInfo(nodeName string) (*framework.NodeInfo, error)
 }
 
 type SchedulerInternalCacheHandler interface {
@@ -52,7 +57,8 @@ type SchedulerInternalQueueHandler interface {
 var _ SchedulerInternalHandler = &SchedulerInternalHandlerImpl{}
 
 type SchedulerInternalHandlerImpl struct {
-	Scheduler *scheduler.Scheduler
+	Scheduler  *scheduler.Scheduler
+	NodeLister corelisters.NodeLister
 }
 
 func (s *SchedulerInternalHandlerImpl) GetCache() SchedulerInternalCacheHandler {
@@ -67,9 +73,22 @@ func (s *SchedulerInternalHandlerImpl) MoveAllToActiveOrBackoffQueue(event frame
 	s.Scheduler.SchedulingQueue.MoveAllToActiveOrBackoffQueue(event, nil)
 }
 
+// GetNodeInfo returns a copy of the node in the scheduler cache. The node in the cache is refreshed with the one in the
+// informer, the same as the update event of the node, which clones only the node instead of dumping the whole cache.
+func (s *SchedulerInternalHandlerImpl) GetNodeInfo(nodeName string) (*framework.NodeInfo, error) {
+	node, err := s.NodeLister.Get(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return s.Scheduler.SchedulerCache.UpdateNode(node, node), nil
+}
+
 var _ SchedulerInternalHandler = &fakeSchedulerInternalHandler{}
 
-type fakeSchedulerInternalHandler struct{}
+type fakeSchedulerInternalHandler struct {
+	nodeInfos map[string]*framework.NodeInfo
+	pods      map[types.UID]*corev1.Pod
+}
 
 func (f *fakeSchedulerInternalHandler) GetCache() SchedulerInternalCacheHandler {
 	return f
@@ -82,11 +101,22 @@ func (f *fakeSchedulerInternalHandler) GetQueue() SchedulerInternalQueueHandler
 func (f *fakeSchedulerInternalHandler) MoveAllToActiveOrBackoffQueue(event framework.ClusterEvent) {
 }
 
+func (f *fakeSchedulerInternalHandler) GetNodeInfo(nodeName string) (*framework.NodeInfo, error) {
+	nodeInfo := f.nodeInfos[nodeName]
+	if nodeInfo == nil {
+		return nil, fmt.Errorf("node %s not found in scheduler cache", nodeName)
+	}
+	return nodeInfo, nil
+}
+
 func (f *fakeSchedulerInternalHandler) AddPod(pod *corev1.Pod) error {
 	return nil
 }
 
 func (f *fakeSchedulerInternalHandler) UpdatePod(oldPod, newPod *corev1.Pod) error {
+	if f.pods != nil {
+		f.pods[newPod.UID] = newPod
+	}
 	return nil
 }
 
@@ -99,7 +129,7 @@ func (f *fakeSchedulerInternalHandler) IsAssumedPod(pod *corev1.Pod) (bool, erro
 }
 
 func (f *fakeSchedulerInternalHandler) GetPod(pod *corev1.Pod) (*corev1.Pod, error) {
-	return nil, nil
+	return f.pods[pod.UID], nil
 }
 
 func (f *fakeSchedulerInternalHandler) Add(pod *corev1.Pod) error {
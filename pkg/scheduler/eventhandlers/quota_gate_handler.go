@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventhandlers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// AddQuotaSchedulingGateErrorHandler keeps the pods refused for the quota scheduling gates out of the scheduling
+// queue, so that they are not retried on every flush of the unschedulable pods. The gated pod is added back to the
+// queue by the pod update when the gate is released.
+func AddQuotaSchedulingGateErrorHandler(sched *scheduler.Scheduler) {
+	defaultErrorFn := sched.Error
+	sched.Error = func(podInfo *framework.QueuedPodInfo, schedulingErr error) {
+		if isQuotaSchedulingGatedError(podInfo.Pod, schedulingErr) {
+			klog.V(4).InfoS("Pod is gated by the quota, waiting for the gate to be released", "pod", klog.KObj(podInfo.Pod))
+			return
+		}
+		defaultErrorFn(podInfo, schedulingErr)
+	}
+}
+
+func isQuotaSchedulingGatedError(pod *corev1.Pod, err error) bool {
+	if !apiext.IsPodQuotaSchedulingGated(pod) {
+		return false
+	}
+	fitError, ok := err.(*framework.FitError)
+	if !ok {
+		return false
+	}
+	// the pod refused in PreFilter has the same status on all the nodes
+	for _, status := range fitError.Diagnosis.NodeToStatusMap {
+		for _, reason := range status.Reasons() {
+			if reason == apiext.ReasonQuotaSchedulingGated {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventhandlers
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestAddQuotaSchedulingGateErrorHandler(t *testing.T) {
+	gatedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			Annotations: map[string]string{
+				apiext.AnnotationQuotaSchedulingGated: "true",
+			},
+		},
+	}
+	newFitError := func(pod *corev1.Pod, reason string) error {
+		return &framework.FitError{
+			Pod:         pod,
+			NumAllNodes: 2,
+			Diagnosis: framework.Diagnosis{
+				NodeToStatusMap: framework.NodeToStatusMap{
+					"test-node-1": framework.NewStatus(framework.UnschedulableAndUnresolvable, reason),
+					"test-node-2": framework.NewStatus(framework.UnschedulableAndUnresolvable, reason),
+				},
+				UnschedulablePlugins: sets.NewString("ElasticQuota"),
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		pod         *corev1.Pod
+		err         error
+		wantRequeue bool
+	}{
+		{
+			name:        "gated pod is not requeued",
+			pod:         gatedPod,
+			err:         newFitError(gatedPod, apiext.ReasonQuotaSchedulingGated),
+			wantRequeue: false,
+		},
+		{
+			name:        "gated pod refused for the other reasons is requeued",
+			pod:         gatedPod,
+			err:         newFitError(gatedPod, "Insufficient cpu"),
+			wantRequeue: true,
+		},
+		{
+			name:        "gated pod failed with an error is requeued",
+			pod:         gatedPod,
+			err:         fmt.Errorf("internal error"),
+			wantRequeue: true,
+		},
+		{
+			name:        "pod not gated is requeued",
+			pod:         &corev1.Pod{},
+			err:         newFitError(gatedPod, apiext.ReasonQuotaSchedulingGated),
+			wantRequeue: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requeued := false
+			sched := &scheduler.Scheduler{
+				Error: func(info *framework.QueuedPodInfo, err error) {
+					requeued = true
+				},
+			}
+			AddQuotaSchedulingGateErrorHandler(sched)
+			sched.Error(&framework.QueuedPodInfo{PodInfo: framework.NewPodInfo(tt.pod)}, tt.err)
+			assert.Equal(t, tt.wantRequeue, requeued)
+		})
+	}
+}
@@ -181,9 +181,12 @@ func truncateMessage(message string) string {
 
 // AddScheduleEventHandler adds reservation event handlers for the scheduler just like pods'.
 // One special case is that reservations have expiration, which the scheduler should cleanup expired ones from the
-// cache and queue.
-func AddScheduleEventHandler(sched *scheduler.Scheduler, internalHandler SchedulerInternalHandler, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory) {
+// cache and queue. Another is that the Available reservations can be resized in place on their nodes.
+func AddScheduleEventHandler(sched *scheduler.Scheduler, internalHandler SchedulerInternalHandler, koordClientSet koordclientset.Interface, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory) {
 	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Informer()
+	resizer := newReservationResizer(internalHandler, koordClientSet,
+		koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Lister(), sched.Profiles)
+	resizer.start(context.TODO().Done())
 	// scheduled reservations for pod cache
 	reservationInformer.AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: func(obj interface{}) bool {
@@ -207,7 +210,7 @@ func AddScheduleEventHandler(sched *scheduler.Scheduler, internalHandler Schedul
 				addReservationToCache(sched, internalHandler, obj)
 			},
 			UpdateFunc: func(oldObj, newObj interface{}) {
-				updateReservationInCache(sched, internalHandler, oldObj, newObj)
+				updateReservationInCache(sched, internalHandler, resizer, oldObj, newObj)
 			},
 			DeleteFunc: func(obj interface{}) {
 				deleteReservationFromCache(sched, internalHandler, obj)
@@ -293,7 +296,7 @@ func addReservationToCache(sched *scheduler.Scheduler, internalHandler Scheduler
 	internalHandler.GetQueue().AssignedPodAdded(reservePod)
 }
 
-func updateReservationInCache(sched *scheduler.Scheduler, internalHandler SchedulerInternalHandler, oldObj, newObj interface{}) {
+func updateReservationInCache(sched *scheduler.Scheduler, internalHandler SchedulerInternalHandler, resizer *reservationResizer, oldObj, newObj interface{}) {
 	oldR, oldOK := oldObj.(*schedulingv1alpha1.Reservation)
 	newR, newOK := newObj.(*schedulingv1alpha1.Reservation)
 	if !oldOK || !newOK {
@@ -324,6 +327,17 @@ func updateReservationInCache(sched *scheduler.Scheduler, internalHandler Schedu
 	}
 	oldReservePod := reservationutil.NewReservePod(oldR)
 	newReservePod := reservationutil.NewReservePod(newR)
+	resizer.lock.Lock()
+	defer resizer.lock.Unlock()
+	// the cached reserve pod holds the resources actually reserved on the node, which differ from the template
+	// until the resize is checked
+	if cachedReservePod, err := internalHandler.GetCache().GetPod(newReservePod); err == nil && cachedReservePod != nil {
+		oldReservePod = cachedReservePod
+	}
+	if isReservePodResized(oldReservePod, newReservePod) {
+		newReservePod = keepReservedResources(newReservePod, oldReservePod)
+		resizer.enqueue(newR)
+	}
 	if err := internalHandler.GetCache().UpdatePod(oldReservePod, newReservePod); err != nil {
 		klog.Errorf("scheduler cache UpdatePod failed for reservation, old %s, new %s, err: %v", klog.KObj(oldR), klog.KObj(newR), err)
 	}
@@ -140,7 +140,7 @@ func TestAddScheduleEventHandler(t *testing.T) {
 		internalHandler := &fakeSchedulerInternalHandler{}
 		koordClientSet := koordfake.NewSimpleClientset()
 		koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
-		AddScheduleEventHandler(sched, internalHandler, koordSharedInformerFactory)
+		AddScheduleEventHandler(sched, internalHandler, koordClientSet, koordSharedInformerFactory)
 	})
 }
 
@@ -332,7 +332,8 @@ func Test_updateReservationInCache(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			updateReservationInCache(nil, tt.args.internalHandler, tt.args.oldObj, tt.args.newObj)
+			resizer := newReservationResizer(tt.args.internalHandler, nil, nil, nil)
+			updateReservationInCache(nil, tt.args.internalHandler, resizer, tt.args.oldObj, tt.args.newObj)
 		})
 	}
 }
@@ -0,0 +1,325 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventhandlers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/profile"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	schedulingv1alpha1lister "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+// isReservePodResized checks if the resource requests of the reserve pod are changed.
+func isReservePodResized(oldReservePod, newReservePod *corev1.Pod) bool {
+	oldRequests, _ := resourceapi.PodRequestsAndLimits(oldReservePod)
+	newRequests, _ := resourceapi.PodRequestsAndLimits(newReservePod)
+	return !quotav1.Equals(oldRequests, newRequests)
+}
+
+// reservationResizer resizes the Available reservations in place on their nodes, so the reservations keep their
+// slots instead of being deleted and recreated. The resized reservation keeps the reserved resources in the scheduler
+// cache until the worker checks the node has the headroom for it, so that the informer event handlers are never
+// blocked by the checks and the API calls. The expansion fails when the node lacks the headroom, and then the
+// reservation keeps the old size, i.e. the template is reverted.
+type reservationResizer struct {
+	internalHandler   SchedulerInternalHandler
+	client            koordclientset.Interface
+	reservationLister schedulingv1alpha1lister.ReservationLister
+	profiles          profile.Map
+	queue             workqueue.RateLimitingInterface
+	// lock serializes the updates of the reserve pods in the scheduler cache by the event handlers and the worker.
+	lock sync.Mutex
+}
+
+func newReservationResizer(internalHandler SchedulerInternalHandler, client koordclientset.Interface,
+	reservationLister schedulingv1alpha1lister.ReservationLister, profiles profile.Map) *reservationResizer {
+	return &reservationResizer{
+		internalHandler:   internalHandler,
+		client:            client,
+		reservationLister: reservationLister,
+		profiles:          profiles,
+		queue:             workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "reservation-resize"),
+	}
+}
+
+func (r *reservationResizer) start(stopCh <-chan struct{}) {
+	go wait.Until(r.worker, time.Second, stopCh)
+}
+
+func (r *reservationResizer) enqueue(reservation *schedulingv1alpha1.Reservation) {
+	r.queue.Add(reservation.Name)
+}
+
+func (r *reservationResizer) worker() {
+	for r.processNextWorkItem() {
+	}
+}
+
+func (r *reservationResizer) processNextWorkItem() bool {
+	key, quit := r.queue.Get()
+	if quit {
+		return false
+	}
+	defer r.queue.Done(key)
+
+	if err := r.resize(key.(string)); err != nil {
+		klog.Warningf("failed to resize reservation %v, retry later, err: %v", key, err)
+		r.queue.AddRateLimited(key)
+		return true
+	}
+	r.queue.Forget(key)
+	return true
+}
+
+// resize checks the resized reservation on its node, and then updates the reserve pod in the scheduler cache and the
+// allocatable of the reservation, or reverts the template if the reservation cannot be resized.
+func (r *reservationResizer) resize(name string) error {
+	reservation, err := r.reservationLister.Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !reservationutil.IsReservationAvailable(reservation) {
+		return nil
+	}
+	newReservePod := reservationutil.NewReservePod(reservation)
+	newRequests, _ := resourceapi.PodRequestsAndLimits(newReservePod)
+	nodeName := reservationutil.GetReservationNodeName(reservation)
+
+	r.lock.Lock()
+	oldReservePod, err := r.internalHandler.GetCache().GetPod(newReservePod)
+	if err != nil || oldReservePod == nil || !isReservePodResized(oldReservePod, newReservePod) {
+		// the reservation is removed from the cache, or the resize is done or reverted
+		r.lock.Unlock()
+		return nil
+	}
+	nodeInfo, err := r.internalHandler.GetNodeInfo(nodeName)
+	if err == nil {
+		err = checkReservationResizable(reservation, nodeInfo, oldReservePod, newRequests)
+	}
+	if err == nil {
+		if status := r.runFilterPlugins(oldReservePod, newReservePod, nodeInfo); !status.IsSuccess() {
+			err = status.AsError()
+		}
+	}
+	if err != nil {
+		r.lock.Unlock()
+		klog.InfoS("Failed to resize reservation, keep the reserved resources", "reservation", klog.KObj(reservation), "node", nodeName, "err", err)
+		rejectReservationResize(r.client, name, newRequests, oldReservePod, err.Error())
+		return nil
+	}
+	if err = r.internalHandler.GetCache().UpdatePod(oldReservePod, newReservePod); err != nil {
+		r.lock.Unlock()
+		return err
+	}
+	r.internalHandler.GetQueue().AssignedPodAdded(newReservePod)
+	r.lock.Unlock()
+
+	klog.V(3).InfoS("Resize reservation in place", "reservation", klog.KObj(reservation), "node", nodeName, "requests", newRequests)
+	completeReservationResize(r.client, name, newRequests)
+	return nil
+}
+
+// runFilterPlugins runs the PreFilter and Filter plugins of the scheduler profile for the resized reserve pod on the
+// node without the reserved resources, which checks the devices and the NUMA resources besides the node resources.
+func (r *reservationResizer) runFilterPlugins(oldReservePod, newReservePod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	fwk, ok := r.profiles[newReservePod.Spec.SchedulerName]
+	if !ok {
+		return nil
+	}
+	if err := nodeInfo.RemovePod(oldReservePod); err != nil {
+		return framework.AsStatus(err)
+	}
+	ctx := context.TODO()
+	cycleState := framework.NewCycleState()
+	if status := fwk.RunPreFilterPlugins(ctx, cycleState, newReservePod); !status.IsSuccess() {
+		return status
+	}
+	if status := fwk.RunPreFilterExtensionRemovePod(ctx, cycleState, newReservePod, framework.NewPodInfo(oldReservePod), nodeInfo); !status.IsSuccess() {
+		return status
+	}
+	return fwk.RunFilterPlugins(ctx, cycleState, newReservePod, nodeInfo).Merge()
+}
+
+// keepReservedResources returns the reserve pod with the resources reserved by the old one.
+func keepReservedResources(newReservePod, oldReservePod *corev1.Pod) *corev1.Pod {
+	keptReservePod := newReservePod.DeepCopy()
+	copyPodSpecResources(&keptReservePod.Spec, &oldReservePod.Spec)
+	return keptReservePod
+}
+
+// checkReservationResizable checks if the node has the headroom for the expanded resources, and the reservation
+// does not shrink below the resources allocated by its owners.
+func checkReservationResizable(r *schedulingv1alpha1.Reservation, nodeInfo *framework.NodeInfo, oldReservePod *corev1.Pod, newRequests corev1.ResourceList) error {
+	if satisfied, exceeded := quotav1.LessThanOrEqual(r.Status.Allocated, newRequests); !satisfied {
+		return fmt.Errorf("cannot shrink below the allocated resources %s", joinResourceNames(exceeded))
+	}
+
+	oldRequests, _ := resourceapi.PodRequestsAndLimits(oldReservePod)
+	oldRequest := framework.NewResource(oldRequests)
+	newRequest := framework.NewResource(newRequests)
+	allocatable, requested := nodeInfo.Allocatable, nodeInfo.Requested
+	var insufficient []corev1.ResourceName
+	checkHeadroom := func(name corev1.ResourceName, newValue, oldValue, allocatable, requested int64) {
+		// the shrunk resources are always satisfied, even if the node is overcommitted
+		if newValue > oldValue && newValue > allocatable-requested+oldValue {
+			insufficient = append(insufficient, name)
+		}
+	}
+	checkHeadroom(corev1.ResourceCPU, newRequest.MilliCPU, oldRequest.MilliCPU, allocatable.MilliCPU, requested.MilliCPU)
+	checkHeadroom(corev1.ResourceMemory, newRequest.Memory, oldRequest.Memory, allocatable.Memory, requested.Memory)
+	checkHeadroom(corev1.ResourceEphemeralStorage, newRequest.EphemeralStorage, oldRequest.EphemeralStorage, allocatable.EphemeralStorage, requested.EphemeralStorage)
+	for name, value := range newRequest.ScalarResources {
+		checkHeadroom(name, value, oldRequest.ScalarResources[name], allocatable.ScalarResources[name], requested.ScalarResources[name])
+	}
+	if len(insufficient) > 0 {
+		return fmt.Errorf("insufficient %s on the node", joinResourceNames(insufficient))
+	}
+	return nil
+}
+
+func joinResourceNames(names []corev1.ResourceName) string {
+	s := make([]string, 0, len(names))
+	for _, name := range names {
+		s = append(s, string(name))
+	}
+	sort.Strings(s)
+	return strings.Join(s, ", ")
+}
+
+// copyPodSpecResources copies the resources of the containers with the same names and the overhead.
+func copyPodSpecResources(dst, src *corev1.PodSpec) {
+	copyResources := func(dstContainers, srcContainers []corev1.Container) {
+		for i := range dstContainers {
+			for j := range srcContainers {
+				if dstContainers[i].Name == srcContainers[j].Name {
+					dstContainers[i].Resources = *srcContainers[j].Resources.DeepCopy()
+					break
+				}
+			}
+		}
+	}
+	copyResources(dst.InitContainers, src.InitContainers)
+	copyResources(dst.Containers, src.Containers)
+	dst.Overhead = src.Overhead.DeepCopy()
+}
+
+// rejectReservationResize reverts the template to the reserved resources and reports the failure, unless the
+// template has been resized again.
+func rejectReservationResize(client koordclientset.Interface, rName string, rejectedRequests corev1.ResourceList, reservedPod *corev1.Pod, msg string) {
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
+		r, err := client.SchedulingV1alpha1().Reservations().Get(context.TODO(), rName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !reservationutil.IsReservationAvailable(r) || r.Spec.Template == nil {
+			return nil
+		}
+		if requests, _ := resourceapi.PodRequestsAndLimits(&corev1.Pod{Spec: r.Spec.Template.Spec}); !quotav1.Equals(requests, rejectedRequests) {
+			klog.V(4).InfoS("skip reverting the resized reservation, the template is changed again", "reservation", rName)
+			return nil
+		}
+
+		curR := r.DeepCopy()
+		copyPodSpecResources(&curR.Spec.Template.Spec, &reservedPod.Spec)
+		if !apiequality.Semantic.DeepEqual(curR.Spec.Template, r.Spec.Template) {
+			if curR, err = client.SchedulingV1alpha1().Reservations().Update(context.TODO(), curR, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+			curR = curR.DeepCopy()
+		}
+		setReservationResizeCondition(curR, schedulingv1alpha1.ConditionStatusFalse, schedulingv1alpha1.ReasonReservationResizeFailed, msg)
+		_, err = client.SchedulingV1alpha1().Reservations().UpdateStatus(context.TODO(), curR, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Warningf("failed to reject the resize of reservation %s, err: %v", rName, err)
+	}
+}
+
+// completeReservationResize updates the allocatable of the reservation to the resized resources.
+func completeReservationResize(client koordclientset.Interface, rName string, requests corev1.ResourceList) {
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
+		r, err := client.SchedulingV1alpha1().Reservations().Get(context.TODO(), rName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if !reservationutil.IsReservationAvailable(r) {
+			return nil
+		}
+
+		curR := r.DeepCopy()
+		curR.Status.Allocatable = requests
+		setReservationResizeCondition(curR, schedulingv1alpha1.ConditionStatusTrue, schedulingv1alpha1.ReasonReservationResized, "")
+		_, err = client.SchedulingV1alpha1().Reservations().UpdateStatus(context.TODO(), curR, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		klog.Warningf("failed to update the allocatable of resized reservation %s, err: %v", rName, err)
+	}
+}
+
+func setReservationResizeCondition(r *schedulingv1alpha1.Reservation, status schedulingv1alpha1.ConditionStatus, reason, msg string) {
+	now := metav1.Now()
+	for i := range r.Status.Conditions {
+		condition := &r.Status.Conditions[i]
+		if condition.Type != schedulingv1alpha1.ReservationConditionResized {
+			continue
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = now
+		}
+		condition.Status = status
+		condition.Reason = reason
+		condition.Message = msg
+		condition.LastProbeTime = now
+		return
+	}
+	r.Status.Conditions = append(r.Status.Conditions, schedulingv1alpha1.ReservationCondition{
+		Type:               schedulingv1alpha1.ReservationConditionResized,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		LastProbeTime:      now,
+		LastTransitionTime: now,
+	})
+}
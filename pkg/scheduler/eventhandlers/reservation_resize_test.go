@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventhandlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/profile"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func TestResizeReservation(t *testing.T) {
+	newReservation := func(cpu string) *schedulingv1alpha1.Reservation {
+		return &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "r-0",
+				UID:  "123",
+			},
+			Spec: schedulingv1alpha1.ReservationSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "main",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse(cpu),
+										corev1.ResourceMemory: resource.MustParse("4Gi"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Owners: []schedulingv1alpha1.ReservationOwner{
+					{
+						Object: &corev1.ObjectReference{Kind: "Pod", Name: "pod-0"},
+					},
+				},
+				TTL: &metav1.Duration{Duration: 30 * time.Minute},
+			},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase:    schedulingv1alpha1.ReservationAvailable,
+				NodeName: "test-node",
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				},
+				Allocated: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		}
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("16"),
+				corev1.ResourceMemory: resource.MustParse("32Gi"),
+			},
+		},
+	}
+	otherPod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("8"),
+							corev1.ResourceMemory: resource.MustParse("8Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	oldR := newReservation("4")
+	oldReservePod := reservationutil.NewReservePod(oldR)
+
+	tests := []struct {
+		name            string
+		cpu             string
+		filterStatus    *framework.Status
+		wantResized     bool
+		wantAllocatable string
+	}{
+		{
+			name:            "expand with the headroom",
+			cpu:             "8",
+			wantResized:     true,
+			wantAllocatable: "8",
+		},
+		{
+			name:            "shrink above the allocated",
+			cpu:             "2",
+			wantResized:     true,
+			wantAllocatable: "2",
+		},
+		{
+			name:            "expand without the headroom",
+			cpu:             "12",
+			wantAllocatable: "4",
+		},
+		{
+			name:            "shrink below the allocated",
+			cpu:             "1",
+			wantAllocatable: "4",
+		},
+		{
+			name:            "expand with the headroom but rejected by the filter plugins",
+			cpu:             "8",
+			filterStatus:    framework.NewStatus(framework.Unschedulable, "Insufficient cpuset"),
+			wantAllocatable: "4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo(otherPod, oldReservePod)
+			nodeInfo.SetNode(node)
+			internalHandler := &fakeSchedulerInternalHandler{
+				nodeInfos: map[string]*framework.NodeInfo{"test-node": nodeInfo},
+				pods:      map[types.UID]*corev1.Pod{oldReservePod.UID: oldReservePod},
+			}
+			newR := newReservation(tt.cpu)
+			client := koordfake.NewSimpleClientset(newR)
+			koordSharedInformerFactory := koordinformers.NewSharedInformerFactory(client, 0)
+			reservationLister := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Lister()
+			assert.NoError(t, koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Informer().GetStore().Add(newR))
+			fwk, err := schedulertesting.NewFramework(
+				[]schedulertesting.RegisterPluginFunc{
+					schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+					schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					schedulertesting.RegisterFilterPlugin("FakeFilter", func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+						return &fakeFilterPlugin{status: tt.filterStatus}, nil
+					}),
+				},
+				corev1.DefaultSchedulerName,
+			)
+			assert.NoError(t, err)
+			resizer := newReservationResizer(internalHandler, client, reservationLister, profile.Map{corev1.DefaultSchedulerName: fwk})
+
+			// the event handler keeps the reserved resources until the resize is checked
+			updateReservationInCache(nil, internalHandler, resizer, oldR, newR)
+			assert.False(t, isReservePodResized(oldReservePod, internalHandler.pods[oldReservePod.UID]))
+			assert.Equal(t, 1, resizer.queue.Len())
+
+			assert.True(t, resizer.processNextWorkItem())
+			assert.Equal(t, 0, resizer.queue.Len())
+			got := internalHandler.pods[oldReservePod.UID]
+			gotR, err := client.SchedulingV1alpha1().Reservations().Get(context.TODO(), newR.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Len(t, gotR.Status.Conditions, 1)
+			assert.Equal(t, schedulingv1alpha1.ReservationConditionResized, gotR.Status.Conditions[0].Type)
+			wantAllocatable := resource.MustParse(tt.wantAllocatable)
+			gotAllocatable := gotR.Status.Allocatable[corev1.ResourceCPU]
+			assert.Equal(t, 0, wantAllocatable.Cmp(gotAllocatable))
+			if tt.wantResized {
+				assert.Equal(t, reservationutil.NewReservePod(newR), got)
+				assert.Equal(t, schedulingv1alpha1.ConditionStatusTrue, gotR.Status.Conditions[0].Status)
+				assert.Equal(t, newR.Spec.Template, gotR.Spec.Template)
+				return
+			}
+			// the reserved resources are kept and the template is reverted
+			assert.False(t, isReservePodResized(oldReservePod, got))
+			assert.Equal(t, schedulingv1alpha1.ConditionStatusFalse, gotR.Status.Conditions[0].Status)
+			assert.Equal(t, schedulingv1alpha1.ReasonReservationResizeFailed, gotR.Status.Conditions[0].Reason)
+			assert.True(t, quotav1.Equals(oldR.Spec.Template.Spec.Containers[0].Resources.Requests, gotR.Spec.Template.Spec.Containers[0].Resources.Requests))
+		})
+	}
+}
+
+type fakeFilterPlugin struct {
+	status *framework.Status
+}
+
+func (f *fakeFilterPlugin) Name() string { return "FakeFilter" }
+
+func (f *fakeFilterPlugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	return f.status
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package frameworkext
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+const ErrReasonNodeSchedulingPaused = "node(s) scheduling paused by koordinator"
+
+// CheckNodeSchedulingPaused returns the unresolvable status if the scheduling of the node is paused, which the
+// koordinator plugins check at the beginning of Filter. The preemption is not attempted on the node either.
+func CheckNodeSchedulingPaused(node *corev1.Node) *framework.Status {
+	if extension.IsNodeSchedulingPaused(node.Annotations) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonNodeSchedulingPaused)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	// SchedulerSubsystem - subsystem name used by koord-scheduler
+	SchedulerSubsystem = "scheduler"
+)
+
+var (
+	DeviceShareNodeDeviceTotal = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "deviceshare_node_device_total",
+			Help:           "Total device resources of the node, by the node name, by the device type, by the resource name.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"node", "device_type", "resource"})
+
+	DeviceShareNodeDeviceAllocated = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "deviceshare_node_device_allocated",
+			Help:           "Allocated device resources of the node, by the node name, by the device type, by the resource name.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"node", "device_type", "resource"})
+
+	DeviceShareAllocationFailures = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "deviceshare_allocation_failures_total",
+			Help:           "Number of device allocation failures of DeviceShare plugin, by the extension point, by the reason.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"extension_point", "reason"})
+
+	DeviceSharePluginDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "deviceshare_plugin_duration_seconds",
+			Help:           "Duration in seconds of DeviceShare plugin, by the extension point, by the status.",
+			Buckets:        metrics.ExponentialBuckets(0.00001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"extension_point", "status"})
+
+	ReservationCount = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "reservations",
+			Help:           "Number of reservations, by the phase.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"phase"})
+
+	ReservationScheduleDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "reservation_schedule_duration_seconds",
+			Help:           "Duration in seconds from the creation of the reservation to it is scheduled.",
+			Buckets:        metrics.ExponentialBuckets(0.01, 2, 20),
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	ReservationResourceUtilization = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "reservation_resource_utilization",
+			Help:           "Number of the active reservations, by the resource name, by the bucket of the ratio of the allocated to the reserved resources.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"resource", "utilization"})
+
+	NodeReservedResources = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "node_reserved_resources",
+			Help:           "Resources reserved by the active reservations on the node, by the node name, by the resource name, by the state which is allocated to the owner pods or unallocated. CPU is in cores and the others are in the base units.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"node", "resource", "state"})
+
+	NodePoolReservedResources = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "node_pool_reserved_resources",
+			Help:           "Resources reserved by the active reservations on the nodes of the node pool, by the node pool, by the resource name, by the state which is allocated to the owner pods or unallocated. CPU is in cores and the others are in the base units.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"pool", "resource", "state"})
+
+	ReservationExpirations = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "reservation_expirations_total",
+			Help:           "Number of expired reservations, by the reason.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"reason"})
+
+	metricsList = []metrics.Registerable{
+		DeviceShareNodeDeviceTotal,
+		DeviceShareNodeDeviceAllocated,
+		DeviceShareAllocationFailures,
+		DeviceSharePluginDuration,
+		ReservationCount,
+		ReservationScheduleDuration,
+		ReservationResourceUtilization,
+		ReservationExpirations,
+		NodeReservedResources,
+		NodePoolReservedResources,
+	}
+)
+
+var registerMetrics sync.Once
+
+// Register all metrics.
+func Register() {
+	// Register the metrics.
+	registerMetrics.Do(func() {
+		RegisterMetrics(metricsList...)
+	})
+}
+
+// RegisterMetrics registers a list of metrics.
+func RegisterMetrics(extraMetrics ...metrics.Registerable) {
+	for _, metric := range extraMetrics {
+		legacyregistry.MustRegister(metric)
+	}
+}
+
+// SinceInSeconds gets the time since the specified start in seconds.
+func SinceInSeconds(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
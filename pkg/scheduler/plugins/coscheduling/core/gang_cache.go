@@ -29,6 +29,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
 type GangCache struct {
@@ -101,6 +102,11 @@ func (gangCache *GangCache) onPodAdd(obj interface{}) {
 	// the gang is created in Annotation way
 	if pod.Labels[v1alpha1.PodGroupLabel] == "" {
 		gang.tryInitByPodConfig(pod, gangCache.pluginArgs)
+	} else if reservationutil.IsReservePod(pod) && gangCache.pgLister != nil {
+		// the gang of the reservations is isolated from the PodGroup, so it is initialized by the PodGroup here
+		if pg, err := gangCache.pgLister.PodGroups(gangNamespace).Get(pod.Labels[v1alpha1.PodGroupLabel]); err == nil {
+			gang.tryInitByPodGroup(pg, gangCache.pluginArgs)
+		}
 	}
 	gang.setChild(pod)
 	if pod.Spec.NodeName != "" {
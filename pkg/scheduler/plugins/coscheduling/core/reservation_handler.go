@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+
+	"k8s.io/client-go/tools/cache"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+// AddReservationEventHandler makes the reservations declaring a gang the children of the gang, so that a group of
+// reservations is scheduled all or nothing like the pods, e.g. a distributed training job pre-claims the resources
+// of all its workers before the pods are submitted. A reservation joins the gang as its reserve pod, which inherits
+// the gang labels and annotations from the reservation and its template.
+func (pgMgr *PodGroupManager) AddReservationEventHandler(koordSharedInformerFactory koordinatorinformers.SharedInformerFactory) {
+	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Informer()
+	reservationEventHandler := reservationutil.NewReservationToPodEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    pgMgr.cache.onPodAdd,
+		UpdateFunc: pgMgr.cache.onReservePodUpdate,
+		DeleteFunc: pgMgr.cache.onPodDelete,
+	}, isGangReservation)
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, reservationInformer, reservationEventHandler)
+}
+
+// onReservePodUpdate marks the reserve pod bound once the reservation is scheduled.
+func (gangCache *GangCache) onReservePodUpdate(oldObj interface{}, newObj interface{}) {
+	gangCache.onPodAdd(newObj)
+}
+
+// isGangReservation checks if the reservation is pending or active and belongs to a gang.
+func isGangReservation(obj interface{}) bool {
+	var r *schedulingv1alpha1.Reservation
+	switch t := obj.(type) {
+	case *schedulingv1alpha1.Reservation:
+		r = t
+	case cache.DeletedFinalStateUnknown:
+		r, _ = t.Obj.(*schedulingv1alpha1.Reservation)
+	}
+	if r == nil || r.Spec.Template == nil {
+		return false
+	}
+	if reservationutil.IsReservationFailed(r) || reservationutil.IsReservationSucceeded(r) {
+		return false
+	}
+	return util.IsPodNeedGang(reservationutil.GetReservePod(r))
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func makeGangReservation(name, gangName string, phase schedulingv1alpha1.ReservationPhase) *schedulingv1alpha1.Reservation {
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(name),
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+				},
+			},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase: phase,
+		},
+	}
+	if gangName != "" {
+		r.Annotations = map[string]string{
+			extension.AnnotationGangName:   gangName,
+			extension.AnnotationGangMinNum: "2",
+		}
+	}
+	return r
+}
+
+func TestPodGroupManager_AddReservationEventHandler(t *testing.T) {
+	reservations := []*schedulingv1alpha1.Reservation{
+		makeGangReservation("r1", "gang-r", schedulingv1alpha1.ReservationPending),
+		makeGangReservation("r2", "gang-r", schedulingv1alpha1.ReservationPending),
+		makeGangReservation("r3", "gang-r", schedulingv1alpha1.ReservationFailed),
+		makeGangReservation("r4", "", schedulingv1alpha1.ReservationPending),
+	}
+	koordClient := koordfake.NewSimpleClientset()
+	for _, r := range reservations {
+		_, err := koordClient.SchedulingV1alpha1().Reservations().Create(context.TODO(), r, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClient, 0)
+	mgr := NewManagerForTest().pgMgr
+	mgr.AddReservationEventHandler(koordSharedInformerFactory)
+
+	// only the pending or active reservations of the gang are the children
+	gang := mgr.cache.getGangFromCacheByGangId("default/reservation:gang-r", false)
+	assert.NotNil(t, gang)
+	assert.Equal(t, 2, gang.getChildrenNum())
+	assert.Equal(t, 2, gang.getGangMinNum())
+
+	// the pods of the gang with the same name are not counted together with the reservations
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod-1",
+			UID:       "pod-1",
+			Annotations: map[string]string{
+				extension.AnnotationGangName:   "gang-r",
+				extension.AnnotationGangMinNum: "2",
+			},
+		},
+	}
+	mgr.cache.onPodAdd(pod)
+	podGang := mgr.cache.getGangFromCacheByGangId("default/gang-r", false)
+	assert.NotNil(t, podGang)
+	assert.Equal(t, 1, podGang.getChildrenNum())
+	assert.Equal(t, 2, gang.getChildrenNum())
+	mgr.cache.onPodDelete(pod)
+
+	// the reservations of the gang are scheduled all or nothing
+	reservePod1 := reservationutil.NewReservePod(reservations[0])
+	reservePod2 := reservationutil.NewReservePod(reservations[1])
+	assert.NoError(t, mgr.PreFilter(context.TODO(), reservePod1))
+	_, status := mgr.Permit(context.TODO(), reservePod1)
+	assert.Equal(t, Wait, status)
+	assert.NoError(t, mgr.PreFilter(context.TODO(), reservePod2))
+	_, status = mgr.Permit(context.TODO(), reservePod2)
+	assert.Equal(t, Success, status)
+
+	// the reservations become bound once scheduled
+	for _, r := range reservations[:2] {
+		scheduled := r.DeepCopy()
+		scheduled.Status.Phase = schedulingv1alpha1.ReservationAvailable
+		scheduled.Status.NodeName = "test-node"
+		mgr.cache.onReservePodUpdate(reservationutil.NewReservePod(r), reservationutil.NewReservePod(scheduled))
+	}
+	assert.Equal(t, 2, gang.getBoundPodNum())
+	assert.True(t, gang.isGangOnceResourceSatisfied())
+
+	// the failed reservation leaves the gang
+	assert.False(t, isGangReservation(reservations[2]))
+	assert.False(t, isGangReservation(reservations[3]))
+	mgr.cache.onPodDelete(reservePod1)
+	assert.Equal(t, 1, gang.getChildrenNum())
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/core"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
 )
@@ -81,6 +82,9 @@ func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	pgInformer := pgInformerFactory.Scheduling().V1alpha1().PodGroups()
 
 	pgMgr := core.NewPodGroupManager(pgClient, pgInformerFactory, handle.SharedInformerFactory(), args)
+	if extendedHandle, ok := handle.(frameworkext.ExtendedHandle); ok {
+		pgMgr.AddReservationEventHandler(extendedHandle.KoordinatorSharedInformerFactory())
+	}
 	plugin := &Coscheduling{
 		args:             args,
 		frameworkHandler: handle,
@@ -154,6 +158,7 @@ func (cs *Coscheduling) Less(podInfo1, podInfo2 *framework.QueuedPodInfo) bool {
 // ii.Check whether the Gang has been timeout(check the pod's annotation,later introduced at Permit section) or is inited, and reject the pod if positive.
 // iii.Check whether the Gang has met the scheduleCycleValid check, and reject the pod if negative.
 // iv.Try update scheduleCycle, scheduleCycleValid, childrenScheduleRoundMap as mentioned above.
+// v.Check whether the aggregated GPU requests of the Gang fit in a single topology domain if the Gang specifies one.
 func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) *framework.Status {
 	// If PreFilter fails, return framework.Error to avoid
 	// any preemption attempts.
@@ -161,6 +166,10 @@ func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleSta
 		klog.ErrorS(err, "PreFilter failed", "pod", klog.KObj(pod))
 		return framework.AsStatus(err)
 	}
+	// fail fast if the whole gang cannot fit in any topology domain instead of timing out after partial placement
+	if status := cs.checkGangGPUAdmission(pod); !status.IsSuccess() {
+		return status
+	}
 	return framework.NewStatus(framework.Success, "")
 }
 
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/util"
+)
+
+const (
+	// ReasonGangGPUAdmissionFailed is the event reason when no topology domain can hold the gang's GPU requests.
+	ReasonGangGPUAdmissionFailed = "GangGPUAdmissionFailed"
+)
+
+// checkGangGPUAdmission validates that the aggregated GPU requests of the pending children still required by the
+// minMember of the pod's gang fit in the free GPU capacity of at least one topology domain, which is specified by
+// the gang annotation `gang.scheduling.koordinator.sh/gpu-topology-key`. The smallest requests are taken, since
+// any of the pending children can satisfy the minMember.
+// The GPU amount is normalized into the percentage of cards, e.g. nvidia.com/gpu=1 equals koordinator.sh/gpu-core=100.
+func (cs *Coscheduling) checkGangGPUAdmission(pod *corev1.Pod) *framework.Status {
+	topologyKey := pod.Annotations[extension.AnnotationGangGPUTopologyKey]
+	if topologyKey == "" || !util.IsPodNeedGang(pod) {
+		return nil
+	}
+	if cs.pgMgr.IsGangMinSatisfied(pod) {
+		return nil
+	}
+
+	gangId := util.GetId(pod.Namespace, util.GetGangNameByPod(pod))
+	gangSummary, ok := cs.pgMgr.GetGangSummary(gangId)
+	if !ok {
+		return nil
+	}
+	assumed := gangSummary.WaitingForBindChildren.Union(gangSummary.BoundChildren)
+	remaining := gangSummary.MinRequiredNumber
+	var pendingRequests []int64
+	for _, child := range cs.pgMgr.GetAllPodsFromGang(gangId) {
+		if child.Spec.NodeName != "" || assumed.Has(util.GetId(child.Namespace, child.Name)) {
+			remaining--
+			continue
+		}
+		pendingRequests = append(pendingRequests, getPodGPURequest(child))
+	}
+	sort.Slice(pendingRequests, func(i, j int) bool {
+		return pendingRequests[i] < pendingRequests[j]
+	})
+	var gangRequested int64
+	for i := 0; i < remaining && i < len(pendingRequests); i++ {
+		gangRequested += pendingRequests[i]
+	}
+	if gangRequested <= 0 {
+		return nil
+	}
+
+	nodeInfos, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	domainFree := getGPUFreeByTopology(nodeInfos, topologyKey)
+	for _, free := range domainFree {
+		if free >= gangRequested {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("gang %v requests %v GPU for its minMember, but no topology domain of %q has enough free GPU",
+		gangId, gangRequested, topologyKey)
+	klog.V(4).InfoS("gang GPU admission failed", "pod", klog.KObj(pod), "gang", gangId, "requested", gangRequested,
+		"topologyKey", topologyKey, "domainFree", domainFree)
+	if recorder := cs.frameworkHandler.EventRecorder(); recorder != nil {
+		recorder.Eventf(pod, nil, corev1.EventTypeWarning, ReasonGangGPUAdmissionFailed, "Scheduling", msg)
+	}
+	// the shortage is transient, the GPUs could be released by the other pods later
+	return framework.NewStatus(framework.Unschedulable, msg)
+}
+
+// getGPUFreeByTopology returns the free GPU capacity of each topology domain.
+// The nodes without the topology label are ignored.
+func getGPUFreeByTopology(nodeInfos []*framework.NodeInfo, topologyKey string) map[string]int64 {
+	domainFree := map[string]int64{}
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		domain, ok := node.Labels[topologyKey]
+		if !ok {
+			continue
+		}
+		total := getNodeGPUCapacity(node.Status.Allocatable)
+		scalarResources := nodeInfo.Requested.ScalarResources
+		requested := scalarResources[extension.ResourceNvidiaGPU]*100 +
+			scalarResources[extension.ResourceGPU] + scalarResources[extension.ResourceGPUCore]
+		if free := total - requested; free > 0 {
+			domainFree[domain] += free
+		} else if _, ok := domainFree[domain]; !ok {
+			domainFree[domain] = 0
+		}
+	}
+	return domainFree
+}
+
+func getNodeGPUCapacity(allocatable corev1.ResourceList) int64 {
+	if gpuCore, ok := allocatable[extension.ResourceGPUCore]; ok {
+		return gpuCore.Value()
+	}
+	nvidiaGPU := allocatable[extension.ResourceNvidiaGPU]
+	return nvidiaGPU.Value() * 100
+}
+
+func getPodGPURequest(pod *corev1.Pod) int64 {
+	requests, _ := resource.PodRequestsAndLimits(pod)
+	nvidiaGPU := requests[extension.ResourceNvidiaGPU]
+	koordGPU := requests[extension.ResourceGPU]
+	gpuCore := requests[extension.ResourceGPUCore]
+	return nvidiaGPU.Value()*100 + koordGPU.Value() + gpuCore.Value()
+}
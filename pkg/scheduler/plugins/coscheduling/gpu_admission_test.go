@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	fakepgclientset "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned/fake"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling/core"
+)
+
+const testZoneKey = "topology.kubernetes.io/zone"
+
+func makeGPUNode(name, zone string, gpus int64) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{testZoneKey: zone},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				extension.ResourceGPUCore: *resource.NewQuantity(gpus*100, resource.DecimalSI),
+			},
+		},
+	}
+}
+
+func makeGPUGangPod(name, gangName string, minNum int, gpus int64, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Annotations: map[string]string{
+				extension.AnnotationGangName:           gangName,
+				extension.AnnotationGangMinNum:         fmt.Sprint(minNum),
+				extension.AnnotationGangGPUTopologyKey: testZoneKey,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							extension.ResourceNvidiaGPU: *resource.NewQuantity(gpus, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetGPUFreeByTopology(t *testing.T) {
+	usedPod := makeGPUGangPod("used", "", 0, 2, "node-1")
+	nodeInfo1 := framework.NewNodeInfo(usedPod)
+	nodeInfo1.SetNode(makeGPUNode("node-1", "zone-a", 4))
+	nodeInfo2 := framework.NewNodeInfo()
+	nodeInfo2.SetNode(makeGPUNode("node-2", "zone-a", 4))
+	nodeInfo3 := framework.NewNodeInfo()
+	nodeInfo3.SetNode(makeGPUNode("node-3", "zone-b", 8))
+	nodeInfo4 := framework.NewNodeInfo()
+	nodeInfo4.SetNode(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-4"}})
+
+	got := getGPUFreeByTopology([]*framework.NodeInfo{nodeInfo1, nodeInfo2, nodeInfo3, nodeInfo4}, testZoneKey)
+	expected := map[string]int64{
+		"zone-a": 600,
+		"zone-b": 800,
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestCheckGangGPUAdmission(t *testing.T) {
+	// no zone has 8 free GPUs
+	smallNodes := []*corev1.Node{
+		makeGPUNode("node-1", "zone-a", 4),
+		makeGPUNode("node-2", "zone-b", 2),
+		makeGPUNode("node-3", "zone-b", 4),
+	}
+	tests := []struct {
+		name        string
+		nodes       []*corev1.Node
+		gangPods    []*corev1.Pod
+		wantSuccess bool
+	}{
+		{
+			name: "gang fits in one zone",
+			gangPods: []*corev1.Pod{
+				makeGPUGangPod("pod-1", "gang-a", 2, 4, ""),
+				makeGPUGangPod("pod-2", "gang-a", 2, 4, ""),
+			},
+			wantSuccess: true,
+		},
+		{
+			name: "gang exceeds every zone",
+			gangPods: []*corev1.Pod{
+				makeGPUGangPod("pod-1", "gang-a", 2, 8, ""),
+				makeGPUGangPod("pod-2", "gang-a", 2, 4, ""),
+			},
+			wantSuccess: false,
+		},
+		{
+			name: "only the children required by minMember are counted",
+			gangPods: []*corev1.Pod{
+				makeGPUGangPod("pod-1", "gang-a", 2, 4, ""),
+				makeGPUGangPod("pod-2", "gang-a", 2, 4, ""),
+				makeGPUGangPod("pod-3", "gang-a", 2, 4, ""),
+			},
+			wantSuccess: true,
+		},
+		{
+			name:  "gang without bound members is checked",
+			nodes: smallNodes,
+			gangPods: []*corev1.Pod{
+				makeGPUGangPod("pod-1", "gang-a", 2, 8, ""),
+				makeGPUGangPod("pod-2", "gang-a", 2, 4, ""),
+				makeGPUGangPod("pod-3", "gang-a", 2, 4, ""),
+			},
+			wantSuccess: false,
+		},
+		{
+			name:  "resource satisfied gang skips the check",
+			nodes: smallNodes,
+			gangPods: []*corev1.Pod{
+				makeGPUGangPod("pod-1", "gang-a", 2, 8, ""),
+				makeGPUGangPod("pod-2", "gang-a", 2, 4, "node-1"),
+				makeGPUGangPod("pod-3", "gang-a", 2, 4, "node-1"),
+			},
+			wantSuccess: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := tt.nodes
+			if nodes == nil {
+				nodes = []*corev1.Node{
+					makeGPUNode("node-1", "zone-a", 8),
+					makeGPUNode("node-2", "zone-b", 4),
+					makeGPUNode("node-3", "zone-b", 4),
+				}
+			}
+			suit := newPluginTestSuit(t, nodes, fakepgclientset.NewSimpleClientset(), kubefake.NewSimpleClientset())
+			gp := suit.plugin.(*Coscheduling)
+			pgMgr := gp.pgMgr.(*core.PodGroupManager)
+			for _, pod := range tt.gangPods {
+				pgMgr.OnPodAdd(pod)
+			}
+			status := gp.checkGangGPUAdmission(tt.gangPods[0])
+			assert.Equal(t, tt.wantSuccess, status.IsSuccess(), status.Message())
+			if !tt.wantSuccess {
+				assert.Equal(t, framework.Unschedulable, status.Code())
+			}
+		})
+	}
+}
@@ -30,8 +30,14 @@ import (
 	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
+// reservationGangNamePrefix isolates the gang of the reservations from the gang of the pods with the same name,
+// so the reserve pods are never counted as the members of the pods' gang. The colon is not allowed in the names of
+// the PodGroups and the pods, which avoids the conflicts with the other gangs.
+const reservationGangNamePrefix = "reservation:"
+
 func GetGangGroupId(s []string) string {
 	sort.Strings(s)
 	return strings.Join(s, ",")
@@ -52,6 +58,9 @@ func GetGangNameByPod(pod *v1.Pod) string {
 			gangName = extension.GetGangName(pod)
 		}
 	}
+	if gangName != "" && reservationutil.IsReservePod(pod) {
+		gangName = reservationGangNamePrefix + gangName
+	}
 	return gangName
 }
 
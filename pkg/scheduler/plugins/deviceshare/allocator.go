@@ -69,7 +69,16 @@ func (a *defaultAllocator) Name() string {
 }
 
 func (a *defaultAllocator) Allocate(nodeName string, pod *corev1.Pod, podRequest corev1.ResourceList, nodeDevice *nodeDevice) (apiext.DeviceAllocations, error) {
-	return nodeDevice.tryAllocateDevice(podRequest)
+	preferredGPUs := nodeDevice.getPreviousGPUs(pod)
+	excludedGPUs := nodeDevice.getExcludedGPUs(pod)
+	if isGPUCompactionPod(pod) {
+		preferredGPUs = preferredGPUs.Union(nodeDevice.getFragmentedGPUs())
+		// the GPU released by the compaction must not be allocated again
+		if minor, ok := getGPUCompactionSourceMinor(pod, nodeName); ok {
+			excludedGPUs.Insert(minor)
+		}
+	}
+	return nodeDevice.tryAllocateDeviceWithPreferredGPUs(podRequest, preferredGPUs, excludedGPUs)
 }
 
 func (a *defaultAllocator) Reserve(pod *corev1.Pod, nodeDevice *nodeDevice, allocations apiext.DeviceAllocations) {
@@ -17,20 +17,32 @@ limitations under the License.
 package deviceshare
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 )
 
+// gpuUsageExpiration is the max age of the GPU usage reported by NodeMetric to be used in allocating and scoring.
+const gpuUsageExpiration = 180 * time.Second
+
+// previousGPUAllocationExpiration is the max age of the GPUs released by the deleted pods to be preferred by the
+// restarted pods of the same controller.
+const previousGPUAllocationExpiration = 10 * time.Minute
+
 // deviceResources is used to present resources per device.
 // we use the minor of device as key
 // "0": {koordinator.sh/gpu-core:100, koordinator.sh/gpu-memory-ratio:100, koordinator.sh/gpu-memory: 16GB}
@@ -67,12 +79,58 @@ func sortDeviceResourcesByMinor(resources deviceResources) []deviceResourceMinor
 	return r
 }
 
+// sortDeviceResourcesByLoad sorts the devices by their physical load in ascending order,
+// and the devices with the same load keep the order of minor.
+func sortDeviceResourcesByLoad(pairs []deviceResourceMinorPair, loads map[int]int64) []deviceResourceMinorPair {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return loads[pairs[i].minor] < loads[pairs[j].minor]
+	})
+	return pairs
+}
+
 type nodeDevice struct {
 	lock        sync.RWMutex
 	deviceTotal map[schedulingv1alpha1.DeviceType]deviceResources
 	deviceFree  map[schedulingv1alpha1.DeviceType]deviceResources
 	deviceUsed  map[schedulingv1alpha1.DeviceType]deviceResources
 	allocateSet map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]map[int]corev1.ResourceList
+	// deviceVFs is the SR-IOV VF inventory of each device, which is sorted by the minor of VF.
+	// It is nil if no device on the node reports VFs.
+	deviceVFs map[schedulingv1alpha1.DeviceType]map[int][]schedulingv1alpha1.VirtualFunction
+	// vfUsed records the minors of VFs bound to pods for each device.
+	vfUsed map[schedulingv1alpha1.DeviceType]map[int]sets.Int32
+	// gpuCoreOversellPercent amplifies the gpu-core capacity of each GPU when calculating deviceFree.
+	// Values not greater than 100 mean no oversell.
+	gpuCoreOversellPercent int64
+	// minimizeGPUFragmentation selects the GPUs leaving the least fragments instead of the less-loaded GPUs.
+	minimizeGPUFragmentation bool
+	// gpuUsage is the physical usage of each GPU reported by koordlet through NodeMetric,
+	// which contains the SM utilization as gpu-core and the memory usage as gpu-memory-ratio.
+	gpuUsage deviceResources
+	// gpuUsageUpdateTime is the update time of the NodeMetric which gpuUsage comes from.
+	gpuUsageUpdateTime time.Time
+	// removedAllocations records the minors allocated to pods which have been released
+	// because the devices are removed from the node.
+	removedAllocations map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]sets.Int
+	// previousGPUAllocations records the GPUs released by the deleted pods for each controller,
+	// which are preferred by the restarted pods of the same controller.
+	previousGPUAllocations map[types.UID]*previousGPUAllocation
+	// gpuVendor is the vendor of the GPUs labeled on the Device, empty means NVIDIA.
+	gpuVendor string
+	// exclusiveGPUs records the pods allocated each GPU exclusively, which is not shared with other pods.
+	exclusiveGPUs map[int]sets.String
+	// maxPodsPerGPU limits the number of pods sharing each GPU, zero means no limit.
+	maxPodsPerGPU int32
+	// gpuPodCountDelta is the change of the pod count of each GPU applied by cloneWithAllocations,
+	// which is not recorded in allocateSet.
+	gpuPodCountDelta map[int]int
+	// reservedDevices are the devices held by the reservations on the node keyed by the reservation UID.
+	reservedDevices map[types.UID]*reservedDevices
+}
+
+type previousGPUAllocation struct {
+	minors       sets.Int
+	releasedTime time.Time
 }
 
 func newNodeDevice() *nodeDevice {
@@ -138,6 +196,23 @@ func (n *nodeDevice) resetDeviceTotal(resources map[schedulingv1alpha1.DeviceTyp
 	}
 }
 
+// getGPULoads returns the physical load of each GPU in percentage, which is the larger one of
+// the SM utilization and the memory usage ratio. It returns nil if the usage is missing or expired.
+func (n *nodeDevice) getGPULoads() map[int]int64 {
+	if len(n.gpuUsage) == 0 || time.Since(n.gpuUsageUpdateTime) >= gpuUsageExpiration {
+		return nil
+	}
+	loads := make(map[int]int64, len(n.gpuUsage))
+	for minor, usage := range n.gpuUsage {
+		load := usage.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value()
+		if memoryRatio := usage.Name(apiext.ResourceGPUMemoryRatio, resource.DecimalSI).Value(); memoryRatio > load {
+			load = memoryRatio
+		}
+		loads[minor] = load
+	}
+	return loads
+}
+
 // updateCacheUsed is used to update deviceUsed when there is a new pod created/deleted
 func (n *nodeDevice) updateCacheUsed(deviceAllocations apiext.DeviceAllocations, pod *corev1.Pod, add bool) {
 	if len(deviceAllocations) > 0 {
@@ -145,7 +220,14 @@ func (n *nodeDevice) updateCacheUsed(deviceAllocations apiext.DeviceAllocations,
 			if !n.isValid(deviceType, pod, add) {
 				continue
 			}
+			if !add {
+				allocations = n.excludeRemovedDevices(deviceType, allocations, pod)
+			}
 			n.updateDeviceUsed(deviceType, allocations, add)
+			n.updateVFUsed(deviceType, allocations, add)
+			if deviceType == schedulingv1alpha1.GPU {
+				n.updateExclusiveGPUs(allocations, pod, add)
+			}
 			n.resetDeviceFree(deviceType)
 			n.updateAllocateSet(deviceType, allocations, pod, add)
 		}
@@ -159,7 +241,11 @@ func (n *nodeDevice) resetDeviceFree(deviceType schedulingv1alpha1.DeviceType) {
 	if n.deviceTotal[deviceType] == nil {
 		n.deviceTotal[deviceType] = make(deviceResources)
 	}
-	n.deviceFree[deviceType] = n.deviceTotal[deviceType].DeepCopy()
+	deviceTotal := n.deviceTotal[deviceType]
+	if deviceType == schedulingv1alpha1.GPU && n.gpuCoreOversellPercent > 100 {
+		deviceTotal = amplifyGPUCore(deviceTotal, n.gpuCoreOversellPercent)
+	}
+	n.deviceFree[deviceType] = deviceTotal.DeepCopy()
 	for minor, usedResource := range n.deviceUsed[deviceType] {
 		if n.deviceFree[deviceType][minor] == nil {
 			n.deviceFree[deviceType][minor] = make(corev1.ResourceList)
@@ -167,12 +253,25 @@ func (n *nodeDevice) resetDeviceFree(deviceType schedulingv1alpha1.DeviceType) {
 		if n.deviceTotal[deviceType][minor] == nil {
 			n.deviceTotal[deviceType][minor] = make(corev1.ResourceList)
 		}
+		// the batch GPU is overcommitted on the GPUs, so it is not taken from the free resources
 		n.deviceFree[deviceType][minor] = quotav1.SubtractWithNonNegativeResult(
-			n.deviceTotal[deviceType][minor],
-			usedResource)
+			deviceTotal[minor],
+			excludeBatchGPUResources(usedResource))
 	}
 }
 
+// amplifyGPUCore returns a copy of the GPU resources whose gpu-core is amplified by the oversell percent.
+// gpu-memory and gpu-memory-ratio are kept strict.
+func amplifyGPUCore(resources deviceResources, percent int64) deviceResources {
+	amplified := resources.DeepCopy()
+	for _, resourceList := range amplified {
+		if gpuCore, ok := resourceList[apiext.ResourceGPUCore]; ok {
+			resourceList[apiext.ResourceGPUCore] = *resource.NewQuantity(gpuCore.Value()*percent/100, resource.DecimalSI)
+		}
+	}
+	return amplified
+}
+
 func (n *nodeDevice) updateDeviceUsed(deviceType schedulingv1alpha1.DeviceType, allocations []*apiext.DeviceAllocation, add bool) {
 	deviceUsed := n.deviceUsed[deviceType]
 	if deviceUsed == nil {
@@ -187,6 +286,7 @@ func (n *nodeDevice) updateDeviceUsed(deviceType schedulingv1alpha1.DeviceType,
 			deviceUsed[int(allocation.Minor)] = quotav1.Add(deviceUsed[int(allocation.Minor)], allocation.Resources)
 		} else {
 			used := quotav1.SubtractWithNonNegativeResult(deviceUsed[int(allocation.Minor)], allocation.Resources)
+			removeZeroBatchGPUResources(used)
 			if quotav1.IsZero(used) {
 				delete(deviceUsed, int(allocation.Minor))
 			} else {
@@ -199,6 +299,184 @@ func (n *nodeDevice) updateDeviceUsed(deviceType schedulingv1alpha1.DeviceType,
 	}
 }
 
+func (n *nodeDevice) updateVFUsed(deviceType schedulingv1alpha1.DeviceType, allocations []*apiext.DeviceAllocation, add bool) {
+	for _, allocation := range allocations {
+		extension, err := apiext.GetDeviceAllocationExtension(allocation)
+		if err != nil {
+			klog.Errorf("failed to parse extension of %v allocation, minor: %v, err: %v", deviceType, allocation.Minor, err)
+			continue
+		}
+		if extension == nil || len(extension.VirtualFunctions) == 0 {
+			continue
+		}
+		if n.vfUsed == nil {
+			n.vfUsed = make(map[schedulingv1alpha1.DeviceType]map[int]sets.Int32)
+		}
+		if n.vfUsed[deviceType] == nil {
+			n.vfUsed[deviceType] = make(map[int]sets.Int32)
+		}
+		minor := int(allocation.Minor)
+		if n.vfUsed[deviceType][minor] == nil {
+			n.vfUsed[deviceType][minor] = sets.NewInt32()
+		}
+		for _, vf := range extension.VirtualFunctions {
+			if add {
+				n.vfUsed[deviceType][minor].Insert(vf.Minor)
+			} else {
+				n.vfUsed[deviceType][minor].Delete(vf.Minor)
+			}
+		}
+		if n.vfUsed[deviceType][minor].Len() == 0 {
+			delete(n.vfUsed[deviceType], minor)
+		}
+		if len(n.vfUsed[deviceType]) == 0 {
+			delete(n.vfUsed, deviceType)
+		}
+	}
+	if len(n.vfUsed) == 0 {
+		n.vfUsed = nil
+	}
+}
+
+// getFreeVFs returns the VFs of the device that are not bound to any pod, ordered by the minor of VF.
+func (n *nodeDevice) getFreeVFs(deviceType schedulingv1alpha1.DeviceType, minor int) []schedulingv1alpha1.VirtualFunction {
+	used := n.vfUsed[deviceType][minor]
+	var freeVFs []schedulingv1alpha1.VirtualFunction
+	for _, vf := range n.deviceVFs[deviceType][minor] {
+		if !used.Has(vf.Minor) {
+			freeVFs = append(freeVFs, vf)
+		}
+	}
+	return freeVFs
+}
+
+// newCommonDeviceAllocation allocates the resources of the device to the pod,
+// and binds the concrete VFs if the pod requests koordinator.sh/rdma-vf.
+func (n *nodeDevice) newCommonDeviceAllocation(deviceType schedulingv1alpha1.DeviceType, minor int, resources corev1.ResourceList) (*apiext.DeviceAllocation, error) {
+	allocation := &apiext.DeviceAllocation{
+		Minor:     int32(minor),
+		Resources: resources,
+	}
+	vfWanted := resources[apiext.ResourceRDMAVF]
+	if deviceType != schedulingv1alpha1.RDMA || vfWanted.Value() <= 0 {
+		return allocation, nil
+	}
+	freeVFs := n.getFreeVFs(deviceType, minor)
+	if int64(len(freeVFs)) < vfWanted.Value() {
+		return nil, fmt.Errorf("device %v-%v does not have enough free VFs, expect %v, got %v", deviceType, minor, vfWanted.Value(), len(freeVFs))
+	}
+	extension, err := json.Marshal(&apiext.DeviceAllocationExtension{
+		VirtualFunctions: freeVFs[:vfWanted.Value()],
+	})
+	if err != nil {
+		return nil, err
+	}
+	allocation.Extension = extension
+	return allocation, nil
+}
+
+// setGPUVendor records the vendor of the GPUs in the allocations for the runtime hooks.
+func (n *nodeDevice) setGPUVendor(allocations []*apiext.DeviceAllocation) error {
+	if n.gpuVendor == "" {
+		return nil
+	}
+	for _, allocation := range allocations {
+		extension, err := apiext.GetDeviceAllocationExtension(allocation)
+		if err != nil {
+			return err
+		}
+		if extension == nil {
+			extension = &apiext.DeviceAllocationExtension{}
+		}
+		extension.GPUVendor = n.gpuVendor
+		data, err := json.Marshal(extension)
+		if err != nil {
+			return err
+		}
+		allocation.Extension = data
+	}
+	return nil
+}
+
+// excludeRemovedDevices drops the allocations which have been released in invalidateRemovedDevices,
+// so that they won't be released again if the device comes back and is allocated to other pods.
+func (n *nodeDevice) excludeRemovedDevices(deviceType schedulingv1alpha1.DeviceType, allocations []*apiext.DeviceAllocation, pod *corev1.Pod) []*apiext.DeviceAllocation {
+	podNamespacedName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	removedMinors := n.removedAllocations[deviceType][podNamespacedName]
+	if removedMinors.Len() == 0 {
+		return allocations
+	}
+	delete(n.removedAllocations[deviceType], podNamespacedName)
+	if len(n.removedAllocations[deviceType]) == 0 {
+		delete(n.removedAllocations, deviceType)
+	}
+	filtered := make([]*apiext.DeviceAllocation, 0, len(allocations))
+	for _, allocation := range allocations {
+		if !removedMinors.Has(int(allocation.Minor)) {
+			filtered = append(filtered, allocation)
+		}
+	}
+	return filtered
+}
+
+// removedDeviceAllocation is the allocation of a pod on a device which has been removed from the node.
+type removedDeviceAllocation struct {
+	pod        types.NamespacedName
+	deviceType schedulingv1alpha1.DeviceType
+	minor      int
+}
+
+// invalidateRemovedDevices releases the allocations on the devices which are missing in the new device resources,
+// e.g. the GPU is removed for RMA, and returns the affected allocations ordered by pod and minor.
+func (n *nodeDevice) invalidateRemovedDevices(resources map[schedulingv1alpha1.DeviceType]deviceResources) []removedDeviceAllocation {
+	var removed []removedDeviceAllocation
+	for deviceType, allocateSet := range n.allocateSet {
+		for podNamespacedName, allocations := range allocateSet {
+			for minor := range allocations {
+				if _, ok := resources[deviceType][minor]; ok {
+					continue
+				}
+				delete(allocations, minor)
+				if n.removedAllocations == nil {
+					n.removedAllocations = make(map[schedulingv1alpha1.DeviceType]map[types.NamespacedName]sets.Int)
+				}
+				if n.removedAllocations[deviceType] == nil {
+					n.removedAllocations[deviceType] = make(map[types.NamespacedName]sets.Int)
+				}
+				if n.removedAllocations[deviceType][podNamespacedName] == nil {
+					n.removedAllocations[deviceType][podNamespacedName] = sets.NewInt()
+				}
+				n.removedAllocations[deviceType][podNamespacedName].Insert(minor)
+				removed = append(removed, removedDeviceAllocation{
+					pod:        podNamespacedName,
+					deviceType: deviceType,
+					minor:      minor,
+				})
+			}
+		}
+		for minor := range n.deviceUsed[deviceType] {
+			if _, ok := resources[deviceType][minor]; !ok {
+				delete(n.deviceUsed[deviceType], minor)
+			}
+		}
+		for minor := range n.vfUsed[deviceType] {
+			if _, ok := resources[deviceType][minor]; !ok {
+				delete(n.vfUsed[deviceType], minor)
+			}
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		if removed[i].pod != removed[j].pod {
+			return removed[i].pod.String() < removed[j].pod.String()
+		}
+		if removed[i].deviceType != removed[j].deviceType {
+			return removed[i].deviceType < removed[j].deviceType
+		}
+		return removed[i].minor < removed[j].minor
+	})
+	return removed
+}
+
 func (n *nodeDevice) isValid(deviceType schedulingv1alpha1.DeviceType, pod *corev1.Pod, add bool) bool {
 	allocateSet := n.allocateSet[deviceType]
 	if allocateSet == nil {
@@ -242,11 +520,17 @@ func (n *nodeDevice) updateAllocateSet(deviceType schedulingv1alpha1.DeviceType,
 }
 
 func (n *nodeDevice) tryAllocateDevice(podRequest corev1.ResourceList) (apiext.DeviceAllocations, error) {
+	return n.tryAllocateDeviceWithPreferredGPUs(podRequest, nil, nil)
+}
+
+// tryAllocateDeviceWithPreferredGPUs tries to allocate the devices, and the preferred GPUs are allocated first
+// if they satisfy the request. The excluded GPUs are never allocated.
+func (n *nodeDevice) tryAllocateDeviceWithPreferredGPUs(podRequest corev1.ResourceList, preferredGPUs, excludedGPUs sets.Int) (apiext.DeviceAllocations, error) {
 	allocateResult := make(apiext.DeviceAllocations)
 
 	for deviceType := range DeviceResourceNames {
 		switch deviceType {
-		case schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA:
+		case schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA, schedulingv1alpha1.NIC:
 			if !hasDeviceResource(podRequest, deviceType) {
 				break
 			}
@@ -257,7 +541,10 @@ func (n *nodeDevice) tryAllocateDevice(podRequest corev1.ResourceList) (apiext.D
 			if !hasDeviceResource(podRequest, deviceType) {
 				break
 			}
-			if err := n.tryAllocateGPU(podRequest, allocateResult); err != nil {
+			if err := n.tryAllocateGPU(podRequest, preferredGPUs, excludedGPUs, allocateResult); err != nil {
+				return nil, err
+			}
+			if err := n.setGPUVendor(allocateResult[schedulingv1alpha1.GPU]); err != nil {
 				return nil, err
 			}
 		default:
@@ -287,6 +574,9 @@ func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, dev
 			podRequestPerCard = corev1.ResourceList{
 				apiext.ResourceRDMA: *resource.NewQuantity(commonDevice.Value()/commonDeviceWanted, resource.DecimalSI),
 			}
+			if vf, ok := podRequest[apiext.ResourceRDMAVF]; ok {
+				podRequestPerCard[apiext.ResourceRDMAVF] = *resource.NewQuantity(vf.Value()/commonDeviceWanted, resource.DecimalSI)
+			}
 		case schedulingv1alpha1.FPGA:
 			commonDevice := podRequest[apiext.ResourceFPGA]
 			commonDeviceWanted = commonDevice.Value() / 100
@@ -298,11 +588,13 @@ func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, dev
 		orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[deviceType])
 		for _, deviceResource := range orderedDeviceResources {
 			if satisfied, _ := quotav1.LessThanOrEqual(podRequestPerCard, deviceResource.resources); satisfied {
+				allocation, err := n.newCommonDeviceAllocation(deviceType, deviceResource.minor, podRequestPerCard)
+				if err != nil {
+					klog.V(5).Infof("skip %v device %v, err: %v", deviceType, deviceResource.minor, err)
+					continue
+				}
 				satisfiedDeviceCount++
-				deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
-					Minor:     int32(deviceResource.minor),
-					Resources: podRequestPerCard,
-				})
+				deviceAllocations = append(deviceAllocations, allocation)
 			}
 			if satisfiedDeviceCount == int(commonDeviceWanted) {
 				allocateResult[deviceType] = deviceAllocations
@@ -316,10 +608,12 @@ func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, dev
 	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[deviceType])
 	for _, deviceResource := range orderedDeviceResources {
 		if satisfied, _ := quotav1.LessThanOrEqual(podRequest, deviceResource.resources); satisfied {
-			deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
-				Minor:     int32(deviceResource.minor),
-				Resources: podRequest,
-			})
+			allocation, err := n.newCommonDeviceAllocation(deviceType, deviceResource.minor, podRequest)
+			if err != nil {
+				klog.V(5).Infof("skip %v device %v, err: %v", deviceType, deviceResource.minor, err)
+				continue
+			}
+			deviceAllocations = append(deviceAllocations, allocation)
 			allocateResult[deviceType] = deviceAllocations
 			return nil
 		}
@@ -328,16 +622,26 @@ func (n *nodeDevice) tryAllocateCommonDevice(podRequest corev1.ResourceList, dev
 	return fmt.Errorf("node does not have enough %v", deviceType)
 }
 
-func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResult apiext.DeviceAllocations) error {
+func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, preferredGPUs, excludedGPUs sets.Int, allocateResult apiext.DeviceAllocations) error {
 	podRequest = quotav1.Mask(podRequest, DeviceResourceNames[schedulingv1alpha1.GPU])
 	nodeDeviceTotal := n.deviceTotal[schedulingv1alpha1.GPU]
 	if len(nodeDeviceTotal) <= 0 {
 		return fmt.Errorf("node does not have enough GPU")
 	}
 
-	fillGPUTotalMem(nodeDeviceTotal, podRequest)
+	if isBatchGPUPod(podRequest) {
+		return n.tryAllocateBatchGPU(podRequest, excludedGPUs, allocateResult)
+	}
+
+	_, memoryInBytes := podRequest[apiext.ResourceGPUMemory]
+	if err := fillGPUTotalMem(nodeDeviceTotal, podRequest); err != nil {
+		return err
+	}
 
 	var deviceAllocations []*apiext.DeviceAllocation
+	if isFractionalMultipleGPUPod(podRequest) {
+		return n.tryAllocateFractionalGPUs(podRequest, memoryInBytes, preferredGPUs, excludedGPUs, allocateResult)
+	}
 	if isMultipleGPUPod(podRequest) {
 		gpuCore, gpuMem, gpuMemRatio := podRequest[apiext.ResourceGPUCore], podRequest[apiext.ResourceGPUMemory], podRequest[apiext.ResourceGPUMemoryRatio]
 		gpuWanted := gpuCore.Value() / 100
@@ -347,7 +651,7 @@ func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResu
 			apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(gpuMemRatio.Value()/gpuWanted, resource.DecimalSI),
 		}
 		satisfiedDeviceCount := 0
-		orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
+		orderedDeviceResources := n.sortGPUResources(podRequestPerCard, preferredGPUs, excludedGPUs)
 		for _, deviceResource := range orderedDeviceResources {
 			if satisfied, _ := quotav1.LessThanOrEqual(podRequestPerCard, deviceResource.resources); satisfied {
 				satisfiedDeviceCount++
@@ -365,7 +669,7 @@ func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResu
 		return fmt.Errorf("node does not have enough GPU")
 	}
 
-	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
+	orderedDeviceResources := n.sortGPUResources(podRequest, preferredGPUs, excludedGPUs)
 	for _, deviceResource := range orderedDeviceResources {
 		if satisfied, _ := quotav1.LessThanOrEqual(podRequest, deviceResource.resources); !satisfied {
 			continue
@@ -382,11 +686,244 @@ func (n *nodeDevice) tryAllocateGPU(podRequest corev1.ResourceList, allocateResu
 	return fmt.Errorf("node does not have enough GPU")
 }
 
+// tryAllocateFractionalGPUs splits a request like gpu-core 150 into the full GPUs allocated exclusively
+// and one partial GPU taking the remaining gpu-core and gpu-memory, e.g. 100 on one GPU and 50 on another.
+// The gpu-memory of each GPU is taken from the GPU itself. If the pod requests gpu-memory in bytes,
+// the full GPUs take their whole memory and the partial GPU takes the remaining bytes.
+func (n *nodeDevice) tryAllocateFractionalGPUs(podRequest corev1.ResourceList, memoryInBytes bool, preferredGPUs, excludedGPUs sets.Int, allocateResult apiext.DeviceAllocations) error {
+	nodeDeviceTotal := n.deviceTotal[schedulingv1alpha1.GPU]
+	gpuCore, gpuMem, gpuMemRatio := podRequest[apiext.ResourceGPUCore], podRequest[apiext.ResourceGPUMemory], podRequest[apiext.ResourceGPUMemoryRatio]
+	fullGPUWanted := gpuCore.Value() / 100
+	partialGPUCore := gpuCore.Value() - fullGPUWanted*100
+	partialGPUMemRatio := gpuMemRatio.Value() - fullGPUWanted*100
+	if !memoryInBytes && partialGPUMemRatio <= 0 {
+		klog.V(5).Infof("pod's fractional GPU request leaves no gpu-memory to the partial GPU, request %v", podRequest)
+		return fmt.Errorf("node does not have enough GPU memory")
+	}
+
+	fullGPURequestPerCard := corev1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+	}
+	var deviceAllocations []*apiext.DeviceAllocation
+	var fullGPUMemory int64
+	allocated := sets.NewInt()
+	for _, deviceResource := range n.sortGPUResources(fullGPURequestPerCard, preferredGPUs, excludedGPUs) {
+		if len(deviceAllocations) == int(fullGPUWanted) {
+			break
+		}
+		totalMemory := nodeDeviceTotal[deviceResource.minor][apiext.ResourceGPUMemory]
+		fullGPURequest := fullGPURequestPerCard.DeepCopy()
+		fullGPURequest[apiext.ResourceGPUMemory] = totalMemory
+		if satisfied, _ := quotav1.LessThanOrEqual(fullGPURequest, deviceResource.resources); satisfied {
+			allocated.Insert(deviceResource.minor)
+			fullGPUMemory += totalMemory.Value()
+			deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
+				Minor:     int32(deviceResource.minor),
+				Resources: fullGPURequest,
+			})
+		}
+	}
+	if len(deviceAllocations) < int(fullGPUWanted) {
+		klog.V(5).Infof("node GPU resource does not satisfy pod's fractional GPU request, expect %v full GPUs, got %v", fullGPUWanted, len(deviceAllocations))
+		return fmt.Errorf("node does not have enough GPU")
+	}
+	partialGPUMem := gpuMem.Value() - fullGPUMemory
+	if memoryInBytes && partialGPUMem <= 0 {
+		klog.V(5).Infof("pod's fractional GPU request leaves no gpu-memory to the partial GPU, request %v", podRequest)
+		return fmt.Errorf("node does not have enough GPU memory")
+	}
+
+	partialGPURequestPerCard := corev1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(partialGPUCore, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(partialGPUMemRatio, resource.DecimalSI),
+	}
+	for _, deviceResource := range n.sortGPUResources(partialGPURequestPerCard, preferredGPUs, excludedGPUs) {
+		if allocated.Has(deviceResource.minor) {
+			continue
+		}
+		totalMemory := nodeDeviceTotal[deviceResource.minor][apiext.ResourceGPUMemory]
+		partialGPURequest := partialGPURequestPerCard.DeepCopy()
+		if memoryInBytes {
+			if totalMemory.IsZero() {
+				continue
+			}
+			partialMemory := *resource.NewQuantity(partialGPUMem, resource.BinarySI)
+			partialGPURequest[apiext.ResourceGPUMemory] = partialMemory
+			partialGPURequest[apiext.ResourceGPUMemoryRatio] = memBytesToRatio(partialMemory, totalMemory)
+		} else {
+			partialGPURequest[apiext.ResourceGPUMemory] = memRatioToBytes(partialGPURequest[apiext.ResourceGPUMemoryRatio], totalMemory)
+		}
+		if satisfied, _ := quotav1.LessThanOrEqual(partialGPURequest, deviceResource.resources); satisfied {
+			deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
+				Minor:     int32(deviceResource.minor),
+				Resources: partialGPURequest,
+			})
+			allocateResult[schedulingv1alpha1.GPU] = deviceAllocations
+			return nil
+		}
+	}
+	klog.V(5).Infof("node GPU resource does not satisfy the partial GPU of pod's fractional GPU request")
+	return fmt.Errorf("node does not have enough GPU")
+}
+
+// tryAllocateBatchGPU allocates the batch GPU reclaimed from the LS pods. The batch GPU is overcommitted on the
+// GPUs allocated to the LS pods, so the capacity of each GPU is only taken by the other batch pods, and the GPUs
+// physically less loaded are preferred. Each GPU is only bounded by its total gpu-core and gpu-memory, the node-level
+// amount reclaimable by GPUReclaimThresholdPercent is not checked here but left to the NodeResourcesFit plugin against
+// the batch GPU allocatable of the node, which can be stale until slo-controller updates it.
+func (n *nodeDevice) tryAllocateBatchGPU(podRequest corev1.ResourceList, excludedGPUs sets.Int, allocateResult apiext.DeviceAllocations) error {
+	batchGPUCore, batchGPUMem := podRequest[apiext.BatchGPUCore], podRequest[apiext.BatchGPUMemory]
+	var gpuWanted int64 = 1
+	if batchGPUCore.Value() > 100 {
+		gpuWanted = batchGPUCore.Value() / 100
+	}
+	podRequestPerCard := corev1.ResourceList{
+		apiext.BatchGPUCore:   *resource.NewQuantity(batchGPUCore.Value()/gpuWanted, resource.DecimalSI),
+		apiext.BatchGPUMemory: *resource.NewQuantity(batchGPUMem.Value()/gpuWanted, resource.BinarySI),
+	}
+
+	var deviceAllocations []*apiext.DeviceAllocation
+	for _, deviceResource := range n.sortBatchGPUResources(excludedGPUs) {
+		if satisfied, _ := quotav1.LessThanOrEqual(podRequestPerCard, deviceResource.resources); !satisfied {
+			continue
+		}
+		deviceAllocations = append(deviceAllocations, &apiext.DeviceAllocation{
+			Minor:     int32(deviceResource.minor),
+			Resources: podRequestPerCard,
+		})
+		if len(deviceAllocations) == int(gpuWanted) {
+			allocateResult[schedulingv1alpha1.GPU] = deviceAllocations
+			return nil
+		}
+	}
+	klog.V(5).Infof("node GPU resource does not satisfy pod's batch GPU request, expect %v, got %v", gpuWanted, len(deviceAllocations))
+	return fmt.Errorf("node does not have enough batch GPU")
+}
+
+// sortBatchGPUResources returns the batch GPU free on each GPU ordered by the physical load, which is the total
+// gpu-core and gpu-memory of the GPU minus the ones allocated to the batch pods. The excluded GPUs are not returned.
+func (n *nodeDevice) sortBatchGPUResources(excludedGPUs sets.Int) []deviceResourceMinorPair {
+	batchFree := deviceResources{}
+	// the GPUs removed from deviceFree are not allocatable, e.g. the ones not reclaimed in the preemption
+	for minor := range n.deviceFree[schedulingv1alpha1.GPU] {
+		if excludedGPUs.Has(minor) {
+			continue
+		}
+		total := n.deviceTotal[schedulingv1alpha1.GPU][minor]
+		used := n.deviceUsed[schedulingv1alpha1.GPU][minor]
+		batchFree[minor] = quotav1.SubtractWithNonNegativeResult(corev1.ResourceList{
+			apiext.BatchGPUCore:   total.Name(apiext.ResourceGPUCore, resource.DecimalSI).DeepCopy(),
+			apiext.BatchGPUMemory: total.Name(apiext.ResourceGPUMemory, resource.BinarySI).DeepCopy(),
+		}, quotav1.Mask(used, []corev1.ResourceName{apiext.BatchGPUCore, apiext.BatchGPUMemory}))
+	}
+	orderedDeviceResources := sortDeviceResourcesByMinor(batchFree)
+	if loads := n.getGPULoads(); loads != nil {
+		orderedDeviceResources = sortDeviceResourcesByLoad(orderedDeviceResources, loads)
+	}
+	return orderedDeviceResources
+}
+
+// sortGPUResources returns the free GPUs ordered by the fragments left by the request if minimizeGPUFragmentation
+// is enabled, or by the physical load if the GPU usage is reported, so that the pods land on less-loaded GPUs.
+// Otherwise, the GPUs are ordered by minor. The preferred GPUs are always placed ahead of the others,
+// and the excluded GPUs are not returned.
+func (n *nodeDevice) sortGPUResources(podRequestPerCard corev1.ResourceList, preferredGPUs, excludedGPUs sets.Int) []deviceResourceMinorPair {
+	orderedDeviceResources := sortDeviceResourcesByMinor(n.deviceFree[schedulingv1alpha1.GPU])
+	if excludedGPUs.Len() > 0 {
+		candidates := orderedDeviceResources[:0]
+		for _, pair := range orderedDeviceResources {
+			if !excludedGPUs.Has(pair.minor) {
+				candidates = append(candidates, pair)
+			}
+		}
+		orderedDeviceResources = candidates
+	}
+	if n.minimizeGPUFragmentation {
+		orderedDeviceResources = n.sortGPUResourcesByFragmentation(orderedDeviceResources, podRequestPerCard)
+	} else if loads := n.getGPULoads(); loads != nil {
+		orderedDeviceResources = sortDeviceResourcesByLoad(orderedDeviceResources, loads)
+	}
+	if preferredGPUs.Len() > 0 {
+		sort.SliceStable(orderedDeviceResources, func(i, j int) bool {
+			return preferredGPUs.Has(orderedDeviceResources[i].minor) && !preferredGPUs.Has(orderedDeviceResources[j].minor)
+		})
+	}
+	return orderedDeviceResources
+}
+
+// recordPreviousGPUs records the GPUs released by the deleted pod for its controller. The GPUs released by the
+// pods of the same controller are merged, since the pods can be deleted together, e.g. by a rolling restart.
+func (n *nodeDevice) recordPreviousGPUs(pod *corev1.Pod, allocations []*apiext.DeviceAllocation) {
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil || len(allocations) == 0 {
+		return
+	}
+	now := time.Now()
+	for uid, previous := range n.previousGPUAllocations {
+		if now.Sub(previous.releasedTime) >= previousGPUAllocationExpiration {
+			delete(n.previousGPUAllocations, uid)
+		}
+	}
+	if n.previousGPUAllocations == nil {
+		n.previousGPUAllocations = map[types.UID]*previousGPUAllocation{}
+	}
+	previous := n.previousGPUAllocations[controllerRef.UID]
+	if previous == nil {
+		previous = &previousGPUAllocation{minors: sets.NewInt()}
+		n.previousGPUAllocations[controllerRef.UID] = previous
+	}
+	for _, allocation := range allocations {
+		previous.minors.Insert(int(allocation.Minor))
+	}
+	previous.releasedTime = now
+}
+
+// getPreviousGPUs returns the GPUs released by the deleted pods of the same controller as the pod.
+func (n *nodeDevice) getPreviousGPUs(pod *corev1.Pod) sets.Int {
+	if pod == nil || len(n.previousGPUAllocations) == 0 {
+		return nil
+	}
+	controllerRef := metav1.GetControllerOf(pod)
+	if controllerRef == nil {
+		return nil
+	}
+	previous := n.previousGPUAllocations[controllerRef.UID]
+	if previous == nil || time.Since(previous.releasedTime) >= previousGPUAllocationExpiration {
+		return nil
+	}
+	return previous.minors
+}
+
+// reusePreviousGPUs checks whether all the GPUs allocated are previously used by the pods of the same controller.
+func (n *nodeDevice) reusePreviousGPUs(pod *corev1.Pod, allocations []*apiext.DeviceAllocation) bool {
+	previous := n.getPreviousGPUs(pod)
+	if previous.Len() == 0 || len(allocations) == 0 {
+		return false
+	}
+	for _, allocation := range allocations {
+		if !previous.Has(int(allocation.Minor)) {
+			return false
+		}
+	}
+	return true
+}
+
 type nodeDeviceCache struct {
 	lock sync.RWMutex
 	// nodeDeviceInfos stores nodeDevice for each node
 	// and uses node name as map key.
 	nodeDeviceInfos map[string]*nodeDevice
+	// defaultGPUCoreOversellPercent is used by the nodes without gpu-core oversell annotation.
+	defaultGPUCoreOversellPercent int64
+	// minimizeGPUFragmentation is set to the nodeDevice of each node.
+	minimizeGPUFragmentation bool
+	// defaultMaxPodsPerGPU is used by the nodes whose Device does not specify maxPodsPerGPU.
+	defaultMaxPodsPerGPU int32
+	// onDevicesRemoved is called with the allocations invalidated because their devices are removed from the node.
+	onDevicesRemoved func(nodeName string, removed []removedDeviceAllocation)
+	// podDeviceAllocations resolves the devices of the pods referencing PodDeviceAllocations.
+	podDeviceAllocations *podDeviceAllocationStore
 }
 
 func newNodeDeviceCache() *nodeDeviceCache {
@@ -404,8 +941,12 @@ func (n *nodeDeviceCache) getNodeDevice(nodeName string) *nodeDevice {
 func (n *nodeDeviceCache) createNodeDevice(nodeName string) *nodeDevice {
 	n.lock.Lock()
 	defer n.lock.Unlock()
-	n.nodeDeviceInfos[nodeName] = newNodeDevice()
-	return n.nodeDeviceInfos[nodeName]
+	info := newNodeDevice()
+	info.gpuCoreOversellPercent = n.defaultGPUCoreOversellPercent
+	info.minimizeGPUFragmentation = n.minimizeGPUFragmentation
+	info.maxPodsPerGPU = n.defaultMaxPodsPerGPU
+	n.nodeDeviceInfos[nodeName] = info
+	return info
 }
 
 func (n *nodeDeviceCache) removeNodeDevice(nodeName string) {
@@ -414,6 +955,11 @@ func (n *nodeDeviceCache) removeNodeDevice(nodeName string) {
 	}
 	n.lock.Lock()
 	defer n.lock.Unlock()
+	if info := n.nodeDeviceInfos[nodeName]; info != nil {
+		info.lock.Lock()
+		info.deleteMetrics(nodeName)
+		info.lock.Unlock()
+	}
 	delete(n.nodeDeviceInfos, nodeName)
 }
 
@@ -427,27 +973,153 @@ func (n *nodeDeviceCache) updateNodeDevice(nodeName string, device *schedulingv1
 		info = n.createNodeDevice(nodeName)
 	}
 
+	removed := n.resetNodeDevice(info, nodeName, device)
+	if len(removed) > 0 && n.onDevicesRemoved != nil {
+		n.onDevicesRemoved(nodeName, removed)
+	}
+}
+
+func (n *nodeDeviceCache) resetNodeDevice(info *nodeDevice, nodeName string, device *schedulingv1alpha1.Device) []removedDeviceAllocation {
 	info.lock.Lock()
 	defer info.lock.Unlock()
 
 	nodeDeviceResource := map[schedulingv1alpha1.DeviceType]deviceResources{}
+	var nodeDeviceVFs map[schedulingv1alpha1.DeviceType]map[int][]schedulingv1alpha1.VirtualFunction
+	faultyDevices := getFaultyDevices(device)
 	for _, deviceInfo := range device.Spec.Devices {
 		if nodeDeviceResource[deviceInfo.Type] == nil {
 			nodeDeviceResource[deviceInfo.Type] = make(deviceResources)
 		}
-		if !deviceInfo.Health {
+		if !deviceInfo.Health || faultyDevices[deviceInfo.Type].Has(int(*deviceInfo.Minor)) {
 			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = make(corev1.ResourceList)
 			klog.Errorf("Find device unhealthy, nodeName:%v, deviceType:%v, minor:%v",
 				nodeName, deviceInfo.Type, deviceInfo.Minor)
 		} else {
 			resources := apiext.TransformDeprecatedDeviceResources(deviceInfo.Resources)
+			if deviceInfo.Type == schedulingv1alpha1.GPU {
+				fillGPUMemoryRatio(resources)
+			}
+			if deviceInfo.Type == schedulingv1alpha1.RDMA && len(deviceInfo.VFs) > 0 {
+				// the VF inventory is the source of truth of koordinator.sh/rdma-vf
+				resources[apiext.ResourceRDMAVF] = *resource.NewQuantity(int64(len(deviceInfo.VFs)), resource.DecimalSI)
+				vfs := make([]schedulingv1alpha1.VirtualFunction, len(deviceInfo.VFs))
+				copy(vfs, deviceInfo.VFs)
+				sort.Slice(vfs, func(i, j int) bool {
+					return vfs[i].Minor < vfs[j].Minor
+				})
+				if nodeDeviceVFs == nil {
+					nodeDeviceVFs = make(map[schedulingv1alpha1.DeviceType]map[int][]schedulingv1alpha1.VirtualFunction)
+				}
+				if nodeDeviceVFs[deviceInfo.Type] == nil {
+					nodeDeviceVFs[deviceInfo.Type] = make(map[int][]schedulingv1alpha1.VirtualFunction)
+				}
+				nodeDeviceVFs[deviceInfo.Type][int(*deviceInfo.Minor)] = vfs
+			}
 			nodeDeviceResource[deviceInfo.Type][int(*deviceInfo.Minor)] = resources
 			klog.V(5).Infof("Find device resource update, nodeName:%v, deviceType:%v, minor:%v, res:%v",
 				nodeName, deviceInfo.Type, deviceInfo.Minor, resources)
 		}
 	}
 
+	removed := info.invalidateRemovedDevices(nodeDeviceResource)
+	for _, v := range removed {
+		klog.Warningf("Device removed with allocation, nodeName:%v, deviceType:%v, minor:%v, pod:%v",
+			nodeName, v.deviceType, v.minor, v.pod)
+	}
 	info.resetDeviceTotal(nodeDeviceResource)
+	info.deviceVFs = nodeDeviceVFs
+	info.gpuVendor = device.Labels[apiext.LabelGPUVendor]
+	info.maxPodsPerGPU = n.defaultMaxPodsPerGPU
+	if device.Spec.MaxPodsPerGPU != nil {
+		info.maxPodsPerGPU = *device.Spec.MaxPodsPerGPU
+	}
+	info.recordMetrics(nodeName)
+	return removed
+}
+
+// getFaultyDevices returns the minors of the devices with the error conditions reported by koordlet,
+// which are regarded as unhealthy.
+func getFaultyDevices(device *schedulingv1alpha1.Device) map[schedulingv1alpha1.DeviceType]sets.Int {
+	var faultyDevices map[schedulingv1alpha1.DeviceType]sets.Int
+	for _, condition := range device.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue || condition.Minor == nil {
+			continue
+		}
+		if faultyDevices == nil {
+			faultyDevices = map[schedulingv1alpha1.DeviceType]sets.Int{}
+		}
+		if faultyDevices[condition.DeviceType] == nil {
+			faultyDevices[condition.DeviceType] = sets.NewInt()
+		}
+		faultyDevices[condition.DeviceType].Insert(int(*condition.Minor))
+	}
+	return faultyDevices
+}
+
+// updateGPUUsage records the physical GPU usage reported in NodeMetric.
+func (n *nodeDeviceCache) updateGPUUsage(nodeMetric *slov1alpha1.NodeMetric) {
+	if nodeMetric == nil {
+		return
+	}
+	var gpuUsage deviceResources
+	if nodeMetric.Status.NodeMetric != nil {
+		for _, deviceInfo := range nodeMetric.Status.NodeMetric.NodeUsage.Devices {
+			if deviceInfo.Type != schedulingv1alpha1.GPU || deviceInfo.Minor == nil {
+				continue
+			}
+			if gpuUsage == nil {
+				gpuUsage = make(deviceResources)
+			}
+			gpuUsage[int(*deviceInfo.Minor)] = deviceInfo.Resources.DeepCopy()
+		}
+	}
+
+	info := n.getNodeDevice(nodeMetric.Name)
+	if info == nil {
+		if len(gpuUsage) == 0 {
+			return
+		}
+		info = n.createNodeDevice(nodeMetric.Name)
+	}
+
+	info.lock.Lock()
+	defer info.lock.Unlock()
+	info.gpuUsage = gpuUsage
+	info.gpuUsageUpdateTime = time.Time{}
+	if nodeMetric.Status.UpdateTime != nil {
+		info.gpuUsageUpdateTime = nodeMetric.Status.UpdateTime.Time
+	}
+}
+
+// updateGPUCoreOversellPercent applies the gpu-core oversell percent declared by the node annotation,
+// and falls back to the default percent if the annotation is missing or invalid.
+func (n *nodeDeviceCache) updateGPUCoreOversellPercent(node *corev1.Node) {
+	if node == nil {
+		return
+	}
+	percent, ok, err := apiext.GetNodeGPUCoreOversellPercent(node.Annotations)
+	if err != nil {
+		klog.Errorf("failed to get gpu-core oversell percent of node %v, err: %v", node.Name, err)
+	}
+	if !ok {
+		percent = n.defaultGPUCoreOversellPercent
+	}
+
+	info := n.getNodeDevice(node.Name)
+	if info == nil {
+		if percent == n.defaultGPUCoreOversellPercent {
+			return
+		}
+		info = n.createNodeDevice(node.Name)
+	}
+
+	info.lock.Lock()
+	defer info.lock.Unlock()
+	if info.gpuCoreOversellPercent == percent {
+		return
+	}
+	info.gpuCoreOversellPercent = percent
+	info.resetDeviceFree(schedulingv1alpha1.GPU)
 }
 
 func (n *nodeDeviceCache) getNodeDeviceSummary(nodeName string) (*NodeDeviceSummary, bool) {
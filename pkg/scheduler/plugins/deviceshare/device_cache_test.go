@@ -17,13 +17,21 @@ limitations under the License.
 package deviceshare
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/utils/pointer"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 )
 
 func Test_newNodeDeviceCache(t *testing.T) {
@@ -42,3 +50,611 @@ func Test_newNodeDevice(t *testing.T) {
 	}
 	assert.Equal(t, expectNodeDevice, newNodeDevice())
 }
+
+func newFakeRDMAVFDevice(nodeName string) *schedulingv1alpha1.Device {
+	var devices []schedulingv1alpha1.DeviceInfo
+	for i := 0; i < 2; i++ {
+		var vfs []schedulingv1alpha1.VirtualFunction
+		for j := 3; j >= 0; j-- {
+			vfs = append(vfs, schedulingv1alpha1.VirtualFunction{
+				Minor: int32(j),
+				BusID: fmt.Sprintf("0000:%d:00.%d", i+1, j),
+			})
+		}
+		devices = append(devices, schedulingv1alpha1.DeviceInfo{
+			Minor:  pointer.Int32(int32(i)),
+			Type:   schedulingv1alpha1.RDMA,
+			Health: true,
+			Resources: v1.ResourceList{
+				apiext.ResourceRDMA: resource.MustParse("100"),
+			},
+			VFs: vfs,
+		})
+	}
+	return &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Spec:       schedulingv1alpha1.DeviceSpec{Devices: devices},
+	}
+}
+
+func Test_nodeDevice_tryAllocateRDMAVFs(t *testing.T) {
+	cache := newNodeDeviceCache()
+	cache.updateNodeDevice("test-node-1", newFakeRDMAVFDevice("test-node-1"))
+	nd := cache.getNodeDevice("test-node-1")
+	vfTotal := nd.deviceTotal[schedulingv1alpha1.RDMA][0][apiext.ResourceRDMAVF]
+	assert.Equal(t, int64(4), vfTotal.Value())
+	assert.Equal(t, int32(0), nd.deviceVFs[schedulingv1alpha1.RDMA][0][0].Minor)
+
+	getVFMinors := func(allocation *apiext.DeviceAllocation) []int32 {
+		extension, err := apiext.GetDeviceAllocationExtension(allocation)
+		assert.NoError(t, err)
+		var minors []int32
+		for _, vf := range extension.VirtualFunctions {
+			minors = append(minors, vf.Minor)
+		}
+		return minors
+	}
+
+	pod1 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}
+	pod1Allocations, err := nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceRDMA:   resource.MustParse("50"),
+		apiext.ResourceRDMAVF: resource.MustParse("3"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), pod1Allocations[schedulingv1alpha1.RDMA][0].Minor)
+	assert.Equal(t, []int32{0, 1, 2}, getVFMinors(pod1Allocations[schedulingv1alpha1.RDMA][0]))
+	nd.updateCacheUsed(pod1Allocations, pod1, true)
+
+	// the remaining VFs of device 0 cannot satisfy the request, so the VFs of device 1 are bound
+	pod2 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2"}}
+	allocations, err := nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceRDMA:   resource.MustParse("50"),
+		apiext.ResourceRDMAVF: resource.MustParse("2"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.RDMA][0].Minor)
+	assert.Equal(t, []int32{0, 1}, getVFMinors(allocations[schedulingv1alpha1.RDMA][0]))
+	nd.updateCacheUsed(allocations, pod2, true)
+
+	// device 0 only has 1 free VF left, so 2 VFs per device cannot be satisfied
+	_, err = nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceRDMA:   resource.MustParse("200"),
+		apiext.ResourceRDMAVF: resource.MustParse("4"),
+	})
+	assert.Error(t, err)
+
+	// the VFs are released after the pods are deleted
+	nd.updateCacheUsed(pod1Allocations, pod1, false)
+	nd.updateCacheUsed(allocations, pod2, false)
+	assert.Nil(t, nd.vfUsed)
+	allocations, err = nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceRDMA:   resource.MustParse("200"),
+		apiext.ResourceRDMAVF: resource.MustParse("4"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, allocations[schedulingv1alpha1.RDMA], 2)
+	assert.Equal(t, []int32{0, 1}, getVFMinors(allocations[schedulingv1alpha1.RDMA][0]))
+	assert.Equal(t, []int32{0, 1}, getVFMinors(allocations[schedulingv1alpha1.RDMA][1]))
+}
+
+func Test_nodeDevice_tryAllocateNIC(t *testing.T) {
+	nd := newNodeDevice()
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.NIC: {
+			0: v1.ResourceList{apiext.ResourceNetBandwidth: resource.MustParse("25G")},
+			1: v1.ResourceList{apiext.ResourceNetBandwidth: resource.MustParse("25G")},
+		},
+	})
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}
+	allocations, err := nd.tryAllocateDevice(v1.ResourceList{apiext.ResourceNetBandwidth: resource.MustParse("20G")})
+	assert.NoError(t, err)
+	expected := apiext.DeviceAllocations{
+		schedulingv1alpha1.NIC: {
+			{
+				Minor:     0,
+				Resources: v1.ResourceList{apiext.ResourceNetBandwidth: resource.MustParse("20G")},
+			},
+		},
+	}
+	assert.Equal(t, expected, allocations)
+	nd.updateCacheUsed(allocations, pod, true)
+
+	// the remaining bandwidth of NIC 0 is not enough
+	allocations, err = nd.tryAllocateDevice(v1.ResourceList{apiext.ResourceNetBandwidth: resource.MustParse("10G")})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.NIC][0].Minor)
+
+	_, err = nd.tryAllocateDevice(v1.ResourceList{apiext.ResourceNetBandwidth: resource.MustParse("30G")})
+	assert.Error(t, err)
+}
+
+func Test_nodeDeviceCache_updateGPUCoreOversellPercent(t *testing.T) {
+	cache := newNodeDeviceCache()
+	cache.defaultGPUCoreOversellPercent = 120
+	cache.updateNodeDevice("test-node", &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: v1.ResourceList{
+						apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+						apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	})
+	nd := cache.getNodeDevice("test-node")
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}
+	allocations, err := nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+	})
+	assert.NoError(t, err)
+	nd.updateCacheUsed(allocations, pod, true)
+
+	// the nodes without the annotation oversell by the default percent
+	assert.Equal(t, int64(120), nd.gpuCoreOversellPercent)
+	free := nd.deviceFree[schedulingv1alpha1.GPU][0]
+	assert.Equal(t, int64(20), free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value())
+	halfGPU := v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+	}
+	_, err = nd.tryAllocateDevice(halfGPU)
+	assert.Error(t, err)
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				apiext.AnnotationNodeGPUCoreOversellPercent: "150",
+			},
+		},
+	}
+	// the annotation overrides the default percent
+	cache.updateGPUCoreOversellPercent(node)
+	assert.Equal(t, int64(150), nd.gpuCoreOversellPercent)
+	free = nd.deviceFree[schedulingv1alpha1.GPU][0]
+	assert.Equal(t, int64(50), free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value())
+	expectedMemory := resource.MustParse("8Gi")
+	assert.Equal(t, expectedMemory.Value(), free.Name(apiext.ResourceGPUMemory, resource.BinarySI).Value())
+	total := nd.deviceTotal[schedulingv1alpha1.GPU][0]
+	assert.Equal(t, int64(100), total.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value())
+
+	allocations, err = nd.tryAllocateDevice(halfGPU)
+	assert.NoError(t, err)
+	nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2"}}, true)
+
+	// gpu-memory is kept strict
+	_, err = nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(1, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(1, resource.DecimalSI),
+	})
+	assert.Error(t, err)
+
+	// fall back to the default percent once the annotation is removed
+	node.Annotations = nil
+	cache.updateGPUCoreOversellPercent(node)
+	assert.Equal(t, int64(120), nd.gpuCoreOversellPercent)
+	free = nd.deviceFree[schedulingv1alpha1.GPU][0]
+	assert.Equal(t, int64(0), free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value())
+}
+
+func Test_nodeDeviceCache_updateMaxPodsPerGPU(t *testing.T) {
+	cache := newNodeDeviceCache()
+	cache.defaultMaxPodsPerGPU = 4
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: v1.ResourceList{
+						apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+						apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	}
+	cache.updateNodeDevice("test-node", device)
+	nd := cache.getNodeDevice("test-node")
+	assert.Equal(t, int32(4), nd.maxPodsPerGPU)
+
+	// the Device overrides the default limit
+	device.Spec.MaxPodsPerGPU = pointer.Int32(1)
+	cache.updateNodeDevice("test-node", device)
+	assert.Equal(t, int32(1), nd.maxPodsPerGPU)
+
+	// fall back to the default limit once the Device does not specify it
+	device.Spec.MaxPodsPerGPU = nil
+	cache.updateNodeDevice("test-node", device)
+	assert.Equal(t, int32(4), nd.maxPodsPerGPU)
+}
+
+func Test_nodeDeviceCache_updateDeviceConditions(t *testing.T) {
+	cache := newNodeDeviceCache()
+	gpuResources := v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+		apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+	}
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{Minor: pointer.Int32(0), Health: true, Type: schedulingv1alpha1.GPU, Resources: gpuResources},
+				{Minor: pointer.Int32(1), Health: true, Type: schedulingv1alpha1.GPU, Resources: gpuResources},
+			},
+		},
+		Status: schedulingv1alpha1.DeviceStatus{
+			Conditions: []schedulingv1alpha1.DeviceCondition{
+				{
+					Type:       schedulingv1alpha1.DeviceConditionXidError,
+					Status:     v1.ConditionTrue,
+					DeviceType: schedulingv1alpha1.GPU,
+					Minor:      pointer.Int32(1),
+					Reason:     "Xid79",
+				},
+				{
+					Type:       schedulingv1alpha1.DeviceConditionLinkFlapping,
+					Status:     v1.ConditionTrue,
+					DeviceType: schedulingv1alpha1.NIC,
+					Name:       "eth0",
+				},
+			},
+		},
+	}
+	cache.updateNodeDevice("test-node", device)
+	nd := cache.getNodeDevice("test-node")
+	assert.True(t, quotav1.Equals(gpuResources, nd.deviceTotal[schedulingv1alpha1.GPU][0]))
+	assert.True(t, quotav1.IsZero(nd.deviceTotal[schedulingv1alpha1.GPU][1]))
+
+	// the GPU is schedulable again once the condition is removed
+	device.Status.Conditions = nil
+	cache.updateNodeDevice("test-node", device)
+	assert.True(t, quotav1.Equals(gpuResources, nd.deviceTotal[schedulingv1alpha1.GPU][1]))
+}
+
+func Test_nodeDevice_tryAllocateGPUByLoad(t *testing.T) {
+	nd := newGPUUsageTestNodeDevice(80, 30, 60)
+	request := v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+	}
+	allocations, err := nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+	nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}, true)
+	allocations, err = nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+	nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-2"}}, true)
+
+	// GPU 1 is fully allocated, so choose the next less-loaded GPU 2
+	allocations, err = nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), allocations[schedulingv1alpha1.GPU][0].Minor)
+
+	// fall back to the order of minor without GPU usage
+	nd.gpuUsage = nil
+	allocations, err = nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), allocations[schedulingv1alpha1.GPU][0].Minor)
+}
+
+func Test_nodeDevice_tryAllocateBatchGPU(t *testing.T) {
+	nd := newGPUUsageTestNodeDevice(80, 30, 60)
+	// the GPUs allocated to the LS pods are still allocatable to the batch pods
+	nd.updateCacheUsed(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 1,
+				Resources: v1.ResourceList{
+					apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+				},
+			},
+		},
+	}, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ls-pod"}}, true)
+	request := v1.ResourceList{
+		apiext.BatchGPUCore:   *resource.NewQuantity(60, resource.DecimalSI),
+		apiext.BatchGPUMemory: resource.MustParse("8Gi"),
+	}
+	allocations, err := nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	expected := []*apiext.DeviceAllocation{
+		{
+			Minor: 1,
+			Resources: v1.ResourceList{
+				apiext.BatchGPUCore:   *resource.NewQuantity(60, resource.DecimalSI),
+				apiext.BatchGPUMemory: *resource.NewQuantity(8*1024*1024*1024, resource.BinarySI),
+			},
+		},
+	}
+	assert.Equal(t, expected, allocations[schedulingv1alpha1.GPU])
+	nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "batch-pod-1"}}, true)
+
+	// the batch GPU of GPU 1 is taken by the other batch pod, so choose the next less-loaded GPU 2
+	allocations, err = nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), allocations[schedulingv1alpha1.GPU][0].Minor)
+
+	// the batch GPU is spread on the GPUs for the multiple GPUs
+	allocations, err = nd.tryAllocateDevice(v1.ResourceList{
+		apiext.BatchGPUCore:   *resource.NewQuantity(200, resource.DecimalSI),
+		apiext.BatchGPUMemory: resource.MustParse("16Gi"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(allocations[schedulingv1alpha1.GPU]))
+	assert.Equal(t, int32(2), allocations[schedulingv1alpha1.GPU][0].Minor)
+	assert.Equal(t, int32(0), allocations[schedulingv1alpha1.GPU][1].Minor)
+
+	_, err = nd.tryAllocateDevice(v1.ResourceList{
+		apiext.BatchGPUCore:   *resource.NewQuantity(300, resource.DecimalSI),
+		apiext.BatchGPUMemory: resource.MustParse("16Gi"),
+	})
+	assert.Error(t, err)
+}
+
+func Test_nodeDevice_updateCacheUsed_batchGPU(t *testing.T) {
+	nd := newGPUUsageTestNodeDevice(30, 80)
+	lsPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ls-pod", UID: "ls-pod"}}
+	nd.updateCacheUsed(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 0,
+				Resources: v1.ResourceList{
+					apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+				},
+			},
+		},
+	}, lsPod, true)
+	expectedFree := nd.deviceFree[schedulingv1alpha1.GPU].DeepCopy()
+	expectedUsed := nd.deviceUsed[schedulingv1alpha1.GPU].DeepCopy()
+
+	batchPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "batch-pod", UID: "batch-pod"}}
+	allocations, err := nd.tryAllocateDevice(v1.ResourceList{
+		apiext.BatchGPUCore:   *resource.NewQuantity(50, resource.DecimalSI),
+		apiext.BatchGPUMemory: resource.MustParse("8Gi"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), allocations[schedulingv1alpha1.GPU][0].Minor)
+	nd.updateCacheUsed(allocations, batchPod, true)
+	// the batch GPU is not taken from the free GPU resources
+	assert.Equal(t, expectedFree, nd.deviceFree[schedulingv1alpha1.GPU])
+	assert.Equal(t, int64(50), nd.deviceUsed[schedulingv1alpha1.GPU][0].Name(apiext.BatchGPUCore, resource.DecimalSI).Value())
+
+	nd.updateCacheUsed(allocations, batchPod, false)
+	assert.Equal(t, expectedFree, nd.deviceFree[schedulingv1alpha1.GPU])
+	assert.Equal(t, expectedUsed, nd.deviceUsed[schedulingv1alpha1.GPU])
+}
+
+func Test_nodeDevice_tryAllocateFractionalGPUs(t *testing.T) {
+	nd := newFragmentationTestNodeDevice(0, 50, 0)
+	nd.minimizeGPUFragmentation = false
+	request := v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(150, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(150, resource.DecimalSI),
+	}
+	allocations, err := nd.tryAllocateDevice(request)
+	assert.NoError(t, err)
+	expected := []*apiext.DeviceAllocation{
+		{
+			Minor: 0,
+			Resources: v1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.ResourceGPUMemory:      *resource.NewQuantity(100*1024*1024*1024, resource.BinarySI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		},
+		{
+			Minor: 1,
+			Resources: v1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+				apiext.ResourceGPUMemory:      *resource.NewQuantity(50*1024*1024*1024, resource.BinarySI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+			},
+		},
+	}
+	assert.Equal(t, expected, allocations[schedulingv1alpha1.GPU])
+	nd.updateCacheUsed(allocations, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"}}, true)
+
+	// only GPU 2 is idle, no GPU left for the partial GPU
+	_, err = nd.tryAllocateDevice(request)
+	assert.Error(t, err)
+
+	// the partial GPU never lands on the allocated full GPU
+	nd = newFragmentationTestNodeDevice(0, 70)
+	nd.minimizeGPUFragmentation = false
+	_, err = nd.tryAllocateDevice(request)
+	assert.Error(t, err)
+
+	// gpu-memory in bytes is taken from each GPU
+	nd = newNodeDevice()
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{
+		schedulingv1alpha1.GPU: {
+			0: {
+				apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.ResourceGPUMemory:      resource.MustParse("40Gi"),
+			},
+			1: {
+				apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.ResourceGPUMemory:      resource.MustParse("80Gi"),
+			},
+		},
+	})
+	allocations, err = nd.tryAllocateDevice(v1.ResourceList{
+		apiext.ResourceGPUCore:   *resource.NewQuantity(150, resource.DecimalSI),
+		apiext.ResourceGPUMemory: resource.MustParse("100Gi"),
+	})
+	assert.NoError(t, err)
+	expected = []*apiext.DeviceAllocation{
+		{
+			Minor: 0,
+			Resources: v1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+				apiext.ResourceGPUMemory:      resource.MustParse("40Gi"),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		},
+		{
+			Minor: 1,
+			Resources: v1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+				apiext.ResourceGPUMemory:      *resource.NewQuantity(60*1024*1024*1024, resource.BinarySI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(75, resource.DecimalSI),
+			},
+		},
+	}
+	assert.Equal(t, expected, allocations[schedulingv1alpha1.GPU])
+}
+
+func Test_nodeDevice_allocatePreviousGPUs(t *testing.T) {
+	cache := newNodeDeviceCache()
+	nd := newGPUUsageTestNodeDevice(80, 30, 60)
+	cache.nodeDeviceInfos["test-node"] = nd
+	newControlledPod := func(name string, controllerUID types.UID) *v1.Pod {
+		controller := true
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: controllerUID, Controller: &controller},
+				},
+			},
+			Spec: v1.PodSpec{NodeName: "test-node"},
+		}
+	}
+	request := v1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+	}
+	allocator := &defaultAllocator{}
+
+	// allocate the next less-loaded GPU 2 after GPU 1 is fully allocated
+	var occupiedPods []*v1.Pod
+	for _, name := range []string{"pod-0", "pod-1"} {
+		occupied := newControlledPod(name, "rs-0")
+		allocations, err := allocator.Allocate("test-node", occupied, request, nd)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+		allocator.Reserve(occupied, nd, allocations)
+		assert.NoError(t, apiext.SetDeviceAllocations(occupied, allocations))
+		occupiedPods = append(occupiedPods, occupied)
+	}
+	pod := newControlledPod("pod-2", "rs-1")
+	allocations, err := allocator.Allocate("test-node", pod, request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), allocations[schedulingv1alpha1.GPU][0].Minor)
+	allocator.Reserve(pod, nd, allocations)
+	assert.NoError(t, apiext.SetDeviceAllocations(pod, allocations))
+
+	// the pods are deleted and the GPUs are released
+	cache.onPodDelete(pod)
+	cache.onPodDelete(occupiedPods[0])
+	assert.Equal(t, []int{2}, nd.getPreviousGPUs(pod).List())
+
+	// the restarted pod of the same controller prefers the GPU 2 rather than the less-loaded GPU 1
+	restarted := newControlledPod("pod-3", "rs-1")
+	allocations, err = allocator.Allocate("test-node", restarted, request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), allocations[schedulingv1alpha1.GPU][0].Minor)
+	assert.True(t, nd.reusePreviousGPUs(restarted, allocations[schedulingv1alpha1.GPU]))
+
+	// the pods of other controllers are not affected
+	other := newControlledPod("pod-4", "rs-2")
+	allocations, err = allocator.Allocate("test-node", other, request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+	assert.False(t, nd.reusePreviousGPUs(other, allocations[schedulingv1alpha1.GPU]))
+
+	// the GPUs released by the pods of the same controller are merged
+	nd.recordPreviousGPUs(newControlledPod("pod-5", "rs-1"), []*apiext.DeviceAllocation{{Minor: 3}})
+	assert.Equal(t, []int{2, 3}, nd.getPreviousGPUs(restarted).List())
+
+	// the previous GPUs expire
+	nd.previousGPUAllocations["rs-1"].releasedTime = time.Now().Add(-previousGPUAllocationExpiration)
+	assert.Nil(t, nd.getPreviousGPUs(restarted))
+	allocations, err = allocator.Allocate("test-node", restarted, request, nd)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), allocations[schedulingv1alpha1.GPU][0].Minor)
+}
+
+func Test_nodeDeviceCache_updateGPUUsage(t *testing.T) {
+	cache := newNodeDeviceCache()
+	updateTime := metav1.Now()
+	cache.updateGPUUsage(&slov1alpha1.NodeMetric{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: slov1alpha1.NodeMetricStatus{
+			UpdateTime: &updateTime,
+			NodeMetric: &slov1alpha1.NodeMetricInfo{
+				NodeUsage: slov1alpha1.ResourceMap{
+					Devices: []schedulingv1alpha1.DeviceInfo{
+						{
+							Type:  schedulingv1alpha1.GPU,
+							Minor: pointer.Int32(1),
+							Resources: v1.ResourceList{
+								apiext.ResourceGPUCore:        *resource.NewQuantity(20, resource.DecimalSI),
+								apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(40, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	nd := cache.getNodeDevice("test-node")
+	assert.NotNil(t, nd)
+	assert.Equal(t, map[int]int64{1: 40}, nd.getGPULoads())
+
+	// nodes without GPU usage are ignored
+	cache.updateGPUUsage(&slov1alpha1.NodeMetric{ObjectMeta: metav1.ObjectMeta{Name: "other-node"}})
+	assert.Nil(t, cache.getNodeDevice("other-node"))
+}
+
+func Test_nodeDevice_tryAllocateDevice_gpuVendor(t *testing.T) {
+	nd := newFragmentationTestNodeDevice(0, 0)
+	nd.gpuVendor = apiext.GPUVendorAMD
+	allocations, err := nd.tryAllocateDevice(gpuResources(200, 200))
+	assert.NoError(t, err)
+	assert.Len(t, allocations[schedulingv1alpha1.GPU], 2)
+	for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+		extension, err := apiext.GetDeviceAllocationExtension(allocation)
+		assert.NoError(t, err)
+		assert.Equal(t, &apiext.DeviceAllocationExtension{GPUVendor: apiext.GPUVendorAMD}, extension)
+	}
+}
+
+func Test_nodeDevice_setGPUVendor_mergeExtension(t *testing.T) {
+	nd := newFragmentationTestNodeDevice(0, 0)
+	nd.gpuVendor = apiext.GPUVendorAMD
+	allocation := &apiext.DeviceAllocation{
+		Minor:     0,
+		Extension: []byte(`{"vfs":[{"minor":1,"busID":"0000:1f:00.1"}]}`),
+	}
+	assert.NoError(t, nd.setGPUVendor([]*apiext.DeviceAllocation{allocation}))
+	extension, err := apiext.GetDeviceAllocationExtension(allocation)
+	assert.NoError(t, err)
+	expected := &apiext.DeviceAllocationExtension{
+		VirtualFunctions: []schedulingv1alpha1.VirtualFunction{{Minor: 1, BusID: "0000:1f:00.1"}},
+		GPUVendor:        apiext.GPUVendorAMD,
+	}
+	assert.Equal(t, expected, extension)
+}
@@ -18,8 +18,14 @@ package deviceshare
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
@@ -76,3 +82,30 @@ func (n *nodeDeviceCache) onDeviceDelete(obj interface{}) {
 	n.removeNodeDevice(device.Name)
 	klog.V(4).InfoS("device cache deleted", "Device", klog.KObj(device))
 }
+
+// newDevicesRemovedEventRecorder returns a callback which emits a warning event for each pod whose
+// allocated devices are removed from the node, e.g. the GPU is removed for RMA.
+func newDevicesRemovedEventRecorder(podLister corelisters.PodLister, recorder events.EventRecorder) func(string, []removedDeviceAllocation) {
+	return func(nodeName string, removed []removedDeviceAllocation) {
+		if recorder == nil {
+			return
+		}
+		var podNames []types.NamespacedName
+		removedDevices := map[types.NamespacedName][]string{}
+		for _, v := range removed {
+			if _, ok := removedDevices[v.pod]; !ok {
+				podNames = append(podNames, v.pod)
+			}
+			removedDevices[v.pod] = append(removedDevices[v.pod], fmt.Sprintf("%s-%d", v.deviceType, v.minor))
+		}
+		for _, podName := range podNames {
+			pod, err := podLister.Pods(podName.Namespace).Get(podName.Name)
+			if err != nil {
+				klog.V(4).InfoS("skip recording event of removed devices", "pod", podName, "err", err)
+				continue
+			}
+			recorder.Eventf(pod, nil, corev1.EventTypeWarning, "DeviceRemoved", "Scheduling",
+				"allocated devices %s are removed from node %s", strings.Join(removedDevices[podName], ","), nodeName)
+		}
+	}
+}
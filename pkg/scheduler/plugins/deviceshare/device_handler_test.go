@@ -25,7 +25,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/utils/pointer"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -607,3 +610,118 @@ func generateFakeNodeDeviceInfos() map[string]*nodeDevice {
 		},
 	}
 }
+
+func Test_nodeDeviceCache_onDeviceUpdateRemoveDevices(t *testing.T) {
+	newDevice := func(minors ...int32) *schedulingv1alpha1.Device {
+		device := &schedulingv1alpha1.Device{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+		}
+		for _, minor := range minors {
+			device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+				Minor:  pointer.Int32Ptr(minor),
+				Health: true,
+				Type:   schedulingv1alpha1.GPU,
+				Resources: corev1.ResourceList{
+					apiext.ResourceGPUCore:        resource.MustParse("100"),
+					apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+					apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+				},
+			})
+		}
+		return device
+	}
+	newPod := func(name string, minor int32) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       uuid.NewUUID(),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "test-node-1",
+			},
+		}
+		err := apiext.SetDeviceAllocations(pod, apiext.DeviceAllocations{
+			schedulingv1alpha1.GPU: {
+				{
+					Minor: minor,
+					Resources: corev1.ResourceList{
+						apiext.ResourceGPUCore:        resource.MustParse("100"),
+						apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+						apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+					},
+				},
+			},
+		})
+		assert.NoError(t, err)
+		return pod
+	}
+
+	deviceCache := newNodeDeviceCache()
+	var gotRemoved []removedDeviceAllocation
+	deviceCache.onDevicesRemoved = func(nodeName string, removed []removedDeviceAllocation) {
+		assert.Equal(t, "test-node-1", nodeName)
+		gotRemoved = append(gotRemoved, removed...)
+	}
+	deviceCache.onDeviceAdd(newDevice(0, 1))
+	podA := newPod("pod-a", 0)
+	podB := newPod("pod-b", 1)
+	deviceCache.onPodAdd(podA)
+	deviceCache.onPodAdd(podB)
+
+	// GPU 1 is removed for RMA and GPU 2 is hot-added
+	deviceCache.onDeviceUpdate(newDevice(0, 1), newDevice(0, 2))
+	assert.Equal(t, []removedDeviceAllocation{
+		{
+			pod:        types.NamespacedName{Namespace: "default", Name: "pod-b"},
+			deviceType: schedulingv1alpha1.GPU,
+			minor:      1,
+		},
+	}, gotRemoved)
+
+	info := deviceCache.getNodeDevice("test-node-1")
+	assert.NotNil(t, info)
+	assert.Len(t, info.deviceUsed[schedulingv1alpha1.GPU], 1)
+	assert.NotNil(t, info.deviceUsed[schedulingv1alpha1.GPU][0])
+	assert.Len(t, info.deviceFree[schedulingv1alpha1.GPU], 2)
+	assert.NotNil(t, info.deviceFree[schedulingv1alpha1.GPU][2])
+	assert.Empty(t, info.allocateSet[schedulingv1alpha1.GPU][types.NamespacedName{Namespace: "default", Name: "pod-b"}])
+
+	// GPU 1 comes back and is allocated to another pod,
+	// deleting the pod whose allocation was invalidated should not release it.
+	gotRemoved = nil
+	deviceCache.onDeviceUpdate(newDevice(0, 2), newDevice(0, 1, 2))
+	assert.Empty(t, gotRemoved)
+	podC := newPod("pod-c", 1)
+	deviceCache.onPodAdd(podC)
+	deviceCache.onPodDelete(podB)
+	expectUsed := corev1.ResourceList{
+		apiext.ResourceGPUCore:        resource.MustParse("100"),
+		apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+		apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+	}
+	assert.True(t, quotav1.Equals(expectUsed, info.deviceUsed[schedulingv1alpha1.GPU][1]))
+	assert.True(t, quotav1.IsZero(info.deviceFree[schedulingv1alpha1.GPU][1]))
+}
+
+func Test_newDevicesRemovedEventRecorder(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod-a",
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(pod))
+	recorder := events.NewFakeRecorder(10)
+	record := newDevicesRemovedEventRecorder(corelisters.NewPodLister(indexer), recorder)
+	record("test-node-1", []removedDeviceAllocation{
+		{pod: types.NamespacedName{Namespace: "default", Name: "pod-a"}, deviceType: schedulingv1alpha1.GPU, minor: 1},
+		{pod: types.NamespacedName{Namespace: "default", Name: "pod-a"}, deviceType: schedulingv1alpha1.RDMA, minor: 2},
+		{pod: types.NamespacedName{Namespace: "default", Name: "pod-not-found"}, deviceType: schedulingv1alpha1.GPU, minor: 3},
+	})
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning DeviceRemoved allocated devices gpu-1,rdma-2 are removed from node test-node-1", <-recorder.Events)
+}
@@ -0,0 +1,375 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+)
+
+// The DRA (Dynamic Resource Allocation) API is not available in the vendored k8s.io/api,
+// so the ResourceClaims and ResourceClasses are accessed through the dynamic client.
+var (
+	draGroupVersion  = schema.GroupVersion{Group: "resource.k8s.io", Version: "v1alpha1"}
+	resourceClaimGVR = draGroupVersion.WithResource("resourceclaims")
+	resourceClassGVR = draGroupVersion.WithResource("resourceclasses")
+)
+
+const (
+	draPodUIDIndex = "draPodUID"
+
+	draStateKey = Name + "/dra"
+)
+
+// resourceClaim mirrors the fields of resource.k8s.io/v1alpha1 ResourceClaim used by the plugin.
+type resourceClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   resourceClaimSpec   `json:"spec"`
+	Status resourceClaimStatus `json:"status,omitempty"`
+}
+
+type resourceClaimSpec struct {
+	ResourceClassName string `json:"resourceClassName"`
+}
+
+type resourceClaimStatus struct {
+	DriverName            string                           `json:"driverName,omitempty"`
+	Allocation            *allocationResult                `json:"allocation,omitempty"`
+	ReservedFor           []resourceClaimConsumerReference `json:"reservedFor,omitempty"`
+	DeallocationRequested bool                             `json:"deallocationRequested,omitempty"`
+}
+
+type allocationResult struct {
+	ResourceHandle   string               `json:"resourceHandle,omitempty"`
+	AvailableOnNodes *corev1.NodeSelector `json:"availableOnNodes,omitempty"`
+	Shareable        bool                 `json:"shareable,omitempty"`
+}
+
+type resourceClaimConsumerReference struct {
+	APIGroup string    `json:"apiGroup,omitempty"`
+	Resource string    `json:"resource"`
+	Name     string    `json:"name"`
+	UID      types.UID `json:"uid"`
+}
+
+// resourceClass mirrors the fields of resource.k8s.io/v1alpha1 ResourceClass used by the plugin.
+type resourceClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	DriverName string `json:"driverName"`
+}
+
+// draClaim is a ResourceClaim of the pod translated into koordinator device resources.
+type draClaim struct {
+	Namespace  string
+	Name       string
+	DriverName string
+}
+
+// draClaimTranslator translates the DRA ResourceClaims of a pod into koordinator device requests,
+// and writes the koordinator device allocations back to the claims, so that users can migrate
+// to DRA gradually. Only the claims owned by the pod (generated from the ResourceClaimTemplates)
+// or reserved for the pod are considered, since the vendored Pod API does not contain spec.resourceClaims.
+type draClaimTranslator struct {
+	client        dynamic.Interface
+	claimInformer cache.SharedIndexInformer
+	classLister   cache.GenericLister
+}
+
+func newDRAClaimTranslator(client dynamic.Interface, factory dynamicinformer.DynamicSharedInformerFactory) (*draClaimTranslator, error) {
+	claimInformer := factory.ForResource(resourceClaimGVR).Informer()
+	if err := claimInformer.AddIndexers(cache.Indexers{draPodUIDIndex: indexResourceClaimByPodUID}); err != nil {
+		return nil, err
+	}
+	return &draClaimTranslator{
+		client:        client,
+		claimInformer: claimInformer,
+		classLister:   factory.ForResource(resourceClassGVR).Lister(),
+	}, nil
+}
+
+func newDRAClaimTranslatorFromHandle(handle framework.Handle) (*draClaimTranslator, error) {
+	if _, err := handle.ClientSet().Discovery().ServerResourcesForGroupVersion(draGroupVersion.String()); err != nil {
+		return nil, fmt.Errorf("DRA compatibility requires %s, err: %w", draGroupVersion.String(), err)
+	}
+	client, err := dynamic.NewForConfig(handle.KubeConfig())
+	if err != nil {
+		return nil, err
+	}
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	translator, err := newDRAClaimTranslator(client, factory)
+	if err != nil {
+		return nil, err
+	}
+	factory.Start(wait.NeverStop)
+	factory.WaitForCacheSync(wait.NeverStop)
+	return translator, nil
+}
+
+func indexResourceClaimByPodUID(obj interface{}) ([]string, error) {
+	claim, err := toResourceClaim(obj)
+	if err != nil {
+		return nil, nil
+	}
+	var podUIDs []string
+	for _, owner := range claim.OwnerReferences {
+		if owner.APIVersion == "v1" && owner.Kind == "Pod" {
+			podUIDs = append(podUIDs, string(owner.UID))
+		}
+	}
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.APIGroup == "" && consumer.Resource == "pods" {
+			podUIDs = append(podUIDs, string(consumer.UID))
+		}
+	}
+	return podUIDs, nil
+}
+
+func toResourceClaim(obj interface{}) (*resourceClaim, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	claim := &resourceClaim{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), claim); err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+// translate returns the device resources requested by the DRA ResourceClaims of the pod.
+// The claims whose ResourceClass is not annotated with scheduling.koordinator.sh/dra-device-resources
+// belong to other drivers and are ignored.
+func (t *draClaimTranslator) translate(pod *corev1.Pod) (corev1.ResourceList, []draClaim, error) {
+	objs, err := t.claimInformer.GetIndexer().ByIndex(draPodUIDIndex, string(pod.UID))
+	if err != nil {
+		return nil, nil, err
+	}
+	var requests corev1.ResourceList
+	var claims []draClaim
+	for _, obj := range objs {
+		claim, err := toResourceClaim(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		if claim.DeletionTimestamp != nil || claim.Status.DeallocationRequested {
+			continue
+		}
+		classObj, err := t.classLister.Get(claim.Spec.ResourceClassName)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil, fmt.Errorf("resourceClass %s of resourceClaim %s/%s not found", claim.Spec.ResourceClassName, claim.Namespace, claim.Name)
+			}
+			return nil, nil, err
+		}
+		class := &resourceClass{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(classObj.(*unstructured.Unstructured).UnstructuredContent(), class); err != nil {
+			return nil, nil, err
+		}
+		resources, err := apiext.GetDRADeviceResources(claim.Annotations)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid device resources of resourceClaim %s/%s, err: %w", claim.Namespace, claim.Name, err)
+		}
+		if resources == nil {
+			resources, err = apiext.GetDRADeviceResources(class.Annotations)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid device resources of resourceClass %s, err: %w", class.Name, err)
+			}
+		}
+		if resources == nil {
+			continue
+		}
+		requests = quotav1.Add(requests, resources)
+		claims = append(claims, draClaim{
+			Namespace:  claim.Namespace,
+			Name:       claim.Name,
+			DriverName: class.DriverName,
+		})
+	}
+	return requests, claims, nil
+}
+
+// allocate marks the claims allocated on the node and reserved for the pod.
+// The resource handle of each claim is the device allocations of the pod.
+func (t *draClaimTranslator) allocate(ctx context.Context, pod *corev1.Pod, nodeName string, claims []draClaim, allocations apiext.DeviceAllocations) error {
+	resourceHandle, err := json.Marshal(allocations)
+	if err != nil {
+		return err
+	}
+	for _, c := range claims {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			obj, err := t.client.Resource(resourceClaimGVR).Namespace(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			claim, err := toResourceClaim(obj)
+			if err != nil {
+				return err
+			}
+			claim.Status.DriverName = c.DriverName
+			claim.Status.Allocation = &allocationResult{
+				ResourceHandle: string(resourceHandle),
+				AvailableOnNodes: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{
+							MatchFields: []corev1.NodeSelectorRequirement{
+								{
+									Key:      "metadata.name",
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{nodeName},
+								},
+							},
+						},
+					},
+				},
+			}
+			if !isReservedForPod(claim, pod) {
+				claim.Status.ReservedFor = append(claim.Status.ReservedFor, resourceClaimConsumerReference{
+					Resource: "pods",
+					Name:     pod.Name,
+					UID:      pod.UID,
+				})
+			}
+			return t.updateClaimStatus(ctx, obj, claim)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to allocate resourceClaim %s/%s, err: %w", c.Namespace, c.Name, err)
+		}
+		klog.V(4).Infof("allocate resourceClaim %s/%s for pod %s on node %s", c.Namespace, c.Name, klog.KObj(pod), nodeName)
+	}
+	return nil
+}
+
+// deallocate releases the claims allocated for the pod which fails to bind. The pod is removed from the consumers
+// of each claim, and the allocation is cleared once the claim is not reserved for any pod.
+func (t *draClaimTranslator) deallocate(ctx context.Context, pod *corev1.Pod, claims []draClaim) {
+	for _, c := range claims {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			obj, err := t.client.Resource(resourceClaimGVR).Namespace(c.Namespace).Get(ctx, c.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			claim, err := toResourceClaim(obj)
+			if err != nil {
+				return err
+			}
+			if !isReservedForPod(claim, pod) {
+				return nil
+			}
+			var reservedFor []resourceClaimConsumerReference
+			for _, consumer := range claim.Status.ReservedFor {
+				if consumer.APIGroup == "" && consumer.Resource == "pods" && consumer.UID == pod.UID {
+					continue
+				}
+				reservedFor = append(reservedFor, consumer)
+			}
+			claim.Status.ReservedFor = reservedFor
+			if len(reservedFor) == 0 {
+				claim.Status.Allocation = nil
+			}
+			return t.updateClaimStatus(ctx, obj, claim)
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			klog.Warningf("failed to deallocate resourceClaim %s/%s for pod %s, err: %v", c.Namespace, c.Name, klog.KObj(pod), err)
+			continue
+		}
+		klog.V(4).Infof("deallocate resourceClaim %s/%s for pod %s", c.Namespace, c.Name, klog.KObj(pod))
+	}
+}
+
+func (t *draClaimTranslator) updateClaimStatus(ctx context.Context, obj *unstructured.Unstructured, claim *resourceClaim) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&claim.Status)
+	if err != nil {
+		return err
+	}
+	if err = unstructured.SetNestedField(obj.Object, status, "status"); err != nil {
+		return err
+	}
+	_, err = t.client.Resource(resourceClaimGVR).Namespace(claim.Namespace).UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+	return err
+}
+
+func isReservedForPod(claim *resourceClaim, pod *corev1.Pod) bool {
+	for _, consumer := range claim.Status.ReservedFor {
+		if consumer.APIGroup == "" && consumer.Resource == "pods" && consumer.UID == pod.UID {
+			return true
+		}
+	}
+	return false
+}
+
+var _ frameworkext.PreFilterTransformer = &Plugin{}
+
+// draState is the device resources translated from the DRA ResourceClaims of the pod in BeforePreFilter.
+type draState struct {
+	requests corev1.ResourceList
+	claims   []draClaim
+}
+
+func (s *draState) Clone() framework.StateData {
+	return s
+}
+
+func getDRAState(cycleState *framework.CycleState) *draState {
+	value, err := cycleState.Read(draStateKey)
+	if err != nil {
+		return nil
+	}
+	state, _ := value.(*draState)
+	return state
+}
+
+// BeforePreFilter adds the device resources translated from the DRA ResourceClaims to the requests of the pod,
+// so that they are accounted by the other plugins as well, e.g. NodeResourcesFit and ElasticQuota.
+func (p *Plugin) BeforePreFilter(handle frameworkext.ExtendedHandle, cycleState *framework.CycleState, pod *corev1.Pod) (*corev1.Pod, bool) {
+	if p.draTranslator == nil || len(pod.Spec.Containers) == 0 {
+		return nil, false
+	}
+	requests, claims, err := p.draTranslator.translate(pod)
+	if err != nil || len(claims) == 0 {
+		// the error is returned by PreFilter
+		return nil, false
+	}
+	cycleState.Write(draStateKey, &draState{requests: requests, claims: claims})
+	newPod := pod.DeepCopy()
+	container := &newPod.Spec.Containers[0]
+	container.Resources.Requests = quotav1.Add(container.Resources.Requests, requests)
+	return newPod, true
+}
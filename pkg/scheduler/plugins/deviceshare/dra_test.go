@@ -0,0 +1,442 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func newTestResourceClass(name, driverName string, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(draGroupVersion.String())
+	obj.SetKind("ResourceClass")
+	obj.SetName(name)
+	obj.SetAnnotations(annotations)
+	obj.Object["driverName"] = driverName
+	return obj
+}
+
+func newTestResourceClaim(namespace, name, className string, owner *corev1.Pod, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(draGroupVersion.String())
+	obj.SetKind("ResourceClaim")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetAnnotations(annotations)
+	if owner != nil {
+		obj.SetOwnerReferences([]metav1.OwnerReference{
+			{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       owner.Name,
+				UID:        owner.UID,
+			},
+		})
+	}
+	obj.Object["spec"] = map[string]interface{}{
+		"resourceClassName": className,
+	}
+	return obj
+}
+
+func newTestDRAClaimTranslator(t *testing.T, objs ...runtime.Object) *draClaimTranslator {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resourceClaimGVR: "ResourceClaimList",
+		resourceClassGVR: "ResourceClassList",
+	}, objs...)
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	translator, err := newDRAClaimTranslator(client, factory)
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	factory.Start(stopCh)
+	factory.WaitForCacheSync(stopCh)
+	return translator
+}
+
+func Test_draClaimTranslator_translate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+	}
+	otherPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "other-pod",
+			UID:       "other-pod-uid",
+		},
+	}
+	gpuClass := newTestResourceClass("koord-gpu-half", "gpu.koordinator.sh", map[string]string{
+		apiext.AnnotationDRADeviceResources: `{"koordinator.sh/gpu-core":"50","koordinator.sh/gpu-memory-ratio":"50"}`,
+	})
+	otherClass := newTestResourceClass("other-driver", "other.example.com", nil)
+
+	tests := []struct {
+		name       string
+		objs       []runtime.Object
+		wantReq    corev1.ResourceList
+		wantClaims []draClaim
+		wantErr    bool
+	}{
+		{
+			name:       "no claims",
+			objs:       []runtime.Object{gpuClass},
+			wantReq:    nil,
+			wantClaims: nil,
+		},
+		{
+			name: "translate claim by class",
+			objs: []runtime.Object{
+				gpuClass,
+				newTestResourceClaim("default", "test-pod-gpu", "koord-gpu-half", pod, nil),
+				newTestResourceClaim("default", "other-pod-gpu", "koord-gpu-half", otherPod, nil),
+			},
+			wantReq: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("50"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("50"),
+			},
+			wantClaims: []draClaim{
+				{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"},
+			},
+		},
+		{
+			name: "claim overrides class and other drivers are ignored",
+			objs: []runtime.Object{
+				gpuClass,
+				otherClass,
+				newTestResourceClaim("default", "test-pod-gpu", "koord-gpu-half", pod, map[string]string{
+					apiext.AnnotationDRADeviceResources: `{"koordinator.sh/gpu":"100"}`,
+				}),
+				newTestResourceClaim("default", "test-pod-other", "other-driver", pod, nil),
+			},
+			wantReq: corev1.ResourceList{
+				apiext.ResourceGPU: resource.MustParse("100"),
+			},
+			wantClaims: []draClaim{
+				{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"},
+			},
+		},
+		{
+			name: "missing class",
+			objs: []runtime.Object{
+				newTestResourceClaim("default", "test-pod-gpu", "koord-gpu-half", pod, nil),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := newTestDRAClaimTranslator(t, tt.objs...)
+			gotReq, gotClaims, err := translator.translate(pod)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, equalResourceList(tt.wantReq, gotReq), "want %v, got %v", tt.wantReq, gotReq)
+			assert.Equal(t, tt.wantClaims, gotClaims)
+		})
+	}
+}
+
+func equalResourceList(a, b corev1.ResourceList) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, qa := range a {
+		qb, ok := b[name]
+		if !ok || qa.Cmp(qb) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func Test_draClaimTranslator_allocate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+	}
+	translator := newTestDRAClaimTranslator(t,
+		newTestResourceClass("koord-gpu", "gpu.koordinator.sh", nil),
+		newTestResourceClaim("default", "test-pod-gpu", "koord-gpu", pod, nil),
+	)
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 1,
+				Resources: corev1.ResourceList{
+					apiext.ResourceGPUCore: resource.MustParse("100"),
+				},
+			},
+		},
+	}
+	claims := []draClaim{{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"}}
+	assert.NoError(t, translator.allocate(context.TODO(), pod, "test-node", claims, allocations))
+	// allocate again should not reserve the claim twice
+	assert.NoError(t, translator.allocate(context.TODO(), pod, "test-node", claims, allocations))
+
+	obj, err := translator.client.Resource(resourceClaimGVR).Namespace("default").Get(context.TODO(), "test-pod-gpu", metav1.GetOptions{})
+	assert.NoError(t, err)
+	claim, err := toResourceClaim(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "koord-gpu", claim.Spec.ResourceClassName)
+	assert.Equal(t, "gpu.koordinator.sh", claim.Status.DriverName)
+	assert.NotNil(t, claim.Status.Allocation)
+	assert.Equal(t, []string{"test-node"}, claim.Status.Allocation.AvailableOnNodes.NodeSelectorTerms[0].MatchFields[0].Values)
+	gotAllocations, err := apiext.GetDeviceAllocations(map[string]string{apiext.AnnotationDeviceAllocated: claim.Status.Allocation.ResourceHandle})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), gotAllocations[schedulingv1alpha1.GPU][0].Minor)
+	assert.Equal(t, []resourceClaimConsumerReference{{Resource: "pods", Name: "test-pod", UID: "test-pod-uid"}}, claim.Status.ReservedFor)
+}
+
+func Test_draClaimTranslator_deallocate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+	}
+	otherPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "other-pod",
+			UID:       "other-pod-uid",
+		},
+	}
+	translator := newTestDRAClaimTranslator(t,
+		newTestResourceClass("koord-gpu", "gpu.koordinator.sh", nil),
+		newTestResourceClaim("default", "test-pod-gpu", "koord-gpu", pod, nil),
+		newTestResourceClaim("default", "shared-gpu", "koord-gpu", pod, nil),
+	)
+	getClaim := func(name string) *resourceClaim {
+		obj, err := translator.client.Resource(resourceClaimGVR).Namespace("default").Get(context.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		claim, err := toResourceClaim(obj)
+		assert.NoError(t, err)
+		return claim
+	}
+	claims := []draClaim{
+		{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"},
+		{Namespace: "default", Name: "shared-gpu", DriverName: "gpu.koordinator.sh"},
+		{Namespace: "default", Name: "deleted-gpu", DriverName: "gpu.koordinator.sh"},
+	}
+	assert.NoError(t, translator.allocate(context.TODO(), pod, "test-node", claims[:2], apiext.DeviceAllocations{}))
+	assert.NoError(t, translator.allocate(context.TODO(), otherPod, "test-node", claims[1:2], apiext.DeviceAllocations{}))
+
+	translator.deallocate(context.TODO(), pod, claims)
+	claim := getClaim("test-pod-gpu")
+	assert.Nil(t, claim.Status.Allocation)
+	assert.Empty(t, claim.Status.ReservedFor)
+	// the claim shared with the other pod is kept allocated
+	claim = getClaim("shared-gpu")
+	assert.NotNil(t, claim.Status.Allocation)
+	assert.Equal(t, []resourceClaimConsumerReference{{Resource: "pods", Name: "other-pod", UID: "other-pod-uid"}}, claim.Status.ReservedFor)
+}
+
+func Test_Plugin_BeforePreFilterWithDRAClaims(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+			},
+		},
+	}
+	translator := newTestDRAClaimTranslator(t,
+		newTestResourceClass("koord-gpu", "gpu.koordinator.sh", map[string]string{
+			apiext.AnnotationDRADeviceResources: `{"koordinator.sh/gpu":"100"}`,
+		}),
+		newTestResourceClaim("default", "test-pod-gpu", "koord-gpu", pod, nil),
+	)
+	p := &Plugin{draTranslator: translator}
+	cycleState := framework.NewCycleState()
+	transformedPod, transformed := p.BeforePreFilter(nil, cycleState, pod)
+	assert.True(t, transformed)
+	// the translated resources are accounted by the other plugins with the transformed pod
+	expectedRequests := corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+		apiext.ResourceGPU: resource.MustParse("100"),
+	}
+	assert.True(t, equalResourceList(expectedRequests, transformedPod.Spec.Containers[0].Resources.Requests))
+	assert.Len(t, pod.Spec.Containers[0].Resources.Requests, 1)
+
+	// the translated resources are not counted twice
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, transformedPod).IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, []draClaim{{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"}}, state.draClaims)
+	expectedResource := corev1.ResourceList{
+		apiext.ResourceGPUCore:        resource.MustParse("100"),
+		apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+	}
+	assert.True(t, equalResourceList(expectedResource, state.convertedDeviceResource), "got %v", state.convertedDeviceResource)
+}
+
+func Test_Plugin_PreFilterWithDRAClaims(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+	}
+	translator := newTestDRAClaimTranslator(t,
+		newTestResourceClass("koord-gpu", "gpu.koordinator.sh", map[string]string{
+			apiext.AnnotationDRADeviceResources: `{"koordinator.sh/gpu":"100"}`,
+		}),
+		newTestResourceClaim("default", "test-pod-gpu", "koord-gpu", pod, nil),
+	)
+	p := &Plugin{draTranslator: translator}
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	state, status := getPreFilterState(cycleState)
+	assert.True(t, status.IsSuccess())
+	assert.False(t, state.skip)
+	assert.Equal(t, []draClaim{{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"}}, state.draClaims)
+	expectedResource := corev1.ResourceList{
+		apiext.ResourceGPUCore:        resource.MustParse("100"),
+		apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+	}
+	assert.True(t, equalResourceList(expectedResource, state.convertedDeviceResource), "got %v", state.convertedDeviceResource)
+}
+
+func Test_Plugin_PreBindWithDRAClaims(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			UID:       "test-pod-uid",
+		},
+	}
+	state := &preFilterState{
+		allocationResult: apiext.DeviceAllocations{
+			schedulingv1alpha1.GPU: {
+				{
+					Minor: 1,
+					Resources: corev1.ResourceList{
+						apiext.ResourceGPUCore: resource.MustParse("100"),
+					},
+				},
+			},
+		},
+		draRequest: corev1.ResourceList{
+			apiext.ResourceGPUCore: resource.MustParse("100"),
+		},
+		draClaims: []draClaim{{Namespace: "default", Name: "test-pod-gpu", DriverName: "gpu.koordinator.sh"}},
+	}
+	tests := []struct {
+		name        string
+		objs        []runtime.Object
+		podNotFound bool
+		wantErr     bool
+	}{
+		{
+			name: "allocate the claims and record the devices",
+			objs: []runtime.Object{
+				newTestResourceClass("koord-gpu", "gpu.koordinator.sh", nil),
+				newTestResourceClaim("default", "test-pod-gpu", "koord-gpu", pod, nil),
+			},
+		},
+		{
+			name:    "the devices are not recorded if the claims failed to allocate",
+			wantErr: true,
+		},
+		{
+			name: "the claims are released if the pod failed to update",
+			objs: []runtime.Object{
+				newTestResourceClass("koord-gpu", "gpu.koordinator.sh", nil),
+				newTestResourceClaim("default", "test-pod-gpu", "koord-gpu", pod, nil),
+			},
+			podNotFound: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suit := newPluginTestSuit(t, nil)
+			if !tt.podNotFound {
+				_, err := suit.ClientSet().CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			pl, err := suit.proxyNew(&config.DeviceShareArgs{}, suit.Framework)
+			assert.NoError(t, err)
+			p := pl.(*Plugin)
+			p.draTranslator = newTestDRAClaimTranslator(t, tt.objs...)
+
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, state.Clone())
+			status := p.PreBind(context.TODO(), cycleState, pod, "test-node")
+			assert.Equal(t, tt.wantErr, !status.IsSuccess(), status.Message())
+			if tt.podNotFound {
+				p.Unreserve(context.TODO(), cycleState, pod, "test-node")
+				obj, err := p.draTranslator.client.Resource(resourceClaimGVR).Namespace("default").Get(context.TODO(), "test-pod-gpu", metav1.GetOptions{})
+				assert.NoError(t, err)
+				claim, err := toResourceClaim(obj)
+				assert.NoError(t, err)
+				assert.Nil(t, claim.Status.Allocation)
+				assert.Empty(t, claim.Status.ReservedFor)
+				return
+			}
+
+			got, err := suit.ClientSet().CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			gotAllocations, err := apiext.GetDeviceAllocations(got.Annotations)
+			assert.NoError(t, err)
+			if tt.wantErr {
+				assert.Nil(t, gotAllocations)
+				return
+			}
+			assert.Equal(t, int32(1), gotAllocations[schedulingv1alpha1.GPU][0].Minor)
+			// the device resources of the claims are recorded to be charged to the quota
+			gotResources, err := apiext.GetDRADeviceResources(got.Annotations)
+			assert.NoError(t, err)
+			assert.True(t, equalResourceList(state.draRequest, gotResources))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// gpuFragmentation returns the fragments of a GPU in percentage of the GPU, which are the free resources unable
+// to hold another request of the same shape as requestPerCard. The free resources of an idle GPU or a GPU able
+// to hold the request are not fragments.
+func gpuFragmentation(free, used, requestPerCard corev1.ResourceList) int64 {
+	if isIdleGPU(used) {
+		return 0
+	}
+	if fit, _ := quotav1.LessThanOrEqual(requestPerCard, free); fit {
+		return 0
+	}
+
+	freeCore := free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value()
+	usedCore := used.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value()
+	var fragment int64
+	if capacity := freeCore + usedCore; capacity > 0 {
+		fragment = freeCore * 100 / capacity
+	}
+	if freeMemoryRatio := free.Name(apiext.ResourceGPUMemoryRatio, resource.DecimalSI).Value(); freeMemoryRatio > fragment {
+		fragment = freeMemoryRatio
+	}
+	return fragment
+}
+
+func isIdleGPU(used corev1.ResourceList) bool {
+	return used.Name(apiext.ResourceGPUCore, resource.DecimalSI).IsZero() &&
+		used.Name(apiext.ResourceGPUMemoryRatio, resource.DecimalSI).IsZero()
+}
+
+// isGPUCompactionPod returns true if the pod is migrated to compact the fragmented GPUs.
+func isGPUCompactionPod(pod *corev1.Pod) bool {
+	return pod.Annotations[apiext.AnnotationGPUCompaction] == "true"
+}
+
+// getGPUCompactionSourceMinor returns the GPU of the node released by the compaction pod.
+func getGPUCompactionSourceMinor(pod *corev1.Pod, nodeName string) (int, bool) {
+	source, err := apiext.GetGPUCompactionSource(pod.Annotations)
+	if err != nil {
+		klog.V(4).InfoS("Failed to get the GPU compaction source", "pod", klog.KObj(pod), "err", err)
+		return 0, false
+	}
+	if source == nil || source.NodeName != nodeName {
+		return 0, false
+	}
+	return int(source.Minor), true
+}
+
+// getFragmentedGPUs returns the GPUs partially allocated, which still have free resources.
+func (n *nodeDevice) getFragmentedGPUs() sets.Int {
+	fragmented := sets.NewInt()
+	for minor, used := range n.deviceUsed[schedulingv1alpha1.GPU] {
+		if isIdleGPU(used) {
+			continue
+		}
+		free := n.deviceFree[schedulingv1alpha1.GPU][minor]
+		if !free.Name(apiext.ResourceGPUCore, resource.DecimalSI).IsZero() {
+			fragmented.Insert(minor)
+		}
+	}
+	return fragmented
+}
+
+// gpuFragmentationDelta returns the change of the GPU fragments of the node in percentage if the GPU allocation
+// is applied. A negative value means the allocation consumes the existing fragments.
+func (n *nodeDevice) gpuFragmentationDelta(minor int, allocation corev1.ResourceList) int64 {
+	free := n.deviceFree[schedulingv1alpha1.GPU][minor]
+	used := n.deviceUsed[schedulingv1alpha1.GPU][minor]
+	before := gpuFragmentation(free, used, allocation)
+	after := gpuFragmentation(
+		quotav1.SubtractWithNonNegativeResult(free, allocation),
+		quotav1.Add(used, allocation),
+		allocation,
+	)
+	return after - before
+}
+
+// sortGPUResourcesByFragmentation sorts the GPUs by the fragments left after allocating the request in ascending
+// order, and then by the free gpu-core in ascending order to fill the GPUs in use first.
+func (n *nodeDevice) sortGPUResourcesByFragmentation(pairs []deviceResourceMinorPair, requestPerCard corev1.ResourceList) []deviceResourceMinorPair {
+	deltas := make(map[int]int64, len(pairs))
+	for _, pair := range pairs {
+		deltas[pair.minor] = n.gpuFragmentationDelta(pair.minor, requestPerCard)
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if deltas[pairs[i].minor] != deltas[pairs[j].minor] {
+			return deltas[pairs[i].minor] < deltas[pairs[j].minor]
+		}
+		coreI := pairs[i].resources.Name(apiext.ResourceGPUCore, resource.DecimalSI)
+		coreJ := pairs[j].resources.Name(apiext.ResourceGPUCore, resource.DecimalSI)
+		return coreI.Cmp(*coreJ) < 0
+	})
+	return pairs
+}
+
+// scoreGPUFragmentation scores the GPU allocations by the fragments they leave, the allocations leaving no new
+// fragments get the MaxNodeScore.
+func (n *nodeDevice) scoreGPUFragmentation(allocations []*apiext.DeviceAllocation) int64 {
+	if len(allocations) == 0 {
+		return 0
+	}
+	var delta int64
+	for _, allocation := range allocations {
+		delta += n.gpuFragmentationDelta(int(allocation.Minor), allocation.Resources)
+	}
+	score := framework.MaxNodeScore - delta/int64(len(allocations))
+	if score > framework.MaxNodeScore {
+		score = framework.MaxNodeScore
+	} else if score < framework.MinNodeScore {
+		score = framework.MinNodeScore
+	}
+	return score
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func gpuResources(core, memoryRatio int64) corev1.ResourceList {
+	return corev1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(core, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(memoryRatio, resource.DecimalSI),
+		apiext.ResourceGPUMemory:      *resource.NewQuantity(memoryRatio*1024*1024*1024, resource.BinarySI),
+	}
+}
+
+// newFragmentationTestNodeDevice returns a nodeDevice whose GPUs have the gpu-core and gpu-memory-ratio used.
+func newFragmentationTestNodeDevice(used ...int64) *nodeDevice {
+	nd := newNodeDevice()
+	nd.minimizeGPUFragmentation = true
+	total := deviceResources{}
+	nd.deviceUsed[schedulingv1alpha1.GPU] = deviceResources{}
+	for i, u := range used {
+		total[i] = gpuResources(100, 100)
+		if u > 0 {
+			nd.deviceUsed[schedulingv1alpha1.GPU][i] = gpuResources(u, u)
+		}
+	}
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{schedulingv1alpha1.GPU: total})
+	return nd
+}
+
+func Test_gpuFragmentation(t *testing.T) {
+	tests := []struct {
+		name    string
+		free    corev1.ResourceList
+		used    corev1.ResourceList
+		request corev1.ResourceList
+		want    int64
+	}{
+		{
+			name:    "idle GPU has no fragment",
+			free:    gpuResources(100, 100),
+			used:    corev1.ResourceList{},
+			request: gpuResources(200, 200),
+			want:    0,
+		},
+		{
+			name:    "free resources able to hold the request",
+			free:    gpuResources(50, 50),
+			used:    gpuResources(50, 50),
+			request: gpuResources(50, 50),
+			want:    0,
+		},
+		{
+			name:    "free resources unable to hold the request",
+			free:    gpuResources(30, 30),
+			used:    gpuResources(70, 70),
+			request: gpuResources(50, 50),
+			want:    30,
+		},
+		{
+			name:    "free gpu-core stranded by the used memory",
+			free:    gpuResources(80, 10),
+			used:    gpuResources(20, 90),
+			request: gpuResources(20, 20),
+			want:    80,
+		},
+		{
+			name:    "oversold gpu-core",
+			free:    gpuResources(50, 60),
+			used:    gpuResources(100, 40),
+			request: gpuResources(100, 10),
+			want:    60,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, gpuFragmentation(tt.free, tt.used, tt.request))
+		})
+	}
+}
+
+func Test_nodeDevice_tryAllocateGPU_minimizeFragmentation(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      []int64
+		request   corev1.ResourceList
+		wantMinor []int32
+	}{
+		{
+			name:      "fill the GPU in use exactly",
+			used:      []int64{0, 50, 70},
+			request:   gpuResources(30, 30),
+			wantMinor: []int32{2},
+		},
+		{
+			name:      "prefer the GPU in use if no fragments are left",
+			used:      []int64{50, 0, 20},
+			request:   gpuResources(40, 40),
+			wantMinor: []int32{2},
+		},
+		{
+			name:      "use the idle GPU rather than leaving fragments",
+			used:      []int64{70, 0},
+			request:   gpuResources(20, 20),
+			wantMinor: []int32{1},
+		},
+		{
+			name:      "multiple GPUs",
+			used:      []int64{0, 50, 0, 0},
+			request:   gpuResources(200, 200),
+			wantMinor: []int32{0, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := newFragmentationTestNodeDevice(tt.used...)
+			allocations, err := nd.tryAllocateDevice(tt.request)
+			assert.NoError(t, err)
+			var minors []int32
+			for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+				minors = append(minors, allocation.Minor)
+			}
+			assert.Equal(t, tt.wantMinor, minors)
+		})
+	}
+}
+
+func Test_Plugin_Score_minimizeFragmentation(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      []int64
+		request   corev1.ResourceList
+		wantScore int64
+	}{
+		{
+			name:      "no new fragments",
+			used:      []int64{50, 0},
+			request:   gpuResources(50, 50),
+			wantScore: framework.MaxNodeScore,
+		},
+		{
+			name:      "fragments left on the only available GPU",
+			used:      []int64{40, 100},
+			request:   gpuResources(50, 50),
+			wantScore: 90,
+		},
+		{
+			name:      "insufficient GPUs",
+			used:      []int64{100, 100},
+			request:   gpuResources(50, 50),
+			wantScore: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newNodeDeviceCache()
+			cache.nodeDeviceInfos["test-node"] = newFragmentationTestNodeDevice(tt.used...)
+			p := &Plugin{nodeDeviceCache: cache, allocator: &defaultAllocator{}}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: tt.request})
+			score, status := p.Score(context.TODO(), cycleState, &corev1.Pod{}, "test-node")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func Test_nodeDevice_getFragmentedGPUs(t *testing.T) {
+	nd := newFragmentationTestNodeDevice(0, 50, 100, 20)
+	assert.Equal(t, []int{1, 3}, nd.getFragmentedGPUs().List())
+}
+
+func Test_defaultAllocator_Allocate_gpuCompaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		pod       *corev1.Pod
+		source    *apiext.GPUCompactionSource
+		wantMinor int32
+	}{
+		{
+			name:      "normal pod",
+			pod:       &corev1.Pod{},
+			wantMinor: 0,
+		},
+		{
+			name: "compaction pod prefers the fragmented GPU",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{apiext.AnnotationGPUCompaction: "true"},
+				},
+			},
+			wantMinor: 2,
+		},
+		{
+			name: "compaction pod never allocates the GPU it releases",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{apiext.AnnotationGPUCompaction: "true"},
+				},
+			},
+			source:    &apiext.GPUCompactionSource{NodeName: "test-node", Minor: 2},
+			wantMinor: 0,
+		},
+		{
+			name: "compaction pod prefers the fragmented GPU released on another node",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{apiext.AnnotationGPUCompaction: "true"},
+				},
+			},
+			source:    &apiext.GPUCompactionSource{NodeName: "other-node", Minor: 2},
+			wantMinor: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.source != nil {
+				assert.NoError(t, apiext.SetGPUCompactionSource(tt.pod, tt.source))
+			}
+			nd := newFragmentationTestNodeDevice(0, 100, 50)
+			nd.minimizeGPUFragmentation = false
+			allocations, err := (&defaultAllocator{}).Allocate("test-node", tt.pod, gpuResources(30, 30), nd)
+			assert.NoError(t, err)
+			assert.Len(t, allocations[schedulingv1alpha1.GPU], 1)
+			assert.Equal(t, tt.wantMinor, allocations[schedulingv1alpha1.GPU][0].Minor)
+		})
+	}
+}
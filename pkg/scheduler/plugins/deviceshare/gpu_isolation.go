@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// isGPUExclusivePod returns true if the GPUs allocated to the pod should not be shared with other pods.
+func isGPUExclusivePod(pod *corev1.Pod) bool {
+	return pod != nil && apiext.GPUIsolation(pod.Annotations[apiext.AnnotationGPUIsolation]) == apiext.GPUIsolationExclusive
+}
+
+// updateExclusiveGPUs records the GPUs allocated to the exclusive pod.
+func (n *nodeDevice) updateExclusiveGPUs(allocations []*apiext.DeviceAllocation, pod *corev1.Pod, add bool) {
+	if !isGPUExclusivePod(pod) {
+		return
+	}
+	podNamespacedName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if !add {
+		for minor, pods := range n.exclusiveGPUs {
+			pods.Delete(podNamespacedName.String())
+			if pods.Len() == 0 {
+				delete(n.exclusiveGPUs, minor)
+			}
+		}
+		return
+	}
+	if n.exclusiveGPUs == nil {
+		n.exclusiveGPUs = map[int]sets.String{}
+	}
+	for _, allocation := range allocations {
+		minor := int(allocation.Minor)
+		if n.exclusiveGPUs[minor] == nil {
+			n.exclusiveGPUs[minor] = sets.NewString()
+		}
+		n.exclusiveGPUs[minor].Insert(podNamespacedName.String())
+	}
+}
+
+// getExcludedGPUs returns the GPUs which can not be allocated to the pod due to the GPU isolation.
+// The GPUs allocated to the exclusive pods are excluded for all pods, and the GPUs with any allocation
+// are also excluded for the exclusive pods, including the batch GPU allocations. The GPUs shared by maxPodsPerGPU pods are excluded too.
+func (n *nodeDevice) getExcludedGPUs(pod *corev1.Pod) sets.Int {
+	excluded := sets.NewInt()
+	for minor := range n.exclusiveGPUs {
+		excluded.Insert(minor)
+	}
+	if n.maxPodsPerGPU > 0 {
+		for minor, count := range n.getGPUPodCounts() {
+			if count >= int(n.maxPodsPerGPU) {
+				excluded.Insert(minor)
+			}
+		}
+	}
+	if isGPUExclusivePod(pod) {
+		for minor, used := range n.deviceUsed[schedulingv1alpha1.GPU] {
+			if !isIdleGPU(used) || !used.Name(apiext.BatchGPUCore, resource.DecimalSI).IsZero() {
+				excluded.Insert(minor)
+			}
+		}
+	}
+	return excluded
+}
+
+// getGPUPodCounts returns the number of pods allocated each GPU. The GPUs of the reservation consumed
+// by the owner pods are counted with the owner pods only.
+func (n *nodeDevice) getGPUPodCounts() map[int]int {
+	consumedGPUs := map[types.NamespacedName]sets.Int{}
+	for _, reserved := range n.reservedDevices {
+		if reserved.reservePod == nil {
+			continue
+		}
+		minors := sets.NewInt()
+		for _, consumed := range reserved.consumers {
+			for _, allocation := range consumed[schedulingv1alpha1.GPU] {
+				minors.Insert(int(allocation.Minor))
+			}
+		}
+		if minors.Len() > 0 {
+			consumedGPUs[types.NamespacedName{Namespace: reserved.reservePod.Namespace, Name: reserved.reservePod.Name}] = minors
+		}
+	}
+	counts := map[int]int{}
+	for podNamespacedName, allocations := range n.allocateSet[schedulingv1alpha1.GPU] {
+		for minor := range allocations {
+			if consumedGPUs[podNamespacedName].Has(minor) {
+				continue
+			}
+			counts[minor]++
+		}
+	}
+	for minor, delta := range n.gpuPodCountDelta {
+		counts[minor] += delta
+	}
+	return counts
+}
+
+// updateGPUPodCountDelta records the pod count change of the GPUs applied without updating allocateSet.
+func (n *nodeDevice) updateGPUPodCountDelta(allocations []*apiext.DeviceAllocation, add bool) {
+	if n.gpuPodCountDelta == nil {
+		n.gpuPodCountDelta = map[int]int{}
+	}
+	for _, allocation := range allocations {
+		if add {
+			n.gpuPodCountDelta[int(allocation.Minor)]++
+		} else {
+			n.gpuPodCountDelta[int(allocation.Minor)]--
+		}
+	}
+}
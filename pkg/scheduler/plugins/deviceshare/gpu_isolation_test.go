@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func Test_exclusiveGPUIsolation(t *testing.T) {
+	newPod := func(name string, exclusive bool) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+		if exclusive {
+			pod.Annotations = map[string]string{apiext.AnnotationGPUIsolation: string(apiext.GPUIsolationExclusive)}
+		}
+		return pod
+	}
+	allocatedMinors := func(allocations apiext.DeviceAllocations) []int32 {
+		var minors []int32
+		for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+			minors = append(minors, allocation.Minor)
+		}
+		return minors
+	}
+
+	nd := newFragmentationTestNodeDevice(0, 0, 0)
+	nd.minimizeGPUFragmentation = false
+	allocator := &defaultAllocator{}
+
+	sharedPod := newPod("shared-1", false)
+	allocations, err := allocator.Allocate("test-node", sharedPod, gpuResources(50, 50), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{0}, allocatedMinors(allocations))
+	allocator.Reserve(sharedPod, nd, allocations)
+
+	// the exclusive pod skips the GPU shared by other pods
+	exclusivePod := newPod("exclusive-1", true)
+	allocations, err = allocator.Allocate("test-node", exclusivePod, gpuResources(50, 50), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1}, allocatedMinors(allocations))
+	allocator.Reserve(exclusivePod, nd, allocations)
+	assert.Equal(t, []string{"default/exclusive-1"}, nd.exclusiveGPUs[1].List())
+
+	// the shared pods can not use the free resources of the exclusive GPU
+	allocations, err = allocator.Allocate("test-node", newPod("shared-2", false), gpuResources(50, 50), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{0}, allocatedMinors(allocations))
+	_, err = allocator.Allocate("test-node", newPod("shared-2", false), gpuResources(200, 200), nd)
+	assert.Error(t, err)
+
+	// another exclusive pod can only use the idle GPU
+	_, err = allocator.Allocate("test-node", newPod("exclusive-2", true), gpuResources(200, 200), nd)
+	assert.Error(t, err)
+	allocations, err = allocator.Allocate("test-node", newPod("exclusive-2", true), gpuResources(100, 100), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{2}, allocatedMinors(allocations))
+
+	// the GPU can be shared again after the exclusive pod is deleted
+	allocator.Unreserve(exclusivePod, nd, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(50, 50)}},
+	})
+	assert.Empty(t, nd.exclusiveGPUs)
+	allocations, err = allocator.Allocate("test-node", newPod("shared-2", false), gpuResources(200, 200), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1, 2}, allocatedMinors(allocations))
+}
+
+func Test_maxPodsPerGPU(t *testing.T) {
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	}
+	allocatedMinors := func(allocations apiext.DeviceAllocations) []int32 {
+		var minors []int32
+		for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+			minors = append(minors, allocation.Minor)
+		}
+		return minors
+	}
+
+	nd := newFragmentationTestNodeDevice(0, 0, 0)
+	nd.minimizeGPUFragmentation = false
+	nd.maxPodsPerGPU = 2
+	allocator := &defaultAllocator{}
+
+	for _, name := range []string{"pod-1", "pod-2"} {
+		pod := newPod(name)
+		allocations, err := allocator.Allocate("test-node", pod, gpuResources(10, 10), nd)
+		assert.NoError(t, err)
+		assert.Equal(t, []int32{0}, allocatedMinors(allocations))
+		allocator.Reserve(pod, nd, allocations)
+	}
+	assert.Equal(t, map[int]int{0: 2}, nd.getGPUPodCounts())
+
+	// the GPU shared by maxPodsPerGPU pods is skipped although it has enough free gpu-core
+	allocations, err := allocator.Allocate("test-node", newPod("pod-3"), gpuResources(10, 10), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{1}, allocatedMinors(allocations))
+
+	// the nominated allocations are counted by the cloned nodeDevice
+	cloned := nd.cloneWithAllocations([]apiext.DeviceAllocations{allocations}, nil)
+	assert.Equal(t, map[int]int{0: 2, 1: 1}, cloned.getGPUPodCounts())
+	assert.Equal(t, map[int]int{0: 2}, nd.getGPUPodCounts())
+
+	// zero means no limit
+	nd.maxPodsPerGPU = 0
+	allocations, err = allocator.Allocate("test-node", newPod("pod-3"), gpuResources(10, 10), nd)
+	assert.NoError(t, err)
+	assert.Equal(t, []int32{0}, allocatedMinors(allocations))
+}
+
+func Test_maxPodsPerGPU_reservationOwners(t *testing.T) {
+	nd := newFragmentationTestNodeDevice(0, 0)
+	r := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	})
+	nd.updateReservedDevices(reservationutil.NewReservePod(r), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	})
+	assert.Equal(t, map[int]int{0: 1}, nd.getGPUPodCounts())
+
+	// the owner pod consuming a part of the reservation is counted once
+	owner := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "owner", UID: "owner"}}
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(50, 50)}},
+	}
+	nd.assumeReservationConsumer(r.UID, owner, allocations)
+	nd.updateCacheUsed(allocations, owner, true)
+	assert.Equal(t, map[int]int{0: 1}, nd.getGPUPodCounts())
+	assert.Equal(t, map[int]int{0: 1}, nd.cloneWithAllocations(nil, nil).getGPUPodCounts())
+
+	// the reservation is counted again after the owner pod is gone
+	nd.updateCacheUsed(allocations, owner, false)
+	nd.forgetReservationConsumer(r.UID, owner)
+	assert.Equal(t, map[int]int{0: 1}, nd.getGPUPodCounts())
+}
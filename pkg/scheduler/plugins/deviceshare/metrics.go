@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/metrics"
+)
+
+const (
+	extensionPointFilter  = "Filter"
+	extensionPointReserve = "Reserve"
+)
+
+// recordPluginDuration records the time spent in the extension point and counts the allocation failure if any.
+func recordPluginDuration(extensionPoint string, startTime time.Time, status *framework.Status) {
+	metrics.DeviceSharePluginDuration.WithLabelValues(extensionPoint, status.Code().String()).Observe(metrics.SinceInSeconds(startTime))
+	if !status.IsSuccess() && status.Code() != framework.Error {
+		metrics.DeviceShareAllocationFailures.WithLabelValues(extensionPoint, status.Message()).Inc()
+	}
+}
+
+// recordMetrics records the total and allocated device resources of the node.
+// It must be called with the lock of nodeDevice held.
+func (n *nodeDevice) recordMetrics(nodeName string) {
+	for deviceType, total := range n.deviceTotal {
+		totalResources := sumDeviceResources(total)
+		usedResources := sumDeviceResources(n.deviceUsed[deviceType])
+		for resourceName := range usedResources {
+			if _, ok := totalResources[resourceName]; !ok {
+				totalResources[resourceName] = 0
+			}
+		}
+		for resourceName, value := range totalResources {
+			metrics.DeviceShareNodeDeviceTotal.WithLabelValues(nodeName, string(deviceType), string(resourceName)).Set(value)
+			metrics.DeviceShareNodeDeviceAllocated.WithLabelValues(nodeName, string(deviceType), string(resourceName)).Set(usedResources[resourceName])
+		}
+	}
+}
+
+// deleteMetrics deletes the metrics of the node recorded by recordMetrics.
+// It must be called with the lock of nodeDevice held.
+func (n *nodeDevice) deleteMetrics(nodeName string) {
+	for _, m := range []map[schedulingv1alpha1.DeviceType]deviceResources{n.deviceTotal, n.deviceUsed} {
+		for deviceType, resources := range m {
+			for resourceName := range sumDeviceResources(resources) {
+				labels := map[string]string{"node": nodeName, "device_type": string(deviceType), "resource": string(resourceName)}
+				metrics.DeviceShareNodeDeviceTotal.Delete(labels)
+				metrics.DeviceShareNodeDeviceAllocated.Delete(labels)
+			}
+		}
+	}
+}
+
+func sumDeviceResources(resources deviceResources) map[corev1.ResourceName]float64 {
+	sum := map[corev1.ResourceName]float64{}
+	for _, resourceList := range resources {
+		for resourceName, quantity := range resourceList {
+			sum[resourceName] += float64(quantity.MilliValue()) / 1000
+		}
+	}
+	return sum
+}
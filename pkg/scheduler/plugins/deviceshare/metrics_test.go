@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/metrics"
+)
+
+func TestNodeDeviceRecordMetrics(t *testing.T) {
+	metrics.Register()
+
+	nd := newNodeDevice()
+	nd.deviceTotal[schedulingv1alpha1.GPU] = deviceResources{
+		0: corev1.ResourceList{
+			apiext.ResourceGPUCore:   resource.MustParse("100"),
+			apiext.ResourceGPUMemory: resource.MustParse("16Gi"),
+		},
+		1: corev1.ResourceList{
+			apiext.ResourceGPUCore:   resource.MustParse("100"),
+			apiext.ResourceGPUMemory: resource.MustParse("16Gi"),
+		},
+	}
+	nd.deviceUsed[schedulingv1alpha1.GPU] = deviceResources{
+		0: corev1.ResourceList{
+			apiext.ResourceGPUCore:   resource.MustParse("50"),
+			apiext.ResourceGPUMemory: resource.MustParse("8Gi"),
+		},
+	}
+	nd.recordMetrics("test-node-1")
+
+	value, err := testutil.GetGaugeMetricValue(metrics.DeviceShareNodeDeviceTotal.WithLabelValues("test-node-1", string(schedulingv1alpha1.GPU), string(apiext.ResourceGPUCore)))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(200), value)
+	value, err = testutil.GetGaugeMetricValue(metrics.DeviceShareNodeDeviceAllocated.WithLabelValues("test-node-1", string(schedulingv1alpha1.GPU), string(apiext.ResourceGPUCore)))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(50), value)
+	value, err = testutil.GetGaugeMetricValue(metrics.DeviceShareNodeDeviceAllocated.WithLabelValues("test-node-1", string(schedulingv1alpha1.GPU), string(apiext.ResourceGPUMemory)))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(8*1024*1024*1024), value)
+
+	nd.deleteMetrics("test-node-1")
+	labels := map[string]string{"node": "test-node-1", "device_type": string(schedulingv1alpha1.GPU), "resource": string(apiext.ResourceGPUCore)}
+	assert.False(t, metrics.DeviceShareNodeDeviceTotal.Delete(labels))
+	assert.False(t, metrics.DeviceShareNodeDeviceAllocated.Delete(labels))
+}
+
+func TestRecordPluginDuration(t *testing.T) {
+	metrics.Register()
+
+	failures := metrics.DeviceShareAllocationFailures.WithLabelValues(extensionPointFilter, ErrInsufficientDevices)
+	before, err := testutil.GetCounterMetricValue(failures)
+	assert.NoError(t, err)
+	duration := metrics.DeviceSharePluginDuration.WithLabelValues(extensionPointFilter, framework.Success.String())
+	countBefore, err := testutil.GetHistogramMetricCount(duration)
+	assert.NoError(t, err)
+
+	recordPluginDuration(extensionPointFilter, time.Now(), nil)
+	recordPluginDuration(extensionPointFilter, time.Now(), framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices))
+	recordPluginDuration(extensionPointFilter, time.Now(), framework.NewStatus(framework.Error, "node not found"))
+
+	after, err := testutil.GetCounterMetricValue(failures)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), after-before)
+
+	countAfter, err := testutil.GetHistogramMetricCount(duration)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), countAfter-countBefore)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+)
+
+func registerNodeEventHandler(deviceCache *nodeDeviceCache, sharedInformerFactory informers.SharedInformerFactory) {
+	nodeInformer := sharedInformerFactory.Core().V1().Nodes().Informer()
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    deviceCache.onNodeAdd,
+		UpdateFunc: deviceCache.onNodeUpdate,
+	}
+	// make sure the gpu-core oversell percent of nodes are loaded before scheduler starts working
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), sharedInformerFactory, nodeInformer, eventHandler)
+}
+
+func (n *nodeDeviceCache) onNodeAdd(obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		klog.Errorf("node cache add failed to parse, obj %T", obj)
+		return
+	}
+	n.updateGPUCoreOversellPercent(node)
+}
+
+func (n *nodeDeviceCache) onNodeUpdate(oldObj, newObj interface{}) {
+	_, oldOK := oldObj.(*corev1.Node)
+	newNode, newOK := newObj.(*corev1.Node)
+	if !oldOK || !newOK {
+		klog.Errorf("node cache update failed to parse, oldObj %T, newObj %T", oldObj, newObj)
+		return
+	}
+	n.updateGPUCoreOversellPercent(newNode)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+)
+
+func registerNodeMetricEventHandler(deviceCache *nodeDeviceCache, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory) {
+	nodeMetricInformer := koordSharedInformerFactory.Slo().V1alpha1().NodeMetrics().Informer()
+	// the GPU usage is only used as a preference, so there is no need to sync it before scheduling
+	nodeMetricInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    deviceCache.onNodeMetricAdd,
+		UpdateFunc: deviceCache.onNodeMetricUpdate,
+	})
+}
+
+func (n *nodeDeviceCache) onNodeMetricAdd(obj interface{}) {
+	nodeMetric, ok := obj.(*slov1alpha1.NodeMetric)
+	if !ok {
+		klog.Errorf("nodeMetric cache add failed to parse, obj %T", obj)
+		return
+	}
+	n.updateGPUUsage(nodeMetric)
+}
+
+func (n *nodeDeviceCache) onNodeMetricUpdate(oldObj, newObj interface{}) {
+	_, oldOK := oldObj.(*slov1alpha1.NodeMetric)
+	newNodeMetric, newOK := newObj.(*slov1alpha1.NodeMetric)
+	if !oldOK || !newOK {
+		klog.Errorf("nodeMetric cache update failed to parse, oldObj %T, newObj %T", oldObj, newObj)
+		return
+	}
+	n.updateGPUUsage(newNodeMetric)
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+)
+
+// deviceNominator records the device allocations of the pods nominated to nodes but not reserved yet,
+// e.g. the preemptors waiting for the victims to terminate, or the pods waiting for the devices released by the
+// terminating pods. It is analogous to the pod nominator of the
+// scheduler framework, so that the devices claimed by the nominated pods are visible to the subsequent
+// scheduling cycles and not allocated to other pods in the meantime.
+type deviceNominator struct {
+	lock sync.RWMutex
+	// nominatedAllocations is keyed by the UID of the nominated pod.
+	nominatedAllocations map[types.UID]*nominatedDeviceAllocation
+	// waitingSince is the time since when the pod waits for the devices released by the terminating pods,
+	// keyed by the UID of the pod. It is kept until the pod is bound or deleted.
+	waitingSince map[types.UID]time.Time
+}
+
+// maxTerminatingPodsWait is the longest time a pod waits for the devices released by the terminating pods.
+// The terminating pods may be stuck on the finalizers, so the pod falls through to the preemption after that.
+var maxTerminatingPodsWait = 2 * time.Minute
+
+type nominatedDeviceAllocation struct {
+	nodeName    string
+	priority    int32
+	allocations apiext.DeviceAllocations
+}
+
+func newDeviceNominator() *deviceNominator {
+	return &deviceNominator{
+		nominatedAllocations: map[types.UID]*nominatedDeviceAllocation{},
+		waitingSince:         map[types.UID]time.Time{},
+	}
+}
+
+func registerNominatorEventHandler(nominator *deviceNominator, sharedInformerFactory informers.SharedInformerFactory) {
+	podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		UpdateFunc: nominator.onPodUpdate,
+		DeleteFunc: nominator.onPodDelete,
+	}
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), sharedInformerFactory, podInformer, eventHandler)
+}
+
+// addNominatedAllocation records the device allocation of the pod nominated to the node.
+func (d *deviceNominator) addNominatedAllocation(pod *corev1.Pod, nodeName string, allocations apiext.DeviceAllocations) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.nominatedAllocations[pod.UID] = &nominatedDeviceAllocation{
+		nodeName:    nodeName,
+		priority:    corev1helpers.PodPriority(pod),
+		allocations: allocations,
+	}
+}
+
+// removeNominatedAllocation forgets the device allocation of the pod once it is reserved, bound or deleted,
+// or the nomination is cleared.
+func (d *deviceNominator) removeNominatedAllocation(pod *corev1.Pod) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.nominatedAllocations, pod.UID)
+}
+
+// waitForTerminatingPods records that the pod waits for the devices released by the terminating pods,
+// and returns false if the pod has waited longer than maxTerminatingPodsWait.
+func (d *deviceNominator) waitForTerminatingPods(pod *corev1.Pod) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	since, ok := d.waitingSince[pod.UID]
+	if !ok {
+		d.waitingSince[pod.UID] = time.Now()
+		return true
+	}
+	return time.Since(since) < maxTerminatingPodsWait
+}
+
+func (d *deviceNominator) forgetPod(pod *corev1.Pod) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.nominatedAllocations, pod.UID)
+	delete(d.waitingSince, pod.UID)
+}
+
+// getNominatedAllocation returns the device allocation of the pod nominated to the node.
+func (d *deviceNominator) getNominatedAllocation(pod *corev1.Pod, nodeName string) apiext.DeviceAllocations {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	nominated := d.nominatedAllocations[pod.UID]
+	if nominated == nil || nominated.nodeName != nodeName {
+		return nil
+	}
+	return nominated.allocations
+}
+
+// getNominatedAllocationsForNode returns the device allocations of the other pods nominated to the node
+// whose priority is not lower than the pod, the same as the nominated pods considered by the framework.
+func (d *deviceNominator) getNominatedAllocationsForNode(pod *corev1.Pod, nodeName string) []apiext.DeviceAllocations {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	priority := corev1helpers.PodPriority(pod)
+	var result []apiext.DeviceAllocations
+	for uid, nominated := range d.nominatedAllocations {
+		if uid == pod.UID || nominated.nodeName != nodeName || nominated.priority < priority {
+			continue
+		}
+		result = append(result, nominated.allocations)
+	}
+	return result
+}
+
+// allocateFromTerminatingPods returns the devices allocated to the pod on the node assuming the devices of the
+// terminating pods are released, or nil if the pod still cannot be allocated.
+func (p *Plugin) allocateFromTerminatingPods(pod *corev1.Pod, podRequest corev1.ResourceList, nodeName string) apiext.DeviceAllocations {
+	if p.handle == nil {
+		return nil
+	}
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return nil
+	}
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+	if nodeDeviceInfo == nil {
+		return nil
+	}
+	nodeDeviceInfo.lock.RLock()
+	defer nodeDeviceInfo.lock.RUnlock()
+
+	var released []apiext.DeviceAllocations
+	for _, podInfo := range nodeInfo.Pods {
+		if podInfo.Pod.DeletionTimestamp == nil {
+			continue
+		}
+		if allocations := nodeDeviceInfo.getPodAllocations(podInfo.Pod); len(allocations) > 0 {
+			released = append(released, allocations)
+		}
+	}
+	if len(released) == 0 {
+		return nil
+	}
+	nominated := p.nominator.getNominatedAllocationsForNode(pod, nodeName)
+	allocations, err := p.allocator.Allocate(nodeName, pod, podRequest, nodeDeviceInfo.cloneWithAllocations(nominated, released))
+	if err != nil {
+		klog.V(5).InfoS("failed to allocate devices released by terminating pods", "pod", klog.KObj(pod), "node", nodeName, "err", err)
+		return nil
+	}
+	return allocations
+}
+
+func (d *deviceNominator) onPodUpdate(oldObj, newObj interface{}) {
+	pod, ok := newObj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	if pod.Spec.NodeName != "" {
+		d.forgetPod(pod)
+		return
+	}
+	if pod.Status.NominatedNodeName == "" {
+		d.removeNominatedAllocation(pod)
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if nominated := d.nominatedAllocations[pod.UID]; nominated != nil && nominated.nodeName != pod.Status.NominatedNodeName {
+		delete(d.nominatedAllocations, pod.UID)
+	}
+}
+
+func (d *deviceNominator) onPodDelete(obj interface{}) {
+	var pod *corev1.Pod
+	switch t := obj.(type) {
+	case *corev1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		pod, ok = t.Obj.(*corev1.Pod)
+		if !ok {
+			return
+		}
+	default:
+		return
+	}
+	d.forgetPod(pod)
+	klog.V(5).InfoS("nominated device allocation removed", "pod", klog.KObj(pod))
+}
+
+// nodeDeviceDelta is the change of the device allocations on a node in a scheduling cycle,
+// which is made by the PreFilterExtensions for the nominated pods and the preemption victims.
+type nodeDeviceDelta struct {
+	// added is the allocations of the nominated pods, keyed by the pod UID.
+	added map[types.UID]apiext.DeviceAllocations
+	// removed is the allocations of the victims, keyed by the pod UID.
+	removed map[types.UID]apiext.DeviceAllocations
+	// victimPriorities is the priorities of the victims, keyed by the pod UID.
+	victimPriorities map[types.UID]int32
+	// preemptionGPUs is the GPUs to be reclaimed from the victims for the preemptor, which is selected
+	// when the preemptor is filtered with all the potential victims removed. Nil means not selected yet.
+	preemptionGPUs sets.Int
+}
+
+func (d *nodeDeviceDelta) clone() *nodeDeviceDelta {
+	out := &nodeDeviceDelta{
+		added:            make(map[types.UID]apiext.DeviceAllocations, len(d.added)),
+		removed:          make(map[types.UID]apiext.DeviceAllocations, len(d.removed)),
+		victimPriorities: make(map[types.UID]int32, len(d.victimPriorities)),
+		preemptionGPUs:   d.preemptionGPUs,
+	}
+	for k, v := range d.added {
+		out.added[k] = v
+	}
+	for k, v := range d.removed {
+		out.removed[k] = v
+	}
+	for k, v := range d.victimPriorities {
+		out.victimPriorities[k] = v
+	}
+	return out
+}
+
+func (d *nodeDeviceDelta) isEmpty() bool {
+	return d == nil || (len(d.added) == 0 && len(d.removed) == 0)
+}
+
+// getPodAllocations returns the device allocations of the pod recorded on the node.
+func (n *nodeDevice) getPodAllocations(pod *corev1.Pod) apiext.DeviceAllocations {
+	podNamespacedName := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	var result apiext.DeviceAllocations
+	for deviceType, allocateSet := range n.allocateSet {
+		allocations, ok := allocateSet[podNamespacedName]
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = apiext.DeviceAllocations{}
+		}
+		for minor, resources := range allocations {
+			result[deviceType] = append(result[deviceType], &apiext.DeviceAllocation{
+				Minor:     int32(minor),
+				Resources: resources,
+			})
+		}
+	}
+	return result
+}
+
+// cloneWithAllocations returns a copy of the nodeDevice with the allocations applied, which is used to
+// allocate the devices without changing the cache. The lock of nodeDevice must be held.
+func (n *nodeDevice) cloneWithAllocations(added, removed []apiext.DeviceAllocations) *nodeDevice {
+	out := &nodeDevice{
+		deviceTotal:              n.deviceTotal,
+		deviceFree:               make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceFree)),
+		deviceUsed:               make(map[schedulingv1alpha1.DeviceType]deviceResources, len(n.deviceUsed)),
+		allocateSet:              n.allocateSet,
+		deviceVFs:                n.deviceVFs,
+		gpuCoreOversellPercent:   n.gpuCoreOversellPercent,
+		minimizeGPUFragmentation: n.minimizeGPUFragmentation,
+		gpuUsage:                 n.gpuUsage,
+		gpuUsageUpdateTime:       n.gpuUsageUpdateTime,
+		removedAllocations:       n.removedAllocations,
+		previousGPUAllocations:   n.previousGPUAllocations,
+		gpuVendor:                n.gpuVendor,
+		exclusiveGPUs:            n.exclusiveGPUs,
+		maxPodsPerGPU:            n.maxPodsPerGPU,
+		reservedDevices:          n.reservedDevices,
+	}
+	if len(n.gpuPodCountDelta) > 0 {
+		out.gpuPodCountDelta = make(map[int]int, len(n.gpuPodCountDelta))
+		for minor, delta := range n.gpuPodCountDelta {
+			out.gpuPodCountDelta[minor] = delta
+		}
+	}
+	for deviceType, resources := range n.deviceFree {
+		out.deviceFree[deviceType] = resources.DeepCopy()
+	}
+	for deviceType, resources := range n.deviceUsed {
+		out.deviceUsed[deviceType] = resources.DeepCopy()
+	}
+	if n.vfUsed != nil {
+		out.vfUsed = make(map[schedulingv1alpha1.DeviceType]map[int]sets.Int32, len(n.vfUsed))
+		for deviceType, vfs := range n.vfUsed {
+			out.vfUsed[deviceType] = make(map[int]sets.Int32, len(vfs))
+			for minor, used := range vfs {
+				out.vfUsed[deviceType][minor] = sets.NewInt32(used.List()...)
+			}
+		}
+	}
+
+	deviceTypes := sets.NewString()
+	apply := func(deviceAllocations []apiext.DeviceAllocations, add bool) {
+		for _, allocations := range deviceAllocations {
+			for deviceType, allocation := range allocations {
+				out.updateDeviceUsed(deviceType, allocation, add)
+				out.updateVFUsed(deviceType, allocation, add)
+				if deviceType == schedulingv1alpha1.GPU {
+					out.updateGPUPodCountDelta(allocation, add)
+				}
+				deviceTypes.Insert(string(deviceType))
+			}
+		}
+	}
+	apply(removed, false)
+	apply(added, true)
+	for _, deviceType := range deviceTypes.List() {
+		out.resetDeviceFree(schedulingv1alpha1.DeviceType(deviceType))
+	}
+	return out
+}
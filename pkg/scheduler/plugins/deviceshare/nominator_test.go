@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newNominatorTestPod(name string, gpu int64, priority int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+		},
+		Spec: corev1.PodSpec{
+			Priority: pointer.Int32(priority),
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							apiext.ResourceGPU: *resource.NewQuantity(gpu, resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newNominatorTestPlugin(gpus int) (*Plugin, *nodeDevice, *framework.NodeInfo) {
+	used := make([]int64, gpus)
+	nd := newFragmentationTestNodeDevice(used...)
+	nd.minimizeGPUFragmentation = false
+	deviceCache := newNodeDeviceCache()
+	deviceCache.nodeDeviceInfos["test-node"] = nd
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	return &Plugin{
+		nodeDeviceCache: deviceCache,
+		allocator:       &defaultAllocator{},
+		nominator:       newDeviceNominator(),
+	}, nd, nodeInfo
+}
+
+func Test_deviceNominator(t *testing.T) {
+	nominator := newDeviceNominator()
+	highPod := newNominatorTestPod("high", 100, 100)
+	lowPod := newNominatorTestPod("low", 100, 10)
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	}
+	nominator.addNominatedAllocation(highPod, "test-node", allocations)
+
+	assert.Equal(t, allocations, nominator.getNominatedAllocation(highPod, "test-node"))
+	assert.Nil(t, nominator.getNominatedAllocation(highPod, "other-node"))
+	assert.Equal(t, []apiext.DeviceAllocations{allocations}, nominator.getNominatedAllocationsForNode(lowPod, "test-node"))
+	assert.Nil(t, nominator.getNominatedAllocationsForNode(highPod, "test-node"))
+	assert.Nil(t, nominator.getNominatedAllocationsForNode(lowPod, "other-node"))
+
+	// the nomination is changed to another node
+	nominatedPod := highPod.DeepCopy()
+	nominatedPod.Status.NominatedNodeName = "other-node"
+	nominator.onPodUpdate(highPod, nominatedPod)
+	assert.Nil(t, nominator.getNominatedAllocation(highPod, "test-node"))
+
+	// the pod is bound
+	nominator.addNominatedAllocation(highPod, "test-node", allocations)
+	nominatedPod.Status.NominatedNodeName = "test-node"
+	nominator.onPodUpdate(highPod, nominatedPod)
+	assert.Equal(t, allocations, nominator.getNominatedAllocation(highPod, "test-node"))
+	boundPod := nominatedPod.DeepCopy()
+	boundPod.Spec.NodeName = "test-node"
+	nominator.onPodUpdate(nominatedPod, boundPod)
+	assert.Nil(t, nominator.getNominatedAllocation(highPod, "test-node"))
+
+	nominator.addNominatedAllocation(highPod, "test-node", allocations)
+	nominator.onPodDelete(highPod)
+	assert.Empty(t, nominator.nominatedAllocations)
+}
+
+func Test_Plugin_RemovePodForPreemption(t *testing.T) {
+	p, nd, nodeInfo := newNominatorTestPlugin(1)
+	victim := newNominatorTestPod("victim", 100, 0)
+	victim.Spec.NodeName = "test-node"
+	nd.updateCacheUsed(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	}, victim, true)
+
+	preemptor := newNominatorTestPod("preemptor", 100, 100)
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, preemptor).IsSuccess())
+	assert.Equal(t, framework.Unschedulable, p.Filter(context.TODO(), cycleState, preemptor, nodeInfo).Code())
+
+	// the preemption simulates removing the victim on a copy of the state
+	stateToRemove := cycleState.Clone()
+	assert.True(t, p.RemovePod(context.TODO(), stateToRemove, preemptor, framework.NewPodInfo(victim), nodeInfo).IsSuccess())
+	assert.True(t, p.Filter(context.TODO(), stateToRemove, preemptor, nodeInfo).IsSuccess())
+	// the original state and the cache are not changed
+	assert.Equal(t, framework.Unschedulable, p.Filter(context.TODO(), cycleState, preemptor, nodeInfo).Code())
+	free := nd.deviceFree[schedulingv1alpha1.GPU][0]
+	assert.Equal(t, int64(0), free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value())
+
+	// the victim is reprieved
+	assert.True(t, p.AddPod(context.TODO(), stateToRemove, preemptor, framework.NewPodInfo(victim), nodeInfo).IsSuccess())
+	assert.Equal(t, framework.Unschedulable, p.Filter(context.TODO(), stateToRemove, preemptor, nodeInfo).Code())
+}
+
+func Test_Plugin_AddNominatedPod(t *testing.T) {
+	p, nd, nodeInfo := newNominatorTestPlugin(2)
+
+	nominatedPod := newNominatorTestPod("nominated", 100, 100)
+	nominatedPod.Status.NominatedNodeName = "test-node"
+	pod := newNominatorTestPod("pod", 200, 10)
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+
+	// the framework adds the nominated pods before running Filter
+	stateWithNominated := cycleState.Clone()
+	assert.True(t, p.AddPod(context.TODO(), stateWithNominated, pod, framework.NewPodInfo(nominatedPod), nodeInfo).IsSuccess())
+	assert.Equal(t, framework.Unschedulable, p.Filter(context.TODO(), stateWithNominated, pod, nodeInfo).Code())
+	nominated := p.nominator.getNominatedAllocation(nominatedPod, "test-node")
+	assert.Len(t, nominated[schedulingv1alpha1.GPU], 1)
+
+	// the nominated devices are kept across the scheduling cycles
+	otherState := framework.NewCycleState()
+	smallPod := newNominatorTestPod("small", 100, 10)
+	assert.True(t, p.PreFilter(context.TODO(), otherState, smallPod).IsSuccess())
+	assert.True(t, p.AddPod(context.TODO(), otherState, smallPod, framework.NewPodInfo(nominatedPod), nodeInfo).IsSuccess())
+	assert.True(t, p.Filter(context.TODO(), otherState, smallPod, nodeInfo).IsSuccess())
+
+	// Reserve doesn't allocate the devices claimed by the nominated pod of higher priority
+	assert.Equal(t, framework.Unschedulable, p.Reserve(context.TODO(), cycleState, pod, "test-node").Code())
+	assert.True(t, p.Reserve(context.TODO(), otherState, smallPod, "test-node").IsSuccess())
+	smallState, _ := getPreFilterState(otherState)
+	assert.NotEqual(t, nominated[schedulingv1alpha1.GPU][0].Minor, smallState.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+
+	// the nomination is removed once the nominated pod is reserved
+	nominatedState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), nominatedState, nominatedPod).IsSuccess())
+	assert.True(t, p.Reserve(context.TODO(), nominatedState, nominatedPod, "test-node").IsSuccess())
+	assert.Empty(t, p.nominator.nominatedAllocations)
+	for minor, free := range nd.deviceFree[schedulingv1alpha1.GPU] {
+		assert.Equal(t, int64(0), free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value(), "minor %d", minor)
+	}
+}
+
+func Test_Plugin_PostFilter(t *testing.T) {
+	p, nd, nodeInfo := newNominatorTestPlugin(1)
+	runningPod := newNominatorTestPod("running", 100, 0)
+	runningPod.Spec.NodeName = "test-node"
+	nd.updateCacheUsed(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	}, runningPod, true)
+
+	setSnapshot := func(pods ...*corev1.Pod) {
+		fh, err := schedulertesting.NewFramework(
+			[]schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			},
+			"koord-scheduler",
+			runtime.WithSnapshotSharedLister(newTestSharedLister(pods, []*corev1.Node{nodeInfo.Node()})),
+		)
+		assert.NoError(t, err)
+		p.handle = fh
+	}
+
+	pod := newNominatorTestPod("pod", 100, 10)
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	filterStatus := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+	assert.Equal(t, framework.Unschedulable, filterStatus.Code())
+	statusMap := framework.NodeToStatusMap{"test-node": filterStatus}
+
+	// the devices are not going to be released
+	setSnapshot(runningPod)
+	result, status := p.PostFilter(context.TODO(), cycleState, pod, statusMap)
+	assert.Nil(t, result)
+	assert.Equal(t, framework.Unschedulable, status.Code())
+	assert.Empty(t, p.nominator.nominatedAllocations)
+
+	// the node rejected by the other reasons is not nominated
+	terminatingPod := runningPod.DeepCopy()
+	terminatingPod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	setSnapshot(terminatingPod)
+	result, status = p.PostFilter(context.TODO(), cycleState, pod, framework.NodeToStatusMap{
+		"test-node": framework.NewStatus(framework.Unschedulable, "Insufficient cpu"),
+	})
+	assert.Nil(t, result)
+	assert.Equal(t, framework.Unschedulable, status.Code())
+
+	// the pod waits for the devices of the terminating pod
+	result, status = p.PostFilter(context.TODO(), cycleState, pod, statusMap)
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, "test-node", result.NominatedNodeName)
+	nominated := p.nominator.getNominatedAllocation(pod, "test-node")
+	assert.Len(t, nominated[schedulingv1alpha1.GPU], 1)
+	assert.Equal(t, int32(0), nominated[schedulingv1alpha1.GPU][0].Minor)
+
+	// the released devices are claimed for the nominated pod of higher priority
+	lowPod := newNominatorTestPod("low", 100, 0)
+	lowState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), lowState, lowPod).IsSuccess())
+	result, status = p.PostFilter(context.TODO(), lowState, lowPod, statusMap)
+	assert.Nil(t, result)
+	assert.Equal(t, framework.Unschedulable, status.Code())
+
+	// the pod falls through to the preemption after waiting too long for the terminating pod
+	p.nominator.waitingSince[pod.UID] = time.Now().Add(-maxTerminatingPodsWait)
+	result, status = p.PostFilter(context.TODO(), cycleState, pod, statusMap)
+	assert.Nil(t, result)
+	assert.Equal(t, framework.Unschedulable, status.Code())
+	assert.Nil(t, p.nominator.getNominatedAllocation(pod, "test-node"))
+
+	// the wait is forgotten once the pod is bound
+	boundPod := pod.DeepCopy()
+	boundPod.Spec.NodeName = "test-node"
+	p.nominator.onPodUpdate(pod, boundPod)
+	assert.NotContains(t, p.nominator.waitingSince, pod.UID)
+}
@@ -18,22 +18,34 @@ package deviceshare
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	schedulinglisters "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/metrics"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/reservation"
 	"github.com/koordinator-sh/koordinator/pkg/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
 const (
@@ -48,29 +60,154 @@ const (
 
 	// ErrInsufficientDevices when node can't satisfy Pod's requested resource.
 	ErrInsufficientDevices = "Insufficient Devices"
+
+	// ErrGPUVendorMismatch when the GPUs of node are not of the vendor required by Pod, e.g. amd.com/gpu.
+	ErrGPUVendorMismatch = "node(s) GPU vendor mismatch"
+
+	// ErrGPUMemoryNotReported when Pod requests GPU memory in bytes but the GPUs of node report no memory.
+	ErrGPUMemoryNotReported = "node(s) GPU memory not reported"
 )
 
 type Plugin struct {
 	handle          framework.Handle
 	nodeDeviceCache *nodeDeviceCache
 	allocator       Allocator
+	draTranslator   *draClaimTranslator
+	nominator       *deviceNominator
+	rLister         schedulinglisters.ReservationLister
+
+	allowFractionalMultiGPU      bool
+	podDeviceAllocationThreshold int32
 }
 
 var (
-	_ framework.PreFilterPlugin = &Plugin{}
-	_ framework.FilterPlugin    = &Plugin{}
-	_ framework.ReservePlugin   = &Plugin{}
-	_ framework.PreBindPlugin   = &Plugin{}
+	_ framework.PreFilterPlugin     = &Plugin{}
+	_ framework.PreFilterExtensions = &Plugin{}
+	_ framework.FilterPlugin        = &Plugin{}
+	_ framework.PostFilterPlugin    = &Plugin{}
+	_ framework.ScorePlugin         = &Plugin{}
+	_ framework.ReservePlugin       = &Plugin{}
+	_ framework.PreBindPlugin       = &Plugin{}
+)
+
+var (
+	// getMatchedReservationsOnNode and getAssumedReservation read the reservations from the state of the
+	// Reservation plugin, which are replaced in the tests.
+	getMatchedReservationsOnNode = reservation.GetMatchedReservationsOnNode
+	getAssumedReservation        = reservation.GetAssumedReservation
 )
 
 type preFilterState struct {
 	skip                    bool
 	allocationResult        apiext.DeviceAllocations
 	convertedDeviceResource corev1.ResourceList
+	draRequest              corev1.ResourceList
+	draClaims               []draClaim
+	// draAllocated is true once the claims are allocated for the pod in PreBind, which are released in Unreserve.
+	draAllocated bool
+	// gpuVendor is the GPU vendor required by the pod, empty means any vendor.
+	gpuVendor string
+	// nodeDeltas is the change of the device allocations on each node made by AddPod and RemovePod,
+	// i.e. the nominated pods and the preemption victims.
+	nodeDeltas map[string]*nodeDeviceDelta
+	// reservation is the reservation whose devices are inherited by the pod.
+	reservation *schedulingv1alpha1.Reservation
+	// filteredReservations are the reservations whose devices are allocated to the pod in Filter.
+	filteredReservations *filteredReservations
+}
+
+// filteredReservations records the reservation picked by Filter on each node, so that Reserve allocates the devices
+// from the same reservation. It is written by the Filters running in parallel.
+type filteredReservations struct {
+	lock         sync.RWMutex
+	reservations map[string]*schedulingv1alpha1.Reservation
+}
+
+func newFilteredReservations() *filteredReservations {
+	return &filteredReservations{
+		reservations: map[string]*schedulingv1alpha1.Reservation{},
+	}
+}
+
+func (f *filteredReservations) set(nodeName string, r *schedulingv1alpha1.Reservation) {
+	if f == nil {
+		return
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if r == nil {
+		delete(f.reservations, nodeName)
+		return
+	}
+	f.reservations[nodeName] = r
+}
+
+func (f *filteredReservations) get(nodeName string) *schedulingv1alpha1.Reservation {
+	if f == nil {
+		return nil
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.reservations[nodeName]
+}
+
+func (f *filteredReservations) clone() *filteredReservations {
+	if f == nil {
+		return nil
+	}
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	out := newFilteredReservations()
+	for nodeName, r := range f.reservations {
+		out.reservations[nodeName] = r
+	}
+	return out
 }
 
 func (s *preFilterState) Clone() framework.StateData {
-	return s
+	out := *s
+	out.filteredReservations = s.filteredReservations.clone()
+	out.nodeDeltas = nil
+	if len(s.nodeDeltas) > 0 {
+		out.nodeDeltas = make(map[string]*nodeDeviceDelta, len(s.nodeDeltas))
+		for nodeName, delta := range s.nodeDeltas {
+			out.nodeDeltas[nodeName] = delta.clone()
+		}
+	}
+	return &out
+}
+
+func (s *preFilterState) getNodeDelta(nodeName string) *nodeDeviceDelta {
+	if s.nodeDeltas == nil {
+		s.nodeDeltas = map[string]*nodeDeviceDelta{}
+	}
+	delta := s.nodeDeltas[nodeName]
+	if delta == nil {
+		delta = &nodeDeviceDelta{
+			added:            map[types.UID]apiext.DeviceAllocations{},
+			removed:          map[types.UID]apiext.DeviceAllocations{},
+			victimPriorities: map[types.UID]int32{},
+		}
+		s.nodeDeltas[nodeName] = delta
+	}
+	return delta
+}
+
+// applyNodeDelta returns the nodeDevice with the delta of the node applied. The lock of nodeDevice must be held.
+func (s *preFilterState) applyNodeDelta(nodeName string, nodeDeviceInfo *nodeDevice) *nodeDevice {
+	delta := s.nodeDeltas[nodeName]
+	if delta.isEmpty() {
+		return nodeDeviceInfo
+	}
+	added := make([]apiext.DeviceAllocations, 0, len(delta.added))
+	for _, allocations := range delta.added {
+		added = append(added, allocations)
+	}
+	removed := make([]apiext.DeviceAllocations, 0, len(delta.removed))
+	for _, allocations := range delta.removed {
+		removed = append(removed, allocations)
+	}
+	return nodeDeviceInfo.cloneWithAllocations(added, removed)
 }
 
 func (p *Plugin) Name() string {
@@ -78,12 +215,47 @@ func (p *Plugin) Name() string {
 }
 
 func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod) *framework.Status {
+	var state *preFilterState
+	var status *framework.Status
+	if dra := getDRAState(cycleState); dra != nil {
+		// the requests of the pod transformed by BeforePreFilter include the device resources of the claims
+		podRequest, _ := resource.PodRequestsAndLimits(pod)
+		state, status = p.preparePodRequest(pod, podRequest, dra.requests, dra.claims)
+	} else {
+		state, status = p.preparePod(pod)
+	}
+	if !status.IsSuccess() {
+		return status
+	}
+	cycleState.Write(stateKey, state)
+	return nil
+}
+
+// preparePod converts the device requests of the pod into the resources understood by the allocator.
+func (p *Plugin) preparePod(pod *corev1.Pod) (*preFilterState, *framework.Status) {
+	podRequest, _ := resource.PodRequestsAndLimits(pod)
+	var draRequest corev1.ResourceList
+	var draClaims []draClaim
+	if p.draTranslator != nil {
+		var err error
+		draRequest, draClaims, err = p.draTranslator.translate(pod)
+		if err != nil {
+			return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+		}
+		podRequest = quotav1.Add(podRequest, draRequest)
+	}
+	return p.preparePodRequest(pod, podRequest, draRequest, draClaims)
+}
+
+// preparePodRequest converts the device requests of the pod, which include the ones translated from the claims.
+func (p *Plugin) preparePodRequest(pod *corev1.Pod, podRequest, draRequest corev1.ResourceList, draClaims []draClaim) (*preFilterState, *framework.Status) {
 	state := &preFilterState{
 		skip:                    true,
 		convertedDeviceResource: make(corev1.ResourceList),
+		draRequest:              draRequest,
+		draClaims:               draClaims,
+		filteredReservations:    newFilteredReservations(),
 	}
-
-	podRequest, _ := resource.PodRequestsAndLimits(pod)
 	podRequest = apiext.TransformDeprecatedDeviceResources(podRequest)
 
 	for deviceType := range DeviceResourceNames {
@@ -92,21 +264,28 @@ func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState
 			if !hasDeviceResource(podRequest, deviceType) {
 				break
 			}
-			combination, err := ValidateGPURequest(podRequest)
+			var combination uint
+			var err error
+			if p.allowFractionalMultiGPU {
+				combination, err = validateGPURequest(podRequest, true)
+			} else {
+				combination, err = ValidateGPURequest(podRequest)
+			}
 			if err != nil {
-				return framework.NewStatus(framework.Error, err.Error())
+				return nil, framework.NewStatus(framework.Error, err.Error())
 			}
 			state.convertedDeviceResource = quotav1.Add(
 				state.convertedDeviceResource,
 				ConvertGPUResource(podRequest, combination),
 			)
+			state.gpuVendor = getRequiredGPUVendor(combination)
 			state.skip = false
-		case schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA:
+		case schedulingv1alpha1.RDMA, schedulingv1alpha1.FPGA, schedulingv1alpha1.NIC:
 			if !hasDeviceResource(podRequest, deviceType) {
 				break
 			}
 			if err := validateCommonDeviceRequest(podRequest, deviceType); err != nil {
-				return framework.NewStatus(framework.Error, err.Error())
+				return nil, framework.NewStatus(framework.Error, err.Error())
 			}
 			state.convertedDeviceResource = quotav1.Add(
 				state.convertedDeviceResource,
@@ -117,15 +296,102 @@ func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState
 			klog.Warningf("device type %v is not supported yet, pod: %v", deviceType, klog.KObj(pod))
 		}
 	}
+	return state, nil
+}
 
-	cycleState.Write(stateKey, state)
+func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
+	return p
+}
+
+// AddPod accounts the devices of the pod added to the node, which is either a preemption victim added back
+// or a pod nominated to the node. The devices of the nominated pod are recorded in the nominator,
+// so that the same devices are claimed for it in the subsequent scheduling cycles.
+func (p *Plugin) AddPod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *corev1.Pod, podInfoToAdd *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return status
+	}
+	if state.skip || nodeInfo.Node() == nil {
+		return nil
+	}
+	nodeName := nodeInfo.Node().Name
+	podToAdd := podInfoToAdd.Pod
+	delta := state.getNodeDelta(nodeName)
+	if _, ok := delta.removed[podToAdd.UID]; ok {
+		delete(delta.removed, podToAdd.UID)
+		return nil
+	}
+	if podToAdd.Spec.NodeName != "" {
+		// the devices of the assigned pods are already accounted in the cache.
+		return nil
+	}
+
+	allocations := p.getNominatedAllocation(podToAdd, nodeName)
+	if allocations == nil {
+		podState, status := p.preparePod(podToAdd)
+		if !status.IsSuccess() || podState.skip {
+			return nil
+		}
+		nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+		if nodeDeviceInfo == nil {
+			return nil
+		}
+		nodeDeviceInfo.lock.RLock()
+		allocateResult, err := p.allocator.Allocate(nodeName, podToAdd, podState.convertedDeviceResource, state.applyNodeDelta(nodeName, nodeDeviceInfo))
+		nodeDeviceInfo.lock.RUnlock()
+		if err != nil || len(allocateResult) == 0 {
+			klog.V(5).InfoS("failed to allocate devices for nominated pod", "pod", klog.KObj(podToAdd), "node", nodeName, "err", err)
+			return nil
+		}
+		allocations = allocateResult
+		if p.nominator != nil {
+			p.nominator.addNominatedAllocation(podToAdd, nodeName, allocations)
+		}
+	}
+	delta.added[podToAdd.UID] = allocations
 	return nil
 }
 
-func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
+// RemovePod releases the devices of the pod removed from the node, e.g. a preemption victim.
+func (p *Plugin) RemovePod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *corev1.Pod, podInfoToRemove *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return status
+	}
+	if state.skip || nodeInfo.Node() == nil {
+		return nil
+	}
+	nodeName := nodeInfo.Node().Name
+	podToRemove := podInfoToRemove.Pod
+	delta := state.getNodeDelta(nodeName)
+	if _, ok := delta.added[podToRemove.UID]; ok {
+		delete(delta.added, podToRemove.UID)
+		return nil
+	}
+	if podToRemove.Spec.NodeName == "" {
+		return nil
+	}
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+	if nodeDeviceInfo == nil {
+		return nil
+	}
+	nodeDeviceInfo.lock.RLock()
+	allocations := nodeDeviceInfo.getPodAllocations(podToRemove)
+	nodeDeviceInfo.lock.RUnlock()
+	if len(allocations) > 0 {
+		delta.removed[podToRemove.UID] = allocations
+		delta.victimPriorities[podToRemove.UID] = corev1helpers.PodPriority(podToRemove)
+	}
 	return nil
 }
 
+func (p *Plugin) getNominatedAllocation(pod *corev1.Pod, nodeName string) apiext.DeviceAllocations {
+	if p.nominator == nil {
+		return nil
+	}
+	return p.nominator.getNominatedAllocation(pod, nodeName)
+}
+
 func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, *framework.Status) {
 	value, err := cycleState.Read(stateKey)
 	if err != nil {
@@ -135,7 +401,12 @@ func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, *fram
 	return state, nil
 }
 
-func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) (status *framework.Status) {
+	if node := nodeInfo.Node(); node != nil {
+		if status := frameworkext.CheckNodeSchedulingPaused(node); !status.IsSuccess() {
+			return status
+		}
+	}
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -144,6 +415,11 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 		return nil
 	}
 
+	startTime := time.Now()
+	defer func() {
+		recordPluginDuration(extensionPointFilter, startTime, status)
+	}()
+
 	if nodeInfo.Node() == nil {
 		return framework.NewStatus(framework.Error, "node not found")
 	}
@@ -158,15 +434,126 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	nodeDeviceInfo.lock.RLock()
 	defer nodeDeviceInfo.lock.RUnlock()
 
-	allocateResult, err := p.allocator.Allocate(nodeInfo.Node().Name, pod, podRequest, nodeDeviceInfo)
+	if !matchGPUVendor(state.gpuVendor, nodeDeviceInfo.gpuVendor) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrGPUVendorMismatch)
+	}
+
+	candidate := state.applyNodeDelta(nodeInfo.Node().Name, nodeDeviceInfo)
+	if delta := state.nodeDeltas[nodeInfo.Node().Name]; delta != nil && len(delta.removed) > 0 &&
+		hasDeviceResource(podRequest, schedulingv1alpha1.GPU) {
+		// the preemption is simulating, the victims are reprieved only if the GPUs reclaimed for the pod are kept
+		if delta.preemptionGPUs == nil {
+			delta.preemptionGPUs = p.selectPreemptionGPUs(nodeInfo.Node().Name, pod, podRequest, delta, candidate)
+		}
+		candidate = candidate.restrictGPUs(delta.preemptionGPUs)
+	}
+	allocateResult, err := p.allocator.Allocate(nodeInfo.Node().Name, pod, podRequest, candidate)
 	if len(allocateResult) != 0 && err == nil {
+		state.filteredReservations.set(nodeInfo.Node().Name, nil)
+		return nil
+	}
+	// the pod can consume the devices held by the reservations it matches
+	reservations := getMatchedReservationsOnNode(cycleState, nodeInfo.Node().Name)
+	allocateResult, r := tryAllocateFromReservations(pod, podRequest, candidate, reservations)
+	state.filteredReservations.set(nodeInfo.Node().Name, r)
+	if len(allocateResult) != 0 {
 		return nil
 	}
+	if errors.Is(err, errGPUMemoryNotReported) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrGPUMemoryNotReported)
+	}
 
 	return framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices)
 }
 
-func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+// PostFilter nominates the pod rejected only for the insufficient devices to the node on which the terminating pods
+// will release enough devices for it, e.g. the workers of a large job just completed. The devices are claimed for
+// the pod in the nominator like a preemptor waiting for the victims, so that they are not taken by the other pods
+// before the pod is retried. The wait is bounded by maxTerminatingPodsWait, after which the pod falls through to
+// the preemption.
+func (p *Plugin) PostFilter(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() || state.skip || p.nominator == nil {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	if !p.nominator.waitForTerminatingPods(pod) {
+		p.nominator.removeNominatedAllocation(pod)
+		klog.V(4).InfoS("pod waited too long for the terminating pods releasing devices", "pod", klog.KObj(pod))
+		return nil, framework.NewStatus(framework.Unschedulable, "waited too long for the terminating pods releasing devices")
+	}
+	nodeNames := make([]string, 0, len(filteredNodeStatusMap))
+	for nodeName, nodeStatus := range filteredNodeStatusMap {
+		if nodeStatus.Code() == framework.Unschedulable && len(nodeStatus.Reasons()) == 1 &&
+			nodeStatus.Reasons()[0] == ErrInsufficientDevices {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+	sort.Strings(nodeNames)
+	for _, nodeName := range nodeNames {
+		allocations := p.allocateFromTerminatingPods(pod, state.convertedDeviceResource, nodeName)
+		if len(allocations) == 0 {
+			continue
+		}
+		p.nominator.addNominatedAllocation(pod, nodeName, allocations)
+		klog.V(4).InfoS("nominate pod to the node with terminating pods releasing devices", "pod", klog.KObj(pod), "node", nodeName)
+		return &framework.PostFilterResult{NominatedNodeName: nodeName}, framework.NewStatus(framework.Success)
+	}
+	return nil, framework.NewStatus(framework.Unschedulable, "no terminating pods releasing enough devices")
+}
+
+// Score prefers the nodes whose GPUs allocated to the pod are physically less loaded,
+// according to the GPU usage reported by koordlet through NodeMetric.
+// With the MinFragmentation scoring strategy, it prefers the nodes on which the allocation leaves the least fragments.
+// The nodes on which the pod can reuse the GPUs previously allocated to the pods of the same controller are always
+// preferred to benefit from the warm GPU state.
+func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	state, status := getPreFilterState(cycleState)
+	if !status.IsSuccess() {
+		return 0, status
+	}
+	if state.skip || !hasDeviceResource(state.convertedDeviceResource, schedulingv1alpha1.GPU) {
+		return 0, nil
+	}
+
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+	if nodeDeviceInfo == nil {
+		return 0, nil
+	}
+
+	nodeDeviceInfo.lock.RLock()
+	defer nodeDeviceInfo.lock.RUnlock()
+
+	allocateResult, err := p.allocator.Allocate(nodeName, pod, state.convertedDeviceResource, nodeDeviceInfo)
+	if err != nil || len(allocateResult[schedulingv1alpha1.GPU]) == 0 {
+		return 0, nil
+	}
+	if nodeDeviceInfo.reusePreviousGPUs(pod, allocateResult[schedulingv1alpha1.GPU]) {
+		return framework.MaxNodeScore, nil
+	}
+	if nodeDeviceInfo.minimizeGPUFragmentation || isGPUCompactionPod(pod) {
+		return nodeDeviceInfo.scoreGPUFragmentation(allocateResult[schedulingv1alpha1.GPU]), nil
+	}
+
+	loads := nodeDeviceInfo.getGPULoads()
+	if loads == nil {
+		return 0, nil
+	}
+	var totalLoad int64
+	for _, allocation := range allocateResult[schedulingv1alpha1.GPU] {
+		totalLoad += loads[int(allocation.Minor)]
+	}
+	score := framework.MaxNodeScore - totalLoad/int64(len(allocateResult[schedulingv1alpha1.GPU]))
+	if score < framework.MinNodeScore {
+		score = framework.MinNodeScore
+	}
+	return score, nil
+}
+
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (status *framework.Status) {
 	state, status := getPreFilterState(cycleState)
 	if !status.IsSuccess() {
 		return status
@@ -175,6 +562,11 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		return nil
 	}
 
+	startTime := time.Now()
+	defer func() {
+		recordPluginDuration(extensionPointReserve, startTime, status)
+	}()
+
 	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
 	if nodeDeviceInfo == nil {
 		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrMissingDevice)
@@ -185,11 +577,44 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	nodeDeviceInfo.lock.Lock()
 	defer nodeDeviceInfo.lock.Unlock()
 
-	allocateResult, err := p.allocator.Allocate(nodeName, pod, podRequest, nodeDeviceInfo)
-	if err != nil || len(allocateResult) == 0 {
-		return framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices)
+	// the devices claimed by the nominated pods are not allocated to the pod of lower priority.
+	candidate := nodeDeviceInfo
+	if p.nominator != nil {
+		if nominated := p.nominator.getNominatedAllocationsForNode(pod, nodeName); len(nominated) > 0 {
+			candidate = nodeDeviceInfo.cloneWithAllocations(nominated, nil)
+		}
+	}
+	// the pod inherits the devices held by the reservation picked by Filter, which may not be the one assumed by
+	// the Reservation plugin since the latter does not check the devices.
+	var reservations []*schedulingv1alpha1.Reservation
+	if r := state.filteredReservations.get(nodeName); r != nil {
+		reservations = append(reservations, r)
+	}
+	if r := getAssumedReservation(cycleState); r != nil && (len(reservations) == 0 || reservations[0].UID != r.UID) {
+		reservations = append(reservations, r)
+	}
+	var allocateResult apiext.DeviceAllocations
+	var err error
+	if len(reservations) > 0 {
+		var r *schedulingv1alpha1.Reservation
+		allocateResult, r = tryAllocateFromReservations(pod, podRequest, candidate, reservations)
+		if len(allocateResult) != 0 {
+			// only the devices consumed by the pod are released, the remainder is kept for the other owners
+			nodeDeviceInfo.assumeReservationConsumer(r.UID, pod, allocateResult)
+			state.reservation = r
+		}
+	}
+	if len(allocateResult) == 0 {
+		allocateResult, err = p.allocator.Allocate(nodeName, pod, podRequest, candidate)
+		if err != nil || len(allocateResult) == 0 {
+			return framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices)
+		}
 	}
 	p.allocator.Reserve(pod, nodeDeviceInfo, allocateResult)
+	nodeDeviceInfo.recordMetrics(nodeName)
+	if p.nominator != nil {
+		p.nominator.removeNominatedAllocation(pod)
+	}
 
 	state.allocationResult = allocateResult
 	return nil
@@ -203,6 +628,10 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	if state.skip {
 		return
 	}
+	if state.draAllocated {
+		p.draTranslator.deallocate(ctx, pod, state.draClaims)
+		state.draAllocated = false
+	}
 
 	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
 	if nodeDeviceInfo == nil {
@@ -213,6 +642,12 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	defer nodeDeviceInfo.lock.Unlock()
 
 	p.allocator.Unreserve(pod, nodeDeviceInfo, state.allocationResult)
+	if state.reservation != nil {
+		// give the devices back to the reservation
+		nodeDeviceInfo.forgetReservationConsumer(state.reservation.UID, pod)
+		state.reservation = nil
+	}
+	nodeDeviceInfo.recordMetrics(nodeName)
 	state.allocationResult = nil
 }
 
@@ -226,8 +661,31 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	}
 
 	allocResult := state.allocationResult
+	if reservationutil.IsReservePod(pod) {
+		return p.preBindReservation(ctx, pod, allocResult)
+	}
+
+	// the claims are allocated before the devices are recorded on the pod, so that the pod is never bound with
+	// the devices whose claims failed to allocate. The claims partially allocated are released in Unreserve.
 	newPod := pod.DeepCopy()
-	if err := apiext.SetDeviceAllocations(newPod, allocResult); err != nil {
+	if len(state.draClaims) > 0 {
+		state.draAllocated = true
+		if err := p.draTranslator.allocate(ctx, pod, nodeName, state.draClaims, allocResult); err != nil {
+			return framework.NewStatus(framework.Error, err.Error())
+		}
+		if err := apiext.SetDRADeviceResources(newPod, state.draRequest); err != nil {
+			return framework.NewStatus(framework.Error, err.Error())
+		}
+	}
+
+	podDeviceAllocations := p.nodeDeviceCache.podDeviceAllocations
+	useRef := podDeviceAllocations != nil && p.podDeviceAllocationThreshold > 0 &&
+		apiext.CountDeviceAllocations(allocResult) >= int(p.podDeviceAllocationThreshold)
+	if useRef {
+		if err := podDeviceAllocations.save(ctx, newPod, nodeName, allocResult); err != nil {
+			return framework.NewStatus(framework.Error, err.Error())
+		}
+	} else if err := apiext.SetDeviceAllocations(newPod, allocResult); err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
@@ -250,12 +708,131 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 		return podErr
 	})
 	if err != nil {
+		if useRef {
+			podDeviceAllocations.delete(ctx, newPod)
+		}
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
 	return nil
 }
 
+// preBindReservation records the devices allocated to the reserve pod in the annotation of the reservation,
+// so that the devices are held by the reservation and inherited by the owner pods.
+func (p *Plugin) preBindReservation(ctx context.Context, reservePod *corev1.Pod, allocResult apiext.DeviceAllocations) *framework.Status {
+	if p.rLister == nil {
+		return framework.NewStatus(framework.Error, "reservation lister not found")
+	}
+	rName := reservationutil.GetReservationNameFromReservePod(reservePod)
+	r, err := p.rLister.Get(rName)
+	if err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	data, err := json.Marshal(allocResult)
+	if err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	err = util.RetryOnConflictOrTooManyRequests(func() error {
+		_, patchErr := util.NewPatch().WithHandle(p.handle).
+			AddAnnotations(map[string]string{apiext.AnnotationDeviceAllocated: string(data)}).
+			PatchReservation(r)
+		return patchErr
+	})
+	if err != nil {
+		klog.V(4).InfoS("failed to patch device allocations of reservation", "reservation", klog.KObj(r), "err", err)
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	return nil
+}
+
+// tryAllocateFromReservations tries to allocate the devices for the pod from the devices held by the reservations.
+// The pod inherits the exact devices of the reservation if it requests the same devices as the reservation,
+// otherwise the devices reserved are preferred. It returns the allocations and the reservation allocated.
+// The lock of nodeDevice must be held.
+func tryAllocateFromReservations(pod *corev1.Pod, podRequest corev1.ResourceList, nodeDeviceInfo *nodeDevice,
+	reservations []*schedulingv1alpha1.Reservation) (apiext.DeviceAllocations, *schedulingv1alpha1.Reservation) {
+	for _, r := range reservations {
+		reservationAllocations := nodeDeviceInfo.getReservationAllocations(r)
+		if len(reservationAllocations) == 0 {
+			continue
+		}
+		candidate := nodeDeviceInfo.cloneWithAllocations(nil, []apiext.DeviceAllocations{reservationAllocations})
+		excludedGPUs := candidate.getExcludedGPUs(pod)
+		if allocateResult := inheritReservationAllocations(podRequest, reservationAllocations, excludedGPUs); len(allocateResult) != 0 {
+			return allocateResult, r
+		}
+		preferredGPUs := sets.NewInt()
+		for _, allocation := range reservationAllocations[schedulingv1alpha1.GPU] {
+			preferredGPUs.Insert(int(allocation.Minor))
+		}
+		allocateResult, err := candidate.tryAllocateDeviceWithPreferredGPUs(podRequest, preferredGPUs, excludedGPUs)
+		if err == nil && len(allocateResult) != 0 {
+			return allocateResult, r
+		}
+	}
+	return nil, nil
+}
+
+// inheritReservationAllocations returns the devices of the reservation for the device types requested by the pod
+// if the pod requests exactly the devices held by the reservation, otherwise it returns nil.
+// The excluded GPUs of the reservation are never inherited.
+func inheritReservationAllocations(podRequest corev1.ResourceList, reservationAllocations apiext.DeviceAllocations, excludedGPUs sets.Int) apiext.DeviceAllocations {
+	var result apiext.DeviceAllocations
+	for deviceType, resourceNames := range DeviceResourceNames {
+		request := quotav1.Mask(podRequest, resourceNames)
+		if quotav1.IsZero(request) {
+			continue
+		}
+		var inherited []*apiext.DeviceAllocation
+		var reserved corev1.ResourceList
+		for _, allocation := range reservationAllocations[deviceType] {
+			if deviceType == schedulingv1alpha1.GPU && excludedGPUs.Has(int(allocation.Minor)) {
+				continue
+			}
+			inherited = append(inherited, allocation)
+			reserved = quotav1.Add(reserved, allocation.Resources)
+		}
+		if !quotav1.Equals(request, quotav1.Mask(reserved, quotav1.ResourceNames(request))) {
+			return nil
+		}
+		if result == nil {
+			result = apiext.DeviceAllocations{}
+		}
+		result[deviceType] = inherited
+	}
+	return result
+}
+
+// SimulateAllocate returns the devices that would be allocated to the pod on the node
+// without reserving them. It is intended for capacity planning and feasibility checks,
+// and a nil result without error means the pod does not request any device.
+func (p *Plugin) SimulateAllocate(pod *corev1.Pod, nodeName string) (apiext.DeviceAllocations, error) {
+	state, status := p.preparePod(pod)
+	if !status.IsSuccess() {
+		return nil, status.AsError()
+	}
+	if state.skip {
+		return nil, nil
+	}
+
+	nodeDeviceInfo := p.nodeDeviceCache.getNodeDevice(nodeName)
+	if nodeDeviceInfo == nil {
+		return nil, errors.New(ErrMissingDevice)
+	}
+
+	nodeDeviceInfo.lock.RLock()
+	defer nodeDeviceInfo.lock.RUnlock()
+
+	allocateResult, err := p.allocator.Allocate(nodeName, pod, state.convertedDeviceResource, nodeDeviceInfo)
+	if err != nil {
+		return nil, err
+	}
+	if len(allocateResult) == 0 {
+		return nil, errors.New(ErrInsufficientDevices)
+	}
+	return allocateResult, nil
+}
+
 func (p *Plugin) getNodeDeviceSummary(nodeName string) (*NodeDeviceSummary, bool) {
 	return p.nodeDeviceCache.getNodeDeviceSummary(nodeName)
 }
@@ -269,15 +846,30 @@ func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type DeviceShareArgs, got %T", obj)
 	}
+	if err := validation.ValidateDeviceShareArgs(args); err != nil {
+		return nil, err
+	}
 
 	extendedHandle, ok := handle.(frameworkext.ExtendedHandle)
 	if !ok {
 		return nil, fmt.Errorf("expect handle to be type frameworkext.ExtendedHandle, got %T", handle)
 	}
 
+	metrics.Register()
+
 	deviceCache := newNodeDeviceCache()
+	deviceCache.defaultGPUCoreOversellPercent = args.GPUCoreOversellPercent
+	deviceCache.minimizeGPUFragmentation = args.ScoringStrategy == config.DeviceMinFragmentation
+	deviceCache.defaultMaxPodsPerGPU = args.MaxPodsPerGPU
+	deviceCache.onDevicesRemoved = newDevicesRemovedEventRecorder(handle.SharedInformerFactory().Core().V1().Pods().Lister(), handle.EventRecorder())
 	registerDeviceEventHandler(deviceCache, extendedHandle.KoordinatorSharedInformerFactory())
+	registerNodeEventHandler(deviceCache, handle.SharedInformerFactory())
+	registerNodeMetricEventHandler(deviceCache, extendedHandle.KoordinatorSharedInformerFactory())
+	deviceCache.podDeviceAllocations = newPodDeviceAllocationStore(extendedHandle.KoordinatorClientSet(), extendedHandle.KoordinatorSharedInformerFactory(), args.PodDeviceAllocationThreshold > 0)
 	registerPodEventHandler(deviceCache, handle.SharedInformerFactory())
+	registerReservationEventHandler(deviceCache, extendedHandle.KoordinatorSharedInformerFactory())
+	nominator := newDeviceNominator()
+	registerNominatorEventHandler(nominator, handle.SharedInformerFactory())
 
 	allocatorOpts := AllocatorOptions{
 		SharedInformerFactory:      extendedHandle.SharedInformerFactory(),
@@ -285,9 +877,24 @@ func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	}
 	allocator := NewAllocator(args.Allocator, allocatorOpts)
 
+	var draTranslator *draClaimTranslator
+	if args.EnableDRACompatibility {
+		var err error
+		draTranslator, err = newDRAClaimTranslatorFromHandle(handle)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &Plugin{
 		handle:          handle,
 		nodeDeviceCache: deviceCache,
 		allocator:       allocator,
+		draTranslator:   draTranslator,
+		nominator:       nominator,
+		rLister:         extendedHandle.KoordinatorSharedInformerFactory().Scheduling().V1alpha1().Reservations().Lister(),
+
+		allowFractionalMultiGPU:      args.AllowFractionalMultiGPU,
+		podDeviceAllocationThreshold: args.PodDeviceAllocationThreshold,
 	}, nil
 }
@@ -20,6 +20,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/services"
 )
@@ -40,4 +41,18 @@ func (p *Plugin) RegisterEndpoints(group *gin.RouterGroup) {
 		}
 		c.JSON(http.StatusOK, nodeDeviceSummary)
 	})
+	group.POST("/simulateAllocation/:name", func(c *gin.Context) {
+		nodeName := c.Param("name")
+		pod := &corev1.Pod{}
+		if err := c.ShouldBindJSON(pod); err != nil {
+			services.ResponseErrorMessage(c, http.StatusBadRequest, "invalid pod: %v", err)
+			return
+		}
+		allocations, err := p.SimulateAllocate(pod, nodeName)
+		if err != nil {
+			services.ResponseErrorMessage(c, http.StatusConflict, "cannot allocate devices on node %s: %v", nodeName, err)
+			return
+		}
+		c.JSON(http.StatusOK, allocations)
+	})
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package deviceshare
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -235,3 +236,95 @@ func TestEndpointsQueryNodeDeviceSummary(t *testing.T) {
 	}
 	assert.True(t, apiequality.Semantic.DeepEqual(nodeDeviceSummary["node1"].AllocateSet, nodeDeviceSummaryExpect["node1"].AllocateSet))
 }
+
+func TestEndpointsSimulateAllocation(t *testing.T) {
+	suit := newPluginTestSuit(t, nil)
+	p, err := suit.proxyNew(&config.DeviceShareArgs{}, suit.Framework)
+	assert.NotNil(t, p)
+	assert.Nil(t, err)
+
+	ds := p.(*Plugin)
+	ds.nodeDeviceCache.onDeviceAdd(&schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+		},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	})
+
+	engine := gin.Default()
+	ds.RegisterEndpoints(engine.Group("/"))
+
+	newRequest := func(nodeName string, gpuCount int64) *http.Request {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "pod1",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								apiext.ResourceNvidiaGPU: *resource.NewQuantity(gpuCount, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+		}
+		data, err := json.Marshal(pod)
+		assert.NoError(t, err)
+		req, _ := http.NewRequest("POST", "/simulateAllocation/"+nodeName, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newRequest("node1", 1))
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	allocations := apiext.DeviceAllocations{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &allocations))
+	expectAllocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{
+				Minor: 0,
+				Resources: corev1.ResourceList{
+					apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+					apiext.ResourceGPUMemory:      *resource.NewQuantity(0, resource.DecimalSI),
+					apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+				},
+			},
+		},
+	}
+	assert.True(t, apiequality.Semantic.DeepEqual(expectAllocations, allocations))
+
+	// simulation must not reserve the devices
+	summary, ok := ds.getNodeDeviceSummary("node1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(100), summary.DeviceFree[apiext.ResourceGPUCore].Value())
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newRequest("node1", 2))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, newRequest("node2", 1))
+	assert.Equal(t, http.StatusConflict, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/simulateAllocation/node1", bytes.NewReader([]byte("{")))
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -104,6 +105,7 @@ func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
 
 type pluginTestSuit struct {
 	framework.Framework
+	koordClientSet                   *koordfake.Clientset
 	koordinatorSharedInformerFactory koordinatorinformers.SharedInformerFactory
 	proxyNew                         runtime.PluginFactory
 }
@@ -136,6 +138,7 @@ func newPluginTestSuit(t *testing.T, nodes []*corev1.Node) *pluginTestSuit {
 	assert.Nil(t, err)
 	return &pluginTestSuit{
 		Framework:                        fh,
+		koordClientSet:                   koordClientSet,
 		koordinatorSharedInformerFactory: koordSharedInformerFactory,
 		proxyNew:                         proxyNew,
 	}
@@ -189,7 +192,7 @@ func Test_New(t *testing.T) {
 func Test_Plugin_PreFilterExtensions(t *testing.T) {
 	t.Run("test not panic", func(t *testing.T) {
 		p := &Plugin{}
-		assert.Nil(t, nil, p.PreFilterExtensions())
+		assert.Equal(t, p, p.PreFilterExtensions())
 	})
 }
 
@@ -368,6 +371,10 @@ func Test_Plugin_Filter(t *testing.T) {
 	}
 	testNodeInfo := &framework.NodeInfo{}
 	testNodeInfo.SetNode(testNode)
+	pausedNode := testNode.DeepCopy()
+	pausedNode.Annotations = map[string]string{apiext.AnnotationNodeSchedulingPaused: "true"}
+	pausedNodeInfo := &framework.NodeInfo{}
+	pausedNodeInfo.SetNode(pausedNode)
 	tests := []struct {
 		name            string
 		state           *preFilterState
@@ -381,6 +388,13 @@ func Test_Plugin_Filter(t *testing.T) {
 			pod:  &corev1.Pod{},
 			want: framework.AsStatus(framework.ErrNotFound),
 		},
+		{
+			name:     "node with scheduling paused",
+			state:    &preFilterState{skip: true},
+			pod:      &corev1.Pod{},
+			nodeInfo: pausedNodeInfo,
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, frameworkext.ErrReasonNodeSchedulingPaused),
+		},
 		{
 			name:  "skip == true",
 			state: &preFilterState{skip: true},
@@ -420,6 +434,25 @@ func Test_Plugin_Filter(t *testing.T) {
 			nodeInfo: testNodeInfo,
 			want:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
 		},
+		{
+			name: "GPU vendor mismatch",
+			state: &preFilterState{
+				skip: false,
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.ResourceGPUCore:        resource.MustParse("100"),
+					apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+				},
+				gpuVendor: apiext.GPUVendorAMD,
+			},
+			pod: &corev1.Pod{},
+			nodeDeviceCache: &nodeDeviceCache{
+				nodeDeviceInfos: map[string]*nodeDevice{
+					"test-node": newNodeDevice(),
+				},
+			},
+			nodeInfo: testNodeInfo,
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrGPUVendorMismatch),
+		},
 		{
 			name: "insufficient device resource 2",
 			state: &preFilterState{
@@ -466,6 +499,42 @@ func Test_Plugin_Filter(t *testing.T) {
 			nodeInfo: testNodeInfo,
 			want:     framework.NewStatus(framework.Unschedulable, ErrInsufficientDevices),
 		},
+		{
+			name: "GPU memory not reported",
+			state: &preFilterState{
+				skip: false,
+				convertedDeviceResource: corev1.ResourceList{
+					apiext.ResourceGPUCore:   resource.MustParse("100"),
+					apiext.ResourceGPUMemory: resource.MustParse("16Gi"),
+				},
+			},
+			pod: &corev1.Pod{},
+			nodeDeviceCache: &nodeDeviceCache{
+				nodeDeviceInfos: map[string]*nodeDevice{
+					"test-node": {
+						deviceFree: map[schedulingv1alpha1.DeviceType]deviceResources{
+							schedulingv1alpha1.GPU: {
+								0: corev1.ResourceList{
+									apiext.ResourceGPUCore:        resource.MustParse("100"),
+									apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+								},
+							},
+						},
+						deviceTotal: map[schedulingv1alpha1.DeviceType]deviceResources{
+							schedulingv1alpha1.GPU: {
+								0: corev1.ResourceList{
+									apiext.ResourceGPUCore:        resource.MustParse("100"),
+									apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+								},
+							},
+						},
+						deviceUsed: map[schedulingv1alpha1.DeviceType]deviceResources{},
+					},
+				},
+			},
+			nodeInfo: testNodeInfo,
+			want:     framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrGPUMemoryNotReported),
+		},
 		{
 			name: "insufficient device resource 3",
 			state: &preFilterState{
@@ -781,6 +850,119 @@ func Test_Plugin_Filter(t *testing.T) {
 	}
 }
 
+func newGPUUsageTestNodeDevice(loads ...int64) *nodeDevice {
+	nd := newNodeDevice()
+	total := deviceResources{}
+	nd.gpuUsage = deviceResources{}
+	for i, load := range loads {
+		total[i] = corev1.ResourceList{
+			apiext.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+			apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+		}
+		nd.gpuUsage[i] = corev1.ResourceList{
+			apiext.ResourceGPUCore:        *resource.NewQuantity(load, resource.DecimalSI),
+			apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(load/2, resource.DecimalSI),
+		}
+	}
+	nd.resetDeviceTotal(map[schedulingv1alpha1.DeviceType]deviceResources{schedulingv1alpha1.GPU: total})
+	nd.gpuUsageUpdateTime = time.Now()
+	return nd
+}
+
+func Test_Plugin_Score(t *testing.T) {
+	sharedGPURequest := corev1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+	}
+	expiredNodeDevice := newGPUUsageTestNodeDevice(10)
+	expiredNodeDevice.gpuUsageUpdateTime = time.Now().Add(-gpuUsageExpiration)
+	tests := []struct {
+		name       string
+		state      *preFilterState
+		nodeDevice *nodeDevice
+		wantScore  int64
+	}{
+		{
+			name:       "skip pods without devices",
+			state:      &preFilterState{skip: true},
+			nodeDevice: newGPUUsageTestNodeDevice(10),
+			wantScore:  0,
+		},
+		{
+			name:       "score by the least loaded GPU",
+			state:      &preFilterState{convertedDeviceResource: sharedGPURequest},
+			nodeDevice: newGPUUsageTestNodeDevice(80, 30, 60),
+			wantScore:  70,
+		},
+		{
+			name: "score by the average load of allocated GPUs",
+			state: &preFilterState{convertedDeviceResource: corev1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(200, resource.DecimalSI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(200, resource.DecimalSI),
+			}},
+			nodeDevice: newGPUUsageTestNodeDevice(80, 30, 60),
+			wantScore:  55,
+		},
+		{
+			name:       "expired GPU usage",
+			state:      &preFilterState{convertedDeviceResource: sharedGPURequest},
+			nodeDevice: expiredNodeDevice,
+			wantScore:  0,
+		},
+		{
+			name:       "missing GPU usage",
+			state:      &preFilterState{convertedDeviceResource: sharedGPURequest},
+			nodeDevice: newGPUUsageTestNodeDevice(),
+			wantScore:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newNodeDeviceCache()
+			cache.nodeDeviceInfos["test-node"] = tt.nodeDevice
+			p := &Plugin{nodeDeviceCache: cache, allocator: &defaultAllocator{}}
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, tt.state)
+			score, status := p.Score(context.TODO(), cycleState, &corev1.Pod{}, "test-node")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantScore, score)
+		})
+	}
+}
+
+func Test_Plugin_Score_previousGPUs(t *testing.T) {
+	controller := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "test", UID: "sts-1", Controller: &controller},
+			},
+		},
+	}
+	nd := newGPUUsageTestNodeDevice(80, 30, 60)
+	cache := newNodeDeviceCache()
+	cache.nodeDeviceInfos["test-node"] = nd
+	p := &Plugin{nodeDeviceCache: cache, allocator: &defaultAllocator{}}
+	cycleState := framework.NewCycleState()
+	cycleState.Write(stateKey, &preFilterState{convertedDeviceResource: corev1.ResourceList{
+		apiext.ResourceGPUCore:        *resource.NewQuantity(50, resource.DecimalSI),
+		apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(50, resource.DecimalSI),
+	}})
+
+	score, status := p.Score(context.TODO(), cycleState, pod, "test-node")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(70), score)
+
+	// reuse the GPU previously allocated to the pod of the same controller
+	nd.recordPreviousGPUs(pod, []*apiext.DeviceAllocation{{Minor: 0}})
+	score, status = p.Score(context.TODO(), cycleState, pod, "test-node")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, framework.MaxNodeScore, score)
+}
+
 func Test_Plugin_Reserve(t *testing.T) {
 	type args struct {
 		nodeDeviceCache *nodeDeviceCache
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordinatorclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	schedulinglisters "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// podDeviceAllocationStore stores the devices allocated by the pods in PodDeviceAllocations, which keeps the pod
+// annotations small for the pods allocating dozens of devices, e.g. 8 GPUs with 8 RDMA VFs.
+type podDeviceAllocationStore struct {
+	lister schedulinglisters.PodDeviceAllocationLister
+	client koordinatorclientset.Interface
+
+	lock sync.Mutex
+	// allocations are the devices resolved from the PodDeviceAllocations, which are kept until the pods are deleted
+	// from the cache since the PodDeviceAllocations are garbage collected along with the pods.
+	allocations map[types.UID]apiext.DeviceAllocations
+}
+
+// newPodDeviceAllocationStore returns the store resolving the PodDeviceAllocations. The informer is only started
+// if the scheduler stores the allocations in PodDeviceAllocations, otherwise the ones left by the previous
+// configuration are fetched from the apiserver.
+func newPodDeviceAllocationStore(koordClientSet koordinatorclientset.Interface, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory, enableInformer bool) *podDeviceAllocationStore {
+	s := &podDeviceAllocationStore{
+		client:      koordClientSet,
+		allocations: map[types.UID]apiext.DeviceAllocations{},
+	}
+	if enableInformer {
+		podDeviceAllocationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().PodDeviceAllocations()
+		// make sure PodDeviceAllocations are loaded before the pods referencing them
+		frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, podDeviceAllocationInformer.Informer(), cache.ResourceEventHandlerFuncs{})
+		s.lister = podDeviceAllocationInformer.Lister()
+	}
+	return s
+}
+
+// getDeviceAllocations returns the devices allocated by the pod, which are resolved from the PodDeviceAllocation
+// if the pod references one, otherwise parsed from the pod annotations.
+func (s *podDeviceAllocationStore) getDeviceAllocations(pod *corev1.Pod) (apiext.DeviceAllocations, error) {
+	if s == nil || apiext.GetDeviceAllocationRef(pod.Annotations) == "" {
+		return apiext.GetDeviceAllocations(pod.Annotations)
+	}
+
+	s.lock.Lock()
+	allocations, ok := s.allocations[pod.UID]
+	s.lock.Unlock()
+	if ok {
+		return allocations, nil
+	}
+
+	allocations, err := apiext.ResolveDeviceAllocations(pod.Namespace, pod.UID, pod.Annotations, s.getPodDeviceAllocation)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	s.allocations[pod.UID] = allocations
+	s.lock.Unlock()
+	return allocations, nil
+}
+
+func (s *podDeviceAllocationStore) getPodDeviceAllocation(namespace, name string) (*schedulingv1alpha1.PodDeviceAllocation, error) {
+	if s.lister != nil {
+		podDeviceAllocation, err := s.lister.PodDeviceAllocations(namespace).Get(name)
+		// the informer may not observe the PodDeviceAllocation created just before the pod is updated
+		if !errors.IsNotFound(err) {
+			return podDeviceAllocation, err
+		}
+	}
+	return s.client.SchedulingV1alpha1().PodDeviceAllocations(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// save stores the devices allocated by the pod in the PodDeviceAllocation and makes the pod reference it.
+// The PodDeviceAllocation left by a deleted pod with the same name or by a failed binding is overwritten.
+func (s *podDeviceAllocationStore) save(ctx context.Context, pod *corev1.Pod, nodeName string, allocations apiext.DeviceAllocations) error {
+	podDeviceAllocation := apiext.NewPodDeviceAllocation(pod, nodeName, allocations)
+	podDeviceAllocations := s.client.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace)
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
+		_, err := podDeviceAllocations.Create(ctx, podDeviceAllocation, metav1.CreateOptions{})
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+		existing, err := podDeviceAllocations.Get(ctx, podDeviceAllocation.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing = existing.DeepCopy()
+		existing.OwnerReferences = podDeviceAllocation.OwnerReferences
+		existing.Spec = podDeviceAllocation.Spec
+		_, err = podDeviceAllocations.Update(ctx, existing, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	apiext.SetDeviceAllocationRef(pod, podDeviceAllocation.Name)
+	s.lock.Lock()
+	s.allocations[pod.UID] = allocations
+	s.lock.Unlock()
+	return nil
+}
+
+// delete removes the PodDeviceAllocation of the pod which fails to bind. The PodDeviceAllocations of the deleted
+// pods are removed by the garbage collector of kube-controller-manager through the owner references.
+func (s *podDeviceAllocationStore) delete(ctx context.Context, pod *corev1.Pod) {
+	s.forget(pod.UID)
+	name := apiext.GetDeviceAllocationRef(pod.Annotations)
+	if name == "" {
+		return
+	}
+	err := s.client.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.V(4).InfoS("failed to delete PodDeviceAllocation", "pod", klog.KObj(pod), "name", name, "err", err)
+	}
+}
+
+// forget drops the devices resolved for the pod, which is called when the pod is deleted from the cache.
+func (s *podDeviceAllocationStore) forget(podUID types.UID) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.allocations, podUID)
+}
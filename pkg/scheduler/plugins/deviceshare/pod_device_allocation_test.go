@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func newTestGPUAllocations(minors ...int32) apiext.DeviceAllocations {
+	allocations := apiext.DeviceAllocations{}
+	for _, minor := range minors {
+		allocations[schedulingv1alpha1.GPU] = append(allocations[schedulingv1alpha1.GPU], &apiext.DeviceAllocation{
+			Minor: minor,
+			Resources: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("100"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+				apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+			},
+		})
+	}
+	return allocations
+}
+
+func TestPodDeviceAllocationStore(t *testing.T) {
+	koordClientSet := koordfake.NewSimpleClientset()
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+	// the informer is not started, so that the PodDeviceAllocations are always resolved from the API server
+	store := &podDeviceAllocationStore{
+		lister:      koordSharedInformerFactory.Scheduling().V1alpha1().PodDeviceAllocations().Lister(),
+		client:      koordClientSet,
+		allocations: map[types.UID]apiext.DeviceAllocations{},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "123456789",
+			Namespace: "default",
+			Name:      "test",
+		},
+	}
+	allocations := newTestGPUAllocations(0, 1)
+
+	// the pods without the reference are parsed from the annotations
+	assert.NoError(t, apiext.SetDeviceAllocations(pod, allocations))
+	got, err := store.getDeviceAllocations(pod)
+	assert.NoError(t, err)
+	assert.Equal(t, allocations, got)
+
+	// the devices are stored in the PodDeviceAllocation referenced by the pod
+	assert.NoError(t, store.save(context.TODO(), pod, "test-node", allocations))
+	assert.Equal(t, pod.Name, apiext.GetDeviceAllocationRef(pod.Annotations))
+	assert.Empty(t, pod.Annotations[apiext.AnnotationDeviceAllocated])
+	podDeviceAllocation, err := koordClientSet.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, pod.UID, podDeviceAllocation.Spec.PodUID)
+	assert.Equal(t, "test-node", podDeviceAllocation.Spec.NodeName)
+
+	// the devices are resolved from the PodDeviceAllocation
+	store.forget(pod.UID)
+	got, err = store.getDeviceAllocations(pod)
+	assert.NoError(t, err)
+	assert.Equal(t, allocations, got)
+
+	// the devices are kept after the PodDeviceAllocation is garbage collected until the pod is forgotten
+	err = koordClientSet.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	got, err = store.getDeviceAllocations(pod)
+	assert.NoError(t, err)
+	assert.Equal(t, allocations, got)
+	store.forget(pod.UID)
+	_, err = store.getDeviceAllocations(pod)
+	assert.Error(t, err)
+
+	// the PodDeviceAllocation left by the deleted pod with the same name is overwritten
+	assert.NoError(t, store.save(context.TODO(), pod, "test-node", allocations))
+	recreatedPod := pod.DeepCopy()
+	recreatedPod.UID = "987654321"
+	store.forget(pod.UID)
+	_, err = store.getDeviceAllocations(recreatedPod)
+	assert.Error(t, err)
+	newAllocations := newTestGPUAllocations(2, 3, 4)
+	assert.NoError(t, store.save(context.TODO(), recreatedPod, "test-node-1", newAllocations))
+	store.forget(recreatedPod.UID)
+	got, err = store.getDeviceAllocations(recreatedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, newAllocations, got)
+
+	// the devices are resolved from the API server without the informer
+	storeWithoutInformer := newPodDeviceAllocationStore(koordClientSet, koordSharedInformerFactory, false)
+	got, err = storeWithoutInformer.getDeviceAllocations(recreatedPod)
+	assert.NoError(t, err)
+	assert.Equal(t, newAllocations, got)
+
+	// the PodDeviceAllocation is deleted if the pod fails to bind
+	store.delete(context.TODO(), recreatedPod)
+	_, err = koordClientSet.SchedulingV1alpha1().PodDeviceAllocations(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	assert.Error(t, err)
+	assert.Empty(t, store.allocations)
+}
+
+func Test_Plugin_PreBindWithPodDeviceAllocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int32
+		wantRef   bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:      "less devices than threshold",
+			threshold: 3,
+		},
+		{
+			name:      "store in PodDeviceAllocation",
+			threshold: 2,
+			wantRef:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:       "123456789",
+					Namespace: "default",
+					Name:      "test",
+				},
+			}
+			suit := newPluginTestSuit(t, nil)
+			_, err := suit.ClientSet().CoreV1().Pods(testPod.Namespace).Create(context.TODO(), testPod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+			pl, err := suit.proxyNew(&config.DeviceShareArgs{PodDeviceAllocationThreshold: tt.threshold}, suit.Framework)
+			assert.NoError(t, err)
+
+			allocations := newTestGPUAllocations(0, 1)
+			cycleState := framework.NewCycleState()
+			cycleState.Write(stateKey, &preFilterState{allocationResult: allocations})
+			status := pl.(*Plugin).PreBind(context.TODO(), cycleState, testPod, "test-node")
+			assert.True(t, status.IsSuccess())
+
+			boundPod, err := suit.ClientSet().CoreV1().Pods(testPod.Namespace).Get(context.TODO(), testPod.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			_, err = suit.koordClientSet.SchedulingV1alpha1().PodDeviceAllocations(testPod.Namespace).Get(context.TODO(), testPod.Name, metav1.GetOptions{})
+			if tt.wantRef {
+				assert.NoError(t, err)
+				assert.Equal(t, testPod.Name, apiext.GetDeviceAllocationRef(boundPod.Annotations))
+				assert.Empty(t, boundPod.Annotations[apiext.AnnotationDeviceAllocated])
+			} else {
+				assert.Error(t, err)
+				assert.Empty(t, apiext.GetDeviceAllocationRef(boundPod.Annotations))
+				assert.NotEmpty(t, boundPod.Annotations[apiext.AnnotationDeviceAllocated])
+			}
+			// the informer is only started if the allocations are stored in PodDeviceAllocations
+			store := pl.(*Plugin).nodeDeviceCache.podDeviceAllocations
+			assert.Equal(t, tt.threshold > 0, store.lister != nil)
+			got, err := store.getDeviceAllocations(boundPod)
+			assert.NoError(t, err)
+			assert.Equal(t, allocations, got)
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
 )
 
@@ -46,7 +47,7 @@ func (n *nodeDeviceCache) onPodAdd(obj interface{}) {
 		return
 	}
 
-	devicesAllocation, err := apiext.GetDeviceAllocations(pod.Annotations)
+	devicesAllocation, err := n.podDeviceAllocations.getDeviceAllocations(pod)
 	if err != nil {
 		klog.Errorf("failed to get device allocation from pod %v, err: %v", klog.KObj(pod), err)
 		return
@@ -65,7 +66,11 @@ func (n *nodeDeviceCache) onPodAdd(obj interface{}) {
 	info.lock.Lock()
 	defer info.lock.Unlock()
 
+	if reservationUID := getConsumedReservationUID(pod); reservationUID != "" {
+		info.assumeReservationConsumer(reservationUID, pod, devicesAllocation)
+	}
 	info.updateCacheUsed(devicesAllocation, pod, true)
+	info.recordMetrics(pod.Spec.NodeName)
 	klog.V(5).InfoS("pod cache added", "pod", klog.KObj(pod))
 }
 
@@ -88,8 +93,9 @@ func (n *nodeDeviceCache) onPodDelete(obj interface{}) {
 	default:
 		return
 	}
+	defer n.podDeviceAllocations.forget(pod.UID)
 
-	devicesAllocation, err := apiext.GetDeviceAllocations(pod.Annotations)
+	devicesAllocation, err := n.podDeviceAllocations.getDeviceAllocations(pod)
 	if err != nil {
 		klog.Errorf("failed to get device allocation from pod %v, err: %v", klog.KObj(pod), err)
 		return
@@ -109,6 +115,11 @@ func (n *nodeDeviceCache) onPodDelete(obj interface{}) {
 	defer info.lock.Unlock()
 
 	info.updateCacheUsed(devicesAllocation, pod, false)
+	if reservationUID := getConsumedReservationUID(pod); reservationUID != "" {
+		info.forgetReservationConsumer(reservationUID, pod)
+	}
+	info.recordPreviousGPUs(pod, devicesAllocation[schedulingv1alpha1.GPU])
+	info.recordMetrics(pod.Spec.NodeName)
 	klog.V(5).InfoS("pod cache deleted", "pod", klog.KObj(pod))
 }
 
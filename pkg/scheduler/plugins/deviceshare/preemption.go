@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// gpuReclaimCost is the cost to reclaim a GPU from the victims holding it.
+type gpuReclaimCost struct {
+	minor int
+	// maxPriority is the highest priority of the victims on the GPU.
+	maxPriority int32
+	victims     int
+}
+
+func (c gpuReclaimCost) less(o gpuReclaimCost) bool {
+	if c.maxPriority != o.maxPriority {
+		return c.maxPriority < o.maxPriority
+	}
+	if c.victims != o.victims {
+		return c.victims < o.victims
+	}
+	return c.minor < o.minor
+}
+
+// selectPreemptionGPUs selects the GPUs to reclaim for the preemptor on the node with all the potential victims removed.
+// The preemptor may be allocated any GPU freed by the victims, while the victims are reprieved only if the GPUs
+// allocated are not touched. So the GPUs are ranked by the victims holding them, i.e. the highest priority and then
+// the number of the victims, and the preemptor is allocated within the cheapest GPUs, which keeps the victims on the
+// same GPUs rather than spread over the GPUs not needed by the preemptor.
+// It returns an empty set if the preemptor cannot be allocated even if all the victims are removed.
+func (p *Plugin) selectPreemptionGPUs(nodeName string, pod *corev1.Pod, podRequest corev1.ResourceList, delta *nodeDeviceDelta, candidate *nodeDevice) sets.Int {
+	costs := map[int]*gpuReclaimCost{}
+	for minor := range candidate.deviceTotal[schedulingv1alpha1.GPU] {
+		costs[minor] = &gpuReclaimCost{minor: minor, maxPriority: math.MinInt32}
+	}
+	for uid, allocations := range delta.removed {
+		priority := delta.victimPriorities[uid]
+		for _, allocation := range allocations[schedulingv1alpha1.GPU] {
+			cost := costs[int(allocation.Minor)]
+			if cost == nil {
+				continue
+			}
+			cost.victims++
+			if priority > cost.maxPriority {
+				cost.maxPriority = priority
+			}
+		}
+	}
+	ranked := make([]gpuReclaimCost, 0, len(costs))
+	for _, cost := range costs {
+		ranked = append(ranked, *cost)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].less(ranked[j])
+	})
+
+	allowed := sets.NewInt()
+	for i, cost := range ranked {
+		allowed.Insert(cost.minor)
+		// the GPUs of the same cost are tried together
+		if i+1 < len(ranked) && ranked[i+1].maxPriority == cost.maxPriority && ranked[i+1].victims == cost.victims {
+			continue
+		}
+		allocateResult, err := p.allocator.Allocate(nodeName, pod, podRequest, candidate.restrictGPUs(allowed))
+		if err != nil || len(allocateResult[schedulingv1alpha1.GPU]) == 0 {
+			continue
+		}
+		minors := sets.NewInt()
+		for _, allocation := range allocateResult[schedulingv1alpha1.GPU] {
+			minors.Insert(int(allocation.Minor))
+		}
+		klog.V(5).InfoS("GPUs selected to reclaim for preemptor", "pod", klog.KObj(pod), "node", nodeName, "minors", minors.List())
+		return minors
+	}
+	return sets.NewInt()
+}
+
+// restrictGPUs returns a copy of the nodeDevice on which only the given GPUs are free to allocate.
+// The lock of nodeDevice must be held.
+func (n *nodeDevice) restrictGPUs(minors sets.Int) *nodeDevice {
+	out := n.cloneWithAllocations(nil, nil)
+	for minor := range out.deviceFree[schedulingv1alpha1.GPU] {
+		if !minors.Has(minor) {
+			delete(out.deviceFree[schedulingv1alpha1.GPU], minor)
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func Test_Plugin_selectVictimsByGPUs(t *testing.T) {
+	type victim struct {
+		name     string
+		priority int32
+		minor    int32
+	}
+	tests := []struct {
+		name        string
+		victims     []victim
+		wantVictims []string
+	}{
+		{
+			name: "reclaim the GPU with the least victims",
+			victims: []victim{
+				{name: "a", minor: 0},
+				{name: "b", minor: 1},
+				{name: "c", minor: 1},
+			},
+			wantVictims: []string{"a"},
+		},
+		{
+			name: "reclaim the GPU with the victims of lower priority",
+			victims: []victim{
+				{name: "a", priority: 10, minor: 0},
+				{name: "b", minor: 1},
+				{name: "c", minor: 1},
+			},
+			wantVictims: []string{"b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, nd, nodeInfo := newNominatorTestPlugin(2)
+			var victims []*corev1.Pod
+			for _, v := range tt.victims {
+				pod := newNominatorTestPod(v.name, 50, v.priority)
+				pod.Spec.NodeName = "test-node"
+				nd.updateCacheUsed(apiext.DeviceAllocations{
+					schedulingv1alpha1.GPU: {{Minor: v.minor, Resources: gpuResources(50, 50)}},
+				}, pod, true)
+				victims = append(victims, pod)
+			}
+			preemptor := newNominatorTestPod("preemptor", 100, 100)
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreFilter(context.TODO(), cycleState, preemptor).IsSuccess())
+			assert.Equal(t, framework.Unschedulable, p.Filter(context.TODO(), cycleState, preemptor, nodeInfo).Code())
+
+			// simulate the preemption like selectVictimsOnNode, all the victims are removed first,
+			// then reprieved one by one if the preemptor still fits.
+			for _, pod := range victims {
+				assert.True(t, p.RemovePod(context.TODO(), cycleState, preemptor, framework.NewPodInfo(pod), nodeInfo).IsSuccess())
+			}
+			assert.True(t, p.Filter(context.TODO(), cycleState, preemptor, nodeInfo).IsSuccess())
+			var gotVictims []string
+			for _, pod := range victims {
+				assert.True(t, p.AddPod(context.TODO(), cycleState, preemptor, framework.NewPodInfo(pod), nodeInfo).IsSuccess())
+				if !p.Filter(context.TODO(), cycleState, preemptor, nodeInfo).IsSuccess() {
+					assert.True(t, p.RemovePod(context.TODO(), cycleState, preemptor, framework.NewPodInfo(pod), nodeInfo).IsSuccess())
+					gotVictims = append(gotVictims, pod.Name)
+				}
+			}
+			assert.Equal(t, tt.wantVictims, gotVictims)
+		})
+	}
+}
+
+func Test_Plugin_selectPreemptionGPUs(t *testing.T) {
+	p, nd, _ := newNominatorTestPlugin(2)
+	victim := newNominatorTestPod("victim", 100, 0)
+	victim.Spec.NodeName = "test-node"
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(100, 100)}},
+	}
+	nd.updateCacheUsed(allocations, victim, true)
+	state := &preFilterState{}
+	delta := state.getNodeDelta("test-node")
+	delta.removed[victim.UID] = allocations
+	candidate := state.applyNodeDelta("test-node", nd)
+
+	// the free GPU is preferred to the GPU of the victim
+	pod := newNominatorTestPod("preemptor", 100, 100)
+	podState, _ := p.preparePod(pod)
+	assert.Equal(t, []int{0}, p.selectPreemptionGPUs("test-node", pod, podState.convertedDeviceResource, delta, candidate).List())
+
+	pod = newNominatorTestPod("preemptor", 200, 100)
+	podState, _ = p.preparePod(pod)
+	assert.Equal(t, []int{0, 1}, p.selectPreemptionGPUs("test-node", pod, podState.convertedDeviceResource, delta, candidate).List())
+
+	// the pod cannot be allocated even if the victim is removed
+	pod = newNominatorTestPod("preemptor", 300, 100)
+	podState, _ = p.preparePod(pod)
+	got := p.selectPreemptionGPUs("test-node", pod, podState.convertedDeviceResource, delta, candidate)
+	assert.NotNil(t, got)
+	assert.Equal(t, 0, got.Len())
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+// registerReservationEventHandler accounts the devices held by the reservations. The devices allocated to the
+// reserve pod are recorded in the annotation of the reservation, and they are held by the reservation until
+// the owner pods consume them or the reservation becomes inactive.
+func registerReservationEventHandler(deviceCache *nodeDeviceCache, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory) {
+	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Informer()
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    deviceCache.onReservationAdd,
+		UpdateFunc: deviceCache.onReservationUpdate,
+		DeleteFunc: deviceCache.onReservationDelete,
+	}
+	// make sure Reservations are loaded before scheduler starts working
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, reservationInformer, eventHandler)
+}
+
+func (n *nodeDeviceCache) onReservationAdd(obj interface{}) {
+	r, ok := obj.(*schedulingv1alpha1.Reservation)
+	if !ok {
+		klog.Errorf("reservation cache add failed to parse, obj %T", obj)
+		return
+	}
+	n.updateReservation(r, isReservationHoldingDevices(r))
+}
+
+func (n *nodeDeviceCache) onReservationUpdate(oldObj, newObj interface{}) {
+	r, ok := newObj.(*schedulingv1alpha1.Reservation)
+	if !ok {
+		klog.Errorf("reservation cache update failed to parse, obj %T", newObj)
+		return
+	}
+	n.updateReservation(r, isReservationHoldingDevices(r))
+}
+
+func (n *nodeDeviceCache) onReservationDelete(obj interface{}) {
+	var r *schedulingv1alpha1.Reservation
+	switch t := obj.(type) {
+	case *schedulingv1alpha1.Reservation:
+		r = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		r, ok = t.Obj.(*schedulingv1alpha1.Reservation)
+		if !ok {
+			klog.V(5).Infof("reservation cache remove failed to parse, obj %T", obj)
+			return
+		}
+	default:
+		return
+	}
+	n.updateReservation(r, false)
+}
+
+// updateReservation adds or removes the devices held by the reservation on its node. It is idempotent since the
+// devices held by the reservation are replaced on each update.
+func (n *nodeDeviceCache) updateReservation(r *schedulingv1alpha1.Reservation, add bool) {
+	nodeName := reservationutil.GetReservationNodeName(r)
+	if len(nodeName) == 0 {
+		return
+	}
+	reservePod := reservationutil.NewReservePod(r)
+	var getter apiext.PodDeviceAllocationGetter
+	if n.podDeviceAllocations != nil {
+		getter = n.podDeviceAllocations.getPodDeviceAllocation
+	}
+	devicesAllocation, err := apiext.ResolveDeviceAllocations(reservePod.Namespace, reservePod.UID, r.Annotations, getter)
+	if err != nil {
+		klog.Errorf("failed to get device allocation from reservation %v, err: %v", klog.KObj(r), err)
+		return
+	}
+	if len(devicesAllocation) == 0 {
+		return
+	}
+	transformDeviceAllocations(devicesAllocation)
+
+	info := n.getNodeDevice(nodeName)
+	if info == nil {
+		if !add {
+			return
+		}
+		info = n.createNodeDevice(nodeName)
+		klog.V(5).Infof("node device cache not found, nodeName: %v, reservation: %v, createNodeDevice", nodeName, klog.KObj(r))
+	}
+
+	info.lock.Lock()
+	defer info.lock.Unlock()
+
+	if add {
+		info.updateReservedDevices(reservePod, devicesAllocation)
+	} else {
+		info.deleteReservedDevices(r.UID)
+	}
+	info.recordMetrics(nodeName)
+	klog.V(5).InfoS("reservation cache updated", "reservation", klog.KObj(r), "add", add)
+}
+
+// isReservationHoldingDevices checks if the devices of the reservation are still held for the owner pods.
+// The devices consumed by the owner pods are accounted with the owner pods instead, see reservedDevices.
+func isReservationHoldingDevices(r *schedulingv1alpha1.Reservation) bool {
+	return reservationutil.IsReservationAvailable(r)
+}
+
+// reservedDevices are the devices held by a reservation on the node. The devices consumed by the owner pods are
+// accounted with the owner pods, and the remainder is charged to the reserve pod for the other owners.
+type reservedDevices struct {
+	reservePod  *corev1.Pod
+	allocations apiext.DeviceAllocations
+	// consumers are the devices allocated to the owner pods consuming the reservation.
+	consumers map[types.UID]apiext.DeviceAllocations
+	// charged are the devices accounted with the reserve pod.
+	charged apiext.DeviceAllocations
+}
+
+// updateReservedDevices replaces the devices held by the reservation. The lock of nodeDevice must be held.
+func (n *nodeDevice) updateReservedDevices(reservePod *corev1.Pod, allocations apiext.DeviceAllocations) {
+	reserved := n.getOrCreateReservedDevices(reservePod.UID)
+	n.unchargeReservedDevices(reserved)
+	reserved.reservePod = reservePod
+	reserved.allocations = allocations
+	n.chargeReservedDevices(reserved)
+}
+
+// deleteReservedDevices releases the devices held by the inactive reservation. The lock of nodeDevice must be held.
+func (n *nodeDevice) deleteReservedDevices(reservationUID types.UID) {
+	reserved := n.reservedDevices[reservationUID]
+	if reserved == nil {
+		return
+	}
+	n.unchargeReservedDevices(reserved)
+	delete(n.reservedDevices, reservationUID)
+}
+
+// assumeReservationConsumer hands the devices of the reservation allocated to the owner pod over to the pod.
+// The lock of nodeDevice must be held.
+func (n *nodeDevice) assumeReservationConsumer(reservationUID types.UID, pod *corev1.Pod, allocations apiext.DeviceAllocations) {
+	reserved := n.getOrCreateReservedDevices(reservationUID)
+	n.unchargeReservedDevices(reserved)
+	reserved.consumers[pod.UID] = allocations
+	n.chargeReservedDevices(reserved)
+}
+
+// forgetReservationConsumer gives the devices consumed by the owner pod back to the reservation.
+// The lock of nodeDevice must be held.
+func (n *nodeDevice) forgetReservationConsumer(reservationUID types.UID, pod *corev1.Pod) {
+	reserved := n.reservedDevices[reservationUID]
+	if reserved == nil {
+		return
+	}
+	if _, ok := reserved.consumers[pod.UID]; !ok {
+		return
+	}
+	n.unchargeReservedDevices(reserved)
+	delete(reserved.consumers, pod.UID)
+	if reserved.reservePod == nil && len(reserved.consumers) == 0 {
+		delete(n.reservedDevices, reservationUID)
+		return
+	}
+	n.chargeReservedDevices(reserved)
+}
+
+func (n *nodeDevice) getOrCreateReservedDevices(reservationUID types.UID) *reservedDevices {
+	if n.reservedDevices == nil {
+		n.reservedDevices = map[types.UID]*reservedDevices{}
+	}
+	reserved := n.reservedDevices[reservationUID]
+	if reserved == nil {
+		reserved = &reservedDevices{consumers: map[types.UID]apiext.DeviceAllocations{}}
+		n.reservedDevices[reservationUID] = reserved
+	}
+	return reserved
+}
+
+func (n *nodeDevice) unchargeReservedDevices(reserved *reservedDevices) {
+	if len(reserved.charged) == 0 {
+		return
+	}
+	n.updateCacheUsed(reserved.charged, reserved.reservePod, false)
+	reserved.charged = nil
+}
+
+func (n *nodeDevice) chargeReservedDevices(reserved *reservedDevices) {
+	if reserved.reservePod == nil {
+		return
+	}
+	charged := subtractConsumedDevices(reserved.allocations, reserved.consumers)
+	if len(charged) == 0 {
+		return
+	}
+	n.updateCacheUsed(charged, reserved.reservePod, true)
+	reserved.charged = charged
+}
+
+// subtractConsumedDevices returns the devices of the reservation not consumed by the owner pods.
+func subtractConsumedDevices(allocations apiext.DeviceAllocations, consumers map[types.UID]apiext.DeviceAllocations) apiext.DeviceAllocations {
+	var result apiext.DeviceAllocations
+	for deviceType, deviceAllocations := range allocations {
+		for _, allocation := range deviceAllocations {
+			remaining := allocation.Resources
+			for _, consumed := range consumers {
+				for _, consumedAllocation := range consumed[deviceType] {
+					if consumedAllocation.Minor == allocation.Minor {
+						remaining = quotav1.SubtractWithNonNegativeResult(remaining, consumedAllocation.Resources)
+					}
+				}
+			}
+			remaining = quotav1.RemoveZeros(quotav1.Mask(remaining, quotav1.ResourceNames(allocation.Resources)))
+			if len(remaining) == 0 {
+				continue
+			}
+			if result == nil {
+				result = apiext.DeviceAllocations{}
+			}
+			result[deviceType] = append(result[deviceType], &apiext.DeviceAllocation{
+				Minor:     allocation.Minor,
+				Resources: remaining,
+				Extension: allocation.Extension,
+			})
+		}
+	}
+	return result
+}
+
+// getConsumedReservationUID returns the UID of the reservation allocated to the pod, or empty if there is none.
+func getConsumedReservationUID(pod *corev1.Pod) types.UID {
+	allocated, err := apiext.GetReservationAllocated(pod)
+	if err != nil || allocated == nil {
+		return ""
+	}
+	return allocated.UID
+}
+
+// getReservationAllocations returns the devices held by the reservation on the node. The lock of nodeDevice must be held.
+func (n *nodeDevice) getReservationAllocations(r *schedulingv1alpha1.Reservation) apiext.DeviceAllocations {
+	allocations := n.getPodAllocations(reservationutil.NewReservePod(r))
+	for _, deviceAllocations := range allocations {
+		for _, allocation := range deviceAllocations {
+			allocation.Resources = allocation.Resources.DeepCopy()
+		}
+	}
+	return allocations
+}
@@ -0,0 +1,366 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deviceshare
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func newDeviceTestReservation(t *testing.T, allocations apiext.DeviceAllocations) *schedulingv1alpha1.Reservation {
+	data, err := json.Marshal(allocations)
+	assert.NoError(t, err)
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-reservation",
+			UID:  "test-reservation-uid",
+			Annotations: map[string]string{
+				apiext.AnnotationDeviceAllocated: string(data),
+			},
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "test-node",
+		},
+	}
+}
+
+func gpuCoreFree(nd *nodeDevice, minor int) int64 {
+	free := nd.deviceFree[schedulingv1alpha1.GPU][minor]
+	return free.Name(apiext.ResourceGPUCore, resource.DecimalSI).Value()
+}
+
+func Test_nodeDeviceCache_onReservationEvents(t *testing.T) {
+	p, nd, _ := newNominatorTestPlugin(2)
+	r := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(100, 100)}},
+	})
+
+	// the devices of the pending reservation are not accounted
+	pendingR := r.DeepCopy()
+	pendingR.Status.Phase = schedulingv1alpha1.ReservationPending
+	p.nodeDeviceCache.onReservationAdd(pendingR)
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 1))
+
+	p.nodeDeviceCache.onReservationUpdate(pendingR, r)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 0))
+	// idempotent for the repeated events
+	p.nodeDeviceCache.onReservationUpdate(r, r)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+
+	// the devices not consumed are still held for the other owners
+	consumedR := r.DeepCopy()
+	consumedR.Status.CurrentOwners = []corev1.ObjectReference{{Namespace: "default", Name: "owner"}}
+	p.nodeDeviceCache.onReservationUpdate(r, consumedR)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+
+	// the devices are released once the reservation becomes inactive
+	succeededR := consumedR.DeepCopy()
+	succeededR.Status.Phase = schedulingv1alpha1.ReservationSucceeded
+	p.nodeDeviceCache.onReservationUpdate(consumedR, succeededR)
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 1))
+
+	p.nodeDeviceCache.onReservationAdd(r)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+	p.nodeDeviceCache.onReservationDelete(r)
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 1))
+}
+
+func Test_nodeDeviceCache_onReservationAddWithPodDeviceAllocation(t *testing.T) {
+	p, nd, _ := newNominatorTestPlugin(2)
+	r := newDeviceTestReservation(t, nil)
+	allocations := apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(100, 100)}},
+	}
+	podDeviceAllocation := apiext.NewPodDeviceAllocation(reservationutil.NewReservePod(r), "test-node", allocations)
+	r.Annotations = map[string]string{apiext.AnnotationDeviceAllocationRef: podDeviceAllocation.Name}
+
+	// the devices referenced by the reservation are not accounted without the PodDeviceAllocation
+	p.nodeDeviceCache.onReservationAdd(r)
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 1))
+
+	koordClientSet := koordfake.NewSimpleClientset(podDeviceAllocation)
+	p.nodeDeviceCache.podDeviceAllocations = newPodDeviceAllocationStore(koordClientSet, koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0), false)
+	p.nodeDeviceCache.onReservationAdd(r)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 0))
+}
+
+func Test_tryAllocateFromReservations(t *testing.T) {
+	_, nd, _ := newNominatorTestPlugin(2)
+	otherPod := newNominatorTestPod("other", 100, 0)
+	nd.updateCacheUsed(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	}, otherPod, true)
+	r := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(100, 100)}},
+	})
+	nd.updateReservedDevices(reservationutil.NewReservePod(r), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(100, 100)}},
+	})
+
+	tests := []struct {
+		name         string
+		gpu          int64
+		reservations []*schedulingv1alpha1.Reservation
+		wantMinor    int32
+		wantResource corev1.ResourceList
+		wantInherit  bool
+	}{
+		{
+			name: "no reservation",
+			gpu:  100,
+		},
+		{
+			name:         "inherit the exact devices of the reservation",
+			gpu:          100,
+			reservations: []*schedulingv1alpha1.Reservation{r},
+			wantMinor:    1,
+			wantResource: gpuResources(100, 100),
+			wantInherit:  true,
+		},
+		{
+			name:         "allocate from the devices released by the reservation",
+			gpu:          50,
+			reservations: []*schedulingv1alpha1.Reservation{r},
+			wantMinor:    1,
+			wantResource: gpuResources(50, 50),
+			wantInherit:  true,
+		},
+		{
+			name:         "insufficient devices of the reservation",
+			gpu:          200,
+			reservations: []*schedulingv1alpha1.Reservation{r},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := newNominatorTestPod("test", tt.gpu, 0)
+			requests := apiext.TransformDeprecatedDeviceResources(pod.Spec.Containers[0].Resources.Requests)
+			combination, err := ValidateGPURequest(requests)
+			assert.NoError(t, err)
+			podRequest := ConvertGPUResource(requests, combination)
+
+			allocateResult, gotR := tryAllocateFromReservations(pod, podRequest, nd, tt.reservations)
+			if !tt.wantInherit {
+				assert.Nil(t, allocateResult)
+				assert.Nil(t, gotR)
+				return
+			}
+			assert.Equal(t, r, gotR)
+			assert.Len(t, allocateResult[schedulingv1alpha1.GPU], 1)
+			assert.Equal(t, tt.wantMinor, allocateResult[schedulingv1alpha1.GPU][0].Minor)
+			for name, want := range tt.wantResource {
+				got := allocateResult[schedulingv1alpha1.GPU][0].Resources[name]
+				assert.Equal(t, want.Value(), got.Value(), name)
+			}
+		})
+	}
+}
+
+func Test_tryAllocateFromReservations_excludedGPUs(t *testing.T) {
+	_, nd, _ := newNominatorTestPlugin(2)
+	r := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 0, Resources: gpuResources(100, 100)},
+			{Minor: 1, Resources: gpuResources(100, 100)},
+		},
+	})
+	nd.updateReservedDevices(reservationutil.NewReservePod(r), apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 0, Resources: gpuResources(100, 100)},
+			{Minor: 1, Resources: gpuResources(100, 100)},
+		},
+	})
+	// the GPU 1 of the reservation is allocated exclusively by another pod
+	nd.exclusiveGPUs = map[int]sets.String{1: sets.NewString("default/exclusive")}
+
+	pod := newNominatorTestPod("test", 200, 0)
+	requests := apiext.TransformDeprecatedDeviceResources(pod.Spec.Containers[0].Resources.Requests)
+	combination, err := ValidateGPURequest(requests)
+	assert.NoError(t, err)
+	podRequest := ConvertGPUResource(requests, combination)
+
+	allocateResult, gotR := tryAllocateFromReservations(pod, podRequest, nd, []*schedulingv1alpha1.Reservation{r})
+	assert.Nil(t, allocateResult)
+	assert.Nil(t, gotR)
+
+	// the excluded GPU is not inherited even if the remaining devices satisfy the request
+	pod = newNominatorTestPod("test", 100, 0)
+	requests = apiext.TransformDeprecatedDeviceResources(pod.Spec.Containers[0].Resources.Requests)
+	combination, err = ValidateGPURequest(requests)
+	assert.NoError(t, err)
+	podRequest = ConvertGPUResource(requests, combination)
+
+	allocateResult, gotR = tryAllocateFromReservations(pod, podRequest, nd, []*schedulingv1alpha1.Reservation{r})
+	assert.Equal(t, r, gotR)
+	assert.Len(t, allocateResult[schedulingv1alpha1.GPU], 1)
+	assert.Equal(t, int32(0), allocateResult[schedulingv1alpha1.GPU][0].Minor)
+}
+
+func Test_Plugin_UnreserveReservationDevices(t *testing.T) {
+	p, nd, nodeInfo := newNominatorTestPlugin(1)
+	r := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(100, 100)}},
+	})
+	p.nodeDeviceCache.onReservationAdd(r)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 0))
+
+	pod := newNominatorTestPod("owner", 100, 0)
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	// the devices are held by the reservation
+	assert.Equal(t, framework.Unschedulable, p.Filter(context.TODO(), cycleState, pod, nodeInfo).Code())
+
+	// simulate the pod inheriting the devices of the assumed reservation
+	state, _ := getPreFilterState(cycleState)
+	reservationAllocations := nd.getReservationAllocations(r)
+	allocateResult, gotR := tryAllocateFromReservations(pod, state.convertedDeviceResource, nd, []*schedulingv1alpha1.Reservation{r})
+	assert.NotNil(t, allocateResult)
+	nd.assumeReservationConsumer(gotR.UID, pod, allocateResult)
+	p.allocator.Reserve(pod, nd, allocateResult)
+	state.allocationResult = allocateResult
+	state.reservation = gotR
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 0))
+	assert.Nil(t, nd.getReservationAllocations(r))
+
+	// the devices are given back to the reservation
+	p.Unreserve(context.TODO(), cycleState, pod, "test-node")
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 0))
+	assert.Equal(t, reservationAllocations, nd.getReservationAllocations(r))
+	assert.Nil(t, nd.getPodAllocations(pod))
+	assert.Nil(t, state.reservation)
+}
+
+func Test_Plugin_ReserveFilteredReservation(t *testing.T) {
+	p, nd, nodeInfo := newNominatorTestPlugin(2)
+	otherPod := newNominatorTestPod("other", 50, 0)
+	nd.updateCacheUsed(apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(50, 50)}},
+	}, otherPod, true)
+	// the assumed reservation holds only half of the GPU 0, which cannot satisfy the pod
+	assumedR := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 0, Resources: gpuResources(50, 50)}},
+	})
+	assumedR.Name, assumedR.UID = "assumed-reservation", "assumed-reservation-uid"
+	fitR := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {{Minor: 1, Resources: gpuResources(100, 100)}},
+	})
+	p.nodeDeviceCache.onReservationAdd(assumedR)
+	p.nodeDeviceCache.onReservationAdd(fitR)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 0))
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+
+	oldGetMatched, oldGetAssumed := getMatchedReservationsOnNode, getAssumedReservation
+	defer func() {
+		getMatchedReservationsOnNode, getAssumedReservation = oldGetMatched, oldGetAssumed
+	}()
+	getMatchedReservationsOnNode = func(_ *framework.CycleState, _ string) []*schedulingv1alpha1.Reservation {
+		return []*schedulingv1alpha1.Reservation{assumedR, fitR}
+	}
+	getAssumedReservation = func(_ *framework.CycleState) *schedulingv1alpha1.Reservation {
+		return assumedR
+	}
+
+	pod := newNominatorTestPod("owner", 100, 0)
+	cycleState := framework.NewCycleState()
+	assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+	assert.True(t, p.Filter(context.TODO(), cycleState, pod, nodeInfo).IsSuccess())
+	state, _ := getPreFilterState(cycleState)
+	assert.Equal(t, fitR, state.filteredReservations.get("test-node"))
+
+	// Reserve allocates from the reservation picked by Filter instead of the assumed one
+	assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node").IsSuccess())
+	assert.Equal(t, fitR, state.reservation)
+	assert.Len(t, state.allocationResult[schedulingv1alpha1.GPU], 1)
+	assert.Equal(t, int32(1), state.allocationResult[schedulingv1alpha1.GPU][0].Minor)
+	assert.Nil(t, nd.getReservationAllocations(fitR))
+	assert.NotNil(t, nd.getReservationAllocations(assumedR))
+}
+
+func Test_nodeDevice_reservationConsumers(t *testing.T) {
+	p, nd, _ := newNominatorTestPlugin(3)
+	r := newDeviceTestReservation(t, apiext.DeviceAllocations{
+		schedulingv1alpha1.GPU: {
+			{Minor: 0, Resources: gpuResources(100, 100)},
+			{Minor: 1, Resources: gpuResources(100, 100)},
+		},
+	})
+	p.nodeDeviceCache.onReservationAdd(r)
+
+	reserveOwner := func(name string) apiext.DeviceAllocations {
+		pod := newNominatorTestPod(name, 100, 0)
+		cycleState := framework.NewCycleState()
+		assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+		state, _ := getPreFilterState(cycleState)
+		allocateResult, gotR := tryAllocateFromReservations(pod, state.convertedDeviceResource, nd, []*schedulingv1alpha1.Reservation{r})
+		assert.NotNil(t, gotR)
+		assert.Len(t, allocateResult[schedulingv1alpha1.GPU], 1)
+		nd.assumeReservationConsumer(gotR.UID, pod, allocateResult)
+		p.allocator.Reserve(pod, nd, allocateResult)
+		return allocateResult
+	}
+
+	// the first owner takes one of the reserved GPUs, and the other is still held by the reservation
+	first := reserveOwner("owner-1")
+	firstMinor := int(first[schedulingv1alpha1.GPU][0].Minor)
+	leftMinor := 1 - firstMinor
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 0))
+	assert.Equal(t, int64(0), gpuCoreFree(nd, 1))
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 2))
+	left := nd.getReservationAllocations(r)
+	assert.Len(t, left[schedulingv1alpha1.GPU], 1)
+	assert.Equal(t, int32(leftMinor), left[schedulingv1alpha1.GPU][0].Minor)
+
+	// the reservation consumed by the owner keeps holding the leftover
+	consumedR := r.DeepCopy()
+	consumedR.Status.CurrentOwners = []corev1.ObjectReference{{Namespace: "default", Name: "owner-1", UID: "owner-1"}}
+	p.nodeDeviceCache.onReservationUpdate(r, consumedR)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, leftMinor))
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 2))
+
+	// the second owner still gets the leftover GPU of the reservation
+	second := reserveOwner("owner-2")
+	assert.Equal(t, int32(leftMinor), second[schedulingv1alpha1.GPU][0].Minor)
+	assert.Nil(t, nd.getReservationAllocations(r))
+	assert.Equal(t, int64(100), gpuCoreFree(nd, 2))
+
+	// the GPU is given back to the reservation when the owner goes away
+	owner2 := newNominatorTestPod("owner-2", 100, 0)
+	p.allocator.Unreserve(owner2, nd, second)
+	nd.forgetReservationConsumer(r.UID, owner2)
+	assert.Equal(t, int64(0), gpuCoreFree(nd, leftMinor))
+	assert.Equal(t, left, nd.getReservationAllocations(r))
+}
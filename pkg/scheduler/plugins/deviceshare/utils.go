@@ -17,6 +17,7 @@ limitations under the License.
 package deviceshare
 
 import (
+	"errors"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
@@ -34,12 +35,17 @@ const (
 	GPUCoreExist
 	GPUMemoryExist
 	GPUMemoryRatioExist
+	AMDGPUExist
+	BatchGPUCoreExist
+	BatchGPUMemoryExist
 )
 
 var DeviceResourceNames = map[schedulingv1alpha1.DeviceType][]corev1.ResourceName{
-	schedulingv1alpha1.GPU:  {apiext.ResourceNvidiaGPU, apiext.ResourceGPU, apiext.ResourceGPUCore, apiext.ResourceGPUMemory, apiext.ResourceGPUMemoryRatio},
-	schedulingv1alpha1.RDMA: {apiext.ResourceRDMA},
+	schedulingv1alpha1.GPU: {apiext.ResourceNvidiaGPU, apiext.ResourceAMDGPU, apiext.ResourceGPU, apiext.ResourceGPUCore, apiext.ResourceGPUMemory, apiext.ResourceGPUMemoryRatio,
+		apiext.BatchGPUCore, apiext.BatchGPUMemory},
+	schedulingv1alpha1.RDMA: {apiext.ResourceRDMA, apiext.ResourceRDMAVF},
 	schedulingv1alpha1.FPGA: {apiext.ResourceFPGA},
+	schedulingv1alpha1.NIC:  {apiext.ResourceNetBandwidth},
 }
 
 func hasDeviceResource(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) bool {
@@ -66,6 +72,16 @@ func validateCommonDeviceRequest(podRequest corev1.ResourceList, deviceType sche
 		commonDevice = podRequest[apiext.ResourceFPGA]
 	case schedulingv1alpha1.RDMA:
 		commonDevice = podRequest[apiext.ResourceRDMA]
+		if err := validateRDMAVFRequest(podRequest); err != nil {
+			return err
+		}
+	case schedulingv1alpha1.NIC:
+		// koordinator.sh/net-bandwidth is the absolute bandwidth rather than the percentage of a device
+		bandwidth := podRequest[apiext.ResourceNetBandwidth]
+		if bandwidth.Value() <= 0 {
+			return fmt.Errorf("failed to validate %v: %v", apiext.ResourceNetBandwidth, bandwidth.String())
+		}
+		return nil
 	default:
 		return fmt.Errorf("device type %v is not supported yet", deviceType)
 	}
@@ -75,10 +91,44 @@ func validateCommonDeviceRequest(podRequest corev1.ResourceList, deviceType sche
 	return nil
 }
 
+// validateRDMAVFRequest checks the combination of koordinator.sh/rdma-vf and koordinator.sh/rdma.
+// The VFs must be requested together with the bandwidth percentage of RDMA devices,
+// and must be evenly distributed to each device if the pod requests multiple RDMA devices.
+func validateRDMAVFRequest(podRequest corev1.ResourceList) error {
+	vf, ok := podRequest[apiext.ResourceRDMAVF]
+	if !ok {
+		return nil
+	}
+	if vf.Value() <= 0 {
+		return fmt.Errorf("failed to validate %v: %v", apiext.ResourceRDMAVF, vf.Value())
+	}
+	rdma, ok := podRequest[apiext.ResourceRDMA]
+	if !ok || rdma.Value() <= 0 {
+		return fmt.Errorf("%v must be requested together with %v", apiext.ResourceRDMAVF, apiext.ResourceRDMA)
+	}
+	if rdma.Value() > 100 && rdma.Value()%100 == 0 {
+		devices := rdma.Value() / 100
+		if vf.Value()%devices != 0 {
+			return fmt.Errorf("failed to validate %v: %v VFs cannot be evenly distributed to %v RDMA devices",
+				apiext.ResourceRDMAVF, vf.Value(), devices)
+		}
+	}
+	return nil
+}
+
 // ValidateGPURequest uses binary to store each request status.
 // For example, 00010 stands for koordinator.sh/gpu exists, and vice versa.
-// only 00001 || 00010 || 10100 || 01100 are valid GPU request combination
+// only 00001 || 00010 || 10100 || 01100 || 01001 || 100000 || 101000 are valid GPU request combination.
+// 01001 means applying for full cards with koordinator.sh/gpu-memory as the per-card memory limit.
+// 100000 and 101000 are the same as 00001 and 01001 for AMD GPUs requested by amd.com/gpu.
+// 11000000 means applying for the batch GPU reclaimed from the LS pods, which can not be combined with the others.
 var ValidateGPURequest = func(podRequest corev1.ResourceList) (uint, error) {
+	return validateGPURequest(podRequest, false)
+}
+
+// validateGPURequest validates the GPU request of the pod. If allowFractionalMultiGPU is true, the percentile
+// requests of more than one GPU are not required to be a multiple of 100, e.g. gpu-core 150.
+func validateGPURequest(podRequest corev1.ResourceList, allowFractionalMultiGPU bool) (uint, error) {
 	var gpuCombination uint
 
 	if podRequest == nil || len(podRequest) == 0 {
@@ -88,15 +138,21 @@ var ValidateGPURequest = func(podRequest corev1.ResourceList) (uint, error) {
 	if _, exist := podRequest[apiext.ResourceNvidiaGPU]; exist {
 		gpuCombination |= NvidiaGPUExist
 	}
+	if amdGPU, exist := podRequest[apiext.ResourceAMDGPU]; exist {
+		if amdGPU.Value() <= 0 {
+			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.ResourceAMDGPU, amdGPU.Value())
+		}
+		gpuCombination |= AMDGPUExist
+	}
 	if koordGPU, exist := podRequest[apiext.ResourceGPU]; exist {
-		if koordGPU.Value() > 100 && koordGPU.Value()%100 != 0 {
+		if !allowFractionalMultiGPU && koordGPU.Value() > 100 && koordGPU.Value()%100 != 0 {
 			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.ResourceGPU, koordGPU.Value())
 		}
 		gpuCombination |= KoordGPUExist
 	}
 	if gpuCore, exist := podRequest[apiext.ResourceGPUCore]; exist {
 		// koordinator.sh/gpu-core should be something like: 25, 50, 75, 100, 200, 300
-		if gpuCore.Value() > 100 && gpuCore.Value()%100 != 0 {
+		if !allowFractionalMultiGPU && gpuCore.Value() > 100 && gpuCore.Value()%100 != 0 {
 			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.ResourceGPUCore, gpuCore.Value())
 		}
 		gpuCombination |= GPUCoreExist
@@ -105,16 +161,48 @@ var ValidateGPURequest = func(podRequest corev1.ResourceList) (uint, error) {
 		gpuCombination |= GPUMemoryExist
 	}
 	if gpuMemRatio, exist := podRequest[apiext.ResourceGPUMemoryRatio]; exist {
-		if gpuMemRatio.Value() > 100 && gpuMemRatio.Value()%100 != 0 {
+		if !allowFractionalMultiGPU && gpuMemRatio.Value() > 100 && gpuMemRatio.Value()%100 != 0 {
 			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.ResourceGPUMemoryRatio, gpuMemRatio.Value())
 		}
 		gpuCombination |= GPUMemoryRatioExist
 	}
+	if batchGPUCore, exist := podRequest[apiext.BatchGPUCore]; exist {
+		if batchGPUCore.Value() <= 0 || (batchGPUCore.Value() > 100 && batchGPUCore.Value()%100 != 0) {
+			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.BatchGPUCore, batchGPUCore.Value())
+		}
+		gpuCombination |= BatchGPUCoreExist
+	}
+	if batchGPUMem, exist := podRequest[apiext.BatchGPUMemory]; exist {
+		if batchGPUMem.Value() <= 0 {
+			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.BatchGPUMemory, batchGPUMem.String())
+		}
+		gpuCombination |= BatchGPUMemoryExist
+	}
 
 	if gpuCombination == (NvidiaGPUExist) ||
+		gpuCombination == (AMDGPUExist) ||
 		gpuCombination == (KoordGPUExist) ||
 		gpuCombination == (GPUCoreExist|GPUMemoryExist) ||
-		gpuCombination == (GPUCoreExist|GPUMemoryRatioExist) {
+		gpuCombination == (GPUCoreExist|GPUMemoryRatioExist) ||
+		gpuCombination == (BatchGPUCoreExist|BatchGPUMemoryExist) {
+		if gpuCombination == (GPUCoreExist|GPUMemoryRatioExist) && isFractionalMultipleGPUPod(podRequest) {
+			// the full GPUs of a fractional request take the whole memory, leaving the rest to the partial GPU.
+			gpuCore, gpuMemRatio := podRequest[apiext.ResourceGPUCore], podRequest[apiext.ResourceGPUMemoryRatio]
+			if gpuMemRatio.Value() <= gpuCore.Value()/100*100 {
+				return gpuCombination, fmt.Errorf("failed to validate %v: %v, should be greater than %v",
+					apiext.ResourceGPUMemoryRatio, gpuMemRatio.Value(), gpuCore.Value()/100*100)
+			}
+		}
+		return gpuCombination, nil
+	}
+	if gpuCombination == (NvidiaGPUExist|GPUMemoryExist) || gpuCombination == (AMDGPUExist|GPUMemoryExist) {
+		// the per-card memory limit makes no sense without any full card
+		if nvidiaGPU, exist := podRequest[apiext.ResourceNvidiaGPU]; exist && nvidiaGPU.Value() <= 0 {
+			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.ResourceNvidiaGPU, nvidiaGPU.Value())
+		}
+		if gpuMem := podRequest[apiext.ResourceGPUMemory]; gpuMem.Value() <= 0 {
+			return gpuCombination, fmt.Errorf("failed to validate %v: %v", apiext.ResourceGPUMemory, gpuMem.String())
+		}
 		return gpuCombination, nil
 	}
 
@@ -133,6 +221,9 @@ func convertCommonDeviceResource(podRequest corev1.ResourceList, deviceType sche
 			resources = corev1.ResourceList{
 				apiext.ResourceRDMA: value,
 			}
+			if vf, ok := podRequest[apiext.ResourceRDMAVF]; ok {
+				resources[apiext.ResourceRDMAVF] = vf
+			}
 		}
 	case schedulingv1alpha1.FPGA:
 		if value, ok := podRequest[apiext.ResourceFPGA]; ok {
@@ -140,6 +231,12 @@ func convertCommonDeviceResource(podRequest corev1.ResourceList, deviceType sche
 				apiext.ResourceFPGA: value,
 			}
 		}
+	case schedulingv1alpha1.NIC:
+		if value, ok := podRequest[apiext.ResourceNetBandwidth]; ok {
+			resources = corev1.ResourceList{
+				apiext.ResourceNetBandwidth: value,
+			}
+		}
 	default:
 		klog.Warningf("device type %v is not supported yet", deviceType)
 		return nil
@@ -148,8 +245,10 @@ func convertCommonDeviceResource(podRequest corev1.ResourceList, deviceType sche
 }
 
 // ConvertGPUResource will convert either nvidia.com/gpu or koordinator.sh/gpu to koordinator.sh/gpu-core and koordinator.sh/gpu-memory-ratio
-// nvidia.com/gpu means applying for full-card
+// nvidia.com/gpu means applying for full-card, and amd.com/gpu is the same for AMD GPUs
 // koordinator.sh/gpu means applying for cards in percentile
+// koordinator.sh/batch-gpu-core and koordinator.sh/batch-gpu-memory are kept as they are allocated from the batch GPU
+// nvidia.com/gpu with koordinator.sh/gpu-memory means applying for full-card with the memory limited per card
 var ConvertGPUResource = func(podRequest corev1.ResourceList, combination uint) corev1.ResourceList {
 	if podRequest == nil || len(podRequest) == 0 {
 		klog.Warningf("pod request should not be empty")
@@ -166,21 +265,68 @@ var ConvertGPUResource = func(podRequest corev1.ResourceList, combination uint)
 			apiext.ResourceGPUCore:        podRequest[apiext.ResourceGPUCore],
 			apiext.ResourceGPUMemoryRatio: podRequest[apiext.ResourceGPUMemoryRatio],
 		}
+	case BatchGPUCoreExist | BatchGPUMemoryExist:
+		return corev1.ResourceList{
+			apiext.BatchGPUCore:   podRequest[apiext.BatchGPUCore],
+			apiext.BatchGPUMemory: podRequest[apiext.BatchGPUMemory],
+		}
 	case KoordGPUExist:
 		return corev1.ResourceList{
 			apiext.ResourceGPUCore:        podRequest[apiext.ResourceGPU],
 			apiext.ResourceGPUMemoryRatio: podRequest[apiext.ResourceGPU],
 		}
-	case NvidiaGPUExist:
-		nvidiaGpu := podRequest[apiext.ResourceNvidiaGPU]
+	case NvidiaGPUExist, AMDGPUExist:
+		fullGPUs := getFullGPUs(podRequest, combination)
+		return corev1.ResourceList{
+			apiext.ResourceGPUCore:        *resource.NewQuantity(fullGPUs*100, resource.DecimalSI),
+			apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(fullGPUs*100, resource.DecimalSI),
+		}
+	case NvidiaGPUExist | GPUMemoryExist, AMDGPUExist | GPUMemoryExist:
+		fullGPUs := getFullGPUs(podRequest, combination)
+		gpuMem := podRequest[apiext.ResourceGPUMemory]
+		// gpu-memory is the limit of each card, the allocator divides the total evenly among the cards.
 		return corev1.ResourceList{
-			apiext.ResourceGPUCore:        *resource.NewQuantity(nvidiaGpu.Value()*100, resource.DecimalSI),
-			apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(nvidiaGpu.Value()*100, resource.DecimalSI),
+			apiext.ResourceGPUCore:   *resource.NewQuantity(fullGPUs*100, resource.DecimalSI),
+			apiext.ResourceGPUMemory: *resource.NewQuantity(gpuMem.Value()*fullGPUs, resource.BinarySI),
 		}
 	}
 	return nil
 }
 
+// getFullGPUs returns the number of full GPUs requested by the vendor-specific resource, e.g. nvidia.com/gpu.
+func getFullGPUs(podRequest corev1.ResourceList, combination uint) int64 {
+	if combination&AMDGPUExist != 0 {
+		amdGPU := podRequest[apiext.ResourceAMDGPU]
+		return amdGPU.Value()
+	}
+	nvidiaGPU := podRequest[apiext.ResourceNvidiaGPU]
+	return nvidiaGPU.Value()
+}
+
+// getRequiredGPUVendor returns the GPU vendor required by the vendor-specific resource of the GPU request,
+// and empty means any vendor.
+func getRequiredGPUVendor(combination uint) string {
+	switch {
+	case combination&AMDGPUExist != 0:
+		return apiext.GPUVendorAMD
+	case combination&NvidiaGPUExist != 0:
+		return apiext.GPUVendorNVIDIA
+	}
+	return ""
+}
+
+// matchGPUVendor checks if the GPUs of the node are of the required vendor.
+// The GPUs without the vendor reported are considered as NVIDIA GPUs.
+func matchGPUVendor(required, nodeVendor string) bool {
+	if required == "" {
+		return true
+	}
+	if nodeVendor == "" {
+		nodeVendor = apiext.GPUVendorNVIDIA
+	}
+	return required == nodeVendor
+}
+
 func isMultipleCommonDevicePod(podRequest corev1.ResourceList, deviceType schedulingv1alpha1.DeviceType) bool {
 	if podRequest == nil || len(podRequest) == 0 {
 		klog.Warningf("pod request should not be empty")
@@ -207,6 +353,40 @@ func isMultipleGPUPod(podRequest corev1.ResourceList) bool {
 	return gpuCore.Value() > 100 && gpuCore.Value()%100 == 0
 }
 
+// isBatchGPUPod returns true if the pod requests the batch GPU reclaimed from the LS pods.
+func isBatchGPUPod(podRequest corev1.ResourceList) bool {
+	_, ok := podRequest[apiext.BatchGPUCore]
+	return ok
+}
+
+// excludeBatchGPUResources returns a copy of the resources without the batch GPU resources.
+func excludeBatchGPUResources(resources corev1.ResourceList) corev1.ResourceList {
+	result := make(corev1.ResourceList, len(resources))
+	for resourceName, quantity := range resources {
+		if resourceName == apiext.BatchGPUCore || resourceName == apiext.BatchGPUMemory {
+			continue
+		}
+		result[resourceName] = quantity.DeepCopy()
+	}
+	return result
+}
+
+// removeZeroBatchGPUResources removes the batch GPU resources which are released by all the batch pods.
+func removeZeroBatchGPUResources(resources corev1.ResourceList) {
+	for _, resourceName := range []corev1.ResourceName{apiext.BatchGPUCore, apiext.BatchGPUMemory} {
+		if quantity, ok := resources[resourceName]; ok && quantity.IsZero() {
+			delete(resources, resourceName)
+		}
+	}
+}
+
+// isFractionalMultipleGPUPod returns true if the pod requests more than one GPU but not a multiple of 100,
+// which is only accepted if AllowFractionalMultiGPU is enabled.
+func isFractionalMultipleGPUPod(podRequest corev1.ResourceList) bool {
+	gpuCore := podRequest[apiext.ResourceGPUCore]
+	return gpuCore.Value() > 100 && gpuCore.Value()%100 != 0
+}
+
 func memRatioToBytes(ratio, totalMemory resource.Quantity) resource.Quantity {
 	return *resource.NewQuantity(ratio.Value()*totalMemory.Value()/100, resource.BinarySI)
 }
@@ -238,20 +418,43 @@ func patchContainerGPUResource(pod *corev1.Pod, podRequest corev1.ResourceList)
 	}
 }
 
-func fillGPUTotalMem(nodeDeviceTotal deviceResources, podRequest corev1.ResourceList) {
+// errGPUMemoryNotReported means the pod requests GPU memory in bytes, but the GPUs of the node report no memory,
+// so the bytes can not be converted to the memory ratio.
+var errGPUMemoryNotReported = errors.New(ErrGPUMemoryNotReported)
+
+func fillGPUTotalMem(nodeDeviceTotal deviceResources, podRequest corev1.ResourceList) error {
 	// nodeDeviceTotal uses the minor of GPU as key. However, under certain circumstances,
-	// minor 0 might not exist. We need to iterate the cache once to find the active minor.
-	var activeMinor int
-	for i := range nodeDeviceTotal {
-		activeMinor = i
-		break
+	// minor 0 might not exist, and the unhealthy GPUs report no resources.
+	// We need to iterate the cache to find a GPU reporting the total memory.
+	// a node can only contain one type of GPU, so each of them has the same total memory.
+	var totalMem resource.Quantity
+	for _, resources := range nodeDeviceTotal {
+		if mem := resources[apiext.ResourceGPUMemory]; !mem.IsZero() {
+			totalMem = mem
+			break
+		}
 	}
 
-	// a node can only contain one type of GPU, so each of them has the same total memory.
 	if gpuMem, ok := podRequest[apiext.ResourceGPUMemory]; ok {
-		podRequest[apiext.ResourceGPUMemoryRatio] = memBytesToRatio(gpuMem, nodeDeviceTotal[activeMinor][apiext.ResourceGPUMemory])
+		if totalMem.IsZero() {
+			return errGPUMemoryNotReported
+		}
+		podRequest[apiext.ResourceGPUMemoryRatio] = memBytesToRatio(gpuMem, totalMem)
 	} else {
+		// the GPUs reporting no memory are allocated by the ratio only.
 		gpuMemRatio := podRequest[apiext.ResourceGPUMemoryRatio]
-		podRequest[apiext.ResourceGPUMemory] = memRatioToBytes(gpuMemRatio, nodeDeviceTotal[activeMinor][apiext.ResourceGPUMemory])
+		podRequest[apiext.ResourceGPUMemory] = memRatioToBytes(gpuMemRatio, totalMem)
+	}
+	return nil
+}
+
+// fillGPUMemoryRatio completes koordinator.sh/gpu-memory-ratio of the GPU which only reports the memory in bytes,
+// so that the pods requesting the memory in either form are accounted consistently.
+func fillGPUMemoryRatio(resources corev1.ResourceList) {
+	if _, ok := resources[apiext.ResourceGPUMemoryRatio]; ok {
+		return
+	}
+	if gpuMem := resources[apiext.ResourceGPUMemory]; !gpuMem.IsZero() {
+		resources[apiext.ResourceGPUMemoryRatio] = *resource.NewQuantity(100, resource.DecimalSI)
 	}
 }
@@ -148,6 +148,69 @@ func Test_validateCommonDeviceRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid rdma vf request",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceRDMA:   resource.MustParse("200"),
+					apiext.ResourceRDMAVF: resource.MustParse("4"),
+				},
+				deviceType: schedulingv1alpha1.RDMA,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid nic request",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceNetBandwidth: resource.MustParse("10G"),
+				},
+				deviceType: schedulingv1alpha1.NIC,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid nic request",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceNetBandwidth: resource.MustParse("0"),
+				},
+				deviceType: schedulingv1alpha1.NIC,
+			},
+			wantErr: true,
+		},
+		{
+			name: "rdma vf request without rdma",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceRDMAVF: resource.MustParse("1"),
+				},
+				deviceType: schedulingv1alpha1.RDMA,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid rdma vf request",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceRDMA:   resource.MustParse("50"),
+					apiext.ResourceRDMAVF: resource.MustParse("0"),
+				},
+				deviceType: schedulingv1alpha1.RDMA,
+			},
+			wantErr: true,
+		},
+		{
+			name: "rdma vf cannot be evenly distributed",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceRDMA:   resource.MustParse("200"),
+					apiext.ResourceRDMAVF: resource.MustParse("3"),
+				},
+				deviceType: schedulingv1alpha1.RDMA,
+			},
+			wantErr: true,
+		},
 		{
 			name: "not common device type",
 			args: args{
@@ -225,6 +288,84 @@ func Test_validateGPURequest(t *testing.T) {
 			want:    NvidiaGPUExist,
 			wantErr: false,
 		},
+		{
+			name: "valid gpu request with nvidia gpu and gpu memory",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceNvidiaGPU: resource.MustParse("2"),
+				apiext.ResourceGPUMemory: resource.MustParse("8Gi"),
+			},
+			want:    NvidiaGPUExist | GPUMemoryExist,
+			wantErr: false,
+		},
+		{
+			name: "invalid gpu request with zero nvidia gpu and gpu memory",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceNvidiaGPU: resource.MustParse("0"),
+				apiext.ResourceGPUMemory: resource.MustParse("8Gi"),
+			},
+			want:    NvidiaGPUExist | GPUMemoryExist,
+			wantErr: true,
+		},
+		{
+			name: "valid gpu request with zero nvidia gpu",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceNvidiaGPU: resource.MustParse("0"),
+			},
+			want:    NvidiaGPUExist,
+			wantErr: false,
+		},
+		{
+			name: "invalid gpu request with nvidia gpu and zero gpu memory",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceNvidiaGPU: resource.MustParse("1"),
+				apiext.ResourceGPUMemory: resource.MustParse("0"),
+			},
+			want:    NvidiaGPUExist | GPUMemoryExist,
+			wantErr: true,
+		},
+		{
+			name: "valid gpu request with amd gpu",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceAMDGPU: resource.MustParse("2"),
+			},
+			want:    AMDGPUExist,
+			wantErr: false,
+		},
+		{
+			name: "valid gpu request with amd gpu and gpu memory",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceAMDGPU:    resource.MustParse("1"),
+				apiext.ResourceGPUMemory: resource.MustParse("8Gi"),
+			},
+			want:    AMDGPUExist | GPUMemoryExist,
+			wantErr: false,
+		},
+		{
+			name: "invalid gpu request with zero amd gpu",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceAMDGPU: resource.MustParse("0"),
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "invalid gpu request with both nvidia gpu and amd gpu",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceNvidiaGPU: resource.MustParse("1"),
+				apiext.ResourceAMDGPU:    resource.MustParse("1"),
+			},
+			want:    NvidiaGPUExist | AMDGPUExist,
+			wantErr: true,
+		},
+		{
+			name: "invalid gpu request with nvidia gpu and gpu memory ratio",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceNvidiaGPU:      resource.MustParse("1"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("50"),
+			},
+			want:    NvidiaGPUExist | GPUMemoryRatioExist,
+			wantErr: true,
+		},
 		{
 			name: "valid gpu request 2",
 			podRequest: corev1.ResourceList{
@@ -303,6 +444,32 @@ func Test_convertCommonDeviceResource(t *testing.T) {
 				apiext.ResourceRDMA: resource.MustParse("80"),
 			},
 		},
+		{
+			name: "rdma with vf",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceRDMA:   resource.MustParse("80"),
+					apiext.ResourceRDMAVF: resource.MustParse("2"),
+				},
+				deviceType: schedulingv1alpha1.RDMA,
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceRDMA:   resource.MustParse("80"),
+				apiext.ResourceRDMAVF: resource.MustParse("2"),
+			},
+		},
+		{
+			name: "nic",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceNetBandwidth: resource.MustParse("10G"),
+				},
+				deviceType: schedulingv1alpha1.NIC,
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceNetBandwidth: resource.MustParse("10G"),
+			},
+		},
 		{
 			name: "fpga",
 			args: args{
@@ -324,6 +491,57 @@ func Test_convertCommonDeviceResource(t *testing.T) {
 	}
 }
 
+func Test_validateFractionalGPURequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		podRequest corev1.ResourceList
+		want       uint
+		wantErr    bool
+	}{
+		{
+			name: "fractional gpu core and memory ratio",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("150"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("150"),
+			},
+			want: GPUCoreExist | GPUMemoryRatioExist,
+		},
+		{
+			name: "fractional koordinator gpu",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceGPU: resource.MustParse("250"),
+			},
+			want: KoordGPUExist,
+		},
+		{
+			name: "fractional gpu core and memory",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceGPUCore:   resource.MustParse("150"),
+				apiext.ResourceGPUMemory: resource.MustParse("24Gi"),
+			},
+			want: GPUCoreExist | GPUMemoryExist,
+		},
+		{
+			name: "memory ratio taken by the full GPUs",
+			podRequest: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("150"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+			},
+			want:    GPUCoreExist | GPUMemoryRatioExist,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateGPURequest(tt.podRequest)
+			assert.Error(t, err)
+			got, err := validateGPURequest(tt.podRequest, true)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func Test_convertGPUResource(t *testing.T) {
 	type args struct {
 		podRequest     corev1.ResourceList
@@ -365,6 +583,47 @@ func Test_convertGPUResource(t *testing.T) {
 				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(200, resource.DecimalSI),
 			},
 		},
+		{
+			name: "nvidiaGpuExist with gpu memory",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceNvidiaGPU: resource.MustParse("2"),
+					apiext.ResourceGPUMemory: resource.MustParse("8Gi"),
+				},
+				gpuCombination: NvidiaGPUExist | GPUMemoryExist,
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceGPUCore:   *resource.NewQuantity(200, resource.DecimalSI),
+				apiext.ResourceGPUMemory: *resource.NewQuantity(16*1024*1024*1024, resource.BinarySI),
+			},
+		},
+		{
+			name: "amdGpuExist",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceAMDGPU: resource.MustParse("2"),
+				},
+				gpuCombination: AMDGPUExist,
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceGPUCore:        *resource.NewQuantity(200, resource.DecimalSI),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(200, resource.DecimalSI),
+			},
+		},
+		{
+			name: "amdGpuExist with gpu memory",
+			args: args{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceAMDGPU:    resource.MustParse("2"),
+					apiext.ResourceGPUMemory: resource.MustParse("8Gi"),
+				},
+				gpuCombination: AMDGPUExist | GPUMemoryExist,
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceGPUCore:   *resource.NewQuantity(200, resource.DecimalSI),
+				apiext.ResourceGPUMemory: *resource.NewQuantity(16*1024*1024*1024, resource.BinarySI),
+			},
+		},
 		{
 			name: "koordGpuExist",
 			args: args{
@@ -670,6 +929,7 @@ func Test_fillGPUTotalMem(t *testing.T) {
 	}
 	type wants struct {
 		podRequest corev1.ResourceList
+		err        error
 	}
 	tests := []struct {
 		name  string
@@ -722,11 +982,149 @@ func Test_fillGPUTotalMem(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "skip unhealthy GPU reporting no resources",
+			args: args{
+				gpuTotal: deviceResources{
+					0: corev1.ResourceList{},
+					1: corev1.ResourceList{
+						apiext.ResourceGPUCore:        resource.MustParse("100"),
+						apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+						apiext.ResourceGPUMemory:      resource.MustParse("32Gi"),
+					},
+				},
+				podRequest: corev1.ResourceList{
+					apiext.ResourceGPUCore:   resource.MustParse("100"),
+					apiext.ResourceGPUMemory: *resource.NewQuantity(8*1024*1024*1024, resource.BinarySI),
+				},
+			},
+			wants: wants{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceGPUCore:        resource.MustParse("100"),
+					apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(25, resource.DecimalSI),
+					apiext.ResourceGPUMemory:      resource.MustParse("8Gi"),
+				},
+			},
+		},
+		{
+			name: "mem requested but node reports zero memory",
+			args: args{
+				gpuTotal: deviceResources{
+					0: corev1.ResourceList{
+						apiext.ResourceGPUCore:        resource.MustParse("100"),
+						apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+					},
+				},
+				podRequest: corev1.ResourceList{
+					apiext.ResourceGPUCore:   resource.MustParse("100"),
+					apiext.ResourceGPUMemory: resource.MustParse("16Gi"),
+				},
+			},
+			wants: wants{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceGPUCore:   resource.MustParse("100"),
+					apiext.ResourceGPUMemory: resource.MustParse("16Gi"),
+				},
+				err: errGPUMemoryNotReported,
+			},
+		},
+		{
+			name: "ratio requested and node reports zero memory",
+			args: args{
+				gpuTotal: deviceResources{
+					0: corev1.ResourceList{
+						apiext.ResourceGPUCore:        resource.MustParse("100"),
+						apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+					},
+				},
+				podRequest: corev1.ResourceList{
+					apiext.ResourceGPUCore:        resource.MustParse("50"),
+					apiext.ResourceGPUMemoryRatio: resource.MustParse("50"),
+				},
+			},
+			wants: wants{
+				podRequest: corev1.ResourceList{
+					apiext.ResourceGPUCore:        resource.MustParse("50"),
+					apiext.ResourceGPUMemoryRatio: resource.MustParse("50"),
+					apiext.ResourceGPUMemory:      *resource.NewQuantity(0, resource.BinarySI),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fillGPUTotalMem(tt.args.gpuTotal, tt.args.podRequest)
+			err := fillGPUTotalMem(tt.args.gpuTotal, tt.args.podRequest)
+			assert.Equal(t, tt.wants.err, err)
 			assert.Equal(t, tt.wants.podRequest, tt.args.podRequest)
 		})
 	}
 }
+
+func Test_fillGPUMemoryRatio(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources corev1.ResourceList
+		want      corev1.ResourceList
+	}{
+		{
+			name: "ratio-less GPU",
+			resources: corev1.ResourceList{
+				apiext.ResourceGPUCore:   resource.MustParse("100"),
+				apiext.ResourceGPUMemory: resource.MustParse("16Gi"),
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("100"),
+				apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+				apiext.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		},
+		{
+			name: "GPU reporting ratio",
+			resources: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("100"),
+				apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceGPUCore:        resource.MustParse("100"),
+				apiext.ResourceGPUMemory:      resource.MustParse("16Gi"),
+				apiext.ResourceGPUMemoryRatio: resource.MustParse("100"),
+			},
+		},
+		{
+			name: "GPU reporting no memory",
+			resources: corev1.ResourceList{
+				apiext.ResourceGPUCore: resource.MustParse("100"),
+			},
+			want: corev1.ResourceList{
+				apiext.ResourceGPUCore: resource.MustParse("100"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fillGPUMemoryRatio(tt.resources)
+			assert.Equal(t, tt.want, tt.resources)
+		})
+	}
+}
+
+func Test_matchGPUVendor(t *testing.T) {
+	tests := []struct {
+		name        string
+		combination uint
+		nodeVendor  string
+		want        bool
+	}{
+		{name: "any vendor", combination: KoordGPUExist, nodeVendor: apiext.GPUVendorAMD, want: true},
+		{name: "nvidia gpu on node without vendor", combination: NvidiaGPUExist, nodeVendor: "", want: true},
+		{name: "nvidia gpu on amd node", combination: NvidiaGPUExist, nodeVendor: apiext.GPUVendorAMD, want: false},
+		{name: "amd gpu on node without vendor", combination: AMDGPUExist, nodeVendor: "", want: false},
+		{name: "amd gpu on amd node", combination: AMDGPUExist | GPUMemoryExist, nodeVendor: apiext.GPUVendorAMD, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchGPUVendor(getRequiredGPUVendor(tt.combination), tt.nodeVendor))
+		})
+	}
+}
@@ -277,6 +277,14 @@ func (p *podWrapper) Label(string1, string2 string) *podWrapper {
 	return p
 }
 
+func (p *podWrapper) Annotation(key, value string) *podWrapper {
+	if p.Annotations == nil {
+		p.Annotations = make(map[string]string)
+	}
+	p.Annotations[key] = value
+	return p
+}
+
 func (p *podWrapper) Phase(phase v1.PodPhase) *podWrapper {
 	p.Pod.Status.Phase = phase
 	return p
@@ -334,6 +342,16 @@ func (r *resourceWrapper) GPU(val int64) *resourceWrapper {
 	return r
 }
 
+func (r *resourceWrapper) BatchCPU(val int64) *resourceWrapper {
+	r.ResourceList[extension.BatchCPU] = *resource.NewQuantity(val, resource.DecimalSI)
+	return r
+}
+
+func (r *resourceWrapper) BatchMem(val int64) *resourceWrapper {
+	r.ResourceList[extension.BatchMemory] = *resource.NewQuantity(val, resource.DecimalSI)
+	return r
+}
+
 func (r *resourceWrapper) Obj() v1.ResourceList {
 	return r.ResourceList
 }
@@ -220,6 +220,12 @@ func (qi *QuotaInfo) GetUsed() v1.ResourceList {
 	return qi.CalculateInfo.Used.DeepCopy()
 }
 
+func (qi *QuotaInfo) GetMin() v1.ResourceList {
+	qi.lock.Lock()
+	defer qi.lock.Unlock()
+	return qi.CalculateInfo.Min.DeepCopy()
+}
+
 func (qi *QuotaInfo) GetRuntime() v1.ResourceList {
 	qi.lock.Lock()
 	defer qi.lock.Unlock()
@@ -239,14 +245,41 @@ func NewQuotaInfoFromQuota(quota *v1alpha1.ElasticQuota) *QuotaInfo {
 	allowLentResource := extension.IsAllowLentResource(quota)
 
 	quotaInfo := NewQuotaInfo(isParent, allowLentResource, quota.Name, parentName)
-	quotaInfo.setMinQuotaNoLock(quota.Spec.Min)
-	quotaInfo.setMaxQuotaNoLock(quota.Spec.Max)
+	min, max := quota.Spec.Min, quota.Spec.Max
 	newSharedWeight := extension.GetSharedWeight(quota)
+	// the batch tier overrides the batch-cpu and batch-memory in the spec, and its max is the default shared weight
+	if tier, err := extension.GetQuotaBatchTier(quota); err != nil {
+		klog.Errorf("failed to get the batch tier of quota %v, err: %v", quota.Name, err)
+	} else if tier != nil {
+		min, max = mergeQuotaTier(min, tier.Min), mergeQuotaTier(max, tier.Max)
+		if newSharedWeight == nil {
+			newSharedWeight = v1.ResourceList{}
+		}
+		for resourceName, quantity := range tier.Max {
+			if _, ok := newSharedWeight[resourceName]; !ok {
+				newSharedWeight[resourceName] = quantity.DeepCopy()
+			}
+		}
+	}
+	quotaInfo.setMinQuotaNoLock(min)
+	quotaInfo.setMaxQuotaNoLock(max)
 	quotaInfo.setSharedWeightNoLock(newSharedWeight)
 
 	return quotaInfo
 }
 
+// mergeQuotaTier returns a copy of the resources whose tier dimensions are overridden by the tier.
+func mergeQuotaTier(resources, tier v1.ResourceList) v1.ResourceList {
+	merged := resources.DeepCopy()
+	if merged == nil {
+		merged = v1.ResourceList{}
+	}
+	for resourceName, quantity := range tier {
+		merged[resourceName] = quantity.DeepCopy()
+	}
+	return merged
+}
+
 func (qi *QuotaInfo) getMaskedRuntimeNoLock() v1.ResourceList {
 	return quotav1.Mask(qi.CalculateInfo.Runtime, quotav1.ResourceNames(qi.CalculateInfo.Max))
 }
@@ -20,7 +20,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schetesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
 )
 
 func TestQuotaInfo_AddPodIfNotPresent_RemovePodIfPresent_GetPodCache(t *testing.T) {
@@ -112,3 +118,36 @@ func TestUpdateQuotaInfoFromRemote(t *testing.T) {
 	assert.NotEqual(t, qi.CalculateInfo.Request, remoteQuotaInfo.CalculateInfo.Request)
 	assert.NotEqual(t, qi.CalculateInfo.Runtime, remoteQuotaInfo.CalculateInfo.Runtime)
 }
+
+func TestNewQuotaInfoFromQuota_batchTier(t *testing.T) {
+	quota := &v1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			Annotations: map[string]string{
+				extension.AnnotationSharedWeight: `{"cpu":"20"}`,
+				extension.AnnotationQuotaBatchTier: `{"min":{"koordinator.sh/batch-cpu":"4000","cpu":"100"},` +
+					`"max":{"koordinator.sh/batch-cpu":"8000","koordinator.sh/batch-memory":"16Gi"}}`,
+			},
+		},
+		Spec: v1alpha1.ElasticQuotaSpec{
+			Min: v1.ResourceList{v1.ResourceCPU: resource.MustParse("10")},
+			Max: v1.ResourceList{v1.ResourceCPU: resource.MustParse("20"), extension.BatchCPU: resource.MustParse("1000")},
+		},
+	}
+	quotaInfo := NewQuotaInfoFromQuota(quota)
+	// the cpu out of the batch tier is ignored
+	assert.Equal(t, v1.ResourceList{
+		v1.ResourceCPU:     resource.MustParse("10"),
+		extension.BatchCPU: resource.MustParse("4000"),
+	}, quotaInfo.CalculateInfo.Min)
+	assert.Equal(t, v1.ResourceList{
+		v1.ResourceCPU:        resource.MustParse("20"),
+		extension.BatchCPU:    resource.MustParse("8000"),
+		extension.BatchMemory: resource.MustParse("16Gi"),
+	}, quotaInfo.CalculateInfo.Max)
+	assert.Equal(t, v1.ResourceList{
+		v1.ResourceCPU:        resource.MustParse("20"),
+		extension.BatchCPU:    resource.MustParse("8000"),
+		extension.BatchMemory: resource.MustParse("16Gi"),
+	}, quotaInfo.CalculateInfo.SharedWeight)
+}
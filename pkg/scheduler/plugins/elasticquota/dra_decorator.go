@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedulingv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+func init() {
+	core.RegisterDecorator(&draDeviceResourcesDecorator{})
+}
+
+// draDeviceResourcesDecorator adds the device resources of the DRA ResourceClaims recorded on the scheduled pods
+// by the DeviceShare plugin to their requests, so that the resources are charged to the quotas.
+type draDeviceResourcesDecorator struct{}
+
+func (d *draDeviceResourcesDecorator) Init(handle framework.Handle) error {
+	return nil
+}
+
+func (d *draDeviceResourcesDecorator) DecorateNode(node *corev1.Node) *corev1.Node {
+	return node
+}
+
+func (d *draDeviceResourcesDecorator) DecoratePod(pod *corev1.Pod) *corev1.Pod {
+	// the resources of the pending pods are added to the requests by the DeviceShare plugin before PreFilter
+	if pod.Spec.NodeName == "" || len(pod.Spec.Containers) == 0 {
+		return pod
+	}
+	resources, err := apiext.GetDRADeviceResources(pod.Annotations)
+	if err != nil || len(resources) == 0 {
+		return pod
+	}
+	container := &pod.Spec.Containers[0]
+	container.Resources.Requests = quotav1.Add(container.Resources.Requests, resources)
+	return pod
+}
+
+func (d *draDeviceResourcesDecorator) DecorateElasticQuota(quota *schedulingv1alpha1.ElasticQuota) *schedulingv1alpha1.ElasticQuota {
+	return quota
+}
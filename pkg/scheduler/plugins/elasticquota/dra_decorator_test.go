@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+func TestDRADeviceResourcesDecorator(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod",
+			Annotations: map[string]string{
+				apiext.AnnotationDRADeviceResources: `{"koordinator.sh/gpu-core":"100"}`,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					},
+				},
+			},
+		},
+	}
+	cpuOnly := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+
+	// the pending pod is not decorated since the resources are added before PreFilter
+	request, _ := resourceapi.PodRequestsAndLimits(core.RunDecoratePod(pod))
+	assert.True(t, quotav1.Equals(cpuOnly, request))
+
+	pod.Spec.NodeName = "test-node"
+	request, _ = resourceapi.PodRequestsAndLimits(core.RunDecoratePod(pod))
+	assert.True(t, quotav1.Equals(corev1.ResourceList{
+		corev1.ResourceCPU:     resource.MustParse("1"),
+		apiext.ResourceGPUCore: resource.MustParse("100"),
+	}, request))
+	// the original pod is not changed
+	request, _ = resourceapi.PodRequestsAndLimits(pod)
+	assert.True(t, quotav1.Equals(cpuOnly, request))
+}
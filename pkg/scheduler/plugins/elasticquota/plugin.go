@@ -36,11 +36,15 @@ import (
 	"sigs.k8s.io/scheduler-plugins/pkg/generated/informers/externalversions"
 	"sigs.k8s.io/scheduler-plugins/pkg/generated/listers/scheduling/v1alpha1"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	schedulinglisters "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/reservation"
 )
 
 const (
@@ -51,11 +55,14 @@ const (
 
 type PostFilterState struct {
 	quotaInfo *core.QuotaInfo
+	// reservationCredited is set if the pod fits the quota only by taking over the charge of a matched reservation.
+	reservationCredited bool
 }
 
 func (p *PostFilterState) Clone() framework.StateData {
 	return &PostFilterState{
-		quotaInfo: p.quotaInfo.DeepCopy(),
+		quotaInfo:           p.quotaInfo.DeepCopy(),
+		reservationCredited: p.reservationCredited,
 	}
 }
 
@@ -71,6 +78,8 @@ type Plugin struct {
 	nodeResourceMapLock sync.Mutex
 	nodeResourceMap     map[string]struct{}
 	groupQuotaManager   *core.GroupQuotaManager
+	reservationCache    *reservationQuotaCache
+	reservationLister   schedulinglisters.ReservationLister
 }
 
 var (
@@ -108,6 +117,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		nodeLister:        handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
 		groupQuotaManager: core.NewGroupQuotaManager(pluginArgs.SystemQuotaGroupMax, pluginArgs.DefaultQuotaGroupMax),
 		nodeResourceMap:   make(map[string]struct{}),
+		reservationCache:  newReservationQuotaCache(),
 	}
 	if err := core.RunDecorateInit(handle); err != nil {
 		return nil, err
@@ -137,6 +147,8 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		DeleteFunc: elasticQuota.OnPodDelete,
 	})
 
+	elasticQuota.registerReservationEventHandler()
+
 	elasticQuota.migrateDefaultQuotaGroupsPod()
 
 	return elasticQuota, nil
@@ -151,7 +163,29 @@ func (g *Plugin) NewControllers() ([]frameworkext.Controller, error) {
 	quotaOverUsedRevokeController := NewQuotaOverUsedRevokeController(g.handle.ClientSet(), g.pluginArgs.DelayEvictTime.Duration,
 		g.pluginArgs.RevokePodInterval.Duration, g.groupQuotaManager, *g.pluginArgs.MonitorAllQuotas)
 	elasticQuotaController := NewElasticQuotaController(g.client, g.quotaLister, g.groupQuotaManager)
-	return []frameworkext.Controller{g, quotaOverUsedRevokeController, elasticQuotaController}, nil
+	scheduledQuotaController := NewScheduledQuotaController(g.client, g.quotaLister)
+	controllers := []frameworkext.Controller{g, quotaOverUsedRevokeController, elasticQuotaController, scheduledQuotaController}
+	if g.pluginArgs.MinStarvationDuration != nil && g.pluginArgs.MinStarvationDuration.Duration > 0 {
+		extendedHandle, ok := g.handle.(frameworkext.ExtendedHandle)
+		if !ok {
+			return nil, fmt.Errorf("expect handle to be type frameworkext.ExtendedHandle, got %T", g.handle)
+		}
+		quotaMinStarvedMigrateController := NewQuotaMinStarvedMigrateController(extendedHandle.KoordinatorClientSet(),
+			g.pluginArgs.MinStarvationDuration.Duration, g.pluginArgs.RevokePodInterval.Duration, g.groupQuotaManager)
+		controllers = append(controllers, quotaMinStarvedMigrateController)
+	}
+	if g.isSchedulingGateEnabled() {
+		quotaSchedulingGateController := NewQuotaSchedulingGateController(g.handle.ClientSet(),
+			g.handle.SharedInformerFactory().Core().V1().Pods().Lister(), g.groupQuotaManager,
+			g.getPodAssociateQuotaName, g.pluginArgs.SchedulingGateInterval.Duration)
+		controllers = append(controllers, quotaSchedulingGateController)
+	}
+	return controllers, nil
+}
+
+// isSchedulingGateEnabled checks if the QuotaSchedulingGateController runs, which is the only one to release the gates.
+func (g *Plugin) isSchedulingGateEnabled() bool {
+	return g.pluginArgs.SchedulingGateInterval != nil && g.pluginArgs.SchedulingGateInterval.Duration > 0
 }
 
 func (g *Plugin) Name() string {
@@ -159,6 +193,10 @@ func (g *Plugin) Name() string {
 }
 
 func (g *Plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) *framework.Status {
+	// the gated pod is requeued only when the gate is released, see eventhandlers.AddQuotaSchedulingGateErrorHandler
+	if g.isSchedulingGateEnabled() && extension.IsPodQuotaSchedulingGated(pod) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, extension.ReasonQuotaSchedulingGated)
+	}
 	quotaName := g.getPodAssociateQuotaName(pod)
 	g.groupQuotaManager.RefreshRuntime(quotaName)
 	quotaInfo := g.groupQuotaManager.GetQuotaInfoByName(quotaName)
@@ -166,21 +204,53 @@ func (g *Plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod
 		return framework.NewStatus(framework.Error, fmt.Sprintf("Could not find the specified ElasticQuota"))
 	}
 	g.snapshotPostFilterState(quotaName, state)
-	quotaUsed := quotaInfo.GetUsed()
-	quotaRuntime := quotaInfo.GetRuntime()
 
 	pod = core.RunDecoratePod(pod)
 	podRequest, _ := resource.PodRequestsAndLimits(pod)
-	newUsed := quotav1.Add(podRequest, quotaUsed)
 
-	if isLessEqual, exceedDimensions := quotav1.LessThanOrEqual(newUsed, quotaRuntime); !isLessEqual {
+	status, credited := g.checkQuotaWithReservations(quotaInfo, podRequest, reservation.GetMatchedReservations(state))
+	if credited {
+		if postFilterState, err := getPostFilterState(state); err == nil {
+			postFilterState.reservationCredited = true
+		}
+	}
+	return status
+}
+
+// checkQuotaWithReservations checks the quota for the pod request, and if it does not fit, checks again for each of the
+// matched reservations with the request less the unconsumed resources of the reservation charged to the same quota,
+// since the pod allocating the reservation takes over the charge, which must not be counted twice. It returns true
+// if the pod fits the quota only with the charge of a reservation.
+func (g *Plugin) checkQuotaWithReservations(quotaInfo *core.QuotaInfo, podRequest corev1.ResourceList,
+	reservations []*schedulingv1alpha1.Reservation) (*framework.Status, bool) {
+	status := g.checkQuota(quotaInfo, podRequest)
+	if status.IsSuccess() {
+		return status, false
+	}
+	for _, r := range reservations {
+		charge := g.getReservationQuotaCharge(r.UID, quotaInfo.Name)
+		if charge == nil {
+			continue
+		}
+		if g.checkQuota(quotaInfo, quotav1.SubtractWithNonNegativeResult(podRequest, charge)).IsSuccess() {
+			return framework.NewStatus(framework.Success, ""), true
+		}
+	}
+	return status, false
+}
+
+// checkQuota checks if the quota and its ancestors if enabled have the headroom for the pod request.
+func (g *Plugin) checkQuota(quotaInfo *core.QuotaInfo, podRequest corev1.ResourceList) *framework.Status {
+	quotaUsed := quotaInfo.GetUsed()
+	quotaRuntime := quotaInfo.GetRuntime()
+	if isLessEqual, exceedDimensions := isQuotaFitForRequest(quotaUsed, podRequest, quotaRuntime); !isLessEqual {
 		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Scheduling refused due to insufficient quotas, "+
 			"quotaName: %v, runtime: %v, used: %v, pod's request: %v, exceedDimensions: %v",
-			quotaName, printResourceList(quotaRuntime), printResourceList(quotaUsed), printResourceList(podRequest), exceedDimensions))
+			quotaInfo.Name, printResourceList(quotaRuntime), printResourceList(quotaUsed), printResourceList(podRequest), exceedDimensions))
 	}
 
 	if *g.pluginArgs.EnableCheckParentQuota {
-		return g.checkQuotaRecursive(quotaName, []string{quotaName}, podRequest)
+		return g.checkQuotaRecursive(quotaInfo.Name, []string{quotaInfo.Name}, podRequest)
 	}
 
 	return framework.NewStatus(framework.Success, "")
@@ -245,11 +315,33 @@ func (g *Plugin) PostFilter(ctx context.Context, state *framework.CycleState, po
 
 func (g *Plugin) Reserve(ctx context.Context, state *framework.CycleState, p *corev1.Pod, nodeName string) *framework.Status {
 	quotaName := g.getPodAssociateQuotaName(p)
+	r := reservation.GetAssumedReservation(state)
+	// recheck the quota if the pod is admitted by the charge of a reservation, which it may not allocate on the node
+	if postFilterState, err := getPostFilterState(state); err == nil && postFilterState.reservationCredited {
+		if quotaInfo := g.groupQuotaManager.GetQuotaInfoByName(quotaName); quotaInfo != nil {
+			podRequest, _ := resource.PodRequestsAndLimits(core.RunDecoratePod(p))
+			if r != nil {
+				if charge := g.getReservationQuotaCharge(r.UID, quotaName); charge != nil {
+					podRequest = quotav1.SubtractWithNonNegativeResult(podRequest, charge)
+				}
+			}
+			if status := g.checkQuota(quotaInfo, podRequest); !status.IsSuccess() {
+				return status
+			}
+		}
+	}
 	g.groupQuotaManager.ReservePod(quotaName, p)
+	// the pod takes over the charge of the resources reserved by the reservation it allocates
+	if r != nil {
+		g.assumeReservationConsumer(r.UID, p)
+	}
 	return framework.NewStatus(framework.Success, "")
 }
 
 func (g *Plugin) Unreserve(ctx context.Context, state *framework.CycleState, p *corev1.Pod, nodeName string) {
 	quotaName := g.getPodAssociateQuotaName(p)
 	g.groupQuotaManager.UnreservePod(quotaName, p)
+	if r := reservation.GetAssumedReservation(state); r != nil {
+		g.forgetReservationConsumer(r.UID, p)
+	}
 }
@@ -172,8 +172,7 @@ func (g *Plugin) checkQuotaRecursive(curQuotaName string, quotaNameTopo []string
 	quotaInfo := g.groupQuotaManager.GetQuotaInfoByName(curQuotaName)
 	quotaUsed := quotaInfo.GetUsed()
 	quotaRuntime := quotaInfo.GetRuntime()
-	newUsed := quotav1.Add(podRequest, quotaUsed)
-	if isLessEqual, exceedDimensions := quotav1.LessThanOrEqual(newUsed, quotaRuntime); !isLessEqual {
+	if isLessEqual, exceedDimensions := isQuotaFitForRequest(quotaUsed, podRequest, quotaRuntime); !isLessEqual {
 		return framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Scheduling refused due to insufficient quotas, "+
 			"quotaNameTopo: %v, runtime: %v, used: %v, pod's request: %v, exceedDimensions: %v", quotaNameTopo,
 			printResourceList(quotaRuntime), printResourceList(quotaUsed), printResourceList(podRequest), exceedDimensions))
@@ -185,6 +184,51 @@ func (g *Plugin) checkQuotaRecursive(curQuotaName string, quotaNameTopo []string
 	return framework.NewStatus(framework.Success, "")
 }
 
+// quotaResourceTiers are the guaranteed cpu and memory and the batch-cpu and batch-memory overcommitted by the batch
+// pods, which are accounted independently in the quota.
+var quotaResourceTiers = [][]v1.ResourceName{
+	{v1.ResourceCPU, v1.ResourceMemory},
+	{extension.BatchCPU, extension.BatchMemory},
+}
+
+// getQuotaResourceTier returns the tier of the resource, or nil if the resource is not tiered.
+func getQuotaResourceTier(resourceName v1.ResourceName) []v1.ResourceName {
+	for _, tier := range quotaResourceTiers {
+		for _, name := range tier {
+			if name == resourceName {
+				return tier
+			}
+		}
+	}
+	return nil
+}
+
+// isQuotaFitForRequest checks if the used of the quota fits the runtime after adding the pod request. The tiers the pod
+// requests nothing of are skipped, so the over-used batch resources, whose runtime shrinks with the batch allocatable
+// of the nodes, do not block the pods requesting only the guaranteed resources, and vice versa. The other dimensions,
+// e.g. the GPUs, are always checked.
+func isQuotaFitForRequest(used, podRequest, runtime v1.ResourceList) (bool, []v1.ResourceName) {
+	newUsed := quotav1.Add(used, podRequest)
+	for _, tier := range quotaResourceTiers {
+		if quotav1.IsZero(quotav1.Mask(podRequest, tier)) {
+			newUsed = quotav1.Mask(newUsed, quotav1.Difference(quotav1.ResourceNames(newUsed), tier))
+		}
+	}
+	return quotav1.LessThanOrEqual(newUsed, runtime)
+}
+
+// isPodHoldingExceededResources checks if revoking the pod releases any of the over-used dimensions. Only the pods
+// requesting nothing of the over-used tiers are skipped, e.g. the batch pods when the guaranteed resources are over-used.
+func isPodHoldingExceededResources(podRequest v1.ResourceList, exceedDimensions []v1.ResourceName) bool {
+	for _, resourceName := range exceedDimensions {
+		tier := getQuotaResourceTier(resourceName)
+		if tier == nil || !quotav1.IsZero(quotav1.Mask(podRequest, tier)) {
+			return true
+		}
+	}
+	return false
+}
+
 func printResourceList(rl v1.ResourceList) string {
 	res := make([]string, 0)
 	for k, v := range rl {
@@ -91,3 +91,61 @@ func TestGetQuotaName(t *testing.T) {
 		})
 	}
 }
+
+func TestIsQuotaFitForRequest(t *testing.T) {
+	runtime := MakeResourceList().CPU(10).Mem(20).BatchCPU(2000).BatchMem(20).GPU(1).Obj()
+	tests := []struct {
+		name           string
+		used           corev1.ResourceList
+		podRequest     corev1.ResourceList
+		wantFit        bool
+		wantExceedDims []corev1.ResourceName
+	}{
+		{
+			name:       "batch tier over-used, the guaranteed pod fits",
+			used:       MakeResourceList().CPU(1).Mem(2).BatchCPU(4000).Obj(),
+			podRequest: MakeResourceList().CPU(1).Mem(2).Obj(),
+			wantFit:    true,
+		},
+		{
+			name:       "guaranteed tier over-used, the batch pod fits",
+			used:       MakeResourceList().CPU(20).Mem(2).Obj(),
+			podRequest: MakeResourceList().BatchCPU(1000).BatchMem(2).Obj(),
+			wantFit:    true,
+		},
+		{
+			name:           "the pod requesting both tiers is blocked by the batch tier",
+			used:           MakeResourceList().BatchCPU(2000).Obj(),
+			podRequest:     MakeResourceList().CPU(1).BatchCPU(1000).Obj(),
+			wantExceedDims: []corev1.ResourceName{extension.BatchCPU},
+		},
+		{
+			name:           "the gpu over-used blocks the guaranteed pod",
+			used:           MakeResourceList().CPU(1).GPU(2).Obj(),
+			podRequest:     MakeResourceList().CPU(1).Mem(2).Obj(),
+			wantExceedDims: []corev1.ResourceName{"nvidia.com/gpu"},
+		},
+		{
+			name:           "the gpu over-used blocks the batch pod",
+			used:           MakeResourceList().GPU(2).Obj(),
+			podRequest:     MakeResourceList().BatchCPU(1000).Obj(),
+			wantExceedDims: []corev1.ResourceName{"nvidia.com/gpu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fit, exceedDims := isQuotaFitForRequest(tt.used, tt.podRequest, runtime)
+			assert.Equal(t, tt.wantFit, fit)
+			assert.Equal(t, tt.wantExceedDims, exceedDims)
+		})
+	}
+}
+
+func TestIsPodHoldingExceededResources(t *testing.T) {
+	batchPodRequest := MakeResourceList().BatchCPU(1000).BatchMem(2).Obj()
+	assert.False(t, isPodHoldingExceededResources(batchPodRequest, []corev1.ResourceName{corev1.ResourceCPU}))
+	assert.True(t, isPodHoldingExceededResources(batchPodRequest, []corev1.ResourceName{extension.BatchMemory}))
+	// the pods are revoked for the non-tiered dimensions as before
+	assert.True(t, isPodHoldingExceededResources(batchPodRequest, []corev1.ResourceName{"nvidia.com/gpu"}))
+	assert.True(t, isPodHoldingExceededResources(MakeResourceList().Mem(2).Obj(), []corev1.ResourceName{corev1.ResourceCPU}))
+}
@@ -784,6 +784,8 @@ func TestPlugin_PreFilter(t *testing.T) {
 		name           string
 		pod            *corev1.Pod
 		quotaInfo      *core.QuotaInfo
+		used           corev1.ResourceList
+		schedulingGate bool
 		expectedStatus framework.Status
 		checkParent    bool
 	}{
@@ -814,6 +816,31 @@ func TestPlugin_PreFilter(t *testing.T) {
 			},
 			expectedStatus: *framework.NewStatus(framework.Success, ""),
 		},
+		{
+			name: "scheduling gated by the quota",
+			pod: MakePod("t1-ns1", "pod1").Annotation(extension.AnnotationQuotaSchedulingGated, "true").Container(
+				MakeResourceList().CPU(1).Mem(2).GPU(1).Obj()).Obj(),
+			quotaInfo: &core.QuotaInfo{
+				Name: extension.DefaultQuotaName,
+				CalculateInfo: core.QuotaCalculateInfo{
+					Runtime: MakeResourceList().CPU(10).Mem(20).GPU(10).Obj(),
+				},
+			},
+			schedulingGate: true,
+			expectedStatus: *framework.NewStatus(framework.UnschedulableAndUnresolvable, extension.ReasonQuotaSchedulingGated),
+		},
+		{
+			name: "scheduling gate is not enforced without the gate controller",
+			pod: MakePod("t1-ns1", "pod1").Annotation(extension.AnnotationQuotaSchedulingGated, "true").Container(
+				MakeResourceList().CPU(1).Mem(2).GPU(1).Obj()).Obj(),
+			quotaInfo: &core.QuotaInfo{
+				Name: extension.DefaultQuotaName,
+				CalculateInfo: core.QuotaCalculateInfo{
+					Runtime: MakeResourceList().CPU(10).Mem(20).GPU(10).Obj(),
+				},
+			},
+			expectedStatus: *framework.NewStatus(framework.Success, ""),
+		},
 		{
 			name: "value not enough",
 			pod: MakePod("t1-ns1", "pod1").Container(
@@ -842,15 +869,81 @@ func TestPlugin_PreFilter(t *testing.T) {
 			},
 			expectedStatus: *framework.NewStatus(framework.Success, ""),
 		},
+		{
+			name: "batch resources over-used, the pod requesting guaranteed resources is not blocked",
+			pod: MakePod("t1-ns1", "pod1").Container(
+				MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+			quotaInfo: &core.QuotaInfo{
+				Name: extension.DefaultQuotaName,
+				CalculateInfo: core.QuotaCalculateInfo{
+					Runtime: MakeResourceList().CPU(10).Mem(20).BatchCPU(1000).BatchMem(10).Obj(),
+				},
+			},
+			used:           MakeResourceList().CPU(1).Mem(2).BatchCPU(2000).BatchMem(20).Obj(),
+			expectedStatus: *framework.NewStatus(framework.Success, ""),
+		},
+		{
+			name: "guaranteed resources over-used, the batch pod is not blocked",
+			pod: MakePod("t1-ns1", "pod1").Container(
+				MakeResourceList().BatchCPU(1000).BatchMem(2).Obj()).Obj(),
+			quotaInfo: &core.QuotaInfo{
+				Name: extension.DefaultQuotaName,
+				CalculateInfo: core.QuotaCalculateInfo{
+					Runtime: MakeResourceList().CPU(10).Mem(20).BatchCPU(4000).BatchMem(20).Obj(),
+				},
+			},
+			used:           MakeResourceList().CPU(20).Mem(20).BatchCPU(2000).Obj(),
+			expectedStatus: *framework.NewStatus(framework.Success, ""),
+		},
+		{
+			name: "batch resources not enough",
+			pod: MakePod("t1-ns1", "pod1").Container(
+				MakeResourceList().BatchCPU(1000).BatchMem(2).Obj()).Obj(),
+			quotaInfo: &core.QuotaInfo{
+				Name: extension.DefaultQuotaName,
+				CalculateInfo: core.QuotaCalculateInfo{
+					Runtime: MakeResourceList().CPU(10).Mem(20).BatchCPU(2000).BatchMem(20).Obj(),
+				},
+			},
+			used: MakeResourceList().CPU(1).Mem(2).BatchCPU(2000).Obj(),
+			expectedStatus: *framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Scheduling refused due to insufficient quotas, "+
+				"quotaName: %v, runtime: %v, used: %v, pod's request: %v, exceedDimensions: [%v]",
+				extension.DefaultQuotaName, printResourceList(MakeResourceList().CPU(10).Mem(20).BatchCPU(2000).BatchMem(20).Obj()),
+				printResourceList(MakeResourceList().CPU(1).Mem(2).BatchCPU(2000).Obj()),
+				printResourceList(MakeResourceList().BatchCPU(1000).BatchMem(2).Obj()), extension.BatchCPU)),
+		},
+		{
+			name: "non-tiered resources over-used, the pod requesting guaranteed resources is blocked",
+			pod: MakePod("t1-ns1", "pod1").Container(
+				MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+			quotaInfo: &core.QuotaInfo{
+				Name: extension.DefaultQuotaName,
+				CalculateInfo: core.QuotaCalculateInfo{
+					Runtime: MakeResourceList().CPU(10).Mem(20).GPU(1).Obj(),
+				},
+			},
+			used: MakeResourceList().CPU(1).Mem(2).GPU(2).Obj(),
+			expectedStatus: *framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Scheduling refused due to insufficient quotas, "+
+				"quotaName: %v, runtime: %v, used: %v, pod's request: %v, exceedDimensions: [%v]",
+				extension.DefaultQuotaName, printResourceList(MakeResourceList().CPU(10).Mem(20).GPU(1).Obj()),
+				printResourceList(MakeResourceList().CPU(1).Mem(2).GPU(2).Obj()),
+				printResourceList(MakeResourceList().CPU(1).Mem(2).Obj()), "nvidia.com/gpu")),
+		},
 	}
 	for _, tt := range test {
 		t.Run(tt.name, func(t *testing.T) {
 			suit := newPluginTestSuit(t, nil)
+			if tt.schedulingGate {
+				suit.elasticQuotaArgs.SchedulingGateInterval = &metav1.Duration{Duration: time.Second}
+			}
 			p, _ := suit.proxyNew(suit.elasticQuotaArgs, suit.Handle)
 			gp := p.(*Plugin)
 			qi := gp.groupQuotaManager.GetQuotaInfoByName(tt.quotaInfo.Name)
 			qi.Lock()
 			qi.CalculateInfo.Runtime = tt.quotaInfo.CalculateInfo.Runtime.DeepCopy()
+			if tt.used != nil {
+				qi.CalculateInfo.Used = tt.used.DeepCopy()
+			}
 			qi.UnLock()
 			state := framework.NewCycleState()
 			ctx := context.TODO()
@@ -34,6 +34,7 @@ func (g *Plugin) OnPodAdd(obj interface{}) {
 	pod = core.RunDecoratePod(pod)
 	quotaName := g.getPodAssociateQuotaName(pod)
 	g.groupQuotaManager.OnPodAdd(quotaName, pod)
+	g.updateReservationConsumer(nil, pod)
 	klog.V(5).Infof("OnPodAddFunc %v.%v add success, quotaName:%v", pod.Namespace, pod.Name, quotaName)
 }
 
@@ -51,6 +52,7 @@ func (g *Plugin) OnPodUpdate(oldObj, newObj interface{}) {
 	oldQuotaName := g.getPodAssociateQuotaName(oldPod)
 	newQuotaName := g.getPodAssociateQuotaName(newPod)
 	g.groupQuotaManager.OnPodUpdate(newQuotaName, oldQuotaName, newPod, oldPod)
+	g.updateReservationConsumer(oldPod, newPod)
 	klog.V(5).Infof("OnPodUpdateFunc %v.%v update success, quotaName:%v", newPod.Namespace, newPod.Name, newQuotaName)
 }
 
@@ -63,5 +65,6 @@ func (g *Plugin) OnPodDelete(obj interface{}) {
 	pod = core.RunDecoratePod(pod)
 	quotaName := g.getPodAssociateQuotaName(pod)
 	g.groupQuotaManager.OnPodDelete(quotaName, pod)
+	g.updateReservationConsumer(pod, nil)
 	klog.V(5).Infof("OnPodDeleteFunc %v.%v delete success", pod.Namespace, pod.Name)
 }
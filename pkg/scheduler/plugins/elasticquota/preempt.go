@@ -26,7 +26,6 @@ import (
 	policy "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/apiserver/pkg/util/feature"
 	policylisters "k8s.io/client-go/listers/policy/v1"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
@@ -294,8 +293,7 @@ func (g *Plugin) selectVictimsOnNode(
 			klog.V(5).InfoS("Pod is a potential preemption victim on node", "pod", klog.KObj(rpi), "node", klog.KObj(nodeInfo.Node()))
 		}
 
-		newUsed := quotav1.Add(quotaInfo.GetUsed(), podReq)
-		if isLessEqual, _ := quotav1.LessThanOrEqual(newUsed, postFilterState.quotaInfo.CalculateInfo.Runtime); !isLessEqual {
+		if isLessEqual, _ := isQuotaFitForRequest(quotaInfo.GetUsed(), podReq, postFilterState.quotaInfo.CalculateInfo.Runtime); !isLessEqual {
 			if err := removePod(pi); err != nil {
 				return false, err
 			}
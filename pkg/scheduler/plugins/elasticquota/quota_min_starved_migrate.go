@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/util"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordinatorclientset "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned"
+	"github.com/koordinator-sh/koordinator/pkg/descheduler/controllers/migration/evictor"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+const (
+	QuotaMinStarvedMigrateControllerName = "QuotaMinStarvedMigrateController"
+
+	// quotaMinStarvedJobPrefix is the name prefix of the PodMigrationJobs created for the starved quotas,
+	// the job is named by the pod UID so that only one job is created for a pod.
+	quotaMinStarvedJobPrefix = "quota-min-starved-"
+)
+
+// QuotaMinStarvedMigrateController watches the quotaGroups whose min are starved by the over-usage of
+// their siblings. When a quotaGroup is starved longer than the starvation duration, the over-used pods of
+// its siblings are migrated by PodMigrationJobs, which are executed by the descheduler, to give the
+// guaranteed resources back to the starved quotaGroup.
+type QuotaMinStarvedMigrateController struct {
+	koordClientSet     koordinatorclientset.Interface
+	groupQuotaManager  *core.GroupQuotaManager
+	starvationDuration time.Duration
+	migratePodCycle    time.Duration
+	lock               sync.Mutex
+	starvedSince       map[string]time.Time
+}
+
+func NewQuotaMinStarvedMigrateController(koordClientSet koordinatorclientset.Interface, starvationDuration, migratePodCycle time.Duration,
+	groupQuotaManager *core.GroupQuotaManager) *QuotaMinStarvedMigrateController {
+	return &QuotaMinStarvedMigrateController{
+		koordClientSet:     koordClientSet,
+		groupQuotaManager:  groupQuotaManager,
+		starvationDuration: starvationDuration,
+		migratePodCycle:    migratePodCycle,
+		starvedSince:       map[string]time.Time{},
+	}
+}
+
+func (controller *QuotaMinStarvedMigrateController) Name() string {
+	return QuotaMinStarvedMigrateControllerName
+}
+
+func (controller *QuotaMinStarvedMigrateController) Start() {
+	go wait.Until(controller.migratePodsForStarvedQuotas, controller.migratePodCycle, nil)
+	klog.Infof("start elasticQuota QuotaMinStarvedMigrateController")
+}
+
+func (controller *QuotaMinStarvedMigrateController) migratePodsForStarvedQuotas() {
+	toMigratePods := controller.getToMigratePods()
+	for starvedQuotaName, pods := range toMigratePods {
+		for _, pod := range pods {
+			if err := controller.createPodMigrationJob(context.TODO(), pod, starvedQuotaName); err != nil {
+				klog.Errorf("failed to create PodMigrationJob due to quota min starved, pod:%v, quotaName:%v, error:%s",
+					klog.KObj(pod), starvedQuotaName, err)
+				continue
+			}
+			klog.V(4).Infof("finish creating PodMigrationJob due to quota min starved, pod:%v, quotaName:%v",
+				klog.KObj(pod), starvedQuotaName)
+		}
+	}
+}
+
+// getToMigratePods returns the over-used pods of the siblings of the quotaGroups starved longer than
+// the starvation duration, keyed by the name of the starved quotaGroup.
+func (controller *QuotaMinStarvedMigrateController) getToMigratePods() map[string][]*v1.Pod {
+	quotaInfos := map[string]*core.QuotaInfo{}
+	for quotaName := range controller.groupQuotaManager.GetAllQuotaNames() {
+		if quotaName == extension.SystemQuotaName || quotaName == extension.RootQuotaName {
+			continue
+		}
+		if quotaInfo := controller.groupQuotaManager.GetQuotaInfoByName(quotaName); quotaInfo != nil {
+			quotaInfos[quotaName] = quotaInfo
+		}
+	}
+
+	controller.lock.Lock()
+	defer controller.lock.Unlock()
+
+	for quotaName := range controller.starvedSince {
+		if _, ok := quotaInfos[quotaName]; !ok {
+			delete(controller.starvedSince, quotaName)
+		}
+	}
+
+	quotaNames := make([]string, 0, len(quotaInfos))
+	for quotaName := range quotaInfos {
+		quotaNames = append(quotaNames, quotaName)
+	}
+	sort.Strings(quotaNames)
+
+	toMigratePods := map[string][]*v1.Pod{}
+	for _, quotaName := range quotaNames {
+		quotaInfo := quotaInfos[quotaName]
+		starved := getStarvedResource(quotaInfo)
+		if quotav1.IsZero(starved) || !isStarvedBySiblings(quotaInfo, starved, quotaInfos) {
+			delete(controller.starvedSince, quotaName)
+			continue
+		}
+		since, ok := controller.starvedSince[quotaName]
+		if !ok {
+			controller.starvedSince[quotaName] = time.Now()
+			continue
+		}
+		if time.Since(since) <= controller.starvationDuration {
+			continue
+		}
+		klog.V(4).Infof("Quota min continue starved by siblings, prepare migrating pods, quotaName:%v, starved:%v, "+
+			"starvedDuration:%v", quotaName, starved, time.Since(since))
+		// wait for another duration before the next migration, in case the migrated pods are not released yet
+		controller.starvedSince[quotaName] = time.Now()
+		if pods := getToMigratePodList(quotaInfo, starved, quotaInfos); len(pods) > 0 {
+			toMigratePods[quotaName] = pods
+		}
+	}
+	return toMigratePods
+}
+
+// getStarvedResource returns the resources the quotaGroup lacks to satisfy its request within the min.
+func getStarvedResource(quotaInfo *core.QuotaInfo) v1.ResourceList {
+	min := quotaInfo.GetMin()
+	request := quotaInfo.GetRequest()
+	used := quotaInfo.GetUsed()
+
+	guaranteed := v1.ResourceList{}
+	for resourceName, minQuantity := range min {
+		requestQuantity := request[resourceName]
+		if requestQuantity.Cmp(minQuantity) < 0 {
+			guaranteed[resourceName] = requestQuantity.DeepCopy()
+		} else {
+			guaranteed[resourceName] = minQuantity.DeepCopy()
+		}
+	}
+	return quotav1.RemoveZeros(quotav1.SubtractWithNonNegativeResult(guaranteed, used))
+}
+
+// getOverUsedResource returns the resources the quotaGroup uses beyond its min.
+func getOverUsedResource(quotaInfo *core.QuotaInfo) v1.ResourceList {
+	return quotav1.RemoveZeros(quotav1.SubtractWithNonNegativeResult(quotaInfo.GetUsed(), quotaInfo.GetMin()))
+}
+
+func getSiblingQuotaInfos(quotaInfo *core.QuotaInfo, quotaInfos map[string]*core.QuotaInfo) []*core.QuotaInfo {
+	var siblings []*core.QuotaInfo
+	for quotaName, sibling := range quotaInfos {
+		if quotaName != quotaInfo.Name && sibling.ParentName == quotaInfo.ParentName {
+			siblings = append(siblings, sibling)
+		}
+	}
+	sort.Slice(siblings, func(i, j int) bool { return siblings[i].Name < siblings[j].Name })
+	return siblings
+}
+
+func isStarvedBySiblings(quotaInfo *core.QuotaInfo, starved v1.ResourceList, quotaInfos map[string]*core.QuotaInfo) bool {
+	for _, sibling := range getSiblingQuotaInfos(quotaInfo, quotaInfos) {
+		overUsed := quotav1.Mask(getOverUsedResource(sibling), quotav1.ResourceNames(starved))
+		if !quotav1.IsZero(overUsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// getToMigratePodList picks the pods of the over-used siblings from low priority to high priority until the
+// starved resources are covered. A sibling only gives up the resources it uses beyond its min.
+func getToMigratePodList(quotaInfo *core.QuotaInfo, starved v1.ResourceList, quotaInfos map[string]*core.QuotaInfo) []*v1.Pod {
+	starvedNames := quotav1.ResourceNames(starved)
+	toMigratePods := make([]*v1.Pod, 0)
+	for _, sibling := range getSiblingQuotaInfos(quotaInfo, quotaInfos) {
+		if quotav1.IsZero(starved) {
+			break
+		}
+		overUsed := quotav1.Mask(getOverUsedResource(sibling), starvedNames)
+		if quotav1.IsZero(overUsed) {
+			continue
+		}
+
+		pods := sibling.GetPodThatIsAssigned()
+		sort.Slice(pods, func(i, j int) bool { return !util.MoreImportantPod(pods[i], pods[j]) })
+		for _, pod := range pods {
+			if quotav1.IsZero(starved) {
+				break
+			}
+			if reservationutil.IsReservePod(pod) {
+				continue
+			}
+			podRequest, _ := resource.PodRequestsAndLimits(pod)
+			podRequest = quotav1.Mask(podRequest, starvedNames)
+			if quotav1.IsZero(podRequest) {
+				continue
+			}
+			if fit, _ := quotav1.LessThanOrEqual(podRequest, overUsed); !fit {
+				continue
+			}
+			overUsed = quotav1.Subtract(overUsed, podRequest)
+			starved = quotav1.RemoveZeros(quotav1.SubtractWithNonNegativeResult(starved, podRequest))
+			toMigratePods = append(toMigratePods, pod)
+			klog.Infof("pod should be migrated by QuotaMinStarvedMigrateController, pod:%v, quotaName:%v, "+
+				"starvedQuotaName:%v", klog.KObj(pod), sibling.Name, quotaInfo.Name)
+		}
+	}
+	return toMigratePods
+}
+
+func (controller *QuotaMinStarvedMigrateController) createPodMigrationJob(ctx context.Context, pod *v1.Pod, starvedQuotaName string) error {
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: quotaMinStarvedJobPrefix + string(pod.UID),
+			Annotations: map[string]string{
+				evictor.AnnotationEvictReason:  fmt.Sprintf("the min of quota %s is starved", starvedQuotaName),
+				evictor.AnnotationEvictTrigger: QuotaMinStarvedMigrateControllerName,
+			},
+		},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &v1.ObjectReference{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+			// the pod is evicted directly to give the resources back, since a migrated pod still consumes the quota
+			Mode: sev1alpha1.PodMigrationJobModeEvictionDirectly,
+		},
+		Status: sev1alpha1.PodMigrationJobStatus{
+			Phase: sev1alpha1.PodMigrationJobPending,
+		},
+	}
+	_, err := controller.koordClientSet.SchedulingV1alpha1().PodMigrationJobs().Create(ctx, job, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
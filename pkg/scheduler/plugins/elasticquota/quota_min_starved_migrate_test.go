@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+func makeStarvedTestPod(name string, cpu int64, priority int32, assigned bool) *corev1.Pod {
+	pod := makePod2(name, createResourceList(cpu, 0))
+	pod.UID = types.UID(name)
+	pod.Spec.Priority = pointer.Int32(priority)
+	if !assigned {
+		pod.Spec.NodeName = ""
+		pod.Status.Phase = corev1.PodPending
+	}
+	return pod
+}
+
+func TestQuotaMinStarvedMigrateController(t *testing.T) {
+	gqm := core.NewGroupQuotaManager(createResourceList(100, 1000), createResourceList(100, 1000))
+	gqm.UpdateClusterTotalResource(createResourceList(100, 1000))
+	assert.NoError(t, gqm.UpdateQuota(CreateQuota2("starved", extension.RootQuotaName, 100, 1000, 10, 100, 1, 1, false), false))
+	assert.NoError(t, gqm.UpdateQuota(CreateQuota2("overused", extension.RootQuotaName, 100, 1000, 10, 100, 1, 1, false), false))
+
+	koordClientSet := fake.NewSimpleClientset()
+	controller := NewQuotaMinStarvedMigrateController(koordClientSet, 0, time.Second, gqm)
+
+	gqm.OnPodAdd("overused", makeStarvedTestPod("overused-pod-2", 10, 2, true))
+	// the sibling does not over-use the min, so the quota is not starved by the siblings
+	gqm.OnPodAdd("starved", makeStarvedTestPod("starved-pod", 8, 10, false))
+	controller.migratePodsForStarvedQuotas()
+	assert.Empty(t, controller.starvedSince)
+
+	gqm.OnPodAdd("overused", makeStarvedTestPod("overused-pod-1", 5, 1, true))
+	gqm.OnPodAdd("overused", makeStarvedTestPod("overused-pod-3", 5, 3, true))
+	// the starvation starts to be recorded
+	controller.migratePodsForStarvedQuotas()
+	assert.Contains(t, controller.starvedSince, "starved")
+	jobs, err := koordClientSet.SchedulingV1alpha1().PodMigrationJobs().List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+
+	// the starvation lasts longer than the duration, the over-used pods of lowest priority are migrated,
+	// and the sibling keeps the resources within its min
+	toMigratePods := controller.getToMigratePods()
+	assert.Len(t, toMigratePods["starved"], 2)
+	assert.Equal(t, "overused-pod-1", toMigratePods["starved"][0].Name)
+	assert.Equal(t, "overused-pod-3", toMigratePods["starved"][1].Name)
+
+	controller.migratePodsForStarvedQuotas()
+	jobs, err = koordClientSet.SchedulingV1alpha1().PodMigrationJobs().List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, jobs.Items, 2)
+	for _, job := range jobs.Items {
+		assert.Equal(t, sev1alpha1.PodMigrationJobModeEvictionDirectly, job.Spec.Mode)
+		assert.Equal(t, quotaMinStarvedJobPrefix+string(job.Spec.PodRef.UID), job.Name)
+		assert.Equal(t, QuotaMinStarvedMigrateControllerName, job.Annotations["koordinator.sh/evict-trigger"])
+	}
+	// the jobs are not created repeatedly
+	controller.migratePodsForStarvedQuotas()
+	jobs, err = koordClientSet.SchedulingV1alpha1().PodMigrationJobs().List(context.TODO(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, jobs.Items, 2)
+
+	// the starvation is cleared once the quota is satisfied
+	gqm.OnPodDelete("starved", makeStarvedTestPod("starved-pod", 8, 10, false))
+	controller.migratePodsForStarvedQuotas()
+	assert.Empty(t, controller.starvedSince)
+}
@@ -36,6 +36,7 @@ import (
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
 const (
@@ -97,6 +98,9 @@ func (monitor *QuotaOverUsedGroupMonitor) getToRevokePodList(quotaName string) [
 	runtime := quotaInfo.GetRuntime()
 	used := quotaInfo.GetUsed()
 	oriUsed := used.DeepCopy()
+	// the batch pods holding no guaranteed resources are kept when only the guaranteed resources are over-used,
+	// and vice versa
+	_, exceedDimensions := quotav1.LessThanOrEqual(used, runtime)
 
 	// order pod from low priority -> high priority
 	priPodCache := quotaInfo.GetPodThatIsAssigned()
@@ -110,7 +114,14 @@ func (monitor *QuotaOverUsedGroupMonitor) getToRevokePodList(quotaName string) [
 		if shouldBreak, _ := quotav1.LessThanOrEqual(used, runtime); shouldBreak {
 			break
 		}
+		// the reserve pods are not real pods to revoke
+		if reservationutil.IsReservePod(pod) {
+			continue
+		}
 		podReq, _ := resource.PodRequestsAndLimits(pod)
+		if !isPodHoldingExceededResources(podReq, exceedDimensions) {
+			continue
+		}
 		used = quotav1.Subtract(used, podReq)
 		tryAssignBackPodCache = append(tryAssignBackPodCache, pod)
 	}
@@ -263,6 +274,9 @@ func EvictPod(ctx context.Context, client clientset.Interface, pod *v1.Pod, dele
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pod.Name,
 			Namespace: pod.Namespace,
+			Annotations: map[string]string{
+				extension.AnnotationEvictionInitiator: extension.EvictionInitiatorScheduler,
+			},
 		},
 		DeleteOptions: deleteOptions,
 	}
@@ -139,6 +139,16 @@ func TestQuotaOverUsedRevokeController_GetToRevokePodList(t *testing.T) {
 	if len(result) != 3 {
 		t.Errorf("error:%v", len(result))
 	}
+
+	// the batch pod holding none of the over-used dimensions is not revoked
+	batchPod := defaultCreatePod("5", 1, 0, 0)
+	batchPod.Spec.Containers[0].Resources.Requests = MakeResourceList().BatchCPU(1000).BatchMem(10).Obj()
+	gqm.OnPodAdd("test1", batchPod)
+	result = con.monitors["test1"].getToRevokePodList("test1")
+	assert.Equal(t, 3, len(result))
+	for _, pod := range result {
+		assert.NotEqual(t, batchPod.Name, pod.Name)
+	}
 }
 
 func TestQuotaOverUsedRevokeController_GetToMonitorQuotas(t *testing.T) {
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/scheduler/util"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	koordutil "github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	QuotaSchedulingGateControllerName = "QuotaSchedulingGateController"
+)
+
+// QuotaSchedulingGateController gates the pending batch pods which cannot be admitted by the headroom of their
+// quotas, so that a huge pending queue does not keep the scheduler busy with the pods refused by the quotas. The gated
+// pods are refused at once in PreFilter, and they are released in the order of importance when the headroom becomes
+// available, which requeues them to the scheduler.
+type QuotaSchedulingGateController struct {
+	client            clientset.Interface
+	podLister         listerv1.PodLister
+	groupQuotaManager *core.GroupQuotaManager
+	getQuotaName      func(pod *v1.Pod) string
+	interval          time.Duration
+}
+
+func NewQuotaSchedulingGateController(client clientset.Interface, podLister listerv1.PodLister,
+	groupQuotaManager *core.GroupQuotaManager, getQuotaName func(pod *v1.Pod) string, interval time.Duration) *QuotaSchedulingGateController {
+	return &QuotaSchedulingGateController{
+		client:            client,
+		podLister:         podLister,
+		groupQuotaManager: groupQuotaManager,
+		getQuotaName:      getQuotaName,
+		interval:          interval,
+	}
+}
+
+func (controller *QuotaSchedulingGateController) Name() string {
+	return QuotaSchedulingGateControllerName
+}
+
+func (controller *QuotaSchedulingGateController) Start() {
+	go wait.Until(controller.syncSchedulingGates, controller.interval, nil)
+	klog.Infof("start elasticQuota QuotaSchedulingGateController")
+}
+
+func (controller *QuotaSchedulingGateController) syncSchedulingGates() {
+	toGatePods, toReleasePods := controller.getSchedulingGateChanges()
+	for _, pod := range toGatePods {
+		if err := controller.setSchedulingGated(pod, true); err != nil {
+			klog.Errorf("failed to gate the scheduling of pod %v, err: %v", klog.KObj(pod), err)
+		}
+	}
+	for _, pod := range toReleasePods {
+		if err := controller.setSchedulingGated(pod, false); err != nil {
+			klog.Errorf("failed to release the scheduling gate of pod %v, err: %v", klog.KObj(pod), err)
+		}
+	}
+}
+
+// getSchedulingGateChanges returns the pending batch pods to gate and the ones to release. The pods of each quota
+// are admitted from the most important one as long as the used of the quota and the requests of the admitted pods fit
+// within the runtime, and the rest ones are gated.
+func (controller *QuotaSchedulingGateController) getSchedulingGateChanges() (toGatePods, toReleasePods []*v1.Pod) {
+	pods, err := controller.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list pods for the quota scheduling gates, err: %v", err)
+		return nil, nil
+	}
+
+	pendingPods := map[string][]*v1.Pod{}
+	for _, pod := range pods {
+		if !isQuotaSchedulingGateCandidate(pod) {
+			continue
+		}
+		quotaName := controller.getQuotaName(core.RunDecoratePod(pod))
+		pendingPods[quotaName] = append(pendingPods[quotaName], pod)
+	}
+
+	quotaNames := make([]string, 0, len(pendingPods))
+	for quotaName := range pendingPods {
+		quotaNames = append(quotaNames, quotaName)
+	}
+	sort.Strings(quotaNames)
+
+	for _, quotaName := range quotaNames {
+		controller.groupQuotaManager.RefreshRuntime(quotaName)
+		quotaInfo := controller.groupQuotaManager.GetQuotaInfoByName(quotaName)
+		if quotaInfo == nil {
+			continue
+		}
+		runtime := quotaInfo.GetRuntime()
+		admitted := quotaInfo.GetUsed()
+
+		quotaPods := pendingPods[quotaName]
+		sort.Slice(quotaPods, func(i, j int) bool { return util.MoreImportantPod(quotaPods[i], quotaPods[j]) })
+		for _, pod := range quotaPods {
+			podRequest, _ := resource.PodRequestsAndLimits(core.RunDecoratePod(pod))
+			fits, _ := isQuotaFitForRequest(admitted, podRequest, runtime)
+			if fits {
+				admitted = quotav1.Add(admitted, podRequest)
+			}
+			gated := extension.IsPodQuotaSchedulingGated(pod)
+			if fits && gated {
+				toReleasePods = append(toReleasePods, pod)
+			} else if !fits && !gated {
+				toGatePods = append(toGatePods, pod)
+			}
+		}
+		klog.V(5).Infof("quota scheduling gates, quotaName: %v, runtime: %v, admitted: %v, pendingPods: %v",
+			quotaName, printResourceList(runtime), printResourceList(admitted), len(quotaPods))
+	}
+	return toGatePods, toReleasePods
+}
+
+func (controller *QuotaSchedulingGateController) setSchedulingGated(pod *v1.Pod, gated bool) error {
+	newPod := pod.DeepCopy()
+	if gated {
+		if newPod.Annotations == nil {
+			newPod.Annotations = map[string]string{}
+		}
+		newPod.Annotations[extension.AnnotationQuotaSchedulingGated] = "true"
+	} else {
+		delete(newPod.Annotations, extension.AnnotationQuotaSchedulingGated)
+	}
+	_, err := koordutil.PatchPod(controller.client, pod, newPod)
+	if err == nil {
+		klog.V(4).Infof("set the quota scheduling gate of pod %v to %v", klog.KObj(pod), gated)
+	}
+	return err
+}
+
+// isQuotaSchedulingGateCandidate checks if the pod is a pending batch pod, whose scheduling can be gated.
+func isQuotaSchedulingGateCandidate(pod *v1.Pod) bool {
+	if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil || koordutil.IsPodTerminated(pod) {
+		return false
+	}
+	return extension.GetPriorityClass(pod) == extension.PriorityBatch
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+)
+
+func makeGateTestPod(name string, priority int32, cpu int64, assigned bool) *corev1.Pod {
+	pod := defaultCreatePodWithQuotaNameAndVersion(name, "test", "1", priority, cpu, 0)
+	pod.Namespace = "default"
+	if !assigned {
+		pod.Spec.NodeName = ""
+		pod.Status.Phase = corev1.PodPending
+	}
+	return pod
+}
+
+func TestQuotaSchedulingGateController(t *testing.T) {
+	gqm := core.NewGroupQuotaManager(createResourceList(100, 1000), createResourceList(100, 1000))
+	gqm.UpdateClusterTotalResource(createResourceList(100, 1000))
+	assert.NoError(t, gqm.UpdateQuota(CreateQuota2("test", extension.RootQuotaName, 10, 1000, 10, 1000, 1, 1, false), false))
+
+	runningPod := makeGateTestPod("running", 5500, 6, true)
+	pods := []*corev1.Pod{
+		runningPod,
+		makeGateTestPod("batch-1", 5900, 3, false),
+		makeGateTestPod("batch-2", 5800, 2, false),
+		makeGateTestPod("batch-3", 5700, 1, false),
+		// the pods of other priority classes are never gated
+		makeGateTestPod("prod", 9500, 5, false),
+	}
+	kubeClient := kubefake.NewSimpleClientset()
+	podInformer := informers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Pods()
+	for _, pod := range pods {
+		_, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, podInformer.Informer().GetStore().Add(pod))
+		gqm.OnPodAdd("test", pod)
+	}
+	getQuotaName := func(pod *corev1.Pod) string {
+		return pod.Labels[extension.LabelQuotaName]
+	}
+	controller := NewQuotaSchedulingGateController(kubeClient, podInformer.Lister(), gqm, getQuotaName, time.Second)
+
+	// the headroom admits the more important pods first, and a less important pod fitting the rest is admitted too
+	toGatePods, toReleasePods := controller.getSchedulingGateChanges()
+	assert.Empty(t, toReleasePods)
+	if assert.Len(t, toGatePods, 1) {
+		assert.Equal(t, "batch-2", toGatePods[0].Name)
+	}
+
+	controller.syncSchedulingGates()
+	gatedPod, err := kubeClient.CoreV1().Pods("default").Get(context.TODO(), "batch-2", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, extension.IsPodQuotaSchedulingGated(gatedPod))
+
+	// the gated pod is released once the headroom is available
+	assert.NoError(t, podInformer.Informer().GetStore().Update(gatedPod))
+	toGatePods, _ = controller.getSchedulingGateChanges()
+	assert.Empty(t, toGatePods)
+	gqm.OnPodDelete("test", runningPod)
+	toGatePods, toReleasePods = controller.getSchedulingGateChanges()
+	assert.Empty(t, toGatePods)
+	if assert.Len(t, toReleasePods, 1) {
+		assert.Equal(t, "batch-2", toReleasePods[0].Name)
+	}
+
+	controller.syncSchedulingGates()
+	releasedPod, err := kubeClient.CoreV1().Pods("default").Get(context.TODO(), "batch-2", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, extension.IsPodQuotaSchedulingGated(releasedPod))
+}
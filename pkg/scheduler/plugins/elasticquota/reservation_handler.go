@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/v1/resource"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota/core"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+const reservedContainerName = "reserved"
+
+// reservationQuotaCache tracks the quota charges of the active reservations. The resources held by a reservation
+// are charged to the quota of the reservation until they are consumed by the owner pods, which are charged to their
+// own quotas instead, so that the reserved resources are charged exactly once.
+type reservationQuotaCache struct {
+	lock sync.Mutex
+	// reservePods are the active reservations represented as the reserve pods.
+	reservePods map[types.UID]*corev1.Pod
+	// chargedPods are the reserve pods with only the unconsumed resources, which are charged to the quotas.
+	chargedPods map[types.UID]*corev1.Pod
+	// consumers are the requests of the pods allocated from each reservation.
+	consumers map[types.UID]map[types.UID]corev1.ResourceList
+	// ownerQuotaNames are the quotas of the reservation owners, which the reservations are charged to
+	// instead of their own quotas in the Owner accounting mode.
+	ownerQuotaNames map[types.UID]string
+}
+
+func newReservationQuotaCache() *reservationQuotaCache {
+	return &reservationQuotaCache{
+		reservePods:     map[types.UID]*corev1.Pod{},
+		chargedPods:     map[types.UID]*corev1.Pod{},
+		consumers:       map[types.UID]map[types.UID]corev1.ResourceList{},
+		ownerQuotaNames: map[types.UID]string{},
+	}
+}
+
+func (g *Plugin) registerReservationEventHandler() {
+	extendedHandle, ok := g.handle.(frameworkext.ExtendedHandle)
+	if !ok {
+		klog.V(3).Infof("skip charging reservations to quotas, cannot convert handle to frameworkext.ExtendedHandle, got %T", g.handle)
+		return
+	}
+	koordSharedInformerFactory := extendedHandle.KoordinatorSharedInformerFactory()
+	g.reservationLister = koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Lister()
+	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations().Informer()
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, reservationInformer,
+		reservationutil.NewReservationToPodEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    g.OnReservationAdd,
+			UpdateFunc: g.OnReservationUpdate,
+			DeleteFunc: g.OnReservationDelete,
+		}, reservationutil.IsObjValidActiveReservation))
+}
+
+// OnReservationAdd charges the active reservation, which is converted into the reserve pod, to its quota.
+func (g *Plugin) OnReservationAdd(obj interface{}) {
+	reservePod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	ownerQuotaName := g.getReservationOwnerQuotaName(reservePod)
+
+	g.reservationCache.lock.Lock()
+	defer g.reservationCache.lock.Unlock()
+	g.reservationCache.reservePods[reservePod.UID] = core.RunDecoratePod(reservePod)
+	if ownerQuotaName != "" {
+		g.reservationCache.ownerQuotaNames[reservePod.UID] = ownerQuotaName
+	} else {
+		delete(g.reservationCache.ownerQuotaNames, reservePod.UID)
+	}
+	g.rechargeReservationNoLock(reservePod.UID)
+	klog.V(5).Infof("OnReservationAdd %v add success", reservationutil.GetReservationNameFromReservePod(reservePod))
+}
+
+func (g *Plugin) OnReservationUpdate(oldObj, newObj interface{}) {
+	g.OnReservationAdd(newObj)
+}
+
+func (g *Plugin) OnReservationDelete(obj interface{}) {
+	reservePod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	g.reservationCache.lock.Lock()
+	defer g.reservationCache.lock.Unlock()
+	delete(g.reservationCache.reservePods, reservePod.UID)
+	delete(g.reservationCache.ownerQuotaNames, reservePod.UID)
+	g.rechargeReservationNoLock(reservePod.UID)
+	klog.V(5).Infof("OnReservationDelete %v delete success", reservationutil.GetReservationNameFromReservePod(reservePod))
+}
+
+// updateReservationConsumer hands the resources of the reservation over to the pod allocated from it, or back to the
+// reservation when the pod no longer consumes it.
+func (g *Plugin) updateReservationConsumer(oldPod, newPod *corev1.Pod) {
+	oldReservationUID := getConsumedReservationUID(oldPod)
+	newReservationUID := getConsumedReservationUID(newPod)
+	if oldReservationUID == "" && newReservationUID == "" {
+		return
+	}
+
+	g.reservationCache.lock.Lock()
+	defer g.reservationCache.lock.Unlock()
+	if oldReservationUID != "" && oldReservationUID != newReservationUID {
+		g.forgetReservationConsumerNoLock(oldReservationUID, oldPod)
+	}
+	if newReservationUID != "" {
+		g.assumeReservationConsumerNoLock(newReservationUID, newPod)
+	}
+}
+
+func (g *Plugin) assumeReservationConsumer(reservationUID types.UID, pod *corev1.Pod) {
+	g.reservationCache.lock.Lock()
+	defer g.reservationCache.lock.Unlock()
+	g.assumeReservationConsumerNoLock(reservationUID, core.RunDecoratePod(pod))
+}
+
+func (g *Plugin) forgetReservationConsumer(reservationUID types.UID, pod *corev1.Pod) {
+	g.reservationCache.lock.Lock()
+	defer g.reservationCache.lock.Unlock()
+	g.forgetReservationConsumerNoLock(reservationUID, pod)
+}
+
+func (g *Plugin) assumeReservationConsumerNoLock(reservationUID types.UID, pod *corev1.Pod) {
+	podRequest, _ := resource.PodRequestsAndLimits(pod)
+	consumers := g.reservationCache.consumers[reservationUID]
+	if consumers == nil {
+		consumers = map[types.UID]corev1.ResourceList{}
+		g.reservationCache.consumers[reservationUID] = consumers
+	}
+	if oldRequest, ok := consumers[pod.UID]; ok && quotav1.Equals(oldRequest, podRequest) {
+		return
+	}
+	consumers[pod.UID] = podRequest
+	g.rechargeReservationNoLock(reservationUID)
+}
+
+func (g *Plugin) forgetReservationConsumerNoLock(reservationUID types.UID, pod *corev1.Pod) {
+	consumers := g.reservationCache.consumers[reservationUID]
+	if _, ok := consumers[pod.UID]; !ok {
+		return
+	}
+	delete(consumers, pod.UID)
+	if len(consumers) == 0 {
+		delete(g.reservationCache.consumers, reservationUID)
+	}
+	g.rechargeReservationNoLock(reservationUID)
+}
+
+// getReservationQuotaCharge returns the unconsumed resources of the reservation charged to the quota, or nil if the
+// reservation is not charged to the quota.
+func (g *Plugin) getReservationQuotaCharge(reservationUID types.UID, quotaName string) corev1.ResourceList {
+	g.reservationCache.lock.Lock()
+	defer g.reservationCache.lock.Unlock()
+	chargedPod := g.reservationCache.chargedPods[reservationUID]
+	if chargedPod == nil || g.getPodAssociateQuotaName(chargedPod) != quotaName {
+		return nil
+	}
+	charge, _ := resource.PodRequestsAndLimits(chargedPod)
+	return charge
+}
+
+// rechargeReservationNoLock replaces the charge of the reservation with its unconsumed resources.
+func (g *Plugin) rechargeReservationNoLock(reservationUID types.UID) {
+	if oldChargedPod := g.reservationCache.chargedPods[reservationUID]; oldChargedPod != nil {
+		g.groupQuotaManager.OnPodDelete(g.getPodAssociateQuotaName(oldChargedPod), oldChargedPod)
+		delete(g.reservationCache.chargedPods, reservationUID)
+	}
+	reservePod := g.reservationCache.reservePods[reservationUID]
+	if reservePod == nil {
+		return
+	}
+	chargedPod := newChargedReservePod(reservePod, g.reservationCache.consumers[reservationUID])
+	if ownerQuotaName := g.reservationCache.ownerQuotaNames[reservationUID]; ownerQuotaName != "" {
+		if chargedPod.Labels == nil {
+			chargedPod.Labels = map[string]string{}
+		}
+		chargedPod.Labels[extension.LabelQuotaName] = ownerQuotaName
+	}
+	g.groupQuotaManager.OnPodAdd(g.getPodAssociateQuotaName(chargedPod), chargedPod)
+	g.reservationCache.chargedPods[reservationUID] = chargedPod
+}
+
+// getReservationOwnerQuotaName returns the quota of the reservation owners in the Owner accounting mode, which is the
+// quota label in the owner label selector, or the quota of the namespace of the owner object or controller. It returns
+// empty if the owners specify no quota or the owners are in different quotas, then the reservation is charged to its
+// own quota.
+func (g *Plugin) getReservationOwnerQuotaName(reservePod *corev1.Pod) string {
+	if g.pluginArgs == nil || g.pluginArgs.ReservationAccountingMode != config.ReservationAccountingOwner ||
+		g.reservationLister == nil {
+		return ""
+	}
+	reservationName := reservationutil.GetReservationNameFromReservePod(reservePod)
+	r, err := g.reservationLister.Get(reservationName)
+	if err != nil || r.UID != reservePod.UID {
+		klog.V(4).Infof("failed to get reservation %s for the owner quota, err: %v", reservationName, err)
+		return ""
+	}
+	quotaNames := reservationutil.GetReservationOwnerQuotaNames(r, func(namespace string) string {
+		return GetQuotaName(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}}, g.quotaLister)
+	})
+	if quotaNames.Len() > 1 {
+		klog.Warningf("owners of reservation %s are in different quotas %v, charge the reservation to its own quota",
+			reservationName, quotaNames.List())
+		return ""
+	}
+	quotaName, _ := quotaNames.PopAny()
+	return quotaName
+}
+
+// newChargedReservePod returns a copy of the reserve pod whose requests are the resources reserved but not consumed.
+func newChargedReservePod(reservePod *corev1.Pod, consumers map[types.UID]corev1.ResourceList) *corev1.Pod {
+	reserved, _ := resource.PodRequestsAndLimits(reservePod)
+	remaining := reserved
+	for _, podRequest := range consumers {
+		remaining = quotav1.SubtractWithNonNegativeResult(remaining, podRequest)
+	}
+	remaining = quotav1.Mask(remaining, quotav1.ResourceNames(reserved))
+
+	chargedPod := reservePod.DeepCopy()
+	chargedPod.Spec.InitContainers = nil
+	chargedPod.Spec.Overhead = nil
+	chargedPod.Spec.Containers = []corev1.Container{
+		{
+			Name:      reservedContainerName,
+			Resources: corev1.ResourceRequirements{Requests: remaining},
+		},
+	}
+	return chargedPod
+}
+
+// getConsumedReservationUID returns the UID of the reservation allocated by the assigned pod, or empty if none.
+func getConsumedReservationUID(pod *corev1.Pod) types.UID {
+	if pod == nil || pod.Spec.NodeName == "" || util.IsPodTerminated(pod) {
+		return ""
+	}
+	allocated, err := extension.GetReservationAllocated(pod)
+	if err != nil || allocated == nil {
+		return ""
+	}
+	return allocated.UID
+}
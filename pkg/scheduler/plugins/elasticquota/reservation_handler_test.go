@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func newTestReservation(name, quotaName string, cpu, mem int64) *schedulingv1alpha1.Reservation {
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(name + "-uid"),
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{extension.LabelQuotaName: quotaName},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Resources: corev1.ResourceRequirements{
+								Requests: createResourceList(cpu, mem),
+							},
+						},
+					},
+				},
+			},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "test",
+		},
+	}
+}
+
+func TestPlugin_ReservationQuotaHandoff(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	plugin := suit.plugin.(*Plugin)
+	gqm := plugin.groupQuotaManager
+	plugin.addQuota("test1", extension.RootQuotaName, 96, 160, 100, 160, 96, 160, true, "")
+	plugin.addQuota("test2", extension.RootQuotaName, 96, 160, 100, 160, 96, 160, true, "")
+
+	assertUsed := func(quotaName string, cpu, mem int64) {
+		assert.True(t, quotav1.Equals(createResourceList(cpu, mem), gqm.GetQuotaInfoByName(quotaName).GetUsed()),
+			"quota %s, expected used %v, got %v", quotaName, createResourceList(cpu, mem), gqm.GetQuotaInfoByName(quotaName).GetUsed())
+	}
+
+	// the consumer is observed before the reservation
+	r := newTestReservation("r1", "test1", 10, 10)
+	pod1 := defaultCreatePodWithQuotaNameAndVersion("1", "test2", "1", 10, 4, 4)
+	pod1.Spec.NodeName = "test"
+	extension.SetReservationAllocated(pod1, r)
+	plugin.OnPodAdd(pod1)
+	assertUsed("test2", 4, 4)
+
+	// the reservation is charged to the reserving quota with the unconsumed resources only
+	reservePod := reservationutil.NewReservePod(r)
+	plugin.OnReservationAdd(reservePod)
+	assertUsed("test1", 6, 6)
+	assertUsed("test2", 4, 4)
+
+	// another pod is assumed to allocate the reservation in the scheduling cycle
+	pod2 := defaultCreatePodWithQuotaNameAndVersion("2", "test2", "1", 10, 5, 5)
+	pod2.Spec.NodeName = ""
+	pod2.Status.Phase = corev1.PodPending
+	plugin.OnPodAdd(pod2)
+	plugin.Reserve(context.TODO(), framework.NewCycleState(), pod2, "test")
+	plugin.assumeReservationConsumer(r.UID, pod2)
+	assertUsed("test1", 1, 1)
+	assertUsed("test2", 9, 9)
+
+	// the pod is bound and observed, which is not charged again
+	newPod2 := pod2.DeepCopy()
+	newPod2.ResourceVersion = "2"
+	newPod2.Spec.NodeName = "test"
+	extension.SetReservationAllocated(newPod2, r)
+	plugin.OnPodUpdate(pod2, newPod2)
+	assertUsed("test1", 1, 1)
+	assertUsed("test2", 9, 9)
+
+	// the consumers exceeding the reservation do not make the charge negative
+	pod3 := defaultCreatePodWithQuotaNameAndVersion("3", "test2", "1", 10, 3, 3)
+	pod3.Spec.NodeName = "test"
+	extension.SetReservationAllocated(pod3, r)
+	plugin.OnPodAdd(pod3)
+	assertUsed("test1", 0, 0)
+	assertUsed("test2", 12, 12)
+
+	// the resources are handed back to the reservation when the consumers are deleted
+	plugin.OnPodDelete(pod3)
+	plugin.OnPodDelete(newPod2)
+	assertUsed("test1", 6, 6)
+	assertUsed("test2", 4, 4)
+
+	// the reservation is not charged after it is deleted
+	plugin.OnReservationDelete(reservePod)
+	assertUsed("test1", 0, 0)
+	assertUsed("test2", 4, 4)
+	plugin.OnPodDelete(pod1)
+	assertUsed("test2", 0, 0)
+	assert.Empty(t, plugin.reservationCache.chargedPods)
+	assert.Empty(t, plugin.reservationCache.consumers)
+}
+
+func TestPlugin_ReservationOwnerQuotaAccounting(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	plugin := suit.plugin.(*Plugin)
+	plugin.pluginArgs.ReservationAccountingMode = config.ReservationAccountingOwner
+	gqm := plugin.groupQuotaManager
+	plugin.addQuota("test1", extension.RootQuotaName, 96, 160, 100, 160, 96, 160, true, "")
+	plugin.addQuota("test2", extension.RootQuotaName, 96, 160, 100, 160, 96, 160, true, "")
+
+	assertUsed := func(quotaName string, cpu, mem int64) {
+		// the quota never charged has no used resources at all
+		assert.True(t, quotav1.Equals(quotav1.RemoveZeros(createResourceList(cpu, mem)), quotav1.RemoveZeros(gqm.GetQuotaInfoByName(quotaName).GetUsed())),
+			"quota %s, expected used %v, got %v", quotaName, createResourceList(cpu, mem), gqm.GetQuotaInfoByName(quotaName).GetUsed())
+	}
+
+	// the reservation is parked in test1, while its owners belong to test2
+	r := newTestReservation("r1", "test1", 10, 10)
+	r.Spec.Owners = []schedulingv1alpha1.ReservationOwner{
+		{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{extension.LabelQuotaName: "test2"},
+			},
+		},
+	}
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordfake.NewSimpleClientset(), 0)
+	reservationInformer := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations()
+	assert.NoError(t, reservationInformer.Informer().GetStore().Add(r))
+	plugin.reservationLister = reservationInformer.Lister()
+
+	reservePod := reservationutil.NewReservePod(r)
+	plugin.OnReservationAdd(reservePod)
+	assertUsed("test1", 0, 0)
+	assertUsed("test2", 10, 10)
+
+	// the owner consumes the reservation
+	pod := defaultCreatePodWithQuotaNameAndVersion("1", "test2", "1", 10, 4, 4)
+	pod.Spec.NodeName = "test"
+	extension.SetReservationAllocated(pod, r)
+	plugin.OnPodAdd(pod)
+	assertUsed("test1", 0, 0)
+	assertUsed("test2", 10, 10)
+
+	plugin.OnReservationDelete(reservePod)
+	assertUsed("test2", 4, 4)
+	assert.Empty(t, plugin.reservationCache.ownerQuotaNames)
+
+	// the reservation is charged to its own quota if the owners specify no quota
+	r2 := newTestReservation("r2", "test1", 10, 10)
+	r2.Spec.Owners = []schedulingv1alpha1.ReservationOwner{
+		{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "test"},
+			},
+		},
+	}
+	assert.NoError(t, reservationInformer.Informer().GetStore().Add(r2))
+	reservePod2 := reservationutil.NewReservePod(r2)
+	plugin.OnReservationAdd(reservePod2)
+	assertUsed("test1", 10, 10)
+	plugin.OnReservationDelete(reservePod2)
+	assertUsed("test1", 0, 0)
+
+	// the reservation is charged to its own quota if the owners are in different quotas
+	r3 := newTestReservation("r3", "test1", 10, 10)
+	r3.Spec.Owners = []schedulingv1alpha1.ReservationOwner{
+		{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{extension.LabelQuotaName: "test1"},
+			},
+		},
+		{
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{extension.LabelQuotaName: "test2"},
+			},
+		},
+	}
+	assert.NoError(t, reservationInformer.Informer().GetStore().Add(r3))
+	reservePod3 := reservationutil.NewReservePod(r3)
+	plugin.OnReservationAdd(reservePod3)
+	assertUsed("test1", 10, 10)
+	assertUsed("test2", 4, 4)
+	assert.Empty(t, plugin.reservationCache.ownerQuotaNames)
+	plugin.OnReservationDelete(reservePod3)
+	assertUsed("test1", 0, 0)
+}
+
+func TestPlugin_checkQuotaWithReservations(t *testing.T) {
+	suit := newPluginTestSuitWithPod(t, nil, nil)
+	plugin := suit.plugin.(*Plugin)
+	gqm := plugin.groupQuotaManager
+	plugin.addQuota("test1", extension.RootQuotaName, 10, 10, 10, 10, 10, 10, false, "")
+	plugin.addQuota("test2", extension.RootQuotaName, 10, 10, 10, 10, 10, 10, false, "")
+
+	r1 := newTestReservation("r1", "test1", 8, 8)
+	plugin.OnReservationAdd(reservationutil.NewReservePod(r1))
+	r2 := newTestReservation("r2", "test2", 8, 8)
+	plugin.OnReservationAdd(reservationutil.NewReservePod(r2))
+
+	pod := defaultCreatePodWithQuotaNameAndVersion("1", "test1", "1", 10, 6, 6)
+	pod.Spec.NodeName = ""
+	pod.Status.Phase = corev1.PodPending
+	plugin.OnPodAdd(pod)
+	gqm.RefreshRuntime("test1")
+	quotaInfo := gqm.GetQuotaInfoByName("test1")
+
+	// the pod does not fit the quota charged by the reservation
+	status, credited := plugin.checkQuotaWithReservations(quotaInfo, createResourceList(6, 6), nil)
+	assert.False(t, status.IsSuccess())
+	assert.False(t, credited)
+
+	// the reservation charged to another quota does not help
+	status, credited = plugin.checkQuotaWithReservations(quotaInfo, createResourceList(6, 6), []*schedulingv1alpha1.Reservation{r2})
+	assert.False(t, status.IsSuccess())
+	assert.False(t, credited)
+
+	// the pod takes over the charge of the reservation in the same quota
+	status, credited = plugin.checkQuotaWithReservations(quotaInfo, createResourceList(6, 6), []*schedulingv1alpha1.Reservation{r2, r1})
+	assert.True(t, status.IsSuccess())
+	assert.True(t, credited)
+
+	// the request exceeding the reservation is still checked
+	status, credited = plugin.checkQuotaWithReservations(quotaInfo, createResourceList(11, 11), []*schedulingv1alpha1.Reservation{r1})
+	assert.False(t, status.IsSuccess())
+	assert.False(t, credited)
+
+	// the pod fits without the reservation
+	status, credited = plugin.checkQuotaWithReservations(quotaInfo, createResourceList(1, 1), []*schedulingv1alpha1.Reservation{r1})
+	assert.True(t, status.IsSuccess())
+	assert.False(t, credited)
+}
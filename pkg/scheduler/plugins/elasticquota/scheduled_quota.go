@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+	schedclientset "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned"
+	schedlister "sigs.k8s.io/scheduler-plugins/pkg/generated/listers/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	koordutil "github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+const (
+	ScheduledQuotaSyncCycle      = 30 * time.Second
+	ScheduledQuotaControllerName = "ScheduledQuotaController"
+)
+
+// ScheduledQuotaController applies the min/max declared in the schedules of the quota to its spec,
+// and restores the original spec when no schedule is active. The plugin reloads the new min/max
+// through OnQuotaUpdate, which keeps the pods and used of the quota.
+type ScheduledQuotaController struct {
+	schedClient schedclientset.Interface
+	eqLister    schedlister.ElasticQuotaLister
+	now         func() time.Time
+}
+
+// NewScheduledQuotaController returns a new *ScheduledQuotaController
+func NewScheduledQuotaController(client schedclientset.Interface, eqLister schedlister.ElasticQuotaLister) *ScheduledQuotaController {
+	return &ScheduledQuotaController{
+		schedClient: client,
+		eqLister:    eqLister,
+		now:         time.Now,
+	}
+}
+
+func (ctrl *ScheduledQuotaController) Name() string {
+	return ScheduledQuotaControllerName
+}
+
+func (ctrl *ScheduledQuotaController) Start() {
+	go wait.Until(ctrl.Run, ScheduledQuotaSyncCycle, context.TODO().Done())
+	klog.Infof("start elasticQuota ScheduledQuotaController")
+}
+
+func (ctrl *ScheduledQuotaController) Run() {
+	if errs := ctrl.syncHandler(); len(errs) != 0 {
+		for _, err := range errs {
+			utilruntime.HandleError(err)
+		}
+	}
+}
+
+func (ctrl *ScheduledQuotaController) syncHandler() []error {
+	eqList, err := ctrl.eqLister.List(labels.Everything())
+	if err != nil {
+		klog.V(3).ErrorS(err, "Unable to list elastic quota from store")
+		return []error{err}
+	}
+	now := ctrl.now()
+	var errors []error
+	for _, eq := range eqList {
+		if err := ctrl.syncQuota(eq, now); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+func (ctrl *ScheduledQuotaController) syncQuota(eq *v1alpha1.ElasticQuota, now time.Time) error {
+	schedules, err := extension.GetQuotaSchedules(eq)
+	if err != nil {
+		return err
+	}
+	originalSpec, err := extension.GetQuotaOriginalSpec(eq)
+	if err != nil {
+		return err
+	}
+	if len(schedules) == 0 && originalSpec == nil {
+		return nil
+	}
+
+	// the min/max changed by the user while a schedule is active are taken into the original spec, so that they
+	// are neither reverted by the schedule nor lost when the schedule ends
+	if originalSpec != nil && originalSpec.Applied != nil {
+		if !isResourceListEqual(eq.Spec.Min, originalSpec.Applied.Min) {
+			originalSpec.Min = eq.Spec.Min
+		}
+		if !isResourceListEqual(eq.Spec.Max, originalSpec.Applied.Max) {
+			originalSpec.Max = eq.Spec.Max
+		}
+	}
+
+	newEQ := eq.DeepCopy()
+	if newEQ.Annotations == nil {
+		newEQ.Annotations = make(map[string]string)
+	}
+	activeSchedule := extension.GetActiveQuotaSchedule(schedules, now)
+	if activeSchedule != nil {
+		if originalSpec == nil {
+			originalSpec = &extension.QuotaOriginalSpec{
+				Min: eq.Spec.Min,
+				Max: eq.Spec.Max,
+			}
+		}
+		newEQ.Spec.Min = originalSpec.Min
+		if activeSchedule.Min != nil {
+			newEQ.Spec.Min = activeSchedule.Min
+		}
+		newEQ.Spec.Max = originalSpec.Max
+		if activeSchedule.Max != nil {
+			newEQ.Spec.Max = activeSchedule.Max
+		}
+		originalSpec.Applied = &extension.QuotaAppliedSpec{
+			Min: newEQ.Spec.Min,
+			Max: newEQ.Spec.Max,
+		}
+		data, err := json.Marshal(originalSpec)
+		if err != nil {
+			return err
+		}
+		newEQ.Annotations[extension.AnnotationQuotaOriginalSpec] = string(data)
+		newEQ.Annotations[extension.AnnotationQuotaActiveSchedule] = activeSchedule.Name
+	} else if originalSpec != nil {
+		newEQ.Spec.Min = originalSpec.Min
+		newEQ.Spec.Max = originalSpec.Max
+		delete(newEQ.Annotations, extension.AnnotationQuotaOriginalSpec)
+		delete(newEQ.Annotations, extension.AnnotationQuotaActiveSchedule)
+	}
+
+	if isResourceListEqual(eq.Spec.Min, newEQ.Spec.Min) &&
+		isResourceListEqual(eq.Spec.Max, newEQ.Spec.Max) &&
+		eq.Annotations[extension.AnnotationQuotaOriginalSpec] == newEQ.Annotations[extension.AnnotationQuotaOriginalSpec] &&
+		eq.Annotations[extension.AnnotationQuotaActiveSchedule] == newEQ.Annotations[extension.AnnotationQuotaActiveSchedule] {
+		return nil
+	}
+
+	klog.V(4).Infof("apply quota schedule, quota: %v, activeSchedule: %v, oldMin: %v, newMin: %v, oldMax: %v, newMax: %v",
+		eq.Name, newEQ.Annotations[extension.AnnotationQuotaActiveSchedule],
+		printResourceList(eq.Spec.Min), printResourceList(newEQ.Spec.Min),
+		printResourceList(eq.Spec.Max), printResourceList(newEQ.Spec.Max))
+
+	patch, err := util.CreateMergePatch(eq, newEQ)
+	if err != nil {
+		return err
+	}
+	return koordutil.RetryOnConflictOrTooManyRequests(func() error {
+		_, patchErr := ctrl.schedClient.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).
+			Patch(context.TODO(), eq.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		return patchErr
+	})
+}
+
+func isResourceListEqual(a, b v1.ResourceList) bool {
+	return quotav1.Equals(quotav1.RemoveZeros(a), quotav1.RemoveZeros(b))
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+	fakeschedclientset "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned/fake"
+	schedlister "sigs.k8s.io/scheduler-plugins/pkg/generated/listers/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestScheduledQuotaController(t *testing.T) {
+	eq := MakeEQ("ns1", "batch").
+		Min(MakeResourceList().CPU(10).Mem(20).Obj()).
+		Max(MakeResourceList().CPU(20).Mem(40).Obj()).
+		Annotations(map[string]string{
+			extension.AnnotationQuotaSchedules: `[{"name":"night","start":"22:00","end":"06:00","max":{"cpu":"100","memory":"200"}}]`,
+		}).Obj()
+	client := fakeschedclientset.NewSimpleClientset(eq)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	ctrl := NewScheduledQuotaController(client, schedlister.NewElasticQuotaLister(indexer))
+
+	sync := func(now time.Time) {
+		got, err := client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).Get(context.TODO(), eq.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.NoError(t, indexer.Update(got))
+		ctrl.now = func() time.Time { return now }
+		assert.Empty(t, ctrl.syncHandler())
+	}
+	get := func() *v1alpha1.ElasticQuota {
+		got, err := client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).Get(context.TODO(), eq.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return got
+	}
+
+	// outside the window, nothing changes
+	sync(time.Date(2022, 11, 4, 12, 0, 0, 0, time.UTC))
+	got := get()
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(20).Mem(40).Obj(), got.Spec.Max))
+	assert.Empty(t, got.Annotations[extension.AnnotationQuotaOriginalSpec])
+
+	// entering the window, max is overridden and min is kept
+	sync(time.Date(2022, 11, 4, 23, 0, 0, 0, time.UTC))
+	got = get()
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(10).Mem(20).Obj(), got.Spec.Min))
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(100).Mem(200).Obj(), got.Spec.Max))
+	assert.Equal(t, "night", got.Annotations[extension.AnnotationQuotaActiveSchedule])
+	originalSpec, err := extension.GetQuotaOriginalSpec(got)
+	assert.NoError(t, err)
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(20).Mem(40).Obj(), originalSpec.Max))
+
+	// still in the window after midnight, the original spec is not overwritten
+	sync(time.Date(2022, 11, 5, 1, 0, 0, 0, time.UTC))
+	got = get()
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(100).Mem(200).Obj(), got.Spec.Max))
+	originalSpec, err = extension.GetQuotaOriginalSpec(got)
+	assert.NoError(t, err)
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(20).Mem(40).Obj(), originalSpec.Max))
+
+	// the min changed by the user in the window is kept and taken into the original spec
+	got.Spec.Min = MakeResourceList().CPU(15).Mem(30).Obj()
+	_, err = client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).Update(context.TODO(), got, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	sync(time.Date(2022, 11, 5, 2, 0, 0, 0, time.UTC))
+	got = get()
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(15).Mem(30).Obj(), got.Spec.Min))
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(100).Mem(200).Obj(), got.Spec.Max))
+	originalSpec, err = extension.GetQuotaOriginalSpec(got)
+	assert.NoError(t, err)
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(15).Mem(30).Obj(), originalSpec.Min))
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(20).Mem(40).Obj(), originalSpec.Max))
+
+	// the max overridden by the schedule is applied again, and the one changed by the user is restored later
+	got.Spec.Max = MakeResourceList().CPU(30).Mem(60).Obj()
+	_, err = client.SchedulingV1alpha1().ElasticQuotas(eq.Namespace).Update(context.TODO(), got, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	sync(time.Date(2022, 11, 5, 3, 0, 0, 0, time.UTC))
+	got = get()
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(100).Mem(200).Obj(), got.Spec.Max))
+	originalSpec, err = extension.GetQuotaOriginalSpec(got)
+	assert.NoError(t, err)
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(30).Mem(60).Obj(), originalSpec.Max))
+
+	// leaving the window, the original spec is restored
+	sync(time.Date(2022, 11, 5, 6, 0, 0, 0, time.UTC))
+	got = get()
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(15).Mem(30).Obj(), got.Spec.Min))
+	assert.True(t, quotav1.Equals(MakeResourceList().CPU(30).Mem(60).Obj(), got.Spec.Max))
+	assert.Empty(t, got.Annotations[extension.AnnotationQuotaOriginalSpec])
+	assert.Empty(t, got.Annotations[extension.AnnotationQuotaActiveSchedule])
+}
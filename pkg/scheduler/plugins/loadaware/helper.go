@@ -40,6 +40,20 @@ func isNodeMetricExpired(nodeMetric *slov1alpha1.NodeMetric, nodeMetricExpiratio
 			time.Since(nodeMetric.Status.UpdateTime.Time) >= time.Duration(nodeMetricExpirationSeconds)*time.Second
 }
 
+// isNodeMetricDegraded checks whether the NodeMetric is marked degraded by koord-manager due to the anomalous reports,
+// in which case the metrics should not be trusted.
+func isNodeMetricDegraded(nodeMetric *slov1alpha1.NodeMetric) bool {
+	if nodeMetric == nil {
+		return false
+	}
+	for _, condition := range nodeMetric.Status.Conditions {
+		if condition.Type == slov1alpha1.NodeMetricConditionDegraded {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func getNodeMetricReportInterval(nodeMetric *slov1alpha1.NodeMetric) time.Duration {
 	if nodeMetric.Spec.CollectPolicy == nil || nodeMetric.Spec.CollectPolicy.ReportIntervalSeconds == nil {
 		return DefaultNodeMetricReportInterval
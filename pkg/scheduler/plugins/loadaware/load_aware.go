@@ -44,6 +44,8 @@ const (
 	ErrReasonNodeMetricExpired              = "node(s) nodeMetric expired"
 	ErrReasonUsageExceedThreshold           = "node(s) %s usage exceed threshold"
 	ErrReasonAggregatedUsageExceedThreshold = "node(s) %s aggregated usage exceed threshold"
+	ErrReasonNodeMigrating                  = "node(s) are being unloaded by pod migration"
+	ErrReasonProdCPUPressureExceedThreshold = "node(s) prod pods cpu pressure exceed threshold"
 )
 
 const (
@@ -68,6 +70,7 @@ type Plugin struct {
 	nodeMetricLister slolisters.NodeMetricLister
 	estimator        estimator.Estimator
 	podAssignCache   *podAssignCache
+	migratingNodes   *migratingNodeCache
 }
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
@@ -91,6 +94,14 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	podLister := podInformer.Lister()
 	nodeMetricLister := frameworkExtender.KoordinatorSharedInformerFactory().Slo().V1alpha1().NodeMetrics().Lister()
 
+	var migratingNodes *migratingNodeCache
+	if pluginArgs.FilterMigratingNodes {
+		koordSharedInformerFactory := frameworkExtender.KoordinatorSharedInformerFactory()
+		migratingNodes = newMigratingNodeCache(podLister)
+		jobInformer := koordSharedInformerFactory.Scheduling().V1alpha1().PodMigrationJobs()
+		frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), koordSharedInformerFactory, jobInformer.Informer(), migratingNodes)
+	}
+
 	estimator, err := estimator.NewEstimator(pluginArgs, handle)
 	if err != nil {
 		return nil, err
@@ -103,6 +114,7 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		nodeMetricLister: nodeMetricLister,
 		estimator:        estimator,
 		podAssignCache:   assignCache,
+		migratingNodes:   migratingNodes,
 	}, nil
 }
 
@@ -113,6 +125,14 @@ func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *c
 	if node == nil {
 		return framework.NewStatus(framework.Error, "node not found")
 	}
+	if status := frameworkext.CheckNodeSchedulingPaused(node); !status.IsSuccess() {
+		return status
+	}
+
+	// the DaemonSet pods and the critical pods are bound to the node, so they are never kept out
+	if p.migratingNodes != nil && !isExemptFromMigratingNodes(pod) && p.migratingNodes.isMigrating(node.Name) {
+		return framework.NewStatus(framework.Unschedulable, ErrReasonNodeMigrating)
+	}
 
 	nodeMetric, err := p.nodeMetricLister.Get(node.Name)
 	if err != nil {
@@ -131,6 +151,18 @@ func (p *Plugin) Filter(ctx context.Context, state *framework.CycleState, pod *c
 		}
 	}
 
+	// the degraded metrics are untrustworthy, fall back to the situation where there is no load-aware scheduling.
+	if isNodeMetricDegraded(nodeMetric) {
+		return nil
+	}
+
+	if p.args.ProdCPUPressureThreshold > 0 && extension.GetPriorityClass(pod) == extension.PriorityProd {
+		status := p.filterProdCPUPressure(nodeMetric, p.args.ProdCPUPressureThreshold)
+		if !status.IsSuccess() {
+			return status
+		}
+	}
+
 	filterProfile := generateUsageThresholdsFilterProfile(node, p.args)
 	if len(filterProfile.ProdUsageThresholds) > 0 && extension.GetPriorityClass(pod) == extension.PriorityProd {
 		status := p.filterProdUsage(node, nodeMetric, filterProfile.ProdUsageThresholds)
@@ -228,6 +260,26 @@ func (p *Plugin) filterProdUsage(node *corev1.Node, nodeMetric *slov1alpha1.Node
 	return nil
 }
 
+// filterProdCPUPressure filters the node if any Prod Pod on it is stalled on the CPU over the threshold, which tells
+// the Pods suffering contention from the Pods simply busy with a high usage.
+func (p *Plugin) filterProdCPUPressure(nodeMetric *slov1alpha1.NodeMetric, threshold int64) *framework.Status {
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		psi, err := extension.GetPodPSI(podMetric)
+		if err != nil || psi == nil {
+			continue
+		}
+		if psi.SomeCPU < float64(threshold) {
+			continue
+		}
+		pod, err := p.podLister.Pods(podMetric.Namespace).Get(podMetric.Name)
+		if err != nil || extension.GetPriorityClass(pod) != extension.PriorityProd {
+			continue
+		}
+		return framework.NewStatus(framework.Unschedulable, ErrReasonProdCPUPressureExceedThreshold)
+	}
+	return nil
+}
+
 func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
 	return nil
 }
@@ -262,6 +314,9 @@ func (p *Plugin) Score(ctx context.Context, state *framework.CycleState, pod *co
 	if p.args.NodeMetricExpirationSeconds != nil && isNodeMetricExpired(nodeMetric, *p.args.NodeMetricExpirationSeconds) {
 		return 0, nil
 	}
+	if isNodeMetricDegraded(nodeMetric) {
+		return 0, nil
+	}
 
 	prodPod := extension.GetPriorityClass(pod) == extension.PriorityProd && p.args.ScoreAccordingProdUsage
 	podMetrics := buildPodMetricMap(p.podLister, nodeMetric, prodPod)
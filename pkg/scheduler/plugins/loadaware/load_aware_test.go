@@ -39,6 +39,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
 	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
@@ -1820,3 +1821,353 @@ func TestScore(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterMigratingNodes(t *testing.T) {
+	tests := []struct {
+		name                 string
+		filterMigratingNodes bool
+		jobPhase             sev1alpha1.PodMigrationJobPhase
+		jobAge               time.Duration
+		schedulingPod        *corev1.Pod
+		wantStatus           *framework.Status
+	}{
+		{
+			name:                 "filter node with running PodMigrationJob",
+			filterMigratingNodes: true,
+			jobPhase:             sev1alpha1.PodMigrationJobRunning,
+			wantStatus:           framework.NewStatus(framework.Unschedulable, ErrReasonNodeMigrating),
+		},
+		{
+			name:                 "node with stale PodMigrationJob is schedulable",
+			filterMigratingNodes: true,
+			jobPhase:             sev1alpha1.PodMigrationJobRunning,
+			jobAge:               10 * time.Minute,
+			wantStatus:           nil,
+		},
+		{
+			name:                 "DaemonSet pod is not filtered by the migrating node",
+			filterMigratingNodes: true,
+			jobPhase:             sev1alpha1.PodMigrationJobRunning,
+			schedulingPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "test-ds", Controller: pointer.Bool(true)},
+					},
+				},
+			},
+			wantStatus: nil,
+		},
+		{
+			name:                 "critical pod is not filtered by the migrating node",
+			filterMigratingNodes: true,
+			jobPhase:             sev1alpha1.PodMigrationJobRunning,
+			schedulingPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system"},
+				Spec:       corev1.PodSpec{PriorityClassName: "system-node-critical", Priority: pointer.Int32(2000001000)},
+			},
+			wantStatus: nil,
+		},
+		{
+			name:                 "node with succeeded PodMigrationJob is schedulable",
+			filterMigratingNodes: true,
+			jobPhase:             sev1alpha1.PodMigrationJobSucceeded,
+			wantStatus:           nil,
+		},
+		{
+			name:                 "filterMigratingNodes disabled",
+			filterMigratingNodes: false,
+			jobPhase:             sev1alpha1.PodMigrationJobRunning,
+			wantStatus:           nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.FilterMigratingNodes = pointer.Bool(tt.filterMigratingNodes)
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test-pod-1",
+					UID:       "123456",
+				},
+				Spec: corev1.PodSpec{
+					NodeName: "test-node-1",
+				},
+			}
+			job := &sev1alpha1.PodMigrationJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-job-1",
+					UID:               "job-1",
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-tt.jobAge)),
+				},
+				Spec: sev1alpha1.PodMigrationJobSpec{
+					PodRef: &corev1.ObjectReference{
+						Namespace: pod.Namespace,
+						Name:      pod.Name,
+						UID:       pod.UID,
+					},
+				},
+				Status: sev1alpha1.PodMigrationJobStatus{
+					Phase: tt.jobPhase,
+				},
+			}
+
+			koordClientSet := koordfake.NewSimpleClientset(job)
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+			extenderFactory, _ := frameworkext.NewFrameworkExtenderFactory(
+				frameworkext.WithKoordinatorClientSet(koordClientSet),
+				frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+			)
+			proxyNew := frameworkext.PluginFactoryProxy(extenderFactory, New)
+
+			cs := kubefake.NewSimpleClientset(pod)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+				},
+			}
+
+			snapshot := newTestSharedLister(nil, nodes)
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			}
+			fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+				frameworkruntime.WithClientSet(cs),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			assert.Nil(t, err)
+
+			p, err := proxyNew(&loadAwareSchedulingArgs, fh)
+			assert.NotNil(t, p)
+			assert.Nil(t, err)
+
+			nodeInfo, err := snapshot.Get("test-node-1")
+			assert.NoError(t, err)
+			assert.NotNil(t, nodeInfo)
+
+			schedulingPod := tt.schedulingPod
+			if schedulingPod == nil {
+				schedulingPod = &corev1.Pod{}
+			}
+			status := p.(*Plugin).Filter(context.TODO(), framework.NewCycleState(), schedulingPod, nodeInfo)
+			assert.True(t, tt.wantStatus.Equal(status), "want status: %s, but got %s", tt.wantStatus.Message(), status.Message())
+		})
+	}
+}
+
+func TestFilterProdCPUPressure(t *testing.T) {
+	tests := []struct {
+		name       string
+		threshold  int64
+		podPSI     *extension.PodPSI
+		podPrio    int32
+		testPod    *corev1.Pod
+		wantStatus *framework.Status
+	}{
+		{
+			name:       "filter node with prod pod under cpu pressure",
+			threshold:  20,
+			podPSI:     &extension.PodPSI{SomeCPU: 30},
+			podPrio:    extension.PriorityProdValueMax,
+			testPod:    schedulertesting.MakePod().Namespace("default").Name("prod-pod-2").Priority(extension.PriorityProdValueMax).Obj(),
+			wantStatus: framework.NewStatus(framework.Unschedulable, ErrReasonProdCPUPressureExceedThreshold),
+		},
+		{
+			name:      "prod pod under the threshold",
+			threshold: 20,
+			podPSI:    &extension.PodPSI{SomeCPU: 10},
+			podPrio:   extension.PriorityProdValueMax,
+			testPod:   schedulertesting.MakePod().Namespace("default").Name("prod-pod-2").Priority(extension.PriorityProdValueMax).Obj(),
+		},
+		{
+			name:      "batch pod under cpu pressure is ignored",
+			threshold: 20,
+			podPSI:    &extension.PodPSI{SomeCPU: 30},
+			podPrio:   extension.PriorityBatchValueMax,
+			testPod:   schedulertesting.MakePod().Namespace("default").Name("prod-pod-2").Priority(extension.PriorityProdValueMax).Obj(),
+		},
+		{
+			name:      "non-prod pod is not filtered",
+			threshold: 20,
+			podPSI:    &extension.PodPSI{SomeCPU: 30},
+			podPrio:   extension.PriorityProdValueMax,
+			testPod:   schedulertesting.MakePod().Namespace("default").Name("batch-pod-1").Priority(extension.PriorityBatchValueMax).Obj(),
+		},
+		{
+			name:    "pod psi not reported",
+			podPrio: extension.PriorityProdValueMax,
+			testPod: schedulertesting.MakePod().Namespace("default").Name("prod-pod-2").Priority(extension.PriorityProdValueMax).Obj(),
+		},
+		{
+			name:    "disabled by default",
+			podPSI:  &extension.PodPSI{SomeCPU: 30},
+			podPrio: extension.PriorityProdValueMax,
+			testPod: schedulertesting.MakePod().Namespace("default").Name("prod-pod-2").Priority(extension.PriorityProdValueMax).Obj(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2args.FilterExpiredNodeMetrics = pointer.Bool(false)
+			v1beta2args.ProdCPUPressureThreshold = pointer.Int64(tt.threshold)
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			koordClientSet := koordfake.NewSimpleClientset()
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+			extenderFactory, _ := frameworkext.NewFrameworkExtenderFactory(
+				frameworkext.WithKoordinatorClientSet(koordClientSet),
+				frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+			)
+			proxyNew := frameworkext.PluginFactoryProxy(extenderFactory, New)
+
+			cs := kubefake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			pod := schedulertesting.MakePod().Namespace("default").Name("pod-1").Priority(tt.podPrio).Obj()
+			_, err = cs.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node-1",
+					},
+				},
+			}
+
+			snapshot := newTestSharedLister(nil, nodes)
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			}
+			fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+				frameworkruntime.WithClientSet(cs),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			assert.Nil(t, err)
+
+			p, err := proxyNew(&loadAwareSchedulingArgs, fh)
+			assert.NotNil(t, p)
+			assert.Nil(t, err)
+
+			podMetric := &slov1alpha1.PodMetricInfo{Namespace: pod.Namespace, Name: pod.Name}
+			if tt.podPSI != nil {
+				extension.SetPodPSI(podMetric, tt.podPSI)
+			}
+			nodeMetric := &slov1alpha1.NodeMetric{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-node-1",
+				},
+				Status: slov1alpha1.NodeMetricStatus{
+					UpdateTime: &metav1.Time{Time: time.Now()},
+					NodeMetric: &slov1alpha1.NodeMetricInfo{},
+					PodsMetric: []*slov1alpha1.PodMetricInfo{podMetric},
+				},
+			}
+			_, err = koordClientSet.SloV1alpha1().NodeMetrics().Create(context.TODO(), nodeMetric, metav1.CreateOptions{})
+			assert.NoError(t, err)
+
+			informerFactory.Start(context.TODO().Done())
+			informerFactory.WaitForCacheSync(context.TODO().Done())
+
+			koordSharedInformerFactory.Start(context.TODO().Done())
+			koordSharedInformerFactory.WaitForCacheSync(context.TODO().Done())
+
+			nodeInfo, err := snapshot.Get("test-node-1")
+			assert.NoError(t, err)
+			assert.NotNil(t, nodeInfo)
+
+			status := p.(*Plugin).Filter(context.TODO(), framework.NewCycleState(), tt.testPod, nodeInfo)
+			assert.True(t, tt.wantStatus.Equal(status), "want status: %s, but got %s", tt.wantStatus.Message(), status.Message())
+		})
+	}
+}
+
+func TestFilterSchedulingPausedNodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantStatus  *framework.Status
+	}{
+		{
+			name:        "filter node with scheduling paused",
+			annotations: map[string]string{extension.AnnotationNodeSchedulingPaused: "true"},
+			wantStatus:  framework.NewStatus(framework.UnschedulableAndUnresolvable, frameworkext.ErrReasonNodeSchedulingPaused),
+		},
+		{
+			name:        "node with scheduling resumed is schedulable",
+			annotations: map[string]string{extension.AnnotationNodeSchedulingPaused: "false"},
+			wantStatus:  nil,
+		},
+		{
+			name:       "node without annotation is schedulable",
+			wantStatus: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v1beta2args v1beta2.LoadAwareSchedulingArgs
+			v1beta2.SetDefaults_LoadAwareSchedulingArgs(&v1beta2args)
+			var loadAwareSchedulingArgs config.LoadAwareSchedulingArgs
+			err := v1beta2.Convert_v1beta2_LoadAwareSchedulingArgs_To_config_LoadAwareSchedulingArgs(&v1beta2args, &loadAwareSchedulingArgs, nil)
+			assert.NoError(t, err)
+
+			koordClientSet := koordfake.NewSimpleClientset()
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+			extenderFactory, _ := frameworkext.NewFrameworkExtenderFactory(
+				frameworkext.WithKoordinatorClientSet(koordClientSet),
+				frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+			)
+			proxyNew := frameworkext.PluginFactoryProxy(extenderFactory, New)
+
+			cs := kubefake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+
+			nodes := []*corev1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "test-node-1",
+						Annotations: tt.annotations,
+					},
+				},
+			}
+
+			snapshot := newTestSharedLister(nil, nodes)
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			}
+			fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+				frameworkruntime.WithClientSet(cs),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			assert.Nil(t, err)
+
+			p, err := proxyNew(&loadAwareSchedulingArgs, fh)
+			assert.NotNil(t, p)
+			assert.Nil(t, err)
+
+			nodeInfo, err := snapshot.Get("test-node-1")
+			assert.NoError(t, err)
+			assert.NotNil(t, nodeInfo)
+
+			status := p.(*Plugin).Filter(context.TODO(), framework.NewCycleState(), &corev1.Pod{}, nodeInfo)
+			assert.True(t, tt.wantStatus.Equal(status), "want status: %s, but got %s", tt.wantStatus.Message(), status.Message())
+		})
+	}
+}
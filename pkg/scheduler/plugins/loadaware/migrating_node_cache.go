@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	kubelettypes "k8s.io/kubernetes/pkg/kubelet/types"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// defaultMigratingJobTTL is the max duration a PodMigrationJob without TTL keeps its source node migrating, in line
+// with the default job TTL of the descheduler. It prevents the nodes being filtered forever by the stale jobs, e.g.
+// whose status is never updated since the descheduler is down.
+const defaultMigratingJobTTL = 5 * time.Minute
+
+// migratingNodeCache records the source nodes of in-flight PodMigrationJobs.
+// These nodes are being unloaded by the descheduler, and placing new Pods onto them
+// would work against the rebalance action.
+type migratingNodeCache struct {
+	lock      sync.RWMutex
+	podLister corev1listers.PodLister
+	// jobs stores the source node of each active PodMigrationJob, indexed by the job's types.UID
+	jobs map[types.UID]string
+	// nodes stores the expiration time of the active PodMigrationJobs on each source node
+	nodes map[string]map[types.UID]time.Time
+}
+
+func newMigratingNodeCache(podLister corev1listers.PodLister) *migratingNodeCache {
+	return &migratingNodeCache{
+		podLister: podLister,
+		jobs:      map[types.UID]string{},
+		nodes:     map[string]map[types.UID]time.Time{},
+	}
+}
+
+func isMigrationJobActive(job *sev1alpha1.PodMigrationJob) bool {
+	return job.Status.Phase == "" ||
+		job.Status.Phase == sev1alpha1.PodMigrationJobPending ||
+		job.Status.Phase == sev1alpha1.PodMigrationJobRunning
+}
+
+// getMigrationJobExpireTime returns the time after which the job is regarded as stale.
+func getMigrationJobExpireTime(job *sev1alpha1.PodMigrationJob) time.Time {
+	ttl := defaultMigratingJobTTL
+	if job.Spec.TTL != nil && job.Spec.TTL.Duration > 0 {
+		ttl = job.Spec.TTL.Duration
+	}
+	startTime := job.CreationTimestamp.Time
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	return startTime.Add(ttl)
+}
+
+// isExemptFromMigratingNodes returns true if the pod can be placed on the migrating nodes, i.e. the DaemonSet pods
+// and the critical pods, which must run on the specific nodes.
+func isExemptFromMigratingNodes(pod *corev1.Pod) bool {
+	if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+		return true
+	}
+	return kubelettypes.IsCriticalPod(pod)
+}
+
+// isMigrating returns true if the node is the source node of any active PodMigrationJob not expired.
+func (c *migratingNodeCache) isMigrating(nodeName string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	now := time.Now()
+	for _, expireTime := range c.nodes[nodeName] {
+		if now.Before(expireTime) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *migratingNodeCache) updateJob(job *sev1alpha1.PodMigrationJob) {
+	if !isMigrationJobActive(job) {
+		c.deleteJob(job)
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if nodeName, ok := c.jobs[job.UID]; ok {
+		c.nodes[nodeName][job.UID] = getMigrationJobExpireTime(job)
+		return
+	}
+	nodeName := c.getSourceNode(job)
+	if nodeName == "" {
+		return
+	}
+	c.jobs[job.UID] = nodeName
+	if c.nodes[nodeName] == nil {
+		c.nodes[nodeName] = map[types.UID]time.Time{}
+	}
+	c.nodes[nodeName][job.UID] = getMigrationJobExpireTime(job)
+}
+
+func (c *migratingNodeCache) deleteJob(job *sev1alpha1.PodMigrationJob) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	nodeName, ok := c.jobs[job.UID]
+	if !ok {
+		return
+	}
+	delete(c.jobs, job.UID)
+	delete(c.nodes[nodeName], job.UID)
+	if len(c.nodes[nodeName]) == 0 {
+		delete(c.nodes, nodeName)
+	}
+}
+
+func (c *migratingNodeCache) getSourceNode(job *sev1alpha1.PodMigrationJob) string {
+	podRef := job.Spec.PodRef
+	if podRef == nil || podRef.Name == "" {
+		return ""
+	}
+	pod, err := c.podLister.Pods(podRef.Namespace).Get(podRef.Name)
+	if err != nil {
+		return ""
+	}
+	if podRef.UID != "" && podRef.UID != pod.UID {
+		return ""
+	}
+	return pod.Spec.NodeName
+}
+
+func (c *migratingNodeCache) OnAdd(obj interface{}) {
+	job, ok := obj.(*sev1alpha1.PodMigrationJob)
+	if !ok {
+		return
+	}
+	c.updateJob(job)
+}
+
+func (c *migratingNodeCache) OnUpdate(oldObj, newObj interface{}) {
+	job, ok := newObj.(*sev1alpha1.PodMigrationJob)
+	if !ok {
+		return
+	}
+	c.updateJob(job)
+}
+
+func (c *migratingNodeCache) OnDelete(obj interface{}) {
+	var job *sev1alpha1.PodMigrationJob
+	switch t := obj.(type) {
+	case *sev1alpha1.PodMigrationJob:
+		job = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		job, ok = t.Obj.(*sev1alpha1.PodMigrationJob)
+		if !ok {
+			return
+		}
+	default:
+		return
+	}
+	c.deleteJob(job)
+}
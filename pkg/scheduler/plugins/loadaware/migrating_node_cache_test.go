@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/pointer"
+
+	sev1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newTestMigratingNodeCache(t *testing.T, pods ...*corev1.Pod) *migratingNodeCache {
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	for _, pod := range pods {
+		assert.NoError(t, podInformer.Informer().GetStore().Add(pod))
+	}
+	return newMigratingNodeCache(podInformer.Lister())
+}
+
+func TestMigratingNodeCache(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod-1",
+			UID:       "123456",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+		},
+	}
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-job-1",
+			UID:  "job-1",
+		},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+		},
+		Status: sev1alpha1.PodMigrationJobStatus{
+			Phase: sev1alpha1.PodMigrationJobPending,
+		},
+	}
+
+	c := newTestMigratingNodeCache(t, pod)
+	c.OnAdd(job)
+	assert.True(t, c.isMigrating("test-node-1"))
+	assert.False(t, c.isMigrating("test-node-2"))
+
+	runningJob := job.DeepCopy()
+	runningJob.Status.Phase = sev1alpha1.PodMigrationJobRunning
+	c.OnUpdate(job, runningJob)
+	assert.True(t, c.isMigrating("test-node-1"))
+	assert.Len(t, c.jobs, 1)
+
+	succeededJob := runningJob.DeepCopy()
+	succeededJob.Status.Phase = sev1alpha1.PodMigrationJobSucceeded
+	c.OnUpdate(runningJob, succeededJob)
+	assert.False(t, c.isMigrating("test-node-1"))
+	assert.Empty(t, c.jobs)
+	assert.Empty(t, c.nodes)
+
+	c.OnAdd(job)
+	assert.True(t, c.isMigrating("test-node-1"))
+	c.OnDelete(cache.DeletedFinalStateUnknown{Obj: job})
+	assert.False(t, c.isMigrating("test-node-1"))
+}
+
+func TestMigratingNodeCacheIgnoreUnknownPod(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod-1",
+			UID:       "123456",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+		},
+	}
+	c := newTestMigratingNodeCache(t, pod)
+
+	c.OnAdd(&sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "missing-pod", UID: "job-1"},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{Namespace: "default", Name: "test-pod-2"},
+		},
+	})
+	c.OnAdd(&sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "mismatched-uid", UID: "job-2"},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{Namespace: "default", Name: "test-pod-1", UID: "654321"},
+		},
+	})
+	assert.False(t, c.isMigrating("test-node-1"))
+	assert.Empty(t, c.jobs)
+}
+
+func TestMigratingNodeCacheExpireStaleJobs(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod-1",
+			UID:       "123456",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+		},
+	}
+	job := &sev1alpha1.PodMigrationJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-job-1",
+			UID:               "job-1",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Minute)),
+		},
+		Spec: sev1alpha1.PodMigrationJobSpec{
+			PodRef: &corev1.ObjectReference{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				UID:       pod.UID,
+			},
+		},
+		Status: sev1alpha1.PodMigrationJobStatus{
+			Phase: sev1alpha1.PodMigrationJobRunning,
+		},
+	}
+
+	c := newTestMigratingNodeCache(t, pod)
+	c.OnAdd(job)
+	assert.False(t, c.isMigrating("test-node-1"), "the job exceeding the default TTL is stale")
+
+	// the job TTL overrides the default
+	jobWithTTL := job.DeepCopy()
+	jobWithTTL.Spec.TTL = &metav1.Duration{Duration: time.Hour}
+	c.OnUpdate(job, jobWithTTL)
+	assert.True(t, c.isMigrating("test-node-1"))
+
+	c.OnDelete(jobWithTTL)
+	assert.False(t, c.isMigrating("test-node-1"))
+	assert.Empty(t, c.jobs)
+	assert.Empty(t, c.nodes)
+}
+
+func Test_isExemptFromMigratingNodes(t *testing.T) {
+	assert.False(t, isExemptFromMigratingNodes(&corev1.Pod{}))
+	assert.True(t, isExemptFromMigratingNodes(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "test-ds", Controller: pointer.Bool(true)},
+			},
+		},
+	}))
+	assert.True(t, isExemptFromMigratingNodes(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system"},
+		Spec:       corev1.PodSpec{PriorityClassName: "system-cluster-critical", Priority: pointer.Int32(2000000000)},
+	}))
+}
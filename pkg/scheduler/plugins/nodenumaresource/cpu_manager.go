@@ -27,7 +27,6 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
-	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
@@ -37,7 +36,8 @@ type CPUManager interface {
 		node *corev1.Node,
 		numCPUsNeeded int,
 		cpuBindPolicy schedulingconfig.CPUBindPolicy,
-		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy) (cpuset.CPUSet, error)
+		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy,
+		excludedCPUs cpuset.CPUSet) (cpuset.CPUSet, error)
 
 	UpdateAllocatedCPUSet(nodeName string, podUID types.UID, cpuset cpuset.CPUSet, cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy)
 
@@ -47,9 +47,12 @@ type CPUManager interface {
 		node *corev1.Node,
 		numCPUsNeeded int,
 		cpuBindPolicy schedulingconfig.CPUBindPolicy,
-		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy) int64
+		cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy,
+		excludedCPUs cpuset.CPUSet) int64
 
 	GetAvailableCPUs(nodeName string) (availableCPUs cpuset.CPUSet, allocated CPUDetails, err error)
+
+	GetAllocatedCPUSet(nodeName string, podUID types.UID) (cpuset.CPUSet, bool)
 }
 
 type cpuManagerImpl struct {
@@ -112,6 +115,7 @@ func (c *cpuManagerImpl) Allocate(
 	numCPUsNeeded int,
 	cpuBindPolicy schedulingconfig.CPUBindPolicy,
 	cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy,
+	excludedCPUs cpuset.CPUSet,
 ) (cpuset.CPUSet, error) {
 	result := cpuset.CPUSet{}
 	// The Pod requires the CPU to be allocated according to CPUBindPolicy,
@@ -125,7 +129,7 @@ func (c *cpuManagerImpl) Allocate(
 		return result, errors.New(ErrInvalidCPUTopology)
 	}
 
-	reservedCPUs := cpuTopologyOptions.ReservedCPUs
+	reservedCPUs := cpuTopologyOptions.ReservedCPUs.Union(excludedCPUs)
 
 	allocation := c.getOrCreateAllocation(node.Name)
 	allocation.lock.Lock()
@@ -171,6 +175,7 @@ func (c *cpuManagerImpl) Score(
 	numCPUsNeeded int,
 	cpuBindPolicy schedulingconfig.CPUBindPolicy,
 	cpuExclusivePolicy schedulingconfig.CPUExclusivePolicy,
+	excludedCPUs cpuset.CPUSet,
 ) int64 {
 	cpuTopologyOptions := c.topologyManager.GetCPUTopologyOptions(node.Name)
 	if cpuTopologyOptions.CPUTopology == nil || !cpuTopologyOptions.CPUTopology.IsValid() {
@@ -178,7 +183,7 @@ func (c *cpuManagerImpl) Score(
 	}
 
 	numaAllocateStrategy := c.getNUMAAllocateStrategy(node)
-	reservedCPUs := cpuTopologyOptions.ReservedCPUs
+	reservedCPUs := cpuTopologyOptions.ReservedCPUs.Union(excludedCPUs)
 
 	allocation := c.getOrCreateAllocation(node.Name)
 	allocation.lock.Lock()
@@ -270,11 +275,7 @@ func leastRequestedScore(requested, capacity int64) int64 {
 }
 
 func (c *cpuManagerImpl) getNUMAAllocateStrategy(node *corev1.Node) schedulingconfig.NUMAAllocateStrategy {
-	numaAllocateStrategy := c.numaAllocateStrategy
-	if val := schedulingconfig.NUMAAllocateStrategy(node.Labels[extension.LabelNodeNUMAAllocateStrategy]); val != "" {
-		numaAllocateStrategy = val
-	}
-	return numaAllocateStrategy
+	return getNUMAAllocateStrategy(node, c.numaAllocateStrategy)
 }
 
 func (c *cpuManagerImpl) GetAvailableCPUs(nodeName string) (availableCPUs cpuset.CPUSet, allocated CPUDetails, err error) {
@@ -292,3 +293,11 @@ func (c *cpuManagerImpl) GetAvailableCPUs(nodeName string) (availableCPUs cpuset
 	availableCPUs, allocated = allocation.getAvailableCPUs(cpuTopologyOptions.CPUTopology, cpuTopologyOptions.MaxRefCount, cpuTopologyOptions.ReservedCPUs)
 	return availableCPUs, allocated, nil
 }
+
+func (c *cpuManagerImpl) GetAllocatedCPUSet(nodeName string, podUID types.UID) (cpuset.CPUSet, bool) {
+	allocation := c.getOrCreateAllocation(nodeName)
+	allocation.lock.Lock()
+	defer allocation.lock.Unlock()
+	cpus, ok := allocation.allocatedPods[podUID]
+	return cpus, ok
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenumaresource
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/tools/cache"
+	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+// hugePageAllocation is the hugepages allocated to a pod on a NUMA Node.
+type hugePageAllocation struct {
+	numaNode  int
+	hugePages corev1.ResourceList
+}
+
+// hugePageManager tracks the hugepages allocated on the NUMA Nodes. The pods requesting hugepages are placed on a
+// single NUMA Node having enough free hugepages, so that the memory of the pods can be bound to the NUMA Node.
+type hugePageManager struct {
+	lock sync.Mutex
+	// allocations are the hugepages allocated to the pods, keyed by the node name and the pod UID.
+	allocations map[string]map[types.UID]hugePageAllocation
+}
+
+func newHugePageManager(handle framework.Handle) *hugePageManager {
+	manager := &hugePageManager{
+		allocations: map[string]map[types.UID]hugePageAllocation{},
+	}
+	handle.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{DeleteFunc: manager.onNodeDelete})
+	return manager
+}
+
+func (m *hugePageManager) onNodeDelete(obj interface{}) {
+	var node *corev1.Node
+	switch t := obj.(type) {
+	case *corev1.Node:
+		node = t
+	case cache.DeletedFinalStateUnknown:
+		node, _ = t.Obj.(*corev1.Node)
+	}
+	if node == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.allocations, node.Name)
+}
+
+// allocate returns the NUMA Node to place the requested hugepages on. The preferred NUMA Nodes, e.g. the ones of the
+// CPUs allocated to the pod, are picked first if they have enough free hugepages.
+func (m *hugePageManager) allocate(
+	nodeName string,
+	numaNodeHugePages map[int]corev1.ResourceList,
+	requests corev1.ResourceList,
+	preferredNUMANodes []int,
+	numaAllocateStrategy schedulingconfig.NUMAAllocateStrategy,
+) (int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	free := m.getFreeHugePagesNoLock(nodeName, numaNodeHugePages)
+	var candidates, preferredCandidates []int
+	for numaNode, freeHugePages := range free {
+		if fits, _ := quotav1.LessThanOrEqual(requests, freeHugePages); !fits || !hasAllResources(freeHugePages, requests) {
+			continue
+		}
+		candidates = append(candidates, numaNode)
+		for _, preferred := range preferredNUMANodes {
+			if preferred == numaNode {
+				preferredCandidates = append(preferredCandidates, numaNode)
+			}
+		}
+	}
+	if len(preferredCandidates) > 0 {
+		candidates = preferredCandidates
+	}
+	if len(candidates) == 0 {
+		return -1, errors.New(ErrInsufficientNUMAHugePages)
+	}
+
+	// pack the hugepages by default, or spread them with the LeastAllocated strategy
+	freeBytes := func(numaNode int) int64 {
+		var total int64
+		for resourceName := range requests {
+			quantity := free[numaNode][resourceName]
+			total += quantity.Value()
+		}
+		return total
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		iFree, jFree := freeBytes(candidates[i]), freeBytes(candidates[j])
+		if iFree != jFree {
+			if numaAllocateStrategy == schedulingconfig.NUMALeastAllocated {
+				return iFree > jFree
+			}
+			return iFree < jFree
+		}
+		return candidates[i] < candidates[j]
+	})
+	return candidates[0], nil
+}
+
+// update records the hugepages allocated to the pod on the NUMA Node.
+func (m *hugePageManager) update(nodeName string, podUID types.UID, numaNode int, hugePages corev1.ResourceList) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	allocations := m.allocations[nodeName]
+	if allocations == nil {
+		allocations = map[types.UID]hugePageAllocation{}
+		m.allocations[nodeName] = allocations
+	}
+	allocations[podUID] = hugePageAllocation{numaNode: numaNode, hugePages: hugePages}
+}
+
+func (m *hugePageManager) free(nodeName string, podUID types.UID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	allocations := m.allocations[nodeName]
+	delete(allocations, podUID)
+	if len(allocations) == 0 {
+		delete(m.allocations, nodeName)
+	}
+}
+
+func (m *hugePageManager) getFreeHugePagesNoLock(nodeName string, numaNodeHugePages map[int]corev1.ResourceList) map[int]corev1.ResourceList {
+	free := make(map[int]corev1.ResourceList, len(numaNodeHugePages))
+	for numaNode, allocatable := range numaNodeHugePages {
+		free[numaNode] = allocatable.DeepCopy()
+	}
+	for _, allocation := range m.allocations[nodeName] {
+		if freeHugePages, ok := free[allocation.numaNode]; ok {
+			free[allocation.numaNode] = quotav1.Subtract(freeHugePages, allocation.hugePages)
+		}
+	}
+	return free
+}
+
+// hasAllResources checks if the resource list contains all the requested resources.
+func hasAllResources(resourceList, requests corev1.ResourceList) bool {
+	for resourceName := range requests {
+		if _, ok := resourceList[resourceName]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getPodHugePageRequests returns the hugepages requested by the pod.
+func getPodHugePageRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests, _ := resourceapi.PodRequestsAndLimits(pod)
+	var hugePages corev1.ResourceList
+	for resourceName, quantity := range requests {
+		if !v1helper.IsHugePageResourceName(resourceName) || quantity.IsZero() {
+			continue
+		}
+		if hugePages == nil {
+			hugePages = corev1.ResourceList{}
+		}
+		hugePages[resourceName] = quantity.DeepCopy()
+	}
+	return hugePages
+}
+
+// getNUMAAllocateStrategy returns the NUMA allocate strategy of the node, which overrides the default one.
+func getNUMAAllocateStrategy(node *corev1.Node, defaultNUMAAllocateStrategy schedulingconfig.NUMAAllocateStrategy) schedulingconfig.NUMAAllocateStrategy {
+	if val := schedulingconfig.NUMAAllocateStrategy(node.Labels[extension.LabelNodeNUMAAllocateStrategy]); val != "" {
+		return val
+	}
+	return defaultNUMAAllocateStrategy
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenumaresource
+
+import (
+	"context"
+	"testing"
+
+	nrtv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+const resourceHugePages2Mi corev1.ResourceName = "hugepages-2Mi"
+
+func newHugePages(quantity string) corev1.ResourceList {
+	return corev1.ResourceList{resourceHugePages2Mi: resource.MustParse(quantity)}
+}
+
+func Test_hugePageManager_allocate(t *testing.T) {
+	numaNodeHugePages := map[int]corev1.ResourceList{
+		0: newHugePages("2Gi"),
+		1: newHugePages("4Gi"),
+	}
+	tests := []struct {
+		name                 string
+		allocated            map[int]string
+		requests             corev1.ResourceList
+		preferredNUMANodes   []int
+		numaAllocateStrategy schedulingconfig.NUMAAllocateStrategy
+		want                 int
+		wantErr              bool
+	}{
+		{
+			name:                 "pack into the NUMA Node with less free hugepages",
+			requests:             newHugePages("1Gi"),
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			want:                 0,
+		},
+		{
+			name:                 "spread to the NUMA Node with more free hugepages",
+			requests:             newHugePages("1Gi"),
+			numaAllocateStrategy: schedulingconfig.NUMALeastAllocated,
+			want:                 1,
+		},
+		{
+			name:                 "prefer the NUMA Node of the allocated CPUs",
+			requests:             newHugePages("1Gi"),
+			preferredNUMANodes:   []int{1},
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			want:                 1,
+		},
+		{
+			name:                 "the preferred NUMA Node has insufficient hugepages",
+			allocated:            map[int]string{1: "4Gi"},
+			requests:             newHugePages("1Gi"),
+			preferredNUMANodes:   []int{1},
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			want:                 0,
+		},
+		{
+			name:                 "skip the NUMA Node with insufficient hugepages",
+			allocated:            map[int]string{0: "1Gi"},
+			requests:             newHugePages("2Gi"),
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			want:                 1,
+		},
+		{
+			name:                 "no NUMA Node has enough hugepages",
+			allocated:            map[int]string{1: "3Gi"},
+			requests:             newHugePages("3Gi"),
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			wantErr:              true,
+		},
+		{
+			name:                 "the hugepages of the size are not reported",
+			requests:             corev1.ResourceList{"hugepages-1Gi": resource.MustParse("1Gi")},
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			wantErr:              true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &hugePageManager{allocations: map[string]map[types.UID]hugePageAllocation{}}
+			for numaNode, quantity := range tt.allocated {
+				m.update("test-node-1", uuid.NewUUID(), numaNode, newHugePages(quantity))
+			}
+			got, err := m.allocate("test-node-1", numaNodeHugePages, tt.requests, tt.preferredNUMANodes, tt.numaAllocateStrategy)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if !tt.wantErr {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_getNUMANodeHugePages(t *testing.T) {
+	zones := nrtv1alpha1.ZoneList{
+		{
+			Name: "node-0",
+			Type: "Node",
+			Resources: nrtv1alpha1.ResourceInfoList{
+				{Name: "cpu", Capacity: resource.MustParse("32"), Allocatable: resource.MustParse("32")},
+				{Name: "hugepages-2Mi", Capacity: resource.MustParse("2Gi"), Allocatable: resource.MustParse("2Gi")},
+			},
+		},
+		{
+			Name: "node-1",
+			Type: "Node",
+			Resources: nrtv1alpha1.ResourceInfoList{
+				{Name: "hugepages-1Gi", Capacity: resource.MustParse("8Gi"), Allocatable: resource.MustParse("4Gi")},
+			},
+		},
+		{
+			Name: "socket-0",
+			Type: "Socket",
+			Resources: nrtv1alpha1.ResourceInfoList{
+				{Name: "hugepages-2Mi", Capacity: resource.MustParse("2Gi"), Allocatable: resource.MustParse("2Gi")},
+			},
+		},
+	}
+	expected := map[int]corev1.ResourceList{
+		0: newHugePages("2Gi"),
+		1: {"hugepages-1Gi": resource.MustParse("4Gi")},
+	}
+	assert.Equal(t, expected, getNUMANodeHugePages(zones))
+	assert.Nil(t, getNUMANodeHugePages(zones[2:]))
+}
+
+func TestPlugin_NUMANodeHugePages(t *testing.T) {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node-1"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("32"),
+					corev1.ResourceMemory: resource.MustParse("64Gi"),
+					resourceHugePages2Mi:  resource.MustParse("6Gi"),
+				},
+			},
+		},
+	}
+	suit := newPluginTestSuit(t, nodes)
+	p, err := suit.proxyNew(suit.nodeNUMAResourceArgs, suit.Handle)
+	assert.NoError(t, err)
+	plg := p.(*Plugin)
+	plg.topologyManager.UpdateCPUTopologyOptions("test-node-1", func(options *CPUTopologyOptions) {
+		options.CPUTopology = buildCPUTopologyForTest(1, 2, 8, 2)
+		options.NUMANodeHugePages = map[int]corev1.ResourceList{
+			0: newHugePages("2Gi"),
+			1: newHugePages("4Gi"),
+		}
+	})
+	suit.start()
+
+	newPod := func(name, hugePages string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: uuid.NewUUID()},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "main",
+						Resources: corev1.ResourceRequirements{
+							Requests: newHugePages(hugePages),
+							Limits:   newHugePages(hugePages),
+						},
+					},
+				},
+			},
+		}
+		_, err := suit.Handle.ClientSet().CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+		assert.NoError(t, err)
+		return pod
+	}
+	nodeInfo, err := suit.Handle.SnapshotSharedLister().NodeInfos().Get("test-node-1")
+	assert.NoError(t, err)
+	schedule := func(pod *corev1.Pod) (*framework.CycleState, *framework.Status) {
+		cycleState := framework.NewCycleState()
+		assert.True(t, plg.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+		if status := plg.Filter(context.TODO(), cycleState, pod, nodeInfo); !status.IsSuccess() {
+			return cycleState, status
+		}
+		return cycleState, plg.Reserve(context.TODO(), cycleState, pod, "test-node-1")
+	}
+
+	// no single NUMA Node can hold the hugepages, though the node has enough in total
+	pod2 := newPod("pod-2", "5Gi")
+	_, status := schedule(pod2)
+	assert.Equal(t, framework.NewStatus(framework.Unschedulable, ErrInsufficientNUMAHugePages), status)
+
+	// the hugepages are packed into the NUMA Node with less free hugepages
+	pod1 := newPod("pod-1", "2Gi")
+	cycleState, status := schedule(pod1)
+	assert.True(t, status.IsSuccess())
+	assert.True(t, plg.PreBind(context.TODO(), cycleState, pod1, "test-node-1").IsSuccess())
+	got, err := suit.Handle.ClientSet().CoreV1().Pods("default").Get(context.TODO(), "pod-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	resourceStatus, err := extension.GetResourceStatus(got.Annotations)
+	assert.NoError(t, err)
+	expectResourceStatus := &extension.ResourceStatus{
+		NUMANodeResources: []extension.NUMANodeResource{
+			{Node: 0, Resources: newHugePages("2Gi")},
+		},
+	}
+	assert.Equal(t, expectResourceStatus, resourceStatus)
+
+	// the hugepages are freed once unreserved
+	pod3 := newPod("pod-3", "4Gi")
+	cycleState, status = schedule(pod3)
+	assert.True(t, status.IsSuccess())
+	plg.Unreserve(context.TODO(), cycleState, pod3, "test-node-1")
+	_, status = schedule(pod3)
+	assert.True(t, status.IsSuccess())
+}
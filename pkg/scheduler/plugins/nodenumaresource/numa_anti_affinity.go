@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenumaresource
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+func (p *Plugin) getNUMAAntiAffinitySelector(namespace string, antiAffinity *extension.NUMAAntiAffinity) (labels.Selector, error) {
+	if antiAffinity.ServiceName != "" && antiAffinity.LabelSelector != nil {
+		return nil, fmt.Errorf("NUMA anti-affinity can only specify one of serviceName and labelSelector")
+	}
+	if antiAffinity.ServiceName != "" {
+		service, err := p.serviceLister.Services(namespace).Get(antiAffinity.ServiceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get service %s/%s of NUMA anti-affinity, err: %v", namespace, antiAffinity.ServiceName, err)
+		}
+		if len(service.Spec.Selector) == 0 {
+			return nil, fmt.Errorf("service %s/%s of NUMA anti-affinity has no selector", namespace, antiAffinity.ServiceName)
+		}
+		return labels.SelectorFromSet(service.Spec.Selector), nil
+	}
+	if antiAffinity.LabelSelector == nil {
+		return nil, fmt.Errorf("NUMA anti-affinity must specify one of serviceName and labelSelector")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(antiAffinity.LabelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid labelSelector of NUMA anti-affinity, err: %v", err)
+	}
+	return selector, nil
+}
+
+// getNUMAAntiAffinityExcludedCPUs returns the CPUs of the NUMA Nodes used by the pods referenced
+// by the NUMA anti-affinity on the node, and whether any referenced pod is found.
+// The referenced pods without CPUSet allocated do not exclude any NUMA Node.
+func (p *Plugin) getNUMAAntiAffinityExcludedCPUs(pod *corev1.Pod, selector labels.Selector, nodeInfo *framework.NodeInfo, cpuTopology *CPUTopology) (cpuset.CPUSet, bool) {
+	excludedCPUs := cpuset.NewCPUSet()
+	if selector == nil {
+		return excludedCPUs, true
+	}
+	if cpuTopology == nil || !cpuTopology.IsValid() {
+		return excludedCPUs, false
+	}
+
+	found := false
+	excludedNUMANodes := cpuset.NewCPUSet()
+	for _, podInfo := range nodeInfo.Pods {
+		referencedPod := podInfo.Pod
+		if referencedPod.UID == pod.UID || referencedPod.Namespace != pod.Namespace ||
+			!selector.Matches(labels.Set(referencedPod.Labels)) {
+			continue
+		}
+		found = true
+		cpus, ok := p.cpuManager.GetAllocatedCPUSet(nodeInfo.Node().Name, referencedPod.UID)
+		if !ok || cpus.IsEmpty() {
+			continue
+		}
+		excludedNUMANodes = excludedNUMANodes.Union(cpuTopology.CPUDetails.KeepOnly(cpus).NUMANodes())
+	}
+	if !excludedNUMANodes.IsEmpty() {
+		excludedCPUs = cpuTopology.CPUDetails.CPUsInNUMANodes(excludedNUMANodes.ToSliceNoSort()...)
+	}
+	return excludedCPUs, found
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodenumaresource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
+)
+
+func newNUMAAntiAffinityTestPlugin(t *testing.T, pods []*corev1.Pod, objs ...apiruntime.Object) *Plugin {
+	nodes := []*corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-node-1",
+			},
+		},
+	}
+	cs := kubefake.NewSimpleClientset(objs...)
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := schedulertesting.NewFramework(
+		registeredPlugins,
+		"koord-scheduler",
+		runtime.WithClientSet(cs),
+		runtime.WithInformerFactory(informerFactory),
+		runtime.WithSnapshotSharedLister(newTestSharedLister(pods, nodes)),
+	)
+	assert.NoError(t, err)
+
+	topologyManager := NewCPUTopologyManager()
+	topologyManager.UpdateCPUTopologyOptions("test-node-1", func(options *CPUTopologyOptions) {
+		options.CPUTopology = buildCPUTopologyForTest(2, 1, 4, 2)
+		options.MaxRefCount = 1
+	})
+	p := &Plugin{
+		handle:          fh,
+		pluginArgs:      &schedulingconfig.NodeNUMAResourceArgs{DefaultCPUBindPolicy: schedulingconfig.CPUBindPolicyFullPCPUs},
+		topologyManager: topologyManager,
+		cpuManager: &cpuManagerImpl{
+			numaAllocateStrategy: schedulingconfig.NUMAMostAllocated,
+			topologyManager:      topologyManager,
+			allocationStates:     map[string]*cpuAllocation{},
+		},
+		serviceLister: informerFactory.Core().V1().Services().Lister(),
+	}
+	informerFactory.Start(nil)
+	informerFactory.WaitForCacheSync(nil)
+	return p
+}
+
+func newNUMAAntiAffinityTestPod(name string, antiAffinity string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       uuid.NewUUID(),
+			Labels: map[string]string{
+				extension.LabelPodQoS: string(extension.QoSLSR),
+			},
+			Annotations: map[string]string{},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "test-node-1",
+			Priority: pointer.Int32Ptr(extension.PriorityProdValueMax),
+			Containers: []corev1.Container{
+				{
+					Name: "container-1",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("4"),
+						},
+					},
+				},
+			},
+		},
+	}
+	if antiAffinity != "" {
+		pod.Annotations[extension.AnnotationNUMAAntiAffinity] = antiAffinity
+	}
+	return pod
+}
+
+func TestPlugin_getNUMAAntiAffinitySelector(t *testing.T) {
+	services := []apiruntime.Object{
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "producer"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "producer"}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "headless"},
+		},
+	}
+	p := newNUMAAntiAffinityTestPlugin(t, nil, services...)
+
+	tests := []struct {
+		name         string
+		antiAffinity *extension.NUMAAntiAffinity
+		wantSelector labels.Selector
+		wantErr      bool
+	}{
+		{
+			name:         "select by service",
+			antiAffinity: &extension.NUMAAntiAffinity{ServiceName: "producer"},
+			wantSelector: labels.SelectorFromSet(map[string]string{"app": "producer"}),
+		},
+		{
+			name: "select by label selector",
+			antiAffinity: &extension.NUMAAntiAffinity{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "consumer"}},
+			},
+			wantSelector: labels.SelectorFromSet(map[string]string{"app": "consumer"}),
+		},
+		{
+			name:         "service not found",
+			antiAffinity: &extension.NUMAAntiAffinity{ServiceName: "not-found"},
+			wantErr:      true,
+		},
+		{
+			name:         "service without selector",
+			antiAffinity: &extension.NUMAAntiAffinity{ServiceName: "headless"},
+			wantErr:      true,
+		},
+		{
+			name: "both service and label selector",
+			antiAffinity: &extension.NUMAAntiAffinity{
+				ServiceName:   "producer",
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "consumer"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:         "neither service nor label selector",
+			antiAffinity: &extension.NUMAAntiAffinity{},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.getNUMAAntiAffinitySelector("default", tt.antiAffinity)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSelector.String(), got.String())
+		})
+	}
+}
+
+func TestPlugin_NUMAAntiAffinity(t *testing.T) {
+	antiAffinity := `{"labelSelector":{"matchLabels":{"app":"producer"}}}`
+	producer := newNUMAAntiAffinityTestPod("producer", "")
+	producer.Labels["app"] = "producer"
+	other := newNUMAAntiAffinityTestPod("other", "")
+
+	tests := []struct {
+		name          string
+		pods          []*corev1.Pod
+		allocatedCPUs map[*corev1.Pod]cpuset.CPUSet
+		wantFilter    *framework.Status
+		wantCPUSet    cpuset.CPUSet
+	}{
+		{
+			name:       "no referenced pod on node",
+			pods:       []*corev1.Pod{other},
+			wantFilter: framework.NewStatus(framework.Unschedulable, ErrNUMAAntiAffinityPodsNotFound),
+		},
+		{
+			name: "allocate from the NUMA Node not used by the referenced pod",
+			pods: []*corev1.Pod{producer, other},
+			allocatedCPUs: map[*corev1.Pod]cpuset.CPUSet{
+				producer: cpuset.NewCPUSet(0, 1),
+			},
+			wantCPUSet: cpuset.NewCPUSet(8, 9, 10, 11),
+		},
+		{
+			name: "insufficient CPUs on the NUMA Node not used by the referenced pod",
+			pods: []*corev1.Pod{producer, other},
+			allocatedCPUs: map[*corev1.Pod]cpuset.CPUSet{
+				producer: cpuset.NewCPUSet(0, 1),
+				other:    cpuset.NewCPUSet(8, 9, 10, 11, 12, 13),
+			},
+			wantFilter: framework.NewStatus(framework.Unschedulable, ErrNUMAAntiAffinityInsufficientCPUs),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newNUMAAntiAffinityTestPlugin(t, tt.pods)
+			cpuTopology := p.topologyManager.GetCPUTopologyOptions("test-node-1").CPUTopology
+			for pod, cpus := range tt.allocatedCPUs {
+				p.cpuManager.UpdateAllocatedCPUSet("test-node-1", pod.UID, cpus, schedulingconfig.CPUExclusivePolicyNone)
+			}
+			assert.NotNil(t, cpuTopology)
+
+			pod := newNUMAAntiAffinityTestPod("consumer", antiAffinity)
+			pod.Spec.NodeName = ""
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreFilter(context.TODO(), cycleState, pod).IsSuccess())
+
+			nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get("test-node-1")
+			assert.NoError(t, err)
+			gotFilter := p.Filter(context.TODO(), cycleState, pod, nodeInfo)
+			assert.Equal(t, tt.wantFilter, gotFilter)
+			if !gotFilter.IsSuccess() {
+				return
+			}
+
+			assert.True(t, p.Reserve(context.TODO(), cycleState, pod, "test-node-1").IsSuccess())
+			state, status := getPreFilterState(cycleState)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.wantCPUSet.String(), state.allocatedCPUs.String())
+		})
+	}
+}
+
+func TestPlugin_PreFilterNUMAAntiAffinityWithoutCPUSet(t *testing.T) {
+	p := newNUMAAntiAffinityTestPlugin(t, nil)
+	pod := newNUMAAntiAffinityTestPod("consumer", `{"labelSelector":{"matchLabels":{"app":"producer"}}}`)
+	pod.Labels[extension.LabelPodQoS] = string(extension.QoSLS)
+	got := p.PreFilter(context.TODO(), framework.NewCycleState(), pod)
+	assert.Equal(t, framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrNUMAAntiAffinityRequiresCPUSet), got)
+}
@@ -23,7 +23,9 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -51,6 +53,12 @@ const (
 	ErrInvalidCPUTopology      = "node(s) invalid CPU Topology"
 	ErrSMTAlignmentError       = "node(s) requested cpus not multiple cpus per core"
 	ErrRequiredFullPCPUsPolicy = "node(s) required FullPCPUs policy"
+
+	ErrNUMAAntiAffinityRequiresCPUSet   = "NUMA anti-affinity requires the pod to bind CPUs"
+	ErrNUMAAntiAffinityPodsNotFound     = "node(s) didn't have the pods referenced by NUMA anti-affinity"
+	ErrNUMAAntiAffinityInsufficientCPUs = "node(s) didn't have enough CPUs on the NUMA Nodes not used by the referenced pods"
+
+	ErrInsufficientNUMAHugePages = "node(s) didn't have enough hugepages on any NUMA Node"
 )
 
 var (
@@ -82,6 +90,8 @@ type Plugin struct {
 	pluginArgs      *schedulingconfig.NodeNUMAResourceArgs
 	topologyManager CPUTopologyManager
 	cpuManager      CPUManager
+	hugePageManager *hugePageManager
+	serviceLister   corelisters.ServiceLister
 }
 
 type Option func(*pluginOptions)
@@ -135,13 +145,16 @@ func NewWithOptions(args runtime.Object, handle framework.Handle, opts ...Option
 			return nil, err
 		}
 	}
-	registerPodEventHandler(handle, options.cpuManager)
+	hugePageManager := newHugePageManager(handle)
+	registerPodEventHandler(handle, options.cpuManager, hugePageManager)
 
 	return &Plugin{
 		handle:          handle,
 		pluginArgs:      pluginArgs,
 		topologyManager: options.topologyManager,
 		cpuManager:      options.cpuManager,
+		hugePageManager: hugePageManager,
+		serviceLister:   handle.SharedInformerFactory().Core().V1().Services().Lister(),
 	}, nil
 }
 
@@ -174,13 +187,21 @@ type preFilterState struct {
 	preferredCPUExclusivePolicy schedulingconfig.CPUExclusivePolicy
 	numCPUsNeeded               int
 	allocatedCPUs               cpuset.CPUSet
+	// numaAntiAffinitySelector selects the pods referenced by the NUMA anti-affinity of the pod
+	numaAntiAffinitySelector labels.Selector
+	// hugePagesNeeded are the hugepages requested by the pod, which are placed on a single NUMA Node
+	hugePagesNeeded    corev1.ResourceList
+	allocatedHugePages *extension.NUMANodeResource
 }
 
 func (s *preFilterState) Clone() framework.StateData {
 	return &preFilterState{
-		skip:          s.skip,
-		resourceSpec:  s.resourceSpec,
-		allocatedCPUs: s.allocatedCPUs.Clone(),
+		skip:                     s.skip,
+		resourceSpec:             s.resourceSpec,
+		allocatedCPUs:            s.allocatedCPUs.Clone(),
+		numaAntiAffinitySelector: s.numaAntiAffinitySelector,
+		hugePagesNeeded:          s.hugePagesNeeded,
+		allocatedHugePages:       s.allocatedHugePages,
 	}
 }
 
@@ -216,6 +237,22 @@ func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState
 		}
 	}
 
+	numaAntiAffinity, err := extension.GetNUMAAntiAffinity(pod.Annotations)
+	if err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	if numaAntiAffinity != nil {
+		if state.skip {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrNUMAAntiAffinityRequiresCPUSet)
+		}
+		selector, err := p.getNUMAAntiAffinitySelector(pod.Namespace, numaAntiAffinity)
+		if err != nil {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+		}
+		state.numaAntiAffinitySelector = selector
+	}
+	state.hugePagesNeeded = getPodHugePageRequests(pod)
+
 	cycleState.Write(stateKey, state)
 	return nil
 }
@@ -238,7 +275,7 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	if !status.IsSuccess() {
 		return status
 	}
-	if state.skip {
+	if state.skip && len(state.hugePagesNeeded) == 0 {
 		return nil
 	}
 
@@ -248,6 +285,16 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	}
 
 	cpuTopologyOptions := p.topologyManager.GetCPUTopologyOptions(node.Name)
+	if len(state.hugePagesNeeded) > 0 && len(cpuTopologyOptions.NUMANodeHugePages) > 0 {
+		if _, err := p.hugePageManager.allocate(node.Name, cpuTopologyOptions.NUMANodeHugePages, state.hugePagesNeeded,
+			nil, GetDefaultNUMAAllocateStrategy(p.pluginArgs)); err != nil {
+			return framework.NewStatus(framework.Unschedulable, ErrInsufficientNUMAHugePages)
+		}
+	}
+	if state.skip {
+		return nil
+	}
+
 	if cpuTopologyOptions.CPUTopology == nil {
 		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrNotFoundCPUTopology)
 	}
@@ -269,6 +316,20 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 		}
 	}
 
+	if state.numaAntiAffinitySelector != nil {
+		excludedCPUs, found := p.getNUMAAntiAffinityExcludedCPUs(pod, state.numaAntiAffinitySelector, nodeInfo, cpuTopologyOptions.CPUTopology)
+		if !found {
+			return framework.NewStatus(framework.Unschedulable, ErrNUMAAntiAffinityPodsNotFound)
+		}
+		preferredCPUBindPolicy, err := p.getPreferredCPUBindPolicy(node, state.preferredCPUBindPolicy)
+		if err != nil {
+			return framework.AsStatus(err)
+		}
+		if _, err = p.cpuManager.Allocate(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy, excludedCPUs); err != nil {
+			return framework.NewStatus(framework.Unschedulable, ErrNUMAAntiAffinityInsufficientCPUs)
+		}
+	}
+
 	return nil
 }
 
@@ -295,7 +356,8 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 		return 0, nil
 	}
 
-	score := p.cpuManager.Score(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy)
+	excludedCPUs, _ := p.getNUMAAntiAffinityExcludedCPUs(pod, state.numaAntiAffinitySelector, nodeInfo, p.topologyManager.GetCPUTopologyOptions(nodeName).CPUTopology)
+	score := p.cpuManager.Score(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy, excludedCPUs)
 	return score, nil
 }
 
@@ -309,7 +371,7 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		return status
 	}
 	if state.skip {
-		return nil
+		return p.reserveNUMANodeHugePages(state, pod, nodeName)
 	}
 
 	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
@@ -325,13 +387,56 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 	if err != nil {
 		return framework.AsStatus(err)
 	}
-	result, err := p.cpuManager.Allocate(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy)
+	excludedCPUs, _ := p.getNUMAAntiAffinityExcludedCPUs(pod, state.numaAntiAffinitySelector, nodeInfo, p.topologyManager.GetCPUTopologyOptions(nodeName).CPUTopology)
+	result, err := p.cpuManager.Allocate(node, state.numCPUsNeeded, preferredCPUBindPolicy, state.preferredCPUExclusivePolicy, excludedCPUs)
 	if err != nil {
 		return framework.AsStatus(err)
 	}
 	p.cpuManager.UpdateAllocatedCPUSet(nodeName, pod.UID, result, state.preferredCPUExclusivePolicy)
 	state.allocatedCPUs = result
 	state.preferredCPUBindPolicy = preferredCPUBindPolicy
+	if status := p.reserveNUMANodeHugePages(state, pod, nodeName); !status.IsSuccess() {
+		p.cpuManager.Free(nodeName, pod.UID)
+		state.allocatedCPUs = cpuset.CPUSet{}
+		return status
+	}
+	return nil
+}
+
+// reserveNUMANodeHugePages places the requested hugepages on a NUMA Node of the node, preferring the NUMA Nodes of
+// the allocated CPUs. The nodes not reporting the hugepages of the NUMA Nodes are skipped.
+func (p *Plugin) reserveNUMANodeHugePages(state *preFilterState, pod *corev1.Pod, nodeName string) *framework.Status {
+	if len(state.hugePagesNeeded) == 0 {
+		return nil
+	}
+	cpuTopologyOptions := p.topologyManager.GetCPUTopologyOptions(nodeName)
+	if len(cpuTopologyOptions.NUMANodeHugePages) == 0 {
+		return nil
+	}
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+
+	var preferredNUMANodes []int
+	if !state.allocatedCPUs.IsEmpty() && cpuTopologyOptions.CPUTopology != nil {
+		preferredNUMANodes = cpuTopologyOptions.CPUTopology.CPUDetails.KeepOnly(state.allocatedCPUs).NUMANodes().ToSlice()
+	}
+	numaAllocateStrategy := getNUMAAllocateStrategy(node, GetDefaultNUMAAllocateStrategy(p.pluginArgs))
+	numaNode, err := p.hugePageManager.allocate(nodeName, cpuTopologyOptions.NUMANodeHugePages, state.hugePagesNeeded,
+		preferredNUMANodes, numaAllocateStrategy)
+	if err != nil {
+		return framework.NewStatus(framework.Unschedulable, err.Error())
+	}
+	p.hugePageManager.update(nodeName, pod.UID, numaNode, state.hugePagesNeeded)
+	state.allocatedHugePages = &extension.NUMANodeResource{
+		Node:      int32(numaNode),
+		Resources: state.hugePagesNeeded,
+	}
 	return nil
 }
 
@@ -340,6 +445,9 @@ func (p *Plugin) Unreserve(ctx context.Context, cycleState *framework.CycleState
 	if !status.IsSuccess() {
 		return
 	}
+	if state.allocatedHugePages != nil {
+		p.hugePageManager.free(nodeName, pod.UID)
+	}
 	if state.skip || state.allocatedCPUs.IsEmpty() {
 		return
 	}
@@ -351,11 +459,7 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	if !status.IsSuccess() {
 		return status
 	}
-	if state.skip {
-		return nil
-	}
-
-	if state.allocatedCPUs.IsEmpty() {
+	if state.allocatedCPUs.IsEmpty() && state.allocatedHugePages == nil {
 		return nil
 	}
 
@@ -363,9 +467,9 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	pod = pod.DeepCopy()
 
 	// Write back ResourceSpec annotation if LSR Pod hasn't specified CPUBindPolicy
-	if state.resourceSpec.PreferredCPUBindPolicy == "" ||
+	if !state.allocatedCPUs.IsEmpty() && (state.resourceSpec.PreferredCPUBindPolicy == "" ||
 		state.resourceSpec.PreferredCPUBindPolicy == schedulingconfig.CPUBindPolicyDefault ||
-		state.resourceSpec.PreferredCPUBindPolicy != state.preferredCPUBindPolicy {
+		state.resourceSpec.PreferredCPUBindPolicy != state.preferredCPUBindPolicy) {
 		resourceSpec := &extension.ResourceSpec{
 			PreferredCPUBindPolicy: state.preferredCPUBindPolicy,
 		}
@@ -379,7 +483,13 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 		pod.Annotations[extension.AnnotationResourceSpec] = string(resourceSpecData)
 	}
 
-	resourceStatus := &extension.ResourceStatus{CPUSet: state.allocatedCPUs.String()}
+	resourceStatus := &extension.ResourceStatus{}
+	if !state.allocatedCPUs.IsEmpty() {
+		resourceStatus.CPUSet = state.allocatedCPUs.String()
+	}
+	if state.allocatedHugePages != nil {
+		resourceStatus.NUMANodeResources = []extension.NUMANodeResource{*state.allocatedHugePages}
+	}
 	err := SetResourceStatus(pod, resourceStatus)
 	if err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
@@ -392,11 +502,12 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 	})
 	if err != nil {
 		klog.V(3).ErrorS(err, "Failed to preBind Pod with CPUSet",
-			"pod", klog.KObj(pod), "CPUSet", state.allocatedCPUs, "node", nodeName)
+			"pod", klog.KObj(pod), "CPUSet", state.allocatedCPUs, "NUMANodeResources", resourceStatus.NUMANodeResources, "node", nodeName)
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	klog.V(4).Infof("Successfully preBind Pod %s/%s with CPUSet %s", pod.Namespace, pod.Name, state.allocatedCPUs)
+	klog.V(4).Infof("Successfully preBind Pod %s/%s with CPUSet %s, NUMANodeResources %v",
+		pod.Namespace, pod.Name, state.allocatedCPUs, resourceStatus.NUMANodeResources)
 	return nil
 }
 
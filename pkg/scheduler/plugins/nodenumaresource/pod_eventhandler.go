@@ -21,21 +21,25 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 	"github.com/koordinator-sh/koordinator/pkg/util/cpuset"
 )
 
 type podEventHandler struct {
-	cpuManager CPUManager
+	cpuManager      CPUManager
+	hugePageManager *hugePageManager
 }
 
-func registerPodEventHandler(handle framework.Handle, cpuManager CPUManager) {
+func registerPodEventHandler(handle framework.Handle, cpuManager CPUManager, hugePageManager *hugePageManager) {
 	podInformer := handle.SharedInformerFactory().Core().V1().Pods().Informer()
 	eventHandler := &podEventHandler{
-		cpuManager: cpuManager,
+		cpuManager:      cpuManager,
+		hugePageManager: hugePageManager,
 	}
 	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), handle.SharedInformerFactory(), podInformer, eventHandler)
 }
@@ -95,6 +99,7 @@ func (c *podEventHandler) updatePod(oldPod, pod *corev1.Pod) {
 	if err != nil {
 		return
 	}
+	c.updateNUMANodeHugePages(pod, resourceStatus)
 	cpus, err := cpuset.Parse(resourceStatus.CPUSet)
 	if err != nil || cpus.IsEmpty() {
 		return
@@ -113,6 +118,10 @@ func (c *podEventHandler) deletePod(pod *corev1.Pod) {
 		return
 	}
 
+	if c.hugePageManager != nil {
+		c.hugePageManager.free(pod.Spec.NodeName, pod.UID)
+	}
+
 	resourceStatus, err := GetResourceStatus(pod.Annotations)
 	if err != nil {
 		return
@@ -124,3 +133,22 @@ func (c *podEventHandler) deletePod(pod *corev1.Pod) {
 
 	c.cpuManager.Free(pod.Spec.NodeName, pod.UID)
 }
+
+// updateNUMANodeHugePages restores the hugepages allocated to the pod on the NUMA Node from its ResourceStatus.
+func (c *podEventHandler) updateNUMANodeHugePages(pod *corev1.Pod, resourceStatus *extension.ResourceStatus) {
+	if c.hugePageManager == nil {
+		return
+	}
+	for _, numaNodeResource := range resourceStatus.NUMANodeResources {
+		hugePages := corev1.ResourceList{}
+		for resourceName, quantity := range numaNodeResource.Resources {
+			if v1helper.IsHugePageResourceName(resourceName) {
+				hugePages[resourceName] = quantity.DeepCopy()
+			}
+		}
+		if len(hugePages) > 0 {
+			c.hugePageManager.update(pod.Spec.NodeName, pod.UID, int(numaNodeResource.Node), hugePages)
+			return
+		}
+	}
+}
@@ -18,13 +18,17 @@ package nodenumaresource
 
 import (
 	"context"
+	"strconv"
+	"strings"
 
 	nrtv1alpha1 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha1"
 	nrtclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned"
 	nrtinformers "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
@@ -126,14 +130,16 @@ func (m *nodeResourceTopologyEventHandler) updateNodeResourceTopology(oldNodeRes
 	cpuTopology := convertCPUTopology(reportedCPUTopology)
 	reservedCPUs := m.getPodAllocsCPUSet(podCPUAllocs)
 	reservedCPUs = reservedCPUs.Union(kubeletReservedCPUs)
+	numaNodeHugePages := getNUMANodeHugePages(newNodeResTopology.Zones)
 
 	nodeName := newNodeResTopology.Name
 	m.topologyManager.UpdateCPUTopologyOptions(nodeName, func(options *CPUTopologyOptions) {
 		*options = CPUTopologyOptions{
-			CPUTopology:  cpuTopology,
-			ReservedCPUs: reservedCPUs,
-			Policy:       kubeletPolicy,
-			MaxRefCount:  options.MaxRefCount,
+			CPUTopology:       cpuTopology,
+			ReservedCPUs:      reservedCPUs,
+			Policy:            kubeletPolicy,
+			MaxRefCount:       options.MaxRefCount,
+			NUMANodeHugePages: numaNodeHugePages,
 		}
 	})
 }
@@ -163,3 +169,32 @@ func convertCPUTopology(reportedCPUTopology *extension.CPUTopology) *CPUTopology
 	}
 	return builder.Result()
 }
+
+// getNUMANodeHugePages returns the allocatable hugepages of the NUMA Nodes reported as the zones of type Node, whose
+// names are formatted as node-<id>.
+func getNUMANodeHugePages(zones nrtv1alpha1.ZoneList) map[int]corev1.ResourceList {
+	var numaNodeHugePages map[int]corev1.ResourceList
+	for _, zone := range zones {
+		if zone.Type != "Node" || !strings.HasPrefix(zone.Name, "node-") {
+			continue
+		}
+		numaNodeID, err := strconv.Atoi(strings.TrimPrefix(zone.Name, "node-"))
+		if err != nil || numaNodeID < 0 {
+			continue
+		}
+		for _, info := range zone.Resources {
+			resourceName := corev1.ResourceName(info.Name)
+			if !v1helper.IsHugePageResourceName(resourceName) {
+				continue
+			}
+			if numaNodeHugePages == nil {
+				numaNodeHugePages = map[int]corev1.ResourceList{}
+			}
+			if numaNodeHugePages[numaNodeID] == nil {
+				numaNodeHugePages[numaNodeID] = corev1.ResourceList{}
+			}
+			numaNodeHugePages[numaNodeID][resourceName] = info.Allocatable.DeepCopy()
+		}
+	}
+	return numaNodeHugePages
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preferrednodes
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+var (
+	timeNowFn = time.Now
+)
+
+// hintCache remembers the preferred nodes hints of the pods evicted by the descheduler by their controllers,
+// so that the replacement pods created by the controllers are scheduled to the preferred nodes.
+type hintCache struct {
+	lock  sync.RWMutex
+	hints map[types.UID]*apiext.PreferredNodes
+}
+
+func newHintCache() *hintCache {
+	return &hintCache{
+		hints: map[types.UID]*apiext.PreferredNodes{},
+	}
+}
+
+func (c *hintCache) onPodDelete(obj interface{}) {
+	var pod *corev1.Pod
+	switch t := obj.(type) {
+	case *corev1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		pod, ok = t.Obj.(*corev1.Pod)
+		if !ok {
+			return
+		}
+	default:
+		return
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return
+	}
+	hint, err := apiext.GetPreferredNodes(pod.Annotations)
+	if err != nil {
+		klog.V(4).ErrorS(err, "failed to parse preferred nodes", "pod", klog.KObj(pod))
+		return
+	}
+	// the hint without expiration is only honored on the pod itself
+	if hint == nil || len(hint.Nodes) == 0 || hint.ExpireTime == nil {
+		return
+	}
+	c.add(owner.UID, hint)
+	klog.V(5).InfoS("preferred nodes hint recorded", "pod", klog.KObj(pod), "controller", owner.UID, "nodes", hint.Nodes)
+}
+
+func (c *hintCache) add(controllerUID types.UID, hint *apiext.PreferredNodes) {
+	now := timeNowFn()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if hint.IsExpired(now) {
+		return
+	}
+	c.hints[controllerUID] = hint
+	for uid, h := range c.hints {
+		if h.IsExpired(now) {
+			delete(c.hints, uid)
+		}
+	}
+}
+
+// get returns the unexpired hint of the controller.
+func (c *hintCache) get(controllerUID types.UID) *apiext.PreferredNodes {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	hint := c.hints[controllerUID]
+	if hint == nil || hint.IsExpired(timeNowFn()) {
+		return nil
+	}
+	return hint
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preferrednodes
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+)
+
+const (
+	Name     = "PreferredNodes"
+	stateKey = Name
+)
+
+var (
+	_ framework.PreScorePlugin = &Plugin{}
+	_ framework.ScorePlugin    = &Plugin{}
+)
+
+// Plugin prefers the nodes evaluated by the descheduler as the targets of the rebalanced pods,
+// which reduces the latency of rescheduling the evicted pods.
+type Plugin struct {
+	hints *hintCache
+}
+
+func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	hints := newHintCache()
+	sharedInformerFactory := handle.SharedInformerFactory()
+	podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		DeleteFunc: hints.onPodDelete,
+	}
+	frameworkexthelper.ForceSyncFromInformer(context.TODO().Done(), sharedInformerFactory, podInformer, eventHandler)
+	return &Plugin{hints: hints}, nil
+}
+
+func (p *Plugin) Name() string {
+	return Name
+}
+
+type preScoreState struct {
+	preferredNodes sets.String
+}
+
+func (s *preScoreState) Clone() framework.StateData {
+	return s
+}
+
+func (p *Plugin) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodes []*corev1.Node) *framework.Status {
+	state := &preScoreState{}
+	if hint := p.getPreferredNodes(pod); hint != nil {
+		state.preferredNodes = sets.NewString(hint.Nodes...)
+	}
+	cycleState.Write(stateKey, state)
+	return nil
+}
+
+// getPreferredNodes returns the unexpired hint of the pod itself, e.g. the reserve pod of a migration,
+// or the hint left by the evicted pods of its controller.
+func (p *Plugin) getPreferredNodes(pod *corev1.Pod) *apiext.PreferredNodes {
+	hint, err := apiext.GetPreferredNodes(pod.Annotations)
+	if err != nil {
+		klog.V(4).ErrorS(err, "failed to parse preferred nodes", "pod", klog.KObj(pod))
+	} else if hint != nil && !hint.IsExpired(timeNowFn()) {
+		return hint
+	}
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return p.hints.get(owner.UID)
+	}
+	return nil
+}
+
+func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, pod *corev1.Pod, nodeName string) (int64, *framework.Status) {
+	value, err := cycleState.Read(stateKey)
+	if err != nil {
+		return 0, nil
+	}
+	state := value.(*preScoreState)
+	if state.preferredNodes.Has(nodeName) {
+		return framework.MaxNodeScore, nil
+	}
+	return 0, nil
+}
+
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preferrednodes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestNew(t *testing.T) {
+	cs := kubefake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	registeredPlugins := []schedulertesting.RegisterPluginFunc{
+		schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithInformerFactory(informerFactory),
+	)
+	assert.NoError(t, err)
+
+	p, err := New(nil, fh)
+	assert.NoError(t, err)
+	assert.Equal(t, Name, p.Name())
+	assert.Nil(t, p.(*Plugin).ScoreExtensions())
+}
+
+func newTestPod(name string, controllerUID string, hint *apiext.PreferredNodes) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+		},
+	}
+	if controllerUID != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       "test-rs",
+				UID:        types.UID(controllerUID),
+				Controller: pointer.Bool(true),
+			},
+		}
+	}
+	if hint != nil {
+		_ = apiext.SetPreferredNodes(pod, hint)
+	}
+	return pod
+}
+
+func TestPlugin_Score(t *testing.T) {
+	now := time.Now()
+	timeNowFn = func() time.Time {
+		return now
+	}
+	defer func() {
+		timeNowFn = time.Now
+	}()
+	expireTime := metav1.NewTime(now.Add(time.Minute))
+	expiredTime := metav1.NewTime(now.Add(-time.Minute))
+
+	tests := []struct {
+		name       string
+		evictedPod *corev1.Pod
+		pod        *corev1.Pod
+		wantScores map[string]int64
+	}{
+		{
+			name:       "no hint",
+			pod:        newTestPod("test-pod", "test-rs-uid", nil),
+			wantScores: map[string]int64{"node-1": 0, "node-2": 0},
+		},
+		{
+			name:       "hint of the pod itself",
+			pod:        newTestPod("test-pod", "", &apiext.PreferredNodes{Nodes: []string{"node-2"}}),
+			wantScores: map[string]int64{"node-1": 0, "node-2": framework.MaxNodeScore},
+		},
+		{
+			name:       "expired hint of the pod itself",
+			pod:        newTestPod("test-pod", "", &apiext.PreferredNodes{Nodes: []string{"node-2"}, ExpireTime: &expiredTime}),
+			wantScores: map[string]int64{"node-1": 0, "node-2": 0},
+		},
+		{
+			name:       "hint of the evicted pod of the same controller",
+			evictedPod: newTestPod("evicted-pod", "test-rs-uid", &apiext.PreferredNodes{Nodes: []string{"node-1"}, ExpireTime: &expireTime}),
+			pod:        newTestPod("test-pod", "test-rs-uid", nil),
+			wantScores: map[string]int64{"node-1": framework.MaxNodeScore, "node-2": 0},
+		},
+		{
+			name:       "hint of the evicted pod of another controller",
+			evictedPod: newTestPod("evicted-pod", "test-rs-uid", &apiext.PreferredNodes{Nodes: []string{"node-1"}, ExpireTime: &expireTime}),
+			pod:        newTestPod("test-pod", "other-rs-uid", nil),
+			wantScores: map[string]int64{"node-1": 0, "node-2": 0},
+		},
+		{
+			name:       "expired hint of the evicted pod",
+			evictedPod: newTestPod("evicted-pod", "test-rs-uid", &apiext.PreferredNodes{Nodes: []string{"node-1"}, ExpireTime: &expiredTime}),
+			pod:        newTestPod("test-pod", "test-rs-uid", nil),
+			wantScores: map[string]int64{"node-1": 0, "node-2": 0},
+		},
+		{
+			name:       "hint without expiration of the evicted pod",
+			evictedPod: newTestPod("evicted-pod", "test-rs-uid", &apiext.PreferredNodes{Nodes: []string{"node-1"}}),
+			pod:        newTestPod("test-pod", "test-rs-uid", nil),
+			wantScores: map[string]int64{"node-1": 0, "node-2": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{hints: newHintCache()}
+			if tt.evictedPod != nil {
+				p.hints.onPodDelete(cache.DeletedFinalStateUnknown{Obj: tt.evictedPod})
+			}
+			cycleState := framework.NewCycleState()
+			assert.True(t, p.PreScore(context.TODO(), cycleState, tt.pod, nil).IsSuccess())
+			for nodeName, want := range tt.wantScores {
+				score, status := p.Score(context.TODO(), cycleState, tt.pod, nodeName)
+				assert.True(t, status.IsSuccess())
+				assert.Equal(t, want, score, nodeName)
+			}
+		})
+	}
+}
+
+func TestHintCache(t *testing.T) {
+	now := time.Now()
+	timeNowFn = func() time.Time {
+		return now
+	}
+	defer func() {
+		timeNowFn = time.Now
+	}()
+	expireTime := metav1.NewTime(now.Add(time.Minute))
+
+	c := newHintCache()
+	c.onPodDelete(newTestPod("evicted-pod", "test-rs-uid", &apiext.PreferredNodes{Nodes: []string{"node-1"}, ExpireTime: &expireTime}))
+	assert.Equal(t, []string{"node-1"}, c.get("test-rs-uid").Nodes)
+
+	// the expired hints are removed when a new one is added
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, c.get("test-rs-uid"))
+	newExpireTime := metav1.NewTime(now.Add(time.Minute))
+	c.onPodDelete(newTestPod("evicted-pod", "other-rs-uid", &apiext.PreferredNodes{Nodes: []string{"node-2"}, ExpireTime: &newExpireTime}))
+	assert.Len(t, c.hints, 1)
+	assert.Equal(t, []string{"node-2"}, c.get("other-rs-uid").Nodes)
+}
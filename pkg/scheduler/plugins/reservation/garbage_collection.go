@@ -21,9 +21,11 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/klog/v2"
 	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
@@ -37,20 +39,31 @@ import (
 const (
 	defaultGCCheckInterval = 60 * time.Second
 	defaultGCDuration      = 24 * time.Hour
+	// defaultRestockGracePeriod is how long the unallocated remainder of a reservation keeps unchanged before it
+	// is restocked, so that the resources just released by the owners are not returned to the node at once.
+	defaultRestockGracePeriod = 5 * time.Minute
 )
 
+// restockCandidate is the unallocated remainder of a reservation observed by the GC and the time it was first seen.
+type restockCandidate struct {
+	remainder corev1.ResourceList
+	since     time.Time
+}
+
 func (p *Plugin) gcReservations() {
 	rList, err := p.rLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to list reservations, abort the GC turn, err: %s", err)
 		return
 	}
+	recordReservationMetrics(rList)
+	p.recordReservedCapacityMetrics(rList)
+	p.pruneRestockCandidates(rList)
 	for _, r := range rList {
 		// expire reservations
 		// the reserve pods of expired reservations would be dequeue or removed from cache by the scheduler handler.
 		if isReservationNeedExpiration(r) {
-			// marked as expired in cache even if the reservation is failed to set expired
-			if err = p.expireReservation(r); err != nil {
+			if err = p.expireReservationIfNotRenewed(r); err != nil {
 				klog.Warningf("failed to update reservation %s as expired, err: %s", klog.KObj(r), err)
 			}
 		} else if reservationutil.IsReservationActive(r) {
@@ -106,7 +119,8 @@ func (p *Plugin) expireReservation(r *schedulingv1alpha1.Reservation) error {
 	// marked as expired in cache even if the reservation is failed to set expired
 	p.reservationCache.AddToInactive(r)
 	// update reservation status
-	return util.RetryOnConflictOrTooManyRequests(func() error {
+	expired := false
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
 		curR, err := p.rLister.Get(r.Name)
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -120,15 +134,59 @@ func (p *Plugin) expireReservation(r *schedulingv1alpha1.Reservation) error {
 		}
 
 		curR = curR.DeepCopy()
-		setReservationExpired(curR)
+		reservationutil.SetReservationExpired(curR)
 		_, err = p.client.Reservations().UpdateStatus(context.TODO(), curR, metav1.UpdateOptions{})
+		expired = err == nil
 		return err
 	})
+	if expired {
+		p.recordReservationExpired(r, expiredReasonNodeDeleted)
+	}
+	return err
+}
+
+// expireReservationIfNotRenewed expires the reservation only if the latest version from the API server still needs
+// expiration. The status update is rejected with a conflict if the reservation is renewed in the meantime, and then
+// the renewal is checked again on retry, so the renewals never race with the expiration.
+func (p *Plugin) expireReservationIfNotRenewed(r *schedulingv1alpha1.Reservation) error {
+	expired := false
+	err := util.RetryOnConflictOrTooManyRequests(func() error {
+		curR, err := p.client.Reservations().Get(context.TODO(), r.Name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				klog.V(4).InfoS("reservation not found, abort the update",
+					"reservation", klog.KObj(r))
+				return nil
+			}
+			klog.V(3).InfoS("failed to get reservation",
+				"reservation", klog.KObj(r), "err", err)
+			return err
+		}
+		if !isReservationNeedExpiration(curR) {
+			klog.V(4).InfoS("skip expiring the renewed reservation", "reservation", klog.KObj(r))
+			return nil
+		}
+
+		curR = curR.DeepCopy()
+		reservationutil.SetReservationExpired(curR)
+		_, err = p.client.Reservations().UpdateStatus(context.TODO(), curR, metav1.UpdateOptions{})
+		expired = err == nil
+		return err
+	})
+	if expired {
+		p.recordReservationExpired(r, expiredReasonTimeout)
+	}
+	// marked as expired in cache even if the reservation is failed to set expired
+	if expired || err != nil {
+		p.reservationCache.AddToInactive(r)
+	}
+	return err
 }
 
 func (p *Plugin) syncActiveReservation(r *schedulingv1alpha1.Reservation) {
 	var actualOwners, missedOwners []corev1.ObjectReference
 	var actualAllocated corev1.ResourceList
+	var actualPods []*corev1.Pod
 	for _, owner := range r.Status.CurrentOwners {
 		pod, err := p.podLister.Pods(owner.Namespace).Get(owner.Name)
 		if err != nil {
@@ -143,20 +201,34 @@ func (p *Plugin) syncActiveReservation(r *schedulingv1alpha1.Reservation) {
 			continue
 		}
 		actualOwners = append(actualOwners, owner)
+		actualPods = append(actualPods, pod)
 		req, _ := resourceapi.PodRequestsAndLimits(pod)
 		actualAllocated = quotav1.Add(actualAllocated, req)
 	}
 
-	// if current owner status is correct
-	if len(missedOwners) <= 0 && quotav1.Equals(actualAllocated, r.Status.Allocated) {
+	newR := r.DeepCopy()
+	needUpdate := false
+	// fix the incorrect owner status
+	if len(missedOwners) > 0 || !quotav1.Equals(actualAllocated, r.Status.Allocated) {
+		actualAllocated = quotav1.Mask(actualAllocated, quotav1.ResourceNames(r.Status.Allocatable))
+		newR.Status.Allocated = actualAllocated
+		newR.Status.CurrentOwners = actualOwners
+		needUpdate = true
+	}
+	// fix the allocated pods missing or left by the owners
+	if syncReservationAllocatedPods(newR, actualPods) {
+		needUpdate = true
+	}
+	// return the unallocated remainder to the node if the reservation needs restocking
+	if p.isRestockDue(newR, time.Now()) && restockReservation(newR) {
+		klog.V(4).InfoS("restock the unallocated remainder of reservation", "reservation", klog.KObj(r),
+			"allocated", newR.Status.Allocated, "restocked", newR.Status.Restocked)
+		needUpdate = true
+	}
+	if !needUpdate {
 		return
 	}
 
-	// fix the incorrect owner status
-	newR := r.DeepCopy()
-	actualAllocated = quotav1.Mask(actualAllocated, quotav1.ResourceNames(r.Status.Allocatable))
-	newR.Status.Allocated = actualAllocated
-	newR.Status.CurrentOwners = actualOwners
 	// if failed to update, abort and let the next event reconcile
 	_, err := p.client.Reservations().UpdateStatus(context.TODO(), newR, metav1.UpdateOptions{})
 	if err != nil {
@@ -166,6 +238,38 @@ func (p *Plugin) syncActiveReservation(r *schedulingv1alpha1.Reservation) {
 	klog.V(5).InfoS("update active reservation for status correction", "reservation", klog.KObj(r))
 }
 
+// syncReservationAllocatedPods makes the allocated pods of the reservation consistent with the actual owner pods.
+// It returns true if the allocated pods are changed.
+func syncReservationAllocatedPods(r *schedulingv1alpha1.Reservation, pods []*corev1.Pod) bool {
+	allocatedPods := make([]schedulingv1alpha1.ReservationAllocatedPod, 0, len(pods))
+	for _, pod := range pods {
+		allocatedPod := schedulingv1alpha1.ReservationAllocatedPod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+		}
+		for i := range r.Status.AllocatedPods {
+			if matchAllocatedPod(pod, &r.Status.AllocatedPods[i]) {
+				allocatedPod.Resources = r.Status.AllocatedPods[i].Resources
+				break
+			}
+		}
+		if allocatedPod.Resources == nil {
+			requests, _ := resourceapi.PodRequestsAndLimits(pod)
+			allocatedPod.Resources = quotav1.Mask(requests, quotav1.ResourceNames(r.Status.Allocatable))
+		}
+		allocatedPods = append(allocatedPods, allocatedPod)
+	}
+	if len(allocatedPods) <= 0 {
+		allocatedPods = nil
+	}
+	if apiequality.Semantic.DeepEqual(allocatedPods, r.Status.AllocatedPods) {
+		return false
+	}
+	r.Status.AllocatedPods = allocatedPods
+	return true
+}
+
 func (p *Plugin) syncPodDeleted(pod *corev1.Pod) {
 	rInfo := p.reservationCache.GetOwned(pod)
 	// Most pods have no reservation allocated.
@@ -214,3 +318,42 @@ func (p *Plugin) syncPodDeleted(pod *corev1.Pod) {
 		klog.V(5).InfoS("sync pod deletion for reservation successfully", "pod", klog.KObj(pod))
 	}
 }
+
+// isRestockDue checks if the unallocated remainder of the reservation has been kept unchanged for the grace period.
+// The grace period restarts whenever the remainder changes, e.g. an owner releases its resources.
+func (p *Plugin) isRestockDue(r *schedulingv1alpha1.Reservation, now time.Time) bool {
+	remainder := getRestockRemainder(r)
+	if len(remainder) <= 0 {
+		delete(p.restockCandidates, r.UID)
+		return false
+	}
+	candidate, ok := p.restockCandidates[r.UID]
+	if !ok || !quotav1.Equals(candidate.remainder, remainder) {
+		if p.restockCandidates == nil {
+			p.restockCandidates = map[types.UID]*restockCandidate{}
+		}
+		p.restockCandidates[r.UID] = &restockCandidate{remainder: remainder, since: now}
+		return false
+	}
+	if now.Sub(candidate.since) < defaultRestockGracePeriod {
+		return false
+	}
+	delete(p.restockCandidates, r.UID)
+	return true
+}
+
+// pruneRestockCandidates drops the restock candidates of the reservations not existing anymore.
+func (p *Plugin) pruneRestockCandidates(rList []*schedulingv1alpha1.Reservation) {
+	if len(p.restockCandidates) <= 0 {
+		return
+	}
+	existing := make(map[types.UID]struct{}, len(rList))
+	for _, r := range rList {
+		existing[r.UID] = struct{}{}
+	}
+	for uid := range p.restockCandidates {
+		if _, ok := existing[uid]; !ok {
+			delete(p.restockCandidates, uid)
+		}
+	}
+}
@@ -622,6 +622,81 @@ func Test_syncActiveReservation(t *testing.T) {
 	}
 }
 
+func Test_syncReservationAllocatedPods(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-pod-1",
+			UID:       "1234",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	allocatedPod := schedulingv1alpha1.ReservationAllocatedPod{
+		Namespace: "default",
+		Name:      "test-pod-1",
+		UID:       "1234",
+		Resources: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("2"),
+		},
+	}
+	tests := []struct {
+		name              string
+		allocatedPods     []schedulingv1alpha1.ReservationAllocatedPod
+		pods              []*corev1.Pod
+		want              bool
+		wantAllocatedPods []schedulingv1alpha1.ReservationAllocatedPod
+	}{
+		{
+			name: "nothing to sync",
+			want: false,
+		},
+		{
+			name:              "keep the allocated pods",
+			allocatedPods:     []schedulingv1alpha1.ReservationAllocatedPod{allocatedPod},
+			pods:              []*corev1.Pod{pod},
+			want:              false,
+			wantAllocatedPods: []schedulingv1alpha1.ReservationAllocatedPod{allocatedPod},
+		},
+		{
+			name:              "add the missing allocated pods",
+			pods:              []*corev1.Pod{pod},
+			want:              true,
+			wantAllocatedPods: []schedulingv1alpha1.ReservationAllocatedPod{allocatedPod},
+		},
+		{
+			name:          "remove the allocated pods of missed owners",
+			allocatedPods: []schedulingv1alpha1.ReservationAllocatedPod{allocatedPod},
+			want:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &schedulingv1alpha1.Reservation{
+				Status: schedulingv1alpha1.ReservationStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("4"),
+					},
+					AllocatedPods: tt.allocatedPods,
+				},
+			}
+			got := syncReservationAllocatedPods(r, tt.pods)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantAllocatedPods, r.Status.AllocatedPods)
+		})
+	}
+}
+
 func Test_syncPodDeleted(t *testing.T) {
 	now := time.Now()
 	testPod := &corev1.Pod{
@@ -835,3 +910,108 @@ func testListExistAndExpired(lister listerschedulingv1alpha1.ReservationLister)
 	}
 	return exist, expired, nil
 }
+
+func Test_expireReservationIfNotRenewed(t *testing.T) {
+	now := time.Now()
+	cached := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "r-0",
+			UID:               "0",
+			CreationTimestamp: metav1.Time{Time: now.Add(-2 * time.Hour)},
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			TTL: &metav1.Duration{Duration: time.Hour},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "node-0",
+		},
+	}
+	renewed := cached.DeepCopy()
+	renewed.Annotations = map[string]string{
+		apiext.AnnotationReservationRenewTime: now.Add(-time.Minute).Format(time.RFC3339),
+	}
+	tests := []struct {
+		name         string
+		latest       *schedulingv1alpha1.Reservation
+		wantPhase    schedulingv1alpha1.ReservationPhase
+		wantInactive bool
+	}{
+		{
+			name:         "expire the reservation not renewed",
+			latest:       cached,
+			wantPhase:    schedulingv1alpha1.ReservationFailed,
+			wantInactive: true,
+		},
+		{
+			name:         "honor the renewal racing with the expiration",
+			latest:       renewed,
+			wantPhase:    schedulingv1alpha1.ReservationAvailable,
+			wantInactive: false,
+		},
+		{
+			name:         "skip the deleted reservation",
+			wantInactive: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister := &fakeReservationLister{reservations: map[string]*schedulingv1alpha1.Reservation{}}
+			if tt.latest != nil {
+				lister.reservations[tt.latest.Name] = tt.latest
+			}
+			p := &Plugin{
+				reservationCache: newReservationCache(),
+				client:           &fakeReservationClient{lister: lister},
+			}
+			assert.True(t, isReservationNeedExpiration(cached))
+
+			err := p.expireReservationIfNotRenewed(cached)
+			assert.NoError(t, err)
+			if tt.latest != nil {
+				assert.Equal(t, tt.wantPhase, lister.reservations[cached.Name].Status.Phase)
+			}
+			assert.Equal(t, tt.wantInactive, p.reservationCache.IsInactive(cached))
+		})
+	}
+}
+
+func Test_isRestockDue(t *testing.T) {
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{Name: "r-0", UID: "r-0"},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Restock: true,
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:         schedulingv1alpha1.ReservationAvailable,
+			NodeName:      "node-0",
+			CurrentOwners: []corev1.ObjectReference{{Namespace: "default", Name: "test-pod-1"}},
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			Allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+		},
+	}
+	p := &Plugin{}
+	now := time.Now()
+	// the remainder is observed for the first time
+	assert.False(t, p.isRestockDue(r, now))
+	assert.False(t, p.isRestockDue(r, now.Add(defaultRestockGracePeriod/2)))
+
+	// the grace period restarts when an owner releases its resources
+	r.Status.Allocated = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("0"),
+	}
+	assert.False(t, p.isRestockDue(r, now.Add(defaultRestockGracePeriod)))
+	assert.False(t, p.isRestockDue(r, now.Add(defaultRestockGracePeriod*3/2)))
+	assert.True(t, p.isRestockDue(r, now.Add(defaultRestockGracePeriod*2)))
+	assert.Empty(t, p.restockCandidates)
+
+	// the candidates of the deleted reservations are pruned
+	assert.False(t, p.isRestockDue(r, now))
+	assert.Len(t, p.restockCandidates, 1)
+	p.pruneRestockCandidates(nil)
+	assert.Empty(t, p.restockCandidates)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/metrics"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+const (
+	// expiredReasonTimeout is the expiration reason for the reservation reaching its TTL or expiration time.
+	expiredReasonTimeout = "Timeout"
+	// expiredReasonNodeDeleted is the expiration reason for the node of the reservation being deleted.
+	expiredReasonNodeDeleted = "NodeDeleted"
+)
+
+const (
+	// reservedStateAllocated is the state of the reserved resources allocated to the owner pods.
+	reservedStateAllocated = "allocated"
+	// reservedStateUnallocated is the state of the reserved resources not allocated yet, which are neither free
+	// for the other pods.
+	reservedStateUnallocated = "unallocated"
+)
+
+// utilizationBuckets are the upper bounds of the utilization buckets of the reservations, which keeps the
+// cardinality of the utilization metric bounded instead of labeling each reservation.
+var utilizationBuckets = []struct {
+	upperBound float64
+	label      string
+}{
+	{upperBound: 0, label: "0"},
+	{upperBound: 0.25, label: "0-0.25"},
+	{upperBound: 0.5, label: "0.25-0.5"},
+	{upperBound: 0.75, label: "0.5-0.75"},
+	{upperBound: 1, label: "0.75-1"},
+}
+
+var reservationPhases = []schedulingv1alpha1.ReservationPhase{
+	schedulingv1alpha1.ReservationPending,
+	schedulingv1alpha1.ReservationWaiting,
+	schedulingv1alpha1.ReservationAvailable,
+	schedulingv1alpha1.ReservationSucceeded,
+	schedulingv1alpha1.ReservationFailed,
+}
+
+// recordReservationMetrics records the number of reservations by phase and the number of the active reservations by
+// the resource utilization bucket. The reservations not processed by the scheduler yet are counted as Pending.
+func recordReservationMetrics(rList []*schedulingv1alpha1.Reservation) {
+	counts := map[schedulingv1alpha1.ReservationPhase]int{}
+	utilizationCounts := map[corev1.ResourceName]map[string]int{}
+	for _, r := range rList {
+		phase := r.Status.Phase
+		if phase == "" {
+			phase = schedulingv1alpha1.ReservationPending
+		}
+		counts[phase]++
+
+		if !reservationutil.IsReservationActive(r) {
+			continue
+		}
+		for resourceName, utilization := range getReservationUtilization(r) {
+			if utilizationCounts[resourceName] == nil {
+				utilizationCounts[resourceName] = map[string]int{}
+			}
+			utilizationCounts[resourceName][getUtilizationBucket(utilization)]++
+		}
+	}
+	for _, phase := range reservationPhases {
+		metrics.ReservationCount.WithLabelValues(string(phase)).Set(float64(counts[phase]))
+	}
+	// reset the utilization so that the resources no longer reserved are removed
+	metrics.ReservationResourceUtilization.Reset()
+	for resourceName, buckets := range utilizationCounts {
+		for _, bucket := range utilizationBuckets {
+			metrics.ReservationResourceUtilization.WithLabelValues(string(resourceName), bucket.label).Set(float64(buckets[bucket.label]))
+		}
+	}
+}
+
+// getUtilizationBucket returns the label of the bucket the utilization falls in.
+func getUtilizationBucket(utilization float64) string {
+	for _, bucket := range utilizationBuckets {
+		if utilization <= bucket.upperBound {
+			return bucket.label
+		}
+	}
+	return utilizationBuckets[len(utilizationBuckets)-1].label
+}
+
+// reservedCapacity is the resources reserved by a set of active reservations.
+type reservedCapacity struct {
+	allocated   corev1.ResourceList
+	unallocated corev1.ResourceList
+}
+
+func (c *reservedCapacity) add(allocated, unallocated corev1.ResourceList) {
+	c.allocated = quotav1.Add(c.allocated, allocated)
+	c.unallocated = quotav1.Add(c.unallocated, unallocated)
+}
+
+// recordReservedCapacityMetrics aggregates the resources reserved by the active reservations per node, and per node
+// pool if the NodePoolLabel is specified, so that the reserved but unallocated capacity can be told apart from the
+// truly free capacity by the autoscalers and dashboards.
+func (p *Plugin) recordReservedCapacityMetrics(rList []*schedulingv1alpha1.Reservation) {
+	nodeCapacities := map[string]*reservedCapacity{}
+	for _, r := range rList {
+		if !reservationutil.IsReservationActive(r) {
+			continue
+		}
+		nodeName := reservationutil.GetReservationNodeName(r)
+		c := nodeCapacities[nodeName]
+		if c == nil {
+			c = &reservedCapacity{}
+			nodeCapacities[nodeName] = c
+		}
+		allocated := quotav1.Mask(r.Status.Allocated, quotav1.ResourceNames(r.Status.Allocatable))
+		c.add(allocated, quotav1.SubtractWithNonNegativeResult(r.Status.Allocatable, allocated))
+	}
+
+	poolCapacities := map[string]*reservedCapacity{}
+	for nodeName, c := range nodeCapacities {
+		pool := p.getNodePool(nodeName)
+		if pool == "" {
+			continue
+		}
+		poolCapacity := poolCapacities[pool]
+		if poolCapacity == nil {
+			poolCapacity = &reservedCapacity{}
+			poolCapacities[pool] = poolCapacity
+		}
+		poolCapacity.add(c.allocated, c.unallocated)
+	}
+
+	// reset the capacities so that the nodes and pools without active reservations are removed
+	metrics.NodeReservedResources.Reset()
+	for nodeName, c := range nodeCapacities {
+		setReservedCapacity(metrics.NodeReservedResources, nodeName, c)
+	}
+	metrics.NodePoolReservedResources.Reset()
+	for pool, c := range poolCapacities {
+		setReservedCapacity(metrics.NodePoolReservedResources, pool, c)
+	}
+}
+
+// getNodePool returns the node pool of the node, or empty if the node belongs to no pool.
+func (p *Plugin) getNodePool(nodeName string) string {
+	if p.nodeLister == nil || p.args == nil || p.args.NodePoolLabel == "" {
+		return ""
+	}
+	node, err := p.nodeLister.Get(nodeName)
+	if err != nil {
+		klog.V(5).InfoS("failed to get node for the node pool", "node", nodeName, "err", err)
+		return ""
+	}
+	return node.Labels[p.args.NodePoolLabel]
+}
+
+func setReservedCapacity(gaugeVec *k8smetrics.GaugeVec, name string, c *reservedCapacity) {
+	for resourceName, quantity := range c.allocated {
+		gaugeVec.WithLabelValues(name, string(resourceName), reservedStateAllocated).Set(quantity.AsApproximateFloat64())
+	}
+	for resourceName, quantity := range c.unallocated {
+		gaugeVec.WithLabelValues(name, string(resourceName), reservedStateUnallocated).Set(quantity.AsApproximateFloat64())
+	}
+}
+
+// getReservationUtilization returns the ratio of the allocated to the allocatable for each reserved resource.
+func getReservationUtilization(r *schedulingv1alpha1.Reservation) map[corev1.ResourceName]float64 {
+	utilization := map[corev1.ResourceName]float64{}
+	for resourceName, allocatable := range r.Status.Allocatable {
+		if allocatable.IsZero() {
+			continue
+		}
+		allocated := r.Status.Allocated[resourceName]
+		utilization[resourceName] = float64(allocated.MilliValue()) / float64(allocatable.MilliValue())
+	}
+	return utilization
+}
+
+// recordReservationExpired counts the expiration and records an event for the expired reservation.
+func (p *Plugin) recordReservationExpired(r *schedulingv1alpha1.Reservation, reason string) {
+	metrics.ReservationExpirations.WithLabelValues(reason).Inc()
+	p.recordReservationEvent(r, schedulingv1alpha1.ReasonReservationExpired, "Expire",
+		"Reservation %v is expired, reason: %v", r.Name, reason)
+}
+
+// recordReservationEvent records an event on the phase transition of the reservation for auditing.
+func (p *Plugin) recordReservationEvent(r *schedulingv1alpha1.Reservation, reason, action, note string, args ...interface{}) {
+	if p.handle == nil || p.handle.EventRecorder() == nil {
+		return
+	}
+	p.handle.EventRecorder().Eventf(r, nil, corev1.EventTypeNormal, reason, action, note, args...)
+}
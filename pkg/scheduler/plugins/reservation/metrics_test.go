@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/component-base/metrics/testutil"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/metrics"
+)
+
+func Test_recordReservationMetrics(t *testing.T) {
+	metrics.Register()
+
+	rList := []*schedulingv1alpha1.Reservation{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "r-new"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "r-pending"},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase: schedulingv1alpha1.ReservationPending,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "r-available"},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase:    schedulingv1alpha1.ReservationAvailable,
+				NodeName: "node-0",
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Allocated: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("1"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "r-failed"},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase: schedulingv1alpha1.ReservationFailed,
+			},
+		},
+	}
+	recordReservationMetrics(rList)
+
+	for phase, want := range map[schedulingv1alpha1.ReservationPhase]float64{
+		schedulingv1alpha1.ReservationPending:   2,
+		schedulingv1alpha1.ReservationAvailable: 1,
+		schedulingv1alpha1.ReservationSucceeded: 0,
+		schedulingv1alpha1.ReservationFailed:    1,
+	} {
+		value, err := testutil.GetGaugeMetricValue(metrics.ReservationCount.WithLabelValues(string(phase)))
+		assert.NoError(t, err)
+		assert.Equal(t, want, value, "phase %s", phase)
+	}
+	value, err := testutil.GetGaugeMetricValue(metrics.ReservationResourceUtilization.WithLabelValues(string(corev1.ResourceCPU), "0-0.25"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), value)
+	value, err = testutil.GetGaugeMetricValue(metrics.ReservationResourceUtilization.WithLabelValues(string(corev1.ResourceCPU), "0.75-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), value)
+	value, err = testutil.GetGaugeMetricValue(metrics.ReservationResourceUtilization.WithLabelValues(string(corev1.ResourceMemory), "0"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), value)
+
+	// the utilization of the resources no longer reserved is removed
+	recordReservationMetrics(rList[:2])
+	labels := map[string]string{"resource": string(corev1.ResourceCPU), "utilization": "0-0.25"}
+	assert.False(t, metrics.ReservationResourceUtilization.Delete(labels))
+}
+
+func TestPlugin_recordReservationExpired(t *testing.T) {
+	metrics.Register()
+
+	expirations := metrics.ReservationExpirations.WithLabelValues(expiredReasonNodeDeleted)
+	before, err := testutil.GetCounterMetricValue(expirations)
+	assert.NoError(t, err)
+
+	p := &Plugin{}
+	p.recordReservationExpired(&schedulingv1alpha1.Reservation{ObjectMeta: metav1.ObjectMeta{Name: "r-0"}}, expiredReasonNodeDeleted)
+
+	after, err := testutil.GetCounterMetricValue(expirations)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), after-before)
+}
+
+func TestPlugin_recordReservedCapacityMetrics(t *testing.T) {
+	metrics.Register()
+
+	informerFactory := informers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	for _, node := range []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-0", Labels: map[string]string{"pool": "pool-a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"pool": "pool-a"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+	} {
+		assert.NoError(t, nodeInformer.Informer().GetStore().Add(node))
+	}
+	p := &Plugin{
+		args:       &config.ReservationArgs{NodePoolLabel: "pool"},
+		nodeLister: nodeInformer.Lister(),
+	}
+
+	newReservation := func(name, nodeName string, phase schedulingv1alpha1.ReservationPhase, allocatable, allocated string) *schedulingv1alpha1.Reservation {
+		r := &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase:       phase,
+				NodeName:    nodeName,
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocatable)},
+			},
+		}
+		if allocated != "" {
+			r.Status.Allocated = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocated)}
+		}
+		return r
+	}
+	rList := []*schedulingv1alpha1.Reservation{
+		newReservation("r-0", "node-0", schedulingv1alpha1.ReservationAvailable, "4", "1"),
+		newReservation("r-1", "node-0", schedulingv1alpha1.ReservationAvailable, "2", ""),
+		newReservation("r-2", "node-1", schedulingv1alpha1.ReservationAvailable, "2", "2"),
+		newReservation("r-3", "node-2", schedulingv1alpha1.ReservationAvailable, "1500m", "500m"),
+		newReservation("r-4", "node-2", schedulingv1alpha1.ReservationFailed, "8", ""),
+	}
+	p.recordReservedCapacityMetrics(rList)
+
+	for _, tt := range []struct {
+		name  string
+		state string
+		want  float64
+	}{
+		{name: "node-0", state: reservedStateAllocated, want: 1},
+		{name: "node-0", state: reservedStateUnallocated, want: 5},
+		{name: "node-1", state: reservedStateAllocated, want: 2},
+		{name: "node-1", state: reservedStateUnallocated, want: 0},
+		{name: "node-2", state: reservedStateAllocated, want: 0.5},
+		{name: "node-2", state: reservedStateUnallocated, want: 1},
+	} {
+		value, err := testutil.GetGaugeMetricValue(metrics.NodeReservedResources.WithLabelValues(tt.name, string(corev1.ResourceCPU), tt.state))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, value, "node %s, state %s", tt.name, tt.state)
+	}
+	value, err := testutil.GetGaugeMetricValue(metrics.NodePoolReservedResources.WithLabelValues("pool-a", string(corev1.ResourceCPU), reservedStateAllocated))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), value)
+	value, err = testutil.GetGaugeMetricValue(metrics.NodePoolReservedResources.WithLabelValues("pool-a", string(corev1.ResourceCPU), reservedStateUnallocated))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(5), value)
+
+	// the capacities of the nodes and pools without active reservations are removed
+	p.recordReservedCapacityMetrics(rList[3:])
+	labels := map[string]string{"node": "node-0", "resource": string(corev1.ResourceCPU), "state": reservedStateUnallocated}
+	assert.False(t, metrics.NodeReservedResources.Delete(labels))
+	labels = map[string]string{"pool": "pool-a", "resource": string(corev1.ResourceCPU), "state": reservedStateUnallocated}
+	assert.False(t, metrics.NodePoolReservedResources.Delete(labels))
+}
@@ -26,12 +26,15 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	listercorev1 "k8s.io/client-go/listers/core/v1"
+	listerschedulingv1 "k8s.io/client-go/listers/scheduling/v1"
 	"k8s.io/client-go/tools/cache"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
 	pluginhelper "k8s.io/kubernetes/pkg/scheduler/framework/plugins/helper"
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
@@ -39,8 +42,10 @@ import (
 	clientschedulingv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/typed/scheduling/v1alpha1"
 	listerschedulingv1alpha1 "github.com/koordinator-sh/koordinator/pkg/client/listers/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/validation"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	frameworkexthelper "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/helper"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/metrics"
 	"github.com/koordinator-sh/koordinator/pkg/util"
 	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
@@ -60,6 +65,11 @@ const (
 	ErrReasonReservationInactive = "reservation is not active"
 	// ErrReasonReservationNotMatchStale is the reason for the assumed reservation does not match the pod any more.
 	ErrReasonReservationNotMatchStale = "reservation is stale and does not match any more"
+	// ErrReasonReservationAffinity is the reason for the pod requires reservations but none matches.
+	ErrReasonReservationAffinity = "node(s) didn't match the reservation affinity"
+	// ErrReasonReservationNodeAffinity is the reason for the reservation's node does not satisfy the node affinity of
+	// the reservation owners any more.
+	ErrReasonReservationNodeAffinity = "node(s) didn't match the node affinity of the reservation owners"
 	// SkipReasonNotReservation is the reason for pod does not match any reservation.
 	SkipReasonNotReservation = "pod does not match any reservation"
 )
@@ -80,9 +90,17 @@ type Plugin struct {
 	informer         cache.SharedIndexInformer
 	rLister          listerschedulingv1alpha1.ReservationLister
 	podLister        listercorev1.PodLister
+	nodeLister       listercorev1.NodeLister                              // nil if the reserved capacity is not aggregated by the node pool
 	client           clientschedulingv1alpha1.SchedulingV1alpha1Interface // for updates
 	parallelizeUntil parallelizeUntilFunc
 	reservationCache *reservationCache
+
+	priorityClassLister listerschedulingv1.PriorityClassLister
+	preemption          *defaultpreemption.DefaultPreemption // nil if the preemption for reserve pods is disabled
+
+	// restockCandidates are the unallocated remainders of the reservations waiting for restocking, which are only
+	// accessed by the GC.
+	restockCandidates map[types.UID]*restockCandidate
 }
 
 func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
@@ -90,6 +108,9 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type ReservationArgs, got %T", args)
 	}
+	if err := validation.ValidateReservationArgs(pluginArgs); err != nil {
+		return nil, err
+	}
 	extendedHandle, ok := handle.(frameworkext.ExtendedHandle)
 	if !ok {
 		return nil, fmt.Errorf("want handle to be of type frameworkext.ExtendedHandle, got %T", handle)
@@ -99,6 +120,8 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 	reservationInterface := koordSharedInformerFactory.Scheduling().V1alpha1().Reservations()
 	reservationInformer := reservationInterface.Informer()
 
+	metrics.Register()
+
 	p := &Plugin{
 		handle:           extendedHandle,
 		args:             pluginArgs,
@@ -109,6 +132,17 @@ func New(args runtime.Object, handle framework.Handle) (framework.Plugin, error)
 		parallelizeUntil: defaultParallelizeUntil(handle),
 		reservationCache: getReservationCache(),
 	}
+	if pluginArgs.NodePoolLabel != "" {
+		p.nodeLister = extendedHandle.SharedInformerFactory().Core().V1().Nodes().Lister()
+	}
+	if pluginArgs.EnablePreemption != nil && *pluginArgs.EnablePreemption {
+		p.priorityClassLister = extendedHandle.SharedInformerFactory().Scheduling().V1().PriorityClasses().Lister()
+		preemption, err := newDefaultPreemption(handle)
+		if err != nil {
+			return nil, err
+		}
+		p.preemption = preemption
+	}
 
 	// handle reservation event in cache; here only scheduled and expired reservations are considered.
 	reservationEventHandler := cache.ResourceEventHandlerFuncs{
@@ -189,6 +223,20 @@ func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState
 	}
 
 	klog.V(5).InfoS("Attempting to pre-filter pod for reservation state", "pod", klog.KObj(pod))
+	// the pod requiring reservation affinity must fail instead of allocating the free node resources
+	affinity, err := apiext.GetReservationAffinity(pod.Annotations)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "invalid reservation affinity, err: "+err.Error())
+	}
+	if affinity != nil {
+		state := getPreFilterState(cycleState)
+		if state == nil || state.skip {
+			if state != nil && len(state.nodeAffinityUnmatched) > 0 {
+				return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationNodeAffinity)
+			}
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationAffinity)
+		}
+	}
 	return nil
 }
 
@@ -202,6 +250,9 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 	if node == nil {
 		return framework.NewStatus(framework.Error, "node not found")
 	}
+	if status := frameworkext.CheckNodeSchedulingPaused(node); !status.IsSuccess() {
+		return status
+	}
 
 	// the pod is a reserve pod
 	if reservationutil.IsReservePod(pod) {
@@ -216,13 +267,31 @@ func (p *Plugin) Filter(ctx context.Context, cycleState *framework.CycleState, p
 		return nil
 	}
 
+	state := getPreFilterState(cycleState)
+	if state != nil && state.requireAffinity && len(state.matchedCache.GetOnNode(node.Name)) <= 0 {
+		if state.nodeAffinityUnmatched[node.Name] {
+			return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationNodeAffinity)
+		}
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationAffinity)
+	}
 	return nil
 }
 
 func (p *Plugin) PostFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
 	if reservationutil.IsReservePod(pod) {
-		// return err to stop default preemption
-		return nil, framework.NewStatus(framework.Error)
+		if p.preemption == nil {
+			// return err to stop default preemption
+			return nil, framework.NewStatus(framework.Error)
+		}
+		nominatedNodeName, status := p.preempt(ctx, state, pod, filteredNodeStatusMap)
+		if !status.IsSuccess() {
+			return nil, status
+		}
+		// the reserve pod is not preemptable by the default preemption, so return unschedulable if nothing is preempted
+		if nominatedNodeName == "" {
+			return nil, framework.NewStatus(framework.Unschedulable)
+		}
+		return &framework.PostFilterResult{NominatedNodeName: nominatedNodeName}, framework.NewStatus(framework.Success)
 	}
 
 	if p.reservationCache == nil || p.reservationCache.active == nil {
@@ -312,10 +381,10 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 		return framework.MinNodeScore, nil
 	}
 
-	// select one reservation for the pod to allocate
-	// sort: here we use MostAllocated (simply set all weights as 1.0)
+	// select one reservation for the pod to allocate according to the scoring strategy
+	scoreRange := p.getScoreRange(state)
 	for i := range rOnNode {
-		rOnNode[i].ScoreForPod(pod)
+		scoreReservation(p.args, scoreRange, pod, rOnNode[i])
 	}
 	sort.Slice(rOnNode, func(i, j int) bool {
 		return rOnNode[i].Score >= rOnNode[j].Score
@@ -324,6 +393,15 @@ func (p *Plugin) Score(ctx context.Context, cycleState *framework.CycleState, po
 	return rOnNode[0].Score, nil
 }
 
+// getScoreRange returns the score range prepared in BeforePreFilter, or calculates it if the state is not prepared
+// with the range.
+func (p *Plugin) getScoreRange(state *stateData) *reservationScoreRange {
+	if state.scoreRange != nil {
+		return state.scoreRange
+	}
+	return newReservationScoreRange(p.args, state.matchedCache)
+}
+
 func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
 	return p
 }
@@ -352,8 +430,8 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 		return nil
 	}
 
-	// select one reservation for the pod to allocate
-	// sort: here we use MostAllocated (simply set all weights as 1.0)
+	// select one reservation for the pod to allocate according to the scoring strategy
+	scoreRange := p.getScoreRange(state)
 	var order int64 = math.MaxInt64
 	for i := range rOnNode {
 		var rInfo *reservationInfo
@@ -364,7 +442,7 @@ func (p *Plugin) Reserve(ctx context.Context, cycleState *framework.CycleState,
 				continue
 			}
 		}
-		rOnNode[i].ScoreForPod(pod)
+		scoreReservation(p.args, scoreRange, pod, rOnNode[i])
 	}
 	sort.Slice(rOnNode, func(i, j int) bool {
 		return rOnNode[i].Score >= rOnNode[j].Score
@@ -570,6 +648,11 @@ func (p *Plugin) PreBind(ctx context.Context, cycleState *framework.CycleState,
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
+	if target.Spec.AllocateOnce {
+		p.recordReservationEvent(target, schedulingv1alpha1.ReasonReservationSucceeded, "Allocate",
+			"Reservation %v is allocated once by pod %v", target.Name, klog.KObj(pod))
+	}
+
 	// assume accepted
 	p.reservationCache.Unassume(target, false)
 	// set the pre-bind flag, unreserve should try to resume
@@ -635,6 +718,7 @@ func (p *Plugin) Bind(ctx context.Context, cycleState *framework.CycleState, pod
 		return framework.AsStatus(err)
 	}
 
+	metrics.ReservationScheduleDuration.Observe(metrics.SinceInSeconds(reservation.CreationTimestamp.Time))
 	p.handle.EventRecorder().Eventf(reservation, nil, corev1.EventTypeNormal, "Scheduled", "Binding", "Successfully assigned %v to %v", rName, nodeName)
 	return nil
 }
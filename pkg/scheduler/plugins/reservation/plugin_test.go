@@ -27,6 +27,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -99,6 +100,17 @@ func (f *fakeReservationClient) Reservations() clientschedulingv1alpha1.Reservat
 	return f
 }
 
+func (f *fakeReservationClient) Get(ctx context.Context, name string, opts metav1.GetOptions) (*schedulingv1alpha1.Reservation, error) {
+	if f.lister.getErr[name] {
+		return nil, fmt.Errorf("get error")
+	}
+	r, ok := f.lister.reservations[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(schedulingv1alpha1.Resource("reservation"), name)
+	}
+	return r, nil
+}
+
 func (f *fakeReservationClient) UpdateStatus(ctx context.Context, reservation *schedulingv1alpha1.Reservation, opts metav1.UpdateOptions) (*schedulingv1alpha1.Reservation, error) {
 	if f.updateStatusErr[reservation.Name] {
 		return nil, fmt.Errorf("updateStatus error")
@@ -508,6 +520,44 @@ func TestPreFilter(t *testing.T) {
 			},
 			want: nil,
 		},
+		{
+			name: "failed for pod requires reservation affinity but no reservation matched",
+			args: args{
+				cycleState: func() *framework.CycleState {
+					cycleState := framework.NewCycleState()
+					cycleState.Write(preFilterStateKey, &stateData{
+						skip:            true,
+						requireAffinity: true,
+						matchedCache:    newAvailableCache(),
+					})
+					return cycleState
+				}(),
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-pod-0",
+						Annotations: map[string]string{
+							apiext.AnnotationReservationAffinity: `{"reservationName":"reserve-pod-0"}`,
+						},
+					},
+				},
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationAffinity),
+		},
+		{
+			name: "failed for invalid reservation affinity",
+			args: args{
+				cycleState: framework.NewCycleState(),
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-pod-0",
+						Annotations: map[string]string{
+							apiext.AnnotationReservationAffinity: `{"reservationName":`,
+						},
+					},
+				},
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, "invalid reservation affinity, err: unexpected end of JSON input"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -526,6 +576,10 @@ func TestFilter(t *testing.T) {
 	}
 	testNodeInfo := &framework.NodeInfo{}
 	testNodeInfo.SetNode(testNode)
+	pausedNode := testNode.DeepCopy()
+	pausedNode.Annotations = map[string]string{apiext.AnnotationNodeSchedulingPaused: "true"}
+	pausedNodeInfo := &framework.NodeInfo{}
+	pausedNodeInfo.SetNode(pausedNode)
 	reservePodForNotSetNode := testGetReservePod(&corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "reserve-pod-0",
@@ -550,6 +604,39 @@ func TestFilter(t *testing.T) {
 			},
 		},
 	})
+	matchedCache := newAvailableCache()
+	matchedCache.Add(&schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reserve-pod-0",
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: testNode.Name,
+		},
+	})
+	otherNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-1",
+		},
+	}
+	otherNodeInfo := &framework.NodeInfo{}
+	otherNodeInfo.SetNode(otherNode)
+	affinityCycleState := framework.NewCycleState()
+	affinityCycleState.Write(preFilterStateKey, &stateData{
+		requireAffinity: true,
+		matchedCache:    matchedCache,
+	})
+	nodeAffinityCycleState := framework.NewCycleState()
+	nodeAffinityCycleState.Write(preFilterStateKey, &stateData{
+		requireAffinity: true,
+		matchedCache:    matchedCache,
+		nodeAffinityUnmatched: map[string]bool{
+			otherNode.Name: true,
+		},
+	})
 	type args struct {
 		cycleState *framework.CycleState
 		pod        *corev1.Pod
@@ -613,6 +700,66 @@ func TestFilter(t *testing.T) {
 			},
 			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonNodeNotMatchReservation),
 		},
+		{
+			name: "filter pod requiring reservation affinity successfully",
+			args: args{
+				cycleState: affinityCycleState,
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "not-reserve",
+					},
+				},
+				nodeInfo: testNodeInfo,
+			},
+			want: nil,
+		},
+		{
+			name: "failed for pod requiring reservation affinity on node without matched reservation",
+			args: args{
+				cycleState: affinityCycleState,
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "not-reserve",
+					},
+				},
+				nodeInfo: otherNodeInfo,
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationAffinity),
+		},
+		{
+			name: "failed for pod requiring reservation affinity on node not matching owner node affinity",
+			args: args{
+				cycleState: nodeAffinityCycleState,
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "not-reserve",
+					},
+				},
+				nodeInfo: otherNodeInfo,
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonReservationNodeAffinity),
+		},
+		{
+			name: "failed for reserve pod on node with scheduling paused",
+			args: args{
+				pod:      reservePodForMatchedNode,
+				nodeInfo: pausedNodeInfo,
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, frameworkext.ErrReasonNodeSchedulingPaused),
+		},
+		{
+			name: "failed for normal pod on node with scheduling paused",
+			args: args{
+				cycleState: framework.NewCycleState(),
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "not-reserve",
+					},
+				},
+				nodeInfo: pausedNodeInfo,
+			},
+			want: framework.NewStatus(framework.UnschedulableAndUnresolvable, frameworkext.ErrReasonNodeSchedulingPaused),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1289,6 +1436,123 @@ func TestScoreWithOrder(t *testing.T) {
 	assert.Equal(t, expectedNodeScoreList, scoreList)
 }
 
+func TestScoreWithScoringStrategy(t *testing.T) {
+	normalPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pod-1",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	now := time.Now()
+	reservationTemplateFn := func(i int, cpu string, createdBefore time.Duration, priority string) *schedulingv1alpha1.Reservation {
+		r := &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				UID:               uuid.NewUUID(),
+				Name:              fmt.Sprintf("test-reservation-%d", i),
+				CreationTimestamp: metav1.NewTime(now.Add(-createdBefore)),
+			},
+			Spec: schedulingv1alpha1.ReservationSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name: "main",
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU: resource.MustParse(cpu),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase:    schedulingv1alpha1.ReservationAvailable,
+				NodeName: fmt.Sprintf("test-node-%d", i),
+			},
+		}
+		if priority != "" {
+			r.Labels = map[string]string{"reservation-priority": priority}
+		}
+		return r
+	}
+	reservations := []*schedulingv1alpha1.Reservation{
+		reservationTemplateFn(1, "2", time.Minute, "10"),
+		reservationTemplateFn(2, "4", time.Hour, ""),
+		reservationTemplateFn(3, "8", 31*time.Minute, "20"),
+	}
+
+	tests := []struct {
+		name string
+		args *config.ReservationArgs
+		want framework.NodeScoreList
+	}{
+		{
+			name: "default most allocated",
+			args: &config.ReservationArgs{},
+			want: framework.NodeScoreList{
+				{Name: "test-node-1", Score: framework.MaxNodeScore},
+				{Name: "test-node-2", Score: 50},
+				{Name: "test-node-3", Score: 25},
+			},
+		},
+		{
+			name: "oldest",
+			args: &config.ReservationArgs{ScoringStrategy: config.ReservationOldest},
+			want: framework.NodeScoreList{
+				{Name: "test-node-1", Score: 0},
+				{Name: "test-node-2", Score: framework.MaxNodeScore},
+				{Name: "test-node-3", Score: 50},
+			},
+		},
+		{
+			name: "label priority",
+			args: &config.ReservationArgs{ScoringStrategy: config.ReservationLabelPriority, PriorityLabel: "reservation-priority"},
+			want: framework.NodeScoreList{
+				{Name: "test-node-1", Score: 99},
+				{Name: "test-node-2", Score: 0},
+				{Name: "test-node-3", Score: framework.MaxNodeScore},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{
+				args:             tt.args,
+				parallelizeUntil: fakeParallelizeUntil(nil),
+			}
+			matchedCache := newAvailableCache(reservations...)
+			cycleState := framework.NewCycleState()
+			cycleState.Write(preFilterStateKey, &stateData{
+				matchedCache: matchedCache,
+				scoreRange:   newReservationScoreRange(tt.args, matchedCache),
+			})
+
+			var scoreList framework.NodeScoreList
+			for i := range reservations {
+				nodeName := fmt.Sprintf("test-node-%d", i+1)
+				score, status := p.Score(context.TODO(), cycleState, normalPod, nodeName)
+				assert.True(t, status.IsSuccess())
+				scoreList = append(scoreList, framework.NodeScore{Name: nodeName, Score: score})
+			}
+			assert.Equal(t, tt.want, scoreList)
+		})
+	}
+}
+
 func TestReserve(t *testing.T) {
 	reservePod := testGetReservePod(&corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1757,7 +2021,7 @@ func TestPreBind(t *testing.T) {
 	rAllocateOnce := rScheduled.DeepCopy()
 	rAllocateOnce.Spec.AllocateOnce = true
 	rExpired := rScheduled.DeepCopy()
-	setReservationExpired(rExpired)
+	reservationutil.SetReservationExpired(rExpired)
 	stateSkip := framework.NewCycleState()
 	stateSkip.Write(preFilterStateKey, &stateData{
 		skip: true,
@@ -2118,7 +2382,7 @@ func Test_handleOnAdd(t *testing.T) {
 		},
 	}
 	rExpired := rScheduled.DeepCopy()
-	setReservationExpired(rExpired)
+	reservationutil.SetReservationExpired(rExpired)
 	tests := []struct {
 		name  string
 		arg   interface{}
@@ -2174,7 +2438,7 @@ func Test_handleOnUpdate(t *testing.T) {
 		},
 	}
 	rExpired := rScheduled.DeepCopy()
-	setReservationExpired(rExpired)
+	reservationutil.SetReservationExpired(rExpired)
 	rScheduledDifferent := rScheduled.DeepCopy()
 	rScheduledDifferent.UID = "abcd"
 	tests := []struct {
@@ -2239,7 +2503,7 @@ func Test_handleOnDelete(t *testing.T) {
 		},
 	}
 	rExpired := rScheduled.DeepCopy()
-	setReservationExpired(rExpired)
+	reservationutil.SetReservationExpired(rExpired)
 	tests := []struct {
 		name  string
 		arg   interface{}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/compatibledefaultpreemption"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func newDefaultPreemption(handle framework.Handle) (*defaultpreemption.DefaultPreemption, error) {
+	plg, err := compatibledefaultpreemption.New(nil, handle)
+	if err != nil {
+		return nil, err
+	}
+	dp, ok := plg.(*compatibledefaultpreemption.CompatibleDefaultPreemption).PostFilterPlugin.(*defaultpreemption.DefaultPreemption)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default preemption plugin type %T", plg)
+	}
+	return dp, nil
+}
+
+// preempt tries to make room for the reserve pod by preempting lower priority pods. It follows the default
+// preemption steps, but the preemptor is the reservation rather than a real pod, so the nominated node and the
+// victims are recorded in the reservation status.
+func (p *Plugin) preempt(ctx context.Context, state *framework.CycleState, reservePod *corev1.Pod, m framework.NodeToStatusMap) (string, *framework.Status) {
+	nodeLister := p.handle.SnapshotSharedLister().NodeInfos()
+
+	// 0) Fetch the latest version of the reservation.
+	rName := reservationutil.GetReservationNameFromReservePod(reservePod)
+	r, err := p.rLister.Get(rName)
+	if err != nil {
+		klog.ErrorS(err, "getting the updated preemptor reservation object", "reservation", rName)
+		return "", framework.AsStatus(err)
+	}
+	if reservationutil.IsReservationFailed(r) || reservationutil.IsReservationSucceeded(r) || reservationutil.IsReservationAvailable(r) {
+		return "", framework.NewStatus(framework.Unschedulable, ErrReasonReservationInactive)
+	}
+	pod := reservationutil.NewReservePod(r)
+	if err = p.setReservePodPriority(pod); err != nil {
+		klog.ErrorS(err, "failed to get priority of reserve pod", "reservation", klog.KObj(r))
+		return "", framework.AsStatus(err)
+	}
+
+	// 1) Ensure the preemptor is eligible to preempt other pods.
+	if !defaultpreemption.PodEligibleToPreemptOthers(pod, nodeLister, m[pod.Status.NominatedNodeName]) {
+		klog.V(5).InfoS("Reservation is not eligible for more preemption", "reservation", klog.KObj(r))
+		return "", nil
+	}
+
+	// 2) Find all preemption candidates.
+	candidates, nodeToStatusMap, status := p.preemption.FindCandidates(ctx, state, pod, m)
+	if !status.IsSuccess() {
+		return "", status
+	}
+	if len(candidates) == 0 {
+		if len(r.Status.NominatedNodeName) > 0 {
+			if err = p.updateReservationPreemption(rName, "", nil); err != nil {
+				klog.ErrorS(err, "cannot clear nominatedNodeName of reservation", "reservation", klog.KObj(r))
+			}
+		}
+		fitError := &framework.FitError{
+			Pod:         pod,
+			NumAllNodes: len(nodeToStatusMap),
+			Diagnosis: framework.Diagnosis{
+				NodeToStatusMap: nodeToStatusMap,
+			},
+		}
+		return "", framework.NewStatus(framework.Unschedulable, fitError.Error())
+	}
+
+	// 3) Interact with registered Extenders to filter out some candidates if needed.
+	candidates, status = defaultpreemption.CallExtenders(p.handle.Extenders(), pod, nodeLister, candidates)
+	if !status.IsSuccess() {
+		return "", status
+	}
+
+	// 4) Find the best candidate.
+	bestCandidate := defaultpreemption.SelectCandidate(candidates)
+	if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
+		return "", nil
+	}
+
+	// 5) Evict the victims and record them in the reservation.
+	if status = defaultpreemption.PrepareCandidate(bestCandidate, p.handle, p.handle.ClientSet(), pod, p.Name()); !status.IsSuccess() {
+		return "", status
+	}
+	if err = p.updateReservationPreemption(rName, bestCandidate.Name(), bestCandidate.Victims().Pods); err != nil {
+		klog.ErrorS(err, "failed to record preemption in reservation", "reservation", klog.KObj(r))
+		return "", framework.AsStatus(err)
+	}
+	p.handle.EventRecorder().Eventf(r, nil, corev1.EventTypeNormal, "Preempted", "Preempting",
+		"Preempted %d pod(s) on node %v", len(bestCandidate.Victims().Pods), bestCandidate.Name())
+
+	return bestCandidate.Name(), nil
+}
+
+// setReservePodPriority resolves the priority from the priorityClassName since the reserve pod does not go through
+// the priority admission.
+func (p *Plugin) setReservePodPriority(pod *corev1.Pod) error {
+	if pod.Spec.Priority != nil || len(pod.Spec.PriorityClassName) <= 0 {
+		return nil
+	}
+	pc, err := p.priorityClassLister.Get(pod.Spec.PriorityClassName)
+	if err != nil {
+		return err
+	}
+	priority := pc.Value
+	pod.Spec.Priority = &priority
+	if pod.Spec.PreemptionPolicy == nil && pc.PreemptionPolicy != nil {
+		policy := *pc.PreemptionPolicy
+		pod.Spec.PreemptionPolicy = &policy
+	}
+	return nil
+}
+
+func (p *Plugin) updateReservationPreemption(rName string, nominatedNodeName string, victims []*corev1.Pod) error {
+	return util.RetryOnConflictOrTooManyRequests(func() error {
+		r, err := p.rLister.Get(rName)
+		if errors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		r = r.DeepCopy()
+		setReservationPreempted(r, nominatedNodeName, victims)
+		_, err = p.client.Reservations().UpdateStatus(context.TODO(), r, metav1.UpdateOptions{})
+		return err
+	})
+}
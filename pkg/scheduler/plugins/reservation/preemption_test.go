@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	scheduledconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	plfeature "k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedulertesting "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/pointer"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+func TestPostFilterWithPreemption(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+	lowPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "low-pod",
+			Namespace: "default",
+			UID:       "low-pod-uid",
+		},
+		Spec: corev1.PodSpec{
+			NodeName: node.Name,
+			Priority: pointer.Int32(0),
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("4"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	highPriorityClass := &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      1000,
+	}
+	newReservation := func(priorityClassName string) *schedulingv1alpha1.Reservation {
+		return &schedulingv1alpha1.Reservation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "r-preemptor",
+				UID:               "r-preemptor-uid",
+				CreationTimestamp: metav1.Now(),
+			},
+			Spec: schedulingv1alpha1.ReservationSpec{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						PriorityClassName: priorityClassName,
+						Containers: []corev1.Container{
+							{
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("2"),
+										corev1.ResourceMemory: resource.MustParse("2Gi"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Owners: []schedulingv1alpha1.ReservationOwner{
+					{Object: &corev1.ObjectReference{Name: "pod-0"}},
+				},
+				TTL: &metav1.Duration{Duration: 30 * time.Minute},
+			},
+			Status: schedulingv1alpha1.ReservationStatus{
+				Phase: schedulingv1alpha1.ReservationPending,
+			},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		priorityClassName     string
+		enablePreemption      bool
+		wantNominatedNodeName string
+		wantStatusCode        framework.Code
+		wantPreempted         []corev1.ObjectReference
+	}{
+		{
+			name:              "preemption disabled",
+			priorityClassName: "high",
+			wantStatusCode:    framework.Error,
+		},
+		{
+			name:                  "preempt lower priority pod",
+			priorityClassName:     "high",
+			enablePreemption:      true,
+			wantNominatedNodeName: node.Name,
+			wantStatusCode:        framework.Success,
+			wantPreempted: []corev1.ObjectReference{
+				{Namespace: lowPod.Namespace, Name: lowPod.Name, UID: lowPod.UID},
+			},
+		},
+		{
+			name:             "reservation without priority cannot preempt",
+			enablePreemption: true,
+			wantStatusCode:   framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := newReservation(tt.priorityClassName)
+			cs := kubefake.NewSimpleClientset(node, lowPod, highPriorityClass)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			koordClientSet := koordfake.NewSimpleClientset(reservation)
+			koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClientSet, 0)
+			extenderFactory, _ := frameworkext.NewFrameworkExtenderFactory(
+				frameworkext.WithKoordinatorClientSet(koordClientSet),
+				frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+			)
+			proxyNew := frameworkext.PluginFactoryProxy(extenderFactory, New)
+
+			registeredPlugins := []schedulertesting.RegisterPluginFunc{
+				schedulertesting.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				schedulertesting.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				schedulertesting.RegisterPluginAsExtensions(noderesources.FitName, func(_ apiruntime.Object, fh framework.Handle) (framework.Plugin, error) {
+					return noderesources.NewFit(&scheduledconfig.NodeResourcesFitArgs{
+						ScoringStrategy: &scheduledconfig.ScoringStrategy{
+							Type: scheduledconfig.LeastAllocated,
+							Resources: []scheduledconfig.ResourceSpec{
+								{Name: string(corev1.ResourceCPU), Weight: 1},
+								{Name: string(corev1.ResourceMemory), Weight: 1},
+							},
+						},
+					}, fh, plfeature.Features{})
+				}, "Filter", "PreFilter"),
+			}
+			fh, err := schedulertesting.NewFramework(registeredPlugins, "koord-scheduler",
+				runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory),
+				runtime.WithSnapshotSharedLister(newFakeSharedLister([]*corev1.Pod{lowPod}, []*corev1.Node{node}, false)),
+				runtime.WithPodNominator(&fakePodNominator{}),
+				runtime.WithEventRecorder(events.NewFakeRecorder(10)),
+			)
+			assert.NoError(t, err)
+
+			args := &config.ReservationArgs{EnablePreemption: pointer.Bool(tt.enablePreemption)}
+			pl, err := proxyNew(args, fh)
+			assert.NoError(t, err)
+			p := pl.(*Plugin)
+			informerFactory.Start(nil)
+			informerFactory.WaitForCacheSync(nil)
+			koordSharedInformerFactory.Start(nil)
+			koordSharedInformerFactory.WaitForCacheSync(nil)
+
+			reservePod := reservationutil.NewReservePod(reservation)
+			state := framework.NewCycleState()
+			assert.True(t, fh.RunPreFilterPlugins(context.TODO(), state, reservePod).IsSuccess())
+			filteredNodeStatusMap := framework.NodeToStatusMap{
+				node.Name: framework.NewStatus(framework.Unschedulable, "Insufficient cpu"),
+			}
+			got, status := p.PostFilter(context.TODO(), state, reservePod, filteredNodeStatusMap)
+			assert.Equal(t, tt.wantStatusCode, status.Code(), status.Message())
+			if tt.wantNominatedNodeName != "" {
+				assert.Equal(t, &framework.PostFilterResult{NominatedNodeName: tt.wantNominatedNodeName}, got)
+			} else {
+				assert.Nil(t, got)
+			}
+
+			r, err := koordClientSet.SchedulingV1alpha1().Reservations().Get(context.TODO(), reservation.Name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantNominatedNodeName, r.Status.NominatedNodeName)
+			assert.Equal(t, tt.wantPreempted, r.Status.PreemptedPods)
+			_, err = cs.CoreV1().Pods(lowPod.Namespace).Get(context.TODO(), lowPod.Name, metav1.GetOptions{})
+			assert.Equal(t, len(tt.wantPreempted) > 0, apierrors.IsNotFound(err))
+		})
+	}
+}
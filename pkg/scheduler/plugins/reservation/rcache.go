@@ -29,6 +29,7 @@ import (
 
 	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
 	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
 
@@ -113,6 +114,72 @@ func findMostPreferredReservationByOrder(rOnNode []*reservationInfo) (*reservati
 	return rInfo, selectOrder
 }
 
+// reservationScoreRange is the range of the values of the matched reservations for the scoring strategies which
+// score the reservations relatively, i.e. Oldest and LabelPriority.
+type reservationScoreRange struct {
+	min int64
+	max int64
+}
+
+func getScoringStrategy(args *config.ReservationArgs) config.ReservationScoringStrategyType {
+	if args == nil || args.ScoringStrategy == "" {
+		return config.ReservationMostAllocated
+	}
+	return args.ScoringStrategy
+}
+
+// getReservationScoreValue returns the value of the reservation for the relative scoring strategies. The reservation
+// with the greater value is preferred.
+func getReservationScoreValue(args *config.ReservationArgs, r *schedulingv1alpha1.Reservation) int64 {
+	switch getScoringStrategy(args) {
+	case config.ReservationOldest:
+		return -r.CreationTimestamp.Unix()
+	case config.ReservationLabelPriority:
+		// the reservations without a valid priority are the least preferred
+		priority, err := strconv.ParseInt(r.Labels[args.PriorityLabel], 10, 64)
+		if err != nil {
+			return math.MinInt32
+		}
+		return priority
+	}
+	return 0
+}
+
+func newReservationScoreRange(args *config.ReservationArgs, cache *AvailableCache) *reservationScoreRange {
+	if getScoringStrategy(args) == config.ReservationMostAllocated || cache == nil {
+		return nil
+	}
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+	scoreRange := &reservationScoreRange{min: math.MaxInt64, max: math.MinInt64}
+	for _, rInfo := range cache.reservations {
+		v := getReservationScoreValue(args, rInfo.Reservation)
+		if v < scoreRange.min {
+			scoreRange.min = v
+		}
+		if v > scoreRange.max {
+			scoreRange.max = v
+		}
+	}
+	return scoreRange
+}
+
+// scoreReservation sets the score of the reservation for the pod in [0, MaxNodeScore] according to the scoring
+// strategy, so that the capacity of the reservations is consumed predictably.
+func scoreReservation(args *config.ReservationArgs, scoreRange *reservationScoreRange, pod *corev1.Pod, rInfo *reservationInfo) {
+	if getScoringStrategy(args) == config.ReservationMostAllocated || scoreRange == nil {
+		rInfo.ScoreForPod(pod)
+		return
+	}
+	if scoreRange.max <= scoreRange.min {
+		rInfo.Score = framework.MaxNodeScore
+		return
+	}
+	v := getReservationScoreValue(args, rInfo.Reservation)
+	// use float to avoid the overflow of the wide range
+	rInfo.Score = int64(float64(framework.MaxNodeScore) * (float64(v) - float64(scoreRange.min)) / (float64(scoreRange.max) - float64(scoreRange.min)))
+}
+
 // AvailableCache is for efficiently querying the reservation allocation results.
 // Typical usages are as below:
 // 1. check if a pod match any of available reservations (ownership and resource requirements).
@@ -366,11 +433,16 @@ var _ framework.StateData = &stateData{}
 
 type stateData struct {
 	skip               bool            // set true if pod does not allocate reserved resources
+	requireAffinity    bool            // set true if pod must allocate reserved resources by the reservation affinity
 	preBind            bool            // set true if pod succeeds the reservation pre-bind
 	matchedCache       *AvailableCache // matched reservations for the scheduling pod
 	mostPreferredNode  string
 	assumed            *schedulingv1alpha1.Reservation // assumed reservation to be allocated by the pod
 	allocatedResources map[string]corev1.ResourceList
+	// nodes whose reservations match the pod but are rejected by the node affinity of the owners
+	nodeAffinityUnmatched map[string]bool
+	// the range of the matched reservations for the relative scoring strategies, nil for MostAllocated
+	scoreRange *reservationScoreRange
 }
 
 func (d *stateData) Clone() framework.StateData {
@@ -387,9 +459,13 @@ func (d *stateData) Clone() framework.StateData {
 	}
 	return &stateData{
 		skip:               d.skip,
+		requireAffinity:    d.requireAffinity,
 		preBind:            d.preBind,
 		matchedCache:       cacheCopy,
 		assumed:            d.assumed,
 		allocatedResources: d.allocatedResources,
+
+		nodeAffinityUnmatched: d.nodeAffinityUnmatched,
+		scoreRange:            d.scoreRange,
 	}
 }
@@ -29,6 +29,7 @@ import (
 	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
 	index "github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/indexer"
@@ -113,10 +114,17 @@ func (p *Plugin) prepareMatchReservationState(handle frameworkext.ExtendedHandle
 		return nil, fmt.Errorf("cannot list NodeInfo, err: %v", err)
 	}
 
+	affinity, err := apiext.GetReservationAffinity(pod.Annotations)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse reservation affinity, err: %v", err)
+	}
+
 	indexer := handle.KoordinatorSharedInformerFactory().Scheduling().V1alpha1().Reservations().Informer().GetIndexer()
+	podLister := handle.SharedInformerFactory().Core().V1().Pods().Lister()
 	matchedCache := newAvailableCache()
 	var lock sync.Mutex
 	allocatedResource := map[string]corev1.ResourceList{}
+	nodeAffinityUnmatched := map[string]bool{}
 	processNode := func(i int) {
 		var resourceNeedUnreserve corev1.ResourceList
 		nodeInfo := allNodes[i]
@@ -139,6 +147,7 @@ func (p *Plugin) prepareMatchReservationState(handle frameworkext.ExtendedHandle
 		count := 0
 		rCache := getReservationCache()
 		hasAllocatedResource := false
+		hasNodeAffinityUnmatched := false
 		for _, obj := range rOnNode {
 			r, ok := obj.(*schedulingv1alpha1.Reservation)
 			if !ok {
@@ -153,8 +162,17 @@ func (p *Plugin) prepareMatchReservationState(handle frameworkext.ExtendedHandle
 			if !reservationutil.IsReservationAvailable(rInfo.Reservation) {
 				continue
 			}
+			// restocked resources are returned to the node no matter whether the reservation matches or not
+			if len(rInfo.Reservation.Status.Restocked) > 0 {
+				hasAllocatedResource = true
+				resourceNeedUnreserve = quotav1.Add(resourceNeedUnreserve, rInfo.Reservation.Status.Restocked)
+			}
 
-			if matchReservation(pod, rInfo) {
+			matched := matchReservation(pod, rInfo) && matchReservationAffinity(affinity, r) &&
+				!isReservationHeldForMigration(podLister, pod, r)
+			// the node affinity of the owners is evaluated against the current node at consumption time
+			nodeAffinityMatched := !matched || matchReservationOwnerNodeAffinity(pod, r, node)
+			if matched && nodeAffinityMatched {
 				matchedCache.Add(r)
 				count++
 			} else {
@@ -162,14 +180,25 @@ func (p *Plugin) prepareMatchReservationState(handle frameworkext.ExtendedHandle
 					hasAllocatedResource = true
 					resourceNeedUnreserve = quotav1.Add(resourceNeedUnreserve, rInfo.Reservation.Status.Allocated)
 				}
+				if !nodeAffinityMatched {
+					hasNodeAffinityUnmatched = true
+					klog.V(5).InfoS("got reservation on node does not match the pod",
+						"reservation", klog.KObj(r), "pod", klog.KObj(pod), "reason", ErrReasonReservationNodeAffinity)
+					continue
+				}
 				klog.V(6).InfoS("got reservation on node does not match the pod",
 					"reservation", klog.KObj(r), "pod", klog.KObj(pod), "reason",
 					dumpMatchReservationReason(pod, newReservationInfo(r)))
 			}
 		}
-		if hasAllocatedResource {
+		if hasAllocatedResource || hasNodeAffinityUnmatched {
 			lock.Lock()
-			allocatedResource[node.Name] = resourceNeedUnreserve
+			if hasAllocatedResource {
+				allocatedResource[node.Name] = resourceNeedUnreserve
+			}
+			if hasNodeAffinityUnmatched {
+				nodeAffinityUnmatched[node.Name] = true
+			}
 			lock.Unlock()
 		}
 		if count <= 0 { // no reservation matched on this node
@@ -186,8 +215,12 @@ func (p *Plugin) prepareMatchReservationState(handle frameworkext.ExtendedHandle
 
 	state := &stateData{
 		skip:               matchedCache.Len() <= 0, // skip if no reservation matched
+		requireAffinity:    affinity != nil,
 		matchedCache:       matchedCache,
 		allocatedResources: allocatedResource,
+
+		nodeAffinityUnmatched: nodeAffinityUnmatched,
+		scoreRange:            newReservationScoreRange(p.args, matchedCache),
 	}
 
 	return state, nil
@@ -268,7 +301,9 @@ func prepareFilterNodeInfo(pod *corev1.Pod, nodeInfo *framework.NodeInfo, rOnNod
 	newNodeInfo.Requested.Add(quotav1.Subtract(util.NewZeroResourceList(), podRequests))
 	newNodeInfo.Requested.Add(quotav1.Subtract(util.NewZeroResourceList(), allocatedResources))
 
-	// 2. ignore reserved node ports on the reserved node, only non-reserved ports are counted
+	// 2. ignore reserved node ports on the reserved node, only non-reserved ports are counted.
+	//    the reserved ports already used by other pods (e.g. the current owners of the reservations) are still counted,
+	//    since the owner pods inherit the port hold of the reservation.
 	portReserved := framework.HostPortInfo{}
 	for _, rInfo := range rOnNode {
 		for ip, protocolPortMap := range rInfo.Port {
@@ -277,9 +312,11 @@ func prepareFilterNodeInfo(pod *corev1.Pod, nodeInfo *framework.NodeInfo, rOnNod
 			}
 		}
 	}
+	portUsedByPods := getPortsUsedByNonReservePods(nodeInfo)
 	for _, container := range pod.Spec.Containers {
 		for _, podPort := range container.Ports {
-			if portReserved.CheckConflict(podPort.HostIP, string(podPort.Protocol), podPort.HostPort) {
+			if portReserved.CheckConflict(podPort.HostIP, string(podPort.Protocol), podPort.HostPort) &&
+				!portUsedByPods.CheckConflict(podPort.HostIP, string(podPort.Protocol), podPort.HostPort) {
 				newNodeInfo.UsedPorts.Remove(podPort.HostIP, string(podPort.Protocol), podPort.HostPort)
 			}
 		}
@@ -287,3 +324,21 @@ func prepareFilterNodeInfo(pod *corev1.Pod, nodeInfo *framework.NodeInfo, rOnNod
 
 	return newNodeInfo
 }
+
+// getPortsUsedByNonReservePods returns the host ports used by the pods on the node except the reserve pods.
+func getPortsUsedByNonReservePods(nodeInfo *framework.NodeInfo) framework.HostPortInfo {
+	portInfo := framework.HostPortInfo{}
+	for _, podInfo := range nodeInfo.Pods {
+		if podInfo.Pod == nil || reservationutil.IsReservePod(podInfo.Pod) {
+			continue
+		}
+		for _, container := range podInfo.Pod.Spec.Containers {
+			for _, podPort := range container.Ports {
+				if podPort.HostPort > 0 {
+					portInfo.Add(podPort.HostIP, string(podPort.Protocol), podPort.HostPort)
+				}
+			}
+		}
+	}
+	return portInfo
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -142,9 +143,10 @@ func TestPreFilterTransformer(t *testing.T) {
 			pod:          normalPod,
 			wantPod:      normalPod,
 			wantState: &stateData{
-				skip:               true,
-				matchedCache:       newAvailableCache(),
-				allocatedResources: map[string]corev1.ResourceList{},
+				skip:                  true,
+				matchedCache:          newAvailableCache(),
+				allocatedResources:    map[string]corev1.ResourceList{},
+				nodeAffinityUnmatched: map[string]bool{},
 			},
 			want1: true,
 		},
@@ -155,9 +157,10 @@ func TestPreFilterTransformer(t *testing.T) {
 			pod:          normalPod,
 			wantPod:      normalPod,
 			wantState: &stateData{
-				skip:               false,
-				matchedCache:       newAvailableCache(rScheduled),
-				allocatedResources: map[string]corev1.ResourceList{},
+				skip:                  false,
+				matchedCache:          newAvailableCache(rScheduled),
+				allocatedResources:    map[string]corev1.ResourceList{},
+				nodeAffinityUnmatched: map[string]bool{},
 			},
 			want1: true,
 		},
@@ -511,3 +514,82 @@ func Test_preparePreFilterPod(t *testing.T) {
 		assert.Nil(t, got.Spec.TopologySpreadConstraints)
 	})
 }
+
+func Test_prepareFilterNodeInfo_hostPorts(t *testing.T) {
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node-0",
+		},
+	}
+	newHostPortPod := func(name string, hostPort int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       types.UID(name),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: testNode.Name,
+				Containers: []corev1.Container{
+					{
+						Ports: []corev1.ContainerPort{
+							{HostPort: hostPort, ContainerPort: hostPort, Protocol: corev1.ProtocolTCP},
+						},
+					},
+				},
+			},
+		}
+	}
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reserve-pod-0",
+			UID:  "reserve-pod-0",
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: newHostPortPod("reserve-pod-0", 8080).Spec,
+			},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: testNode.Name,
+		},
+	}
+	reservePod := reservationutil.NewReservePod(r)
+	tests := []struct {
+		name          string
+		pods          []*corev1.Pod
+		pod           *corev1.Pod
+		wantConflicts bool
+	}{
+		{
+			name:          "inherit the port reserved",
+			pods:          []*corev1.Pod{reservePod},
+			pod:           newHostPortPod("owner-0", 8080),
+			wantConflicts: false,
+		},
+		{
+			name:          "the port reserved is already used by the current owner",
+			pods:          []*corev1.Pod{reservePod, newHostPortPod("owner-0", 8080)},
+			pod:           newHostPortPod("owner-1", 8080),
+			wantConflicts: true,
+		},
+		{
+			name:          "the port not reserved",
+			pods:          []*corev1.Pod{reservePod, newHostPortPod("other-0", 9090)},
+			pod:           newHostPortPod("owner-0", 9090),
+			wantConflicts: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodeInfo := framework.NewNodeInfo(tt.pods...)
+			nodeInfo.SetNode(testNode)
+			got := prepareFilterNodeInfo(tt.pod, nodeInfo, []*reservationInfo{newReservationInfo(r)}, nil)
+			podPort := tt.pod.Spec.Containers[0].Ports[0]
+			assert.Equal(t, tt.wantConflicts, got.UsedPorts.CheckConflict(podPort.HostIP, string(podPort.Protocol), podPort.HostPort))
+			// the original nodeInfo is not changed
+			assert.True(t, nodeInfo.UsedPorts.CheckConflict(podPort.HostIP, string(podPort.Protocol), podPort.HostPort))
+		})
+	}
+}
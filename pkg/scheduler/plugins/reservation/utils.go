@@ -25,10 +25,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	listercorev1 "k8s.io/client-go/listers/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	resourceapi "k8s.io/kubernetes/pkg/api/v1/resource"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
 )
@@ -38,13 +41,9 @@ func isReservationNeedExpiration(r *schedulingv1alpha1.Reservation) bool {
 	if r.Status.Phase == schedulingv1alpha1.ReservationFailed || r.Status.Phase == schedulingv1alpha1.ReservationSucceeded {
 		return false
 	}
-	// 2. disable expiration if TTL is set as 0
-	if r.Spec.TTL != nil && r.Spec.TTL.Duration == 0 {
-		return false
-	}
-	// 3. if both TTL and Expires are set, firstly check Expires
-	return r.Spec.Expires != nil && time.Now().After(r.Spec.Expires.Time) ||
-		r.Spec.TTL != nil && time.Since(r.CreationTimestamp.Time) > r.Spec.TTL.Duration
+	// 2. expire at the earlier one of Expires and the TTL counted from the last renewal, and TTL 0 disables expiration
+	expirationTime := reservationutil.GetReservationExpirationTime(r)
+	return expirationTime != nil && time.Now().After(*expirationTime)
 }
 
 func isReservationNeedCleanup(r *schedulingv1alpha1.Reservation) bool {
@@ -69,8 +68,10 @@ func isReservationNeedCleanup(r *schedulingv1alpha1.Reservation) bool {
 
 func setReservationAvailable(r *schedulingv1alpha1.Reservation, nodeName string) {
 	r.Status.NodeName = nodeName
+	r.Status.NominatedNodeName = ""
 	r.Status.Phase = schedulingv1alpha1.ReservationAvailable
 	r.Status.CurrentOwners = make([]corev1.ObjectReference, 0)
+	r.Status.AllocatedPods = nil
 
 	requests := getReservationRequests(r)
 	r.Status.Allocatable = requests
@@ -95,41 +96,6 @@ func setReservationAvailable(r *schedulingv1alpha1.Reservation, nodeName string)
 	}
 }
 
-func setReservationExpired(r *schedulingv1alpha1.Reservation) {
-	r.Status.Phase = schedulingv1alpha1.ReservationFailed
-	// not duplicate expired info
-	idx := -1
-	isReady := false
-	for i, condition := range r.Status.Conditions {
-		if condition.Type == schedulingv1alpha1.ReservationConditionReady {
-			idx = i
-			isReady = condition.Status == schedulingv1alpha1.ConditionStatusTrue
-		}
-	}
-	if idx < 0 { // if not set condition
-		condition := schedulingv1alpha1.ReservationCondition{
-			Type:               schedulingv1alpha1.ReservationConditionReady,
-			Status:             schedulingv1alpha1.ConditionStatusFalse,
-			Reason:             schedulingv1alpha1.ReasonReservationExpired,
-			LastProbeTime:      metav1.Now(),
-			LastTransitionTime: metav1.Now(),
-		}
-		r.Status.Conditions = append(r.Status.Conditions, condition)
-	} else if isReady { // if was ready
-		condition := schedulingv1alpha1.ReservationCondition{
-			Type:               schedulingv1alpha1.ReservationConditionReady,
-			Status:             schedulingv1alpha1.ConditionStatusFalse,
-			Reason:             schedulingv1alpha1.ReasonReservationExpired,
-			LastProbeTime:      metav1.Now(),
-			LastTransitionTime: metav1.Now(),
-		}
-		r.Status.Conditions[idx] = condition
-	} else { // if already not ready
-		r.Status.Conditions[idx].Reason = schedulingv1alpha1.ReasonReservationExpired
-		r.Status.Conditions[idx].LastProbeTime = metav1.Now()
-	}
-}
-
 func setReservationAllocated(r *schedulingv1alpha1.Reservation, pod *corev1.Pod) {
 	owner := getPodOwner(pod)
 	requests, _ := resourceapi.PodRequestsAndLimits(pod)
@@ -148,15 +114,100 @@ func setReservationAllocated(r *schedulingv1alpha1.Reservation, pod *corev1.Pod)
 		} else {
 			r.Status.Allocated = quotav1.Add(r.Status.Allocated, requests)
 		}
+		setReservationAllocatedPod(r, pod, requests)
 	} else {
 		// keep old allocated
 		r.Status.CurrentOwners[idx] = owner
+		setReservationAllocatedPod(r, pod, nil)
 	}
 	if r.Spec.AllocateOnce {
 		setReservationSucceeded(r)
 	}
 }
 
+// setReservationAllocatedPod records the pod in the allocated pods of the reservation. The resources of a recorded pod
+// are kept if the requests are nil.
+func setReservationAllocatedPod(r *schedulingv1alpha1.Reservation, pod *corev1.Pod, requests corev1.ResourceList) {
+	for i := range r.Status.AllocatedPods {
+		allocatedPod := &r.Status.AllocatedPods[i]
+		if matchAllocatedPod(pod, allocatedPod) {
+			allocatedPod.UID = pod.UID
+			if requests != nil {
+				allocatedPod.Resources = requests.DeepCopy()
+			}
+			return
+		}
+	}
+	r.Status.AllocatedPods = append(r.Status.AllocatedPods, schedulingv1alpha1.ReservationAllocatedPod{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		UID:       pod.UID,
+		Resources: requests.DeepCopy(),
+	})
+}
+
+func removeReservationAllocatedPod(r *schedulingv1alpha1.Reservation, pod *corev1.Pod) {
+	for i := range r.Status.AllocatedPods {
+		if matchAllocatedPod(pod, &r.Status.AllocatedPods[i]) {
+			r.Status.AllocatedPods = append(r.Status.AllocatedPods[:i], r.Status.AllocatedPods[i+1:]...)
+			return
+		}
+	}
+}
+
+func matchAllocatedPod(pod *corev1.Pod, allocatedPod *schedulingv1alpha1.ReservationAllocatedPod) bool {
+	return (len(allocatedPod.UID) <= 0 || pod.UID == allocatedPod.UID) &&
+		pod.Name == allocatedPod.Name && pod.Namespace == allocatedPod.Namespace
+}
+
+// restockReservation returns the unallocated remainder of a partially allocated reservation to the node by adding
+// it to the restocked resources. It returns true if the restocked resources are changed.
+func restockReservation(r *schedulingv1alpha1.Reservation) bool {
+	remainder := getRestockRemainder(r)
+	if len(remainder) <= 0 {
+		return false
+	}
+	r.Status.Restocked = quotav1.Add(r.Status.Restocked, remainder)
+	return true
+}
+
+// getRestockRemainder returns the unallocated remainder of the reservation to restock, or nil if the reservation
+// does not need restocking.
+func getRestockRemainder(r *schedulingv1alpha1.Reservation) corev1.ResourceList {
+	if !r.Spec.Restock || r.Spec.AllocateOnce || !reservationutil.IsReservationAvailable(r) ||
+		len(r.Status.CurrentOwners) <= 0 {
+		return nil
+	}
+	remainder := quotav1.Subtract(quotav1.Subtract(r.Status.Allocatable, r.Status.Allocated), r.Status.Restocked)
+	remainder = quotav1.Mask(remainder, quotav1.ResourceNames(r.Status.Allocatable))
+	for resourceName, q := range remainder {
+		if q.Sign() <= 0 {
+			delete(remainder, resourceName)
+		}
+	}
+	if len(remainder) <= 0 {
+		return nil
+	}
+	return remainder
+}
+
+// setReservationPreempted records the node nominated by preemption and appends the victims to the preempted pods.
+func setReservationPreempted(r *schedulingv1alpha1.Reservation, nominatedNodeName string, victims []*corev1.Pod) {
+	r.Status.NominatedNodeName = nominatedNodeName
+	for _, victim := range victims {
+		found := false
+		for i := range r.Status.PreemptedPods {
+			if matchObjectRef(victim, &r.Status.PreemptedPods[i]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Status.PreemptedPods = append(r.Status.PreemptedPods, getPodOwner(victim))
+		}
+	}
+}
+
 func setReservationSucceeded(r *schedulingv1alpha1.Reservation) {
 	r.Status.Phase = schedulingv1alpha1.ReservationSucceeded
 	idx := -1
@@ -193,6 +244,7 @@ func removeReservationAllocated(r *schedulingv1alpha1.Reservation, pod *corev1.P
 		return fmt.Errorf("current owner not matched")
 	}
 	r.Status.CurrentOwners = append(r.Status.CurrentOwners[:idx], r.Status.CurrentOwners[idx+1:]...)
+	removeReservationAllocatedPod(r, pod)
 
 	// decrease resources allocated
 	requests, _ := resourceapi.PodRequestsAndLimits(pod)
@@ -239,6 +291,23 @@ func matchReservation(pod *corev1.Pod, rMeta *reservationInfo) bool {
 		matchReservationPort(pod, rMeta)
 }
 
+// matchReservationAffinity checks if the reservation satisfies the reservation affinity of the scheduling pod.
+func matchReservationAffinity(affinity *apiext.ReservationAffinity, r *schedulingv1alpha1.Reservation) bool {
+	if affinity == nil {
+		return true
+	}
+	if len(affinity.ReservationName) > 0 && affinity.ReservationName != r.Name {
+		return false
+	}
+	if affinity.LabelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(affinity.LabelSelector)
+		if err != nil || !selector.Matches(labels.Set(r.Labels)) {
+			return false
+		}
+	}
+	return true
+}
+
 func matchReservationPort(pod *corev1.Pod, rMeta *reservationInfo) bool {
 	for _, container := range pod.Spec.Containers {
 		for _, podPort := range container.Ports {
@@ -255,8 +324,22 @@ func matchReservationResources(pod *corev1.Pod, r *schedulingv1alpha1.Reservatio
 		// multi owners can share one reservation when reserved resources are sufficient
 		reservedResources = quotav1.Subtract(reservedResources, r.Status.Allocated)
 	}
+	if r.Status.Restocked != nil {
+		// restocked resources have been returned to the node
+		reservedResources = quotav1.Subtract(reservedResources, r.Status.Restocked)
+	}
 	reservedResources = quotav1.Mask(reservedResources, quotav1.ResourceNames(r.Status.Allocatable))
 	podRequests, _ := resourceapi.PodRequestsAndLimits(pod)
+	if r.Spec.AllocatePolicy == schedulingv1alpha1.ReservationAllocatePolicyExactMatch {
+		// not match if any pod request of the reserved resources is not equal to the reserved
+		for resource, q := range reservedResources {
+			quantity := podRequests[resource]
+			if quantity.Cmp(q) != 0 {
+				return false
+			}
+		}
+		return true
+	}
 	for resource, quantity := range podRequests {
 		q, ok := reservedResources[resource]
 		if ok && quantity.Cmp(q) > 0 {
@@ -272,17 +355,65 @@ func matchReservationResources(pod *corev1.Pod, r *schedulingv1alpha1.Reservatio
 // (extended), LabelSelector, which means multiple selectors are firstly ANDed and secondly ORed.
 func matchReservationOwners(pod *corev1.Pod, r *schedulingv1alpha1.Reservation) bool {
 	// assert pod != nil && r != nil
+	// capacity-only reservations have no owners, and are matched by the tolerations instead
+	if r.Spec.CapacityOnly {
+		return matchReservationTolerations(pod, r)
+	}
 	// Owners == nil matches nothing, while Owners = [{}] matches everything
-	for _, owner := range r.Spec.Owners {
-		if matchObjectRef(pod, owner.Object) &&
-			matchReservationControllerReference(pod, owner.Controller) &&
-			matchLabelSelector(pod, owner.LabelSelector) {
+	for i := range r.Spec.Owners {
+		if matchReservationOwner(pod, &r.Spec.Owners[i]) {
 			return true
 		}
 	}
 	return false
 }
 
+func matchReservationOwner(pod *corev1.Pod, owner *schedulingv1alpha1.ReservationOwner) bool {
+	return matchObjectRef(pod, owner.Object) &&
+		matchReservationControllerReference(pod, owner.Controller) &&
+		matchLabelSelector(pod, owner.LabelSelector)
+}
+
+// matchReservationOwnerNodeAffinity checks if the reservation's node satisfies the node affinity of any owner which
+// matches the scheduling pod. The node affinity is evaluated when the pod consumes the reservation, so the
+// reservation is rejected if the node labels are changed after the reservation is scheduled.
+func matchReservationOwnerNodeAffinity(pod *corev1.Pod, r *schedulingv1alpha1.Reservation, node *corev1.Node) bool {
+	// capacity-only reservations have no owners
+	if r.Spec.CapacityOnly {
+		return true
+	}
+	for i := range r.Spec.Owners {
+		owner := &r.Spec.Owners[i]
+		if matchReservationOwner(pod, owner) && matchNodeAffinity(node, owner.NodeAffinity) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchNodeAffinity(node *corev1.Node, nodeAffinity *corev1.NodeSelector) bool {
+	if nodeAffinity == nil {
+		return true
+	}
+	matched, err := corev1helpers.MatchNodeSelectorTerms(node, nodeAffinity)
+	return err == nil && matched
+}
+
+// matchReservationTolerations checks if the scheduling pod tolerates all taints of the capacity-only reservation.
+// The pods without the reservation tolerations annotation never match.
+func matchReservationTolerations(pod *corev1.Pod, r *schedulingv1alpha1.Reservation) bool {
+	tolerations, err := apiext.GetReservationTolerations(pod.Annotations)
+	if err != nil || tolerations == nil {
+		return false
+	}
+	for i := range r.Spec.Taints {
+		if !corev1helpers.TolerationsTolerateTaint(tolerations, &r.Spec.Taints[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func matchObjectRef(pod *corev1.Pod, objRef *corev1.ObjectReference) bool {
 	// `ResourceVersion`, `FieldPath` are ignored.
 	// since only pod type are compared, `Kind` field is also ignored.
@@ -315,6 +446,25 @@ func matchReservationControllerReference(pod *corev1.Pod, controllerRef *schedul
 	return false
 }
 
+// isReservationHeldForMigration checks if the reservation is held for the replacement of the migrating pod, which is
+// still running. The reservation is released once the migrating pod is evicted, while the migrating pod itself can
+// allocate the reservation anytime.
+func isReservationHeldForMigration(podLister listercorev1.PodLister, pod *corev1.Pod, r *schedulingv1alpha1.Reservation) bool {
+	reservedForMigration, err := apiext.GetReservedForMigration(r.Annotations)
+	if err != nil {
+		klog.V(4).InfoS("failed to parse the migrating pod of reservation", "reservation", klog.KObj(r), "err", err)
+		return false
+	}
+	if reservedForMigration == nil || reservedForMigration.UID == pod.UID {
+		return false
+	}
+	migratingPod, err := podLister.Pods(reservedForMigration.Namespace).Get(reservedForMigration.Name)
+	if err != nil {
+		return false
+	}
+	return migratingPod.UID == reservedForMigration.UID && migratingPod.DeletionTimestamp == nil
+}
+
 func dumpMatchReservationReason(pod *corev1.Pod, rMeta *reservationInfo) string {
 	var msg strings.Builder
 	if !matchReservationOwners(pod, rMeta.Reservation) {
@@ -364,3 +514,43 @@ func getPreFilterState(cycleState *framework.CycleState) *stateData {
 	}
 	return cache
 }
+
+// GetMatchedReservationsOnNode returns the reservations on the node matched by the scheduling pod in the cycle.
+// It is used by other plugins to take the resources held by the reservations into account, e.g. the devices.
+func GetMatchedReservationsOnNode(cycleState *framework.CycleState, nodeName string) []*schedulingv1alpha1.Reservation {
+	state := getPreFilterState(cycleState)
+	if state == nil || state.skip || state.matchedCache == nil {
+		return nil
+	}
+	rOnNode := state.matchedCache.GetOnNode(nodeName)
+	reservations := make([]*schedulingv1alpha1.Reservation, 0, len(rOnNode))
+	for _, rInfo := range rOnNode {
+		reservations = append(reservations, rInfo.GetReservation())
+	}
+	return reservations
+}
+
+// GetMatchedReservations returns the reservations on all the nodes matched by the scheduling pod in the cycle.
+func GetMatchedReservations(cycleState *framework.CycleState) []*schedulingv1alpha1.Reservation {
+	state := getPreFilterState(cycleState)
+	if state == nil || state.skip || state.matchedCache == nil {
+		return nil
+	}
+	state.matchedCache.lock.RLock()
+	defer state.matchedCache.lock.RUnlock()
+	reservations := make([]*schedulingv1alpha1.Reservation, 0, len(state.matchedCache.reservations))
+	for _, rInfo := range state.matchedCache.reservations {
+		reservations = append(reservations, rInfo.GetReservation())
+	}
+	return reservations
+}
+
+// GetAssumedReservation returns the reservation assumed to be allocated by the scheduling pod in the cycle.
+// NOTE: It is only valid after the Reserve of the Reservation plugin.
+func GetAssumedReservation(cycleState *framework.CycleState) *schedulingv1alpha1.Reservation {
+	state := getPreFilterState(cycleState)
+	if state == nil || state.skip {
+		return nil
+	}
+	return state.assumed
+}
@@ -23,9 +23,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	listercorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/utils/pointer"
 
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 )
 
@@ -418,6 +422,96 @@ func Test_matchReservationOwners(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "capacity-only reservation does not match pod without tolerations",
+			args: args{
+				pod: &corev1.Pod{},
+				r: &schedulingv1alpha1.Reservation{
+					Spec: schedulingv1alpha1.ReservationSpec{
+						CapacityOnly: true,
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "capacity-only reservation without taints matches pod with empty tolerations",
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							apiext.AnnotationReservationTolerations: `[]`,
+						},
+					},
+				},
+				r: &schedulingv1alpha1.Reservation{
+					Spec: schedulingv1alpha1.ReservationSpec{
+						CapacityOnly: true,
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "capacity-only reservation matches pod tolerating the taints",
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							apiext.AnnotationReservationTolerations: `[{"key":"pool","operator":"Equal","value":"gpu"}]`,
+						},
+					},
+				},
+				r: &schedulingv1alpha1.Reservation{
+					Spec: schedulingv1alpha1.ReservationSpec{
+						CapacityOnly: true,
+						Taints: []corev1.Taint{
+							{Key: "pool", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "capacity-only reservation does not match pod not tolerating the taints",
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							apiext.AnnotationReservationTolerations: `[{"key":"pool","operator":"Equal","value":"cpu"}]`,
+						},
+					},
+				},
+				r: &schedulingv1alpha1.Reservation{
+					Spec: schedulingv1alpha1.ReservationSpec{
+						CapacityOnly: true,
+						Taints: []corev1.Taint{
+							{Key: "pool", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+						},
+					},
+				},
+			},
+			want: false,
+		},
+		{
+			name: "capacity-only reservation does not match pod with invalid tolerations",
+			args: args{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							apiext.AnnotationReservationTolerations: `invalid`,
+						},
+					},
+				},
+				r: &schedulingv1alpha1.Reservation{
+					Spec: schedulingv1alpha1.ReservationSpec{
+						CapacityOnly: true,
+					},
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -427,13 +521,254 @@ func Test_matchReservationOwners(t *testing.T) {
 	}
 }
 
-func Test_matchReservationResources(t *testing.T) {
+func Test_matchReservationAffinity(t *testing.T) {
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "reservation-0",
+			Labels: map[string]string{
+				"app": "test",
+			},
+		},
+	}
+	tests := []struct {
+		name     string
+		affinity *apiext.ReservationAffinity
+		want     bool
+	}{
+		{
+			name: "no affinity",
+			want: true,
+		},
+		{
+			name:     "match name",
+			affinity: &apiext.ReservationAffinity{ReservationName: "reservation-0"},
+			want:     true,
+		},
+		{
+			name:     "name not matched",
+			affinity: &apiext.ReservationAffinity{ReservationName: "reservation-1"},
+			want:     false,
+		},
+		{
+			name: "match labels",
+			affinity: &apiext.ReservationAffinity{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+			},
+			want: true,
+		},
+		{
+			name: "labels not matched",
+			affinity: &apiext.ReservationAffinity{
+				ReservationName: "reservation-0",
+				LabelSelector:   &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchReservationAffinity(tt.affinity, r))
+		})
+	}
+}
+
+func Test_isReservationHeldForMigration(t *testing.T) {
+	migratingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod", UID: "123456"},
+	}
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-reservation"},
+	}
+	assert.NoError(t, apiext.SetReservedForMigration(r, &apiext.ReservedForMigration{
+		Namespace: migratingPod.Namespace,
+		Name:      migratingPod.Name,
+		UID:       migratingPod.UID,
+		JobName:   "test-job",
+	}))
+	replacementPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pod-new", UID: "abcdef"},
+	}
+	terminatingPod := migratingPod.DeepCopy()
+	terminatingPod.DeletionTimestamp = &metav1.Time{}
+	recreatedPod := migratingPod.DeepCopy()
+	recreatedPod.UID = "654321"
+
 	tests := []struct {
 		name        string
-		requests    corev1.ResourceList
-		allocatable corev1.ResourceList
-		allocated   corev1.ResourceList
+		existingPod *corev1.Pod
+		pod         *corev1.Pod
+		r           *schedulingv1alpha1.Reservation
 		want        bool
+	}{
+		{
+			name:        "not reserved for migration",
+			existingPod: migratingPod,
+			pod:         replacementPod,
+			r:           &schedulingv1alpha1.Reservation{},
+			want:        false,
+		},
+		{
+			name:        "held until the migrating pod is evicted",
+			existingPod: migratingPod,
+			pod:         replacementPod,
+			r:           r,
+			want:        true,
+		},
+		{
+			name:        "the migrating pod can allocate",
+			existingPod: migratingPod,
+			pod:         migratingPod,
+			r:           r,
+			want:        false,
+		},
+		{
+			name:        "the migrating pod is terminating",
+			existingPod: terminatingPod,
+			pod:         replacementPod,
+			r:           r,
+			want:        false,
+		},
+		{
+			name:        "the migrating pod is recreated with the same name",
+			existingPod: recreatedPod,
+			pod:         recreatedPod,
+			r:           r,
+			want:        false,
+		},
+		{
+			name: "the migrating pod is deleted",
+			pod:  replacementPod,
+			r:    r,
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tt.existingPod != nil {
+				assert.NoError(t, indexer.Add(tt.existingPod))
+			}
+			got := isReservationHeldForMigration(listercorev1.NewPodLister(indexer), tt.pod, tt.r)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_matchReservationOwnerNodeAffinity(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-0",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app": "test",
+			},
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-0",
+			Labels: map[string]string{
+				"pool": "online",
+			},
+		},
+	}
+	nodeAffinity := func(pool string) *corev1.NodeSelector {
+		return &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      "pool",
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{pool},
+						},
+					},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name   string
+		owners []schedulingv1alpha1.ReservationOwner
+		want   bool
+	}{
+		{
+			name: "owner without node affinity",
+			owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				},
+			},
+			want: true,
+		},
+		{
+			name: "node matches owner node affinity",
+			owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					NodeAffinity:  nodeAffinity("online"),
+				},
+			},
+			want: true,
+		},
+		{
+			name: "node labels changed and not match owner node affinity",
+			owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					NodeAffinity:  nodeAffinity("offline"),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "ignore node affinity of owner not matching the pod",
+			owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+				},
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					NodeAffinity:  nodeAffinity("offline"),
+				},
+			},
+			want: false,
+		},
+		{
+			name: "node matches node affinity of another matched owner",
+			owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					NodeAffinity:  nodeAffinity("offline"),
+				},
+				{
+					Object: &corev1.ObjectReference{Name: "pod-0"},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &schedulingv1alpha1.Reservation{
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Owners: tt.owners,
+				},
+			}
+			assert.Equal(t, tt.want, matchReservationOwnerNodeAffinity(pod, r, node))
+		})
+	}
+}
+
+func Test_matchReservationResources(t *testing.T) {
+	tests := []struct {
+		name           string
+		requests       corev1.ResourceList
+		allocatable    corev1.ResourceList
+		allocated      corev1.ResourceList
+		restocked      corev1.ResourceList
+		allocatePolicy schedulingv1alpha1.ReservationAllocatePolicy
+		want           bool
 	}{
 		{
 			name: "full matched",
@@ -501,6 +836,105 @@ func Test_matchReservationResources(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "exactly matched",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatePolicy: schedulingv1alpha1.ReservationAllocatePolicyExactMatch,
+			want:           true,
+		},
+		{
+			name: "smaller requests not exactly matched",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatePolicy: schedulingv1alpha1.ReservationAllocatePolicyExactMatch,
+			want:           false,
+		},
+		{
+			name: "missing requests not exactly matched",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatePolicy: schedulingv1alpha1.ReservationAllocatePolicyExactMatch,
+			want:           false,
+		},
+		{
+			name: "exactly matched with the unreserved requests ignored",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			allocatePolicy: schedulingv1alpha1.ReservationAllocatePolicyExactMatch,
+			want:           true,
+		},
+		{
+			name: "exactly matched with allocated",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			allocatePolicy: schedulingv1alpha1.ReservationAllocatePolicyExactMatch,
+			want:           false,
+		},
+		{
+			name: "failed to match restocked resources",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("2"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			restocked: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("3"),
+			},
+			want: false,
+		},
+		{
+			name: "match the remainder not restocked",
+			requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			restocked: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("2"),
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -517,9 +951,13 @@ func Test_matchReservationResources(t *testing.T) {
 				},
 			}
 			reeservation := &schedulingv1alpha1.Reservation{
+				Spec: schedulingv1alpha1.ReservationSpec{
+					AllocatePolicy: tt.allocatePolicy,
+				},
 				Status: schedulingv1alpha1.ReservationStatus{
 					Allocatable: tt.allocatable,
 					Allocated:   tt.allocated,
+					Restocked:   tt.restocked,
 				},
 			}
 			got := matchReservationResources(pod, reeservation, tt.allocatable)
@@ -591,6 +1029,17 @@ func Test_setReservationAllocated(t *testing.T) {
 							Name:      "test",
 						},
 					},
+					AllocatedPods: []schedulingv1alpha1.ReservationAllocatedPod{
+						{
+							Namespace: "test-ns",
+							Name:      "test",
+							UID:       "1234567890",
+							Resources: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("1"),
+								corev1.ResourceMemory: resource.MustParse("1Gi"),
+							},
+						},
+					},
 				},
 			},
 		},
@@ -635,6 +1084,16 @@ func Test_setReservationAllocated(t *testing.T) {
 							Name:      "test",
 						},
 					},
+					AllocatedPods: []schedulingv1alpha1.ReservationAllocatedPod{
+						{
+							Namespace: "test-ns",
+							Name:      "test",
+							UID:       "1234567890",
+							Resources: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse("1"),
+							},
+						},
+					},
 				},
 			},
 		},
@@ -646,3 +1105,230 @@ func Test_setReservationAllocated(t *testing.T) {
 		})
 	}
 }
+
+func Test_removeReservationAllocated(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       "1234567890",
+			Namespace: "test-ns",
+			Name:      "test",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+	otherPod := pod.DeepCopy()
+	otherPod.UID = "0987654321"
+	otherPod.Name = "test-1"
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-reservation",
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase: schedulingv1alpha1.ReservationAvailable,
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"),
+			},
+		},
+	}
+	setReservationAllocated(r, pod)
+	setReservationAllocated(r, otherPod)
+	assert.Len(t, r.Status.AllocatedPods, 2)
+
+	err := removeReservationAllocated(r, pod)
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.ObjectReference{getPodOwner(otherPod)}, r.Status.CurrentOwners)
+	assert.Equal(t, []schedulingv1alpha1.ReservationAllocatedPod{
+		{
+			Namespace: "test-ns",
+			Name:      "test-1",
+			UID:       "0987654321",
+			Resources: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+		},
+	}, r.Status.AllocatedPods)
+
+	err = removeReservationAllocated(r, pod)
+	assert.Error(t, err)
+	assert.Len(t, r.Status.AllocatedPods, 1)
+}
+
+func Test_restockReservation(t *testing.T) {
+	owner := corev1.ObjectReference{Namespace: "default", Name: "test-pod-1"}
+	tests := []struct {
+		name          string
+		restock       bool
+		allocateOnce  bool
+		phase         schedulingv1alpha1.ReservationPhase
+		owners        []corev1.ObjectReference
+		allocated     corev1.ResourceList
+		restocked     corev1.ResourceList
+		want          bool
+		wantRestocked corev1.ResourceList
+	}{
+		{
+			name:   "restock not enabled",
+			phase:  schedulingv1alpha1.ReservationAvailable,
+			owners: []corev1.ObjectReference{owner},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			want: false,
+		},
+		{
+			name:         "skip allocate once reservation",
+			restock:      true,
+			allocateOnce: true,
+			phase:        schedulingv1alpha1.ReservationAvailable,
+			owners:       []corev1.ObjectReference{owner},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			want: false,
+		},
+		{
+			name:    "skip reservation without owners",
+			restock: true,
+			phase:   schedulingv1alpha1.ReservationAvailable,
+			want:    false,
+		},
+		{
+			name:    "skip unavailable reservation",
+			restock: true,
+			phase:   schedulingv1alpha1.ReservationSucceeded,
+			owners:  []corev1.ObjectReference{owner},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			want: false,
+		},
+		{
+			name:    "restock the unallocated remainder",
+			restock: true,
+			phase:   schedulingv1alpha1.ReservationAvailable,
+			owners:  []corev1.ObjectReference{owner},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+			want: true,
+			wantRestocked: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		{
+			name:    "restock the remainder released by owners",
+			restock: true,
+			phase:   schedulingv1alpha1.ReservationAvailable,
+			owners:  []corev1.ObjectReference{owner},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			restocked: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+			want: true,
+			wantRestocked: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3"),
+				corev1.ResourceMemory: resource.MustParse("3Gi"),
+			},
+		},
+		{
+			name:    "nothing to restock",
+			restock: true,
+			phase:   schedulingv1alpha1.ReservationAvailable,
+			owners:  []corev1.ObjectReference{owner},
+			allocated: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			restocked: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3"),
+				corev1.ResourceMemory: resource.MustParse("3Gi"),
+			},
+			want: false,
+			wantRestocked: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("3"),
+				corev1.ResourceMemory: resource.MustParse("3Gi"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &schedulingv1alpha1.Reservation{
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Restock:      tt.restock,
+					AllocateOnce: tt.allocateOnce,
+				},
+				Status: schedulingv1alpha1.ReservationStatus{
+					Phase:    tt.phase,
+					NodeName: "test-node",
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+					CurrentOwners: tt.owners,
+					Allocated:     tt.allocated,
+					Restocked:     tt.restocked,
+				},
+			}
+			got := restockReservation(r)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, quotav1.Equals(tt.wantRestocked, r.Status.Restocked), r.Status.Restocked)
+		})
+	}
+}
+
+func TestGetMatchedAndAssumedReservation(t *testing.T) {
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-reservation",
+			UID:               "test-reservation-uid",
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+		},
+		Status: schedulingv1alpha1.ReservationStatus{
+			Phase:    schedulingv1alpha1.ReservationAvailable,
+			NodeName: "test-node",
+		},
+	}
+	matchedCache := newAvailableCache()
+	matchedCache.Add(r)
+
+	cycleState := framework.NewCycleState()
+	assert.Nil(t, GetMatchedReservationsOnNode(cycleState, "test-node"))
+	assert.Nil(t, GetAssumedReservation(cycleState))
+	assert.Nil(t, GetMatchedReservations(cycleState))
+
+	cycleState.Write(preFilterStateKey, &stateData{
+		matchedCache: matchedCache,
+		assumed:      r,
+	})
+	assert.Equal(t, []*schedulingv1alpha1.Reservation{r}, GetMatchedReservationsOnNode(cycleState, "test-node"))
+	assert.Empty(t, GetMatchedReservationsOnNode(cycleState, "other-node"))
+	assert.Equal(t, r, GetAssumedReservation(cycleState))
+	assert.Equal(t, []*schedulingv1alpha1.Reservation{r}, GetMatchedReservations(cycleState))
+
+	skipState := framework.NewCycleState()
+	skipState.Write(preFilterStateKey, &stateData{
+		skip:         true,
+		matchedCache: matchedCache,
+		assumed:      r,
+	})
+	assert.Nil(t, GetMatchedReservationsOnNode(skipState, "test-node"))
+	assert.Nil(t, GetAssumedReservation(skipState))
+	assert.Nil(t, GetMatchedReservations(skipState))
+}
@@ -84,6 +84,8 @@ func IsColocationStrategyValid(strategy *extension.ColocationStrategy) bool {
 		(strategy.MetricReportIntervalSeconds == nil || *strategy.MetricReportIntervalSeconds > 0) &&
 		(strategy.CPUReclaimThresholdPercent == nil || *strategy.CPUReclaimThresholdPercent > 0) &&
 		(strategy.MemoryReclaimThresholdPercent == nil || *strategy.MemoryReclaimThresholdPercent > 0) &&
+		(strategy.GPUReclaimThresholdPercent == nil || *strategy.GPUReclaimThresholdPercent > 0) &&
+		(strategy.GPUCoreOversellPercent == nil || *strategy.GPUCoreOversellPercent == 0 || *strategy.GPUCoreOversellPercent >= 100) &&
 		(strategy.DegradeTimeMinutes == nil || *strategy.DegradeTimeMinutes > 0) &&
 		(strategy.UpdateTimeThresholdSeconds == nil || *strategy.UpdateTimeThresholdSeconds > 0) &&
 		(strategy.ResourceDiffThreshold == nil || *strategy.ResourceDiffThreshold > 0)
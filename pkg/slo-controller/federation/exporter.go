@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
+)
+
+const (
+	// CapacityPath is the path of the capacity endpoint served on the metrics server.
+	CapacityPath = "/federation/capacity"
+	// CapacityConfigMapKey is the key of the capacity in the published configmap.
+	CapacityConfigMapKey = "capacity"
+)
+
+var (
+	// ClusterName is the name of the member cluster reported in the capacity.
+	ClusterName = ""
+	// ExportInterval is the interval to aggregate and publish the capacity.
+	ExportInterval = 30 * time.Second
+	// CapacityConfigMap is the name of the configmap the capacity published to. Skip publishing if empty.
+	CapacityConfigMap = "koord-cluster-capacity"
+)
+
+func InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&ClusterName, "federation-cluster-name", ClusterName, "determines the cluster name reported in the federation capacity.")
+	fs.DurationVar(&ExportInterval, "federation-export-interval", ExportInterval, "determines the interval to aggregate the federation capacity.")
+	fs.StringVar(&CapacityConfigMap, "federation-capacity-config-name", CapacityConfigMap, "determines the name of the configmap the federation capacity published to, skip publishing if empty.")
+}
+
+// Exporter aggregates the colocation capacity of the cluster periodically. The capacity is served on the metrics
+// server and published into a configmap in the config namespace, so it can be collected by the multi-cluster
+// schedulers via either the endpoint or the member cluster resources.
+type Exporter struct {
+	client      client.Client
+	clusterName string
+	interval    time.Duration
+	configMap   string
+
+	lock     sync.RWMutex
+	capacity *ClusterCapacity
+}
+
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,namespace=koordinator-system,resources=configmaps,verbs=get;list;watch;create;update
+
+func Add(mgr ctrl.Manager) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.FederationCapacityExporter) {
+		return nil
+	}
+	e := &Exporter{
+		client:      mgr.GetClient(),
+		clusterName: ClusterName,
+		interval:    ExportInterval,
+		configMap:   CapacityConfigMap,
+	}
+	if err := mgr.AddMetricsExtraHandler(CapacityPath, e); err != nil {
+		return err
+	}
+	return mgr.Add(e)
+}
+
+// NeedLeaderElection makes only the leader aggregate and publish the capacity.
+func (e *Exporter) NeedLeaderElection() bool {
+	return true
+}
+
+func (e *Exporter) Start(ctx context.Context) error {
+	klog.Infof("start federation capacity exporter, interval %v", e.interval)
+	wait.UntilWithContext(ctx, e.export, e.interval)
+	return nil
+}
+
+func (e *Exporter) export(ctx context.Context) {
+	capacity, err := e.aggregate(ctx)
+	if err != nil {
+		klog.Errorf("failed to aggregate federation capacity, err: %v", err)
+		return
+	}
+	e.lock.Lock()
+	e.capacity = capacity
+	e.lock.Unlock()
+
+	if len(e.configMap) <= 0 {
+		return
+	}
+	if err = e.publish(ctx, capacity); err != nil {
+		klog.Errorf("failed to publish federation capacity to configmap %s/%s, err: %v",
+			config.ConfigNameSpace, e.configMap, err)
+	}
+}
+
+func (e *Exporter) aggregate(ctx context.Context) (*ClusterCapacity, error) {
+	nodeList := &corev1.NodeList{}
+	if err := e.client.List(ctx, nodeList); err != nil {
+		return nil, fmt.Errorf("failed to list nodes, err: %w", err)
+	}
+	podList := &corev1.PodList{}
+	if err := e.client.List(ctx, podList); err != nil {
+		return nil, fmt.Errorf("failed to list pods, err: %w", err)
+	}
+
+	podRequested := map[string]corev1.ResourceList{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if len(pod.Spec.NodeName) <= 0 || util.IsPodTerminated(pod) {
+			continue
+		}
+		requests := util.GetPodRequest(pod, exportedResources...)
+		podRequested[pod.Spec.NodeName] = quotav1.Add(podRequested[pod.Spec.NodeName], requests)
+	}
+
+	capacity := &ClusterCapacity{
+		ClusterName: e.clusterName,
+		UpdateTime:  metav1.Now(),
+		Allocatable: corev1.ResourceList{},
+		Requested:   corev1.ResourceList{},
+		Free:        corev1.ResourceList{},
+	}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		allocatable := quotav1.Mask(node.Status.Allocatable, exportedResources)
+		requested := quotav1.Mask(podRequested[node.Name], quotav1.ResourceNames(allocatable))
+		capacity.NodeCount++
+		capacity.Allocatable = quotav1.Add(capacity.Allocatable, allocatable)
+		capacity.Requested = quotav1.Add(capacity.Requested, requested)
+		// the overcommitted resources of a node cannot be reclaimed by the other nodes
+		free := quotav1.Subtract(allocatable, requested)
+		for resourceName, q := range free {
+			if q.Sign() < 0 {
+				free[resourceName] = *resource.NewQuantity(0, q.Format)
+			}
+		}
+		capacity.Free = quotav1.Add(capacity.Free, free)
+	}
+	return capacity, nil
+}
+
+func (e *Exporter) publish(ctx context.Context, capacity *ClusterCapacity) error {
+	data, err := json.Marshal(capacity)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{}
+	err = e.client.Get(ctx, types.NamespacedName{Namespace: config.ConfigNameSpace, Name: e.configMap}, cm)
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: config.ConfigNameSpace,
+				Name:      e.configMap,
+			},
+			Data: map[string]string{CapacityConfigMapKey: string(data)},
+		}
+		return e.client.Create(ctx, cm)
+	} else if err != nil {
+		return err
+	}
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[CapacityConfigMapKey] = string(data)
+	return e.client.Update(ctx, cm)
+}
+
+func (e *Exporter) getCapacity() *ClusterCapacity {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.capacity
+}
+
+// ServeHTTP serves the latest capacity aggregated, it returns 503 if no capacity is aggregated yet, e.g. the
+// manager is not the leader.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	capacity := e.getCapacity()
+	if capacity == nil {
+		http.Error(w, "federation capacity is not ready", http.StatusServiceUnavailable)
+		return
+	}
+	data, err := json.Marshal(capacity)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
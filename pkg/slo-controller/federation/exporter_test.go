@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+)
+
+func TestExporter(t *testing.T) {
+	newNode := func(name string, unschedulable bool, allocatable corev1.ResourceList) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+			Status:     corev1.NodeStatus{Allocatable: allocatable},
+		}
+	}
+	newPod := func(name, nodeName string, phase corev1.PodPhase, requests corev1.ResourceList) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{
+					{Resources: corev1.ResourceRequirements{Requests: requests}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	objs := []runtime.Object{
+		newNode("node-0", false, corev1.ResourceList{
+			corev1.ResourceCPU:        resource.MustParse("32"),
+			extension.BatchCPU:        resource.MustParse("10000"),
+			extension.BatchMemory:     resource.MustParse("20Gi"),
+			extension.ResourceGPUCore: resource.MustParse("200"),
+		}),
+		newNode("node-1", false, corev1.ResourceList{
+			extension.BatchCPU:          resource.MustParse("2000"),
+			extension.BatchMemory:       resource.MustParse("4Gi"),
+			extension.ResourceNvidiaGPU: resource.MustParse("8"),
+		}),
+		newNode("node-2", true, corev1.ResourceList{
+			extension.BatchCPU:    resource.MustParse("8000"),
+			extension.BatchMemory: resource.MustParse("16Gi"),
+		}),
+		newPod("pod-0", "node-0", corev1.PodRunning, corev1.ResourceList{
+			corev1.ResourceCPU:        resource.MustParse("4"),
+			extension.BatchCPU:        resource.MustParse("4000"),
+			extension.BatchMemory:     resource.MustParse("8Gi"),
+			extension.ResourceGPUCore: resource.MustParse("50"),
+		}),
+		newPod("pod-1", "node-1", corev1.PodRunning, corev1.ResourceList{
+			extension.BatchCPU:          resource.MustParse("3000"),
+			extension.BatchMemory:       resource.MustParse("2Gi"),
+			extension.ResourceNvidiaGPU: resource.MustParse("2"),
+		}),
+		newPod("pod-2", "node-0", corev1.PodSucceeded, corev1.ResourceList{
+			extension.BatchCPU: resource.MustParse("6000"),
+		}),
+		newPod("pod-3", "", corev1.PodPending, corev1.ResourceList{
+			extension.BatchCPU: resource.MustParse("1000"),
+		}),
+	}
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+	e := &Exporter{
+		client:      c,
+		clusterName: "member-1",
+		configMap:   "koord-cluster-capacity",
+	}
+
+	// not ready before the first aggregation
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CapacityPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	e.export(context.TODO())
+
+	wantAllocatable := corev1.ResourceList{
+		extension.BatchCPU:          resource.MustParse("12000"),
+		extension.BatchMemory:       resource.MustParse("24Gi"),
+		extension.ResourceGPUCore:   resource.MustParse("200"),
+		extension.ResourceNvidiaGPU: resource.MustParse("8"),
+	}
+	wantRequested := corev1.ResourceList{
+		extension.BatchCPU:          resource.MustParse("7000"),
+		extension.BatchMemory:       resource.MustParse("10Gi"),
+		extension.ResourceGPUCore:   resource.MustParse("50"),
+		extension.ResourceNvidiaGPU: resource.MustParse("2"),
+	}
+	// the overcommitted batch-cpu of node-1 is not counted as negative
+	wantFree := corev1.ResourceList{
+		extension.BatchCPU:          resource.MustParse("6000"),
+		extension.BatchMemory:       resource.MustParse("14Gi"),
+		extension.ResourceGPUCore:   resource.MustParse("150"),
+		extension.ResourceNvidiaGPU: resource.MustParse("6"),
+	}
+	assertCapacity := func(got *ClusterCapacity) {
+		assert.Equal(t, "member-1", got.ClusterName)
+		assert.Equal(t, 2, got.NodeCount)
+		for _, tc := range []struct {
+			want, got corev1.ResourceList
+		}{
+			{wantAllocatable, got.Allocatable},
+			{wantRequested, got.Requested},
+			{wantFree, got.Free},
+		} {
+			assert.Equal(t, len(tc.want), len(tc.got))
+			for resourceName, q := range tc.want {
+				gotQ := tc.got[resourceName]
+				assert.Equal(t, 0, q.Cmp(gotQ), "resource %s, want %s, got %s", resourceName, q.String(), gotQ.String())
+			}
+		}
+	}
+	assertCapacity(e.getCapacity())
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CapacityPath, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	served := &ClusterCapacity{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), served))
+	assertCapacity(served)
+
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: config.ConfigNameSpace, Name: "koord-cluster-capacity"}, cm))
+	published := &ClusterCapacity{}
+	assert.NoError(t, json.Unmarshal([]byte(cm.Data[CapacityConfigMapKey]), published))
+	assertCapacity(published)
+
+	// update the published configmap
+	e.clusterName = "member-2"
+	e.export(context.TODO())
+	assert.NoError(t, c.Get(context.TODO(), types.NamespacedName{Namespace: config.ConfigNameSpace, Name: "koord-cluster-capacity"}, cm))
+	assert.NoError(t, json.Unmarshal([]byte(cm.Data[CapacityConfigMapKey]), published))
+	assert.Equal(t, "member-2", published.ClusterName)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package federation
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+// exportedResources are the colocation resources reclaimable for the cross-cluster batch placement.
+var exportedResources = []corev1.ResourceName{
+	extension.BatchCPU,
+	extension.BatchMemory,
+	extension.ResourceGPUCore,
+	extension.ResourceGPUMemoryRatio,
+	extension.ResourceGPUMemory,
+	extension.ResourceNvidiaGPU,
+}
+
+// ClusterCapacity is the aggregated colocation capacity of the cluster, which is consumed by the multi-cluster
+// schedulers (e.g. Karmada) to place the batch workloads across clusters.
+type ClusterCapacity struct {
+	// ClusterName is the name of the member cluster.
+	ClusterName string `json:"clusterName,omitempty"`
+	// UpdateTime is the time when the capacity is aggregated.
+	UpdateTime metav1.Time `json:"updateTime"`
+	// NodeCount is the number of schedulable nodes counted.
+	NodeCount int `json:"nodeCount"`
+	// Allocatable is the sum of the colocation resources allocatable on the nodes.
+	Allocatable corev1.ResourceList `json:"allocatable,omitempty"`
+	// Requested is the sum of the colocation resources requested by the pods on the nodes.
+	Requested corev1.ResourceList `json:"requested,omitempty"`
+	// Free is the reclaimable capacity left, i.e. the allocatable minus the requested of each node.
+	Free corev1.ResourceList `json:"free,omitempty"`
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemetric
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+const (
+	// flatlineReportsThreshold is the number of the consecutive reports with exactly the same node usage regarded as
+	// flatlined, e.g. koordlet keeps reporting the stale metrics after the collector gets stuck.
+	flatlineReportsThreshold = 5
+	// cliffMinUsageRatio and cliffDropRatio define a sudden usage cliff: the usage drops from at least
+	// cliffMinUsageRatio of the capacity to less than cliffDropRatio of the previous usage between two reports.
+	cliffMinUsageRatio = 0.3
+	cliffDropRatio     = 0.1
+)
+
+var anomalyDetectedResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+
+// nodeMetricAnomaly is the anomaly detected from a report of the NodeMetric.
+type nodeMetricAnomaly struct {
+	reason  string
+	message string
+}
+
+type nodeMetricHistory struct {
+	updateTime time.Time
+	usage      corev1.ResourceList
+	// capacity is the node capacity when the usage is reported
+	capacity corev1.ResourceList
+	// flatlineCount is the number of the consecutive reports with the same usage
+	flatlineCount int
+	// anomaly is the result of the last report, nil if the report is normal
+	anomaly *nodeMetricAnomaly
+}
+
+// anomalyDetector detects the anomalous node metrics reported by koordlet, e.g. sudden usage cliffs, flatlined
+// reports and impossible values, by comparing each report with the previous one of the same node.
+type anomalyDetector struct {
+	lock      sync.Mutex
+	histories map[string]*nodeMetricHistory
+}
+
+func newAnomalyDetector() *anomalyDetector {
+	return &anomalyDetector{
+		histories: map[string]*nodeMetricHistory{},
+	}
+}
+
+// detect returns the anomaly of the latest report of the NodeMetric, or nil if it is normal. A report is only
+// evaluated once, so the repeated reconciliations of the same report return the same result.
+func (d *anomalyDetector) detect(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric) *nodeMetricAnomaly {
+	if node == nil || nodeMetric == nil || nodeMetric.Status.UpdateTime == nil || nodeMetric.Status.NodeMetric == nil {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	updateTime := nodeMetric.Status.UpdateTime.Time
+	usage := nodeMetric.Status.NodeMetric.NodeUsage.ResourceList
+	history := d.histories[node.Name]
+	if history != nil && history.updateTime.Equal(updateTime) {
+		return history.anomaly
+	}
+	if history == nil {
+		history = &nodeMetricHistory{}
+		d.histories[node.Name] = history
+	}
+
+	anomaly := detectImpossibleValue(node, nodeMetric)
+	if history.usage != nil && isSameUsage(history.usage, usage) {
+		history.flatlineCount++
+	} else {
+		history.flatlineCount = 0
+	}
+	if anomaly == nil && history.flatlineCount+1 >= flatlineReportsThreshold {
+		anomaly = &nodeMetricAnomaly{
+			reason:  slov1alpha1.NodeMetricReasonFlatlined,
+			message: fmt.Sprintf("node usage has not changed for %d reports", history.flatlineCount+1),
+		}
+	}
+	if anomaly == nil && history.usage != nil {
+		anomaly = detectUsageCliff(node, history.capacity, history.usage, usage)
+	}
+
+	history.updateTime = updateTime
+	history.usage = usage.DeepCopy()
+	history.capacity = quotav1.Mask(node.Status.Capacity, anomalyDetectedResources)
+	history.anomaly = anomaly
+	return anomaly
+}
+
+// forget removes the history of the node, e.g. when the node is deleted.
+func (d *anomalyDetector) forget(nodeName string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.histories, nodeName)
+}
+
+func detectImpossibleValue(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric) *nodeMetricAnomaly {
+	usage := nodeMetric.Status.NodeMetric.NodeUsage.ResourceList
+	for _, resourceName := range anomalyDetectedResources {
+		q, ok := usage[resourceName]
+		if !ok {
+			continue
+		}
+		if q.Sign() < 0 {
+			return &nodeMetricAnomaly{
+				reason:  slov1alpha1.NodeMetricReasonImpossibleValue,
+				message: fmt.Sprintf("node %s usage %s is negative", resourceName, q.String()),
+			}
+		}
+		if capacity, ok := node.Status.Capacity[resourceName]; ok && !capacity.IsZero() && q.Cmp(capacity) > 0 {
+			return &nodeMetricAnomaly{
+				reason:  slov1alpha1.NodeMetricReasonImpossibleValue,
+				message: fmt.Sprintf("node %s usage %s exceeds the capacity %s", resourceName, q.String(), capacity.String()),
+			}
+		}
+	}
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		if podMetric == nil {
+			continue
+		}
+		for _, resourceName := range anomalyDetectedResources {
+			if q, ok := podMetric.PodUsage.ResourceList[resourceName]; ok && q.Sign() < 0 {
+				return &nodeMetricAnomaly{
+					reason: slov1alpha1.NodeMetricReasonImpossibleValue,
+					message: fmt.Sprintf("pod %s/%s %s usage %s is negative",
+						podMetric.Namespace, podMetric.Name, resourceName, q.String()),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// detectUsageCliff compares the current usage with the previous one expected on the current capacity, since a
+// legitimate capacity drop, e.g. a device is removed, also makes the usage drop proportionally.
+func detectUsageCliff(node *corev1.Node, previousCapacity, previous, current corev1.ResourceList) *nodeMetricAnomaly {
+	for _, resourceName := range anomalyDetectedResources {
+		capacity, ok := node.Status.Capacity[resourceName]
+		if !ok || capacity.IsZero() {
+			continue
+		}
+		prev, ok := previous[resourceName]
+		if !ok {
+			continue
+		}
+		cur, ok := current[resourceName]
+		if !ok {
+			continue
+		}
+		prevValue, curValue := float64(prev.MilliValue()), float64(cur.MilliValue())
+		if prevCapacity, ok := previousCapacity[resourceName]; ok && !prevCapacity.IsZero() && prevCapacity.Cmp(capacity) != 0 {
+			prevValue = prevValue * float64(capacity.MilliValue()) / float64(prevCapacity.MilliValue())
+		}
+		if prevValue >= cliffMinUsageRatio*float64(capacity.MilliValue()) && curValue < cliffDropRatio*prevValue {
+			return &nodeMetricAnomaly{
+				reason:  slov1alpha1.NodeMetricReasonUsageCliff,
+				message: fmt.Sprintf("node %s usage drops suddenly from %s to %s", resourceName, prev.String(), cur.String()),
+			}
+		}
+	}
+	return nil
+}
+
+func isSameUsage(previous, current corev1.ResourceList) bool {
+	compared := false
+	for _, resourceName := range anomalyDetectedResources {
+		prev, ok1 := previous[resourceName]
+		cur, ok2 := current[resourceName]
+		if ok1 != ok2 {
+			return false
+		}
+		if !ok1 {
+			continue
+		}
+		if prev.Cmp(cur) != 0 {
+			return false
+		}
+		compared = true
+	}
+	return compared
+}
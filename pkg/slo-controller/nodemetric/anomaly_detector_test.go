@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemetric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func Test_anomalyDetector_detect(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+	}
+	baseTime := time.Now()
+	newNodeMetric := func(seq int, cpu, memory string) *slov1alpha1.NodeMetric {
+		updateTime := metav1.NewTime(baseTime.Add(time.Duration(seq) * time.Minute))
+		return &slov1alpha1.NodeMetric{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Status: slov1alpha1.NodeMetricStatus{
+				UpdateTime: &updateTime,
+				NodeMetric: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(cpu),
+							corev1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		}
+	}
+	type report struct {
+		cpu        string
+		memory     string
+		wantReason string
+		// cpuCapacity overrides the cpu capacity of the node if set
+		cpuCapacity string
+	}
+	tests := []struct {
+		name    string
+		reports []report
+	}{
+		{
+			name: "normal reports",
+			reports: []report{
+				{cpu: "4", memory: "4Gi"},
+				{cpu: "5", memory: "4Gi"},
+				{cpu: "3", memory: "5Gi"},
+			},
+		},
+		{
+			name: "impossible values",
+			reports: []report{
+				{cpu: "4", memory: "4Gi"},
+				{cpu: "12", memory: "4Gi", wantReason: slov1alpha1.NodeMetricReasonImpossibleValue},
+				{cpu: "-1", memory: "4Gi", wantReason: slov1alpha1.NodeMetricReasonImpossibleValue},
+				{cpu: "5", memory: "4Gi"},
+			},
+		},
+		{
+			name: "usage cliff",
+			reports: []report{
+				{cpu: "8", memory: "4Gi"},
+				{cpu: "500m", memory: "4Gi", wantReason: slov1alpha1.NodeMetricReasonUsageCliff},
+				{cpu: "600m", memory: "4Gi"},
+			},
+		},
+		{
+			name: "usage drop due to the capacity drop is not a cliff",
+			reports: []report{
+				{cpu: "8", memory: "4Gi"},
+				{cpu: "500m", memory: "4Gi", cpuCapacity: "1"},
+				{cpu: "600m", memory: "4Gi", cpuCapacity: "1"},
+			},
+		},
+		{
+			name: "usage cliff after the capacity drop",
+			reports: []report{
+				{cpu: "8", memory: "4Gi"},
+				{cpu: "100m", memory: "4Gi", cpuCapacity: "5", wantReason: slov1alpha1.NodeMetricReasonUsageCliff},
+			},
+		},
+		{
+			name: "small usage drop is not a cliff",
+			reports: []report{
+				{cpu: "2", memory: "4Gi"},
+				{cpu: "100m", memory: "4Gi"},
+			},
+		},
+		{
+			name: "flatlined reports",
+			reports: []report{
+				{cpu: "4", memory: "4Gi"},
+				{cpu: "4", memory: "4Gi"},
+				{cpu: "4", memory: "4Gi"},
+				{cpu: "4", memory: "4Gi"},
+				{cpu: "4", memory: "4Gi", wantReason: slov1alpha1.NodeMetricReasonFlatlined},
+				{cpu: "4", memory: "4Gi", wantReason: slov1alpha1.NodeMetricReasonFlatlined},
+				{cpu: "4100m", memory: "4Gi"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newAnomalyDetector()
+			for i, r := range tt.reports {
+				node := node
+				if r.cpuCapacity != "" {
+					node = node.DeepCopy()
+					node.Status.Capacity[corev1.ResourceCPU] = resource.MustParse(r.cpuCapacity)
+				}
+				nodeMetric := newNodeMetric(i, r.cpu, r.memory)
+				got := d.detect(node, nodeMetric)
+				if r.wantReason == "" {
+					assert.Nil(t, got, "report %d", i)
+				} else if assert.NotNil(t, got, "report %d", i) {
+					assert.Equal(t, r.wantReason, got.reason, "report %d", i)
+				}
+				// the same report is evaluated only once
+				assert.Equal(t, got, d.detect(node, nodeMetric), "report %d", i)
+			}
+			d.forget(node.Name)
+			assert.Empty(t, d.histories)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodemetric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	nodeMetricAnomalies = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "koordinator_manager_nodemetric_anomalies_total",
+			Help: "Number of the anomalous NodeMetric reports detected, by the reason",
+		}, []string{"reason"})
+
+	nodeMetricDegraded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "koordinator_manager_nodemetric_degraded",
+			Help: "Whether the NodeMetric of the node is degraded, by the node and the reason",
+		}, []string{"node", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(nodeMetricAnomalies, nodeMetricDegraded)
+}
+
+func recordNodeMetricDegraded(nodeName string, anomaly *nodeMetricAnomaly) {
+	nodeMetricDegraded.DeletePartialMatch(prometheus.Labels{"node": nodeName})
+	if anomaly != nil {
+		nodeMetricDegraded.WithLabelValues(nodeName, anomaly.reason).Set(1)
+	}
+}
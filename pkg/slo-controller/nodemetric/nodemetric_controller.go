@@ -23,6 +23,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
@@ -41,6 +42,8 @@ type NodeMetricReconciler struct {
 	Scheme   *runtime.Scheme
 	cfgCache config.ColocationCfgCache
 	Recorder record.EventRecorder
+
+	anomalyDetector *anomalyDetector
 }
 
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
@@ -86,6 +89,8 @@ func (r *NodeMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	} else if !nodeExist {
 		// if !nodeExist && nodeMetricExist, delete NodeMetric.
+		r.anomalyDetector.forget(nodeName)
+		recordNodeMetricDegraded(nodeName, nil)
 		if err := r.Client.Delete(context.TODO(), nodeMetric); err != nil {
 			klog.Errorf("failed to delete nodeMetric %v, error: %v", nodeMetricName, err)
 			if errors.IsNotFound(err) {
@@ -119,6 +124,10 @@ func (r *NodeMetricReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				return ctrl.Result{Requeue: true}, err
 			}
 		}
+		if err := r.syncDegradedCondition(node, nodeMetric); err != nil {
+			klog.Errorf("failed to update degraded condition of nodeMetric %v, error: %v", nodeMetricName, err)
+			return ctrl.Result{Requeue: true}, err
+		}
 	}
 
 	klog.V(6).Infof("nodemetric-controller succeeded to update nodeMetric %v", nodeMetricName)
@@ -151,6 +160,55 @@ func (r *NodeMetricReconciler) getNodeMetricSpec(node *corev1.Node, oldSpec *slo
 	return nodeMetricSpec, nil
 }
 
+// syncDegradedCondition updates the Degraded condition of the NodeMetric according to the anomaly detected from the
+// latest report, so that the consumers like the load-aware scheduling can skip the bad data.
+func (r *NodeMetricReconciler) syncDegradedCondition(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric) error {
+	anomaly := r.anomalyDetector.detect(node, nodeMetric)
+	recordNodeMetricDegraded(node.Name, anomaly)
+
+	var oldCondition *slov1alpha1.NodeMetricCondition
+	conditionIndex := -1
+	for i := range nodeMetric.Status.Conditions {
+		if nodeMetric.Status.Conditions[i].Type == slov1alpha1.NodeMetricConditionDegraded {
+			oldCondition = &nodeMetric.Status.Conditions[i]
+			conditionIndex = i
+			break
+		}
+	}
+	newCondition := slov1alpha1.NodeMetricCondition{
+		Type:   slov1alpha1.NodeMetricConditionDegraded,
+		Status: corev1.ConditionFalse,
+		Reason: slov1alpha1.NodeMetricReasonNormal,
+	}
+	if anomaly != nil {
+		newCondition.Status = corev1.ConditionTrue
+		newCondition.Reason = anomaly.reason
+		newCondition.Message = anomaly.message
+	} else if oldCondition == nil {
+		// no need to add the condition for the normal metrics
+		return nil
+	}
+	if oldCondition != nil && oldCondition.Status == newCondition.Status &&
+		oldCondition.Reason == newCondition.Reason && oldCondition.Message == newCondition.Message {
+		return nil
+	}
+	if anomaly != nil {
+		nodeMetricAnomalies.WithLabelValues(anomaly.reason).Inc()
+		klog.V(4).Infof("nodeMetric %v is degraded, reason: %s, message: %s", nodeMetric.Name, anomaly.reason, anomaly.message)
+	}
+
+	newCondition.LastTransitionTime = metav1.Now()
+	if oldCondition != nil && oldCondition.Status == newCondition.Status {
+		newCondition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+	if conditionIndex >= 0 {
+		nodeMetric.Status.Conditions[conditionIndex] = newCondition
+	} else {
+		nodeMetric.Status.Conditions = append(nodeMetric.Status.Conditions, newCondition)
+	}
+	return r.Client.Status().Update(context.TODO(), nodeMetric)
+}
+
 func getDefaultSpec() *slov1alpha1.NodeMetricSpec {
 	defaultColocationCfg := config.NewDefaultColocationCfg()
 	return &slov1alpha1.NodeMetricSpec{
@@ -166,6 +224,8 @@ func Add(mgr ctrl.Manager) error {
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("nodemetric-controller"),
+
+		anomalyDetector: newAnomalyDetector(),
 	}
 	return reconciler.SetupWithManager(mgr)
 }
@@ -541,6 +541,8 @@ func createTestReconciler() (*NodeMetricReconciler, *config.ColocationHandlerFor
 	reconciler := &NodeMetricReconciler{
 		Client: client,
 		Scheme: scheme,
+
+		anomalyDetector: newAnomalyDetector(),
 	}
 	handler := config.NewColocationHandlerForConfigMapEvent(reconciler.Client, *config.NewDefaultColocationCfg(), &record.FakeRecorder{})
 	reconciler.cfgCache = handler
@@ -49,6 +49,10 @@ func (r *NodeResourceReconciler) updateDeviceResources(node *corev1.Node) error
 		return fmt.Errorf("failed to update gpus node resources, err: %w", err)
 	}
 
+	if err := r.updateNICNodeResource(node, device); err != nil {
+		return fmt.Errorf("failed to update nics node resources, err: %w", err)
+	}
+
 	// update node labels for device
 	if err := r.updateGPUDriverAndModel(node, device); err != nil {
 		klog.Errorf("failed to update gpu model and driver, err: %v", node.Name, err)
@@ -78,7 +82,8 @@ func (r *NodeResourceReconciler) isGPUResourceNeedSync(new, old *corev1.Node) bo
 
 func (r *NodeResourceReconciler) isGPULabelNeedSync(new, old map[string]string) bool {
 	return new[extension.LabelGPUModel] != old[extension.LabelGPUModel] ||
-		new[extension.LabelGPUDriverVersion] != old[extension.LabelGPUDriverVersion]
+		new[extension.LabelGPUDriverVersion] != old[extension.LabelGPUDriverVersion] ||
+		new[extension.LabelGPUVendor] != old[extension.LabelGPUVendor]
 }
 
 func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device *schedulingv1alpha1.Device) error {
@@ -104,6 +109,15 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 		return nil
 	}
 
+	// the gpu-core could be oversold on the node, while gpu-memory is kept strict
+	if percent := r.getGPUCoreOversellPercent(node); percent > 0 {
+		for _, resourceName := range []corev1.ResourceName{extension.ResourceGPUCore, extension.ResourceGPU} {
+			if q, exist := gpuResources[resourceName]; exist {
+				gpuResources[resourceName] = *resource.NewQuantity(q.Value()*percent/100, resource.DecimalSI)
+			}
+		}
+	}
+
 	copyNode := node.DeepCopy()
 	util.AddResourceList(copyNode.Status.Allocatable, gpuResources)
 	if !r.isGPUResourceNeedSync(copyNode, node) {
@@ -136,6 +150,73 @@ func (r *NodeResourceReconciler) updateGPUNodeResource(node *corev1.Node, device
 	return err
 }
 
+// getGPUCoreOversellPercent returns the gpu-core oversell percent declared by the node annotation, and falls back to
+// the colocation strategy if the annotation is missing or invalid, the same as the scheduler does with DeviceShareArgs.
+func (r *NodeResourceReconciler) getGPUCoreOversellPercent(node *corev1.Node) int64 {
+	percent, ok, err := extension.GetNodeGPUCoreOversellPercent(node.Annotations)
+	if err != nil {
+		klog.Warningf("failed to get gpu-core oversell percent of node %v, err: %v", node.Name, err)
+	}
+	if ok {
+		return percent
+	}
+	strategy := config.GetNodeColocationStrategy(r.cfgCache.GetCfgCopy(), node)
+	if strategy == nil || strategy.GPUCoreOversellPercent == nil {
+		return 0
+	}
+	return *strategy.GPUCoreOversellPercent
+}
+
+// updateNICNodeResource updates the total bandwidth of healthy NICs into node allocatable,
+// so the pods requesting koordinator.sh/net-bandwidth can pass the node resources fit check
+func (r *NodeResourceReconciler) updateNICNodeResource(node *corev1.Node, device *schedulingv1alpha1.Device) error {
+	if device == nil {
+		return nil
+	}
+	totalBandwidth := resource.NewQuantity(0, resource.DecimalSI)
+	hasNICDevice := false
+	for _, device := range device.Spec.Devices {
+		if device.Type != schedulingv1alpha1.NIC || !device.Health {
+			continue
+		}
+		hasNICDevice = true
+		totalBandwidth.Add(device.Resources[extension.ResourceNetBandwidth])
+	}
+	if !hasNICDevice {
+		return nil
+	}
+	if current, ok := node.Status.Allocatable[extension.ResourceNetBandwidth]; ok && current.Cmp(*totalBandwidth) == 0 {
+		return nil
+	}
+
+	return util.RetryOnConflictOrTooManyRequests(func() error {
+		updateNode := &corev1.Node{}
+		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: node.Name}, updateNode); err != nil {
+			klog.Errorf("failed to get node %v, error: %v", node.Name, err)
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+
+		updateNode = updateNode.DeepCopy() // avoid overwriting the cache
+		if updateNode.Status.Capacity == nil {
+			updateNode.Status.Capacity = corev1.ResourceList{}
+		}
+		if updateNode.Status.Allocatable == nil {
+			updateNode.Status.Allocatable = corev1.ResourceList{}
+		}
+		updateNode.Status.Capacity[extension.ResourceNetBandwidth] = totalBandwidth.DeepCopy()
+		updateNode.Status.Allocatable[extension.ResourceNetBandwidth] = totalBandwidth.DeepCopy()
+
+		if err := r.Client.Status().Update(context.TODO(), updateNode); err != nil {
+			klog.Errorf("failed to update node nic resource, %v, error: %v", updateNode.Name, err)
+			return err
+		}
+		return nil
+	})
+}
+
 func (r *NodeResourceReconciler) updateGPUDriverAndModel(node *corev1.Node, device *schedulingv1alpha1.Device) error {
 	// TODO: currently update the device resources barely. move to device plugins or implement a standard plugin later
 	if device == nil || device.Labels == nil {
@@ -162,6 +243,11 @@ func (r *NodeResourceReconciler) updateGPUDriverAndModel(node *corev1.Node, devi
 		}
 		updateNodeNew.Labels[extension.LabelGPUModel] = device.Labels[extension.LabelGPUModel]
 		updateNodeNew.Labels[extension.LabelGPUDriverVersion] = device.Labels[extension.LabelGPUDriverVersion]
+		if vendor := device.Labels[extension.LabelGPUVendor]; vendor != "" {
+			updateNodeNew.Labels[extension.LabelGPUVendor] = vendor
+		} else {
+			delete(updateNodeNew.Labels, extension.LabelGPUVendor)
+		}
 
 		patch := client.MergeFrom(updateNode)
 		if err := r.Client.Patch(context.Background(), updateNodeNew, patch); err != nil {
@@ -128,6 +128,165 @@ func Test_updateNodeGPUResource_updateGPUDriverAndModel(t *testing.T) {
 	assert.Equal(t, testNode.Labels[extension.LabelGPUDriverVersion], "480")
 }
 
+func Test_updateGPUNodeResourceWithOversell(t *testing.T) {
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+			Annotations: map[string]string{
+				extension.AnnotationNodeGPUCoreOversellPercent: "150",
+			},
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("20"),
+			},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("20"),
+			},
+		},
+	}
+	// the node without the annotation is oversold by the colocation strategy
+	defaultNode := testNode.DeepCopy()
+	defaultNode.Name = "test-node-1"
+	defaultNode.Annotations = nil
+	scheme := runtime.NewScheme()
+	schedulingv1alpha1.AddToScheme(scheme)
+	metav1.AddMetaToScheme(scheme)
+	corev1.AddToScheme(scheme)
+	r := &NodeResourceReconciler{
+		Client:         fake.NewClientBuilder().WithRuntimeObjects(testNode, defaultNode).WithScheme(scheme).Build(),
+		GPUSyncContext: framework.NewSyncContext(),
+		Clock:          clock.RealClock{},
+		cfgCache: &FakeCfgCache{
+			cfg: extension.ColocationCfg{
+				ColocationStrategy: extension.ColocationStrategy{
+					Enable:                     pointer.BoolPtr(true),
+					UpdateTimeThresholdSeconds: pointer.Int64Ptr(300),
+					ResourceDiffThreshold:      pointer.Float64Ptr(0.1),
+					GPUCoreOversellPercent:     pointer.Int64Ptr(120),
+				},
+			},
+		},
+	}
+	fakeDevice := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testNode.Name,
+		},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						extension.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						extension.ResourceGPUMemory:      resource.MustParse("16Gi"),
+						extension.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+				{
+					Minor:  pointer.Int32Ptr(1),
+					Health: true,
+					Type:   schedulingv1alpha1.GPU,
+					Resources: corev1.ResourceList{
+						extension.ResourceGPUCore:        *resource.NewQuantity(100, resource.DecimalSI),
+						extension.ResourceGPUMemory:      resource.MustParse("16Gi"),
+						extension.ResourceGPUMemoryRatio: *resource.NewQuantity(100, resource.DecimalSI),
+					},
+				},
+			},
+		},
+	}
+	err := r.updateGPUNodeResource(testNode, fakeDevice)
+	assert.NoError(t, err)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: testNode.Name}, testNode)
+	assert.NoError(t, err)
+	actualCore := testNode.Status.Allocatable[extension.ResourceGPUCore]
+	actualGPU := testNode.Status.Allocatable[extension.ResourceGPU]
+	actualMemory := testNode.Status.Allocatable[extension.ResourceGPUMemory]
+	actualMemoryRatio := testNode.Status.Allocatable[extension.ResourceGPUMemoryRatio]
+	assert.Equal(t, int64(300), actualCore.Value())
+	assert.Equal(t, int64(300), actualGPU.Value())
+	expectedMemory := resource.MustParse("32Gi")
+	assert.Equal(t, expectedMemory.Value(), actualMemory.Value())
+	assert.Equal(t, int64(200), actualMemoryRatio.Value())
+
+	err = r.updateGPUNodeResource(defaultNode, fakeDevice)
+	assert.NoError(t, err)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: defaultNode.Name}, defaultNode)
+	assert.NoError(t, err)
+	actualCore = defaultNode.Status.Allocatable[extension.ResourceGPUCore]
+	actualGPU = defaultNode.Status.Allocatable[extension.ResourceGPU]
+	actualMemory = defaultNode.Status.Allocatable[extension.ResourceGPUMemory]
+	assert.Equal(t, int64(240), actualCore.Value())
+	assert.Equal(t, int64(240), actualGPU.Value())
+	assert.Equal(t, expectedMemory.Value(), actualMemory.Value())
+}
+
+func Test_updateNICNodeResource(t *testing.T) {
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-node",
+		},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("20"),
+			},
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("20"),
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	schedulingv1alpha1.AddToScheme(scheme)
+	metav1.AddMetaToScheme(scheme)
+	corev1.AddToScheme(scheme)
+	r := &NodeResourceReconciler{
+		Client: fake.NewClientBuilder().WithRuntimeObjects(testNode).WithScheme(scheme).Build(),
+	}
+	fakeDevice := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: testNode.Name,
+		},
+		Spec: schedulingv1alpha1.DeviceSpec{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				{
+					Minor:  pointer.Int32Ptr(0),
+					Health: true,
+					Type:   schedulingv1alpha1.NIC,
+					Resources: corev1.ResourceList{
+						extension.ResourceNetBandwidth: resource.MustParse("25G"),
+					},
+				},
+				{
+					Minor:  pointer.Int32Ptr(1),
+					Health: true,
+					Type:   schedulingv1alpha1.NIC,
+					Resources: corev1.ResourceList{
+						extension.ResourceNetBandwidth: resource.MustParse("25G"),
+					},
+				},
+				{
+					Minor:  pointer.Int32Ptr(2),
+					Health: false,
+					Type:   schedulingv1alpha1.NIC,
+					Resources: corev1.ResourceList{
+						extension.ResourceNetBandwidth: resource.MustParse("25G"),
+					},
+				},
+			},
+		},
+	}
+	err := r.updateNICNodeResource(testNode, fakeDevice)
+	assert.NoError(t, err)
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: testNode.Name}, testNode)
+	assert.NoError(t, err)
+	actualAllocatable := testNode.Status.Allocatable[extension.ResourceNetBandwidth]
+	actualCapacity := testNode.Status.Capacity[extension.ResourceNetBandwidth]
+	assert.Equal(t, int64(50000000000), actualAllocatable.Value())
+	assert.Equal(t, int64(50000000000), actualCapacity.Value())
+}
+
 func Test_isGPUResourceNeedSync(t *testing.T) {
 	tests := []struct {
 		oldNode     *corev1.Node
@@ -25,6 +25,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/noderesource/framework"
 	"github.com/koordinator-sh/koordinator/pkg/util"
@@ -38,7 +39,11 @@ func init() {
 
 const PluginName = "BatchResource"
 
-var ResourceNames = []corev1.ResourceName{extension.BatchCPU, extension.BatchMemory}
+var (
+	ResourceNames = []corev1.ResourceName{extension.BatchCPU, extension.BatchMemory, extension.BatchGPUCore,
+		extension.BatchGPUMemory}
+	gpuResourceNames = []corev1.ResourceName{extension.ResourceGPUCore, extension.ResourceGPUMemory}
+)
 
 type Plugin struct{}
 
@@ -48,7 +53,9 @@ func (p *Plugin) Name() string {
 
 func (p *Plugin) NeedSync(strategy *extension.ColocationStrategy, oldNode, newNode *corev1.Node) (bool, string) {
 	// batch resource diff is bigger than ResourceDiffThreshold
-	resourcesToDiff := util.ExtendedResourceNames
+	resourcesToDiff := make([]corev1.ResourceName, 0, len(util.ExtendedResourceNames)+2)
+	resourcesToDiff = append(resourcesToDiff, util.ExtendedResourceNames...)
+	resourcesToDiff = append(resourcesToDiff, extension.BatchGPUCore, extension.BatchGPUMemory)
 	for _, resourceName := range resourcesToDiff {
 		if util.IsResourceDiff(oldNode.Status.Allocatable, newNode.Status.Allocatable, resourceName,
 			*strategy.ResourceDiffThreshold) {
@@ -92,6 +99,7 @@ func (p *Plugin) Calculate(strategy *extension.ColocationStrategy, node *corev1.
 	podLSUsed := util.NewZeroResourceList()
 	// pod(All).Used = pod(LS).Used + pod(BE).Used
 	podAllUsed := util.NewZeroResourceList()
+	podBEGPUUsed := corev1.ResourceList{}
 
 	nodeMetric := metrics.NodeMetric
 	podMetricMap := make(map[string]*slov1alpha1.PodMetricInfo)
@@ -120,18 +128,23 @@ func (p *Plugin) Calculate(strategy *extension.ColocationStrategy, node *corev1.
 			continue
 		}
 
+		// the reclaimable usage of LS pods (e.g. the cold memory) can be over-committed to BE pods, while the node usage
+		// still includes it, so the system usage is derived from the pods' usage including the reclaimable
 		if qosClass != extension.QoSBE {
-			podLSUsed = quotav1.Add(podLSUsed, getPodMetricUsage(podMetric))
+			podLSUsed = quotav1.Add(podLSUsed, getPodMetricHotUsage(podMetric))
 		}
 		podAllUsed = quotav1.Add(podAllUsed, getPodMetricUsage(podMetric))
+		if qosClass == extension.QoSBE {
+			podBEGPUUsed = quotav1.Add(podBEGPUUsed, getGPUUsage(podMetric.PodUsage.Devices))
+		}
 	}
 
 	nodeAllocatable := getNodeAllocatable(node)
 	nodeReservation := getNodeReservation(strategy, node)
 
-	// System.Used = Node.Used - Pod(All).Used
-	nodeUsage := getNodeMetricUsage(nodeMetric.Status.NodeMetric)
-	systemUsed := quotav1.Max(quotav1.Subtract(nodeUsage, podAllUsed), util.NewZeroResourceList())
+	// System.Used = System.Reported if koordlet reports the usage of the system cgroups,
+	// otherwise System.Used = Node.Used - Pod(All).Used
+	systemUsed := getSystemUsage(nodeMetric.Status.NodeMetric, podAllUsed)
 
 	batchAllocatable, cpuMsg, memMsg := calculateBatchResourceByPolicy(strategy, node, nodeAllocatable,
 		nodeReservation, systemUsed,
@@ -139,7 +152,7 @@ func (p *Plugin) Calculate(strategy *extension.ColocationStrategy, node *corev1.
 	klog.V(6).InfoS("calculate batch resource for node", "node", node.Name, "batch resource",
 		batchAllocatable, "cpu", cpuMsg, "memory", memMsg)
 
-	return []framework.ResourceItem{
+	items := []framework.ResourceItem{
 		{
 			Name:     extension.BatchCPU,
 			Quantity: resource.NewQuantity(batchAllocatable.Cpu().MilliValue(), resource.DecimalSI),
@@ -150,7 +163,63 @@ func (p *Plugin) Calculate(strategy *extension.ColocationStrategy, node *corev1.
 			Quantity: batchAllocatable.Memory(),
 			Message:  memMsg,
 		},
-	}, nil
+	}
+	return append(items, calculateBatchGPU(strategy, node, nodeMetric.Status.NodeMetric, podBEGPUUsed)...), nil
+}
+
+// calculateBatchGPU calculates the Batch GPU resources using the formula below:
+// Node(BE).GPU = Node.GPU.Total - Node.GPU.Reserved - (Node.GPU.Used - Pod(BE).GPU.Used).
+// The GPU usage not belonging to the BE pods is all regarded as used by the LS pods. Nothing is returned if the GPU
+// reclaiming is disabled or the node has no GPU, so the batch GPU resources are removed from the node.
+// The result only bounds the batch pods through the node allocatable, the scheduler allocates each GPU up to its total.
+func calculateBatchGPU(strategy *extension.ColocationStrategy, node *corev1.Node, nodeMetric *slov1alpha1.NodeMetricInfo,
+	podBEGPUUsed corev1.ResourceList) []framework.ResourceItem {
+	if strategy.GPUReclaimThresholdPercent == nil {
+		return nil
+	}
+	gpuAllocatable := quotav1.Mask(node.Status.Allocatable, gpuResourceNames)
+	if q, ok := gpuAllocatable[extension.ResourceGPUCore]; !ok || q.IsZero() {
+		return nil
+	}
+	reserveRatio := getReserveRatio(*strategy.GPUReclaimThresholdPercent)
+	gpuReservation := corev1.ResourceList{
+		extension.ResourceGPUCore:   util.MultiplyQuant(gpuAllocatable[extension.ResourceGPUCore], reserveRatio),
+		extension.ResourceGPUMemory: util.MultiplyQuant(gpuAllocatable[extension.ResourceGPUMemory], reserveRatio),
+	}
+	var gpuUsed corev1.ResourceList
+	if nodeMetric != nil {
+		gpuUsed = getGPUUsage(nodeMetric.NodeUsage.Devices)
+	}
+	zeroGPU := corev1.ResourceList{
+		extension.ResourceGPUCore:   *resource.NewQuantity(0, resource.DecimalSI),
+		extension.ResourceGPUMemory: *resource.NewQuantity(0, resource.BinarySI),
+	}
+	gpuLSUsed := quotav1.Max(quotav1.Subtract(gpuUsed, podBEGPUUsed), zeroGPU)
+	batchGPU := quotav1.Max(quotav1.Subtract(quotav1.Subtract(gpuAllocatable, gpuReservation), gpuLSUsed), zeroGPU)
+
+	batchGPUCore := batchGPU[extension.ResourceGPUCore]
+	batchGPUMemory := batchGPU[extension.ResourceGPUMemory]
+	gpuCoreAllocatable := gpuAllocatable[extension.ResourceGPUCore]
+	gpuMemoryAllocatable := gpuAllocatable[extension.ResourceGPUMemory]
+	gpuCoreReservation := gpuReservation[extension.ResourceGPUCore]
+	gpuMemoryReservation := gpuReservation[extension.ResourceGPUMemory]
+	gpuCoreLSUsed := gpuLSUsed[extension.ResourceGPUCore]
+	gpuMemoryLSUsed := gpuLSUsed[extension.ResourceGPUMemory]
+	return []framework.ResourceItem{
+		{
+			Name:     extension.BatchGPUCore,
+			Quantity: resource.NewQuantity(batchGPUCore.Value(), resource.DecimalSI),
+			Message: fmt.Sprintf("batchAllocatable[GPUCore]:%v = gpuAllocatable:%v - gpuReservation:%v - gpuLSUsed:%v",
+				batchGPUCore.Value(), gpuCoreAllocatable.Value(), gpuCoreReservation.Value(), gpuCoreLSUsed.Value()),
+		},
+		{
+			Name:     extension.BatchGPUMemory,
+			Quantity: resource.NewQuantity(batchGPUMemory.Value(), resource.BinarySI),
+			Message: fmt.Sprintf("batchAllocatable[GPUMem(GB)]:%v = gpuAllocatable:%v - gpuReservation:%v - gpuLSUsed:%v",
+				batchGPUMemory.ScaledValue(resource.Giga), gpuMemoryAllocatable.ScaledValue(resource.Giga),
+				gpuMemoryReservation.ScaledValue(resource.Giga), gpuMemoryLSUsed.ScaledValue(resource.Giga)),
+		},
+	}
 }
 
 func calculateBatchResourceByPolicy(strategy *extension.ColocationStrategy, node *corev1.Node,
@@ -212,6 +281,34 @@ func getPodMetricUsage(info *slov1alpha1.PodMetricInfo) corev1.ResourceList {
 	return corev1.ResourceList{corev1.ResourceCPU: *cpuUsageQuant, corev1.ResourceMemory: *memUsageQuant}
 }
 
+// getPodMetricHotUsage gets pod usage excluding the reclaimable usage from the PodMetricInfo
+func getPodMetricHotUsage(info *slov1alpha1.PodMetricInfo) corev1.ResourceList {
+	usage := getPodMetricUsage(info)
+	reclaimable, ok := info.ReclaimableUsage.ResourceList[corev1.ResourceMemory]
+	if !ok {
+		return usage
+	}
+	memUsage := usage[corev1.ResourceMemory]
+	if memUsage.Cmp(reclaimable) <= 0 {
+		usage[corev1.ResourceMemory] = *resource.NewQuantity(0, memUsage.Format)
+		return usage
+	}
+	usage[corev1.ResourceMemory] = *resource.NewQuantity(memUsage.Value()-reclaimable.Value(), memUsage.Format)
+	return usage
+}
+
+// getGPUUsage sums the gpu-core and gpu-memory usages of the GPU devices
+func getGPUUsage(devices []schedulingv1alpha1.DeviceInfo) corev1.ResourceList {
+	usage := corev1.ResourceList{}
+	for _, device := range devices {
+		if device.Type != schedulingv1alpha1.GPU {
+			continue
+		}
+		usage = quotav1.Add(usage, quotav1.Mask(device.Resources, gpuResourceNames))
+	}
+	return usage
+}
+
 // getNodeMetricUsage gets node usage from the NodeMetricInfo
 func getNodeMetricUsage(info *slov1alpha1.NodeMetricInfo) corev1.ResourceList {
 	cpuQ := info.NodeUsage.ResourceList[corev1.ResourceCPU]
@@ -221,6 +318,21 @@ func getNodeMetricUsage(info *slov1alpha1.NodeMetricInfo) corev1.ResourceList {
 	return corev1.ResourceList{corev1.ResourceCPU: *cpuUsageQ, corev1.ResourceMemory: *memUsageQ}
 }
 
+// getSystemUsage gets the usage of the system components (e.g. kubelet, container runtime), preferring the usage of the
+// system cgroups reported by koordlet, and falls back to the node usage excluding the pods' usage for the resources
+// not reported.
+func getSystemUsage(info *slov1alpha1.NodeMetricInfo, podAllUsed corev1.ResourceList) corev1.ResourceList {
+	nodeUsage := getNodeMetricUsage(info)
+	systemUsed := quotav1.Max(quotav1.Subtract(nodeUsage, podAllUsed), util.NewZeroResourceList())
+	if q, ok := info.SystemUsage.ResourceList[corev1.ResourceCPU]; ok {
+		systemUsed[corev1.ResourceCPU] = *resource.NewMilliQuantity(q.MilliValue(), q.Format)
+	}
+	if q, ok := info.SystemUsage.ResourceList[corev1.ResourceMemory]; ok {
+		systemUsed[corev1.ResourceMemory] = *resource.NewQuantity(q.Value(), q.Format)
+	}
+	return systemUsed
+}
+
 // getNodeAllocatable gets node allocatable and filters out non-CPU and non-Mem resources
 func getNodeAllocatable(node *corev1.Node) corev1.ResourceList {
 	result := node.Status.Allocatable.DeepCopy()
@@ -27,6 +27,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
 	"github.com/koordinator-sh/koordinator/pkg/slo-controller/noderesource/framework"
 )
@@ -251,6 +252,218 @@ func TestPluginCalculate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "calculate with memory usage excluding the reclaimable memory",
+			args: args{
+				strategy: &extension.ColocationStrategy{
+					Enable:                        pointer.BoolPtr(true),
+					CPUReclaimThresholdPercent:    pointer.Int64Ptr(65),
+					MemoryReclaimThresholdPercent: pointer.Int64Ptr(65),
+					DegradeTimeMinutes:            pointer.Int64Ptr(15),
+					UpdateTimeThresholdSeconds:    pointer.Int64Ptr(300),
+					ResourceDiffThreshold:         pointer.Float64Ptr(0.1),
+				},
+				node: &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-node1",
+					},
+					Status: corev1.NodeStatus{
+						Allocatable: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100"),
+							corev1.ResourceMemory: resource.MustParse("120G"),
+						},
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("100"),
+							corev1.ResourceMemory: resource.MustParse("120G"),
+						},
+					},
+				},
+				podList: &corev1.PodList{
+					Items: []corev1.Pod{
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "podA",
+								Namespace: "test",
+								Labels: map[string]string{
+									extension.LabelPodQoS: string(extension.QoSLS),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node1",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("20"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("20"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "podB",
+								Namespace: "test",
+								Labels: map[string]string{
+									extension.LabelPodQoS: string(extension.QoSBE),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName:   "test-node1",
+								Containers: []corev1.Container{{}},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodRunning,
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "podC",
+								Namespace: "test",
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node1",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+										},
+									}, {
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("20G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodPending,
+							},
+						},
+						{
+							ObjectMeta: metav1.ObjectMeta{
+								Name:      "podD",
+								Namespace: "test",
+								Labels: map[string]string{
+									extension.LabelPodQoS: string(extension.QoSBE),
+								},
+							},
+							Spec: corev1.PodSpec{
+								NodeName: "test-node1",
+								Containers: []corev1.Container{
+									{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("10G"),
+											},
+											Limits: corev1.ResourceList{
+												corev1.ResourceCPU:    resource.MustParse("10"),
+												corev1.ResourceMemory: resource.MustParse("10G"),
+											},
+										},
+									},
+								},
+							},
+							Status: corev1.PodStatus{
+								Phase: corev1.PodSucceeded,
+							},
+						},
+					},
+				},
+				metrics: &framework.ResourceMetrics{
+					NodeMetric: &slov1alpha1.NodeMetric{
+						Status: slov1alpha1.NodeMetricStatus{
+							UpdateTime: &metav1.Time{Time: time.Now()},
+							NodeMetric: &slov1alpha1.NodeMetricInfo{
+								NodeUsage: slov1alpha1.ResourceMap{
+									ResourceList: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("50"),
+										corev1.ResourceMemory: resource.MustParse("55G"),
+									},
+								},
+							},
+							PodsMetric: []*slov1alpha1.PodMetricInfo{
+								{
+									Namespace: "test",
+									Name:      "podA",
+									PodUsage: slov1alpha1.ResourceMap{
+										ResourceList: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("11"),
+											corev1.ResourceMemory: resource.MustParse("11G"),
+										},
+									},
+									ReclaimableUsage: slov1alpha1.ResourceMap{
+										ResourceList: corev1.ResourceList{
+											corev1.ResourceMemory: resource.MustParse("5G"),
+										},
+									},
+								}, {
+									Namespace: "test",
+									Name:      "podB",
+									PodUsage: slov1alpha1.ResourceMap{
+										ResourceList: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("10"),
+											corev1.ResourceMemory: resource.MustParse("10G"),
+										},
+									},
+									ReclaimableUsage: slov1alpha1.ResourceMap{
+										ResourceList: corev1.ResourceList{
+											corev1.ResourceMemory: resource.MustParse("4G"),
+										},
+									},
+								},
+								{
+									Namespace: "test",
+									Name:      "podC",
+									PodUsage: slov1alpha1.ResourceMap{
+										ResourceList: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("22"),
+											corev1.ResourceMemory: resource.MustParse("22G"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			want: []framework.ResourceItem{
+				{
+					Name:     extension.BatchCPU,
+					Quantity: resource.NewQuantity(25000, resource.DecimalSI),
+					Message:  "batchAllocatable[CPU(Milli-Core)]:25000 = nodeAllocatable:100000 - nodeReservation:35000 - systemUsage:7000 - podLSUsed:33000",
+				},
+				{
+					Name:     extension.BatchMemory,
+					Quantity: resource.NewScaledQuantity(38, 9),
+					Message:  "batchAllocatable[Mem(GB)]:38 = nodeAllocatable:120 - nodeReservation:42 - systemUsage:12 - podLSUsed:28",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "calculate with memory request",
 			args: args{
@@ -504,6 +717,76 @@ func Test_getPodMetricUsage(t *testing.T) {
 	}
 }
 
+func Test_getPodMetricHotUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		info *slov1alpha1.PodMetricInfo
+		want corev1.ResourceList
+	}{
+		{
+			name: "no reclaimable usage",
+			info: &slov1alpha1.PodMetricInfo{
+				PodUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+		{
+			name: "exclude the reclaimable memory",
+			info: &slov1alpha1.PodMetricInfo{
+				PodUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("10Gi"),
+					},
+				},
+				ReclaimableUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("6Gi"),
+			},
+		},
+		{
+			name: "reclaimable memory larger than the usage",
+			info: &slov1alpha1.PodMetricInfo{
+				PodUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("2Gi"),
+					},
+				},
+				ReclaimableUsage: slov1alpha1.ResourceMap{
+					ResourceList: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("4"),
+				corev1.ResourceMemory: resource.MustParse("0"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getPodMetricHotUsage(tt.info)
+			testingCorrectResourceList(t, &tt.want, &got)
+		})
+	}
+}
+
 func Test_getNodeMetricUsage(t *testing.T) {
 	type args struct {
 		info *slov1alpha1.NodeMetricInfo
@@ -540,6 +823,99 @@ func Test_getNodeMetricUsage(t *testing.T) {
 	}
 }
 
+func Test_getSystemUsage(t *testing.T) {
+	type args struct {
+		info       *slov1alpha1.NodeMetricInfo
+		podAllUsed corev1.ResourceList
+	}
+	tests := []struct {
+		name string
+		args args
+		want corev1.ResourceList
+	}{
+		{
+			name: "calculate by node usage when system usage not reported",
+			args: args{
+				info: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("40"),
+							corev1.ResourceMemory: resource.MustParse("80Gi"),
+						},
+					},
+				},
+				podAllUsed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("38"),
+					corev1.ResourceMemory: resource.MustParse("82Gi"),
+				},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("0"),
+			},
+		},
+		{
+			name: "use the reported system usage",
+			args: args{
+				info: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("40"),
+							corev1.ResourceMemory: resource.MustParse("80Gi"),
+						},
+					},
+					SystemUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1500m"),
+							corev1.ResourceMemory: resource.MustParse("3Gi"),
+						},
+					},
+				},
+				podAllUsed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("30"),
+					corev1.ResourceMemory: resource.MustParse("70Gi"),
+				},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1500m"),
+				corev1.ResourceMemory: resource.MustParse("3Gi"),
+			},
+		},
+		{
+			name: "fall back for the resources not reported",
+			args: args{
+				info: &slov1alpha1.NodeMetricInfo{
+					NodeUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("40"),
+							corev1.ResourceMemory: resource.MustParse("80Gi"),
+						},
+					},
+					SystemUsage: slov1alpha1.ResourceMap{
+						ResourceList: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("1"),
+						},
+					},
+				},
+				podAllUsed: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("30"),
+					corev1.ResourceMemory: resource.MustParse("70Gi"),
+				},
+			},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getSystemUsage(tt.args.info, tt.args.podAllUsed)
+			testingCorrectResourceList(t, &tt.want, &got)
+		})
+	}
+}
+
 func Test_getNodeReservation(t *testing.T) {
 	type args struct {
 		strategy *extension.ColocationStrategy
@@ -588,6 +964,109 @@ func Test_getNodeReservation(t *testing.T) {
 	}
 }
 
+func Test_calculateBatchGPU(t *testing.T) {
+	gpuNode := &corev1.Node{
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:          resource.MustParse("100"),
+				extension.ResourceGPUCore:   resource.MustParse("200"),
+				extension.ResourceGPUMemory: resource.MustParse("160Gi"),
+			},
+		},
+	}
+	gpuDevice := func(minor int32, core int64, memory string) schedulingv1alpha1.DeviceInfo {
+		return schedulingv1alpha1.DeviceInfo{
+			Minor: pointer.Int32(minor),
+			Type:  schedulingv1alpha1.GPU,
+			Resources: corev1.ResourceList{
+				extension.ResourceGPUCore:   *resource.NewQuantity(core, resource.DecimalSI),
+				extension.ResourceGPUMemory: resource.MustParse(memory),
+			},
+		}
+	}
+	nodeMetric := &slov1alpha1.NodeMetricInfo{
+		NodeUsage: slov1alpha1.ResourceMap{
+			Devices: []schedulingv1alpha1.DeviceInfo{
+				gpuDevice(0, 60, "40Gi"),
+				gpuDevice(1, 20, "20Gi"),
+				{
+					Type: schedulingv1alpha1.RDMA,
+					Resources: corev1.ResourceList{
+						extension.ResourceGPUCore: resource.MustParse("100"),
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name         string
+		strategy     *extension.ColocationStrategy
+		node         *corev1.Node
+		nodeMetric   *slov1alpha1.NodeMetricInfo
+		podBEGPUUsed corev1.ResourceList
+		wantCore     int64
+		wantMemory   int64
+		wantNothing  bool
+	}{
+		{
+			name:        "gpu reclaiming disabled",
+			strategy:    &extension.ColocationStrategy{},
+			node:        gpuNode,
+			nodeMetric:  nodeMetric,
+			wantNothing: true,
+		},
+		{
+			name:     "node without gpu",
+			strategy: &extension.ColocationStrategy{GPUReclaimThresholdPercent: pointer.Int64(80)},
+			node: &corev1.Node{
+				Status: corev1.NodeStatus{
+					Allocatable: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("100"),
+					},
+				},
+			},
+			nodeMetric:  nodeMetric,
+			wantNothing: true,
+		},
+		{
+			name:       "reclaim the gpu unused by the LS pods",
+			strategy:   &extension.ColocationStrategy{GPUReclaimThresholdPercent: pointer.Int64(80)},
+			node:       gpuNode,
+			nodeMetric: nodeMetric,
+			podBEGPUUsed: corev1.ResourceList{
+				extension.ResourceGPUCore:   resource.MustParse("20"),
+				extension.ResourceGPUMemory: resource.MustParse("20Gi"),
+			},
+			// 200 - 200*0.2 - (80 - 20)
+			wantCore: 100,
+			// 160Gi - 160Gi*0.2 - (60Gi - 20Gi)
+			wantMemory: 88 << 30,
+		},
+		{
+			name:       "no gpu left for batch",
+			strategy:   &extension.ColocationStrategy{GPUReclaimThresholdPercent: pointer.Int64(30)},
+			node:       gpuNode,
+			nodeMetric: nodeMetric,
+			wantCore:   0,
+			wantMemory: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateBatchGPU(tt.strategy, tt.node, tt.nodeMetric, tt.podBEGPUUsed)
+			if tt.wantNothing {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, 2, len(got))
+			assert.Equal(t, extension.BatchGPUCore, got[0].Name)
+			assert.Equal(t, tt.wantCore, got[0].Quantity.Value())
+			assert.Equal(t, extension.BatchGPUMemory, got[1].Name)
+			assert.Equal(t, tt.wantMemory, got[1].Quantity.Value())
+		})
+	}
+}
+
 func testingCorrectResourceItems(t *testing.T, want, got []framework.ResourceItem) {
 	assert.Equal(t, len(want), len(got))
 	for i := range want {
@@ -0,0 +1,218 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeslo
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+var (
+	// DriftThreshold is the duration koordlet is allowed to lag behind the NodeSLO spec before the node is regarded
+	// as drifted. The drift detection is disabled if it is not positive.
+	DriftThreshold = 10 * time.Minute
+	// ReportGracePeriod is the duration koordlet is allowed to never report the applied state since the NodeSLO is
+	// created before the node is regarded as drifted, which tolerates the rollout of koordlet. The koordlet not
+	// reporting is ignored if it is not positive, e.g. the koordlet of older versions.
+	ReportGracePeriod = 30 * time.Minute
+)
+
+func InitFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&DriftThreshold, "nodeslo-drift-threshold", DriftThreshold, "determines the duration koordlet is allowed to lag behind the NodeSLO spec before the node is regarded as drifted, disable the drift detection if not positive.")
+	fs.DurationVar(&ReportGracePeriod, "nodeslo-report-grace-period", ReportGracePeriod, "determines the duration koordlet is allowed to never report the applied NodeSLO spec before the node is regarded as drifted, ignore the koordlet not reporting if not positive.")
+}
+
+// nodeSLODrift is the drift detected between the NodeSLO spec and the state applied by koordlet.
+type nodeSLODrift struct {
+	reason  string
+	message string
+}
+
+// NodeSLODriftReconciler compares the generation of the NodeSLO spec with the generation applied by koordlet, and
+// marks the NodeSLO drifted when koordlet lags behind for longer than the DriftThreshold, so the stuck koordlet
+// instances can be found across the fleet.
+type NodeSLODriftReconciler struct {
+	client.Client
+
+	lock sync.Mutex
+	// laggingSince records the time each NodeSLO is first observed not applied by koordlet.
+	laggingSince map[string]time.Time
+	now          func() time.Time
+}
+
+func NewNodeSLODriftReconciler(c client.Client) *NodeSLODriftReconciler {
+	return &NodeSLODriftReconciler{
+		Client:       c,
+		laggingSince: map[string]time.Time{},
+		now:          time.Now,
+	}
+}
+
+// +kubebuilder:rbac:groups=slo.koordinator.sh,resources=nodeslos,verbs=get;list;watch
+// +kubebuilder:rbac:groups=slo.koordinator.sh,resources=nodeslos/status,verbs=get;update;patch
+
+func (r *NodeSLODriftReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx, "nodeslo-drift-reconciler", req.NamespacedName)
+
+	nodeSLO := &slov1alpha1.NodeSLO{}
+	if err := r.Client.Get(context.TODO(), req.NamespacedName, nodeSLO); err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("failed to find nodeSLO %v, error: %v", req.Name, err)
+			return ctrl.Result{Requeue: true}, err
+		}
+		r.forget(req.Name)
+		recordNodeSLODrifted(req.Name, nil)
+		return ctrl.Result{}, nil
+	}
+
+	drift, requeueAfter := r.detect(nodeSLO)
+	recordNodeSLODrifted(nodeSLO.Name, drift)
+	if err := r.syncDriftedCondition(nodeSLO, drift); err != nil {
+		klog.Errorf("failed to update drifted condition of nodeSLO %v, error: %v", nodeSLO.Name, err)
+		return ctrl.Result{Requeue: true}, err
+	}
+	// check again when the lag would exceed the threshold
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// detect returns the drift of the NodeSLO, or nil if koordlet has applied the latest spec or the lag is still within
+// the threshold, in which case it also returns the duration after which the NodeSLO should be checked again.
+func (r *NodeSLODriftReconciler) detect(nodeSLO *slov1alpha1.NodeSLO) (*nodeSLODrift, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	observedGeneration := nodeSLO.Status.ObservedGeneration
+	notReported := observedGeneration <= 0
+	if observedGeneration >= nodeSLO.Generation || (notReported && ReportGracePeriod <= 0) {
+		delete(r.laggingSince, nodeSLO.Name)
+		return nil, 0
+	}
+	threshold := DriftThreshold
+	if notReported {
+		// koordlet has never reported the applied state, e.g. it keeps failing or is stuck since started
+		threshold = ReportGracePeriod
+	}
+
+	now := r.now()
+	since, ok := r.laggingSince[nodeSLO.Name]
+	if !ok {
+		since = now
+		if notReported && !nodeSLO.CreationTimestamp.IsZero() {
+			since = nodeSLO.CreationTimestamp.Time
+		}
+		// keep the drift observed before the restart of koord-manager
+		if oldCondition := getDriftedCondition(nodeSLO); oldCondition != nil && oldCondition.Status == corev1.ConditionTrue {
+			since = oldCondition.LastTransitionTime.Add(-threshold)
+		}
+		r.laggingSince[nodeSLO.Name] = since
+	}
+	if lag := now.Sub(since); lag < threshold {
+		return nil, threshold - lag
+	}
+	if notReported {
+		return &nodeSLODrift{
+			reason: slov1alpha1.NodeSLOReasonNotReported,
+			message: fmt.Sprintf("koordlet has not reported applying the generation %d for over %v",
+				nodeSLO.Generation, threshold),
+		}, 0
+	}
+	return &nodeSLODrift{
+		reason: slov1alpha1.NodeSLOReasonApplyLagging,
+		message: fmt.Sprintf("koordlet has applied the generation %d, lagging behind the generation %d for over %v",
+			observedGeneration, nodeSLO.Generation, threshold),
+	}, 0
+}
+
+func (r *NodeSLODriftReconciler) forget(nodeSLOName string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.laggingSince, nodeSLOName)
+}
+
+// syncDriftedCondition updates the Drifted condition of the NodeSLO according to the drift detected.
+func (r *NodeSLODriftReconciler) syncDriftedCondition(nodeSLO *slov1alpha1.NodeSLO, drift *nodeSLODrift) error {
+	oldCondition := getDriftedCondition(nodeSLO)
+	newCondition := slov1alpha1.NodeSLOCondition{
+		Type:   slov1alpha1.NodeSLOConditionDrifted,
+		Status: corev1.ConditionFalse,
+		Reason: slov1alpha1.NodeSLOReasonSynced,
+	}
+	if drift != nil {
+		newCondition.Status = corev1.ConditionTrue
+		newCondition.Reason = drift.reason
+		newCondition.Message = drift.message
+	} else if oldCondition == nil {
+		// no need to add the condition for the synced NodeSLO
+		return nil
+	}
+	if oldCondition != nil && oldCondition.Status == newCondition.Status &&
+		oldCondition.Reason == newCondition.Reason && oldCondition.Message == newCondition.Message {
+		return nil
+	}
+	if drift != nil {
+		nodeSLODrifts.WithLabelValues(drift.reason).Inc()
+		klog.V(4).Infof("nodeSLO %v is drifted, reason: %s, message: %s", nodeSLO.Name, drift.reason, drift.message)
+	}
+
+	newCondition.LastTransitionTime = metav1.NewTime(r.now())
+	if oldCondition != nil && oldCondition.Status == newCondition.Status {
+		newCondition.LastTransitionTime = oldCondition.LastTransitionTime
+	}
+	if oldCondition != nil {
+		*oldCondition = newCondition
+	} else {
+		nodeSLO.Status.Conditions = append(nodeSLO.Status.Conditions, newCondition)
+	}
+	return r.Client.Status().Update(context.TODO(), nodeSLO)
+}
+
+func getDriftedCondition(nodeSLO *slov1alpha1.NodeSLO) *slov1alpha1.NodeSLOCondition {
+	for i := range nodeSLO.Status.Conditions {
+		if nodeSLO.Status.Conditions[i].Type == slov1alpha1.NodeSLOConditionDrifted {
+			return &nodeSLO.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func AddDriftController(mgr ctrl.Manager) error {
+	if DriftThreshold <= 0 {
+		klog.V(4).Infof("nodeslo drift detection is disabled")
+		return nil
+	}
+	return NewNodeSLODriftReconciler(mgr.GetClient()).SetupWithManager(mgr)
+}
+
+func (r *NodeSLODriftReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&slov1alpha1.NodeSLO{}).
+		Named("nodeslo-drift").
+		Complete(r)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeslo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestNodeSLODriftReconciler_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	slov1alpha1.AddToScheme(scheme)
+	nodeSLO := &slov1alpha1.NodeSLO{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-node",
+			Generation: 2,
+		},
+		Status: slov1alpha1.NodeSLOStatus{
+			ObservedGeneration: 1,
+		},
+	}
+	r := NewNodeSLODriftReconciler(fake.NewClientBuilder().WithScheme(scheme).WithObjects(nodeSLO).Build())
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: nodeSLO.Name}}
+	getCondition := func() *slov1alpha1.NodeSLOCondition {
+		got := &slov1alpha1.NodeSLO{}
+		assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, got))
+		return getDriftedCondition(got)
+	}
+
+	// koordlet lags within the threshold
+	result, err := r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, DriftThreshold, result.RequeueAfter)
+	assert.Nil(t, getCondition())
+
+	// koordlet lags over the threshold
+	now = now.Add(DriftThreshold)
+	result, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+	condition := getCondition()
+	assert.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, slov1alpha1.NodeSLOReasonApplyLagging, condition.Reason)
+
+	// the drift is kept after koord-manager restarts
+	restarted := NewNodeSLODriftReconciler(r.Client)
+	restarted.now = r.now
+	_, err = restarted.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, condition, getCondition())
+
+	// koordlet catches up the spec
+	got := &slov1alpha1.NodeSLO{}
+	assert.NoError(t, r.Client.Get(context.TODO(), req.NamespacedName, got))
+	got.Status.ObservedGeneration = 2
+	assert.NoError(t, r.Client.Status().Update(context.TODO(), got))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+	condition = getCondition()
+	assert.NotNil(t, condition)
+	assert.Equal(t, corev1.ConditionFalse, condition.Status)
+	assert.Equal(t, slov1alpha1.NodeSLOReasonSynced, condition.Reason)
+	assert.Empty(t, r.laggingSince)
+
+	// the NodeSLO is deleted
+	assert.NoError(t, r.Client.Delete(context.TODO(), got))
+	_, err = r.Reconcile(context.TODO(), req)
+	assert.NoError(t, err)
+}
+
+func TestNodeSLODriftReconciler_detect(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   slov1alpha1.NodeSLOStatus
+		wantNext time.Duration
+	}{
+		{
+			name:     "koordlet not reporting the applied state",
+			status:   slov1alpha1.NodeSLOStatus{},
+			wantNext: ReportGracePeriod,
+		},
+		{
+			name:   "koordlet applied the latest spec",
+			status: slov1alpha1.NodeSLOStatus{ObservedGeneration: 3},
+		},
+		{
+			name:     "koordlet lags behind the spec",
+			status:   slov1alpha1.NodeSLOStatus{ObservedGeneration: 2},
+			wantNext: DriftThreshold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewNodeSLODriftReconciler(nil)
+			nodeSLO := &slov1alpha1.NodeSLO{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-node", Generation: 3},
+				Status:     tt.status,
+			}
+			drift, next := r.detect(nodeSLO)
+			assert.Nil(t, drift)
+			assert.Equal(t, tt.wantNext, next)
+		})
+	}
+}
+
+func TestNodeSLODriftReconciler_detectNotReported(t *testing.T) {
+	now := time.Now()
+	nodeSLO := &slov1alpha1.NodeSLO{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-node",
+			Generation:        1,
+			CreationTimestamp: metav1.NewTime(now.Add(-ReportGracePeriod / 2)),
+		},
+	}
+	r := NewNodeSLODriftReconciler(nil)
+	r.now = func() time.Time { return now }
+
+	// koordlet is allowed to not report since the NodeSLO created within the grace period
+	drift, next := r.detect(nodeSLO)
+	assert.Nil(t, drift)
+	assert.Equal(t, ReportGracePeriod/2, next)
+
+	// koordlet has not reported for over the grace period
+	now = now.Add(ReportGracePeriod / 2)
+	drift, next = r.detect(nodeSLO)
+	assert.NotNil(t, drift)
+	assert.Equal(t, slov1alpha1.NodeSLOReasonNotReported, drift.reason)
+	assert.Equal(t, time.Duration(0), next)
+
+	// the koordlet not reporting is ignored if the grace period is disabled
+	defer func(gracePeriod time.Duration) { ReportGracePeriod = gracePeriod }(ReportGracePeriod)
+	ReportGracePeriod = 0
+	drift, next = r.detect(nodeSLO)
+	assert.Nil(t, drift)
+	assert.Equal(t, time.Duration(0), next)
+	assert.Empty(t, r.laggingSince)
+}
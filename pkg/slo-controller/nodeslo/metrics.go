@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeslo
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	nodeSLODrifts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "koordinator_manager_nodeslo_drifts_total",
+			Help: "Number of the NodeSLO drifts detected, by the reason",
+		}, []string{"reason"})
+
+	nodeSLODrifted = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "koordinator_manager_nodeslo_drifted",
+			Help: "Whether koordlet lags behind the NodeSLO spec for too long, by the node and the reason",
+		}, []string{"node", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(nodeSLODrifts, nodeSLODrifted)
+}
+
+func recordNodeSLODrifted(nodeName string, drift *nodeSLODrift) {
+	nodeSLODrifted.DeletePartialMatch(prometheus.Labels{"node": nodeName})
+	if drift != nil {
+		nodeSLODrifted.WithLabelValues(nodeName, drift.reason).Set(1)
+	}
+}
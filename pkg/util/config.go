@@ -106,6 +106,28 @@ func DefaultResctrlQOS(qos apiext.QoSClass) *slov1alpha1.ResctrlQOS {
 	return resctrlQOS
 }
 
+// DefaultNetworkQOS returns the recommended DSCP marking, which is EF for LSR, AF41 for LS and CS1 for BE.
+func DefaultNetworkQOS(qos apiext.QoSClass) *slov1alpha1.NetworkQOS {
+	var networkQOS *slov1alpha1.NetworkQOS
+	switch qos {
+	case apiext.QoSLSR:
+		networkQOS = &slov1alpha1.NetworkQOS{
+			DSCP: pointer.Int64Ptr(46),
+		}
+	case apiext.QoSLS:
+		networkQOS = &slov1alpha1.NetworkQOS{
+			DSCP: pointer.Int64Ptr(34),
+		}
+	case apiext.QoSBE:
+		networkQOS = &slov1alpha1.NetworkQOS{
+			DSCP: pointer.Int64Ptr(8),
+		}
+	default:
+		klog.V(5).Infof("network qos has no auto config for qos %s", qos)
+	}
+	return networkQOS
+}
+
 // DefaultMemoryQOS returns the recommended configuration for memory qos strategy.
 // Please refer to `apis/slo/v1alpha1` for the definition of each field.
 // In the recommended configuration, all abilities of memcg qos are disable, including `MinLimitPercent`,
@@ -176,6 +198,10 @@ func DefaultResourceQOSStrategy() *slov1alpha1.ResourceQOSStrategy {
 				Enable:    pointer.BoolPtr(false),
 				MemoryQOS: *DefaultMemoryQOS(apiext.QoSLSR),
 			},
+			NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+				Enable:     pointer.BoolPtr(false),
+				NetworkQOS: *DefaultNetworkQOS(apiext.QoSLSR),
+			},
 		},
 		LSClass: &slov1alpha1.ResourceQOS{
 			CPUQOS: &slov1alpha1.CPUQOSCfg{
@@ -190,6 +216,10 @@ func DefaultResourceQOSStrategy() *slov1alpha1.ResourceQOSStrategy {
 				Enable:    pointer.BoolPtr(false),
 				MemoryQOS: *DefaultMemoryQOS(apiext.QoSLS),
 			},
+			NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+				Enable:     pointer.BoolPtr(false),
+				NetworkQOS: *DefaultNetworkQOS(apiext.QoSLS),
+			},
 		},
 		BEClass: &slov1alpha1.ResourceQOS{
 			CPUQOS: &slov1alpha1.CPUQOSCfg{
@@ -204,6 +234,10 @@ func DefaultResourceQOSStrategy() *slov1alpha1.ResourceQOSStrategy {
 				Enable:    pointer.BoolPtr(false),
 				MemoryQOS: *DefaultMemoryQOS(apiext.QoSBE),
 			},
+			NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+				Enable:     pointer.BoolPtr(false),
+				NetworkQOS: *DefaultNetworkQOS(apiext.QoSBE),
+			},
 		},
 	}
 }
@@ -222,6 +256,10 @@ func NoneResourceQOS(qos apiext.QoSClass) *slov1alpha1.ResourceQOS {
 			Enable:    pointer.BoolPtr(false),
 			MemoryQOS: *NoneMemoryQOS(),
 		},
+		NetworkQOS: &slov1alpha1.NetworkQOSCfg{
+			Enable:     pointer.BoolPtr(false),
+			NetworkQOS: *NoneNetworkQOS(),
+		},
 	}
 }
 
@@ -254,6 +292,13 @@ func NoneMemoryQOS() *slov1alpha1.MemoryQOS {
 	}
 }
 
+// NoneNetworkQOS returns the all-disabled configuration for network qos strategy.
+func NoneNetworkQOS() *slov1alpha1.NetworkQOS {
+	return &slov1alpha1.NetworkQOS{
+		DSCP: pointer.Int64Ptr(0),
+	}
+}
+
 // NoneResourceQOSStrategy indicates the qos strategy with all qos
 func NoneResourceQOSStrategy() *slov1alpha1.ResourceQOSStrategy {
 	return &slov1alpha1.ResourceQOSStrategy{
@@ -18,8 +18,11 @@ package reservation
 
 import (
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -70,6 +73,9 @@ func NewReservePod(r *schedulingv1alpha1.Reservation) *corev1.Pod {
 	reservePod.Annotations[AnnotationReservePod] = "true"
 	reservePod.Annotations[AnnotationReservationName] = r.Name // for search inversely
 
+	// restrict the nodes with the node selector and affinity of the reservation besides the template
+	setReservePodNodeConstraints(reservePod, r)
+
 	// annotate node name specified
 	if len(reservePod.Spec.NodeName) > 0 {
 		// if the reservation specifies a nodeName, annotate it and cleanup spec.nodeName for other plugins not
@@ -89,10 +95,102 @@ func NewReservePod(r *schedulingv1alpha1.Reservation) *corev1.Pod {
 	}
 
 	reservePod.Spec.SchedulerName = GetReservationSchedulerName(r)
+	// keep the node nominated by preemption, so the scheduler can reserve the space for the reserve pod
+	reservePod.Status.NominatedNodeName = r.Status.NominatedNodeName
 
 	return reservePod
 }
 
+// setReservePodNodeConstraints makes the reserve pod only fit the nodes matching both the node constraints of the
+// reservation and the ones of the template. The node selector of the reservation is merged into the pod's unless the
+// template selects another value of the same label, which is required via the node affinity instead so that no node
+// matches. The required node selector terms are combined so that a node must satisfy a term of each.
+func setReservePodNodeConstraints(reservePod *corev1.Pod, r *schedulingv1alpha1.Reservation) {
+	if len(r.Spec.NodeSelector) == 0 && r.Spec.NodeAffinity == nil {
+		return
+	}
+
+	var conflicts []corev1.NodeSelectorRequirement
+	for key, value := range r.Spec.NodeSelector {
+		if reservePod.Spec.NodeSelector == nil {
+			reservePod.Spec.NodeSelector = map[string]string{}
+		}
+		if oldValue, ok := reservePod.Spec.NodeSelector[key]; ok && oldValue != value {
+			conflicts = append(conflicts, corev1.NodeSelectorRequirement{
+				Key:      key,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{value},
+			})
+			continue
+		}
+		reservePod.Spec.NodeSelector[key] = value
+	}
+
+	var required []corev1.NodeSelectorTerm
+	var preferred []corev1.PreferredSchedulingTerm
+	if r.Spec.NodeAffinity != nil {
+		if r.Spec.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+			required = r.Spec.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.DeepCopy().NodeSelectorTerms
+		}
+		for i := range r.Spec.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			preferred = append(preferred, *r.Spec.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].DeepCopy())
+		}
+	}
+	if len(conflicts) > 0 {
+		required = combineNodeSelectorTerms(required, []corev1.NodeSelectorTerm{{MatchExpressions: conflicts}})
+	}
+	if len(required) == 0 && len(preferred) == 0 {
+		return
+	}
+
+	if reservePod.Spec.Affinity == nil {
+		reservePod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if reservePod.Spec.Affinity.NodeAffinity == nil {
+		reservePod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := reservePod.Spec.Affinity.NodeAffinity
+	if len(required) > 0 {
+		if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+			nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+		}
+		nodeSelector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		nodeSelector.NodeSelectorTerms = combineNodeSelectorTerms(nodeSelector.NodeSelectorTerms, required)
+	}
+	nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, preferred...)
+}
+
+// combineNodeSelectorTerms returns the terms which a node satisfies iff it satisfies a term of a and a term of b.
+// Since the terms are ORed, each term of a is ANDed with each term of b. An empty term matches no node, so does the
+// combination of it.
+func combineNodeSelectorTerms(a, b []corev1.NodeSelectorTerm) []corev1.NodeSelectorTerm {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	isEmpty := func(term *corev1.NodeSelectorTerm) bool {
+		return len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0
+	}
+	terms := make([]corev1.NodeSelectorTerm, 0, len(a)*len(b))
+	for i := range a {
+		for j := range b {
+			if isEmpty(&a[i]) || isEmpty(&b[j]) {
+				terms = append(terms, corev1.NodeSelectorTerm{})
+				continue
+			}
+			var term corev1.NodeSelectorTerm
+			term.MatchExpressions = append(term.MatchExpressions, a[i].MatchExpressions...)
+			term.MatchExpressions = append(term.MatchExpressions, b[j].MatchExpressions...)
+			term.MatchFields = append(term.MatchFields, a[i].MatchFields...)
+			term.MatchFields = append(term.MatchFields, b[j].MatchFields...)
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
 func ValidateReservation(r *schedulingv1alpha1.Reservation) error {
 	if r == nil {
 		return fmt.Errorf("the reservation is nil")
@@ -100,7 +198,11 @@ func ValidateReservation(r *schedulingv1alpha1.Reservation) error {
 	if r.Spec.Template == nil {
 		return fmt.Errorf("the reservation misses the template spec")
 	}
-	if len(r.Spec.Owners) <= 0 {
+	if r.Spec.CapacityOnly {
+		if len(r.Spec.Owners) > 0 {
+			return fmt.Errorf("the capacity-only reservation should not specify the owner spec")
+		}
+	} else if len(r.Spec.Owners) <= 0 {
 		return fmt.Errorf("the reservation misses the owner spec")
 	}
 	if r.Spec.TTL == nil && r.Spec.Expires == nil {
@@ -168,10 +270,105 @@ func IsReservationExpired(r *schedulingv1alpha1.Reservation) bool {
 	return false
 }
 
+// SetReservationExpired marks the reservation as Failed with the Expired reason in its Ready condition.
+func SetReservationExpired(r *schedulingv1alpha1.Reservation) {
+	r.Status.Phase = schedulingv1alpha1.ReservationFailed
+	// not duplicate expired info
+	idx := -1
+	isReady := false
+	for i, condition := range r.Status.Conditions {
+		if condition.Type == schedulingv1alpha1.ReservationConditionReady {
+			idx = i
+			isReady = condition.Status == schedulingv1alpha1.ConditionStatusTrue
+		}
+	}
+	if idx < 0 { // if not set condition
+		condition := schedulingv1alpha1.ReservationCondition{
+			Type:               schedulingv1alpha1.ReservationConditionReady,
+			Status:             schedulingv1alpha1.ConditionStatusFalse,
+			Reason:             schedulingv1alpha1.ReasonReservationExpired,
+			LastProbeTime:      metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+		}
+		r.Status.Conditions = append(r.Status.Conditions, condition)
+	} else if isReady { // if was ready
+		condition := schedulingv1alpha1.ReservationCondition{
+			Type:               schedulingv1alpha1.ReservationConditionReady,
+			Status:             schedulingv1alpha1.ConditionStatusFalse,
+			Reason:             schedulingv1alpha1.ReasonReservationExpired,
+			LastProbeTime:      metav1.Now(),
+			LastTransitionTime: metav1.Now(),
+		}
+		r.Status.Conditions[idx] = condition
+	} else { // if already not ready
+		r.Status.Conditions[idx].Reason = schedulingv1alpha1.ReasonReservationExpired
+		r.Status.Conditions[idx].LastProbeTime = metav1.Now()
+	}
+}
+
+// GetReservationRenewTime returns the time from which the TTL of the reservation counts, which is the last renewal
+// if the reservation is renewed, otherwise the creation.
+func GetReservationRenewTime(r *schedulingv1alpha1.Reservation) time.Time {
+	renewTime, err := extension.GetReservationRenewTime(r.Annotations)
+	if err != nil {
+		klog.V(4).InfoS("failed to parse reservation renew time, ignore it", "reservation", klog.KObj(r), "err", err)
+		return r.CreationTimestamp.Time
+	}
+	if renewTime != nil && renewTime.After(r.CreationTimestamp.Time) {
+		return renewTime.Time
+	}
+	return r.CreationTimestamp.Time
+}
+
+// GetReservationExpirationTime returns the time when the reservation expires, which is the earlier one of
+// spec.expires and the TTL counted from the last renewal. It returns nil if the reservation never expires.
+func GetReservationExpirationTime(r *schedulingv1alpha1.Reservation) *time.Time {
+	// disable expiration if TTL is set as 0
+	if r.Spec.TTL != nil && r.Spec.TTL.Duration == 0 {
+		return nil
+	}
+	var expirationTime *time.Time
+	if r.Spec.Expires != nil {
+		t := r.Spec.Expires.Time
+		expirationTime = &t
+	}
+	if r.Spec.TTL != nil {
+		t := GetReservationRenewTime(r).Add(r.Spec.TTL.Duration)
+		if expirationTime == nil || t.Before(*expirationTime) {
+			expirationTime = &t
+		}
+	}
+	return expirationTime
+}
+
 func GetReservationNodeName(r *schedulingv1alpha1.Reservation) string {
 	return r.Status.NodeName
 }
 
+// GetReservationOwnerQuotaNames returns the quotas of the reservation owners, which are the quota labels in the owner
+// label selectors, or the quotas of the namespaces of the owner objects or controllers resolved by getNamespaceQuotaName.
+func GetReservationOwnerQuotaNames(r *schedulingv1alpha1.Reservation, getNamespaceQuotaName func(namespace string) string) sets.String {
+	quotaNames := sets.NewString()
+	for _, owner := range r.Spec.Owners {
+		if owner.LabelSelector != nil {
+			if quotaName := owner.LabelSelector.MatchLabels[extension.LabelQuotaName]; quotaName != "" {
+				quotaNames.Insert(quotaName)
+				continue
+			}
+		}
+		var namespace string
+		if owner.Object != nil {
+			namespace = owner.Object.Namespace
+		} else if owner.Controller != nil {
+			namespace = owner.Controller.Namespace
+		}
+		if namespace != "" {
+			quotaNames.Insert(getNamespaceQuotaName(namespace))
+		}
+	}
+	return quotaNames
+}
+
 func IsObjValidActiveReservation(obj interface{}) bool {
 	reservation, _ := obj.(*schedulingv1alpha1.Reservation)
 	err := ValidateReservation(reservation)
@@ -207,17 +404,19 @@ type ReservationToPodEventHandler struct {
 var _ cache.ResourceEventHandler = &ReservationToPodEventHandler{}
 
 func NewReservationToPodEventHandler(handler cache.ResourceEventHandler, filters ...func(obj interface{}) bool) cache.ResourceEventHandler {
-	return cache.FilteringResourceEventHandler{
-		FilterFunc: func(obj interface{}) bool {
-			for _, fn := range filters {
-				if !fn(obj) {
-					return false
+	return &reservePodCacheCleaner{
+		handler: cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				for _, fn := range filters {
+					if !fn(obj) {
+						return false
+					}
 				}
-			}
-			return true
-		},
-		Handler: &ReservationToPodEventHandler{
-			handler: handler,
+				return true
+			},
+			Handler: &ReservationToPodEventHandler{
+				handler: handler,
+			},
 		},
 	}
 }
@@ -227,7 +426,7 @@ func (r ReservationToPodEventHandler) OnAdd(obj interface{}) {
 	if !ok {
 		return
 	}
-	pod := NewReservePod(reservation)
+	pod := GetReservePod(reservation)
 	r.handler.OnAdd(pod)
 }
 
@@ -239,8 +438,8 @@ func (r ReservationToPodEventHandler) OnUpdate(oldObj, newObj interface{}) {
 		return
 	}
 
-	oldPod := NewReservePod(oldR)
-	newPod := NewReservePod(newR)
+	oldPod := defaultReservePodCache.get(oldR, false)
+	newPod := GetReservePod(newR)
 	r.handler.OnUpdate(oldPod, newPod)
 }
 
@@ -260,6 +459,38 @@ func (r ReservationToPodEventHandler) OnDelete(obj interface{}) {
 		return
 	}
 
-	pod := NewReservePod(reservation)
+	pod := defaultReservePodCache.get(reservation, false)
 	r.handler.OnDelete(pod)
 }
+
+// reservePodCacheCleaner drops the cached reserve pods of the deleted reservations after the events are handled,
+// no matter whether the events are filtered or not.
+type reservePodCacheCleaner struct {
+	handler cache.ResourceEventHandler
+}
+
+func (c *reservePodCacheCleaner) OnAdd(obj interface{}) {
+	c.handler.OnAdd(obj)
+}
+
+func (c *reservePodCacheCleaner) OnUpdate(oldObj, newObj interface{}) {
+	c.handler.OnUpdate(oldObj, newObj)
+	oldR, oldOK := oldObj.(*schedulingv1alpha1.Reservation)
+	newR, newOK := newObj.(*schedulingv1alpha1.Reservation)
+	// a delete event followed by an immediate add event may be merged into an update event
+	if oldOK && newOK && oldR.UID != newR.UID {
+		defaultReservePodCache.forget(oldR.UID)
+	}
+}
+
+func (c *reservePodCacheCleaner) OnDelete(obj interface{}) {
+	c.handler.OnDelete(obj)
+	switch t := obj.(type) {
+	case *schedulingv1alpha1.Reservation:
+		defaultReservePodCache.forget(t.UID)
+	case cache.DeletedFinalStateUnknown:
+		if r, ok := t.Obj.(*schedulingv1alpha1.Reservation); ok {
+			defaultReservePodCache.forget(r.UID)
+		}
+	}
+}
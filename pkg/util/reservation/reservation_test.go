@@ -24,7 +24,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
 
+	"github.com/koordinator-sh/koordinator/apis/extension"
 	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
 )
 
@@ -60,6 +62,114 @@ func TestNewReservePod(t *testing.T) {
 	})
 }
 
+func TestNewReservePodWithNodeConstraints(t *testing.T) {
+	newNode := func(labels map[string]string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node", Labels: labels}}
+	}
+	zoneTerm := func(zones ...string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: zones},
+			},
+		}
+	}
+	tests := []struct {
+		name          string
+		template      corev1.PodSpec
+		nodeSelector  map[string]string
+		nodeAffinity  *corev1.NodeAffinity
+		matchedNodes  []map[string]string
+		rejectedNodes []map[string]string
+	}{
+		{
+			name:          "node selector merged with the template",
+			template:      corev1.PodSpec{NodeSelector: map[string]string{"zone": "a"}},
+			nodeSelector:  map[string]string{"pool": "dedicated"},
+			matchedNodes:  []map[string]string{{"zone": "a", "pool": "dedicated"}},
+			rejectedNodes: []map[string]string{{"zone": "a"}, {"pool": "dedicated"}},
+		},
+		{
+			name:          "conflicting node selector matches no node",
+			template:      corev1.PodSpec{NodeSelector: map[string]string{"pool": "shared"}},
+			nodeSelector:  map[string]string{"pool": "dedicated"},
+			rejectedNodes: []map[string]string{{"pool": "shared"}, {"pool": "dedicated"}},
+		},
+		{
+			name: "required terms combined with the template",
+			template: corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{zoneTerm("a"), zoneTerm("b")},
+						},
+					},
+				},
+			},
+			nodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{zoneTerm("b", "c")},
+				},
+			},
+			matchedNodes:  []map[string]string{{"zone": "b"}},
+			rejectedNodes: []map[string]string{{"zone": "a"}, {"zone": "c"}},
+		},
+		{
+			name: "required terms without the template ones",
+			nodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{zoneTerm("a")},
+				},
+			},
+			matchedNodes:  []map[string]string{{"zone": "a"}},
+			rejectedNodes: []map[string]string{{"zone": "b"}, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &schedulingv1alpha1.Reservation{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-reservation"},
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template:     &corev1.PodTemplateSpec{Spec: tt.template},
+					NodeSelector: tt.nodeSelector,
+					NodeAffinity: tt.nodeAffinity,
+				},
+			}
+			originalTemplate := r.Spec.Template.DeepCopy()
+			reservePod := NewReservePod(r)
+			assert.Equal(t, originalTemplate, r.Spec.Template, "the template should not be modified")
+			requiredNodeAffinity := nodeaffinity.GetRequiredNodeAffinity(reservePod)
+			for _, labels := range tt.matchedNodes {
+				matched, err := requiredNodeAffinity.Match(newNode(labels))
+				assert.NoError(t, err)
+				assert.True(t, matched, labels)
+			}
+			for _, labels := range tt.rejectedNodes {
+				matched, err := requiredNodeAffinity.Match(newNode(labels))
+				assert.NoError(t, err)
+				assert.False(t, matched, labels)
+			}
+		})
+	}
+
+	// the preferred terms are appended to the ones of the template
+	preferredTerm := corev1.PreferredSchedulingTerm{Weight: 10, Preference: zoneTerm("a")}
+	r := &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-reservation"},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+			NodeAffinity: &corev1.NodeAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{preferredTerm},
+			},
+		},
+	}
+	reservePod := NewReservePod(r)
+	assert.Equal(t, &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{preferredTerm},
+		},
+	}, reservePod.Spec.Affinity)
+}
+
 func TestIsReservationActive(t *testing.T) {
 	t.Run("test not panic", func(t *testing.T) {
 		rPending := &schedulingv1alpha1.Reservation{
@@ -235,6 +345,71 @@ func TestIsReservationExpired(t *testing.T) {
 	}
 }
 
+func TestGetReservationExpirationTime(t *testing.T) {
+	now := time.Now()
+	createTime := metav1.NewTime(now.Add(-time.Hour))
+	renewTime := now.Add(-10 * time.Minute)
+	tests := []struct {
+		name        string
+		ttl         *metav1.Duration
+		expires     *metav1.Time
+		annotations map[string]string
+		want        *time.Time
+	}{
+		{
+			name: "never expires",
+		},
+		{
+			name:    "TTL 0 disables expiration",
+			ttl:     &metav1.Duration{Duration: 0},
+			expires: &metav1.Time{Time: now},
+		},
+		{
+			name: "TTL counts from creation",
+			ttl:  &metav1.Duration{Duration: 2 * time.Hour},
+			want: func() *time.Time { t := createTime.Add(2 * time.Hour); return &t }(),
+		},
+		{
+			name:        "TTL counts from renewal",
+			ttl:         &metav1.Duration{Duration: 2 * time.Hour},
+			annotations: map[string]string{extension.AnnotationReservationRenewTime: renewTime.Format(time.RFC3339Nano)},
+			want:        func() *time.Time { t := renewTime.Add(2 * time.Hour); return &t }(),
+		},
+		{
+			name:    "expires earlier than TTL",
+			ttl:     &metav1.Duration{Duration: 2 * time.Hour},
+			expires: &metav1.Time{Time: now},
+			want:    &now,
+		},
+		{
+			name:    "only expires",
+			expires: &metav1.Time{Time: now.Add(time.Hour)},
+			want:    func() *time.Time { t := now.Add(time.Hour); return &t }(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &schedulingv1alpha1.Reservation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "r",
+					CreationTimestamp: createTime,
+					Annotations:       tt.annotations,
+				},
+				Spec: schedulingv1alpha1.ReservationSpec{
+					TTL:     tt.ttl,
+					Expires: tt.expires,
+				},
+			}
+			got := GetReservationExpirationTime(r)
+			if tt.want == nil {
+				assert.Nil(t, got)
+			} else if assert.NotNil(t, got) {
+				assert.True(t, tt.want.Equal(*got), "want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestGetReservationSchedulerName(t *testing.T) {
 	tests := []struct {
 		name string
@@ -282,6 +457,40 @@ func TestGetReservationSchedulerName(t *testing.T) {
 	}
 }
 
+func TestGetReservationOwnerQuotaNames(t *testing.T) {
+	getNamespaceQuotaName := func(namespace string) string {
+		return namespace + "-quota"
+	}
+	r := &schedulingv1alpha1.Reservation{
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{extension.LabelQuotaName: "label-quota"},
+					},
+				},
+				{
+					Object: &corev1.ObjectReference{Namespace: "ns-1", Name: "pod-1"},
+				},
+				{
+					Controller: &schedulingv1alpha1.ReservationControllerReference{Namespace: "ns-2"},
+				},
+				{
+					// the owner without namespace specifies no quota
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "test"},
+					},
+				},
+			},
+		},
+	}
+	got := GetReservationOwnerQuotaNames(r, getNamespaceQuotaName)
+	assert.Equal(t, []string{"label-quota", "ns-1-quota", "ns-2-quota"}, got.List())
+
+	got = GetReservationOwnerQuotaNames(&schedulingv1alpha1.Reservation{}, getNamespaceQuotaName)
+	assert.Equal(t, 0, got.Len())
+}
+
 func TestIsObjValidActiveReservation(t *testing.T) {
 	tests := []struct {
 		name string
@@ -389,6 +598,58 @@ func TestIsObjValidActiveReservation(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "valid capacity-only reservation",
+			arg: &schedulingv1alpha1.Reservation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "reserve-pod-0",
+				},
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template: &corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "reserve-pod-0",
+						},
+					},
+					CapacityOnly: true,
+					TTL:          &metav1.Duration{Duration: 30 * time.Minute},
+				},
+				Status: schedulingv1alpha1.ReservationStatus{
+					Phase:    schedulingv1alpha1.ReservationAvailable,
+					NodeName: "test-node-0",
+				},
+			},
+			want: true,
+		},
+		{
+			name: "invalid capacity-only reservation with owners",
+			arg: &schedulingv1alpha1.Reservation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "reserve-pod-0",
+				},
+				Spec: schedulingv1alpha1.ReservationSpec{
+					Template: &corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "reserve-pod-0",
+						},
+					},
+					CapacityOnly: true,
+					Owners: []schedulingv1alpha1.ReservationOwner{
+						{
+							Object: &corev1.ObjectReference{
+								Kind: "Pod",
+								Name: "test-pod-0",
+							},
+						},
+					},
+					TTL: &metav1.Duration{Duration: 30 * time.Minute},
+				},
+				Status: schedulingv1alpha1.ReservationStatus{
+					Phase:    schedulingv1alpha1.ReservationAvailable,
+					NodeName: "test-node-0",
+				},
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+// reservePodCache caches the reserve pods converted from the reservations by the UID and ResourceVersion, so that
+// the event handlers and filters converting the same version of a reservation share one reserve pod instead of
+// deep-copying the template for each of them.
+type reservePodCache struct {
+	lock sync.RWMutex
+	pods map[types.UID]*cachedReservePod
+}
+
+type cachedReservePod struct {
+	resourceVersion string
+	pod             *corev1.Pod
+}
+
+var defaultReservePodCache = newReservePodCache()
+
+func newReservePodCache() *reservePodCache {
+	return &reservePodCache{
+		pods: map[types.UID]*cachedReservePod{},
+	}
+}
+
+// GetReservePod returns the reserve pod of the reservation from the shared cache. The returned pod is shared with
+// the other consumers and MUST NOT be modified, use NewReservePod to get a private one instead.
+// The cached pods are dropped when the reservations are deleted in the handlers of NewReservationToPodEventHandler.
+func GetReservePod(r *schedulingv1alpha1.Reservation) *corev1.Pod {
+	return defaultReservePodCache.get(r, true)
+}
+
+// get returns the cached reserve pod if the reservation is not changed, otherwise converts the reservation and caches
+// the result if store is true. The reservations without ResourceVersion, e.g. the objects built in memory, are
+// never cached since their changes cannot be told.
+func (c *reservePodCache) get(r *schedulingv1alpha1.Reservation, store bool) *corev1.Pod {
+	if r.UID == "" || r.ResourceVersion == "" {
+		return NewReservePod(r)
+	}
+
+	c.lock.RLock()
+	cached := c.pods[r.UID]
+	c.lock.RUnlock()
+	if cached != nil && cached.resourceVersion == r.ResourceVersion {
+		return cached.pod
+	}
+
+	pod := NewReservePod(r)
+	if store {
+		c.lock.Lock()
+		c.pods[r.UID] = &cachedReservePod{resourceVersion: r.ResourceVersion, pod: pod}
+		c.lock.Unlock()
+	}
+	return pod
+}
+
+func (c *reservePodCache) forget(uid types.UID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.pods, uid)
+}
+
+func (c *reservePodCache) len() int {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.pods)
+}
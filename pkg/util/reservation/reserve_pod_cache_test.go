@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reservation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func newCachedTestReservation(uid, resourceVersion string) *schedulingv1alpha1.Reservation {
+	return &schedulingv1alpha1.Reservation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "reserve-" + uid,
+			UID:             types.UID(uid),
+			ResourceVersion: resourceVersion,
+		},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+			TTL:      &metav1.Duration{Duration: 30 * time.Minute},
+		},
+	}
+}
+
+func Test_reservePodCache(t *testing.T) {
+	c := newReservePodCache()
+
+	r := newCachedTestReservation("cache-0", "1")
+	pod := c.get(r, true)
+	assert.Equal(t, NewReservePod(r), pod)
+	// the same version shares the reserve pod
+	assert.Same(t, pod, c.get(r.DeepCopy(), true))
+	assert.Same(t, pod, c.get(r.DeepCopy(), false))
+
+	// a new version is converted again, which is not cached without store
+	newR := r.DeepCopy()
+	newR.ResourceVersion = "2"
+	newR.Status.NodeName = "test-node"
+	newPod := c.get(newR, false)
+	assert.Equal(t, "test-node", newPod.Spec.NodeName)
+	assert.Same(t, pod, c.get(r, false))
+	newPod = c.get(newR, true)
+	assert.Same(t, newPod, c.get(newR, false))
+	assert.NotSame(t, pod, c.get(r, false))
+
+	// the reservations without ResourceVersion are never cached
+	inMemory := newCachedTestReservation("cache-1", "")
+	assert.NotSame(t, c.get(inMemory, true), c.get(inMemory, true))
+	assert.Equal(t, 1, c.len())
+
+	c.forget(r.UID)
+	assert.Equal(t, 0, c.len())
+}
+
+func TestReservationToPodEventHandler_reservePodCache(t *testing.T) {
+	var added, updated, deleted []*corev1.Pod
+	h := NewReservationToPodEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			added = append(added, obj.(*corev1.Pod))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			updated = append(updated, newObj.(*corev1.Pod))
+		},
+		DeleteFunc: func(obj interface{}) {
+			deleted = append(deleted, obj.(*corev1.Pod))
+		},
+	})
+	filtered := NewReservationToPodEventHandler(&fakePodHandler{t: t}, func(obj interface{}) bool {
+		return false
+	})
+
+	r := newCachedTestReservation("handler-0", "1")
+	h.OnAdd(r)
+	filtered.OnAdd(r)
+	assert.Same(t, added[0], GetReservePod(r))
+
+	newR := r.DeepCopy()
+	newR.ResourceVersion = "2"
+	h.OnUpdate(r, newR)
+	filtered.OnUpdate(r, newR)
+	assert.Same(t, updated[0], GetReservePod(newR))
+
+	// the cached reserve pod is dropped once the reservation is deleted, even if the event is filtered
+	h.OnDelete(cache.DeletedFinalStateUnknown{Key: newR.Name, Obj: newR})
+	assert.Same(t, updated[0], deleted[0])
+	defaultReservePodCache.get(newR, true)
+	filtered.OnDelete(newR)
+	defaultReservePodCache.lock.RLock()
+	_, ok := defaultReservePodCache.pods[newR.UID]
+	defaultReservePodCache.lock.RUnlock()
+	assert.False(t, ok)
+}
@@ -31,4 +31,8 @@ func init() {
 	addHandlersWithGate(validating.HandlerMap, func() (enabled bool) {
 		return utilfeature.DefaultFeatureGate.Enabled(features.PodValidatingWebhook)
 	})
+
+	addHandlersWithGate(validating.EvictionHandlerMap, func() (enabled bool) {
+		return utilfeature.DefaultFeatureGate.Enabled(features.EvictionBudgetWebhook)
+	})
 }
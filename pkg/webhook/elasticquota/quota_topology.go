@@ -69,6 +69,9 @@ func (qt *quotaTopology) ValidAddQuota(quota *v1alpha1.ElasticQuota) error {
 	if err := qt.validateQuotaTopology(nil, quotaInfo); err != nil {
 		return err
 	}
+	if err := qt.validateQuotaSchedulesTopology(quota, quotaInfo); err != nil {
+		return err
+	}
 
 	qt.quotaInfoMap[quotaInfo.Name] = quotaInfo
 	qt.quotaHierarchyInfo[quotaInfo.Name] = make(map[string]struct{})
@@ -107,6 +110,9 @@ func (qt *quotaTopology) ValidUpdateQuota(oldQuota, newQuota *v1alpha1.ElasticQu
 	if err := qt.validateQuotaTopology(oldQuotaInfo, newQuotaInfo); err != nil {
 		return err
 	}
+	if err := qt.validateQuotaSchedulesTopology(newQuota, newQuotaInfo); err != nil {
+		return err
+	}
 
 	qt.quotaInfoMap[quotaName] = newQuotaInfo
 	if oldQuotaInfo.ParentName != newQuotaInfo.ParentName {
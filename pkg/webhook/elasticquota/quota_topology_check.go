@@ -53,10 +53,110 @@ func (qt *quotaTopology) validateQuotaSelfItem(quota *v1alpha1.ElasticQuota) err
 		}
 	}
 
+	batchTier, err := extension.GetQuotaBatchTier(quota)
+	if err != nil {
+		return fmt.Errorf("%v quota.Annotation[%v] is invalid, err: %v", quota.Name, extension.AnnotationQuotaBatchTier, err)
+	}
+	if batchTier != nil {
+		if resourceNames := quotav1.IsNegative(batchTier.Min); len(resourceNames) > 0 {
+			return fmt.Errorf("%v quota batch tier's min < 0, in dimensions :%v", quota.Name, resourceNames)
+		}
+		if resourceNames := quotav1.IsNegative(batchTier.Max); len(resourceNames) > 0 {
+			return fmt.Errorf("%v quota batch tier's max < 0, in dimensions :%v", quota.Name, resourceNames)
+		}
+		if !isMinLessEqualMax(batchTier.Min, batchTier.Max) {
+			return fmt.Errorf("%v quota batch tier's min :%v > max,%v", quota.Name, batchTier.Min, batchTier.Max)
+		}
+	}
+
+	schedules, err := extension.GetQuotaSchedules(quota)
+	if err != nil {
+		return fmt.Errorf("%v quota.Annotation[%v] is invalid, err: %v", quota.Name, extension.AnnotationQuotaSchedules, err)
+	}
+	var baseMin, baseMax v1.ResourceList
+	if len(schedules) > 0 {
+		if baseMin, baseMax, err = getQuotaScheduleBase(quota); err != nil {
+			return fmt.Errorf("%v quota.Annotation[%v] is invalid, err: %v", quota.Name, extension.AnnotationQuotaOriginalSpec, err)
+		}
+	}
+	for _, schedule := range schedules {
+		if resourceNames := quotav1.IsNegative(schedule.Min); len(resourceNames) > 0 {
+			return fmt.Errorf("%v quota schedule %v's min < 0, in dimensions :%v", quota.Name, schedule.Name, resourceNames)
+		}
+		if resourceNames := quotav1.IsNegative(schedule.Max); len(resourceNames) > 0 {
+			return fmt.Errorf("%v quota schedule %v's max < 0, in dimensions :%v", quota.Name, schedule.Name, resourceNames)
+		}
+		scheduleMin, scheduleMax := getQuotaScheduleMinMax(schedule, baseMin, baseMax)
+		if !isMinLessEqualMax(scheduleMin, scheduleMax) {
+			return fmt.Errorf("%v quota schedule %v's min :%v > max,%v", quota.Name, schedule.Name, scheduleMin, scheduleMax)
+		}
+	}
+
 	// minQuota <= maxQuota
-	for key, val := range quota.Spec.Min {
-		if maxVal, exist := quota.Spec.Max[key]; !exist || maxVal.Cmp(val) == -1 {
-			return fmt.Errorf("%v min :%v > max,%v", quota.Name, quota.Spec.Min, quota.Spec.Max)
+	if !isMinLessEqualMax(quota.Spec.Min, quota.Spec.Max) {
+		return fmt.Errorf("%v min :%v > max,%v", quota.Name, quota.Spec.Min, quota.Spec.Max)
+	}
+	return nil
+}
+
+func isMinLessEqualMax(min, max v1.ResourceList) bool {
+	for key, val := range min {
+		if maxVal, exist := max[key]; !exist || maxVal.Cmp(val) == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// getQuotaScheduleBase returns the min/max overridden by the quota schedules, which is the original spec kept by
+// the active schedule, or the spec if no schedule is active or the spec is changed by the user since then.
+func getQuotaScheduleBase(quota *v1alpha1.ElasticQuota) (v1.ResourceList, v1.ResourceList, error) {
+	originalSpec, err := extension.GetQuotaOriginalSpec(quota)
+	if err != nil || originalSpec == nil {
+		return quota.Spec.Min, quota.Spec.Max, err
+	}
+	baseMin, baseMax := originalSpec.Min, originalSpec.Max
+	if applied := originalSpec.Applied; applied != nil {
+		if !quotav1.Equals(quotav1.RemoveZeros(quota.Spec.Min), quotav1.RemoveZeros(applied.Min)) {
+			baseMin = quota.Spec.Min
+		}
+		if !quotav1.Equals(quotav1.RemoveZeros(quota.Spec.Max), quotav1.RemoveZeros(applied.Max)) {
+			baseMax = quota.Spec.Max
+		}
+	}
+	return baseMin, baseMax, nil
+}
+
+// getQuotaScheduleMinMax returns the min/max of the quota during the schedule window.
+func getQuotaScheduleMinMax(schedule extension.QuotaSchedule, baseMin, baseMax v1.ResourceList) (v1.ResourceList, v1.ResourceList) {
+	scheduleMin, scheduleMax := baseMin, baseMax
+	if schedule.Min != nil {
+		scheduleMin = schedule.Min
+	}
+	if schedule.Max != nil {
+		scheduleMax = schedule.Max
+	}
+	return scheduleMin, scheduleMax
+}
+
+// validateQuotaSchedulesTopology checks the min/max of each schedule window with the parent and the children
+// in the same way as the spec, the neighbours are taken with their current min/max.
+func (qt *quotaTopology) validateQuotaSchedulesTopology(quota *v1alpha1.ElasticQuota, quotaInfo *QuotaInfo) error {
+	schedules, err := extension.GetQuotaSchedules(quota)
+	if err != nil || len(schedules) == 0 {
+		return err
+	}
+	baseMin, baseMax, err := getQuotaScheduleBase(quota)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		scheduleMin, scheduleMax := getQuotaScheduleMinMax(schedule, baseMin, baseMax)
+		scheduleInfo := NewQuotaInfo(quotaInfo.IsParent, quotaInfo.AllowLentResource, quotaInfo.Name, quotaInfo.ParentName)
+		scheduleInfo.setMinQuotaNoLock(scheduleMin)
+		scheduleInfo.setMaxQuotaNoLock(scheduleMax)
+		if err := qt.validateQuotaTopology(nil, scheduleInfo); err != nil {
+			return fmt.Errorf("%v quota schedule %v is invalid, err: %v", quota.Name, schedule.Name, err)
 		}
 	}
 	return nil
@@ -230,6 +330,9 @@ func quotaFieldsCopy(q *v1alpha1.ElasticQuota) v1alpha1.ElasticQuota {
 				extension.LabelQuotaParent:   q.Labels[extension.LabelQuotaParent],
 				extension.LabelQuotaIsParent: q.Labels[extension.LabelQuotaIsParent],
 			},
+			Annotations: map[string]string{
+				extension.AnnotationQuotaSchedules: q.Annotations[extension.AnnotationQuotaSchedules],
+			},
 		},
 		Spec: *q.Spec.DeepCopy(),
 	}
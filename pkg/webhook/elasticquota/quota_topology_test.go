@@ -90,6 +90,49 @@ func TestQuotaTopology_basicItemCheck(t *testing.T) {
 			quota: MakeQuota("temp").sharedWeight(MakeResourceList().CPU(-1).Mem(1048576).Obj()).Obj(),
 			err:   fmt.Errorf("%v quota.Annotation[%v]'s value < 0, in dimension :%v", "temp", extension.AnnotationSharedWeight, "[cpu]"),
 		},
+		{
+			name:  "invalid schedule",
+			quota: MakeQuota("temp").schedules(`[{"name":"night","start":"22:00","end":"06:00","weekdays":["Someday"]}]`).Obj(),
+			err: fmt.Errorf("%v quota.Annotation[%v] is invalid, err: %v", "temp", extension.AnnotationQuotaSchedules,
+				`invalid weekday "Someday" of quota schedule "night"`),
+		},
+		{
+			name:  "schedule max <0",
+			quota: MakeQuota("temp").schedules(`[{"name":"night","start":"22:00","end":"06:00","max":{"cpu":"-1"}}]`).Obj(),
+			err:   fmt.Errorf("%v quota schedule %v's max < 0, in dimensions :%v", "temp", "night", "[cpu]"),
+		},
+		{
+			name: "schedule min > max",
+			quota: MakeQuota("temp").Min(MakeResourceList().CPU(10).Obj()).Max(MakeResourceList().CPU(20).Obj()).
+				schedules(`[{"name":"night","start":"22:00","end":"06:00","min":{"cpu":"30"}}]`).Obj(),
+			err: fmt.Errorf("%v quota schedule %v's min :%v > max,%v", "temp", "night",
+				v1.ResourceList{v1.ResourceCPU: resource.MustParse("30")}, MakeResourceList().CPU(20).Obj()),
+		},
+		{
+			name:  "invalid batch tier",
+			quota: MakeQuota("temp").batchTier(`{"max":`).Obj(),
+			err: fmt.Errorf("%v quota.Annotation[%v] is invalid, err: %v", "temp", extension.AnnotationQuotaBatchTier,
+				"unexpected end of JSON input"),
+		},
+		{
+			name:  "batch tier max <0",
+			quota: MakeQuota("temp").batchTier(`{"max":{"koordinator.sh/batch-cpu":"-1"}}`).Obj(),
+			err:   fmt.Errorf("%v quota batch tier's max < 0, in dimensions :%v", "temp", "[koordinator.sh/batch-cpu]"),
+		},
+		{
+			name: "batch tier min > max",
+			quota: MakeQuota("temp").Max(MakeResourceList().CPU(20).Obj()).
+				batchTier(`{"min":{"koordinator.sh/batch-cpu":"20000"},"max":{"koordinator.sh/batch-cpu":"10000"}}`).Obj(),
+			err: fmt.Errorf("%v quota batch tier's min :%v > max,%v", "temp",
+				v1.ResourceList{extension.BatchCPU: resource.MustParse("20000")},
+				v1.ResourceList{extension.BatchCPU: resource.MustParse("10000")}),
+		},
+		{
+			name: "admit batch tier",
+			quota: MakeQuota("temp").Max(MakeResourceList().CPU(20).Obj()).
+				batchTier(`{"min":{"koordinator.sh/batch-cpu":"10000"},"max":{"koordinator.sh/batch-cpu":"40000"}}`).Obj(),
+			err: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -293,6 +336,15 @@ func TestQuotaTopology_ValidAddQuota(t *testing.T) {
 	assert.Equal(t, 4, len(qt.quotaHierarchyInfo))
 	assert.Equal(t, 2, len(qt.quotaHierarchyInfo["temp"]))
 
+	// the schedule window exceeding the parent's min is rejected
+	sub3 := MakeQuota("sub-3").ParentName("temp").Max(MakeResourceList().CPU(120).Mem(1048576).Obj()).
+		Min(MakeResourceList().CPU(16).Mem(12800).Obj()).IsParent(false).
+		schedules(`[{"name":"night","start":"22:00","end":"06:00","min":{"cpu":"40","memory":"12800"}}]`).Obj()
+	qt.fillQuotaDefaultInformation(sub3)
+	err = qt.ValidAddQuota(sub3)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, len(qt.quotaInfoMap))
+
 	err = qt.ValidAddQuota(nil)
 	assert.NotNil(t, err)
 }
@@ -350,6 +402,14 @@ func TestQuotaTopology_ValidUpdateQuota(t *testing.T) {
 	assert.Equal(t, 0, len(qt.quotaHierarchyInfo["temp"]))
 	assert.Equal(t, 1, len(qt.quotaHierarchyInfo["temp2"]))
 
+	// the schedules changed alone are validated
+	sub1.Spec.Min = MakeResourceList().CPU(60).Mem(12800).Obj()
+	oldSub1 := sub1.DeepCopy()
+	sub1.Annotations[extension.AnnotationQuotaSchedules] = `[{"name":"night","start":"22:00","end":"06:00","min":{"cpu":"200"}}]`
+	err = qt.ValidUpdateQuota(oldSub1, sub1)
+	assert.NotNil(t, err)
+	delete(sub1.Annotations, extension.AnnotationQuotaSchedules)
+
 	sub1.Name = "tmp"
 	err = qt.ValidUpdateQuota(nil, sub1)
 	assert.Equal(t, "quota not exist in quotaInfoMap:tmp", err.Error())
@@ -638,6 +698,16 @@ func (q *quotaWrapper) sharedWeight(sharedWeight v1.ResourceList) *quotaWrapper
 	return q
 }
 
+func (q *quotaWrapper) schedules(schedules string) *quotaWrapper {
+	q.ElasticQuota.Annotations[extension.AnnotationQuotaSchedules] = schedules
+	return q
+}
+
+func (q *quotaWrapper) batchTier(batchTier string) *quotaWrapper {
+	q.ElasticQuota.Annotations[extension.AnnotationQuotaBatchTier] = batchTier
+	return q
+}
+
 func (q *quotaWrapper) IsParent(isParent bool) *quotaWrapper {
 	if isParent {
 		q.Labels[extension.LabelQuotaIsParent] = "true"
@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func (h *PodValidatingHandler) annotationProtocolValidatingPod(ctx context.Context, req admission.Request) (bool, string, error) {
+	newPod := &corev1.Pod{}
+	if err := h.Decoder.DecodeRaw(req.Object, newPod); err != nil {
+		return false, "", err
+	}
+	annotations := newPod.Annotations
+	if req.Operation == admissionv1.Update {
+		oldPod := &corev1.Pod{}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldPod); err != nil {
+			return false, "", err
+		}
+		// only validate the changed annotations, so that the pods created before the validation are still updatable.
+		annotations = map[string]string{}
+		for k, v := range newPod.Annotations {
+			if oldValue, ok := oldPod.Annotations[k]; !ok || oldValue != v {
+				annotations[k] = v
+			}
+		}
+	}
+
+	allErrs := extension.ValidateAnnotations(annotations, field.NewPath("metadata", "annotations"))
+	if err := allErrs.ToAggregate(); err != nil {
+		return false, err.Error(), nil
+	}
+	return true, "", nil
+}
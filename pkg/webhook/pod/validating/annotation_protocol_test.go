@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
+)
+
+func TestAnnotationProtocolValidatingPod(t *testing.T) {
+	podWithAnnotations := func(annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-pod",
+				Annotations: annotations,
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		operation   admissionv1.Operation
+		oldPod      *corev1.Pod
+		newPod      *corev1.Pod
+		wantAllowed bool
+		wantReason  string
+	}{
+		{
+			name:        "pod without annotations",
+			operation:   admissionv1.Create,
+			newPod:      podWithAnnotations(nil),
+			wantAllowed: true,
+		},
+		{
+			name:      "valid resource spec",
+			operation: admissionv1.Create,
+			newPod: podWithAnnotations(map[string]string{
+				extension.AnnotationResourceSpec: `{"preferredCPUBindPolicy":"FullPCPUs"}`,
+			}),
+			wantAllowed: true,
+		},
+		{
+			name:      "invalid resource spec",
+			operation: admissionv1.Create,
+			newPod: podWithAnnotations(map[string]string{
+				extension.AnnotationResourceSpec: `{"preferredCPUBindPolicy":"Unknown"}`,
+			}),
+			wantAllowed: false,
+			wantReason:  `metadata.annotations[scheduling.koordinator.sh/resource-spec].preferredCPUBindPolicy: Unsupported value: "Unknown": supported values: "Default", "FullPCPUs", "SpreadByPCPUs", "ConstrainedBurst"`,
+		},
+		{
+			name:      "unchanged invalid annotation on update",
+			operation: admissionv1.Update,
+			oldPod: podWithAnnotations(map[string]string{
+				extension.AnnotationResourceSpec: `{"preferredCPUBindPolicy":"Unknown"}`,
+			}),
+			newPod: podWithAnnotations(map[string]string{
+				extension.AnnotationResourceSpec: `{"preferredCPUBindPolicy":"Unknown"}`,
+				"foo":                            "bar",
+			}),
+			wantAllowed: true,
+		},
+		{
+			name:      "changed invalid annotation on update",
+			operation: admissionv1.Update,
+			oldPod:    podWithAnnotations(nil),
+			newPod: podWithAnnotations(map[string]string{
+				extension.AnnotationDeviceAllocated: `{"gpu":[{"minor":-1}]}`,
+			}),
+			wantAllowed: false,
+			wantReason:  `metadata.annotations[scheduling.koordinator.sh/device-allocated][gpu][0].minor: Invalid value: -1: must be greater than or equal to 0`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().Build()
+			decoder, _ := admission.NewDecoder(scheme.Scheme)
+			h := &PodValidatingHandler{
+				Client:  client,
+				Decoder: decoder,
+			}
+
+			var objRawExt, oldObjRawExt runtime.RawExtension
+			if tt.newPod != nil {
+				objRawExt = runtime.RawExtension{
+					Raw: []byte(util.DumpJSON(tt.newPod)),
+				}
+			}
+			if tt.oldPod != nil {
+				oldObjRawExt = runtime.RawExtension{
+					Raw: []byte(util.DumpJSON(tt.oldPod)),
+				}
+			}
+
+			req := newAdmissionRequest(tt.operation, objRawExt, oldObjRawExt, "pods")
+			gotAllowed, gotReason, err := h.annotationProtocolValidatingPod(context.TODO(), admission.Request{AdmissionRequest: req})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAllowed, gotAllowed)
+			assert.Equal(t, tt.wantReason, gotReason)
+		})
+	}
+}
+
+func TestAnnotationProtocolValidationFeatureGate(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-pod",
+			Annotations: map[string]string{
+				extension.AnnotationResourceSpec: `{"preferredCPUBindPolicy":"Unknown"}`,
+			},
+		},
+	}
+	objRawExt := runtime.RawExtension{
+		Raw: []byte(util.DumpJSON(pod)),
+	}
+	req := newAdmissionRequest(admissionv1.Create, objRawExt, runtime.RawExtension{}, "")
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("feature gate enabled %v", enabled), func(t *testing.T) {
+			defer utilfeature.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.PodAnnotationProtocolValidation, enabled)()
+			h := makeTestHandler()
+			response := h.Handle(context.TODO(), admission.Request{AdmissionRequest: req})
+			assert.Equal(t, !enabled, response.Allowed)
+		})
+	}
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sloconfig "github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+)
+
+const (
+	evictionBudgetMaxWindow = 24 * time.Hour
+
+	// EvictionBudgetRecordsConfigMap is the configmap in the namespace of the slo-controller configmap recording the
+	// admitted evictions, which is shared by the webhook replicas and kept across the restarts.
+	EvictionBudgetRecordsConfigMap = "koord-eviction-budget-records"
+	evictionBudgetRecordsKey       = "records"
+
+	// evictionAdmissionGracePeriod is the time for the admitted eviction to delete the pod. The pod still running after
+	// it means the eviction is rejected after the webhook, e.g. by the PodDisruptionBudget, and it is not counted.
+	evictionAdmissionGracePeriod = 30 * time.Second
+)
+
+// EvictionBudgetValidatingHandler limits the Pod evictions initiated by koordinator components
+// according to the ClusterEvictionBudgets configured in the slo-controller configmap.
+// The evictions are recorded in a configmap, so the webhook replicas enforce the budgets together.
+type EvictionBudgetValidatingHandler struct {
+	Client client.Client
+	// APIReader reads the records from the apiserver directly instead of the cache which may be out of date.
+	APIReader client.Reader
+
+	// lock serializes the updates of the records in the replica to reduce the conflicts.
+	lock sync.Mutex
+	now  func() time.Time
+}
+
+var _ admission.Handler = &EvictionBudgetValidatingHandler{}
+
+func NewEvictionBudgetValidatingHandler() *EvictionBudgetValidatingHandler {
+	return &EvictionBudgetValidatingHandler{
+		now: time.Now,
+	}
+}
+
+func shouldIgnoreIfNotEviction(req admission.Request) bool {
+	return req.Operation != admissionv1.Create ||
+		req.AdmissionRequest.Resource.Resource != "pods" ||
+		req.AdmissionRequest.SubResource != "eviction"
+}
+
+// Handle handles admission requests.
+func (h *EvictionBudgetValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if shouldIgnoreIfNotEviction(req) {
+		return admission.ValidationResponse(true, "")
+	}
+
+	eviction := &policyv1.Eviction{}
+	if err := json.Unmarshal(req.Object.Raw, eviction); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	initiator := eviction.Annotations[extension.AnnotationEvictionInitiator]
+	if initiator == "" {
+		return admission.ValidationResponse(true, "")
+	}
+
+	budgetCfg, err := h.getEvictionBudgetCfg(ctx)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if budgetCfg == nil || len(budgetCfg.Budgets) == 0 {
+		return admission.ValidationResponse(true, "")
+	}
+
+	pod := &corev1.Pod{}
+	if err := h.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: req.Name}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return admission.ValidationResponse(true, "")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	dryRun := (req.DryRun != nil && *req.DryRun) ||
+		(eviction.DeleteOptions != nil && len(eviction.DeleteOptions.DryRun) > 0)
+	budgets := matchEvictionBudgets(budgetCfg.Budgets, initiator, pod)
+	exceeded, err := h.tryAcquire(ctx, budgets, pod, dryRun)
+	if err != nil {
+		klog.Errorf("failed to record eviction of pod %s/%s, err: %v", pod.Namespace, pod.Name, err)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if exceeded != nil {
+		message := fmt.Sprintf("eviction of pod %s/%s by %s is limited by eviction budget %q, at most %d evictions in %v",
+			pod.Namespace, pod.Name, initiator, exceeded.budget.Name, exceeded.budget.MaxEvictions, exceeded.budget.Window.Duration)
+		klog.V(4).Info(message)
+		return admission.Response{
+			AdmissionResponse: admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result: &metav1.Status{
+					Code:    http.StatusTooManyRequests,
+					Reason:  metav1.StatusReasonTooManyRequests,
+					Message: message,
+				},
+			},
+		}
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+func (h *EvictionBudgetValidatingHandler) getEvictionBudgetCfg(ctx context.Context) (*extension.EvictionBudgetCfg, error) {
+	configMap := &corev1.ConfigMap{}
+	err := h.Client.Get(ctx, types.NamespacedName{Namespace: sloconfig.ConfigNameSpace, Name: sloconfig.SLOCtrlConfigMap}, configMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cfgStr, ok := configMap.Data[extension.EvictionBudgetConfigKey]
+	if !ok {
+		return nil, nil
+	}
+	cfg := &extension.EvictionBudgetCfg{}
+	if err := json.Unmarshal([]byte(cfgStr), cfg); err != nil {
+		klog.Warningf("failed to unmarshal config %s, err: %s", extension.EvictionBudgetConfigKey, err)
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// matchedEvictionBudget is a budget matched by the eviction and the key the eviction is counted by.
+type matchedEvictionBudget struct {
+	budget *extension.ClusterEvictionBudget
+	key    string
+}
+
+func matchEvictionBudgets(budgets []extension.ClusterEvictionBudget, initiator string, pod *corev1.Pod) []matchedEvictionBudget {
+	var matched []matchedEvictionBudget
+	for i := range budgets {
+		budget := &budgets[i]
+		if budget.MaxEvictions < 0 || budget.Window.Duration <= 0 || budget.Window.Duration > evictionBudgetMaxWindow {
+			klog.V(5).Infof("skip invalid eviction budget %q", budget.Name)
+			continue
+		}
+		if len(budget.Initiators) > 0 && !containsString(budget.Initiators, initiator) {
+			continue
+		}
+		if len(budget.Namespaces) > 0 && !containsString(budget.Namespaces, pod.Namespace) {
+			continue
+		}
+		if budget.PodSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(budget.PodSelector)
+			if err != nil {
+				klog.V(5).Infof("skip eviction budget %q with invalid podSelector, err: %v", budget.Name, err)
+				continue
+			}
+			if !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+		}
+
+		budgetName := budget.Name
+		if budgetName == "" {
+			budgetName = fmt.Sprintf("#%d", i)
+		}
+		key := fmt.Sprintf("%s/%s", budgetName, pod.Namespace)
+		if budget.Scope != extension.EvictionBudgetScopeNamespace {
+			if owner := metav1.GetControllerOf(pod); owner != nil {
+				key = fmt.Sprintf("%s/%s/%s", key, owner.Kind, owner.Name)
+			} else {
+				key = fmt.Sprintf("%s/Pod/%s", key, pod.Name)
+			}
+		}
+		matched = append(matched, matchedEvictionBudget{budget: budget, key: key})
+	}
+	return matched
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// evictionRecord is an eviction admitted by the webhook.
+type evictionRecord struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	UID       types.UID   `json:"uid"`
+	Time      metav1.Time `json:"time"`
+	// Confirmed indicates the pod has been deleted after the eviction, i.e. the eviction has taken effect.
+	Confirmed bool `json:"confirmed,omitempty"`
+}
+
+func (r *evictionRecord) isOf(pod *corev1.Pod) bool {
+	return r.Namespace == pod.Namespace && r.Name == pod.Name && r.UID == pod.UID
+}
+
+// evictionRecords are the admitted evictions of each counting key.
+type evictionRecords map[string][]evictionRecord
+
+// tryAcquire records the eviction if none of the budgets are exceeded, otherwise it returns the exceeded one.
+// The records are updated with the optimistic concurrency, so the budgets are shared by the webhook replicas.
+func (h *EvictionBudgetValidatingHandler) tryAcquire(ctx context.Context, budgets []matchedEvictionBudget, pod *corev1.Pod, dryRun bool) (*matchedEvictionBudget, error) {
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	var exceeded *matchedEvictionBudget
+	err := retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}, func() error {
+		exceeded = nil
+		configMap, records, err := h.getEvictionRecords(ctx)
+		if err != nil {
+			return err
+		}
+		now := h.now()
+		changed := h.pruneEvictionRecords(ctx, records, now)
+		for i := range budgets {
+			b := &budgets[i]
+			since := now.Add(-b.budget.Window.Duration)
+			count := 0
+			for _, record := range records[b.key] {
+				// the retried eviction of the same pod is counted once
+				if !record.isOf(pod) && record.Time.After(since) {
+					count++
+				}
+			}
+			if count >= int(b.budget.MaxEvictions) {
+				exceeded = b
+				break
+			}
+		}
+		if exceeded == nil && !dryRun {
+			for _, b := range budgets {
+				keyRecords := records[b.key][:0]
+				for _, record := range records[b.key] {
+					if !record.isOf(pod) {
+						keyRecords = append(keyRecords, record)
+					}
+				}
+				records[b.key] = append(keyRecords, evictionRecord{
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+					UID:       pod.UID,
+					Time:      metav1.NewTime(now),
+				})
+			}
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return h.saveEvictionRecords(ctx, configMap, records)
+	})
+	return exceeded, err
+}
+
+// pruneEvictionRecords removes the records out of the max window, and the ones whose pods are still running after the
+// grace period, i.e. the evictions rejected after the webhook. It returns whether any record is changed.
+func (h *EvictionBudgetValidatingHandler) pruneEvictionRecords(ctx context.Context, records evictionRecords, now time.Time) bool {
+	changed := false
+	for key, keyRecords := range records {
+		pruned := keyRecords[:0]
+		for _, record := range keyRecords {
+			if now.Sub(record.Time.Time) > evictionBudgetMaxWindow {
+				changed = true
+				continue
+			}
+			if !record.Confirmed && now.Sub(record.Time.Time) > evictionAdmissionGracePeriod {
+				pod := &corev1.Pod{}
+				err := h.Client.Get(ctx, types.NamespacedName{Namespace: record.Namespace, Name: record.Name}, pod)
+				if errors.IsNotFound(err) || (err == nil && (pod.UID != record.UID || pod.DeletionTimestamp != nil)) {
+					record.Confirmed = true
+					changed = true
+				} else if err == nil {
+					klog.V(5).Infof("eviction of pod %s/%s is not counted since the pod is still running", record.Namespace, record.Name)
+					changed = true
+					continue
+				}
+			}
+			pruned = append(pruned, record)
+		}
+		if len(pruned) == 0 {
+			delete(records, key)
+		} else {
+			records[key] = pruned
+		}
+	}
+	return changed
+}
+
+func (h *EvictionBudgetValidatingHandler) getEvictionRecords(ctx context.Context) (*corev1.ConfigMap, evictionRecords, error) {
+	reader := h.APIReader
+	if reader == nil {
+		reader = h.Client
+	}
+	configMap := &corev1.ConfigMap{}
+	err := reader.Get(ctx, types.NamespacedName{Namespace: sloconfig.ConfigNameSpace, Name: EvictionBudgetRecordsConfigMap}, configMap)
+	if errors.IsNotFound(err) {
+		return nil, evictionRecords{}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	records := evictionRecords{}
+	if data := configMap.Data[evictionBudgetRecordsKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &records); err != nil {
+			klog.Warningf("failed to unmarshal eviction records, reset them, err: %v", err)
+			records = evictionRecords{}
+		}
+	}
+	return configMap, records, nil
+}
+
+func (h *EvictionBudgetValidatingHandler) saveEvictionRecords(ctx context.Context, configMap *corev1.ConfigMap, records evictionRecords) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: sloconfig.ConfigNameSpace,
+				Name:      EvictionBudgetRecordsConfigMap,
+			},
+			Data: map[string]string{evictionBudgetRecordsKey: string(data)},
+		}
+		return h.Client.Create(ctx, configMap)
+	}
+	configMap = configMap.DeepCopy()
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[evictionBudgetRecordsKey] = string(data)
+	return h.Client.Update(ctx, configMap)
+}
+
+var _ inject.Client = &EvictionBudgetValidatingHandler{}
+
+// InjectClient injects the client into the EvictionBudgetValidatingHandler
+func (h *EvictionBudgetValidatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ inject.APIReader = &EvictionBudgetValidatingHandler{}
+
+// InjectAPIReader injects the reader of the apiserver into the EvictionBudgetValidatingHandler
+func (h *EvictionBudgetValidatingHandler) InjectAPIReader(reader client.Reader) error {
+	h.APIReader = reader
+	return nil
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	sloconfig "github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+func newEvictionRequest(pod *corev1.Pod, initiator string) admission.Request {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		},
+	}
+	if initiator != "" {
+		eviction.Annotations = map[string]string{extension.AnnotationEvictionInitiator: initiator}
+	}
+	req := newAdmissionRequest(admissionv1.Create, runtime.RawExtension{Raw: []byte(util.DumpJSON(eviction))}, runtime.RawExtension{}, "eviction")
+	req.Namespace = pod.Namespace
+	req.Name = pod.Name
+	return admission.Request{AdmissionRequest: req}
+}
+
+func newEvictionTestPod(name, owner string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			Labels:    map[string]string{"app": "test"},
+		},
+	}
+	if owner != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: owner, UID: "uid", Controller: pointer.Bool(true)},
+		}
+	}
+	return pod
+}
+
+func TestEvictionBudgetValidatingHandler(t *testing.T) {
+	pods := []*corev1.Pod{
+		newEvictionTestPod("pod-1", "rs-1"),
+		newEvictionTestPod("pod-2", "rs-1"),
+		newEvictionTestPod("pod-3", "rs-2"),
+		newEvictionTestPod("pod-4", ""),
+	}
+	type eviction struct {
+		pod         string
+		initiator   string
+		wantAllowed bool
+	}
+	tests := []struct {
+		name      string
+		budgetCfg *extension.EvictionBudgetCfg
+		evictions []eviction
+	}{
+		{
+			name: "no budget",
+			evictions: []eviction{
+				{pod: "pod-1", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-2", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+			},
+		},
+		{
+			name: "limit evictions per workload",
+			budgetCfg: &extension.EvictionBudgetCfg{
+				Budgets: []extension.ClusterEvictionBudget{
+					{Name: "test", MaxEvictions: 1, Window: metav1.Duration{Duration: time.Hour}},
+				},
+			},
+			evictions: []eviction{
+				{pod: "pod-1", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-2", initiator: extension.EvictionInitiatorDescheduler, wantAllowed: false},
+				{pod: "pod-3", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-4", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-2", initiator: "", wantAllowed: true},
+			},
+		},
+		{
+			name: "limit evictions per namespace",
+			budgetCfg: &extension.EvictionBudgetCfg{
+				Budgets: []extension.ClusterEvictionBudget{
+					{Name: "test", Scope: extension.EvictionBudgetScopeNamespace, MaxEvictions: 2, Window: metav1.Duration{Duration: time.Hour}},
+				},
+			},
+			evictions: []eviction{
+				{pod: "pod-1", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-3", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-4", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: false},
+			},
+		},
+		{
+			name: "budget of specified initiators",
+			budgetCfg: &extension.EvictionBudgetCfg{
+				Budgets: []extension.ClusterEvictionBudget{
+					{
+						Name:         "test",
+						Initiators:   []string{extension.EvictionInitiatorDescheduler},
+						MaxEvictions: 0,
+						Window:       metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			evictions: []eviction{
+				{pod: "pod-1", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+				{pod: "pod-2", initiator: extension.EvictionInitiatorDescheduler, wantAllowed: false},
+			},
+		},
+		{
+			name: "budget not matched",
+			budgetCfg: &extension.EvictionBudgetCfg{
+				Budgets: []extension.ClusterEvictionBudget{
+					{
+						Name:         "test",
+						Namespaces:   []string{"other"},
+						MaxEvictions: 0,
+						Window:       metav1.Duration{Duration: time.Hour},
+					},
+					{
+						Name:         "test-selector",
+						PodSelector:  &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+						MaxEvictions: 0,
+						Window:       metav1.Duration{Duration: time.Hour},
+					},
+				},
+			},
+			evictions: []eviction{
+				{pod: "pod-1", initiator: extension.EvictionInitiatorKoordlet, wantAllowed: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []runtime.Object{}
+			for _, pod := range pods {
+				objs = append(objs, pod)
+			}
+			if tt.budgetCfg != nil {
+				objs = append(objs, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: sloconfig.ConfigNameSpace,
+						Name:      sloconfig.SLOCtrlConfigMap,
+					},
+					Data: map[string]string{
+						extension.EvictionBudgetConfigKey: util.DumpJSON(tt.budgetCfg),
+					},
+				})
+			}
+			h := NewEvictionBudgetValidatingHandler()
+			assert.NoError(t, h.InjectClient(fake.NewClientBuilder().WithRuntimeObjects(objs...).Build()))
+			for _, e := range tt.evictions {
+				resp := h.Handle(context.TODO(), newEvictionRequest(newEvictionTestPod(e.pod, ""), e.initiator))
+				assert.Equal(t, e.wantAllowed, resp.Allowed, e.pod)
+				if !e.wantAllowed {
+					assert.Equal(t, int32(http.StatusTooManyRequests), resp.Result.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestEvictionBudgetValidatingHandler_tryAcquire(t *testing.T) {
+	budget := &extension.ClusterEvictionBudget{Name: "test", MaxEvictions: 1, Window: metav1.Duration{Duration: time.Minute}}
+	budgets := []matchedEvictionBudget{{budget: budget, key: "test/default"}}
+	pod1, pod2, pod3 := newEvictionTestPod("pod-1", ""), newEvictionTestPod("pod-2", ""), newEvictionTestPod("pod-3", "")
+	pod1.UID, pod2.UID, pod3.UID = "uid-1", "uid-2", "uid-3"
+	terminatingPod1 := pod1.DeepCopy()
+	terminatingPod1.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	terminatingPod1.Finalizers = []string{"test"}
+	cli := fake.NewClientBuilder().WithRuntimeObjects(terminatingPod1, pod2, pod3).Build()
+
+	now := time.Now()
+	h := NewEvictionBudgetValidatingHandler()
+	assert.NoError(t, h.InjectClient(cli))
+	h.now = func() time.Time { return now }
+	getRecords := func() evictionRecords {
+		_, records, err := h.getEvictionRecords(context.TODO())
+		assert.NoError(t, err)
+		return records
+	}
+
+	exceeded, err := h.tryAcquire(context.TODO(), budgets, pod1, true)
+	assert.NoError(t, err)
+	assert.Nil(t, exceeded)
+	assert.Empty(t, getRecords(), "dry run doesn't record")
+	exceeded, err = h.tryAcquire(context.TODO(), budgets, pod1, false)
+	assert.NoError(t, err)
+	assert.Nil(t, exceeded)
+	exceeded, err = h.tryAcquire(context.TODO(), budgets, pod1, false)
+	assert.NoError(t, err)
+	assert.Nil(t, exceeded, "retried eviction of the same pod is counted once")
+
+	// the records are shared by the replicas
+	another := NewEvictionBudgetValidatingHandler()
+	assert.NoError(t, another.InjectClient(cli))
+	another.now = func() time.Time { return now.Add(40 * time.Second) }
+	exceeded, err = another.tryAcquire(context.TODO(), budgets, pod2, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, exceeded)
+	assert.True(t, getRecords()["test/default"][0].Confirmed, "pod-1 is being deleted by the eviction")
+
+	// the eviction of pod-2 is admitted out of the window of pod-1, but pod-2 is still running after the grace period
+	now = now.Add(61 * time.Second)
+	exceeded, err = h.tryAcquire(context.TODO(), budgets, pod2, false)
+	assert.NoError(t, err)
+	assert.Nil(t, exceeded)
+	now = now.Add(evictionAdmissionGracePeriod + time.Second)
+	exceeded, err = h.tryAcquire(context.TODO(), budgets, pod3, false)
+	assert.NoError(t, err)
+	assert.Nil(t, exceeded, "rejected eviction is not counted")
+	records := getRecords()["test/default"]
+	assert.Len(t, records, 2)
+	assert.Equal(t, "pod-1", records[0].Name)
+	assert.Equal(t, "pod-3", records[1].Name)
+
+	// the records out of the max window are removed
+	now = now.Add(25 * time.Hour)
+	exceeded, err = h.tryAcquire(context.TODO(), []matchedEvictionBudget{{budget: budget, key: "other"}}, pod2, true)
+	assert.NoError(t, err)
+	assert.Nil(t, exceeded)
+	assert.Empty(t, getRecords())
+}
@@ -26,6 +26,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
 	"github.com/koordinator-sh/koordinator/pkg/webhook/elasticquota"
 )
 
@@ -58,6 +60,14 @@ func (h *PodValidatingHandler) validatingPodFn(ctx context.Context, req admissio
 		return
 	}
 
+	if (req.Operation == admissionv1.Create || req.Operation == admissionv1.Update) &&
+		utilfeature.DefaultFeatureGate.Enabled(features.PodAnnotationProtocolValidation) {
+		allowed, reason, err = h.annotationProtocolValidatingPod(ctx, req)
+		if !allowed || err != nil {
+			return
+		}
+	}
+
 	allowed, reason, err = h.clusterColocationProfileValidatingPod(ctx, req)
 	if err == nil {
 		plugin := elasticquota.NewPlugin(h.Decoder, h.Client)
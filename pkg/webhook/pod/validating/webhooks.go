@@ -22,9 +22,16 @@ import (
 
 // +kubebuilder:webhook:path=/validate-pod,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups="",resources=pods,verbs=create;update,versions=v1,name=vpod.kb.io
 
+// +kubebuilder:webhook:path=/validate-pod-eviction,mutating=false,failurePolicy=ignore,sideEffects=NoneOnDryRun,admissionReviewVersions=v1;v1beta1,groups="",resources=pods/eviction,verbs=create,versions=v1,name=vpodeviction.kb.io
+
 var (
 	// HandlerMap contains admission webhook handlers
 	HandlerMap = map[string]admission.Handler{
 		"validate-pod": &PodValidatingHandler{},
 	}
+
+	// EvictionHandlerMap contains admission webhook handlers of pod evictions
+	EvictionHandlerMap = map[string]admission.Handler{
+		"validate-pod-eviction": NewEvictionBudgetValidatingHandler(),
+	}
 )
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"reflect"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+var (
+	// DefaultReservationTTL is the TTL of the reservations specifying neither ttl nor expires.
+	DefaultReservationTTL = 24 * time.Hour
+	// DefaultReservationSchedulerName is the scheduler name of the reservations whose template does not specify one.
+	DefaultReservationSchedulerName = "koord-scheduler"
+)
+
+func InitFlags(fs *flag.FlagSet) {
+	fs.StringVar(&DefaultReservationSchedulerName, "reservation-default-scheduler-name", DefaultReservationSchedulerName, "determines the scheduler name defaulted to the reservations whose template does not specify one.")
+}
+
+// ReservationMutatingHandler handles Reservation
+type ReservationMutatingHandler struct {
+	Client client.Client
+
+	// Decoder decodes the objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ReservationMutatingHandler{}
+
+func shouldIgnoreIfNotReservations(req admission.Request) bool {
+	// Ignore all calls to sub resources or resources other than reservations.
+	if len(req.AdmissionRequest.SubResource) != 0 ||
+		req.AdmissionRequest.Resource.Resource != "reservations" {
+		return true
+	}
+	return false
+}
+
+func (h *ReservationMutatingHandler) Handle(ctx context.Context, request admission.Request) (resp admission.Response) {
+	if shouldIgnoreIfNotReservations(request) {
+		return admission.Allowed("")
+	}
+
+	obj := &schedulingv1alpha1.Reservation{}
+	if err := h.Decoder.Decode(request, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	copied := obj.DeepCopy()
+	klog.V(5).Infof("Webhook start mutating reservation %s", obj.Name)
+	setReservationDefaults(copied)
+
+	if reflect.DeepEqual(obj, copied) {
+		return admission.Allowed("")
+	}
+	marshaled, err := json.Marshal(copied)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(request.AdmissionRequest.Object.Raw, marshaled)
+}
+
+// setReservationDefaults defaults the expiration and the scheduler name of the reservation.
+func setReservationDefaults(r *schedulingv1alpha1.Reservation) {
+	if r.Spec.TTL == nil && r.Spec.Expires == nil {
+		r.Spec.TTL = &metav1.Duration{Duration: DefaultReservationTTL}
+	}
+	if r.Spec.Template != nil && len(r.Spec.Template.Spec.SchedulerName) <= 0 {
+		r.Spec.Template.Spec.SchedulerName = DefaultReservationSchedulerName
+	}
+}
+
+var _ inject.Client = &ReservationMutatingHandler{}
+
+// InjectClient injects the client into the ReservationMutatingHandler
+func (h *ReservationMutatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ReservationMutatingHandler{}
+
+// InjectDecoder injects the decoder into the ReservationMutatingHandler
+func (h *ReservationMutatingHandler) InjectDecoder(decoder *admission.Decoder) error {
+	h.Decoder = decoder
+	return nil
+}
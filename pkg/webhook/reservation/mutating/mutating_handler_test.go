@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+func makeTestHandler() *ReservationMutatingHandler {
+	client := fake.NewClientBuilder().Build()
+	sche := client.Scheme()
+	_ = schedulingv1alpha1.AddToScheme(sche)
+	decoder, _ := admission.NewDecoder(sche)
+	handler := &ReservationMutatingHandler{}
+	_ = handler.InjectClient(client)
+	_ = handler.InjectDecoder(decoder)
+	return handler
+}
+
+func gvr(resource string) metav1.GroupVersionResource {
+	return metav1.GroupVersionResource{
+		Group:    schedulingv1alpha1.GroupVersion.Group,
+		Version:  schedulingv1alpha1.GroupVersion.Version,
+		Resource: resource,
+	}
+}
+
+func TestReservationMutatingHandler_Handle(t *testing.T) {
+	handler := makeTestHandler()
+
+	resp := handler.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource:  gvr("pods"),
+			Operation: admissionv1.Create,
+		},
+	})
+	assert.True(t, resp.Allowed)
+
+	resp = handler.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource:  gvr("reservations"),
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{},
+		},
+	})
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, int32(http.StatusBadRequest), resp.Result.Code)
+
+	reservation := &schedulingv1alpha1.Reservation{
+		TypeMeta:   metav1.TypeMeta{Kind: "Reservation", APIVersion: schedulingv1alpha1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-reservation"},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+		},
+	}
+	raw, err := json.Marshal(reservation)
+	assert.NoError(t, err)
+	resp = handler.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource:  gvr("reservations"),
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+	assert.True(t, resp.Allowed)
+	assert.Len(t, resp.Patches, 2)
+
+	// nothing to default
+	reservation.Spec.Template.Spec.SchedulerName = "koord-scheduler"
+	reservation.Spec.Expires = &metav1.Time{Time: time.Now()}
+	raw, err = json.Marshal(reservation)
+	assert.NoError(t, err)
+	resp = handler.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource:  gvr("reservations"),
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	})
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+}
+
+func Test_setReservationDefaults(t *testing.T) {
+	reservation := &schedulingv1alpha1.Reservation{
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{},
+		},
+	}
+	setReservationDefaults(reservation)
+	assert.Equal(t, &metav1.Duration{Duration: 24 * time.Hour}, reservation.Spec.TTL)
+	assert.Equal(t, "koord-scheduler", reservation.Spec.Template.Spec.SchedulerName)
+
+	// keep the specified fields
+	reservation = &schedulingv1alpha1.Reservation{
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{Spec: corev1.PodSpec{SchedulerName: "other-scheduler"}},
+			TTL:      &metav1.Duration{},
+		},
+	}
+	setReservationDefaults(reservation)
+	assert.Equal(t, &metav1.Duration{}, reservation.Spec.TTL)
+	assert.Equal(t, "other-scheduler", reservation.Spec.Template.Spec.SchedulerName)
+
+	// the expires is specified
+	reservation = &schedulingv1alpha1.Reservation{
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Expires: &metav1.Time{Time: time.Now()},
+		},
+	}
+	setReservationDefaults(reservation)
+	assert.Nil(t, reservation.Spec.TTL)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/v1/resource"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	sloconfig "github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+)
+
+// defaultReservationTTL is the TTL of the Reservations specifying neither ttl nor expires.
+const defaultReservationTTL = 24 * time.Hour
+
+// validateReservationNamespaceLimit rejects the reservation if it exceeds the limits of its namespace configured in
+// the slo-controller configmap. The pending reservations are counted as well as the active ones, since they hold
+// the resources once scheduled. Updates are only checked when they extend the requests or the lifetime, so that the
+// reservations admitted before the limits are tightened can still be changed otherwise.
+func (h *ReservationValidatingHandler) validateReservationNamespaceLimit(ctx context.Context, oldR, newR *schedulingv1alpha1.Reservation) field.ErrorList {
+	if newR.Spec.Template == nil || reservationutil.IsReservationFailed(newR) || reservationutil.IsReservationSucceeded(newR) {
+		return nil
+	}
+	limitCfg, err := h.getReservationLimitCfg(ctx)
+	if err != nil {
+		klog.Errorf("Failed to get reservation limits for reservation %s, err: %v", newR.Name, err)
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), err)}
+	}
+	if limitCfg == nil {
+		return nil
+	}
+	reservePod := reservationutil.NewReservePod(newR)
+	limit := matchReservationNamespaceLimit(limitCfg.NamespaceLimits, reservePod.Namespace)
+	if limit == nil {
+		return nil
+	}
+	limitName := limit.Name
+	if limitName == "" {
+		limitName = reservePod.Namespace
+	}
+
+	var allErrs field.ErrorList
+	if limit.MaxTTL != nil {
+		ttl, expiring := getReservationLifetime(newR)
+		extended := true
+		if oldR != nil {
+			oldTTL, oldExpiring := getReservationLifetime(oldR)
+			extended = (oldExpiring && !expiring) || (expiring && ttl > oldTTL)
+		}
+		if extended && (!expiring || ttl > limit.MaxTTL.Duration) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "ttl"),
+				fmt.Sprintf("exceeded reservation limit %s of namespace %s, the reservation must expire within %v",
+					limitName, reservePod.Namespace, limit.MaxTTL.Duration)))
+		}
+	}
+
+	if len(limit.Max) == 0 {
+		return allErrs
+	}
+	requests, _ := resource.PodRequestsAndLimits(reservePod)
+	if oldR != nil && oldR.Spec.Template != nil {
+		oldRequests, _ := resource.PodRequestsAndLimits(&corev1.Pod{Spec: oldR.Spec.Template.Spec})
+		if quotav1.IsZero(quotav1.Mask(quotav1.SubtractWithNonNegativeResult(requests, oldRequests), quotav1.ResourceNames(limit.Max))) {
+			return allErrs
+		}
+	}
+	reservationList := &schedulingv1alpha1.ReservationList{}
+	if err := h.Client.List(ctx, reservationList); err != nil {
+		klog.Errorf("Failed to list reservations for namespace %s, err: %v", reservePod.Namespace, err)
+		return append(allErrs, field.InternalError(field.NewPath("spec", "template"), err))
+	}
+	used := corev1.ResourceList{}
+	for i := range reservationList.Items {
+		r := &reservationList.Items[i]
+		if r.Name == newR.Name || r.Spec.Template == nil ||
+			reservationutil.IsReservationFailed(r) || reservationutil.IsReservationSucceeded(r) {
+			continue
+		}
+		pod := reservationutil.NewReservePod(r)
+		if pod.Namespace != reservePod.Namespace {
+			continue
+		}
+		podRequests, _ := resource.PodRequestsAndLimits(pod)
+		used = quotav1.Add(used, podRequests)
+	}
+	if ok, exceeded := quotav1.LessThanOrEqual(quotav1.Add(used, requests), limit.Max); !ok {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "template", "spec"),
+			fmt.Sprintf("exceeded reservation limit %s of namespace %s on %v, requested: %s, used: %s, max: %s",
+				limitName, reservePod.Namespace, exceeded, printResourceList(quotav1.Mask(requests, exceeded)),
+				printResourceList(quotav1.Mask(used, exceeded)), printResourceList(quotav1.Mask(limit.Max, exceeded)))))
+	}
+	return allErrs
+}
+
+func (h *ReservationValidatingHandler) getReservationLimitCfg(ctx context.Context) (*apiext.ReservationLimitCfg, error) {
+	configMap := &corev1.ConfigMap{}
+	err := h.Client.Get(ctx, types.NamespacedName{Namespace: sloconfig.ConfigNameSpace, Name: sloconfig.SLOCtrlConfigMap}, configMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	cfgStr, ok := configMap.Data[apiext.ReservationLimitConfigKey]
+	if !ok {
+		return nil, nil
+	}
+	cfg := &apiext.ReservationLimitCfg{}
+	if err := json.Unmarshal([]byte(cfgStr), cfg); err != nil {
+		klog.Warningf("failed to unmarshal config %s, err: %s", apiext.ReservationLimitConfigKey, err)
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// matchReservationNamespaceLimit returns the first limit listing the namespace, or the first limit without any
+// namespaces as the default.
+func matchReservationNamespaceLimit(limits []apiext.ReservationNamespaceLimit, namespace string) *apiext.ReservationNamespaceLimit {
+	var defaultLimit *apiext.ReservationNamespaceLimit
+	for i := range limits {
+		limit := &limits[i]
+		if len(limit.Namespaces) == 0 {
+			if defaultLimit == nil {
+				defaultLimit = limit
+			}
+			continue
+		}
+		for _, ns := range limit.Namespaces {
+			if ns == namespace {
+				return limit
+			}
+		}
+	}
+	return defaultLimit
+}
+
+// getReservationLifetime returns the lifetime of the reservation since created, and false if it never expires.
+func getReservationLifetime(r *schedulingv1alpha1.Reservation) (time.Duration, bool) {
+	if r.Spec.Expires != nil {
+		createTime := r.CreationTimestamp.Time
+		if createTime.IsZero() {
+			createTime = time.Now()
+		}
+		return r.Spec.Expires.Sub(createTime), true
+	}
+	if r.Spec.TTL != nil {
+		return r.Spec.TTL.Duration, r.Spec.TTL.Duration > 0
+	}
+	return defaultReservationTTL, true
+}
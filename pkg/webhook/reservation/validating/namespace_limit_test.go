@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	sloconfig "github.com/koordinator-sh/koordinator/pkg/slo-controller/config"
+)
+
+func TestReservationValidatingHandler_validateReservationNamespaceLimit(t *testing.T) {
+	newReservation := func(name, namespace, cpu string, phase schedulingv1alpha1.ReservationPhase) *schedulingv1alpha1.Reservation {
+		r := newTestReservation()
+		r.Name = name
+		r.Spec.Template.Namespace = namespace
+		r.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+		r.Spec.TTL = &metav1.Duration{Duration: time.Hour}
+		r.Status.Phase = phase
+		return r
+	}
+	withTTL := func(r *schedulingv1alpha1.Reservation, ttl time.Duration) *schedulingv1alpha1.Reservation {
+		r.Spec.TTL = &metav1.Duration{Duration: ttl}
+		return r
+	}
+	limitCfg := &apiext.ReservationLimitCfg{
+		NamespaceLimits: []apiext.ReservationNamespaceLimit{
+			{
+				Name:       "team-a",
+				Namespaces: []string{"team-a"},
+				Max:        corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")},
+			},
+			{
+				Name:   "default",
+				Max:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+				MaxTTL: &metav1.Duration{Duration: 2 * time.Hour},
+			},
+		},
+	}
+	data, err := json.Marshal(limitCfg)
+	assert.NoError(t, err)
+
+	sche := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(sche)
+	_ = schedulingv1alpha1.AddToScheme(sche)
+	client := fake.NewClientBuilder().WithScheme(sche).WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: sloconfig.ConfigNameSpace, Name: sloconfig.SLOCtrlConfigMap},
+			Data:       map[string]string{apiext.ReservationLimitConfigKey: string(data)},
+		},
+		// the pending and active reservations of the namespace are counted
+		newReservation("pending", "team-a", "4", schedulingv1alpha1.ReservationPending),
+		newReservation("active", "team-a", "4", schedulingv1alpha1.ReservationAvailable),
+		newReservation("succeeded", "team-a", "4", schedulingv1alpha1.ReservationSucceeded),
+		newReservation("other", "team-b", "2", schedulingv1alpha1.ReservationAvailable),
+	).Build()
+	handler := &ReservationValidatingHandler{Client: client}
+
+	tests := []struct {
+		name    string
+		oldR    *schedulingv1alpha1.Reservation
+		newR    *schedulingv1alpha1.Reservation
+		wantErr bool
+	}{
+		{
+			name: "fit in the limit of the namespace",
+			newR: newReservation("test", "team-a", "2", ""),
+		},
+		{
+			name:    "exceed the limit of the namespace",
+			newR:    newReservation("test", "team-a", "3", ""),
+			wantErr: true,
+		},
+		{
+			name: "each namespace is limited separately by the default limit",
+			newR: newReservation("test", "team-c", "4", ""),
+		},
+		{
+			name:    "exceed the default limit",
+			newR:    newReservation("test", "team-b", "3", ""),
+			wantErr: true,
+		},
+		{
+			name: "the reservation itself is not counted twice",
+			oldR: newReservation("active", "team-a", "4", schedulingv1alpha1.ReservationAvailable),
+			newR: newReservation("active", "team-a", "6", schedulingv1alpha1.ReservationAvailable),
+		},
+		{
+			name:    "exceed the limit with the increment",
+			oldR:    newReservation("active", "team-a", "4", schedulingv1alpha1.ReservationAvailable),
+			newR:    newReservation("active", "team-a", "7", schedulingv1alpha1.ReservationAvailable),
+			wantErr: true,
+		},
+		{
+			name: "update without extending the requests",
+			oldR: newReservation("other", "team-b", "6", schedulingv1alpha1.ReservationAvailable),
+			newR: newReservation("other", "team-b", "5", schedulingv1alpha1.ReservationAvailable),
+		},
+		{
+			name:    "exceed the max TTL",
+			newR:    withTTL(newReservation("test", "team-c", "1", ""), 3*time.Hour),
+			wantErr: true,
+		},
+		{
+			name:    "never expire with the max TTL",
+			newR:    withTTL(newReservation("test", "team-c", "1", ""), 0),
+			wantErr: true,
+		},
+		{
+			name: "no max TTL for the namespace",
+			newR: withTTL(newReservation("test", "team-a", "1", ""), 0),
+		},
+		{
+			name: "update without extending the TTL",
+			oldR: withTTL(newReservation("other", "team-b", "2", schedulingv1alpha1.ReservationAvailable), 0),
+			newR: withTTL(newReservation("other", "team-b", "2", schedulingv1alpha1.ReservationAvailable), 0),
+		},
+		{
+			name:    "extend the TTL beyond the max TTL",
+			oldR:    newReservation("other", "team-b", "2", schedulingv1alpha1.ReservationAvailable),
+			newR:    withTTL(newReservation("other", "team-b", "2", schedulingv1alpha1.ReservationAvailable), 3*time.Hour),
+			wantErr: true,
+		},
+		{
+			name: "skip the failed reservation",
+			newR: newReservation("test", "team-a", "100", schedulingv1alpha1.ReservationFailed),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := handler.validateReservationNamespaceLimit(context.TODO(), tt.oldR, tt.newR)
+			assert.Equal(t, tt.wantErr, len(errs) > 0, errs.ToAggregate())
+		})
+	}
+}
+
+func TestReservationValidatingHandler_validateReservationNamespaceLimitWithoutConfig(t *testing.T) {
+	handler := makeTestHandler()
+	r := newTestReservation()
+	r.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("1000")
+	assert.Empty(t, handler.validateReservationNamespaceLimit(context.TODO(), nil, r))
+}
@@ -0,0 +1,449 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/api/v1/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	quotav1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/features"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	utilfeature "github.com/koordinator-sh/koordinator/pkg/util/feature"
+	reservationutil "github.com/koordinator-sh/koordinator/pkg/util/reservation"
+	"github.com/koordinator-sh/koordinator/pkg/webhook/elasticquota"
+)
+
+// ReservationQuotaAccountingMode is the quota the reservations are charged to, which must be consistent with the
+// reservationAccountingMode of the ElasticQuota plugin of koord-scheduler.
+var ReservationQuotaAccountingMode = config.ReservationAccountingReservation
+
+func InitFlags(fs *flag.FlagSet) {
+	fs.Var(&reservationAccountingModeValue{mode: &ReservationQuotaAccountingMode}, "reservation-quota-accounting-mode", "determines the quota the reservations are charged to when validating the quota, Reservation or Owner, which should be the same as the reservationAccountingMode of koord-scheduler.")
+}
+
+// reservationAccountingModeValue is the flag value of the reservation accounting mode, which rejects the modes
+// unsupported by koord-scheduler when parsing the flags.
+type reservationAccountingModeValue struct {
+	mode *config.ReservationAccountingMode
+}
+
+func (v *reservationAccountingModeValue) String() string {
+	if v.mode == nil {
+		return ""
+	}
+	return string(*v.mode)
+}
+
+func (v *reservationAccountingModeValue) Set(s string) error {
+	switch mode := config.ReservationAccountingMode(s); mode {
+	case config.ReservationAccountingReservation, config.ReservationAccountingOwner:
+		*v.mode = mode
+		return nil
+	}
+	return fmt.Errorf("unsupported reservation quota accounting mode %q, must be %s or %s", s,
+		config.ReservationAccountingReservation, config.ReservationAccountingOwner)
+}
+
+// ReservationValidatingHandler validates the Reservation
+type ReservationValidatingHandler struct {
+	Client client.Client
+
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ReservationValidatingHandler{}
+
+func shouldIgnoreIfNotReservations(req admission.Request) bool {
+	// Ignore all calls to sub resources or resources other than reservations.
+	if len(req.AdmissionRequest.SubResource) != 0 ||
+		req.AdmissionRequest.Resource.Resource != "reservations" {
+		return true
+	}
+	return false
+}
+
+func (h *ReservationValidatingHandler) Handle(ctx context.Context, request admission.Request) admission.Response {
+	if shouldIgnoreIfNotReservations(request) {
+		return admission.Allowed("")
+	}
+
+	obj := &schedulingv1alpha1.Reservation{}
+	if err := h.Decoder.Decode(request, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	allErrs := validateReservation(obj)
+	var oldObj *schedulingv1alpha1.Reservation
+	if request.Operation == admissionv1.Update && len(request.OldObject.Raw) > 0 {
+		oldObj = &schedulingv1alpha1.Reservation{}
+		if err := h.Decoder.DecodeRaw(request.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		allErrs = append(allErrs, validateReservationUpdate(oldObj, obj)...)
+	}
+	if len(allErrs) == 0 && (request.Operation == admissionv1.Create || request.Operation == admissionv1.Update) {
+		allErrs = append(allErrs, h.validateReservationOwnerQuotas(obj)...)
+	}
+	if len(allErrs) == 0 && utilfeature.DefaultFeatureGate.Enabled(features.ReservationQuotaAdmission) &&
+		(request.Operation == admissionv1.Create || request.Operation == admissionv1.Update) {
+		allErrs = append(allErrs, h.validateReservationQuota(ctx, oldObj, obj)...)
+	}
+	if len(allErrs) == 0 && (request.Operation == admissionv1.Create || request.Operation == admissionv1.Update) {
+		allErrs = append(allErrs, h.validateReservationNamespaceLimit(ctx, oldObj, obj)...)
+	}
+	if len(allErrs) > 0 {
+		klog.V(4).Infof("Webhook rejects reservation %s, err: %v", obj.Name, allErrs.ToAggregate())
+		return admission.ValidationResponse(false, allErrs.ToAggregate().Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+// validateReservation checks the reservation as the scheduler does, and rejects the malformed templates which the
+// scheduler can not reserve resources for.
+func validateReservation(r *schedulingv1alpha1.Reservation) field.ErrorList {
+	specPath := field.NewPath("spec")
+	if err := reservationutil.ValidateReservation(r); err != nil {
+		return field.ErrorList{field.Invalid(specPath, "", err.Error())}
+	}
+
+	var allErrs field.ErrorList
+	templateSpecPath := specPath.Child("template", "spec")
+	if len(r.Spec.Template.Spec.Containers) <= 0 {
+		allErrs = append(allErrs, field.Required(templateSpecPath.Child("containers"), "must specify at least one container"))
+	}
+	validateResources := func(resources corev1.ResourceList, fldPath *field.Path) {
+		for name, quantity := range resources {
+			if quantity.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Key(string(name)), quantity.String(), "must be greater than or equal to 0"))
+			}
+		}
+	}
+	for i := range r.Spec.Template.Spec.InitContainers {
+		container := &r.Spec.Template.Spec.InitContainers[i]
+		fldPath := templateSpecPath.Child("initContainers").Index(i).Child("resources")
+		validateResources(container.Resources.Requests, fldPath.Child("requests"))
+		validateResources(container.Resources.Limits, fldPath.Child("limits"))
+	}
+	for i := range r.Spec.Template.Spec.Containers {
+		container := &r.Spec.Template.Spec.Containers[i]
+		fldPath := templateSpecPath.Child("containers").Index(i).Child("resources")
+		validateResources(container.Resources.Requests, fldPath.Child("requests"))
+		validateResources(container.Resources.Limits, fldPath.Child("limits"))
+	}
+	validateResources(r.Spec.Template.Spec.Overhead, templateSpecPath.Child("overhead"))
+
+	for i, owner := range r.Spec.Owners {
+		if owner.LabelSelector != nil {
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(owner.LabelSelector, specPath.Child("owners").Index(i).Child("labelSelector"))...)
+		}
+	}
+	if r.Spec.TTL != nil && r.Spec.TTL.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("ttl"), r.Spec.TTL.Duration.String(), "must be greater than or equal to 0"))
+	}
+	if _, err := apiext.GetReservationRenewTime(r.Annotations); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "annotations").Key(apiext.AnnotationReservationRenewTime),
+			r.Annotations[apiext.AnnotationReservationRenewTime], "must be a RFC3339 time"))
+	}
+	if len(r.Spec.Taints) > 0 && !r.Spec.CapacityOnly {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("taints"), "only the capacity-only reservation can specify taints"))
+	}
+	allErrs = append(allErrs, validateReservationNodeConstraints(r, specPath)...)
+	return allErrs
+}
+
+// validateReservationNodeConstraints checks the node selector and affinity restricting the nodes of the reservation.
+// The node selector must not select another value of a label than the template does, which no node could match.
+func validateReservationNodeConstraints(r *schedulingv1alpha1.Reservation, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	nodeSelectorPath := specPath.Child("nodeSelector")
+	allErrs = append(allErrs, metav1validation.ValidateLabels(r.Spec.NodeSelector, nodeSelectorPath)...)
+	for key, value := range r.Spec.NodeSelector {
+		if templateValue, ok := r.Spec.Template.Spec.NodeSelector[key]; ok && templateValue != value {
+			allErrs = append(allErrs, field.Invalid(nodeSelectorPath.Key(key), value,
+				fmt.Sprintf("conflicts with the template.spec.nodeSelector %s=%s", key, templateValue)))
+		}
+	}
+
+	nodeAffinity := r.Spec.NodeAffinity
+	if nodeAffinity == nil {
+		return allErrs
+	}
+	nodeAffinityPath := specPath.Child("nodeAffinity")
+	if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+		requiredPath := nodeAffinityPath.Child("requiredDuringSchedulingIgnoredDuringExecution")
+		if len(required.NodeSelectorTerms) == 0 {
+			allErrs = append(allErrs, field.Required(requiredPath.Child("nodeSelectorTerms"), "must have at least one node selector term"))
+		} else if _, err := nodeaffinity.NewNodeSelector(required, field.WithPath(requiredPath)); err != nil {
+			allErrs = append(allErrs, toFieldErrors(err, requiredPath)...)
+		}
+	}
+	preferredPath := nodeAffinityPath.Child("preferredDuringSchedulingIgnoredDuringExecution")
+	for i, term := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if term.Weight <= 0 || term.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(preferredPath.Index(i).Child("weight"), term.Weight, "must be in the range 1-100"))
+		}
+	}
+	if _, err := nodeaffinity.NewPreferredSchedulingTerms(nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, field.WithPath(preferredPath)); err != nil {
+		allErrs = append(allErrs, toFieldErrors(err, preferredPath)...)
+	}
+	return allErrs
+}
+
+// toFieldErrors unwraps the parsing errors of the node selectors, which are mostly field errors already.
+func toFieldErrors(err error, fldPath *field.Path) field.ErrorList {
+	var errs []error
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		errs = agg.Errors()
+	} else {
+		errs = []error{err}
+	}
+	var allErrs field.ErrorList
+	for _, e := range errs {
+		if fieldErr, ok := e.(*field.Error); ok {
+			allErrs = append(allErrs, fieldErr)
+		} else {
+			allErrs = append(allErrs, field.Invalid(fldPath, "", e.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateReservationUpdate blocks the mutations to the fields deciding what and whom the resources are reserved
+// for after the reservation is Available, since the scheduler has reserved the resources accordingly. Only the
+// resources of the template can be changed, which the scheduler tries to resize in place on the node.
+func validateReservationUpdate(oldR, newR *schedulingv1alpha1.Reservation) field.ErrorList {
+	if !reservationutil.IsReservationAvailable(oldR) {
+		return nil
+	}
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	checkImmutable := func(oldValue, newValue interface{}, fldPath *field.Path) {
+		if !apiequality.Semantic.DeepEqual(oldValue, newValue) {
+			allErrs = append(allErrs, field.Forbidden(fldPath, "field is immutable after the reservation is Available"))
+		}
+	}
+	checkImmutable(templateWithoutResources(oldR.Spec.Template), templateWithoutResources(newR.Spec.Template), specPath.Child("template"))
+	if oldR.Spec.Template != nil && newR.Spec.Template != nil {
+		oldRequests, _ := resource.PodRequestsAndLimits(&corev1.Pod{Spec: oldR.Spec.Template.Spec})
+		newRequests, _ := resource.PodRequestsAndLimits(&corev1.Pod{Spec: newR.Spec.Template.Spec})
+		if !quotav1.Equals(quotav1.Mask(oldRequests, deviceResourceNames), quotav1.Mask(newRequests, deviceResourceNames)) {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("template", "spec"),
+				"device resources cannot be resized after the reservation is Available, since the devices have been allocated"))
+		}
+	}
+	checkImmutable(oldR.Spec.Owners, newR.Spec.Owners, specPath.Child("owners"))
+	checkImmutable(oldR.Spec.PreAllocation, newR.Spec.PreAllocation, specPath.Child("preAllocation"))
+	checkImmutable(oldR.Spec.AllocateOnce, newR.Spec.AllocateOnce, specPath.Child("allocateOnce"))
+	checkImmutable(oldR.Spec.AllocatePolicy, newR.Spec.AllocatePolicy, specPath.Child("allocatePolicy"))
+	checkImmutable(oldR.Spec.CapacityOnly, newR.Spec.CapacityOnly, specPath.Child("capacityOnly"))
+	checkImmutable(oldR.Spec.Taints, newR.Spec.Taints, specPath.Child("taints"))
+	checkImmutable(oldR.Spec.NodeSelector, newR.Spec.NodeSelector, specPath.Child("nodeSelector"))
+	checkImmutable(oldR.Spec.NodeAffinity, newR.Spec.NodeAffinity, specPath.Child("nodeAffinity"))
+	return allErrs
+}
+
+// validateReservationQuota rejects the reservation if its ElasticQuota cannot cover the reserved resources, which
+// stops the tenants from holding the resources beyond their quotas with the reservations. The active reservations are
+// charged to the quota used by the scheduler, while the pending ones are not charged until scheduled, so the requests
+// of the pending reservations of the same quota are charged here once they are admitted. An update is only charged
+// with the increment of the requests, so that the admitted reservations can be updated otherwise.
+func (h *ReservationValidatingHandler) validateReservationQuota(ctx context.Context, oldR, newR *schedulingv1alpha1.Reservation) field.ErrorList {
+	if newR.Spec.Template == nil || reservationutil.IsReservationFailed(newR) || reservationutil.IsReservationSucceeded(newR) {
+		return nil
+	}
+	quotaName := h.getReservationQuotaName(newR)
+	if quotaName == "" || quotaName == apiext.DefaultQuotaName {
+		return nil
+	}
+	quota, err := h.getElasticQuota(ctx, quotaName)
+	if err != nil {
+		klog.Errorf("Failed to get ElasticQuota %s for reservation %s, err: %v", quotaName, newR.Name, err)
+		return field.ErrorList{field.InternalError(field.NewPath("spec", "template"), err)}
+	}
+	if quota == nil || len(quota.Spec.Max) == 0 {
+		return nil
+	}
+
+	requests, _ := resource.PodRequestsAndLimits(reservationutil.NewReservePod(newR))
+	var oldRequests corev1.ResourceList
+	if oldR != nil && oldR.Spec.Template != nil && !reservationutil.IsReservationFailed(oldR) &&
+		!reservationutil.IsReservationSucceeded(oldR) && h.getReservationQuotaName(oldR) == quotaName {
+		// the old reservation has been charged to the same quota, by the scheduler if active or here if pending
+		oldRequests, _ = resource.PodRequestsAndLimits(reservationutil.NewReservePod(oldR))
+		requests = quotav1.SubtractWithNonNegativeResult(requests, oldRequests)
+	}
+	if quotav1.IsZero(quotav1.Mask(requests, quotav1.ResourceNames(quota.Spec.Max))) {
+		return nil
+	}
+
+	reservationList := &schedulingv1alpha1.ReservationList{}
+	if err := h.Client.List(ctx, reservationList); err != nil {
+		klog.Errorf("Failed to list reservations for quota %s, err: %v", quotaName, err)
+		return field.ErrorList{field.InternalError(field.NewPath("spec", "template"), err)}
+	}
+	used := quota.Status.Used.DeepCopy()
+	if oldRequests != nil && !reservationutil.IsReservationActive(oldR) {
+		used = quotav1.Add(used, oldRequests)
+	}
+	for i := range reservationList.Items {
+		r := &reservationList.Items[i]
+		if r.Name == newR.Name || r.Spec.Template == nil || reservationutil.IsReservationActive(r) ||
+			reservationutil.IsReservationFailed(r) || reservationutil.IsReservationSucceeded(r) {
+			continue
+		}
+		if h.getReservationQuotaName(r) != quotaName {
+			continue
+		}
+		pendingRequests, _ := resource.PodRequestsAndLimits(reservationutil.NewReservePod(r))
+		used = quotav1.Add(used, pendingRequests)
+	}
+
+	if ok, exceeded := quotav1.LessThanOrEqual(quotav1.Add(used, requests), quota.Spec.Max); !ok {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "template", "spec"),
+			fmt.Sprintf("exceeded quota %s on %v, requested: %s, used: %s, max: %s", quotaName, exceeded,
+				printResourceList(quotav1.Mask(requests, exceeded)), printResourceList(quotav1.Mask(used, exceeded)),
+				printResourceList(quotav1.Mask(quota.Spec.Max, exceeded))))}
+	}
+	return nil
+}
+
+// getReservationQuotaName returns the quota the reservation is charged to as the scheduler does. In the Owner
+// accounting mode, it is the quota label in the owner label selector, or the quota of the namespace of the owner
+// object or controller, and falls back to the quota of the reservation itself if the owners specify no quota.
+func (h *ReservationValidatingHandler) getReservationQuotaName(r *schedulingv1alpha1.Reservation) string {
+	if ReservationQuotaAccountingMode == config.ReservationAccountingOwner {
+		if quotaNames := h.getReservationOwnerQuotaNames(r); quotaNames.Len() == 1 {
+			quotaName, _ := quotaNames.PopAny()
+			return quotaName
+		}
+	}
+	return elasticquota.GetQuotaName(reservationutil.NewReservePod(r), h.Client)
+}
+
+// getReservationOwnerQuotaNames returns the quotas of the reservation owners as the scheduler does.
+func (h *ReservationValidatingHandler) getReservationOwnerQuotaNames(r *schedulingv1alpha1.Reservation) sets.String {
+	return reservationutil.GetReservationOwnerQuotaNames(r, func(namespace string) string {
+		return elasticquota.GetQuotaName(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}}, h.Client)
+	})
+}
+
+// validateReservationOwnerQuotas rejects the reservation whose owners are in different quotas in the Owner accounting
+// mode, since the reservation can only be charged to one quota.
+func (h *ReservationValidatingHandler) validateReservationOwnerQuotas(r *schedulingv1alpha1.Reservation) field.ErrorList {
+	if ReservationQuotaAccountingMode != config.ReservationAccountingOwner {
+		return nil
+	}
+	if quotaNames := h.getReservationOwnerQuotaNames(r); quotaNames.Len() > 1 {
+		return field.ErrorList{field.Invalid(field.NewPath("spec", "owners"), strings.Join(quotaNames.List(), ","),
+			"the owners must be in the same quota in the Owner accounting mode")}
+	}
+	return nil
+}
+
+// getElasticQuota returns the ElasticQuota of the name, or nil if not found. The names of the ElasticQuotas are
+// unique across the namespaces.
+func (h *ReservationValidatingHandler) getElasticQuota(ctx context.Context, quotaName string) (*quotav1alpha1.ElasticQuota, error) {
+	quotaList := &quotav1alpha1.ElasticQuotaList{}
+	if err := h.Client.List(ctx, quotaList); err != nil {
+		return nil, err
+	}
+	for i := range quotaList.Items {
+		if quotaList.Items[i].Name == quotaName {
+			return &quotaList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func printResourceList(rl corev1.ResourceList) string {
+	res := make([]string, 0, len(rl))
+	for k, v := range rl {
+		res = append(res, string(k)+":"+v.String())
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
+
+// deviceResourceNames are the resources allocated by the devices on the node instead of the node capacity.
+var deviceResourceNames = []corev1.ResourceName{
+	apiext.ResourceNvidiaGPU,
+	apiext.ResourceAMDGPU,
+	apiext.ResourceGPU,
+	apiext.ResourceGPUCore,
+	apiext.ResourceGPUMemory,
+	apiext.ResourceGPUMemoryRatio,
+	apiext.ResourceRDMA,
+	apiext.ResourceRDMAVF,
+	apiext.ResourceFPGA,
+	apiext.ResourceNetBandwidth,
+}
+
+// templateWithoutResources returns a copy of the template whose containers and overhead have no resources.
+func templateWithoutResources(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	if template == nil {
+		return nil
+	}
+	template = template.DeepCopy()
+	for i := range template.Spec.InitContainers {
+		template.Spec.InitContainers[i].Resources = corev1.ResourceRequirements{}
+	}
+	for i := range template.Spec.Containers {
+		template.Spec.Containers[i].Resources = corev1.ResourceRequirements{}
+	}
+	template.Spec.Overhead = nil
+	return template
+}
+
+var _ inject.Client = &ReservationValidatingHandler{}
+
+// InjectClient injects the client into the ReservationValidatingHandler
+func (h *ReservationValidatingHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ReservationValidatingHandler{}
+
+// InjectDecoder injects the decoder into the ReservationValidatingHandler
+func (h *ReservationValidatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -0,0 +1,552 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	quotav1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	apiext "github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+)
+
+func makeTestHandler() *ReservationValidatingHandler {
+	client := fake.NewClientBuilder().Build()
+	sche := client.Scheme()
+	_ = schedulingv1alpha1.AddToScheme(sche)
+	decoder, _ := admission.NewDecoder(sche)
+	handler := &ReservationValidatingHandler{}
+	_ = handler.InjectClient(client)
+	_ = handler.InjectDecoder(decoder)
+	return handler
+}
+
+func newTestReservation() *schedulingv1alpha1.Reservation {
+	return &schedulingv1alpha1.Reservation{
+		TypeMeta:   metav1.TypeMeta{Kind: "Reservation", APIVersion: schedulingv1alpha1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-reservation"},
+		Spec: schedulingv1alpha1.ReservationSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "main",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse("4"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Owners: []schedulingv1alpha1.ReservationOwner{
+				{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+				},
+			},
+			TTL: &metav1.Duration{},
+		},
+	}
+}
+
+func TestReservationValidatingHandler_Handle(t *testing.T) {
+	handler := makeTestHandler()
+	newRequest := func(operation admissionv1.Operation, obj, oldObj *schedulingv1alpha1.Reservation) admission.Request {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Resource: metav1.GroupVersionResource{
+					Group:    schedulingv1alpha1.GroupVersion.Group,
+					Version:  schedulingv1alpha1.GroupVersion.Version,
+					Resource: "reservations",
+				},
+				Operation: operation,
+			},
+		}
+		req.Object.Raw, _ = json.Marshal(obj)
+		if oldObj != nil {
+			req.OldObject.Raw, _ = json.Marshal(oldObj)
+		}
+		return req
+	}
+
+	resp := handler.Handle(context.TODO(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Resource:  metav1.GroupVersionResource{Resource: "reservations"},
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{},
+		},
+	})
+	assert.False(t, resp.Allowed)
+
+	reservation := newTestReservation()
+	resp = handler.Handle(context.TODO(), newRequest(admissionv1.Create, reservation, nil))
+	assert.True(t, resp.Allowed)
+
+	invalid := newTestReservation()
+	invalid.Spec.Owners = nil
+	resp = handler.Handle(context.TODO(), newRequest(admissionv1.Create, invalid, nil))
+	assert.False(t, resp.Allowed)
+
+	// the pending reservation can be updated
+	updated := newTestReservation()
+	updated.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("8")
+	resp = handler.Handle(context.TODO(), newRequest(admissionv1.Update, updated, reservation))
+	assert.True(t, resp.Allowed)
+
+	// the available reservation can be resized in place, but the rest of the template is immutable
+	reservation.Status = schedulingv1alpha1.ReservationStatus{
+		Phase:    schedulingv1alpha1.ReservationAvailable,
+		NodeName: "test-node",
+	}
+	updated.Status = reservation.Status
+	resp = handler.Handle(context.TODO(), newRequest(admissionv1.Update, updated, reservation))
+	assert.True(t, resp.Allowed)
+	updated = updated.DeepCopy()
+	updated.Spec.Template.Spec.Containers[0].Image = "test-image:v2"
+	resp = handler.Handle(context.TODO(), newRequest(admissionv1.Update, updated, reservation))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, string(resp.Result.Reason), "spec.template")
+}
+
+func TestReservationValidatingHandler_validateReservationQuota(t *testing.T) {
+	newReservation := func(name, quotaName, cpu string, phase schedulingv1alpha1.ReservationPhase) *schedulingv1alpha1.Reservation {
+		r := newTestReservation()
+		r.Name = name
+		r.Spec.Template.Namespace = "test-ns"
+		if quotaName != "" {
+			r.Spec.Template.Labels = map[string]string{apiext.LabelQuotaName: quotaName}
+		}
+		r.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+		r.Status.Phase = phase
+		if phase == schedulingv1alpha1.ReservationAvailable {
+			r.Status.NodeName = "test-node"
+		}
+		return r
+	}
+	newQuota := func(name, namespace string) *quotav1alpha1.ElasticQuota {
+		return &quotav1alpha1.ElasticQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: quotav1alpha1.ElasticQuotaSpec{
+				Max: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")},
+			},
+			Status: quotav1alpha1.ElasticQuotaStatus{
+				Used: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+		}
+	}
+
+	withLabels := func(r *schedulingv1alpha1.Reservation, labels map[string]string) *schedulingv1alpha1.Reservation {
+		r.Labels = labels
+		return r
+	}
+	withOwnerQuota := func(r *schedulingv1alpha1.Reservation, quotaName string) *schedulingv1alpha1.Reservation {
+		r.Spec.Owners[0].LabelSelector.MatchLabels[apiext.LabelQuotaName] = quotaName
+		return r
+	}
+	fullQuota := newQuota("full-quota", "kube-system")
+	fullQuota.Status.Used = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10")}
+
+	sche := runtime.NewScheme()
+	_ = schedulingv1alpha1.AddToScheme(sche)
+	_ = quotav1alpha1.AddToScheme(sche)
+	client := fake.NewClientBuilder().WithScheme(sche).WithObjects(
+		newQuota("test-quota", "kube-system"),
+		newQuota("test-ns", "test-ns"),
+		fullQuota,
+		newReservation("full-pending", "full-quota", "2", schedulingv1alpha1.ReservationPending),
+		// the pending reservation is charged since admitted
+		newReservation("pending", "test-quota", "4", schedulingv1alpha1.ReservationPending),
+		// the active reservation has been charged to the quota used by the scheduler
+		newReservation("active", "test-quota", "4", schedulingv1alpha1.ReservationAvailable),
+		newReservation("failed", "test-quota", "4", schedulingv1alpha1.ReservationFailed),
+	).Build()
+	handler := &ReservationValidatingHandler{Client: client}
+
+	tests := []struct {
+		name           string
+		accountingMode config.ReservationAccountingMode
+		oldR           *schedulingv1alpha1.Reservation
+		newR           *schedulingv1alpha1.Reservation
+		wantErr        bool
+	}{
+		{
+			name: "fit in the quota",
+			newR: newReservation("test", "test-quota", "2", ""),
+		},
+		{
+			name:    "exceed the quota with the pending reservations",
+			newR:    newReservation("test", "test-quota", "3", ""),
+			wantErr: true,
+		},
+		{
+			name:    "exceed the quota of the namespace",
+			newR:    newReservation("test", "", "7", ""),
+			wantErr: true,
+		},
+		{
+			name: "fit in the quota of the namespace",
+			newR: newReservation("test", "", "6", ""),
+		},
+		{
+			name: "pending reservation is not charged twice",
+			oldR: newReservation("pending", "test-quota", "4", schedulingv1alpha1.ReservationPending),
+			newR: newReservation("pending", "test-quota", "6", schedulingv1alpha1.ReservationPending),
+		},
+		{
+			name: "active reservation is charged with the increment",
+			oldR: newReservation("active", "test-quota", "4", schedulingv1alpha1.ReservationAvailable),
+			newR: newReservation("active", "test-quota", "6", schedulingv1alpha1.ReservationAvailable),
+		},
+		{
+			name:    "active reservation exceeds the quota with the increment",
+			oldR:    newReservation("active", "test-quota", "4", schedulingv1alpha1.ReservationAvailable),
+			newR:    newReservation("active", "test-quota", "7", schedulingv1alpha1.ReservationAvailable),
+			wantErr: true,
+		},
+		{
+			name: "quota not found",
+			newR: newReservation("test", "unknown-quota", "100", ""),
+		},
+		{
+			name: "metadata update is not charged in the full quota",
+			oldR: newReservation("full-pending", "full-quota", "2", schedulingv1alpha1.ReservationPending),
+			newR: withLabels(newReservation("full-pending", "full-quota", "2", schedulingv1alpha1.ReservationPending), map[string]string{"foo": "bar"}),
+		},
+		{
+			name:    "pending reservation exceeds the full quota with the increment",
+			oldR:    newReservation("full-pending", "full-quota", "2", schedulingv1alpha1.ReservationPending),
+			newR:    newReservation("full-pending", "full-quota", "3", schedulingv1alpha1.ReservationPending),
+			wantErr: true,
+		},
+		{
+			name:           "charged to the reservation quota in the Reservation accounting mode",
+			accountingMode: config.ReservationAccountingReservation,
+			newR:           withOwnerQuota(newReservation("test", "test-quota", "2", ""), "full-quota"),
+		},
+		{
+			name:           "charged to the owner quota in the Owner accounting mode",
+			accountingMode: config.ReservationAccountingOwner,
+			newR:           withOwnerQuota(newReservation("test", "test-quota", "2", ""), "full-quota"),
+			wantErr:        true,
+		},
+		{
+			name:           "charged to the reservation quota if the owners specify no quota",
+			accountingMode: config.ReservationAccountingOwner,
+			newR:           newReservation("test", "test-quota", "2", ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.accountingMode != "" {
+				defer func(mode config.ReservationAccountingMode) { ReservationQuotaAccountingMode = mode }(ReservationQuotaAccountingMode)
+				ReservationQuotaAccountingMode = tt.accountingMode
+			}
+			errs := handler.validateReservationQuota(context.TODO(), tt.oldR, tt.newR)
+			assert.Equal(t, tt.wantErr, len(errs) > 0, errs.ToAggregate())
+		})
+	}
+}
+
+func TestReservationValidatingHandler_validateReservationOwnerQuotas(t *testing.T) {
+	withOwnerQuotas := func(quotaNames ...string) *schedulingv1alpha1.Reservation {
+		r := newTestReservation()
+		r.Spec.Owners = nil
+		for _, quotaName := range quotaNames {
+			r.Spec.Owners = append(r.Spec.Owners, schedulingv1alpha1.ReservationOwner{
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{apiext.LabelQuotaName: quotaName},
+				},
+			})
+		}
+		return r
+	}
+	handler := &ReservationValidatingHandler{}
+
+	tests := []struct {
+		name           string
+		accountingMode config.ReservationAccountingMode
+		r              *schedulingv1alpha1.Reservation
+		wantErr        bool
+	}{
+		{
+			name:           "owners in the same quota",
+			accountingMode: config.ReservationAccountingOwner,
+			r:              withOwnerQuotas("test-quota", "test-quota"),
+		},
+		{
+			name:           "owners in different quotas",
+			accountingMode: config.ReservationAccountingOwner,
+			r:              withOwnerQuotas("test-quota", "other-quota"),
+			wantErr:        true,
+		},
+		{
+			name:           "owners in different quotas in the Reservation accounting mode",
+			accountingMode: config.ReservationAccountingReservation,
+			r:              withOwnerQuotas("test-quota", "other-quota"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(mode config.ReservationAccountingMode) { ReservationQuotaAccountingMode = mode }(ReservationQuotaAccountingMode)
+			ReservationQuotaAccountingMode = tt.accountingMode
+			errs := handler.validateReservationOwnerQuotas(tt.r)
+			assert.Equal(t, tt.wantErr, len(errs) > 0, errs.ToAggregate())
+		})
+	}
+}
+
+func Test_reservationAccountingModeValue(t *testing.T) {
+	mode := config.ReservationAccountingReservation
+	value := &reservationAccountingModeValue{mode: &mode}
+	assert.NoError(t, value.Set(string(config.ReservationAccountingOwner)))
+	assert.Equal(t, config.ReservationAccountingOwner, mode)
+	assert.Equal(t, string(config.ReservationAccountingOwner), value.String())
+	// the typo is rejected instead of falling back to the Reservation mode
+	assert.Error(t, value.Set("owner"))
+	assert.Equal(t, config.ReservationAccountingOwner, mode)
+}
+
+func Test_validateReservation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(r *schedulingv1alpha1.Reservation)
+		wantErr bool
+	}{
+		{
+			name:   "valid reservation",
+			mutate: func(r *schedulingv1alpha1.Reservation) {},
+		},
+		{
+			name: "missing template",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Template = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing containers",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Template.Spec.Containers = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative requests",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("-1")
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid owner label selector",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Owners[0].LabelSelector.MatchLabels = map[string]string{"app": "invalid value"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative ttl",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.TTL = &metav1.Duration{Duration: -1}
+			},
+			wantErr: true,
+		},
+		{
+			name: "renew time",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Annotations = map[string]string{apiext.AnnotationReservationRenewTime: "2022-10-01T08:00:00Z"}
+			},
+		},
+		{
+			name: "invalid renew time",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Annotations = map[string]string{apiext.AnnotationReservationRenewTime: "1h"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "taints without capacity-only",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Taints = []corev1.Taint{{Key: "pool", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "capacity-only reservation with taints",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Owners = nil
+				r.Spec.CapacityOnly = true
+				r.Spec.Taints = []corev1.Taint{{Key: "pool", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+			},
+		},
+		{
+			name: "node selector and affinity",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Template.Spec.NodeSelector = map[string]string{"zone": "a"}
+				r.Spec.NodeSelector = map[string]string{"zone": "a", "pool": "dedicated"}
+				r.Spec.NodeAffinity = &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "pool", Operator: corev1.NodeSelectorOpExists},
+								},
+							},
+						},
+					},
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+						{
+							Weight: 10,
+							Preference: corev1.NodeSelectorTerm{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "gpu", Operator: corev1.NodeSelectorOpDoesNotExist},
+								},
+							},
+						},
+					},
+				}
+			},
+		},
+		{
+			name: "invalid node selector",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.NodeSelector = map[string]string{"pool": "invalid value"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "node selector conflicting with the template",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.Template.Spec.NodeSelector = map[string]string{"pool": "shared"}
+				r.Spec.NodeSelector = map[string]string{"pool": "dedicated"}
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty required node selector terms",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.NodeAffinity = &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid required node selector operator",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.NodeAffinity = &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "pool", Operator: "Unknown"},
+								},
+							},
+						},
+					},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid preferred term weight",
+			mutate: func(r *schedulingv1alpha1.Reservation) {
+				r.Spec.NodeAffinity = &corev1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+						{
+							Weight: 101,
+							Preference: corev1.NodeSelectorTerm{
+								MatchExpressions: []corev1.NodeSelectorRequirement{
+									{Key: "gpu", Operator: corev1.NodeSelectorOpExists},
+								},
+							},
+						},
+					},
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReservation()
+			tt.mutate(r)
+			errs := validateReservation(r)
+			assert.Equal(t, tt.wantErr, len(errs) > 0, errs)
+		})
+	}
+}
+
+func Test_validateReservationUpdate(t *testing.T) {
+	oldR := newTestReservation()
+	oldR.Status = schedulingv1alpha1.ReservationStatus{
+		Phase:    schedulingv1alpha1.ReservationAvailable,
+		NodeName: "test-node",
+	}
+
+	// the expiration can be updated
+	newR := oldR.DeepCopy()
+	newR.Spec.TTL = &metav1.Duration{Duration: 1}
+	newR.Labels = map[string]string{"foo": "bar"}
+	assert.Empty(t, validateReservationUpdate(oldR, newR))
+
+	newR = oldR.DeepCopy()
+	newR.Spec.Owners = nil
+	newR.Spec.AllocateOnce = true
+	errs := validateReservationUpdate(oldR, newR)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.owners", errs[0].Field)
+	assert.Equal(t, "spec.allocateOnce", errs[1].Field)
+
+	// the nodes of the reservation cannot be restricted after it is Available
+	newR = oldR.DeepCopy()
+	newR.Spec.NodeSelector = map[string]string{"pool": "dedicated"}
+	errs = validateReservationUpdate(oldR, newR)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.nodeSelector", errs[0].Field)
+
+	// the resources can be resized except the devices
+	newR = oldR.DeepCopy()
+	newR.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("8")
+	assert.Empty(t, validateReservationUpdate(oldR, newR))
+	newR.Spec.Template.Spec.Containers[0].Resources.Requests[apiext.ResourceGPUCore] = resource.MustParse("100")
+	errs = validateReservationUpdate(oldR, newR)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.template.spec", errs[0].Field)
+	newR = oldR.DeepCopy()
+	newR.Spec.Template.Spec.Containers[0].Image = "test-image"
+	errs = validateReservationUpdate(oldR, newR)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "spec.template", errs[0].Field)
+
+	// the pending reservation is mutable
+	oldR.Status = schedulingv1alpha1.ReservationStatus{}
+	assert.Empty(t, validateReservationUpdate(oldR, newR))
+}
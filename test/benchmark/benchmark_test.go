@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	traceFiles     = flag.String("trace-files", "testdata/*.json", "The glob of the trace files to replay.")
+	speedup        = flag.Float64("speedup", 0, "Compress the arrivals of the pods by the factor, zero submits all pods back to back.")
+	idleTimeout    = flag.Duration("idle-timeout", 5*time.Second, "End the replay when no pod is bound in the duration.")
+	baselineFile   = flag.String("baseline", "", "Fail the benchmark if the results regress from the baseline file.")
+	updateBaseline = flag.Bool("update-baseline", false, "Write the results to the baseline file instead of comparing with it.")
+	tolerance      = flag.Float64("tolerance", 0.2, "The ratio of the throughput and latency fluctuations allowed by the baseline.")
+	perfDataDir    = flag.String("perf-data-dir", "", "Write the results of each trace as perfdash data into the directory.")
+)
+
+func loadTraces(tb testing.TB) []*Trace {
+	paths, err := filepath.Glob(*traceFiles)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if len(paths) == 0 {
+		tb.Fatalf("no trace matches %s", *traceFiles)
+	}
+	var traces []*Trace
+	for _, path := range paths {
+		trace, err := LoadTrace(path)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		traces = append(traces, trace)
+	}
+	return traces
+}
+
+// BenchmarkReplayTraces replays each trace against koord-scheduler, e.g.
+//
+//	go test ./test/benchmark -run ^$ -bench . -benchtime 3x -baseline baseline.json
+//
+// which fails if the results regress from the baseline written with -update-baseline.
+func BenchmarkReplayTraces(b *testing.B) {
+	var baseline map[string]*Result
+	if *baselineFile != "" && !*updateBaseline {
+		var err error
+		if baseline, err = LoadBaseline(*baselineFile); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var results []*Result
+	for _, trace := range loadTraces(b) {
+		trace := trace
+		b.Run(trace.Name, func(b *testing.B) {
+			var result *Result
+			for i := 0; i < b.N; i++ {
+				var err error
+				result, err = Replay(context.TODO(), trace, Options{Speedup: *speedup, IdleTimeout: *idleTimeout})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(result.Throughput, "pods/s")
+			b.ReportMetric(float64(result.LatencyP50)/float64(time.Millisecond), "p50-ms")
+			b.ReportMetric(float64(result.LatencyP99)/float64(time.Millisecond), "p99-ms")
+			b.ReportMetric(float64(result.Unschedulable), "unschedulable")
+			b.Log(result)
+			results = append(results, result)
+
+			if *perfDataDir != "" {
+				data, err := json.Marshal(result.PerfData())
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(*perfDataDir, trace.Name+".json"), data, 0644); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if expected := baseline[trace.Name]; expected != nil {
+				if err := result.CheckRegression(expected, *tolerance); err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	}
+
+	if *baselineFile != "" && *updateBaseline {
+		if err := SaveBaseline(*baselineFile, results); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReplayTraces(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip replaying the traces in short mode")
+	}
+	for _, trace := range loadTraces(t) {
+		trace := trace
+		t.Run(trace.Name, func(t *testing.T) {
+			result, err := Replay(context.TODO(), trace, Options{IdleTimeout: *idleTimeout})
+			assert.NoError(t, err)
+			assert.Equal(t, len(trace.Pods), result.Pods)
+			assert.Equal(t, result.Pods, result.Scheduled+result.Unschedulable)
+			assert.NotZero(t, result.Scheduled)
+			t.Log(result)
+		})
+	}
+}
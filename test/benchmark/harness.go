@@ -0,0 +1,387 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"sync"
+	"time"
+
+	nrtclientset "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned"
+	nrtfake "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/generated/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler"
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	pgclientset "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned"
+	pgfake "sigs.k8s.io/scheduler-plugins/pkg/generated/clientset/versioned/fake"
+
+	koordfake "github.com/koordinator-sh/koordinator/pkg/client/clientset/versioned/fake"
+	koordinatorinformers "github.com/koordinator-sh/koordinator/pkg/client/informers/externalversions"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config/scheme"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/eventhandlers"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/frameworkext/sharedlisterext"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/batchresource"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/compatibledefaultpreemption"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/coscheduling"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/deviceshare"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/elasticquota"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/loadaware"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/nodenumaresource"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/preferrednodes"
+	"github.com/koordinator-sh/koordinator/pkg/scheduler/plugins/reservation"
+)
+
+//go:embed scheduler-config.yaml
+var schedulerConfig []byte
+
+// Options tunes the replay of a trace.
+type Options struct {
+	// Speedup compresses the arrivals of the pods, e.g. 60 replays a trace of an hour in a minute.
+	// Zero submits all pods back to back, which measures the peak throughput of the scheduler.
+	Speedup float64
+	// IdleTimeout ends the replay when no pod is bound in the duration after all pods are submitted,
+	// and the pods still pending are reported as unschedulable.
+	IdleTimeout time.Duration
+}
+
+func (o *Options) complete() {
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = 5 * time.Second
+	}
+}
+
+// Replay schedules the pods of the trace with koord-scheduler running all the koordinator plugins against fake
+// clients, and measures the latency from the creation to the binding of each pod.
+func Replay(ctx context.Context, trace *Trace, opts Options) (*Result, error) {
+	opts.complete()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	nodes, devices := trace.buildNodes()
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "pods", bindPodReactor(kubeClient))
+	koordClient := koordfake.NewSimpleClientset()
+	pgClient := pgfake.NewSimpleClientset()
+	for _, node := range nodes {
+		if _, err := kubeClient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	for _, device := range devices {
+		if _, err := koordClient.SchedulingV1alpha1().Devices().Create(ctx, device, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	for _, quota := range trace.buildQuotas() {
+		if _, err := pgClient.SchedulingV1alpha1().ElasticQuotas(quota.Namespace).Create(ctx, quota, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	informerFactory := scheduler.NewInformerFactory(kubeClient, 0)
+	koordSharedInformerFactory := koordinatorinformers.NewSharedInformerFactory(koordClient, 0)
+	sched, extenderFactory, err := newScheduler(ctx, kubeClient, informerFactory, koordClient, koordSharedInformerFactory, pgClient, nrtfake.NewSimpleClientset())
+	if err != nil {
+		return nil, err
+	}
+	recorder := newLatencyRecorder(len(trace.Pods))
+	informerFactory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    recorder.onPodAdd,
+		UpdateFunc: recorder.onPodUpdate,
+	})
+
+	informerFactory.Start(ctx.Done())
+	koordSharedInformerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+	koordSharedInformerFactory.WaitForCacheSync(ctx.Done())
+	go extenderFactory.Run()
+	go sched.Run(ctx)
+
+	start := time.Now()
+	for i := range trace.Pods {
+		tracePod := &trace.Pods[i]
+		if opts.Speedup > 0 {
+			arrival := start.Add(time.Duration(float64(tracePod.ArrivalMillis)/opts.Speedup) * time.Millisecond)
+			select {
+			case <-time.After(time.Until(arrival)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if err := recorder.throttle(ctx); err != nil {
+			return nil, err
+		}
+		pod := tracePod.buildPod()
+		recorder.onPodSubmit(pod)
+		if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	if err := recorder.wait(ctx, opts.IdleTimeout); err != nil {
+		return nil, err
+	}
+	return recorder.result(trace.Name), nil
+}
+
+func newScheduler(ctx context.Context, kubeClient *kubefake.Clientset, informerFactory informers.SharedInformerFactory,
+	koordClient *koordfake.Clientset, koordSharedInformerFactory koordinatorinformers.SharedInformerFactory,
+	pgClient pgclientset.Interface, nrtClient nrtclientset.Interface) (*scheduler.Scheduler, *frameworkext.FrameworkExtenderFactory, error) {
+	cfg, err := loadSchedulerConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	extenderFactory, err := frameworkext.NewFrameworkExtenderFactory(
+		frameworkext.WithKoordinatorClientSet(koordClient),
+		frameworkext.WithKoordinatorSharedInformerFactory(koordSharedInformerFactory),
+		frameworkext.WithSharedListerFactory(sharedlisterext.NewSharedListerAdapter),
+		frameworkext.WithDefaultTransformers(frameworkext.DefaultTransformers...),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	registry, err := newPluginRegistry(extenderFactory, pgClient, nrtClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	sched, err := scheduler.New(kubeClient,
+		informerFactory,
+		func(string) events.EventRecorder { return &events.FakeRecorder{} },
+		ctx.Done(),
+		scheduler.WithProfiles(cfg.Profiles...),
+		scheduler.WithPercentageOfNodesToScore(cfg.PercentageOfNodesToScore),
+		scheduler.WithFrameworkOutOfTreeRegistry(registry),
+		scheduler.WithPodMaxBackoffSeconds(cfg.PodMaxBackoffSeconds),
+		scheduler.WithPodInitialBackoffSeconds(cfg.PodInitialBackoffSeconds),
+		scheduler.WithParallelism(cfg.Parallelism),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k := range sched.Profiles {
+		if extender := extenderFactory.GetExtender(k); extender != nil {
+			sched.Profiles[k] = extender
+		}
+	}
+	schedulerInternalHandler := &eventhandlers.SchedulerInternalHandlerImpl{
+		Scheduler:  sched,
+		NodeLister: informerFactory.Core().V1().Nodes().Lister(),
+	}
+	eventhandlers.AddScheduleEventHandler(sched, schedulerInternalHandler, koordClient, koordSharedInformerFactory)
+	eventhandlers.AddReservationErrorHandler(sched, schedulerInternalHandler, koordClient, koordSharedInformerFactory)
+	return sched, extenderFactory, nil
+}
+
+func loadSchedulerConfig() (*kubeschedulerconfig.KubeSchedulerConfiguration, error) {
+	obj, gvk, err := scheme.Codecs.UniversalDecoder().Decode(schedulerConfig, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok := obj.(*kubeschedulerconfig.KubeSchedulerConfiguration)
+	if !ok {
+		return nil, fmt.Errorf("couldn't decode as KubeSchedulerConfiguration, got %s", gvk)
+	}
+	return cfg, nil
+}
+
+// pgClientHandle and nrtClientHandle supply the fake clients to the plugins building their clients from the handle.
+type pgClientHandle struct {
+	frameworkext.ExtendedHandle
+	pgclientset.Interface
+}
+
+type nrtClientHandle struct {
+	frameworkext.ExtendedHandle
+	nrtclientset.Interface
+}
+
+// newPluginRegistry registers the same plugins as cmd/koord-scheduler.
+func newPluginRegistry(extenderFactory *frameworkext.FrameworkExtenderFactory, pgClient pgclientset.Interface, nrtClient nrtclientset.Interface) (frameworkruntime.Registry, error) {
+	withPGClient := func(factory frameworkruntime.PluginFactory) frameworkruntime.PluginFactory {
+		return func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return factory(args, &pgClientHandle{ExtendedHandle: handle.(frameworkext.ExtendedHandle), Interface: pgClient})
+		}
+	}
+	withNRTClient := func(factory frameworkruntime.PluginFactory) frameworkruntime.PluginFactory {
+		return func(args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+			return factory(args, &nrtClientHandle{ExtendedHandle: handle.(frameworkext.ExtendedHandle), Interface: nrtClient})
+		}
+	}
+	koordinatorPlugins := map[string]frameworkruntime.PluginFactory{
+		loadaware.Name:                   loadaware.New,
+		nodenumaresource.Name:            withNRTClient(nodenumaresource.New),
+		reservation.Name:                 reservation.New,
+		batchresource.Name:               batchresource.New,
+		coscheduling.Name:                withPGClient(coscheduling.New),
+		deviceshare.Name:                 deviceshare.New,
+		elasticquota.Name:                withPGClient(elasticquota.New),
+		compatibledefaultpreemption.Name: compatibledefaultpreemption.New,
+		preferrednodes.Name:              preferrednodes.New,
+	}
+	registry := frameworkruntime.Registry{}
+	for name, factory := range koordinatorPlugins {
+		if err := registry.Register(name, frameworkext.PluginFactoryProxy(extenderFactory, factory)); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// bindPodReactor binds the pods in the fake client, which handles the creation of the pods/binding subresource
+// as an update of the pod with the binding object otherwise.
+func bindPodReactor(client *kubefake.Clientset) clienttesting.ReactionFunc {
+	return func(action clienttesting.Action) (bool, runtime.Object, error) {
+		createAction, ok := action.(clienttesting.CreateAction)
+		if !ok || createAction.GetSubresource() != "binding" {
+			return false, nil, nil
+		}
+		binding, ok := createAction.GetObject().(*corev1.Binding)
+		if !ok {
+			return false, nil, nil
+		}
+		podsGVR := corev1.SchemeGroupVersion.WithResource("pods")
+		obj, err := client.Tracker().Get(podsGVR, binding.Namespace, binding.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		pod := obj.(*corev1.Pod).DeepCopy()
+		pod.Spec.NodeName = binding.Target.Name
+		return true, binding, client.Tracker().Update(podsGVR, pod, pod.Namespace)
+	}
+}
+
+// maxUnobservedPods bounds the pods submitted but not yet observed by the informer. The fake watchers buffer at most
+// 100 events and panic when full, so the pods submitted back to back must not outpace the informer.
+const maxUnobservedPods = 32
+
+// latencyRecorder tracks the submitted pods until they are bound.
+type latencyRecorder struct {
+	lock        sync.Mutex
+	submitted   map[string]time.Time
+	observed    int
+	latencies   []time.Duration
+	firstSubmit time.Time
+	lastBind    time.Time
+	progress    chan struct{}
+}
+
+func newLatencyRecorder(size int) *latencyRecorder {
+	return &latencyRecorder{
+		submitted: make(map[string]time.Time, size),
+		latencies: make([]time.Duration, 0, size),
+		progress:  make(chan struct{}, 1),
+	}
+}
+
+func (r *latencyRecorder) onPodSubmit(pod *corev1.Pod) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := time.Now()
+	if r.firstSubmit.IsZero() {
+		r.firstSubmit = now
+	}
+	r.submitted[pod.Namespace+"/"+pod.Name] = now
+}
+
+func (r *latencyRecorder) onPodAdd(obj interface{}) {
+	if _, ok := obj.(*corev1.Pod); !ok {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.observed++
+}
+
+// throttle waits until the informer catches up with the submitted pods.
+func (r *latencyRecorder) throttle(ctx context.Context) error {
+	return wait.PollImmediateUntil(time.Millisecond, func() (bool, error) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		return len(r.submitted)-r.observed < maxUnobservedPods, nil
+	}, ctx.Done())
+}
+
+func (r *latencyRecorder) onPodUpdate(oldObj, newObj interface{}) {
+	oldPod, ok := oldObj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	newPod, ok := newObj.(*corev1.Pod)
+	if !ok || oldPod.Spec.NodeName != "" || newPod.Spec.NodeName == "" {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	submitted, ok := r.submitted[newPod.Namespace+"/"+newPod.Name]
+	if !ok {
+		return
+	}
+	r.lastBind = time.Now()
+	r.latencies = append(r.latencies, r.lastBind.Sub(submitted))
+	select {
+	case r.progress <- struct{}{}:
+	default:
+	}
+}
+
+// wait waits until all pods are bound or no pod is bound during the idle timeout.
+func (r *latencyRecorder) wait(ctx context.Context, idleTimeout time.Duration) error {
+	timer := time.NewTimer(idleTimeout)
+	defer timer.Stop()
+	for {
+		r.lock.Lock()
+		done := len(r.latencies) == len(r.submitted)
+		r.lock.Unlock()
+		if done {
+			return nil
+		}
+		select {
+		case <-r.progress:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(idleTimeout)
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *latencyRecorder) result(traceName string) *Result {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var elapsed time.Duration
+	if len(r.latencies) > 0 {
+		elapsed = r.lastBind.Sub(r.firstSubmit)
+	}
+	return newResult(traceName, len(r.submitted), elapsed, r.latencies)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/koordinator-sh/koordinator/test/e2e/perftype"
+)
+
+// Result is the scheduling performance measured by replaying a trace.
+type Result struct {
+	Trace         string `json:"trace"`
+	Pods          int    `json:"pods"`
+	Scheduled     int    `json:"scheduled"`
+	Unschedulable int    `json:"unschedulable"`
+	// Throughput is the number of the pods bound per second.
+	Throughput float64 `json:"throughput"`
+	// LatencyP50, LatencyP90 and LatencyP99 are the percentiles of the duration from the creation to the binding
+	// of the scheduled pods.
+	LatencyP50 time.Duration `json:"latencyP50"`
+	LatencyP90 time.Duration `json:"latencyP90"`
+	LatencyP99 time.Duration `json:"latencyP99"`
+}
+
+func newResult(traceName string, pods int, elapsed time.Duration, latencies []time.Duration) *Result {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	result := &Result{
+		Trace:         traceName,
+		Pods:          pods,
+		Scheduled:     len(sorted),
+		Unschedulable: pods - len(sorted),
+		LatencyP50:    percentile(sorted, 0.5),
+		LatencyP90:    percentile(sorted, 0.9),
+		LatencyP99:    percentile(sorted, 0.99),
+	}
+	if elapsed > 0 {
+		result.Throughput = float64(len(sorted)) / elapsed.Seconds()
+	}
+	return result
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("trace %s: %d/%d pods scheduled, throughput %.2f pods/s, latency p50 %v, p90 %v, p99 %v",
+		r.Trace, r.Scheduled, r.Pods, r.Throughput, r.LatencyP50, r.LatencyP90, r.LatencyP99)
+}
+
+// PerfData converts the result into the format of perfdash.
+func (r *Result) PerfData() *perftype.PerfData {
+	toMillis := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return &perftype.PerfData{
+		Version: "v1",
+		DataItems: []perftype.DataItem{
+			{
+				Data: map[string]float64{
+					"Perc50": toMillis(r.LatencyP50),
+					"Perc90": toMillis(r.LatencyP90),
+					"Perc99": toMillis(r.LatencyP99),
+				},
+				Unit:   "ms",
+				Labels: map[string]string{"Metric": "SchedulingLatency"},
+			},
+			{
+				Data:   map[string]float64{"Average": r.Throughput},
+				Unit:   "pods/s",
+				Labels: map[string]string{"Metric": "SchedulingThroughput"},
+			},
+		},
+		Labels: map[string]string{"Trace": r.Trace},
+	}
+}
+
+// CheckRegression compares the result with the baseline of the same trace. It fails if fewer pods are scheduled,
+// or the throughput drops or the p99 latency grows by more than the tolerance, e.g. 0.2 allows 20% fluctuations.
+func (r *Result) CheckRegression(baseline *Result, tolerance float64) error {
+	if r.Scheduled < baseline.Scheduled {
+		return fmt.Errorf("trace %s: scheduled pods regressed from %d to %d", r.Trace, baseline.Scheduled, r.Scheduled)
+	}
+	if r.Throughput < baseline.Throughput*(1-tolerance) {
+		return fmt.Errorf("trace %s: throughput regressed from %.2f to %.2f pods/s", r.Trace, baseline.Throughput, r.Throughput)
+	}
+	if float64(r.LatencyP99) > float64(baseline.LatencyP99)*(1+tolerance) {
+		return fmt.Errorf("trace %s: p99 latency regressed from %v to %v", r.Trace, baseline.LatencyP99, r.LatencyP99)
+	}
+	return nil
+}
+
+// LoadBaseline reads the results saved by SaveBaseline, indexed by the trace names.
+func LoadBaseline(path string) (map[string]*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []*Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s, err: %w", path, err)
+	}
+	baseline := make(map[string]*Result, len(results))
+	for _, r := range results {
+		baseline[r.Trace] = r
+	}
+	return baseline, nil
+}
+
+func SaveBaseline(path string, results []*Result) error {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Trace < results[j].Trace
+	})
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResult(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	result := newResult("test", 120, 10*time.Second, latencies)
+	assert.Equal(t, &Result{
+		Trace:         "test",
+		Pods:          120,
+		Scheduled:     100,
+		Unschedulable: 20,
+		Throughput:    10,
+		LatencyP50:    50 * time.Millisecond,
+		LatencyP90:    90 * time.Millisecond,
+		LatencyP99:    99 * time.Millisecond,
+	}, result)
+	// the latencies of the recorder are not reordered
+	assert.Equal(t, 100*time.Millisecond, latencies[0])
+
+	assert.Equal(t, &Result{Trace: "test", Pods: 3, Unschedulable: 3}, newResult("test", 3, 0, nil))
+}
+
+func TestResult_CheckRegression(t *testing.T) {
+	baseline := &Result{
+		Trace:      "test",
+		Scheduled:  100,
+		Throughput: 100,
+		LatencyP99: 100 * time.Millisecond,
+	}
+	tests := []struct {
+		name    string
+		result  Result
+		wantErr bool
+	}{
+		{
+			name:   "fluctuations in tolerance",
+			result: Result{Trace: "test", Scheduled: 100, Throughput: 85, LatencyP99: 115 * time.Millisecond},
+		},
+		{
+			name:    "fewer pods scheduled",
+			result:  Result{Trace: "test", Scheduled: 99, Throughput: 100, LatencyP99: 100 * time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:    "throughput regressed",
+			result:  Result{Trace: "test", Scheduled: 100, Throughput: 70, LatencyP99: 100 * time.Millisecond},
+			wantErr: true,
+		},
+		{
+			name:    "latency regressed",
+			result:  Result{Trace: "test", Scheduled: 100, Throughput: 100, LatencyP99: 130 * time.Millisecond},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.result.CheckRegression(baseline, 0.2)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestSaveAndLoadBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	results := []*Result{
+		{Trace: "b", Pods: 10, Scheduled: 10, Throughput: 20, LatencyP99: time.Second},
+		{Trace: "a", Pods: 5, Scheduled: 4, Unschedulable: 1, Throughput: 10, LatencyP50: time.Millisecond},
+	}
+	assert.NoError(t, SaveBaseline(path, results))
+	baseline, err := LoadBaseline(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*Result{"a": results[0], "b": results[1]}, baseline)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/utils/pointer"
+	pgv1alpha1 "sigs.k8s.io/scheduler-plugins/pkg/apis/scheduling/v1alpha1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	schedulingv1alpha1 "github.com/koordinator-sh/koordinator/apis/scheduling/v1alpha1"
+)
+
+const (
+	schedulerName = "koord-scheduler"
+
+	defaultNamespace = "default"
+	defaultMaxPods   = 110
+	// gpuMemoryPerDevice is the memory of each GPU, traces only record the GPU ratios the pods request.
+	gpuMemoryPerDevice = "80Gi"
+)
+
+// Trace is an anonymized record of the pods submitted to a cluster, which is replayed against koord-scheduler.
+// The names of the nodes, namespaces, quotas, gangs and pods are replaced with generated ones, and only the
+// features affecting the scheduling are kept.
+type Trace struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// NodePools are the groups of identical nodes in the cluster.
+	NodePools []NodePool `json:"nodePools"`
+	// Quotas are the elastic quotas the pods are charged to.
+	Quotas []Quota `json:"quotas,omitempty"`
+	// Pods are the pods in the order of arrival.
+	Pods []TracePod `json:"pods"`
+}
+
+type NodePool struct {
+	Name   string            `json:"name"`
+	Count  int               `json:"count"`
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+	// GPUs is the number of GPUs on each node.
+	GPUs int `json:"gpus,omitempty"`
+}
+
+type Quota struct {
+	Name      string              `json:"name"`
+	Namespace string              `json:"namespace,omitempty"`
+	Min       corev1.ResourceList `json:"min,omitempty"`
+	Max       corev1.ResourceList `json:"max,omitempty"`
+}
+
+type TracePod struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// ArrivalMillis is the offset of the pod creation from the start of the trace.
+	ArrivalMillis int64             `json:"arrivalMillis"`
+	CPU           resource.Quantity `json:"cpu"`
+	Memory        resource.Quantity `json:"memory"`
+	// GPU is the GPU ratio requested by the pod, where 100 is a whole GPU and 50 is half of a shared GPU.
+	GPU int64 `json:"gpu,omitempty"`
+	// Gang and GangMinMember declare the gang the pod belongs to.
+	Gang          string `json:"gang,omitempty"`
+	GangMinMember int    `json:"gangMinMember,omitempty"`
+	// Quota is the name of the elastic quota the pod is charged to.
+	Quota string `json:"quota,omitempty"`
+}
+
+// LoadTrace reads a trace from the JSON file.
+func LoadTrace(path string) (*Trace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trace := &Trace{}
+	if err := json.Unmarshal(data, trace); err != nil {
+		return nil, fmt.Errorf("failed to parse trace %s, err: %w", path, err)
+	}
+	if err := trace.validate(); err != nil {
+		return nil, fmt.Errorf("invalid trace %s, err: %w", path, err)
+	}
+	sort.SliceStable(trace.Pods, func(i, j int) bool {
+		return trace.Pods[i].ArrivalMillis < trace.Pods[j].ArrivalMillis
+	})
+	return trace, nil
+}
+
+func (t *Trace) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	if len(t.NodePools) == 0 {
+		return fmt.Errorf("no node pools")
+	}
+	quotas := map[string]bool{}
+	for _, q := range t.Quotas {
+		quotas[q.Name] = true
+	}
+	pods := map[string]bool{}
+	for i := range t.Pods {
+		p := &t.Pods[i]
+		key := getNamespace(p.Namespace) + "/" + p.Name
+		if p.Name == "" || pods[key] {
+			return fmt.Errorf("pod %d has an empty or duplicated name %q", i, key)
+		}
+		pods[key] = true
+		if p.ArrivalMillis < 0 {
+			return fmt.Errorf("pod %s arrives before the trace starts", key)
+		}
+		if p.GPU < 0 || (p.GPU > 100 && p.GPU%100 != 0) {
+			return fmt.Errorf("pod %s requests an invalid GPU ratio %d", key, p.GPU)
+		}
+		if p.Gang != "" && p.GangMinMember <= 0 {
+			return fmt.Errorf("pod %s has no gang min member", key)
+		}
+		if p.Quota != "" && !quotas[p.Quota] {
+			return fmt.Errorf("pod %s is charged to the unknown quota %s", key, p.Quota)
+		}
+	}
+	return nil
+}
+
+func (t *Trace) buildNodes() ([]*corev1.Node, []*schedulingv1alpha1.Device) {
+	var nodes []*corev1.Node
+	var devices []*schedulingv1alpha1.Device
+	for _, pool := range t.NodePools {
+		for i := 0; i < pool.Count; i++ {
+			name := fmt.Sprintf("%s-%04d", pool.Name, i)
+			allocatable := corev1.ResourceList{
+				corev1.ResourceCPU:    pool.CPU,
+				corev1.ResourceMemory: pool.Memory,
+				corev1.ResourcePods:   *resource.NewQuantity(defaultMaxPods, resource.DecimalSI),
+			}
+			if pool.GPUs > 0 {
+				gpuMemory := resource.MustParse(gpuMemoryPerDevice)
+				gpuMemory.Set(gpuMemory.Value() * int64(pool.GPUs))
+				allocatable[extension.ResourceGPUCore] = *resource.NewQuantity(int64(100*pool.GPUs), resource.DecimalSI)
+				allocatable[extension.ResourceGPUMemoryRatio] = *resource.NewQuantity(int64(100*pool.GPUs), resource.DecimalSI)
+				allocatable[extension.ResourceGPUMemory] = gpuMemory
+				devices = append(devices, buildGPUDevice(name, pool.GPUs))
+			}
+			nodes = append(nodes, &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+					Labels: map[string]string{
+						corev1.LabelHostname: name,
+					},
+				},
+				Status: corev1.NodeStatus{
+					Capacity:    allocatable,
+					Allocatable: allocatable,
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+					},
+				},
+			})
+		}
+	}
+	return nodes, devices
+}
+
+func buildGPUDevice(nodeName string, gpus int) *schedulingv1alpha1.Device {
+	device := &schedulingv1alpha1.Device{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeName,
+		},
+	}
+	for i := 0; i < gpus; i++ {
+		device.Spec.Devices = append(device.Spec.Devices, schedulingv1alpha1.DeviceInfo{
+			UUID:   fmt.Sprintf("%s-gpu-%d", nodeName, i),
+			Minor:  pointer.Int32(int32(i)),
+			Type:   schedulingv1alpha1.GPU,
+			Health: true,
+			Resources: corev1.ResourceList{
+				extension.ResourceGPUCore:        resource.MustParse("100"),
+				extension.ResourceGPUMemoryRatio: resource.MustParse("100"),
+				extension.ResourceGPUMemory:      resource.MustParse(gpuMemoryPerDevice),
+			},
+		})
+	}
+	return device
+}
+
+func (t *Trace) buildQuotas() []*pgv1alpha1.ElasticQuota {
+	var quotas []*pgv1alpha1.ElasticQuota
+	for _, q := range t.Quotas {
+		quotas = append(quotas, &pgv1alpha1.ElasticQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      q.Name,
+				Namespace: getNamespace(q.Namespace),
+				Labels: map[string]string{
+					extension.LabelQuotaParent: extension.RootQuotaName,
+				},
+			},
+			Spec: pgv1alpha1.ElasticQuotaSpec{
+				Min: q.Min,
+				Max: q.Max,
+			},
+		})
+	}
+	return quotas
+}
+
+// buildPod builds the pod as if it has been mutated by koord-manager, e.g. the GPU ratio is translated into
+// the GPU resources koord-scheduler allocates.
+func (p *TracePod) buildPod() *corev1.Pod {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    p.CPU,
+		corev1.ResourceMemory: p.Memory,
+	}
+	if p.GPU > 0 {
+		requests[extension.ResourceGPUCore] = *resource.NewQuantity(p.GPU, resource.DecimalSI)
+		requests[extension.ResourceGPUMemoryRatio] = *resource.NewQuantity(p.GPU, resource.DecimalSI)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              p.Name,
+			Namespace:         getNamespace(p.Namespace),
+			UID:               uuid.NewUUID(),
+			CreationTimestamp: metav1.Now(),
+			Labels:            map[string]string{},
+			Annotations:       map[string]string{},
+		},
+		Spec: corev1.PodSpec{
+			SchedulerName: schedulerName,
+			Containers: []corev1.Container{
+				{
+					Name: "main",
+					Resources: corev1.ResourceRequirements{
+						Requests: requests,
+					},
+				},
+			},
+		},
+	}
+	if p.Gang != "" {
+		pod.Annotations[extension.AnnotationGangName] = p.Gang
+		pod.Annotations[extension.AnnotationGangMinNum] = strconv.Itoa(p.GangMinMember)
+	}
+	if p.Quota != "" {
+		pod.Labels[extension.LabelQuotaName] = p.Quota
+	}
+	return pod
+}
+
+func getNamespace(namespace string) string {
+	if namespace == "" {
+		return defaultNamespace
+	}
+	return namespace
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package benchmark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestLoadTrace(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid trace",
+			data: `{"name": "test", "nodePools": [{"name": "node", "count": 2, "cpu": "32", "memory": "64Gi", "gpus": 2}],
+"quotas": [{"name": "quota-0", "max": {"cpu": "16"}}],
+"pods": [{"name": "pod-1", "arrivalMillis": 20, "cpu": "1", "memory": "1Gi", "gpu": 50, "quota": "quota-0"},
+{"name": "pod-0", "arrivalMillis": 10, "cpu": "1", "memory": "1Gi", "gang": "gang-0", "gangMinMember": 1}]}`,
+		},
+		{
+			name:    "duplicated pods",
+			data:    `{"name": "test", "nodePools": [{"name": "node", "count": 1}], "pods": [{"name": "pod-0"}, {"name": "pod-0"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid gpu ratio",
+			data:    `{"name": "test", "nodePools": [{"name": "node", "count": 1}], "pods": [{"name": "pod-0", "gpu": 150}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown quota",
+			data:    `{"name": "test", "nodePools": [{"name": "node", "count": 1}], "pods": [{"name": "pod-0", "quota": "quota-0"}]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "trace.json")
+			assert.NoError(t, os.WriteFile(path, []byte(tt.data), 0644))
+			trace, err := LoadTrace(path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			// the pods are sorted by the arrivals
+			assert.Equal(t, "pod-0", trace.Pods[0].Name)
+			pod := trace.Pods[1].buildPod()
+			assert.Equal(t, defaultNamespace, pod.Namespace)
+			assert.Equal(t, schedulerName, pod.Spec.SchedulerName)
+			assert.Equal(t, "quota-0", pod.Labels[extension.LabelQuotaName])
+			gpuCore := pod.Spec.Containers[0].Resources.Requests[extension.ResourceGPUCore]
+			assert.Equal(t, int64(50), gpuCore.Value())
+			gangPod := trace.Pods[0].buildPod()
+			assert.Equal(t, "gang-0", gangPod.Annotations[extension.AnnotationGangName])
+			assert.Equal(t, "1", gangPod.Annotations[extension.AnnotationGangMinNum])
+
+			nodes, devices := trace.buildNodes()
+			assert.Len(t, nodes, 2)
+			assert.Equal(t, "node-0001", nodes[1].Name)
+			nodeGPUCore := nodes[1].Status.Allocatable[extension.ResourceGPUCore]
+			assert.Equal(t, int64(200), nodeGPUCore.Value())
+			assert.Len(t, devices, 2)
+			assert.Len(t, devices[0].Spec.Devices, 2)
+			assert.Len(t, trace.buildQuotas(), 1)
+		})
+	}
+}